@@ -0,0 +1,286 @@
+// Command benchmark measures the execution engine on synthetic blueprints in both execution
+// modes, optionally with CPU and heap profiles, and reports how the modes compare.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/runner"
+)
+
+const usage = `Usage: benchmark [flags]
+
+Runs synthetic blueprints in the standard and actor execution modes and reports their
+throughput, latency and allocations. Scenarios:
+%s
+Flags:
+`
+
+// options are the parsed flags of a benchmark
+type options struct {
+	scenarios   []scenario
+	modes       []string
+	size        int
+	iterations  int
+	warmup      int
+	concurrency int
+	timeout     time.Duration
+	profileDir  string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the benchmark and returns the process exit code: 0 if every execution
+// succeeded, 1 if some failed and 2 for invalid arguments
+func run(args []string) int {
+	flags := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	flags.Usage = func() {
+		var descriptions strings.Builder
+		for _, s := range scenarios {
+			fmt.Fprintf(&descriptions, "  %-8s %s\n", s.name, s.description)
+		}
+		fmt.Fprintf(flags.Output(), usage, descriptions.String())
+		flags.PrintDefaults()
+	}
+	scenarioNames := flags.String("scenarios", "fan-out,chain,loop", "Comma-separated scenarios to run")
+	modeNames := flags.String("modes", runner.ModeStandard+","+runner.ModeActor, "Comma-separated execution modes to run")
+	size := flags.Int("size", 100, "Number of nodes, or loop iterations, of the generated blueprints")
+	iterations := flags.Int("iterations", 200, "Number of measured executions per scenario and mode")
+	warmup := flags.Int("warmup", 10, "Number of executions before measuring")
+	concurrency := flags.Int("concurrency", 1, "Number of executions running at a time")
+	timeout := flags.Duration("timeout", 30*time.Second, "Maximum duration of a single execution")
+	profileDir := flags.String("profile-dir", "", "Directory to write a CPU and a heap profile of every run to")
+	outputFormat := flags.String("output-format", outputFormatText, "Format of the report: text or json")
+	output := flags.String("output", "", "Path to write the report to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *size < 1 || *iterations < 1 || *warmup < 0 || *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "benchmark: size, iterations and concurrency must be positive")
+		return 2
+	}
+	if *outputFormat != outputFormatText && *outputFormat != outputFormatJSON {
+		fmt.Fprintf(os.Stderr, "benchmark: unknown output format %q\n", *outputFormat)
+		return 2
+	}
+
+	opts := options{
+		size:        *size,
+		iterations:  *iterations,
+		warmup:      *warmup,
+		concurrency: *concurrency,
+		timeout:     *timeout,
+		profileDir:  *profileDir,
+	}
+	for _, name := range splitList(*scenarioNames) {
+		s, exists := findScenario(name)
+		if !exists {
+			fmt.Fprintf(os.Stderr, "benchmark: unknown scenario %q\n", name)
+			return 2
+		}
+		opts.scenarios = append(opts.scenarios, s)
+	}
+	for _, mode := range splitList(*modeNames) {
+		if mode != runner.ModeStandard && mode != runner.ModeActor {
+			fmt.Fprintf(os.Stderr, "benchmark: unknown mode %q\n", mode)
+			return 2
+		}
+		opts.modes = append(opts.modes, mode)
+	}
+	if opts.profileDir != "" {
+		if err := os.MkdirAll(opts.profileDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "benchmark: %v\n", err)
+			return 2
+		}
+	}
+
+	result := &report{
+		GeneratedAt: time.Now(),
+		GoVersion:   runtime.Version(),
+		CPUs:        runtime.NumCPU(),
+		Size:        opts.size,
+		Iterations:  opts.iterations,
+		Concurrency: opts.concurrency,
+	}
+
+	exitCode := 0
+	runners := make(map[string]*runner.Runner, len(opts.modes))
+	for _, mode := range opts.modes {
+		runners[mode] = runner.New(runner.Options{Mode: mode, MaxDuration: opts.timeout})
+		defer runners[mode].Close()
+	}
+	for _, s := range opts.scenarios {
+		bp := s.generate(opts.size)
+		for _, mode := range opts.modes {
+			fmt.Fprintf(os.Stderr, "Running %s in %s mode...\n", s.name, mode)
+			m, err := measure(runners[mode], bp, s.name, mode, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "benchmark: %v\n", err)
+				return 1
+			}
+			if m.Failures > 0 {
+				exitCode = 1
+			}
+			result.Measurements = append(result.Measurements, m)
+		}
+	}
+	result.Comparisons = compare(result.Measurements)
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "benchmark: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeReport(out, result, *outputFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "benchmark: failed to write the report: %v\n", err)
+		return 1
+	}
+	return exitCode
+}
+
+// measure runs a blueprint the configured number of times and records the throughput,
+// latencies and allocations, profiling the measured executions if asked to
+func measure(r *runner.Runner, bp *blueprint.Blueprint, scenarioName, mode string, opts options) (measurement, error) {
+	m := measurement{
+		Scenario: scenarioName,
+		Mode:     mode,
+		Nodes:    len(bp.Nodes),
+	}
+
+	// Warm-up executions fill the pools and caches the measured ones reuse
+	execute(r, bp, opts.warmup, opts.concurrency, opts.timeout)
+
+	var cpuFile *os.File
+	if opts.profileDir != "" {
+		m.CPUProfile = filepath.Join(opts.profileDir, fmt.Sprintf("%s-%s.cpu.pprof", scenarioName, mode))
+		m.HeapProfile = filepath.Join(opts.profileDir, fmt.Sprintf("%s-%s.heap.pprof", scenarioName, mode))
+
+		var err error
+		if cpuFile, err = os.Create(m.CPUProfile); err != nil {
+			return m, err
+		}
+		defer cpuFile.Close()
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			return m, fmt.Errorf("failed to start the CPU profile: %w", err)
+		}
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	startedAt := time.Now()
+
+	latencies, nodesRun, failures, firstErr := execute(r, bp, opts.iterations, opts.concurrency, opts.timeout)
+
+	elapsed := time.Since(startedAt)
+	runtime.ReadMemStats(&after)
+	if cpuFile != nil {
+		pprof.StopCPUProfile()
+		if err := writeHeapProfile(m.HeapProfile); err != nil {
+			return m, err
+		}
+	}
+
+	m.Executions = len(latencies)
+	m.NodesRun = nodesRun
+	m.Failures = failures
+	m.DurationMs = milliseconds(elapsed)
+	m.Throughput = float64(m.Executions) / elapsed.Seconds()
+	m.Latency = summarize(latencies)
+	m.AllocsPerExecution = (after.Mallocs - before.Mallocs) / uint64(m.Executions)
+	m.BytesPerExecution = (after.TotalAlloc - before.TotalAlloc) / uint64(m.Executions)
+	if firstErr != nil {
+		m.FirstError = firstErr.Error()
+	}
+	return m, nil
+}
+
+// execute runs a blueprint count times, concurrency at a time, and returns the latency of
+// every execution, the most nodes that produced outputs in one, the number that failed and
+// the error of the first failure
+func execute(r *runner.Runner, bp *blueprint.Blueprint, count, concurrency int, timeout time.Duration) ([]time.Duration, int, int, error) {
+	latencies := make([]time.Duration, count)
+	jobs := make(chan int)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	nodesRun := 0
+	failures := 0
+	var firstErr error
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				startedAt := time.Now()
+				result, err := r.Run(ctx, bp, nil)
+				latencies[i] = time.Since(startedAt)
+				cancel()
+
+				mutex.Lock()
+				if err != nil {
+					failures++
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					nodesRun = max(nodesRun, len(result.Outputs))
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return latencies, nodesRun, failures, firstErr
+}
+
+// writeHeapProfile writes a profile of the live heap to the path
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("failed to write the heap profile: %w", err)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, ignoring empty entries
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+	"webblueprint/pkg/runner"
+)
+
+// Formats of the report
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// latencyStats summarizes the latencies of the executions of a run, in milliseconds
+type latencyStats struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// measurement is the outcome of running a scenario in an execution mode
+type measurement struct {
+	Scenario           string       `json:"scenario"`
+	Mode               string       `json:"mode"`
+	Nodes              int          `json:"nodes"`
+	NodesRun           int          `json:"nodesRun"` // Most nodes that produced outputs in an execution
+	Executions         int          `json:"executions"`
+	Failures           int          `json:"failures"`
+	DurationMs         float64      `json:"durationMs"`
+	Throughput         float64      `json:"throughput"` // Executions per second
+	Latency            latencyStats `json:"latency"`
+	AllocsPerExecution uint64       `json:"allocsPerExecution"`
+	BytesPerExecution  uint64       `json:"bytesPerExecution"`
+	CPUProfile         string       `json:"cpuProfile,omitempty"`
+	HeapProfile        string       `json:"heapProfile,omitempty"`
+	FirstError         string       `json:"firstError,omitempty"`
+}
+
+// comparison relates the actor mode run of a scenario to its standard mode run
+type comparison struct {
+	Scenario        string  `json:"scenario"`
+	ThroughputRatio float64 `json:"throughputRatio"` // Actor throughput over standard throughput
+	LatencyRatio    float64 `json:"latencyRatio"`    // Actor p50 latency over standard p50 latency
+}
+
+// report is the outcome of a benchmark
+type report struct {
+	GeneratedAt  time.Time     `json:"generatedAt"`
+	GoVersion    string        `json:"goVersion"`
+	CPUs         int           `json:"cpus"`
+	Size         int           `json:"size"`
+	Iterations   int           `json:"iterations"`
+	Concurrency  int           `json:"concurrency"`
+	Measurements []measurement `json:"measurements"`
+	Comparisons  []comparison  `json:"comparisons"`
+}
+
+// summarize computes the latency statistics of a run
+func summarize(latencies []time.Duration) latencyStats {
+	if len(latencies) == 0 {
+		return latencyStats{}
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+
+	return latencyStats{
+		Mean: milliseconds(total / time.Duration(len(sorted))),
+		P50:  milliseconds(percentile(sorted, 50)),
+		P95:  milliseconds(percentile(sorted, 95)),
+		P99:  milliseconds(percentile(sorted, 99)),
+		Max:  milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// compare relates the modes of every scenario that ran in both
+func compare(measurements []measurement) []comparison {
+	byScenario := make(map[string]map[string]measurement)
+	order := make([]string, 0)
+	for _, m := range measurements {
+		if byScenario[m.Scenario] == nil {
+			byScenario[m.Scenario] = make(map[string]measurement)
+			order = append(order, m.Scenario)
+		}
+		byScenario[m.Scenario][m.Mode] = m
+	}
+
+	comparisons := make([]comparison, 0)
+	for _, name := range order {
+		actor, hasActor := byScenario[name][runner.ModeActor]
+		standard, hasStandard := byScenario[name][runner.ModeStandard]
+		if !hasActor || !hasStandard {
+			continue
+		}
+
+		c := comparison{Scenario: name}
+		if standard.Throughput > 0 {
+			c.ThroughputRatio = actor.Throughput / standard.Throughput
+		}
+		if standard.Latency.P50 > 0 {
+			c.LatencyRatio = actor.Latency.P50 / standard.Latency.P50
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// writeReport writes the report in the format
+func writeReport(w io.Writer, r *report, format string) error {
+	if format == outputFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	fmt.Fprintf(w, "Benchmark of %d executions per run, %d at a time, on %d CPUs (%s)\n\n",
+		r.Iterations, r.Concurrency, r.CPUs, r.GoVersion)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "scenario\tmode\tnodes\tran\texec/s\tmean ms\tp50 ms\tp95 ms\tp99 ms\tmax ms\tallocs/exec\tB/exec\tfailures\t")
+	for _, m := range r.Measurements {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%.1f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%d\t%d\t%d\t\n",
+			m.Scenario, m.Mode, m.Nodes, m.NodesRun, m.Throughput,
+			m.Latency.Mean, m.Latency.P50, m.Latency.P95, m.Latency.P99, m.Latency.Max,
+			m.AllocsPerExecution, m.BytesPerExecution, m.Failures)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Comparisons) > 0 {
+		fmt.Fprintln(w, "\nActor mode relative to standard mode:")
+		for _, c := range r.Comparisons {
+			fmt.Fprintf(w, "  %-8s %.2fx throughput, %.2fx p50 latency\n", c.Scenario, c.ThroughputRatio, c.LatencyRatio)
+		}
+	}
+
+	for _, m := range r.Measurements {
+		if m.FirstError != "" {
+			fmt.Fprintf(w, "\n%s in %s mode: %d failed executions, first error: %s\n", m.Scenario, m.Mode, m.Failures, m.FirstError)
+		}
+	}
+	for _, m := range r.Measurements {
+		if m.CPUProfile != "" {
+			fmt.Fprintf(w, "\nProfiles written to %s\n", filepath.Dir(m.CPUProfile))
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"webblueprint/pkg/blueprint"
+)
+
+// scenario generates a synthetic blueprint of a given size
+type scenario struct {
+	name        string
+	description string
+	generate    func(size int) *blueprint.Blueprint
+}
+
+// scenarios are the shapes of blueprints the benchmark runs, in report order
+var scenarios = []scenario{
+	{
+		name:        "fan-out",
+		description: "an entry point triggering size print nodes at once",
+		generate:    fanOutBlueprint,
+	},
+	{
+		name:        "chain",
+		description: "a chain of size print nodes, each triggering the next",
+		generate:    chainBlueprint,
+	},
+	{
+		name:        "loop",
+		description: "a loop node running a print node size times, which needs actor mode",
+		generate:    loopBlueprint,
+	},
+}
+
+// findScenario returns the scenario with the name
+func findScenario(name string) (scenario, bool) {
+	for _, s := range scenarios {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return scenario{}, false
+}
+
+// fanOutBlueprint connects the entry point to size print nodes
+func fanOutBlueprint(size int) *blueprint.Blueprint {
+	bp := newBenchmarkBlueprint("fan-out", size)
+	for i := 0; i < size; i++ {
+		printID := fmt.Sprintf("print-%d", i)
+		bp.AddNode(printNode(printID, i))
+		bp.AddConnection(executionConnection("start", "then", printID))
+	}
+	return bp
+}
+
+// chainBlueprint connects size print nodes one after another
+func chainBlueprint(size int) *blueprint.Blueprint {
+	bp := newBenchmarkBlueprint("chain", size)
+	previous, previousPin := "start", "then"
+	for i := 0; i < size; i++ {
+		printID := fmt.Sprintf("print-%d", i)
+		bp.AddNode(printNode(printID, i))
+		bp.AddConnection(executionConnection(previous, previousPin, printID))
+		previous, previousPin = printID, "then"
+	}
+	return bp
+}
+
+// loopBlueprint runs a print node size times from a loop node
+func loopBlueprint(size int) *blueprint.Blueprint {
+	bp := newBenchmarkBlueprint("loop", size)
+	bp.AddNode(blueprint.BlueprintNode{
+		ID:   "loop",
+		Type: "loop",
+		Properties: []blueprint.NodeProperty{
+			{Name: "input_iterations", Value: size},
+		},
+	})
+	bp.AddNode(printNode("body", 0))
+	bp.AddConnection(executionConnection("start", "then", "loop"))
+	bp.AddConnection(blueprint.Connection{
+		ID:             "loop-body",
+		SourceNodeID:   "loop",
+		SourcePinID:    "loop",
+		TargetNodeID:   "body",
+		TargetPinID:    "exec",
+		ConnectionType: "execution",
+	})
+	return bp
+}
+
+// newBenchmarkBlueprint creates a blueprint with an entry point only
+func newBenchmarkBlueprint(name string, size int) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint(fmt.Sprintf("benchmark-%s-%d", name, size), "Benchmark "+name, "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	return bp
+}
+
+// printNode creates a print node with a constant message
+func printNode(id string, index int) blueprint.BlueprintNode {
+	return blueprint.BlueprintNode{
+		ID:   id,
+		Type: "print",
+		Properties: []blueprint.NodeProperty{
+			{Name: "input_message", Value: fmt.Sprintf("message %d", index)},
+		},
+	}
+}
+
+// executionConnection connects an execution output to the execution input of a print node
+func executionConnection(sourceNodeID, sourcePinID, targetNodeID string) blueprint.Connection {
+	return blueprint.Connection{
+		ID:             sourceNodeID + "-" + targetNodeID,
+		SourceNodeID:   sourceNodeID,
+		SourcePinID:    sourcePinID,
+		TargetNodeID:   targetNodeID,
+		TargetPinID:    "exec",
+		ConnectionType: "execution",
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/db"
+	"webblueprint/pkg/service"
+)
+
+// defaultImportUserID is the user recorded for imports without one, as for API requests
+// without a user
+const defaultImportUserID = "00000000-0000-0000-0000-000000000001"
+
+const exportUsage = `Usage: new_server export -blueprint ID [flags]
+
+Writes a stored blueprint as JSON, in the format read by run -file, validate -file and
+import. The database is configured as for migrate.
+
+Flags:
+`
+
+const importUsage = `Usage: new_server import -file PATH [flags]
+
+Stores a blueprint JSON file in the database. A blueprint whose ID is already stored
+gets a new version; other blueprints are created in the workspace.
+
+Flags:
+`
+
+// newBlueprintService creates the blueprint service of the commands working on stored blueprints
+func newBlueprintService(ctx context.Context) (*service.BlueprintService, *db.ConnectionManager, error) {
+	connManager, repoFactory, err := db.Setup(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blueprintService := service.NewBlueprintService(
+		repoFactory.GetBlueprintRepository(),
+		repoFactory.GetWorkspaceRepository(),
+		repoFactory.GetAssetRepository(),
+		repoFactory.GetExecutionRepository(),
+	)
+	// Imports obey the node policies of their workspace like saves through the API
+	blueprintService.SetNodePolicyService(service.NewNodePolicyService(repoFactory.GetWorkspaceRepository()))
+
+	return blueprintService, connManager, nil
+}
+
+// runExport runs the export subcommand and returns the process exit code
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), exportUsage)
+		flags.PrintDefaults()
+	}
+	bpId := flags.String("blueprint", "", "ID of the blueprint to export (required)")
+	version := flags.Int("version", 0, "Version to export (default: the current version)")
+	output := flags.String("output", "", "Path to write the blueprint to (default: stdout)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *bpId == "" {
+		fmt.Fprintln(os.Stderr, "export: a blueprint ID is required")
+		return 2
+	}
+
+	ctx := context.Background()
+	blueprintService, connManager, err := newBlueprintService(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	defer connManager.Close()
+
+	var bp *blueprint.Blueprint
+	if *version > 0 {
+		bp, err = blueprintService.GetVersion(ctx, *bpId, *version)
+	} else {
+		bp, err = blueprintService.GetBlueprint(ctx, *bpId)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+
+	contents, err := json.MarshalIndent(bp, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		fmt.Println(string(contents))
+		return 0
+	}
+	if err := os.WriteFile(*output, contents, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Exported blueprint %s to %s\n", bp.ID, *output)
+	return 0
+}
+
+// runImport runs the import subcommand and returns the process exit code
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), importUsage)
+		flags.PrintDefaults()
+	}
+	file := flags.String("file", "", "Path to the blueprint JSON file to import (required)")
+	workspaceID := flags.String("workspace", "", "Workspace to create new blueprints in")
+	userID := flags.String("user", defaultImportUserID, "User recorded as the author of the import")
+	comment := flags.String("comment", "Imported from the command line", "Comment of the version created for stored blueprints")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "import: a blueprint file is required")
+		return 2
+	}
+
+	bp, err := readBlueprintFile(*file, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	blueprintService, connManager, err := newBlueprintService(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+	defer connManager.Close()
+
+	if _, err := blueprintService.GetBlueprint(ctx, bp.ID); err == nil {
+		versionNumber, err := blueprintService.SaveVersion(ctx, bp.ID, bp, *comment, *userID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import: %v\n", err)
+			return 1
+		}
+
+		fmt.Printf("Imported blueprint %s as version %d\n", bp.ID, versionNumber)
+		return 0
+	}
+
+	if *workspaceID == "" {
+		fmt.Fprintf(os.Stderr, "import: blueprint %s isn't stored yet, so a workspace is required\n", bp.ID)
+		return 2
+	}
+
+	blueprintID, err := blueprintService.CreateBlueprint(ctx, bp, *workspaceID, *userID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Imported blueprint %s into workspace %s\n", blueprintID, *workspaceID)
+	return 0
+}
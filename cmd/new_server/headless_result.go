@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"webblueprint/internal/common"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/export"
+	"webblueprint/pkg/blueprint"
+)
+
+// Result formats of headless runs
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// headlessResult is the result document of a headless run
+type headlessResult struct {
+	BlueprintID   string                 `json:"blueprintId"`
+	ExecutionID   string                 `json:"executionId"`
+	Success       bool                   `json:"success"`
+	Error         string                 `json:"error,omitempty"`
+	StartTime     time.Time              `json:"startTime"`
+	EndTime       time.Time              `json:"endTime"`
+	DurationMs    int64                  `json:"durationMs"`
+	Nodes         []headlessNodeResult   `json:"nodes"`
+	ErrorAnalysis map[string]interface{} `json:"errorAnalysis,omitempty"`
+}
+
+// headlessNodeResult is the outcome of a node in a headless run
+type headlessNodeResult struct {
+	NodeID     string                 `json:"nodeId"`
+	Status     string                 `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	StartTime  *time.Time             `json:"startTime,omitempty"`
+	EndTime    *time.Time             `json:"endTime,omitempty"`
+	DurationMs int64                  `json:"durationMs"`
+	Outputs    map[string]interface{} `json:"outputs,omitempty"`
+}
+
+// newHeadlessResult builds the result document of an execution from its result and the
+// node statuses tracked by the engine
+func newHeadlessResult(blueprintID string, result common.ExecutionResult, execErr error, status engine.ExecutionStatus) headlessResult {
+	document := headlessResult{
+		BlueprintID:   blueprintID,
+		ExecutionID:   result.ExecutionID,
+		Success:       result.Success && execErr == nil,
+		StartTime:     result.StartTime,
+		EndTime:       result.EndTime,
+		DurationMs:    result.EndTime.Sub(result.StartTime).Milliseconds(),
+		Nodes:         make([]headlessNodeResult, 0),
+		ErrorAnalysis: result.ErrorAnalysis,
+	}
+	if execErr != nil {
+		document.Error = execErr.Error()
+	} else if result.Error != nil {
+		document.Error = result.Error.Error()
+	}
+
+	nodes := make(map[string]*headlessNodeResult)
+	nodeResult := func(nodeID string) *headlessNodeResult {
+		if nodes[nodeID] == nil {
+			nodes[nodeID] = &headlessNodeResult{NodeID: nodeID, Status: "idle"}
+		}
+		return nodes[nodeID]
+	}
+
+	for nodeID, nodeStatus := range status.NodeStatuses {
+		entry := nodeResult(nodeID)
+		entry.Status = nodeStatus.Status
+		if nodeStatus.Error != nil {
+			entry.Error = nodeStatus.Error.Error()
+		}
+		if !nodeStatus.StartTime.IsZero() {
+			startTime := nodeStatus.StartTime
+			entry.StartTime = &startTime
+		}
+		if !nodeStatus.EndTime.IsZero() {
+			endTime := nodeStatus.EndTime
+			entry.EndTime = &endTime
+			if entry.StartTime != nil {
+				entry.DurationMs = endTime.Sub(*entry.StartTime).Milliseconds()
+			}
+		}
+	}
+	for nodeID, outputs := range result.NodeResults {
+		entry := nodeResult(nodeID)
+		entry.Outputs = outputs
+		if entry.Status == "idle" {
+			// Data nodes are evaluated without being tracked
+			entry.Status = "completed"
+		}
+	}
+
+	for _, entry := range nodes {
+		document.Nodes = append(document.Nodes, *entry)
+	}
+	sort.Slice(document.Nodes, func(i, j int) bool {
+		return document.Nodes[i].NodeID < document.Nodes[j].NodeID
+	})
+
+	return document
+}
+
+// failedNodes returns the nodes of the result that failed
+func (r headlessResult) failedNodes() []headlessNodeResult {
+	failed := make([]headlessNodeResult, 0)
+	for _, entry := range r.Nodes {
+		if entry.Status == "error" {
+			failed = append(failed, entry)
+		}
+	}
+	return failed
+}
+
+// writeHeadlessResult writes the result document in the format to the output file, or to
+// stdout if there's none
+func writeHeadlessResult(document headlessResult, format, output string) error {
+	var writer io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	if format == outputFormatJSON {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	}
+
+	state := "succeeded"
+	if !document.Success {
+		state = "failed"
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Blueprint %s %s in %dms (execution %s)\n", document.BlueprintID, state, document.DurationMs, document.ExecutionID)
+	if document.Error != "" {
+		fmt.Fprintf(&text, "Error: %s\n", document.Error)
+	}
+	for _, entry := range document.failedNodes() {
+		fmt.Fprintf(&text, "Node %s failed: %s\n", entry.NodeID, entry.Error)
+	}
+	fmt.Fprintf(&text, "%d nodes, %d failed\n", len(document.Nodes), len(document.failedNodes()))
+
+	_, err := io.WriteString(writer, text.String())
+	return err
+}
+
+// exportHeadlessResult writes the output pin values of the nodes of the result as a table
+// in the format to the export file
+func exportHeadlessResult(bp *blueprint.Blueprint, document headlessResult, format, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	nodes := make([]export.NodeResult, 0, len(document.Nodes))
+	for _, entry := range document.Nodes {
+		result := export.NodeResult{NodeID: entry.NodeID, Status: entry.Status, Outputs: entry.Outputs}
+		if node := bp.FindNode(entry.NodeID); node != nil {
+			result.NodeType = node.Type
+		}
+		nodes = append(nodes, result)
+	}
+
+	writer, err := export.NewWriter(file, format)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteExecution(writer, document.ExecutionID, document.BlueprintID, nodes); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"webblueprint/internal/lint"
+	"webblueprint/internal/registry"
+	"webblueprint/pkg/db"
+	"webblueprint/pkg/repository"
+	"webblueprint/pkg/service"
+)
+
+const lintUsage = `Usage: new_server lint (-blueprint ID | -file PATH) [flags]
+
+Checks a blueprint against the lint rules, and exits with 0 when no finding is an error,
+1 when one is and 2 for invalid arguments. Stored blueprints use the rules of their
+workspace unless -rules is given; files use the defaults.
+
+Flags:
+`
+
+// runLint runs the lint subcommand and returns the process exit code
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), lintUsage)
+		flags.PrintDefaults()
+	}
+	bpId := flags.String("blueprint", "", "ID of the stored blueprint to lint")
+	file := flags.String("file", "", "Path to a blueprint JSON file to lint without a database")
+	rulesFile := flags.String("rules", "", "Path to a JSON rule set, keyed by rule name, replacing the workspace rules")
+	outputFormat := flags.String("output-format", outputFormatText, "Format of the lint report: text or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *bpId == "" && *file == "" {
+		fmt.Fprintln(os.Stderr, "lint: a blueprint ID or file is required")
+		return 2
+	}
+	if *outputFormat != outputFormatText && *outputFormat != outputFormatJSON {
+		fmt.Fprintf(os.Stderr, "lint: unknown output format %q\n", *outputFormat)
+		return 2
+	}
+
+	var ruleSet lint.RuleSet
+	if *rulesFile != "" {
+		contents, err := os.ReadFile(*rulesFile)
+		if err == nil {
+			err = json.Unmarshal(contents, &ruleSet)
+		}
+		if err == nil {
+			err = ruleSet.Validate()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint: invalid rules file %s: %v\n", *rulesFile, err)
+			return 2
+		}
+	}
+
+	ctx := context.Background()
+
+	var repoFactory repository.RepositoryFactory
+	if *file == "" {
+		connManager, factory, err := db.Setup(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint: %v\n", err)
+			return 1
+		}
+		defer connManager.Close()
+		repoFactory = factory
+	}
+
+	bp, err := loadBlueprint(ctx, repoFactory, *bpId, *file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lint: %v\n", err)
+		return 1
+	}
+
+	// Node types are needed to find the side-effecting nodes
+	registry.Make()
+	registerCoreNodes(nil)
+	registerVariableNodes(bp)
+
+	var report lint.Report
+	if repoFactory != nil && ruleSet == nil {
+		lintService := service.NewLintService(repoFactory.GetWorkspaceRepository(), repoFactory.GetBlueprintRepository())
+		lintService.SetNodeFactoryLookup(registry.GetInstance().GetNodeFactory)
+		report, err = lintService.LintStored(ctx, *bpId, bp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint: %v\n", err)
+			return 1
+		}
+	} else {
+		linter := lint.NewLinter()
+		linter.SetNodeFactoryLookup(registry.GetInstance().GetNodeFactory)
+		report = linter.Lint(bp, ruleSet)
+	}
+
+	if *outputFormat == outputFormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "lint: %v\n", err)
+			return 1
+		}
+	} else {
+		printLintReport(report)
+	}
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+// printLintReport prints a lint report for people
+func printLintReport(report lint.Report) {
+	state := "passed"
+	if !report.Passed {
+		state = "failed"
+	}
+	fmt.Printf("Blueprint %s %s linting (%d errors, %d warnings, %d infos)\n", report.BlueprintID, state,
+		report.Counts[lint.SeverityError], report.Counts[lint.SeverityWarning], report.Counts[lint.SeverityInfo])
+
+	for _, finding := range report.Findings {
+		fmt.Printf("%s [%s] %s\n", finding.Severity, finding.Rule, finding.Message)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+	"webblueprint/pkg/db"
+)
+
+const migrateUsage = `Usage: new_server migrate <command> [flags]
+
+Commands:
+  up                  Apply all pending migrations
+  down [-steps N]     Revert the N most recently applied migrations (default 1)
+  status              List the migrations and whether they have been applied
+
+The database is configured through DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME
+and DB_SSLMODE. Migrations are read from SCHEMA_PATH if set, otherwise the ones
+built into the binary are used.
+`
+
+// runMigrate runs the migrate subcommand and returns the process exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	command := args[0]
+	if command != "up" && command != "down" && command != "status" {
+		fmt.Fprintf(os.Stderr, "migrate: unknown command %q\n\n%s", command, migrateUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "Number of migrations to revert")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	connManager, err := db.Connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	defer connManager.Close()
+
+	ctx := context.Background()
+	manager := db.NewMigrationManager(connManager.GetDB(), db.MigrationsFromEnv())
+
+	switch command {
+	case "up":
+		applied, err := manager.Up(ctx)
+		printVersions("Applied", applied)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+
+	case "down":
+		reverted, err := manager.Down(ctx, *steps)
+		printVersions("Reverted", reverted)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+
+	case "status":
+		statuses, err := manager.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT\tREVERSIBLE")
+		for _, status := range statuses {
+			state, appliedAt := "pending", "-"
+			if status.Applied {
+				state, appliedAt = "applied", status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", status.Version, state, appliedAt, status.Reversible)
+		}
+		w.Flush()
+	}
+
+	return 0
+}
+
+// printVersions lists the migrations a command applied or reverted
+func printVersions(action string, versions []string) {
+	if len(versions) == 0 {
+		fmt.Printf("%s no migrations\n", action)
+		return
+	}
+	for _, version := range versions {
+		fmt.Printf("%s %s\n", action, version)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"webblueprint/internal/nodes"
+	"webblueprint/internal/types"
+)
+
+const listNodesUsage = `Usage: new_server list-nodes [flags]
+
+Lists the core node types with their category, version and pins.
+
+Flags:
+`
+
+// nodeTypeDocument describes a node type in the JSON output of list-nodes
+type nodeTypeDocument struct {
+	TypeID      string        `json:"typeId"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	Version     string        `json:"version"`
+	Inputs      []pinDocument `json:"inputs"`
+	Outputs     []pinDocument `json:"outputs"`
+}
+
+// pinDocument describes a pin of a node type
+type pinDocument struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// runListNodes runs the list-nodes subcommand and returns the process exit code
+func runListNodes(args []string) int {
+	flags := flag.NewFlagSet("list-nodes", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), listNodesUsage)
+		flags.PrintDefaults()
+	}
+	category := flags.String("category", "", "Only list the node types of the category")
+	outputFormat := flags.String("output-format", outputFormatText, "Format of the list: text or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *outputFormat != outputFormatText && *outputFormat != outputFormatJSON {
+		fmt.Fprintf(os.Stderr, "list-nodes: unknown output format %q\n", *outputFormat)
+		return 2
+	}
+
+	nodeTypes := make([]nodeTypeDocument, 0, len(nodes.Core))
+	for _, factory := range nodes.Core {
+		nodeInstance := factory()
+		metadata := nodeInstance.GetMetadata()
+		if *category != "" && !strings.EqualFold(metadata.Category, *category) {
+			continue
+		}
+
+		nodeTypes = append(nodeTypes, nodeTypeDocument{
+			TypeID:      metadata.TypeID,
+			Name:        metadata.Name,
+			Description: metadata.Description,
+			Category:    metadata.Category,
+			Version:     metadata.Version,
+			Inputs:      pinDocuments(nodeInstance.GetInputPins()),
+			Outputs:     pinDocuments(nodeInstance.GetOutputPins()),
+		})
+	}
+	sort.Slice(nodeTypes, func(i, j int) bool {
+		if nodeTypes[i].Category != nodeTypes[j].Category {
+			return nodeTypes[i].Category < nodeTypes[j].Category
+		}
+		return nodeTypes[i].TypeID < nodeTypes[j].TypeID
+	})
+
+	if *outputFormat == outputFormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(nodeTypes); err != nil {
+			fmt.Fprintf(os.Stderr, "list-nodes: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tCATEGORY\tVERSION\tDESCRIPTION")
+	for _, nodeType := range nodeTypes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", nodeType.TypeID, nodeType.Category, nodeType.Version, nodeType.Description)
+	}
+	w.Flush()
+
+	return 0
+}
+
+// pinDocuments describes the pins of a node type
+func pinDocuments(pins []types.Pin) []pinDocument {
+	documents := make([]pinDocument, 0, len(pins))
+	for _, pin := range pins {
+		document := pinDocument{ID: pin.ID, Name: pin.Name, Optional: pin.Optional}
+		if pin.Type != nil {
+			document.Type = pin.Type.ID
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
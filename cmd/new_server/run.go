@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"webblueprint/internal/api"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/common"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/event"
+	"webblueprint/internal/export"
+	"webblueprint/internal/logsink"
+	"webblueprint/internal/nodes"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/registry"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/db"
+	"webblueprint/pkg/repository"
+)
+
+const runUsage = `Usage: new_server run (-blueprint ID | -file PATH) [flags]
+
+Runs a blueprint once and exits with 0 when the execution succeeded, 1 when it failed
+and 2 for invalid arguments. Stored blueprints are read from the database, which
+-file doesn't need.
+
+Flags:
+`
+
+// runRun runs the run subcommand and returns the process exit code
+func runRun(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), runUsage)
+		flags.PrintDefaults()
+	}
+	bpId := flags.String("blueprint", "", "ID of the stored blueprint to run")
+	file := flags.String("file", "", "Path to a blueprint JSON file to run without a database")
+	path := flags.String("snapshot", "", "Path to write the stored blueprint to after the run")
+	output := flags.String("output", "", "Path to write the execution result to (default: stdout)")
+	outputFormat := flags.String("output-format", outputFormatText, "Format of the execution result: text or json")
+	exportPath := flags.String("export", "", "Path to export the output pin values of the nodes to as a table")
+	exportFormat := flags.String("export-format", export.FormatCSV, "Format of the exported table: csv or parquet")
+	envPrefix := flags.String("env-prefix", defaultVariableEnvPrefix, "Prefix of the environment variables passed as variables (empty to disable)")
+	variables := variableFlags{}
+	flags.Var(variables, "var", "Variable as key=value, parsed as number, bool or JSON (repeatable, overrides the environment)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	return headless(bpId, path, file, output, *outputFormat, *exportPath, *exportFormat, *envPrefix, variables)
+}
+
+type headlessData struct {
+	engine      *engine.ExecutionEngine
+	connManager *db.ConnectionManager
+	repoFactory repository.RepositoryFactory
+	logger      *api.WebSocketLogger
+}
+
+// setupHeadless wires the engine of a headless run. Without a database, the core nodes are
+// registered directly and the blueprint has to come from a file.
+func setupHeadless(ctx context.Context, useDB bool) *headlessData {
+	var connManager *db.ConnectionManager
+	var repoFactory repository.RepositoryFactory
+	if useDB {
+		var dbErr error
+		connManager, repoFactory, dbErr = db.Setup(ctx) // Capture connManager
+		if dbErr != nil {
+			slog.Error("Failed to setup database", slog.String("error", dbErr.Error()))
+			// Decide if the application should exit or continue without DB functionality
+			// For now, let's return, preventing API setup without DB.
+			return nil
+		}
+	}
+
+	wsManager := api.NewWebSocketManager()
+	logger := api.NewWebSocketLogger(wsManager)
+	// The log of a headless run only covers that run, see headless
+	if fileSink, err := logsink.NewFileSink("./log.out", 0, 0); err == nil {
+		logger.SetSink(fileSink)
+	} else {
+		slog.Warn("Failed to open the log file", slog.String("error", err.Error()))
+	}
+	debugManager := engine.NewDebugManager()
+	errorManager := bperrors.NewErrorManager()
+	recoveryManager := bperrors.NewRecoveryManager(errorManager)
+
+	flowEngine := engine.NewExecutionEngine(logger, debugManager)
+	// Create the concrete EventManager, passing the engine as the controller
+	eventManager := event.NewEventManager(flowEngine)
+	// Get the core interface adapter directly from the concrete manager
+	eventManagerCoreAdapter := eventManager.AsEventManagerInterface()
+	// eventManagerAdapter := event.NewEventManagerAdapter(eventManager) // Remove usage of the separate adapter
+
+	flowEngine.SetExecutionMode(engine.ModeActor)
+
+	contextManager := engineext.NewContextManager(
+		errorManager,
+		recoveryManager,
+		eventManagerCoreAdapter, // Pass the core interface adapter
+		repoFactory,             // Pass the repoFactory
+	)
+	// engineext.InitializeExtensions expects the concrete manager
+	contextExtension := engineext.InitializeExtensions(
+		flowEngine,
+		contextManager,
+		errorManager,
+		recoveryManager,
+		eventManager, // Pass the concrete *event.EventManager
+	)
+	// A headless run ends with its execution, so pending activations aren't persisted
+	contextExtension.TimerService = engineext.NewTimerService(nil)
+
+	flowEngine.SetExtensions(contextExtension)
+
+	if !useDB {
+		registerCoreNodes(flowEngine)
+
+		return &headlessData{
+			engine: flowEngine,
+			logger: logger,
+		}
+	}
+
+	dbConn := connManager.GetDB()
+
+	server := api.NewAPIServerWithDB(
+		flowEngine,
+		wsManager,
+		debugManager,
+		repoFactory,
+		dbConn,
+		contextExtension,
+		logger,
+	)
+
+	server.InitiateCoreNodes()
+
+	return &headlessData{
+		engine:      flowEngine,
+		connManager: connManager,
+		repoFactory: repoFactory,
+		logger:      logger,
+	}
+}
+
+// loadBlueprint reads a blueprint from the file, if any, or from the database otherwise
+func loadBlueprint(ctx context.Context, repoFactory repository.RepositoryFactory, bpId, file string) (*blueprint.Blueprint, error) {
+	if file != "" {
+		return readBlueprintFile(file, bpId)
+	}
+
+	bpModel, err := repoFactory.GetBlueprintRepository().GetByID(ctx, bpId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repoFactory.GetBlueprintRepository().ToPkgBlueprint(bpModel, bpModel.CurrentVersion)
+}
+
+// readBlueprintFile reads a blueprint JSON file. Blueprints without an ID get the fallback
+// ID, or the name of the file without one.
+func readBlueprintFile(file, fallbackID string) (*blueprint.Blueprint, error) {
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var bp blueprint.Blueprint
+	if err := json.Unmarshal(contents, &bp); err != nil {
+		return nil, fmt.Errorf("invalid blueprint file %s: %w", file, err)
+	}
+	if bp.ID == "" {
+		bp.ID = fallbackID
+	}
+	if bp.ID == "" {
+		bp.ID = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	return &bp, nil
+}
+
+// headless runs a blueprint once and returns the exit code of the process: 0 if the
+// execution succeeded, 1 if it failed and 2 for invalid arguments
+func headless(bpId, path, file, output *string, outputFormat, exportPath, exportFormat, envPrefix string, variables variableFlags) int {
+	log.Println("Cleaning log file...")
+	if err := os.Remove("./log.out"); err != nil {
+		log.Println("Failed to remove old log file")
+	}
+
+	log.Println("Cleaning up done")
+
+	if *bpId == "" && *file == "" {
+		slog.Error("A blueprint ID or file is required")
+		return 2
+	}
+
+	if outputFormat != outputFormatText && outputFormat != outputFormatJSON {
+		slog.Error("Unknown headless output format", slog.String("format", outputFormat))
+		return 2
+	}
+
+	if exportPath != "" && exportFormat != export.FormatCSV && exportFormat != export.FormatParquet {
+		slog.Error("Unknown headless export format", slog.String("format", exportFormat))
+		return 2
+	}
+
+	registry.Make()
+
+	setupData := setupHeadless(context.Background(), *file == "")
+	if setupData == nil {
+		return 1
+	}
+
+	bp, err := loadBlueprint(context.Background(), setupData.repoFactory, *bpId, *file)
+	if err != nil {
+		slog.Error("Failed to get Blueprint",
+			slog.String("id", *bpId),
+			slog.String("file", *file),
+			slog.Any("error", err.Error()))
+		return 1
+	}
+
+	executionId := uuid.New().String()
+
+	initialData, err := headlessInitialData(bp, envPrefix, variables)
+	if err != nil {
+		slog.Error("Invalid headless variables",
+			slog.String("id", bp.ID),
+			slog.Any("error", err.Error()))
+		return 2
+	}
+
+	var result common.ExecutionResult
+	var execErr error
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func(bp *blueprint.Blueprint, wg *sync.WaitGroup) {
+		defer wg.Done()
+		result, execErr = setupData.engine.Execute(bp, executionId, initialData)
+		if execErr != nil {
+			slog.Error("Failed to execute blueprint",
+				slog.String("id", bp.ID),
+				slog.Any("error", execErr.Error()),
+			)
+			return
+		}
+	}(bp, &wg)
+
+	wg.Wait()
+
+	status, _ := setupData.engine.GetExecutionStatus(executionId)
+	document := newHeadlessResult(bp.ID, result, execErr, status)
+
+	exitCode := 0
+	if !document.Success {
+		exitCode = 1
+	}
+
+	if err := writeHeadlessResult(document, outputFormat, *output); err != nil {
+		slog.Error("Failed to write execution result",
+			slog.String("id", bp.ID),
+			slog.Any("error", err.Error()))
+		exitCode = 1
+	}
+
+	if exportPath != "" {
+		if err := exportHeadlessResult(bp, document, exportFormat, exportPath); err != nil {
+			slog.Error("Failed to export execution result",
+				slog.String("id", bp.ID),
+				slog.Any("error", err.Error()))
+			exitCode = 1
+		}
+	}
+
+	// File runs are self-contained, so there's no snapshot to take
+	if *path != "" && *file == "" {
+		snapshotBlueprint(bp, *path)
+	}
+
+	registry.GetInstance().Close()
+
+	setupData.logger.Close()
+
+	return exitCode
+}
+
+// snapshotBlueprint writes the blueprint of a headless run to the path
+func snapshotBlueprint(bp *blueprint.Blueprint, path string) {
+	contents, err := json.Marshal(bp)
+	if err != nil {
+		slog.Error("Failed to marshal blueprint",
+			slog.String("id", bp.ID),
+			slog.Any("error", err.Error()))
+		return
+	}
+
+	err = os.WriteFile(path, contents, 0644)
+	if err != nil {
+		slog.Error("Failed to take snapshot of blueprint",
+			slog.String("id", bp.ID),
+			slog.Any("error", err.Error()))
+		return
+	}
+
+	slog.Info("Successfully take snapshot of blueprint",
+		slog.String("id", bp.ID))
+}
+
+// registerCoreNodes registers the core node types with the registry and the engine, if any,
+// for the commands working without the API server
+func registerCoreNodes(flowEngine *engine.ExecutionEngine) {
+	for typeID, factory := range nodes.Core {
+		registry.GetInstance().RegisterNodeType(typeID, factory)
+		if flowEngine != nil {
+			flowEngine.RegisterNodeType(typeID, factory)
+		}
+	}
+}
+
+// registerVariableNodes registers the getter and setter nodes of the variables of a blueprint
+// for the commands that check a blueprint without loading it into an engine. No API server
+// listens for runtime node types here, so they go to the registry directly.
+func registerVariableNodes(bp *blueprint.Blueprint) {
+	for typeID, factory := range data.VariableNodeFactories(bp) {
+		registry.GetInstance().RegisterNodeType(typeID, factory)
+	}
+}
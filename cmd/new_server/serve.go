@@ -0,0 +1,466 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"webblueprint"
+	"webblueprint/internal/api"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/config"
+	"webblueprint/internal/crash"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/event"
+	"webblueprint/internal/health"
+	"webblueprint/internal/i18n"
+	"webblueprint/internal/locks"
+	"webblueprint/internal/logsink"
+	"webblueprint/internal/nodes"
+	"webblueprint/internal/nodes/convert"
+	"webblueprint/internal/proxy"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/requestid"
+	"webblueprint/internal/sandbox"
+	"webblueprint/internal/statestore"
+	"webblueprint/internal/webui"
+	"webblueprint/pkg/db"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+const serveUsage = `Usage: new_server serve [flags]
+
+Starts the HTTP and WebSocket server along with the web editor. The server is configured
+through a YAML file (-config or CONFIG_FILE) and the environment, which takes precedence;
+-port overrides both.
+
+Flags:
+`
+
+// runServe runs the serve subcommand and returns the process exit code
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), serveUsage)
+		flags.PrintDefaults()
+	}
+	configPath := flags.String("config", os.Getenv("CONFIG_FILE"), "Path to the YAML configuration file")
+	port := flags.String("port", "", "Server port, overriding the configuration (default: 8089)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "serve: invalid configuration: %v\n", err)
+		return 2
+	}
+	if *port != "" {
+		cfg.Server.Port = *port
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "serve: invalid configuration: %v\n", err)
+			return 2
+		}
+	}
+
+	// Set log level
+	// Records logged with the context of a request carry its ID
+	h := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel()})
+	slog.SetDefault(slog.New(requestid.LogHandler(h)))
+
+	// Message catalogs of the operator translate the node catalog and error messages too
+	if cfg.Server.LocalesDir != "" {
+		if err := i18n.Default().LoadDir(cfg.Server.LocalesDir); err != nil {
+			fmt.Fprintf(os.Stderr, "serve: invalid message catalogs: %v\n", err)
+			return 2
+		}
+	}
+
+	// Channel to catch SIGINT and SIGTERM signals
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	serverPort := cfg.Server.Port
+
+	ctx := context.Background()
+	// Create router
+	router := mux.NewRouter()
+
+	// The probes answer from the start, while the other routes wait for the setup
+	checker := health.NewChecker(health.DefaultTimeout)
+	probes := mux.NewRouter()
+	api.NewHealthHandler(checker).RegisterRoutes(probes)
+
+	// Behind a reverse proxy, the server is served under the base path and believes the
+	// X-Forwarded-* headers of the trusted proxies only; the configuration is validated
+	trustedProxies, _ := proxy.ParseTrusted(cfg.Server.TrustedProxies)
+
+	// Start HTTP server
+	server := &http.Server{
+		Addr:         ":" + serverPort,
+		Handler:      proxy.Handler(api.RequestID(api.Localize(api.AccessLog(startupGate(checker, probes, router)))), cfg.Server.BasePath, trustedProxies),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+
+	// Start server in a goroutine
+	go func() {
+		slog.Info("Starting server", slog.String("port", serverPort))
+		if err := server.ListenAndServe(); err != nil && !strings.Contains(err.Error(), "Server closed") {
+			slog.Error("Server error", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}()
+
+	registry.Make()
+	registry.GetInstance().StartRuntimeCleanup(registry.DefaultRuntimeCleanupInterval, registry.DefaultRuntimeTTL)
+
+	//registerNodes()
+
+	logger := setupAPI(ctx, router, cfg, checker)
+
+	// Serve the web editor, with the routes of the app falling back to its index.html
+	router.PathPrefix("/").Handler(webui.NewHandler(editorFiles(cfg.Server.StaticDir), "/api/", "/apis/", "/ws/"))
+
+	checker.SetPhase(health.PhaseReady)
+	slog.Info("Server is ready")
+
+	// Wait for interrupt signal
+	<-signals
+	slog.Info("Shutdown signal received")
+
+	// Load balancers stop sending requests once readiness fails; until then they're served
+	checker.SetPhase(health.PhaseDraining)
+	if cfg.Server.DrainDelay > 0 {
+		slog.Info("Draining", slog.Duration("delay", cfg.Server.DrainDelay))
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+
+	// Create a timeout context for shutdown
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
+	defer shutdownCancel()
+
+	registry.GetInstance().Close()
+
+	// Shutdown gracefully
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server shutdown error", slog.String("error", err.Error()))
+	}
+
+	// Persist and forward the crash reports still pending
+	crash.Default().Wait()
+
+	// Flush the logs still pending in the sinks
+	if logger != nil {
+		logger.Close()
+	}
+
+	slog.Info("Server shutdown complete")
+
+	return 0
+}
+
+// setupAPI wires the engine and the API routes, registers the readiness checks of their
+// dependencies, and returns the logger of the engine, or nil if the database can't be reached
+func setupAPI(ctx context.Context, router *mux.Router, cfg *config.Config, checker *health.Checker) *api.WebSocketLogger {
+	connManager, repoFactory, dbErr := db.SetupWithConfig(ctx, cfg.DB()) // Capture connManager
+	if dbErr != nil {
+		slog.Error("Failed to setup database", slog.String("error", dbErr.Error()))
+		// The server stays up for the editor, but isn't ready without its API
+		checker.Register("database", func(ctx context.Context) error {
+			return fmt.Errorf("database setup failed: %w", dbErr)
+		})
+		// Decide if the application should exit or continue without DB functionality
+		// For now, let's return, preventing API setup without DB.
+		return nil
+	}
+	dbConn := connManager.GetDB() // Get the *sql.DB connection
+
+	wsManager := api.NewWebSocketManager()
+	logger := api.NewWebSocketLogger(wsManager)
+	logger.SetSink(logSinks(cfg.Log.Sinks))
+	debugManager := engine.NewDebugManager()
+	debugManager.SetRetentionPolicy(debugRetentionPolicyFromEnv())
+	errorManager := bperrors.NewErrorManager()
+	recoveryManager := bperrors.NewRecoveryManager(errorManager)
+
+	flowEngine := engine.NewExecutionEngine(logger, debugManager)
+	// Create the concrete EventManager, passing the engine as the controller
+	eventManager := event.NewEventManager(flowEngine)
+	// Get the core interface adapter directly from the concrete manager
+	eventManagerCoreAdapter := eventManager.AsEventManagerInterface()
+	// eventManagerAdapter := event.NewEventManagerAdapter(eventManager) // Remove usage of the separate adapter
+
+	flowEngine.SetExecutionMode(engine.ExecutionMode(cfg.Execution.Mode))
+	flowEngine.SetActorTimeout(cfg.Execution.Timeout)
+	flowEngine.SetNodeLimits(engine.NodeLimits{
+		Timeout:        cfg.Execution.NodeTimeout,
+		MaxOutputBytes: cfg.Execution.MaxOutputSize << 20,
+	})
+	flowEngine.SetMailboxPolicy("", mailboxPolicy(cfg.Execution.Mailbox))
+	for nodeType := range cfg.Execution.Mailbox.NodeTypes {
+		flowEngine.SetMailboxPolicy(nodeType, mailboxPolicy(cfg.Execution.Mailbox.ForNodeType(nodeType)))
+	}
+
+	contextManager := engineext.NewContextManager(
+		errorManager,
+		recoveryManager,
+		eventManagerCoreAdapter, // Pass the core interface adapter
+		repoFactory,             // Pass the repoFactory
+	)
+	// engineext.InitializeExtensions expects the concrete manager
+	contextExtension := engineext.InitializeExtensions(
+		flowEngine,
+		contextManager,
+		errorManager,
+		recoveryManager,
+		eventManager, // Pass the concrete *event.EventManager
+	)
+	// Pending debounce and throttle activations survive a restart
+	contextExtension.TimerService = engineext.NewTimerService(repoFactory.GetTimerRepository())
+	// Values of the state nodes outlive executions and restarts
+	statestore.SetDefault(statestore.NewStore(repoFactory.GetStateRepository()))
+	// Lock nodes serialize executions across every server on the database
+	locks.SetDefault(locks.NewPostgresLocker(dbConn))
+	// Exchange rates and geocoding of the convert nodes
+	setupConvertProviders(cfg.Convert)
+	// The run-command node stays disabled without an enabled sandbox
+	setupCommandSandbox(cfg.Commands)
+	// Panics of executions are persisted and optionally forwarded to Sentry
+	setupCrashReporter(cfg.Crash, repoFactory.GetCrashReportRepository())
+
+	flowEngine.SetExtensions(contextExtension)
+
+	server := api.NewAPIServerWithDB(
+		flowEngine,
+		wsManager,
+		debugManager,
+		repoFactory,
+		dbConn,
+		contextExtension,
+		logger,
+	)
+
+	server.SetTrashRetention(trashRetentionFromEnv())
+	server.SetConfig(cfg)
+
+	server.InitiateCoreNodes()
+	server.RestoreTimers(ctx)
+	server.RestoreDeliveries(ctx)
+	server.SetupRoutes(router)
+	go server.ListenRuntimeNodes()
+	go server.PurgeTrash(time.Hour)
+
+	registerReadinessChecks(checker, dbConn, flowEngine, eventManager)
+
+	return logger
+}
+
+// registerReadinessChecks registers the checks of the dependencies the API needs: the
+// database and its schema, the node types of the engine and the event manager
+func registerReadinessChecks(checker *health.Checker, dbConn *sql.DB, flowEngine *engine.ExecutionEngine, eventManager *event.EventManager) {
+	checker.Register("database", func(ctx context.Context) error {
+		return dbConn.PingContext(ctx)
+	})
+
+	migrations := db.NewMigrationManager(dbConn, db.MigrationsFromEnv())
+	checker.Register("migrations", func(ctx context.Context) error {
+		statuses, err := migrations.Status(ctx)
+		if err != nil {
+			return err
+		}
+		var pending []string
+		for _, status := range statuses {
+			if !status.Applied {
+				pending = append(pending, status.Version)
+			}
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migrations aren't applied: %s", len(pending), strings.Join(pending, ", "))
+		}
+		return nil
+	})
+
+	checker.Register("registry", func(ctx context.Context) error {
+		if registry.GetInstance() == nil {
+			return errors.New("node registry isn't initialized")
+		}
+		for typeID := range nodes.Core {
+			if _, exists := flowEngine.GetNodeFactory(typeID); !exists {
+				return fmt.Errorf("node type %s isn't registered", typeID)
+			}
+		}
+		return nil
+	})
+
+	checker.Register("events", func(ctx context.Context) error {
+		return eventManager.Health()
+	})
+}
+
+// startupGate serves the health probes, and the other routes once the server has started.
+// Until then they're refused with 503, as the router is still being set up.
+func startupGate(checker *health.Checker, probes *mux.Router, router http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var match mux.RouteMatch
+		if probes.Match(r, &match) {
+			probes.ServeHTTP(w, r)
+			return
+		}
+		if checker.Phase() == health.PhaseStarting {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":"server is starting"}`)
+			return
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+// editorFiles returns the files of the web editor: the ones embedded in the binary, unless
+// another directory than the default is configured, or else the ones of the directory, from
+// ./dist in the container image
+func editorFiles(staticDir string) fs.FS {
+	defaultDir := config.Default().Server.StaticDir
+	if embedded, ok := webblueprint.EmbeddedUI(); ok && staticDir == defaultDir {
+		slog.Info("Serving the embedded web editor")
+		return embedded
+	}
+
+	if _, err := os.Stat(staticDir); os.IsNotExist(err) && staticDir == defaultDir {
+		staticDir = "./dist"
+	}
+	slog.Info("Serving static files", slog.String("dir", staticDir))
+	return os.DirFS(staticDir)
+}
+
+// setupConvertProviders configures the rate provider and the geocoder of the convert nodes
+func setupConvertProviders(cfg config.ConvertConfig) {
+	if cfg.RatesURL != "" {
+		convert.SetDefaultRates(convert.NewHTTPRates(cfg.RatesURL, cfg.RatesTTL))
+	}
+	if cfg.GeocoderURL != "" {
+		convert.SetDefaultGeocoder(convert.NewNominatimGeocoder(cfg.GeocoderURL, cfg.GeocoderUserAgent))
+	}
+}
+
+// setupCommandSandbox enables the run-command node if commands are enabled. A sandbox that
+// can't be created, e.g. as an allowed binary is missing, is reported and commands stay
+// disabled.
+func setupCommandSandbox(cfg config.CommandsConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	box, err := sandbox.New(sandbox.Policy{
+		Allow:     cfg.Allow,
+		Root:      cfg.Root,
+		PassEnv:   cfg.PassEnv,
+		AllowEnv:  cfg.AllowEnv,
+		Timeout:   cfg.Timeout,
+		CPUTime:   cfg.CPUTime,
+		MaxOutput: cfg.MaxOutput << 10,
+	})
+	if err != nil {
+		slog.Error("Failed to enable commands", slog.String("error", err.Error()))
+		return
+	}
+	sandbox.SetDefault(box)
+	slog.Warn("Commands are enabled", slog.Any("allow", cfg.Allow), slog.String("root", cfg.Root))
+}
+
+// setupCrashReporter persists crash reports in the store and forwards them to the Sentry DSN
+// of the configuration, if any
+func setupCrashReporter(cfg config.CrashConfig, store crash.Store) {
+	var forwarders []crash.Forwarder
+	if cfg.SentryDSN != "" {
+		forwarder, err := crash.NewSentryForwarder(cfg.SentryDSN, cfg.Environment)
+		if err != nil {
+			slog.Error("Failed to forward crash reports to Sentry", slog.String("error", err.Error()))
+		} else {
+			forwarders = append(forwarders, forwarder)
+		}
+	}
+	crash.SetDefault(crash.NewReporter(store, forwarders...))
+}
+
+// mailboxPolicy converts a validated mailbox configuration to the engine's mailbox policy
+func mailboxPolicy(mailbox config.MailboxConfig) engine.MailboxPolicy {
+	return engine.MailboxPolicy{
+		Capacity: mailbox.Capacity,
+		Overflow: engine.OverflowStrategy(mailbox.Overflow),
+	}
+}
+
+// logSinks opens the validated log sinks of the configuration. Sinks that can't be opened,
+// e.g. as their syslog server is down, are reported and left out.
+func logSinks(sinks []config.LogSinkConfig) logsink.Sink {
+	opened := make([]logsink.Sink, 0, len(sinks))
+	for _, sinkConfig := range sinks {
+		var sink logsink.Sink
+		var err error
+		switch sinkConfig.Type {
+		case config.LogSinkFile:
+			sink, err = logsink.NewFileSink(sinkConfig.Path, sinkConfig.MaxSize, sinkConfig.MaxBackups)
+		case config.LogSinkSyslog:
+			sink, err = logsink.NewSyslogSink(sinkConfig.Network, sinkConfig.Address, sinkConfig.Tag)
+		case config.LogSinkLoki:
+			sink = logsink.NewLokiSink(sinkConfig.URL, sinkConfig.Labels, sinkConfig.BatchSize, sinkConfig.FlushInterval)
+		}
+		if err != nil {
+			slog.Error("Failed to open log sink", slog.String("type", sinkConfig.Type), slog.String("error", err.Error()))
+			continue
+		}
+		opened = append(opened, logsink.Leveled(sink, sinkConfig.Level))
+	}
+	return logsink.NewMulti(opened...)
+}
+
+// debugRetentionPolicyFromEnv reads the debug data retention policy from the environment
+func debugRetentionPolicyFromEnv() engine.DebugRetentionPolicy {
+	policy := engine.DefaultDebugRetentionPolicy()
+
+	if value := os.Getenv("DEBUG_MAX_EXECUTIONS"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			policy.MaxExecutions = n
+		}
+	}
+	if value := os.Getenv("DEBUG_MAX_BYTES_PER_EXECUTION"); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			policy.MaxBytesPerExecution = n
+		}
+	}
+	if value := os.Getenv("DEBUG_TTL"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil {
+			policy.TTL = ttl
+		}
+	}
+	if value := os.Getenv("DEBUG_SPILL_TO_DB"); value != "" {
+		policy.SpillToDB = value == "true" || value == "1"
+	}
+
+	return policy
+}
+
+// trashRetentionFromEnv reads how long deleted blueprints stay in the trash from the environment
+func trashRetentionFromEnv() time.Duration {
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		if days, err := strconv.Atoi(value); err == nil && days >= 0 {
+			return time.Duration(days) * 24 * time.Hour
+		}
+	}
+	return service.DefaultTrashRetention
+}
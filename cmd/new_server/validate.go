@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/common"
+	"webblueprint/internal/registry"
+	"webblueprint/pkg/db"
+	"webblueprint/pkg/repository"
+)
+
+const validateUsage = `Usage: new_server validate (-blueprint ID | -file PATH) [flags]
+
+Checks the structure, connections and entry points of a blueprint without running it,
+and exits with 0 when it's valid, 1 when it isn't and 2 for invalid arguments.
+
+Flags:
+`
+
+// validationDocument is the JSON form of a validation result
+type validationDocument struct {
+	BlueprintID string              `json:"blueprintId"`
+	Valid       bool                `json:"valid"`
+	Errors      []string            `json:"errors"`
+	Warnings    []string            `json:"warnings"`
+	NodeIssues  map[string][]string `json:"nodeIssues,omitempty"`
+}
+
+// runValidate runs the validate subcommand and returns the process exit code
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), validateUsage)
+		flags.PrintDefaults()
+	}
+	bpId := flags.String("blueprint", "", "ID of the stored blueprint to validate")
+	file := flags.String("file", "", "Path to a blueprint JSON file to validate without a database")
+	outputFormat := flags.String("output-format", outputFormatText, "Format of the validation result: text or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *bpId == "" && *file == "" {
+		fmt.Fprintln(os.Stderr, "validate: a blueprint ID or file is required")
+		return 2
+	}
+	if *outputFormat != outputFormatText && *outputFormat != outputFormatJSON {
+		fmt.Fprintf(os.Stderr, "validate: unknown output format %q\n", *outputFormat)
+		return 2
+	}
+
+	ctx := context.Background()
+
+	var repoFactory repository.RepositoryFactory
+	if *file == "" {
+		connManager, factory, err := db.Setup(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "validate: %v\n", err)
+			return 1
+		}
+		defer connManager.Close()
+		repoFactory = factory
+	}
+
+	bp, err := loadBlueprint(ctx, repoFactory, *bpId, *file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "validate: %v\n", err)
+		return 1
+	}
+
+	// Node types are needed to check the pin types of data connections
+	registry.Make()
+	registerCoreNodes(nil)
+	registerVariableNodes(bp)
+
+	validator := bperrors.NewBlueprintValidator(bperrors.NewErrorManager())
+	validator.SetNodeFactoryLookup(registry.GetInstance().GetNodeFactory)
+	document := newValidationDocument(bp.ID, validator.ValidateBlueprint(bp))
+
+	if *outputFormat == outputFormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			fmt.Fprintf(os.Stderr, "validate: %v\n", err)
+			return 1
+		}
+	} else {
+		printValidation(document)
+	}
+
+	if !document.Valid {
+		return 1
+	}
+	return 0
+}
+
+// newValidationDocument converts a validation result to its JSON form
+func newValidationDocument(blueprintID string, result common.ValidationResult) validationDocument {
+	document := validationDocument{
+		BlueprintID: blueprintID,
+		Valid:       result.Valid,
+		Errors:      make([]string, 0, len(result.Errors)),
+		Warnings:    make([]string, 0, len(result.Warnings)),
+		NodeIssues:  result.NodeIssues,
+	}
+	for _, err := range result.Errors {
+		document.Errors = append(document.Errors, err.Error())
+	}
+	for _, warning := range result.Warnings {
+		document.Warnings = append(document.Warnings, warning.Error())
+	}
+	return document
+}
+
+// printValidation prints a validation result for people
+func printValidation(document validationDocument) {
+	state := "valid"
+	if !document.Valid {
+		state = "invalid"
+	}
+	fmt.Printf("Blueprint %s is %s\n", document.BlueprintID, state)
+
+	for _, err := range document.Errors {
+		fmt.Printf("Error: %s\n", err)
+	}
+	for _, warning := range document.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+
+	nodeIDs := make([]string, 0, len(document.NodeIssues))
+	for nodeID := range document.NodeIssues {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	for _, nodeID := range nodeIDs {
+		for _, issue := range document.NodeIssues[nodeID] {
+			fmt.Printf("Node %s: %s\n", nodeID, issue)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// defaultVariableEnvPrefix marks the environment variables passed to headless runs
+const defaultVariableEnvPrefix = "BLUEPRINT_VAR_"
+
+// variableFlags collects the repeatable -var key=value flags
+type variableFlags map[string]string
+
+func (f variableFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for key, value := range f {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f variableFlags) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", pair)
+	}
+	f[strings.TrimSpace(key)] = value
+	return nil
+}
+
+// environmentVariables returns the environment variables with the prefix, keyed by their
+// name without it
+func environmentVariables(environ []string, prefix string) map[string]string {
+	variables := make(map[string]string)
+	if prefix == "" {
+		return variables
+	}
+
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			continue
+		}
+		variables[strings.TrimPrefix(key, prefix)] = value
+	}
+	return variables
+}
+
+// headlessInitialData builds the initial data of a headless run from the environment
+// variables with the prefix and the -var flags, which take precedence. Values are
+// converted to the type of the blueprint variable they set, or parsed as numbers,
+// booleans or JSON otherwise.
+func headlessInitialData(bp *blueprint.Blueprint, envPrefix string, flags variableFlags) (map[string]types.Value, error) {
+	raw := environmentVariables(os.Environ(), envPrefix)
+	for key, value := range flags {
+		raw[key] = value
+	}
+
+	declared := make(map[string]string, len(bp.Variables))
+	for _, variable := range bp.Variables {
+		declared[variable.Name] = variable.Type
+	}
+
+	initialData := make(map[string]types.Value, len(raw))
+	for name, text := range raw {
+		parsed := parseVariableValue(text)
+		value := types.NewValue(types.InferPinType(parsed), parsed)
+
+		if pinType, ok := types.GetPinTypeByID(declared[name]); ok {
+			// Declared strings keep the text as is, e.g. "007"
+			if pinType.ID == types.PinTypes.String.ID {
+				value = types.NewValue(pinType, text)
+			} else {
+				coerced, err := types.Coerce(value, pinType)
+				if err != nil {
+					return nil, fmt.Errorf("variable %s: %w", name, err)
+				}
+				value = coerced
+			}
+		}
+
+		initialData[name] = value
+	}
+
+	return initialData, nil
+}
+
+// parseVariableValue parses the text of a variable as a boolean, a number or JSON, and
+// falls back to the text itself. JSON strings, e.g. "\"42\"", force a string.
+func parseVariableValue(text string) interface{} {
+	trimmed := strings.TrimSpace(text)
+
+	switch trimmed {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if number, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+		return number
+	}
+
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, `"`) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(trimmed), &value); err == nil {
+			return value
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"webblueprint/pkg/repository"
+	"webblueprint/pkg/service"
+)
+
+// respondWithAccessError writes the error of an access check: 403 when the user isn't
+// allowed to perform the action, 404 when the workspace it concerns can't be found and
+// 500 otherwise. It returns false if err is nil.
+func respondWithAccessError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if bpErr, denied := service.IsPermissionDeniedError(err); denied {
+		respondWithBlueprintError(w, http.StatusForbidden, bpErr)
+		return true
+	}
+
+	respondWithError(w, workspaceErrorStatus(err), fmt.Sprintf("Error checking access: %v", err))
+	return true
+}
+
+// workspaceErrorStatus returns 404 if err is about a workspace that doesn't exist, 500 otherwise
+func workspaceErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrWorkspaceNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// respondWithPermissionError writes an error denying the user an action as 403. It returns
+// false if err doesn't deny an action.
+func respondWithPermissionError(w http.ResponseWriter, err error) bool {
+	bpErr, denied := service.IsPermissionDeniedError(err)
+	if denied {
+		respondWithBlueprintError(w, http.StatusForbidden, bpErr)
+	}
+	return denied
+}
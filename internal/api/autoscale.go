@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// externalMetricsGroupVersion is the Kubernetes API the external metrics are served as
+const externalMetricsGroupVersion = "external.metrics.k8s.io/v1beta1"
+
+// externalMetrics are the load signals served as Kubernetes external metrics, by name
+var externalMetrics = map[string]func(engine.LoadSignals) float64{
+	"webblueprint_queued_executions":  func(s engine.LoadSignals) float64 { return float64(s.Queued) },
+	"webblueprint_waiting_executions": func(s engine.LoadSignals) float64 { return float64(s.Waiting) },
+	"webblueprint_running_executions": func(s engine.LoadSignals) float64 { return float64(s.Running) },
+	"webblueprint_worker_utilization": func(s engine.LoadSignals) float64 { return s.Utilization },
+	"webblueprint_node_latency_ms":    func(s engine.LoadSignals) float64 { return s.NodeLatencyMs },
+}
+
+// AutoscaleHandler reports the load of the engine to autoscalers
+type AutoscaleHandler struct {
+	executionService *service.ExecutionService
+	externalMetrics  bool
+}
+
+// NewAutoscaleHandler creates a new autoscale handler. With externalMetrics, the load is
+// also served as the Kubernetes external metrics API for HorizontalPodAutoscalers.
+func NewAutoscaleHandler(executionService *service.ExecutionService, externalMetrics bool) *AutoscaleHandler {
+	return &AutoscaleHandler{
+		executionService: executionService,
+		externalMetrics:  externalMetrics,
+	}
+}
+
+// RegisterRoutes registers all autoscale-related routes
+func (h *AutoscaleHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/autoscale", h.handleGetLoad).Methods("GET")
+
+	if h.externalMetrics {
+		router.HandleFunc("/apis/"+externalMetricsGroupVersion, h.handleListExternalMetrics).Methods("GET")
+		router.HandleFunc("/apis/"+externalMetricsGroupVersion+"/namespaces/{namespace}/{metric}", h.handleGetExternalMetric).Methods("GET")
+	}
+}
+
+// handleGetLoad gets the load signals of the engine
+func (h *AutoscaleHandler) handleGetLoad(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"signals":   h.executionService.GetLoadSignals(),
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// handleListExternalMetrics lists the external metrics, as Kubernetes discovers them
+func (h *AutoscaleHandler) handleListExternalMetrics(w http.ResponseWriter, r *http.Request) {
+	resources := make([]map[string]interface{}, 0, len(externalMetrics))
+	for name := range externalMetrics {
+		resources = append(resources, map[string]interface{}{
+			"name":         name,
+			"singularName": "",
+			"namespaced":   true,
+			"kind":         "ExternalMetricValueList",
+			"verbs":        []string{"get"},
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"kind":         "APIResourceList",
+		"apiVersion":   "v1",
+		"groupVersion": externalMetricsGroupVersion,
+		"resources":    resources,
+	})
+}
+
+// handleGetExternalMetric gets an external metric. The load is the same in every namespace
+// and label selectors are ignored, as the server has a single engine.
+func (h *AutoscaleHandler) handleGetExternalMetric(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["metric"]
+	metric, exists := externalMetrics[name]
+	if !exists {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Unknown metric: %s", name))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"kind":       "ExternalMetricValueList",
+		"apiVersion": externalMetricsGroupVersion,
+		"metadata":   map[string]interface{}{},
+		"items": []map[string]interface{}{{
+			"metricName":   name,
+			"metricLabels": map[string]string{},
+			"timestamp":    time.Now().UTC().Format(time.RFC3339),
+			"value":        quantity(metric(h.executionService.GetLoadSignals())),
+		}},
+	})
+}
+
+// quantity formats a value as a Kubernetes quantity, in thousandths unless it's whole
+func quantity(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%d", int64(value))
+	}
+	return fmt.Sprintf("%dm", int64(math.Round(value*1000)))
+}
@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"webblueprint/internal/layout"
 	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/paging"
 	"webblueprint/internal/registry"
 	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/repository"
 	"webblueprint/pkg/service"
 
 	"github.com/gorilla/mux"
@@ -35,6 +40,10 @@ func (h *BlueprintHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/blueprints/{id}", h.handleGetBlueprint).Methods("GET")
 	router.HandleFunc("/api/blueprints/{id}", h.handleUpdateBlueprint).Methods("PUT")
 	router.HandleFunc("/api/blueprints/{id}", h.handleDeleteBlueprint).Methods("DELETE")
+	router.HandleFunc("/api/blueprints/{id}/restore", h.handleRestoreBlueprint).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/duplicate", h.handleDuplicateBlueprint).Methods("POST")
+	router.HandleFunc("/api/workspaces/{id}/trash", h.handleGetTrash).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/layout", h.handleLayoutBlueprint).Methods("POST")
 
 	// Blueprint variable operations/api/blueprints/{id}/variable
 	router.HandleFunc("/api/blueprints/{id}/variable", h.handleAddVariable).Methods("POST")
@@ -49,32 +58,38 @@ func (h *BlueprintHandler) RegisterRoutes(router *mux.Router) {
 
 	// Blueprint execution
 	//router.HandleFunc("/api/blueprints/{id}/execute", h.handleExecuteBlueprint).Methods("POST")
+
+	// Node registry metrics
+	router.HandleFunc("/api/metrics/node-registry", h.handleGetRegistryMetrics).Methods("GET")
 }
 
-// handleGetBlueprints gets all blueprints, optionally filtered by workspace
+// handleGetBlueprints gets a page of the blueprints. ?workspace=, ?tag= (repeated or
+// comma-separated), ?category= and ?is_template= filter them, and ?sort= orders them by
+// name, updated_at or created_at, most recently updated first by default.
 func (h *BlueprintHandler) handleGetBlueprints(w http.ResponseWriter, r *http.Request) {
-	// Get workspace ID from query parameters (optional)
-	workspaceID := r.URL.Query().Get("workspace")
-	limit := 100
-	offset := 0
-
-	var blueprints []*blueprint.Blueprint
-	var err error
-
-	// Get blueprints by workspace if provided
-	if workspaceID != "" {
-		blueprints, err = h.blueprintService.GetByWorkspace(r.Context(), workspaceID)
-	} else {
-		// Without a workspace ID, get all accessible blueprints
-		blueprints, err = h.blueprintService.GetAll(r.Context(), limit, offset)
+	query := r.URL.Query()
+	page, ok := parsePage(w, r, repository.BlueprintSortFields, paging.Sort{Field: "updated_at", Desc: true})
+	if !ok {
+		return
+	}
+	isTemplate, err := paging.Bool(query, "is_template")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
+	blueprints, err := h.blueprintService.ListBlueprints(r.Context(), repository.BlueprintFilter{
+		WorkspaceID: query.Get("workspace"),
+		Tags:        paging.Filters(query, "tag"),
+		Category:    query.Get("category"),
+		IsTemplate:  isTemplate,
+	}, page)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving blueprints: %v", err))
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, blueprints)
+	respondWithJSON(w, http.StatusOK, newListResponse(blueprints))
 }
 
 // handleGetBlueprint gets a specific blueprint by ID
@@ -88,10 +103,9 @@ func (h *BlueprintHandler) handleGetBlueprint(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	for _, variable := range bp.Variables {
-		registry.GetInstance().RegisterNodeTypeRuntime(fmt.Sprintf("variable-get-%s", variable.Name), data.NewVariableGetDefinedNode(variable.Name, variable.Type, variable.Type))
-		registry.GetInstance().RegisterNodeTypeRuntime(fmt.Sprintf("variable-set-%s", variable.Name), data.NewVariableSetDefinedNode(variable.Name, variable.Type, variable.Value))
-	}
+	// The editor lists the getter and setter nodes of the variables before the blueprint runs.
+	// They're refreshed whenever the blueprint is opened and collected once it no longer is.
+	registry.GetInstance().RegisterRuntimeNodeTypes(registry.BlueprintNamespace(id), data.VariableNodeFactories(bp))
 
 	respondWithJSON(w, http.StatusOK, bp)
 }
@@ -100,8 +114,7 @@ func (h *BlueprintHandler) handleGetBlueprint(w http.ResponseWriter, r *http.Req
 func (h *BlueprintHandler) handleCreateBlueprint(w http.ResponseWriter, r *http.Request) {
 	// Parse the blueprint from request body
 	var bp blueprint.Blueprint
-	if err := json.NewDecoder(r.Body).Decode(&bp); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid blueprint format")
+	if !decodeRequest(w, r, &bp) {
 		return
 	}
 
@@ -122,6 +135,9 @@ func (h *BlueprintHandler) handleCreateBlueprint(w http.ResponseWriter, r *http.
 
 	// Create the blueprint
 	blueprintID, err := h.blueprintService.CreateBlueprint(r.Context(), &bp, workspaceID, userID)
+	if respondWithNodePolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error creating blueprint: %v", err))
 		return
@@ -148,8 +164,7 @@ func (h *BlueprintHandler) handleUpdateBlueprint(w http.ResponseWriter, r *http.
 
 	// Parse the blueprint from request body
 	var bp blueprint.Blueprint
-	if err := json.NewDecoder(r.Body).Decode(&bp); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid blueprint format")
+	if !decodeRequest(w, r, &bp) {
 		return
 	}
 
@@ -187,6 +202,9 @@ func (h *BlueprintHandler) handleUpdateBlueprint(w http.ResponseWriter, r *http.
 
 	// Create a new version with the updated blueprint
 	versionNumber, err := h.blueprintService.SaveVersion(r.Context(), id, &bp, "Updated via API", userID)
+	if respondWithNodePolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error updating blueprint: %v", err))
 		return
@@ -207,6 +225,12 @@ func (h *BlueprintHandler) handleUpdateBlueprint(w http.ResponseWriter, r *http.
 	respondWithJSON(w, http.StatusOK, updatedBP)
 }
 
+// handleGetRegistryMetrics gets the size of the node registry, including the node types
+// registered at runtime and how many of them the cleanup removed
+func (h *BlueprintHandler) handleGetRegistryMetrics(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, registry.GetInstance().Stats())
+}
+
 // handleDeleteBlueprint deletes a blueprint
 func (h *BlueprintHandler) handleDeleteBlueprint(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -219,8 +243,109 @@ func (h *BlueprintHandler) handleDeleteBlueprint(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// The getter and setter nodes registered for the editor go with the blueprint
+	registry.GetInstance().ReleaseRuntimeNodeTypes(registry.BlueprintNamespace(id))
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Blueprint moved to the trash",
+	})
+}
+
+// handleRestoreBlueprint restores a blueprint from the trash
+func (h *BlueprintHandler) handleRestoreBlueprint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	err := h.blueprintService.RestoreBlueprint(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error restoring blueprint: %v", err))
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "Blueprint deleted successfully",
+		"message": "Blueprint restored successfully",
+	})
+}
+
+// duplicateBlueprintRequest is the optional body of a blueprint duplication
+type duplicateBlueprintRequest struct {
+	WorkspaceID string `json:"workspaceId"`
+	Name        string `json:"name"`
+	NewNodeIDs  bool   `json:"newNodeIds"`
+}
+
+// handleDuplicateBlueprint copies the current version of a blueprint into a new blueprint,
+// in the same workspace unless the body names another one, and returns the ID of the copy
+func (h *BlueprintHandler) handleDuplicateBlueprint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var request duplicateBlueprintRequest
+	if !decodeOptionalRequest(w, r, &request) {
+		return
+	}
+
+	userID := getUserIDFromRequest(r)
+	copyID, err := h.blueprintService.DuplicateBlueprint(r.Context(), id, service.DuplicateOptions{
+		WorkspaceID: request.WorkspaceID,
+		Name:        strings.TrimSpace(request.Name),
+		NewNodeIDs:  request.NewNodeIDs,
+	}, userID)
+	if errors.Is(err, service.ErrDuplicateSourceNotFound) || errors.Is(err, service.ErrDuplicateWorkspaceNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if respondWithNodePolicyError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error duplicating blueprint: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]string{"id": copyID})
+}
+
+// handleGetTrash gets the blueprints of a workspace that are in the trash
+func (h *BlueprintHandler) handleGetTrash(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["id"]
+
+	trash, err := h.blueprintService.GetTrash(r.Context(), workspaceID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving trash: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, trash)
+}
+
+// handleLayoutBlueprint computes a layered layout of a blueprint and returns the new node
+// positions, without saving them. The layout options in the body are optional.
+func (h *BlueprintHandler) handleLayoutBlueprint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var options layout.Options
+	if !decodeOptionalRequest(w, r, &options) {
+		return
+	}
+
+	bp, err := h.blueprintService.GetBlueprint(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Blueprint not found: %v", err))
+		return
+	}
+
+	positions, err := layout.Layered(bp, options)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid layout options: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"blueprintId": bp.ID,
+		"positions":   positions,
 	})
 }
 
@@ -269,6 +394,9 @@ func (h *BlueprintHandler) handleCreateVersion(w http.ResponseWriter, r *http.Re
 
 	// Create a new version
 	versionNumber, err := h.blueprintService.SaveVersion(r.Context(), id, bp, request.Comment, userID)
+	if respondWithNodePolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error creating version: %v", err))
 		return
@@ -349,8 +477,7 @@ func (h *BlueprintHandler) handleAddVariable(w http.ResponseWriter, r *http.Requ
 		Value   interface{} `json:"value"`
 		Version string      `json:"version"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload, "+err.Error())
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"webblueprint/internal/bptest"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// BlueprintTestHandler handles blueprint test API requests
+type BlueprintTestHandler struct {
+	testService *service.BlueprintTestService
+}
+
+// NewBlueprintTestHandler creates a new blueprint test handler
+func NewBlueprintTestHandler(testService *service.BlueprintTestService) *BlueprintTestHandler {
+	return &BlueprintTestHandler{
+		testService: testService,
+	}
+}
+
+// RegisterRoutes registers all blueprint test routes
+func (h *BlueprintTestHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/blueprints/{id}/tests", h.handleGetTests).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/tests", h.handleCreateTest).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/tests/run", h.handleRunTests).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/tests/runs", h.handleGetTestRuns).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/tests/coverage", h.handleGetCoverage).Methods("GET")
+	router.HandleFunc("/api/test-runs/{id}", h.handleGetTestRun).Methods("GET")
+}
+
+// handleGetTests gets the test definitions of a blueprint
+func (h *BlueprintTestHandler) handleGetTests(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	tests, err := h.testService.GetTests(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving tests: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tests)
+}
+
+// handleCreateTest stores a new test definition for a blueprint
+func (h *BlueprintTestHandler) handleCreateTest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var def bptest.Definition
+	if !decodeRequest(w, r, &def) {
+		return
+	}
+
+	// Get the user ID
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	created, err := h.testService.CreateTest(r.Context(), id, &def, userID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error creating test: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, created)
+}
+
+// handleRunTests runs the tests of a blueprint. The stored run with its results and
+// coverage is returned as JSON, or as a JUnit XML report with ?format=junit for CI systems.
+func (h *BlueprintTestHandler) handleRunTests(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var request struct {
+		TestIDs []string `json:"testIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		// If body can't be parsed, run every test
+		request.TestIDs = nil
+	}
+
+	// Get the user ID
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	run, err := h.testService.RunTests(r.Context(), id, request.TestIDs, userID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error running tests: %v", err))
+		return
+	}
+
+	if strings.EqualFold(r.URL.Query().Get("format"), "junit") {
+		var report bytes.Buffer
+		if err := bptest.WriteJUnit(&report, run.Suites); err != nil {
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error writing report: %v", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(report.Bytes())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, run)
+}
+
+// handleGetTestRuns gets the most recent test runs of a blueprint
+func (h *BlueprintTestHandler) handleGetTestRuns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.testService.GetTestRuns(r.Context(), id, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving test runs: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, runs)
+}
+
+// handleGetCoverage gets the coverage of the most recent test run of a blueprint.
+// Add ?uncovered=true to only list the nodes, connections and branches that weren't exercised.
+func (h *BlueprintTestHandler) handleGetCoverage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	coverage, err := h.testService.GetLatestCoverage(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving coverage: %v", err))
+		return
+	}
+
+	if r.URL.Query().Get("uncovered") == "true" {
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"blueprintId": coverage.BlueprintID,
+			"nodes":       coverage.Nodes.Uncovered(),
+			"connections": coverage.Connections.Uncovered(),
+			"branches":    coverage.Branches.Uncovered(),
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, coverage)
+}
+
+// handleGetTestRun gets a stored test run
+func (h *BlueprintTestHandler) handleGetTestRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	run, err := h.testService.GetTestRun(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Test run not found: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/circuit"
+
+	"github.com/gorilla/mux"
+)
+
+// CircuitHandler exposes the circuit breaker state of the external-call nodes
+type CircuitHandler struct {
+	breaker *circuit.Breaker
+}
+
+// NewCircuitHandler creates a new circuit handler
+func NewCircuitHandler(breaker *circuit.Breaker) *CircuitHandler {
+	return &CircuitHandler{
+		breaker: breaker,
+	}
+}
+
+// RegisterRoutes registers all circuit-related routes
+func (h *CircuitHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/diagnostics/circuits", h.handleGetCircuits).Methods("GET")
+}
+
+// handleGetCircuits gets the configuration of the breaker and the circuits with failures
+func (h *CircuitHandler) handleGetCircuits(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"threshold": h.breaker.Threshold(),
+		"cooldown":  h.breaker.Cooldown().String(),
+		"circuits":  h.breaker.Statuses(),
+	})
+}
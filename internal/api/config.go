@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/config"
+
+	"github.com/gorilla/mux"
+)
+
+// ConfigHandler reports the active configuration of the server
+type ConfigHandler struct {
+	config *config.Config
+}
+
+// NewConfigHandler creates a new config handler
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{
+		config: cfg,
+	}
+}
+
+// RegisterRoutes registers all config-related routes
+func (h *ConfigHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/config", h.handleGetConfig).Methods("GET")
+}
+
+// handleGetConfig gets the active configuration with its secrets masked
+func (h *ConfigHandler) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.config.Redacted())
+}
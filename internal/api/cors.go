@@ -0,0 +1,190 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"webblueprint/internal/config"
+
+	"github.com/gorilla/mux"
+)
+
+// corsMethods are the methods cross-origin requests may use
+const corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// corsExposedHeaders are the response headers cross-origin scripts may read
+const corsExposedHeaders = "Retry-After, Content-Disposition, X-Request-ID"
+
+// normalizeOrigin returns an HTTP origin with its scheme and host in lower case. It fails
+// for anything but a scheme and a host, such as the opaque origin "null" or URLs with a path.
+func normalizeOrigin(origin string) (string, bool) {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" || parsed.User != nil || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", false
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", false
+	}
+	return scheme + "://" + strings.ToLower(parsed.Host), true
+}
+
+// originAllowed checks if an origin, such as https://app.example.com, matches one of the
+// allowed origin patterns. Opaque origins are never allowed, not even by "*".
+func originAllowed(origin string, allowed []string) bool {
+	normalized, ok := normalizeOrigin(origin)
+	if !ok {
+		return false
+	}
+
+	for _, pattern := range allowed {
+		if pattern == "*" {
+			return true
+		}
+		// https://*.example.com covers the subdomains of example.com, not example.com itself
+		scheme, domain, wildcard := strings.Cut(pattern, "://*.")
+		if !wildcard {
+			if exact, ok := normalizeOrigin(pattern); ok && exact == normalized {
+				return true
+			}
+			continue
+		}
+		base, ok := normalizeOrigin(scheme + "://" + domain)
+		if !ok {
+			continue
+		}
+		baseScheme, baseHost, _ := strings.Cut(base, "://")
+		originScheme, host, _ := strings.Cut(normalized, "://")
+		if subdomain, matched := strings.CutSuffix(host, "."+baseHost); originScheme == baseScheme && matched && subdomain != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreflight checks if a request is the CORS preflight of an allowed origin
+func isPreflight(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" &&
+		origin != "" && !sameOrigin(origin, r) && originAllowed(origin, allowed)
+}
+
+// sameOrigin checks if an origin is the host the request was sent to
+func sameOrigin(origin string, r *http.Request) bool {
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host != "" && strings.EqualFold(parsed.Host, r.Host)
+}
+
+// corsMiddleware answers the preflight requests of the allowed origins and lets their
+// scripts read the responses. Requests of other origins get no CORS headers, so browsers
+// keep their responses from them.
+func corsMiddleware(cfg config.CORSConfig) mux.MiddlewareFunc {
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || sameOrigin(origin, r) || !originAllowed(origin, cfg.AllowedOrigins) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if isPreflight(r, cfg.AllowedOrigins) {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+				if allowedHeaders != "" {
+					w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+				}
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setupCrossOrigin installs the cross-origin policy of the configuration. Middlewares only
+// run on matched routes, so the preflight requests of allowed origins get a route of their
+// own; other OPTIONS requests answer 404 or 405.
+func setupCrossOrigin(r *mux.Router, cfg *config.Config) {
+	r.Use(corsMiddleware(cfg.CORS))
+	if cfg.CSRF.Enabled {
+		r.Use(csrfMiddleware(cfg.CSRF, cfg.CORS.AllowedOrigins))
+	}
+	r.PathPrefix("/").MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+		return isPreflight(r, cfg.CORS.AllowedOrigins)
+	}).HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The CORS middleware answers the preflight
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// csrfMiddleware rejects browser requests that change state, and WebSocket upgrades, unless
+// they come from the server's own origin or an origin allowed under cors. Browsers send
+// the origin of every such request, and pages can't forge it. Clients that aren't browsers,
+// such as the CLI, send no origin and aren't affected.
+func csrfMiddleware(cfg config.CSRFConfig, allowedOrigins []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !changesState(r) || csrfExempt(r.URL.Path, cfg.ExemptPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			origin := requestOrigin(r)
+			if origin == "" || sameOrigin(origin, r) || originAllowed(origin, allowedOrigins) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			respondWithError(w, http.StatusForbidden, "Cross-site request rejected: origin "+origin+" isn't allowed")
+		})
+	}
+}
+
+// changesState checks if a request may change state: any method but the safe ones, and
+// WebSocket upgrades, whose messages run blueprints
+func changesState(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+	default:
+		return true
+	}
+}
+
+// requestOrigin returns the origin of a request, taken from the Referer if the browser
+// didn't send an Origin header. Opaque origins ("null") are kept, so they're rejected.
+func requestOrigin(r *http.Request) string {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return origin
+	}
+	referer, err := url.Parse(r.Header.Get("Referer"))
+	if err != nil || referer.Host == "" {
+		return ""
+	}
+	return referer.Scheme + "://" + referer.Host
+}
+
+// csrfExempt checks if a path matches one of the exempt path patterns
+func csrfExempt(requestPath string, exemptPaths []string) bool {
+	for _, pattern := range exemptPaths {
+		if matched, _ := path.Match(pattern, requestPath); matched {
+			return true
+		}
+	}
+	return false
+}
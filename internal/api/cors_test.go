@@ -0,0 +1,142 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webblueprint/internal/config"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"https://app.test", "https://*.partner.test", "http://*.local.test:8080"}
+
+	for origin, expected := range map[string]bool{
+		"https://app.test":                 true,
+		"HTTPS://App.Test":                 true,
+		"http://app.test":                  false,
+		"https://app.test:8443":            false,
+		"https://app.test/path":            false,
+		"https://eu.partner.test":          true,
+		"HTTPS://EU.Partner.Test":          true,
+		"https://a.eu.partner.test":        true,
+		"https://partner.test":             false,
+		"https://evilpartner.test":         false,
+		"https://eu.partner.test.evil":     false,
+		"http://eu.partner.test":           false,
+		"https://user@eu.partner.test":     false,
+		"https://eu.partner.test@evil.com": false,
+		"http://dev.local.test:8080":       true,
+		"http://dev.local.test":            false,
+		"null":                             false,
+		"":                                 false,
+	} {
+		if got := originAllowed(origin, allowed); got != expected {
+			t.Errorf("%q: expected allowed to be %v, got %v", origin, expected, got)
+		}
+	}
+
+	if originAllowed("null", []string{"*"}) {
+		t.Error("expected the opaque origin not to be allowed by *")
+	}
+	if !originAllowed("https://anywhere.test", []string{"*"}) {
+		t.Error("expected * to allow every origin")
+	}
+}
+
+// newCrossOriginRouter returns a router with the cross-origin policy of the defaults, the
+// allowed origins and the exempt paths, and a GET and a POST route
+func newCrossOriginRouter(allowedOrigins, exemptPaths []string) *mux.Router {
+	cfg := config.Default()
+	cfg.CORS.AllowedOrigins = allowedOrigins
+	cfg.CSRF.ExemptPaths = exemptPaths
+
+	router := mux.NewRouter()
+	setupCrossOrigin(router, cfg)
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/api/items", ok).Methods("GET", "POST")
+	router.HandleFunc("/api/hooks/{id}", ok).Methods("POST")
+	return router
+}
+
+// serveCrossOrigin sends a request with the headers to the router
+func serveCrossOrigin(router *mux.Router, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestPreflightsOnlyAnswerAllowedOrigins(t *testing.T) {
+	router := newCrossOriginRouter([]string{"https://*.partner.test"}, nil)
+
+	recorder := serveCrossOrigin(router, http.MethodOptions, "/api/items", map[string]string{
+		"Origin":                        "https://eu.partner.test",
+		"Access-Control-Request-Method": "POST",
+	})
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected the preflight of an allowed origin to be answered, got %s", statusOf(recorder))
+	}
+	if origin := recorder.Header().Get("Access-Control-Allow-Origin"); origin != "https://eu.partner.test" {
+		t.Errorf("expected the origin to be allowed, got %q", origin)
+	}
+	if methods := recorder.Header().Get("Access-Control-Allow-Methods"); methods != corsMethods {
+		t.Errorf("expected the allowed methods, got %q", methods)
+	}
+
+	for name, test := range map[string]struct {
+		path     string
+		headers  map[string]string
+		expected int
+	}{
+		"origin not allowed": {"/api/items", map[string]string{"Origin": "https://evil.test", "Access-Control-Request-Method": "POST"}, http.StatusMethodNotAllowed},
+		"opaque origin":      {"/api/items", map[string]string{"Origin": "null", "Access-Control-Request-Method": "POST"}, http.StatusMethodNotAllowed},
+		"not a preflight":    {"/api/items", map[string]string{"Origin": "https://eu.partner.test"}, http.StatusMethodNotAllowed},
+		"without an origin":  {"/api/items", map[string]string{"Access-Control-Request-Method": "POST"}, http.StatusMethodNotAllowed},
+		"unknown path":       {"/api/unknown", map[string]string{"Origin": "https://evil.test", "Access-Control-Request-Method": "POST"}, http.StatusNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := serveCrossOrigin(router, http.MethodOptions, test.path, test.headers)
+			if recorder.Code != test.expected {
+				t.Errorf("expected %d, got %s", test.expected, statusOf(recorder))
+			}
+			if origin := recorder.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+				t.Errorf("expected no CORS headers, got the allowed origin %q", origin)
+			}
+		})
+	}
+}
+
+func TestCrossSiteRequestsAreRejected(t *testing.T) {
+	router := newCrossOriginRouter([]string{"https://app.test", "https://*.partner.test"}, []string{"/api/hooks/*"})
+
+	for name, test := range map[string]struct {
+		method   string
+		path     string
+		headers  map[string]string
+		expected int
+	}{
+		"allowed origin":            {"POST", "/api/items", map[string]string{"Origin": "https://app.test"}, http.StatusOK},
+		"allowed wildcard origin":   {"POST", "/api/items", map[string]string{"Origin": "https://eu.partner.test"}, http.StatusOK},
+		"same origin":               {"POST", "/api/items", map[string]string{"Origin": "http://example.com"}, http.StatusOK},
+		"no origin":                 {"POST", "/api/items", nil, http.StatusOK},
+		"other origin":              {"POST", "/api/items", map[string]string{"Origin": "https://evil.test"}, http.StatusForbidden},
+		"opaque origin":             {"POST", "/api/items", map[string]string{"Origin": "null"}, http.StatusForbidden},
+		"allowed referer":           {"POST", "/api/items", map[string]string{"Referer": "https://eu.partner.test/editor?id=1"}, http.StatusOK},
+		"other referer":             {"POST", "/api/items", map[string]string{"Referer": "https://evil.test/page"}, http.StatusForbidden},
+		"origin before the referer": {"POST", "/api/items", map[string]string{"Origin": "https://evil.test", "Referer": "https://app.test/"}, http.StatusForbidden},
+		"safe method":               {"GET", "/api/items", map[string]string{"Origin": "https://evil.test"}, http.StatusOK},
+		"websocket upgrade":         {"GET", "/api/items", map[string]string{"Origin": "https://evil.test", "Upgrade": "websocket"}, http.StatusForbidden},
+		"exempt path":               {"POST", "/api/hooks/github", map[string]string{"Origin": "https://evil.test"}, http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if recorder := serveCrossOrigin(router, test.method, test.path, test.headers); recorder.Code != test.expected {
+				t.Errorf("expected %d, got %s", test.expected, statusOf(recorder))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"webblueprint/pkg/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// Number of crash reports listed by default, and at most
+const (
+	defaultCrashReportLimit = 50
+	maxCrashReportLimit     = 500
+)
+
+// CrashReportHandler handles crash report API requests
+type CrashReportHandler struct {
+	crashReportRepo repository.CrashReportRepository
+}
+
+// NewCrashReportHandler creates a new crash report handler
+func NewCrashReportHandler(crashReportRepo repository.CrashReportRepository) *CrashReportHandler {
+	return &CrashReportHandler{
+		crashReportRepo: crashReportRepo,
+	}
+}
+
+// RegisterRoutes registers all crash report routes
+func (h *CrashReportHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/crash-reports", h.handleListCrashReports).Methods("GET")
+	router.HandleFunc("/api/crash-reports/{id}", h.handleGetCrashReport).Methods("GET")
+}
+
+// handleListCrashReports lists the latest crash reports, newest first. Add ?executionId= or
+// ?blueprintId= to only list the reports of an execution or a blueprint.
+func (h *CrashReportHandler) handleListCrashReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := defaultCrashReportLimit
+	if limitParam := query.Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(parsed, maxCrashReportLimit)
+	}
+
+	reports, err := h.crashReportRepo.List(r.Context(), query.Get("executionId"), query.Get("blueprintId"), limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving crash reports: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reports)
+}
+
+// handleGetCrashReport gets a crash report with its stack and the events before the crash
+func (h *CrashReportHandler) handleGetCrashReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	report, err := h.crashReportRepo.GetByID(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving crash report: %v", err))
+		return
+	}
+	if report == nil {
+		respondWithError(w, http.StatusNotFound, "Crash report not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
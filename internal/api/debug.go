@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/engine"
+
+	"github.com/gorilla/mux"
+)
+
+// DebugHandler handles debug data retention requests
+type DebugHandler struct {
+	debugManager *engine.DebugManager
+}
+
+// NewDebugHandler creates a new debug handler
+func NewDebugHandler(debugManager *engine.DebugManager) *DebugHandler {
+	return &DebugHandler{
+		debugManager: debugManager,
+	}
+}
+
+// RegisterRoutes registers all debug-related routes
+func (h *DebugHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/debug/stats", h.handleGetStats).Methods("GET")
+	router.HandleFunc("/api/debug/executions", h.handlePurgeAll).Methods("DELETE")
+	router.HandleFunc("/api/debug/executions/{id}", h.handlePurgeExecution).Methods("DELETE")
+}
+
+// handleGetStats gets the memory use and retention policy of the debug manager
+func (h *DebugHandler) handleGetStats(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.debugManager.Stats())
+}
+
+// handlePurgeAll removes the debug data of all finished executions
+func (h *DebugHandler) handlePurgeAll(w http.ResponseWriter, r *http.Request) {
+	purged := h.debugManager.PurgeAll()
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"purged": purged,
+	})
+}
+
+// handlePurgeExecution removes the debug data of an execution
+func (h *DebugHandler) handlePurgeExecution(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if !h.debugManager.Purge(id) {
+		respondWithError(w, http.StatusNotFound, "No debug data retained for execution")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// DeprecationHandler handles the deprecations of blueprints and their versions
+type DeprecationHandler struct {
+	deprecationService *service.DeprecationService
+}
+
+// NewDeprecationHandler creates a new deprecation handler
+func NewDeprecationHandler(deprecationService *service.DeprecationService) *DeprecationHandler {
+	return &DeprecationHandler{
+		deprecationService: deprecationService,
+	}
+}
+
+// RegisterRoutes registers all deprecation routes
+func (h *DeprecationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/blueprints/{id}/deprecations", h.handleGetDeprecations).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/deprecations", h.handleDeprecate).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/deprecations/{version}", h.handleUndeprecate).Methods("DELETE")
+}
+
+// deprecationRequest is the body of a request deprecating a blueprint or version
+type deprecationRequest service.DeprecationOptions
+
+// Validate checks that the deprecation has a message and a valid version number
+func (d *deprecationRequest) Validate() error {
+	if strings.TrimSpace(d.Message) == "" {
+		return fieldError("message", "Message is required")
+	}
+	if d.VersionNumber < 0 {
+		return fieldError("versionNumber", "Version must be a positive version number, or 0 for every version")
+	}
+	return nil
+}
+
+// handleGetDeprecations gets the deprecations of a blueprint and of its versions
+func (h *DeprecationHandler) handleGetDeprecations(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	deprecations, err := h.deprecationService.GetDeprecations(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving deprecations: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, deprecations)
+}
+
+// handleDeprecate deprecates a blueprint, or one of its versions, replacing the deprecation
+// it had
+func (h *DeprecationHandler) handleDeprecate(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var request deprecationRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	deprecation, err := h.deprecationService.Deprecate(r.Context(), id, userID, service.DeprecationOptions(request))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error deprecating blueprint: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, deprecation)
+}
+
+// handleUndeprecate withdraws the deprecation of a version, or of every version for 0
+func (h *DeprecationHandler) handleUndeprecate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	versionNumber, err := strconv.Atoi(vars["version"])
+	if err != nil || versionNumber < 0 {
+		respondWithError(w, http.StatusBadRequest, "Version must be a positive version number, or 0 for every version")
+		return
+	}
+
+	err = h.deprecationService.Undeprecate(r.Context(), id, versionNumber)
+	if errors.Is(err, service.ErrDeprecationNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error withdrawing deprecation: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Deprecation withdrawn successfully",
+	})
+}
+
+// respondWithSunsetError writes an execution blocked by the sunset of its blueprint as 410,
+// with a Sunset header. It returns false if err isn't such an error.
+func respondWithSunsetError(w http.ResponseWriter, err error) bool {
+	bpErr, ok := service.IsBlueprintSunsetError(err)
+	if !ok {
+		return false
+	}
+
+	if sunsetAt, ok := bpErr.Details["sunsetAt"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, sunsetAt); err == nil {
+			w.Header().Set("Sunset", parsed.UTC().Format(http.TimeFormat))
+		}
+	}
+	respondWithBlueprintError(w, http.StatusGone, bpErr)
+	return true
+}
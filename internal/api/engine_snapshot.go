@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"webblueprint/internal/engine"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// EngineSnapshotHandler handles the export and restore of the engine's runtime state, so a
+// new server version takes over the work of the old one during a rolling upgrade
+type EngineSnapshotHandler struct {
+	executionService *service.ExecutionService
+	accessService    *service.AccessService
+}
+
+// NewEngineSnapshotHandler creates a new engine snapshot handler
+func NewEngineSnapshotHandler(executionService *service.ExecutionService, accessService *service.AccessService) *EngineSnapshotHandler {
+	return &EngineSnapshotHandler{
+		executionService: executionService,
+		accessService:    accessService,
+	}
+}
+
+// RegisterRoutes registers all engine snapshot routes
+func (h *EngineSnapshotHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/engine/snapshot", h.handleGetSnapshot).Methods("GET")
+	router.HandleFunc("/api/admin/engine/restore", h.handleRestoreSnapshot).Methods("POST")
+}
+
+// handleGetSnapshot exports the runtime state of the engine
+func (h *EngineSnapshotHandler) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Snapshots hold the blueprints of every workspace
+	if respondWithAccessError(w, h.accessService.RequireAdmin(r.Context(), getUserIDFromRequest(r))) {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, h.executionService.SnapshotEngine())
+}
+
+// handleRestoreSnapshot restores the runtime state exported by another server
+func (h *EngineSnapshotHandler) handleRestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	if respondWithAccessError(w, h.accessService.RequireAdmin(r.Context(), getUserIDFromRequest(r))) {
+		return
+	}
+
+	var snapshot engine.EngineSnapshot
+	if !decodeRequest(w, r, &snapshot) {
+		return
+	}
+
+	restored, err := h.executionService.RestoreEngine(r.Context(), &snapshot)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error restoring engine snapshot: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, restored)
+}
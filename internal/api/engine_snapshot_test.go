@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestEngineSnapshotRequiresAdministrator(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	flowEngine := engine.NewExecutionEngine(mocks.NewMockLogger(), engine.NewDebugManager())
+	router := mux.NewRouter()
+	NewEngineSnapshotHandler(service.NewExecutionService(nil, nil, flowEngine), service.NewAccessService(users, workspaces)).RegisterRoutes(router)
+
+	for _, userID := range []string{"owner", "manager", "unknown"} {
+		if recorder := serve(router, http.MethodGet, "/api/admin/engine/snapshot", userID, ""); recorder.Code != http.StatusForbidden {
+			t.Errorf("%s: expected taking a snapshot to be forbidden, got %s", userID, statusOf(recorder))
+		}
+		if recorder := serve(router, http.MethodPost, "/api/admin/engine/restore", userID, `{"version": 1}`); recorder.Code != http.StatusForbidden {
+			t.Errorf("%s: expected restoring a snapshot to be forbidden, got %s", userID, statusOf(recorder))
+		}
+	}
+
+	if recorder := serve(router, http.MethodGet, "/api/admin/engine/snapshot", "admin", ""); recorder.Code != http.StatusOK {
+		t.Errorf("expected the administrator to take a snapshot, got %s", statusOf(recorder))
+	}
+	if recorder := serve(router, http.MethodPost, "/api/admin/engine/restore", "admin", `{"version": 99}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected a snapshot of an unknown version to be rejected, got %s", statusOf(recorder))
+	}
+}
@@ -1,82 +1,137 @@
 package api
 
 import (
-	"encoding/json"
+	stderrors "errors"
 	"net/http"
-	"time"
 	errors "webblueprint/internal/bperrors"
+	"webblueprint/internal/i18n"
+	"webblueprint/internal/requestid"
 )
 
-// ErrorResponse represents an API error response
+// ErrorResponse is the body of every error response of the API. The code is one of the
+// blueprint error codes, and the request ID matches the X-Request-ID header of the response
+// and the logs of the request. Messages translated into the locale of the request keep the
+// English one as the original message.
 type ErrorResponse struct {
-	Success   bool                   `json:"success"`
-	Error     *errors.BlueprintError `json:"error"`
-	Message   string                 `json:"message"`
-	ErrorCode string                 `json:"errorCode"`
-	Details   map[string]interface{} `json:"details,omitempty"`
-	Timestamp string                 `json:"timestamp"`
+	Code            errors.BlueprintErrorCode `json:"code"`
+	Message         string                    `json:"message"`
+	OriginalMessage string                    `json:"originalMessage,omitempty"`
+	Details         map[string]interface{}    `json:"details,omitempty"`
+	RequestID       string                    `json:"requestId,omitempty"`
 }
 
-// writeErrorResponse writes a structured error response
-func writeErrorResponse(w http.ResponseWriter, err error, statusCode int) {
-	response := ErrorResponse{
-		Success:   false,
-		Message:   err.Error(),
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
+// statusCodes are the codes of error responses whose handler gives none
+var statusCodes = map[int]errors.BlueprintErrorCode{
+	http.StatusBadRequest:            errors.ErrInvalidRequest,
+	http.StatusUnauthorized:          errors.ErrNotAuthenticated,
+	http.StatusForbidden:             errors.ErrPermissionDenied,
+	http.StatusNotFound:              errors.ErrResourceNotFound,
+	http.StatusConflict:              errors.ErrConflict,
+	http.StatusGone:                  errors.ErrResourceGone,
+	http.StatusRequestEntityTooLarge: errors.ErrRequestTooLarge,
+	http.StatusUnprocessableEntity:   errors.ErrInvalidRequest,
+	http.StatusTooManyRequests:       errors.ErrRateLimitExceeded,
+	http.StatusServiceUnavailable:    errors.ErrSystemUnavailable,
+}
 
-	// Handle BlueprintError specifically
-	if bpErr, ok := err.(*errors.BlueprintError); ok {
-		response.Error = bpErr
-		response.ErrorCode = string(bpErr.Code)
-		response.Details = bpErr.Details
-	} else {
-		// Wrap in a generic BlueprintError
-		bpErr = errors.Wrap(
-			err,
-			errors.ErrorTypeSystem,
-			errors.ErrUnknown,
-			err.Error(),
-			errors.SeverityMedium,
-		)
-		response.Error = bpErr
-		response.ErrorCode = string(bpErr.Code)
+// codeForStatus returns the code of an error response by its status
+func codeForStatus(status int) errors.BlueprintErrorCode {
+	if code, exists := statusCodes[status]; exists {
+		return code
 	}
+	if status < http.StatusInternalServerError {
+		return errors.ErrInvalidRequest
+	}
+	return errors.ErrInternalServerError
+}
 
-	// Write response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(response)
+// respondWithError writes an error response with the code of its status
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithErrorCode(w, status, codeForStatus(status), message, nil)
 }
 
-// handleAPIError handles an error in an API handler
-func handleAPIError(w http.ResponseWriter, err error) {
-	// Set appropriate status code based on error type
-	statusCode := http.StatusInternalServerError
+// respondWithErrorCode writes an error response with a code of its own
+func respondWithErrorCode(w http.ResponseWriter, status int, code errors.BlueprintErrorCode, message string, details map[string]interface{}) {
+	response := ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: w.Header().Get(requestid.Header),
+	}
+	if localized := responseLocalizer(w).Format(i18n.ErrorKey(string(code)), message, details); localized != message {
+		response.Message = localized
+		response.OriginalMessage = message
+	}
+	respondWithJSON(w, status, response)
+}
 
-	// If it's a BlueprintError, use more specific status codes
-	if bpErr, ok := err.(*errors.BlueprintError); ok {
-		switch bpErr.Type {
-		case errors.ErrorTypeValidation:
-			statusCode = http.StatusBadRequest
-		case errors.ErrorTypePermission:
-			statusCode = http.StatusForbidden
-		case errors.ErrorTypeConnection:
-			statusCode = http.StatusServiceUnavailable
-		case errors.ErrorTypeDatabase:
-			statusCode = http.StatusInternalServerError
+// respondWithBlueprintError writes a blueprint error as an error response. Its details
+// carry the type and severity of the error, and the node, blueprint and execution it
+// concerns.
+func respondWithBlueprintError(w http.ResponseWriter, status int, bpErr *errors.BlueprintError) {
+	details := make(map[string]interface{}, len(bpErr.Details)+2)
+	for key, value := range bpErr.Details {
+		details[key] = value
+	}
+	details["type"] = bpErr.Type
+	details["severity"] = bpErr.Severity
+	for key, value := range map[string]string{
+		"nodeId":      bpErr.NodeID,
+		"pinId":       bpErr.PinID,
+		"blueprintId": bpErr.BlueprintID,
+		"executionId": bpErr.ExecutionID,
+	} {
+		if value != "" {
+			details[key] = value
 		}
+	}
+	if len(bpErr.RecoveryOptions) > 0 {
+		details["recoveryOptions"] = bpErr.RecoveryOptions
+	}
 
-		// Special case error codes
-		switch bpErr.Code {
-		case errors.ErrBlueprintNotFound:
-			statusCode = http.StatusNotFound
-		case errors.ErrNodeNotFound:
-			statusCode = http.StatusNotFound
-		}
+	respondWithErrorCode(w, status, bpErr.Code, bpErr.Message, details)
+}
+
+// statusForError returns the status of a blueprint error
+func statusForError(bpErr *errors.BlueprintError) int {
+	// Special case error codes
+	switch bpErr.Code {
+	case errors.ErrBlueprintNotFound, errors.ErrBlueprintVersionNotFound, errors.ErrNodeNotFound, errors.ErrResourceNotFound:
+		return http.StatusNotFound
+	case errors.ErrResourceGone, errors.ErrBlueprintSunset:
+		return http.StatusGone
+	case errors.ErrRequestTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case errors.ErrNotAuthenticated:
+		return http.StatusUnauthorized
+	case errors.ErrConflict, errors.ErrBlueprintRunning:
+		return http.StatusConflict
+	case errors.ErrRateLimitExceeded, errors.ErrConcurrencyLimitExceeded:
+		return http.StatusTooManyRequests
 	}
 
-	writeErrorResponse(w, err, statusCode)
+	switch bpErr.Type {
+	case errors.ErrorTypeValidation:
+		return http.StatusBadRequest
+	case errors.ErrorTypePermission:
+		return http.StatusForbidden
+	case errors.ErrorTypeQuota:
+		return http.StatusUnprocessableEntity
+	case errors.ErrorTypeConnection:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// handleAPIError handles an error in an API handler
+func handleAPIError(w http.ResponseWriter, err error) {
+	var bpErr *errors.BlueprintError
+	if !stderrors.As(err, &bpErr) {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondWithBlueprintError(w, statusForError(bpErr), bpErr)
 }
 
 // ErrorHandler wraps an HTTP handler with standardized error handling
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/json"
 	"net/http"
 	"webblueprint/internal/bperrors"
 )
@@ -27,12 +26,7 @@ func (api *ErrorHandlingAPI) HandleListErrors(w http.ResponseWriter, r *http.Req
 	// Get execution ID from query params
 	executionID := r.URL.Query().Get("executionId")
 	if executionID == "" {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Missing executionId parameter",
-			ErrorCode: string(bperrors.ErrMissingRequiredInput),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+		respondWithErrorCode(w, http.StatusBadRequest, bperrors.ErrMissingRequiredInput, "Missing executionId parameter", nil)
 		return
 	}
 
@@ -54,12 +48,7 @@ func (api *ErrorHandlingAPI) HandleGetErrorAnalysis(w http.ResponseWriter, r *ht
 	// Get execution ID from query params
 	executionID := r.URL.Query().Get("executionId")
 	if executionID == "" {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Missing executionId parameter",
-			ErrorCode: string(bperrors.ErrMissingRequiredInput),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+		respondWithErrorCode(w, http.StatusBadRequest, bperrors.ErrMissingRequiredInput, "Missing executionId parameter", nil)
 		return
 	}
 
@@ -80,35 +69,20 @@ func (api *ErrorHandlingAPI) HandleGetExecutionInfo(w http.ResponseWriter, r *ht
 	// Get execution ID from query params
 	executionID := r.URL.Query().Get("executionId")
 	if executionID == "" {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Missing executionId parameter",
-			ErrorCode: string(bperrors.ErrMissingRequiredInput),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+		respondWithErrorCode(w, http.StatusBadRequest, bperrors.ErrMissingRequiredInput, "Missing executionId parameter", nil)
 		return
 	}
 
 	// If InfoStore is nil, respond with an error
 	if api.InfoStore == nil {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Execution info store not available",
-			ErrorCode: string(bperrors.ErrInternalServerError),
-		}
-		respondWithJSON(w, http.StatusInternalServerError, errorResponse)
+		respondWithErrorCode(w, http.StatusInternalServerError, bperrors.ErrInternalServerError, "Execution info store not available", nil)
 		return
 	}
 
 	// Get execution info
 	info, exists := api.InfoStore.GetExecutionInfo(executionID)
 	if !exists {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Execution info not found",
-			ErrorCode: string(bperrors.ErrBlueprintNotFound),
-		}
-		respondWithJSON(w, http.StatusNotFound, errorResponse)
+		respondWithErrorCode(w, http.StatusNotFound, bperrors.ErrBlueprintNotFound, "Execution info not found", nil)
 		return
 	}
 
@@ -131,24 +105,13 @@ func (api *ErrorHandlingAPI) HandleAttemptRecovery(w http.ResponseWriter, r *htt
 		Strategy    string `json:"strategy"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Invalid request format",
-			ErrorCode: string(bperrors.ErrInvalidNodeConfiguration),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate request
 	if req.ExecutionID == "" || req.NodeID == "" || req.ErrorCode == "" || req.Strategy == "" {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Missing required fields",
-			ErrorCode: string(bperrors.ErrMissingRequiredInput),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+		respondWithErrorCode(w, http.StatusBadRequest, bperrors.ErrMissingRequiredInput, "Missing required fields", nil)
 		return
 	}
 
@@ -164,12 +127,7 @@ func (api *ErrorHandlingAPI) HandleAttemptRecovery(w http.ResponseWriter, r *htt
 	}
 
 	if targetError == nil {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Error not found",
-			ErrorCode: string(bperrors.ErrNodeNotFound),
-		}
-		respondWithJSON(w, http.StatusNotFound, errorResponse)
+		respondWithErrorCode(w, http.StatusNotFound, bperrors.ErrNodeNotFound, "Error not found", nil)
 		return
 	}
 
@@ -194,24 +152,13 @@ func (api *ErrorHandlingAPI) HandleClearErrors(w http.ResponseWriter, r *http.Re
 		ExecutionID string `json:"executionId"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Invalid request format",
-			ErrorCode: string(bperrors.ErrInvalidNodeConfiguration),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate request
 	if req.ExecutionID == "" {
-		errorResponse := ErrorResponse{
-			Success:   false,
-			Message:   "Missing executionId",
-			ErrorCode: string(bperrors.ErrMissingRequiredInput),
-		}
-		respondWithJSON(w, http.StatusBadRequest, errorResponse)
+		respondWithErrorCode(w, http.StatusBadRequest, bperrors.ErrMissingRequiredInput, "Missing executionId", nil)
 		return
 	}
 
@@ -48,14 +48,13 @@ func NewErrorRecoveryHandler(
 func (h *ErrorRecoveryHandler) HandleErrorRecovery(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var req RecoveryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate request
 	if req.ExecutionID == "" || req.NodeID == "" || req.ErrorCode == "" || req.Strategy == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing required fields")
 		return
 	}
 
@@ -71,7 +70,7 @@ func (h *ErrorRecoveryHandler) HandleErrorRecovery(w http.ResponseWriter, r *htt
 	}
 
 	if targetError == nil {
-		http.Error(w, "Error not found", http.StatusNotFound)
+		respondWithError(w, http.StatusNotFound, "Error not found")
 		return
 	}
 
@@ -87,7 +86,7 @@ func (h *ErrorRecoveryHandler) HandleErrorRecovery(w http.ResponseWriter, r *htt
 	}
 
 	if !validStrategy {
-		http.Error(w, "Invalid recovery strategy", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Invalid recovery strategy")
 		return
 	}
 
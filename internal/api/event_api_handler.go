@@ -2,10 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 	"webblueprint/internal/event"
+	"webblueprint/internal/paging"
 	"webblueprint/internal/types"
+	"webblueprint/pkg/models"
 	"webblueprint/pkg/service"
 
 	"github.com/gorilla/mux"
@@ -30,6 +33,10 @@ func NewEventAPIHandler(eventManager *event.EventManager, eventService *service.
 
 // RegisterRoutes registers the event API routes with the router
 func (h *EventAPIHandler) RegisterEventRoutes(router *mux.Router) {
+	// Delivery endpoints of at-least-once bindings, ahead of /events/{id} which matches them too
+	router.HandleFunc("/events/deliveries", h.GetDeliveries).Methods("GET")
+	router.HandleFunc("/events/deliveries/{id}/redeliver", h.Redeliver).Methods("POST")
+
 	// Event definition endpoints
 	router.HandleFunc("/events", h.GetEvents).Methods("GET")
 	router.HandleFunc("/events", h.CreateEventDispatcher).Methods("POST") // Using CreateEventDispatcher instead of CreateEvent
@@ -37,6 +44,7 @@ func (h *EventAPIHandler) RegisterEventRoutes(router *mux.Router) {
 	router.HandleFunc("/events/{id}", h.UpdateEvent).Methods("PUT")
 	router.HandleFunc("/events/{id}", h.DeleteEvent).Methods("DELETE")
 	router.HandleFunc("/events/blueprint/{blueprintID}", h.GetBlueprintEvents).Methods("GET")
+	router.HandleFunc("/events/blueprint/{blueprintID}/bindings", h.GetBlueprintBindings).Methods("GET")
 
 	// Event binding endpoints
 	router.HandleFunc("/events/bindings", h.GetAllBindings).Methods("GET")
@@ -44,6 +52,8 @@ func (h *EventAPIHandler) RegisterEventRoutes(router *mux.Router) {
 	router.HandleFunc("/events/bindings", h.CreateBinding).Methods("POST")
 	router.HandleFunc("/events/bindings/{id}", h.UpdateBinding).Methods("PUT")
 	router.HandleFunc("/events/bindings/{id}", h.DeleteBinding).Methods("DELETE")
+	router.HandleFunc("/events/bindings/{id}/enable", h.EnableBinding).Methods("POST")
+	router.HandleFunc("/events/bindings/{id}/disable", h.DisableBinding).Methods("POST")
 
 	// System event endpoints
 	router.HandleFunc("/events/system", h.GetSystemEvents).Methods("GET")
@@ -56,8 +66,7 @@ func (h *EventAPIHandler) RegisterEventRoutes(router *mux.Router) {
 func (h *EventAPIHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var event event.EventDefinition
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &event) {
 		return
 	}
 
@@ -77,7 +86,7 @@ func (h *EventAPIHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	// Create the event
 	err := h.eventService.CreateEvent(r.Context(), event)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -97,12 +106,22 @@ func (h *EventAPIHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetEvents returns all registered events
+// eventSortFields are the fields event lists can be sorted by
+var eventSortFields = []string{"name", "created_at"}
+
+// GetEvents returns a page of the registered events. ?category= and ?blueprintId= filter
+// them, and ?sort= orders them by name or created_at, by name by default.
 func (h *EventAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, ok := parsePage(w, r, eventSortFields, paging.Sort{Field: "name"})
+	if !ok {
+		return
+	}
+
 	// Get events from service
 	events, err := h.eventService.GetAllEvents(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -123,24 +142,25 @@ func (h *EventAPIHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 		mergedEvents[evt.ID] = evt
 	}
 
-	// Convert back to slice
+	// Convert back to slice, keeping the events matching the filters
+	category, blueprintID := query.Get("category"), query.Get("blueprintId")
 	result := make([]event.EventDefinition, 0, len(mergedEvents))
 	for _, evt := range mergedEvents {
-		result = append(result, evt)
+		if (category == "" || evt.Category == category) && (blueprintID == "" || evt.BlueprintID == blueprintID) {
+			result = append(result, evt)
+		}
 	}
 
 	for _, definition := range events {
 		h.eventManager.RegisterEvent(definition)
 	}
 
-	// Set response headers
-	w.Header().Set("Content-Type", "application/json")
-
-	// Encode and send response
-	if err := json.NewEncoder(w).Encode(result); err != nil {
-		http.Error(w, "Failed to encode response "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	respondWithJSON(w, http.StatusOK, newListResponse(paging.Slice(result, page, func(evt event.EventDefinition) (string, string) {
+		if page.Sort.Field == "created_at" {
+			return paging.TimeKey(evt.CreatedAt), evt.ID
+		}
+		return evt.Name, evt.ID
+	})))
 }
 
 // GetEvent returns a specific event by ID
@@ -163,7 +183,7 @@ func (h *EventAPIHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
 			return // Return after handling the in-memory case
 		}
 		// If not found in DB and not found in memory
-		http.Error(w, "Event not found", http.StatusNotFound)
+		respondWithError(w, http.StatusNotFound, "Event not found")
 		return
 	}
 
@@ -186,8 +206,7 @@ func (h *EventAPIHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request
 	var updatedEvent event.EventDefinition
-	if err := json.NewDecoder(r.Body).Decode(&updatedEvent); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &updatedEvent) {
 		return
 	}
 
@@ -197,7 +216,7 @@ func (h *EventAPIHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	// Update the event
 	err := h.eventService.UpdateEvent(r.Context(), updatedEvent)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -225,7 +244,7 @@ func (h *EventAPIHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	// Delete the event
 	err := h.eventService.DeleteEvent(r.Context(), eventID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -248,7 +267,7 @@ func (h *EventAPIHandler) GetBlueprintEvents(w http.ResponseWriter, r *http.Requ
 	// Get events from service
 	events, err := h.eventService.GetBlueprintEvents(r.Context(), blueprintID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -267,7 +286,7 @@ func (h *EventAPIHandler) GetAllBindings(w http.ResponseWriter, r *http.Request)
 	// Get bindings from service
 	bindings, err := h.eventService.GetAllBindings(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -290,7 +309,7 @@ func (h *EventAPIHandler) GetBinding(w http.ResponseWriter, r *http.Request) {
 	// Get binding from service
 	binding, err := h.eventService.GetBindingByID(r.Context(), bindingID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		respondWithError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
@@ -318,8 +337,7 @@ type BindingRequest struct {
 func (h *EventAPIHandler) CreateBinding(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var request BindingRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -338,14 +356,14 @@ func (h *EventAPIHandler) CreateBinding(w http.ResponseWriter, r *http.Request)
 	// Create binding in service
 	err := h.eventService.CreateBinding(r.Context(), binding)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	// Register binding with in-memory event manager
 	err = h.eventManager.BindEvent(binding)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -368,15 +386,14 @@ func (h *EventAPIHandler) UpdateBinding(w http.ResponseWriter, r *http.Request)
 
 	// Parse request body
 	var request BindingRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
 	// Get existing binding
 	existingBinding, err := h.eventService.GetBindingByID(r.Context(), bindingID)
 	if err != nil {
-		http.Error(w, "Binding not found", http.StatusNotFound)
+		respondWithError(w, http.StatusNotFound, "Binding not found")
 		return
 	}
 
@@ -386,7 +403,7 @@ func (h *EventAPIHandler) UpdateBinding(w http.ResponseWriter, r *http.Request)
 	// Delete old binding from service
 	err = h.eventService.DeleteBinding(r.Context(), bindingID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -405,14 +422,14 @@ func (h *EventAPIHandler) UpdateBinding(w http.ResponseWriter, r *http.Request)
 	// Create binding in service
 	err = h.eventService.CreateBinding(r.Context(), binding)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	// Register binding with in-memory event manager
 	err = h.eventManager.BindEvent(binding)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -438,7 +455,7 @@ func (h *EventAPIHandler) DeleteBinding(w http.ResponseWriter, r *http.Request)
 	// Delete binding from service
 	err := h.eventService.DeleteBinding(r.Context(), bindingID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -449,6 +466,121 @@ func (h *EventAPIHandler) DeleteBinding(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// BindingStatus is a binding held by the event manager along with its dispatches
+type BindingStatus struct {
+	ID          string             `json:"id"`
+	EventID     string             `json:"eventId"`
+	HandlerID   string             `json:"handlerId"`
+	HandlerType string             `json:"handlerType"`
+	BlueprintID string             `json:"blueprintId"`
+	Priority    int                `json:"priority"`
+	Enabled     bool               `json:"enabled"`
+	Delivery    string             `json:"delivery,omitempty"`
+	Stats       event.BindingStats `json:"stats"`
+}
+
+// newBindingStatus describes a binding with its dispatches
+func (h *EventAPIHandler) newBindingStatus(binding event.EventBinding) BindingStatus {
+	return BindingStatus{
+		ID:          binding.ID,
+		EventID:     binding.EventID,
+		HandlerID:   binding.HandlerID,
+		HandlerType: binding.HandlerType,
+		BlueprintID: binding.BlueprintID,
+		Priority:    binding.Priority,
+		Enabled:     binding.Enabled,
+		Delivery:    binding.Delivery,
+		Stats:       h.eventManager.GetBindingStats(binding.ID),
+	}
+}
+
+// GetBlueprintBindings returns the bindings the event manager holds for a blueprint, with
+// their dispatch counts and latest error
+func (h *EventAPIHandler) GetBlueprintBindings(w http.ResponseWriter, r *http.Request) {
+	blueprintID := mux.Vars(r)["blueprintID"]
+
+	bindings := h.eventManager.GetBlueprintBindings(blueprintID)
+	statuses := make([]BindingStatus, 0, len(bindings))
+	for _, binding := range bindings {
+		statuses = append(statuses, h.newBindingStatus(binding))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// EnableBinding enables a binding without redeploying its blueprint
+func (h *EventAPIHandler) EnableBinding(w http.ResponseWriter, r *http.Request) {
+	h.setBindingEnabled(w, r, true)
+}
+
+// DisableBinding disables a binding without redeploying its blueprint, so events are no
+// longer dispatched to its handler
+func (h *EventAPIHandler) DisableBinding(w http.ResponseWriter, r *http.Request) {
+	h.setBindingEnabled(w, r, false)
+}
+
+// setBindingEnabled persists the enabled state of a bound binding and applies it to the
+// event manager right away
+func (h *EventAPIHandler) setBindingEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	bindingID := mux.Vars(r)["id"]
+
+	binding, bound := h.eventManager.GetBinding(bindingID)
+	if !bound {
+		respondWithError(w, http.StatusNotFound, "Binding not found")
+		return
+	}
+
+	if _, err := h.eventService.SetBindingEnabled(r.Context(), binding, enabled, getUserIDFromRequest(r)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	binding, _ = h.eventManager.SetBindingEnabled(bindingID, enabled)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.newBindingStatus(binding)); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetDeliveries returns a page of the unacknowledged deliveries of at-least-once bindings,
+// oldest first, optionally filtered by the blueprint of their handler and by status, e.g.
+// ?status=dead for the dead letters
+func (h *EventAPIHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	page, ok := parsePage(w, r, []string{"created_at"}, paging.Sort{Field: "created_at"})
+	if !ok {
+		return
+	}
+	deliveries := h.eventManager.GetDeliveries(r.URL.Query().Get("blueprintId"), r.URL.Query().Get("status"))
+
+	respondWithJSON(w, http.StatusOK, newListResponse(paging.Slice(deliveries, page, func(delivery models.EventDelivery) (string, string) {
+		return paging.TimeKey(delivery.CreatedAt), delivery.ID
+	})))
+}
+
+// Redeliver gives a dead-lettered delivery a new round of attempts
+func (h *EventAPIHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.eventManager.Redeliver(mux.Vars(r)["id"])
+	if errors.Is(err, event.ErrDeliveryNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(delivery); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 // GetSystemEvents returns all system events
 func (h *EventAPIHandler) GetSystemEvents(w http.ResponseWriter, r *http.Request) {
 	// Get events directly from the event manager
@@ -482,8 +614,7 @@ func (h *EventAPIHandler) DispatchEvent(w http.ResponseWriter, r *http.Request)
 		Params  map[string]interface{} `json:"params"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -503,7 +634,7 @@ func (h *EventAPIHandler) DispatchEvent(w http.ResponseWriter, r *http.Request)
 	// Dispatch the event
 	errs := h.eventManager.DispatchEvent(dispatchRequest)
 	if len(errs) > 0 {
-		http.Error(w, errs[0].Error(), http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, errs[0].Error())
 		return
 	}
 
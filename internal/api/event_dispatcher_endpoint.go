@@ -20,14 +20,13 @@ type CreateEventDispatcherRequest struct {
 func (h *EventAPIHandler) CreateEventDispatcher(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var request CreateEventDispatcherRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
 	// Validate the request
 	if request.Name == "" {
-		http.Error(w, "Event name is required", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Event name is required")
 		return
 	}
 
@@ -57,7 +56,7 @@ func (h *EventAPIHandler) CreateEventDispatcher(w http.ResponseWriter, r *http.R
 	// First, save to the database
 	err := h.eventService.CreateEvent(r.Context(), eventDef)
 	if err != nil {
-		http.Error(w, "Failed to save event to database: "+err.Error(), http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, "Failed to save event to database: "+err.Error())
 		return
 	}
 
@@ -87,15 +87,14 @@ type DispatchEventRequest struct {
 func (h *EventTestHandler) DispatchEvent(w http.ResponseWriter, r *http.Request) {
 	// Parse the request
 	var request DispatchEventRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
 	// Get the event definition
 	eventDef, exists := h.eventManager.GetEventDefinition(request.EventID)
 	if !exists {
-		http.Error(w, "Event not found", http.StatusNotFound)
+		respondWithError(w, http.StatusNotFound, "Event not found")
 		return
 	}
 
@@ -2,8 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/export"
+	"webblueprint/internal/paging"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/repository"
 	"webblueprint/pkg/service"
 
 	"github.com/gorilla/mux"
@@ -12,21 +20,33 @@ import (
 // ExecutionHandler handles execution-related API requests
 type ExecutionHandler struct {
 	executionService *service.ExecutionService
+	accessService    *service.AccessService
 }
 
 // NewExecutionHandler creates a new execution handler
-func NewExecutionHandler(executionService *service.ExecutionService) *ExecutionHandler {
+func NewExecutionHandler(executionService *service.ExecutionService, accessService *service.AccessService) *ExecutionHandler {
 	return &ExecutionHandler{
 		executionService: executionService,
+		accessService:    accessService,
 	}
 }
 
 // RegisterRoutes registers all execution-related routes
 func (h *ExecutionHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/executions", h.handleGetExecutions).Methods("GET")
+	router.HandleFunc("/api/executions/compare", h.handleCompareExecutions).Methods("GET")
 	router.HandleFunc("/api/executions/{id}", h.handleGetExecution).Methods("GET")
 	router.HandleFunc("/api/executions/{id}/logs", h.handleGetExecutionLogs).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/nodes/{nodeId}/logs", h.handleGetNodeLogs).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/timeline", h.handleGetExecutionTimeline).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/recording", h.handleGetExecutionRecording).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/lineage", h.handleGetExecutionLineage).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/state", h.handleGetVariableState).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/profile", h.handleGetExecutionProfile).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/replay", h.handleReplayExecution).Methods("POST")
 	router.HandleFunc("/api/executions/{id}/cancel", h.handleCancelExecution).Methods("POST")
+	router.HandleFunc("/api/executions/{id}/result", h.handleGetExecutionResult).Methods("GET")
+	router.HandleFunc("/api/executions/{id}/export", h.handleExportExecution).Methods("GET")
 
 	//
 	router.HandleFunc("/api/executions/{id}/nodes/{nodeId}", func(writer http.ResponseWriter, request *http.Request) {
@@ -36,25 +56,59 @@ func (h *ExecutionHandler) RegisterRoutes(router *mux.Router) {
 
 	// Blueprint execution endpoint (could also be in BlueprintHandler)
 	router.HandleFunc("/api/blueprints/{id}/execute", h.handleExecuteBlueprint).Methods("POST")
+
+	// Comparison of two versions run with the same inputs
+	router.HandleFunc("/api/blueprints/{id}/executions:compare", h.handleRunComparison).Methods("POST")
+
+	// Batch execution endpoints
+	router.HandleFunc("/api/blueprints/{id}/executions:batch", h.handleExecuteBlueprintBatch).Methods("POST")
+	router.HandleFunc("/api/batches/{id}", h.handleGetBatch).Methods("GET")
+
+	// Usage accounting
+	router.HandleFunc("/api/workspaces/{id}/usage/daily", h.handleGetWorkspaceUsage).Methods("GET")
+
+	// Executions of blueprints that don't run concurrently
+	router.HandleFunc("/api/blueprints/{id}/executions/queue", h.handleGetExecutionQueue).Methods("GET")
+
+	// Triggers rejected by the guards of entry points
+	router.HandleFunc("/api/blueprints/{id}/skipped-triggers", h.handleGetSkippedTriggers).Methods("GET")
+
+	// Scheduler metrics
+	router.HandleFunc("/api/metrics/execution-queue", h.handleGetQueueMetrics).Methods("GET")
+
+	// Blueprints running as services until they're stopped
+	router.HandleFunc("/api/services", h.handleListServices).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/service", h.handleGetService).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/service/start", h.handleStartService).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/service/stop", h.handleStopService).Methods("POST")
 }
 
-// handleGetExecutions gets executions, optionally filtered by blueprint
+// handleGetExecutions gets a page of the executions of the blueprint given as ?blueprint=.
+// ?status= and ?initiatedBy= filter them, and ?sort= orders them by started_at or status,
+// most recent first by default.
 func (h *ExecutionHandler) handleGetExecutions(w http.ResponseWriter, r *http.Request) {
-	// Get blueprint ID from query parameters (required)
-	blueprintID := r.URL.Query().Get("blueprint")
+	query := r.URL.Query()
+	blueprintID := query.Get("blueprint")
 	if blueprintID == "" {
 		respondWithError(w, http.StatusBadRequest, "Blueprint ID is required")
 		return
 	}
+	page, ok := parsePage(w, r, repository.ExecutionSortFields, paging.Sort{Field: "started_at", Desc: true})
+	if !ok {
+		return
+	}
 
-	// Get executions using the service
-	executions, err := h.executionService.GetExecutionsByBlueprint(r.Context(), blueprintID)
+	executions, err := h.executionService.ListExecutions(r.Context(), repository.ExecutionFilter{
+		BlueprintID: blueprintID,
+		Status:      query.Get("status"),
+		InitiatedBy: query.Get("initiatedBy"),
+	}, page)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving executions: %v", err))
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, executions)
+	respondWithJSON(w, http.StatusOK, newListResponse(executions))
 }
 
 // handleGetExecution gets a specific execution by ID
@@ -87,6 +141,279 @@ func (h *ExecutionHandler) handleGetExecutionLogs(w http.ResponseWriter, r *http
 	respondWithJSON(w, http.StatusOK, logs)
 }
 
+// handleGetNodeLogs gets a page of the logs of a node of an execution. ?level= lists the
+// logs at or above a level, and ?limit= and ?offset= page through them.
+func (h *ExecutionHandler) handleGetNodeLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	nodeID := vars["nodeId"]
+	query := r.URL.Query()
+
+	level := query.Get("level")
+	if level != "" && blueprint.LogLevelRank(level) < 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid level, expected debug, info, warn or error")
+		return
+	}
+
+	limit, offset := 0, 0
+	if limitParam := query.Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		parsed, err := strconv.Atoi(offsetParam)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset")
+			return
+		}
+		offset = parsed
+	}
+
+	page, err := h.executionService.GetNodeLogs(r.Context(), id, nodeID, level, limit, offset)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving node logs: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handleGetExecutionTimeline gets the node timings and critical path of an execution
+func (h *ExecutionHandler) handleGetExecutionTimeline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	timeline, err := h.executionService.GetExecutionTimeline(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving timeline: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, timeline)
+}
+
+// handleGetExecutionRecording gets the recorded external inputs of an execution
+func (h *ExecutionHandler) handleGetExecutionRecording(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	recording, err := h.executionService.GetExecutionRecording(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving recording: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recording)
+}
+
+// handleGetExecutionLineage gets the lineage of the final output values of an execution,
+// or with ?nodeId= and ?pinId= of a single output value
+func (h *ExecutionHandler) handleGetExecutionLineage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	nodeID := r.URL.Query().Get("nodeId")
+	pinID := r.URL.Query().Get("pinId")
+
+	if nodeID == "" && pinID == "" {
+		lineage, err := h.executionService.GetExecutionLineage(r.Context(), id)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving lineage: %v", err))
+			return
+		}
+		respondWithJSON(w, http.StatusOK, lineage)
+		return
+	}
+
+	if nodeID == "" || pinID == "" {
+		respondWithError(w, http.StatusBadRequest, "nodeId and pinId must be given together")
+		return
+	}
+
+	lineage, err := h.executionService.GetValueLineage(r.Context(), id, nodeID, pinID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving lineage: %v", err))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, lineage)
+}
+
+// handleGetVariableState gets the variables of an execution at a node with ?atNode=, at its
+// nth execution with ?iteration=n (the first by default), or at the end of the execution
+func (h *ExecutionHandler) handleGetVariableState(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	nodeID := r.URL.Query().Get("atNode")
+
+	iteration := 1
+	if iterationParam := r.URL.Query().Get("iteration"); iterationParam != "" {
+		if nodeID == "" {
+			respondWithError(w, http.StatusBadRequest, "iteration requires atNode")
+			return
+		}
+		parsed, err := strconv.Atoi(iterationParam)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "iteration must be a positive integer")
+			return
+		}
+		iteration = parsed
+	}
+
+	state, err := h.executionService.GetVariableState(r.Context(), id, nodeID, iteration)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving variable state: %v", err))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, state)
+}
+
+// handleGetExecutionProfile downloads the node execution profile of a profiled execution
+// as a speedscope file, or with ?format=summary returns its time and allocations by node type
+func (h *ExecutionHandler) handleGetExecutionProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "speedscope" && format != "summary" {
+		respondWithError(w, http.StatusBadRequest, "format must be speedscope or summary")
+		return
+	}
+
+	profile, err := h.executionService.GetExecutionProfile(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving profile: %v", err))
+		return
+	}
+
+	if format == "summary" {
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"executionId": profile.ExecutionID,
+			"nodeTypes":   profile.NodeTypes(),
+			"truncated":   profile.Truncated,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".speedscope.json"))
+	respondWithJSON(w, http.StatusOK, profile.Speedscope())
+}
+
+// handleGetExecutionResult returns the outputs of an execution. ?wait= (e.g. 30s) blocks
+// until the execution finishes or the wait elapses, whichever comes first, so clients
+// don't need a WebSocket to get the result of a short execution. Finished executions
+// respond with 200, and executions still running after the wait with 202.
+func (h *ExecutionHandler) handleGetExecutionResult(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var wait time.Duration
+	if waitParam := r.URL.Query().Get("wait"); waitParam != "" {
+		parsed, err := time.ParseDuration(waitParam)
+		if err != nil {
+			// Plain numbers are seconds
+			seconds, convErr := strconv.Atoi(waitParam)
+			if convErr != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid wait, expected a duration such as 30s")
+				return
+			}
+			parsed = time.Duration(seconds) * time.Second
+		}
+		if parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid wait, expected a positive duration")
+			return
+		}
+		wait = min(parsed, service.MaxResultWait)
+	}
+
+	// The wait may outlast the write timeout of the server
+	if wait > 0 {
+		http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + 10*time.Second))
+	}
+
+	result, err := h.executionService.WaitForExecutionResult(r.Context(), id, wait)
+	if err != nil {
+		if r.Context().Err() != nil {
+			return
+		}
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving result: %v", err))
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Done {
+		status = http.StatusAccepted
+	}
+	respondWithJSON(w, status, result)
+}
+
+// handleExportExecution streams the output pin values of a finished execution as a table,
+// ?format=csv (the default) or ?format=parquet
+func (h *ExecutionHandler) handleExportExecution(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if format != export.FormatCSV && format != export.FormatParquet {
+		respondWithError(w, http.StatusBadRequest, "Invalid format, expected csv or parquet")
+		return
+	}
+
+	execution, err := h.executionService.GetExecution(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Execution not found: %v", err))
+		return
+	}
+	if execution.Status == "queued" || execution.Status == "running" {
+		respondWithError(w, http.StatusConflict, "Execution is still running")
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+"."+format))
+	w.WriteHeader(http.StatusOK)
+
+	// The status is sent once the rows start streaming, so errors can only end the response
+	writer, _ := export.NewWriter(w, format)
+	if err := h.executionService.ExportExecution(r.Context(), execution, writer); err != nil {
+		return
+	}
+	writer.Close()
+}
+
+// handleReplayExecution re-runs an execution with its recorded external inputs
+func (h *ExecutionHandler) handleReplayExecution(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Get the user ID
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	replayID, err := h.executionService.ReplayExecution(r.Context(), id, userID)
+	if respondWithQuotaError(w, err) || respondWithNodePolicyError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error replaying execution: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{
+		"executionId": replayID,
+		"replayOf":    id,
+		"status":      "running",
+	})
+}
+
 // handleCancelExecution cancels a running execution
 func (h *ExecutionHandler) handleCancelExecution(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -112,12 +439,27 @@ func (h *ExecutionHandler) handleExecuteBlueprint(w http.ResponseWriter, r *http
 	// Parse request body for execution parameters
 	var request struct {
 		Variables map[string]interface{} `json:"variables"`
+		Priority  string                 `json:"priority"`
+		Version   int                    `json:"version"` // Version number to run instead of the current one
+		Profile   bool                   `json:"profile"` // Profile the node executions
+		// Run a deprecated blueprint past its sunset date
+		IgnoreSunset bool `json:"ignoreSunset"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		// If body can't be parsed, use empty variables
 		request.Variables = make(map[string]interface{})
 	}
 
+	priority, err := engine.ParseExecutionPriority(request.Priority)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Version < 0 {
+		respondWithError(w, http.StatusBadRequest, "Version must be a positive version number")
+		return
+	}
+
 	// Get the user ID
 	userID := getUserIDFromRequest(r)
 	if userID == "" {
@@ -125,8 +467,20 @@ func (h *ExecutionHandler) handleExecuteBlueprint(w http.ResponseWriter, r *http
 		return
 	}
 
+	ctx := r.Context()
+	if request.Profile {
+		ctx = service.WithProfiling(ctx)
+	}
+	if request.IgnoreSunset {
+		ctx = service.WithSunsetOverride(ctx)
+	}
+
 	// Execute the blueprint using the service
-	executionID, err := h.executionService.StartExecution(r.Context(), id, request.Variables, userID)
+	executionID, err := h.executionService.StartVersionExecution(ctx, id, request.Version, request.Variables, userID, priority)
+	if respondWithQuotaError(w, err) || respondWithNodePolicyError(w, err) || respondWithSunsetError(w, err) ||
+		respondWithBlueprintRunningError(w, err) || respondWithTriggerSkipped(w, err) {
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error executing blueprint: %v", err))
 		return
@@ -137,3 +491,279 @@ func (h *ExecutionHandler) handleExecuteBlueprint(w http.ResponseWriter, r *http
 		"status":      "running",
 	})
 }
+
+// comparisonRequest is the body of a comparison run. The blueprint of either side defaults
+// to the blueprint of the path, and its version to the current one.
+type comparisonRequest struct {
+	Baseline  service.ComparisonTarget `json:"baseline"`
+	Candidate service.ComparisonTarget `json:"candidate"`
+	Variables map[string]interface{}   `json:"variables"`
+	Wait      string                   `json:"wait"` // How long to wait for both executions, e.g. "30s"
+	wait      time.Duration
+}
+
+// Validate checks the versions and parses the wait
+func (c *comparisonRequest) Validate() error {
+	if c.Baseline.Version < 0 {
+		return fieldError("baseline.version", "Version must be a positive version number")
+	}
+	if c.Candidate.Version < 0 {
+		return fieldError("candidate.version", "Version must be a positive version number")
+	}
+	c.wait = service.MaxResultWait
+	if c.Wait != "" {
+		wait, err := time.ParseDuration(c.Wait)
+		if err != nil || wait < 0 {
+			return fieldError("wait", "Wait must be a positive duration such as 30s")
+		}
+		c.wait = min(wait, service.MaxResultWait)
+	}
+	return nil
+}
+
+// handleRunComparison runs two versions of a blueprint, or two blueprints, with the same
+// variables and returns their comparison. If they don't finish within the wait, it answers
+// 202 with their execution IDs, which GET /api/executions/compare takes once they're done.
+func (h *ExecutionHandler) handleRunComparison(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var request comparisonRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	for _, target := range []*service.ComparisonTarget{&request.Baseline, &request.Candidate} {
+		if target.BlueprintID == "" {
+			target.BlueprintID = id
+		}
+	}
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// The wait may outlast the write timeout of the server
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(request.wait + 10*time.Second))
+
+	run, err := h.executionService.RunComparison(r.Context(), request.Baseline, request.Candidate, request.Variables, request.wait, userID)
+	if respondWithQuotaError(w, err) || respondWithNodePolicyError(w, err) || respondWithBlueprintRunningError(w, err) || respondWithTriggerSkipped(w, err) {
+		return
+	}
+	if err != nil {
+		if r.Context().Err() != nil {
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error running comparison: %v", err))
+		return
+	}
+
+	status := http.StatusOK
+	if !run.Done {
+		status = http.StatusAccepted
+	}
+	respondWithJSON(w, status, run)
+}
+
+// handleCompareExecutions compares two finished executions given as ?baseline= and
+// ?candidate=
+func (h *ExecutionHandler) handleCompareExecutions(w http.ResponseWriter, r *http.Request) {
+	baselineID, candidateID := r.URL.Query().Get("baseline"), r.URL.Query().Get("candidate")
+	if baselineID == "" || candidateID == "" {
+		respondWithError(w, http.StatusBadRequest, "Baseline and candidate execution IDs are required")
+		return
+	}
+
+	comparison, err := h.executionService.CompareExecutions(r.Context(), baselineID, candidateID)
+	if errors.Is(err, service.ErrExecutionRunning) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error comparing executions: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
+
+// batchExecutionRequest is the body of a batch execution
+type batchExecutionRequest struct {
+	Inputs       []map[string]interface{} `json:"inputs"`
+	Concurrency  int                      `json:"concurrency"`
+	IgnoreSunset bool                     `json:"ignoreSunset"` // Run a deprecated blueprint past its sunset date
+}
+
+// Validate checks that the batch has input sets and a usable concurrency
+func (b *batchExecutionRequest) Validate() error {
+	if len(b.Inputs) == 0 {
+		return fieldError("inputs", "At least one input set is required")
+	}
+	if b.Concurrency < 0 {
+		return fieldError("concurrency", "Concurrency must not be negative")
+	}
+	return nil
+}
+
+// handleExecuteBlueprintBatch executes a blueprint once for each input set
+func (h *ExecutionHandler) handleExecuteBlueprintBatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var request batchExecutionRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	// Get the user ID
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	ctx := r.Context()
+	if request.IgnoreSunset {
+		ctx = service.WithSunsetOverride(ctx)
+	}
+
+	batch, err := h.executionService.StartBatchExecution(ctx, id, request.Inputs, request.Concurrency, userID)
+	if respondWithQuotaError(w, err) || respondWithNodePolicyError(w, err) || respondWithSunsetError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error starting batch execution: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+		"batchId":     batch.ID,
+		"status":      batch.Status,
+		"total":       batch.Total,
+		"concurrency": batch.Concurrency,
+	})
+}
+
+// handleGetBatch gets a batch and its item-level results
+func (h *ExecutionHandler) handleGetBatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	batch, err := h.executionService.GetBatch(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Batch not found: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, batch)
+}
+
+// handleGetQueueMetrics reports the execution queue depth per priority
+func (h *ExecutionHandler) handleGetQueueMetrics(w http.ResponseWriter, r *http.Request) {
+	depth := h.executionService.GetQueueDepth()
+
+	total := 0
+	for _, count := range depth {
+		total += count
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"queueDepth": depth,
+		"queued":     total,
+	})
+}
+
+// handleGetExecutionQueue gets the running and queued executions of a blueprint whose
+// concurrency policy keeps its executions from overlapping
+func (h *ExecutionHandler) handleGetExecutionQueue(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	respondWithJSON(w, http.StatusOK, h.executionService.GetBlueprintConcurrency(id))
+}
+
+// handleGetSkippedTriggers gets the latest triggers of a blueprint its entry point guards
+// rejected, with the reasons they were
+func (h *ExecutionHandler) handleGetSkippedTriggers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	respondWithJSON(w, http.StatusOK, h.executionService.GetSkippedTriggers(id))
+}
+
+// respondWithTriggerSkipped writes an execution skipped because the entry point guards
+// rejected its payload. Skipping isn't a failure, so it's 200 without an execution ID. It
+// returns false if err isn't such an error.
+func respondWithTriggerSkipped(w http.ResponseWriter, err error) bool {
+	bpErr, ok := service.IsTriggerSkippedError(err)
+	if !ok {
+		return false
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "skipped",
+		"reason":  bpErr.Message,
+		"details": bpErr,
+	})
+	return true
+}
+
+// respondWithBlueprintRunningError writes an execution turned away by the concurrency policy
+// of its blueprint as 409. It returns false if err isn't such an error.
+func respondWithBlueprintRunningError(w http.ResponseWriter, err error) bool {
+	bpErr, ok := service.IsBlueprintRunningError(err)
+	if !ok {
+		return false
+	}
+
+	respondWithBlueprintError(w, http.StatusConflict, bpErr)
+	return true
+}
+
+// handleGetWorkspaceUsage gets per-day usage summaries of a workspace.
+// The range is given as from/to dates (YYYY-MM-DD) and defaults to the last 30 days.
+func (h *ExecutionHandler) handleGetWorkspaceUsage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if respondWithAccessError(w, h.accessService.RequireAdminOfWorkspace(r.Context(), id, getUserIDFromRequest(r))) {
+		return
+	}
+
+	now := time.Now().UTC()
+	to := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	from := to.AddDate(0, 0, -30)
+
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid 'from' date, expected YYYY-MM-DD")
+			return
+		}
+		from = parsed
+	}
+
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid 'to' date, expected YYYY-MM-DD")
+			return
+		}
+		// Include the whole end day
+		to = parsed.Add(24 * time.Hour)
+	}
+
+	if !to.After(from) {
+		respondWithError(w, http.StatusBadRequest, "Invalid range, 'to' must not be before 'from'")
+		return
+	}
+
+	summaries, err := h.executionService.GetWorkspaceUsage(r.Context(), id, from, to)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving usage: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summaries)
+}
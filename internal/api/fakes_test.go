@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// fakeUserRepo serves users from memory. Methods the tests don't use panic.
+type fakeUserRepo struct {
+	repository.UserRepository
+
+	users map[string]*models.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	user, exists := r.users[id]
+	if !exists {
+		return nil, fmt.Errorf("user %s does not exist", id)
+	}
+	return user, nil
+}
+
+// fakeWorkspaceRepo serves workspaces and their members from memory
+type fakeWorkspaceRepo struct {
+	repository.WorkspaceRepository
+
+	workspaces map[string]*models.Workspace
+	members    map[string][]*models.WorkspaceMember
+	err        error // Returned by GetByID when set, like a failing database
+}
+
+func (r *fakeWorkspaceRepo) GetByID(ctx context.Context, id string) (*models.Workspace, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	workspace, exists := r.workspaces[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", repository.ErrWorkspaceNotFound, id)
+	}
+	return workspace, nil
+}
+
+func (r *fakeWorkspaceRepo) Update(ctx context.Context, workspace *models.Workspace) error {
+	r.workspaces[workspace.ID] = workspace
+	return nil
+}
+
+func (r *fakeWorkspaceRepo) GetMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	return r.members[workspaceID], nil
+}
+
+// fakeExecutionRepo serves the daily usage of workspaces from memory
+type fakeExecutionRepo struct {
+	repository.ExecutionRepository
+
+	usage map[string][]*models.ExecutionUsageSummary
+	err   error // Returned by GetDailyUsage when set, like a failing database
+}
+
+func (r *fakeExecutionRepo) GetDailyUsage(ctx context.Context, workspaceID string, from, to time.Time) ([]*models.ExecutionUsageSummary, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.usage[workspaceID], nil
+}
+
+// newFakeAccessRepos returns a workspace owned by "owner" with a "manager" admin member and
+// an "editor" member, and an "admin" user administering the server
+func newFakeAccessRepos() (*fakeUserRepo, *fakeWorkspaceRepo) {
+	users := &fakeUserRepo{users: map[string]*models.User{
+		"admin":   {ID: "admin", Role: service.RoleAdmin, IsActive: true},
+		"owner":   {ID: "owner", Role: "user", IsActive: true},
+		"manager": {ID: "manager", Role: "user", IsActive: true},
+		"editor":  {ID: "editor", Role: "user", IsActive: true},
+	}}
+	workspaces := &fakeWorkspaceRepo{
+		workspaces: map[string]*models.Workspace{
+			"workspace": {ID: "workspace", OwnerType: "user", OwnerID: "owner"},
+		},
+		members: map[string][]*models.WorkspaceMember{
+			"workspace": {
+				{WorkspaceID: "workspace", UserID: "manager", Role: service.MemberRoleAdmin},
+				{WorkspaceID: "workspace", UserID: "editor", Role: service.MemberRoleEditor},
+			},
+		},
+	}
+	return users, workspaces
+}
+
+// serve sends a request as the user through the routes and returns the recorded response
+func serve(router *mux.Router, method, path, userID, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-User-ID", userID)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// statusOf reports the status of a response along with its body, for test failures
+func statusOf(recorder *httptest.ResponseRecorder) string {
+	return fmt.Sprintf("%d %s", recorder.Code, http.StatusText(recorder.Code)) + ": " + recorder.Body.String()
+}
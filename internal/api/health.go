@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"time"
+	"webblueprint/internal/health"
+
+	"github.com/gorilla/mux"
+)
+
+// HealthHandler serves the liveness and readiness probes of the server
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// RegisterRoutes registers all health-related routes
+func (h *HealthHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/health", h.handleLive).Methods("GET")
+	router.HandleFunc("/api/health/live", h.handleLive).Methods("GET")
+	router.HandleFunc("/api/health/ready", h.handleReady).Methods("GET")
+}
+
+// handleLive reports that the process is alive. It doesn't depend on anything else, so
+// orchestrators don't restart the server while a dependency is down.
+func (h *HealthHandler) handleLive(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    health.StatusOK,
+		"phase":     h.checker.Phase(),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handleReady reports whether the server should take traffic, with the result of each
+// dependency check. It responds with 503 while starting, draining or failing a check.
+func (h *HealthHandler) handleReady(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Ready(r.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, status, report)
+}
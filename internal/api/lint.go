@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"webblueprint/internal/lint"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// LintHandler handles blueprint lint requests and workspace lint rule configuration
+type LintHandler struct {
+	lintService      *service.LintService
+	blueprintService *service.BlueprintService
+}
+
+// NewLintHandler creates a new lint handler
+func NewLintHandler(lintService *service.LintService, blueprintService *service.BlueprintService) *LintHandler {
+	return &LintHandler{
+		lintService:      lintService,
+		blueprintService: blueprintService,
+	}
+}
+
+// RegisterRoutes registers all lint routes
+func (h *LintHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/lint/rules", h.handleGetRules).Methods("GET")
+	router.HandleFunc("/api/workspaces/{id}/lint-rules", h.handleGetRuleSet).Methods("GET")
+	router.HandleFunc("/api/workspaces/{id}/lint-rules", h.handleUpdateRuleSet).Methods("PUT")
+	router.HandleFunc("/api/workspaces/{id}/lint", h.handleLintBlueprint).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/lint", h.handleLintStoredBlueprint).Methods("POST")
+}
+
+// handleGetRules gets the available lint rules with their default severities and options
+func (h *LintHandler) handleGetRules(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, lint.Rules())
+}
+
+// handleGetRuleSet gets the lint rules configured for a workspace
+func (h *LintHandler) handleGetRuleSet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ruleSet, err := h.lintService.GetRuleSet(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving lint rules: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ruleSet)
+}
+
+// handleUpdateRuleSet replaces the lint rules configured for a workspace
+func (h *LintHandler) handleUpdateRuleSet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var ruleSet lint.RuleSet
+	if !decodeRequest(w, r, &ruleSet) {
+		return
+	}
+
+	if err := h.lintService.SetRuleSet(r.Context(), id, ruleSet); err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error updating lint rules: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ruleSet)
+}
+
+// handleLintBlueprint lints the blueprint in the request body, e.g. the unsaved state of
+// the editor, against the lint rules of a workspace
+func (h *LintHandler) handleLintBlueprint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var bp blueprint.Blueprint
+	if !decodeRequest(w, r, &bp) {
+		return
+	}
+
+	report, err := h.lintService.Lint(r.Context(), id, &bp)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error linting blueprint: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// handleLintStoredBlueprint lints the current version of a stored blueprint against the
+// lint rules of its workspace
+func (h *LintHandler) handleLintStoredBlueprint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	bp, err := h.blueprintService.GetBlueprint(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Blueprint not found: %v", err))
+		return
+	}
+
+	report, err := h.lintService.LintStored(r.Context(), id, bp)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error linting blueprint: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/engine"
+
+	"github.com/gorilla/mux"
+)
+
+// ListenerHandler exposes the event delivery to the execution listeners of the engine
+type ListenerHandler struct {
+	executionEngine *engine.ExecutionEngine
+}
+
+// NewListenerHandler creates a new listener handler
+func NewListenerHandler(executionEngine *engine.ExecutionEngine) *ListenerHandler {
+	return &ListenerHandler{
+		executionEngine: executionEngine,
+	}
+}
+
+// RegisterRoutes registers all listener-related routes
+func (h *ListenerHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/diagnostics/listeners", h.handleGetListeners).Methods("GET")
+}
+
+// handleGetListeners gets the pending, delivered, coalesced and dropped events of each listener
+func (h *ListenerHandler) handleGetListeners(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.executionEngine.GetListenerStats())
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/i18n"
+
+	"github.com/gorilla/mux"
+)
+
+// Localize negotiates the locale of the requests handled by next from their Accept-Language
+// header. The locale is announced in the Content-Language header of the response and
+// carried by the request context, so the node catalog and the error responses are
+// translated into it.
+func Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.Default().Match(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.NewContext(r.Context(), locale)))
+	})
+}
+
+// responseLocalizer returns the localizer of the locale a response is written in
+func responseLocalizer(w http.ResponseWriter) *i18n.Localizer {
+	locale := w.Header().Get("Content-Language")
+	if locale == "" || locale == i18n.DefaultLocale {
+		return nil
+	}
+	return i18n.Default().Localizer(locale)
+}
+
+// LocaleHandler serves the message catalogs to the editor
+type LocaleHandler struct{}
+
+// NewLocaleHandler creates a new locale handler
+func NewLocaleHandler() *LocaleHandler {
+	return &LocaleHandler{}
+}
+
+// RegisterRoutes registers all locale-related routes
+func (h *LocaleHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/i18n", h.handleGetMessages).Methods("GET")
+}
+
+// handleGetMessages returns the messages of the negotiated locale, or of the one in the
+// locale query parameter, with the locales the server has catalogs for. The editor uses
+// them for the texts it renders itself, like the messages of error codes.
+func (h *LocaleHandler) handleGetMessages(w http.ResponseWriter, r *http.Request) {
+	locale := i18n.FromContext(r.Context())
+	if requested := r.URL.Query().Get("locale"); requested != "" {
+		locale = i18n.Canonical(requested)
+		if locale == "" {
+			respondWithBlueprintError(w, http.StatusBadRequest, fieldError("locale", "invalid locale"))
+			return
+		}
+	}
+
+	bundle := i18n.Default()
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"locale":   locale,
+		"locales":  bundle.Locales(),
+		"messages": bundle.Catalog(locale),
+	})
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/locks"
+
+	"github.com/gorilla/mux"
+)
+
+// LockHandler exposes the locks held by the executions of this server
+type LockHandler struct {
+	locker locks.Locker
+}
+
+// NewLockHandler creates a new lock handler
+func NewLockHandler(locker locks.Locker) *LockHandler {
+	return &LockHandler{
+		locker: locker,
+	}
+}
+
+// RegisterRoutes registers all lock-related routes
+func (h *LockHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/diagnostics/locks", h.handleGetLocks).Methods("GET")
+}
+
+// handleGetLocks gets the held locks with their owning executions and expiry
+func (h *LockHandler) handleGetLocks(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.locker.Locks())
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// NodePolicyHandler handles workspace node policy administration requests
+type NodePolicyHandler struct {
+	nodePolicyService *service.NodePolicyService
+	accessService     *service.AccessService
+}
+
+// NewNodePolicyHandler creates a new node policy handler
+func NewNodePolicyHandler(nodePolicyService *service.NodePolicyService, accessService *service.AccessService) *NodePolicyHandler {
+	return &NodePolicyHandler{
+		nodePolicyService: nodePolicyService,
+		accessService:     accessService,
+	}
+}
+
+// RegisterRoutes registers all node policy routes
+func (h *NodePolicyHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/workspaces/{id}/node-policies", h.handleGetPolicies).Methods("GET")
+	router.HandleFunc("/api/admin/workspaces/{id}/node-policies", h.handleUpdatePolicies).Methods("PUT")
+}
+
+// handleGetPolicies gets the node policies of a workspace
+func (h *NodePolicyHandler) handleGetPolicies(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	policies, err := h.nodePolicyService.GetPolicies(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving node policies: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policies)
+}
+
+// handleUpdatePolicies replaces the node policies of a workspace
+func (h *NodePolicyHandler) handleUpdatePolicies(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Only the administrators and owners of the workspace restrict its node types
+	if respondWithAccessError(w, h.accessService.RequireWorkspaceAdmin(r.Context(), id, getUserIDFromRequest(r))) {
+		return
+	}
+
+	var policies []service.NodePolicy
+	if !decodeRequest(w, r, &policies) {
+		return
+	}
+
+	if err := h.nodePolicyService.SetPolicies(r.Context(), id, policies); err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error updating node policies: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policies)
+}
+
+// respondWithNodePolicyError writes a node policy violation as 403, naming the blocked
+// node types and their policies. It returns false if err isn't a node policy violation.
+func respondWithNodePolicyError(w http.ResponseWriter, err error) bool {
+	bpErr, ok := service.IsNodePolicyError(err)
+	if !ok {
+		return false
+	}
+
+	respondWithBlueprintError(w, http.StatusForbidden, bpErr)
+	return true
+}
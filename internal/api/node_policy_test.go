@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateNodePoliciesRequiresWorkspaceAdministrator(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	router := mux.NewRouter()
+	NewNodePolicyHandler(service.NewNodePolicyService(workspaces), service.NewAccessService(users, workspaces)).RegisterRoutes(router)
+
+	body := `[{"name": "no-scripts", "mode": "deny", "nodeTypes": ["script-*"]}]`
+	for _, userID := range []string{"editor", "unknown", ""} {
+		recorder := serve(router, http.MethodPut, "/api/admin/workspaces/workspace/node-policies", userID, body)
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("%q: expected 403, got %s", userID, statusOf(recorder))
+		}
+		if !strings.Contains(recorder.Body.String(), string(bperrors.ErrPermissionDenied)) {
+			t.Errorf("%q: expected the %s code, got %s", userID, bperrors.ErrPermissionDenied, recorder.Body.String())
+		}
+	}
+	if policies := workspaces.workspaces["workspace"].Metadata; policies != nil {
+		t.Errorf("expected the rejected updates not to store policies, got %v", policies)
+	}
+
+	for _, userID := range []string{"owner", "manager", "admin"} {
+		recorder := serve(router, http.MethodPut, "/api/admin/workspaces/workspace/node-policies", userID, body)
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: expected the node policies to be updated, got %s", userID, statusOf(recorder))
+		}
+	}
+
+	recorder := serve(router, http.MethodPut, "/api/admin/workspaces/missing/node-policies", "owner", body)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown workspace to be reported, got %s", statusOf(recorder))
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/paging"
+)
+
+// ListResponse is the body of every list response of the API: a page of the list, the
+// number of items in the whole list, and the cursor of the next page, which is passed back
+// as ?cursor= and is absent on the last page
+type ListResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// newListResponse returns the body of a list response for a page
+func newListResponse[T any](page paging.Page[T]) ListResponse[T] {
+	response := ListResponse[T]{Items: page.Items, Total: page.Total}
+	if response.Items == nil {
+		response.Items = []T{}
+	}
+	if page.Next != nil {
+		response.NextCursor = page.Next.Encode()
+	}
+	return response
+}
+
+// parsePage reads the ?limit=, ?sort= and ?cursor= parameters of a list request, writing
+// an error response if they're invalid
+func parsePage(w http.ResponseWriter, r *http.Request, sortable []string, defaultSort paging.Sort) (paging.Request, bool) {
+	page, err := paging.Parse(r.URL.Query(), sortable, defaultSort)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return page, false
+	}
+	return page, true
+}
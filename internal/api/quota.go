@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// QuotaHandler handles workspace quota administration requests
+type QuotaHandler struct {
+	quotaService  *service.QuotaService
+	accessService *service.AccessService
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(quotaService *service.QuotaService, accessService *service.AccessService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService:  quotaService,
+		accessService: accessService,
+	}
+}
+
+// RegisterRoutes registers all quota-related routes
+func (h *QuotaHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/workspaces/{id}/quota", h.handleGetQuota).Methods("GET")
+	router.HandleFunc("/api/admin/workspaces/{id}/quota", h.handleUpdateQuota).Methods("PUT")
+	router.HandleFunc("/api/workspaces/{id}/usage", h.handleGetUsage).Methods("GET")
+}
+
+// handleGetQuota gets the quota of a workspace
+func (h *QuotaHandler) handleGetQuota(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if respondWithAccessError(w, h.accessService.RequireAdmin(r.Context(), getUserIDFromRequest(r))) {
+		return
+	}
+
+	quota, err := h.quotaService.GetQuota(r.Context(), id)
+	if err != nil {
+		respondWithError(w, workspaceErrorStatus(err), fmt.Sprintf("Error retrieving quota: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, quota)
+}
+
+// handleUpdateQuota replaces the quota of a workspace
+func (h *QuotaHandler) handleUpdateQuota(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Quotas are set by the administrators of the server, not of the workspace
+	if respondWithAccessError(w, h.accessService.RequireAdmin(r.Context(), getUserIDFromRequest(r))) {
+		return
+	}
+
+	var quota service.WorkspaceQuota
+	if !decodeRequest(w, r, &quota) {
+		return
+	}
+
+	if err := h.quotaService.SetQuota(r.Context(), id, quota); err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error updating quota: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, quota)
+}
+
+// handleGetUsage gets the current usage of a workspace against its quota
+func (h *QuotaHandler) handleGetUsage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if respondWithAccessError(w, h.accessService.RequireAdmin(r.Context(), getUserIDFromRequest(r))) {
+		return
+	}
+
+	usage, err := h.quotaService.GetUsage(r.Context(), id)
+	if err != nil {
+		respondWithError(w, workspaceErrorStatus(err), fmt.Sprintf("Error retrieving usage: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, usage)
+}
+
+// respondWithQuotaError writes a quota error as 429 (rate and concurrency limits)
+// or 422 (limits that retrying cannot satisfy). It returns false if err isn't a quota error.
+func respondWithQuotaError(w http.ResponseWriter, err error) bool {
+	bpErr, ok := service.IsQuotaError(err)
+	if !ok {
+		return false
+	}
+
+	status := http.StatusUnprocessableEntity
+	if bpErr.Code == bperrors.ErrRateLimitExceeded || bpErr.Code == bperrors.ErrConcurrencyLimitExceeded {
+		status = http.StatusTooManyRequests
+		if retryAfter, exists := bpErr.Details["retryAfterSeconds"].(int); exists {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+	}
+
+	respondWithBlueprintError(w, status, bpErr)
+	return true
+}
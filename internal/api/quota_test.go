@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateQuotaRequiresAdministrator(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	router := mux.NewRouter()
+	NewQuotaHandler(service.NewQuotaService(workspaces), service.NewAccessService(users, workspaces)).RegisterRoutes(router)
+
+	body := `{"executionsPerMinute": 10, "maxConcurrentExecutions": 2}`
+	for _, userID := range []string{"owner", "editor", "unknown"} {
+		recorder := serve(router, http.MethodPut, "/api/admin/workspaces/workspace/quota", userID, body)
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %s", userID, statusOf(recorder))
+		}
+		if !strings.Contains(recorder.Body.String(), string(bperrors.ErrPermissionDenied)) {
+			t.Errorf("%s: expected the %s code, got %s", userID, bperrors.ErrPermissionDenied, recorder.Body.String())
+		}
+	}
+	if quota := workspaces.workspaces["workspace"].Metadata; quota != nil {
+		t.Errorf("expected the rejected updates not to store a quota, got %v", quota)
+	}
+
+	recorder := serve(router, http.MethodPut, "/api/admin/workspaces/workspace/quota", "admin", body)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected the administrator to update the quota, got %s", statusOf(recorder))
+	}
+
+	recorder = serve(router, http.MethodGet, "/api/admin/workspaces/workspace/quota", "owner", "")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected reading the quota to require an administrator, got %s", statusOf(recorder))
+	}
+}
+
+func TestUsageRequiresAdministrator(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	router := mux.NewRouter()
+	NewQuotaHandler(service.NewQuotaService(workspaces), service.NewAccessService(users, workspaces)).RegisterRoutes(router)
+
+	for _, userID := range []string{"owner", "manager", "unknown", ""} {
+		if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage", userID, ""); recorder.Code != http.StatusForbidden {
+			t.Errorf("%q: expected 403, got %s", userID, statusOf(recorder))
+		}
+	}
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage", "admin", ""); recorder.Code != http.StatusOK {
+		t.Errorf("expected the administrator to read the usage, got %s", statusOf(recorder))
+	}
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/missing/usage", "admin", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected the usage of a missing workspace not to be found, got %s", statusOf(recorder))
+	}
+
+	workspaces.err = errors.New("connection refused")
+	for _, path := range []string{"/api/workspaces/other/usage", "/api/admin/workspaces/other/quota"} {
+		if recorder := serve(router, http.MethodGet, path, "admin", ""); recorder.Code != http.StatusInternalServerError {
+			t.Errorf("%s: expected a failing database to answer 500, got %s", path, statusOf(recorder))
+		}
+	}
+}
+
+func TestDailyUsageRequiresAdministrator(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	executions := &fakeExecutionRepo{usage: map[string][]*models.ExecutionUsageSummary{
+		"workspace": {{WorkspaceID: "workspace"}},
+	}}
+	flowEngine := engine.NewExecutionEngine(mocks.NewMockLogger(), engine.NewDebugManager())
+	router := mux.NewRouter()
+	NewExecutionHandler(service.NewExecutionService(executions, nil, flowEngine), service.NewAccessService(users, workspaces)).RegisterRoutes(router)
+
+	for _, userID := range []string{"owner", "manager", "unknown", ""} {
+		if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage/daily", userID, ""); recorder.Code != http.StatusForbidden {
+			t.Errorf("%q: expected 403, got %s", userID, statusOf(recorder))
+		}
+	}
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage/daily", "admin", ""); recorder.Code != http.StatusOK {
+		t.Errorf("expected the administrator to read the daily usage, got %s", statusOf(recorder))
+	}
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/missing/usage/daily", "admin", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected the daily usage of a missing workspace not to be found, got %s", statusOf(recorder))
+	}
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage/daily?from=2026-02-01&to=2026-01-01", "admin", ""); recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected an inverted range to be rejected, got %s", statusOf(recorder))
+	}
+
+	executions.err = errors.New("connection refused")
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage/daily", "admin", ""); recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected a failing database to answer 500, got %s", statusOf(recorder))
+	}
+	workspaces.err = errors.New("connection refused")
+	if recorder := serve(router, http.MethodGet, "/api/workspaces/workspace/usage/daily", "admin", ""); recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected a failing workspace lookup to answer 500, got %s", statusOf(recorder))
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"webblueprint/internal/bperrors"
+)
+
+// maxRequestBody is the size limit of the JSON bodies of requests
+const maxRequestBody = 32 << 20
+
+// errTrailingData reports a body with more than one JSON value
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// validatable is implemented by request bodies that check their fields once decoded
+type validatable interface {
+	Validate() error
+}
+
+// decodeRequest decodes the JSON body of a request into dst and validates it, if it has a
+// Validate method. It writes a 400 error response naming the problem, or 413 for bodies
+// over the size limit, and returns false if the body isn't valid.
+func decodeRequest(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeBody(w, r, dst, false)
+}
+
+// decodeOptionalRequest is decodeRequest for bodies that may be left out, leaving dst as it
+// is when the body is empty
+func decodeOptionalRequest(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeBody(w, r, dst, true)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, dst interface{}, optional bool) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	err := decoder.Decode(dst)
+	if err == nil && decoder.More() {
+		err = errTrailingData
+	}
+	if errors.Is(err, io.EOF) && optional {
+		err = nil
+	}
+	if err == nil {
+		if v, ok := dst.(validatable); ok {
+			err = v.Validate()
+		}
+	}
+	if err == nil {
+		return true
+	}
+
+	var bpErr *bperrors.BlueprintError
+	if errors.As(err, &bpErr) {
+		respondWithBlueprintError(w, http.StatusBadRequest, bpErr)
+		return false
+	}
+	status, message, details := describeDecodeError(err)
+	code := bperrors.ErrInvalidRequest
+	if status == http.StatusRequestEntityTooLarge {
+		code = bperrors.ErrRequestTooLarge
+	}
+	respondWithErrorCode(w, status, code, message, details)
+	return false
+}
+
+// describeDecodeError returns the status, message and details of a body that couldn't be
+// decoded or validated
+func describeDecodeError(err error) (int, string, map[string]interface{}) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var sizeErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return http.StatusBadRequest, "Request body is required", nil
+	case errors.Is(err, errTrailingData):
+		return http.StatusBadRequest, "Request body must contain a single JSON value", nil
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest, "Request body is truncated JSON", nil
+	case errors.As(err, &syntaxErr):
+		return http.StatusBadRequest, fmt.Sprintf("Request body is malformed JSON at offset %d", syntaxErr.Offset),
+			map[string]interface{}{"offset": syntaxErr.Offset}
+	case errors.As(err, &typeErr):
+		message := fmt.Sprintf("Request body has %s where %s is expected", typeErr.Value, typeErr.Type)
+		details := map[string]interface{}{"expected": typeErr.Type.String(), "got": typeErr.Value}
+		if typeErr.Field != "" {
+			message = fmt.Sprintf("Field %s must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+			details["field"] = typeErr.Field
+		}
+		return http.StatusBadRequest, message, details
+	case errors.As(err, &sizeErr):
+		return http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds %d bytes", sizeErr.Limit),
+			map[string]interface{}{"limit": sizeErr.Limit}
+	default:
+		return http.StatusBadRequest, "Invalid request: " + err.Error(), nil
+	}
+}
+
+// fieldError reports an invalid field of a request body
+func fieldError(field, message string) *bperrors.BlueprintError {
+	return bperrors.New(bperrors.ErrorTypeValidation, bperrors.ErrInvalidRequest, message, bperrors.SeverityLow).
+		WithDetails(map[string]interface{}{"field": field})
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+	"webblueprint/internal/requestid"
+)
+
+// RequestID identifies the requests handled by next. A valid X-Request-ID header of the
+// client or proxy is kept, otherwise an ID is generated. The ID is echoed in the response
+// header and carried by the request context into the logs, the error responses and the
+// executions the request starts.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
@@ -27,21 +27,20 @@ func (h *SchemaComponentHandler) CreateSchemaComponent(w http.ResponseWriter, r
 		SchemaDefinition string `json:"schema_definition"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &payload) {
 		return
 	}
 	defer r.Body.Close()
 
 	if payload.Name == "" || payload.SchemaDefinition == "" {
-		http.Error(w, "Missing required fields: name and schema_definition", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing required fields: name and schema_definition")
 		return
 	}
 
 	component, err := h.Store.CreateSchemaComponent(payload.Name, payload.SchemaDefinition)
 	if err != nil {
 		fmt.Printf("Error creating schema component: %v\n", err) // Replace with proper logging
-		http.Error(w, "Failed to create schema component", http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create schema component")
 		return
 	}
 
@@ -55,7 +54,7 @@ func (h *SchemaComponentHandler) ListSchemaComponents(w http.ResponseWriter, r *
 	components, err := h.Store.ListSchemaComponents()
 	if err != nil {
 		fmt.Printf("Error listing schema components: %v\n", err) // Replace with proper logging
-		http.Error(w, "Failed to list schema components", http.StatusInternalServerError)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list schema components")
 		return
 	}
 
@@ -72,7 +71,7 @@ func (h *SchemaComponentHandler) ListSchemaComponents(w http.ResponseWriter, r *
 func (h *SchemaComponentHandler) GetSchemaComponent(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id") // Assuming chi router for URL params
 	if id == "" {
-		http.Error(w, "Missing schema component ID", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing schema component ID")
 		return
 	}
 
@@ -80,10 +79,10 @@ func (h *SchemaComponentHandler) GetSchemaComponent(w http.ResponseWriter, r *ht
 	if err != nil {
 		// Check if it's a 'not found' error
 		if err.Error() == fmt.Sprintf("schema component not found with id: %s", id) { // Basic check, improve if needed
-			http.Error(w, err.Error(), http.StatusNotFound)
+			respondWithError(w, http.StatusNotFound, err.Error())
 		} else {
 			fmt.Printf("Error getting schema component: %v\n", err) // Replace with proper logging
-			http.Error(w, "Failed to get schema component", http.StatusInternalServerError)
+			respondWithError(w, http.StatusInternalServerError, "Failed to get schema component")
 		}
 		return
 	}
@@ -98,7 +97,7 @@ func (h *SchemaComponentHandler) GetSchemaComponent(w http.ResponseWriter, r *ht
 func (h *SchemaComponentHandler) UpdateSchemaComponent(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		http.Error(w, "Missing schema component ID", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing schema component ID")
 		return
 	}
 
@@ -107,24 +106,23 @@ func (h *SchemaComponentHandler) UpdateSchemaComponent(w http.ResponseWriter, r
 		SchemaDefinition string `json:"schema_definition"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &payload) {
 		return
 	}
 	defer r.Body.Close()
 
 	if payload.Name == "" || payload.SchemaDefinition == "" {
-		http.Error(w, "Missing required fields: name and schema_definition", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing required fields: name and schema_definition")
 		return
 	}
 
 	component, err := h.Store.UpdateSchemaComponent(id, payload.Name, payload.SchemaDefinition)
 	if err != nil {
 		if err.Error() == fmt.Sprintf("schema component not found with id for update: %s", id) { // Basic check
-			http.Error(w, err.Error(), http.StatusNotFound)
+			respondWithError(w, http.StatusNotFound, err.Error())
 		} else {
 			fmt.Printf("Error updating schema component: %v\n", err) // Replace with proper logging
-			http.Error(w, "Failed to update schema component", http.StatusInternalServerError)
+			respondWithError(w, http.StatusInternalServerError, "Failed to update schema component")
 		}
 		return
 	}
@@ -137,17 +135,17 @@ func (h *SchemaComponentHandler) UpdateSchemaComponent(w http.ResponseWriter, r
 func (h *SchemaComponentHandler) DeleteSchemaComponent(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		http.Error(w, "Missing schema component ID", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing schema component ID")
 		return
 	}
 
 	err := h.Store.DeleteSchemaComponent(id)
 	if err != nil {
 		if err.Error() == fmt.Sprintf("schema component not found with id for deletion: %s", id) { // Basic check
-			http.Error(w, err.Error(), http.StatusNotFound)
+			respondWithError(w, http.StatusNotFound, err.Error())
 		} else {
 			fmt.Printf("Error deleting schema component: %v\n", err) // Replace with proper logging
-			http.Error(w, "Failed to delete schema component", http.StatusInternalServerError)
+			respondWithError(w, http.StatusInternalServerError, "Failed to delete schema component")
 		}
 		return
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql" // Added import
 	"encoding/json"
 	"fmt"
@@ -10,16 +11,22 @@ import (
 	"sync"
 	"time"
 	"webblueprint/internal/bperrors"
+	"webblueprint/internal/circuit"
 	"webblueprint/internal/db" // Added import
 	"webblueprint/internal/nodes"
 
 	// "webblueprint/internal/core" // No longer needed directly
+	"webblueprint/internal/config"
 	"webblueprint/internal/engine"
 	"webblueprint/internal/engineext"
 	"webblueprint/internal/event" // Ensure event is imported
+	"webblueprint/internal/i18n"
+	"webblueprint/internal/locks"
 	"webblueprint/internal/node"
 	"webblueprint/internal/registry"
+	"webblueprint/internal/statestore"
 	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
 	"webblueprint/pkg/repository"
 	"webblueprint/pkg/service"
 
@@ -42,8 +49,16 @@ type APIServerWithDB struct {
 	userService              *service.UserService
 	workspaceService         *service.WorkspaceService
 	executionService         *service.ExecutionService
+	quotaService             *service.QuotaService
+	accessService            *service.AccessService
+	nodePolicyService        *service.NodePolicyService
+	shareLinkService         *service.ShareLinkService
+	deprecationService       *service.DeprecationService
+	lintService              *service.LintService
+	testService              *service.BlueprintTestService
 	eventService             *service.EventService
 	schemaComponentHandler   *SchemaComponentHandler // Added handler
+	config                   *config.Config          // Active configuration, reported when set
 	logger                   node.Logger
 	rw                       *sync.RWMutex
 }
@@ -72,12 +87,54 @@ func NewAPIServerWithDB(
 	)
 
 	userService := service.NewUserService(repoFactory.GetUserRepository())
+
+	// Administrative endpoints check the role of the user and their workspace membership
+	accessService := service.NewAccessService(repoFactory.GetUserRepository(), repoFactory.GetWorkspaceRepository())
 	executionService := service.NewExecutionService(
 		repoFactory.GetExecutionRepository(),
 		repoFactory.GetBlueprintRepository(),
 		executionEngine,
 	)
 
+	// Enforce workspace quotas on executions
+	quotaService := service.NewQuotaService(repoFactory.GetWorkspaceRepository())
+	executionService.SetQuotaService(quotaService)
+
+	// Enforce workspace node policies on saved blueprints and executions
+	nodePolicyService := service.NewNodePolicyService(repoFactory.GetWorkspaceRepository())
+	blueprintService.SetNodePolicyService(nodePolicyService)
+	executionService.SetNodePolicyService(nodePolicyService)
+
+	// Deprecated blueprints run with warnings until their sunset date
+	deprecationService := service.NewDeprecationService(repoFactory.GetDeprecationRepository(), repoFactory.GetBlueprintRepository())
+	executionService.SetDeprecationService(deprecationService)
+
+	// Public links run blueprints on behalf of their creator, rate limited per link
+	shareLinkService := service.NewShareLinkService(repoFactory.GetShareLinkRepository(), blueprintService, executionService, accessService)
+
+	// Lint blueprints against the rules configured for their workspace
+	lintService := service.NewLintService(repoFactory.GetWorkspaceRepository(), repoFactory.GetBlueprintRepository())
+	lintService.SetNodeFactoryLookup(registry.GetInstance().GetNodeFactory)
+
+	// Event blueprints loaded by the engine switch to newly activated versions
+	blueprintService.SetActivationHandler(func(bp *blueprint.Blueprint) {
+		if err := executionEngine.ReloadBlueprint(bp); err != nil {
+			logger.Warn("Failed to rebind the events of the reloaded blueprint", map[string]interface{}{"blueprintId": bp.ID, "error": err.Error()})
+		}
+	})
+	blueprintService.SetDeletionHandler(executionEngine.UnloadBlueprint)
+
+	// Persist debug data evicted from memory when the retention policy asks for it
+	debugManager.SetSpillFunc(executionService.SpillDebugData)
+
+	// Push watch expression updates to the clients that registered them
+	wsManager.SetDebugManager(debugManager)
+
+	// Stream execution events to the clients, batching the values of busy nodes
+	listenerOptions := engine.DefaultListenerOptions()
+	listenerOptions.BatchInterval = engine.DefaultValueBatchInterval
+	executionEngine.AddExecutionListenerWithOptions(NewExecutionEventListener(wsManager), listenerOptions)
+
 	// Pass the blueprint repository to the workspace service
 	workspaceService := service.NewWorkspaceService(
 		repoFactory.GetWorkspaceRepository(),
@@ -87,6 +144,14 @@ func NewAPIServerWithDB(
 	)
 	eventService := service.NewEventService(repoFactory.GetEventRepository())
 
+	// Blueprint tests run on their own engine, apart from recorded executions
+	testService := service.NewBlueprintTestService(
+		repoFactory.GetBlueprintRepository(),
+		repoFactory.GetAssetRepository(),
+		repoFactory.GetTestRunRepository(),
+		logger,
+	)
+
 	// --- Instantiate Schema Component Store and Handler ---
 	if dbConn == nil {
 		panic("Database connection (*sql.DB) is required for APIServerWithDB")
@@ -106,6 +171,27 @@ func NewAPIServerWithDB(
 		panic("Concrete Event Manager not found in engine extensions")
 	}
 
+	// Bindings enabled or disabled at runtime keep their state across restarts
+	if states, err := eventService.GetBindingStates(context.Background()); err != nil {
+		logger.Warn("Failed to load the runtime state of event bindings", map[string]interface{}{"error": err.Error()})
+	} else {
+		for _, state := range states {
+			concreteEventManager.SetBindingEnabled(state.BindingID, state.Enabled)
+		}
+	}
+	// Deliveries of at-least-once bindings are persisted until their handler acknowledges them
+	concreteEventManager.SetDeliveryStore(repoFactory.GetEventRepository())
+
+	// Report batch execution progress to connected clients
+	executionService.OnBatchProgressHook = func(progress service.BatchProgress) {
+		wsManager.BroadcastMessage(MsgTypeBatchStatus, progress)
+	}
+
+	// Notify connected clients about unattended executions of deprecated blueprints
+	executionService.OnDeprecationHook = func(warning service.DeprecationWarning) {
+		wsManager.BroadcastMessage(MsgTypeDeprecation, warning)
+	}
+
 	// Register WebSocket handlers with error manager
 	wsManager.RegisterErrorHandlers(errorManager, wsManager.Logger)
 
@@ -124,6 +210,13 @@ func NewAPIServerWithDB(
 		userService:              userService,
 		workspaceService:         workspaceService,
 		executionService:         executionService,
+		quotaService:             quotaService,
+		accessService:            accessService,
+		nodePolicyService:        nodePolicyService,
+		shareLinkService:         shareLinkService,
+		deprecationService:       deprecationService,
+		lintService:              lintService,
+		testService:              testService,
 		eventService:             eventService,
 		schemaComponentHandler:   schemaComponentHandler, // Assign handler
 		logger:                   logger,
@@ -143,27 +236,51 @@ func (s *APIServerWithDB) RegisterNodeType(typeID string, factory node.NodeFacto
 	// Register with execution engine
 	s.executionEngine.RegisterNodeType(typeID, factory)
 
-	// Create a node instance to get metadata
-	nodeInstance := factory()
-	metadata := nodeInstance.GetMetadata()
-
 	// Broadcast node type to connected clients
-	s.wsManager.BroadcastMessage(MsgTypeNodeIntro, map[string]interface{}{
-		"typeId":      metadata.TypeID,
-		"name":        metadata.Name,
-		"description": metadata.Description,
-		"category":    metadata.Category,
-		"version":     metadata.Version,
-		"inputs":      convertPinsToInfo(nodeInstance.GetInputPins()),
-		"outputs":     convertPinsToInfo(nodeInstance.GetOutputPins()),
-		"properties":  convertPropertiesToInfo(nodeInstance.GetProperties()),
-	})
+	// Connected clients get the English texts; they fetch the catalog in their locale
+	s.wsManager.BroadcastMessage(MsgTypeNodeIntro, nodeTypeInfo(factory(), nil))
+}
+
+// nodeTypeInfo describes a node type to the client, with its texts translated by the
+// localizer. The category stays untranslated since the palette groups by it; its
+// translation is the category name.
+func nodeTypeInfo(nodeInstance node.Node, localizer *i18n.Localizer) map[string]interface{} {
+	metadata := nodeInstance.GetMetadata()
+	typeID := metadata.TypeID
+
+	info := map[string]interface{}{
+		"typeId":       typeID,
+		"name":         localizer.Text(i18n.NodeKey(typeID, "name"), metadata.Name),
+		"description":  localizer.Text(i18n.NodeKey(typeID, "description"), metadata.Description),
+		"category":     metadata.Category,
+		"categoryName": localizer.Text(i18n.CategoryKey(metadata.Category), metadata.Category),
+		"version":      metadata.Version,
+		"inputs":       convertPinsToInfo(nodeInstance.GetInputPins(), localizer, i18n.InputKey, typeID),
+		"outputs":      convertPinsToInfo(nodeInstance.GetOutputPins(), localizer, i18n.OutputKey, typeID),
+		"properties":   convertPropertiesToInfo(nodeInstance.GetProperties(), localizer, typeID),
+		// Editors and accepted values of the properties, checked again when blueprints are validated
+		"propertySchema": node.PropertySchemas(nodeInstance),
+	}
+	if _, dynamic := nodeInstance.(node.DynamicPinNode); dynamic {
+		// The pins of these nodes depend on the data of each blueprint node
+		info["dynamicPins"] = true
+	}
+	if variadic := node.DescribeVariadicPins(nodeInstance); variadic != nil {
+		// Groups of numbered pins whose count is set in the data of each blueprint node
+		info["variadicPins"] = variadic
+	}
+	return info
 }
 
 // SetupRoutes sets up the HTTP routes for the API server
 func (s *APIServerWithDB) SetupRoutes(r *mux.Router) *mux.Router {
-	// WebSocket endpoint
-	r.HandleFunc("/ws", s.wsManager.HandleWebSocket)
+	if s.config != nil {
+		setupCrossOrigin(r, s.config)
+	}
+
+	// WebSocket endpoint and the schema of its messages
+	r.HandleFunc("/ws", s.wsManager.HandleWebSocket).Methods("GET")
+	r.HandleFunc("/api/ws/schema", s.wsManager.HandleSchema).Methods("GET")
 
 	// Create a blueprint handler
 	blueprintHandler := NewBlueprintHandler(s.blueprintService, s.blueprintVariableService)
@@ -175,9 +292,59 @@ func (s *APIServerWithDB) SetupRoutes(r *mux.Router) *mux.Router {
 	workspaceHandler := NewWorkspaceHandler(s.workspaceService)
 	workspaceHandler.RegisterRoutes(r)
 
-	executionHandler := NewExecutionHandler(s.executionService)
+	executionHandler := NewExecutionHandler(s.executionService, s.accessService)
 	executionHandler.RegisterRoutes(r)
 
+	quotaHandler := NewQuotaHandler(s.quotaService, s.accessService)
+	quotaHandler.RegisterRoutes(r)
+
+	nodePolicyHandler := NewNodePolicyHandler(s.nodePolicyService, s.accessService)
+	nodePolicyHandler.RegisterRoutes(r)
+
+	shareLinkHandler := NewShareLinkHandler(s.shareLinkService)
+	shareLinkHandler.RegisterRoutes(r)
+
+	deprecationHandler := NewDeprecationHandler(s.deprecationService)
+	deprecationHandler.RegisterRoutes(r)
+
+	engineSnapshotHandler := NewEngineSnapshotHandler(s.executionService, s.accessService)
+	engineSnapshotHandler.RegisterRoutes(r)
+
+	lintHandler := NewLintHandler(s.lintService, s.blueprintService)
+	lintHandler.RegisterRoutes(r)
+
+	testHandler := NewBlueprintTestHandler(s.testService)
+	testHandler.RegisterRoutes(r)
+
+	debugHandler := NewDebugHandler(s.debugManager)
+	debugHandler.RegisterRoutes(r)
+
+	circuitHandler := NewCircuitHandler(circuit.Default())
+	circuitHandler.RegisterRoutes(r)
+
+	stateHandler := NewStateHandler(statestore.Default())
+	stateHandler.RegisterRoutes(r)
+
+	lockHandler := NewLockHandler(locks.Default())
+	lockHandler.RegisterRoutes(r)
+
+	listenerHandler := NewListenerHandler(s.executionEngine)
+	listenerHandler.RegisterRoutes(r)
+
+	autoscaleHandler := NewAutoscaleHandler(s.executionService, s.config != nil && s.config.Autoscale.ExternalMetrics)
+	autoscaleHandler.RegisterRoutes(r)
+
+	crashReportHandler := NewCrashReportHandler(s.repoFactory.GetCrashReportRepository())
+	crashReportHandler.RegisterRoutes(r)
+
+	if s.config != nil {
+		configHandler := NewConfigHandler(s.config)
+		configHandler.RegisterRoutes(r)
+	}
+
+	localeHandler := NewLocaleHandler()
+	localeHandler.RegisterRoutes(r)
+
 	// API endpoints that aren't handled by the blueprint handler
 	api := r.PathPrefix("/api").Subrouter()
 
@@ -263,23 +430,10 @@ func (s *APIServerWithDB) setupEventAPI(router *mux.Router) {
 
 func (s *APIServerWithDB) handleGetNodeTypes(w http.ResponseWriter, request *http.Request) {
 	nodeTypes := make([]map[string]interface{}, 0)
+	localizer := i18n.Default().Localizer(i18n.FromContext(request.Context()))
 
 	for _, factory := range registry.GetInstance().GetAllNodeFactories() {
-		_node := factory()
-		metadata := _node.GetMetadata()
-
-		nodeType := map[string]interface{}{
-			"typeId":      metadata.TypeID,
-			"name":        metadata.Name,
-			"description": metadata.Description,
-			"category":    metadata.Category,
-			"version":     metadata.Version,
-			"inputs":      convertPinsToInfo(_node.GetInputPins()),
-			"outputs":     convertPinsToInfo(_node.GetOutputPins()),
-			"properties":  convertPropertiesToInfo(_node.GetProperties()),
-		}
-
-		nodeTypes = append(nodeTypes, nodeType)
+		nodeTypes = append(nodeTypes, nodeTypeInfo(factory(), localizer))
 	}
 
 	respondWithJSON(w, http.StatusOK, nodeTypes)
@@ -316,15 +470,96 @@ func (s *APIServerWithDB) ListenRuntimeNodes() {
 	}
 }
 
-// convertPinsToInfo converts pins to a format suitable for the client
-func convertPinsToInfo(pins []types.Pin) []map[string]interface{} {
+// SetConfig sets the active configuration, which is reported on /api/config
+func (s *APIServerWithDB) SetConfig(cfg *config.Config) {
+	s.config = cfg
+}
+
+// SetTrashRetention sets how long deleted blueprints stay in the trash before they're purged
+func (s *APIServerWithDB) SetTrashRetention(retention time.Duration) {
+	s.blueprintService.SetTrashRetention(retention)
+}
+
+// PurgeTrash purges the blueprints past their trash retention at every interval. It
+// blocks, so it should be run in a goroutine.
+func (s *APIServerWithDB) PurgeTrash(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.blueprintService.PurgeTrash(context.Background())
+		if err != nil {
+			log.Printf("Error purging blueprint trash: %v", err)
+		} else if purged > 0 {
+			s.logger.Info(fmt.Sprintf("Purged %d blueprints from the trash", purged), map[string]interface{}{
+				"purged": purged,
+			})
+		}
+
+		<-ticker.C
+	}
+}
+
+// RestoreTimers schedules the debounce and throttle activations that were pending when the
+// server stopped, loading their blueprints into the engine
+func (s *APIServerWithDB) RestoreTimers(ctx context.Context) {
+	timers := s.engineExtensions.GetTimerService()
+	if timers == nil {
+		return
+	}
+
+	restored, err := timers.Restore(ctx, func(blueprintID string) error {
+		bp, err := s.blueprintService.GetBlueprint(ctx, blueprintID)
+		if err != nil {
+			return err
+		}
+		return s.executionEngine.LoadBlueprint(bp)
+	})
+	if err != nil {
+		log.Printf("Error restoring node timers: %v", err)
+	}
+	if restored > 0 {
+		s.logger.Info(fmt.Sprintf("Restored %d pending node timers", restored), map[string]interface{}{
+			"restored": restored,
+		})
+	}
+}
+
+// RestoreDeliveries schedules the retries of the event deliveries that were pending when
+// the server stopped, loading the blueprints of their handlers into the engine
+func (s *APIServerWithDB) RestoreDeliveries(ctx context.Context) {
+	eventManager := s.engineExtensions.GetConcreteEventManager()
+	if eventManager == nil {
+		return
+	}
+
+	restored, err := eventManager.RestoreDeliveries(ctx, func(blueprintID string) error {
+		bp, err := s.blueprintService.GetBlueprint(ctx, blueprintID)
+		if err != nil {
+			return err
+		}
+		return s.executionEngine.LoadBlueprint(bp)
+	})
+	if err != nil {
+		log.Printf("Error restoring event deliveries: %v", err)
+	}
+	if restored > 0 {
+		s.logger.Info(fmt.Sprintf("Restored %d pending event deliveries", restored), map[string]interface{}{
+			"restored": restored,
+		})
+	}
+}
+
+// convertPinsToInfo converts pins to a format suitable for the client, with the labels of
+// the pins of the node type translated by the localizer
+func convertPinsToInfo(pins []types.Pin, localizer *i18n.Localizer, key func(typeID, pinID, field string) string, typeID string) []map[string]interface{} {
 	result := make([]map[string]interface{}, len(pins))
 
 	for i, pin := range pins {
 		result[i] = map[string]interface{}{
 			"id":          pin.ID,
-			"name":        pin.Name,
-			"description": pin.Description,
+			"name":        localizer.Text(key(typeID, pin.ID, "name"), pin.Name),
+			"description": localizer.Text(key(typeID, pin.ID, "description"), pin.Description),
 			"type": map[string]string{
 				"id":          pin.Type.ID,
 				"name":        pin.Type.Name,
@@ -341,7 +576,7 @@ func convertPinsToInfo(pins []types.Pin) []map[string]interface{} {
 	return result
 }
 
-func convertPropertiesToInfo(pins []types.Property) []map[string]interface{} {
+func convertPropertiesToInfo(pins []types.Property, localizer *i18n.Localizer, typeID string) []map[string]interface{} {
 	result := make([]map[string]interface{}, len(pins))
 	defaultType := map[string]string{
 		"id":          types.PinTypes.Any.ID,
@@ -361,8 +596,8 @@ func convertPropertiesToInfo(pins []types.Property) []map[string]interface{} {
 
 		result[i] = map[string]interface{}{
 			"name":        pin.Name,
-			"displayName": pin.DisplayName,
-			"description": pin.Description,
+			"displayName": localizer.Text(i18n.PropertyKey(typeID, pin.Name, "displayName"), pin.DisplayName),
+			"description": localizer.Text(i18n.PropertyKey(typeID, pin.Name, "description"), pin.Description),
 			"value":       pin.Value,
 			"type":        defaultType,
 		}
@@ -383,7 +618,3 @@ func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
 		return
 	}
 }
-
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
-}
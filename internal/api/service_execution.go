@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// startServiceRequest is the body of a request running a blueprint as a service
+type startServiceRequest struct {
+	Variables         map[string]interface{} `json:"variables"`
+	MaxRestarts       int                    `json:"maxRestarts"`       // Default if 0, unlimited if negative
+	RestartDelayMs    int                    `json:"restartDelayMs"`    // Delay before the first restart
+	MaxRestartDelayMs int                    `json:"maxRestartDelayMs"` // Cap of the delay between restarts
+	IgnoreSunset      bool                   `json:"ignoreSunset"`      // Run, and restart, a deprecated blueprint past its sunset date
+}
+
+// Validate checks that the restart delays aren't negative
+func (s *startServiceRequest) Validate() error {
+	if s.RestartDelayMs < 0 {
+		return fieldError("restartDelayMs", "Restart delay must not be negative")
+	}
+	if s.MaxRestartDelayMs < 0 {
+		return fieldError("maxRestartDelayMs", "Maximum restart delay must not be negative")
+	}
+	return nil
+}
+
+// handleStartService runs a blueprint as a service until it's stopped
+func (h *ExecutionHandler) handleStartService(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var request startServiceRequest
+	if !decodeOptionalRequest(w, r, &request) {
+		return
+	}
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	ctx := r.Context()
+	if request.IgnoreSunset {
+		ctx = service.WithSunsetOverride(ctx)
+	}
+
+	started, err := h.executionService.StartService(ctx, id, request.Variables, userID, service.ServiceOptions{
+		MaxRestarts:     request.MaxRestarts,
+		RestartDelay:    time.Duration(request.RestartDelayMs) * time.Millisecond,
+		MaxRestartDelay: time.Duration(request.MaxRestartDelayMs) * time.Millisecond,
+	})
+	if errors.Is(err, service.ErrServiceRunning) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if respondWithQuotaError(w, err) || respondWithNodePolicyError(w, err) || respondWithSunsetError(w, err) ||
+		respondWithBlueprintRunningError(w, err) || respondWithTriggerSkipped(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error starting service: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, started)
+}
+
+// handleStopService stops a blueprint running as a service, waiting for its execution to end
+func (h *ExecutionHandler) handleStopService(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	stopped, err := h.executionService.StopService(r.Context(), id)
+	if errors.Is(err, service.ErrServiceNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error stopping service: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stopped)
+}
+
+// handleGetService gets the status of the service of a blueprint
+func (h *ExecutionHandler) handleGetService(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	status, err := h.executionService.GetService(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// handleListServices lists the services started on this server with their status
+func (h *ExecutionHandler) handleListServices(w http.ResponseWriter, r *http.Request) {
+	services := h.executionService.ListServices()
+
+	running := 0
+	for _, status := range services {
+		if status.Status == service.ServiceStatusRunning || status.Status == service.ServiceStatusRestarting {
+			running++
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"services": services,
+		"running":  running,
+	})
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"webblueprint/pkg/service"
+
+	"github.com/gorilla/mux"
+)
+
+// ShareLinkHandler handles the management of share links and the requests of their tokens,
+// which don't need an account
+type ShareLinkHandler struct {
+	shareLinkService *service.ShareLinkService
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(shareLinkService *service.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareLinkService: shareLinkService,
+	}
+}
+
+// RegisterRoutes registers all share link routes
+func (h *ShareLinkHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/blueprints/{id}/share-links", h.handleGetLinks).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/share-links", h.handleCreateLink).Methods("POST")
+	router.HandleFunc("/api/blueprints/{id}/share-links/{linkId}", h.handleRevokeLink).Methods("DELETE")
+
+	router.HandleFunc("/api/shared/{token}", h.handleGetShared).Methods("GET")
+	router.HandleFunc("/api/shared/{token}/run", h.handleRunShared).Methods("POST")
+	router.HandleFunc("/api/shared/{token}/executions/{executionId}", h.handleGetSharedExecution).Methods("GET")
+}
+
+// handleGetLinks gets the share links of a blueprint for a user who may edit it
+func (h *ShareLinkHandler) handleGetLinks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	links, err := h.shareLinkService.GetLinks(r.Context(), id, getUserIDFromRequest(r))
+	if respondWithPermissionError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving share links: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, links)
+}
+
+// handleCreateLink creates a share link for a blueprint. The token is only part of this
+// response.
+func (h *ShareLinkHandler) handleCreateLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var options service.ShareLinkOptions
+	if !decodeRequest(w, r, &options) {
+		return
+	}
+
+	link, token, err := h.shareLinkService.CreateLink(r.Context(), id, userID, options)
+	if respondWithPermissionError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error creating share link: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"link":  link,
+		"token": token,
+		"url":   "/api/shared/" + token,
+	})
+}
+
+// handleRevokeLink revokes a share link of a blueprint
+func (h *ShareLinkHandler) handleRevokeLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	linkID := vars["linkId"]
+
+	userID := getUserIDFromRequest(r)
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	err := h.shareLinkService.RevokeLink(r.Context(), id, linkID, userID)
+	if respondWithPermissionError(w, err) || respondWithShareLinkError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error revoking share link: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Share link revoked successfully",
+	})
+}
+
+// handleGetShared gets the blueprint of a share token, read-only
+func (h *ShareLinkHandler) handleGetShared(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+
+	link, bp, err := h.shareLinkService.GetSharedBlueprint(r.Context(), token)
+	if respondWithShareLinkError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving shared blueprint: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"blueprint":  bp,
+		"permission": link.Permission,
+		"expiresAt":  link.ExpiresAt,
+	})
+}
+
+// handleRunShared executes the blueprint of a share token with the variables of the body
+func (h *ShareLinkHandler) handleRunShared(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+
+	var request struct {
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		// If body can't be parsed, use empty variables
+		request.Variables = make(map[string]interface{})
+	}
+
+	executionID, err := h.shareLinkService.RunShared(r.Context(), token, request.Variables)
+	if respondWithShareLinkError(w, err) || respondWithQuotaError(w, err) || respondWithNodePolicyError(w, err) ||
+		respondWithSunsetError(w, err) || respondWithBlueprintRunningError(w, err) || respondWithTriggerSkipped(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error executing shared blueprint: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{
+		"executionId": executionID,
+		"status":      "running",
+	})
+}
+
+// handleGetSharedExecution gets the status and result of an execution started through the
+// link of a share token
+func (h *ShareLinkHandler) handleGetSharedExecution(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+	executionID := vars["executionId"]
+
+	execution, err := h.shareLinkService.GetSharedExecution(r.Context(), token, executionID)
+	if respondWithShareLinkError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("Execution not found: %v", err))
+		return
+	}
+
+	// Only the outcome is shared, not the variables the execution started with
+	response := map[string]interface{}{
+		"executionId": execution.ID,
+		"status":      execution.Status,
+		"startedAt":   execution.StartedAt,
+		"result":      execution.Result,
+	}
+	if execution.CompletedAt.Valid {
+		response["completedAt"] = execution.CompletedAt.Time
+	}
+	if execution.Error.Valid {
+		response["error"] = execution.Error.String
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// respondWithShareLinkError writes an unknown share link as 404, an expired or revoked one
+// as 410 and a run through a view link as 403. It returns false if err isn't one of these.
+func respondWithShareLinkError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrShareLinkNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrShareLinkInactive):
+		respondWithError(w, http.StatusGone, err.Error())
+	case errors.Is(err, service.ErrShareLinkViewOnly):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"webblueprint/internal/statestore"
+
+	"github.com/gorilla/mux"
+)
+
+// StateHandler exposes the values blueprints keep across executions
+type StateHandler struct {
+	store *statestore.Store
+}
+
+// NewStateHandler creates a new state handler
+func NewStateHandler(store *statestore.Store) *StateHandler {
+	return &StateHandler{
+		store: store,
+	}
+}
+
+// RegisterRoutes registers all state-related routes
+func (h *StateHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/blueprints/{id}/state", h.handleGetState).Methods("GET")
+	router.HandleFunc("/api/blueprints/{id}/state/{key}", h.handleDeleteState).Methods("DELETE")
+}
+
+// handleGetState gets the state values of a blueprint with their versions
+func (h *StateHandler) handleGetState(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	entries, err := h.store.List(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving blueprint state: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entries)
+}
+
+// handleDeleteState resets a state value of a blueprint, e.g. a cursor to start over
+// from. A version query parameter only deletes the value at that version.
+func (h *StateHandler) handleDeleteState(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	key := vars["key"]
+
+	expectedVersion := statestore.AnyVersion
+	if version := r.URL.Query().Get("version"); version != "" {
+		parsed, err := strconv.ParseInt(version, 10, 64)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid version")
+			return
+		}
+		expectedVersion = parsed
+	}
+
+	err := h.store.Delete(r.Context(), id, key, expectedVersion)
+	var conflict *statestore.ConflictError
+	if errors.As(err, &conflict) {
+		respondWithError(w, http.StatusConflict, conflict.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error deleting blueprint state: %v", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/json"
 	"net/http"
 	errors "webblueprint/internal/bperrors"
 
@@ -37,14 +36,13 @@ func (h *TestErrorHandler) HandleGenerateTestError(w http.ResponseWriter, r *htt
 		Recoverable bool   `json:"recoverable"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate minimum required fields
 	if req.ExecutionID == "" {
-		http.Error(w, "Missing executionId", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing executionId")
 		return
 	}
 
@@ -123,19 +121,18 @@ func (h *TestErrorHandler) HandleGenerateErrorScenario(w http.ResponseWriter, r
 		ScenarioType string `json:"scenarioType"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate request
 	if req.ExecutionID == "" {
-		http.Error(w, "Missing executionId", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing executionId")
 		return
 	}
 
 	if req.ScenarioType == "" {
-		http.Error(w, "Missing scenarioType", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Missing scenarioType")
 		return
 	}
 
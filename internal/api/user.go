@@ -1,9 +1,9 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"webblueprint/pkg/service"
 
 	"github.com/gorilla/mux"
@@ -54,18 +54,32 @@ func (h *UserHandler) handleGetUser(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, user)
 }
 
+// createUserRequest is the body of a new user
+type createUserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	FullName string `json:"fullName"`
+}
+
+// Validate checks that the user has a username, an email address and a password
+func (c *createUserRequest) Validate() error {
+	switch {
+	case strings.TrimSpace(c.Username) == "":
+		return fieldError("username", "Username is required")
+	case !strings.Contains(c.Email, "@"):
+		return fieldError("email", "A valid email address is required")
+	case c.Password == "":
+		return fieldError("password", "Password is required")
+	}
+	return nil
+}
+
 // handleCreateUser creates a new user
 func (h *UserHandler) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 	// Parse the user from request body
-	var request struct {
-		Username string `json:"username"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-		FullName string `json:"fullName"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid user format")
+	var request createUserRequest
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -109,8 +123,7 @@ func (h *UserHandler) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid user format")
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -171,8 +184,7 @@ func (h *UserHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid login format")
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"strconv"
 	"sync"
 	"time"
 	"webblueprint/internal/bperrors"
 	"webblueprint/internal/engine"
+	"webblueprint/internal/logsink"
+	"webblueprint/internal/types"
 
 	"github.com/gorilla/websocket"
 )
@@ -22,6 +24,9 @@ type WebSocketManager struct {
 	broadcast  chan []byte
 	mutex      sync.RWMutex
 	Logger     Logger // Logger interface for error handling
+
+	debugManager *engine.DebugManager // Evaluates watch expressions of clients
+	events       eventBuffer          // Recent broadcast messages for reconnecting clients
 }
 
 // WebSocketClient represents a connected WebSocket client
@@ -30,12 +35,15 @@ type WebSocketClient struct {
 	conn     *websocket.Conn
 	send     chan []byte
 	clientID string
+	joinedAt uint64 // Sequence number of the latest broadcast message before the client connected
 }
 
 // WebSocketMessage represents a message sent over WebSocket
 type WebSocketMessage struct {
 	Type    string          `json:"type"`
+	Version int             `json:"version,omitempty"` // Protocol version the message is encoded in
 	Payload json.RawMessage `json:"payload"`
+	Seq     uint64          `json:"seq,omitempty"` // Number of broadcast messages, used for resume tokens
 }
 
 // Message types
@@ -44,15 +52,39 @@ const (
 	MsgTypeNodeStart    = "node.start"       // Node execution started
 	MsgTypeNodeComplete = "node.complete"    // Node execution completed
 	MsgTypeNodeError    = "node.error"       // Node execution error
+	MsgTypeNodeRecovery = "node.recovered"   // Node error recovered
 	MsgTypeDataFlow     = "data.flow"        // Data flowing between nodes
+	MsgTypeDataBatch    = "data.batch"       // Latest data of a node's pins, batched
+	MsgTypeFidelity     = "values.fidelity"  // Full fidelity of a node's values changed
 	MsgTypeDebugData    = "debug.data"       // Debug data available
 	MsgTypeExecStart    = "execution.start"  // Blueprint execution started
 	MsgTypeExecEnd      = "execution.end"    // Blueprint execution ended
 	MsgTypeExecStatus   = "execution.status" // Execution status update
 	MsgTypeResult       = "result"           // Pin output value
 	MsgTypeLog          = "log"              // Log message
+	MsgTypeBatchStatus  = "batch.status"     // Batch execution progress
+	MsgTypeDeprecation  = "deprecation.warn" // Execution attempt of a deprecated blueprint
+	MsgTypeWatchAdded   = "watch.added"      // Watch expression registered
+	MsgTypeWatchRemoved = "watch.removed"    // Watch expression removed
+	MsgTypeWatchUpdate  = "watch.update"     // Watched value changed
+	MsgTypeWatchError   = "watch.error"      // Watch expression rejected
+	MsgTypeResumed      = "events.resumed"   // Missed broadcast messages replayed
 )
 
+// fidelityRequest is the payload of values.fidelity messages, asking for every value a node
+// produces instead of batches while debugging it
+type fidelityRequest struct {
+	NodeID string `json:"nodeId"`
+	Full   bool   `json:"full"`
+}
+
+// watchRequest is the payload of watch.add and watch.remove messages
+type watchRequest struct {
+	ExecutionID string `json:"executionId"`
+	Expression  string `json:"expression,omitempty"`
+	WatchID     string `json:"watchId,omitempty"`
+}
+
 // HTTP connection upgrader
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
@@ -91,7 +123,14 @@ func (h *WebSocketManager) run() {
 				delete(h.clients, client.clientID)
 				close(client.send)
 			}
+			debugManager := h.debugManager
 			h.mutex.Unlock()
+
+			// Watches and full fidelity requests apply to their client only
+			if debugManager != nil {
+				debugManager.RemoveWatchesByOwner(client.clientID)
+				debugManager.RemoveFullFidelityByOwner(client.clientID)
+			}
 			log.Printf("Client disconnected: %s", client.clientID)
 
 		case message := <-h.broadcast:
@@ -112,6 +151,12 @@ func (h *WebSocketManager) run() {
 
 // HandleWebSocket handles a new WebSocket connection
 func (h *WebSocketManager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Clients asking for a protocol version the server doesn't speak are turned away
+	if _, err := requestedVersion(r); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Upgrade HTTP connection to WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -128,8 +173,9 @@ func (h *WebSocketManager) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 		clientID: clientID,
 	}
 
-	// Register client
+	// Register client; messages broadcast after joinedAt are sent to it
 	h.register <- client
+	client.joinedAt = h.events.head()
 
 	// Start client handlers
 	go client.readPump()
@@ -137,8 +183,10 @@ func (h *WebSocketManager) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 
 	// Send welcome message
 	client.sendMessage(MsgTypeExecStatus, map[string]interface{}{
-		"status":  "connected",
-		"message": "WebSocket connection established",
+		"status":          "connected",
+		"message":         "WebSocket connection established",
+		"resumeToken":     strconv.FormatUint(client.joinedAt, 10),
+		"protocolVersion": ProtocolVersion,
 	})
 }
 
@@ -150,18 +198,74 @@ func (h *WebSocketManager) BroadcastMessage(messageType string, payload interfac
 		return
 	}
 
-	msg := WebSocketMessage{
-		Type:    messageType,
-		Payload: data,
+	err = h.events.add(messageExecutionID(data), func(seq uint64) ([]byte, error) {
+		return encodeMessage(messageType, data, seq)
+	}, func(msgData []byte) {
+		h.broadcast <- msgData
+	})
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
 	}
+}
 
-	msgData, err := json.Marshal(msg)
+// SetDebugManager enables watch expressions, pushing their updates to the clients that registered them
+func (h *WebSocketManager) SetDebugManager(debugManager *engine.DebugManager) {
+	h.mutex.Lock()
+	h.debugManager = debugManager
+	h.mutex.Unlock()
+
+	debugManager.SetWatchListener(func(update engine.WatchUpdate) {
+		h.SendToClient(update.Owner, MsgTypeWatchUpdate, update)
+	})
+}
+
+// SendToClient sends a message to a single client, dropping it if the client is gone or too slow
+func (h *WebSocketManager) SendToClient(clientID, messageType string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling message payload: %v", err)
+		return
+	}
+
+	msgData, err := encodeMessage(messageType, data, 0)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
 
-	h.broadcast <- msgData
+	// Holding the read lock keeps the send channel from being closed
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	client, exists := h.clients[clientID]
+	if !exists {
+		return
+	}
+
+	select {
+	case client.send <- msgData:
+	default:
+		log.Printf("Dropping %s message for slow client %s", messageType, clientID)
+	}
+}
+
+// sendRaw sends an encoded message to a single client. It reports whether the message was
+// queued, which fails if the client is gone or too slow.
+func (h *WebSocketManager) sendRaw(clientID string, msgData []byte) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	client, exists := h.clients[clientID]
+	if !exists {
+		return false
+	}
+
+	select {
+	case client.send <- msgData:
+		return true
+	default:
+		return false
+	}
 }
 
 // SendErrorNotification sends an error notification to clients
@@ -226,25 +330,102 @@ func (c *WebSocketClient) readPump() {
 			break
 		}
 
-		// Parse the message
-		var wsMsg WebSocketMessage
-		if err := json.Unmarshal(message, &wsMsg); err != nil {
-			log.Printf("Error parsing message: %v", err)
-			continue
-		}
+		c.handleMessage(message)
+	}
+}
 
-		// Handle message based on type
-		// This will be expanded as we add more message handlers
-		switch wsMsg.Type {
-		case "blueprint.execute":
-			// Handle blueprint execution request
-			log.Printf("Blueprint execution requested")
+// handleWatchAdd registers a watch expression for the client
+func (c *WebSocketClient) handleWatchAdd(payload json.RawMessage) {
+	request, debugManager, ok := c.parseWatchRequest(payload)
+	if !ok {
+		return
+	}
 
-		case "node.inspect":
-			// Handle node inspection request
-			log.Printf("Node inspection requested")
+	watch, err := debugManager.AddWatch(request.ExecutionID, c.clientID, request.Expression)
+	if err != nil {
+		c.manager.SendToClient(c.clientID, MsgTypeWatchError, map[string]interface{}{
+			"executionId": request.ExecutionID,
+			"expression":  request.Expression,
+			"error":       err.Error(),
+		})
+		return
+	}
+
+	c.manager.SendToClient(c.clientID, MsgTypeWatchAdded, watch)
+	debugManager.PushWatch(request.ExecutionID, watch.ID)
+}
+
+// handleWatchRemove removes a watch expression of the client
+func (c *WebSocketClient) handleWatchRemove(payload json.RawMessage) {
+	request, debugManager, ok := c.parseWatchRequest(payload)
+	if !ok {
+		return
+	}
+
+	for _, watch := range debugManager.GetWatches(request.ExecutionID) {
+		if watch.ID == request.WatchID && watch.Owner == c.clientID {
+			debugManager.RemoveWatch(request.ExecutionID, request.WatchID)
+			c.manager.SendToClient(c.clientID, MsgTypeWatchRemoved, request)
+			return
 		}
 	}
+
+	c.manager.SendToClient(c.clientID, MsgTypeWatchError, map[string]interface{}{
+		"executionId": request.ExecutionID,
+		"watchId":     request.WatchID,
+		"error":       "watch not found",
+	})
+}
+
+// handleFidelity turns full fidelity of a node's values on or off for the client
+func (c *WebSocketClient) handleFidelity(payload json.RawMessage) {
+	var request fidelityRequest
+	if err := json.Unmarshal(payload, &request); err != nil || request.NodeID == "" {
+		c.manager.SendToClient(c.clientID, MsgTypeFidelity, map[string]interface{}{
+			"error": "nodeId is required",
+		})
+		return
+	}
+
+	c.manager.mutex.RLock()
+	debugManager := c.manager.debugManager
+	c.manager.mutex.RUnlock()
+
+	if debugManager == nil {
+		c.manager.SendToClient(c.clientID, MsgTypeFidelity, map[string]interface{}{
+			"nodeId": request.NodeID,
+			"error":  "full fidelity is not available",
+		})
+		return
+	}
+
+	debugManager.SetFullFidelity(c.clientID, request.NodeID, request.Full)
+	c.manager.SendToClient(c.clientID, MsgTypeFidelity, request)
+}
+
+// parseWatchRequest decodes a watch message, reporting invalid requests to the client
+func (c *WebSocketClient) parseWatchRequest(payload json.RawMessage) (watchRequest, *engine.DebugManager, bool) {
+	var request watchRequest
+	if err := json.Unmarshal(payload, &request); err != nil || request.ExecutionID == "" {
+		c.manager.SendToClient(c.clientID, MsgTypeWatchError, map[string]interface{}{
+			"error": "executionId is required",
+		})
+		return request, nil, false
+	}
+
+	c.manager.mutex.RLock()
+	debugManager := c.manager.debugManager
+	c.manager.mutex.RUnlock()
+
+	if debugManager == nil {
+		c.manager.SendToClient(c.clientID, MsgTypeWatchError, map[string]interface{}{
+			"executionId": request.ExecutionID,
+			"error":       "watch expressions are not available",
+		})
+		return request, nil, false
+	}
+
+	return request, debugManager, true
 }
 
 // writePump pumps messages to the WebSocket connection
@@ -300,12 +481,7 @@ func (c *WebSocketClient) sendMessage(messageType string, payload interface{}) {
 		return
 	}
 
-	msg := WebSocketMessage{
-		Type:    messageType,
-		Payload: data,
-	}
-
-	msgData, err := json.Marshal(msg)
+	msgData, err := encodeMessage(messageType, data, 0)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
@@ -316,22 +492,29 @@ func (c *WebSocketClient) sendMessage(messageType string, payload interface{}) {
 
 // Create a WebSocket logger that sends logs to clients
 type WebSocketLogger struct {
-	wsManager        *WebSocketManager
-	persistentLogger *os.File
-	nodeID           string
-	mu               sync.RWMutex
+	wsManager *WebSocketManager
+	sink      logsink.Sink // Forwards the logs as structured entries, if set
+	nodeID    string
+	mu        sync.RWMutex
 }
 
 // NewWebSocketLogger creates a new logger that sends logs via WebSocket
 func NewWebSocketLogger(wsManager *WebSocketManager) *WebSocketLogger {
-	f, err := os.OpenFile("./log.out", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatalf("error opening file: %v, skipping persistent logger", err)
+	return &WebSocketLogger{
+		wsManager: wsManager,
 	}
+}
 
-	return &WebSocketLogger{
-		wsManager:        wsManager,
-		persistentLogger: f,
+// SetSink sets the sink the logs are forwarded to besides the clients, closing the
+// previous one
+func (l *WebSocketLogger) SetSink(sink logsink.Sink) {
+	l.mu.Lock()
+	previous := l.sink
+	l.sink = sink
+	l.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
 	}
 }
 
@@ -376,15 +559,23 @@ func (l *WebSocketLogger) sendLogMessage(level, msg string, fields map[string]in
 	}
 	fmt.Printf("[%s] %s: %s %v\n", level, l.nodeID, msg, fields)
 
-	if l.persistentLogger != nil {
-		l.persistentLogger.Write([]byte(msg))
-		l.persistentLogger.Write([]byte{'\n'})
+	now := time.Now()
+	if l.sink != nil {
+		if err := l.sink.Write(logsink.Entry{
+			Time:    now,
+			Level:   level,
+			NodeID:  l.nodeID,
+			Message: msg,
+			Fields:  fields,
+		}); err != nil {
+			fmt.Printf("[warn] failed to forward log entry: %v\n", err)
+		}
 	}
 
 	// Then broadcast via WebSocket
 	l.wsManager.BroadcastMessage(MsgTypeLog, map[string]interface{}{
 		"level":     level,
-		"timestamp": time.Now().Format(time.RFC3339Nano),
+		"timestamp": now.Format(time.RFC3339Nano),
 		"nodeId":    l.nodeID,
 		"message":   msg,
 		"fields":    fields,
@@ -392,7 +583,13 @@ func (l *WebSocketLogger) sendLogMessage(level, msg string, fields map[string]in
 }
 
 func (l *WebSocketLogger) Close() error {
-	return l.persistentLogger.Close()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sink == nil {
+		return nil
+	}
+	return l.sink.Close()
 }
 
 // ExecutionEventListener implements the engine.ExecutionListener interface
@@ -419,8 +616,12 @@ func (l *ExecutionEventListener) OnExecutionEvent(event engine.ExecutionEvent) {
 		msgType = MsgTypeNodeComplete
 	case engine.EventNodeError:
 		msgType = MsgTypeNodeError
+	case engine.EventNodeRecovered:
+		msgType = MsgTypeNodeRecovery
 	case engine.EventValueProduced:
 		msgType = MsgTypeDataFlow
+	case engine.EventValuesBatched:
+		msgType = MsgTypeDataBatch
 	case engine.EventExecutionStart:
 		msgType = MsgTypeExecStart
 	case engine.EventExecutionEnd:
@@ -434,7 +635,8 @@ func (l *ExecutionEventListener) OnExecutionEvent(event engine.ExecutionEvent) {
 	// Create payload with timestamp
 	payload := make(map[string]interface{})
 	for k, v := range event.Data {
-		payload[k] = v
+		// Large binary values are truncated and base64 encoded
+		payload[k] = types.DebugValue(v)
 	}
 	payload["timestamp"] = event.Timestamp.Format(time.RFC3339Nano)
 	if event.NodeID != "" {
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Versions of the WebSocket protocol. Every message the server sends carries the version it
+// is encoded in. Clients pick theirs with ?version= when connecting, and client messages
+// without a version are read as MinProtocolVersion.
+const (
+	ProtocolVersion    = 1 // Version of the messages the server sends
+	MinProtocolVersion = 1 // Oldest version the server still accepts from clients
+)
+
+// MsgTypeProtocolError is sent to a client whose message the server can't handle
+const MsgTypeProtocolError = "protocol.error"
+
+// Codes of protocol.error messages
+const (
+	ProtocolErrorMalformed          = "malformed"           // The message isn't a JSON envelope
+	ProtocolErrorUnsupportedVersion = "unsupported_version" // The envelope's version isn't supported
+	ProtocolErrorUnknownType        = "unknown_type"        // No client message has the envelope's type
+	ProtocolErrorInvalidPayload     = "invalid_payload"     // The payload doesn't match its schema
+)
+
+// Directions of message types
+const (
+	DirectionServer = "server" // Sent by the server
+	DirectionClient = "client" // Sent by clients
+)
+
+// MessageSchema describes a message type of the WebSocket protocol
+type MessageSchema struct {
+	Type        string        `json:"type"`
+	Direction   string        `json:"direction"`
+	Description string        `json:"description"`
+	Since       int           `json:"since"`             // Protocol version that introduced the type
+	Payload     []FieldSchema `json:"payload,omitempty"` // Fields of the payload, checked for client messages
+}
+
+// FieldSchema describes a field of a message payload
+type FieldSchema struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // JSON type: "string", "boolean", "number", "array" or "object"
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProtocolSchema is the schema document of the WebSocket protocol
+type ProtocolSchema struct {
+	Version    int             `json:"version"`
+	MinVersion int             `json:"minVersion"`
+	Envelope   []FieldSchema   `json:"envelope"`
+	Messages   []MessageSchema `json:"messages"`
+}
+
+// envelopeSchema describes the fields every message has
+var envelopeSchema = []FieldSchema{
+	{Name: "type", Type: "string", Required: true, Description: "Message type"},
+	{Name: "version", Type: "number", Description: "Protocol version the message is encoded in"},
+	{Name: "payload", Type: "object", Description: "Payload, whose fields depend on the type"},
+	{Name: "seq", Type: "number", Description: "Number of a broadcast message, used as resume token"},
+}
+
+// serverMessages describes the messages the server sends
+var serverMessages = []MessageSchema{
+	{Type: MsgTypeNodeIntro, Description: "Node type introduction"},
+	{Type: MsgTypeNodeStart, Description: "Node execution started"},
+	{Type: MsgTypeNodeComplete, Description: "Node execution completed"},
+	{Type: MsgTypeNodeError, Description: "Node execution error"},
+	{Type: MsgTypeNodeRecovery, Description: "Node error recovered"},
+	{Type: MsgTypeDataFlow, Description: "Data flowing between nodes"},
+	{Type: MsgTypeDataBatch, Description: "Latest data of a node's pins, batched"},
+	{Type: MsgTypeFidelity, Description: "Full fidelity of a node's values changed"},
+	{Type: MsgTypeDebugData, Description: "Debug data available"},
+	{Type: MsgTypeExecStart, Description: "Blueprint execution started"},
+	{Type: MsgTypeExecEnd, Description: "Blueprint execution ended"},
+	{Type: MsgTypeExecStatus, Description: "Execution status update, and the welcome message of a connection"},
+	{Type: MsgTypeResult, Description: "Pin output value"},
+	{Type: MsgTypeLog, Description: "Log message"},
+	{Type: MsgTypeBatchStatus, Description: "Batch execution progress"},
+	{Type: MsgTypeDeprecation, Description: "A share link, batch or service triggered a deprecated blueprint, or was blocked by its sunset"},
+	{Type: MsgTypeWatchAdded, Description: "Watch expression registered"},
+	{Type: MsgTypeWatchRemoved, Description: "Watch expression removed"},
+	{Type: MsgTypeWatchUpdate, Description: "Watched value changed"},
+	{Type: MsgTypeWatchError, Description: "Watch expression rejected"},
+	{Type: MsgTypeResumed, Description: "Missed broadcast messages replayed"},
+	{Type: MsgTypeProtocolError, Description: "A client message was rejected", Payload: []FieldSchema{
+		{Name: "code", Type: "string", Required: true, Description: "malformed, unsupported_version, unknown_type or invalid_payload"},
+		{Name: "error", Type: "string", Required: true},
+		{Name: "type", Type: "string", Description: "Type of the rejected message"},
+	}},
+}
+
+// clientMessage is a message type clients send, with the handler of its validated payload
+type clientMessage struct {
+	schema MessageSchema
+	handle func(c *WebSocketClient, payload json.RawMessage)
+}
+
+// clientMessages maps the types of client messages to their schema and handler
+var clientMessages = map[string]clientMessage{
+	"watch.add": {
+		schema: MessageSchema{Description: "Register a watch expression on an execution", Payload: []FieldSchema{
+			{Name: "executionId", Type: "string", Required: true},
+			{Name: "expression", Type: "string", Required: true},
+		}},
+		handle: (*WebSocketClient).handleWatchAdd,
+	},
+	"watch.remove": {
+		schema: MessageSchema{Description: "Remove a watch expression of the client", Payload: []FieldSchema{
+			{Name: "executionId", Type: "string", Required: true},
+			{Name: "watchId", Type: "string", Required: true},
+		}},
+		handle: (*WebSocketClient).handleWatchRemove,
+	},
+	"events.resume": {
+		schema: MessageSchema{Description: "Replay the broadcast messages missed since a resume token", Payload: []FieldSchema{
+			{Name: "token", Type: "string", Required: true},
+			{Name: "executionIds", Type: "array", Description: "Only replay the messages of these executions"},
+		}},
+		handle: (*WebSocketClient).handleResume,
+	},
+	MsgTypeFidelity: {
+		schema: MessageSchema{Description: "Receive every value of a node instead of batches, or stop", Payload: []FieldSchema{
+			{Name: "nodeId", Type: "string", Required: true},
+			{Name: "full", Type: "boolean"},
+		}},
+		handle: (*WebSocketClient).handleFidelity,
+	},
+}
+
+// Schema returns the schema document of the protocol
+func Schema() ProtocolSchema {
+	messages := make([]MessageSchema, 0, len(serverMessages)+len(clientMessages))
+	for _, message := range serverMessages {
+		message.Direction = DirectionServer
+		message.Since = 1
+		messages = append(messages, message)
+	}
+
+	clientTypes := make([]string, 0, len(clientMessages))
+	for messageType := range clientMessages {
+		clientTypes = append(clientTypes, messageType)
+	}
+	sort.Strings(clientTypes)
+	for _, messageType := range clientTypes {
+		message := clientMessages[messageType].schema
+		message.Type = messageType
+		message.Direction = DirectionClient
+		message.Since = 1
+		messages = append(messages, message)
+	}
+
+	return ProtocolSchema{
+		Version:    ProtocolVersion,
+		MinVersion: MinProtocolVersion,
+		Envelope:   envelopeSchema,
+		Messages:   messages,
+	}
+}
+
+// HandleSchema serves the schema document of the protocol
+func (h *WebSocketManager) HandleSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(Schema()); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// requestedVersion returns the protocol version a connecting client asked for with
+// ?version=, the current one if it didn't ask
+func requestedVersion(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("version")
+	if value == "" {
+		return ProtocolVersion, nil
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || !supportedVersion(version) {
+		return 0, fmt.Errorf("unsupported protocol version %q, supported versions are %d to %d", value, MinProtocolVersion, ProtocolVersion)
+	}
+	return version, nil
+}
+
+// supportedVersion reports whether the server accepts messages of a protocol version
+func supportedVersion(version int) bool {
+	return version >= MinProtocolVersion && version <= ProtocolVersion
+}
+
+// encodeMessage encodes a message of the current protocol version
+func encodeMessage(messageType string, payload json.RawMessage, seq uint64) ([]byte, error) {
+	return json.Marshal(WebSocketMessage{
+		Type:    messageType,
+		Version: ProtocolVersion,
+		Payload: payload,
+		Seq:     seq,
+	})
+}
+
+// handleMessage validates a client message against the schema of its type and hands its
+// payload to the handler. Messages the server can't handle are answered with a
+// protocol.error message instead of closing the connection.
+func (c *WebSocketClient) handleMessage(data []byte) {
+	var message WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil || message.Type == "" {
+		c.protocolError(ProtocolErrorMalformed, "", "message must be a JSON object with a type")
+		return
+	}
+
+	version := message.Version
+	if version == 0 {
+		version = MinProtocolVersion
+	}
+	if !supportedVersion(version) {
+		c.protocolError(ProtocolErrorUnsupportedVersion, message.Type,
+			fmt.Sprintf("version %d is not supported, supported versions are %d to %d", version, MinProtocolVersion, ProtocolVersion))
+		return
+	}
+
+	handler, known := clientMessages[message.Type]
+	if !known {
+		c.protocolError(ProtocolErrorUnknownType, message.Type, fmt.Sprintf("unknown message type %q", message.Type))
+		return
+	}
+
+	if err := validatePayload(handler.schema.Payload, message.Payload); err != nil {
+		c.protocolError(ProtocolErrorInvalidPayload, message.Type, err.Error())
+		return
+	}
+
+	handler.handle(c, message.Payload)
+}
+
+// protocolError tells the client one of its messages was rejected
+func (c *WebSocketClient) protocolError(code, messageType, reason string) {
+	payload := map[string]interface{}{
+		"code":  code,
+		"error": reason,
+	}
+	if messageType != "" {
+		payload["type"] = messageType
+	}
+	c.manager.SendToClient(c.clientID, MsgTypeProtocolError, payload)
+}
+
+// validatePayload checks that a payload is an object whose fields have the types of their
+// schema and that the required ones are set. Fields the schema doesn't know are allowed,
+// so that clients can send fields of newer versions.
+func validatePayload(fields []FieldSchema, payload json.RawMessage) error {
+	values := make(map[string]json.RawMessage)
+	if len(payload) > 0 && string(payload) != "null" {
+		if err := json.Unmarshal(payload, &values); err != nil {
+			return fmt.Errorf("payload must be an object")
+		}
+	}
+
+	for _, field := range fields {
+		value, set := values[field.Name]
+		if !set || string(value) == "null" {
+			if field.Required {
+				return fmt.Errorf("%s is required", field.Name)
+			}
+			continue
+		}
+		if jsonType(value) != field.Type {
+			return fmt.Errorf("%s must be a %s", field.Name, field.Type)
+		}
+		if field.Required && field.Type == "string" && string(value) == `""` {
+			return fmt.Errorf("%s is required", field.Name)
+		}
+	}
+	return nil
+}
+
+// jsonType returns the JSON type of an encoded value
+func jsonType(value json.RawMessage) string {
+	switch value[0] {
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case '[':
+		return "array"
+	case '{':
+		return "object"
+	default:
+		return "number"
+	}
+}
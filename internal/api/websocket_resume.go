@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	resumeBufferSize = 4096            // Broadcast messages kept for reconnecting clients
+	resumeBufferTTL  = 2 * time.Minute // Time a broadcast message can be resumed
+)
+
+// resumeRequest is the payload of events.resume messages
+type resumeRequest struct {
+	Token        string   `json:"token"`
+	ExecutionIDs []string `json:"executionIds,omitempty"`
+}
+
+// bufferedMessage is a broadcast message kept for reconnecting clients
+type bufferedMessage struct {
+	seq         uint64
+	executionID string
+	data        []byte
+	sentAt      time.Time
+}
+
+// eventBuffer numbers the broadcast messages and keeps the recent ones, so a client that
+// reconnects can request the messages it missed since its resume token
+type eventBuffer struct {
+	messages []bufferedMessage
+	seq      uint64
+	mutex    sync.Mutex
+}
+
+// add numbers a broadcast message and keeps it. The message is built and sent under the
+// lock of the buffer, so clients receive the messages in the order of their numbers.
+func (b *eventBuffer) add(executionID string, build func(seq uint64) ([]byte, error), send func(data []byte)) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	data, err := build(b.seq + 1)
+	if err != nil {
+		return err
+	}
+	b.seq++
+
+	now := time.Now()
+	b.messages = append(b.messages, bufferedMessage{
+		seq:         b.seq,
+		executionID: executionID,
+		data:        data,
+		sentAt:      now,
+	})
+	b.evict(now)
+
+	send(data)
+	return nil
+}
+
+// evict drops the messages beyond the size or age limit of the buffer
+func (b *eventBuffer) evict(now time.Time) {
+	drop := 0
+	if len(b.messages) > resumeBufferSize {
+		drop = len(b.messages) - resumeBufferSize
+	}
+	for drop < len(b.messages) && now.Sub(b.messages[drop].sentAt) > resumeBufferTTL {
+		drop++
+	}
+	if drop > 0 {
+		b.messages = append(b.messages[:0:0], b.messages[drop:]...)
+	}
+}
+
+// head returns the sequence number of the latest broadcast message
+func (b *eventBuffer) head() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.seq
+}
+
+// since returns the messages broadcast after a resume token up to a sequence number,
+// limited to the messages of the given executions if any. It reports whether the buffer
+// still holds every message in that range.
+func (b *eventBuffer) since(token string, until uint64, executionIDs []string) ([]bufferedMessage, bool) {
+	seq, err := strconv.ParseUint(token, 10, 64)
+	if err != nil || seq > until {
+		// Tokens ahead of the buffer were issued before the server restarted
+		return nil, false
+	}
+
+	filter := make(map[string]bool, len(executionIDs))
+	for _, executionID := range executionIDs {
+		filter[executionID] = true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.evict(time.Now())
+
+	oldest := b.seq + 1
+	if len(b.messages) > 0 {
+		oldest = b.messages[0].seq
+	}
+	complete := oldest <= seq+1
+
+	missed := make([]bufferedMessage, 0)
+	for _, message := range b.messages {
+		if message.seq <= seq || message.seq > until {
+			continue
+		}
+		if len(filter) > 0 && !filter[message.executionID] {
+			continue
+		}
+		missed = append(missed, message)
+	}
+	return missed, complete
+}
+
+// messageExecutionID returns the execution a broadcast payload belongs to, if any
+func messageExecutionID(payload []byte) string {
+	var scoped struct {
+		ExecutionID string `json:"executionId"`
+	}
+	if err := json.Unmarshal(payload, &scoped); err != nil {
+		return ""
+	}
+	return scoped.ExecutionID
+}
+
+// handleResume sends the client the broadcast messages it missed between its resume token
+// and its connection, followed by an events.resumed message. Messages broadcast after the
+// client connected were already sent to it, so they aren't replayed.
+func (c *WebSocketClient) handleResume(payload json.RawMessage) {
+	var request resumeRequest
+	if err := json.Unmarshal(payload, &request); err != nil || request.Token == "" {
+		c.manager.SendToClient(c.clientID, MsgTypeResumed, map[string]interface{}{
+			"complete": false,
+			"replayed": 0,
+			"error":    "token is required",
+		})
+		return
+	}
+
+	missed, complete := c.manager.events.since(request.Token, c.joinedAt, request.ExecutionIDs)
+
+	replayed := 0
+	for _, message := range missed {
+		if !c.manager.sendRaw(c.clientID, message.data) {
+			// The client can't take the replay, so it still has a gap
+			complete = false
+			break
+		}
+		replayed++
+	}
+
+	c.manager.SendToClient(c.clientID, MsgTypeResumed, map[string]interface{}{
+		"complete": complete,
+		"replayed": replayed,
+	})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// broadcast adds numbered messages of the executions to the buffer
+func broadcast(t *testing.T, buffer *eventBuffer, executionIDs ...string) {
+	t.Helper()
+	for _, executionID := range executionIDs {
+		build := func(seq uint64) ([]byte, error) {
+			return []byte(fmt.Sprintf(`{"seq":%d,"executionId":%q}`, seq, executionID)), nil
+		}
+		if err := buffer.add(executionID, build, func([]byte) {}); err != nil {
+			t.Fatalf("failed to add message: %v", err)
+		}
+	}
+}
+
+// sequences returns the sequence numbers of buffered messages
+func sequences(messages []bufferedMessage) []uint64 {
+	seqs := make([]uint64, len(messages))
+	for i, message := range messages {
+		seqs[i] = message.seq
+	}
+	return seqs
+}
+
+func TestEventBufferReplaysMissedMessages(t *testing.T) {
+	var buffer eventBuffer
+	broadcast(t, &buffer, "a", "b", "a", "b", "a")
+
+	// A client disconnected after message 2 and reconnected after message 4
+	missed, complete := buffer.since("2", 4, nil)
+	if !complete || fmt.Sprint(sequences(missed)) != "[3 4]" {
+		t.Errorf("expected messages 3 and 4, got %v (complete: %v)", sequences(missed), complete)
+	}
+
+	missed, complete = buffer.since("0", buffer.head(), []string{"a"})
+	if !complete || fmt.Sprint(sequences(missed)) != "[1 3 5]" {
+		t.Errorf("expected the messages of execution a, got %v (complete: %v)", sequences(missed), complete)
+	}
+	if got := messageExecutionID(missed[0].data); got != "a" {
+		t.Errorf("expected the payload to name execution a, got %q", got)
+	}
+}
+
+func TestEventBufferReportsGaps(t *testing.T) {
+	var buffer eventBuffer
+	broadcast(t, &buffer, "a", "a", "a")
+
+	for _, token := range []string{"", "not a number", "7"} {
+		if missed, complete := buffer.since(token, buffer.head(), nil); complete || len(missed) != 0 {
+			t.Errorf("token %q: expected nothing to resume, got %v (complete: %v)", token, sequences(missed), complete)
+		}
+	}
+
+	// Messages older than the TTL are evicted, leaving a gap for old tokens
+	buffer.mutex.Lock()
+	buffer.messages[0].sentAt = time.Now().Add(-2 * resumeBufferTTL)
+	buffer.mutex.Unlock()
+
+	missed, complete := buffer.since("0", buffer.head(), nil)
+	if complete {
+		t.Error("expected the resume to be incomplete once missed messages were evicted")
+	}
+	if fmt.Sprint(sequences(missed)) != "[2 3]" {
+		t.Errorf("expected the messages still buffered, got %v", sequences(missed))
+	}
+	if _, complete := buffer.since("1", buffer.head(), nil); !complete {
+		t.Error("expected tokens after the evicted message to resume completely")
+	}
+}
@@ -1,10 +1,10 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/gorilla/mux"
 	"net/http"
+	"strings"
 	"webblueprint/pkg/service"
 )
 
@@ -69,17 +69,26 @@ func (h *WorkspaceHandler) handleGetWorkspace(w http.ResponseWriter, r *http.Req
 	respondWithJSON(w, http.StatusOK, workspace)
 }
 
+// createWorkspaceRequest is the body of a new workspace
+type createWorkspaceRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"isPublic"`
+}
+
+// Validate checks that the workspace is named
+func (c *createWorkspaceRequest) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fieldError("name", "Workspace name is required")
+	}
+	return nil
+}
+
 // handleCreateWorkspace creates a new workspace
 func (h *WorkspaceHandler) handleCreateWorkspace(w http.ResponseWriter, r *http.Request) {
 	// Parse the workspace from request body
-	var request struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		IsPublic    bool   `json:"isPublic"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid workspace format")
+	var request createWorkspaceRequest
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -131,8 +140,7 @@ func (h *WorkspaceHandler) handleUpdateWorkspace(w http.ResponseWriter, r *http.
 		IsPublic    bool   `json:"isPublic"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid workspace format")
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -211,8 +219,7 @@ func (h *WorkspaceHandler) handleAddWorkspaceMember(w http.ResponseWriter, r *ht
 		Role   string `json:"role"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request format")
+	if !decodeRequest(w, r, &request) {
 		return
 	}
 
@@ -1,6 +1,7 @@
 package bperrors
 
 import (
+	"context"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -24,7 +25,7 @@ type ErrorAwareContext interface {
 // This allows us to adapt any existing execution context implementation
 type ErrorContextWrapper struct {
 	// The wrapped execution context
-	Context node.ExecutionContext
+	Wrapped node.ExecutionContext
 
 	// Error handling components
 	ErrorManager    *ErrorManager
@@ -34,7 +35,7 @@ type ErrorContextWrapper struct {
 // NewErrorContextWrapper creates a new wrapper around an existing execution context
 func NewErrorContextWrapper(ctx node.ExecutionContext, em *ErrorManager, rm *RecoveryManager) *ErrorContextWrapper {
 	return &ErrorContextWrapper{
-		Context:         ctx,
+		Wrapped:         ctx,
 		ErrorManager:    em,
 		RecoveryManager: rm,
 	}
@@ -44,13 +45,18 @@ func NewErrorContextWrapper(ctx node.ExecutionContext, em *ErrorManager, rm *Rec
 
 // IsInputPinActive checks if an input pin triggered execution
 func (w *ErrorContextWrapper) IsInputPinActive(pinID string) bool {
-	return w.Context.IsInputPinActive(pinID)
+	return w.Wrapped.IsInputPinActive(pinID)
+}
+
+// GetUpstreamOutput delegates to the wrapped context
+func (w *ErrorContextWrapper) GetUpstreamOutput(nodeID, pinID string) (types.Value, bool) {
+	return w.Wrapped.GetUpstreamOutput(nodeID, pinID)
 }
 
 // GetInputValue retrieves an input value by pin ID, with error recovery if needed
 func (w *ErrorContextWrapper) GetInputValue(pinID string) (types.Value, bool) {
 	// First try to get the value from the original context
-	value, exists := w.Context.GetInputValue(pinID)
+	value, exists := w.Wrapped.GetInputValue(pinID)
 
 	// If the value doesn't exist, try to recover with a default value
 	if !exists {
@@ -64,18 +70,18 @@ func (w *ErrorContextWrapper) GetInputValue(pinID string) (types.Value, bool) {
 			ErrMissingRequiredInput,
 			"Required input value missing",
 			SeverityMedium,
-		).WithNodeInfo(w.Context.GetNodeID(), pinID).WithBlueprintInfo(w.Context.GetBlueprintID(), w.Context.GetExecutionID())
+		).WithNodeInfo(w.Wrapped.GetNodeID(), pinID).WithBlueprintInfo(w.Wrapped.GetBlueprintID(), w.Wrapped.GetExecutionID())
 
 		// Record the error
-		w.ErrorManager.RecordError(w.Context.GetExecutionID(), err)
+		w.ErrorManager.RecordError(w.Wrapped.GetExecutionID(), err)
 
 		// Attempt recovery
-		if success, _ := w.RecoveryManager.RecoverFromError(w.Context.GetExecutionID(), err); success {
+		if success, _ := w.RecoveryManager.RecoverFromError(w.Wrapped.GetExecutionID(), err); success {
 			// Get a default value for this type
 			if defaultValue, err := w.RecoveryManager.GetDefaultValue(pinType); err == nil {
 				// Log the recovery
-				w.Context.Logger().Info("Recovered from missing input by using default value", map[string]interface{}{
-					"nodeId": w.Context.GetNodeID(),
+				w.Wrapped.Logger().Info("Recovered from missing input by using default value", map[string]interface{}{
+					"nodeId": w.Wrapped.GetNodeID(),
 					"pinId":  pinID,
 					"value":  defaultValue.RawValue,
 				})
@@ -90,71 +96,76 @@ func (w *ErrorContextWrapper) GetInputValue(pinID string) (types.Value, bool) {
 
 // SetOutputValue sets an output value by pin ID
 func (w *ErrorContextWrapper) SetOutputValue(pinID string, value types.Value) {
-	w.Context.SetOutputValue(pinID, value)
+	w.Wrapped.SetOutputValue(pinID, value)
 }
 
 // ActivateOutputFlow activates an output execution flow
 func (w *ErrorContextWrapper) ActivateOutputFlow(pinID string) error {
-	return w.Context.ActivateOutputFlow(pinID)
+	return w.Wrapped.ActivateOutputFlow(pinID)
 }
 
 // ExecuteConnectedNodes executes all nodes connected to the given output pin
 func (w *ErrorContextWrapper) ExecuteConnectedNodes(pinID string) error {
-	return w.Context.ExecuteConnectedNodes(pinID)
+	return w.Wrapped.ExecuteConnectedNodes(pinID)
 }
 
 // GetVariable retrieves a variable by name
 func (w *ErrorContextWrapper) GetVariable(name string) (types.Value, bool) {
-	return w.Context.GetVariable(name)
+	return w.Wrapped.GetVariable(name)
 }
 
 // SetVariable sets a variable by name
 func (w *ErrorContextWrapper) SetVariable(name string, value types.Value) {
-	w.Context.SetVariable(name, value)
+	w.Wrapped.SetVariable(name, value)
 }
 
 // Logger returns the execution logger
 func (w *ErrorContextWrapper) Logger() node.Logger {
-	return w.Context.Logger()
+	return w.Wrapped.Logger()
 }
 
 // RecordDebugInfo stores debug information
 func (w *ErrorContextWrapper) RecordDebugInfo(info types.DebugInfo) {
-	w.Context.RecordDebugInfo(info)
+	w.Wrapped.RecordDebugInfo(info)
 }
 
 // GetDebugData returns all debug data
 func (w *ErrorContextWrapper) GetDebugData() map[string]interface{} {
-	return w.Context.GetDebugData()
+	return w.Wrapped.GetDebugData()
 }
 
 // GetNodeID returns the ID of the executing node
 func (w *ErrorContextWrapper) GetNodeID() string {
-	return w.Context.GetNodeID()
+	return w.Wrapped.GetNodeID()
 }
 
 // GetNodeType returns the type of the executing node
 func (w *ErrorContextWrapper) GetNodeType() string {
-	return w.Context.GetNodeType()
+	return w.Wrapped.GetNodeType()
 }
 
 // GetBlueprintID returns the ID of the executing blueprint
 func (w *ErrorContextWrapper) GetBlueprintID() string {
-	return w.Context.GetBlueprintID()
+	return w.Wrapped.GetBlueprintID()
 }
 
 // GetExecutionID returns the current execution ID
 func (w *ErrorContextWrapper) GetExecutionID() string {
-	return w.Context.GetExecutionID()
+	return w.Wrapped.GetExecutionID()
+}
+
+// Context returns the context of the execution
+func (w *ErrorContextWrapper) Context() context.Context {
+	return w.Wrapped.Context()
 }
 
 func (w *ErrorContextWrapper) SaveData(key string, value interface{}) {
-	w.Context.SaveData(key, value)
+	w.Wrapped.SaveData(key, value)
 }
 
 // CreateLoopContext delegates to the wrapped context
 func (w *ErrorContextWrapper) CreateLoopContext(loopVarName string, maxIterations int, startIndex float64) (node.LoopContext, bool) {
-	return w.Context.CreateLoopContext(loopVarName, maxIterations, startIndex)
+	return w.Wrapped.CreateLoopContext(loopVarName, maxIterations, startIndex)
 }
 
 // Error handling methods
@@ -200,14 +211,14 @@ func (w *ErrorContextWrapper) ReportError(errType ErrorType, code BlueprintError
 	}
 
 	// Add context
-	err.WithNodeInfo(w.Context.GetNodeID(), "").WithBlueprintInfo(w.Context.GetBlueprintID(), w.Context.GetExecutionID())
+	err.WithNodeInfo(w.Wrapped.GetNodeID(), "").WithBlueprintInfo(w.Wrapped.GetBlueprintID(), w.Wrapped.GetExecutionID())
 
 	// Record the error
-	w.ErrorManager.RecordError(w.Context.GetExecutionID(), err)
+	w.ErrorManager.RecordError(w.Wrapped.GetExecutionID(), err)
 
 	// Log the error
-	w.Context.Logger().Error(message, map[string]interface{}{
-		"nodeId":      w.Context.GetNodeID(),
+	w.Wrapped.Logger().Error(message, map[string]interface{}{
+		"nodeId":      w.Wrapped.GetNodeID(),
 		"errorType":   string(errType),
 		"errorCode":   string(code),
 		"originalErr": originalErr,
@@ -218,12 +229,12 @@ func (w *ErrorContextWrapper) ReportError(errType ErrorType, code BlueprintError
 
 // AttemptRecovery tries to recover from an error
 func (w *ErrorContextWrapper) AttemptRecovery(err *BlueprintError) (bool, map[string]interface{}) {
-	return w.RecoveryManager.RecoverFromError(w.Context.GetExecutionID(), err)
+	return w.RecoveryManager.RecoverFromError(w.Wrapped.GetExecutionID(), err)
 }
 
 // GetErrorSummary gets a summary of errors for this node
 func (w *ErrorContextWrapper) GetErrorSummary() map[string]interface{} {
-	nodeErrors := w.ErrorManager.GetNodeErrors(w.Context.GetExecutionID(), w.Context.GetNodeID())
+	nodeErrors := w.ErrorManager.GetNodeErrors(w.Wrapped.GetExecutionID(), w.Wrapped.GetNodeID())
 
 	if len(nodeErrors) == 0 {
 		return map[string]interface{}{
@@ -49,9 +49,7 @@ func (em *ErrorManager) registerDefaultRecoveryStrategies() {
 
 // RecordError records an error for a given execution
 func (em *ErrorManager) RecordError(executionID string, err *BlueprintError) {
-	if em.mutex.TryLock() {
-		defer em.mutex.Unlock()
-	}
+	em.mutex.Lock()
 
 	// If missing recovery options, add them from our registry
 	if len(err.RecoveryOptions) == 0 {
@@ -66,14 +64,17 @@ func (em *ErrorManager) RecordError(executionID string, err *BlueprintError) {
 		em.errors[executionID] = make([]*BlueprintError, 0)
 	}
 	em.errors[executionID] = append(em.errors[executionID], err)
+	handlers := em.errorHandlers[err.Type]
+
+	// Handlers run without the lock, so that they can use the error manager and concurrent
+	// executions don't wait for them
+	em.mutex.Unlock()
 
 	// Call relevant error handlers
-	if handlers, ok := em.errorHandlers[err.Type]; ok {
-		for _, handler := range handlers {
-			// Don't propagate handler errors, just log them
-			if handlerErr := handler(err); handlerErr != nil {
-				fmt.Printf("Error handler failed: %s\n", handlerErr.Error())
-			}
+	for _, handler := range handlers {
+		// Don't propagate handler errors, just log them
+		if handlerErr := handler(err); handlerErr != nil {
+			fmt.Printf("Error handler failed: %s\n", handlerErr.Error())
 		}
 	}
 }
@@ -193,6 +194,14 @@ func (em *ErrorManager) AttemptRecovery(err *BlueprintError, strategy RecoverySt
 			"timestamp":    time.Now(),
 		}
 
+	case RecoveryAlternatePath:
+		// Signal that the node's error flow should be followed
+		return true, map[string]interface{}{
+			"recoveryType": "alternate_path",
+			"nodeId":       err.NodeID,
+			"timestamp":    time.Now(),
+		}
+
 	default:
 		return false, nil
 	}
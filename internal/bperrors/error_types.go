@@ -17,6 +17,7 @@ const (
 	ErrorTypeNetwork    ErrorType = "network"    // Network-related errors
 	ErrorTypePlugin     ErrorType = "plugin"     // Plugin-related errors
 	ErrorTypeSystem     ErrorType = "system"     // System-level errors
+	ErrorTypeQuota      ErrorType = "quota"      // Workspace quota and rate limit errors
 	ErrorTypeUnknown    ErrorType = "unknown"    // Unclassified errors
 )
 
@@ -42,6 +43,14 @@ const (
 	ErrExecutionTimeout      BlueprintErrorCode = "E004"
 	ErrExecutionCancelled    BlueprintErrorCode = "E005"
 	ErrNoEntryPoints         BlueprintErrorCode = "E006"
+	ErrOperationFailed       BlueprintErrorCode = "E007" // A node couldn't complete its operation on valid inputs
+	ErrServiceUnavailable    BlueprintErrorCode = "E008" // A service the node needs isn't available in its context
+	ErrNodeTimeout           BlueprintErrorCode = "E009" // A node ran longer than its wall-clock limit
+	ErrNodePanicked          BlueprintErrorCode = "E010" // A node panicked while executing
+	ErrActorFailed           BlueprintErrorCode = "E011" // The actor of a node kept exiting and ran out of restarts
+	ErrBlueprintRunning      BlueprintErrorCode = "E012" // The concurrency policy of the blueprint turned the execution away
+	ErrTriggerSkipped        BlueprintErrorCode = "E013" // The guards of the entry points rejected the payload of the trigger
+	ErrExecutionPanicked     BlueprintErrorCode = "E014" // The engine panicked while running the execution, outside of its nodes
 
 	// Connection errors
 	ErrInvalidConnection    BlueprintErrorCode = "C001"
@@ -49,23 +58,51 @@ const (
 	ErrMissingRequiredInput BlueprintErrorCode = "C003"
 	ErrTypeMismatch         BlueprintErrorCode = "C004"
 	ErrNodeDisconnected     BlueprintErrorCode = "C005"
+	ErrInvalidTransform     BlueprintErrorCode = "C006" // A connection transform expression is invalid or failed
+	ErrInvalidCondition     BlueprintErrorCode = "C007" // A connection condition is invalid or failed
 
 	// Validation errors
 	ErrInvalidBlueprintStructure BlueprintErrorCode = "V001"
 	ErrInvalidNodeConfiguration  BlueprintErrorCode = "V002"
 	ErrMissingProperty           BlueprintErrorCode = "V003"
 	ErrInvalidPropertyValue      BlueprintErrorCode = "V004"
+	ErrInvalidInputValue         BlueprintErrorCode = "V005"
+	ErrInvalidRequest            BlueprintErrorCode = "V006" // The body or parameters of an API request are malformed or invalid
+	ErrRequestTooLarge           BlueprintErrorCode = "V007" // The body of an API request exceeds the size limit
 
 	// Database errors
 	ErrDatabaseConnection       BlueprintErrorCode = "D001"
 	ErrBlueprintNotFound        BlueprintErrorCode = "D002"
 	ErrBlueprintVersionNotFound BlueprintErrorCode = "D003"
 	ErrDatabaseQuery            BlueprintErrorCode = "D004"
+	ErrResourceNotFound         BlueprintErrorCode = "D005" // The resource an API request names doesn't exist
+	ErrResourceGone             BlueprintErrorCode = "D006" // The resource an API request names expired or was revoked
+
+	// Network errors
+	ErrRequestFailed   BlueprintErrorCode = "N001"
+	ErrRequestTimeout  BlueprintErrorCode = "N002"
+	ErrInvalidResponse BlueprintErrorCode = "N003"
+	ErrCircuitOpen     BlueprintErrorCode = "N004" // Calls to the host are short-circuited after repeated failures
 
 	// System errors
 	ErrInternalServerError BlueprintErrorCode = "S001"
 	ErrResourceExhausted   BlueprintErrorCode = "S002"
 	ErrSystemUnavailable   BlueprintErrorCode = "S003"
+	ErrConflict            BlueprintErrorCode = "S004" // An API request conflicts with the current state of its resource
+
+	// Quota errors
+	ErrRateLimitExceeded         BlueprintErrorCode = "Q001"
+	ErrConcurrencyLimitExceeded  BlueprintErrorCode = "Q002"
+	ErrBlueprintTooLarge         BlueprintErrorCode = "Q003"
+	ErrExecutionDurationExceeded BlueprintErrorCode = "Q004"
+	ErrBudgetExceeded            BlueprintErrorCode = "Q005" // The execution used up the budget of its blueprint
+
+	// Permission errors
+	ErrNodeTypeBlocked   BlueprintErrorCode = "P001" // A workspace node policy blocks the node type
+	ErrCommandNotAllowed BlueprintErrorCode = "P002" // Commands are disabled, or the sandbox refuses the command
+	ErrNotAuthenticated  BlueprintErrorCode = "P003" // An API request needs a user and names none
+	ErrPermissionDenied  BlueprintErrorCode = "P004" // The user of an API request isn't allowed to perform it
+	ErrBlueprintSunset   BlueprintErrorCode = "P005" // The deprecated blueprint or version passed its sunset date
 
 	// Other error codes
 	ErrUnknown BlueprintErrorCode = "U001"
@@ -78,6 +115,7 @@ const (
 	RecoveryRetry              RecoveryStrategy = "retry"             // Retry the operation
 	RecoverySkipNode           RecoveryStrategy = "skip_node"         // Skip the problematic node
 	RecoveryUseDefaultValue    RecoveryStrategy = "use_default_value" // Use a default value for the operation
+	RecoveryAlternatePath      RecoveryStrategy = "alternate_path"    // Continue along the node's error flow
 	RecoveryManualIntervention RecoveryStrategy = "manual"            // Require manual intervention
 	RecoveryNone               RecoveryStrategy = "none"              // No recovery possible
 )
@@ -1,9 +1,14 @@
 package bperrors_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 	errors "webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/logic"
+	"webblueprint/internal/test/mocks"
 	"webblueprint/internal/types"
 	"webblueprint/pkg/blueprint"
 )
@@ -237,6 +242,311 @@ func TestValidation(t *testing.T) {
 	}
 }
 
+func TestConnectionExpressionValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Conditions",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "test-start"},
+			{ID: "ok", Type: "test-action"},
+			{ID: "failed", Type: "test-action"},
+			{ID: "value", Type: "constant-number"},
+		},
+		Connections: []blueprint.Connection{
+			{ID: "c1", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "ok", TargetPinID: "execute",
+				ConnectionType: "execution", Data: map[string]any{"condition": "value.status == 200"}},
+			{ID: "c2", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "failed", TargetPinID: "execute",
+				ConnectionType: "execution", Data: map[string]any{"condition": "value.status >= 400"}},
+			{ID: "c3", SourceNodeID: "value", SourcePinID: "value", TargetNodeID: "ok", TargetPinID: "input",
+				ConnectionType: "data", Data: map[string]any{"transform": "value *"}},
+		},
+	}
+
+	codes := func(issues []error) map[errors.BlueprintErrorCode]int {
+		found := make(map[errors.BlueprintErrorCode]int)
+		for _, issue := range issues {
+			found[issue.(*errors.BlueprintError).Code]++
+		}
+		return found
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if codes(result.Errors)[errors.ErrInvalidTransform] != 1 {
+		t.Errorf("Expected an invalid transform error, got %v", result.Errors)
+	}
+	if codes(result.Warnings)[errors.ErrInvalidCondition] != 1 {
+		t.Errorf("Expected a warning about uncovered conditions, got %v", result.Warnings)
+	}
+
+	// An unconditional connection covers the remaining cases
+	bp.Connections[2].Data = nil
+	bp.Connections = append(bp.Connections, blueprint.Connection{
+		ID: "c4", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "failed", TargetPinID: "execute", ConnectionType: "execution",
+	})
+
+	result = validator.ValidateBlueprint(bp)
+	if codes(result.Errors)[errors.ErrInvalidTransform] != 0 || codes(result.Warnings)[errors.ErrInvalidCondition] != 0 {
+		t.Errorf("Expected no connection expression issues, got errors %v and warnings %v", result.Errors, result.Warnings)
+	}
+}
+
+func TestGuardValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Guarded",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "event-on-created", Data: map[string]interface{}{"guard": "value.amount >"}},
+		},
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if result.Valid || result.Errors[0].(*errors.BlueprintError).Code != errors.ErrInvalidNodeConfiguration {
+		t.Errorf("Expected the invalid guard to be rejected, got %v", result.Errors)
+	}
+
+	bp.Nodes[0].Data["guard"] = "value.amount > 100"
+	result = validator.ValidateBlueprint(bp)
+	if !result.Valid {
+		t.Errorf("Expected a valid guard to pass, got %v", result.Errors)
+	}
+}
+
+func TestLogLevelValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Logged",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "event-on-created", Data: map[string]interface{}{"logLevel": "verbose"}},
+		},
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if !result.Valid || len(result.Warnings) != 1 {
+		t.Errorf("Expected the unknown log level to only be warned about, got errors %v and warnings %v", result.Errors, result.Warnings)
+	}
+
+	bp.Nodes[0].Data["logLevel"] = "WARN"
+	result = validator.ValidateBlueprint(bp)
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected a known log level to pass, got %v", result.Warnings)
+	}
+}
+
+func TestFanInValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Branching",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "event-on-created"},
+			{ID: "a", Type: "constant-number"},
+			{ID: "b", Type: "constant-number"},
+			{ID: "result", Type: "print", Data: map[string]interface{}{"fanIn": map[string]interface{}{"message": "newest"}}},
+		},
+		Connections: []blueprint.Connection{
+			{ID: "c1", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "result", TargetPinID: "exec", ConnectionType: "execution"},
+			{ID: "c2", SourceNodeID: "a", SourcePinID: "value", TargetNodeID: "result", TargetPinID: "message", ConnectionType: "data"},
+			{ID: "c3", SourceNodeID: "b", SourcePinID: "value", TargetNodeID: "result", TargetPinID: "message", ConnectionType: "data"},
+		},
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if result.Valid {
+		t.Errorf("Expected an unknown fan-in policy to be rejected")
+	}
+
+	delete(bp.Nodes[3].Data, "fanIn")
+	result = validator.ValidateBlueprint(bp)
+	if !result.Valid || len(result.Warnings) != 1 {
+		t.Errorf("Expected a pin fed by two connections without a policy to be warned about, got errors %v and warnings %v", result.Errors, result.Warnings)
+	}
+
+	bp.Nodes[3].Data["fanIn"] = map[string]interface{}{"message": "Collect"}
+	result = validator.ValidateBlueprint(bp)
+	if !result.Valid || len(result.Warnings) != 0 {
+		t.Errorf("Expected a known fan-in policy to pass, got errors %v and warnings %v", result.Errors, result.Warnings)
+	}
+}
+
+// schemaNode is a node type whose properties declare a schema
+type schemaNode struct {
+	node.BaseNode
+}
+
+func (n *schemaNode) Execute(ctx node.ExecutionContext) error {
+	return nil
+}
+
+func TestPropertySchemaValidation(t *testing.T) {
+	maxRetries := 5.0
+	factory := func() node.Node {
+		return &schemaNode{BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID: "retrying",
+				PropertySchema: []node.PropertySchema{
+					{Name: "mode", Enum: []string{"fast", "safe"}, Required: true},
+					{Name: "retries", Max: &maxRetries},
+				},
+			},
+			Properties: []types.Property{
+				{Name: "mode", Type: types.PinTypes.String},
+				{Name: "retries", Type: types.PinTypes.Number},
+				{Name: "label", Type: types.PinTypes.String, Options: []string{"a", "b"}},
+			},
+		}}
+	}
+
+	schemas := node.PropertySchemas(factory())
+	if len(schemas) != 3 || schemas[0].Editor != node.PropertyEditorSelect || schemas[1].Editor != node.PropertyEditorNumber || schemas[2].Editor != node.PropertyEditorSelect {
+		t.Fatalf("Expected editors to be derived from the properties, got %+v", schemas)
+	}
+
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+	validator.SetNodeFactoryLookup(func(typeID string) (node.NodeFactory, bool) {
+		return factory, typeID == "retrying"
+	})
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Retrying",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "event-on-created"},
+			{ID: "call", Type: "retrying", Properties: []blueprint.NodeProperty{
+				{Name: "retries", Value: float64(9)},
+				{Name: "label", Value: "c"},
+			}},
+		},
+		Connections: []blueprint.Connection{
+			{ID: "c1", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "call", TargetPinID: "exec", ConnectionType: "execution"},
+		},
+	}
+
+	invalid := func(issues []error) int {
+		count := 0
+		for _, issue := range issues {
+			if issue.(*errors.BlueprintError).Code == errors.ErrInvalidPropertyValue {
+				count++
+			}
+		}
+		return count
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if result.Valid || invalid(result.Errors) != 3 {
+		t.Errorf("Expected a missing, an out of range and an unknown property value, got %v", result.Errors)
+	}
+
+	bp.Nodes[1].Properties = []blueprint.NodeProperty{
+		{Name: "mode", Value: "safe"},
+		{Name: "retries", Value: "3"},
+		{Name: "label", Value: "a"},
+	}
+	result = validator.ValidateBlueprint(bp)
+	if !result.Valid {
+		t.Errorf("Expected accepted property values to pass, got %v", result.Errors)
+	}
+}
+
+func TestDynamicPinValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+	validator.SetNodeFactoryLookup(func(typeID string) (node.NodeFactory, bool) {
+		return logic.NewJoinNode, typeID == "join"
+	})
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Joining",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "event-on-created"},
+			{ID: "join", Type: "join", Data: map[string]interface{}{"inputs": 3.0}},
+		},
+		Connections: []blueprint.Connection{
+			{ID: "c1", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "join", TargetPinID: "in_3", ConnectionType: "execution"},
+		},
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if !result.Valid {
+		t.Errorf("Expected a pin declared by the node data to be accepted, got %v", result.Errors)
+	}
+
+	bp.Nodes[1].Data["inputs"] = 2.0
+	result = validator.ValidateBlueprint(bp)
+	if result.Valid || result.Errors[0].(*errors.BlueprintError).Code != errors.ErrInvalidConnection {
+		t.Errorf("Expected a connection to a removed pin to be rejected, got %v", result.Errors)
+	}
+
+	bp.Nodes[1].Data["inputs"] = 0.0
+	result = validator.ValidateBlueprint(bp)
+	if result.Valid || result.Errors[0].(*errors.BlueprintError).Code != errors.ErrInvalidNodeConfiguration {
+		t.Errorf("Expected an invalid pin count to be rejected, got %v", result.Errors)
+	}
+}
+
+func TestBudgetValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+
+	bp := &blueprint.Blueprint{
+		ID:     "bp-1",
+		Name:   "Bounded",
+		Nodes:  []blueprint.BlueprintNode{{ID: "start", Type: "event-on-created"}},
+		Budget: &blueprint.ExecutionBudget{MaxDuration: "30s", MaxNodeExecutions: 100},
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if !result.Valid {
+		t.Errorf("Expected a valid budget to be accepted, got %v", result.Errors)
+	}
+
+	for _, budget := range []blueprint.ExecutionBudget{
+		{MaxDuration: "soon"},
+		{MaxDuration: "-1s"},
+		{MaxNodeExecutions: -1},
+	} {
+		bp.Budget = &budget
+		result = validator.ValidateBlueprint(bp)
+		if result.Valid || result.Errors[0].(*errors.BlueprintError).Code != errors.ErrInvalidBlueprintStructure {
+			t.Errorf("Expected budget %+v to be rejected, got %v", budget, result.Errors)
+		}
+	}
+}
+
+func TestAnnotationValidation(t *testing.T) {
+	validator := errors.NewBlueprintValidator(errors.NewErrorManager())
+
+	bp := &blueprint.Blueprint{
+		ID:   "bp-1",
+		Name: "Documented",
+		Nodes: []blueprint.BlueprintNode{
+			{ID: "start", Type: "event-on-created"},
+			{ID: "note", Type: blueprint.CommentNodeType, Data: map[string]interface{}{"text": "Runs once"}},
+		},
+		Frames: []blueprint.Frame{
+			{ID: "frame-1", Title: "Setup", NodeIDs: []string{"start", "missing"}},
+		},
+	}
+
+	result := validator.ValidateBlueprint(bp)
+	if !result.Valid || len(result.NodeIssues) != 0 {
+		t.Errorf("Expected comments and frames to be ignored, got errors %v and node issues %v", result.Errors, result.NodeIssues)
+	}
+
+	bp.Connections = []blueprint.Connection{
+		{ID: "c1", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "note", TargetPinID: "exec", ConnectionType: "execution"},
+	}
+	result = validator.ValidateBlueprint(bp)
+	if result.Valid || result.Errors[0].(*errors.BlueprintError).NodeID != "note" {
+		t.Errorf("Expected the connection to the comment to be rejected, got %v", result.Errors)
+	}
+}
+
 func TestDefaultValueProvider(t *testing.T) {
 	// Create error manager and recovery manager
 	errorManager := errors.NewErrorManager()
@@ -270,3 +580,57 @@ func TestDefaultValueProvider(t *testing.T) {
 		t.Errorf("Expected false as default boolean, got %v", booly)
 	}
 }
+
+func TestNodeErrors(t *testing.T) {
+	missing := errors.MissingInput("url")
+	if missing.Code != errors.ErrMissingRequiredInput || missing.PinID != "url" {
+		t.Errorf("Unexpected missing input error: %+v", missing)
+	}
+	if missing.IsRetryable() {
+		t.Error("Expected missing input errors not to be retryable")
+	}
+
+	timeout := errors.RequestFailed("HTTP request failed", context.DeadlineExceeded)
+	if timeout.Code != errors.ErrRequestTimeout || !timeout.IsRetryable() {
+		t.Errorf("Expected a retryable timeout, got %+v", timeout)
+	}
+	if !errors.IsRetryable(fmt.Errorf("fetch: %w", timeout)) {
+		t.Error("Expected wrapped timeouts to be retryable")
+	}
+
+	wrapped := errors.From(fmt.Errorf("plain error"))
+	if wrapped.Code != errors.ErrNodeExecutionFailed || wrapped.Message != "plain error" {
+		t.Errorf("Unexpected wrapped error: %+v", wrapped)
+	}
+	if errors.From(missing) != missing {
+		t.Error("Expected BlueprintErrors to be returned as is")
+	}
+
+	nodeTimeout := errors.NodeTimeout(time.Minute)
+	if nodeTimeout.Code != errors.ErrNodeTimeout || nodeTimeout.IsRetryable() {
+		t.Errorf("Expected a node timeout that isn't retryable, got %+v", nodeTimeout)
+	}
+
+	tooLarge := errors.OutputTooLarge("body", 2048, 1024)
+	if tooLarge.Code != errors.ErrResourceExhausted || tooLarge.PinID != "body" {
+		t.Errorf("Unexpected output size error: %+v", tooLarge)
+	}
+}
+
+func TestSetErrorOutput(t *testing.T) {
+	ctx := mocks.NewMockExecutionContext("fetch", "http-request", mocks.NewMockLogger())
+	bpErr := errors.SetErrorOutput(ctx, errors.InvalidInput("body", fmt.Errorf("unsupported type")))
+
+	if bpErr.NodeID != "fetch" || bpErr.ExecutionID != "test-execution" {
+		t.Errorf("Expected the error to be tagged with the node, got %+v", bpErr)
+	}
+
+	value, exists := ctx.GetOutputValue(errors.ErrorPinID)
+	if !exists {
+		t.Fatal("Expected the error pin to be set")
+	}
+	info := value.RawValue.(map[string]interface{})
+	if info["code"] != string(errors.ErrInvalidInputValue) || info["pinId"] != "body" || info["retryable"] != false {
+		t.Errorf("Unexpected error info: %+v", info)
+	}
+}
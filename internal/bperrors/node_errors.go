@@ -0,0 +1,209 @@
+package bperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// ErrorPinID is the ID of the data output through which side-effecting nodes
+// describe the error that activated their catch flow
+const ErrorPinID = "error"
+
+// CatchPinID is the ID of the execution output side-effecting nodes activate when they fail
+const CatchPinID = "catch"
+
+// ErrorPin returns the standard error data output of side-effecting nodes
+func ErrorPin() types.Pin {
+	return types.Pin{
+		ID:          ErrorPinID,
+		Name:        "Error",
+		Description: "Error information (code, message, retryable) if the node failed",
+		Type:        types.PinTypes.Object,
+	}
+}
+
+// MissingInput creates the error of a required input pin without a value
+func MissingInput(pinID string) *BlueprintError {
+	return New(
+		ErrorTypeValidation,
+		ErrMissingRequiredInput,
+		fmt.Sprintf("missing required input: %s", pinID),
+		SeverityMedium,
+	).WithNodeInfo("", pinID)
+}
+
+// InvalidInput creates the error of an input pin whose value can't be used
+func InvalidInput(pinID string, err error) *BlueprintError {
+	bpErr := New(
+		ErrorTypeValidation,
+		ErrInvalidInputValue,
+		fmt.Sprintf("invalid input %s: %v", pinID, err),
+		SeverityMedium,
+	).WithNodeInfo("", pinID)
+	bpErr.OriginalError = err
+	return bpErr
+}
+
+// OperationFailed creates the error of a node that couldn't complete its operation,
+// e.g. a division by zero or an index out of bounds
+func OperationFailed(message string) *BlueprintError {
+	return New(ErrorTypeExecution, ErrOperationFailed, message, SeverityMedium)
+}
+
+// ServiceUnavailable creates the error of a node whose context lacks a service it needs
+func ServiceUnavailable(service string) *BlueprintError {
+	return New(
+		ErrorTypeSystem,
+		ErrServiceUnavailable,
+		fmt.Sprintf("%s not available in context", service),
+		SeverityHigh,
+	)
+}
+
+// RequestFailed creates the error of a call to an external service. Timeouts and
+// network failures are retryable; other failures are not.
+func RequestFailed(message string, err error) *BlueprintError {
+	code := ErrRequestFailed
+	if isTimeout(err) {
+		code = ErrRequestTimeout
+	}
+
+	bpErr := Wrap(err, ErrorTypeNetwork, code, fmt.Sprintf("%s: %v", message, err), SeverityHigh)
+	var netErr net.Error
+	if code == ErrRequestTimeout || errors.As(err, &netErr) {
+		bpErr.WithRecoveryOptions(RecoveryRetry)
+	}
+	return bpErr
+}
+
+// InvalidResponse creates the error of a response from an external service that can't be read
+func InvalidResponse(message string, err error) *BlueprintError {
+	return Wrap(err, ErrorTypeNetwork, ErrInvalidResponse, fmt.Sprintf("%s: %v", message, err), SeverityHigh)
+}
+
+// CircuitOpen creates the error of a call short-circuited because the previous calls
+// of the node type to the host kept failing
+func CircuitOpen(nodeType, host string, retryAt time.Time) *BlueprintError {
+	return New(
+		ErrorTypeNetwork,
+		ErrCircuitOpen,
+		fmt.Sprintf("calls to %s are short-circuited after repeated failures", host),
+		SeverityMedium,
+	).WithDetails(map[string]interface{}{
+		"nodeType": nodeType,
+		"host":     host,
+		"retryAt":  retryAt.Format(time.RFC3339),
+	})
+}
+
+// NodeTimeout creates the error of a node that ran longer than its wall-clock limit. The
+// node is abandoned rather than stopped, so it isn't retried.
+func NodeTimeout(limit time.Duration) *BlueprintError {
+	return New(
+		ErrorTypeExecution,
+		ErrNodeTimeout,
+		fmt.Sprintf("node exceeded its time limit of %s", limit),
+		SeverityHigh,
+	).WithDetails(map[string]interface{}{
+		"limit": limit.String(),
+	})
+}
+
+// NodePanicked creates the error of a node that panicked while executing
+func NodePanicked(recovered interface{}, stack []byte) *BlueprintError {
+	return New(
+		ErrorTypeExecution,
+		ErrNodePanicked,
+		fmt.Sprintf("node panicked: %v", recovered),
+		SeverityCritical,
+	).WithDetails(map[string]interface{}{
+		"stack": string(stack),
+	})
+}
+
+// OutputTooLarge creates the error of an output pin value larger than the engine accepts
+func OutputTooLarge(pinID string, size, limit int) *BlueprintError {
+	return New(
+		ErrorTypeSystem,
+		ErrResourceExhausted,
+		fmt.Sprintf("output %s is %d bytes, exceeding the limit of %d bytes", pinID, size, limit),
+		SeverityHigh,
+	).WithNodeInfo("", pinID).WithDetails(map[string]interface{}{
+		"size":  size,
+		"limit": limit,
+	})
+}
+
+// From returns err as a BlueprintError, wrapping errors that aren't one as a node execution failure
+func From(err error) *BlueprintError {
+	var bpErr *BlueprintError
+	if errors.As(err, &bpErr) {
+		return bpErr
+	}
+	return Wrap(err, ErrorTypeExecution, ErrNodeExecutionFailed, err.Error(), SeverityHigh)
+}
+
+// IsRetryable checks if retrying the operation may succeed
+func (e *BlueprintError) IsRetryable() bool {
+	for _, option := range e.RecoveryOptions {
+		if option == RecoveryRetry {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable checks if err is a BlueprintError that allows a retry
+func IsRetryable(err error) bool {
+	var bpErr *BlueprintError
+	return errors.As(err, &bpErr) && bpErr.IsRetryable()
+}
+
+// PinValue returns the structured error information carried by error pins
+func (e *BlueprintError) PinValue() map[string]interface{} {
+	value := map[string]interface{}{
+		"code":      string(e.Code),
+		"type":      string(e.Type),
+		"message":   e.Message,
+		"severity":  string(e.Severity),
+		"retryable": e.IsRetryable(),
+	}
+	if e.NodeID != "" {
+		value["nodeId"] = e.NodeID
+	}
+	if e.PinID != "" {
+		value["pinId"] = e.PinID
+	}
+	if len(e.Details) > 0 {
+		value["details"] = e.Details
+	}
+	return value
+}
+
+// SetErrorOutput sets the error pin of the executing node to the structured
+// information of err and returns err as a BlueprintError of the node
+func SetErrorOutput(ctx node.ExecutionContext, err error) *BlueprintError {
+	bpErr := From(err)
+	if bpErr.NodeID == "" {
+		bpErr.NodeID = ctx.GetNodeID()
+	}
+	if bpErr.ExecutionID == "" {
+		bpErr.WithBlueprintInfo(ctx.GetBlueprintID(), ctx.GetExecutionID())
+	}
+
+	ctx.SetOutputValue(ErrorPinID, types.NewValue(types.PinTypes.Object, bpErr.PinValue()))
+	return bpErr
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -53,7 +53,7 @@ func (rm *RecoveryManager) registerDefaultValueProviders() {
 
 	// Number default provider
 	rm.RegisterDefaultValueProvider("number", func(pt *types.PinType) types.Value {
-		return types.NewValue(types.PinTypes.Number, 0)
+		return types.NewValue(types.PinTypes.Number, float64(0))
 	})
 
 	// Boolean default provider
@@ -89,18 +89,25 @@ func (rm *RecoveryManager) RecoverFromError(executionID string, err *BlueprintEr
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
-	// Get available recovery strategies for this error
+	// Get available recovery strategies for this error, falling back to the
+	// options of the error when none are registered for its code
 	strategies := rm.errorManager.GetRecoveryStrategies(err)
 	if len(strategies) == 0 || strategies[0] == RecoveryNone {
+		strategies = err.RecoveryOptions
+	}
+	if len(strategies) == 0 {
 		return false, nil
 	}
 
-	// Choose the best recovery strategy
-	// (in real implementation, this could use more sophisticated logic)
-	chosenStrategy := strategies[0]
-
-	// Check if we've already tried this strategy too many times
-	if rm.tooManyAttempts(executionID, err.NodeID, chosenStrategy) {
+	// Choose the first strategy that hasn't been tried too many times
+	var chosenStrategy RecoveryStrategy
+	for _, strategy := range strategies {
+		if strategy != RecoveryNone && !rm.tooManyAttempts(executionID, err.NodeID, strategy) {
+			chosenStrategy = strategy
+			break
+		}
+	}
+	if chosenStrategy == "" {
 		return false, map[string]interface{}{
 			"reason":      "too_many_attempts",
 			"maxAttempts": 3,
@@ -109,6 +116,9 @@ func (rm *RecoveryManager) RecoverFromError(executionID string, err *BlueprintEr
 
 	// Attempt recovery
 	success, details := rm.errorManager.AttemptRecovery(err, chosenStrategy)
+	if success {
+		details["strategy"] = string(chosenStrategy)
+	}
 
 	// Record the attempt
 	rc := RecoveryContext{
@@ -141,8 +151,8 @@ func (rm *RecoveryManager) GetDefaultValue(pinType *types.PinType) (types.Value,
 	rm.mutex.RLock()
 	defer rm.mutex.RUnlock()
 
-	// Get type name as string key
-	typeName := string(pinType.Name)
+	// Providers are registered by pin type ID
+	typeName := pinType.ID
 
 	if provider, ok := rm.defaultProviders[typeName]; ok {
 		return provider(pinType), nil
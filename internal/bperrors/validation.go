@@ -1,13 +1,22 @@
 package bperrors
 
 import (
+	"fmt"
+	"sort"
 	"webblueprint/internal/common"
+	"webblueprint/internal/expr"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
 	"webblueprint/pkg/blueprint"
 )
 
+// NodeFactoryLookup resolves the factory of a node type
+type NodeFactoryLookup func(typeID string) (node.NodeFactory, bool)
+
 // BlueprintValidator validates blueprint structures
 type BlueprintValidator struct {
-	errorManager *ErrorManager
+	errorManager  *ErrorManager
+	lookupFactory NodeFactoryLookup
 }
 
 // NewBlueprintValidator creates a new blueprint validator
@@ -17,6 +26,11 @@ func NewBlueprintValidator(errorManager *ErrorManager) *BlueprintValidator {
 	}
 }
 
+// SetNodeFactoryLookup enables pin type checks on data connections
+func (v *BlueprintValidator) SetNodeFactoryLookup(lookup NodeFactoryLookup) {
+	v.lookupFactory = lookup
+}
+
 // ValidateBlueprint performs validation checks on a blueprint
 func (v *BlueprintValidator) ValidateBlueprint(bp *blueprint.Blueprint) common.ValidationResult {
 	result := common.ValidationResult{
@@ -38,20 +52,97 @@ func (v *BlueprintValidator) ValidateBlueprint(bp *blueprint.Blueprint) common.V
 		result.Warnings = append(result.Warnings, err)
 	}
 
-	if len(bp.Nodes) == 0 {
+	// An execution budget that can't be enforced would leave every execution unbounded
+	budget := bp.GetBudget()
+	if _, err := budget.Duration(); err != nil {
+		issue := New(ErrorTypeValidation, ErrInvalidBlueprintStructure,
+			fmt.Sprintf("Invalid execution budget: %v", err), SeverityHigh)
+		result.Errors = append(result.Errors, issue.WithDetails(map[string]interface{}{
+			"maxDuration": budget.MaxDuration,
+		}))
+		result.Valid = false
+	}
+	if budget.MaxNodeExecutions < 0 {
+		issue := New(ErrorTypeValidation, ErrInvalidBlueprintStructure,
+			fmt.Sprintf("Invalid execution budget: maximum node executions %d is negative", budget.MaxNodeExecutions), SeverityHigh)
+		result.Errors = append(result.Errors, issue.WithDetails(map[string]interface{}{
+			"maxNodeExecutions": budget.MaxNodeExecutions,
+		}))
+		result.Valid = false
+	}
+
+	// Comments and frames only document the blueprint and aren't validated
+	if len(bp.ExecutableNodes()) == 0 {
 		err := New(ErrorTypeValidation, ErrInvalidBlueprintStructure, "Blueprint has no nodes", SeverityHigh)
 		result.Errors = append(result.Errors, err)
 		result.Valid = false
 	}
 
 	// Validate nodes
-	for _, node := range bp.Nodes {
+	for _, node := range bp.ExecutableNodes() {
 		nodeIssues := v.validateNode(bp, &node)
 		if len(nodeIssues) > 0 {
 			result.NodeIssues[node.ID] = nodeIssues
 		}
 	}
 
+	// Guards must parse, or every trigger of their entry point would be skipped
+	for _, node := range bp.ExecutableNodes() {
+		guard := node.GetGuard()
+		if guard == "" {
+			continue
+		}
+		if _, err := expr.Parse(guard); err != nil {
+			issue := New(ErrorTypeValidation, ErrInvalidNodeConfiguration,
+				fmt.Sprintf("Invalid entry point guard: %v", err), SeverityHigh)
+			result.Errors = append(result.Errors, issue.WithNodeInfo(node.ID, "").WithDetails(map[string]interface{}{
+				"guard": guard,
+			}))
+			result.Valid = false
+		}
+	}
+
+	// Unknown log levels fall back to the default one
+	for _, node := range bp.ExecutableNodes() {
+		level := node.GetLogLevel()
+		if level == "" || blueprint.LogLevelRank(level) >= 0 {
+			continue
+		}
+		issue := New(ErrorTypeValidation, ErrInvalidNodeConfiguration,
+			fmt.Sprintf("Unknown log level %q, expected debug, info, warn or error", level), SeverityLow)
+		result.Warnings = append(result.Warnings, issue.WithNodeInfo(node.ID, "").WithDetails(map[string]interface{}{
+			"logLevel": level,
+		}))
+	}
+
+	// Pins that several data connections lead to should say which value they take
+	for _, node := range bp.ExecutableNodes() {
+		for _, issue := range v.validateFanIn(bp, node) {
+			if issue.Severity == SeverityHigh {
+				result.Errors = append(result.Errors, issue)
+				result.Valid = false
+			} else {
+				result.Warnings = append(result.Warnings, issue)
+			}
+		}
+	}
+
+	// The pins of nodes with dynamic pins must be declared by valid node data
+	for _, node := range bp.ExecutableNodes() {
+		if issue := v.validatePinConfiguration(node); issue != nil {
+			result.Errors = append(result.Errors, issue)
+			result.Valid = false
+		}
+	}
+
+	// Property values must be accepted by the schemas of their node types
+	for _, node := range bp.ExecutableNodes() {
+		for _, issue := range v.validateProperties(node) {
+			result.Errors = append(result.Errors, issue)
+			result.Valid = false
+		}
+	}
+
 	// Validate connections
 	connectionIssues := v.validateConnections(bp)
 	if len(connectionIssues) > 0 {
@@ -130,11 +221,293 @@ func (v *BlueprintValidator) validateConnections(bp *blueprint.Blueprint) []*Blu
 			issues = append(issues, err)
 			continue
 		}
+
+		if sourceNode.IsAnnotation() || targetNode.IsAnnotation() {
+			err := New(ErrorTypeValidation, ErrInvalidConnection,
+				"Connection references a comment node, which has no pins", SeverityHigh)
+			annotation := sourceNode
+			if targetNode.IsAnnotation() {
+				annotation = targetNode
+			}
+			err.WithNodeInfo(annotation.ID, "").WithDetails(map[string]interface{}{"connectionId": conn.ID})
+			issues = append(issues, err)
+			continue
+		}
+
+		if issue := v.validateConnectionPins(conn, sourceNode, targetNode); issue != nil {
+			issues = append(issues, issue)
+			continue
+		}
+
+		if transform := conn.GetTransform(); transform != "" {
+			// A transform can change the type of the value, so only the expression is checked
+			if _, err := expr.Parse(transform); err != nil {
+				issue := New(ErrorTypeConnection, ErrInvalidTransform,
+					fmt.Sprintf("Invalid connection transform: %v", err), SeverityHigh)
+				issues = append(issues, issue.WithNodeInfo(conn.TargetNodeID, conn.TargetPinID).WithDetails(map[string]interface{}{
+					"connectionId": conn.ID,
+					"transform":    transform,
+				}))
+			}
+		} else if conn.ConnectionType == "data" {
+			if err := v.validateConnectionTypes(conn, sourceNode, targetNode); err != nil {
+				issues = append(issues, err)
+			}
+		}
+
+		if condition := conn.GetCondition(); condition != "" {
+			if _, err := expr.Parse(condition); err != nil {
+				issue := New(ErrorTypeConnection, ErrInvalidCondition,
+					fmt.Sprintf("Invalid connection condition: %v", err), SeverityHigh)
+				issues = append(issues, issue.WithNodeInfo(conn.SourceNodeID, conn.SourcePinID).WithDetails(map[string]interface{}{
+					"connectionId": conn.ID,
+					"condition":    condition,
+				}))
+			}
+		}
+	}
+
+	return append(issues, v.validateConditionCoverage(bp)...)
+}
+
+// validateConditionCoverage warns about the execution pins whose connections all have
+// conditions, as the flow stops there when none of the conditions holds. Adding an
+// unconditional connection to the pin covers the remaining cases.
+func (v *BlueprintValidator) validateConditionCoverage(bp *blueprint.Blueprint) []*BlueprintError {
+	type pinKey struct{ nodeID, pinID string }
+
+	conditions := make(map[pinKey][]string)
+	covered := make(map[pinKey]bool)
+	order := make([]pinKey, 0)
+	for _, conn := range bp.Connections {
+		if conn.ConnectionType != "execution" {
+			continue
+		}
+
+		key := pinKey{conn.SourceNodeID, conn.SourcePinID}
+		if _, seen := conditions[key]; !seen && !covered[key] {
+			order = append(order, key)
+		}
+		if condition := conn.GetCondition(); condition != "" {
+			conditions[key] = append(conditions[key], condition)
+		} else {
+			covered[key] = true
+		}
+	}
+
+	issues := make([]*BlueprintError, 0)
+	for _, key := range order {
+		if covered[key] || len(conditions[key]) == 0 {
+			continue
+		}
+
+		err := New(ErrorTypeConnection, ErrInvalidCondition,
+			"The conditions of the connections from this pin may not cover all cases, so the flow can stop here", SeverityLow)
+		issues = append(issues, err.WithNodeInfo(key.nodeID, key.pinID).WithDetails(map[string]interface{}{
+			"conditions": conditions[key],
+		}))
 	}
 
 	return issues
 }
 
+// validateFanIn checks the fan-in policies of a node's input pins, and warns about pins that
+// several data connections lead to without one
+func (v *BlueprintValidator) validateFanIn(bp *blueprint.Blueprint, node blueprint.BlueprintNode) []*BlueprintError {
+	issues := make([]*BlueprintError, 0)
+
+	connections := make(map[string]int)
+	for _, conn := range bp.GetNodeInputConnections(node.ID) {
+		if conn.ConnectionType == "data" {
+			connections[conn.TargetPinID]++
+		}
+	}
+
+	policies := node.GetFanInPolicies()
+	pinIDs := make([]string, 0, len(policies)+len(connections))
+	for pinID := range policies {
+		pinIDs = append(pinIDs, pinID)
+	}
+	for pinID := range connections {
+		if _, exists := policies[pinID]; !exists {
+			pinIDs = append(pinIDs, pinID)
+		}
+	}
+	sort.Strings(pinIDs)
+
+	for _, pinID := range pinIDs {
+		policy, hasPolicy := policies[pinID]
+		switch {
+		case hasPolicy && !blueprint.IsFanInPolicy(policy):
+			issue := New(ErrorTypeValidation, ErrInvalidNodeConfiguration,
+				fmt.Sprintf("Unknown fan-in policy %q, expected last, first, merge or collect", policy), SeverityHigh)
+			issues = append(issues, issue.WithNodeInfo(node.ID, pinID).WithDetails(map[string]interface{}{
+				"fanIn": policy,
+			}))
+		case hasPolicy && connections[pinID] == 0:
+			issue := New(ErrorTypeValidation, ErrInvalidNodeConfiguration,
+				"Fan-in policy set on a pin no data connection leads to", SeverityLow)
+			issues = append(issues, issue.WithNodeInfo(node.ID, pinID).WithDetails(map[string]interface{}{
+				"fanIn": policy,
+			}))
+		case !hasPolicy && connections[pinID] > 1:
+			issue := New(ErrorTypeValidation, ErrInvalidNodeConfiguration,
+				fmt.Sprintf("%d data connections lead to the pin without a fan-in policy, it takes the last value received", connections[pinID]), SeverityLow)
+			issues = append(issues, issue.WithNodeInfo(node.ID, pinID).WithDetails(map[string]interface{}{
+				"connections": connections[pinID],
+			}))
+		}
+	}
+
+	return issues
+}
+
+// validateProperties checks the property values of a node against the property schemas of
+// its type, if the type is known
+func (v *BlueprintValidator) validateProperties(bpNode blueprint.BlueprintNode) []*BlueprintError {
+	if v.lookupFactory == nil {
+		return nil
+	}
+	factory, exists := v.lookupFactory(bpNode.Type)
+	if !exists {
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(bpNode.Properties))
+	for _, property := range bpNode.Properties {
+		values[property.Name] = property.Value
+	}
+
+	issues := make([]*BlueprintError, 0)
+	for _, schema := range node.PropertySchemas(factory()) {
+		if err := schema.Validate(values[schema.Name]); err != nil {
+			issue := New(ErrorTypeValidation, ErrInvalidPropertyValue, err.Error(), SeverityHigh)
+			issues = append(issues, issue.WithNodeInfo(bpNode.ID, "").WithDetails(map[string]interface{}{
+				"property": schema.Name,
+				"nodeType": bpNode.Type,
+			}))
+		}
+	}
+	return issues
+}
+
+// validateConnectionTypes checks a data connection against the coercion rules.
+// Lossy connections produce a warning, incompatible connections an error.
+func (v *BlueprintValidator) validateConnectionTypes(conn blueprint.Connection, sourceNode, targetNode *blueprint.BlueprintNode) *BlueprintError {
+	sourceType := v.findPinType(sourceNode, conn.SourcePinID, true)
+	targetType := v.findPinType(targetNode, conn.TargetPinID, false)
+	if sourceType == nil || targetType == nil {
+		return nil
+	}
+
+	var err *BlueprintError
+	switch types.CheckCompatibility(sourceType, targetType) {
+	case types.CompatibilityIncompatible:
+		err = New(ErrorTypeConnection, ErrTypeMismatch,
+			fmt.Sprintf("Incompatible pin types: %s -> %s", sourceType.Name, targetType.Name), SeverityHigh)
+	case types.CompatibilityLossy:
+		err = New(ErrorTypeConnection, ErrTypeMismatch,
+			fmt.Sprintf("Lossy conversion: %s -> %s may fail at runtime", sourceType.Name, targetType.Name), SeverityLow)
+	default:
+		return nil
+	}
+
+	return err.WithNodeInfo(conn.TargetNodeID, conn.TargetPinID).WithDetails(map[string]interface{}{
+		"sourceNodeId": conn.SourceNodeID,
+		"sourcePinId":  conn.SourcePinID,
+		"sourceType":   sourceType.ID,
+		"targetType":   targetType.ID,
+	})
+}
+
+// findPinType returns the declared type of a node pin, or nil if it's unknown
+func (v *BlueprintValidator) findPinType(bpNode *blueprint.BlueprintNode, pinID string, output bool) *types.PinType {
+	instance := v.nodeInstance(bpNode)
+	if instance == nil {
+		return nil
+	}
+
+	pins := instance.GetInputPins()
+	if output {
+		pins = instance.GetOutputPins()
+	}
+	if pin, found := types.FindPin(pins, pinID); found {
+		return pin.Type
+	}
+	return nil
+}
+
+// nodeInstance creates an instance of the type of a blueprint node with the pins its data
+// declares, or returns nil if the type is unknown or the data is invalid
+func (v *BlueprintValidator) nodeInstance(bpNode *blueprint.BlueprintNode) node.Node {
+	if v.lookupFactory == nil {
+		return nil
+	}
+	factory, exists := v.lookupFactory(bpNode.Type)
+	if !exists {
+		return nil
+	}
+
+	instance := factory()
+	if node.ConfigurePins(instance, bpNode.Data) != nil {
+		return nil
+	}
+	return instance
+}
+
+// validatePinConfiguration checks that the data of a node with dynamic pins declares them
+// correctly, e.g. that the pin count of a variadic node is in range
+func (v *BlueprintValidator) validatePinConfiguration(bpNode blueprint.BlueprintNode) *BlueprintError {
+	if v.lookupFactory == nil {
+		return nil
+	}
+	factory, exists := v.lookupFactory(bpNode.Type)
+	if !exists {
+		return nil
+	}
+
+	if err := node.ConfigurePins(factory(), bpNode.Data); err != nil {
+		issue := New(ErrorTypeValidation, ErrInvalidNodeConfiguration,
+			fmt.Sprintf("Invalid pin configuration: %v", err), SeverityHigh)
+		return issue.WithNodeInfo(bpNode.ID, "").WithDetails(map[string]interface{}{
+			"nodeType": bpNode.Type,
+		})
+	}
+	return nil
+}
+
+// validateConnectionPins checks that the pins a connection joins exist on nodes with dynamic
+// pins, whose pins depend on their data rather than their type, e.g. after the pin count of
+// a variadic node was lowered
+func (v *BlueprintValidator) validateConnectionPins(conn blueprint.Connection, sourceNode, targetNode *blueprint.BlueprintNode) *BlueprintError {
+	check := func(bpNode *blueprint.BlueprintNode, pinID string, output bool) *BlueprintError {
+		instance := v.nodeInstance(bpNode)
+		if _, dynamic := instance.(node.DynamicPinNode); !dynamic {
+			return nil
+		}
+
+		pins, side := instance.GetInputPins(), "input"
+		if output {
+			pins, side = instance.GetOutputPins(), "output"
+		}
+		if _, found := types.FindPin(pins, pinID); found {
+			return nil
+		}
+
+		issue := New(ErrorTypeValidation, ErrInvalidConnection,
+			fmt.Sprintf("Connection references %s pin %s, which the node data doesn't declare", side, pinID), SeverityHigh)
+		return issue.WithNodeInfo(bpNode.ID, pinID).WithDetails(map[string]interface{}{
+			"connectionId": conn.ID,
+			"nodeType":     bpNode.Type,
+		})
+	}
+
+	if issue := check(sourceNode, conn.SourcePinID, true); issue != nil {
+		return issue
+	}
+	return check(targetNode, conn.TargetPinID, false)
+}
+
 // checkForCycles detects circular dependencies in the blueprint
 func (v *BlueprintValidator) checkForCycles(bp *blueprint.Blueprint) bool {
 	// Create a directed graph representation of the blueprint
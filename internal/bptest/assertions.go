@@ -0,0 +1,77 @@
+package bptest
+
+import (
+	"sync"
+	"time"
+)
+
+// AssertionKind identifies the kind of check an assertion node performed
+type AssertionKind string
+
+const (
+	KindEqual  AssertionKind = "expect-equal"  // Actual value compared to an expected value
+	KindError  AssertionKind = "expect-error"  // An error value was expected
+	KindCalled AssertionKind = "expect-called" // A flow was expected to be reached
+)
+
+// AssertionResult is the outcome of a single assertion made during a test execution
+type AssertionResult struct {
+	NodeID    string        `json:"nodeId"`
+	Kind      AssertionKind `json:"kind"`
+	Passed    bool          `json:"passed"`
+	Message   string        `json:"message,omitempty"`
+	Expected  interface{}   `json:"expected,omitempty"`
+	Actual    interface{}   `json:"actual,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Recorder accumulates assertion results per execution ID
+type Recorder struct {
+	executions map[string][]AssertionResult
+	mutex      sync.Mutex
+}
+
+// NewRecorder creates a new assertion recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		executions: make(map[string][]AssertionResult),
+	}
+}
+
+// Record adds an assertion result to an execution
+func (r *Recorder) Record(executionID string, result AssertionResult) {
+	if executionID == "" {
+		return
+	}
+	if result.Timestamp.IsZero() {
+		result.Timestamp = time.Now()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.executions[executionID] = append(r.executions[executionID], result)
+}
+
+// Collect returns the assertion results of an execution and stops tracking it
+func (r *Recorder) Collect(executionID string) []AssertionResult {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	results := r.executions[executionID]
+	delete(r.executions, executionID)
+	return results
+}
+
+// defaultRecorder is the process-wide recorder used by assertion nodes and the runner
+var defaultRecorder = NewRecorder()
+
+// Record adds an assertion result to an execution on the default recorder
+func Record(executionID string, result AssertionResult) {
+	defaultRecorder.Record(executionID, result)
+}
+
+// Collect returns and clears the assertion results of an execution on the default recorder
+func Collect(executionID string) []AssertionResult {
+	return defaultRecorder.Collect(executionID)
+}
@@ -0,0 +1,259 @@
+package bptest
+
+import (
+	"webblueprint/internal/engine"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// CoverageItem is a node, connection or branch of the blueprint under test
+type CoverageItem struct {
+	ID     string `json:"id"` // Node ID, connection ID, or "nodeId.pinId" for branches
+	NodeID string `json:"nodeId"`
+	PinID  string `json:"pinId,omitempty"`
+	Hits   int    `json:"hits"`
+}
+
+// CoverageSet tracks which items of one kind were exercised
+type CoverageSet struct {
+	Total   int            `json:"total"`
+	Covered int            `json:"covered"`
+	Percent float64        `json:"percent"`
+	Items   []CoverageItem `json:"items"`
+}
+
+// Uncovered returns the items that weren't exercised
+func (s *CoverageSet) Uncovered() []CoverageItem {
+	uncovered := make([]CoverageItem, 0)
+	for _, item := range s.Items {
+		if item.Hits == 0 {
+			uncovered = append(uncovered, item)
+		}
+	}
+	return uncovered
+}
+
+// hit adds hits to an item of the set
+func (s *CoverageSet) hit(id string, hits int) {
+	for i := range s.Items {
+		if s.Items[i].ID == id {
+			s.Items[i].Hits += hits
+			return
+		}
+	}
+}
+
+// summarize updates the totals of the set from its items
+func (s *CoverageSet) summarize() {
+	s.Total = len(s.Items)
+	s.Covered = 0
+	for _, item := range s.Items {
+		if item.Hits > 0 {
+			s.Covered++
+		}
+	}
+
+	s.Percent = 100
+	if s.Total > 0 {
+		s.Percent = float64(s.Covered) * 100 / float64(s.Total)
+	}
+}
+
+// Coverage reports which nodes, connections and branches of a blueprint were exercised
+// by its tests. A branch is an execution output of a node with several of them, e.g.
+// the true and false pins of an if-condition. Assertion nodes aren't counted.
+type Coverage struct {
+	BlueprintID string      `json:"blueprintId"`
+	Nodes       CoverageSet `json:"nodes"`
+	Connections CoverageSet `json:"connections"`
+	Branches    CoverageSet `json:"branches"`
+}
+
+// NewCoverage creates an empty coverage report listing every item of the blueprint
+func NewCoverage(bp *blueprint.Blueprint) *Coverage {
+	coverage := &Coverage{
+		BlueprintID: bp.ID,
+		Nodes:       CoverageSet{Items: make([]CoverageItem, 0)},
+		Connections: CoverageSet{Items: make([]CoverageItem, 0)},
+		Branches:    CoverageSet{Items: make([]CoverageItem, 0)},
+	}
+
+	for _, bpNode := range bp.ExecutableNodes() {
+		if isAssertionNode(bp, bpNode.ID) {
+			continue
+		}
+		coverage.Nodes.Items = append(coverage.Nodes.Items, CoverageItem{ID: bpNode.ID, NodeID: bpNode.ID})
+
+		for _, pinID := range branchPins(bp, bpNode) {
+			coverage.Branches.Items = append(coverage.Branches.Items, CoverageItem{
+				ID:     branchID(bpNode.ID, pinID),
+				NodeID: bpNode.ID,
+				PinID:  pinID,
+			})
+		}
+	}
+
+	for _, conn := range bp.Connections {
+		if isAssertionNode(bp, conn.SourceNodeID) || isAssertionNode(bp, conn.TargetNodeID) {
+			continue
+		}
+		coverage.Connections.Items = append(coverage.Connections.Items, CoverageItem{
+			ID:     conn.ID,
+			NodeID: conn.SourceNodeID,
+			PinID:  conn.SourcePinID,
+		})
+	}
+
+	coverage.summarize()
+	return coverage
+}
+
+// Record adds the nodes, connections and branches exercised by an execution. Execution
+// connections are taken from the pin activations of the timeline; data connections
+// count as exercised when the nodes on both ends ran.
+func (c *Coverage) Record(bp *blueprint.Blueprint, timeline *engine.ExecutionTimeline, nodeResults map[string]map[string]interface{}) {
+	executed := make(map[string]int)
+	activations := make(map[string]int) // "source.pin>target.pin" -> count
+	branches := make(map[string]int)
+
+	if timeline != nil {
+		for _, span := range timeline.Spans {
+			executed[span.NodeID]++
+			if span.TriggeredBy == nil {
+				continue
+			}
+			trigger := span.TriggeredBy
+			activations[activationKey(trigger.SourceNodeID, trigger.SourcePinID, span.NodeID, trigger.TargetPinID)]++
+			branches[branchID(trigger.SourceNodeID, trigger.SourcePinID)]++
+		}
+	}
+
+	// Nodes that only produce data, e.g. variable getters, don't show up in the timeline
+	for nodeID := range nodeResults {
+		if executed[nodeID] == 0 {
+			executed[nodeID] = 1
+		}
+	}
+
+	for nodeID, hits := range executed {
+		c.Nodes.hit(nodeID, hits)
+	}
+
+	for _, conn := range bp.Connections {
+		if conn.ConnectionType == "execution" {
+			if hits := activations[activationKey(conn.SourceNodeID, conn.SourcePinID, conn.TargetNodeID, conn.TargetPinID)]; hits > 0 {
+				c.Connections.hit(conn.ID, hits)
+			}
+			continue
+		}
+
+		if executed[conn.SourceNodeID] > 0 && executed[conn.TargetNodeID] > 0 {
+			c.Connections.hit(conn.ID, executed[conn.TargetNodeID])
+		}
+	}
+
+	for id, hits := range branches {
+		c.Branches.hit(id, hits)
+	}
+
+	c.summarize()
+}
+
+// Merge adds the hits of another coverage report of the same blueprint
+func (c *Coverage) Merge(other *Coverage) {
+	if other == nil {
+		return
+	}
+
+	for _, item := range other.Nodes.Items {
+		c.Nodes.hit(item.ID, item.Hits)
+	}
+	for _, item := range other.Connections.Items {
+		c.Connections.hit(item.ID, item.Hits)
+	}
+	for _, item := range other.Branches.Items {
+		c.Branches.hit(item.ID, item.Hits)
+	}
+
+	c.summarize()
+}
+
+// summarize updates the totals of every set
+func (c *Coverage) summarize() {
+	c.Nodes.summarize()
+	c.Connections.summarize()
+	c.Branches.summarize()
+}
+
+// branchPins returns the connected execution outputs of a node with several execution outputs
+func branchPins(bp *blueprint.Blueprint, bpNode blueprint.BlueprintNode) []string {
+	connected := make(map[string]bool)
+	for _, conn := range bp.GetNodeOutputConnections(bpNode.ID) {
+		if conn.ConnectionType == "execution" {
+			connected[conn.SourcePinID] = true
+		}
+	}
+
+	pins := executionOutputs(bp, bpNode)
+	if len(pins) < 2 {
+		return nil
+	}
+
+	branches := make([]string, 0, len(pins))
+	for _, pinID := range pins {
+		if connected[pinID] {
+			branches = append(branches, pinID)
+		}
+	}
+	return branches
+}
+
+// executionOutputs returns the execution output pins of a node, preferring the
+// pins of its registered node type over the pins its connections use
+func executionOutputs(bp *blueprint.Blueprint, bpNode blueprint.BlueprintNode) []string {
+	pins := make([]string, 0)
+
+	if nodeRegistry := registry.GetInstance(); nodeRegistry != nil {
+		if factory, exists := nodeRegistry.GetNodeFactory(bpNode.Type); exists {
+			for _, pin := range factory().GetOutputPins() {
+				if pin.Type == types.PinTypes.Execution {
+					pins = append(pins, pin.ID)
+				}
+			}
+			return pins
+		}
+	}
+
+	// Unknown node types are judged by their connections
+	seen := make(map[string]bool)
+	for _, conn := range bp.GetNodeOutputConnections(bpNode.ID) {
+		if conn.ConnectionType == "execution" && !seen[conn.SourcePinID] {
+			seen[conn.SourcePinID] = true
+			pins = append(pins, conn.SourcePinID)
+		}
+	}
+	return pins
+}
+
+// isAssertionNode checks if a node is part of the test harness rather than the blueprint logic
+func isAssertionNode(bp *blueprint.Blueprint, nodeID string) bool {
+	bpNode := bp.FindNode(nodeID)
+	if bpNode == nil {
+		return false
+	}
+
+	switch AssertionKind(bpNode.Type) {
+	case KindEqual, KindError, KindCalled:
+		return true
+	}
+	return false
+}
+
+func branchID(nodeID, pinID string) string {
+	return nodeID + "." + pinID
+}
+
+func activationKey(sourceNodeID, sourcePinID, targetNodeID, targetPinID string) string {
+	return branchID(sourceNodeID, sourcePinID) + ">" + branchID(targetNodeID, targetPinID)
+}
@@ -0,0 +1,137 @@
+package bptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit writes the suite results as a JUnit XML report for CI systems
+func WriteJUnit(w io.Writer, suites []*SuiteResult) error {
+	report := junitTestSuites{
+		Suites: make([]junitTestSuite, 0, len(suites)),
+	}
+
+	var total time.Duration
+	for _, suite := range suites {
+		junitSuite := junitTestSuite{
+			Name:      suite.Name,
+			Tests:     suite.Tests,
+			Failures:  suite.Failures,
+			Errors:    suite.Errors,
+			Time:      junitSeconds(suite.Duration),
+			Timestamp: suite.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+			Cases:     make([]junitTestCase, 0, len(suite.Cases)),
+		}
+
+		for i := range suite.Cases {
+			tc := &suite.Cases[i]
+			junitCase := junitTestCase{
+				Name:      tc.Name,
+				ClassName: suite.BlueprintID,
+				Time:      junitSeconds(tc.Duration),
+			}
+
+			switch tc.Status {
+			case StatusFailed:
+				junitCase.Failure = failureMessage(tc)
+			case StatusError:
+				junitCase.Error = &junitMessage{
+					Message: tc.Error,
+					Type:    "ExecutionError",
+					Content: tc.Error,
+				}
+			}
+
+			junitSuite.Cases = append(junitSuite.Cases, junitCase)
+		}
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		total += suite.Duration
+		report.Suites = append(report.Suites, junitSuite)
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode junit report: %w", err)
+	}
+	return nil
+}
+
+// failureMessage summarizes the failed expectations of a test case
+func failureMessage(tc *CaseResult) *junitMessage {
+	lines := make([]string, 0)
+	if tc.Error != "" {
+		lines = append(lines, tc.Error)
+	}
+	for _, failure := range tc.Failures() {
+		line := fmt.Sprintf("%s (%s)", failure.Kind, failure.NodeID)
+		if failure.Message != "" {
+			line += ": " + failure.Message
+		}
+		if failure.Expected != nil || failure.Actual != nil {
+			line += fmt.Sprintf(" expected=%v actual=%v", failure.Expected, failure.Actual)
+		}
+		lines = append(lines, line)
+	}
+
+	message := "test case failed"
+	if len(lines) > 0 {
+		message = lines[0]
+	}
+
+	return &junitMessage{
+		Message: message,
+		Type:    "AssertionFailure",
+		Content: strings.Join(lines, "\n"),
+	}
+}
+
+// junitSeconds formats a duration as seconds, as JUnit reports expect
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
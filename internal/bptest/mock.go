@@ -0,0 +1,104 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+	"webblueprint/internal/node"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// NodeMock replaces a node of the blueprint under test with canned results,
+// e.g. the response of an http-request node or the rows of a db-query node
+type NodeMock struct {
+	NodeID  string                 `json:"nodeId"`
+	Outputs map[string]interface{} `json:"outputs,omitempty"` // Output pin ID -> value
+	Flow    string                 `json:"flow,omitempty"`    // Output flow to activate, defaults to the node's first one
+	Error   string                 `json:"error,omitempty"`   // Fails the node with this message instead
+}
+
+// MockNode stands in for a mocked node. It keeps the pins and properties of the
+// node it replaces, so connections and input coercion behave the same.
+type MockNode struct {
+	node.Node
+	mock NodeMock
+}
+
+// NewMockFactory creates a factory for a mock of a node created by the original factory
+func NewMockFactory(original node.NodeFactory, mock NodeMock) node.NodeFactory {
+	return func() node.Node {
+		return &MockNode{
+			Node: original(),
+			mock: mock,
+		}
+	}
+}
+
+// Execute sets the mocked outputs and activates the mocked flow
+func (n *MockNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing mocked node", map[string]interface{}{
+		"nodeType": n.GetMetadata().TypeID,
+	})
+
+	if n.mock.Error != "" {
+		return errors.New(n.mock.Error)
+	}
+
+	for pinID, value := range n.mock.Outputs {
+		pinType := types.InferPinType(value)
+		if pin, exists := types.FindPin(n.GetOutputPins(), pinID); exists && pin.Type != nil {
+			pinType = pin.Type
+		}
+		ctx.SetOutputValue(pinID, types.NewValue(pinType, value))
+	}
+
+	flow := n.mock.Flow
+	if flow == "" {
+		for _, pin := range n.GetOutputPins() {
+			if pin.Type == types.PinTypes.Execution {
+				flow = pin.ID
+				break
+			}
+		}
+	}
+	if flow == "" {
+		return nil
+	}
+
+	return ctx.ActivateOutputFlow(flow)
+}
+
+// buildOverrides creates the node overrides for the mocks of a test case
+func buildOverrides(bp *blueprint.Blueprint, mocks []NodeMock) (map[string]node.NodeFactory, error) {
+	overrides := make(map[string]node.NodeFactory, len(mocks))
+	for _, mock := range mocks {
+		nodeConfig := bp.FindNode(mock.NodeID)
+		if nodeConfig == nil {
+			return nil, fmt.Errorf("mocked node not found: %s", mock.NodeID)
+		}
+
+		original, exists := registry.GetInstance().GetNodeFactory(nodeConfig.Type)
+		if !exists {
+			return nil, fmt.Errorf("node type not registered: %s", nodeConfig.Type)
+		}
+
+		overrides[mock.NodeID] = NewMockFactory(original, mock)
+	}
+	return overrides, nil
+}
+
+// mergeMocks combines the mocks of a definition with those of a case; case mocks win
+func mergeMocks(definitionMocks, caseMocks []NodeMock) []NodeMock {
+	merged := make([]NodeMock, 0, len(definitionMocks)+len(caseMocks))
+	overridden := make(map[string]bool, len(caseMocks))
+	for _, mock := range caseMocks {
+		overridden[mock.NodeID] = true
+	}
+	for _, mock := range definitionMocks {
+		if !overridden[mock.NodeID] {
+			merged = append(merged, mock)
+		}
+	}
+	return append(merged, caseMocks...)
+}
@@ -0,0 +1,256 @@
+package bptest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCaseTimeout bounds a test case that doesn't specify a timeout
+const DefaultCaseTimeout = 30 * time.Second
+
+// Test case statuses
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed" // An assertion or the error expectation failed
+	StatusError  = "error"  // The execution failed unexpectedly
+)
+
+// TestCase is a single execution of the blueprint under test
+type TestCase struct {
+	Name                 string                 `json:"name"`
+	Inputs               map[string]interface{} `json:"inputs,omitempty"`
+	ExpectError          bool                   `json:"expectError,omitempty"`
+	ExpectedErrorMessage string                 `json:"expectedErrorMessage,omitempty"`
+	TimeoutMs            int                    `json:"timeoutMs,omitempty"`
+	Mocks                []NodeMock             `json:"mocks,omitempty"`
+}
+
+// Definition describes a test of a blueprint. The assertions live in the
+// blueprint itself as assertion nodes; the cases provide the inputs. Mocks of
+// the definition apply to every case unless a case mocks the same node.
+type Definition struct {
+	ID          string     `json:"id,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	BlueprintID string     `json:"blueprintId"`
+	Mocks       []NodeMock `json:"mocks,omitempty"`
+	Cases       []TestCase `json:"cases"`
+}
+
+// CaseResult is the outcome of a single test case
+type CaseResult struct {
+	Name        string            `json:"name"`
+	ExecutionID string            `json:"executionId"`
+	Status      string            `json:"status"`
+	Error       string            `json:"error,omitempty"`
+	Duration    time.Duration     `json:"duration"`
+	Assertions  []AssertionResult `json:"assertions"`
+}
+
+// Failures returns the assertions of the case that didn't pass
+func (c *CaseResult) Failures() []AssertionResult {
+	failures := make([]AssertionResult, 0)
+	for _, assertion := range c.Assertions {
+		if !assertion.Passed {
+			failures = append(failures, assertion)
+		}
+	}
+	return failures
+}
+
+// SuiteResult is the outcome of running all cases of a test definition
+type SuiteResult struct {
+	TestID      string        `json:"testId,omitempty"`
+	Name        string        `json:"name"`
+	BlueprintID string        `json:"blueprintId"`
+	Tests       int           `json:"tests"`
+	Passed      int           `json:"passed"`
+	Failures    int           `json:"failures"`
+	Errors      int           `json:"errors"`
+	Duration    time.Duration `json:"duration"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Cases       []CaseResult  `json:"cases"`
+	Coverage    *Coverage     `json:"coverage"`
+}
+
+// Runner executes blueprint tests on a dedicated execution engine, so test runs
+// are kept apart from the recorded executions of the workspace
+type Runner struct {
+	engine *engine.ExecutionEngine
+}
+
+// NewRunner creates a new test runner
+func NewRunner(logger node.Logger) *Runner {
+	return &Runner{
+		engine: engine.NewExecutionEngine(logger, engine.NewDebugManager()),
+	}
+}
+
+// Run executes every case of a definition against the blueprint.
+// A definition without cases runs the blueprint once without inputs.
+func (r *Runner) Run(bp *blueprint.Blueprint, def *Definition) *SuiteResult {
+	suite := &SuiteResult{
+		TestID:      def.ID,
+		Name:        def.Name,
+		BlueprintID: bp.ID,
+		Timestamp:   time.Now(),
+		Cases:       make([]CaseResult, 0, len(def.Cases)),
+		Coverage:    NewCoverage(bp),
+	}
+	if suite.Name == "" {
+		suite.Name = bp.Name
+	}
+
+	cases := def.Cases
+	if len(cases) == 0 {
+		cases = []TestCase{{Name: "default"}}
+	}
+
+	for i, tc := range cases {
+		if tc.Name == "" {
+			tc.Name = fmt.Sprintf("case %d", i+1)
+		}
+
+		result := r.runCase(bp, tc, mergeMocks(def.Mocks, tc.Mocks), suite.Coverage)
+		switch result.Status {
+		case StatusPassed:
+			suite.Passed++
+		case StatusFailed:
+			suite.Failures++
+		default:
+			suite.Errors++
+		}
+		suite.Tests++
+		suite.Duration += result.Duration
+		suite.Cases = append(suite.Cases, result)
+	}
+
+	return suite
+}
+
+// runCase executes the blueprint once with the mocked nodes swapped in, evaluates its
+// assertions and adds what the execution exercised to the coverage
+func (r *Runner) runCase(bp *blueprint.Blueprint, tc TestCase, mocks []NodeMock, coverage *Coverage) CaseResult {
+	executionID := fmt.Sprintf("test-%s", uuid.New().String())
+	result := CaseResult{
+		Name:        tc.Name,
+		ExecutionID: executionID,
+		Assertions:  make([]AssertionResult, 0),
+	}
+
+	overrides, err := buildOverrides(bp, mocks)
+	if err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+		return result
+	}
+	r.engine.SetNodeOverrides(executionID, overrides)
+
+	variables := make(map[string]types.Value, len(tc.Inputs))
+	for name, value := range tc.Inputs {
+		variables[name] = types.NewValue(types.InferPinType(value), value)
+	}
+
+	timeout := DefaultCaseTimeout
+	if tc.TimeoutMs > 0 {
+		timeout = time.Duration(tc.TimeoutMs) * time.Millisecond
+	}
+
+	startedAt := time.Now()
+	execResult, err := r.engine.ExecuteWithLimits(engine.PriorityNormal, bp, executionID, variables, engine.ExecutionLimits{
+		MaxDuration: timeout,
+	})
+	result.Duration = time.Since(startedAt)
+	result.Assertions = evaluateCalls(bp, Collect(executionID))
+
+	timeline, _ := r.engine.GetExecutionTimeline(executionID)
+	coverage.Record(bp, timeline, execResult.NodeResults)
+
+	switch {
+	case tc.ExpectError && err == nil:
+		result.Status = StatusFailed
+		result.Error = "expected the execution to fail, but it succeeded"
+	case tc.ExpectError && tc.ExpectedErrorMessage != "" && !strings.Contains(err.Error(), tc.ExpectedErrorMessage):
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("expected an error containing %q, got: %v", tc.ExpectedErrorMessage, err)
+	case !tc.ExpectError && err != nil:
+		result.Status = StatusError
+		result.Error = err.Error()
+	case len(result.Failures()) > 0:
+		result.Status = StatusFailed
+	default:
+		result.Status = StatusPassed
+	}
+
+	return result
+}
+
+// evaluateCalls replaces the calls recorded by expect-called nodes with one assertion
+// per node, comparing the number of calls to the node's "times" property. Nodes that
+// were never reached are only known from the blueprint, so every node is checked.
+func evaluateCalls(bp *blueprint.Blueprint, recorded []AssertionResult) []AssertionResult {
+	calls := make(map[string]int)
+	labels := make(map[string]string)
+	results := make([]AssertionResult, 0, len(recorded))
+
+	for _, assertion := range recorded {
+		if assertion.Kind == KindCalled {
+			calls[assertion.NodeID]++
+			labels[assertion.NodeID] = assertion.Message
+			continue
+		}
+		results = append(results, assertion)
+	}
+
+	for _, bpNode := range bp.Nodes {
+		if bpNode.Type != string(KindCalled) {
+			continue
+		}
+
+		actual := calls[bpNode.ID]
+		assertion := AssertionResult{
+			NodeID:    bpNode.ID,
+			Kind:      KindCalled,
+			Actual:    actual,
+			Message:   labels[bpNode.ID],
+			Timestamp: time.Now(),
+		}
+
+		if times, exact := expectedCalls(bpNode); exact {
+			assertion.Expected = times
+			assertion.Passed = actual == times
+		} else {
+			assertion.Expected = "at least once"
+			assertion.Passed = actual > 0
+		}
+
+		if !assertion.Passed && assertion.Message == "" {
+			assertion.Message = fmt.Sprintf("expected %v call(s), got %d", assertion.Expected, actual)
+		}
+		results = append(results, assertion)
+	}
+
+	return results
+}
+
+// expectedCalls reads the exact number of calls an expect-called node expects.
+// Without a "times" property the node expects to be reached at least once.
+func expectedCalls(bpNode blueprint.BlueprintNode) (int, bool) {
+	for _, property := range bpNode.Properties {
+		if property.Name != "times" || property.Value == nil {
+			continue
+		}
+		value := types.NewValue(types.PinTypes.Any, property.Value)
+		if times, err := value.AsNumber(); err == nil && times >= 0 {
+			return int(times), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,186 @@
+package bptest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/nodes/web"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+func TestEvaluateCalls(t *testing.T) {
+	bp := blueprint.NewBlueprint("bp", "Calls", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "once", Type: string(KindCalled)})
+	bp.AddNode(blueprint.BlueprintNode{
+		ID:         "twice",
+		Type:       string(KindCalled),
+		Properties: []blueprint.NodeProperty{{Name: "times", Value: 2.0}},
+	})
+	bp.AddNode(blueprint.BlueprintNode{ID: "never", Type: string(KindCalled)})
+
+	recorded := []AssertionResult{
+		{NodeID: "once", Kind: KindCalled, Passed: true},
+		{NodeID: "twice", Kind: KindCalled, Passed: true},
+		{NodeID: "equal", Kind: KindEqual, Passed: false},
+	}
+
+	results := evaluateCalls(bp, recorded)
+	passed := make(map[string]bool)
+	for _, result := range results {
+		passed[result.NodeID] = result.Passed
+	}
+
+	expected := map[string]bool{"once": true, "twice": false, "never": false, "equal": false}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for nodeID, want := range expected {
+		if passed[nodeID] != want {
+			t.Errorf("node %s: expected passed=%v, got %v", nodeID, want, passed[nodeID])
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	suite := &SuiteResult{
+		Name:        "Checkout",
+		BlueprintID: "bp-1",
+		Tests:       2,
+		Passed:      1,
+		Failures:    1,
+		Duration:    1500 * time.Millisecond,
+		Timestamp:   time.Now(),
+		Cases: []CaseResult{
+			{Name: "happy path", Status: StatusPassed, Duration: time.Second},
+			{
+				Name:     "empty cart",
+				Status:   StatusFailed,
+				Duration: 500 * time.Millisecond,
+				Assertions: []AssertionResult{
+					{NodeID: "total", Kind: KindEqual, Passed: false, Message: "total mismatch", Expected: 0, Actual: 10},
+				},
+			},
+		},
+	}
+
+	var report bytes.Buffer
+	if err := WriteJUnit(&report, []*SuiteResult{suite}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := report.String()
+	for _, fragment := range []string{
+		`<testsuites tests="2" failures="1" errors="0" time="1.500">`,
+		`<testcase name="happy path" classname="bp-1" time="1.000"></testcase>`,
+		`<failure message="expect-equal (total): total mismatch expected=0 actual=10" type="AssertionFailure">`,
+	} {
+		if !strings.Contains(output, fragment) {
+			t.Errorf("expected report to contain %q, got:\n%s", fragment, output)
+		}
+	}
+}
+
+func TestMockNode(t *testing.T) {
+	factory := NewMockFactory(web.NewHTTPRequestNode, NodeMock{
+		NodeID:  "fetch",
+		Outputs: map[string]interface{}{"response": map[string]interface{}{"id": 1.0}, "status": 200.0},
+	})
+
+	ctx := mocks.NewMockExecutionContext("fetch", "http-request", mocks.NewMockLogger())
+	if err := factory().Execute(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response, exists := ctx.GetOutputValue("response"); !exists || response.RawValue.(map[string]interface{})["id"] != 1.0 {
+		t.Errorf("expected the mocked response, got %+v", response)
+	}
+	if status, exists := ctx.GetOutputValue("status"); !exists || status.Type != types.PinTypes.Number {
+		t.Errorf("expected the mocked status to keep the pin type, got %+v", status)
+	}
+	if ctx.GetActivatedFlow() != "then" {
+		t.Errorf("expected the first execution output to be activated, got %q", ctx.GetActivatedFlow())
+	}
+
+	failing := NewMockFactory(web.NewHTTPRequestNode, NodeMock{NodeID: "fetch", Error: "connection refused"})
+	if err := failing().Execute(ctx); err == nil || err.Error() != "connection refused" {
+		t.Errorf("expected the mocked error, got %v", err)
+	}
+}
+
+func TestMergeMocks(t *testing.T) {
+	merged := mergeMocks(
+		[]NodeMock{{NodeID: "a", Flow: "then"}, {NodeID: "b", Flow: "then"}},
+		[]NodeMock{{NodeID: "b", Flow: "catch"}},
+	)
+
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 mocks, got %d", len(merged))
+	}
+	for _, mock := range merged {
+		if mock.NodeID == "b" && mock.Flow != "catch" {
+			t.Errorf("expected the case mock to win, got flow %q", mock.Flow)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	bp := blueprint.NewBlueprint("bp", "Coverage", "1.0.0")
+	for _, bpNode := range []blueprint.BlueprintNode{
+		{ID: "start", Type: "test-start"},
+		{ID: "branch", Type: "test-branch"},
+		{ID: "yes", Type: "test-action"},
+		{ID: "no", Type: "test-action"},
+		{ID: "value", Type: "test-constant"},
+		{ID: "called", Type: string(KindCalled)},
+	} {
+		bp.AddNode(bpNode)
+	}
+	for _, conn := range []blueprint.Connection{
+		{ID: "c1", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "branch", TargetPinID: "execute", ConnectionType: "execution"},
+		{ID: "c2", SourceNodeID: "branch", SourcePinID: "true", TargetNodeID: "yes", TargetPinID: "execute", ConnectionType: "execution"},
+		{ID: "c3", SourceNodeID: "branch", SourcePinID: "false", TargetNodeID: "no", TargetPinID: "execute", ConnectionType: "execution"},
+		{ID: "c4", SourceNodeID: "value", SourcePinID: "value", TargetNodeID: "yes", TargetPinID: "input", ConnectionType: "data"},
+		{ID: "c5", SourceNodeID: "yes", SourcePinID: "then", TargetNodeID: "called", TargetPinID: "execute", ConnectionType: "execution"},
+	} {
+		bp.AddConnection(conn)
+	}
+
+	coverage := NewCoverage(bp)
+	if coverage.Nodes.Total != 5 || coverage.Connections.Total != 4 || coverage.Branches.Total != 2 {
+		t.Fatalf("expected 5 nodes, 4 connections and 2 branches, got %d, %d and %d",
+			coverage.Nodes.Total, coverage.Connections.Total, coverage.Branches.Total)
+	}
+
+	coverage.Record(bp, &engine.ExecutionTimeline{Spans: []engine.TimelineSpan{
+		{NodeID: "start"},
+		{NodeID: "branch", TriggeredBy: &engine.TimelineTrigger{SourceNodeID: "start", SourcePinID: "then", TargetPinID: "execute"}},
+		{NodeID: "yes", TriggeredBy: &engine.TimelineTrigger{SourceNodeID: "branch", SourcePinID: "true", TargetPinID: "execute"}},
+	}}, map[string]map[string]interface{}{"value": {"value": 1.0}})
+
+	if coverage.Nodes.Covered != 4 {
+		t.Errorf("expected 4 covered nodes, got %d", coverage.Nodes.Covered)
+	}
+	if coverage.Connections.Covered != 3 {
+		t.Errorf("expected 3 covered connections, got %d", coverage.Connections.Covered)
+	}
+	if uncovered := coverage.Branches.Uncovered(); len(uncovered) != 1 || uncovered[0].PinID != "false" {
+		t.Errorf("expected the false branch to be uncovered, got %+v", uncovered)
+	}
+
+	other := NewCoverage(bp)
+	other.Record(bp, &engine.ExecutionTimeline{Spans: []engine.TimelineSpan{
+		{NodeID: "no", TriggeredBy: &engine.TimelineTrigger{SourceNodeID: "branch", SourcePinID: "false", TargetPinID: "execute"}},
+	}}, nil)
+	coverage.Merge(other)
+
+	if coverage.Branches.Percent != 100 {
+		t.Errorf("expected full branch coverage after merging, got %.1f%%", coverage.Branches.Percent)
+	}
+	if coverage.Nodes.Covered != 5 {
+		t.Errorf("expected every node to be covered after merging, got %d", coverage.Nodes.Covered)
+	}
+}
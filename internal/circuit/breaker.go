@@ -0,0 +1,182 @@
+package circuit
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+)
+
+// State is the state of the circuit of a node type and target host
+type State string
+
+const (
+	StateClosed   State = "closed"    // Calls pass through
+	StateOpen     State = "open"      // Calls are short-circuited until the cool-down ends
+	StateHalfOpen State = "half-open" // A single trial call decides whether the circuit closes again
+)
+
+const (
+	DefaultThreshold = 5                // Consecutive failures that open a circuit
+	DefaultCooldown  = 30 * time.Second // Time an open circuit short-circuits calls
+)
+
+// Status describes the circuit of a node type and target host
+type Status struct {
+	NodeType            string     `json:"nodeType"`
+	Host                string     `json:"host"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	RetryAt             *time.Time `json:"retryAt,omitempty"`
+}
+
+type key struct {
+	nodeType string
+	host     string
+}
+
+type circuit struct {
+	state    State
+	failures int
+	openedAt time.Time
+}
+
+// Breaker tracks the failures of external calls per node type and target host, and
+// short-circuits the calls to a host after too many consecutive failures
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	circuits  map[key]*circuit
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+// NewBreaker creates a breaker that opens a circuit after threshold consecutive
+// failures and keeps it open for the cool-down period
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = DefaultThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[key]*circuit),
+		now:       time.Now,
+	}
+}
+
+// Allow checks if a node type may call a host. Once the cool-down of an open circuit
+// ends, a single trial call is allowed; the others are short-circuited until it reports.
+func (b *Breaker) Allow(nodeType, host string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, exists := b.circuits[key{nodeType, host}]
+	if !exists {
+		return nil
+	}
+
+	switch c.state {
+	case StateOpen:
+		retryAt := c.openedAt.Add(b.cooldown)
+		if b.now().Before(retryAt) {
+			return bperrors.CircuitOpen(nodeType, host, retryAt)
+		}
+		c.state = StateHalfOpen
+		return nil
+	case StateHalfOpen:
+		return bperrors.CircuitOpen(nodeType, host, b.now().Add(b.cooldown))
+	}
+	return nil
+}
+
+// Record reports the outcome of a call of a node type to a host
+func (b *Breaker) Record(nodeType, host string, failed bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	k := key{nodeType, host}
+	c, exists := b.circuits[k]
+	if !failed {
+		// Closed circuits without failures aren't tracked
+		if exists {
+			delete(b.circuits, k)
+		}
+		return
+	}
+
+	if !exists {
+		c = &circuit{state: StateClosed}
+		b.circuits[k] = c
+	}
+	c.failures++
+
+	if c.state == StateHalfOpen || c.failures >= b.threshold {
+		c.state = StateOpen
+		c.openedAt = b.now()
+	}
+}
+
+// Statuses returns the circuits with failures, ordered by node type and host
+func (b *Breaker) Statuses() []Status {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	statuses := make([]Status, 0, len(b.circuits))
+	for k, c := range b.circuits {
+		status := Status{
+			NodeType:            k.nodeType,
+			Host:                k.host,
+			State:               c.state,
+			ConsecutiveFailures: c.failures,
+		}
+		if c.state != StateClosed {
+			openedAt := c.openedAt
+			retryAt := c.openedAt.Add(b.cooldown)
+			status.OpenedAt = &openedAt
+			status.RetryAt = &retryAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].NodeType != statuses[j].NodeType {
+			return statuses[i].NodeType < statuses[j].NodeType
+		}
+		return statuses[i].Host < statuses[j].Host
+	})
+	return statuses
+}
+
+// Threshold returns the number of consecutive failures that open a circuit
+func (b *Breaker) Threshold() int {
+	return b.threshold
+}
+
+// Cooldown returns the time an open circuit short-circuits calls
+func (b *Breaker) Cooldown() time.Duration {
+	return b.cooldown
+}
+
+// defaultBreaker is the process-wide breaker used by the external-call nodes
+var defaultBreaker = NewBreaker(DefaultThreshold, DefaultCooldown)
+
+// Default returns the process-wide breaker
+func Default() *Breaker {
+	return defaultBreaker
+}
+
+// Allow checks if a node type may call a host on the default breaker
+func Allow(nodeType, host string) error {
+	return defaultBreaker.Allow(nodeType, host)
+}
+
+// Record reports the outcome of a call on the default breaker
+func Record(nodeType, host string, failed bool) {
+	defaultBreaker.Record(nodeType, host, failed)
+}
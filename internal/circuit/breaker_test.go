@@ -0,0 +1,68 @@
+package circuit
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"webblueprint/internal/bperrors"
+)
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	breaker := NewBreaker(3, time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		breaker.Record("http-request", "api.example.com", true)
+	}
+	if err := breaker.Allow("http-request", "api.example.com"); err != nil {
+		t.Fatalf("expected the circuit to stay closed below the threshold, got %v", err)
+	}
+
+	breaker.Record("http-request", "api.example.com", true)
+	err := breaker.Allow("http-request", "api.example.com")
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrCircuitOpen {
+		t.Fatalf("expected a circuit open error, got %v", err)
+	}
+
+	// Circuits are keyed by node type and host
+	if err := breaker.Allow("grpc-call", "api.example.com"); err != nil {
+		t.Errorf("expected other node types to pass, got %v", err)
+	}
+	if err := breaker.Allow("http-request", "other.example.com"); err != nil {
+		t.Errorf("expected other hosts to pass, got %v", err)
+	}
+}
+
+func TestBreakerHalfOpenTrial(t *testing.T) {
+	breaker := NewBreaker(1, time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Record("http-request", "api.example.com", true)
+	now = now.Add(time.Minute)
+
+	if err := breaker.Allow("http-request", "api.example.com"); err != nil {
+		t.Fatalf("expected a trial call after the cool-down, got %v", err)
+	}
+	if err := breaker.Allow("http-request", "api.example.com"); err == nil {
+		t.Fatal("expected concurrent calls to be short-circuited during the trial")
+	}
+
+	// A failed trial opens the circuit again
+	breaker.Record("http-request", "api.example.com", true)
+	if statuses := breaker.Statuses(); len(statuses) != 1 || statuses[0].State != StateOpen {
+		t.Fatalf("expected the circuit to open again, got %+v", statuses)
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	if err := breaker.Allow("http-request", "api.example.com"); err != nil {
+		t.Fatalf("expected a trial call after the cool-down, got %v", err)
+	}
+	breaker.Record("http-request", "api.example.com", false)
+	if statuses := breaker.Statuses(); len(statuses) != 0 {
+		t.Errorf("expected the circuit to close, got %+v", statuses)
+	}
+}
@@ -12,6 +12,42 @@ type ExecutionResult struct {
 	NodeResults    map[string]map[string]interface{} `json:"nodeResults,omitempty"` // NodeID -> PinID -> Value
 	ErrorAnalysis  map[string]interface{}            `json:"errorAnalysis,omitempty"`
 	PartialSuccess bool                              `json:"partialSuccess"`
+	Recoveries     []NodeRecovery                    `json:"recoveries,omitempty"`
+	// Mailboxes of node actors that were full when a message arrived
+	MailboxOverflows []MailboxOverflow `json:"mailboxOverflows,omitempty"`
+	// How much of the blueprint's execution budget the execution consumed
+	Budget *BudgetUsage `json:"budget,omitempty"`
+}
+
+// NodeRecovery describes a recovery strategy applied to the error of a node during an execution
+type NodeRecovery struct {
+	NodeID    string    `json:"nodeId"`
+	Strategy  string    `json:"strategy"`
+	ErrorCode string    `json:"errorCode"`
+	Error     string    `json:"error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MailboxOverflow describes how often the mailbox of a node actor was full during an execution
+type MailboxOverflow struct {
+	NodeID      string    `json:"nodeId"`
+	NodeType    string    `json:"nodeType"`
+	Capacity    int       `json:"capacity"`
+	Strategy    string    `json:"strategy"`
+	Occurrences int       `json:"occurrences"`
+	Dropped     int       `json:"dropped"` // Messages lost to the overflow strategy
+	FirstAt     time.Time `json:"firstAt"`
+	LastAt      time.Time `json:"lastAt"`
+}
+
+// BudgetUsage describes how much of the execution budget of its blueprint an execution
+// consumed. Zero limits don't bound the execution.
+type BudgetUsage struct {
+	MaxDurationMs     int64  `json:"maxDurationMs,omitempty"`
+	DurationMs        int64  `json:"durationMs"`
+	MaxNodeExecutions int    `json:"maxNodeExecutions,omitempty"`
+	NodeExecutions    int    `json:"nodeExecutions"`
+	Exceeded          string `json:"exceeded,omitempty"` // Limit the execution was aborted for: "duration" or "nodeExecutions"
 }
 
 // ValidationResult represents the result of a blueprint validation
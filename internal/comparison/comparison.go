@@ -0,0 +1,244 @@
+// Package comparison compares two executions run with the same inputs, e.g. of two versions
+// of a blueprint before the new one is activated: the outputs they produced, how long each
+// node took, and the branches only one of them followed.
+package comparison
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"webblueprint/internal/engine"
+)
+
+// Sides of a comparison
+const (
+	SideBaseline  = "baseline"
+	SideCandidate = "candidate"
+)
+
+// Run is an execution taking part in a comparison
+type Run struct {
+	ExecutionID string
+	Status      string
+	Error       string
+	DurationMs  float64
+	Outputs     map[string]interface{}    // Outputs of each node by node ID, as maps of pin values
+	Timeline    *engine.ExecutionTimeline // Node executions of the run, nil if unknown
+}
+
+// RunSummary is the outcome of a run in a comparison
+type RunSummary struct {
+	ExecutionID string  `json:"executionId"`
+	Status      string  `json:"status"`
+	Error       string  `json:"error,omitempty"`
+	DurationMs  float64 `json:"durationMs"`
+}
+
+// OutputDiff is an output pin whose value differs between the runs. A value missing on one
+// side means the node didn't produce it in that run.
+type OutputDiff struct {
+	NodeID    string      `json:"nodeId"`
+	PinID     string      `json:"pinId"`
+	Baseline  interface{} `json:"baseline,omitempty"`
+	Candidate interface{} `json:"candidate,omitempty"`
+	Missing   string      `json:"missing,omitempty"` // Side that has no value, if any
+}
+
+// NodeTiming compares the time a node took in both runs. Nodes running several times, e.g.
+// in loops, add up the durations of their executions.
+type NodeTiming struct {
+	NodeID              string  `json:"nodeId"`
+	NodeType            string  `json:"nodeType"`
+	BaselineExecutions  int     `json:"baselineExecutions"`
+	CandidateExecutions int     `json:"candidateExecutions"`
+	BaselineMs          float64 `json:"baselineMs"`
+	CandidateMs         float64 `json:"candidateMs"`
+	DeltaMs             float64 `json:"deltaMs"` // Candidate minus baseline
+}
+
+// BranchDiff is a pin activation that only one of the runs followed
+type BranchDiff struct {
+	SourceNodeID string `json:"sourceNodeId"`
+	SourcePinID  string `json:"sourcePinId"`
+	TargetNodeID string `json:"targetNodeId"`
+	Only         string `json:"only"` // Side that followed the activation
+}
+
+// Comparison is the structured comparison of two runs
+type Comparison struct {
+	Baseline  RunSummary `json:"baseline"`
+	Candidate RunSummary `json:"candidate"`
+	// Identical is set when both runs ended with the same status and outputs, following the
+	// same branches
+	Identical       bool         `json:"identical"`
+	DurationDeltaMs float64      `json:"durationDeltaMs"`
+	Outputs         []OutputDiff `json:"outputs"`
+	Nodes           []NodeTiming `json:"nodes"`    // Largest deltas first
+	Branches        []BranchDiff `json:"branches"` // Activations followed by one run only
+}
+
+// Compare compares the candidate run against the baseline run
+func Compare(baseline, candidate Run) *Comparison {
+	comparison := &Comparison{
+		Baseline:        summarize(baseline),
+		Candidate:       summarize(candidate),
+		DurationDeltaMs: round(candidate.DurationMs - baseline.DurationMs),
+		Outputs:         compareOutputs(baseline.Outputs, candidate.Outputs),
+		Nodes:           compareTimings(baseline.Timeline, candidate.Timeline),
+		Branches:        compareBranches(baseline.Timeline, candidate.Timeline),
+	}
+	comparison.Identical = baseline.Status == candidate.Status &&
+		len(comparison.Outputs) == 0 &&
+		len(comparison.Branches) == 0
+	return comparison
+}
+
+func summarize(run Run) RunSummary {
+	return RunSummary{
+		ExecutionID: run.ExecutionID,
+		Status:      run.Status,
+		Error:       run.Error,
+		DurationMs:  run.DurationMs,
+	}
+}
+
+// compareOutputs lists the output pins whose values differ, ordered by node and pin
+func compareOutputs(baseline, candidate map[string]interface{}) []OutputDiff {
+	diffs := make([]OutputDiff, 0)
+	for _, nodeID := range unionKeys(baseline, candidate) {
+		baselinePins, _ := baseline[nodeID].(map[string]interface{})
+		candidatePins, _ := candidate[nodeID].(map[string]interface{})
+		for _, pinID := range unionKeys(baselinePins, candidatePins) {
+			baselineValue, inBaseline := baselinePins[pinID]
+			candidateValue, inCandidate := candidatePins[pinID]
+			if inBaseline && inCandidate && reflect.DeepEqual(baselineValue, candidateValue) {
+				continue
+			}
+
+			diff := OutputDiff{NodeID: nodeID, PinID: pinID, Baseline: baselineValue, Candidate: candidateValue}
+			if !inBaseline {
+				diff.Missing = SideBaseline
+			} else if !inCandidate {
+				diff.Missing = SideCandidate
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// compareTimings adds up the node executions of both timelines by node, ordered by the size
+// of their delta
+func compareTimings(baseline, candidate *engine.ExecutionTimeline) []NodeTiming {
+	timings := make(map[string]*NodeTiming)
+	add := func(timeline *engine.ExecutionTimeline, isCandidate bool) {
+		if timeline == nil {
+			return
+		}
+		for _, span := range timeline.Spans {
+			timing, exists := timings[span.NodeID]
+			if !exists {
+				timing = &NodeTiming{NodeID: span.NodeID, NodeType: span.NodeType}
+				timings[span.NodeID] = timing
+			}
+			if isCandidate {
+				timing.CandidateExecutions++
+				timing.CandidateMs += span.DurationMs
+			} else {
+				timing.BaselineExecutions++
+				timing.BaselineMs += span.DurationMs
+			}
+		}
+	}
+	add(baseline, false)
+	add(candidate, true)
+
+	result := make([]NodeTiming, 0, len(timings))
+	for _, timing := range timings {
+		timing.BaselineMs = round(timing.BaselineMs)
+		timing.CandidateMs = round(timing.CandidateMs)
+		timing.DeltaMs = round(timing.CandidateMs - timing.BaselineMs)
+		result = append(result, *timing)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		deltaI, deltaJ := math.Abs(result[i].DeltaMs), math.Abs(result[j].DeltaMs)
+		if deltaI != deltaJ {
+			return deltaI > deltaJ
+		}
+		return result[i].NodeID < result[j].NodeID
+	})
+	return result
+}
+
+// branch is a pin activation between two nodes
+type branch struct {
+	sourceNodeID, sourcePinID, targetNodeID string
+}
+
+// compareBranches lists the activations that only one of the timelines holds, ordered by
+// source node, pin and target node. Timelines rebuilt from stored node executions don't
+// know their activations and have no branches.
+func compareBranches(baseline, candidate *engine.ExecutionTimeline) []BranchDiff {
+	baselineBranches, candidateBranches := branches(baseline), branches(candidate)
+
+	diffs := make([]BranchDiff, 0)
+	for b := range baselineBranches {
+		if !candidateBranches[b] {
+			diffs = append(diffs, BranchDiff{SourceNodeID: b.sourceNodeID, SourcePinID: b.sourcePinID, TargetNodeID: b.targetNodeID, Only: SideBaseline})
+		}
+	}
+	for b := range candidateBranches {
+		if !baselineBranches[b] {
+			diffs = append(diffs, BranchDiff{SourceNodeID: b.sourceNodeID, SourcePinID: b.sourcePinID, TargetNodeID: b.targetNodeID, Only: SideCandidate})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		a, b := diffs[i], diffs[j]
+		if a.SourceNodeID != b.SourceNodeID {
+			return a.SourceNodeID < b.SourceNodeID
+		}
+		if a.SourcePinID != b.SourcePinID {
+			return a.SourcePinID < b.SourcePinID
+		}
+		if a.TargetNodeID != b.TargetNodeID {
+			return a.TargetNodeID < b.TargetNodeID
+		}
+		return a.Only < b.Only
+	})
+	return diffs
+}
+
+// branches returns the activations that started the node executions of a timeline
+func branches(timeline *engine.ExecutionTimeline) map[branch]bool {
+	result := make(map[branch]bool)
+	if timeline == nil {
+		return result
+	}
+	for _, span := range timeline.Spans {
+		if span.TriggeredBy == nil {
+			continue
+		}
+		result[branch{span.TriggeredBy.SourceNodeID, span.TriggeredBy.SourcePinID, span.NodeID}] = true
+	}
+	return result
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// round rounds a duration in milliseconds to microseconds
+func round(ms float64) float64 {
+	return math.Round(ms*1000) / 1000
+}
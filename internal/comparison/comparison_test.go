@@ -0,0 +1,111 @@
+package comparison
+
+import (
+	"reflect"
+	"testing"
+	"webblueprint/internal/engine"
+)
+
+func span(nodeID string, durationMs float64, sourceNodeID, sourcePinID string) engine.TimelineSpan {
+	s := engine.TimelineSpan{NodeID: nodeID, NodeType: "print", DurationMs: durationMs}
+	if sourceNodeID != "" {
+		s.TriggeredBy = &engine.TimelineTrigger{SourceNodeID: sourceNodeID, SourcePinID: sourcePinID}
+	}
+	return s
+}
+
+func TestCompareFindsOutputAndBranchDifferences(t *testing.T) {
+	baseline := Run{
+		ExecutionID: "a",
+		Status:      "completed",
+		DurationMs:  10,
+		Outputs: map[string]interface{}{
+			"start":  map[string]interface{}{"value": 1.0},
+			"branch": map[string]interface{}{"result": "low", "count": 2.0},
+		},
+		Timeline: &engine.ExecutionTimeline{Spans: []engine.TimelineSpan{
+			span("start", 1, "", ""),
+			span("branch", 2, "start", "then"),
+			span("low", 4, "branch", "false"),
+		}},
+	}
+	candidate := Run{
+		ExecutionID: "b",
+		Status:      "completed",
+		DurationMs:  14.5,
+		Outputs: map[string]interface{}{
+			"start":  map[string]interface{}{"value": 1.0},
+			"branch": map[string]interface{}{"result": "high"},
+			"high":   map[string]interface{}{"value": true},
+		},
+		Timeline: &engine.ExecutionTimeline{Spans: []engine.TimelineSpan{
+			span("start", 1, "", ""),
+			span("branch", 3, "start", "then"),
+			span("high", 9, "branch", "true"),
+		}},
+	}
+
+	comparison := Compare(baseline, candidate)
+
+	if comparison.Identical {
+		t.Error("expected the runs to differ")
+	}
+	if comparison.DurationDeltaMs != 4.5 {
+		t.Errorf("expected a duration delta of 4.5ms, got %v", comparison.DurationDeltaMs)
+	}
+
+	expectedOutputs := []OutputDiff{
+		{NodeID: "branch", PinID: "count", Baseline: 2.0, Missing: SideCandidate},
+		{NodeID: "branch", PinID: "result", Baseline: "low", Candidate: "high"},
+		{NodeID: "high", PinID: "value", Candidate: true, Missing: SideBaseline},
+	}
+	if !reflect.DeepEqual(comparison.Outputs, expectedOutputs) {
+		t.Errorf("expected outputs %+v, got %+v", expectedOutputs, comparison.Outputs)
+	}
+
+	expectedBranches := []BranchDiff{
+		{SourceNodeID: "branch", SourcePinID: "false", TargetNodeID: "low", Only: SideBaseline},
+		{SourceNodeID: "branch", SourcePinID: "true", TargetNodeID: "high", Only: SideCandidate},
+	}
+	if !reflect.DeepEqual(comparison.Branches, expectedBranches) {
+		t.Errorf("expected branches %+v, got %+v", expectedBranches, comparison.Branches)
+	}
+
+	// Largest deltas first: high (+9), low (-4), branch (+1), start (0)
+	var order []string
+	for _, timing := range comparison.Nodes {
+		order = append(order, timing.NodeID)
+	}
+	if expected := []string{"high", "low", "branch", "start"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected timings in order %v, got %v", expected, order)
+	}
+	if high := comparison.Nodes[0]; high.BaselineExecutions != 0 || high.CandidateExecutions != 1 || high.DeltaMs != 9 {
+		t.Errorf("unexpected timing of the candidate-only node: %+v", high)
+	}
+}
+
+func TestCompareIdenticalRuns(t *testing.T) {
+	run := func(id string, durationMs float64) Run {
+		return Run{
+			ExecutionID: id,
+			Status:      "completed",
+			Outputs:     map[string]interface{}{"n": map[string]interface{}{"items": []interface{}{1.0, "x"}}},
+			Timeline: &engine.ExecutionTimeline{Spans: []engine.TimelineSpan{
+				span("loop", durationMs, "", ""),
+				span("n", durationMs, "loop", "body"),
+				span("n", durationMs, "loop", "body"),
+			}},
+		}
+	}
+
+	comparison := Compare(run("a", 1), run("b", 2))
+
+	if !comparison.Identical {
+		t.Errorf("expected identical runs, got outputs %+v and branches %+v", comparison.Outputs, comparison.Branches)
+	}
+	for _, timing := range comparison.Nodes {
+		if timing.NodeID == "n" && (timing.BaselineExecutions != 2 || timing.BaselineMs != 2 || timing.CandidateMs != 4) {
+			t.Errorf("expected the executions of n to add up, got %+v", timing)
+		}
+	}
+}
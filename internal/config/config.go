@@ -0,0 +1,784 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"webblueprint/internal/proxy"
+	"webblueprint/pkg/db"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces secrets in the reported configuration
+const redactedValue = "********"
+
+// Execution modes of the engine, mirroring engine.ModeStandard and engine.ModeActor
+const (
+	ExecutionModeStandard = "standard"
+	ExecutionModeActor    = "actor"
+)
+
+// Mailbox overflow strategies of node actors, mirroring the engine.Overflow constants
+const (
+	MailboxOverflowBlock         = "block"
+	MailboxOverflowDropOldest    = "drop-oldest"
+	MailboxOverflowFailExecution = "fail-execution"
+)
+
+// Config is the configuration of the server. It's read from the defaults, a YAML file and
+// the environment, in that order of precedence.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Execution ExecutionConfig `yaml:"execution"`
+	Log       LogConfig       `yaml:"log"`
+	Convert   ConvertConfig   `yaml:"convert"`
+	Commands  CommandsConfig  `yaml:"commands"`
+	Autoscale AutoscaleConfig `yaml:"autoscale"`
+	Crash     CrashConfig     `yaml:"crash"`
+	CORS      CORSConfig      `yaml:"cors"`
+	CSRF      CSRFConfig      `yaml:"csrf"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Port            string        `yaml:"port"`
+	StaticDir       string        `yaml:"staticDir"`
+	ReadTimeout     time.Duration `yaml:"readTimeout"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	DrainDelay      time.Duration `yaml:"drainDelay"`     // How long the server reports not ready before it stops taking requests
+	BasePath        string        `yaml:"basePath"`       // URL prefix the server is served under behind a proxy, e.g. /webblueprint
+	TrustedProxies  []string      `yaml:"trustedProxies"` // IPs and CIDR ranges of the proxies whose X-Forwarded-* headers are believed
+	LocalesDir      string        `yaml:"localesDir"`     // Directory of <locale>.json message catalogs extending the built-in ones
+}
+
+// DatabaseConfig configures the database connection. The DSN takes precedence over the
+// other fields when set.
+type DatabaseConfig struct {
+	DSN      string `yaml:"dsn"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslMode"`
+}
+
+// ExecutionConfig configures the execution engine
+type ExecutionConfig struct {
+	Mode          string        `yaml:"mode"`
+	Timeout       time.Duration `yaml:"timeout"`       // How long actor mode executions wait for their nodes
+	NodeTimeout   time.Duration `yaml:"nodeTimeout"`   // Wall-clock limit of a single node, 0 for none
+	MaxOutputSize int           `yaml:"maxOutputSize"` // Limit of a single output pin value in MB, 0 for none
+	Mailbox       MailboxConfig `yaml:"mailbox"`
+}
+
+// MailboxConfig configures the mailboxes of node actors. Node types without their own
+// capacity or overflow strategy use the ones of the default.
+type MailboxConfig struct {
+	Capacity  int                      `yaml:"capacity"`
+	Overflow  string                   `yaml:"overflow"` // block, drop-oldest or fail-execution
+	NodeTypes map[string]MailboxConfig `yaml:"nodeTypes"`
+}
+
+// ForNodeType returns the mailbox configuration of a node type
+func (m MailboxConfig) ForNodeType(nodeType string) MailboxConfig {
+	mailbox := MailboxConfig{Capacity: m.Capacity, Overflow: m.Overflow}
+	if own, exists := m.NodeTypes[nodeType]; exists {
+		if own.Capacity != 0 {
+			mailbox.Capacity = own.Capacity
+		}
+		if own.Overflow != "" {
+			mailbox.Overflow = own.Overflow
+		}
+	}
+	return mailbox
+}
+
+// Types of log sinks
+const (
+	LogSinkFile   = "file"
+	LogSinkSyslog = "syslog"
+	LogSinkLoki   = "loki"
+)
+
+// LogConfig configures the server logs
+type LogConfig struct {
+	Level string          `yaml:"level"`
+	Sinks []LogSinkConfig `yaml:"sinks"` // Destinations the engine and node logs are forwarded to
+}
+
+// LogSinkConfig configures a destination of the engine and node logs, written as JSON.
+// Only the settings of its type apply.
+type LogSinkConfig struct {
+	Type  string `yaml:"type"`  // file, syslog or loki
+	Level string `yaml:"level"` // Least severe level forwarded, every level if empty
+
+	// file
+	Path       string `yaml:"path"`
+	MaxSize    int    `yaml:"maxSize"`    // Size in MB the file is rotated at, 0 to never rotate
+	MaxBackups int    `yaml:"maxBackups"` // Rotated files kept
+
+	// syslog
+	Network string `yaml:"network"` // udp or tcp
+	Address string `yaml:"address"`
+	Tag     string `yaml:"tag"`
+
+	// loki
+	URL           string            `yaml:"url"` // Base URL of the Loki server
+	Labels        map[string]string `yaml:"labels"`
+	BatchSize     int               `yaml:"batchSize"`
+	FlushInterval time.Duration     `yaml:"flushInterval"`
+}
+
+// ConvertConfig configures the providers of the convert nodes. Currency conversion and
+// geocoding are unavailable without their URL.
+type ConvertConfig struct {
+	RatesURL          string        `yaml:"ratesURL"`    // JSON endpoint of the exchange rates
+	RatesTTL          time.Duration `yaml:"ratesTTL"`    // How long fetched rates are used
+	GeocoderURL       string        `yaml:"geocoderURL"` // Nominatim-compatible search endpoint
+	GeocoderUserAgent string        `yaml:"geocoderUserAgent"`
+}
+
+// CommandsConfig configures the sandbox of the run-command node. Commands are disabled
+// unless enabled, and workspaces still have to opt into the node type.
+type CommandsConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Allow     []string      `yaml:"allow"`     // Binaries that may run, as absolute paths or names looked up in PATH
+	Root      string        `yaml:"root"`      // Directory the working directories of commands must be inside
+	PassEnv   []string      `yaml:"passEnv"`   // Variables of the server environment commands inherit
+	AllowEnv  []string      `yaml:"allowEnv"`  // Variables blueprints may set for commands; others are dropped
+	Timeout   time.Duration `yaml:"timeout"`   // Longest wall-clock time a command runs
+	CPUTime   time.Duration `yaml:"cpuTime"`   // CPU time limit of a command on Linux, 0 for none
+	MaxOutput int           `yaml:"maxOutput"` // Limit of stdout and of stderr in KB, 0 for none
+}
+
+// AutoscaleConfig configures how the server reports its load to autoscalers. /api/autoscale
+// is always served.
+type AutoscaleConfig struct {
+	ExternalMetrics bool `yaml:"externalMetrics"` // Whether to serve the Kubernetes external metrics API
+}
+
+// CrashConfig configures where crash reports are forwarded. Reports are always logged and
+// stored in the database.
+type CrashConfig struct {
+	SentryDSN   string `yaml:"sentryDSN"`   // DSN of a Sentry-compatible endpoint, empty to not forward
+	Environment string `yaml:"environment"` // Environment forwarded reports are tagged with
+}
+
+// CORSConfig configures which other origins may call the API from a browser, e.g. apps that
+// embed the editor. Origins are exact, such as https://app.example.com, or cover the
+// subdomains of a domain, such as https://*.example.com; "*" allows every origin.
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowedOrigins"`   // Origins allowed to call the API, none by default
+	AllowedHeaders   []string      `yaml:"allowedHeaders"`   // Request headers cross-origin requests may send
+	AllowCredentials bool          `yaml:"allowCredentials"` // Whether cross-origin requests may send cookies and credentials
+	MaxAge           time.Duration `yaml:"maxAge"`           // How long browsers may cache a preflight response
+}
+
+// CSRFConfig configures the protection of state-changing requests against cross-site
+// request forgery. Browser requests that change state must come from the server's own
+// origin or an origin allowed under cors.
+type CSRFConfig struct {
+	Enabled     bool     `yaml:"enabled"`
+	ExemptPaths []string `yaml:"exemptPaths"` // Path patterns that aren't checked, e.g. /api/hooks/*
+}
+
+// Default returns the configuration used for the settings that aren't configured
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8089",
+			StaticDir:       "./web/dist",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Port:     5432,
+			User:     "root",
+			Password: "root",
+			Name:     "webblueprint",
+			SSLMode:  "disable",
+		},
+		Execution: ExecutionConfig{
+			Mode:          ExecutionModeActor,
+			Timeout:       30 * time.Second,
+			NodeTimeout:   5 * time.Minute,
+			MaxOutputSize: 16,
+			Mailbox: MailboxConfig{
+				Capacity: 1024,
+				Overflow: MailboxOverflowBlock,
+			},
+		},
+		Log: LogConfig{
+			Level: "info",
+			Sinks: []LogSinkConfig{
+				{Type: LogSinkFile, Path: "./log.out", MaxSize: 100, MaxBackups: 3},
+			},
+		},
+		Convert: ConvertConfig{
+			RatesTTL:          time.Hour,
+			GeocoderUserAgent: "webblueprint",
+		},
+		Commands: CommandsConfig{
+			Root:      "./sandbox",
+			PassEnv:   []string{"PATH", "LANG", "TZ"},
+			Timeout:   30 * time.Second,
+			CPUTime:   10 * time.Second,
+			MaxOutput: 1024,
+		},
+		CORS: CORSConfig{
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-User-ID", "X-Request-ID"},
+			MaxAge:         10 * time.Minute,
+		},
+		CSRF: CSRFConfig{
+			Enabled: true,
+		},
+	}
+}
+
+// Load reads the configuration from the file, if any, and the environment, and validates it
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(contents, cfg); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides the configuration with the environment variables that are set
+func (c *Config) applyEnv(lookup func(key string) (string, bool)) error {
+	var errs []error
+
+	setString := func(key string, target *string) {
+		if value, ok := lookup(key); ok && value != "" {
+			*target = value
+		}
+	}
+	setInt := func(key string, target *int) {
+		if value, ok := lookup(key); ok && value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setBool := func(key string, target *bool) {
+		if value, ok := lookup(key); ok && value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setList := func(key string, target *[]string) {
+		if value, ok := lookup(key); ok && value != "" {
+			list := make([]string, 0)
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			*target = list
+		}
+	}
+	setDuration := func(key string, target *time.Duration) {
+		if value, ok := lookup(key); ok && value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 15s, got %q", key, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	setString("PORT", &c.Server.Port)
+	setString("STATIC_DIR", &c.Server.StaticDir)
+	setString("LOCALES_DIR", &c.Server.LocalesDir)
+	setDuration("SERVER_READ_TIMEOUT", &c.Server.ReadTimeout)
+	setDuration("SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	setDuration("SERVER_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	setDuration("SERVER_DRAIN_DELAY", &c.Server.DrainDelay)
+	setString("BASE_PATH", &c.Server.BasePath)
+	setList("TRUSTED_PROXIES", &c.Server.TrustedProxies)
+
+	setString("DATABASE_URL", &c.Database.DSN)
+	setString("DB_HOST", &c.Database.Host)
+	setInt("DB_PORT", &c.Database.Port)
+	setString("DB_USER", &c.Database.User)
+	setString("DB_PASSWORD", &c.Database.Password)
+	setString("DB_NAME", &c.Database.Name)
+	setString("DB_SSLMODE", &c.Database.SSLMode)
+
+	setString("EXECUTION_MODE", &c.Execution.Mode)
+	setDuration("EXECUTION_TIMEOUT", &c.Execution.Timeout)
+	setDuration("EXECUTION_NODE_TIMEOUT", &c.Execution.NodeTimeout)
+	setInt("EXECUTION_MAX_OUTPUT_SIZE", &c.Execution.MaxOutputSize)
+	setInt("EXECUTION_MAILBOX_CAPACITY", &c.Execution.Mailbox.Capacity)
+	setString("EXECUTION_MAILBOX_OVERFLOW", &c.Execution.Mailbox.Overflow)
+
+	setString("LOG_LEVEL", &c.Log.Level)
+
+	setString("CONVERT_RATES_URL", &c.Convert.RatesURL)
+	setDuration("CONVERT_RATES_TTL", &c.Convert.RatesTTL)
+	setString("CONVERT_GEOCODER_URL", &c.Convert.GeocoderURL)
+	setString("CONVERT_GEOCODER_USER_AGENT", &c.Convert.GeocoderUserAgent)
+
+	setBool("COMMANDS_ENABLED", &c.Commands.Enabled)
+	setList("COMMANDS_ALLOW", &c.Commands.Allow)
+	setString("COMMANDS_ROOT", &c.Commands.Root)
+	setList("COMMANDS_PASS_ENV", &c.Commands.PassEnv)
+	setList("COMMANDS_ALLOW_ENV", &c.Commands.AllowEnv)
+	setDuration("COMMANDS_TIMEOUT", &c.Commands.Timeout)
+	setDuration("COMMANDS_CPU_TIME", &c.Commands.CPUTime)
+	setInt("COMMANDS_MAX_OUTPUT", &c.Commands.MaxOutput)
+
+	setBool("AUTOSCALE_EXTERNAL_METRICS", &c.Autoscale.ExternalMetrics)
+
+	setString("CRASH_SENTRY_DSN", &c.Crash.SentryDSN)
+	setString("CRASH_ENVIRONMENT", &c.Crash.Environment)
+
+	setList("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	setList("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	setBool("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials)
+	setDuration("CORS_MAX_AGE", &c.CORS.MaxAge)
+
+	setBool("CSRF_ENABLED", &c.CSRF.Enabled)
+	setList("CSRF_EXEMPT_PATHS", &c.CSRF.ExemptPaths)
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid setting of the configuration
+func (c *Config) Validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be a port number, got %q", c.Server.Port))
+	}
+	if c.Server.StaticDir == "" {
+		errs = append(errs, errors.New("server.staticDir is required"))
+	}
+	if c.Server.ReadTimeout <= 0 {
+		errs = append(errs, errors.New("server.readTimeout must be positive"))
+	}
+	if c.Server.WriteTimeout <= 0 {
+		errs = append(errs, errors.New("server.writeTimeout must be positive"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdownTimeout must be positive"))
+	}
+	if c.Server.DrainDelay < 0 {
+		errs = append(errs, errors.New("server.drainDelay must not be negative"))
+	}
+	if c.Server.BasePath != "" && !basePathPattern.MatchString(c.Server.BasePath) {
+		errs = append(errs, fmt.Errorf("server.basePath must be a URL path such as /webblueprint, got %q", c.Server.BasePath))
+	}
+	if _, err := proxy.ParseTrusted(c.Server.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("server.trustedProxies: %w", err))
+	}
+
+	if c.Database.DSN == "" {
+		if c.Database.Host == "" {
+			errs = append(errs, errors.New("database.host is required without database.dsn"))
+		}
+		if c.Database.Port < 1 || c.Database.Port > 65535 {
+			errs = append(errs, fmt.Errorf("database.port must be a port number, got %d", c.Database.Port))
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, errors.New("database.name is required without database.dsn"))
+		}
+	}
+
+	if c.Execution.Mode != ExecutionModeStandard && c.Execution.Mode != ExecutionModeActor {
+		errs = append(errs, fmt.Errorf("execution.mode must be %s or %s, got %q", ExecutionModeStandard, ExecutionModeActor, c.Execution.Mode))
+	}
+	if c.Execution.Timeout <= 0 {
+		errs = append(errs, errors.New("execution.timeout must be positive"))
+	}
+	if c.Execution.NodeTimeout < 0 {
+		errs = append(errs, errors.New("execution.nodeTimeout must not be negative"))
+	}
+	if c.Execution.MaxOutputSize < 0 {
+		errs = append(errs, errors.New("execution.maxOutputSize must not be negative"))
+	}
+	if c.Execution.Mailbox.Capacity <= 0 {
+		errs = append(errs, errors.New("execution.mailbox.capacity must be positive"))
+	}
+	if !validOverflow(c.Execution.Mailbox.Overflow) {
+		errs = append(errs, fmt.Errorf("execution.mailbox.overflow must be %s, %s or %s, got %q", MailboxOverflowBlock, MailboxOverflowDropOldest, MailboxOverflowFailExecution, c.Execution.Mailbox.Overflow))
+	}
+	for nodeType, mailbox := range c.Execution.Mailbox.NodeTypes {
+		if mailbox.Capacity < 0 {
+			errs = append(errs, fmt.Errorf("execution.mailbox.nodeTypes.%s.capacity must not be negative", nodeType))
+		}
+		if mailbox.Overflow != "" && !validOverflow(mailbox.Overflow) {
+			errs = append(errs, fmt.Errorf("execution.mailbox.nodeTypes.%s.overflow must be %s, %s or %s, got %q", nodeType, MailboxOverflowBlock, MailboxOverflowDropOldest, MailboxOverflowFailExecution, mailbox.Overflow))
+		}
+	}
+
+	if _, err := parseLevel(c.Log.Level); err != nil {
+		errs = append(errs, err)
+	}
+	for i, sink := range c.Log.Sinks {
+		errs = append(errs, sink.validate(fmt.Sprintf("log.sinks[%d]", i))...)
+	}
+
+	if c.Convert.RatesURL != "" && !validURL(c.Convert.RatesURL) {
+		errs = append(errs, fmt.Errorf("convert.ratesURL must be an HTTP URL, got %q", redactDSN(c.Convert.RatesURL)))
+	}
+	if c.Convert.RatesTTL <= 0 {
+		errs = append(errs, errors.New("convert.ratesTTL must be positive"))
+	}
+	if c.Convert.GeocoderURL != "" && !validURL(c.Convert.GeocoderURL) {
+		errs = append(errs, fmt.Errorf("convert.geocoderURL must be an HTTP URL, got %q", redactDSN(c.Convert.GeocoderURL)))
+	}
+
+	if c.Commands.Enabled {
+		if len(c.Commands.Allow) == 0 {
+			errs = append(errs, errors.New("commands.allow must list a binary when commands are enabled"))
+		}
+		if c.Commands.Root == "" {
+			errs = append(errs, errors.New("commands.root is required when commands are enabled"))
+		}
+	}
+	if c.Commands.Timeout <= 0 {
+		errs = append(errs, errors.New("commands.timeout must be positive"))
+	}
+	if c.Commands.CPUTime < 0 {
+		errs = append(errs, errors.New("commands.cpuTime must not be negative"))
+	}
+	if c.Commands.MaxOutput < 0 {
+		errs = append(errs, errors.New("commands.maxOutput must not be negative"))
+	}
+
+	if c.Crash.SentryDSN != "" && !validSentryDSN(c.Crash.SentryDSN) {
+		errs = append(errs, errors.New("crash.sentryDSN must be an HTTP URL with a public key and a project ID"))
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				errs = append(errs, errors.New(`cors.allowedOrigins can't be "*" with cors.allowCredentials`))
+			}
+			continue
+		}
+		if !originPattern.MatchString(origin) {
+			errs = append(errs, fmt.Errorf("cors.allowedOrigins must list origins such as https://app.example.com, got %q", origin))
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, errors.New("cors.maxAge must not be negative"))
+	}
+	for _, pattern := range c.CSRF.ExemptPaths {
+		if _, err := path.Match(pattern, ""); err != nil || !strings.HasPrefix(pattern, "/") {
+			errs = append(errs, fmt.Errorf("csrf.exemptPaths must list path patterns such as /api/hooks/*, got %q", pattern))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// DB returns the database connection configuration
+func (c *Config) DB() db.Config {
+	return db.Config{
+		DSN:      c.Database.DSN,
+		Host:     c.Database.Host,
+		Port:     c.Database.Port,
+		User:     c.Database.User,
+		Password: c.Database.Password,
+		DBName:   c.Database.Name,
+		SSLMode:  c.Database.SSLMode,
+	}
+}
+
+// LogLevel returns the level of the server logs
+func (c *Config) LogLevel() slog.Level {
+	level, _ := parseLevel(c.Log.Level)
+	return level
+}
+
+// Redacted returns the configuration with its secrets masked, for reporting
+func (c *Config) Redacted() map[string]interface{} {
+	password := ""
+	if c.Database.Password != "" {
+		password = redactedValue
+	}
+
+	return map[string]interface{}{
+		"server": map[string]interface{}{
+			"port":            c.Server.Port,
+			"staticDir":       c.Server.StaticDir,
+			"readTimeout":     c.Server.ReadTimeout.String(),
+			"writeTimeout":    c.Server.WriteTimeout.String(),
+			"shutdownTimeout": c.Server.ShutdownTimeout.String(),
+			"drainDelay":      c.Server.DrainDelay.String(),
+			"basePath":        c.Server.BasePath,
+			"trustedProxies":  c.Server.TrustedProxies,
+			"localesDir":      c.Server.LocalesDir,
+		},
+		"database": map[string]interface{}{
+			"dsn":      redactDSN(c.Database.DSN),
+			"host":     c.Database.Host,
+			"port":     c.Database.Port,
+			"user":     c.Database.User,
+			"password": password,
+			"name":     c.Database.Name,
+			"sslMode":  c.Database.SSLMode,
+		},
+		"execution": map[string]interface{}{
+			"mode":          c.Execution.Mode,
+			"timeout":       c.Execution.Timeout.String(),
+			"nodeTimeout":   c.Execution.NodeTimeout.String(),
+			"maxOutputSize": c.Execution.MaxOutputSize,
+			"mailbox":       c.Execution.Mailbox.redacted(),
+		},
+		"log": map[string]interface{}{
+			"level": c.Log.Level,
+			"sinks": c.Log.redactedSinks(),
+		},
+		"convert": map[string]interface{}{
+			"ratesURL":          redactDSN(c.Convert.RatesURL),
+			"ratesTTL":          c.Convert.RatesTTL.String(),
+			"geocoderURL":       redactDSN(c.Convert.GeocoderURL),
+			"geocoderUserAgent": c.Convert.GeocoderUserAgent,
+		},
+		"commands": map[string]interface{}{
+			"enabled":   c.Commands.Enabled,
+			"allow":     c.Commands.Allow,
+			"root":      c.Commands.Root,
+			"passEnv":   c.Commands.PassEnv,
+			"allowEnv":  c.Commands.AllowEnv,
+			"timeout":   c.Commands.Timeout.String(),
+			"cpuTime":   c.Commands.CPUTime.String(),
+			"maxOutput": c.Commands.MaxOutput,
+		},
+		"autoscale": map[string]interface{}{
+			"externalMetrics": c.Autoscale.ExternalMetrics,
+		},
+		"cors": map[string]interface{}{
+			"allowedOrigins":   c.CORS.AllowedOrigins,
+			"allowedHeaders":   c.CORS.AllowedHeaders,
+			"allowCredentials": c.CORS.AllowCredentials,
+			"maxAge":           c.CORS.MaxAge.String(),
+		},
+		"csrf": map[string]interface{}{
+			"enabled":     c.CSRF.Enabled,
+			"exemptPaths": c.CSRF.ExemptPaths,
+		},
+		"crash": map[string]interface{}{
+			"sentryDSN":   redactSentryDSN(c.Crash.SentryDSN),
+			"environment": c.Crash.Environment,
+		},
+	}
+}
+
+// validate reports every invalid setting of a log sink, prefixed with its path
+func (s LogSinkConfig) validate(path string) []error {
+	var errs []error
+
+	if s.Level != "" {
+		if _, err := parseLevel(s.Level); err != nil {
+			errs = append(errs, fmt.Errorf("%s.level must be debug, info, warn or error, got %q", path, s.Level))
+		}
+	}
+
+	switch s.Type {
+	case LogSinkFile:
+		if s.Path == "" {
+			errs = append(errs, fmt.Errorf("%s.path is required", path))
+		}
+		if s.MaxSize < 0 {
+			errs = append(errs, fmt.Errorf("%s.maxSize must not be negative", path))
+		}
+		if s.MaxBackups < 0 {
+			errs = append(errs, fmt.Errorf("%s.maxBackups must not be negative", path))
+		}
+	case LogSinkSyslog:
+		if s.Address == "" {
+			errs = append(errs, fmt.Errorf("%s.address is required", path))
+		}
+		switch s.Network {
+		case "", "udp", "tcp":
+		default:
+			errs = append(errs, fmt.Errorf("%s.network must be udp or tcp, got %q", path, s.Network))
+		}
+	case LogSinkLoki:
+		if parsed, err := url.Parse(s.URL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("%s.url must be the URL of the Loki server, got %q", path, redactDSN(s.URL)))
+		}
+		if s.BatchSize < 0 {
+			errs = append(errs, fmt.Errorf("%s.batchSize must not be negative", path))
+		}
+		if s.FlushInterval < 0 {
+			errs = append(errs, fmt.Errorf("%s.flushInterval must not be negative", path))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.type must be %s, %s or %s, got %q", path, LogSinkFile, LogSinkSyslog, LogSinkLoki, s.Type))
+	}
+
+	return errs
+}
+
+// redactedSinks returns the log sinks for reporting, masking the credentials of Loki URLs
+func (l LogConfig) redactedSinks() []interface{} {
+	sinks := make([]interface{}, 0, len(l.Sinks))
+	for _, sink := range l.Sinks {
+		redacted := map[string]interface{}{
+			"type":  sink.Type,
+			"level": sink.Level,
+		}
+		switch sink.Type {
+		case LogSinkFile:
+			redacted["path"] = sink.Path
+			redacted["maxSize"] = sink.MaxSize
+			redacted["maxBackups"] = sink.MaxBackups
+		case LogSinkSyslog:
+			redacted["network"] = sink.Network
+			redacted["address"] = sink.Address
+			redacted["tag"] = sink.Tag
+		case LogSinkLoki:
+			redacted["url"] = redactDSN(sink.URL)
+			redacted["labels"] = sink.Labels
+			redacted["batchSize"] = sink.BatchSize
+			redacted["flushInterval"] = sink.FlushInterval.String()
+		}
+		sinks = append(sinks, redacted)
+	}
+	return sinks
+}
+
+// redacted returns the mailbox configuration for reporting
+func (m MailboxConfig) redacted() map[string]interface{} {
+	nodeTypes := make(map[string]interface{}, len(m.NodeTypes))
+	for nodeType, mailbox := range m.NodeTypes {
+		nodeTypes[nodeType] = map[string]interface{}{
+			"capacity": mailbox.Capacity,
+			"overflow": mailbox.Overflow,
+		}
+	}
+	return map[string]interface{}{
+		"capacity":  m.Capacity,
+		"overflow":  m.Overflow,
+		"nodeTypes": nodeTypes,
+	}
+}
+
+// validOverflow reports whether the name is a mailbox overflow strategy
+func validOverflow(name string) bool {
+	switch name {
+	case MailboxOverflowBlock, MailboxOverflowDropOldest, MailboxOverflowFailExecution:
+		return true
+	}
+	return false
+}
+
+// validURL reports whether the value is an absolute HTTP or HTTPS URL
+func validURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// validSentryDSN reports whether the value is a Sentry DSN such as
+// https://<key>@sentry.example.com/<project>
+func validSentryDSN(value string) bool {
+	if !validURL(value) {
+		return false
+	}
+	parsed, _ := url.Parse(value)
+	return parsed.User != nil && parsed.User.Username() != "" && strings.Trim(parsed.Path, "/") != ""
+}
+
+// parseLevel parses the name of a log level
+func parseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("log.level must be debug, info, warn or error, got %q", name)
+	}
+	return level, nil
+}
+
+// basePathPattern matches the URL prefixes the server may be served under
+var basePathPattern = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)+/?$`)
+
+// originPattern matches allowed origins: a scheme and a host, whose first label may be *,
+// and an optional port
+var originPattern = regexp.MustCompile(`^https?://(\*\.)?[A-Za-z0-9.-]+(:[0-9]+)?$`)
+
+// dsnPasswordPattern matches the password of key/value connection strings
+var dsnPasswordPattern = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// redactDSN masks the password of a connection string, in URL or key/value form
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+
+	if strings.Contains(dsn, "://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return redactedValue
+		}
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			parsed.User = url.UserPassword(parsed.User.Username(), redactedValue)
+		}
+		query := parsed.Query()
+		if query.Has("password") {
+			query.Set("password", redactedValue)
+			parsed.RawQuery = query.Encode()
+		}
+		redacted, _ := url.PathUnescape(parsed.String())
+		return redacted
+	}
+
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+redactedValue)
+}
+
+// redactSentryDSN masks the key, and the secret if any, of a Sentry DSN
+func redactSentryDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return redactedValue
+	}
+	if parsed.User != nil {
+		parsed.User = url.User(redactedValue)
+	}
+	redacted, _ := url.PathUnescape(parsed.String())
+	return redacted
+}
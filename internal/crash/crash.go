@@ -0,0 +1,115 @@
+// Package crash reports the panics recovered during executions. Reports carry the
+// execution, node and blueprint version they happened in along with the latest events of
+// the execution, and are logged, persisted and forwarded to error trackers.
+package crash
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"webblueprint/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Sources of crash reports
+const (
+	SourceNode         = "node"          // A node panicked while executing
+	SourceActor        = "actor"         // The actor running a node panicked outside the node
+	SourceEventHandler = "event-handler" // The handler of an event binding panicked
+	SourceExecution    = "execution"     // The engine panicked while running an execution
+)
+
+// reportTimeout bounds how long persisting or forwarding a report may take
+const reportTimeout = 10 * time.Second
+
+// Store persists crash reports. The crash report repository implements it.
+type Store interface {
+	Save(ctx context.Context, report *models.CrashReport) error
+}
+
+// Forwarder sends crash reports to an external error tracker
+type Forwarder interface {
+	Forward(ctx context.Context, report *models.CrashReport) error
+}
+
+// Reporter logs crash reports, persists them in its store and forwards them
+type Reporter struct {
+	store      Store
+	forwarders []Forwarder
+	pending    sync.WaitGroup
+}
+
+// NewReporter creates a reporter. Without a store, reports are only logged and forwarded.
+func NewReporter(store Store, forwarders ...Forwarder) *Reporter {
+	return &Reporter{
+		store:      store,
+		forwarders: forwarders,
+	}
+}
+
+// Report reports a crash, setting its ID and time if they're missing. It's logged right
+// away and persisted and forwarded in the background, so the panicking execution isn't held
+// up by the database or the network.
+func (r *Reporter) Report(report *models.CrashReport) {
+	if report.ID == "" {
+		report.ID = uuid.New().String()
+	}
+	if report.OccurredAt.IsZero() {
+		report.OccurredAt = time.Now().UTC()
+	}
+
+	log.Printf("Crash report %s: %s %s of blueprint %s (version %s) panicked in execution %s: %s\n%s",
+		report.ID, report.Source, report.NodeID, report.BlueprintID, report.BlueprintVersion, report.ExecutionID, report.Message, report.Stack)
+
+	if r.store == nil && len(r.forwarders) == 0 {
+		return
+	}
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+		defer cancel()
+
+		if r.store != nil {
+			if err := r.store.Save(ctx, report); err != nil {
+				log.Printf("Failed to save crash report %s: %v", report.ID, err)
+			}
+		}
+		for _, forwarder := range r.forwarders {
+			if err := forwarder.Forward(ctx, report); err != nil {
+				log.Printf("Failed to forward crash report %s: %v", report.ID, err)
+			}
+		}
+	}()
+}
+
+// Wait blocks until the reports in the background are persisted and forwarded, e.g. before
+// the server exits
+func (r *Reporter) Wait() {
+	r.pending.Wait()
+}
+
+// defaultReporter reports the crashes of the engine; it only logs them until the server
+// configures a store
+var defaultReporter = NewReporter(nil)
+var defaultMutex sync.RWMutex
+
+// Default returns the reporter the engine reports crashes to
+func Default() *Reporter {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultReporter
+}
+
+// SetDefault replaces the reporter the engine reports crashes to; nil restores one that only
+// logs them
+func SetDefault(reporter *Reporter) {
+	if reporter == nil {
+		reporter = NewReporter(nil)
+	}
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultReporter = reporter
+}
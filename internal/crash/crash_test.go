@@ -0,0 +1,118 @@
+package crash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"webblueprint/pkg/models"
+)
+
+// recorder is a store and forwarder that keeps the reports it gets
+type recorder struct {
+	mutex   sync.Mutex
+	reports []*models.CrashReport
+	err     error
+}
+
+func (r *recorder) Save(ctx context.Context, report *models.CrashReport) error {
+	return r.record(report)
+}
+
+func (r *recorder) Forward(ctx context.Context, report *models.CrashReport) error {
+	return r.record(report)
+}
+
+func (r *recorder) record(report *models.CrashReport) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reports = append(r.reports, report)
+	return r.err
+}
+
+func TestReporterSavesAndForwards(t *testing.T) {
+	store := &recorder{}
+	forwarder := &recorder{err: errors.New("tracker is down")}
+	reporter := NewReporter(store, forwarder)
+
+	reporter.Report(&models.CrashReport{Source: SourceNode, ExecutionID: "exec-1", NodeID: "node-1", Message: "boom"})
+	reporter.Wait()
+
+	if len(store.reports) != 1 || len(forwarder.reports) != 1 {
+		t.Fatalf("expected the report to be saved and forwarded once, got %d and %d", len(store.reports), len(forwarder.reports))
+	}
+	report := store.reports[0]
+	if report.ID == "" || report.OccurredAt.IsZero() {
+		t.Errorf("expected the ID and time to be set, got %+v", report)
+	}
+	if forwarder.reports[0] != report {
+		t.Error("expected a failing store or forwarder not to affect the others")
+	}
+}
+
+func TestSentryForwarder(t *testing.T) {
+	var auth, path string
+	var event map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("X-Sentry-Auth"), r.URL.Path
+		json.NewDecoder(r.Body).Decode(&event)
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public:secret@", 1) + "/errors/42"
+	forwarder, err := NewSentryForwarder(dsn, "staging")
+	if err != nil {
+		t.Fatalf("expected the DSN to be valid, got %v", err)
+	}
+
+	err = forwarder.Forward(context.Background(), &models.CrashReport{
+		ID:          "0b6c7d3e-5c1f-4f4e-9a59-0a5d1c1f5e2a",
+		Source:      SourceNode,
+		ExecutionID: "exec-1",
+		NodeID:      "node-1",
+		NodeType:    "http-request",
+		Message:     "boom",
+		Events:      []models.CrashEvent{{Type: "node.execution", NodeID: "node-0", Status: "completed", Timestamp: time.Now()}},
+		OccurredAt:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("expected the report to be forwarded, got %v", err)
+	}
+
+	if path != "/errors/api/42/store/" {
+		t.Errorf("expected the store endpoint of the project, got %s", path)
+	}
+	if !strings.Contains(auth, "sentry_key=public") || !strings.Contains(auth, "sentry_secret=secret") {
+		t.Errorf("expected the key and secret in the auth header, got %s", auth)
+	}
+	tags := event["tags"].(map[string]interface{})
+	if tags["executionId"] != "exec-1" || tags["nodeType"] != "http-request" {
+		t.Errorf("expected the execution context in the tags, got %v", tags)
+	}
+	if _, exists := tags["blueprintVersion"]; exists {
+		t.Error("expected empty tags to be left out")
+	}
+	if event["environment"] != "staging" || event["event_id"] != "0b6c7d3e5c1f4f4e9a590a5d1c1f5e2a" {
+		t.Errorf("expected the environment and event ID, got %v and %v", event["environment"], event["event_id"])
+	}
+	if breadcrumbs := event["breadcrumbs"].(map[string]interface{})["values"].([]interface{}); len(breadcrumbs) != 1 {
+		t.Errorf("expected a breadcrumb per event, got %v", breadcrumbs)
+	}
+}
+
+func TestSentryForwarderRejectsInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{
+		"sentry.example.com/42",
+		"https://sentry.example.com/42",
+		"https://public@sentry.example.com/",
+	} {
+		if _, err := NewSentryForwarder(dsn, ""); err == nil {
+			t.Errorf("expected %s to be rejected", dsn)
+		}
+	}
+}
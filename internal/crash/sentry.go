@@ -0,0 +1,134 @@
+package crash
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"webblueprint/pkg/models"
+)
+
+// SentryForwarder sends crash reports to the store endpoint of a Sentry-compatible error
+// tracker, such as Sentry or GlitchTip
+type SentryForwarder struct {
+	endpoint    string
+	auth        string
+	environment string
+	client      *http.Client
+}
+
+// NewSentryForwarder creates a forwarder for a DSN such as
+// https://<key>@sentry.example.com/<project>. Reports are tagged with the environment if
+// it isn't empty.
+func NewSentryForwarder(dsn, environment string) (*SentryForwarder, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, errors.New("invalid DSN: must be an HTTP URL")
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return nil, errors.New("invalid DSN: missing public key")
+	}
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if project == "" {
+		return nil, errors.New("invalid DSN: missing project ID")
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=webblueprint/1.0, sentry_key=%s", parsed.User.Username())
+	if secret, exists := parsed.User.Password(); exists {
+		auth += ", sentry_secret=" + secret
+	}
+
+	return &SentryForwarder{
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, path[:slash], project),
+		auth:        auth,
+		environment: environment,
+		client:      &http.Client{Timeout: reportTimeout},
+	}, nil
+}
+
+// Forward sends a crash report as a fatal Sentry event. Its latest execution events become
+// the breadcrumbs of the event.
+func (f *SentryForwarder) Forward(ctx context.Context, report *models.CrashReport) error {
+	body, err := json.Marshal(f.event(report))
+	if err != nil {
+		return fmt.Errorf("failed to encode Sentry event: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, f.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Sentry-Auth", f.auth)
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send Sentry event: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("sentry responded with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// event converts a crash report to the payload of the Sentry store endpoint
+func (f *SentryForwarder) event(report *models.CrashReport) map[string]interface{} {
+	tags := map[string]string{"source": report.Source}
+	for name, value := range map[string]string{
+		"executionId":      report.ExecutionID,
+		"blueprintId":      report.BlueprintID,
+		"blueprintVersion": report.BlueprintVersion,
+		"nodeId":           report.NodeID,
+		"nodeType":         report.NodeType,
+	} {
+		if value != "" {
+			tags[name] = value
+		}
+	}
+
+	breadcrumbs := make([]map[string]interface{}, 0, len(report.Events))
+	for _, event := range report.Events {
+		level := "info"
+		if event.Error != "" {
+			level = "error"
+		}
+		breadcrumbs = append(breadcrumbs, map[string]interface{}{
+			"timestamp": event.Timestamp.UTC().Format(time.RFC3339Nano),
+			"category":  event.Type,
+			"level":     level,
+			"message":   strings.TrimSpace(fmt.Sprintf("%s %s %s %s", event.NodeType, event.NodeID, event.Status, event.Error)),
+			"data":      map[string]interface{}{"durationMs": event.DurationMs},
+		})
+	}
+
+	event := map[string]interface{}{
+		"event_id":  strings.ReplaceAll(report.ID, "-", ""),
+		"timestamp": report.OccurredAt.UTC().Format(time.RFC3339Nano),
+		"platform":  "go",
+		"level":     "fatal",
+		"logger":    "webblueprint",
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{"type": "panic", "value": report.Message}},
+		},
+		"tags":        tags,
+		"extra":       map[string]interface{}{"stack": report.Stack},
+		"breadcrumbs": map[string]interface{}{"values": breadcrumbs},
+	}
+	if f.environment != "" {
+		event["environment"] = f.environment
+	}
+	return event
+}
@@ -2,12 +2,16 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
+	"runtime/debug"
 
 	// "strings" // No longer needed directly here
 	"sync"
 	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/crash"
 	"webblueprint/internal/db" // Added for SchemaAccessContext implementation
 
 	// "webblueprint/internal/engineext" // No longer needed directly here
@@ -34,9 +38,15 @@ type NodeActor struct {
 	listeners     []ExecutionListener
 	debugMgr      *DebugManager
 	done          chan struct{}
+	exited        chan struct{}         // Closed when the message loop ends without Stop, e.g. after a panic
+	exitErr       error                 // Why the message loop ended, set before exited is closed
+	inFlight      *NodeMessage          // Message the loop was handling when it ended, if any
+	mailboxPolicy MailboxPolicy         // Size of the mailbox and what happens when it's full
 	mutex         sync.RWMutex          // General mutex for actor state (inputs, outputs, status)
 	variableMutex *sync.RWMutex         // Specific mutex for shared variables map
 	properties    []types.Property      // Store node properties from the blueprint
+	fanInPolicies map[string]string     // Fan-in policies of the input pins, by pin ID
+	fanIn         fanInValues           // Values delivered to the pins with a fan-in policy
 	decoratedCtx  node.ExecutionContext // Store the potentially decorated context passed to Start
 	system        *ActorSystem          // Reference to the parent actor system
 
@@ -61,6 +71,10 @@ type NodeMessage struct {
 	Response   chan NodeResponse      // Channel for the response
 	FlowData   map[string]interface{} // Additional flow data
 	TriggerPin string                 // Pin that triggered an execute message
+
+	// Source of input messages sent over a data connection
+	SourceNodeID string
+	SourcePinID  string
 }
 
 // NodeResponse is the response to a NodeMessage
@@ -69,6 +83,7 @@ type NodeResponse struct {
 	Error          error
 	OutputPins     map[string]types.Value // Outputs generated by this step
 	FlowToActivate string                 // Explicitly signals which execution pin to follow (used by LoopNode)
+	ActivatedFlows []string               // Execution pins the node activated in this step, nil if unknown
 	// IterationPayload types.Value // Payload is derived from OutputPins["index"] by ActorSystem now
 }
 
@@ -90,9 +105,16 @@ func NewNodeActor(
 		details map[string]interface{}) error,
 ) *NodeActor {
 
+	mailboxPolicy := DefaultMailboxPolicy
+	if system != nil {
+		mailboxPolicy = system.mailboxes.forNodeType(nodeType)
+	}
+
 	// Load node properties from blueprint
 	var properties []types.Property
+	fanInPolicies := make(map[string]string)
 	if nodeConfig := bp.FindNode(nodeID); nodeConfig != nil {
+		fanInPolicies = nodeConfig.GetFanInPolicies()
 		propertyLog := make(map[string]interface{})
 		for _, property := range nodeConfig.Properties {
 			// Attempt to set property on the node instance if method exists
@@ -118,7 +140,8 @@ func NewNodeActor(
 		ExecutionID:   executionID,
 		node:          nodeInstance,
 		bp:            bp,
-		mailbox:       make(chan NodeMessage, 1024), // Buffer for handling multiple messages
+		mailbox:       make(chan NodeMessage, mailboxPolicy.Capacity), // Buffer for handling multiple messages
+		mailboxPolicy: mailboxPolicy,
 		inputs:        make(map[string]types.Value), // Actor's current inputs
 		outputs:       make(map[string]types.Value), // Actor's persistent outputs (if needed)
 		variables:     sharedVariables,              // Store reference to shared map
@@ -131,7 +154,10 @@ func NewNodeActor(
 		listeners:         listeners,
 		debugMgr:          debugMgr,
 		done:              make(chan struct{}),
+		exited:            make(chan struct{}),
 		properties:        properties,
+		fanInPolicies:     fanInPolicies,
+		fanIn:             make(fanInValues),
 		nodeExecutionHook: nodeExecutionHook,
 		anyHook:           anyHook,
 		system:            system, // Store system reference
@@ -177,6 +203,59 @@ func (a *NodeActor) Stop() {
 	close(a.done)
 }
 
+// actorExitedError is the response to the messages of an actor whose message loop ended
+type actorExitedError struct {
+	NodeID string
+	Cause  error
+}
+
+func (e *actorExitedError) Error() string {
+	return fmt.Sprintf("actor of node %s exited: %v", e.NodeID, e.Cause)
+}
+
+func (e *actorExitedError) Unwrap() error {
+	return e.Cause
+}
+
+// exit records why the message loop of the actor ended and wakes up its supervisor and senders
+func (a *NodeActor) exit(cause error) {
+	a.exitErr = cause
+	close(a.exited)
+}
+
+// exitResponse is the response to a message the actor can no longer handle
+func (a *NodeActor) exitResponse() NodeResponse {
+	return NodeResponse{
+		Success: false,
+		Error:   &actorExitedError{NodeID: a.NodeID, Cause: a.exitErr},
+	}
+}
+
+// post puts a message in the mailbox of the actor, applying the overflow strategy of the actor
+// when it's full. Messages to an exited actor fail with an actorExitedError, also when its
+// mailbox was closed.
+func (a *NodeActor) post(msg NodeMessage) (sent bool, err error) {
+	defer func() {
+		if recover() != nil {
+			sent, err = false, &actorExitedError{NodeID: a.NodeID, Cause: errors.New("mailbox closed")}
+		}
+	}()
+
+	// Exited actors don't read their mailbox anymore
+	select {
+	case <-a.exited:
+		return false, a.exitResponse().Error
+	default:
+	}
+
+	select {
+	case a.mailbox <- msg:
+		return true, nil
+	default:
+		return a.overflow(msg)
+	}
+}
+
 // Send sends a message to the actor and waits for a response
 func (a *NodeActor) Send(msg NodeMessage) NodeResponse {
 	// Create a response channel if not provided
@@ -185,48 +264,62 @@ func (a *NodeActor) Send(msg NodeMessage) NodeResponse {
 		msg.Response = responseChan
 	}
 
+	if _, err := a.post(msg); err != nil {
+		return NodeResponse{Success: false, Error: err}
+	}
+
+	// Message sent, wait for response
 	select {
-	case a.mailbox <- msg:
-		// Message sent, wait for response
-		select {
-		case response, ok := <-msg.Response:
-			if !ok {
-				// Channel was closed
-				return NodeResponse{
-					Success: false,
-					Error:   fmt.Errorf("response channel closed for node %s", a.NodeID),
-				}
-			}
-			return response
-		case <-time.After(10 * time.Second): // TODO: Make timeout configurable
-			// Timeout waiting for response
+	case response, ok := <-msg.Response:
+		if !ok {
+			// Channel was closed
 			return NodeResponse{
 				Success: false,
-				Error:   fmt.Errorf("timeout waiting for node response from %s", a.NodeID),
+				Error:   fmt.Errorf("response channel closed for node %s", a.NodeID),
 			}
 		}
-	case <-time.After(2 * time.Second): // TODO: Make timeout configurable
-		// Timeout sending message
+		return response
+	case <-a.exited:
+		// The actor exited before answering
+		return a.exitResponse()
+	case <-time.After(10 * time.Second): // TODO: Make timeout configurable
+		// Timeout waiting for response
 		return NodeResponse{
 			Success: false,
-			Error:   fmt.Errorf("mailbox full for node %s, node may be busy or deadlocked", a.NodeID),
+			Error:   fmt.Errorf("timeout waiting for node response from %s", a.NodeID),
 		}
 	}
 }
 
 // SendAsync sends a message to the actor without waiting for a response
 func (a *NodeActor) SendAsync(msg NodeMessage) bool {
-	select {
-	case a.mailbox <- msg:
-		// Message sent
-		return true
-	case <-time.After(1 * time.Second): // TODO: Make timeout configurable
-		// Mailbox full, log an error
-		a.logger.Error("Failed to send message to node actor, mailbox full", map[string]interface{}{
+	a.track(msg)
+	sent, err := a.post(msg)
+	if err != nil {
+		a.logger.Error("Failed to send message to node actor", map[string]interface{}{
 			"nodeId":  a.NodeID,
 			"msgType": msg.Type,
+			"error":   err.Error(),
 		})
-		return false
+	}
+	if !sent {
+		a.settle(msg)
+	}
+	return sent
+}
+
+// track counts a message sent without waiting for a response as work of the execution, which
+// doesn't complete until the message is handled or dropped
+func (a *NodeActor) track(msg NodeMessage) {
+	if msg.Response == nil && a.system != nil {
+		a.system.waitGroup.Add(1)
+	}
+}
+
+// settle ends the work of a tracked message
+func (a *NodeActor) settle(msg NodeMessage) {
+	if msg.Response == nil && a.system != nil {
+		a.system.waitGroup.Done()
 	}
 }
 
@@ -251,8 +344,23 @@ func (a *NodeActor) InitializeLoop(startIndex float64, maxIterations int) {
 	a.isLooping = true
 }
 
-// processMessages handles messages from the mailbox
+// processMessages handles messages from the mailbox. A panic or a closed mailbox ends the
+// loop without Stop, which the supervisor of the actor system picks up.
 func (a *NodeActor) processMessages() {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			a.logger.Error("Node actor panicked", map[string]interface{}{
+				"nodeId": a.NodeID,
+				"panic":  fmt.Sprint(recovered),
+				"stack":  string(debug.Stack()),
+			})
+			a.reportPanic(crash.SourceActor, bperrors.NodePanicked(recovered, debug.Stack()).
+				WithNodeInfo(a.NodeID, "").
+				WithBlueprintInfo(a.bp.ID, a.ExecutionID))
+			a.exit(fmt.Errorf("panic: %v", recovered))
+		}
+	}()
+
 	for {
 		select {
 		case <-a.done:
@@ -261,10 +369,14 @@ func (a *NodeActor) processMessages() {
 		case msg, ok := <-a.mailbox:
 			if !ok {
 				// Mailbox was closed
+				a.exit(errors.New("mailbox closed"))
 				return
 			}
-			// Process the message
+			// Process the message. A panic leaves it in flight for the replacement of the actor.
+			a.inFlight = &msg
 			response := a.handleMessage(msg)
+			a.inFlight = nil
+			a.settle(msg)
 
 			// Send the response if a response channel was provided
 			if msg.Response != nil {
@@ -308,7 +420,91 @@ func (a *NodeActor) handleMessage(msg NodeMessage) NodeResponse {
 }
 
 // handleExecuteMessage handles an execute message
-func (a *NodeActor) handleExecuteMessage(msg NodeMessage) NodeResponse {
+func (a *NodeActor) handleExecuteMessage(msg NodeMessage) (response NodeResponse) {
+	// A panic outside of the node's own code fails the node rather than the actor system
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err := bperrors.NodePanicked(recovered, debug.Stack()).
+				WithNodeInfo(a.NodeID, "").
+				WithBlueprintInfo(a.bp.ID, a.ExecutionID)
+			a.reportPanic(crash.SourceActor, err)
+			a.mutex.Lock()
+			a.status.Status = "error"
+			a.status.Error = err
+			a.status.EndTime = time.Now()
+			a.mutex.Unlock()
+			a.emitNodeErrorEvent(err)
+			response = NodeResponse{Success: false, Error: err}
+		}
+	}()
+
+	var limits NodeLimits
+	if a.system != nil {
+		limits = a.system.limits
+	}
+	limits, bpErr := limits.forNode(a.bp.FindNode(a.NodeID), a.bp.ID, a.ExecutionID)
+	if bpErr != nil {
+		return NodeResponse{Success: false, Error: bpErr}
+	}
+	sandbox := newNodeSandbox(limits, a.NodeID, a.bp.ID, a.ExecutionID)
+	sandbox.onPanic = func(err *bperrors.BlueprintError) { a.reportPanic(crash.SourceNode, err) }
+	var profile *profileSession
+	if a.system != nil {
+		profile = a.system.profile
+	}
+	sandbox.profiled = profile != nil
+
+	// Nodes beyond the blueprint's budget fail the execution whatever the error policy
+	if a.system != nil {
+		if bpErr := a.system.budget.charge(a.NodeID); bpErr != nil {
+			a.mutex.Lock()
+			a.status.Status = "error"
+			a.status.Error = bpErr
+			a.status.EndTime = time.Now()
+			a.mutex.Unlock()
+			a.emitNodeErrorEvent(bpErr)
+			a.system.fail(bpErr)
+			return NodeResponse{Success: false, Error: &propagatedError{bpErr}}
+		}
+	}
+
+	// Nodes of a cancelled execution don't start, like those beyond the budget
+	if a.system != nil {
+		if bpErr := executionCancelled(a.system.ctx, a.NodeID, a.bp.ID, a.ExecutionID); bpErr != nil {
+			a.mutex.Lock()
+			a.status.Status = "error"
+			a.status.Error = bpErr
+			a.status.EndTime = time.Now()
+			a.mutex.Unlock()
+			a.emitNodeErrorEvent(bpErr)
+			a.system.fail(bpErr)
+			return NodeResponse{Success: false, Error: &propagatedError{bpErr}}
+		}
+	}
+
+	// Apply pin defaults and fail nodes missing a required input. The node didn't run, so
+	// the error skips recovery as in the standard engine.
+	if bpErr := a.applyInputDefaults(); bpErr != nil {
+		a.mutex.Lock()
+		a.status.Status = "error"
+		a.status.Error = bpErr
+		a.status.EndTime = time.Now()
+		a.mutex.Unlock()
+		a.emitNodeErrorEvent(bpErr)
+		return NodeResponse{Success: false, Error: &propagatedError{bpErr}}
+	}
+
+	// Convert the inputs to the types declared by the node's pins, as the standard engine does
+	if bpErr := a.coerceInputs(); bpErr != nil {
+		a.mutex.Lock()
+		a.status.Status = "error"
+		a.status.Error = bpErr
+		a.status.EndTime = time.Now()
+		a.mutex.Unlock()
+		a.emitNodeErrorEvent(bpErr)
+		return NodeResponse{Success: false, Error: &propagatedError{bpErr}}
+	}
+
 	// Update node status
 	a.mutex.Lock()
 	a.status.Status = "executing"
@@ -355,12 +551,34 @@ func (a *NodeActor) handleExecuteMessage(msg NodeMessage) NodeResponse {
 	}
 
 	// Execute the node using the prepared context
-	err := a.node.Execute(execCtx) // Pass the ActorExecutionContext
+	span := -1
+	if a.debugMgr != nil {
+		span = a.debugMgr.BeginNodeSpan(a.ExecutionID, a.NodeID, a.NodeType)
+	}
+	var replay *replaySession
+	if a.system != nil {
+		replay = a.system.replay
+	}
+	execCtx.resetActivatedFlows()
+	err := sandbox.run(func() error {
+		return replay.executeOrReplay(a.NodeID, a.node, execCtx) // Pass the ActorExecutionContext
+	})
 
 	// Retrieve outputs generated during this execution step from the context
 	// Use the specific getter that accesses the context's local outputs
 	outputs := execCtx.GetAllOutputs()
 
+	// Oversized outputs are rejected before they're sent to other actors
+	if err == nil {
+		if err = sandbox.checkOutputs(outputs); err != nil {
+			outputs = make(map[string]types.Value)
+		}
+	}
+	if a.debugMgr != nil {
+		a.debugMgr.EndNodeSpan(a.ExecutionID, span, err)
+	}
+	profile.record(span, a.NodeType, sandbox)
+
 	// Update actor's persistent outputs if necessary (optional, depends on design)
 	a.mutex.Lock()
 	for k, v := range a.outputs {
@@ -410,9 +628,59 @@ func (a *NodeActor) handleExecuteMessage(msg NodeMessage) NodeResponse {
 
 	a.emitNodeCompletedEvent()
 	return NodeResponse{
-		Success:    true,
-		OutputPins: outputs, // Return outputs generated by this step
+		Success:        true,
+		OutputPins:     outputs, // Return outputs generated by this step
+		ActivatedFlows: execCtx.GetActivatedOutputFlows(),
+	}
+}
+
+// applyInputDefaults applies the pin defaults to the inputs the actor didn't receive
+func (a *NodeActor) applyInputDefaults() *bperrors.BlueprintError {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	inputs := make(map[string]types.Value, len(a.inputs))
+	for pinID, value := range a.inputs {
+		inputs[pinID] = value
+	}
+	if bpErr := applyInputDefaults(a.node, a.bp, a.bp.FindNode(a.NodeID), inputs, nil); bpErr != nil {
+		return bpErr.WithBlueprintInfo(a.bp.ID, a.ExecutionID)
+	}
+
+	for pinID, value := range inputs {
+		if current, exists := a.inputs[pinID]; exists && !current.IsNull() {
+			continue
+		}
+		a.inputs[pinID] = value
+		if a.ctx != nil {
+			a.ctx.SetInput(pinID, value)
+		}
+	}
+	return nil
+}
+
+// coerceInputs converts the inputs of the node to the types of its input pins
+func (a *NodeActor) coerceInputs() *bperrors.BlueprintError {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	inputs := make(map[string]types.Value, len(a.inputs))
+	for pinID, value := range a.inputs {
+		inputs[pinID] = value
+	}
+	if err := coerceInputs(a.NodeID, a.node, inputs); err != nil {
+		return bperrors.Wrap(err, bperrors.ErrorTypeConnection, bperrors.ErrTypeMismatch, err.Error(), bperrors.SeverityHigh).
+			WithNodeInfo(a.NodeID, err.PinID).
+			WithBlueprintInfo(a.bp.ID, a.ExecutionID)
+	}
+
+	for pinID, value := range inputs {
+		a.inputs[pinID] = value
+		if a.ctx != nil {
+			a.ctx.SetInput(pinID, value)
+		}
 	}
+	return nil
 }
 
 // handleInputMessage handles an input message
@@ -433,15 +701,23 @@ func (a *NodeActor) handleInputMessage(msg NodeMessage) NodeResponse {
 		// For now, let's allow it but log.
 	}
 
-	// Store the input value in the actor's input map
+	// Store the input value in the actor's input map, resolving pins with a fan-in policy
+	// from the values of all their connections
+	value := msg.Value
 	a.mutex.Lock()
-	a.inputs[msg.PinID] = msg.Value
+	if policy, exists := a.fanInPolicies[msg.PinID]; exists && msg.SourceNodeID != "" {
+		a.fanIn.set(msg.PinID, msg.SourceNodeID, msg.SourcePinID, msg.Value)
+		if resolved, ok := resolveFanIn(a.bp, a.NodeID, msg.PinID, policy, a.fanIn[msg.PinID]); ok {
+			value = resolved
+		}
+	}
+	a.inputs[msg.PinID] = value
 	a.mutex.Unlock()
 
 	// Also store input in the internal execution context 'a.ctx'
 	// This might be redundant if GetInputValue reads directly from actor.inputs
 	if a.ctx != nil {
-		a.ctx.SetInput(msg.PinID, msg.Value)
+		a.ctx.SetInput(msg.PinID, value)
 	}
 
 	// Log the received input for debugging
@@ -575,6 +851,13 @@ func (a *NodeActor) emitNodeCompletedEvent() {
 	}
 }
 
+// reportPanic reports a panic recovered in the actor through its actor system
+func (a *NodeActor) reportPanic(source string, err *bperrors.BlueprintError) {
+	if a.system != nil && a.system.panics != nil {
+		a.system.panics(source, err)
+	}
+}
+
 // emitNodeErrorEvent emits an event when the node encounters an error
 func (a *NodeActor) emitNodeErrorEvent(err error) {
 	for _, listener := range a.listeners {
@@ -645,6 +928,18 @@ func (a *NodeActor) GetOutput(pinID string) (types.Value, bool) {
 	return value, exists
 }
 
+// inputValues returns a copy of the input values the actor received so far
+func (a *NodeActor) inputValues() map[string]types.Value {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	inputs := make(map[string]types.Value, len(a.inputs))
+	for pinID, value := range a.inputs {
+		inputs[pinID] = value
+	}
+	return inputs
+}
+
 // GetStatus returns the current status of the node
 func (a *NodeActor) GetStatus() NodeStatus {
 	a.mutex.RLock()
@@ -753,6 +1048,23 @@ func (ctx *ActorExecutionContext) IsInputPinActive(pinID string) bool {
 	return false
 }
 
+// GetUpstreamOutput returns the latest output value of a node upstream of this one
+func (ctx *ActorExecutionContext) GetUpstreamOutput(nodeID, pinID string) (types.Value, bool) {
+	if ctx.actor == nil || ctx.actor.system == nil {
+		return types.Value{}, false
+	}
+	return ctx.actor.system.upstreamOutput(ctx.actor.bp, ctx.nodeID, nodeID, pinID)
+}
+
+// RunScope runs the branch connected to an output execution pin in a child scope of the
+// actor system
+func (ctx *ActorExecutionContext) RunScope(pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error) {
+	if ctx.actor == nil || ctx.actor.system == nil {
+		return types.Value{}, false, fmt.Errorf("node %s can't run pin %s in a child scope outside an actor system", ctx.nodeID, pinID)
+	}
+	return ctx.actor.system.runScope(ctx.actor, pinID, outputs, resultPin)
+}
+
 // GetInputValue retrieves an input value by pin ID
 func (ctx *ActorExecutionContext) GetInputValue(pinID string) (types.Value, bool) {
 	// Inputs are generally set before execution, potentially no mutex needed here
@@ -761,7 +1073,22 @@ func (ctx *ActorExecutionContext) GetInputValue(pinID string) (types.Value, bool
 	value, exists := ctx.inputs[pinID]
 
 	if !exists {
-		return ctx.baseCtx.GetInputValue(pinID)
+		value, exists = ctx.baseCtx.GetInputValue(pinID)
+	}
+
+	// A stream crossing actor boundaries can only be consumed by one node
+	if stream, ok := value.RawValue.(*types.Stream); ok {
+		if err := stream.Claim(ctx.nodeID); err != nil {
+			ctx.logger.Error("Stream input rejected", map[string]interface{}{"pinId": pinID, "error": err.Error()})
+			return types.Value{}, false
+		}
+	}
+
+	// Apply the same null and default semantics as the standard execution mode
+	if (!exists || value.IsNull()) && ctx.actor != nil && ctx.actor.node != nil {
+		if pin, found := types.FindPin(ctx.actor.node.GetInputPins(), pinID); found {
+			return types.ResolveInputValue(pin, value, exists)
+		}
 	}
 
 	return value, exists
@@ -787,6 +1114,13 @@ func (ctx *ActorExecutionContext) SetOutputValue(pinID string, value types.Value
 	}
 }
 
+// resetActivatedFlows forgets the flows activated by an earlier execution of the node
+func (ctx *ActorExecutionContext) resetActivatedFlows() {
+	ctx.activatedFlowMutex.Lock()
+	defer ctx.activatedFlowMutex.Unlock()
+	ctx.activatedFlows = make([]string, 0)
+}
+
 // ActivateOutputFlow marks an output execution flow as activated
 func (ctx *ActorExecutionContext) ActivateOutputFlow(pinID string) error {
 	ctx.activatedFlowMutex.Lock()         // Use specific mutex
@@ -824,6 +1158,10 @@ func (ctx *ActorExecutionContext) SetVariable(name string, value types.Value) {
 	defer ctx.sharedVarMutex.Unlock() // Unlock shared mutex
 	ctx.sharedVariables[name] = value
 	ctx.logger.Debug("Set shared variable", map[string]interface{}{"name": name, "value": value.RawValue})
+
+	if ctx.actor != nil && ctx.actor.debugMgr != nil {
+		ctx.actor.debugMgr.RecordVariableChange(ctx.executionID, name, value.RawValue)
+	}
 }
 
 // Logger returns the execution logger
@@ -953,6 +1291,14 @@ func (ctx *ActorExecutionContext) GetExecutionID() string {
 	return ctx.executionID
 }
 
+// Context returns the context of the execution, taken from the decorated context
+func (ctx *ActorExecutionContext) Context() context.Context {
+	if ctx.baseCtx == nil {
+		return context.Background()
+	}
+	return ctx.baseCtx.Context()
+}
+
 // SaveData is required by node.ExecutionContext
 func (ctx *ActorExecutionContext) SaveData(key string, value interface{}) {
 	// Actor context doesn't directly manage the underlying context.Context's values
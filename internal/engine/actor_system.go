@@ -6,8 +6,10 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/common"
 	"webblueprint/internal/engineext"
+	"webblueprint/internal/expr"
 	"webblueprint/internal/node"
 	"webblueprint/internal/nodes/data"
 	"webblueprint/internal/types"
@@ -18,7 +20,7 @@ import (
 type ActorSystem struct {
 	ctxManager    *engineext.ContextManager
 	actors        map[string]*NodeActor
-	connections   map[string][]Connection
+	connections   map[string][]Connection // Shared with the other executions of the plan, read only
 	executionID   string
 	blueprintID   string
 	nodeRegistry  map[string]node.NodeFactory
@@ -36,6 +38,58 @@ type ActorSystem struct {
 		inputs, outputs map[string]interface{}) error
 	anyHook func(ctx context.Context, executionID, nodeID, level, message string,
 		details map[string]interface{}) error
+
+	// Records or replays the results of nondeterministic nodes
+	replay *replaySession
+
+	// Profiles the node executions, if the execution is profiled
+	profile *profileSession
+
+	// Node ID -> factory replacing the registered node type
+	overrides map[string]node.NodeFactory
+
+	// Node types registered in the namespace of the blueprint, e.g. its variable nodes
+	blueprintTypes map[string]node.NodeFactory
+
+	// Routes unhandled node errors by the blueprint's error policy
+	errorPolicy *errorPolicyState
+	errorMutex  sync.Mutex
+
+	// Applies the recovery strategies registered for node errors
+	recovery *recoveryState
+
+	// Holds join nodes back until enough of their branches arrived
+	joins *joinState
+
+	// Coalesces the triggers of rate limited nodes
+	timers *engineext.TimerService
+
+	// Bounds every node execution
+	limits NodeLimits
+
+	// Restarts the actors whose message loop ended, up to the restart budget of each node
+	restarts        map[string]int
+	restartBudget   int
+	failure         error // Fails the execution whatever the error policy, e.g. once a node used up its restart budget
+	supervisorMutex sync.Mutex
+
+	// Sizes the mailboxes of the actors and records their overflows
+	mailboxes mailboxPolicies
+	overflows *overflowState
+
+	// Charges the node executions against the blueprint's execution budget
+	budget *budgetState
+
+	// Context of the execution; nodes don't start once it's done
+	ctx context.Context
+
+	// Reports the panics recovered in the actors, if set
+	panics func(source string, err *bperrors.BlueprintError)
+
+	// Set on the system of a child scope run by a scoped node, which keeps the first node
+	// error of the scope instead of routing it by the error policy
+	parent     *ActorSystem
+	scopeError error
 }
 
 // Connection represents a connection between nodes
@@ -45,7 +99,9 @@ type Connection struct {
 	SourcePinID    string
 	TargetNodeID   string
 	TargetPinID    string
-	ConnectionType string // "execution" or "data"
+	ConnectionType string           // "execution" or "data"
+	Transform      *expr.Expression // Applied to the values of data connections, if set
+	Condition      *expr.Expression // Execution connections are only followed if it holds, if set
 }
 
 // NewActorSystem creates a new actor system for a blueprint execution
@@ -53,6 +109,7 @@ func NewActorSystem(
 	ctxManager *engineext.ContextManager,
 	executionID string,
 	bp *blueprint.Blueprint,
+	plan *ExecutionPlan,
 	nodeRegistry map[string]node.NodeFactory,
 	logger node.Logger,
 	listeners []ExecutionListener,
@@ -64,18 +121,9 @@ func NewActorSystem(
 	anyHook func(ctx context.Context, executionID, nodeID, level, message string,
 		details map[string]interface{}) error,
 ) (*ActorSystem, error) {
-	// Convert blueprint connections to internal format
-	connections := make(map[string][]Connection)
-	for _, conn := range bp.Connections {
-		sourceNodeID := conn.SourceNodeID
-		connections[sourceNodeID] = append(connections[sourceNodeID], Connection{
-			ID:             conn.ID,
-			SourceNodeID:   conn.SourceNodeID,
-			SourcePinID:    conn.SourcePinID,
-			TargetNodeID:   conn.TargetNodeID,
-			TargetPinID:    conn.TargetPinID,
-			ConnectionType: conn.ConnectionType,
-		})
+	// Connections come from the plan, which parsed their expressions once for the version
+	if plan.invalidExpression != nil {
+		return nil, plan.invalidExpression.blueprintError(plan.invalidNodeID, bp.ID, executionID)
 	}
 
 	// Initialize variables
@@ -87,7 +135,7 @@ func NewActorSystem(
 	return &ActorSystem{
 		ctxManager:        ctxManager,
 		actors:            make(map[string]*NodeActor),
-		connections:       connections,
+		connections:       plan.connections,
 		executionID:       executionID,
 		blueprintID:       bp.ID,
 		nodeRegistry:      nodeRegistry,
@@ -99,6 +147,9 @@ func NewActorSystem(
 		hooks:             hooks,
 		nodeExecutionHook: nodeExecutionHook,
 		anyHook:           anyHook,
+		restarts:          make(map[string]int),
+		restartBudget:     DefaultActorRestartBudget,
+		mailboxes:         mailboxPolicies{defaults: DefaultMailboxPolicy},
 	}, nil
 }
 
@@ -107,83 +158,25 @@ func (s *ActorSystem) Start(bp *blueprint.Blueprint) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// First pass: create all actors
-	for _, nodeConfig := range bp.Nodes {
-		// Get the node factory
-		factory, exists := s.nodeRegistry[nodeConfig.Type]
-		if !exists {
-			return fmt.Errorf("node type not registered: %s", nodeConfig.Type)
+	// First pass: create all actors. Comments only document the blueprint and get none.
+	for _, nodeConfig := range bp.ExecutableNodes() {
+		actor, actorCtx, err := s.newActor(bp, nodeConfig)
+		if err != nil {
+			return err
 		}
 
-		// Create the node instance
-		nodeInstance := factory()
-
-		// Create a node-specific logger with the nodeId
-		nodeLogger := s.logger
-		nodeLogger.Opts(map[string]interface{}{
-			"nodeId": nodeConfig.ID,
-		})
-
-		// inputs := s.preprocessInputs(bp, nodeConfig.ID, s.executionID, s.variables) // Removed call
-		inputs := make(map[string]types.Value) // Initialize empty inputs, handled by messages now
-
-		// Simple activateFlow placeholder
-		activateFlow := func(ctx *engineext.DefaultExecutionContext, nodeID, pinID string) error {
-			// In the actor model, the actual triggering happens in followConnections.
-			// This callback might be less critical here.
-			return nil
-		}
-
-		actorCtx := s.ctxManager.CreateActorContext(
-			bp,
-			nodeConfig.ID,
-			nodeConfig.Type,
-			bp.ID,
-			s.executionID,
-			inputs,
-			s.variables,
-			s.logger,
-			s.hooks,
-			activateFlow,
-		)
-
-		// Removed incorrect attempt to create LoopContext here.
-		// LoopContext creation should be handled within the LoopNode's execution.
-		// if nodeConfig.Type == "loop" {
-		// 	actorCtx = s.ctxManager.CreateLoopContext() // This was incorrect
-		// }
-
-		// Create the actor
-		actor := NewNodeActor(
-			nodeConfig.ID,
-			nodeConfig.Type,
-			bp,
-			s.executionID,
-			nodeInstance,
-			nodeLogger,
-			s.listeners,
-			s.debugMgr,
-			s.variables, // Pass ActorSystem's shared variables map
-			&s.mutex,    // Pass ActorSystem's mutex for shared variables
-			s,           // Pass ActorSystem reference
-			s.nodeExecutionHook,
-			s.anyHook,
-		)
-
-		actorCtx.SaveData("node.properties", actor.properties)
-		actorCtx.SaveData("node.inputPins", nodeInstance.GetInputPins())
-
 		// Store the actor
 		s.actors[nodeConfig.ID] = actor
 
 		// Start the actor
 		actor.Start(actorCtx)
+		go s.supervise(actor)
 	}
 
 	// TODO try to delete those codes ?
 	// Second pass: execute data nodes immediately
 	// This ensures that constant values are available right away
-	for _, nodeConfig := range bp.Nodes {
+	for _, nodeConfig := range bp.ExecutableNodes() {
 		actor := s.actors[nodeConfig.ID]
 
 		// Check if this is a data node (like constant-*)
@@ -216,6 +209,84 @@ func (s *ActorSystem) Start(bp *blueprint.Blueprint) error {
 	return nil
 }
 
+// newActor creates the actor of a blueprint node and the context it starts with
+func (s *ActorSystem) newActor(bp *blueprint.Blueprint, nodeConfig blueprint.BlueprintNode) (*NodeActor, node.ExecutionContext, error) {
+	// Get the node factory
+	factory, exists := resolveNodeFactory(s.nodeRegistry, s.blueprintTypes, s.overrides, &nodeConfig)
+	if !exists {
+		return nil, nil, fmt.Errorf("node type not registered: %s", nodeConfig.Type)
+	}
+
+	// Create the node instance
+	nodeInstance := factory()
+	if err := configureNodePins(nodeInstance, &nodeConfig, bp.ID, s.executionID); err != nil {
+		return nil, nil, err
+	}
+
+	// Create a node-specific logger honoring the node's log level
+	nodeLogger := newNodeLogger(s.logger, s.executionID, &nodeConfig, s.anyHook)
+
+	// inputs := s.preprocessInputs(bp, nodeConfig.ID, s.executionID, s.variables) // Removed call
+	inputs := make(map[string]types.Value) // Initialize empty inputs, handled by messages now
+
+	// Simple activateFlow placeholder
+	activateFlow := func(ctx *engineext.DefaultExecutionContext, nodeID, pinID string) error {
+		// In the actor model, the actual triggering happens in followConnections.
+		// This callback might be less critical here.
+		return nil
+	}
+
+	actorCtx := s.ctxManager.CreateActorContext(
+		bp,
+		nodeConfig.ID,
+		nodeConfig.Type,
+		bp.ID,
+		s.executionID,
+		inputs,
+		s.variables,
+		nodeLogger,
+		s.hooks,
+		activateFlow,
+	)
+
+	// Removed incorrect attempt to create LoopContext here.
+	// LoopContext creation should be handled within the LoopNode's execution.
+	// if nodeConfig.Type == "loop" {
+	// 	actorCtx = s.ctxManager.CreateLoopContext() // This was incorrect
+	// }
+
+	// Create the actor
+	actor := NewNodeActor(
+		nodeConfig.ID,
+		nodeConfig.Type,
+		bp,
+		s.executionID,
+		nodeInstance,
+		nodeLogger,
+		s.listeners,
+		s.debugMgr,
+		s.variables,       // Pass ActorSystem's shared variables map
+		s.variableMutex(), // Pass ActorSystem's mutex for shared variables
+		s,                 // Pass ActorSystem reference
+		s.nodeExecutionHook,
+		s.anyHook,
+	)
+
+	actorCtx.SaveData("node.properties", actor.properties)
+	actorCtx.SaveData("node.inputPins", nodeInstance.GetInputPins())
+
+	return actor, actorCtx, nil
+}
+
+// variableMutex returns the mutex guarding the variables, which child scopes share with
+// their parent
+func (s *ActorSystem) variableMutex() *sync.RWMutex {
+	if s.parent != nil {
+		return s.parent.variableMutex()
+	}
+	return &s.mutex
+}
+
 // isDataNode determines if a node is a "data node" that doesn't require execution flow
 func isDataNode(nodeType string) bool {
 	// List of node types that are considered data nodes
@@ -294,6 +365,11 @@ func (s *ActorSystem) executeNode(nodeID string) {
 		return
 	}
 
+	// A failed execution doesn't start new nodes unless the error policy lets the other branches continue
+	if s.errorPolicy.stopped(nodeID) || s.failed() != nil {
+		return
+	}
+
 	// Log node execution
 	s.logger.Debug("Executing node", map[string]interface{}{
 		"nodeId":   nodeID,
@@ -309,13 +385,16 @@ func (s *ActorSystem) executeNode(nodeID string) {
 	}
 
 	// Send the message to the actor
-	response := actor.Send(msg)
+	actor, response := s.send(actor, msg)
 
 	if !response.Success {
 		s.logger.Error("Node execution failed", map[string]interface{}{
 			"nodeId": nodeID,
 			"error":  response.Error.Error(),
 		})
+		if !s.recoverNodeError(actor, "", response.Error) {
+			s.handleNodeError(nodeID, response.Error)
+		}
 		return
 	}
 
@@ -352,23 +431,21 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 		return
 	}
 
-	// Use the explicit flow signal from the response, if available
+	// Use the explicit flow signal from the response, if available, otherwise the flows the
+	// node activated. Responses that report neither follow every execution connection.
 	flowToActivate := response.FlowToActivate
-	if flowToActivate == "" {
-		// Fallback to context's activated flows if response doesn't specify one
-		// This maintains compatibility with nodes not using the new response field
-		if extCtx := engineext.GetExtendedContext(actor.ctx); extCtx != nil {
-			activatedFlows := extCtx.GetActivatedOutputFlows()
-			if len(activatedFlows) > 0 {
-				flowToActivate = activatedFlows[0] // Use the first activated flow as default? Or handle multiple?
-				if len(activatedFlows) > 1 {
-					s.logger.Warn("Multiple flows activated in context, but only following the first", map[string]interface{}{"nodeId": actor.NodeID, "flows": activatedFlows})
-				}
-			}
-		} else {
-			s.logger.Warn("Could not retrieve ExtendedExecutionContext to get activated flows", map[string]interface{}{"nodeId": actor.NodeID, "contextType": fmt.Sprintf("%T", actor.decoratedCtx)})
+	activated := make(map[string]bool)
+	if flowToActivate != "" {
+		activated[flowToActivate] = true
+	} else {
+		for _, pinID := range response.ActivatedFlows {
+			activated[pinID] = true
+		}
+		if len(response.ActivatedFlows) > 0 {
+			flowToActivate = response.ActivatedFlows[0]
 		}
 	}
+	routed := response.FlowToActivate != "" || response.ActivatedFlows != nil
 
 	// Process data connections first to ensure data is available before execution
 	for _, conn := range connections {
@@ -402,13 +479,31 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 				continue
 			}
 
+			produced := value
+			value, transformErr := applyTransform(conn.Transform, conn.ID, conn.TargetPinID, value)
+			if transformErr != nil {
+				s.logger.Error("Connection transform failed", map[string]interface{}{
+					"connectionId": conn.ID,
+					"targetNodeId": conn.TargetNodeID,
+					"error":        transformErr.Error(),
+				})
+				s.handleNodeError(conn.TargetNodeID, transformErr.blueprintError(conn.TargetNodeID, s.blueprintID, s.executionID))
+				continue
+			}
+
+			if s.debugMgr != nil {
+				s.debugMgr.RecordDataFlow(s.executionID, lineageEdge(conn.ID, conn.SourceNodeID, conn.SourcePinID, conn.TargetNodeID, conn.TargetPinID, conn.Transform, produced, value))
+			}
+
 			// Send the value to the target actor
 			inputMsg := NodeMessage{
-				Type:  "input",
-				PinID: conn.TargetPinID,
-				Value: value,
+				Type:         "input",
+				PinID:        conn.TargetPinID,
+				Value:        value,
+				SourceNodeID: conn.SourceNodeID,
+				SourcePinID:  conn.SourcePinID,
 			}
-			if sent := targetActor.SendAsync(inputMsg); !sent {
+			if sent := s.sendAsync(targetActor, inputMsg); !sent {
 				s.logger.Warn("Failed to send input value to target actor", map[string]interface{}{
 					"sourceNodeId": conn.SourceNodeID,
 					"sourcePinId":  conn.SourcePinID,
@@ -466,6 +561,28 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 	for _, conn := range connections {
 		// Check if the connection's source pin matches the flow we need to activate
 		if conn.ConnectionType == "execution" {
+			if routed && !activated[conn.SourcePinID] {
+				continue
+			}
+
+			holds, conditionErr := checkCondition(conn.Condition, conn.ID, conn.SourcePinID, response.OutputPins)
+			if conditionErr != nil {
+				s.logger.Error("Connection condition failed", map[string]interface{}{
+					"connectionId": conn.ID,
+					"sourceNodeId": actor.NodeID,
+					"error":        conditionErr.Error(),
+				})
+				s.handleNodeError(actor.NodeID, conditionErr.blueprintError(actor.NodeID, s.blueprintID, s.executionID))
+				continue
+			}
+			if !holds {
+				continue
+			}
+
+			// Scoped nodes only run the branches of their scope pins in child scopes
+			if isScopePin(actor.node, conn.SourcePinID) && conn.SourcePinID != flowToActivate {
+				continue
+			}
 
 			// Get the target actor
 			s.mutex.RLock()
@@ -475,8 +592,13 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 				continue // Target actor doesn't exist
 			}
 
+			if s.debugMgr != nil {
+				s.debugMgr.RecordActivation(s.executionID, actor.NodeID, conn.SourcePinID, conn.TargetNodeID, conn.TargetPinID)
+			}
+
 			// --- Special Handling for Loop Node ---
-			if actor.NodeType == "loop" && conn.SourcePinID == flowToActivate {
+			// Only the loop body iterates; the completed flow runs like any other
+			if actor.NodeType == "loop" && flowToActivate == "loop" && conn.SourcePinID == flowToActivate {
 				indexValue, indexExists := response.OutputPins["index"]
 				if !indexExists {
 					s.logger.Warn("Loop node activated 'loop' pin but 'index' output is missing", map[string]interface{}{"loopNodeId": actor.NodeID})
@@ -496,9 +618,10 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 				go func(sourceActor, targetActor *NodeActor, msg NodeMessage) {
 					defer s.waitGroup.Done()
 					s.logger.Debug("Executing loop body node", map[string]interface{}{"targetNodeId": targetActor.NodeID, "indexPayload": msg.Value.RawValue})
-					execResponse := targetActor.Send(msg) // Execute the loop body node
+					targetActor, execResponse := s.send(targetActor, msg) // Execute the loop body node
 					if !execResponse.Success {
 						s.logger.Error("Loop body node execution failed", map[string]interface{}{"nodeId": targetActor.NodeID, "error": execResponse.Error})
+						s.handleNodeError(targetActor.NodeID, execResponse.Error)
 						// If body fails, should we stop the loop? Send error back to loop actor?
 						// For now, we just log and don't proceed with this path or signal loop actor.
 						return
@@ -508,7 +631,7 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 
 					// After body execution (and its downstream effects) are done *for this iteration*,
 					// signal the original LoopNode actor to proceed to the next iteration.
-					sourceActor.Send(NodeMessage{Type: "loop_next"})
+					s.send(sourceActor, NodeMessage{Type: "loop_next"})
 
 					//s.logger.Debug("execution response", map[string]interface{}{
 					//	"response": execResponse,
@@ -529,8 +652,19 @@ func (s *ActorSystem) followConnections(actor *NodeActor, response NodeResponse)
 	}
 }
 
-// Wait waits for all execution to complete with a timeout
+// Wait waits for all execution to complete with a timeout, or until the context of the
+// execution is done without one
 func (s *ActorSystem) Wait(timeout time.Duration) bool {
+	// Without a timeout the execution is waited for until its context is done
+	if timeout <= 0 {
+		select {
+		case <-s.executionDone:
+			return true
+		case <-s.context().Done():
+			return false
+		}
+	}
+
 	select {
 	case <-s.executionDone:
 		// Execution completed
@@ -541,6 +675,14 @@ func (s *ActorSystem) Wait(timeout time.Duration) bool {
 	}
 }
 
+// context returns the context of the execution, the background context if it has none
+func (s *ActorSystem) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 // Stop stops all actors and cleans up resources
 func (s *ActorSystem) Stop() {
 	s.mutex.Lock()
@@ -606,6 +748,24 @@ func (s *ActorSystem) executeNodeTriggered(nodeID, triggerPinID string) { // Sim
 		return
 	}
 
+	if s.errorPolicy.stopped(nodeID) || s.failed() != nil {
+		return
+	}
+
+	// Join nodes only run once enough of their branches arrived
+	if s.joins != nil && isJoinNode(actor.node) && !s.joins.arrive(actor.node, nodeID, triggerPinID) {
+		s.logger.Debug("Join node waiting for branches", map[string]interface{}{"nodeId": nodeID, "triggerPin": triggerPinID})
+		return
+	}
+
+	// Rate limited nodes wait for their timer, which runs them in the standard engine
+	if pass, err := passRateLimit(s.timers, actor.node, s.blueprintID, nodeID, s.executionID, actor.inputValues()); !pass {
+		if err != nil {
+			s.logger.Warn("Failed to persist node timer", map[string]interface{}{"nodeId": nodeID, "error": err.Error()})
+		}
+		return
+	}
+
 	s.logger.Debug("Executing node (triggered)", map[string]interface{}{"nodeId": nodeID, "triggerPin": triggerPinID})
 
 	// Create execute message
@@ -622,10 +782,13 @@ func (s *ActorSystem) executeNodeTriggered(nodeID, triggerPinID string) { // Sim
 	}
 
 	// Send the message to the actor
-	response := actor.Send(msg)
+	actor, response := s.send(actor, msg)
 
 	if !response.Success {
 		s.logger.Error("Node execution failed (triggered)", map[string]interface{}{"nodeId": nodeID, "error": response.Error})
+		if !s.recoverNodeError(actor, triggerPinID, response.Error) {
+			s.handleNodeError(nodeID, response.Error)
+		}
 		return
 	}
 
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/common"
+	"webblueprint/pkg/blueprint"
+)
+
+// Limits an execution can exceed its budget for
+const (
+	budgetDuration       = "duration"
+	budgetNodeExecutions = "nodeExecutions"
+)
+
+// budgetState charges the node executions of an execution against the budget of its blueprint.
+// The budget is checked before each node runs, so a node that is already running finishes.
+type budgetState struct {
+	blueprintID    string
+	executionID    string
+	maxDuration    time.Duration
+	maxNodes       int
+	start          time.Time
+	nodeExecutions int
+	exceeded       string
+	err            *bperrors.BlueprintError
+	mutex          sync.Mutex
+}
+
+// newBudgetState starts charging an execution against the budget of its blueprint. Budgets
+// with an invalid duration are reported by validation and only bound the node executions.
+func newBudgetState(bp *blueprint.Blueprint, executionID string) *budgetState {
+	budget := bp.GetBudget()
+	maxDuration, _ := budget.Duration()
+	return &budgetState{
+		blueprintID: bp.ID,
+		executionID: executionID,
+		maxDuration: maxDuration,
+		maxNodes:    budget.MaxNodeExecutions,
+		start:       time.Now(),
+	}
+}
+
+// bounded reports whether the blueprint sets a budget at all
+func (s *budgetState) bounded() bool {
+	return s != nil && (s.maxDuration > 0 || s.maxNodes > 0)
+}
+
+// charge counts the execution of a node, or returns the budget-exceeded error if the
+// execution used up its budget. Once exceeded, every later node fails with the same error.
+func (s *budgetState) charge(nodeID string) *bperrors.BlueprintError {
+	if !s.bounded() {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	elapsed := time.Since(s.start)
+	switch {
+	case s.maxDuration > 0 && elapsed > s.maxDuration:
+		s.exceed(nodeID, budgetDuration, fmt.Sprintf("execution exceeded its budget of %s", s.maxDuration))
+	case s.maxNodes > 0 && s.nodeExecutions >= s.maxNodes:
+		s.exceed(nodeID, budgetNodeExecutions, fmt.Sprintf("execution exceeded its budget of %d node executions", s.maxNodes))
+	default:
+		s.nodeExecutions++
+		return nil
+	}
+	return s.err
+}
+
+// exceed records the limit the execution exceeded before running a node
+func (s *budgetState) exceed(nodeID, limit, message string) {
+	s.exceeded = limit
+	s.err = bperrors.New(
+		bperrors.ErrorTypeQuota,
+		bperrors.ErrBudgetExceeded,
+		message,
+		bperrors.SeverityHigh,
+	).WithNodeInfo(nodeID, "").WithBlueprintInfo(s.blueprintID, s.executionID).WithDetails(map[string]interface{}{
+		"limit":          limit,
+		"maxDuration":    s.maxDuration.String(),
+		"duration":       time.Since(s.start).String(),
+		"maxNodes":       s.maxNodes,
+		"nodeExecutions": s.nodeExecutions,
+	})
+}
+
+// exhausted returns the budget-exceeded error of the execution, if any
+func (s *budgetState) exhausted() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err == nil {
+		return nil
+	}
+	return s.err
+}
+
+// usage returns how much of the budget the execution consumed, or nil if it has no budget
+func (s *budgetState) usage() *common.BudgetUsage {
+	if !s.bounded() {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return &common.BudgetUsage{
+		MaxDurationMs:     s.maxDuration.Milliseconds(),
+		DurationMs:        time.Since(s.start).Milliseconds(),
+		MaxNodeExecutions: s.maxNodes,
+		NodeExecutions:    s.nodeExecutions,
+		Exceeded:          s.exceeded,
+	}
+}
+
+// setBudget starts charging an execution against the budget of its blueprint
+func (e *ExecutionEngine) setBudget(executionID string, bp *blueprint.Blueprint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.budgets[executionID] = newBudgetState(bp, executionID)
+}
+
+// budget returns the budget state of an execution, if any
+func (e *ExecutionEngine) budget(executionID string) *budgetState {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.budgets[executionID]
+}
+
+// clearBudget drops the budget state of an execution
+func (e *ExecutionEngine) clearBudget(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.budgets, executionID)
+}
@@ -0,0 +1,164 @@
+package engine_test
+
+import (
+	"sync"
+	"testing"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// sourceNode outputs a fixed value on its "value" pin
+type sourceNode struct {
+	node.BaseNode
+	value types.Value
+}
+
+func newSourceNode(value types.Value) node.Node {
+	return &sourceNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{TypeID: "test-source", Name: "Source"},
+			Inputs:   []types.Pin{{ID: "exec", Name: "Execute", Type: types.PinTypes.Execution}},
+			Outputs: []types.Pin{
+				{ID: "then", Name: "Then", Type: types.PinTypes.Execution},
+				{ID: "value", Name: "Value", Type: value.Type},
+			},
+		},
+		value: value,
+	}
+}
+
+func (n *sourceNode) Execute(ctx node.ExecutionContext) error {
+	ctx.SetOutputValue("value", n.value)
+	return ctx.ActivateOutputFlow("then")
+}
+
+// probeNode records the value it receives on its input pin, by execution
+type probeNode struct {
+	node.BaseNode
+	received *sync.Map
+}
+
+func newProbeNode(pinType *types.PinType, received *sync.Map) node.Node {
+	return &probeNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{TypeID: "test-probe", Name: "Probe"},
+			Inputs: []types.Pin{
+				{ID: "exec", Name: "Execute", Type: types.PinTypes.Execution},
+				{ID: "value", Name: "Value", Type: pinType, Optional: true},
+			},
+			Outputs: []types.Pin{{ID: "then", Name: "Then", Type: types.PinTypes.Execution}},
+		},
+		received: received,
+	}
+}
+
+func (n *probeNode) Execute(ctx node.ExecutionContext) error {
+	value, exists := ctx.GetInputValue("value")
+	if exists {
+		n.received.Store(ctx.GetExecutionID(), value)
+	}
+	return ctx.ActivateOutputFlow("then")
+}
+
+// probeBlueprint connects the value of a source node to the input of a probe node
+func probeBlueprint(source, probe string) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("coercion", "Coercion", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "source", Type: source})
+	bp.AddNode(blueprint.BlueprintNode{ID: "probe", Type: probe})
+	for _, conn := range []blueprint.Connection{
+		{ID: "exec-source", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "source", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "exec-probe", SourceNodeID: "source", SourcePinID: "then", TargetNodeID: "probe", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "data-value", SourceNodeID: "source", SourcePinID: "value", TargetNodeID: "probe", TargetPinID: "value", ConnectionType: "data"},
+	} {
+		bp.AddConnection(conn)
+	}
+	return bp
+}
+
+func TestInputsAreCoercedInEveryMode(t *testing.T) {
+	var received sync.Map
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			flowEngine := newEngine(t, mode)
+			registry.GetInstance().RegisterNodeType("string-source", func() node.Node {
+				return newSourceNode(types.NewValue(types.PinTypes.String, "42"))
+			})
+			registry.GetInstance().RegisterNodeType("number-probe", func() node.Node {
+				return newProbeNode(types.PinTypes.Number, &received)
+			})
+			executionID := "coerced-" + string(mode)
+
+			result, err := flowEngine.Execute(probeBlueprint("string-source", "number-probe"), executionID, map[string]types.Value{})
+			if err != nil || !result.Success {
+				t.Fatalf("expected the execution to succeed, got %v", err)
+			}
+
+			stored, exists := received.Load(executionID)
+			if !exists {
+				t.Fatal("expected the probe to receive a value")
+			}
+			value := stored.(types.Value)
+			if value.Type != types.PinTypes.Number || value.RawValue != float64(42) {
+				t.Errorf("expected the string to be coerced to the number 42, got %v (%s)", value.RawValue, value.Type.ID)
+			}
+		})
+	}
+}
+
+func TestUncoercibleInputsFailInEveryMode(t *testing.T) {
+	var received sync.Map
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			flowEngine := newEngine(t, mode)
+			registry.GetInstance().RegisterNodeType("text-source", func() node.Node {
+				return newSourceNode(types.NewValue(types.PinTypes.String, "not a number"))
+			})
+			registry.GetInstance().RegisterNodeType("strict-number-probe", func() node.Node {
+				return newProbeNode(types.PinTypes.Number, &received)
+			})
+			executionID := "mismatch-" + string(mode)
+
+			result, err := flowEngine.Execute(probeBlueprint("text-source", "strict-number-probe"), executionID, map[string]types.Value{})
+			if err == nil && result.Success {
+				t.Fatal("expected the execution to fail on the type mismatch")
+			}
+			if _, ran := received.Load(executionID); ran {
+				t.Error("expected the probe not to run with an uncoercible input")
+			}
+		})
+	}
+}
+
+func TestNullInputsAreHandledAlikeInEveryMode(t *testing.T) {
+	var received sync.Map
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			flowEngine := newEngine(t, mode)
+			registry.GetInstance().RegisterNodeType("null-source", func() node.Node {
+				return newSourceNode(types.NullValue(types.PinTypes.String))
+			})
+			registry.GetInstance().RegisterNodeType("null-probe", func() node.Node {
+				return newProbeNode(types.PinTypes.Number, &received)
+			})
+			executionID := "null-" + string(mode)
+
+			result, err := flowEngine.Execute(probeBlueprint("null-source", "null-probe"), executionID, map[string]types.Value{})
+			if err != nil || !result.Success {
+				t.Fatalf("expected the execution to succeed, got %v", err)
+			}
+
+			stored, exists := received.Load(executionID)
+			if !exists {
+				t.Fatal("expected the probe to receive the null value")
+			}
+			value := stored.(types.Value)
+			if !value.IsNull() || value.Type != types.PinTypes.Number {
+				t.Errorf("expected a null number rather than a zero value, got %v (%s)", value.RawValue, value.Type.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/blueprint"
+)
+
+// QueuedExecution is an execution waiting for the running execution of its blueprint
+type QueuedExecution struct {
+	ExecutionID string    `json:"executionId"`
+	BlueprintID string    `json:"blueprintId"`
+	QueuedAt    time.Time `json:"queuedAt"`
+	Position    int       `json:"position"` // 1 for the next execution to start
+}
+
+// BlueprintConcurrency describes the running and queued executions of a blueprint whose
+// concurrency policy keeps its executions from overlapping
+type BlueprintConcurrency struct {
+	BlueprintID string                      `json:"blueprintId"`
+	Policy      blueprint.ConcurrencyPolicy `json:"policy"`
+	Running     string                      `json:"running,omitempty"` // ID of the running execution
+	Queued      []QueuedExecution           `json:"queued"`
+}
+
+// waitingExecution is a queued execution and the channel it's admitted or cancelled through
+type waitingExecution struct {
+	executionID string
+	queuedAt    time.Time
+	admitted    chan error
+}
+
+// concurrencySlot is the single execution slot of a blueprint that doesn't run in parallel
+type concurrencySlot struct {
+	policy  blueprint.ConcurrencyPolicy
+	running string
+	queue   []*waitingExecution
+}
+
+// acquireConcurrency takes the execution slot of a blueprint whose concurrency policy keeps
+// executions from overlapping, waiting for it in queue mode. The returned function gives
+// the slot to the next queued execution. An execution that already holds the slot gets it
+// again with nothing to release, so the scheduled and direct paths can both call this.
+func (e *ExecutionEngine) acquireConcurrency(bp *blueprint.Blueprint, executionID string) (func(), error) {
+	policy := bp.GetConcurrencyPolicy()
+	if policy.Mode == blueprint.ConcurrencyAllowParallel {
+		return func() {}, nil
+	}
+
+	e.concurrencyMutex.Lock()
+	slot, exists := e.concurrency[bp.ID]
+	if !exists {
+		slot = &concurrencySlot{}
+		e.concurrency[bp.ID] = slot
+	}
+	slot.policy = policy
+
+	if slot.running == executionID {
+		e.concurrencyMutex.Unlock()
+		return func() {}, nil
+	}
+
+	release := func() { e.releaseConcurrency(bp.ID, executionID) }
+	if slot.running == "" {
+		slot.running = executionID
+		e.concurrencyMutex.Unlock()
+		return release, nil
+	}
+
+	if err := slot.reject(bp, executionID); err != nil {
+		e.concurrencyMutex.Unlock()
+		return nil, err
+	}
+
+	waiting := &waitingExecution{
+		executionID: executionID,
+		queuedAt:    time.Now(),
+		admitted:    make(chan error, 1),
+	}
+	slot.queue = append(slot.queue, waiting)
+	e.concurrencyMutex.Unlock()
+
+	record := e.executions.store(ExecutionStatus{
+		ExecutionID: executionID,
+		Status:      "queued",
+		StartTime:   waiting.queuedAt,
+	})
+
+	if err := <-waiting.admitted; err != nil {
+		record.update(func(status *ExecutionStatus) {
+			status.Status = "cancelled"
+			status.EndTime = time.Now()
+		})
+		return nil, err
+	}
+	return release, nil
+}
+
+// reject returns the error of an execution the slot can't take or queue, if any
+func (s *concurrencySlot) reject(bp *blueprint.Blueprint, executionID string) error {
+	if s.running == "" || s.running == executionID {
+		return nil
+	}
+
+	if s.policy.Mode == blueprint.ConcurrencyRejectIfRunning {
+		return blueprintRunningError(bp, executionID, s.running, fmt.Sprintf("the blueprint doesn't run concurrently and execution %s is running", s.running))
+	}
+	if s.policy.MaxQueued > 0 && len(s.queue) >= s.policy.MaxQueued {
+		return blueprintRunningError(bp, executionID, s.running, fmt.Sprintf("the execution queue of the blueprint is full (%d waiting behind execution %s)", s.policy.MaxQueued, s.running))
+	}
+	return nil
+}
+
+// CheckConcurrency returns the error the concurrency policy of a blueprint would turn an
+// execution started now away with, so callers can reject it before recording it. The
+// policy is still enforced when the execution starts.
+func (e *ExecutionEngine) CheckConcurrency(bp *blueprint.Blueprint) error {
+	policy := bp.GetConcurrencyPolicy()
+	if policy.Mode == blueprint.ConcurrencyAllowParallel {
+		return nil
+	}
+
+	e.concurrencyMutex.Lock()
+	defer e.concurrencyMutex.Unlock()
+
+	slot, exists := e.concurrency[bp.ID]
+	if !exists {
+		return nil
+	}
+	checked := *slot
+	checked.policy = policy
+	return checked.reject(bp, "")
+}
+
+// releaseConcurrency gives the slot of a blueprint to its next queued execution
+func (e *ExecutionEngine) releaseConcurrency(blueprintID, executionID string) {
+	e.concurrencyMutex.Lock()
+	defer e.concurrencyMutex.Unlock()
+
+	slot, exists := e.concurrency[blueprintID]
+	if !exists || slot.running != executionID {
+		return
+	}
+
+	if len(slot.queue) == 0 {
+		delete(e.concurrency, blueprintID)
+		return
+	}
+
+	next := slot.queue[0]
+	slot.queue = slot.queue[1:]
+	slot.running = next.executionID
+	next.admitted <- nil
+}
+
+// CancelQueuedExecution removes an execution from the queue of its blueprint. It reports
+// false if the execution isn't queued.
+func (e *ExecutionEngine) CancelQueuedExecution(executionID string) bool {
+	e.concurrencyMutex.Lock()
+	defer e.concurrencyMutex.Unlock()
+
+	for _, slot := range e.concurrency {
+		for i, waiting := range slot.queue {
+			if waiting.executionID != executionID {
+				continue
+			}
+			slot.queue = append(slot.queue[:i:i], slot.queue[i+1:]...)
+			waiting.admitted <- bperrors.New(
+				bperrors.ErrorTypeExecution,
+				bperrors.ErrExecutionCancelled,
+				"execution was cancelled while queued",
+				bperrors.SeverityMedium,
+			).WithBlueprintInfo("", executionID)
+			return true
+		}
+	}
+	return false
+}
+
+// GetBlueprintConcurrency returns the running and queued executions of a blueprint. Blueprints
+// without a running execution under a limiting concurrency policy have neither.
+func (e *ExecutionEngine) GetBlueprintConcurrency(blueprintID string) BlueprintConcurrency {
+	e.concurrencyMutex.Lock()
+	defer e.concurrencyMutex.Unlock()
+
+	concurrency := BlueprintConcurrency{
+		BlueprintID: blueprintID,
+		Policy:      blueprint.ConcurrencyPolicy{Mode: blueprint.ConcurrencyAllowParallel},
+		Queued:      make([]QueuedExecution, 0),
+	}
+
+	slot, exists := e.concurrency[blueprintID]
+	if !exists {
+		return concurrency
+	}
+
+	concurrency.Policy = slot.policy
+	concurrency.Running = slot.running
+	for i, waiting := range slot.queue {
+		concurrency.Queued = append(concurrency.Queued, QueuedExecution{
+			ExecutionID: waiting.executionID,
+			BlueprintID: blueprintID,
+			QueuedAt:    waiting.queuedAt,
+			Position:    i + 1,
+		})
+	}
+	return concurrency
+}
+
+// blueprintRunningError creates the error of an execution turned away by the concurrency
+// policy of its blueprint
+func blueprintRunningError(bp *blueprint.Blueprint, executionID, running, message string) *bperrors.BlueprintError {
+	return bperrors.New(
+		bperrors.ErrorTypeExecution,
+		bperrors.ErrBlueprintRunning,
+		message,
+		bperrors.SeverityMedium,
+	).WithBlueprintInfo(bp.ID, executionID).WithDetails(map[string]interface{}{
+		"runningExecutionId": running,
+		"policy":             bp.GetConcurrencyPolicy(),
+	})
+}
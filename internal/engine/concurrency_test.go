@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/blueprint"
+)
+
+// concurrencyBlueprint creates a blueprint with a concurrency policy
+func concurrencyBlueprint(mode blueprint.ConcurrencyMode, maxQueued int) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("migration", "Migration", "1.0.0")
+	bp.Concurrency = &blueprint.ConcurrencyPolicy{Mode: mode, MaxQueued: maxQueued}
+	return bp
+}
+
+// acquired is the outcome of an execution waiting for the slot of its blueprint
+type acquired struct {
+	release func()
+	err     error
+}
+
+// acquireAsync waits for the slot of a blueprint in the background, once the execution
+// is queued
+func acquireAsync(t *testing.T, e *ExecutionEngine, bp *blueprint.Blueprint, executionID string) <-chan acquired {
+	t.Helper()
+	result := make(chan acquired, 1)
+	go func() {
+		release, err := e.acquireConcurrency(bp, executionID)
+		result <- acquired{release, err}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !isQueued(e, bp.ID, executionID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s to be queued", executionID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return result
+}
+
+func isQueued(e *ExecutionEngine, blueprintID, executionID string) bool {
+	for _, queued := range e.GetBlueprintConcurrency(blueprintID).Queued {
+		if queued.ExecutionID == executionID {
+			return true
+		}
+	}
+	return false
+}
+
+// admitted waits for a queued execution to get the slot
+func admitted(t *testing.T, result <-chan acquired) acquired {
+	t.Helper()
+	select {
+	case outcome := <-result:
+		return outcome
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the queued execution")
+		return acquired{}
+	}
+}
+
+// expectQueue checks the running execution of the blueprint and the queue behind it
+func expectQueue(t *testing.T, e *ExecutionEngine, running string, queued ...string) {
+	t.Helper()
+	concurrency := e.GetBlueprintConcurrency("migration")
+	if concurrency.Running != running {
+		t.Errorf("expected %q to be running, got %q", running, concurrency.Running)
+	}
+	if len(concurrency.Queued) != len(queued) {
+		t.Fatalf("expected %v to be queued, got %+v", queued, concurrency.Queued)
+	}
+	for i, executionID := range queued {
+		if entry := concurrency.Queued[i]; entry.ExecutionID != executionID || entry.Position != i+1 {
+			t.Errorf("expected %s at position %d, got %+v", executionID, i+1, entry)
+		}
+	}
+}
+
+func isBlueprintRunning(err error, running string) bool {
+	var bpErr *bperrors.BlueprintError
+	return errors.As(err, &bpErr) && bpErr.Code == bperrors.ErrBlueprintRunning && bpErr.Details["runningExecutionId"] == running
+}
+
+func TestConcurrencyAllowParallel(t *testing.T) {
+	e := NewExecutionEngine(discardLogger{}, NewDebugManager())
+	bp := concurrencyBlueprint(blueprint.ConcurrencyAllowParallel, 0)
+
+	for _, executionID := range []string{"exec-1", "exec-2"} {
+		if _, err := e.acquireConcurrency(bp, executionID); err != nil {
+			t.Fatalf("expected %s to run in parallel, got %v", executionID, err)
+		}
+	}
+	expectQueue(t, e, "")
+	if err := e.CheckConcurrency(bp); err != nil {
+		t.Errorf("expected executions to be accepted, got %v", err)
+	}
+}
+
+func TestConcurrencyRejectIfRunning(t *testing.T) {
+	e := NewExecutionEngine(discardLogger{}, NewDebugManager())
+	bp := concurrencyBlueprint(blueprint.ConcurrencyRejectIfRunning, 0)
+
+	release, err := e.acquireConcurrency(bp, "exec-1")
+	if err != nil {
+		t.Fatalf("expected the first execution to run, got %v", err)
+	}
+
+	// The running execution gets the slot again with nothing to release
+	again, err := e.acquireConcurrency(bp, "exec-1")
+	if err != nil {
+		t.Fatalf("expected the running execution to keep the slot, got %v", err)
+	}
+	again()
+	expectQueue(t, e, "exec-1")
+
+	if _, err := e.acquireConcurrency(bp, "exec-2"); !isBlueprintRunning(err, "exec-1") {
+		t.Errorf("expected the second execution to be rejected, got %v", err)
+	}
+	if err := e.CheckConcurrency(bp); !isBlueprintRunning(err, "exec-1") {
+		t.Errorf("expected the check to reject executions, got %v", err)
+	}
+	expectQueue(t, e, "exec-1")
+
+	release()
+	expectQueue(t, e, "")
+	if err := e.CheckConcurrency(bp); err != nil {
+		t.Errorf("expected executions to be accepted once the slot is free, got %v", err)
+	}
+	if _, err := e.acquireConcurrency(bp, "exec-2"); err != nil {
+		t.Errorf("expected the execution to run once the slot is free, got %v", err)
+	}
+}
+
+func TestConcurrencyQueue(t *testing.T) {
+	e := NewExecutionEngine(discardLogger{}, NewDebugManager())
+	bp := concurrencyBlueprint(blueprint.ConcurrencyQueue, 0)
+
+	release, err := e.acquireConcurrency(bp, "exec-1")
+	if err != nil {
+		t.Fatalf("expected the first execution to run, got %v", err)
+	}
+	second := acquireAsync(t, e, bp, "exec-2")
+	third := acquireAsync(t, e, bp, "exec-3")
+	expectQueue(t, e, "exec-1", "exec-2", "exec-3")
+	if status, _ := e.GetExecutionStatus("exec-2"); status.Status != "queued" {
+		t.Errorf("expected exec-2 to be queued, got %q", status.Status)
+	}
+	if err := e.CheckConcurrency(bp); err != nil {
+		t.Errorf("expected an unlimited queue to accept executions, got %v", err)
+	}
+
+	// Executions start in the order they were queued as the running one releases the slot
+	release()
+	outcome := admitted(t, second)
+	if outcome.err != nil {
+		t.Fatalf("expected exec-2 to start, got %v", outcome.err)
+	}
+	expectQueue(t, e, "exec-2", "exec-3")
+
+	outcome.release()
+	outcome = admitted(t, third)
+	if outcome.err != nil {
+		t.Fatalf("expected exec-3 to start, got %v", outcome.err)
+	}
+	expectQueue(t, e, "exec-3")
+
+	outcome.release()
+	expectQueue(t, e, "")
+
+	// Releasing twice doesn't hand the slot on again
+	outcome.release()
+	if _, err := e.acquireConcurrency(bp, "exec-4"); err != nil {
+		t.Errorf("expected the free slot to be taken, got %v", err)
+	}
+}
+
+func TestConcurrencyQueueMaxQueued(t *testing.T) {
+	e := NewExecutionEngine(discardLogger{}, NewDebugManager())
+	bp := concurrencyBlueprint(blueprint.ConcurrencyQueue, 1)
+
+	release, err := e.acquireConcurrency(bp, "exec-1")
+	if err != nil {
+		t.Fatalf("expected the first execution to run, got %v", err)
+	}
+	second := acquireAsync(t, e, bp, "exec-2")
+
+	// The queue is full
+	if _, err := e.acquireConcurrency(bp, "exec-3"); !isBlueprintRunning(err, "exec-1") {
+		t.Errorf("expected the execution beyond the queue to be rejected, got %v", err)
+	}
+	if err := e.CheckConcurrency(bp); !isBlueprintRunning(err, "exec-1") {
+		t.Errorf("expected the check to reject executions beyond the queue, got %v", err)
+	}
+	expectQueue(t, e, "exec-1", "exec-2")
+
+	// Cancelling a queued execution frees its place
+	if e.CancelQueuedExecution("exec-1") {
+		t.Error("expected the running execution not to be cancelled as queued")
+	}
+	if !e.CancelQueuedExecution("exec-2") {
+		t.Fatal("expected the queued execution to be cancelled")
+	}
+	outcome := admitted(t, second)
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(outcome.err, &bpErr) || bpErr.Code != bperrors.ErrExecutionCancelled {
+		t.Errorf("expected exec-2 to be cancelled, got %v", outcome.err)
+	}
+	if status, _ := e.GetExecutionStatus("exec-2"); status.Status != "cancelled" {
+		t.Errorf("expected exec-2 to be recorded as cancelled, got %q", status.Status)
+	}
+	if e.CancelQueuedExecution("exec-2") {
+		t.Error("expected a cancelled execution not to be cancelled again")
+	}
+	expectQueue(t, e, "exec-1")
+
+	third := acquireAsync(t, e, bp, "exec-3")
+	expectQueue(t, e, "exec-1", "exec-3")
+	release()
+	if outcome := admitted(t, third); outcome.err != nil {
+		t.Errorf("expected exec-3 to start, got %v", outcome.err)
+	}
+	expectQueue(t, e, "exec-3")
+}
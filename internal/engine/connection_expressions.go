@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/expr"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// Kinds of connection expressions
+const (
+	expressionTransform = "transform" // Transforms the value of a data connection
+	expressionCondition = "condition" // Decides whether an execution connection is followed
+)
+
+// expressionCache keeps the parsed connection expressions by source, as the standard
+// engine applies them every time a node collects its inputs or activates a flow
+var expressionCache sync.Map
+
+// connectionExpressionError reports an expression of a connection that couldn't be
+// parsed or evaluated
+type connectionExpressionError struct {
+	ConnectionID string
+	PinID        string
+	Kind         string
+	Expression   string
+	Err          error
+}
+
+func (e *connectionExpressionError) Error() string {
+	return fmt.Sprintf("%s %q of connection '%s': %v", e.Kind, e.Expression, e.ConnectionID, e.Err)
+}
+
+func (e *connectionExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// blueprintError converts the error into a blueprint error of a node
+func (e *connectionExpressionError) blueprintError(nodeID, blueprintID, executionID string) *bperrors.BlueprintError {
+	code := bperrors.ErrInvalidTransform
+	if e.Kind == expressionCondition {
+		code = bperrors.ErrInvalidCondition
+	}
+
+	return bperrors.Wrap(e, bperrors.ErrorTypeConnection, code, e.Error(), bperrors.SeverityHigh).
+		WithNodeInfo(nodeID, e.PinID).
+		WithBlueprintInfo(blueprintID, executionID).
+		WithDetails(map[string]interface{}{
+			"connectionId": e.ConnectionID,
+			e.Kind:         e.Expression,
+		})
+}
+
+// parseConnectionExpression parses an expression of a connection, or returns nil if the
+// source is empty
+func parseConnectionExpression(conn blueprint.Connection, kind, source, pinID string) (*expr.Expression, *connectionExpressionError) {
+	if source == "" {
+		return nil, nil
+	}
+
+	if cached, ok := expressionCache.Load(source); ok {
+		return cached.(*expr.Expression), nil
+	}
+
+	expression, err := expr.Parse(source)
+	if err != nil {
+		return nil, &connectionExpressionError{ConnectionID: conn.ID, PinID: pinID, Kind: kind, Expression: source, Err: err}
+	}
+	expressionCache.Store(source, expression)
+	return expression, nil
+}
+
+// parseTransform returns the parsed transform expression of a connection, or nil if the
+// connection doesn't transform its values
+func parseTransform(conn blueprint.Connection) (*expr.Expression, *connectionExpressionError) {
+	return parseConnectionExpression(conn, expressionTransform, conn.GetTransform(), conn.TargetPinID)
+}
+
+// parseCondition returns the parsed condition of a connection, or nil if the connection
+// is always followed
+func parseCondition(conn blueprint.Connection) (*expr.Expression, *connectionExpressionError) {
+	return parseConnectionExpression(conn, expressionCondition, conn.GetCondition(), conn.SourcePinID)
+}
+
+// applyTransform evaluates a transform expression on a value flowing across a connection.
+// Values pass through unchanged if there's no transform.
+func applyTransform(transform *expr.Expression, connectionID, targetPinID string, value types.Value) (types.Value, *connectionExpressionError) {
+	if transform == nil {
+		return value, nil
+	}
+
+	result, err := transform.Eval(value.RawValue)
+	if err != nil {
+		return value, &connectionExpressionError{ConnectionID: connectionID, PinID: targetPinID, Kind: expressionTransform, Expression: transform.String(), Err: err}
+	}
+	return types.NewValue(types.PinTypes.Any, result), nil
+}
+
+// transformInput applies the transform expression of a data connection to its value
+func transformInput(conn blueprint.Connection, value types.Value) (types.Value, *connectionExpressionError) {
+	transform, err := parseTransform(conn)
+	if err != nil {
+		return value, err
+	}
+	return applyTransform(transform, conn.ID, conn.TargetPinID, value)
+}
+
+// checkCondition evaluates the condition of an execution connection on the outputs of
+// its source node. Connections without a condition are always followed.
+func checkCondition(condition *expr.Expression, connectionID, sourcePinID string, outputs map[string]types.Value) (bool, *connectionExpressionError) {
+	if condition == nil {
+		return true, nil
+	}
+
+	values := make(map[string]interface{}, len(outputs))
+	for pinID, value := range outputs {
+		values[pinID] = value.RawValue
+	}
+
+	result, err := condition.Eval(values)
+	if err != nil {
+		return false, &connectionExpressionError{ConnectionID: connectionID, PinID: sourcePinID, Kind: expressionCondition, Expression: condition.String(), Err: err}
+	}
+	return expr.Truthy(result), nil
+}
+
+// conditionHolds checks the condition of an execution connection on the outputs of its
+// source node
+func conditionHolds(conn blueprint.Connection, outputs map[string]types.Value) (bool, *connectionExpressionError) {
+	condition, err := parseCondition(conn)
+	if err != nil {
+		return false, err
+	}
+	return checkCondition(condition, conn.ID, conn.SourcePinID, outputs)
+}
@@ -0,0 +1,193 @@
+package engine_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/event"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// nopLogger discards the log of the engine, from any goroutine
+type nopLogger struct{}
+
+func (nopLogger) Opts(map[string]interface{})          {}
+func (nopLogger) Debug(string, map[string]interface{}) {}
+func (nopLogger) Info(string, map[string]interface{})  {}
+func (nopLogger) Warn(string, map[string]interface{})  {}
+func (nopLogger) Error(string, map[string]interface{}) {}
+
+// newEngine creates an engine with the core node types, like the embedded runner
+func newEngine(t testing.TB, mode engine.ExecutionMode) *engine.ExecutionEngine {
+	t.Helper()
+	return newEngineWithLogger(t, mode, nopLogger{})
+}
+
+// newEngineWithLogger creates an engine with the core node types that logs to the given logger
+func newEngineWithLogger(t testing.TB, mode engine.ExecutionMode, logger node.Logger) *engine.ExecutionEngine {
+	t.Helper()
+	registry.Make()
+	for typeID, factory := range nodes.Core {
+		registry.GetInstance().RegisterNodeType(typeID, factory)
+	}
+
+	errorManager := bperrors.NewErrorManager()
+	recoveryManager := bperrors.NewRecoveryManager(errorManager)
+	flowEngine := engine.NewExecutionEngine(logger, engine.NewDebugManager())
+	flowEngine.SetExecutionMode(mode)
+	eventManager := event.NewEventManager(flowEngine)
+	contextManager := engineext.NewContextManager(errorManager, recoveryManager, eventManager.AsEventManagerInterface(), nil)
+	flowEngine.SetExtensions(engineext.InitializeExtensions(flowEngine, contextManager, errorManager, recoveryManager, eventManager))
+	for typeID, factory := range nodes.Core {
+		flowEngine.RegisterNodeType(typeID, factory)
+	}
+	return flowEngine
+}
+
+// chainBlueprint starts with an event node running a chain of print nodes
+func chainBlueprint(length int) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("contention", "Contention", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	previous := "start"
+	for i := 0; i < length; i++ {
+		nodeID := fmt.Sprintf("print-%d", i)
+		bp.AddNode(blueprint.BlueprintNode{ID: nodeID, Type: "print"})
+		bp.AddConnection(blueprint.Connection{
+			ID:             "exec-" + nodeID,
+			SourceNodeID:   previous,
+			SourcePinID:    "then",
+			TargetNodeID:   nodeID,
+			TargetPinID:    "execute",
+			ConnectionType: "execution",
+		})
+		previous = nodeID
+	}
+	return bp
+}
+
+func TestConcurrentExecutions(t *testing.T) {
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			flowEngine := newEngine(t, mode)
+			bp := chainBlueprint(5)
+
+			const executions = 16
+			var wg sync.WaitGroup
+			done := make(chan struct{})
+			var reads atomic.Int64
+
+			// The hooks record the completed nodes of every execution, and are replaced while
+			// the executions read them
+			var recordedMutex sync.Mutex
+			recorded := make(map[string]int)
+			hooks := engine.Hooks{OnNodeExecution: func(_ context.Context, executionID, _, _, execState string, _, _ map[string]interface{}) error {
+				if execState == "completed" {
+					recordedMutex.Lock()
+					recorded[executionID]++
+					recordedMutex.Unlock()
+				}
+				return nil
+			}}
+			flowEngine.SetHooks(hooks)
+
+			// Readers poll the statuses and load while the executions update them
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func(reader int) {
+					defer wg.Done()
+					for {
+						select {
+						case <-done:
+							return
+						default:
+						}
+						flowEngine.GetExecutionStatus(fmt.Sprintf("exec-%d", reader))
+						flowEngine.GetNodeStatus(fmt.Sprintf("exec-%d", reader), "print-0")
+						flowEngine.GetLoadSignals()
+						flowEngine.GetNodeFactory("print")
+						flowEngine.SetHooks(hooks)
+						reads.Add(1)
+					}
+				}(i)
+			}
+
+			errs := make(chan error, executions)
+			var runs sync.WaitGroup
+			for i := 0; i < executions; i++ {
+				runs.Add(1)
+				go func(executionID string) {
+					defer runs.Done()
+					result, err := flowEngine.Execute(bp, executionID, map[string]types.Value{})
+					if err == nil && !result.Success {
+						err = fmt.Errorf("execution %s failed: %v", executionID, result.Error)
+					}
+					if err != nil {
+						errs <- err
+					}
+				}(fmt.Sprintf("exec-%d", i))
+			}
+			runs.Wait()
+			close(done)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Error(err)
+			}
+			if reads.Load() == 0 {
+				t.Error("expected the readers to poll the engine")
+			}
+
+			// Every execution records the statuses of its own nodes only
+			for i := 0; i < executions; i++ {
+				status, exists := flowEngine.GetExecutionStatus(fmt.Sprintf("exec-%d", i))
+				if !exists {
+					t.Fatalf("expected a status for exec-%d", i)
+				}
+				if status.Status != "completed" {
+					t.Errorf("exec-%d: expected completed, got %s", i, status.Status)
+				}
+				if len(status.NodeStatuses) != 6 {
+					t.Errorf("exec-%d: expected 6 node statuses, got %d", i, len(status.NodeStatuses))
+				}
+				recordedMutex.Lock()
+				if completed := recorded[fmt.Sprintf("exec-%d", i)]; completed != 6 {
+					t.Errorf("exec-%d: expected the hooks to record 6 completed nodes, got %d", i, completed)
+				}
+				recordedMutex.Unlock()
+				for nodeID, nodeStatus := range status.NodeStatuses {
+					if nodeStatus.Status != "completed" {
+						t.Errorf("exec-%d: expected node %s to be completed, got %s", i, nodeID, nodeStatus.Status)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkConcurrentExecutions(b *testing.B) {
+	flowEngine := newEngine(b, engine.ModeStandard)
+	bp := chainBlueprint(10)
+	var next atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			executionID := fmt.Sprintf("bench-%d", next.Add(1))
+			if _, err := flowEngine.Execute(bp, executionID, map[string]types.Value{}); err != nil {
+				b.Error(err)
+			}
+			flowEngine.GetExecutionStatus(executionID)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"fmt"
+	"runtime/debug"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/crash"
+	"webblueprint/pkg/models"
+)
+
+// recentCrashEvents is the number of the latest node executions a crash report holds
+const recentCrashEvents = 20
+
+// reportPanic reports a panic recovered during an execution, with the version of its
+// blueprint, the type of the node and the latest node executions before it
+func (e *ExecutionEngine) reportPanic(source string, err *bperrors.BlueprintError) {
+	report := &models.CrashReport{
+		Source:      source,
+		ExecutionID: err.ExecutionID,
+		BlueprintID: err.BlueprintID,
+		NodeID:      err.NodeID,
+		Message:     err.Message,
+	}
+	if stack, ok := err.Details["stack"].(string); ok {
+		report.Stack = stack
+	}
+
+	if bp, key, exists := e.resolveBlueprint(err.BlueprintID, err.ExecutionID); exists {
+		report.BlueprintVersion = key.version
+		if nodeConfig := bp.FindNode(err.NodeID); nodeConfig != nil {
+			report.NodeType = nodeConfig.Type
+		}
+	}
+
+	if e.debugManager != nil {
+		report.Events = recentEvents(e.debugManager, err.ExecutionID)
+	}
+
+	crash.Default().Report(report)
+}
+
+// recentEvents returns the latest node executions of an execution as crash events
+func recentEvents(debugManager *DebugManager, executionID string) []models.CrashEvent {
+	timeline, exists := debugManager.GetExecutionTimeline(executionID)
+	if !exists {
+		return nil
+	}
+
+	spans := timeline.Spans
+	if len(spans) > recentCrashEvents {
+		spans = spans[len(spans)-recentCrashEvents:]
+	}
+	events := make([]models.CrashEvent, 0, len(spans))
+	for _, span := range spans {
+		events = append(events, models.CrashEvent{
+			Type:       "node.execution",
+			NodeID:     span.NodeID,
+			NodeType:   span.NodeType,
+			Status:     span.Status,
+			Error:      span.Error,
+			DurationMs: span.DurationMs,
+			Timestamp:  span.StartTime,
+		})
+	}
+	return events
+}
+
+// executionPanicked creates the error of an execution the engine panicked in, outside of
+// its nodes
+func executionPanicked(recovered interface{}, blueprintID, executionID string) *bperrors.BlueprintError {
+	return bperrors.New(
+		bperrors.ErrorTypeExecution,
+		bperrors.ErrExecutionPanicked,
+		fmt.Sprintf("execution panicked: %v", recovered),
+		bperrors.SeverityCritical,
+	).WithBlueprintInfo(blueprintID, executionID).WithDetails(map[string]interface{}{
+		"stack": string(debug.Stack()),
+	})
+}
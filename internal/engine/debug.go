@@ -1,9 +1,11 @@
 package engine
 
 import (
+	"container/list"
 	"sync"
 	"time"
 	"webblueprint/internal/node"
+	"webblueprint/internal/types"
 )
 
 // DebugManager stores and manages debug information during execution
@@ -17,6 +19,37 @@ type DebugManager struct {
 	// Maps: executionID -> data
 	executionData map[string]map[string]interface{}
 
+	// Maps: executionID -> streams produced by the execution's nodes
+	streams map[string][]*types.Stream
+
+	// Maps: executionID -> node execution timeline
+	timelines map[string]*executionTimeline
+
+	// Maps: executionID -> values that flowed across data connections
+	dataFlows map[string]*executionDataFlow
+
+	// Maps: executionID -> variable name -> value, for watch expressions
+	variables map[string]map[string]interface{}
+
+	// Maps: executionID -> ordered variable changes and node boundaries
+	variableHistories map[string]*variableHistory
+
+	// Maps: executionID -> watchID -> watch expression state
+	watches       map[string]map[string]*watchState
+	watchListener WatchListener
+	watchSequence int64
+
+	// Maps: nodeID -> owners that debug the node's values in full fidelity
+	fullFidelity map[string]map[string]bool
+
+	// Retention state, ordered from most to least recently used
+	policy       DebugRetentionPolicy
+	lru          *list.List
+	entries      map[string]*list.Element
+	evictedCount int64
+	spill        DebugSpillFunc
+	sweptAt      time.Time // When expired executions were last evicted
+
 	mutex sync.RWMutex
 
 	logger node.Logger
@@ -25,16 +58,32 @@ type DebugManager struct {
 // NewDebugManager creates a new debug manager
 func NewDebugManager() *DebugManager {
 	return &DebugManager{
-		debugData:     make(map[string]map[string]map[string]interface{}),
-		outputValues:  make(map[string]map[string]map[string]interface{}),
-		executionData: make(map[string]map[string]interface{}),
+		debugData:         make(map[string]map[string]map[string]interface{}),
+		outputValues:      make(map[string]map[string]map[string]interface{}),
+		executionData:     make(map[string]map[string]interface{}),
+		streams:           make(map[string][]*types.Stream),
+		timelines:         make(map[string]*executionTimeline),
+		dataFlows:         make(map[string]*executionDataFlow),
+		variables:         make(map[string]map[string]interface{}),
+		variableHistories: make(map[string]*variableHistory),
+		watches:           make(map[string]map[string]*watchState),
+		fullFidelity:      make(map[string]map[string]bool),
+		policy:            DefaultDebugRetentionPolicy(),
+		lru:               list.New(),
+		entries:           make(map[string]*list.Element),
 	}
 }
 
 // StoreNodeDebugData stores debug data for a node
 func (dm *DebugManager) StoreNodeDebugData(executionID, nodeID string, data map[string]interface{}) {
+	// Values are prepared and sized before taking the lock
+	values := make(map[string]sizedDebugValue, len(data))
+	for key, value := range data {
+		debugValue := types.DebugValue(value)
+		values[key] = sizedDebugValue{value: debugValue, size: estimateDebugSize(debugValue)}
+	}
+
 	dm.mutex.Lock()
-	defer dm.mutex.Unlock()
 
 	// Initialize maps if needed
 	if _, exists := dm.debugData[executionID]; !exists {
@@ -45,19 +94,32 @@ func (dm *DebugManager) StoreNodeDebugData(executionID, nodeID string, data map[
 		dm.debugData[executionID][nodeID] = make(map[string]interface{})
 	}
 
-	// Store data with timestamp
-	for key, value := range data {
+	// Store data with timestamp, dropping data over the per-execution limit
+	for key, value := range values {
+		if !dm.reserveLocked(executionID, value.size) {
+			continue
+		}
 		dm.debugData[executionID][nodeID][key] = map[string]interface{}{
-			"value":     value,
+			"value":     value.value,
 			"timestamp": time.Now(),
 		}
 	}
+	due := dm.retentionDueLocked(time.Now())
+	dm.mutex.Unlock()
+
+	if due {
+		dm.enforceRetention()
+	}
 }
 
 // StoreExecutionDebugData stores debug data for an entire execution
 func (dm *DebugManager) StoreExecutionDebugData(executionID string, data map[string]interface{}) {
+	sizes := make(map[string]int64, len(data))
+	for key, value := range data {
+		sizes[key] = estimateDebugSize(value)
+	}
+
 	dm.mutex.Lock()
-	defer dm.mutex.Unlock()
 
 	// Initialize map if needed
 	if _, exists := dm.executionData[executionID]; !exists {
@@ -66,17 +128,25 @@ func (dm *DebugManager) StoreExecutionDebugData(executionID string, data map[str
 
 	// Store data with timestamp
 	for key, value := range data {
+		if !dm.reserveLocked(executionID, sizes[key]) {
+			continue
+		}
 		dm.executionData[executionID][key] = map[string]interface{}{
 			"value":     value,
 			"timestamp": time.Now(),
 		}
 	}
+	due := dm.retentionDueLocked(time.Now())
+	dm.mutex.Unlock()
+
+	if due {
+		dm.enforceRetention()
+	}
 }
 
 // StoreNodeOutputValue stores an output value for a node
 func (dm *DebugManager) StoreNodeOutputValue(executionID, nodeID, pinID string, value interface{}) {
 	dm.mutex.Lock()
-	defer dm.mutex.Unlock()
 
 	// Initialize maps if needed
 	if _, exists := dm.outputValues[executionID]; !exists {
@@ -87,8 +157,38 @@ func (dm *DebugManager) StoreNodeOutputValue(executionID, nodeID, pinID string,
 		dm.outputValues[executionID][nodeID] = make(map[string]interface{})
 	}
 
-	// Store the value
+	// Store the value; output values feed downstream nodes and are never dropped
 	dm.outputValues[executionID][nodeID][pinID] = value
+	dm.touchLocked(executionID)
+
+	// Track streams so they can be closed when the execution finishes
+	if stream, ok := value.(*types.Stream); ok {
+		dm.streams[executionID] = append(dm.streams[executionID], stream)
+	}
+
+	updates := dm.collectWatchUpdatesLocked(executionID, func(w *WatchExpression) bool {
+		return w.NodeID == nodeID && w.PinID == pinID
+	})
+	dm.mutex.Unlock()
+
+	dm.notifyWatches(updates)
+}
+
+// CloseStreams closes all streams produced during an execution
+func (dm *DebugManager) CloseStreams(executionID string) {
+	dm.mutex.Lock()
+	streams := dm.streams[executionID]
+	delete(dm.streams, executionID)
+	dm.mutex.Unlock()
+
+	for _, stream := range streams {
+		if err := stream.Close(); err != nil && dm.logger != nil {
+			dm.logger.Warn("Failed to close stream", map[string]interface{}{
+				"executionId": executionID,
+				"error":       err.Error(),
+			})
+		}
+	}
 }
 
 // ClearCachedPinTypes clears any cached type information for a pin
@@ -121,6 +221,8 @@ func (dm *DebugManager) GetNodeDebugData(executionID, nodeID string) (map[string
 
 // GetExecutionDebugData retrieves debug data for an execution
 func (dm *DebugManager) GetExecutionDebugData(executionID string) map[string]interface{} {
+	defer dm.touch(executionID)
+
 	dm.mutex.RLock()
 	defer dm.mutex.RUnlock()
 
@@ -130,6 +232,9 @@ func (dm *DebugManager) GetExecutionDebugData(executionID string) map[string]int
 		for key, value := range execData {
 			result[key] = value
 		}
+		if element, exists := dm.entries[executionID]; exists && element.Value.(*debugEntry).truncated {
+			result["debugTruncated"] = true
+		}
 		return result
 	}
 
@@ -192,6 +297,8 @@ func (dm *DebugManager) GetAllNodeOutputValues(executionID, nodeID string) (map[
 
 // GetAllNodeDebugData retrieves all debug data for all nodes in an execution
 func (dm *DebugManager) GetAllNodeDebugData(executionID string) map[string]map[string]interface{} {
+	defer dm.touch(executionID)
+
 	dm.mutex.RLock()
 	defer dm.mutex.RUnlock()
 
@@ -232,12 +339,12 @@ func (dm *DebugManager) GetExecutionOutputValues(executionID string) map[string]
 
 // ClearExecutionData removes all data for an execution
 func (dm *DebugManager) ClearExecutionData(executionID string) {
+	dm.CloseStreams(executionID)
+
 	dm.mutex.Lock()
 	defer dm.mutex.Unlock()
 
-	delete(dm.debugData, executionID)
-	delete(dm.outputValues, executionID)
-	delete(dm.executionData, executionID)
+	dm.removeLocked(executionID)
 }
 
 // ClearAllData removes all debug data
@@ -248,4 +355,11 @@ func (dm *DebugManager) ClearAllData() {
 	dm.debugData = make(map[string]map[string]map[string]interface{})
 	dm.outputValues = make(map[string]map[string]map[string]interface{})
 	dm.executionData = make(map[string]map[string]interface{})
+	dm.timelines = make(map[string]*executionTimeline)
+	dm.dataFlows = make(map[string]*executionDataFlow)
+	dm.variables = make(map[string]map[string]interface{})
+	dm.variableHistories = make(map[string]*variableHistory)
+	dm.watches = make(map[string]map[string]*watchState)
+	dm.lru = list.New()
+	dm.entries = make(map[string]*list.Element)
 }
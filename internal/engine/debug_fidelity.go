@@ -0,0 +1,46 @@
+package engine
+
+// SetFullFidelity makes listeners that batch values deliver every value produced by a node
+// while at least one owner, e.g. a WebSocket client debugging the node, asks for it
+func (dm *DebugManager) SetFullFidelity(owner, nodeID string, full bool) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	if !full {
+		delete(dm.fullFidelity[nodeID], owner)
+		if len(dm.fullFidelity[nodeID]) == 0 {
+			delete(dm.fullFidelity, nodeID)
+		}
+		return
+	}
+
+	if _, exists := dm.fullFidelity[nodeID]; !exists {
+		dm.fullFidelity[nodeID] = make(map[string]bool)
+	}
+	dm.fullFidelity[nodeID][owner] = true
+}
+
+// HasFullFidelity reports whether every value produced by a node is to be delivered
+func (dm *DebugManager) HasFullFidelity(nodeID string) bool {
+	dm.mutex.RLock()
+	defer dm.mutex.RUnlock()
+	return len(dm.fullFidelity[nodeID]) > 0
+}
+
+// RemoveFullFidelityByOwner drops the full fidelity requests of a client
+func (dm *DebugManager) RemoveFullFidelityByOwner(owner string) int {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	removed := 0
+	for nodeID, owners := range dm.fullFidelity {
+		if owners[owner] {
+			delete(owners, owner)
+			removed++
+		}
+		if len(owners) == 0 {
+			delete(dm.fullFidelity, nodeID)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,338 @@
+package engine
+
+import (
+	"encoding/json"
+	"time"
+	"webblueprint/internal/types"
+)
+
+// DebugRetentionPolicy bounds the memory used by the debug manager; zero values disable a limit
+type DebugRetentionPolicy struct {
+	MaxExecutions        int           `json:"maxExecutions"`        // Executions retained before the least recently used is evicted
+	MaxBytesPerExecution int64         `json:"maxBytesPerExecution"` // Debug data kept per execution, further data is dropped
+	TTL                  time.Duration `json:"ttl"`                  // Time after the last use before an execution is evicted
+	SpillToDB            bool          `json:"spillToDb"`            // Persist debug data of evicted executions
+}
+
+// DefaultDebugRetentionPolicy returns the retention policy used by new debug managers
+func DefaultDebugRetentionPolicy() DebugRetentionPolicy {
+	return DebugRetentionPolicy{
+		MaxExecutions:        100,
+		MaxBytesPerExecution: 8 << 20,
+		TTL:                  time.Hour,
+	}
+}
+
+// DebugSpillFunc persists the node debug data of an evicted execution
+type DebugSpillFunc func(executionID string, nodeData map[string]map[string]interface{}) error
+
+// DebugStats describes the current memory use of the debug manager
+type DebugStats struct {
+	Executions       int                  `json:"executions"`
+	ActiveExecutions int                  `json:"activeExecutions"`
+	Bytes            int64                `json:"bytes"`
+	Truncated        int                  `json:"truncated"`
+	Evicted          int64                `json:"evicted"`
+	Policy           DebugRetentionPolicy `json:"policy"`
+}
+
+// debugEntry tracks the retention state of an execution
+type debugEntry struct {
+	executionID string
+	lastUsed    time.Time
+	bytes       int64
+	truncated   bool
+	active      bool
+}
+
+// evictedExecution holds the debug data of an evicted execution until it's spilled
+type evictedExecution struct {
+	executionID string
+	nodeData    map[string]map[string]interface{}
+	streams     []*types.Stream
+}
+
+// SetRetentionPolicy replaces the retention policy and applies it immediately
+func (dm *DebugManager) SetRetentionPolicy(policy DebugRetentionPolicy) {
+	dm.mutex.Lock()
+	dm.policy = policy
+	dm.mutex.Unlock()
+
+	dm.enforceRetention()
+}
+
+// RetentionPolicy returns the current retention policy
+func (dm *DebugManager) RetentionPolicy() DebugRetentionPolicy {
+	dm.mutex.RLock()
+	defer dm.mutex.RUnlock()
+	return dm.policy
+}
+
+// SetSpillFunc sets the function evicted debug data is persisted with
+func (dm *DebugManager) SetSpillFunc(spill DebugSpillFunc) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	dm.spill = spill
+}
+
+// BeginExecution marks an execution as running; running executions are never evicted
+func (dm *DebugManager) BeginExecution(executionID string) {
+	dm.mutex.Lock()
+	dm.touchLocked(executionID).active = true
+	dm.mutex.Unlock()
+
+	dm.enforceRetention()
+}
+
+// EndExecution marks an execution as finished and closes its streams
+func (dm *DebugManager) EndExecution(executionID string) {
+	dm.CloseStreams(executionID)
+
+	dm.mutex.Lock()
+	if element, exists := dm.entries[executionID]; exists {
+		entry := element.Value.(*debugEntry)
+		entry.active = false
+		entry.lastUsed = time.Now()
+		dm.lru.MoveToFront(element)
+	}
+	dm.mutex.Unlock()
+
+	dm.enforceRetention()
+}
+
+// Purge removes all data of an execution without spilling it
+func (dm *DebugManager) Purge(executionID string) bool {
+	dm.mutex.Lock()
+	_, exists := dm.entries[executionID]
+	evicted := dm.removeLocked(executionID)
+	dm.mutex.Unlock()
+
+	closeEvictedStreams(evicted)
+	return exists
+}
+
+// PurgeAll removes the data of all finished executions without spilling it
+func (dm *DebugManager) PurgeAll() int {
+	dm.mutex.Lock()
+	purged := make([]evictedExecution, 0)
+	for executionID, element := range dm.entries {
+		if element.Value.(*debugEntry).active {
+			continue
+		}
+		purged = append(purged, dm.removeLocked(executionID))
+	}
+	dm.mutex.Unlock()
+
+	for _, evicted := range purged {
+		closeEvictedStreams(evicted)
+	}
+	return len(purged)
+}
+
+// Stats returns the current memory use of the debug manager
+func (dm *DebugManager) Stats() DebugStats {
+	dm.mutex.RLock()
+	defer dm.mutex.RUnlock()
+
+	stats := DebugStats{
+		Executions: len(dm.entries),
+		Evicted:    dm.evictedCount,
+		Policy:     dm.policy,
+	}
+	for _, element := range dm.entries {
+		entry := element.Value.(*debugEntry)
+		stats.Bytes += entry.bytes
+		if entry.active {
+			stats.ActiveExecutions++
+		}
+		if entry.truncated {
+			stats.Truncated++
+		}
+	}
+	return stats
+}
+
+// touchLocked marks an execution as recently used and returns its entry. Requires the write lock.
+func (dm *DebugManager) touchLocked(executionID string) *debugEntry {
+	if element, exists := dm.entries[executionID]; exists {
+		entry := element.Value.(*debugEntry)
+		entry.lastUsed = time.Now()
+		dm.lru.MoveToFront(element)
+		return entry
+	}
+
+	entry := &debugEntry{executionID: executionID, lastUsed: time.Now()}
+	dm.entries[executionID] = dm.lru.PushFront(entry)
+	return entry
+}
+
+// touch marks an execution as recently used if it's retained
+func (dm *DebugManager) touch(executionID string) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	if _, exists := dm.entries[executionID]; exists {
+		dm.touchLocked(executionID)
+	}
+}
+
+// reserveLocked accounts debug data of the estimated size to an execution. It returns
+// false if the data would exceed the per-execution limit. Requires the write lock.
+func (dm *DebugManager) reserveLocked(executionID string, size int64) bool {
+	entry := dm.touchLocked(executionID)
+	if dm.policy.MaxBytesPerExecution <= 0 {
+		return true
+	}
+
+	if entry.bytes+size > dm.policy.MaxBytesPerExecution {
+		entry.truncated = true
+		return false
+	}
+	entry.bytes += size
+	return true
+}
+
+// retentionSweepInterval is how often storing debug data evicts expired executions, at
+// most the TTL
+const retentionSweepInterval = time.Second
+
+// retentionDueLocked reports whether storing debug data needs to enforce the retention
+// policy: when there are more executions than retained, or the expired ones are due to be
+// evicted. Requires the lock.
+func (dm *DebugManager) retentionDueLocked(now time.Time) bool {
+	if dm.policy.MaxExecutions > 0 && len(dm.entries) > dm.policy.MaxExecutions {
+		return true
+	}
+	if dm.policy.TTL <= 0 {
+		return false
+	}
+	return now.Sub(dm.sweptAt) >= min(dm.policy.TTL, retentionSweepInterval)
+}
+
+// enforceRetention evicts expired and least recently used executions
+func (dm *DebugManager) enforceRetention() {
+	dm.mutex.Lock()
+	evicted := make([]evictedExecution, 0)
+	now := time.Now()
+	dm.sweptAt = now
+
+	// The list is ordered by last use, so expired executions are at the back
+	if dm.policy.TTL > 0 {
+		for element := dm.lru.Back(); element != nil; {
+			previous := element.Prev()
+			entry := element.Value.(*debugEntry)
+			if now.Sub(entry.lastUsed) < dm.policy.TTL {
+				break
+			}
+			if !entry.active {
+				evicted = append(evicted, dm.removeLocked(entry.executionID))
+			}
+			element = previous
+		}
+	}
+
+	if dm.policy.MaxExecutions > 0 {
+		for element := dm.lru.Back(); element != nil && len(dm.entries) > dm.policy.MaxExecutions; {
+			previous := element.Prev()
+			entry := element.Value.(*debugEntry)
+			if !entry.active {
+				evicted = append(evicted, dm.removeLocked(entry.executionID))
+			}
+			element = previous
+		}
+	}
+
+	dm.evictedCount += int64(len(evicted))
+	spill := dm.spill
+	spillToDB := dm.policy.SpillToDB
+	dm.mutex.Unlock()
+
+	for _, execution := range evicted {
+		closeEvictedStreams(execution)
+
+		// Spill in the background so node execution isn't blocked by the database
+		if spillToDB && spill != nil && len(execution.nodeData) > 0 {
+			go func(execution evictedExecution) {
+				if err := spill(execution.executionID, execution.nodeData); err != nil && dm.logger != nil {
+					dm.logger.Warn("Failed to spill debug data", map[string]interface{}{
+						"executionId": execution.executionID,
+						"error":       err.Error(),
+					})
+				}
+			}(execution)
+		}
+	}
+}
+
+// removeLocked deletes all data of an execution and returns it. Requires the write lock.
+func (dm *DebugManager) removeLocked(executionID string) evictedExecution {
+	evicted := evictedExecution{
+		executionID: executionID,
+		nodeData:    dm.debugData[executionID],
+		streams:     dm.streams[executionID],
+	}
+
+	if element, exists := dm.entries[executionID]; exists {
+		dm.lru.Remove(element)
+		delete(dm.entries, executionID)
+	}
+	delete(dm.debugData, executionID)
+	delete(dm.outputValues, executionID)
+	delete(dm.executionData, executionID)
+	delete(dm.streams, executionID)
+	delete(dm.timelines, executionID)
+	delete(dm.dataFlows, executionID)
+	delete(dm.variables, executionID)
+	delete(dm.variableHistories, executionID)
+	delete(dm.watches, executionID)
+
+	return evicted
+}
+
+// closeEvictedStreams closes the streams of a removed execution
+func closeEvictedStreams(evicted evictedExecution) {
+	for _, stream := range evicted.streams {
+		_ = stream.Close()
+	}
+}
+
+// sizedDebugValue is a debug value with its estimated size
+type sizedDebugValue struct {
+	value interface{}
+	size  int64
+}
+
+// estimateDebugSize approximates the memory used by a debug value by its JSON size. The
+// values nodes produce are walked without encoding them; other values are encoded.
+func estimateDebugSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 4
+	case string:
+		return int64(len(v)) + 2
+	case bool:
+		return 5
+	case float64, float32, int, int32, int64:
+		return 8
+	case time.Time:
+		return 32
+	case map[string]interface{}:
+		size := int64(2)
+		for key, item := range v {
+			size += int64(len(key)) + 4 + estimateDebugSize(item)
+		}
+		return size
+	case []interface{}:
+		size := int64(2)
+		for _, item := range v {
+			size += estimateDebugSize(item) + 1
+		}
+		return size
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
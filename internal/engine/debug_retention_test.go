@@ -0,0 +1,51 @@
+package engine_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+)
+
+func TestDebugDataOverTheLimitIsDropped(t *testing.T) {
+	dm := engine.NewDebugManager()
+	dm.SetRetentionPolicy(engine.DebugRetentionPolicy{MaxBytesPerExecution: 1024})
+
+	dm.StoreNodeDebugData("exec", "small", map[string]interface{}{
+		"value": map[string]interface{}{"items": []interface{}{"a", 1.5, true, nil}},
+	})
+	dm.StoreNodeDebugData("exec", "large", map[string]interface{}{"value": strings.Repeat("x", 2048)})
+
+	if _, exists := dm.GetNodeDebugData("exec", "small"); !exists {
+		t.Error("expected the debug data within the limit to be kept")
+	}
+	if data, _ := dm.GetNodeDebugData("exec", "large"); len(data) != 0 {
+		t.Errorf("expected the debug data over the limit to be dropped, got %v", data)
+	}
+	if stats := dm.Stats(); stats.Truncated != 1 || stats.Bytes == 0 || stats.Bytes > 1024 {
+		t.Errorf("expected one truncated execution within the limit, got %+v", stats)
+	}
+}
+
+func TestStoringDebugDataEvictsExecutions(t *testing.T) {
+	dm := engine.NewDebugManager()
+	dm.SetRetentionPolicy(engine.DebugRetentionPolicy{MaxExecutions: 2})
+
+	for i := 0; i < 4; i++ {
+		dm.StoreNodeDebugData(fmt.Sprintf("exec-%d", i), "node", map[string]interface{}{"value": i})
+	}
+	if stats := dm.Stats(); stats.Executions != 2 || stats.Evicted != 2 {
+		t.Errorf("expected the least recently used executions to be evicted, got %+v", stats)
+	}
+	if _, exists := dm.GetNodeDebugData("exec-3", "node"); !exists {
+		t.Error("expected the latest execution to be kept")
+	}
+
+	dm.SetRetentionPolicy(engine.DebugRetentionPolicy{TTL: time.Millisecond})
+	time.Sleep(5 * time.Millisecond)
+	dm.StoreExecutionDebugData("exec-4", map[string]interface{}{"value": "v"})
+	if stats := dm.Stats(); stats.Executions != 1 {
+		t.Errorf("expected the expired executions to be evicted, got %+v", stats)
+	}
+}
@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"webblueprint/internal/types"
+)
+
+// WatchExpression is a value of an execution a client is watching. The expression
+// is either a variable name or a node output in the form node.pin, optionally
+// followed by a path into the value (node.pin.field.0).
+type WatchExpression struct {
+	ID          string   `json:"id"`
+	ExecutionID string   `json:"executionId"`
+	Expression  string   `json:"expression"`
+	Owner       string   `json:"owner,omitempty"` // Client that registered the watch
+	Variable    string   `json:"variable,omitempty"`
+	NodeID      string   `json:"nodeId,omitempty"`
+	PinID       string   `json:"pinId,omitempty"`
+	Path        []string `json:"path,omitempty"`
+}
+
+// WatchUpdate carries the new value of a watch expression
+type WatchUpdate struct {
+	WatchID     string      `json:"watchId"`
+	ExecutionID string      `json:"executionId"`
+	Expression  string      `json:"expression"`
+	Owner       string      `json:"-"`
+	Value       interface{} `json:"value"`
+	Available   bool        `json:"available"` // False if the watched value doesn't exist (anymore)
+	Timestamp   time.Time   `json:"timestamp"`
+}
+
+// WatchListener receives the updates of all watch expressions
+type WatchListener func(update WatchUpdate)
+
+// watchState tracks the last value pushed for a watch expression
+type watchState struct {
+	expression WatchExpression
+	last       string // JSON encoding of the last pushed value
+	available  bool
+}
+
+// ParseWatchExpression parses a variable name or node.pin path
+func ParseWatchExpression(expression string) (WatchExpression, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return WatchExpression{}, fmt.Errorf("watch expression is empty")
+	}
+
+	parts := strings.Split(expression, ".")
+	for _, part := range parts {
+		if part == "" {
+			return WatchExpression{}, fmt.Errorf("invalid watch expression: %s", expression)
+		}
+	}
+
+	watch := WatchExpression{Expression: expression}
+	if len(parts) == 1 {
+		watch.Variable = parts[0]
+		return watch, nil
+	}
+
+	watch.NodeID = parts[0]
+	watch.PinID = parts[1]
+	watch.Path = parts[2:]
+	return watch, nil
+}
+
+// SetWatchListener sets the function watch updates are pushed to
+func (dm *DebugManager) SetWatchListener(listener WatchListener) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	dm.watchListener = listener
+}
+
+// AddWatch registers a watch expression for an execution. Changes are pushed to the
+// watch listener as they happen; PushWatch pushes the current value.
+func (dm *DebugManager) AddWatch(executionID, owner, expression string) (WatchExpression, error) {
+	watch, err := ParseWatchExpression(expression)
+	if err != nil {
+		return watch, err
+	}
+
+	dm.mutex.Lock()
+	dm.watchSequence++
+	watch.ID = fmt.Sprintf("watch-%d", dm.watchSequence)
+	watch.ExecutionID = executionID
+	watch.Owner = owner
+
+	dm.touchLocked(executionID)
+	if _, exists := dm.watches[executionID]; !exists {
+		dm.watches[executionID] = make(map[string]*watchState)
+	}
+	dm.watches[executionID][watch.ID] = &watchState{expression: watch}
+	dm.mutex.Unlock()
+
+	return watch, nil
+}
+
+// PushWatch pushes the current value of a watch expression if it's known
+func (dm *DebugManager) PushWatch(executionID, watchID string) {
+	dm.mutex.Lock()
+	updates := dm.collectWatchUpdatesLocked(executionID, func(w *WatchExpression) bool {
+		return w.ID == watchID
+	})
+	dm.mutex.Unlock()
+
+	dm.notifyWatches(updates)
+}
+
+// RemoveWatch removes a watch expression of an execution
+func (dm *DebugManager) RemoveWatch(executionID, watchID string) bool {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	if _, exists := dm.watches[executionID][watchID]; !exists {
+		return false
+	}
+	delete(dm.watches[executionID], watchID)
+	return true
+}
+
+// RemoveWatchesByOwner removes all watch expressions registered by a client
+func (dm *DebugManager) RemoveWatchesByOwner(owner string) int {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	removed := 0
+	for _, watches := range dm.watches {
+		for watchID, state := range watches {
+			if state.expression.Owner == owner {
+				delete(watches, watchID)
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+// GetWatches returns the watch expressions of an execution
+func (dm *DebugManager) GetWatches(executionID string) []WatchExpression {
+	dm.mutex.RLock()
+	defer dm.mutex.RUnlock()
+
+	watches := make([]WatchExpression, 0, len(dm.watches[executionID]))
+	for _, state := range dm.watches[executionID] {
+		watches = append(watches, state.expression)
+	}
+	return watches
+}
+
+// RecordVariableChange stores the new value of an execution variable, appends it to the
+// variable history and updates the watches on it
+func (dm *DebugManager) RecordVariableChange(executionID, name string, value interface{}) {
+	dm.mutex.Lock()
+	if _, exists := dm.variables[executionID]; !exists {
+		dm.variables[executionID] = make(map[string]interface{})
+	}
+	dm.variables[executionID][name] = value
+	dm.recordVariableChangeLocked(executionID, name, value)
+	dm.touchLocked(executionID)
+
+	updates := dm.collectWatchUpdatesLocked(executionID, func(w *WatchExpression) bool {
+		return w.Variable == name
+	})
+	dm.mutex.Unlock()
+
+	dm.notifyWatches(updates)
+}
+
+// collectWatchUpdatesLocked evaluates the matching watches of an execution and
+// returns the ones whose value changed. Requires the write lock.
+func (dm *DebugManager) collectWatchUpdatesLocked(executionID string, match func(w *WatchExpression) bool) []WatchUpdate {
+	watches := dm.watches[executionID]
+	if len(watches) == 0 {
+		return nil
+	}
+
+	updates := make([]WatchUpdate, 0)
+	for _, state := range watches {
+		if !match(&state.expression) {
+			continue
+		}
+
+		value, available := dm.evaluateWatchLocked(executionID, &state.expression)
+		if !available && !state.available {
+			continue
+		}
+
+		debugValue := types.DebugValue(value)
+		encoded, err := json.Marshal(debugValue)
+		if err != nil {
+			encoded = []byte(fmt.Sprintf("%v", debugValue))
+		}
+		if available == state.available && string(encoded) == state.last {
+			continue
+		}
+		state.last = string(encoded)
+		state.available = available
+
+		updates = append(updates, WatchUpdate{
+			WatchID:     state.expression.ID,
+			ExecutionID: executionID,
+			Expression:  state.expression.Expression,
+			Owner:       state.expression.Owner,
+			Value:       debugValue,
+			Available:   available,
+			Timestamp:   time.Now(),
+		})
+	}
+	return updates
+}
+
+// evaluateWatchLocked returns the current value of a watch expression. Requires a read lock.
+func (dm *DebugManager) evaluateWatchLocked(executionID string, watch *WatchExpression) (interface{}, bool) {
+	var value interface{}
+	var exists bool
+	if watch.Variable != "" {
+		value, exists = dm.variables[executionID][watch.Variable]
+	} else {
+		value, exists = dm.outputValues[executionID][watch.NodeID][watch.PinID]
+	}
+	if !exists {
+		return nil, false
+	}
+
+	for _, segment := range watch.Path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if value, exists = v[segment]; !exists {
+				return nil, false
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// notifyWatches pushes watch updates to the watch listener
+func (dm *DebugManager) notifyWatches(updates []WatchUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+
+	dm.mutex.RLock()
+	listener := dm.watchListener
+	dm.mutex.RUnlock()
+
+	if listener == nil {
+		return
+	}
+	for _, update := range updates {
+		listener(update)
+	}
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWatchExpression(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		expected   WatchExpression
+		invalid    bool
+	}{
+		{expression: "counter", expected: WatchExpression{Expression: "counter", Variable: "counter"}},
+		{expression: "  counter ", expected: WatchExpression{Expression: "counter", Variable: "counter"}},
+		{expression: "node.pin", expected: WatchExpression{Expression: "node.pin", NodeID: "node", PinID: "pin", Path: []string{}}},
+		{
+			expression: "node.pin.field.0",
+			expected:   WatchExpression{Expression: "node.pin.field.0", NodeID: "node", PinID: "pin", Path: []string{"field", "0"}},
+		},
+		{expression: "", invalid: true},
+		{expression: "   ", invalid: true},
+		{expression: "node.", invalid: true},
+		{expression: ".pin", invalid: true},
+		{expression: "node..field", invalid: true},
+		{expression: "node.pin.field.", invalid: true},
+	} {
+		t.Run(test.expression, func(t *testing.T) {
+			watch, err := ParseWatchExpression(test.expression)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected %q to be rejected, got %+v", test.expression, watch)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", test.expression, err)
+			}
+			if !reflect.DeepEqual(watch, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, watch)
+			}
+		})
+	}
+}
+
+// collectAllWatches evaluates every watch of the execution "exec"
+func collectAllWatches(dm *DebugManager) []WatchUpdate {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	return dm.collectWatchUpdatesLocked("exec", func(*WatchExpression) bool { return true })
+}
+
+func TestWatchPaths(t *testing.T) {
+	output := map[string]interface{}{
+		"field": []interface{}{"first", map[string]interface{}{"name": "second"}},
+		"count": 2.0,
+	}
+
+	for _, test := range []struct {
+		expression string
+		expected   interface{}
+		available  bool
+	}{
+		{expression: "counter", expected: 1.0, available: true},
+		{expression: "node.pin", expected: output, available: true},
+		{expression: "node.pin.count", expected: 2.0, available: true},
+		{expression: "node.pin.field.0", expected: "first", available: true},
+		{expression: "node.pin.field.1.name", expected: "second", available: true},
+		{expression: "missing"},
+		{expression: "node.other"},
+		{expression: "other.pin"},
+		{expression: "node.pin.missing"},
+		{expression: "node.pin.field.2"},
+		{expression: "node.pin.field.-1"},
+		{expression: "node.pin.field.name"},
+		{expression: "node.pin.count.0"},
+	} {
+		t.Run(test.expression, func(t *testing.T) {
+			dm := NewDebugManager()
+			dm.RecordVariableChange("exec", "counter", 1.0)
+			dm.StoreNodeOutputValue("exec", "node", "pin", output)
+			if _, err := dm.AddWatch("exec", "client", test.expression); err != nil {
+				t.Fatalf("failed to add the watch: %v", err)
+			}
+
+			// Values that were never available aren't pushed
+			updates := collectAllWatches(dm)
+			if !test.available {
+				if len(updates) != 0 {
+					t.Errorf("expected no update of an unavailable value, got %+v", updates)
+				}
+				return
+			}
+			if len(updates) != 1 {
+				t.Fatalf("expected 1 update, got %+v", updates)
+			}
+			if update := updates[0]; !update.Available || !reflect.DeepEqual(update.Value, test.expected) {
+				t.Errorf("expected the value %v, got %+v", test.expected, update)
+			}
+		})
+	}
+}
+
+func TestWatchUpdatesOnlyPushChanges(t *testing.T) {
+	dm := NewDebugManager()
+	var updates []WatchUpdate
+	dm.SetWatchListener(func(update WatchUpdate) {
+		updates = append(updates, update)
+	})
+	counter, _ := dm.AddWatch("exec", "client", "counter")
+	field, _ := dm.AddWatch("exec", "client", "node.pin.field")
+	if _, err := dm.AddWatch("other", "client", "counter"); err != nil {
+		t.Fatalf("failed to add the watch: %v", err)
+	}
+
+	expect := func(step string, expected ...WatchUpdate) {
+		t.Helper()
+		if len(updates) != len(expected) {
+			t.Fatalf("%s: expected %d updates, got %+v", step, len(expected), updates)
+		}
+		for i, update := range updates {
+			if update.WatchID != expected[i].WatchID || update.Available != expected[i].Available || !reflect.DeepEqual(update.Value, expected[i].Value) {
+				t.Errorf("%s: expected %+v, got %+v", step, expected[i], update)
+			}
+			if update.ExecutionID != "exec" || update.Owner != "client" {
+				t.Errorf("%s: expected an update of exec for client, got %+v", step, update)
+			}
+		}
+		updates = nil
+	}
+
+	dm.RecordVariableChange("exec", "counter", 1.0)
+	expect("first value", WatchUpdate{WatchID: counter.ID, Value: 1.0, Available: true})
+
+	dm.RecordVariableChange("exec", "counter", 1.0)
+	expect("same value")
+
+	dm.RecordVariableChange("exec", "counter", 2.0)
+	expect("changed value", WatchUpdate{WatchID: counter.ID, Value: 2.0, Available: true})
+
+	dm.RecordVariableChange("exec", "total", 2.0)
+	expect("other variable")
+
+	dm.StoreNodeOutputValue("exec", "node", "pin", map[string]interface{}{"field": []interface{}{"a"}})
+	expect("first output", WatchUpdate{WatchID: field.ID, Value: []interface{}{"a"}, Available: true})
+
+	// Values are compared by their encoding, not by identity
+	dm.StoreNodeOutputValue("exec", "node", "pin", map[string]interface{}{"field": []interface{}{"a"}, "extra": true})
+	expect("equal output")
+
+	dm.StoreNodeOutputValue("exec", "node", "pin", map[string]interface{}{"extra": true})
+	expect("removed field", WatchUpdate{WatchID: field.ID, Available: false})
+
+	dm.StoreNodeOutputValue("exec", "node", "pin", map[string]interface{}{"extra": false})
+	expect("still removed")
+
+	dm.StoreNodeOutputValue("exec", "node", "pin", map[string]interface{}{"field": []interface{}{"a"}})
+	expect("restored field", WatchUpdate{WatchID: field.ID, Value: []interface{}{"a"}, Available: true})
+
+	// Pushing a watch only pushes its value once it changed
+	dm.PushWatch("exec", counter.ID)
+	expect("pushed unchanged value")
+}
@@ -6,14 +6,19 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/common"
 	"webblueprint/internal/core"
+	"webblueprint/internal/crash"
 	"webblueprint/internal/engineext"
 	"webblueprint/internal/event" // Add event import
+	"webblueprint/internal/locks"
 	"webblueprint/internal/node"
 	"webblueprint/internal/registry"
 	"webblueprint/internal/types"
+	"webblueprint/internal/usage"
 	"webblueprint/pkg/blueprint"
 )
 
@@ -37,6 +42,8 @@ const (
 	EventExecutionStart ExecutionEventType = "execution.start"
 	EventExecutionEnd   ExecutionEventType = "execution.end"
 	EventDebugData      ExecutionEventType = "debug.data"
+	EventNodeRecovered  ExecutionEventType = "node.recovered"
+	EventValuesBatched  ExecutionEventType = "values.batched"
 )
 
 // ExecutionListener listens for execution events
@@ -72,48 +79,123 @@ const (
 
 // ExecutionEngine manages blueprint execution
 type ExecutionEngine struct {
-	OnAnyHook func(
+	// Extensions for context management
+	extensions *engineext.ExecutionEngineExtensions
+
+	hooks atomic.Pointer[Hooks] // Replaced as a whole, read without locking
+
+	nodeRegistry   atomic.Pointer[map[string]node.NodeFactory] // Replaced as a whole, read without locking
+	registryMutex  sync.Mutex                                  // Serializes replacements of the node types
+	executions     *executionRecords                           // ExecutionID -> status, sharded with a lock per execution
+	variables      sync.Map                                    // Blueprint version -> VariableName -> Value
+	listeners      atomic.Pointer[[]ExecutionListener]         // Replaced as a whole, read without locking
+	listenersMutex sync.Mutex                                  // Serializes additions of listeners
+
+	blueprints        map[blueprintKey]*blueprint.Blueprint
+	plans             map[blueprintKey]*cachedPlan // Blueprint version -> compiled execution plan
+	debugManager      *DebugManager
+	logger            node.Logger
+	executionMode     ExecutionMode
+	scheduler         *ExecutionScheduler                    // Priority queue for submitted executions
+	replays           map[string]*replaySession              // ExecutionID -> recording or replay of external inputs
+	profiles          map[string]*profileSession             // ExecutionID -> profile of the node executions
+	overrides         map[string]map[string]node.NodeFactory // ExecutionID -> NodeID -> factory replacing the node
+	errorPolicies     map[string]*errorPolicyState           // ExecutionID -> unhandled node errors under the blueprint's error policy
+	recoveries        map[string]*recoveryState              // ExecutionID -> recovery strategies applied to node errors
+	joinStates        map[string]*joinState                  // ExecutionID -> execution inputs activated on join nodes
+	activeVersions    map[string]string                      // BlueprintID -> version activated through ReloadBlueprint
+	currentVersions   map[string]string                      // BlueprintID -> version event handlers and executions without a version run
+	executionVersions map[string]blueprintKey                // ExecutionID -> version of the blueprint the execution runs
+	pinned            map[string]bool                        // ExecutionID -> whether the execution runs its version without making it current
+	longRunning       map[string]bool                        // ExecutionID -> whether the execution is exempt from the actor timeout
+	actorTimeout      time.Duration                          // How long actor mode executions wait for their nodes
+	nodeLimits        NodeLimits                             // Bounds every node execution
+	mailboxes         mailboxPolicies                        // Sizes the mailboxes of node actors
+	overflows         map[string]*overflowState              // ExecutionID -> mailbox overflows of node actors
+	budgets           map[string]*budgetState                // ExecutionID -> consumption of the blueprint's execution budget
+	suspended         map[string]SuspendedExecution          // ExecutionID -> execution submitted to the scheduler that hasn't started
+	submitted         map[string]chan struct{}               // ExecutionID -> closed once the scheduler job of the execution ends
+	mutex             sync.RWMutex
+
+	concurrency      map[string]*concurrencySlot // BlueprintID -> execution slot under a limiting concurrency policy
+	concurrencyMutex sync.Mutex
+
+	skippedTriggers map[string][]SkippedTrigger // BlueprintID -> latest triggers rejected by entry point guards
+	skippedMutex    sync.Mutex
+
+	latencies *latencyWindow // Durations of the latest node executions
+}
+
+// DefaultActorTimeout is how long actor mode executions wait for their nodes by default
+const DefaultActorTimeout = 30 * time.Second
+
+// Hooks record the progress of executions, e.g. in the execution records
+type Hooks struct {
+	// OnAny is called with the log entries of executions
+	OnAny func(
 		ctx context.Context,
 		executionID, nodeID, level, message string,
 		details map[string]interface{},
 	) error
 
-	// Extensions for context management
-	extensions *engineext.ExecutionEngineExtensions
-
-	// Add the hook for node execution recording
-	OnNodeExecutionHook func(
+	// OnNodeExecution is called when a node starts and completes
+	OnNodeExecution func(
 		ctx context.Context,
 		executionID, nodeID, nodeType, execState string,
 		inputs, outputs map[string]interface{},
 	) error
 
-	nodeRegistry    map[string]node.NodeFactory
-	blueprints      map[string]*blueprint.Blueprint
-	executionStatus map[string]*ExecutionStatus
-	variables       map[string]map[string]types.Value // BlueprintID -> VariableName -> Value
-	listeners       []ExecutionListener
-	debugManager    *DebugManager
-	logger          node.Logger
-	executionMode   ExecutionMode
-	hooks           *node.ExecutionHooks // Keep track of hooks for the current execution
-	mutex           sync.RWMutex
+	// OnExecutionStart is called when a scheduler worker starts a queued execution
+	OnExecutionStart func(executionID string)
 }
 
 // NewExecutionEngine creates a new execution engine
 func NewExecutionEngine(logger node.Logger, debugManager *DebugManager) *ExecutionEngine {
 	return &ExecutionEngine{
-		nodeRegistry:    make(map[string]node.NodeFactory),
-		blueprints:      make(map[string]*blueprint.Blueprint),
-		executionStatus: make(map[string]*ExecutionStatus),
-		variables:       make(map[string]map[string]types.Value),
-		listeners:       make([]ExecutionListener, 0),
-		logger:          logger,
-		debugManager:    debugManager,
-		executionMode:   ModeStandard, // Default to standard mode
+		executions:        newExecutionRecords(),
+		blueprints:        make(map[blueprintKey]*blueprint.Blueprint),
+		plans:             make(map[blueprintKey]*cachedPlan),
+		logger:            logger,
+		debugManager:      debugManager,
+		executionMode:     ModeStandard, // Default to standard mode
+		scheduler:         NewExecutionScheduler(DefaultSchedulerWorkers),
+		replays:           make(map[string]*replaySession),
+		profiles:          make(map[string]*profileSession),
+		overrides:         make(map[string]map[string]node.NodeFactory),
+		errorPolicies:     make(map[string]*errorPolicyState),
+		recoveries:        make(map[string]*recoveryState),
+		joinStates:        make(map[string]*joinState),
+		activeVersions:    make(map[string]string),
+		currentVersions:   make(map[string]string),
+		executionVersions: make(map[string]blueprintKey),
+		pinned:            make(map[string]bool),
+		longRunning:       make(map[string]bool),
+		actorTimeout:      DefaultActorTimeout,
+		nodeLimits:        DefaultNodeLimits,
+		mailboxes:         mailboxPolicies{defaults: DefaultMailboxPolicy},
+		overflows:         make(map[string]*overflowState),
+		budgets:           make(map[string]*budgetState),
+		suspended:         make(map[string]SuspendedExecution),
+		submitted:         make(map[string]chan struct{}),
+		concurrency:       make(map[string]*concurrencySlot),
+		skippedTriggers:   make(map[string][]SkippedTrigger),
+		latencies:         &latencyWindow{},
 	}
 }
 
+// SetHooks replaces the hooks of the engine. Executions running meanwhile may call either.
+func (e *ExecutionEngine) SetHooks(hooks Hooks) {
+	e.hooks.Store(&hooks)
+}
+
+// currentHooks returns the hooks of the engine, none if they weren't set
+func (e *ExecutionEngine) currentHooks() Hooks {
+	if hooks := e.hooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return Hooks{}
+}
+
 // SetExecutionMode sets the execution mode
 func (e *ExecutionEngine) SetExecutionMode(mode ExecutionMode) {
 	e.mutex.Lock()
@@ -127,29 +209,253 @@ func (e *ExecutionEngine) GetExecutionMode() ExecutionMode {
 	return e.executionMode
 }
 
-// RegisterNodeType registers a node type with the engine
-func (e *ExecutionEngine) RegisterNodeType(typeID string, factory node.NodeFactory) {
+// SetActorTimeout sets how long actor mode executions wait for their nodes before timing out
+func (e *ExecutionEngine) SetActorTimeout(timeout time.Duration) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.actorTimeout = timeout
+}
+
+// ExecutionLimits bounds a single execution; zero values mean unlimited
+type ExecutionLimits struct {
+	MaxNodes    int
+	MaxDuration time.Duration
+}
+
+// ExecuteWithPriority queues an execution on the scheduler and blocks until it completes
+func (e *ExecutionEngine) ExecuteWithPriority(priority ExecutionPriority, bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value) (common.ExecutionResult, error) {
+	return e.ExecuteWithLimits(priority, bp, executionID, initialData, ExecutionLimits{})
+}
+
+// ExecuteWithLimits queues an execution and enforces the node count and duration limits.
+// When the duration is exceeded the execution is marked as timed out, its context is done
+// and an error is returned. The execution doesn't start new nodes once its context is done
+// and finishes in the background.
+func (e *ExecutionEngine) ExecuteWithLimits(priority ExecutionPriority, bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value, limits ExecutionLimits) (common.ExecutionResult, error) {
+	return e.ExecuteWithLimitsContext(context.Background(), priority, bp, executionID, initialData, limits)
+}
+
+// ExecuteWithLimitsContext queues an execution like ExecuteWithLimits, running it with the
+// context. Cancelling the context stops the execution before its next node, and the call
+// returns once it ended.
+func (e *ExecutionEngine) ExecuteWithLimitsContext(ctx context.Context, priority ExecutionPriority, bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value, limits ExecutionLimits) (common.ExecutionResult, error) {
+	if limits.MaxNodes > 0 && len(bp.Nodes) > limits.MaxNodes {
+		err := bperrors.New(
+			bperrors.ErrorTypeQuota,
+			bperrors.ErrBlueprintTooLarge,
+			fmt.Sprintf("blueprint has %d nodes, exceeding the limit of %d", len(bp.Nodes), limits.MaxNodes),
+			bperrors.SeverityHigh,
+		).WithBlueprintInfo(bp.ID, executionID).WithDetails(map[string]interface{}{
+			"nodeCount": len(bp.Nodes),
+			"limit":     limits.MaxNodes,
+		})
+		return common.ExecutionResult{ExecutionID: executionID, Success: false, Error: err, StartTime: time.Now(), EndTime: time.Now()}, err
+	}
+
+	// Executions are suspended until a worker starts them, so snapshots can hand them over
+	e.setSuspended(executionID, bp.ID, priority)
+
+	// Executions wait for their blueprint's slot before taking a scheduler worker, and keep
+	// it until they end, even when they outlive their duration limit
+	release, err := e.acquireConcurrency(bp, executionID)
+	if err != nil {
+		e.clearSuspended(executionID)
+		return common.ExecutionResult{ExecutionID: executionID, Success: false, Error: err, StartTime: time.Now(), EndTime: time.Now()}, err
+	}
+
+	// The duration limit counts from the submission, like the time the execution is queued
+	parent := ctx
+	if limits.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.MaxDuration)
+		defer cancel()
+	}
+
+	var result common.ExecutionResult
+	var execErr error
+	done := make(chan struct{})
+
+	// Executions are queued until a worker takes them
+	e.executions.store(ExecutionStatus{
+		ExecutionID: executionID,
+		Status:      "queued",
+		StartTime:   time.Now(),
+	})
+	e.setSubmitted(executionID, done)
+
+	err = e.scheduler.Submit(priority, func() {
+		defer e.clearSubmitted(executionID)
+		defer close(done)
+		defer release()
+		// A panic of the engine fails the execution instead of taking down the server
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				bpErr := executionPanicked(recovered, bp.ID, executionID)
+				e.reportPanic(crash.SourceExecution, bpErr)
+				e.executions.update(executionID, func(status *ExecutionStatus) {
+					if status.Status == "running" {
+						status.Status = "failed"
+						status.EndTime = time.Now()
+					}
+				})
+				result = common.ExecutionResult{ExecutionID: executionID, Success: false, Error: bpErr, EndTime: time.Now()}
+				execErr = bpErr
+			}
+		}()
+		e.clearSuspended(executionID)
+		if onStart := e.currentHooks().OnExecutionStart; onStart != nil {
+			onStart(executionID)
+		}
+		result, execErr = e.ExecuteContext(ctx, bp, executionID, initialData)
+	})
+	if err != nil {
+		e.clearSuspended(executionID)
+		e.clearSubmitted(executionID)
+		release()
+		e.executions.update(executionID, func(status *ExecutionStatus) {
+			status.Status = "failed"
+			status.EndTime = time.Now()
+		})
+		return common.ExecutionResult{ExecutionID: executionID, Success: false, Error: err}, err
+	}
+
+	if limits.MaxDuration <= 0 {
+		<-done
+		return result, execErr
+	}
+
+	select {
+	case <-done:
+		return result, execErr
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			<-done
+			return result, execErr
+		}
+		e.executions.update(executionID, func(status *ExecutionStatus) {
+			status.Status = "timeout"
+			status.EndTime = time.Now()
+		})
+
+		err := bperrors.New(
+			bperrors.ErrorTypeQuota,
+			bperrors.ErrExecutionDurationExceeded,
+			fmt.Sprintf("execution exceeded the maximum duration of %v", limits.MaxDuration),
+			bperrors.SeverityHigh,
+		).WithBlueprintInfo(bp.ID, executionID).WithDetails(map[string]interface{}{
+			"limit": limits.MaxDuration.String(),
+		})
+		return common.ExecutionResult{ExecutionID: executionID, Success: false, Error: err, EndTime: time.Now()}, err
+	}
+}
+
+// WaitForExecution blocks until the execution submitted to the scheduler has ended, which
+// can be after ExecuteWithLimits returned on its duration limit. It returns right away for
+// executions that aren't submitted.
+func (e *ExecutionEngine) WaitForExecution(executionID string) {
+	e.mutex.RLock()
+	done, submitted := e.submitted[executionID]
+	e.mutex.RUnlock()
+
+	if submitted {
+		<-done
+	}
+}
+
+// setSubmitted tracks the end of an execution submitted to the scheduler
+func (e *ExecutionEngine) setSubmitted(executionID string, done chan struct{}) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
+	e.submitted[executionID] = done
+}
+
+// clearSubmitted drops an execution whose scheduler job ended or was turned away
+func (e *ExecutionEngine) clearSubmitted(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.submitted, executionID)
+}
+
+// GetQueueDepth returns the number of queued executions per priority
+func (e *ExecutionEngine) GetQueueDepth() map[string]int {
+	return e.scheduler.QueueDepth()
+}
+
+// GetRunningExecutions returns the number of executions started by the scheduler that are still running
+func (e *ExecutionEngine) GetRunningExecutions() int {
+	return e.scheduler.Running()
+}
+
+// RegisterNodeType registers a node type with the engine
+func (e *ExecutionEngine) RegisterNodeType(typeID string, factory node.NodeFactory) {
+	e.registryMutex.Lock()
+	defer e.registryMutex.Unlock()
+
+	// Executions read the node types without locking, so they're copied rather than changed
+	current := e.nodeFactories()
+	factories := make(map[string]node.NodeFactory, len(current)+1)
+	for id, existing := range current {
+		factories[id] = existing
+	}
+	factories[typeID] = factory
+	e.nodeRegistry.Store(&factories)
+}
 
-	e.nodeRegistry[typeID] = factory
+// GetNodeFactory returns the factory registered for a node type
+func (e *ExecutionEngine) GetNodeFactory(typeID string) (node.NodeFactory, bool) {
+	factory, exists := e.nodeFactories()[typeID]
+	return factory, exists
 }
 
-// LoadBlueprint registers a blueprint with the engine and auto-binds event listeners
+// LoadBlueprint registers a blueprint with the engine and auto-binds event listeners. The
+// engine keeps its own copy, so later changes to the blueprint don't reach it; changes are
+// saved as a new version and reloaded instead.
 func (e *ExecutionEngine) LoadBlueprint(bp *blueprint.Blueprint) error {
+	_, err := e.loadBlueprint(bp, false)
+	return err
+}
+
+// loadBlueprint registers a copy of a version of a blueprint and returns the copy, which
+// is never changed. Pinned versions are loaded for the executions that run them and never
+// become current.
+func (e *ExecutionEngine) loadBlueprint(bp *blueprint.Blueprint, pinned bool) (*blueprint.Blueprint, error) {
+	// Executions and event handlers share the loaded blueprint, so the caller's one, which
+	// it may still change, isn't kept
+	bp = bp.Clone()
+
 	// --- Step 1: Update Engine State (Requires Lock) ---
 	e.mutex.Lock()
-	// Initialize variables for this blueprint if not already present
-	if _, exists := e.variables[bp.ID]; !exists {
-		e.variables[bp.ID] = make(map[string]types.Value)
+	key := keyOf(bp)
+	// Initialize variables for this version if not already present
+	e.initVariables(key)
+	// Store the blueprint alongside its other loaded versions
+	e.blueprints[key] = bp
+	// Versions other than the activated one, e.g. still running when a new version was
+	// activated or run as a canary, don't replace the current one
+	version, active := e.activeVersions[bp.ID]
+	current := !pinned && (!active || version == bp.Version)
+	var retired blueprintKey
+	var retiredExists bool
+	if current {
+		retired, retiredExists = e.makeCurrentLocked(bp)
 	}
-	// Store the blueprint
-	e.blueprints[bp.ID] = bp
 	// Get extensions reference while holding lock
 	extensions := e.extensions
 	logger := e.logger // Get logger reference
 	e.mutex.Unlock()   // --- Release Lock ---
 
+	if retiredExists {
+		e.unregisterVersionNodes(retired)
+	}
+
+	// Getter and setter nodes of the variables are available to every execution path
+	e.registerVariableNodes(bp)
+
+	// Only the current version receives events, so other versions run in isolation
+	if !current {
+		return bp, nil
+	}
+
 	// --- Step 2: Get Event Manager (Does not require engine lock) ---
 	var concreteEventManager *event.EventManager
 	if extensions != nil {
@@ -158,36 +464,17 @@ func (e *ExecutionEngine) LoadBlueprint(bp *blueprint.Blueprint) error {
 
 	if concreteEventManager == nil {
 		logger.Warn("Cannot register custom events or bind listeners: EventManager not available.", map[string]interface{}{"blueprintId": bp.ID})
-		return nil // Allow loading blueprint even if event manager isn't ready
-	}
-
-	for _, definition := range bp.Events {
-		eventParams := make([]event.EventParameter, len(definition.Parameters))
-		for _, parameter := range definition.Parameters {
-			pinType, _ := types.GetPinTypeByID(parameter.TypeID)
-			eventParams = append(eventParams, event.EventParameter{
-				Name:        parameter.Name,
-				Type:        pinType,
-				Description: parameter.Description,
-				Optional:    parameter.Optional,
-				Default:     parameter.Default,
-			})
-		}
-		rErr := concreteEventManager.RegisterEvent(event.EventDefinition{
-			ID:          definition.ID,
-			Name:        definition.Name,
-			Description: definition.Description,
-			Parameters:  eventParams,
-			Category:    definition.Category,
-			BlueprintID: bp.ID,
-			CreatedAt:   time.Now(),
-		})
+		return bp, nil // Allow loading blueprint even if event manager isn't ready
+	}
+
+	for _, definition := range eventDefinitions(bp) {
+		rErr := concreteEventManager.RegisterEvent(definition)
 		if rErr != nil {
 			logger.Error("An error occurred while event registration", map[string]interface{}{
 				"blueprintId": bp.ID,
 				"name":        definition.Name,
 				"description": definition.Description,
-				"parameters":  eventParams,
+				"parameters":  definition.Parameters,
 				"category":    definition.Category,
 				"error":       rErr.Error(),
 			})
@@ -197,22 +484,13 @@ func (e *ExecutionEngine) LoadBlueprint(bp *blueprint.Blueprint) error {
 			"blueprintId": bp.ID,
 			"name":        definition.Name,
 			"description": definition.Description,
-			"parameters":  eventParams,
+			"parameters":  definition.Parameters,
 			"category":    definition.Category,
 		})
 	}
 
-	for _, binding := range bp.EventBindings {
-		bErr := concreteEventManager.BindEvent(event.EventBinding{
-			ID:          binding.ID,
-			EventID:     binding.EventID,
-			HandlerID:   binding.HandlerID,
-			HandlerType: binding.HandlerType,
-			BlueprintID: bp.ID,
-			Priority:    binding.Priority,
-			CreatedAt:   time.Now(),
-			Enabled:     binding.Enabled,
-		})
+	for _, binding := range eventBindings(bp) {
+		bErr := concreteEventManager.BindEvent(binding)
 		if bErr != nil {
 			logger.Error("An error occurred while event binding", map[string]interface{}{
 				"blueprintId": bp.ID,
@@ -232,25 +510,48 @@ func (e *ExecutionEngine) LoadBlueprint(bp *blueprint.Blueprint) error {
 		})
 	}
 
-	return nil
+	return bp, nil
 }
 
-// AddExecutionListener adds a listener for execution events
+// AddExecutionListener adds a listener for execution events. Events are queued with the
+// default listener options and delivered from the listener's own goroutine.
 func (e *ExecutionEngine) AddExecutionListener(listener ExecutionListener) {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	e.listeners = append(e.listeners, listener)
+	e.AddExecutionListenerWithOptions(listener, DefaultListenerOptions())
 }
 
-// EmitEvent sends an event to all listeners
-func (e *ExecutionEngine) EmitEvent(event ExecutionEvent) {
-	e.mutex.RLock()
-	listeners := make([]ExecutionListener, len(e.listeners))
-	copy(listeners, e.listeners)
-	e.mutex.RUnlock()
+// AddExecutionListenerWithOptions adds a listener for execution events with its own queue size
+// and overflow policy
+func (e *ExecutionEngine) AddExecutionListenerWithOptions(listener ExecutionListener, options ListenerOptions) {
+	e.listenersMutex.Lock()
+	defer e.listenersMutex.Unlock()
+
+	var fullFidelity func(nodeID string) bool
+	if e.debugManager != nil {
+		fullFidelity = e.debugManager.HasFullFidelity
+	}
+	// Events are emitted without locking, so the listeners are copied rather than changed
+	current := e.executionListeners()
+	listeners := make([]ExecutionListener, len(current), len(current)+1)
+	copy(listeners, current)
+	listeners = append(listeners, newQueuedListener(listener, options, fullFidelity))
+	e.listeners.Store(&listeners)
+}
 
+// GetListenerStats returns the delivered, coalesced and dropped events of each listener
+func (e *ExecutionEngine) GetListenerStats() []ListenerStats {
+	listeners := e.executionListeners()
+	stats := make([]ListenerStats, 0, len(listeners))
 	for _, listener := range listeners {
+		if queued, ok := listener.(*queuedListener); ok {
+			stats = append(stats, queued.stats())
+		}
+	}
+	return stats
+}
+
+// EmitEvent queues an event for all listeners
+func (e *ExecutionEngine) EmitEvent(event ExecutionEvent) {
+	for _, listener := range e.executionListeners() {
 		listener.OnExecutionEvent(event)
 	}
 }
@@ -264,6 +565,16 @@ func (e *ExecutionEngine) SetExtensions(extensions *engineext.ExecutionEngineExt
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	e.extensions = extensions
+
+	// Contexts of nodes return the context of their execution
+	if extensions != nil && extensions.ContextManager != nil {
+		extensions.ContextManager.SetExecutionContexts(e.executionContext)
+	}
+
+	// Rate limited nodes run again once their timer fires
+	if extensions != nil && extensions.TimerService != nil {
+		extensions.TimerService.SetFireHandler(e.fireTimer)
+	}
 }
 
 // GetExtensions returns the engine extensions
@@ -334,6 +645,11 @@ func (c *BasicExecutionContext) GetExecutionID() string {
 	return c.executionID
 }
 
+// Context returns the background context, as basic contexts run outside of executions
+func (c *BasicExecutionContext) Context() context.Context {
+	return context.Background()
+}
+
 // GetInputValue gets an input value by pin ID
 func (c *BasicExecutionContext) GetInputValue(pinID string) (types.Value, bool) {
 	value, exists := c.inputs[pinID]
@@ -402,67 +718,69 @@ func (c *BasicExecutionContext) GetDebugData() map[string]interface{} {
 
 // GetNodeStatus returns the current execution status of a node
 func (e *ExecutionEngine) GetNodeStatus(executionID, nodeID string) (NodeStatus, bool) {
-	e.mutex.RLock()
-	defer e.mutex.RUnlock()
-
-	status, exists := e.executionStatus[executionID]
+	record, exists := e.executions.get(executionID)
 	if !exists {
 		return NodeStatus{}, false
 	}
 
-	nodeStatus, exists := status.NodeStatuses[nodeID]
+	record.mutex.Lock()
+	defer record.mutex.Unlock()
+	nodeStatus, exists := record.status.NodeStatuses[nodeID]
 	return nodeStatus, exists
 }
 
 // GetExecutionStatus returns the current execution status
 func (e *ExecutionEngine) GetExecutionStatus(executionID string) (ExecutionStatus, bool) {
-	e.mutex.RLock()
-	defer e.mutex.RUnlock()
-
-	status, exists := e.executionStatus[executionID]
+	record, exists := e.executions.get(executionID)
 	if !exists {
 		return ExecutionStatus{}, false
 	}
 
-	return *status, true
+	return record.snapshot(), true
 }
 
 // TriggerNodeExecution starts the execution of a specific node, typically an event handler.
 // This method implements the core.EngineController interface.
 func (e *ExecutionEngine) TriggerNodeExecution(blueprintID string, nodeID string, triggerContext core.EventHandlerContext) error {
-	e.mutex.RLock()
-	bp, bpExists := e.blueprints[blueprintID]
-	// extensions := e.extensions // No longer needed here
-	e.mutex.RUnlock()
+	// Replayed executions only receive the recorded events
+	if session := e.replaySession(triggerContext.ExecutionID); session != nil {
+		if session.replaying {
+			e.logger.Debug("Ignoring live event for replayed execution", map[string]interface{}{"nodeId": nodeID, "eventId": triggerContext.EventID})
+			return nil
+		}
+		session.recordEvent(triggerContext)
+	}
+
+	return e.triggerNodeExecution(blueprintID, nodeID, triggerContext)
+}
 
+// triggerNodeExecution executes an event handler node for an event
+func (e *ExecutionEngine) triggerNodeExecution(blueprintID string, nodeID string, triggerContext core.EventHandlerContext) error {
+	// Handlers run the version of the execution that triggered them, if the engine runs it
+	bp, key, bpExists := e.resolveBlueprint(blueprintID, triggerContext.ExecutionID)
 	if !bpExists {
 		return fmt.Errorf("TriggerNodeExecution: blueprint %s not loaded", blueprintID)
 	}
 
 	// We need variables, but hooks might not apply directly when triggering
 	// Get variables associated with the blueprint instance if possible
-	e.mutex.RLock()
 	variables := make(map[string]types.Value)
-	if blueprintVars, ok := e.variables[blueprintID]; ok {
+	if blueprintVars, ok := e.blueprintVariables(key); ok {
 		for k, v := range blueprintVars {
 			variables[k] = v // Copy variables
 		}
 	}
-	e.mutex.RUnlock()
 
 	executionID := triggerContext.ExecutionID
 	hooks := &node.ExecutionHooks{
 		OnNodeStart: func(nID, nodeType string) {
-			e.mutex.Lock()
-			status, ok := e.executionStatus[executionID]
-			if ok {
-				status.NodeStatuses[nID] = NodeStatus{
+			if record, ok := e.executions.get(executionID); ok {
+				record.startNode(nID, NodeStatus{
 					NodeID:    nID,
 					Status:    "executing",
 					StartTime: time.Now(),
-				}
+				})
 			}
-			e.mutex.Unlock()
 			e.EmitEvent(ExecutionEvent{
 				Type:      EventNodeStarted,
 				Timestamp: time.Now(),
@@ -471,17 +789,11 @@ func (e *ExecutionEngine) TriggerNodeExecution(blueprintID string, nodeID string
 			})
 		},
 		OnNodeComplete: func(nID, nodeType string) {
-			e.mutex.Lock()
-			status, ok := e.executionStatus[executionID]
-			if ok {
-				nodeStatus, exists := status.NodeStatuses[nID]
-				if exists {
-					nodeStatus.Status = "completed"
-					nodeStatus.EndTime = time.Now()
-					status.NodeStatuses[nID] = nodeStatus
+			if record, ok := e.executions.get(executionID); ok {
+				if nodeStatus, exists := record.endNode(nID, "completed", nil); exists {
+					e.observeNode(nodeStatus)
 				}
 			}
-			e.mutex.Unlock()
 			e.EmitEvent(ExecutionEvent{
 				Type:      EventNodeCompleted,
 				Timestamp: time.Now(),
@@ -490,18 +802,11 @@ func (e *ExecutionEngine) TriggerNodeExecution(blueprintID string, nodeID string
 			})
 		},
 		OnNodeError: func(nID string, err error) {
-			e.mutex.Lock()
-			status, ok := e.executionStatus[executionID]
-			if ok {
-				nodeStatus, exists := status.NodeStatuses[nID]
-				if exists {
-					nodeStatus.Status = "error"
-					nodeStatus.Error = err
-					nodeStatus.EndTime = time.Now()
-					status.NodeStatuses[nID] = nodeStatus
+			if record, ok := e.executions.get(executionID); ok {
+				if nodeStatus, exists := record.endNode(nID, "error", err); exists {
+					e.observeNode(nodeStatus)
 				}
 			}
-			e.mutex.Unlock()
 			e.EmitEvent(ExecutionEvent{
 				Type:      EventNodeError,
 				Timestamp: time.Now(),
@@ -523,12 +828,27 @@ func (e *ExecutionEngine) TriggerNodeExecution(blueprintID string, nodeID string
 		},
 	}
 
+	// Events whose payload the guard of the handler rejects don't run it
+	if bpNode := bp.FindNode(triggerContext.HandlerID); bpNode != nil {
+		if ok, reason := evaluateGuard(bpNode, triggerContext.Parameters); !ok {
+			e.recordSkippedTrigger(SkippedTrigger{
+				BlueprintID: blueprintID,
+				NodeID:      triggerContext.HandlerID,
+				EventID:     triggerContext.EventID,
+				ExecutionID: executionID,
+				Guard:       bpNode.GetGuard(),
+				Reason:      reason,
+			})
+			return nil
+		}
+	}
+
 	e.logger.Info("Triggering event handler node execution", map[string]interface{}{"nodeId": nodeID, "eventId": triggerContext.EventID})
 
 	//entryPoints := []string{triggerContext.HandlerID}
 	//err := e.executeWithActorSystem(bp, executionID, entryPoints, variables)
 	// Call executeNode, passing the triggerContext and the newly defined hooks.
-	err := e.executeNode(triggerContext.HandlerID, bp, blueprintID, executionID, variables, hooks, &triggerContext) // Pass hooks
+	err := e.executeNode(triggerContext.HandlerID, bp, blueprintID, executionID, variables, hooks, &triggerContext, nil) // Pass hooks
 	if err != nil {
 		e.logger.Error("Error executing triggered event handler node", map[string]interface{}{"nodeId": nodeID, "error": err.Error()})
 		// Potentially call OnNodeError hook here as well if executeNode fails immediately
@@ -546,37 +866,117 @@ func (e *ExecutionEngine) GetNodeDebugData(executionID, nodeID string) (map[stri
 	return e.debugManager.GetNodeDebugData(executionID, nodeID)
 }
 
-// Execute runs a blueprint
+// GetExecutionTimeline returns the node execution timeline of an execution retained by the debug manager
+func (e *ExecutionEngine) GetExecutionTimeline(executionID string) (*ExecutionTimeline, bool) {
+	return e.debugManager.GetExecutionTimeline(executionID)
+}
+
+// Execute runs a blueprint and dispatches the lifecycle events of the execution to the
+// handlers bound to them
 func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value) (common.ExecutionResult, error) {
-	e.nodeRegistry = registry.GetInstance().GetAllNodeFactories()
+	return e.ExecuteContext(context.Background(), bp, executionID, initialData)
+}
+
+// ExecuteContext runs a blueprint like Execute. The nodes get the context, which is done
+// once it is cancelled or the execution ends, for their outbound calls.
+func (e *ExecutionEngine) ExecuteContext(ctx context.Context, bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value) (common.ExecutionResult, error) {
+	result, err := e.execute(ctx, bp, executionID, initialData)
+	e.dispatchExecutionEnd(bp, executionID, result.Success, err)
+	return result, err
+}
+
+// execute runs a blueprint
+func (e *ExecutionEngine) execute(ctx context.Context, bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value) (common.ExecutionResult, error) {
+	e.replaceNodeFactories(registry.GetInstance().GetAllNodeFactories())
+
+	// The blueprint's concurrency policy may queue or turn away overlapping executions
+	release, acquireErr := e.acquireConcurrency(bp, executionID)
+	if acquireErr != nil {
+		return common.ExecutionResult{
+			ExecutionID: executionID,
+			Success:     false,
+			Error:       acquireErr,
+			StartTime:   time.Now(),
+			EndTime:     time.Now(),
+		}, acquireErr
+	}
+	defer release()
+
+	// Locks the execution took and didn't release are released once it ends
+	defer locks.Default().ReleaseAll(context.Background(), executionID)
+
+	// Node overrides only apply to this execution
+	defer e.clearNodeOverrides(executionID)
+	pinned := e.versionPinned(executionID)
+	defer e.unpinVersion(executionID)
+	defer e.unmarkLongRunning(executionID)
+
+	// Unhandled node errors are routed by the blueprint's error policy
+	e.setErrorPolicy(executionID, bp)
+	defer e.clearErrorPolicy(executionID)
+
+	// Node errors are recovered by the strategies registered for their error codes first
+	e.setRecovery(executionID, bp)
+	defer e.clearRecovery(executionID)
 
-	// Load the blueprint (this will register event bindings)
-	if err := e.LoadBlueprint(bp); err != nil {
+	// Join nodes wait for their parallel branches within this execution
+	e.setJoinState(executionID)
+	defer e.clearJoinState(executionID)
+
+	e.setOverflowState(executionID)
+	defer e.clearOverflowState(executionID)
+
+	// Executions beyond the blueprint's budget of time and node executions are aborted
+	e.setBudget(executionID, bp)
+	defer e.clearBudget(executionID)
+
+	// Load the blueprint (this will register event bindings). The execution runs the loaded
+	// copy, which the caller can't change while it runs.
+	loaded, loadErr := e.loadBlueprint(bp, pinned)
+	if loadErr != nil {
 		// Create minimal error result
 		return common.ExecutionResult{
 			ExecutionID: executionID,
 			Success:     false,
-			Error:       fmt.Errorf("failed to load blueprint: %w", err),
+			Error:       fmt.Errorf("failed to load blueprint: %w", loadErr),
 			StartTime:   time.Now(), // Or get from somewhere?
 			EndTime:     time.Now(),
-		}, err
+		}, loadErr
 	}
+	bp = loaded
 	// Keep mutex locked for status/variable initialization? No, LoadBlueprint unlocks. Lock again.
 	blueprintID := bp.ID
 
+	// The topology of the version is compiled once and shared by its executions
+	plan := e.planFor(bp)
+
+	// The execution runs this version, whichever version is current
+	e.setExecutionVersion(executionID, bp)
+	defer e.clearExecutionVersion(executionID)
+
 	// Initialize execution status
-	status := &ExecutionStatus{
-		ExecutionID:  executionID,
-		Status:       "running",
-		StartTime:    time.Now(),
-		NodeStatuses: make(map[string]NodeStatus),
-	}
-	e.executionStatus[executionID] = status
+	startTime := time.Now()
+	record := e.executions.store(ExecutionStatus{
+		ExecutionID: executionID,
+		Status:      "running",
+		StartTime:   startTime,
+	})
+
+	// Nodes use the context of the execution for their outbound calls until it ends
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	record.setContext(ctx)
+	defer record.setContext(nil)
+
+	// Running executions are exempt from debug data eviction, and streams
+	// must not outlive the execution that produced them
+	e.debugManager.BeginExecution(executionID)
+	defer e.debugManager.EndExecution(executionID)
 
 	// Initialize result
 	result := common.ExecutionResult{
 		ExecutionID: executionID,
-		StartTime:   status.StartTime,
+		StartTime:   startTime,
 		NodeResults: make(map[string]map[string]interface{}),
 	}
 
@@ -584,8 +984,7 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 	variables := make(map[string]types.Value)
 
 	// Copy blueprint variables
-	// Need lock? Assume variables are read-only after LoadBlueprint or copied safely
-	if vars, exists := e.variables[blueprintID]; exists {
+	if vars, exists := e.blueprintVariables(keyOf(bp)); exists {
 		for k, v := range vars {
 			variables[k] = v
 		}
@@ -598,10 +997,11 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 
 	// Process variables first to ensure they're available to all nodes
 	if err := e.processVariableNodes(bp, executionID, variables); err != nil {
-		e.mutex.Lock()
-		status.Status = "failed"
-		status.EndTime = time.Now()
-		e.mutex.Unlock()
+		endTime := time.Now()
+		record.update(func(status *ExecutionStatus) {
+			status.Status = "failed"
+			status.EndTime = endTime
+		})
 
 		// Emit execution end event
 		e.EmitEvent(ExecutionEvent{
@@ -621,6 +1021,11 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 		return result, err
 	}
 
+	// Make the initial variable values available to watch expressions
+	for name, value := range variables {
+		e.debugManager.RecordVariableChange(executionID, name, value.RawValue)
+	}
+
 	// Emit execution start event
 	e.EmitEvent(ExecutionEvent{
 		Type:      EventExecutionStart,
@@ -631,15 +1036,23 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 		},
 	})
 
-	// Find entry points
-	entryPoints := bp.FindEntryPoints()
+	// Find entry points, leaving out those whose guards reject the initial data
+	foundEntryPoints := plan.EntryPoints()
+	entryPoints, skipReasons := e.admitEntryPoints(plan, executionID, foundEntryPoints, initialData)
 	if len(entryPoints) == 0 {
-		err := fmt.Errorf("no entry points found in blueprint")
+		var err error = fmt.Errorf("no entry points found in blueprint")
+		finalStatus := "failed"
+		if len(foundEntryPoints) > 0 {
+			err = triggerSkippedError(blueprintID, executionID, skipReasons)
+			finalStatus = "skipped"
+		}
+
 		// Update execution status
-		e.mutex.Lock()
-		status.Status = "failed"
-		status.EndTime = time.Now()
-		e.mutex.Unlock()
+		endTime := time.Now()
+		record.update(func(status *ExecutionStatus) {
+			status.Status = finalStatus
+			status.EndTime = endTime
+		})
 
 		// Emit execution end event
 		e.EmitEvent(ExecutionEvent{
@@ -655,22 +1068,24 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 
 		result.Success = false
 		result.Error = err
-		result.EndTime = status.EndTime
+		result.EndTime = endTime
 
 		return result, err
 	}
 
-	// Define hooks for this execution
-	e.hooks = &node.ExecutionHooks{
+	// Executions whose trigger was skipped or that can't run don't count as started
+	e.dispatchLifecycleEvent(event.EventTypeExecutionStarted, blueprintID, executionID, map[string]types.Value{})
+
+	// Define hooks for this execution; they're its own, as executions run concurrently
+	hooks := &node.ExecutionHooks{
 		OnNodeStart: func(nodeID, nodeType string) {
-			e.mutex.Lock()
-			status := e.executionStatus[executionID]
-			status.NodeStatuses[nodeID] = NodeStatus{
+			record.startNode(nodeID, NodeStatus{
 				NodeID:    nodeID,
 				Status:    "executing",
 				StartTime: time.Now(),
-			}
-			e.mutex.Unlock()
+			})
+
+			usage.Record(executionID, usage.MetricNodesExecuted, 1)
 
 			e.EmitEvent(ExecutionEvent{
 				Type:      EventNodeStarted,
@@ -682,15 +1097,10 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 			})
 		},
 		OnNodeComplete: func(nodeID, nodeType string) {
-			e.mutex.Lock()
-			status := e.executionStatus[executionID]
-			nodeStatus, exists := status.NodeStatuses[nodeID]
+			nodeStatus, exists := record.endNode(nodeID, "completed", nil)
 			if exists {
-				nodeStatus.Status = "completed"
-				nodeStatus.EndTime = time.Now()
-				status.NodeStatuses[nodeID] = nodeStatus
+				e.observeNode(nodeStatus)
 			}
-			e.mutex.Unlock()
 
 			e.EmitEvent(ExecutionEvent{
 				Type:      EventNodeCompleted,
@@ -703,16 +1113,9 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 			})
 		},
 		OnNodeError: func(nodeID string, err error) {
-			e.mutex.Lock()
-			status := e.executionStatus[executionID]
-			nodeStatus, exists := status.NodeStatuses[nodeID]
-			if exists {
-				nodeStatus.Status = "error"
-				nodeStatus.Error = err
-				nodeStatus.EndTime = time.Now()
-				status.NodeStatuses[nodeID] = nodeStatus
+			if nodeStatus, exists := record.endNode(nodeID, "error", err); exists {
+				e.observeNode(nodeStatus)
 			}
-			e.mutex.Unlock()
 
 			e.EmitEvent(ExecutionEvent{
 				Type:      EventNodeError,
@@ -728,7 +1131,7 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 			e.debugManager.StoreNodeOutputValue(executionID, nodeID, pinName, value)
 
 			// Find connections from this output pin
-			for _, conn := range bp.GetNodeOutputConnections(nodeID) {
+			for _, conn := range plan.OutputConnections(nodeID) {
 				if conn.SourcePinID == pinName && conn.ConnectionType == "data" {
 					// Emit value produced event
 					e.EmitEvent(ExecutionEvent{
@@ -757,6 +1160,10 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 				},
 			})
 		},
+		OnVariableChange: func(name string, value interface{}) {
+			// Update watch expressions on the variable
+			e.debugManager.RecordVariableChange(executionID, name, value)
+		},
 	}
 
 	// Select execution method based on mode
@@ -764,18 +1171,33 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 	executionMode := e.GetExecutionMode()
 
 	if executionMode == ModeActor {
-		err = e.executeWithActorSystem(bp, executionID, entryPoints, variables)
+		err = e.executeWithActorSystem(bp, executionID, entryPoints, variables, hooks)
 	} else {
-		err = e.executeWithStandardEngine(bp, executionID, entryPoints, variables)
+		err = e.executeWithStandardEngine(bp, executionID, entryPoints, variables, hooks)
 	}
 
+	// Events recorded during the original execution are dispatched once the main flow is done
+	if err == nil {
+		err = e.replayEvents(bp, executionID)
+	}
+	result.Recoveries = e.recovery(executionID).recoveries()
+	result.MailboxOverflows = e.overflowState(executionID).list()
+
+	// An exceeded budget is what the execution fails with, whichever node hit it
+	budget := e.budget(executionID)
+	if budgetErr := budget.exhausted(); budgetErr != nil {
+		err = budgetErr
+	}
+	result.Budget = budget.usage()
+
 	// Handle execution result
 	if err != nil {
 		// Update execution status
-		e.mutex.Lock()
-		status.Status = "failed"
-		status.EndTime = time.Now()
-		e.mutex.Unlock()
+		endTime := time.Now()
+		record.update(func(status *ExecutionStatus) {
+			status.Status = "failed"
+			status.EndTime = endTime
+		})
 
 		// Emit execution end event
 		e.EmitEvent(ExecutionEvent{
@@ -794,10 +1216,11 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 		result.EndTime = time.Now()
 	} else {
 		// Update execution status
-		e.mutex.Lock()
-		status.Status = "completed"
-		status.EndTime = time.Now()
-		e.mutex.Unlock()
+		endTime := time.Now()
+		record.update(func(status *ExecutionStatus) {
+			status.Status = "completed"
+			status.EndTime = endTime
+		})
 
 		// Get node results from debug manager
 		nodeResults := make(map[string]map[string]interface{})
@@ -815,7 +1238,7 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 				"blueprintID": blueprintID,
 				"executionID": executionID,
 				"success":     true,
-				"duration":    time.Since(status.StartTime).String(),
+				"duration":    time.Since(startTime).String(),
 			},
 		})
 
@@ -827,48 +1250,18 @@ func (e *ExecutionEngine) Execute(bp *blueprint.Blueprint, executionID string, i
 	return result, err
 }
 
-// Pre-process variable nodes before execution
-func (e *ExecutionEngine) processVariableNodes(bp *blueprint.Blueprint, executionID string, variables map[string]types.Value) error {
-	// Find all variable get and set nodes
-	var setterNodes []string
-	var getterNodes []string
-
-	for _, nodeConfig := range bp.Nodes {
-		// Check if this is a variable node (get or set)
-		if strings.HasPrefix(nodeConfig.Type, "set-variable-") {
-			setterNodes = append(setterNodes, nodeConfig.ID)
-		} else if strings.HasPrefix(nodeConfig.Type, "get-variable-") {
-			getterNodes = append(getterNodes, nodeConfig.ID)
-		}
-	}
-
-	// Process set nodes first - to ensure variables are initialized
-	for _, nodeID := range setterNodes {
-		if err := e.executeVariableNode(nodeID, bp, executionID, variables); err != nil {
-			return fmt.Errorf("error processing setter node %s: %w", nodeID, err)
-		}
-	}
-
-	// Process get nodes second - to ensure they have the latest values
-	for _, nodeID := range getterNodes {
-		if err := e.executeVariableNode(nodeID, bp, executionID, variables); err != nil {
-			return fmt.Errorf("error processing getter node %s: %w", nodeID, err)
-		}
-	}
-
-	return nil
-}
-
-// Special execution for variable nodes that don't participate in execution flow
-func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Blueprint, executionID string, variables map[string]types.Value) error {
+// Special execution for variable nodes that don't participate in execution flow. Changed is
+// called with the name of every variable the node sets, if not nil.
+func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Blueprint, executionID string, variables map[string]types.Value, changed func(name string)) error {
 	// Find the node in the blueprint
-	nodeConfig := bp.FindNode(nodeID)
+	plan := e.planFor(bp)
+	nodeConfig := plan.Node(nodeID)
 	if nodeConfig == nil {
 		return fmt.Errorf("node not found: %s", nodeID)
 	}
 
 	// Get the node factory
-	factory, exists := e.nodeRegistry[nodeConfig.Type]
+	factory, exists := e.nodeFactory(bp.ID, executionID, nodeConfig)
 	if !exists {
 		return fmt.Errorf("node type not registered: %s", nodeConfig.Type)
 	}
@@ -877,11 +1270,11 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 	nodeInstance := factory()
 
 	// Create logger for this node
-	nodeLogger := e.logger
-	nodeLogger.Opts(map[string]interface{}{"nodeId": nodeID})
+	recording := e.currentHooks()
+	nodeLogger := newNodeLogger(e.logger, executionID, nodeConfig, recording.OnAny)
 
 	// Get all input connections for this node
-	inputConnections := bp.GetNodeInputConnections(nodeID)
+	inputConnections := plan.InputConnections(nodeID)
 
 	// Prepare input values
 	inputValues := make(map[string]types.Value)
@@ -897,56 +1290,18 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 			// Try to get the value from debug manager
 			if outputValue, exists := e.debugManager.GetNodeOutputValue(executionID, sourceNodeID, sourcePinID); exists {
 				// Convert to Value type
-				var pinType *types.PinType
-				switch outputValue.(type) {
-				case string:
-					pinType = types.PinTypes.String
-				case float64, int:
-					pinType = types.PinTypes.Number
-				case bool:
-					pinType = types.PinTypes.Boolean
-				case map[string]interface{}:
-					pinType = types.PinTypes.Object
-				case []interface{}:
-					pinType = types.PinTypes.Array
-				default:
-					pinType = types.PinTypes.Any
-				}
-				inputValues[targetPinID] = types.NewValue(pinType, outputValue)
+				inputValues[targetPinID] = types.NewValue(types.InferPinType(outputValue), outputValue)
 			} else {
 				// Try to execute the source node if it's a constant or another data node
-				sourceNodeConfig := bp.FindNode(sourceNodeID)
+				sourceNodeConfig := plan.Node(sourceNodeID)
 				if sourceNodeConfig != nil {
 					// Check if this is a constant or another non-executable node
-					isDataNode := false
-					nodeType := sourceNodeConfig.Type
-					if strings.HasPrefix(nodeType, "constant-") ||
-						strings.HasPrefix(nodeType, "get-variable-") ||
-						strings.HasPrefix(nodeType, "set-variable-") {
-						isDataNode = true
-					}
-
-					if isDataNode {
+					if isPreprocessedNode(sourceNodeConfig.Type) {
 						// Execute this node first
-						if err := e.executeVariableNode(sourceNodeID, bp, executionID, variables); err == nil {
+						if err := e.executeVariableNode(sourceNodeID, bp, executionID, variables, changed); err == nil {
 							// Now try to get the output value again
 							if outputValue, exists := e.debugManager.GetNodeOutputValue(executionID, sourceNodeID, sourcePinID); exists {
-								var pinType *types.PinType
-								switch outputValue.(type) {
-								case string:
-									pinType = types.PinTypes.String
-								case float64, int:
-									pinType = types.PinTypes.Number
-								case bool:
-									pinType = types.PinTypes.Boolean
-								case map[string]interface{}:
-									pinType = types.PinTypes.Object
-								case []interface{}:
-									pinType = types.PinTypes.Array
-								default:
-									pinType = types.PinTypes.Any
-								}
-								inputValues[targetPinID] = types.NewValue(pinType, outputValue)
+								inputValues[targetPinID] = types.NewValue(types.InferPinType(outputValue), outputValue)
 							}
 						}
 					}
@@ -958,8 +1313,8 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 	// Create execution hooks
 	hooks := &node.ExecutionHooks{
 		OnNodeStart: func(nodeID, nodeType string) {
-			if e.OnAnyHook != nil {
-				anyErr := e.OnAnyHook(context.Background(), executionID, nodeID, "info", string(EventNodeStarted), map[string]interface{}{
+			if recording.OnAny != nil {
+				anyErr := recording.OnAny(context.Background(), executionID, nodeID, "info", string(EventNodeStarted), map[string]interface{}{
 					"nodeType":  nodeType,
 					"timestamp": time.Now(),
 				})
@@ -967,13 +1322,13 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 			}
 
 			// Record node execution with inputs
-			if e.OnNodeExecutionHook != nil {
+			if recording.OnNodeExecution != nil {
 				inputMap := make(map[string]interface{})
 				for pinID, value := range inputValues {
 					inputMap[pinID] = value.RawValue
 				}
 
-				err := e.OnNodeExecutionHook(context.Background(), executionID, nodeID, nodeType, "executing", inputMap, nil)
+				err := recording.OnNodeExecution(context.Background(), executionID, nodeID, nodeType, "executing", inputMap, nil)
 				if err != nil {
 					slog.Debug("[DEBUG] Error recording node execution", slog.Any("error", err))
 				}
@@ -989,8 +1344,8 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 			})
 		},
 		OnNodeComplete: func(nodeID, nodeType string) {
-			if e.OnAnyHook != nil {
-				anyErr := e.OnAnyHook(context.Background(), executionID, nodeID, "info", string(EventNodeCompleted), map[string]interface{}{
+			if recording.OnAny != nil {
+				anyErr := recording.OnAny(context.Background(), executionID, nodeID, "info", string(EventNodeCompleted), map[string]interface{}{
 					"nodeType":  nodeType,
 					"timestamp": time.Now(),
 				})
@@ -998,11 +1353,11 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 			}
 
 			// Record node execution with outputs
-			if e.OnNodeExecutionHook != nil {
+			if recording.OnNodeExecution != nil {
 				// Collect output values
 				outputMap, _ := e.debugManager.GetNodeDebugData(executionID, nodeID)
 
-				err := e.OnNodeExecutionHook(context.Background(), executionID, nodeID, nodeType, "completed", nil, outputMap)
+				err := recording.OnNodeExecution(context.Background(), executionID, nodeID, nodeType, "completed", nil, outputMap)
 				if err != nil {
 					slog.Debug("[DEBUG] Error recording node execution completion", slog.Any("error", err))
 				}
@@ -1018,8 +1373,8 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 			})
 		},
 		OnNodeError: func(nodeID string, err error) {
-			if e.OnAnyHook != nil {
-				anyErr := e.OnAnyHook(context.Background(), executionID, nodeID, "error", string(EventNodeError), map[string]interface{}{
+			if recording.OnAny != nil {
+				anyErr := recording.OnAny(context.Background(), executionID, nodeID, "error", string(EventNodeError), map[string]interface{}{
 					"error": err.Error(),
 				})
 				slog.Debug("[DEBUG] An error caught on any hook", slog.Any("error", anyErr))
@@ -1038,8 +1393,8 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 			// Store the output value in debug manager
 			e.debugManager.StoreNodeOutputValue(executionID, nodeID, pinName, value)
 
-			if e.OnAnyHook != nil {
-				anyErr := e.OnAnyHook(context.Background(), executionID, nodeID, "debug", string(EventValueProduced), map[string]interface{}{
+			if recording.OnAny != nil {
+				anyErr := recording.OnAny(context.Background(), executionID, nodeID, "debug", string(EventValueProduced), map[string]interface{}{
 					"pinId": pinName,
 					"value": value,
 				})
@@ -1057,6 +1412,11 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 				},
 			})
 		},
+		OnVariableChange: func(name string, value interface{}) {
+			if changed != nil {
+				changed(name)
+			}
+		},
 	}
 
 	// Custom function that does nothing since these nodes don't activate flow
@@ -1075,7 +1435,7 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 		nodeLogger,
 		hooks,
 		dummyActivateFlow,
-		context.Background(), // Add context.Context argument
+		e.executionContext(executionID), // Context of the execution for outbound calls
 		e.extensions.ContextManager.GetRepoFactory(), // Pass repoFactory using getter
 	)
 
@@ -1107,90 +1467,84 @@ func (e *ExecutionEngine) executeVariableNode(nodeID string, bp *blueprint.Bluep
 }
 
 // executeWithActorSystem executes a blueprint using the actor system
-func (e *ExecutionEngine) executeWithActorSystem(bp *blueprint.Blueprint, executionID string, entryPoints []string, variables map[string]types.Value) error {
+func (e *ExecutionEngine) executeWithActorSystem(bp *blueprint.Blueprint, executionID string, entryPoints []string, variables map[string]types.Value, hooks *node.ExecutionHooks) error {
 	// Create an actor system for this execution
+	recording := e.currentHooks()
 	actorSystem, err := NewActorSystem(
 		e.GetExtensions().GetContextManager(),
 		executionID,
 		bp,
-		e.nodeRegistry,
+		e.planFor(bp),
+		e.nodeFactories(),
 		e.logger,
-		e.listeners,
+		e.executionListeners(),
 		e.debugManager,
 		variables,
-		e.hooks,
-		e.OnNodeExecutionHook, // Pass the node execution hook
-		e.OnAnyHook,           // Pass the any hook
+		hooks,
+		recording.OnNodeExecution, // Pass the node execution hook
+		recording.OnAny,           // Pass the any hook
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create actor system: %w", err)
 	}
-
-	// Initialize actor system
+	actorSystem.replay = e.replaySession(executionID)
+	actorSystem.profile = e.profileSession(executionID)
+	actorSystem.overrides = e.nodeOverrides(executionID)
+	actorSystem.blueprintTypes = blueprintNodeTypes(keyOf(bp))
+	actorSystem.errorPolicy = e.errorPolicy(executionID)
+	actorSystem.recovery = e.recovery(executionID)
+	actorSystem.joins = e.joinState(executionID)
+	actorSystem.timers = e.timerService()
+	actorSystem.limits = e.getNodeLimits()
+	actorSystem.mailboxes = e.getMailboxPolicies()
+	actorSystem.overflows = e.overflowState(executionID)
+	actorSystem.budget = e.budget(executionID)
+	actorSystem.ctx = e.executionContext(executionID)
+	actorSystem.panics = e.reportPanic
+
+	// Initialize actor system; execute already preprocessed the variable nodes
 	if err := actorSystem.Start(bp); err != nil {
 		return fmt.Errorf("failed to start actor system: %w", err)
 	}
 
-	if err := e.processVariableNodes(bp, executionID, variables); err != nil {
-		// Emit execution end event
-		e.EmitEvent(ExecutionEvent{
-			Type:      EventExecutionEnd,
-			Timestamp: time.Now(),
-			Data: map[string]interface{}{
-				"blueprintID":  bp.ID,
-				"executionID":  executionID,
-				"success":      false,
-				"errorMessage": err.Error(),
-			},
-		})
-
-		return err
-	}
-
 	// Execute the blueprint
 	if err := actorSystem.Execute(entryPoints); err != nil {
 		return fmt.Errorf("actor system execution failed: %w", err)
 	}
 
-	// Wait for completion with timeout (30 seconds)
-	if !actorSystem.Wait(30 * time.Second) {
+	// Wait for completion with timeout; long-running executions wait until they're cancelled
+	e.mutex.RLock()
+	timeout := e.actorTimeout
+	e.mutex.RUnlock()
+	if e.isLongRunning(executionID) {
+		timeout = 0
+	}
+	if !actorSystem.Wait(timeout) {
 		actorSystem.Stop()
+		if bpErr := executionCancelled(actorSystem.ctx, "", bp.ID, executionID); bpErr != nil {
+			return bpErr
+		}
 		return fmt.Errorf("actor system execution timed out")
 	}
 
 	// Get node statuses from actor system
-	e.mutex.Lock()
-	status := e.executionStatus[executionID]
-	status.NodeStatuses = actorSystem.GetNodesStatus()
-	e.mutex.Unlock()
+	nodeStatuses := actorSystem.GetNodesStatus()
+	e.executions.update(executionID, func(status *ExecutionStatus) {
+		status.NodeStatuses = nodeStatuses
+	})
 
 	// Clean up resources
 	actorSystem.Stop()
 
-	return nil
-}
-
-// executeWithStandardEngine executes a blueprint using the standard engine
-func (e *ExecutionEngine) executeWithStandardEngine(bp *blueprint.Blueprint, executionID string, entryPoints []string, variables map[string]types.Value) error {
-	// Create hooks for tracking execution
-
-	// Process variables first to ensure they're available to all nodes
-	if err := e.processVariableNodes(bp, executionID, variables); err != nil {
-		// Emit execution end event
-		e.EmitEvent(ExecutionEvent{
-			Type:      EventExecutionEnd,
-			Timestamp: time.Now(),
-			Data: map[string]interface{}{
-				"blueprintID":  bp.ID,
-				"executionID":  executionID,
-				"success":      false,
-				"errorMessage": err.Error(),
-			},
-		})
-
+	// A node that used up its actor restarts fails the execution whatever the error policy
+	if err := actorSystem.failed(); err != nil {
 		return err
 	}
+	return actorSystem.errorPolicy.result()
+}
 
+// executeWithStandardEngine executes a blueprint using the standard engine
+func (e *ExecutionEngine) executeWithStandardEngine(bp *blueprint.Blueprint, executionID string, entryPoints []string, variables map[string]types.Value, hooks *node.ExecutionHooks) error {
 	// Process each entry point
 	wg := sync.WaitGroup{}
 	errors := make(chan error, len(entryPoints))
@@ -1200,8 +1554,8 @@ func (e *ExecutionEngine) executeWithStandardEngine(bp *blueprint.Blueprint, exe
 
 		go func(currentNodeID string) { // Pass nodeID as argument
 			defer wg.Done()
-			// Pass e.hooks and nil for triggerCtx in standard execution flow
-			if err := e.executeNode(currentNodeID, bp, bp.ID, executionID, variables, e.hooks, nil); err != nil { // Pass e.hooks and nil triggerCtx
+			// Pass the hooks of the execution and nil for triggerCtx in standard execution flow
+			if err := e.executeNode(currentNodeID, bp, bp.ID, executionID, variables, hooks, nil, nil); err != nil { // Pass hooks and nil triggerCtx
 				errors <- err
 			}
 		}(nodeID) // Pass nodeID to the goroutine
@@ -1219,6 +1573,11 @@ func (e *ExecutionEngine) executeWithStandardEngine(bp *blueprint.Blueprint, exe
 		lastError = err
 	}
 
+	// Errors routed to the error handler don't fail the execution when the other branches continue
+	if err := e.errorPolicy(executionID).result(); err != nil {
+		return err
+	}
+
 	if errorCount > 0 {
 		return lastError
 	}
@@ -1245,17 +1604,42 @@ func (e *ExecutionEngine) createGenericActivateFlow(
 	}
 }
 
-func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, blueprintID, executionID string, variables map[string]types.Value, hooks *node.ExecutionHooks, triggerCtx *core.EventHandlerContext) error {
+func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, blueprintID, executionID string, variables map[string]types.Value, hooks *node.ExecutionHooks, triggerCtx *core.EventHandlerContext, scope *executionScope) error {
+	// A failed execution doesn't start new nodes unless the error policy lets the other branches continue
+	if e.errorPolicy(executionID).stopped(nodeID) {
+		return nil
+	}
+	recording := e.currentHooks()
+
 	// Find the node in the blueprint
-	nodeConfig := bp.FindNode(nodeID)
+	plan := e.planFor(bp)
+	nodeConfig := plan.Node(nodeID)
 	if nodeConfig == nil {
 		return fmt.Errorf("node not found: %s", nodeID)
 	}
 
-	e.mutex.RLock()
-	factory, exists := e.nodeRegistry[nodeConfig.Type]
-	e.mutex.RUnlock()
+	// Comments only document the blueprint
+	if nodeConfig.IsAnnotation() {
+		return nil
+	}
+
+	// Nodes beyond the blueprint's budget abort the execution, bypassing the error policy
+	if bpErr := e.budget(executionID).charge(nodeID); bpErr != nil {
+		if hooks != nil && hooks.OnNodeError != nil {
+			hooks.OnNodeError(nodeID, bpErr)
+		}
+		return &propagatedError{bpErr}
+	}
 
+	// Nodes of a cancelled execution don't start, bypassing the error policy like the budget
+	if bpErr := executionCancelled(e.executionContext(executionID), nodeID, blueprintID, executionID); bpErr != nil {
+		if hooks != nil && hooks.OnNodeError != nil {
+			hooks.OnNodeError(nodeID, bpErr)
+		}
+		return &propagatedError{bpErr}
+	}
+
+	factory, exists := e.nodeFactory(blueprintID, executionID, nodeConfig)
 	if !exists {
 		return fmt.Errorf("node type not registered: %s", nodeConfig.Type)
 	}
@@ -1271,18 +1655,63 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 		}
 	}
 
+	if bpErr := configureNodePins(nodeInstance, nodeConfig, blueprintID, executionID); bpErr != nil {
+		return e.handleNodeError(nodeID, bpErr, bp, executionID, variables, hooks, scope)
+	}
+
+	limits, bpErr := e.getNodeLimits().forNode(nodeConfig, blueprintID, executionID)
+	if bpErr != nil {
+		return e.handleNodeError(nodeID, bpErr, bp, executionID, variables, hooks, scope)
+	}
+	sandbox := newNodeSandbox(limits, nodeID, blueprintID, executionID)
+	sandbox.onPanic = func(err *bperrors.BlueprintError) { e.reportPanic(crash.SourceNode, err) }
+	profile := e.profileSession(executionID)
+	sandbox.profiled = profile != nil
+
 	// Create execution context
 	// Collect input values from connected nodes
-	inputValues := e.preprocessInputs(bp, nodeID, executionID, variables)
+	inputValues, transformErr := e.preprocessInputs(bp, nodeID, executionID, variables, scope)
+	if transformErr != nil {
+		bpErr := transformErr.blueprintError(nodeID, blueprintID, executionID)
+		return e.handleNodeError(nodeID, bpErr, bp, executionID, variables, hooks, scope)
+	}
+
+	// Apply pin defaults and fail nodes missing a required input
+	var eventParams map[string]types.Value
+	if triggerCtx != nil {
+		eventParams = triggerCtx.Parameters
+	}
+	if bpErr := applyInputDefaults(nodeInstance, bp, nodeConfig, inputValues, eventParams); bpErr != nil {
+		return e.handleNodeError(nodeID, bpErr.WithBlueprintInfo(blueprintID, executionID), bp, executionID, variables, hooks, scope)
+	}
+
+	// Convert the inputs to the types declared by the node's pins
+	if err := coerceInputs(nodeID, nodeInstance, inputValues); err != nil {
+		bpErr := bperrors.Wrap(err, bperrors.ErrorTypeConnection, bperrors.ErrTypeMismatch, err.Error(), bperrors.SeverityHigh).
+			WithNodeInfo(nodeID, err.PinID).
+			WithBlueprintInfo(blueprintID, executionID)
+		return e.handleNodeError(nodeID, bpErr, bp, executionID, variables, hooks, scope)
+	}
+
+	// Rate limited nodes wait for their timer unless it's the timer that runs them
+	if !isTimerTrigger(triggerCtx) {
+		pass, err := passRateLimit(e.timerService(), nodeInstance, blueprintID, nodeID, executionID, inputValues)
+		if err != nil {
+			e.logger.Warn("Failed to persist node timer", map[string]interface{}{"nodeId": nodeID, "error": err.Error()})
+		}
+		if !pass {
+			return nil
+		}
+	}
 
 	// Record node execution with inputs
-	if e.OnNodeExecutionHook != nil {
+	if recording.OnNodeExecution != nil {
 		inputMap := make(map[string]interface{})
 		for pinID, value := range inputValues {
 			inputMap[pinID] = value.RawValue
 		}
 
-		err := e.OnNodeExecutionHook(context.Background(), executionID, nodeID, nodeConfig.Type, "executing", inputMap, nil)
+		err := recording.OnNodeExecution(context.Background(), executionID, nodeID, nodeConfig.Type, "executing", inputMap, nil)
 		if err != nil {
 			slog.Debug("[DEBUG] Error recording node execution", slog.Any("error", err))
 		}
@@ -1290,23 +1719,49 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 
 	// Create a function to activate output flows
 	activateFlowFn := func(ctx *engineext.DefaultExecutionContext, nodeID, pinID string) error {
+		// Abandoned nodes can't start other nodes, and the time taken by the downstream nodes
+		// doesn't count against the node's time limit
+		if err := sandbox.pause(); err != nil {
+			return err
+		}
+		defer sandbox.resume()
+
+		// Oversized outputs never reach the downstream nodes
+		if err := sandbox.checkOutputs(ctx.GetAllOutputs()); err != nil {
+			return err
+		}
+
 		// Store all outputs before activating flows
 		for _, pin := range nodeInstance.GetOutputPins() {
 			if value, exists := ctx.GetOutputValue(pin.ID); exists {
-				e.debugManager.StoreNodeOutputValue(executionID, nodeID, pin.ID, value.RawValue)
+				e.storeOutput(scope, executionID, nodeID, pin.ID, value.RawValue)
 			}
 		}
 
 		// Find connections from this output pin
-		outputConnections := bp.GetNodeOutputConnections(nodeID)
+		outputConnections := plan.OutputConnections(nodeID)
 		for _, conn := range outputConnections {
 			if conn.ConnectionType == "execution" && conn.SourcePinID == pinID {
+				holds, err := conditionHolds(conn, ctx.GetAllOutputs())
+				if err != nil {
+					return err.blueprintError(nodeID, blueprintID, executionID)
+				}
+				if !holds {
+					continue
+				}
+
 				targetNodeID := conn.TargetNodeID
+				e.debugManager.RecordActivation(executionID, nodeID, pinID, targetNodeID, conn.TargetPinID)
+
+				// Join nodes only run once enough of their branches arrived
+				if !e.joinArrived(bp, executionID, targetNodeID, conn.TargetPinID) {
+					continue
+				}
 
 				// Execute the target node
 				// Pass the correct 'hooks' variable down
 				// Pass nil for triggerCtx when activating flow normally
-				if err := e.executeNode(targetNodeID, bp, blueprintID, executionID, variables, hooks, nil); err != nil { // Pass hooks and nil triggerCtx
+				if err := e.executeNode(targetNodeID, bp, blueprintID, executionID, variables, hooks, nil, scope); err != nil { // Pass hooks and nil triggerCtx
 					return err
 				}
 			}
@@ -1315,6 +1770,7 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 	}
 
 	// --- Create Execution Context using ContextManager ---
+	nodeLog := newNodeLogger(e.logger, executionID, nodeConfig, recording.OnAny)
 	var ctx node.ExecutionContext
 	extensions := e.GetExtensions()
 	contextManager := (*engineext.ContextManager)(nil) // Initialize to nil
@@ -1331,9 +1787,9 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 		e.logger.Error("ContextManager not available in engine extensions, cannot create proper context", map[string]interface{}{"nodeId": nodeID})
 		// Fallback to a very basic context that likely won't work for complex nodes
 		// Ensure NewExecutionContext exists and has the correct signature
-		// Pass the 'hooks' parameter from executeNode and context.Background()
+		// Pass the 'hooks' parameter from executeNode and the context of the execution
 		// Also pass the repoFactory from the extensions using the getter
-		ctx = engineext.NewExecutionContext(nodeID, nodeConfig.Type, blueprintID, executionID, inputValues, variables, e.logger, hooks, activateFlowFn, context.Background(), e.extensions.ContextManager.GetRepoFactory()) // Use getter
+		ctx = engineext.NewExecutionContext(nodeID, nodeConfig.Type, blueprintID, executionID, inputValues, variables, nodeLog, hooks, activateFlowFn, e.executionContext(executionID), e.extensions.ContextManager.GetRepoFactory()) // Use getter
 	} else {
 		// Use the ContextManager to create the appropriate context
 		if triggerCtx != nil {
@@ -1356,7 +1812,7 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 				executionID, // Use execution ID from trigger? Or main execution? Needs clarification. Using main for now.
 				eventInputs, // Pass combined inputs
 				variables,
-				e.logger,
+				nodeLog,
 				hooks,          // Pass the 'hooks' parameter from executeNode
 				activateFlowFn, // Pass the correct activateFlowFn
 				triggerCtx,
@@ -1372,23 +1828,46 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 				executionID,
 				inputValues,
 				variables,
-				e.logger,
+				nodeLog,
 				hooks,          // Pass the 'hooks' parameter from executeNode
 				activateFlowFn, // Pass the correct activateFlowFn
 			)
 		}
 	}
 
+	// Outputs of upstream nodes are resolved from those the debug manager stored
+	if baseCtx, ok := engineext.GetExtendedContext(ctx).(*engineext.DefaultExecutionContext); ok {
+		baseCtx.SetUpstreamResolver(func(sourceNodeID, pinID string) (types.Value, bool) {
+			return e.upstreamOutput(bp, executionID, nodeID, sourceNodeID, pinID, scope)
+		})
+		baseCtx.SetScopeRunner(func(pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error) {
+			// The time taken by the scope's nodes doesn't count against the node's time limit
+			if err := sandbox.pause(); err != nil {
+				return types.Value{}, false, err
+			}
+			defer sandbox.resume()
+			return e.runScope(nodeID, pinID, resultPin, outputs, scope, bp, executionID, variables, hooks)
+		})
+	}
+
 	// Notify node start
-	if e.hooks != nil && e.hooks.OnNodeStart != nil {
-		e.hooks.OnNodeStart(nodeID, nodeConfig.Type)
+	if hooks != nil && hooks.OnNodeStart != nil {
+		hooks.OnNodeStart(nodeID, nodeConfig.Type)
 	}
 
 	//ctx.SaveData("node.properties", actor.properties)
 	ctx.SaveData("node.inputPins", nodeInstance.GetInputPins())
 
 	// Execute the node
-	err := nodeInstance.Execute(ctx)
+	span := e.debugManager.BeginNodeSpan(executionID, nodeID, nodeConfig.Type)
+	err := sandbox.run(func() error {
+		return e.replaySession(executionID).executeOrReplay(nodeID, nodeInstance, ctx)
+	})
+	if extCtx := engineext.GetExtendedContext(ctx); err == nil && extCtx != nil {
+		err = sandbox.checkOutputs(extCtx.GetAllOutputs())
+	}
+	e.debugManager.EndNodeSpan(executionID, span, err)
+	profile.record(span, nodeConfig.Type, sandbox)
 
 	// Collect output values
 	outputMap := make(map[string]interface{})
@@ -1403,8 +1882,8 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 	}
 
 	// Record node execution with outputs
-	if e.OnNodeExecutionHook != nil {
-		err := e.OnNodeExecutionHook(context.Background(), executionID, nodeID, nodeConfig.Type, "completed", nil, outputMap)
+	if recording.OnNodeExecution != nil {
+		err := recording.OnNodeExecution(context.Background(), executionID, nodeID, nodeConfig.Type, "completed", nil, outputMap)
 		if err != nil {
 			slog.Debug("[DEBUG] Error recording node execution completion", slog.Any("error", err))
 		}
@@ -1412,10 +1891,13 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 
 	// Handle errors
 	if err != nil {
-		if e.hooks != nil && e.hooks.OnNodeError != nil {
-			e.hooks.OnNodeError(nodeID, err)
+		if hooks != nil && hooks.OnNodeError != nil {
+			hooks.OnNodeError(nodeID, err)
 		}
-		return err
+		if recovered, flowErr := e.recoverNodeError(nodeID, err, nodeInstance, ctx, bp, blueprintID, executionID, variables, hooks, triggerCtx, scope); recovered {
+			return flowErr
+		}
+		return e.handleNodeError(nodeID, err, bp, executionID, variables, hooks, scope)
 	}
 
 	// Store debug data
@@ -1442,22 +1924,82 @@ func (e *ExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint, bl
 	}
 
 	// Notify node completion
-	if e.hooks != nil && e.hooks.OnNodeComplete != nil {
-		e.hooks.OnNodeComplete(nodeID, nodeConfig.Type)
+	if hooks != nil && hooks.OnNodeComplete != nil {
+		hooks.OnNodeComplete(nodeID, nodeConfig.Type)
+	}
+
+	return nil
+}
+
+// configureNodePins declares the pins of a node with dynamic pins from the data of its
+// blueprint node
+func configureNodePins(nodeInstance node.Node, nodeConfig *blueprint.BlueprintNode, blueprintID, executionID string) *bperrors.BlueprintError {
+	if err := node.ConfigurePins(nodeInstance, nodeConfig.Data); err != nil {
+		return bperrors.Wrap(err, bperrors.ErrorTypeValidation, bperrors.ErrInvalidNodeConfiguration,
+			fmt.Sprintf("Invalid pin configuration: %v", err), bperrors.SeverityHigh).
+			WithNodeInfo(nodeConfig.ID, "").
+			WithBlueprintInfo(blueprintID, executionID)
 	}
+	return nil
+}
+
+// inputCoercionError reports an input value that doesn't match its pin type
+type inputCoercionError struct {
+	PinID string
+	Err   error
+}
+
+func (e *inputCoercionError) Error() string {
+	return fmt.Sprintf("input '%s': %v", e.PinID, e.Err)
+}
+
+func (e *inputCoercionError) Unwrap() error {
+	return e.Err
+}
+
+// coerceInputs converts input values in place to the types of the node's input pins
+// and claims input streams for the node
+func coerceInputs(nodeID string, nodeInstance node.Node, inputValues map[string]types.Value) *inputCoercionError {
+	for _, pin := range nodeInstance.GetInputPins() {
+		value, exists := inputValues[pin.ID]
+		if !exists {
+			continue
+		}
 
+		if stream, ok := value.RawValue.(*types.Stream); ok {
+			if err := stream.Claim(nodeID); err != nil {
+				return &inputCoercionError{PinID: pin.ID, Err: err}
+			}
+		}
+
+		coerced, err := types.Coerce(value, pin.Type)
+		if err != nil {
+			return &inputCoercionError{PinID: pin.ID, Err: err}
+		}
+		inputValues[pin.ID] = coerced
+	}
 	return nil
 }
 
-func (e *ExecutionEngine) preprocessInputs(bp *blueprint.Blueprint, nodeID, executionID string, variables map[string]types.Value) map[string]types.Value {
+// preprocessInputs collects the input values of a node from its data connections and
+// applies the transform expressions of the connections
+func (e *ExecutionEngine) preprocessInputs(bp *blueprint.Blueprint, nodeID, executionID string, variables map[string]types.Value, scope *executionScope) (map[string]types.Value, *connectionExpressionError) {
 	inputValues := make(map[string]types.Value)
 
+	// Pins with a fan-in policy take their value from every connection once all are read
+	plan := e.planFor(bp)
+	var policies map[string]string
+	if nodeConfig := plan.Node(nodeID); nodeConfig != nil {
+		policies = nodeConfig.GetFanInPolicies()
+	}
+	fanIn := make(fanInValues)
+
 	// Get input connections for this node
-	inputConnections := bp.GetNodeInputConnections(nodeID)
+	inputConnections := plan.InputConnections(nodeID)
 
 	for _, conn := range inputConnections {
 		if conn.ConnectionType == "data" {
-			sourceNode := bp.FindNode(conn.SourceNodeID)
+			sourceNode := plan.Node(conn.SourceNodeID)
 			if sourceNode != nil && strings.HasPrefix(sourceNode.Type, "get-variable-") {
 				// This is a connection from a variable getter node
 				// Extract variable name from the node type
@@ -1465,11 +2007,19 @@ func (e *ExecutionEngine) preprocessInputs(bp *blueprint.Blueprint, nodeID, exec
 
 				// Try to get the variable value
 				if varValue, exists := variables[varName]; exists {
-					// Add it directly to input values
-					inputValues[conn.TargetPinID] = varValue
-
 					// Also ensure the value is stored in debug manager for the source node
 					e.debugManager.StoreNodeOutputValue(executionID, conn.SourceNodeID, conn.SourcePinID, varValue.RawValue)
+
+					transformed, err := transformInput(conn, varValue)
+					if err != nil {
+						return nil, err
+					}
+					e.recordDataFlow(executionID, conn, varValue, transformed)
+					// Add it directly to input values
+					inputValues[conn.TargetPinID] = transformed
+					if _, exists := policies[conn.TargetPinID]; exists {
+						fanIn.set(conn.TargetPinID, conn.SourceNodeID, conn.SourcePinID, transformed)
+					}
 				}
 			}
 		}
@@ -1484,9 +2034,18 @@ func (e *ExecutionEngine) preprocessInputs(bp *blueprint.Blueprint, nodeID, exec
 			targetPinID := conn.TargetPinID
 
 			// Check if we have a result for this pin
-			if nodeResults, ok := e.debugManager.GetNodeOutputValue(executionID, sourceNodeID, sourcePinID); ok {
+			if nodeResults, ok := e.outputValue(scope, executionID, sourceNodeID, sourcePinID); ok {
 				// Convert to Value
-				inputValues[targetPinID] = types.NewValue(types.PinTypes.Any, nodeResults)
+				produced := types.NewValue(types.PinTypes.Any, nodeResults)
+				transformed, err := transformInput(conn, produced)
+				if err != nil {
+					return nil, err
+				}
+				e.recordDataFlow(executionID, conn, produced, transformed)
+				inputValues[targetPinID] = transformed
+				if _, exists := policies[targetPinID]; exists {
+					fanIn.set(targetPinID, sourceNodeID, sourcePinID, transformed)
+				}
 
 				// Emit value consumed event
 				e.EmitEvent(ExecutionEvent{
@@ -1497,12 +2056,18 @@ func (e *ExecutionEngine) preprocessInputs(bp *blueprint.Blueprint, nodeID, exec
 						"sourceNodeID": sourceNodeID,
 						"sourcePinID":  sourcePinID,
 						"targetPinID":  targetPinID,
-						"value":        nodeResults,
+						"value":        transformed.RawValue,
 					},
 				})
 			}
 		}
 	}
 
-	return inputValues
+	for pinID, delivered := range fanIn {
+		if value, exists := resolveFanIn(bp, nodeID, pinID, policies[pinID], delivered); exists {
+			inputValues[pinID] = value
+		}
+	}
+
+	return inputValues, nil
 }
@@ -36,6 +36,7 @@ func NewErrorAwareEngine(baseEngine *ExecutionEngine) *ErrorAwareEngine {
 func (e *ErrorAwareEngine) Execute(bp *blueprint.Blueprint, executionID string, initialData map[string]types.Value) (map[string]interface{}, error) {
 	// Validate blueprint before execution
 	validator := bperrors.NewBlueprintValidator(e.ErrorManager)
+	validator.SetNodeFactoryLookup(e.BaseEngine.GetNodeFactory)
 	validationResult := validator.ValidateBlueprint(bp)
 
 	// Create extended execution info
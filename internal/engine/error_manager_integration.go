@@ -66,6 +66,7 @@ func NewErrorAwareExecutionEngine(engine *ExecutionEngine) *ErrorAwareExecutionE
 	errorManager := errors.NewErrorManager()
 	recoveryManager := errors.NewRecoveryManager(errorManager)
 	validator := errors.NewBlueprintValidator(errorManager)
+	validator.SetNodeFactoryLookup(engine.GetNodeFactory)
 
 	// Register error handlers
 	errorManager.RegisterErrorHandler(errors.ErrorTypeExecution, func(err *errors.BlueprintError) error {
@@ -208,6 +209,16 @@ func (e *ErrorAwareExecutionEngine) executeNode(nodeID string, bp *blueprint.Blu
 		outputConnections := bp.GetNodeOutputConnections(nodeID)
 		for _, conn := range outputConnections {
 			if conn.ConnectionType == "execution" && conn.SourcePinID == pinID {
+				holds, conditionErr := conditionHolds(conn, ctx.GetAllOutputs())
+				if conditionErr != nil {
+					err := conditionErr.blueprintError(nodeID, blueprintID, executionID)
+					e.errorManager.RecordError(executionID, err)
+					return err
+				}
+				if !holds {
+					continue
+				}
+
 				targetNodeID := conn.TargetNodeID
 
 				// Execute the target node with error handling
@@ -245,7 +256,7 @@ func (e *ErrorAwareExecutionEngine) executeNode(nodeID string, bp *blueprint.Blu
 	}
 
 	// Get the node factory
-	factory, exists := e.nodeRegistry[nodeConfig.Type]
+	factory, exists := e.nodeFactories()[nodeConfig.Type]
 	if !exists {
 		err := errors.New(
 			errors.ErrorTypeExecution,
@@ -260,6 +271,10 @@ func (e *ErrorAwareExecutionEngine) executeNode(nodeID string, bp *blueprint.Blu
 
 	// Create the node instance
 	nodeInstance := factory()
+	if err := configureNodePins(nodeInstance, nodeConfig, blueprintID, executionID); err != nil {
+		e.errorManager.RecordError(executionID, err)
+		return err
+	}
 
 	// Prepare input values with error handling
 	inputConnections := bp.GetNodeInputConnections(nodeID)
@@ -271,7 +286,12 @@ func (e *ErrorAwareExecutionEngine) executeNode(nodeID string, bp *blueprint.Blu
 
 			// Try to get value with error recovery
 			if outputs, ok := e.debugManager.GetNodeOutputValue(executionID, sourceNodeID, sourcePinID); ok {
-				value := types.NewValue(types.PinTypes.Any, outputs)
+				value, transformErr := transformInput(conn, types.NewValue(types.PinTypes.Any, outputs))
+				if transformErr != nil {
+					err := transformErr.blueprintError(nodeID, blueprintID, executionID)
+					e.errorManager.RecordError(executionID, err)
+					return err
+				}
 				// Define a helper method to set the input value
 				// This is a temporary workaround until proper methods are added
 				SetInputValue(ctx, targetPinID, value)
@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/core"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// errorPolicyState applies the error policy of a blueprint to the unhandled node errors of an execution
+type errorPolicyState struct {
+	mode        blueprint.ErrorMode
+	handlerID   string
+	handlerFlow map[string]bool // Nodes run by the error handler, whose errors aren't routed back to it
+	firstErr    error
+	unhandled   error
+	onError     func(nodeID string, err error) // Reports every unhandled node error, e.g. as a lifecycle event
+	mutex       sync.Mutex
+}
+
+// newErrorPolicyState resolves the error policy and the error handler flow of a blueprint
+func newErrorPolicyState(bp *blueprint.Blueprint) *errorPolicyState {
+	policy := bp.GetErrorPolicy()
+	state := &errorPolicyState{
+		mode:        policy.Mode,
+		handlerFlow: make(map[string]bool),
+	}
+
+	if policy.HandlerNodeID == "" || bp.FindNode(policy.HandlerNodeID) == nil {
+		return state
+	}
+	state.handlerID = policy.HandlerNodeID
+
+	// Every node reachable from the handler belongs to the error handling flow
+	pending := []string{policy.HandlerNodeID}
+	for len(pending) > 0 {
+		nodeID := pending[0]
+		pending = pending[1:]
+		if state.handlerFlow[nodeID] {
+			continue
+		}
+		state.handlerFlow[nodeID] = true
+
+		for _, conn := range bp.GetNodeOutputConnections(nodeID) {
+			if conn.ConnectionType == "execution" {
+				pending = append(pending, conn.TargetNodeID)
+			}
+		}
+	}
+
+	return state
+}
+
+// fail records the unhandled error of a node and reports whether it is routed to the error handler.
+// Errors of the error handling flow itself are never routed.
+func (s *errorPolicyState) fail(nodeID string, err error) bool {
+	if s == nil {
+		return false
+	}
+	if s.onError != nil {
+		s.onError(nodeID, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.firstErr == nil {
+		s.firstErr = err
+	}
+
+	routed := s.handlerID != "" && !s.handlerFlow[nodeID]
+	if !routed && s.unhandled == nil {
+		s.unhandled = err
+	}
+	return routed
+}
+
+// stopped reports whether a node is skipped because a fail-fast execution already failed.
+// The error handling flow still runs.
+func (s *errorPolicyState) stopped(nodeID string) bool {
+	if s == nil || s.mode != blueprint.ErrorModeFailFast {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.firstErr != nil && !s.handlerFlow[nodeID]
+}
+
+// continues reports whether the other branches keep running after a node error
+func (s *errorPolicyState) continues() bool {
+	return s != nil && s.mode == blueprint.ErrorModeContinue
+}
+
+// result returns the error the execution fails with: the first error when failing fast,
+// otherwise the first error that the error handler didn't receive
+func (s *errorPolicyState) result() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.mode == blueprint.ErrorModeContinue {
+		return s.unhandled
+	}
+	return s.firstErr
+}
+
+// propagatedError marks a node error that the failing node already handled (recovery, error policy),
+// so the upstream nodes it propagates through leave it alone
+type propagatedError struct {
+	error
+}
+
+func (e *propagatedError) Unwrap() error {
+	return e.error
+}
+
+// errorHandlerInputs returns the inputs of the error handler for the error of a node
+func errorHandlerInputs(blueprintID, executionID, nodeID string, err error) map[string]types.Value {
+	bpErr := bperrors.From(err)
+	if bpErr.NodeID == "" {
+		bpErr.NodeID = nodeID
+	}
+	if bpErr.ExecutionID == "" {
+		bpErr.WithBlueprintInfo(blueprintID, executionID)
+	}
+
+	return map[string]types.Value{
+		"error":  types.NewValue(types.PinTypes.Object, bpErr.PinValue()),
+		"nodeId": types.NewValue(types.PinTypes.String, nodeID),
+	}
+}
+
+// setErrorPolicy applies the error policy of a blueprint to an execution
+func (e *ExecutionEngine) setErrorPolicy(executionID string, bp *blueprint.Blueprint) {
+	state := newErrorPolicyState(bp)
+	state.onError = func(nodeID string, err error) {
+		e.dispatchNodeError(bp.ID, executionID, nodeID, err)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.errorPolicies[executionID] = state
+}
+
+// errorPolicy returns the error policy state of an execution, if any
+func (e *ExecutionEngine) errorPolicy(executionID string) *errorPolicyState {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.errorPolicies[executionID]
+}
+
+// clearErrorPolicy drops the error policy state of an execution
+func (e *ExecutionEngine) clearErrorPolicy(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.errorPolicies, executionID)
+}
+
+// handleNodeError applies the error policy of an execution to the unhandled error of a node in the
+// standard engine. It returns the error to propagate upstream, or nil if the other branches continue.
+// Errors in a child scope propagate to the scoped node instead.
+func (e *ExecutionEngine) handleNodeError(nodeID string, err error, bp *blueprint.Blueprint, executionID string, variables map[string]types.Value, hooks *node.ExecutionHooks, scope *executionScope) error {
+	var propagated *propagatedError
+	if errors.As(err, &propagated) {
+		return err
+	}
+	if scope != nil {
+		return &propagatedError{err}
+	}
+
+	policy := e.errorPolicy(executionID)
+	if policy == nil {
+		return &propagatedError{err}
+	}
+
+	if policy.fail(nodeID, err) {
+		triggerCtx := &core.EventHandlerContext{
+			EventID:     blueprint.ErrorHandlerNodeType,
+			Parameters:  errorHandlerInputs(bp.ID, executionID, nodeID, err),
+			SourceID:    nodeID,
+			BlueprintID: bp.ID,
+			ExecutionID: executionID,
+			HandlerID:   policy.handlerID,
+			Timestamp:   time.Now(),
+		}
+		if handlerErr := e.executeNode(policy.handlerID, bp, bp.ID, executionID, variables, hooks, triggerCtx, nil); handlerErr != nil {
+			e.logger.Error("Error handler failed", map[string]interface{}{"nodeId": nodeID, "handlerId": policy.handlerID, "error": handlerErr.Error()})
+		}
+	}
+
+	if policy.continues() {
+		return nil
+	}
+	return &propagatedError{err}
+}
+
+// handleNodeError applies the error policy of the execution to the unhandled error of a node in
+// the actor system, running the error handler with the error details if the error is routed to it
+func (s *ActorSystem) handleNodeError(nodeID string, err error) {
+	// Errors in a child scope are reported to the scoped node instead
+	if s.parent != nil {
+		s.recordScopeError(err)
+		return
+	}
+
+	// An execution out of budget fails without running its error handler
+	if s.budget.exhausted() != nil {
+		return
+	}
+	if !s.errorPolicy.fail(nodeID, err) {
+		return
+	}
+
+	s.mutex.RLock()
+	handler, exists := s.actors[s.errorPolicy.handlerID]
+	s.mutex.RUnlock()
+	if !exists {
+		return
+	}
+
+	// Concurrent errors must not interleave the inputs of the handler
+	s.errorMutex.Lock()
+	defer s.errorMutex.Unlock()
+
+	for pinID, value := range errorHandlerInputs(s.blueprintID, s.executionID, nodeID, err) {
+		handler.Send(NodeMessage{Type: "input", PinID: pinID, Value: value})
+	}
+	s.executeNode(handler.NodeID)
+}
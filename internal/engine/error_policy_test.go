@@ -0,0 +1,137 @@
+package engine_test
+
+import (
+	"sync"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// failingNode fails every time it runs, with an error no recovery strategy handles
+type failingNode struct {
+	node.BaseNode
+}
+
+func newFailingNode() node.Node {
+	return &failingNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{TypeID: "test-fail", Name: "Fail"},
+			Inputs:   []types.Pin{{ID: "exec", Name: "Execute", Type: types.PinTypes.Execution}},
+			Outputs:  []types.Pin{{ID: "then", Name: "Then", Type: types.PinTypes.Execution}},
+		},
+	}
+}
+
+func (n *failingNode) Execute(ctx node.ExecutionContext) error {
+	return bperrors.New(bperrors.ErrorTypeExecution, bperrors.ErrOperationFailed, "the node failed", bperrors.SeverityHigh)
+}
+
+// errorPolicyBlueprint starts a failing branch and a probe branch. With a handler, the error
+// handler passes the ID of the failing node to a second probe.
+func errorPolicyBlueprint(mode blueprint.ErrorMode, withHandler bool) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("error-policy", "Error Policy", "1.0.0")
+	bp.ErrorPolicy = &blueprint.ErrorPolicy{Mode: mode}
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "fail", Type: "test-fail"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "branch", Type: "branch-probe"})
+	connections := []blueprint.Connection{
+		{ID: "exec-fail", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "fail", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "exec-branch", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "branch", TargetPinID: "exec", ConnectionType: "execution"},
+	}
+
+	if withHandler {
+		bp.AddNode(blueprint.BlueprintNode{ID: "on-error", Type: blueprint.ErrorHandlerNodeType})
+		bp.AddNode(blueprint.BlueprintNode{ID: "handled", Type: "handler-probe"})
+		connections = append(connections,
+			blueprint.Connection{ID: "exec-handled", SourceNodeID: "on-error", SourcePinID: "then", TargetNodeID: "handled", TargetPinID: "exec", ConnectionType: "execution"},
+			blueprint.Connection{ID: "data-node", SourceNodeID: "on-error", SourcePinID: "nodeId", TargetNodeID: "handled", TargetPinID: "value", ConnectionType: "data"},
+		)
+	}
+
+	for _, conn := range connections {
+		bp.AddConnection(conn)
+	}
+	return bp
+}
+
+// registerErrorPolicyNodes registers the node types of errorPolicyBlueprint on a new engine
+func registerErrorPolicyNodes(branch, handled *sync.Map) {
+	registry.GetInstance().RegisterNodeType("test-fail", newFailingNode)
+	registry.GetInstance().RegisterNodeType("branch-probe", func() node.Node {
+		return newProbeNode(types.PinTypes.Any, branch)
+	})
+	registry.GetInstance().RegisterNodeType("handler-probe", func() node.Node {
+		return newProbeNode(types.PinTypes.String, handled)
+	})
+}
+
+func TestFailFastErrorPolicyFailsTheExecution(t *testing.T) {
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			var branch, handled sync.Map
+			flowEngine := newEngine(t, mode)
+			registerErrorPolicyNodes(&branch, &handled)
+			executionID := "fail-fast-" + string(mode)
+
+			result, err := flowEngine.Execute(errorPolicyBlueprint(blueprint.ErrorModeFailFast, true), executionID, map[string]types.Value{})
+			if err == nil || result.Success {
+				t.Fatal("expected the execution to fail with the node error")
+			}
+
+			// The error handler still runs after the execution failed
+			if value, exists := handled.Load(executionID); !exists || value.(types.Value).RawValue != "fail" {
+				t.Errorf("expected the error handler to receive the failing node, got %v", value)
+			}
+
+			// The standard engine runs the branches in order, so the second one is skipped
+			if _, exists := branch.Load(executionID); mode == engine.ModeStandard && exists {
+				t.Error("expected the branch after the failing node to be skipped")
+			}
+		})
+	}
+}
+
+func TestContinueErrorPolicyRunsTheOtherBranches(t *testing.T) {
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			var branch, handled sync.Map
+			flowEngine := newEngine(t, mode)
+			registerErrorPolicyNodes(&branch, &handled)
+			executionID := "continue-" + string(mode)
+
+			result, err := flowEngine.Execute(errorPolicyBlueprint(blueprint.ErrorModeContinue, true), executionID, map[string]types.Value{})
+			if err != nil || !result.Success {
+				t.Fatalf("expected the handled error not to fail the execution, got %v", err)
+			}
+			if _, exists := branch.Load(executionID); !exists {
+				t.Error("expected the other branch to run")
+			}
+			if value, exists := handled.Load(executionID); !exists || value.(types.Value).RawValue != "fail" {
+				t.Errorf("expected the error handler to receive the failing node, got %v", value)
+			}
+		})
+	}
+}
+
+func TestContinueErrorPolicyFailsWithoutAHandler(t *testing.T) {
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			var branch, handled sync.Map
+			flowEngine := newEngine(t, mode)
+			registerErrorPolicyNodes(&branch, &handled)
+			executionID := "unhandled-" + string(mode)
+
+			result, err := flowEngine.Execute(errorPolicyBlueprint(blueprint.ErrorModeContinue, false), executionID, map[string]types.Value{})
+			if err == nil || result.Success {
+				t.Fatal("expected the unhandled error to fail the execution")
+			}
+			if _, exists := branch.Load(executionID); !exists {
+				t.Error("expected the other branch to run before the execution failed")
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to the events of a listener that can't keep up
+type OverflowPolicy string
+
+const (
+	OverflowDrop     OverflowPolicy = "drop"     // New events are dropped while the queue is full
+	OverflowCoalesce OverflowPolicy = "coalesce" // Pending values of the same connection are replaced by newer ones; other events are dropped while the queue is full
+)
+
+// DefaultListenerQueueSize is the number of pending events buffered per listener
+const DefaultListenerQueueSize = 1024
+
+// DefaultValueBatchInterval is how often batched values are delivered to listeners that batch them
+const DefaultValueBatchInterval = 100 * time.Millisecond
+
+// ListenerOptions configures the event queue of a listener
+type ListenerOptions struct {
+	QueueSize int            `json:"queueSize"`
+	Policy    OverflowPolicy `json:"policy"`
+	// Values produced by a node within the interval are delivered as one values.batched
+	// event with the latest value of each pin, 0 to deliver every value
+	BatchInterval time.Duration `json:"batchInterval"`
+}
+
+// DefaultListenerOptions returns the options of listeners added without options
+func DefaultListenerOptions() ListenerOptions {
+	return ListenerOptions{
+		QueueSize: DefaultListenerQueueSize,
+		Policy:    OverflowCoalesce,
+	}
+}
+
+// ListenerStats describes the event delivery to a listener
+type ListenerStats struct {
+	Listener  string          `json:"listener"`
+	Options   ListenerOptions `json:"options"`
+	Pending   int             `json:"pending"`
+	Delivered int64           `json:"delivered"`
+	Coalesced int64           `json:"coalesced"`
+	Dropped   int64           `json:"dropped"`
+	Batched   int64           `json:"batched"`
+}
+
+// queuedListener delivers execution events to a listener from its own goroutine, so a
+// slow listener doesn't stall the execution that emits them
+type queuedListener struct {
+	listener ExecutionListener
+	options  ListenerOptions
+	pending  []ExecutionEvent
+	keys     map[string]int // Coalescing key -> index of the pending event
+	signal   chan struct{}
+	mutex    sync.Mutex
+
+	// Values waiting for the next batch, except those of nodes debugged in full fidelity
+	batches      map[string]*valueBatch // Node ID -> values produced since the last batch
+	fullFidelity func(nodeID string) bool
+	batchMutex   sync.Mutex
+
+	delivered atomic.Int64
+	coalesced atomic.Int64
+	dropped   atomic.Int64
+	batched   atomic.Int64
+}
+
+// valueBatch collects the latest value of each pin of a node between two batches
+type valueBatch struct {
+	values  map[string]map[string]interface{} // Pin key -> data of the latest value event
+	order   []string                          // Pin keys in the order their first value arrived
+	updates int
+	last    time.Time
+}
+
+// newQueuedListener starts the delivery of events to a listener. Nodes for which
+// fullFidelity is true have their values delivered without batching or coalescing.
+func newQueuedListener(listener ExecutionListener, options ListenerOptions, fullFidelity func(nodeID string) bool) *queuedListener {
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultListenerQueueSize
+	}
+	if options.Policy == "" {
+		options.Policy = OverflowCoalesce
+	}
+
+	q := &queuedListener{
+		listener:     listener,
+		options:      options,
+		keys:         make(map[string]int),
+		signal:       make(chan struct{}, 1),
+		batches:      make(map[string]*valueBatch),
+		fullFidelity: fullFidelity,
+	}
+	go q.deliver()
+	if options.BatchInterval > 0 {
+		go q.flushEvery(options.BatchInterval)
+	}
+	return q
+}
+
+// OnExecutionEvent queues an event without waiting for the listener
+func (q *queuedListener) OnExecutionEvent(event ExecutionEvent) {
+	if q.options.BatchInterval > 0 {
+		switch event.Type {
+		case EventValueProduced:
+			if q.fullFidelity == nil || !q.fullFidelity(event.NodeID) {
+				q.addToBatch(event)
+				return
+			}
+		case EventExecutionEnd:
+			// Listeners see the last values before the end of the execution
+			q.flushBatches()
+		}
+	}
+	q.enqueue(event)
+}
+
+// enqueue adds an event to the pending events by the overflow policy
+func (q *queuedListener) enqueue(event ExecutionEvent) {
+	q.mutex.Lock()
+	key := ""
+	if q.options.Policy == OverflowCoalesce {
+		key = coalescingKey(event)
+	}
+	if key != "" && q.fullFidelity != nil && q.fullFidelity(event.NodeID) {
+		// Nodes debugged in full fidelity keep every value
+		key = ""
+	}
+
+	if index, exists := q.keys[key]; key != "" && exists {
+		q.pending[index] = event
+		q.mutex.Unlock()
+		q.coalesced.Add(1)
+		return
+	}
+
+	if len(q.pending) >= q.options.QueueSize {
+		q.mutex.Unlock()
+		q.dropped.Add(1)
+		return
+	}
+
+	if key != "" {
+		q.keys[key] = len(q.pending)
+	}
+	q.pending = append(q.pending, event)
+	q.mutex.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// deliver passes the pending events to the listener in the order they were emitted
+func (q *queuedListener) deliver() {
+	for range q.signal {
+		q.mutex.Lock()
+		events := q.pending
+		q.pending = nil
+		q.keys = make(map[string]int)
+		q.mutex.Unlock()
+
+		for _, event := range events {
+			q.listener.OnExecutionEvent(event)
+			q.delivered.Add(1)
+		}
+	}
+}
+
+// addToBatch keeps a value event for the next batch of its node, replacing an earlier value
+// of the same pin
+func (q *queuedListener) addToBatch(event ExecutionEvent) {
+	q.batchMutex.Lock()
+	defer q.batchMutex.Unlock()
+
+	batch, exists := q.batches[event.NodeID]
+	if !exists {
+		batch = &valueBatch{values: make(map[string]map[string]interface{})}
+		q.batches[event.NodeID] = batch
+	}
+
+	key := pinKey(event)
+	if _, seen := batch.values[key]; !seen {
+		batch.order = append(batch.order, key)
+	}
+	batch.values[key] = event.Data
+	batch.updates++
+	batch.last = event.Timestamp
+	q.batched.Add(1)
+}
+
+// flushEvery delivers the batched values at every interval
+func (q *queuedListener) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		q.flushBatches()
+	}
+}
+
+// flushBatches queues a values.batched event for every node that produced values since the
+// last batch. Each event carries the latest value of the pins that changed and the number of
+// values it stands for.
+func (q *queuedListener) flushBatches() {
+	q.batchMutex.Lock()
+	batches := q.batches
+	if len(batches) == 0 {
+		q.batchMutex.Unlock()
+		return
+	}
+	q.batches = make(map[string]*valueBatch)
+	q.batchMutex.Unlock()
+
+	nodeIDs := make([]string, 0, len(batches))
+	for nodeID := range batches {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
+	for _, nodeID := range nodeIDs {
+		batch := batches[nodeID]
+		values := make([]interface{}, 0, len(batch.order))
+		for _, key := range batch.order {
+			values = append(values, batch.values[key])
+		}
+		q.enqueue(ExecutionEvent{
+			Type:      EventValuesBatched,
+			Timestamp: batch.last,
+			NodeID:    nodeID,
+			Data: map[string]interface{}{
+				"values":  values,
+				"updates": batch.updates,
+			},
+		})
+	}
+}
+
+// stats returns the delivery counters of the listener
+func (q *queuedListener) stats() ListenerStats {
+	q.mutex.Lock()
+	pending := len(q.pending)
+	q.mutex.Unlock()
+
+	return ListenerStats{
+		Listener:  fmt.Sprintf("%T", q.listener),
+		Options:   q.options,
+		Pending:   pending,
+		Delivered: q.delivered.Load(),
+		Coalesced: q.coalesced.Load(),
+		Dropped:   q.dropped.Load(),
+		Batched:   q.batched.Load(),
+	}
+}
+
+// coalescingKey identifies the events that only matter in their latest state: the values
+// flowing through a connection. Lifecycle events, errors and logs are never coalesced.
+func coalescingKey(event ExecutionEvent) string {
+	switch event.Type {
+	case EventValueProduced:
+		return event.NodeID + "|" + pinKey(event)
+	}
+	return ""
+}
+
+// pinKey identifies the pin or connection of a value event within its node. Actor mode
+// reports the output pin, standard mode the connection the value flows through.
+func pinKey(event ExecutionEvent) string {
+	return fmt.Sprintf("%v|%v|%v|%v", event.Data["pinId"], event.Data["sourcePinId"], event.Data["targetNodeId"], event.Data["targetPinId"])
+}
@@ -0,0 +1,135 @@
+package engine_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+)
+
+// gatedListener records the events it receives, holding the first one until it's opened
+type gatedListener struct {
+	entered chan struct{}
+	open    chan struct{}
+	once    sync.Once
+	mutex   sync.Mutex
+	events  []engine.ExecutionEvent
+}
+
+func newGatedListener() *gatedListener {
+	return &gatedListener{entered: make(chan struct{}), open: make(chan struct{})}
+}
+
+func (l *gatedListener) OnExecutionEvent(event engine.ExecutionEvent) {
+	l.once.Do(func() {
+		close(l.entered)
+		<-l.open
+	})
+	l.mutex.Lock()
+	l.events = append(l.events, event)
+	l.mutex.Unlock()
+}
+
+func (l *gatedListener) received() []engine.ExecutionEvent {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]engine.ExecutionEvent(nil), l.events...)
+}
+
+// blockedListener adds a gated listener with the options to a new engine and waits until the
+// listener holds its first event
+func blockedListener(t *testing.T, options engine.ListenerOptions) (*engine.ExecutionEngine, *gatedListener) {
+	t.Helper()
+	flowEngine := engine.NewExecutionEngine(nopLogger{}, engine.NewDebugManager())
+	listener := newGatedListener()
+	flowEngine.AddExecutionListenerWithOptions(listener, options)
+
+	flowEngine.EmitEvent(engine.ExecutionEvent{Type: engine.EventExecutionStart})
+	select {
+	case <-listener.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the listener didn't receive the first event")
+	}
+	return flowEngine, listener
+}
+
+// waitForDelivery waits until the listener received the number of events
+func waitForDelivery(t *testing.T, listener *gatedListener, count int) []engine.ExecutionEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if events := listener.received(); len(events) >= count {
+			return events
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d delivered events, got %d", count, len(listener.received()))
+	return nil
+}
+
+func TestSlowListenerDropsEventsBeyondItsQueue(t *testing.T) {
+	const queueSize = 4
+	flowEngine, listener := blockedListener(t, engine.ListenerOptions{QueueSize: queueSize, Policy: engine.OverflowDrop})
+
+	// Emitting doesn't wait for the blocked listener
+	emitted := make(chan struct{})
+	go func() {
+		defer close(emitted)
+		for i := 0; i < queueSize+3; i++ {
+			flowEngine.EmitEvent(engine.ExecutionEvent{Type: engine.EventNodeStarted, NodeID: fmt.Sprintf("node-%d", i)})
+		}
+	}()
+	select {
+	case <-emitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected emitting events not to wait for a slow listener")
+	}
+
+	stats := flowEngine.GetListenerStats()
+	if len(stats) != 1 || stats[0].Pending != queueSize || stats[0].Dropped != 3 {
+		t.Fatalf("expected %d pending and 3 dropped events, got %+v", queueSize, stats)
+	}
+
+	close(listener.open)
+	events := waitForDelivery(t, listener, queueSize+1)
+	for i, event := range events[1:] {
+		if event.NodeID != fmt.Sprintf("node-%d", i) {
+			t.Errorf("expected the queued events in the order they were emitted, got %s at %d", event.NodeID, i)
+		}
+	}
+}
+
+func TestSlowListenerCoalescesValues(t *testing.T) {
+	flowEngine, listener := blockedListener(t, engine.ListenerOptions{QueueSize: 8, Policy: engine.OverflowCoalesce})
+
+	for i := 0; i < 5; i++ {
+		flowEngine.EmitEvent(engine.ExecutionEvent{
+			Type:   engine.EventValueProduced,
+			NodeID: "source",
+			Data:   map[string]interface{}{"pinId": "value", "value": i},
+		})
+		flowEngine.EmitEvent(engine.ExecutionEvent{Type: engine.EventNodeCompleted, NodeID: "source"})
+	}
+
+	stats := flowEngine.GetListenerStats()
+	if len(stats) != 1 || stats[0].Pending != 6 || stats[0].Coalesced != 4 || stats[0].Dropped != 0 {
+		t.Fatalf("expected the values to coalesce into one pending event, got %+v", stats)
+	}
+
+	close(listener.open)
+	events := waitForDelivery(t, listener, 7)
+	values := 0
+	for _, event := range events {
+		if event.Type != engine.EventValueProduced {
+			continue
+		}
+		values++
+		if event.Data["value"] != 4 {
+			t.Errorf("expected the latest value, got %v", event.Data["value"])
+		}
+	}
+	if values != 1 {
+		t.Errorf("expected one coalesced value, got %d", values)
+	}
+}
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// The state executions touch on every node execution lives outside the engine's mutex, so
+// concurrent executions don't serialize on it: each execution updates its status under its
+// own lock, statuses are spread over shards, and the node types and listeners, which change
+// rarely, are replaced as a whole and read without locking.
+
+// executionShardCount is the number of shards the statuses of executions are spread over
+const executionShardCount = 32
+
+// executionRecord is the status of an execution with its own lock
+type executionRecord struct {
+	mutex  sync.Mutex
+	status ExecutionStatus
+	ctx    context.Context // Context of the execution while it runs
+}
+
+// setContext sets the context of the execution, nil once it ended
+func (r *executionRecord) setContext(ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ctx = ctx
+}
+
+// context returns the context of the execution, nil unless it runs
+func (r *executionRecord) context() context.Context {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ctx
+}
+
+// update changes the status of the execution under its lock
+func (r *executionRecord) update(change func(status *ExecutionStatus)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	change(&r.status)
+}
+
+// snapshot returns a copy of the status of the execution, with its own node statuses
+func (r *executionRecord) snapshot() ExecutionStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	status := r.status
+	status.NodeStatuses = make(map[string]NodeStatus, len(r.status.NodeStatuses))
+	for nodeID, nodeStatus := range r.status.NodeStatuses {
+		status.NodeStatuses[nodeID] = nodeStatus
+	}
+	return status
+}
+
+// startNode records the start of a node execution
+func (r *executionRecord) startNode(nodeID string, status NodeStatus) {
+	r.update(func(s *ExecutionStatus) {
+		s.NodeStatuses[nodeID] = status
+	})
+}
+
+// endNode records the end of a node execution, with its error if it failed, and returns
+// its status if the execution recorded its start
+func (r *executionRecord) endNode(nodeID, outcome string, err error) (NodeStatus, bool) {
+	var nodeStatus NodeStatus
+	var exists bool
+	r.update(func(s *ExecutionStatus) {
+		nodeStatus, exists = s.NodeStatuses[nodeID]
+		if !exists {
+			return
+		}
+		nodeStatus.Status = outcome
+		nodeStatus.Error = err
+		nodeStatus.EndTime = time.Now()
+		s.NodeStatuses[nodeID] = nodeStatus
+	})
+	return nodeStatus, exists
+}
+
+// executionShard holds the records of the executions whose IDs hash to it
+type executionShard struct {
+	mutex   sync.RWMutex
+	records map[string]*executionRecord
+}
+
+// executionRecords holds the statuses of the executions, sharded by execution ID
+type executionRecords struct {
+	shards [executionShardCount]executionShard
+}
+
+func newExecutionRecords() *executionRecords {
+	records := &executionRecords{}
+	for i := range records.shards {
+		records.shards[i].records = make(map[string]*executionRecord)
+	}
+	return records
+}
+
+// shard returns the shard of an execution
+func (r *executionRecords) shard(executionID string) *executionShard {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(executionID))
+	return &r.shards[hash.Sum32()%executionShardCount]
+}
+
+// store records the status of an execution, replacing the one it had
+func (r *executionRecords) store(status ExecutionStatus) *executionRecord {
+	if status.NodeStatuses == nil {
+		status.NodeStatuses = make(map[string]NodeStatus)
+	}
+	record := &executionRecord{status: status}
+
+	shard := r.shard(status.ExecutionID)
+	shard.mutex.Lock()
+	shard.records[status.ExecutionID] = record
+	shard.mutex.Unlock()
+	return record
+}
+
+// get returns the record of an execution
+func (r *executionRecords) get(executionID string) (*executionRecord, bool) {
+	shard := r.shard(executionID)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	record, exists := shard.records[executionID]
+	return record, exists
+}
+
+// update changes the status of an execution, if it has one
+func (r *executionRecords) update(executionID string, change func(status *ExecutionStatus)) {
+	if record, exists := r.get(executionID); exists {
+		record.update(change)
+	}
+}
+
+// count returns the number of executions with a status
+func (r *executionRecords) count(status string) int {
+	count := 0
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mutex.RLock()
+		for _, record := range shard.records {
+			record.mutex.Lock()
+			if record.status.Status == status {
+				count++
+			}
+			record.mutex.Unlock()
+		}
+		shard.mutex.RUnlock()
+	}
+	return count
+}
+
+// executionContext returns the context of a running execution. Nodes running outside of one,
+// e.g. event handlers triggered once it ended, get the background context.
+func (e *ExecutionEngine) executionContext(executionID string) context.Context {
+	if record, exists := e.executions.get(executionID); exists {
+		if ctx := record.context(); ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// nodeFactories returns the node types registered with the engine. The map is shared and
+// must not be modified.
+func (e *ExecutionEngine) nodeFactories() map[string]node.NodeFactory {
+	if factories := e.nodeRegistry.Load(); factories != nil {
+		return *factories
+	}
+	return nil
+}
+
+// replaceNodeFactories replaces the node types registered with the engine
+func (e *ExecutionEngine) replaceNodeFactories(factories map[string]node.NodeFactory) {
+	e.registryMutex.Lock()
+	defer e.registryMutex.Unlock()
+	e.nodeRegistry.Store(&factories)
+}
+
+// executionListeners returns the listeners of the engine. The slice is shared and must not
+// be modified.
+func (e *ExecutionEngine) executionListeners() []ExecutionListener {
+	if listeners := e.listeners.Load(); listeners != nil {
+		return *listeners
+	}
+	return nil
+}
+
+// blueprintVariables returns the variables of a loaded blueprint version
+func (e *ExecutionEngine) blueprintVariables(key blueprintKey) (map[string]types.Value, bool) {
+	variables, exists := e.variables.Load(key)
+	if !exists {
+		return nil, false
+	}
+	return variables.(map[string]types.Value), true
+}
+
+// initVariables gives a blueprint version its variables if it has none yet
+func (e *ExecutionEngine) initVariables(key blueprintKey) {
+	e.variables.LoadOrStore(key, make(map[string]types.Value))
+}
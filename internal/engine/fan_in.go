@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// fanInValues holds the values delivered to the input pins of a node that have a fan-in
+// policy, by pin ID and source ("node.pin"). Each source keeps its latest value.
+type fanInValues map[string]map[string]types.Value
+
+// set stores the value a source delivered to a pin
+func (f fanInValues) set(pinID, sourceNodeID, sourcePinID string, value types.Value) {
+	if f[pinID] == nil {
+		f[pinID] = make(map[string]types.Value)
+	}
+	f[pinID][fanInSource(sourceNodeID, sourcePinID)] = value
+}
+
+// fanInSource identifies the source of a data connection
+func fanInSource(sourceNodeID, sourcePinID string) string {
+	return sourceNodeID + "." + sourcePinID
+}
+
+// resolveFanIn resolves the value of an input pin under its fan-in policy. The values are
+// ordered as their connections are in the blueprint, so the policy gives the same value
+// whatever order the sources ran in.
+func resolveFanIn(bp *blueprint.Blueprint, nodeID, pinID, policy string, delivered map[string]types.Value) (types.Value, bool) {
+	values := make([]types.Value, 0, len(delivered))
+	seen := make(map[string]bool, len(delivered))
+	for _, conn := range bp.GetNodeInputConnections(nodeID) {
+		if conn.ConnectionType != "data" || conn.TargetPinID != pinID {
+			continue
+		}
+		source := fanInSource(conn.SourceNodeID, conn.SourcePinID)
+		if value, exists := delivered[source]; exists && !seen[source] {
+			seen[source] = true
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return types.Value{}, false
+	}
+
+	switch policy {
+	case blueprint.FanInFirst:
+		return values[0], true
+
+	case blueprint.FanInCollect:
+		collected := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			collected = append(collected, value.RawValue)
+		}
+		return types.NewValue(types.PinTypes.Array, collected), true
+
+	case blueprint.FanInMerge:
+		return types.MergeValues(values), true
+	}
+
+	return values[len(values)-1], true
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/expr"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// maxSkippedTriggers is how many skipped triggers are kept per blueprint
+const maxSkippedTriggers = 100
+
+// SkippedTrigger is a trigger of an entry point whose guard rejected its payload
+type SkippedTrigger struct {
+	BlueprintID string    `json:"blueprintId"`
+	NodeID      string    `json:"nodeId"`
+	EventID     string    `json:"eventId,omitempty"`     // Event that triggered the node, if any
+	ExecutionID string    `json:"executionId,omitempty"` // Execution the trigger belonged to, if one was created
+	Guard       string    `json:"guard"`
+	Reason      string    `json:"reason"`
+	Time        time.Time `json:"time"`
+}
+
+// evaluateGuard evaluates the guard of an entry point on the payload of a trigger. It
+// reports whether the node may run and, if not, why.
+func evaluateGuard(bpNode *blueprint.BlueprintNode, payload map[string]types.Value) (bool, string) {
+	source := bpNode.GetGuard()
+	if source == "" {
+		return true, ""
+	}
+
+	expression, ok := cachedExpression(source)
+	if !ok {
+		parsed, err := expr.Parse(source)
+		if err != nil {
+			return false, fmt.Sprintf("guard %q is invalid: %v", source, err)
+		}
+		expressionCache.Store(source, parsed)
+		expression = parsed
+	}
+
+	value := make(map[string]interface{}, len(payload))
+	for name, v := range payload {
+		value[name] = v.RawValue
+	}
+
+	result, err := expression.Eval(value)
+	if err != nil {
+		return false, fmt.Sprintf("guard %q failed: %v", source, err)
+	}
+	if !expr.Truthy(result) {
+		return false, fmt.Sprintf("guard %q evaluated to %v", source, result)
+	}
+	return true, ""
+}
+
+// cachedExpression returns a parsed expression of the expression cache
+func cachedExpression(source string) (*expr.Expression, bool) {
+	cached, ok := expressionCache.Load(source)
+	if !ok {
+		return nil, false
+	}
+	return cached.(*expr.Expression), true
+}
+
+// admitEntryPoints returns the entry points whose guards admit the payload of an
+// execution, and records the others as skipped along with the reasons they were
+func (e *ExecutionEngine) admitEntryPoints(plan *ExecutionPlan, executionID string, entryPoints []string, payload map[string]types.Value) ([]string, []string) {
+	admitted := make([]string, 0, len(entryPoints))
+	reasons := make([]string, 0)
+	for _, nodeID := range entryPoints {
+		bpNode := plan.Node(nodeID)
+		if bpNode == nil {
+			admitted = append(admitted, nodeID)
+			continue
+		}
+
+		if ok, reason := evaluateGuard(bpNode, payload); !ok {
+			e.recordSkippedTrigger(SkippedTrigger{
+				BlueprintID: plan.BlueprintID(),
+				NodeID:      nodeID,
+				ExecutionID: executionID,
+				Guard:       bpNode.GetGuard(),
+				Reason:      reason,
+			})
+			reasons = append(reasons, reason)
+			continue
+		}
+		admitted = append(admitted, nodeID)
+	}
+	return admitted, reasons
+}
+
+// CheckGuards returns the error an execution started now with a payload would be skipped
+// with because the guards of all entry points of its blueprint reject it, so callers can
+// skip it before recording it. Skipped executions are recorded as skipped triggers.
+func (e *ExecutionEngine) CheckGuards(bp *blueprint.Blueprint, payload map[string]types.Value) error {
+	entryPoints := bp.FindEntryPoints()
+	if len(entryPoints) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(entryPoints))
+	skipped := make([]SkippedTrigger, 0, len(entryPoints))
+	for _, nodeID := range entryPoints {
+		bpNode := bp.FindNode(nodeID)
+		if bpNode == nil {
+			return nil
+		}
+
+		ok, reason := evaluateGuard(bpNode, payload)
+		if ok {
+			return nil
+		}
+		reasons = append(reasons, reason)
+		skipped = append(skipped, SkippedTrigger{
+			BlueprintID: bp.ID,
+			NodeID:      nodeID,
+			Guard:       bpNode.GetGuard(),
+			Reason:      reason,
+		})
+	}
+
+	for _, trigger := range skipped {
+		e.recordSkippedTrigger(trigger)
+	}
+	return triggerSkippedError(bp.ID, "", reasons)
+}
+
+// recordSkippedTrigger keeps a skipped trigger of a blueprint, dropping the oldest ones
+// beyond maxSkippedTriggers
+func (e *ExecutionEngine) recordSkippedTrigger(trigger SkippedTrigger) {
+	if trigger.Time.IsZero() {
+		trigger.Time = time.Now()
+	}
+
+	e.logger.Info("Skipped trigger rejected by the entry point guard", map[string]interface{}{
+		"blueprintId": trigger.BlueprintID,
+		"nodeId":      trigger.NodeID,
+		"eventId":     trigger.EventID,
+		"reason":      trigger.Reason,
+	})
+
+	e.skippedMutex.Lock()
+	defer e.skippedMutex.Unlock()
+
+	triggers := append(e.skippedTriggers[trigger.BlueprintID], trigger)
+	if len(triggers) > maxSkippedTriggers {
+		triggers = triggers[len(triggers)-maxSkippedTriggers:]
+	}
+	e.skippedTriggers[trigger.BlueprintID] = triggers
+}
+
+// GetSkippedTriggers returns the latest triggers of a blueprint its entry point guards
+// rejected, newest first
+func (e *ExecutionEngine) GetSkippedTriggers(blueprintID string) []SkippedTrigger {
+	e.skippedMutex.Lock()
+	defer e.skippedMutex.Unlock()
+
+	triggers := e.skippedTriggers[blueprintID]
+	result := make([]SkippedTrigger, 0, len(triggers))
+	for i := len(triggers) - 1; i >= 0; i-- {
+		result = append(result, triggers[i])
+	}
+	return result
+}
+
+// triggerSkippedError creates the error of a trigger all entry point guards rejected
+func triggerSkippedError(blueprintID, executionID string, reasons []string) *bperrors.BlueprintError {
+	return bperrors.New(
+		bperrors.ErrorTypeExecution,
+		bperrors.ErrTriggerSkipped,
+		fmt.Sprintf("the entry point guards rejected the trigger: %s", strings.Join(reasons, "; ")),
+		bperrors.SeverityLow,
+	).WithBlueprintInfo(blueprintID, executionID).WithDetails(map[string]interface{}{
+		"reasons": reasons,
+	})
+}
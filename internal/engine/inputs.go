@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"strings"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// applyInputDefaults fills the inputs a node didn't receive, or received as null, with
+// the default of their pin: the default declared by the pin, else the one set on the
+// node in the blueprint (Data["defaults"]). Received holds inputs the node gets besides
+// inputValues, e.g. event parameters.
+//
+// It returns the error of the first required input that's still missing. An input is
+// provided by a value, a data connection (the source may run later or produce null) or
+// a property of the node the node reads it from.
+func applyInputDefaults(nodeInstance node.Node, bp *blueprint.Blueprint, nodeConfig *blueprint.BlueprintNode, inputValues, received map[string]types.Value) *bperrors.BlueprintError {
+	var defaults map[string]interface{}
+	if nodeConfig != nil {
+		defaults, _ = nodeConfig.Data["defaults"].(map[string]interface{})
+	}
+
+	for _, pin := range nodeInstance.GetInputPins() {
+		if pin.Type != nil && pin.Type.ID == types.PinTypes.Execution.ID {
+			continue
+		}
+
+		value, exists := inputValues[pin.ID]
+		if !exists {
+			value, exists = received[pin.ID]
+		}
+		if exists && !value.IsNull() {
+			continue
+		}
+
+		if pin.Default != nil {
+			inputValues[pin.ID] = types.NewValue(pin.Type, pin.Default)
+			continue
+		}
+		if value, ok := defaults[pin.ID]; ok && value != nil {
+			inputValues[pin.ID] = types.NewValue(pin.Type, value)
+			continue
+		}
+
+		if exists || pin.Optional || inputConnected(bp, nodeConfig, pin.ID) || inputFromProperty(nodeConfig, pin.ID) {
+			continue
+		}
+
+		nodeID := ""
+		if nodeConfig != nil {
+			nodeID = nodeConfig.ID
+		}
+		return bperrors.MissingInput(pin.ID).
+			WithNodeInfo(nodeID, pin.ID).
+			WithDetails(map[string]interface{}{"pinName": pin.Name, "nodeType": nodeInstance.GetMetadata().TypeID})
+	}
+	return nil
+}
+
+// inputConnected reports whether a data connection leads to an input pin of a node
+func inputConnected(bp *blueprint.Blueprint, nodeConfig *blueprint.BlueprintNode, pinID string) bool {
+	if bp == nil || nodeConfig == nil {
+		return false
+	}
+	for _, conn := range bp.GetNodeInputConnections(nodeConfig.ID) {
+		if conn.ConnectionType == "data" && conn.TargetPinID == pinID {
+			return true
+		}
+	}
+	return false
+}
+
+// inputFromProperty reports whether a node has a property it reads an input pin from
+// when the pin isn't connected
+func inputFromProperty(nodeConfig *blueprint.BlueprintNode, pinID string) bool {
+	if nodeConfig == nil {
+		return false
+	}
+	for _, property := range nodeConfig.Properties {
+		switch property.Name {
+		case pinID, "input_" + pinID, "_loop_" + pinID:
+			return true
+		case "constantValue":
+			if strings.HasPrefix(nodeConfig.Type, "constant-") {
+				return true
+			}
+		}
+	}
+	return false
+}
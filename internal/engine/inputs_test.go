@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// pinsNode only declares input pins
+type pinsNode struct {
+	node.BaseNode
+}
+
+func (n *pinsNode) Execute(ctx node.ExecutionContext) error {
+	return nil
+}
+
+func newPinsNode(pins ...types.Pin) node.Node {
+	inputs := append([]types.Pin{{ID: "exec", Name: "Execute", Type: types.PinTypes.Execution}}, pins...)
+	return &pinsNode{BaseNode: node.BaseNode{
+		Metadata: node.NodeMetadata{TypeID: "test-pins", Name: "Pins"},
+		Inputs:   inputs,
+	}}
+}
+
+func TestApplyInputDefaults(t *testing.T) {
+	required := types.Pin{ID: "input", Name: "Input", Type: types.PinTypes.Number}
+	withDefault := required
+	withDefault.Default = float64(1)
+	optional := required
+	optional.Optional = true
+
+	for _, test := range []struct {
+		name       string
+		pin        types.Pin
+		nodeConfig blueprint.BlueprintNode
+		connected  bool
+		inputs     map[string]types.Value
+		received   map[string]types.Value
+		expected   interface{} // Value of the input afterwards, nil if it stays unset or null
+		missing    bool
+	}{
+		{
+			name:    "missing required input",
+			pin:     required,
+			missing: true,
+		},
+		{
+			name:     "provided input",
+			pin:      withDefault,
+			inputs:   map[string]types.Value{"input": types.NewValue(types.PinTypes.Number, float64(5))},
+			expected: float64(5),
+		},
+		{
+			name:     "received input",
+			pin:      required,
+			received: map[string]types.Value{"input": types.NewValue(types.PinTypes.Number, float64(6))},
+		},
+		{
+			name:     "pin default",
+			pin:      withDefault,
+			expected: float64(1),
+		},
+		{
+			name:       "pin default before node default",
+			pin:        withDefault,
+			nodeConfig: blueprint.BlueprintNode{Data: map[string]interface{}{"defaults": map[string]interface{}{"input": float64(2)}}},
+			expected:   float64(1),
+		},
+		{
+			name:       "node default",
+			pin:        required,
+			nodeConfig: blueprint.BlueprintNode{Data: map[string]interface{}{"defaults": map[string]interface{}{"input": float64(2)}}},
+			expected:   float64(2),
+		},
+		{
+			name:       "null node default",
+			pin:        required,
+			nodeConfig: blueprint.BlueprintNode{Data: map[string]interface{}{"defaults": map[string]interface{}{"input": nil}}},
+			missing:    true,
+		},
+		{
+			name:     "null input takes the default",
+			pin:      withDefault,
+			inputs:   map[string]types.Value{"input": types.NullValue(types.PinTypes.Number)},
+			expected: float64(1),
+		},
+		{
+			name: "optional input",
+			pin:  optional,
+		},
+		{
+			name:       "input from a property",
+			pin:        required,
+			nodeConfig: blueprint.BlueprintNode{Properties: []blueprint.NodeProperty{{Name: "input_input", Value: float64(3)}}},
+		},
+		{
+			name:      "connected input",
+			pin:       required,
+			connected: true,
+		},
+		{
+			name:      "connected input produced null",
+			pin:       required,
+			connected: true,
+			inputs:    map[string]types.Value{"input": types.NullValue(types.PinTypes.Number)},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			bp := blueprint.NewBlueprint("inputs", "Inputs", "1.0.0")
+			nodeConfig := test.nodeConfig
+			nodeConfig.ID, nodeConfig.Type = "node", "test-pins"
+			bp.AddNode(blueprint.BlueprintNode{ID: "source", Type: "test-source"})
+			bp.AddNode(nodeConfig)
+			if test.connected {
+				bp.AddConnection(blueprint.Connection{ID: "data", SourceNodeID: "source", SourcePinID: "value", TargetNodeID: "node", TargetPinID: "input", ConnectionType: "data"})
+			}
+			inputs := test.inputs
+			if inputs == nil {
+				inputs = make(map[string]types.Value)
+			}
+
+			bpErr := applyInputDefaults(newPinsNode(test.pin), bp, bp.FindNode("node"), inputs, test.received)
+			if test.missing {
+				if bpErr == nil || bpErr.Code != bperrors.ErrMissingRequiredInput || bpErr.NodeID != "node" || bpErr.PinID != "input" {
+					t.Fatalf("expected the input to be reported missing, got %v", bpErr)
+				}
+				return
+			}
+			if bpErr != nil {
+				t.Fatalf("expected no error, got %v", bpErr)
+			}
+
+			value, exists := inputs["input"]
+			if test.expected == nil {
+				if exists && !value.IsNull() {
+					t.Errorf("expected the input to stay unset, got %v", value.RawValue)
+				}
+				return
+			}
+			if !exists || value.RawValue != test.expected {
+				t.Errorf("expected the input %v, got %v", test.expected, value.RawValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"sync"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// joinState keeps track of the execution inputs activated on the join nodes of an
+// execution, so that parallel branches wait for each other before the join runs
+type joinState struct {
+	mutex   sync.Mutex
+	arrived map[string]map[string]bool // Node ID -> execution inputs activated in the current round
+	joined  map[string]bool            // Node ID -> whether the node ran in the current round
+}
+
+func newJoinState() *joinState {
+	return &joinState{
+		arrived: make(map[string]map[string]bool),
+		joined:  make(map[string]bool),
+	}
+}
+
+// arrive records the activation of an execution input of a join node and reports whether
+// the node runs now. A round ends once every input has been activated, so that a node
+// waiting for fewer inputs absorbs the late branches instead of running again.
+func (s *joinState) arrive(joinNode node.Node, nodeID, pinID string) bool {
+	required := joinNode.(node.JoinNode).RequiredInputs()
+	inputs := 0
+	for _, pin := range joinNode.GetInputPins() {
+		if pin.Type == types.PinTypes.Execution {
+			inputs++
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	arrived := s.arrived[nodeID]
+	if arrived == nil {
+		arrived = make(map[string]bool)
+		s.arrived[nodeID] = arrived
+	}
+	if arrived[pinID] {
+		return false
+	}
+	arrived[pinID] = true
+
+	run := !s.joined[nodeID] && len(arrived) >= required
+	if run {
+		s.joined[nodeID] = true
+	}
+	if len(arrived) >= inputs {
+		delete(s.arrived, nodeID)
+		delete(s.joined, nodeID)
+	}
+	return run
+}
+
+// isJoinNode reports whether a node synchronizes parallel branches
+func isJoinNode(n node.Node) bool {
+	_, ok := n.(node.JoinNode)
+	return ok
+}
+
+// setJoinState starts tracking the join nodes of an execution
+func (e *ExecutionEngine) setJoinState(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.joinStates[executionID] = newJoinState()
+}
+
+// joinState returns the join state of an execution, if any
+func (e *ExecutionEngine) joinState(executionID string) *joinState {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.joinStates[executionID]
+}
+
+// clearJoinState drops the join state of an execution
+func (e *ExecutionEngine) clearJoinState(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.joinStates, executionID)
+}
+
+// joinArrived records the activation of an execution input of a node in the standard
+// engine and reports whether the node runs now. Nodes that aren't join nodes always run.
+func (e *ExecutionEngine) joinArrived(bp *blueprint.Blueprint, executionID, nodeID, pinID string) bool {
+	state := e.joinState(executionID)
+	if state == nil {
+		return true
+	}
+
+	nodeConfig := bp.FindNode(nodeID)
+	if nodeConfig == nil {
+		return true
+	}
+	factory, exists := e.nodeFactory(bp.ID, executionID, nodeConfig)
+	if !exists {
+		return true
+	}
+
+	// Configuration errors are reported when the node runs
+	joinNode := factory()
+	if !isJoinNode(joinNode) || node.ConfigurePins(joinNode, nodeConfig.Data) != nil {
+		return true
+	}
+	return state.arrive(joinNode, nodeID, pinID)
+}
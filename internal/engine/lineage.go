@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"sort"
+	"time"
+	"webblueprint/internal/expr"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// maxLineageEdges bounds the data flows recorded per execution, e.g. for long loops
+const maxLineageEdges = 10000
+
+// LineageEdge is a value that flowed across a data connection
+type LineageEdge struct {
+	ConnectionID string      `json:"connectionId"`
+	SourceNodeID string      `json:"sourceNodeId"`
+	SourcePinID  string      `json:"sourcePinId"`
+	TargetNodeID string      `json:"targetNodeId"`
+	TargetPinID  string      `json:"targetPinId"`
+	Transform    string      `json:"transform,omitempty"` // Transform expression of the connection, if any
+	Upstream     bool        `json:"upstream,omitempty"`  // Read with GetUpstreamOutput instead of flowing across a connection
+	Value        interface{} `json:"value"`               // Value as it left the source pin
+	Delivered    interface{} `json:"delivered"`           // Value as it reached the target pin, after the transform
+	Time         time.Time   `json:"time"`
+}
+
+// ExecutionDataFlow is the recorded data flow of an execution that value lineage is
+// built from
+type ExecutionDataFlow struct {
+	ExecutionID string                            `json:"executionId"`
+	Edges       []LineageEdge                     `json:"edges"`
+	Outputs     map[string]map[string]interface{} `json:"outputs"`   // NodeID -> PinID -> latest value
+	NodeTypes   map[string]string                 `json:"nodeTypes"` // NodeID -> type, for the nodes that ran
+	Truncated   bool                              `json:"truncated"`
+}
+
+// LineageNode is a node a value passed through, at its distance from the value
+type LineageNode struct {
+	NodeID   string `json:"nodeId"`
+	NodeType string `json:"nodeType,omitempty"`
+	Depth    int    `json:"depth"` // 0 for the node that produced the value
+}
+
+// ValueLineage is the graph of the nodes, pins and transforms an output value of an
+// execution came from
+type ValueLineage struct {
+	NodeID string        `json:"nodeId"`
+	PinID  string        `json:"pinId"`
+	Value  interface{}   `json:"value"`
+	Nodes  []LineageNode `json:"nodes"` // Nearest first
+	Edges  []LineageEdge `json:"edges"` // Data flows into the nodes, nearest first
+}
+
+// ExecutionLineage is the lineage of the final output values of an execution, those that
+// weren't passed on to other nodes
+type ExecutionLineage struct {
+	ExecutionID string         `json:"executionId"`
+	Outputs     []ValueLineage `json:"outputs"`
+	Truncated   bool           `json:"truncated"` // Data flows beyond maxLineageEdges weren't recorded
+}
+
+// executionDataFlow records the data flows of an execution
+type executionDataFlow struct {
+	edges     []LineageEdge
+	truncated bool
+}
+
+// lineageEdge creates the lineage edge of a value that flowed across a data connection
+func lineageEdge(connectionID, sourceNodeID, sourcePinID, targetNodeID, targetPinID string, transform *expr.Expression, produced, delivered types.Value) LineageEdge {
+	edge := LineageEdge{
+		ConnectionID: connectionID,
+		SourceNodeID: sourceNodeID,
+		SourcePinID:  sourcePinID,
+		TargetNodeID: targetNodeID,
+		TargetPinID:  targetPinID,
+		Value:        produced.RawValue,
+		Delivered:    delivered.RawValue,
+		Time:         time.Now(),
+	}
+	if transform != nil {
+		edge.Transform = transform.String()
+	}
+	return edge
+}
+
+// recordDataFlow records a value that flowed across a data connection of a standard mode
+// execution
+func (e *ExecutionEngine) recordDataFlow(executionID string, conn blueprint.Connection, produced, delivered types.Value) {
+	edge := lineageEdge(conn.ID, conn.SourceNodeID, conn.SourcePinID, conn.TargetNodeID, conn.TargetPinID, nil, produced, delivered)
+	edge.Transform = conn.GetTransform()
+	e.debugManager.RecordDataFlow(executionID, edge)
+}
+
+// GetExecutionDataFlow returns the recorded data flow of an execution still retained by
+// the debug manager
+func (e *ExecutionEngine) GetExecutionDataFlow(executionID string) (*ExecutionDataFlow, bool) {
+	return e.debugManager.GetDataFlow(executionID)
+}
+
+// RecordDataFlow records that a value flowed across a data connection
+func (dm *DebugManager) RecordDataFlow(executionID string, edge LineageEdge) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	dm.touchLocked(executionID)
+
+	flow, exists := dm.dataFlows[executionID]
+	if !exists {
+		flow = &executionDataFlow{edges: make([]LineageEdge, 0)}
+		dm.dataFlows[executionID] = flow
+	}
+
+	if len(flow.edges) >= maxLineageEdges {
+		flow.truncated = true
+		return
+	}
+	if edge.Time.IsZero() {
+		edge.Time = time.Now()
+	}
+	flow.edges = append(flow.edges, edge)
+}
+
+// GetDataFlow returns the recorded data flow of an execution
+func (dm *DebugManager) GetDataFlow(executionID string) (*ExecutionDataFlow, bool) {
+	dm.mutex.RLock()
+	flow, hasFlow := dm.dataFlows[executionID]
+	outputs, hasOutputs := dm.outputValues[executionID]
+	if !hasFlow && !hasOutputs {
+		dm.mutex.RUnlock()
+		return nil, false
+	}
+
+	result := &ExecutionDataFlow{
+		ExecutionID: executionID,
+		Edges:       make([]LineageEdge, 0),
+		Outputs:     make(map[string]map[string]interface{}, len(outputs)),
+		NodeTypes:   make(map[string]string),
+	}
+	if hasFlow {
+		result.Edges = append(result.Edges, flow.edges...)
+		result.Truncated = flow.truncated
+	}
+	for nodeID, pins := range outputs {
+		result.Outputs[nodeID] = make(map[string]interface{}, len(pins))
+		for pinID, value := range pins {
+			result.Outputs[nodeID][pinID] = value
+		}
+	}
+	if timeline, exists := dm.timelines[executionID]; exists {
+		for _, span := range timeline.spans {
+			result.NodeTypes[span.NodeID] = span.NodeType
+		}
+	}
+	dm.mutex.RUnlock()
+
+	dm.touch(executionID)
+	return result, true
+}
+
+// Lineage returns the lineage of an output value of the execution. The value passed
+// through every node upstream of its node over data connections; each node is listed
+// once, at its shortest distance.
+func (f *ExecutionDataFlow) Lineage(nodeID, pinID string) (*ValueLineage, bool) {
+	value, exists := f.Outputs[nodeID][pinID]
+	if !exists {
+		return nil, false
+	}
+
+	// Latest flow into each input pin, since that's the value the node ran with. Upstream
+	// reads have no input pin, so they're told apart by the output they read.
+	inputs := make(map[string]map[string]LineageEdge)
+	for _, edge := range f.Edges {
+		if inputs[edge.TargetNodeID] == nil {
+			inputs[edge.TargetNodeID] = make(map[string]LineageEdge)
+		}
+		pin := edge.TargetPinID
+		if edge.Upstream {
+			pin = "upstream:" + edge.SourceNodeID + "." + edge.SourcePinID
+		}
+		inputs[edge.TargetNodeID][pin] = edge
+	}
+
+	lineage := &ValueLineage{
+		NodeID: nodeID,
+		PinID:  pinID,
+		Value:  value,
+		Nodes:  make([]LineageNode, 0),
+		Edges:  make([]LineageEdge, 0),
+	}
+
+	visited := map[string]bool{nodeID: true}
+	queue := []LineageNode{{NodeID: nodeID, NodeType: f.NodeTypes[nodeID]}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		lineage.Nodes = append(lineage.Nodes, current)
+
+		pins := make([]string, 0, len(inputs[current.NodeID]))
+		for pin := range inputs[current.NodeID] {
+			pins = append(pins, pin)
+		}
+		sort.Strings(pins)
+
+		for _, pin := range pins {
+			edge := inputs[current.NodeID][pin]
+			lineage.Edges = append(lineage.Edges, edge)
+			if visited[edge.SourceNodeID] {
+				continue
+			}
+			visited[edge.SourceNodeID] = true
+			queue = append(queue, LineageNode{
+				NodeID:   edge.SourceNodeID,
+				NodeType: f.NodeTypes[edge.SourceNodeID],
+				Depth:    current.Depth + 1,
+			})
+		}
+	}
+
+	return lineage, true
+}
+
+// FinalOutputs returns the lineage of the output values of the execution that weren't
+// passed on over a data connection, ordered by node and pin
+func (f *ExecutionDataFlow) FinalOutputs() *ExecutionLineage {
+	consumed := make(map[string]map[string]bool)
+	for _, edge := range f.Edges {
+		if consumed[edge.SourceNodeID] == nil {
+			consumed[edge.SourceNodeID] = make(map[string]bool)
+		}
+		consumed[edge.SourceNodeID][edge.SourcePinID] = true
+	}
+
+	nodeIDs := make([]string, 0, len(f.Outputs))
+	for nodeID := range f.Outputs {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
+	result := &ExecutionLineage{
+		ExecutionID: f.ExecutionID,
+		Outputs:     make([]ValueLineage, 0),
+		Truncated:   f.Truncated,
+	}
+	for _, nodeID := range nodeIDs {
+		pinIDs := make([]string, 0, len(f.Outputs[nodeID]))
+		for pinID := range f.Outputs[nodeID] {
+			if !consumed[nodeID][pinID] {
+				pinIDs = append(pinIDs, pinID)
+			}
+		}
+		sort.Strings(pinIDs)
+
+		for _, pinID := range pinIDs {
+			if lineage, exists := f.Lineage(nodeID, pinID); exists {
+				result.Outputs = append(result.Outputs, *lineage)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// nodeLatencySamples is the number of latest node executions the average node latency covers
+const nodeLatencySamples = 512
+
+// LoadSignals describe how busy the engine is, for autoscalers deciding how many servers
+// to run
+type LoadSignals struct {
+	Queued           int            `json:"queued"`           // Executions waiting for a scheduler worker
+	QueuedByPriority map[string]int `json:"queuedByPriority"` // Queued executions per priority
+	Waiting          int            `json:"waiting"`          // Executions waiting for their blueprint under its concurrency policy
+	Running          int            `json:"running"`          // Executions running, scheduled or triggered by events
+	Workers          int            `json:"workers"`          // Executions the scheduler runs at once
+	Utilization      float64        `json:"utilization"`      // Share of the scheduler workers that are busy, from 0 to 1
+	NodeLatencyMs    float64        `json:"nodeLatencyMs"`    // Average duration of the latest node executions
+	NodeSamples      int            `json:"nodeSamples"`      // Node executions the average covers
+}
+
+// latencyWindow keeps the durations of the latest node executions
+type latencyWindow struct {
+	samples [nodeLatencySamples]time.Duration
+	next    int
+	count   int
+	mutex   sync.Mutex
+}
+
+// observe records the duration of a node execution, replacing the oldest once full
+func (w *latencyWindow) observe(duration time.Duration) {
+	if duration < 0 {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.samples[w.next] = duration
+	w.next = (w.next + 1) % nodeLatencySamples
+	if w.count < nodeLatencySamples {
+		w.count++
+	}
+}
+
+// average returns the average of the recorded durations and how many there are
+func (w *latencyWindow) average() (time.Duration, int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.count == 0 {
+		return 0, 0
+	}
+	var total time.Duration
+	for _, sample := range w.samples[:w.count] {
+		total += sample
+	}
+	return total / time.Duration(w.count), w.count
+}
+
+// observeNode records the latency of a node that finished, successfully or not
+func (e *ExecutionEngine) observeNode(status NodeStatus) {
+	if !status.StartTime.IsZero() {
+		e.latencies.observe(status.EndTime.Sub(status.StartTime))
+	}
+}
+
+// GetLoadSignals returns the current load of the engine
+func (e *ExecutionEngine) GetLoadSignals() LoadSignals {
+	signals := LoadSignals{
+		QueuedByPriority: e.scheduler.QueueDepth(),
+		Workers:          e.scheduler.Workers(),
+	}
+	for _, count := range signals.QueuedByPriority {
+		signals.Queued += count
+	}
+	if signals.Workers > 0 {
+		signals.Utilization = float64(e.scheduler.Running()) / float64(signals.Workers)
+	}
+
+	e.concurrencyMutex.Lock()
+	for _, slot := range e.concurrency {
+		signals.Waiting += len(slot.queue)
+	}
+	e.concurrencyMutex.Unlock()
+
+	signals.Running = e.executions.count("running")
+
+	latency, samples := e.latencies.average()
+	signals.NodeLatencyMs = float64(latency) / float64(time.Millisecond)
+	signals.NodeSamples = samples
+	return signals
+}
@@ -0,0 +1,55 @@
+package engine_test
+
+import (
+	"testing"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+func TestLoadedBlueprintIsCopied(t *testing.T) {
+	flowEngine := newEngine(t, engine.ModeStandard)
+	bp := chainBlueprint(2)
+	if err := flowEngine.LoadBlueprint(bp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Changes to the caller's blueprint don't reach the loaded one
+	bp.Name = "Changed"
+	bp.Nodes[1].Type = "missing-type"
+	bp.AddNode(blueprint.BlueprintNode{ID: "extra", Type: "print"})
+
+	loaded := snapshotBlueprint(t, flowEngine, bp.ID)
+	if loaded.Name != "Contention" || loaded.Nodes[1].Type != "print" || len(loaded.Nodes) != 3 {
+		t.Fatalf("expected the loaded blueprint to be unchanged, got %q with %d nodes", loaded.Name, len(loaded.Nodes))
+	}
+
+	// Nor do changes to the blueprints the engine hands out
+	loaded.Nodes[0].Type = "missing-type"
+	if again := snapshotBlueprint(t, flowEngine, bp.ID); again.Nodes[0].Type != "event-on-created" {
+		t.Errorf("expected the snapshot to get its own copy, got node type %q", again.Nodes[0].Type)
+	}
+
+	// Executions run their copy, which is the one the engine keeps loaded
+	source := chainBlueprint(2)
+	source.Name = "Executed"
+	if result, err := flowEngine.Execute(source, "copied", map[string]types.Value{}); err != nil || !result.Success {
+		t.Fatalf("expected the execution to succeed, got %v", err)
+	}
+	source.Name = "Changed"
+	if executed := snapshotBlueprint(t, flowEngine, source.ID); executed.Name != "Executed" {
+		t.Errorf("expected the executed copy to stay loaded, got %q", executed.Name)
+	}
+}
+
+// snapshotBlueprint returns the current version of a blueprint loaded by the engine
+func snapshotBlueprint(t *testing.T, flowEngine *engine.ExecutionEngine, blueprintID string) *blueprint.Blueprint {
+	t.Helper()
+	for _, loaded := range flowEngine.Snapshot().Blueprints {
+		if loaded.Blueprint.ID == blueprintID {
+			return loaded.Blueprint
+		}
+	}
+	t.Fatalf("blueprint %s isn't loaded", blueprintID)
+	return nil
+}
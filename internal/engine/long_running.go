@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"webblueprint/internal/bperrors"
+)
+
+// Long-running executions, e.g. those of service blueprints consuming events or polling
+// APIs, run until the context they're executed with is done. Actor mode doesn't time them
+// out while they wait for their nodes. Cancelled executions, like those past their duration
+// limit, don't start new nodes; a node that is already running finishes, or stops its
+// outbound calls with the context.
+
+// MarkLongRunning exempts an execution from the actor timeout. The mark is dropped when the
+// execution ends.
+func (e *ExecutionEngine) MarkLongRunning(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.longRunning[executionID] = true
+}
+
+// isLongRunning reports whether an execution is exempt from the actor timeout
+func (e *ExecutionEngine) isLongRunning(executionID string) bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.longRunning[executionID]
+}
+
+// unmarkLongRunning drops the long-running mark of an execution
+func (e *ExecutionEngine) unmarkLongRunning(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.longRunning, executionID)
+}
+
+// executionCancelled returns the error a node fails with instead of starting once the
+// context of its execution is done, or nil while it isn't
+func executionCancelled(ctx context.Context, nodeID, blueprintID, executionID string) *bperrors.BlueprintError {
+	if ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return bperrors.New(
+			bperrors.ErrorTypeQuota,
+			bperrors.ErrExecutionDurationExceeded,
+			"execution exceeded its maximum duration",
+			bperrors.SeverityHigh,
+		).WithNodeInfo(nodeID, "").WithBlueprintInfo(blueprintID, executionID)
+	}
+	return bperrors.New(
+		bperrors.ErrorTypeExecution,
+		bperrors.ErrExecutionCancelled,
+		"execution was cancelled",
+		bperrors.SeverityMedium,
+	).WithNodeInfo(nodeID, "").WithBlueprintInfo(blueprintID, executionID)
+}
@@ -0,0 +1,116 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// slowNode takes a while before running the node it wraps
+type slowNode struct {
+	node.Node
+}
+
+func (n slowNode) Execute(ctx node.ExecutionContext) error {
+	time.Sleep(10 * time.Millisecond)
+	return n.Node.Execute(ctx)
+}
+
+// slowBlueprint is a chain of print nodes taking 10ms each
+func slowBlueprint(length int) *blueprint.Blueprint {
+	registry.GetInstance().RegisterNodeType("slow-print", func() node.Node {
+		return slowNode{utility.NewPrintNode()}
+	})
+	bp := chainBlueprint(length)
+	for i := range bp.Nodes {
+		if bp.Nodes[i].Type == "print" {
+			bp.Nodes[i].Type = "slow-print"
+		}
+	}
+	return bp
+}
+
+func TestCancelledExecutionStops(t *testing.T) {
+	for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+		t.Run(string(mode), func(t *testing.T) {
+			flowEngine := newEngine(t, mode)
+			bp := slowBlueprint(500)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err := flowEngine.ExecuteWithLimitsContext(ctx, engine.PriorityNormal, bp, "cancelled", map[string]types.Value{}, engine.ExecutionLimits{})
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("expected the execution to stop once cancelled, took %v", elapsed)
+			}
+			var bpErr *bperrors.BlueprintError
+			if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrExecutionCancelled {
+				t.Fatalf("expected the execution to be cancelled, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLongRunningExecutionOutlivesActorTimeout(t *testing.T) {
+	flowEngine := newEngine(t, engine.ModeActor)
+	flowEngine.SetActorTimeout(50 * time.Millisecond)
+	bp := slowBlueprint(20)
+
+	if _, err := flowEngine.Execute(bp, "bounded", map[string]types.Value{}); err == nil {
+		t.Fatal("expected the execution to time out")
+	}
+
+	flowEngine.MarkLongRunning("long-running")
+	result, err := flowEngine.Execute(bp, "long-running", map[string]types.Value{})
+	if err != nil || !result.Success {
+		t.Fatalf("expected the long-running execution to succeed, got %v", err)
+	}
+}
+
+func TestWaitForExecutionOutlastsDurationLimit(t *testing.T) {
+	flowEngine := newEngine(t, engine.ModeStandard)
+	unblock := make(chan struct{})
+	registry.GetInstance().RegisterNodeType("limited-print", func() node.Node {
+		return blockingNode{utility.NewPrintNode(), unblock}
+	})
+	bp := chainBlueprint(1)
+	bp.Nodes[1].Type = "limited-print"
+
+	limits := engine.ExecutionLimits{MaxDuration: 20 * time.Millisecond}
+	_, err := flowEngine.ExecuteWithLimits(engine.PriorityNormal, bp, "limited", map[string]types.Value{}, limits)
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrExecutionDurationExceeded {
+		t.Fatalf("expected the execution to exceed its duration, got %v", err)
+	}
+
+	ended := make(chan struct{})
+	go func() {
+		flowEngine.WaitForExecution("limited")
+		close(ended)
+	}()
+
+	select {
+	case <-ended:
+		t.Fatal("expected the wait to last until the node returns")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the wait to end with the execution")
+	}
+
+	// Executions that were never submitted don't block
+	flowEngine.WaitForExecution("unknown")
+}
@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/common"
+)
+
+// OverflowStrategy decides what happens to a message sent to an actor whose mailbox is full
+type OverflowStrategy string
+
+const (
+	OverflowBlock         OverflowStrategy = "block"          // The sender waits until the mailbox has room
+	OverflowDropOldest    OverflowStrategy = "drop-oldest"    // The oldest message in the mailbox makes room
+	OverflowFailExecution OverflowStrategy = "fail-execution" // The execution fails
+)
+
+// ParseOverflowStrategy returns the overflow strategy of the name
+func ParseOverflowStrategy(name string) (OverflowStrategy, error) {
+	switch strategy := OverflowStrategy(name); strategy {
+	case OverflowBlock, OverflowDropOldest, OverflowFailExecution:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown mailbox overflow strategy %q", name)
+}
+
+// MailboxPolicy sizes the mailbox of node actors and handles its overflow
+type MailboxPolicy struct {
+	Capacity int
+	Overflow OverflowStrategy
+}
+
+// DefaultMailboxPolicy is the mailbox policy of node types without their own
+var DefaultMailboxPolicy = MailboxPolicy{
+	Capacity: 1024,
+	Overflow: OverflowBlock,
+}
+
+// mailboxPolicies holds the default mailbox policy and the policies of node types
+type mailboxPolicies struct {
+	defaults  MailboxPolicy
+	nodeTypes map[string]MailboxPolicy
+}
+
+// forNodeType returns the mailbox policy of a node type
+func (p mailboxPolicies) forNodeType(nodeType string) MailboxPolicy {
+	if policy, exists := p.nodeTypes[nodeType]; exists {
+		return policy
+	}
+	return p.defaults
+}
+
+// SetMailboxPolicy sets the mailbox policy of the actors of a node type, or of all node
+// types without their own when the node type is empty
+func (e *ExecutionEngine) SetMailboxPolicy(nodeType string, policy MailboxPolicy) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if nodeType == "" {
+		e.mailboxes.defaults = policy
+		return
+	}
+
+	// Executions keep the policies they started with
+	nodeTypes := make(map[string]MailboxPolicy, len(e.mailboxes.nodeTypes)+1)
+	for typeID, existing := range e.mailboxes.nodeTypes {
+		nodeTypes[typeID] = existing
+	}
+	nodeTypes[nodeType] = policy
+	e.mailboxes.nodeTypes = nodeTypes
+}
+
+// getMailboxPolicies returns the mailbox policies of new executions
+func (e *ExecutionEngine) getMailboxPolicies() mailboxPolicies {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.mailboxes
+}
+
+// overflowState records the mailbox overflows of an execution
+type overflowState struct {
+	mutex     sync.Mutex
+	overflows map[string]*common.MailboxOverflow // Node ID -> overflows of the node's mailbox
+}
+
+func newOverflowState() *overflowState {
+	return &overflowState{overflows: make(map[string]*common.MailboxOverflow)}
+}
+
+// record adds an overflow of the mailbox of an actor, and whether a message was dropped
+func (s *overflowState) record(actor *NodeActor, dropped bool) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	overflow, exists := s.overflows[actor.NodeID]
+	if !exists {
+		overflow = &common.MailboxOverflow{
+			NodeID:   actor.NodeID,
+			NodeType: actor.NodeType,
+			Capacity: actor.mailboxPolicy.Capacity,
+			Strategy: string(actor.mailboxPolicy.Overflow),
+			FirstAt:  time.Now(),
+		}
+		s.overflows[actor.NodeID] = overflow
+	}
+	overflow.Occurrences++
+	if dropped {
+		overflow.Dropped++
+	}
+	overflow.LastAt = time.Now()
+}
+
+// list returns the mailbox overflows of the execution, by node ID
+func (s *overflowState) list() []common.MailboxOverflow {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	overflows := make([]common.MailboxOverflow, 0, len(s.overflows))
+	for _, overflow := range s.overflows {
+		overflows = append(overflows, *overflow)
+	}
+	sort.Slice(overflows, func(i, j int) bool {
+		return overflows[i].NodeID < overflows[j].NodeID
+	})
+	return overflows
+}
+
+// setOverflowState starts recording the mailbox overflows of an execution
+func (e *ExecutionEngine) setOverflowState(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.overflows[executionID] = newOverflowState()
+}
+
+// overflowState returns the mailbox overflows of an execution, if any
+func (e *ExecutionEngine) overflowState(executionID string) *overflowState {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.overflows[executionID]
+}
+
+// clearOverflowState drops the mailbox overflows of an execution
+func (e *ExecutionEngine) clearOverflowState(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.overflows, executionID)
+}
+
+// overflow handles a message for an actor whose mailbox is full by the overflow strategy of
+// the actor, and reports whether the message was put in the mailbox
+func (a *NodeActor) overflow(msg NodeMessage) (bool, error) {
+	var overflows *overflowState
+	if a.system != nil {
+		overflows = a.system.overflows
+	}
+
+	switch a.mailboxPolicy.Overflow {
+	case OverflowDropOldest:
+		overflows.record(a, true)
+		for {
+			select {
+			case a.mailbox <- msg:
+				return true, nil
+			default:
+			}
+
+			select {
+			case dropped := <-a.mailbox:
+				a.settle(dropped)
+				if dropped.Response != nil {
+					select {
+					case dropped.Response <- NodeResponse{
+						Success: false,
+						Error:   fmt.Errorf("message dropped from the full mailbox of node %s", a.NodeID),
+					}:
+					default:
+					}
+				}
+			default:
+			}
+		}
+
+	case OverflowFailExecution:
+		overflows.record(a, true)
+		err := bperrors.New(
+			bperrors.ErrorTypeSystem,
+			bperrors.ErrResourceExhausted,
+			fmt.Sprintf("mailbox of node %s is full (capacity %d)", a.NodeID, a.mailboxPolicy.Capacity),
+			bperrors.SeverityCritical,
+		).WithNodeInfo(a.NodeID, "").WithBlueprintInfo(a.bp.ID, a.ExecutionID).WithDetails(map[string]interface{}{
+			"capacity":    a.mailboxPolicy.Capacity,
+			"messageType": msg.Type,
+		})
+		if a.system != nil {
+			a.system.fail(err)
+		}
+		return false, err
+
+	default:
+		overflows.record(a, false)
+		select {
+		case a.mailbox <- msg:
+			return true, nil
+		case <-a.exited:
+			return false, a.exitResponse().Error
+		case <-a.done:
+			return false, fmt.Errorf("actor of node %s stopped", a.NodeID)
+		}
+	}
+}
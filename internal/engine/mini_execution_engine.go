@@ -218,6 +218,9 @@ func (e *MiniExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint
 
 	// Create the node instance
 	nodeInstance := factory()
+	if err := configureNodePins(nodeInstance, nodeConfig, blueprintID, ""); err != nil {
+		return err
+	}
 
 	// Collect input values from connected nodes
 	inputValues := make(map[string]types.Value)
@@ -235,12 +238,16 @@ func (e *MiniExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint
 
 			// Check if we have a value for this pin
 			e.mutex.RLock()
-			if nodeOutputs, exists := e.outputs[sourceNodeID]; exists {
-				if value, exists := nodeOutputs[sourcePinID]; exists {
-					inputValues[targetPinID] = value
+			value, exists := e.outputs[sourceNodeID][sourcePinID]
+			e.mutex.RUnlock()
+
+			if exists {
+				transformed, err := transformInput(conn, value)
+				if err != nil {
+					return err.blueprintError(nodeID, blueprintID, "")
 				}
+				inputValues[targetPinID] = transformed
 			}
-			e.mutex.RUnlock()
 		}
 	}
 
@@ -269,6 +276,14 @@ func (e *MiniExecutionEngine) executeNode(nodeID string, bp *blueprint.Blueprint
 		outputConnections := bp.GetNodeOutputConnections(nodeID)
 		for _, conn := range outputConnections {
 			if conn.ConnectionType == "execution" && conn.SourcePinID == pinID {
+				holds, err := conditionHolds(conn, ctx.GetAllOutputs())
+				if err != nil {
+					return err.blueprintError(nodeID, blueprintID, "")
+				}
+				if !holds {
+					continue
+				}
+
 				targetNodeID := conn.TargetNodeID
 
 				// Execute the target node
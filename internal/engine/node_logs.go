@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"webblueprint/internal/node"
+	"webblueprint/pkg/blueprint"
+)
+
+// logHook persists a log entry of an execution, like Hooks.OnAny
+type logHook func(ctx context.Context, executionID, nodeID, level, message string, details map[string]interface{}) error
+
+// nodeLogger is the logger of a node's execution context. It drops the entries below the
+// log level the node is configured with, tags the rest with the node and persists them
+// with the execution.
+type nodeLogger struct {
+	base        node.Logger
+	executionID string
+	nodeID      string
+	streamRank  int // Least severe level passed on to the base logger
+	persistRank int // Least severe level persisted with the execution
+	persist     logHook
+}
+
+// newNodeLogger creates the logger of a node of an execution. Nodes without a log level
+// stream every entry and persist those of info and above.
+func newNodeLogger(base node.Logger, executionID string, bpNode *blueprint.BlueprintNode, persist logHook) *nodeLogger {
+	l := &nodeLogger{
+		base:        base,
+		executionID: executionID,
+		nodeID:      bpNode.ID,
+		streamRank:  blueprint.LogLevelRank(blueprint.LogLevelDebug),
+		persistRank: blueprint.LogLevelRank(blueprint.LogLevelInfo),
+		persist:     persist,
+	}
+	if rank := blueprint.LogLevelRank(bpNode.GetLogLevel()); rank >= 0 {
+		l.streamRank = rank
+		l.persistRank = rank
+	}
+	return l
+}
+
+// Opts passes options on to the base logger
+func (l *nodeLogger) Opts(options map[string]interface{}) {
+	l.base.Opts(options)
+}
+
+func (l *nodeLogger) Debug(msg string, fields map[string]interface{}) {
+	l.log(blueprint.LogLevelDebug, msg, fields)
+}
+
+func (l *nodeLogger) Info(msg string, fields map[string]interface{}) {
+	l.log(blueprint.LogLevelInfo, msg, fields)
+}
+
+func (l *nodeLogger) Warn(msg string, fields map[string]interface{}) {
+	l.log(blueprint.LogLevelWarn, msg, fields)
+}
+
+func (l *nodeLogger) Error(msg string, fields map[string]interface{}) {
+	l.log(blueprint.LogLevelError, msg, fields)
+}
+
+// log streams and persists an entry if its level is severe enough
+func (l *nodeLogger) log(level, msg string, fields map[string]interface{}) {
+	rank := blueprint.LogLevelRank(level)
+	if rank < l.streamRank && rank < l.persistRank {
+		return
+	}
+
+	// The base logger is shared by the nodes of an execution, so entries carry their node
+	tagged := make(map[string]interface{}, len(fields)+1)
+	for key, value := range fields {
+		tagged[key] = value
+	}
+	tagged["nodeId"] = l.nodeID
+
+	if rank >= l.streamRank {
+		switch level {
+		case blueprint.LogLevelDebug:
+			l.base.Debug(msg, tagged)
+		case blueprint.LogLevelInfo:
+			l.base.Info(msg, tagged)
+		case blueprint.LogLevelWarn:
+			l.base.Warn(msg, tagged)
+		default:
+			l.base.Error(msg, tagged)
+		}
+	}
+
+	if rank >= l.persistRank && l.persist != nil {
+		if err := l.persist(context.Background(), l.executionID, l.nodeID, level, msg, fields); err != nil {
+			l.base.Warn("Failed to persist node log", map[string]interface{}{"nodeId": l.nodeID, "error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"webblueprint/internal/node"
+	"webblueprint/pkg/blueprint"
+)
+
+// SetNodeOverrides replaces node implementations for a single execution. The overrides
+// map node IDs to the factory used instead of the registered one for the node type, e.g.
+// to stub nodes that call external services during test runs. The overrides are dropped
+// when the execution ends.
+func (e *ExecutionEngine) SetNodeOverrides(executionID string, overrides map[string]node.NodeFactory) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(overrides) == 0 {
+		delete(e.overrides, executionID)
+		return
+	}
+
+	copied := make(map[string]node.NodeFactory, len(overrides))
+	for nodeID, factory := range overrides {
+		copied[nodeID] = factory
+	}
+	e.overrides[executionID] = copied
+}
+
+// nodeOverrides returns the node overrides of an execution, if any
+func (e *ExecutionEngine) nodeOverrides(executionID string) map[string]node.NodeFactory {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.overrides[executionID]
+}
+
+// clearNodeOverrides drops the node overrides of an execution
+func (e *ExecutionEngine) clearNodeOverrides(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.overrides, executionID)
+}
+
+// nodeFactory returns the factory for a node of an execution, preferring its override and
+// then the types of the blueprint version the execution runs
+func (e *ExecutionEngine) nodeFactory(blueprintID, executionID string, nodeConfig *blueprint.BlueprintNode) (node.NodeFactory, bool) {
+	overrides := e.nodeOverrides(executionID)
+	e.mutex.RLock()
+	key := e.resolveKey(blueprintID, executionID)
+	e.mutex.RUnlock()
+	blueprintTypes := blueprintNodeTypes(key)
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return resolveNodeFactory(e.nodeFactories(), blueprintTypes, overrides, nodeConfig)
+}
+
+// resolveNodeFactory looks up the override for a node before the factory of its type, the
+// types of the node's blueprint coming first
+func resolveNodeFactory(registry, blueprintTypes, overrides map[string]node.NodeFactory, nodeConfig *blueprint.BlueprintNode) (node.NodeFactory, bool) {
+	if factory, exists := overrides[nodeConfig.ID]; exists {
+		return factory, true
+	}
+	if factory, exists := blueprintTypes[nodeConfig.Type]; exists {
+		return factory, true
+	}
+
+	factory, exists := registry[nodeConfig.Type]
+	return factory, exists
+}
@@ -0,0 +1,293 @@
+package engine
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+	"webblueprint/pkg/blueprint"
+)
+
+// ExecutionPlan is the compiled topology of a blueprint version: its entry points, the
+// connections leading into and out of every node and its variable nodes. Executions read it
+// instead of walking the blueprint again, so it's never modified once compiled, and the
+// slices and nodes it returns are shared by every execution of the version.
+type ExecutionPlan struct {
+	blueprintID string
+	version     string
+	entryPoints []string
+	nodes       map[string]*blueprint.BlueprintNode
+	inputs      map[string][]blueprint.Connection
+	outputs     map[string][]blueprint.Connection
+	setters     []string // Variable setter nodes, run before the getters
+	getters     []string
+
+	// Variable nodes and the data nodes they read from, in stages whose nodes don't depend
+	// on each other and run in parallel
+	variableStages [][]string
+
+	// Connections of the actor system by source node, with their expressions parsed
+	connections map[string][]Connection
+
+	// First connection whose transform or condition doesn't parse, reported by actor mode
+	// executions when they start
+	invalidExpression *connectionExpressionError
+	invalidNodeID     string
+}
+
+// CompilePlan compiles the execution plan of a blueprint
+func CompilePlan(bp *blueprint.Blueprint) *ExecutionPlan {
+	plan := &ExecutionPlan{
+		blueprintID: bp.ID,
+		version:     bp.Version,
+		entryPoints: bp.FindEntryPoints(),
+		nodes:       make(map[string]*blueprint.BlueprintNode, len(bp.Nodes)),
+		inputs:      make(map[string][]blueprint.Connection),
+		outputs:     make(map[string][]blueprint.Connection),
+		connections: make(map[string][]Connection),
+	}
+
+	for i := range bp.Nodes {
+		nodeConfig := &bp.Nodes[i]
+		plan.nodes[nodeConfig.ID] = nodeConfig
+		if strings.HasPrefix(nodeConfig.Type, "set-variable-") {
+			plan.setters = append(plan.setters, nodeConfig.ID)
+		} else if strings.HasPrefix(nodeConfig.Type, "get-variable-") {
+			plan.getters = append(plan.getters, nodeConfig.ID)
+		}
+	}
+
+	for _, conn := range bp.Connections {
+		plan.inputs[conn.TargetNodeID] = append(plan.inputs[conn.TargetNodeID], conn)
+		plan.outputs[conn.SourceNodeID] = append(plan.outputs[conn.SourceNodeID], conn)
+
+		transform, err := parseTransform(conn)
+		if err != nil {
+			plan.invalidate(conn.TargetNodeID, err)
+			continue
+		}
+		condition, err := parseCondition(conn)
+		if err != nil {
+			plan.invalidate(conn.SourceNodeID, err)
+			continue
+		}
+		plan.connections[conn.SourceNodeID] = append(plan.connections[conn.SourceNodeID], Connection{
+			ID:             conn.ID,
+			SourceNodeID:   conn.SourceNodeID,
+			SourcePinID:    conn.SourcePinID,
+			TargetNodeID:   conn.TargetNodeID,
+			TargetPinID:    conn.TargetPinID,
+			ConnectionType: conn.ConnectionType,
+			Transform:      transform,
+			Condition:      condition,
+		})
+	}
+
+	plan.variableStages = compileVariableStages(bp, plan)
+	return plan
+}
+
+// invalidate records the first connection expression that doesn't parse
+func (p *ExecutionPlan) invalidate(nodeID string, err *connectionExpressionError) {
+	if p.invalidExpression == nil {
+		p.invalidExpression = err
+		p.invalidNodeID = nodeID
+	}
+}
+
+// BlueprintID returns the ID of the compiled blueprint
+func (p *ExecutionPlan) BlueprintID() string {
+	return p.blueprintID
+}
+
+// Version returns the version of the compiled blueprint
+func (p *ExecutionPlan) Version() string {
+	return p.version
+}
+
+// EntryPoints returns the nodes executions start from
+func (p *ExecutionPlan) EntryPoints() []string {
+	return p.entryPoints
+}
+
+// Node returns the configuration of a node
+func (p *ExecutionPlan) Node(nodeID string) *blueprint.BlueprintNode {
+	return p.nodes[nodeID]
+}
+
+// InputConnections returns the connections leading into a node, in blueprint order
+func (p *ExecutionPlan) InputConnections(nodeID string) []blueprint.Connection {
+	return p.inputs[nodeID]
+}
+
+// OutputConnections returns the connections leading out of a node, in blueprint order
+func (p *ExecutionPlan) OutputConnections(nodeID string) []blueprint.Connection {
+	return p.outputs[nodeID]
+}
+
+// VariableNodes returns the variable setter and getter nodes, which run before the entry points
+func (p *ExecutionPlan) VariableNodes() (setters, getters []string) {
+	return p.setters, p.getters
+}
+
+// VariableStages returns the variable nodes and the data nodes they read from in the order
+// they're preprocessed: the nodes of a stage run in parallel once the previous stage is done
+func (p *ExecutionPlan) VariableStages() [][]string {
+	return p.variableStages
+}
+
+// cachedPlan is the plan compiled for a loaded version of a blueprint
+type cachedPlan struct {
+	source      *blueprint.Blueprint
+	fingerprint uint64
+	plan        *ExecutionPlan
+}
+
+// Plan returns the execution plan compiled for a loaded version of a blueprint
+func (e *ExecutionEngine) Plan(blueprintID, version string) (*ExecutionPlan, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	cached, exists := e.plans[blueprintKey{id: blueprintID, version: version}]
+	if !exists {
+		return nil, false
+	}
+	return cached.plan, true
+}
+
+// planFor returns the execution plan of a blueprint, compiling it unless the plan of its
+// version was compiled from the same blueprint. Blueprints loaded again, e.g. read from the
+// database for every execution, reuse the plan as long as their content is the same.
+func (e *ExecutionEngine) planFor(bp *blueprint.Blueprint) *ExecutionPlan {
+	key := keyOf(bp)
+	e.mutex.RLock()
+	cached, exists := e.plans[key]
+	e.mutex.RUnlock()
+	if exists && cached.source == bp {
+		return cached.plan
+	}
+
+	fingerprint := fingerprintBlueprint(bp)
+	if exists && fingerprint != 0 && cached.fingerprint == fingerprint {
+		e.mutex.Lock()
+		e.plans[key] = &cachedPlan{source: bp, fingerprint: fingerprint, plan: cached.plan}
+		e.mutex.Unlock()
+		return cached.plan
+	}
+
+	plan := CompilePlan(bp)
+	e.mutex.Lock()
+	e.plans[key] = &cachedPlan{source: bp, fingerprint: fingerprint, plan: plan}
+	e.mutex.Unlock()
+	return plan
+}
+
+// fingerprintBlueprint hashes what a plan is compiled from. Blueprints that can't be
+// encoded get a zero fingerprint, which never matches a cached plan.
+func fingerprintBlueprint(bp *blueprint.Blueprint) uint64 {
+	hash := fnv.New64a()
+	err := json.NewEncoder(hash).Encode(struct {
+		Nodes       []blueprint.BlueprintNode `json:"nodes"`
+		Connections []blueprint.Connection    `json:"connections"`
+	}{bp.Nodes, bp.Connections})
+	if err != nil {
+		return 0
+	}
+	return hash.Sum64()
+}
+
+// isPreprocessedNode reports whether nodes of a type run before the entry points when
+// variable nodes read from them
+func isPreprocessedNode(nodeType string) bool {
+	return strings.HasPrefix(nodeType, "constant-") ||
+		strings.HasPrefix(nodeType, "get-variable-") ||
+		strings.HasPrefix(nodeType, "set-variable-")
+}
+
+// compileVariableStages orders the variable nodes and the data nodes they read from by their
+// dependencies: a node follows the nodes its data inputs are connected to, the setters of a
+// variable run in blueprint order and its getters follow all of them. Nodes on a cycle run
+// one at a time after the others, in blueprint order.
+func compileVariableStages(bp *blueprint.Blueprint, plan *ExecutionPlan) [][]string {
+	members := make(map[string]bool)
+	pending := append(append([]string(nil), plan.setters...), plan.getters...)
+	for len(pending) > 0 {
+		nodeID := pending[0]
+		pending = pending[1:]
+		if members[nodeID] {
+			continue
+		}
+		members[nodeID] = true
+		for _, conn := range plan.inputs[nodeID] {
+			source := plan.nodes[conn.SourceNodeID]
+			if conn.ConnectionType == "data" && source != nil && isPreprocessedNode(source.Type) {
+				pending = append(pending, source.ID)
+			}
+		}
+	}
+
+	dependencies := make(map[string]map[string]bool, len(members))
+	depend := func(nodeID, dependencyID string) {
+		if nodeID == dependencyID {
+			return
+		}
+		if dependencies[nodeID] == nil {
+			dependencies[nodeID] = make(map[string]bool)
+		}
+		dependencies[nodeID][dependencyID] = true
+	}
+	for nodeID := range members {
+		for _, conn := range plan.inputs[nodeID] {
+			if conn.ConnectionType == "data" && members[conn.SourceNodeID] {
+				depend(nodeID, conn.SourceNodeID)
+			}
+		}
+	}
+
+	settersOf := make(map[string][]string)
+	for _, nodeID := range plan.setters {
+		variable := strings.TrimPrefix(plan.nodes[nodeID].Type, "set-variable-")
+		if previous := settersOf[variable]; len(previous) > 0 {
+			depend(nodeID, previous[len(previous)-1])
+		}
+		settersOf[variable] = append(settersOf[variable], nodeID)
+	}
+	for _, nodeID := range plan.getters {
+		for _, setterID := range settersOf[strings.TrimPrefix(plan.nodes[nodeID].Type, "get-variable-")] {
+			depend(nodeID, setterID)
+		}
+	}
+
+	stages := make([][]string, 0)
+	done := make(map[string]bool, len(members))
+	for len(done) < len(members) {
+		stage := make([]string, 0)
+		for _, nodeConfig := range bp.Nodes {
+			if !members[nodeConfig.ID] || done[nodeConfig.ID] {
+				continue
+			}
+			ready := true
+			for dependencyID := range dependencies[nodeConfig.ID] {
+				if !done[dependencyID] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				stage = append(stage, nodeConfig.ID)
+			}
+		}
+		if len(stage) == 0 {
+			break
+		}
+		for _, nodeID := range stage {
+			done[nodeID] = true
+		}
+		stages = append(stages, stage)
+	}
+
+	for _, nodeConfig := range bp.Nodes {
+		if members[nodeConfig.ID] && !done[nodeConfig.ID] {
+			stages = append(stages, []string{nodeConfig.ID})
+		}
+	}
+	return stages
+}
@@ -0,0 +1,258 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/event"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// variablesBlueprint creates a blueprint of the given nodes, with data connections from
+// the "value" output of a source to the "value" input of a target
+func variablesBlueprint(nodes [][2]string, connections ...[2]string) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("variables", "Variables", "1.0.0")
+	for _, nodeConfig := range nodes {
+		bp.AddNode(blueprint.BlueprintNode{ID: nodeConfig[0], Type: nodeConfig[1]})
+	}
+	for _, conn := range connections {
+		bp.AddConnection(blueprint.Connection{
+			ID:             conn[0] + "-" + conn[1],
+			SourceNodeID:   conn[0],
+			SourcePinID:    "value",
+			TargetNodeID:   conn[1],
+			TargetPinID:    "value",
+			ConnectionType: "data",
+		})
+	}
+	return bp
+}
+
+func TestCompileVariableStages(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		nodes       [][2]string // ID and type of the nodes, in blueprint order
+		connections [][2]string // Data connections from a source to a target
+		expected    [][]string
+	}{
+		{
+			name:     "no variable nodes",
+			nodes:    [][2]string{{"print", "print"}},
+			expected: [][]string{},
+		},
+		{
+			name: "setters of different variables run together",
+			nodes: [][2]string{
+				{"set-a", "set-variable-a"},
+				{"set-b", "set-variable-b"},
+			},
+			expected: [][]string{{"set-a", "set-b"}},
+		},
+		{
+			name: "setters of a variable run in blueprint order",
+			nodes: [][2]string{
+				{"set-a-2", "set-variable-a"},
+				{"set-b", "set-variable-b"},
+				{"set-a-1", "set-variable-a"},
+			},
+			expected: [][]string{{"set-a-2", "set-b"}, {"set-a-1"}},
+		},
+		{
+			name: "getters run after all setters of their variable",
+			nodes: [][2]string{
+				{"set-a-1", "set-variable-a"},
+				{"get-a", "get-variable-a"},
+				{"get-b", "get-variable-b"},
+				{"set-a-2", "set-variable-a"},
+			},
+			expected: [][]string{{"set-a-1", "get-b"}, {"set-a-2"}, {"get-a"}},
+		},
+		{
+			name: "data nodes run before the nodes reading them",
+			nodes: [][2]string{
+				{"set-a", "set-variable-a"},
+				{"one", "constant-number"},
+				{"get-a", "get-variable-a"},
+				{"set-b", "set-variable-b"},
+				{"print", "print"},
+			},
+			connections: [][2]string{{"one", "set-a"}, {"get-a", "set-b"}, {"print", "set-b"}},
+			expected:    [][]string{{"one"}, {"set-a"}, {"get-a"}, {"set-b"}},
+		},
+		{
+			name: "unread data nodes don't run",
+			nodes: [][2]string{
+				{"one", "constant-number"},
+				{"set-a", "set-variable-a"},
+			},
+			expected: [][]string{{"set-a"}},
+		},
+		{
+			name: "cycles run one node at a time after the others",
+			nodes: [][2]string{
+				{"set-a", "set-variable-a"},
+				{"set-b", "set-variable-b"},
+				{"get-a", "get-variable-a"},
+				{"get-b", "get-variable-b"},
+				{"set-c", "set-variable-c"},
+			},
+			connections: [][2]string{{"get-b", "set-a"}, {"get-a", "set-b"}},
+			expected:    [][]string{{"set-c"}, {"set-a"}, {"set-b"}, {"get-a"}, {"get-b"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			bp := variablesBlueprint(test.nodes, test.connections...)
+			stages := CompilePlan(bp).VariableStages()
+			if !reflect.DeepEqual(stages, test.expected) {
+				t.Errorf("expected stages %v, got %v", test.expected, stages)
+			}
+		})
+	}
+}
+
+// discardLogger discards the log of the engine
+type discardLogger struct{}
+
+func (discardLogger) Opts(map[string]interface{})          {}
+func (discardLogger) Debug(string, map[string]interface{}) {}
+func (discardLogger) Info(string, map[string]interface{})  {}
+func (discardLogger) Warn(string, map[string]interface{})  {}
+func (discardLogger) Error(string, map[string]interface{}) {}
+
+// variableNode reads and writes the variable of its type: setters append their value input
+// to the variable, and getters output it
+type variableNode struct {
+	node.BaseNode
+	variable string
+	setter   bool
+}
+
+func newVariableNode(variable string, setter bool) node.NodeFactory {
+	return func() node.Node {
+		typeID := "get-variable-" + variable
+		if setter {
+			typeID = "set-variable-" + variable
+		}
+		return &variableNode{
+			BaseNode: node.BaseNode{
+				Metadata: node.NodeMetadata{TypeID: typeID, Name: typeID},
+				Inputs:   []types.Pin{{ID: "value", Name: "Value", Type: types.PinTypes.Any, Optional: true}},
+				Outputs:  []types.Pin{{ID: "value", Name: "Value", Type: types.PinTypes.Any}},
+			},
+			variable: variable,
+			setter:   setter,
+		}
+	}
+}
+
+func (n *variableNode) Execute(ctx node.ExecutionContext) error {
+	current := ""
+	if value, exists := ctx.GetVariable(n.variable); exists {
+		current, _ = value.RawValue.(string)
+	}
+	if n.setter {
+		input, _ := ctx.GetInputValue("value")
+		current += fmt.Sprint(input.RawValue) + ";"
+		ctx.SetVariable(n.variable, types.NewValue(types.PinTypes.String, current))
+	}
+	ctx.SetOutputValue("value", types.NewValue(types.PinTypes.String, current))
+	return nil
+}
+
+// oneNode is a constant node that outputs 1
+type oneNode struct {
+	node.BaseNode
+}
+
+func newOneNode() node.Node {
+	return &oneNode{BaseNode: node.BaseNode{
+		Metadata: node.NodeMetadata{TypeID: "constant-one", Name: "One"},
+		Outputs:  []types.Pin{{ID: "value", Name: "Value", Type: types.PinTypes.Number}},
+	}}
+}
+
+func (n *oneNode) Execute(ctx node.ExecutionContext) error {
+	ctx.SetOutputValue("value", types.NewValue(types.PinTypes.Number, 1.0))
+	return nil
+}
+
+// newVariablesEngine creates an engine that runs the variable nodes of the given variables
+// and the constant 1
+func newVariablesEngine(variables ...string) *ExecutionEngine {
+	errorManager := bperrors.NewErrorManager()
+	recoveryManager := bperrors.NewRecoveryManager(errorManager)
+	flowEngine := NewExecutionEngine(discardLogger{}, NewDebugManager())
+	eventManager := event.NewEventManager(flowEngine)
+	contextManager := engineext.NewContextManager(errorManager, recoveryManager, eventManager.AsEventManagerInterface(), nil)
+	flowEngine.SetExtensions(engineext.InitializeExtensions(flowEngine, contextManager, errorManager, recoveryManager, eventManager))
+
+	flowEngine.RegisterNodeType("constant-one", newOneNode)
+	for _, variable := range variables {
+		flowEngine.RegisterNodeType("set-variable-"+variable, newVariableNode(variable, true))
+		flowEngine.RegisterNodeType("get-variable-"+variable, newVariableNode(variable, false))
+	}
+	return flowEngine
+}
+
+// processVariableNodesSerially runs the setters and then the getters one at a time, in
+// blueprint order, the way variable nodes were preprocessed before they ran in stages
+func processVariableNodesSerially(e *ExecutionEngine, bp *blueprint.Blueprint, executionID string, variables map[string]types.Value) error {
+	setters, getters := e.planFor(bp).VariableNodes()
+	for _, nodeID := range append(append([]string(nil), setters...), getters...) {
+		if err := e.executeVariableNode(nodeID, bp, executionID, variables, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVariableStagesMatchSerialProcessing(t *testing.T) {
+	flowEngine := newVariablesEngine("a", "b", "c")
+
+	// Setters of a and b run in parallel stages, the setters of a in blueprint order, and
+	// b and c read the value of a once all its setters ran
+	bp := variablesBlueprint(
+		[][2]string{
+			{"one", "constant-one"},
+			{"set-a-1", "set-variable-a"},
+			{"set-b-1", "set-variable-b"},
+			{"set-a-2", "set-variable-a"},
+			{"get-a", "get-variable-a"},
+			{"set-b-2", "set-variable-b"},
+			{"set-c", "set-variable-c"},
+			{"get-b", "get-variable-b"},
+		},
+		[2]string{"one", "set-a-1"},
+		[2]string{"one", "set-b-1"},
+		[2]string{"one", "set-a-2"},
+		[2]string{"get-a", "set-b-2"},
+		[2]string{"get-b", "set-c"},
+	)
+	if len(CompilePlan(bp).VariableStages()) < 2 {
+		t.Fatal("expected the variable nodes to run in several stages")
+	}
+
+	staged := make(map[string]types.Value)
+	if err := flowEngine.processVariableNodes(bp, "staged", staged); err != nil {
+		t.Fatalf("failed to process the variable nodes in stages: %v", err)
+	}
+	serial := make(map[string]types.Value)
+	if err := processVariableNodesSerially(flowEngine, bp, "serial", serial); err != nil {
+		t.Fatalf("failed to process the variable nodes serially: %v", err)
+	}
+
+	// Setters append their input to the variable, so the values record the order they ran in
+	expected := map[string]interface{}{"a": "1;1;", "b": "1;1;1;;", "c": "1;1;1;;;"}
+	for name, value := range expected {
+		if serial[name].RawValue != value {
+			t.Errorf("variable %s: expected %v from the serial processing, got %v", name, value, serial[name].RawValue)
+		}
+		if staged[name].RawValue != value {
+			t.Errorf("variable %s: expected %v like the serial processing, got %v", name, value, staged[name].RawValue)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package engine
+
+import (
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxProfileRecords bounds the node executions profiled per execution
+const maxProfileRecords = 10000
+
+// SpeedscopeSchema is the schema of the speedscope file format
+const SpeedscopeSchema = "https://www.speedscope.app/file-format-schema.json"
+
+// ProfileSample is the cost of the node executions sharing a stack in a profiled execution.
+// Time and allocations are the node's own: in standard mode nodes run their downstream
+// nodes through their output flows, which counts for those nodes only.
+type ProfileSample struct {
+	Stack        []string `json:"stack"` // Node types from the entry node down to the node
+	Executions   int      `json:"executions"`
+	SelfMs       float64  `json:"selfMs"`
+	AllocBytes   uint64   `json:"allocBytes"`
+	AllocObjects uint64   `json:"allocObjects"`
+}
+
+// NodeTypeProfile is the cost of all the executions of a node type in a profiled execution
+type NodeTypeProfile struct {
+	NodeType     string  `json:"nodeType"`
+	Executions   int     `json:"executions"`
+	SelfMs       float64 `json:"selfMs"`
+	AllocBytes   uint64  `json:"allocBytes"`
+	AllocObjects uint64  `json:"allocObjects"`
+}
+
+// ExecutionProfile holds the time and allocations of the node executions of a profiled
+// execution. Allocations are read from the counters of the process, so nodes running
+// alongside other work, e.g. in the actor mode or next to other executions, are charged
+// with its allocations too.
+type ExecutionProfile struct {
+	ExecutionID string          `json:"executionId"`
+	StartedAt   time.Time       `json:"startedAt"`
+	Samples     []ProfileSample `json:"samples"`   // Ordered by stack
+	Truncated   bool            `json:"truncated"` // Executions beyond maxProfileRecords weren't profiled
+}
+
+// profileRecord is the cost of a node execution
+type profileRecord struct {
+	span         int // Span of the node execution in the timeline, -1 if it has none
+	nodeType     string
+	self         time.Duration
+	allocBytes   uint64
+	allocObjects uint64
+}
+
+// profileSession profiles the node executions of a single execution
+type profileSession struct {
+	startedAt time.Time
+	records   []profileRecord
+	truncated bool
+	mutex     sync.Mutex
+}
+
+// StartProfiling profiles the node executions of an execution until TakeProfile is called.
+// It does nothing if the execution is already profiled.
+func (e *ExecutionEngine) StartProfiling(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exists := e.profiles[executionID]; exists {
+		return
+	}
+	e.profiles[executionID] = &profileSession{
+		startedAt: time.Now(),
+		records:   make([]profileRecord, 0),
+	}
+}
+
+// profileSession returns the profile session of an execution, if it's profiled
+func (e *ExecutionEngine) profileSession(executionID string) *profileSession {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.profiles[executionID]
+}
+
+// TakeProfile ends the profiling of an execution and returns its profile, with the stacks
+// of the node executions taken from the timeline of the execution
+func (e *ExecutionEngine) TakeProfile(executionID string) (*ExecutionProfile, bool) {
+	e.mutex.Lock()
+	session, exists := e.profiles[executionID]
+	delete(e.profiles, executionID)
+	e.mutex.Unlock()
+
+	if !exists {
+		return nil, false
+	}
+
+	var spans []TimelineSpan
+	if timeline, recorded := e.debugManager.GetExecutionTimeline(executionID); recorded {
+		spans = timeline.Spans
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	profile := &ExecutionProfile{
+		ExecutionID: executionID,
+		StartedAt:   session.startedAt,
+		Samples:     make([]ProfileSample, 0),
+		Truncated:   session.truncated,
+	}
+	samples := make(map[string]*ProfileSample)
+	for _, record := range session.records {
+		stack := profileStack(spans, record)
+		key := strings.Join(stack, "\x00")
+		sample, exists := samples[key]
+		if !exists {
+			sample = &ProfileSample{Stack: stack}
+			samples[key] = sample
+		}
+		sample.Executions++
+		sample.SelfMs += float64(record.self) / float64(time.Millisecond)
+		sample.AllocBytes += record.allocBytes
+		sample.AllocObjects += record.allocObjects
+	}
+	for _, sample := range samples {
+		profile.Samples = append(profile.Samples, *sample)
+	}
+	sort.Slice(profile.Samples, func(i, j int) bool {
+		return strings.Join(profile.Samples[i].Stack, "\x00") < strings.Join(profile.Samples[j].Stack, "\x00")
+	})
+	return profile, true
+}
+
+// profileStack returns the node types from the entry node down to the node of a record,
+// following the nodes that triggered each node execution
+func profileStack(spans []TimelineSpan, record profileRecord) []string {
+	stack := []string{record.nodeType}
+	for index := record.span; index >= 0 && index < len(spans); {
+		trigger := spans[index].TriggeredBy
+		if trigger == nil || trigger.SpanIndex < 0 || trigger.SpanIndex >= index {
+			break
+		}
+		index = trigger.SpanIndex
+		stack = append(stack, spans[index].NodeType)
+	}
+
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack
+}
+
+// record adds the cost of a node execution measured by its sandbox
+func (s *profileSession) record(span int, nodeType string, sandbox *nodeSandbox) {
+	if s == nil {
+		return
+	}
+	self, allocBytes, allocObjects := sandbox.usage()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.records) >= maxProfileRecords {
+		s.truncated = true
+		return
+	}
+	s.records = append(s.records, profileRecord{
+		span:         span,
+		nodeType:     nodeType,
+		self:         self,
+		allocBytes:   allocBytes,
+		allocObjects: allocObjects,
+	})
+}
+
+// readAllocs returns the bytes and objects allocated on the heap by the process so far
+func readAllocs() (uint64, uint64) {
+	samples := []metrics.Sample{
+		{Name: "/gc/heap/allocs:bytes"},
+		{Name: "/gc/heap/allocs:objects"},
+	}
+	metrics.Read(samples)
+
+	var bytes, objects uint64
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		bytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		objects = samples[1].Value.Uint64()
+	}
+	return bytes, objects
+}
+
+// NodeTypes adds up the samples of the profile by node type, the most time first
+func (p *ExecutionProfile) NodeTypes() []NodeTypeProfile {
+	totals := make(map[string]*NodeTypeProfile)
+	for _, sample := range p.Samples {
+		nodeType := sample.Stack[len(sample.Stack)-1]
+		total, exists := totals[nodeType]
+		if !exists {
+			total = &NodeTypeProfile{NodeType: nodeType}
+			totals[nodeType] = total
+		}
+		total.Executions += sample.Executions
+		total.SelfMs += sample.SelfMs
+		total.AllocBytes += sample.AllocBytes
+		total.AllocObjects += sample.AllocObjects
+	}
+
+	result := make([]NodeTypeProfile, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SelfMs != result[j].SelfMs {
+			return result[i].SelfMs > result[j].SelfMs
+		}
+		return result[i].NodeType < result[j].NodeType
+	})
+	return result
+}
+
+// SpeedscopeFile is a profile in the speedscope file format
+type SpeedscopeFile struct {
+	Schema             string              `json:"$schema"`
+	Name               string              `json:"name"`
+	Exporter           string              `json:"exporter"`
+	ActiveProfileIndex int                 `json:"activeProfileIndex"`
+	Shared             SpeedscopeShared    `json:"shared"`
+	Profiles           []SpeedscopeProfile `json:"profiles"`
+}
+
+// SpeedscopeShared holds the frames the profiles of a speedscope file refer to
+type SpeedscopeShared struct {
+	Frames []SpeedscopeFrame `json:"frames"`
+}
+
+// SpeedscopeFrame is a frame of a speedscope profile, a node type
+type SpeedscopeFrame struct {
+	Name string `json:"name"`
+}
+
+// SpeedscopeProfile is a sampled speedscope profile: stacks of frame indexes, each with
+// its weight
+type SpeedscopeProfile struct {
+	Type       string    `json:"type"`
+	Name       string    `json:"name"`
+	Unit       string    `json:"unit"`
+	StartValue float64   `json:"startValue"`
+	EndValue   float64   `json:"endValue"`
+	Samples    [][]int   `json:"samples"`
+	Weights    []float64 `json:"weights"`
+}
+
+// Speedscope returns the profile in the speedscope format, as flame graphs of the time and
+// of the allocated bytes of the node types
+func (p *ExecutionProfile) Speedscope() *SpeedscopeFile {
+	file := &SpeedscopeFile{
+		Schema:   SpeedscopeSchema,
+		Name:     "Execution " + p.ExecutionID,
+		Exporter: "webblueprint",
+		Shared:   SpeedscopeShared{Frames: make([]SpeedscopeFrame, 0)},
+	}
+
+	frames := make(map[string]int)
+	stacks := make([][]int, 0, len(p.Samples))
+	for _, sample := range p.Samples {
+		stack := make([]int, len(sample.Stack))
+		for i, nodeType := range sample.Stack {
+			index, exists := frames[nodeType]
+			if !exists {
+				index = len(file.Shared.Frames)
+				frames[nodeType] = index
+				file.Shared.Frames = append(file.Shared.Frames, SpeedscopeFrame{Name: nodeType})
+			}
+			stack[i] = index
+		}
+		stacks = append(stacks, stack)
+	}
+
+	timeProfile := SpeedscopeProfile{Type: "sampled", Name: "Time", Unit: "milliseconds", Samples: stacks, Weights: make([]float64, 0, len(stacks))}
+	allocProfile := SpeedscopeProfile{Type: "sampled", Name: "Allocations", Unit: "bytes", Samples: stacks, Weights: make([]float64, 0, len(stacks))}
+	for _, sample := range p.Samples {
+		timeProfile.Weights = append(timeProfile.Weights, sample.SelfMs)
+		timeProfile.EndValue += sample.SelfMs
+		allocProfile.Weights = append(allocProfile.Weights, float64(sample.AllocBytes))
+		allocProfile.EndValue += float64(sample.AllocBytes)
+	}
+	file.Profiles = []SpeedscopeProfile{timeProfile, allocProfile}
+	return file
+}
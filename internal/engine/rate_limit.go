@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"time"
+	"webblueprint/internal/core"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/models"
+)
+
+// timerService returns the timer service of rate limited nodes, if any
+func (e *ExecutionEngine) timerService() *engineext.TimerService {
+	extensions := e.GetExtensions()
+	if extensions == nil {
+		return nil
+	}
+	return extensions.GetTimerService()
+}
+
+// isTimerTrigger reports whether a node runs because its rate limit timer fired
+func isTimerTrigger(triggerCtx *core.EventHandlerContext) bool {
+	return triggerCtx != nil && triggerCtx.EventID == engineext.TimerEventID
+}
+
+// passRateLimit hands a trigger of a rate limited node to the timer service and reports
+// whether the node runs now. Other nodes always run, as do all nodes without a timer service.
+func passRateLimit(timers *engineext.TimerService, nodeInstance node.Node, blueprintID, nodeID, executionID string, inputs map[string]types.Value) (bool, error) {
+	limited, ok := nodeInstance.(node.RateLimitedNode)
+	if !ok || timers == nil {
+		return true, nil
+	}
+
+	rawInputs := make(models.JSONB, len(inputs))
+	for pinID, value := range inputs {
+		rawInputs[pinID] = value.RawValue
+	}
+
+	limit := limited.RateLimit()
+	if limit.Mode == node.RateLimitThrottle {
+		return timers.Throttle(blueprintID, nodeID, executionID, limit.Interval, limit.Trailing, rawInputs)
+	}
+	return false, timers.Debounce(blueprintID, nodeID, executionID, limit.Interval, rawInputs)
+}
+
+// fireTimer runs a rate limited node with the inputs of its latest trigger once its timer fired
+func (e *ExecutionEngine) fireTimer(timer models.NodeTimer, storeErr error) {
+	if storeErr != nil {
+		e.logger.Warn("Failed to drop the persisted node timer", map[string]interface{}{"nodeId": timer.NodeID, "error": storeErr.Error()})
+	}
+
+	parameters := make(map[string]types.Value, len(timer.Inputs)+1)
+	for pinID, value := range timer.Inputs {
+		parameters[pinID] = types.NewValue(types.PinTypes.Any, value)
+	}
+	parameters[engineext.TimerTriggersParameter] = types.NewValue(types.PinTypes.Number, float64(timer.Triggers))
+
+	err := e.TriggerNodeExecution(timer.BlueprintID, timer.NodeID, core.EventHandlerContext{
+		EventID:     engineext.TimerEventID,
+		Parameters:  parameters,
+		SourceID:    timer.NodeID,
+		BlueprintID: timer.BlueprintID,
+		ExecutionID: timer.ExecutionID,
+		HandlerID:   timer.NodeID,
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		e.logger.Error("Failed to run node after its timer fired", map[string]interface{}{"nodeId": timer.NodeID, "mode": timer.Mode, "error": err.Error()})
+	}
+}
@@ -0,0 +1,303 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/common"
+	"webblueprint/internal/core"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// retryBackoff is the delay before the first retry of a node, doubled for every further retry
+const retryBackoff = 100 * time.Millisecond
+
+// recoveryState applies the recovery strategies registered for node errors to an execution
+// and keeps track of the recoveries that changed its control flow
+type recoveryState struct {
+	blueprintID     string
+	executionID     string
+	errorManager    *bperrors.ErrorManager
+	recoveryManager *bperrors.RecoveryManager
+	onRecovered     func(recovery common.NodeRecovery)
+	applied         []common.NodeRecovery
+	mutex           sync.Mutex
+}
+
+// strategy records the error of a node and returns the recovery strategy chosen for it, or
+// RecoveryNone if the error can't be recovered. Errors propagated from downstream nodes are
+// left to the node that failed.
+func (s *recoveryState) strategy(nodeID string, err error) (bperrors.RecoveryStrategy, *bperrors.BlueprintError) {
+	var propagated *propagatedError
+	if s == nil || errors.As(err, &propagated) {
+		return bperrors.RecoveryNone, nil
+	}
+
+	bpErr := bperrors.From(err)
+	if bpErr.NodeID == "" {
+		bpErr.NodeID = nodeID
+	}
+	if bpErr.ExecutionID == "" {
+		bpErr.WithBlueprintInfo(s.blueprintID, s.executionID)
+	}
+
+	s.errorManager.RecordError(s.executionID, bpErr)
+	recovered, details := s.recoveryManager.RecoverFromError(s.executionID, bpErr)
+	if !recovered {
+		return bperrors.RecoveryNone, bpErr
+	}
+
+	strategy, _ := details["strategy"].(string)
+	return bperrors.RecoveryStrategy(strategy), bpErr
+}
+
+// record stores a recovery applied to the error of a node
+func (s *recoveryState) record(nodeID string, strategy bperrors.RecoveryStrategy, bpErr *bperrors.BlueprintError) {
+	recovery := common.NodeRecovery{
+		NodeID:    nodeID,
+		Strategy:  string(strategy),
+		ErrorCode: string(bpErr.Code),
+		Error:     bpErr.Message,
+		Timestamp: time.Now(),
+	}
+
+	s.mutex.Lock()
+	s.applied = append(s.applied, recovery)
+	s.mutex.Unlock()
+
+	if s.onRecovered != nil {
+		s.onRecovered(recovery)
+	}
+}
+
+// retryDelay returns the backoff before the latest retry of a node
+func (s *recoveryState) retryDelay(nodeID string) time.Duration {
+	attempts := s.recoveryManager.CountRecoveryAttempts(s.executionID, nodeID, bperrors.RecoveryRetry)
+	if attempts < 1 {
+		attempts = 1
+	}
+	return retryBackoff << (attempts - 1)
+}
+
+// recoveries returns the recoveries applied so far
+func (s *recoveryState) recoveries() []common.NodeRecovery {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]common.NodeRecovery(nil), s.applied...)
+}
+
+// successFlow returns the execution output a node activates when it succeeds, if any
+func successFlow(pins []types.Pin) string {
+	for _, pin := range pins {
+		if pin.Type != nil && pin.Type.ID == types.PinTypes.Execution.ID && pin.ID != bperrors.CatchPinID {
+			return pin.ID
+		}
+	}
+	return ""
+}
+
+// hasPin checks if a node declares an output pin
+func hasPin(pins []types.Pin, pinID string) bool {
+	for _, pin := range pins {
+		if pin.ID == pinID {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultOutputs returns the default values of the data outputs a node didn't set
+func (s *recoveryState) defaultOutputs(pins []types.Pin, isSet func(pinID string) bool) map[string]types.Value {
+	defaults := make(map[string]types.Value)
+	for _, pin := range pins {
+		if pin.Type == nil || pin.Type.ID == types.PinTypes.Execution.ID || isSet(pin.ID) {
+			continue
+		}
+		if value, err := s.recoveryManager.GetDefaultValue(pin.Type); err == nil {
+			defaults[pin.ID] = value
+		}
+	}
+	return defaults
+}
+
+// setRecovery applies the registered recovery strategies to the node errors of an execution,
+// provided the engine extensions carry the error and recovery managers
+func (e *ExecutionEngine) setRecovery(executionID string, bp *blueprint.Blueprint) {
+	extensions := e.GetExtensions()
+	if extensions == nil || extensions.ErrorManager == nil || extensions.RecoveryManager == nil {
+		return
+	}
+
+	state := &recoveryState{
+		blueprintID:     bp.ID,
+		executionID:     executionID,
+		errorManager:    extensions.ErrorManager,
+		recoveryManager: extensions.RecoveryManager,
+		onRecovered: func(recovery common.NodeRecovery) {
+			details := map[string]interface{}{
+				"strategy":  recovery.Strategy,
+				"errorCode": recovery.ErrorCode,
+				"error":     recovery.Error,
+			}
+			e.EmitEvent(ExecutionEvent{
+				Type:      EventNodeRecovered,
+				Timestamp: recovery.Timestamp,
+				NodeID:    recovery.NodeID,
+				Data:      details,
+			})
+			if onAny := e.currentHooks().OnAny; onAny != nil {
+				_ = onAny(context.Background(), executionID, recovery.NodeID, "warn", string(EventNodeRecovered), details)
+			}
+		},
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.recoveries[executionID] = state
+}
+
+// recovery returns the recovery state of an execution, if any
+func (e *ExecutionEngine) recovery(executionID string) *recoveryState {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.recoveries[executionID]
+}
+
+// clearRecovery drops the recovery state and the recovery attempts of an execution
+func (e *ExecutionEngine) clearRecovery(executionID string) {
+	e.mutex.Lock()
+	state := e.recoveries[executionID]
+	delete(e.recoveries, executionID)
+	e.mutex.Unlock()
+
+	if state != nil {
+		state.recoveryManager.ClearRecoveryAttempts(executionID)
+	}
+}
+
+// recoverNodeError applies the recovery strategy registered for the error of a node in the
+// standard engine. It reports whether the node recovered, along with the error of the flow
+// the node continued with.
+func (e *ExecutionEngine) recoverNodeError(nodeID string, err error, nodeInstance node.Node, ctx node.ExecutionContext, bp *blueprint.Blueprint, blueprintID, executionID string, variables map[string]types.Value, hooks *node.ExecutionHooks, triggerCtx *core.EventHandlerContext, scope *executionScope) (bool, error) {
+	state := e.recovery(executionID)
+	strategy, bpErr := state.strategy(nodeID, err)
+	outputPins := nodeInstance.GetOutputPins()
+
+	switch strategy {
+	case bperrors.RecoveryRetry:
+		state.record(nodeID, strategy, bpErr)
+		time.Sleep(state.retryDelay(nodeID))
+		return true, e.executeNode(nodeID, bp, blueprintID, executionID, variables, hooks, triggerCtx, scope)
+
+	case bperrors.RecoverySkipNode:
+		state.record(nodeID, strategy, bpErr)
+		return true, activateRecoveryFlow(ctx, successFlow(outputPins))
+
+	case bperrors.RecoveryUseDefaultValue:
+		isSet := func(pinID string) bool { return false }
+		if extCtx := engineext.GetExtendedContext(ctx); extCtx != nil {
+			outputs := extCtx.GetAllOutputs()
+			isSet = func(pinID string) bool {
+				_, exists := outputs[pinID]
+				return exists
+			}
+		}
+		for pinID, value := range state.defaultOutputs(outputPins, isSet) {
+			ctx.SetOutputValue(pinID, value)
+		}
+
+		state.record(nodeID, strategy, bpErr)
+		return true, activateRecoveryFlow(ctx, successFlow(outputPins))
+
+	case bperrors.RecoveryAlternatePath:
+		// Only nodes with a catch flow have an alternate path
+		if !hasPin(outputPins, bperrors.CatchPinID) {
+			return false, nil
+		}
+		if hasPin(outputPins, bperrors.ErrorPinID) {
+			bperrors.SetErrorOutput(ctx, bpErr)
+		}
+
+		state.record(nodeID, strategy, bpErr)
+		return true, activateRecoveryFlow(ctx, bperrors.CatchPinID)
+	}
+
+	return false, nil
+}
+
+// activateRecoveryFlow continues the execution of a recovered node along an output flow
+func activateRecoveryFlow(ctx node.ExecutionContext, pinID string) error {
+	if pinID == "" {
+		return nil
+	}
+	return ctx.ActivateOutputFlow(pinID)
+}
+
+// recoverNodeError applies the recovery strategy registered for the error of a node in the
+// actor system, following the connections of the flow the node continues with. It reports
+// whether the node recovered.
+func (s *ActorSystem) recoverNodeError(actor *NodeActor, triggerPinID string, err error) bool {
+	strategy, bpErr := s.recovery.strategy(actor.NodeID, err)
+	outputPins := actor.node.GetOutputPins()
+	response := NodeResponse{Success: true, OutputPins: make(map[string]types.Value)}
+
+	switch strategy {
+	case bperrors.RecoveryRetry:
+		s.recovery.record(actor.NodeID, strategy, bpErr)
+		time.Sleep(s.recovery.retryDelay(actor.NodeID))
+		s.executeNodeTriggered(actor.NodeID, triggerPinID)
+		return true
+
+	case bperrors.RecoverySkipNode:
+		response.FlowToActivate = successFlow(outputPins)
+
+	case bperrors.RecoveryUseDefaultValue:
+		for _, pin := range outputPins {
+			if value, exists := actor.GetOutput(pin.ID); exists {
+				response.OutputPins[pin.ID] = value
+			}
+		}
+		for pinID, value := range s.recovery.defaultOutputs(outputPins, func(pinID string) bool {
+			_, exists := response.OutputPins[pinID]
+			return exists
+		}) {
+			response.OutputPins[pinID] = value
+		}
+		response.FlowToActivate = successFlow(outputPins)
+
+	case bperrors.RecoveryAlternatePath:
+		// Only nodes with a catch flow have an alternate path
+		if !hasPin(outputPins, bperrors.CatchPinID) {
+			return false
+		}
+		if hasPin(outputPins, bperrors.ErrorPinID) {
+			response.OutputPins[bperrors.ErrorPinID] = types.NewValue(types.PinTypes.Object, bpErr.PinValue())
+		}
+		response.FlowToActivate = bperrors.CatchPinID
+
+	default:
+		return false
+	}
+
+	s.recovery.record(actor.NodeID, strategy, bpErr)
+	if response.FlowToActivate == "" && len(response.OutputPins) == 0 {
+		return true
+	}
+
+	for pinID, value := range response.OutputPins {
+		if s.debugMgr != nil {
+			s.debugMgr.StoreNodeOutputValue(s.executionID, actor.NodeID, pinID, value.RawValue)
+		}
+	}
+	s.followConnections(actor, response)
+	return true
+}
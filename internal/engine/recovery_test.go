@@ -0,0 +1,111 @@
+package engine_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// flakyNode fails with its error until it ran the given number of times
+type flakyNode struct {
+	node.BaseNode
+	runs     *atomic.Int32
+	failures int32
+	err      error
+}
+
+func newFlakyNode(runs *atomic.Int32, failures int32, err error) node.Node {
+	return &flakyNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{TypeID: "test-flaky", Name: "Flaky"},
+			Inputs:   []types.Pin{{ID: "exec", Name: "Execute", Type: types.PinTypes.Execution}},
+			Outputs:  []types.Pin{{ID: "then", Name: "Then", Type: types.PinTypes.Execution}},
+		},
+		runs:     runs,
+		failures: failures,
+		err:      err,
+	}
+}
+
+func (n *flakyNode) Execute(ctx node.ExecutionContext) error {
+	if n.runs.Add(1) <= n.failures {
+		return n.err
+	}
+	return ctx.ActivateOutputFlow("then")
+}
+
+// recoveryBlueprint runs a flaky node followed by a probe
+func recoveryBlueprint() *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("recovery", "Recovery", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "flaky", Type: "test-flaky"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "after", Type: "after-probe"})
+	for _, conn := range []blueprint.Connection{
+		{ID: "exec-flaky", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "flaky", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "exec-after", SourceNodeID: "flaky", SourcePinID: "then", TargetNodeID: "after", TargetPinID: "exec", ConnectionType: "execution"},
+	} {
+		bp.AddConnection(conn)
+	}
+	return bp
+}
+
+func TestRecoveryStrategiesInEveryMode(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		failures  int32
+		err       error
+		recovered bperrors.RecoveryStrategy // Empty if the execution fails
+		runs      int32
+	}{
+		{"retry", 1, errors.New("temporary failure"), bperrors.RecoveryRetry, 2},
+		{"skip", 1000, bperrors.New(bperrors.ErrorTypeExecution, bperrors.ErrNodeNotFound, "missing", bperrors.SeverityMedium), bperrors.RecoverySkipNode, 1},
+		{"unrecoverable", 1000, bperrors.New(bperrors.ErrorTypeExecution, bperrors.ErrOperationFailed, "broken", bperrors.SeverityHigh), "", 1},
+	} {
+		for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+			t.Run(test.name+"/"+string(mode), func(t *testing.T) {
+				var runs atomic.Int32
+				var after sync.Map
+				flowEngine := newEngine(t, mode)
+				registry.GetInstance().RegisterNodeType("test-flaky", func() node.Node {
+					return newFlakyNode(&runs, test.failures, test.err)
+				})
+				registry.GetInstance().RegisterNodeType("after-probe", func() node.Node {
+					return newProbeNode(types.PinTypes.Any, &after)
+				})
+				executionID := "recovery-" + test.name + "-" + string(mode)
+
+				result, err := flowEngine.Execute(recoveryBlueprint(), executionID, map[string]types.Value{})
+				if runs.Load() != test.runs {
+					t.Errorf("expected the node to run %d times, got %d", test.runs, runs.Load())
+				}
+
+				if test.recovered == "" {
+					if err == nil || result.Success {
+						t.Fatal("expected the unrecoverable error to fail the execution")
+					}
+					if len(result.Recoveries) != 0 {
+						t.Errorf("expected no recoveries, got %+v", result.Recoveries)
+					}
+					return
+				}
+
+				if err != nil || !result.Success {
+					t.Fatalf("expected the execution to recover, got %v", err)
+				}
+				if len(result.Recoveries) != 1 || result.Recoveries[0].NodeID != "flaky" || result.Recoveries[0].Strategy != string(test.recovered) {
+					t.Errorf("expected a %s recovery of the flaky node, got %+v", test.recovered, result.Recoveries)
+				}
+				if _, exists := after.Load(executionID); !exists {
+					t.Error("expected the execution to continue after the recovered node")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"time"
+	"webblueprint/internal/event"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// ReloadBlueprint makes a newly activated version of a loaded blueprint current and replaces
+// its custom events and bindings in one step. Executions in flight finish on the version
+// they started with, which stays loaded until they end, while event handlers and later
+// executions use the new one. Blueprints that aren't loaded are left to their first execution.
+// Like LoadBlueprint, the engine keeps its own copy of the version.
+func (e *ExecutionEngine) ReloadBlueprint(bp *blueprint.Blueprint) error {
+	bp = bp.Clone()
+
+	e.mutex.Lock()
+	if _, loaded := e.currentVersions[bp.ID]; !loaded {
+		e.mutex.Unlock()
+		return nil
+	}
+	key := keyOf(bp)
+	e.initVariables(key)
+	e.blueprints[key] = bp
+	e.activeVersions[bp.ID] = bp.Version
+	retired, retiredExists := e.makeCurrentLocked(bp)
+	extensions := e.extensions
+	e.mutex.Unlock()
+
+	if retiredExists {
+		e.unregisterVersionNodes(retired)
+	}
+	e.registerVariableNodes(bp)
+	e.logger.Info("Reloaded blueprint", map[string]interface{}{"blueprintId": bp.ID, "version": bp.Version})
+
+	if extensions == nil || extensions.GetConcreteEventManager() == nil {
+		return nil
+	}
+	return extensions.GetConcreteEventManager().ReplaceBlueprintEvents(bp.ID, eventDefinitions(bp), eventBindings(bp))
+}
+
+// UnloadBlueprint drops every version of a deleted blueprint from the engine along with its
+// custom events, bindings, the node types registered in their namespaces and those the
+// editor registered for it at runtime. Executions in flight finish.
+func (e *ExecutionEngine) UnloadBlueprint(blueprintID string) {
+	e.mutex.Lock()
+	unloaded := make([]blueprintKey, 0)
+	for key := range e.blueprints {
+		if key.id == blueprintID {
+			unloaded = append(unloaded, key)
+			delete(e.blueprints, key)
+			e.variables.Delete(key)
+			delete(e.plans, key)
+		}
+	}
+	delete(e.activeVersions, blueprintID)
+	delete(e.currentVersions, blueprintID)
+	extensions := e.extensions
+	e.mutex.Unlock()
+
+	for _, key := range unloaded {
+		e.unregisterVersionNodes(key)
+	}
+	if global := registry.GetInstance(); global != nil {
+		global.ReleaseRuntimeNodeTypes(registry.BlueprintNamespace(blueprintID))
+	}
+	if extensions != nil && extensions.GetConcreteEventManager() != nil {
+		extensions.GetConcreteEventManager().ReplaceBlueprintEvents(blueprintID, nil, nil)
+	}
+
+	e.logger.Info("Unloaded blueprint", map[string]interface{}{"blueprintId": blueprintID})
+}
+
+// eventDefinitions returns the custom events declared by a blueprint
+func eventDefinitions(bp *blueprint.Blueprint) []event.EventDefinition {
+	definitions := make([]event.EventDefinition, 0, len(bp.Events))
+	for _, definition := range bp.Events {
+		parameters := make([]event.EventParameter, 0, len(definition.Parameters))
+		for _, parameter := range definition.Parameters {
+			pinType, _ := types.GetPinTypeByID(parameter.TypeID)
+			parameters = append(parameters, event.EventParameter{
+				Name:        parameter.Name,
+				Type:        pinType,
+				Description: parameter.Description,
+				Optional:    parameter.Optional,
+				Default:     parameter.Default,
+			})
+		}
+
+		definitions = append(definitions, event.EventDefinition{
+			ID:           definition.ID,
+			Name:         definition.Name,
+			Description:  definition.Description,
+			Parameters:   parameters,
+			Category:     definition.Category,
+			BlueprintID:  bp.ID,
+			DispatchMode: definition.DispatchMode,
+			CreatedAt:    time.Now(),
+		})
+	}
+	return definitions
+}
+
+// eventBindings returns the event bindings declared by a blueprint
+func eventBindings(bp *blueprint.Blueprint) []event.EventBinding {
+	bindings := make([]event.EventBinding, 0, len(bp.EventBindings))
+	for _, binding := range bp.EventBindings {
+		bindings = append(bindings, event.EventBinding{
+			ID:          binding.ID,
+			EventID:     binding.EventID,
+			HandlerID:   binding.HandlerID,
+			HandlerType: binding.HandlerType,
+			BlueprintID: bp.ID,
+			Priority:    binding.Priority,
+			CreatedAt:   time.Now(),
+			Enabled:     binding.Enabled,
+			Delivery:    binding.Delivery,
+		})
+	}
+	return bindings
+}
@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/core"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// ExecutionRecording holds the external inputs of an execution: the results of
+// nondeterministic nodes and the events dispatched to it
+type ExecutionRecording struct {
+	ExecutionID string                             `json:"executionId"`
+	RecordedAt  time.Time                          `json:"recordedAt"`
+	Nodes       map[string][]RecordedNodeExecution `json:"nodes"` // Node ID -> executions in order
+	Events      []RecordedEvent                    `json:"events"`
+}
+
+// RecordedNodeExecution is the result of one execution of a nondeterministic node
+type RecordedNodeExecution struct {
+	NodeType       string                 `json:"nodeType"`
+	Outputs        map[string]interface{} `json:"outputs"`
+	ActivatedFlows []string               `json:"activatedFlows"`
+	Error          string                 `json:"error,omitempty"`
+}
+
+// RecordedEvent is an event that triggered an event handler of the execution
+type RecordedEvent struct {
+	EventID    string                 `json:"eventId"`
+	HandlerID  string                 `json:"handlerId"`
+	SourceID   string                 `json:"sourceId"`
+	Parameters map[string]interface{} `json:"parameters"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// replaySession records or replays the external inputs of a single execution
+type replaySession struct {
+	replaying bool
+	recording *ExecutionRecording
+	cursor    map[string]int // Node ID -> next recorded execution to replay
+	mutex     sync.Mutex
+}
+
+// isNondeterministic checks if a node's results depend on the outside world
+func isNondeterministic(nodeInstance node.Node) bool {
+	nondeterministic, ok := nodeInstance.(node.NondeterministicNode)
+	return ok && nondeterministic.IsNondeterministic()
+}
+
+// StartRecording records the external inputs of an execution until TakeRecording is called.
+// It does nothing if the execution is already recorded or replayed.
+func (e *ExecutionEngine) StartRecording(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exists := e.replays[executionID]; exists {
+		return
+	}
+
+	e.replays[executionID] = &replaySession{
+		recording: &ExecutionRecording{
+			ExecutionID: executionID,
+			RecordedAt:  time.Now(),
+			Nodes:       make(map[string][]RecordedNodeExecution),
+			Events:      make([]RecordedEvent, 0),
+		},
+	}
+}
+
+// StartReplay makes an execution use the results of a recording instead of calling
+// nondeterministic nodes, and re-dispatches the recorded events once the main flow finishes
+func (e *ExecutionEngine) StartReplay(executionID string, recording *ExecutionRecording) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.replays[executionID] = &replaySession{
+		replaying: true,
+		recording: recording,
+		cursor:    make(map[string]int),
+	}
+}
+
+// TakeRecording ends the recording or replay of an execution and returns what was recorded.
+// Replayed executions return false as they don't produce a new recording.
+func (e *ExecutionEngine) TakeRecording(executionID string) (*ExecutionRecording, bool) {
+	e.mutex.Lock()
+	session, exists := e.replays[executionID]
+	delete(e.replays, executionID)
+	e.mutex.Unlock()
+
+	if !exists || session.replaying {
+		return nil, false
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.recording, true
+}
+
+// replaySession returns the recording or replay session of an execution, if any
+func (e *ExecutionEngine) replaySession(executionID string) *replaySession {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.replays[executionID]
+}
+
+// executeOrReplay executes a node, or feeds back its recorded result when replaying.
+// Results of nondeterministic nodes are recorded when recording.
+func (s *replaySession) executeOrReplay(nodeID string, nodeInstance node.Node, ctx node.ExecutionContext) error {
+	if s == nil || !isNondeterministic(nodeInstance) {
+		return nodeInstance.Execute(ctx)
+	}
+
+	if s.replaying {
+		recorded, err := s.next(nodeID)
+		if err != nil {
+			return err
+		}
+		return replayNodeExecution(ctx, nodeInstance, recorded)
+	}
+
+	err := nodeInstance.Execute(ctx)
+	s.recordNodeExecution(nodeID, nodeInstance, ctx, err)
+	return err
+}
+
+// next returns the next recorded execution of a node
+func (s *replaySession) next(nodeID string) (RecordedNodeExecution, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	index := s.cursor[nodeID]
+	executions := s.recording.Nodes[nodeID]
+	if index >= len(executions) {
+		return RecordedNodeExecution{}, fmt.Errorf("replay: no recorded result for node %s (execution %d of %d recorded)", nodeID, index+1, len(executions))
+	}
+
+	s.cursor[nodeID] = index + 1
+	return executions[index], nil
+}
+
+// recordNodeExecution stores the outputs and activated flows of a node execution
+func (s *replaySession) recordNodeExecution(nodeID string, nodeInstance node.Node, ctx node.ExecutionContext, err error) {
+	recorded := RecordedNodeExecution{
+		NodeType:       nodeInstance.GetMetadata().TypeID,
+		Outputs:        make(map[string]interface{}),
+		ActivatedFlows: make([]string, 0),
+	}
+	if err != nil {
+		recorded.Error = err.Error()
+	}
+
+	if extCtx := engineext.GetExtendedContext(ctx); extCtx != nil {
+		for pinID, value := range extCtx.GetAllOutputs() {
+			recorded.Outputs[pinID] = recordValue(value.RawValue)
+		}
+		recorded.ActivatedFlows = append(recorded.ActivatedFlows, extCtx.GetActivatedOutputFlows()...)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.recording.Nodes[nodeID] = append(s.recording.Nodes[nodeID], recorded)
+}
+
+// recordEvent stores an event dispatched to an event handler of the execution
+func (s *replaySession) recordEvent(triggerContext core.EventHandlerContext) {
+	parameters := make(map[string]interface{})
+	for name, value := range triggerContext.Parameters {
+		parameters[name] = recordValue(value.RawValue)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.recording.Events = append(s.recording.Events, RecordedEvent{
+		EventID:    triggerContext.EventID,
+		HandlerID:  triggerContext.HandlerID,
+		SourceID:   triggerContext.SourceID,
+		Parameters: parameters,
+		Timestamp:  time.Now(),
+	})
+}
+
+// replayNodeExecution sets the recorded outputs of a node and activates its recorded flows
+func replayNodeExecution(ctx node.ExecutionContext, nodeInstance node.Node, recorded RecordedNodeExecution) error {
+	for pinID, raw := range recorded.Outputs {
+		value, err := types.DecodeBytesValue(raw)
+		if err != nil {
+			return fmt.Errorf("replay: invalid recorded output %s: %w", pinID, err)
+		}
+
+		pinType := types.InferPinType(value)
+		if pin, exists := types.FindPin(nodeInstance.GetOutputPins(), pinID); exists {
+			pinType = pin.Type
+		}
+		ctx.SetOutputValue(pinID, types.NewValue(pinType, value))
+	}
+
+	for _, flow := range recorded.ActivatedFlows {
+		if err := ctx.ActivateOutputFlow(flow); err != nil {
+			return err
+		}
+	}
+
+	if recorded.Error != "" {
+		return errors.New(recorded.Error)
+	}
+	return nil
+}
+
+// replayEvents re-dispatches the recorded events of a replayed execution in their original order
+func (e *ExecutionEngine) replayEvents(bp *blueprint.Blueprint, executionID string) error {
+	session := e.replaySession(executionID)
+	if session == nil || !session.replaying {
+		return nil
+	}
+
+	for _, event := range session.recording.Events {
+		parameters := make(map[string]types.Value)
+		for name, raw := range event.Parameters {
+			value, err := types.DecodeBytesValue(raw)
+			if err != nil {
+				return fmt.Errorf("replay: invalid parameter %s of event %s: %w", name, event.EventID, err)
+			}
+			parameters[name] = types.NewValue(types.InferPinType(value), value)
+		}
+
+		err := e.triggerNodeExecution(bp.ID, event.HandlerID, core.EventHandlerContext{
+			EventID:     event.EventID,
+			Parameters:  parameters,
+			SourceID:    event.SourceID,
+			BlueprintID: bp.ID,
+			ExecutionID: executionID,
+			HandlerID:   event.HandlerID,
+			Timestamp:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordValue prepares a value for storage in a recording. Bytes are base64 encoded;
+// streams can't be recorded without consuming them and are stored as null.
+func recordValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return types.EncodeBytes(v)
+	case *types.Stream:
+		return nil
+	default:
+		return value
+	}
+}
@@ -0,0 +1,275 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// NodeTimeoutDataKey is the key of the blueprint node data that overrides the time limit of
+// the node, as a duration such as "90s" or a number of milliseconds
+const NodeTimeoutDataKey = "timeout"
+
+// NodeLimits bounds every node execution; zero values mean unlimited
+type NodeLimits struct {
+	Timeout        time.Duration // Wall-clock time a node may spend in its own code
+	MaxOutputBytes int           // Size of a single output pin value, as JSON
+}
+
+// DefaultNodeLimits are the limits of node executions unless the engine is given others
+var DefaultNodeLimits = NodeLimits{
+	Timeout:        5 * time.Minute,
+	MaxOutputBytes: 16 << 20,
+}
+
+// SetNodeLimits sets the limits of every node execution
+func (e *ExecutionEngine) SetNodeLimits(limits NodeLimits) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.nodeLimits = limits
+}
+
+// getNodeLimits returns the limits of every node execution
+func (e *ExecutionEngine) getNodeLimits() NodeLimits {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.nodeLimits
+}
+
+// forNode returns the limits of a blueprint node, with the time limit of its data if set
+func (l NodeLimits) forNode(nodeConfig *blueprint.BlueprintNode, blueprintID, executionID string) (NodeLimits, *bperrors.BlueprintError) {
+	if nodeConfig == nil {
+		return l, nil
+	}
+	raw, exists := nodeConfig.Data[NodeTimeoutDataKey]
+	if !exists || raw == nil {
+		return l, nil
+	}
+
+	var timeout time.Duration
+	var err error
+	switch value := raw.(type) {
+	case string:
+		timeout, err = time.ParseDuration(value)
+	case float64:
+		timeout = time.Duration(value) * time.Millisecond
+	default:
+		err = fmt.Errorf("expected a duration or a number of milliseconds, got %T", raw)
+	}
+	if err == nil && timeout < 0 {
+		err = errors.New("must not be negative")
+	}
+	if err != nil {
+		return l, bperrors.Wrap(err, bperrors.ErrorTypeValidation, bperrors.ErrInvalidNodeConfiguration,
+			fmt.Sprintf("Invalid node time limit: %v", err), bperrors.SeverityHigh).
+			WithNodeInfo(nodeConfig.ID, "").
+			WithBlueprintInfo(blueprintID, executionID)
+	}
+
+	l.Timeout = timeout
+	return l, nil
+}
+
+// nodeSandbox runs a node within its limits and turns panics into node errors, so a failing
+// node can't take down its execution or the actor system. The time limit only covers the
+// node's own code: in standard mode nodes run their downstream nodes through their output
+// flows, during which the sandbox is paused.
+type nodeSandbox struct {
+	nodeID      string
+	blueprintID string
+	executionID string
+	limits      NodeLimits
+	onPanic     func(err *bperrors.BlueprintError) // Reports the panics of the node, if set
+	profiled    bool                               // Measures the allocations of the node too
+
+	mutex     sync.Mutex
+	used      time.Duration // Time spent in the node's own code before the last pause
+	since     time.Time     // When the node last resumed
+	paused    int           // Downstream runs in progress
+	abandoned bool          // The node exceeded its time limit
+
+	allocBytes, allocObjects uint64 // Allocated in the node's own code before the last pause, when profiled
+	bytesSince, objectsSince uint64 // Allocated by the process when the node last resumed
+}
+
+func newNodeSandbox(limits NodeLimits, nodeID, blueprintID, executionID string) *nodeSandbox {
+	return &nodeSandbox{
+		nodeID:      nodeID,
+		blueprintID: blueprintID,
+		executionID: executionID,
+		limits:      limits,
+	}
+}
+
+// run executes a node. Nodes can't be stopped, so a node that exceeds its time limit is
+// abandoned with a timeout error and finishes in the background, without starting other nodes.
+func (s *nodeSandbox) run(execute func() error) error {
+	s.mutex.Lock()
+	s.since = time.Now()
+	if s.profiled {
+		s.bytesSince, s.objectsSince = readAllocs()
+	}
+	s.mutex.Unlock()
+
+	if s.limits.Timeout <= 0 {
+		return s.recoverPanic(execute)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.recoverPanic(execute)
+	}()
+
+	for {
+		remaining, expired := s.remaining()
+		if expired {
+			return bperrors.NodeTimeout(s.limits.Timeout).
+				WithNodeInfo(s.nodeID, "").
+				WithBlueprintInfo(s.blueprintID, s.executionID)
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case err := <-done:
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// recoverPanic executes a node, returning its panic as a node error
+func (s *nodeSandbox) recoverPanic(execute func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			bpErr := bperrors.NodePanicked(recovered, debug.Stack()).
+				WithNodeInfo(s.nodeID, "").
+				WithBlueprintInfo(s.blueprintID, s.executionID)
+			if s.onPanic != nil {
+				s.onPanic(bpErr)
+			}
+			err = bpErr
+		}
+	}()
+	return execute()
+}
+
+// remaining returns how long the node may still run, or abandons it once it used up its
+// time limit. Paused nodes don't use up time.
+func (s *nodeSandbox) remaining() (time.Duration, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	used := s.used
+	if s.paused == 0 {
+		used += time.Since(s.since)
+	}
+	if used >= s.limits.Timeout {
+		s.abandoned = true
+		return 0, true
+	}
+	return s.limits.Timeout - used, false
+}
+
+// pause stops the clock of the node while it runs downstream nodes. It fails once the node
+// was abandoned.
+func (s *nodeSandbox) pause() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.abandoned {
+		return bperrors.NodeTimeout(s.limits.Timeout).
+			WithNodeInfo(s.nodeID, "").
+			WithBlueprintInfo(s.blueprintID, s.executionID)
+	}
+	if s.paused == 0 {
+		s.used += time.Since(s.since)
+		s.addAllocsLocked()
+	}
+	s.paused++
+	return nil
+}
+
+// resume restarts the clock of the node once its downstream nodes ran
+func (s *nodeSandbox) resume() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.paused--
+	if s.paused == 0 {
+		s.since = time.Now()
+		if s.profiled {
+			s.bytesSince, s.objectsSince = readAllocs()
+		}
+	}
+}
+
+// addAllocsLocked adds the allocations since the node last resumed, when profiled.
+// Requires the lock.
+func (s *nodeSandbox) addAllocsLocked() {
+	if !s.profiled {
+		return
+	}
+	bytes, objects := readAllocs()
+	s.allocBytes += bytes - s.bytesSince
+	s.allocObjects += objects - s.objectsSince
+}
+
+// usage returns the time the node spent in its own code and, when profiled, the bytes and
+// objects it allocated there. It's read once the node ran.
+func (s *nodeSandbox) usage() (time.Duration, uint64, uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	used, allocBytes, allocObjects := s.used, s.allocBytes, s.allocObjects
+	if s.paused == 0 {
+		used += time.Since(s.since)
+		if s.profiled {
+			bytes, objects := readAllocs()
+			allocBytes += bytes - s.bytesSince
+			allocObjects += objects - s.objectsSince
+		}
+	}
+	return used, allocBytes, allocObjects
+}
+
+// checkOutputs rejects the output values of the node that are larger than the limit
+func (s *nodeSandbox) checkOutputs(outputs map[string]types.Value) error {
+	if s.limits.MaxOutputBytes <= 0 {
+		return nil
+	}
+
+	for pinID, value := range outputs {
+		if size := valueSize(value.RawValue); size > s.limits.MaxOutputBytes {
+			return bperrors.OutputTooLarge(pinID, size, s.limits.MaxOutputBytes).
+				WithNodeInfo(s.nodeID, pinID).
+				WithBlueprintInfo(s.blueprintID, s.executionID)
+		}
+	}
+	return nil
+}
+
+// valueSize returns the size of a pin value as JSON. Streams are read piecewise and values
+// that can't be encoded have no size.
+func valueSize(raw interface{}) int {
+	switch value := raw.(type) {
+	case nil, *types.Stream:
+		return 0
+	case string:
+		return len(value)
+	case []byte:
+		return len(value)
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
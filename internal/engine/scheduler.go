@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ExecutionPriority determines the order in which queued executions are started
+type ExecutionPriority int
+
+const (
+	PriorityLow ExecutionPriority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// DefaultSchedulerWorkers is the number of executions the scheduler runs at once
+const DefaultSchedulerWorkers = 8
+
+// String returns the name of the priority
+func (p ExecutionPriority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// ParseExecutionPriority parses a priority name; an empty name is normal priority
+func ParseExecutionPriority(name string) (ExecutionPriority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	default:
+		return PriorityNormal, fmt.Errorf("unknown execution priority: %s", name)
+	}
+}
+
+// ExecutionScheduler runs queued executions on a fixed pool of workers,
+// always picking the oldest job from the highest non-empty priority queue
+type ExecutionScheduler struct {
+	queues  [PriorityHigh + 1][]func()
+	running int
+	workers int
+	stopped bool
+	mutex   sync.Mutex
+	cond    *sync.Cond
+}
+
+// NewExecutionScheduler creates a scheduler and starts its workers
+func NewExecutionScheduler(workers int) *ExecutionScheduler {
+	if workers <= 0 {
+		workers = DefaultSchedulerWorkers
+	}
+
+	s := &ExecutionScheduler{workers: workers}
+	s.cond = sync.NewCond(&s.mutex)
+
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+
+	return s
+}
+
+// Submit queues a job with the given priority
+func (s *ExecutionScheduler) Submit(priority ExecutionPriority, job func()) error {
+	if priority < PriorityLow || priority > PriorityHigh {
+		priority = PriorityNormal
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return fmt.Errorf("execution scheduler is stopped")
+	}
+
+	s.queues[priority] = append(s.queues[priority], job)
+	s.cond.Signal()
+	return nil
+}
+
+// QueueDepth returns the number of waiting jobs per priority
+func (s *ExecutionScheduler) QueueDepth() map[string]int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	depth := make(map[string]int, len(s.queues))
+	for priority, queue := range s.queues {
+		depth[ExecutionPriority(priority).String()] = len(queue)
+	}
+	return depth
+}
+
+// Running returns the number of jobs currently executing
+func (s *ExecutionScheduler) Running() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.running
+}
+
+// Workers returns the number of jobs the scheduler runs at once
+func (s *ExecutionScheduler) Workers() int {
+	return s.workers
+}
+
+// Stop stops the workers once they finish their current job; queued jobs are dropped
+func (s *ExecutionScheduler) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stopped = true
+	for priority := range s.queues {
+		s.queues[priority] = nil
+	}
+	s.cond.Broadcast()
+}
+
+// next blocks until a job is available and returns it, or nil when stopped
+func (s *ExecutionScheduler) next() func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for {
+		if s.stopped {
+			return nil
+		}
+
+		for priority := PriorityHigh; priority >= PriorityLow; priority-- {
+			if len(s.queues[priority]) > 0 {
+				job := s.queues[priority][0]
+				s.queues[priority][0] = nil
+				s.queues[priority] = s.queues[priority][1:]
+				s.running++
+				return job
+			}
+		}
+
+		s.cond.Wait()
+	}
+}
+
+// worker runs jobs until the scheduler is stopped
+func (s *ExecutionScheduler) worker() {
+	for {
+		job := s.next()
+		if job == nil {
+			return
+		}
+
+		func() {
+			defer func() {
+				s.mutex.Lock()
+				s.running--
+				s.mutex.Unlock()
+			}()
+			job()
+		}()
+	}
+}
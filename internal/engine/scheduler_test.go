@@ -0,0 +1,204 @@
+package engine_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+)
+
+// recordedOrder collects the jobs a scheduler ran, in order
+type recordedOrder struct {
+	mutex sync.Mutex
+	jobs  []string
+	done  sync.WaitGroup
+}
+
+func (r *recordedOrder) job(name string) func() {
+	r.done.Add(1)
+	return func() {
+		defer r.done.Done()
+		r.mutex.Lock()
+		r.jobs = append(r.jobs, name)
+		r.mutex.Unlock()
+	}
+}
+
+// blockWorker occupies the only worker of a scheduler until the returned function is called
+func blockWorker(t *testing.T, scheduler *engine.ExecutionScheduler) func() {
+	t.Helper()
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	if err := scheduler.Submit(engine.PriorityHigh, func() {
+		close(started)
+		<-unblock
+	}); err != nil {
+		t.Fatalf("failed to submit the blocking job: %v", err)
+	}
+	<-started
+	return func() { close(unblock) }
+}
+
+func TestSchedulerRunsHigherPrioritiesFirst(t *testing.T) {
+	scheduler := engine.NewExecutionScheduler(1)
+	defer scheduler.Stop()
+	unblock := blockWorker(t, scheduler)
+
+	var order recordedOrder
+	for _, job := range []struct {
+		name     string
+		priority engine.ExecutionPriority
+	}{
+		{"low", engine.PriorityLow},
+		{"normal", engine.PriorityNormal},
+		{"high", engine.PriorityHigh},
+	} {
+		if err := scheduler.Submit(job.priority, order.job(job.name)); err != nil {
+			t.Fatalf("failed to submit %s: %v", job.name, err)
+		}
+	}
+
+	depth := scheduler.QueueDepth()
+	for _, priority := range []string{"low", "normal", "high"} {
+		if depth[priority] != 1 {
+			t.Errorf("expected 1 queued %s job, got %d", priority, depth[priority])
+		}
+	}
+	if scheduler.Running() != 1 {
+		t.Errorf("expected the blocking job to be running, got %d running", scheduler.Running())
+	}
+
+	unblock()
+	order.done.Wait()
+
+	if fmt.Sprint(order.jobs) != "[high normal low]" {
+		t.Errorf("expected the jobs to run by priority, got %v", order.jobs)
+	}
+	depth = scheduler.QueueDepth()
+	if depth["low"]+depth["normal"]+depth["high"] != 0 {
+		t.Errorf("expected the queues to be empty, got %v", depth)
+	}
+}
+
+func TestSchedulerRunsJobsOfAPriorityInOrder(t *testing.T) {
+	scheduler := engine.NewExecutionScheduler(1)
+	defer scheduler.Stop()
+	unblock := blockWorker(t, scheduler)
+
+	var order recordedOrder
+	expected := make([]string, 10)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("job-%d", i)
+		if err := scheduler.Submit(engine.PriorityNormal, order.job(expected[i])); err != nil {
+			t.Fatalf("failed to submit %s: %v", expected[i], err)
+		}
+	}
+	if depth := scheduler.QueueDepth()["normal"]; depth != len(expected) {
+		t.Errorf("expected %d queued jobs, got %d", len(expected), depth)
+	}
+
+	unblock()
+	order.done.Wait()
+
+	if fmt.Sprint(order.jobs) != fmt.Sprint(expected) {
+		t.Errorf("expected the jobs to run in submission order, got %v", order.jobs)
+	}
+}
+
+func TestSchedulerRejectsJobsOnceStopped(t *testing.T) {
+	scheduler := engine.NewExecutionScheduler(1)
+	unblock := blockWorker(t, scheduler)
+	if err := scheduler.Submit(engine.PriorityNormal, func() {}); err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+
+	scheduler.Stop()
+	unblock()
+
+	if depth := scheduler.QueueDepth()["normal"]; depth != 0 {
+		t.Errorf("expected queued jobs to be dropped, got %d", depth)
+	}
+	if err := scheduler.Submit(engine.PriorityNormal, func() {}); err == nil {
+		t.Error("expected a stopped scheduler to reject jobs")
+	}
+}
+
+// blockingNode waits for its channel to close before running the node it wraps
+type blockingNode struct {
+	node.Node
+	unblock <-chan struct{}
+}
+
+func (n blockingNode) Execute(ctx node.ExecutionContext) error {
+	<-n.unblock
+	return n.Node.Execute(ctx)
+}
+
+func TestExecutionIsQueuedUntilAWorkerStartsIt(t *testing.T) {
+	flowEngine := newEngine(t, engine.ModeStandard)
+	unblock := make(chan struct{})
+	registry.GetInstance().RegisterNodeType("blocking-print", func() node.Node {
+		return blockingNode{utility.NewPrintNode(), unblock}
+	})
+	blocking := chainBlueprint(1)
+	blocking.Nodes[1].Type = "blocking-print"
+
+	var startedMutex sync.Mutex
+	started := make(map[string]bool)
+	flowEngine.SetHooks(engine.Hooks{OnExecutionStart: func(executionID string) {
+		startedMutex.Lock()
+		started[executionID] = true
+		startedMutex.Unlock()
+	}})
+	wasStarted := func(executionID string) bool {
+		startedMutex.Lock()
+		defer startedMutex.Unlock()
+		return started[executionID]
+	}
+
+	// Take every worker of the scheduler
+	var wg sync.WaitGroup
+	for i := 0; i < engine.DefaultSchedulerWorkers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			flowEngine.ExecuteWithPriority(engine.PriorityNormal, blocking, fmt.Sprintf("busy-%d", i), map[string]types.Value{})
+		}(i)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for flowEngine.GetRunningExecutions() < engine.DefaultSchedulerWorkers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		flowEngine.ExecuteWithPriority(engine.PriorityNormal, chainBlueprint(1), "waiting", map[string]types.Value{})
+	}()
+	for flowEngine.GetQueueDepth()["normal"] == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	status, exists := flowEngine.GetExecutionStatus("waiting")
+	if !exists || status.Status != "queued" {
+		t.Errorf("expected the waiting execution to be queued, got %+v", status)
+	}
+	if wasStarted("waiting") {
+		t.Error("expected the start hook not to be called before a worker takes the execution")
+	}
+
+	close(unblock)
+	wg.Wait()
+
+	if !wasStarted("waiting") {
+		t.Error("expected the start hook to be called once a worker took the execution")
+	}
+	if status, _ := flowEngine.GetExecutionStatus("waiting"); status.Status != "completed" {
+		t.Errorf("expected the execution to complete, got %s", status.Status)
+	}
+}
@@ -0,0 +1,301 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// executionScope is a child scope of a standard mode execution, in which a scoped node runs
+// a branch, e.g. once per element of an array. The output values produced in the scope stay
+// there, and lookups fall back to the parent scope and finally to the debug manager, so that
+// concurrent scopes don't read each other's values.
+type executionScope struct {
+	parent  *executionScope
+	outputs map[string]map[string]interface{} // Node ID -> pin ID -> value
+	mutex   sync.RWMutex
+}
+
+func newExecutionScope(parent *executionScope) *executionScope {
+	return &executionScope{
+		parent:  parent,
+		outputs: make(map[string]map[string]interface{}),
+	}
+}
+
+// store records an output value produced in the scope
+func (s *executionScope) store(nodeID, pinID string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.outputs[nodeID] == nil {
+		s.outputs[nodeID] = make(map[string]interface{})
+	}
+	s.outputs[nodeID][pinID] = value
+}
+
+// lookup returns an output value produced in the scope or one of its parents
+func (s *executionScope) lookup(nodeID, pinID string) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		scope.mutex.RLock()
+		value, exists := scope.outputs[nodeID][pinID]
+		scope.mutex.RUnlock()
+		if exists {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// storeOutput records an output value of a node in the scope it ran in, or in the debug
+// manager outside of child scopes
+func (e *ExecutionEngine) storeOutput(scope *executionScope, executionID, nodeID, pinID string, value interface{}) {
+	if scope != nil {
+		scope.store(nodeID, pinID, value)
+		return
+	}
+	e.debugManager.StoreNodeOutputValue(executionID, nodeID, pinID, value)
+}
+
+// outputValue returns an output value of a node as seen from a scope
+func (e *ExecutionEngine) outputValue(scope *executionScope, executionID, nodeID, pinID string) (interface{}, bool) {
+	if value, exists := scope.lookup(nodeID, pinID); exists {
+		return value, true
+	}
+	return e.debugManager.GetNodeOutputValue(executionID, nodeID, pinID)
+}
+
+// runScope runs the nodes connected to an output execution pin of a scoped node in a new
+// child scope of the standard engine, and returns the value delivered to the node's result
+// pin from within it. Node errors in the scope are returned rather than routed by the error
+// policy, so that the scoped node can report them per scope.
+func (e *ExecutionEngine) runScope(nodeID, pinID, resultPin string, outputs map[string]types.Value, parent *executionScope, bp *blueprint.Blueprint, executionID string, variables map[string]types.Value, hooks *node.ExecutionHooks) (types.Value, bool, error) {
+	scope := newExecutionScope(parent)
+	for outputPin, value := range outputs {
+		scope.store(nodeID, outputPin, value.RawValue)
+	}
+
+	plan := e.planFor(bp)
+	for _, conn := range plan.OutputConnections(nodeID) {
+		if conn.ConnectionType != "execution" || conn.SourcePinID != pinID {
+			continue
+		}
+		holds, err := conditionHolds(conn, outputs)
+		if err != nil {
+			return types.Value{}, false, err.blueprintError(nodeID, bp.ID, executionID)
+		}
+		if !holds {
+			continue
+		}
+
+		e.debugManager.RecordActivation(executionID, nodeID, pinID, conn.TargetNodeID, conn.TargetPinID)
+		if err := e.executeNode(conn.TargetNodeID, bp, bp.ID, executionID, variables, hooks, nil, scope); err != nil {
+			return types.Value{}, false, scopeError(err)
+		}
+	}
+
+	if resultPin == "" {
+		return types.Value{}, false, nil
+	}
+	for _, conn := range plan.InputConnections(nodeID) {
+		if conn.ConnectionType != "data" || conn.TargetPinID != resultPin {
+			continue
+		}
+		raw, exists := scope.lookup(conn.SourceNodeID, conn.SourcePinID)
+		if !exists {
+			continue
+		}
+		produced := types.NewValue(types.InferPinType(raw), raw)
+		value, err := transformInput(conn, produced)
+		if err != nil {
+			return types.Value{}, false, err.blueprintError(nodeID, bp.ID, executionID)
+		}
+		e.recordDataFlow(executionID, conn, produced, value)
+		return value, true, nil
+	}
+	return types.Value{}, false, nil
+}
+
+// scopeError returns the node error a scope failed with, without the marks of its propagation
+func scopeError(err error) error {
+	var propagated *propagatedError
+	if errors.As(err, &propagated) {
+		return propagated.error
+	}
+	return err
+}
+
+// scopeSystem creates the actor system of a child scope of an actor mode execution. It has
+// its own actors for the nodes reachable from the scope pin, which start with the inputs the
+// parent's actors received, and shares the execution wide state with the parent.
+func (s *ActorSystem) scopeSystem(scopedActor *NodeActor, pinID string) (*ActorSystem, error) {
+	child := &ActorSystem{
+		ctxManager:        s.ctxManager,
+		actors:            make(map[string]*NodeActor),
+		connections:       s.connections,
+		executionID:       s.executionID,
+		blueprintID:       s.blueprintID,
+		nodeRegistry:      s.nodeRegistry,
+		logger:            s.logger,
+		listeners:         s.listeners,
+		debugMgr:          s.debugMgr,
+		variables:         s.variables,
+		executionDone:     make(chan struct{}),
+		hooks:             s.hooks,
+		nodeExecutionHook: s.nodeExecutionHook,
+		anyHook:           s.anyHook,
+		replay:            s.replay,
+		overrides:         s.overrides,
+		blueprintTypes:    s.blueprintTypes,
+		errorPolicy:       s.errorPolicy,
+		recovery:          s.recovery,
+		timers:            s.timers,
+		limits:            s.limits,
+		restarts:          make(map[string]int),
+		restartBudget:     s.restartBudget,
+		mailboxes:         s.mailboxes,
+		overflows:         s.overflows,
+		budget:            s.budget,
+		ctx:               s.ctx,
+		panics:            s.panics,
+		parent:            s,
+	}
+
+	bp := scopedActor.bp
+	for _, nodeID := range scopeNodes(s.connections, scopedActor.NodeID, pinID) {
+		nodeConfig := bp.FindNode(nodeID)
+		if nodeConfig == nil || nodeConfig.IsAnnotation() {
+			continue
+		}
+		actor, actorCtx, err := child.newActor(bp, *nodeConfig)
+		if err != nil {
+			child.Stop()
+			return nil, err
+		}
+
+		s.mutex.RLock()
+		if source, exists := s.actors[nodeID]; exists {
+			source.mutex.RLock()
+			for inputPin, value := range source.inputs {
+				actor.inputs[inputPin] = value
+			}
+			source.mutex.RUnlock()
+		}
+		s.mutex.RUnlock()
+
+		child.actors[nodeID] = actor
+		actor.Start(actorCtx)
+		go child.supervise(actor)
+	}
+	return child, nil
+}
+
+// scopeNodes returns the nodes reachable from an output execution pin of a scoped node over
+// connections of any kind, leaving out the scoped node itself
+func scopeNodes(connections map[string][]Connection, scopedNodeID, pinID string) []string {
+	var pending []string
+	for _, conn := range connections[scopedNodeID] {
+		if conn.ConnectionType == "execution" && conn.SourcePinID == pinID {
+			pending = append(pending, conn.TargetNodeID)
+		}
+	}
+
+	visited := map[string]bool{scopedNodeID: true}
+	var nodes []string
+	for len(pending) > 0 {
+		nodeID := pending[0]
+		pending = pending[1:]
+		if visited[nodeID] {
+			continue
+		}
+		visited[nodeID] = true
+		nodes = append(nodes, nodeID)
+		for _, conn := range connections[nodeID] {
+			pending = append(pending, conn.TargetNodeID)
+		}
+	}
+	return nodes
+}
+
+// runScope runs the nodes connected to an output execution pin of a scoped node in a child
+// scope of the actor system, and returns the value delivered to the node's result pin from
+// within it along with the first node error of the scope
+func (s *ActorSystem) runScope(scopedActor *NodeActor, pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error) {
+	child, err := s.scopeSystem(scopedActor, pinID)
+	if err != nil {
+		return types.Value{}, false, err
+	}
+	defer child.Stop()
+
+	child.followConnections(scopedActor, NodeResponse{Success: true, OutputPins: outputs, FlowToActivate: pinID})
+	child.waitGroup.Wait()
+
+	if err := child.scopeErr(); err != nil {
+		return types.Value{}, false, err
+	}
+	if resultPin == "" {
+		return types.Value{}, false, nil
+	}
+
+	for _, connections := range s.connections {
+		for _, conn := range connections {
+			if conn.ConnectionType != "data" || conn.TargetNodeID != scopedActor.NodeID || conn.TargetPinID != resultPin {
+				continue
+			}
+			child.mutex.RLock()
+			source, exists := child.actors[conn.SourceNodeID]
+			child.mutex.RUnlock()
+			if !exists {
+				continue
+			}
+			value, exists := source.GetOutput(conn.SourcePinID)
+			if !exists && source.ctx != nil {
+				value, exists = source.ctx.GetOutputValue(conn.SourcePinID)
+			}
+			if !exists {
+				continue
+			}
+			value, transformErr := applyTransform(conn.Transform, conn.ID, conn.TargetPinID, value)
+			if transformErr != nil {
+				return types.Value{}, false, transformErr.blueprintError(scopedActor.NodeID, s.blueprintID, s.executionID)
+			}
+			return value, true, nil
+		}
+	}
+	return types.Value{}, false, nil
+}
+
+// recordScopeError keeps the first node error of a child scope
+func (s *ActorSystem) recordScopeError(err error) {
+	s.supervisorMutex.Lock()
+	defer s.supervisorMutex.Unlock()
+	if s.scopeError == nil {
+		s.scopeError = scopeError(err)
+	}
+}
+
+// scopeErr returns the first node error of a child scope, if any
+func (s *ActorSystem) scopeErr() error {
+	s.supervisorMutex.Lock()
+	defer s.supervisorMutex.Unlock()
+	if s.scopeError != nil {
+		return s.scopeError
+	}
+	return s.failure
+}
+
+// isScopePin reports whether an output pin of a node only runs its branch in child scopes
+func isScopePin(n node.Node, pinID string) bool {
+	scoped, ok := n.(node.ScopedNode)
+	if !ok {
+		return false
+	}
+	for _, scopePin := range scoped.ScopePins() {
+		if scopePin == pinID {
+			return true
+		}
+	}
+	return false
+}
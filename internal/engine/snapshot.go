@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"webblueprint/internal/event"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+)
+
+// SnapshotVersion is the format version of engine snapshots. Snapshots of other versions
+// aren't restored.
+const SnapshotVersion = 1
+
+// Reasons a suspended execution waits for
+const (
+	WaitingForWorker    = "worker"    // A scheduler worker to start it
+	WaitingForBlueprint = "blueprint" // The running execution of its blueprint, under a limiting concurrency policy
+)
+
+// EngineSnapshot is the runtime state of an engine that a new process takes over during a
+// rolling upgrade: the loaded blueprints with their events and bindings, the pending
+// activations of rate limited nodes and the executions that haven't started yet
+type EngineSnapshot struct {
+	Version    int                  `json:"version"`
+	CreatedAt  time.Time            `json:"createdAt"`
+	Blueprints []SnapshotBlueprint  `json:"blueprints"`
+	Schedules  []SnapshotSchedule   `json:"schedules"`
+	Executions []SuspendedExecution `json:"executions"`
+}
+
+// SnapshotBlueprint is a loaded blueprint along with the custom events and bindings the event
+// manager holds for it, which may differ from the ones it declares
+type SnapshotBlueprint struct {
+	Blueprint *blueprint.Blueprint        `json:"blueprint"`
+	Active    bool                        `json:"active"` // Whether the version was activated through ReloadBlueprint
+	Events    []blueprint.EventDefinition `json:"events"`
+	Bindings  []blueprint.EventBinding    `json:"bindings"`
+}
+
+// SnapshotSchedule is the pending activation of a debounce or throttle node
+type SnapshotSchedule struct {
+	BlueprintID string                 `json:"blueprintId"`
+	NodeID      string                 `json:"nodeId"`
+	ExecutionID string                 `json:"executionId"`
+	Mode        string                 `json:"mode"`
+	FireAt      time.Time              `json:"fireAt"`
+	Triggers    int                    `json:"triggers"`
+	Inputs      map[string]interface{} `json:"inputs,omitempty"`
+}
+
+// SuspendedExecution is an execution that was submitted but hasn't started, waiting for a
+// scheduler worker or for the running execution of its blueprint
+type SuspendedExecution struct {
+	ExecutionID string    `json:"executionId"`
+	BlueprintID string    `json:"blueprintId"`
+	Priority    string    `json:"priority"`
+	WaitingFor  string    `json:"waitingFor"`
+	QueuedAt    time.Time `json:"queuedAt"`
+}
+
+// SnapshotRestore describes what restoring a snapshot took over. Suspended executions are
+// left to the caller, which holds their initial data.
+type SnapshotRestore struct {
+	Blueprints int                  `json:"blueprints"`
+	Skipped    []string             `json:"skipped,omitempty"` // Blueprints already loaded by this engine, which keeps its version
+	Schedules  int                  `json:"schedules"`
+	Executions []SuspendedExecution `json:"executions"`
+}
+
+// Snapshot captures the runtime state of the engine so that a new process can restore it
+func (e *ExecutionEngine) Snapshot() *EngineSnapshot {
+	snapshot := &EngineSnapshot{
+		Version:    SnapshotVersion,
+		CreatedAt:  time.Now(),
+		Blueprints: make([]SnapshotBlueprint, 0),
+		Schedules:  make([]SnapshotSchedule, 0),
+	}
+
+	// Versions only executions in flight run aren't captured, as those executions aren't
+	// handed over
+	e.mutex.RLock()
+	for id, version := range e.currentVersions {
+		bp, exists := e.blueprints[blueprintKey{id: id, version: version}]
+		if !exists {
+			continue
+		}
+		activeVersion, active := e.activeVersions[id]
+		// The snapshot gets its own copy, so encoding or changing it doesn't touch the
+		// blueprint executions run
+		snapshot.Blueprints = append(snapshot.Blueprints, SnapshotBlueprint{
+			Blueprint: bp.Clone(),
+			Active:    active && activeVersion == bp.Version,
+		})
+	}
+	extensions := e.extensions
+	e.mutex.RUnlock()
+
+	sort.Slice(snapshot.Blueprints, func(i, j int) bool {
+		return snapshot.Blueprints[i].Blueprint.ID < snapshot.Blueprints[j].Blueprint.ID
+	})
+
+	if extensions != nil {
+		if events := extensions.GetConcreteEventManager(); events != nil {
+			for i := range snapshot.Blueprints {
+				id := snapshot.Blueprints[i].Blueprint.ID
+				snapshot.Blueprints[i].Events = snapshotEvents(events.GetBlueprintEvents(id))
+				snapshot.Blueprints[i].Bindings = snapshotBindings(events.GetBlueprintBindings(id))
+			}
+		}
+	}
+
+	if timers := e.timerService(); timers != nil {
+		for _, pending := range timers.Pending() {
+			snapshot.Schedules = append(snapshot.Schedules, SnapshotSchedule{
+				BlueprintID: pending.BlueprintID,
+				NodeID:      pending.NodeID,
+				ExecutionID: pending.ExecutionID,
+				Mode:        pending.Mode,
+				FireAt:      pending.FireAt,
+				Triggers:    pending.Triggers,
+				Inputs:      pending.Inputs,
+			})
+		}
+	}
+
+	snapshot.Executions = e.suspendedExecutions()
+	return snapshot
+}
+
+// RestoreSnapshot loads the blueprints of a snapshot with their events and bindings, and
+// schedules its pending node activations. Blueprints this engine already loaded keep their
+// version, events and bindings. Invalid snapshots are rejected before anything is restored.
+func (e *ExecutionEngine) RestoreSnapshot(snapshot *EngineSnapshot) (*SnapshotRestore, error) {
+	if err := validateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	restore := &SnapshotRestore{Executions: snapshot.Executions}
+	if restore.Executions == nil {
+		restore.Executions = make([]SuspendedExecution, 0)
+	}
+
+	var errs []error
+	for _, loaded := range snapshot.Blueprints {
+		bp := loaded.Blueprint
+
+		e.mutex.Lock()
+		_, exists := e.currentVersions[bp.ID]
+		if !exists && loaded.Active {
+			e.activeVersions[bp.ID] = bp.Version
+		}
+		extensions := e.extensions
+		e.mutex.Unlock()
+		if exists {
+			restore.Skipped = append(restore.Skipped, bp.ID)
+			continue
+		}
+
+		if err := e.LoadBlueprint(bp); err != nil {
+			errs = append(errs, fmt.Errorf("blueprint %s: %w", bp.ID, err))
+			continue
+		}
+
+		// The events and bindings held for the blueprint replace the ones it declares
+		if extensions != nil && extensions.GetConcreteEventManager() != nil {
+			replaced := &blueprint.Blueprint{ID: bp.ID, Events: loaded.Events, EventBindings: loaded.Bindings}
+			if err := extensions.GetConcreteEventManager().ReplaceBlueprintEvents(bp.ID, eventDefinitions(replaced), eventBindings(replaced)); err != nil {
+				errs = append(errs, fmt.Errorf("events of blueprint %s: %w", bp.ID, err))
+			}
+		}
+		restore.Blueprints++
+	}
+
+	if timers := e.timerService(); timers != nil && len(snapshot.Schedules) > 0 {
+		pending := make([]models.NodeTimer, 0, len(snapshot.Schedules))
+		for _, schedule := range snapshot.Schedules {
+			pending = append(pending, models.NodeTimer{
+				BlueprintID: schedule.BlueprintID,
+				NodeID:      schedule.NodeID,
+				ExecutionID: schedule.ExecutionID,
+				Mode:        schedule.Mode,
+				FireAt:      schedule.FireAt,
+				Triggers:    schedule.Triggers,
+				Inputs:      schedule.Inputs,
+			})
+		}
+		scheduled, err := timers.Schedule(pending)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node timers: %w", err))
+		}
+		restore.Schedules = scheduled
+	}
+
+	e.logger.Info("Restored engine snapshot", map[string]interface{}{
+		"blueprints": restore.Blueprints,
+		"skipped":    len(restore.Skipped),
+		"schedules":  restore.Schedules,
+		"executions": len(restore.Executions),
+	})
+	return restore, errors.Join(errs...)
+}
+
+// validateSnapshot checks that a snapshot is complete and consistent: its blueprints have
+// unique IDs and their connections join their nodes, its schedules name a node and a known
+// mode, and its suspended executions are unique
+func validateSnapshot(snapshot *EngineSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("snapshot is empty")
+	}
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion)
+	}
+
+	blueprints := make(map[string]*blueprint.Blueprint, len(snapshot.Blueprints))
+	for i, loaded := range snapshot.Blueprints {
+		bp := loaded.Blueprint
+		if bp == nil || bp.ID == "" {
+			return fmt.Errorf("blueprint %d has no ID", i)
+		}
+		if _, exists := blueprints[bp.ID]; exists {
+			return fmt.Errorf("blueprint %s appears more than once", bp.ID)
+		}
+		blueprints[bp.ID] = bp
+
+		nodes := make(map[string]bool, len(bp.Nodes))
+		for _, blueprintNode := range bp.Nodes {
+			if blueprintNode.ID == "" || blueprintNode.Type == "" {
+				return fmt.Errorf("blueprint %s has a node without an ID or type", bp.ID)
+			}
+			if nodes[blueprintNode.ID] {
+				return fmt.Errorf("blueprint %s has more than one node %s", bp.ID, blueprintNode.ID)
+			}
+			nodes[blueprintNode.ID] = true
+		}
+		for _, conn := range bp.Connections {
+			if !nodes[conn.SourceNodeID] || !nodes[conn.TargetNodeID] {
+				return fmt.Errorf("connection %s of blueprint %s joins unknown nodes", conn.ID, bp.ID)
+			}
+		}
+	}
+
+	for _, schedule := range snapshot.Schedules {
+		if schedule.BlueprintID == "" || schedule.NodeID == "" || schedule.ExecutionID == "" {
+			return fmt.Errorf("schedule of node %q is missing its blueprint, node or execution", schedule.NodeID)
+		}
+		if schedule.Mode != node.RateLimitDebounce && schedule.Mode != node.RateLimitThrottle {
+			return fmt.Errorf("schedule of node %s has unknown mode %q", schedule.NodeID, schedule.Mode)
+		}
+		// Schedules may belong to blueprints the snapshot leaves to the loaded ones
+		if bp, exists := blueprints[schedule.BlueprintID]; exists && bp.FindNode(schedule.NodeID) == nil {
+			return fmt.Errorf("schedule names node %s, which blueprint %s doesn't have", schedule.NodeID, schedule.BlueprintID)
+		}
+	}
+
+	executions := make(map[string]bool, len(snapshot.Executions))
+	for _, suspended := range snapshot.Executions {
+		if suspended.ExecutionID == "" || suspended.BlueprintID == "" {
+			return fmt.Errorf("suspended execution %q is missing its ID or blueprint", suspended.ExecutionID)
+		}
+		if executions[suspended.ExecutionID] {
+			return fmt.Errorf("suspended execution %s appears more than once", suspended.ExecutionID)
+		}
+		executions[suspended.ExecutionID] = true
+	}
+
+	return nil
+}
+
+// setSuspended records an execution submitted to the scheduler until it starts
+func (e *ExecutionEngine) setSuspended(executionID, blueprintID string, priority ExecutionPriority) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.suspended[executionID] = SuspendedExecution{
+		ExecutionID: executionID,
+		BlueprintID: blueprintID,
+		Priority:    priority.String(),
+		WaitingFor:  WaitingForWorker,
+		QueuedAt:    time.Now(),
+	}
+}
+
+// clearSuspended drops an execution that started or was turned away
+func (e *ExecutionEngine) clearSuspended(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.suspended, executionID)
+}
+
+// suspendedExecutions returns the executions waiting for a scheduler worker or for the
+// running execution of their blueprint, oldest first
+func (e *ExecutionEngine) suspendedExecutions() []SuspendedExecution {
+	e.mutex.RLock()
+	suspended := make(map[string]SuspendedExecution, len(e.suspended))
+	for id, execution := range e.suspended {
+		suspended[id] = execution
+	}
+	e.mutex.RUnlock()
+
+	// Executions queued behind a running one may have been started outside the scheduler
+	e.concurrencyMutex.Lock()
+	for blueprintID, slot := range e.concurrency {
+		for _, waiting := range slot.queue {
+			execution, exists := suspended[waiting.executionID]
+			if !exists {
+				execution = SuspendedExecution{
+					ExecutionID: waiting.executionID,
+					BlueprintID: blueprintID,
+					Priority:    PriorityNormal.String(),
+					QueuedAt:    waiting.queuedAt,
+				}
+			}
+			execution.WaitingFor = WaitingForBlueprint
+			suspended[waiting.executionID] = execution
+		}
+	}
+	e.concurrencyMutex.Unlock()
+
+	executions := make([]SuspendedExecution, 0, len(suspended))
+	for _, execution := range suspended {
+		executions = append(executions, execution)
+	}
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].QueuedAt.Before(executions[j].QueuedAt)
+	})
+	return executions
+}
+
+// snapshotEvents converts the custom events of a blueprint to their declared form
+func snapshotEvents(definitions []event.EventDefinition) []blueprint.EventDefinition {
+	events := make([]blueprint.EventDefinition, 0, len(definitions))
+	for _, definition := range definitions {
+		parameters := make([]blueprint.EventParameter, 0, len(definition.Parameters))
+		for _, parameter := range definition.Parameters {
+			typeID := types.PinTypes.Any.ID
+			if parameter.Type != nil {
+				typeID = parameter.Type.ID
+			}
+			parameters = append(parameters, blueprint.EventParameter{
+				Name:        parameter.Name,
+				TypeID:      typeID,
+				Description: parameter.Description,
+				Optional:    parameter.Optional,
+				Default:     parameter.Default,
+			})
+		}
+		events = append(events, blueprint.EventDefinition{
+			ID:           definition.ID,
+			Name:         definition.Name,
+			Description:  definition.Description,
+			Parameters:   parameters,
+			Category:     definition.Category,
+			DispatchMode: definition.DispatchMode,
+		})
+	}
+	return events
+}
+
+// snapshotBindings converts the event bindings of a blueprint to their declared form
+func snapshotBindings(eventBindings []event.EventBinding) []blueprint.EventBinding {
+	bindings := make([]blueprint.EventBinding, 0, len(eventBindings))
+	for _, binding := range eventBindings {
+		bindings = append(bindings, blueprint.EventBinding{
+			ID:          binding.ID,
+			EventID:     binding.EventID,
+			HandlerID:   binding.HandlerID,
+			HandlerType: binding.HandlerType,
+			Priority:    binding.Priority,
+			Enabled:     binding.Enabled,
+			Delivery:    binding.Delivery,
+		})
+	}
+	return bindings
+}
@@ -0,0 +1,89 @@
+package engine_test
+
+import (
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/pkg/blueprint"
+)
+
+func TestRestoreSnapshotLoadsTheBlueprints(t *testing.T) {
+	source := newEngine(t, engine.ModeStandard)
+	if err := source.LoadBlueprint(chainBlueprint(2)); err != nil {
+		t.Fatalf("failed to load blueprint: %v", err)
+	}
+	snapshot := source.Snapshot()
+	if len(snapshot.Blueprints) != 1 {
+		t.Fatalf("expected the loaded blueprint in the snapshot, got %d blueprints", len(snapshot.Blueprints))
+	}
+
+	target := newEngine(t, engine.ModeStandard)
+	restored, err := target.RestoreSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("failed to restore snapshot: %v", err)
+	}
+	if restored.Blueprints != 1 {
+		t.Errorf("expected 1 restored blueprint, got %d", restored.Blueprints)
+	}
+
+	// Blueprints the engine already loaded keep their version
+	restored, err = target.RestoreSnapshot(snapshot)
+	if err != nil || restored.Blueprints != 0 || len(restored.Skipped) != 1 {
+		t.Errorf("expected the loaded blueprint to be skipped, got %+v (%v)", restored, err)
+	}
+}
+
+func TestRestoreSnapshotRejectsInvalidSnapshots(t *testing.T) {
+	valid := func() *engine.EngineSnapshot {
+		return &engine.EngineSnapshot{
+			Version:    engine.SnapshotVersion,
+			Blueprints: []engine.SnapshotBlueprint{{Blueprint: chainBlueprint(1)}},
+		}
+	}
+	schedule := engine.SnapshotSchedule{BlueprintID: "contention", NodeID: "print-0", ExecutionID: "execution", Mode: node.RateLimitDebounce, FireAt: time.Now()}
+
+	for name, corrupt := range map[string]func(snapshot *engine.EngineSnapshot){
+		"unknown version": func(snapshot *engine.EngineSnapshot) { snapshot.Version = engine.SnapshotVersion + 1 },
+		"missing blueprint": func(snapshot *engine.EngineSnapshot) {
+			snapshot.Blueprints = append(snapshot.Blueprints, engine.SnapshotBlueprint{})
+		},
+		"duplicate blueprint": func(snapshot *engine.EngineSnapshot) {
+			snapshot.Blueprints = append(snapshot.Blueprints, engine.SnapshotBlueprint{Blueprint: chainBlueprint(1)})
+		},
+		"dangling connection": func(snapshot *engine.EngineSnapshot) {
+			snapshot.Blueprints[0].Blueprint.AddConnection(blueprint.Connection{ID: "dangling", SourceNodeID: "start", TargetNodeID: "missing", ConnectionType: "execution"})
+		},
+		"schedule of an unknown node": func(snapshot *engine.EngineSnapshot) {
+			unknown := schedule
+			unknown.NodeID = "missing"
+			snapshot.Schedules = []engine.SnapshotSchedule{schedule, unknown}
+		},
+		"schedule of an unknown mode": func(snapshot *engine.EngineSnapshot) {
+			unknown := schedule
+			unknown.Mode = "sometimes"
+			snapshot.Schedules = []engine.SnapshotSchedule{unknown}
+		},
+		"duplicate execution": func(snapshot *engine.EngineSnapshot) {
+			suspended := engine.SuspendedExecution{ExecutionID: "execution", BlueprintID: "contention"}
+			snapshot.Executions = []engine.SuspendedExecution{suspended, suspended}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			flowEngine := newEngine(t, engine.ModeStandard)
+			snapshot := valid()
+			corrupt(snapshot)
+
+			if restored, err := flowEngine.RestoreSnapshot(snapshot); err == nil {
+				t.Fatalf("expected the snapshot to be rejected, got %+v", restored)
+			}
+			if loaded := flowEngine.Snapshot().Blueprints; len(loaded) != 0 {
+				t.Errorf("expected nothing to be restored, got %d blueprints", len(loaded))
+			}
+		})
+	}
+
+	if _, err := newEngine(t, engine.ModeStandard).RestoreSnapshot(nil); err == nil {
+		t.Error("expected an empty snapshot to be rejected")
+	}
+}
@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"webblueprint/internal/bperrors"
+)
+
+// DefaultActorRestartBudget is how often the actor of a node is restarted during an execution
+// before the execution fails
+const DefaultActorRestartBudget = 3
+
+// supervise restarts the actor of a node when its message loop ends without Stop
+func (s *ActorSystem) supervise(actor *NodeActor) {
+	select {
+	case <-actor.exited:
+		if _, err := s.restart(actor); err != nil {
+			s.logger.Error("Node actor not restarted", map[string]interface{}{"nodeId": actor.NodeID, "error": err.Error()})
+		}
+	case <-actor.done:
+	}
+}
+
+// restart replaces an exited actor with a new one that keeps its inputs and outputs, and
+// returns the actor now running the node. Once the node used up its restart budget the
+// execution fails with the cause instead.
+func (s *ActorSystem) restart(exited *NodeActor) (*NodeActor, error) {
+	// Senders may notice a closed mailbox before the message loop does
+	<-exited.exited
+
+	s.supervisorMutex.Lock()
+	defer s.supervisorMutex.Unlock()
+
+	if s.failure != nil {
+		dropPending(exited)
+		return nil, s.failure
+	}
+
+	// Senders and the supervisor race to restart the same actor
+	s.mutex.RLock()
+	current := s.actors[exited.NodeID]
+	s.mutex.RUnlock()
+	if current != exited {
+		return current, nil
+	}
+
+	s.restarts[exited.NodeID]++
+	restarts := s.restarts[exited.NodeID]
+	if restarts > s.restartBudget {
+		s.failure = bperrors.New(
+			bperrors.ErrorTypeSystem,
+			bperrors.ErrActorFailed,
+			fmt.Sprintf("actor of node %s exited %d times, exceeding the restart budget of %d: %v", exited.NodeID, restarts, s.restartBudget, exited.exitErr),
+			bperrors.SeverityCritical,
+		).WithNodeInfo(exited.NodeID, "").WithBlueprintInfo(s.blueprintID, s.executionID).WithDetails(map[string]interface{}{
+			"restarts": restarts - 1,
+			"cause":    exited.exitErr.Error(),
+		})
+		exited.emitNodeErrorEvent(s.failure)
+		dropPending(exited)
+		return nil, s.failure
+	}
+
+	nodeConfig := exited.bp.FindNode(exited.NodeID)
+	if nodeConfig == nil {
+		dropPending(exited)
+		return nil, fmt.Errorf("node not found: %s", exited.NodeID)
+	}
+	replacement, actorCtx, err := s.newActor(exited.bp, *nodeConfig)
+	if err != nil {
+		dropPending(exited)
+		return nil, err
+	}
+
+	// The replacement picks up where the exited actor left off
+	exited.mutex.RLock()
+	for pinID, value := range exited.inputs {
+		replacement.inputs[pinID] = value
+	}
+	for pinID, value := range exited.outputs {
+		replacement.outputs[pinID] = value
+	}
+	replacement.loopCurrentIndex = exited.loopCurrentIndex
+	replacement.loopMaxIterations = exited.loopMaxIterations
+	replacement.loopStartIndex = exited.loopStartIndex
+	replacement.isLooping = exited.isLooping
+	exited.mutex.RUnlock()
+
+	s.mutex.Lock()
+	s.actors[exited.NodeID] = replacement
+	s.mutex.Unlock()
+
+	replacement.Start(actorCtx)
+	for pinID, value := range replacement.inputValues() {
+		replacement.ctx.SetInput(pinID, value)
+	}
+
+	// Messages sent without waiting for a response would be lost with the exited actor,
+	// including the one it was handling. Senders waiting for a response send theirs again.
+	forwardPending(exited, replacement)
+
+	go s.supervise(replacement)
+
+	s.logger.Warn("Restarted node actor", map[string]interface{}{
+		"nodeId":   exited.NodeID,
+		"restarts": restarts,
+		"cause":    exited.exitErr.Error(),
+	})
+	return replacement, nil
+}
+
+// forwardPending moves the messages without a response channel that an exited actor was
+// handling or left in its mailbox to its replacement, in the order they were sent. They stay
+// tracked as work of the execution until the replacement handles them.
+func forwardPending(exited, replacement *NodeActor) {
+	forward := func(msg NodeMessage) {
+		if sent, _ := replacement.post(msg); !sent {
+			replacement.settle(msg)
+		}
+	}
+	if exited.inFlight != nil && exited.inFlight.Response == nil {
+		forward(*exited.inFlight)
+	}
+	exited.inFlight = nil
+	for {
+		select {
+		case msg, ok := <-exited.mailbox:
+			if !ok {
+				return
+			}
+			if msg.Response == nil {
+				forward(msg)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// dropPending settles the messages without a response channel an exited actor that isn't
+// replaced was handling or left in its mailbox, so the execution can complete
+func dropPending(exited *NodeActor) {
+	if exited.inFlight != nil {
+		exited.settle(*exited.inFlight)
+	}
+	exited.inFlight = nil
+	for {
+		select {
+		case msg, ok := <-exited.mailbox:
+			if !ok {
+				return
+			}
+			exited.settle(msg)
+		default:
+			return
+		}
+	}
+}
+
+// send sends a message to the actor of a node and returns the actor that answered with its
+// response. Messages lost with an exited actor are sent again to its replacement.
+func (s *ActorSystem) send(actor *NodeActor, msg NodeMessage) (*NodeActor, NodeResponse) {
+	for {
+		response := actor.Send(msg)
+
+		var exited *actorExitedError
+		if response.Success || !errors.As(response.Error, &exited) {
+			return actor, response
+		}
+
+		replacement, err := s.restart(actor)
+		if err != nil {
+			return actor, NodeResponse{Success: false, Error: err}
+		}
+		actor = replacement
+		msg.Response = make(chan NodeResponse, 1)
+	}
+}
+
+// sendAsync sends a message to the actor of a node without waiting for a response, to the
+// replacement of the actor if it exited
+func (s *ActorSystem) sendAsync(actor *NodeActor, msg NodeMessage) bool {
+	select {
+	case <-actor.exited:
+		replacement, err := s.restart(actor)
+		if err != nil {
+			return false
+		}
+		actor = replacement
+	default:
+	}
+	return actor.SendAsync(msg)
+}
+
+// fail fails the execution whatever the error policy, unless it already failed
+func (s *ActorSystem) fail(err error) {
+	s.supervisorMutex.Lock()
+	defer s.supervisorMutex.Unlock()
+	if s.failure == nil {
+		s.failure = err
+	}
+}
+
+// failed returns the error the execution failed with whatever the error policy, if any
+func (s *ActorSystem) failed() error {
+	s.supervisorMutex.Lock()
+	defer s.supervisorMutex.Unlock()
+	return s.failure
+}
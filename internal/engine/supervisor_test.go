@@ -0,0 +1,135 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// panickingLogger panics while the loop node logs a message starting with the given prefix,
+// the given number of times or always if negative, which ends the message loop of its actor
+type panickingLogger struct {
+	nopLogger
+	prefix string
+	always bool
+	panics atomic.Int64
+}
+
+func newPanickingLogger(prefix string, panics int64) *panickingLogger {
+	logger := &panickingLogger{prefix: prefix, always: panics < 0}
+	logger.panics.Store(panics)
+	return logger
+}
+
+func (l *panickingLogger) Info(message string, _ map[string]interface{}) {
+	if !strings.HasPrefix(message, l.prefix) {
+		return
+	}
+	if l.always || l.panics.Add(-1) >= 0 {
+		panic("logger failed")
+	}
+}
+
+// The loop node handles its first step, sent without waiting for a response, and logs the
+// iteration, and handles its last step, sent waiting for the response, and logs it finished
+const (
+	asyncLoopMessage = "handleLoop Loop iteration"
+	syncLoopMessage  = "Loop finished or stopped"
+)
+
+// loopBlueprint runs a print node for every iteration of a loop and another one once the
+// loop completed
+func loopBlueprint(iterations int) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("supervised-loop", "Supervised Loop", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "loop", Type: "loop", Data: map[string]interface{}{
+		"defaults": map[string]interface{}{"iterations": float64(iterations)},
+	}})
+	bp.AddNode(blueprint.BlueprintNode{ID: "body", Type: "print"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "done", Type: "print"})
+	for _, conn := range []blueprint.Connection{
+		{ID: "start-loop", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "loop", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "loop-body", SourceNodeID: "loop", SourcePinID: "loop", TargetNodeID: "body", TargetPinID: "execute", ConnectionType: "execution"},
+		{ID: "loop-done", SourceNodeID: "loop", SourcePinID: "completed", TargetNodeID: "done", TargetPinID: "execute", ConnectionType: "execution"},
+	} {
+		bp.AddConnection(conn)
+	}
+	return bp
+}
+
+func TestActorRestartedAfterPanic(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		message    string
+		iterations int
+		panics     int64
+	}{
+		{name: "async message", message: asyncLoopMessage, iterations: 1, panics: 1},
+		{name: "sync message", message: syncLoopMessage, iterations: 1, panics: 1},
+		{name: "async message up to the budget", message: asyncLoopMessage, iterations: 5, panics: engine.DefaultActorRestartBudget},
+		{name: "sync message up to the budget", message: syncLoopMessage, iterations: 1, panics: engine.DefaultActorRestartBudget},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			flowEngine := newEngineWithLogger(t, engine.ModeActor, newPanickingLogger(test.message, test.panics))
+
+			var mutex sync.Mutex
+			runs := make(map[string]int)
+			flowEngine.SetHooks(engine.Hooks{OnNodeExecution: func(_ context.Context, _, nodeID, _, execState string, _, _ map[string]interface{}) error {
+				if execState == "completed" {
+					mutex.Lock()
+					runs[nodeID]++
+					mutex.Unlock()
+				}
+				return nil
+			}})
+
+			result, err := flowEngine.Execute(loopBlueprint(test.iterations), "restart-exec", map[string]types.Value{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("Expected the execution to succeed, got %v", result.Error)
+			}
+
+			// The replacement of the actor handles the message the exited one was handling
+			mutex.Lock()
+			defer mutex.Unlock()
+			if runs["done"] != 1 {
+				t.Errorf("Expected the completed flow to run once after the restarts, got %d", runs["done"])
+			}
+		})
+	}
+}
+
+func TestActorRestartBudgetExceeded(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		message    string
+		iterations int
+	}{
+		{name: "async message", message: asyncLoopMessage, iterations: 10},
+		{name: "sync message", message: syncLoopMessage, iterations: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			flowEngine := newEngineWithLogger(t, engine.ModeActor, newPanickingLogger(test.message, -1))
+
+			result, err := flowEngine.Execute(loopBlueprint(test.iterations), "budget-exec", map[string]types.Value{})
+			if result.Success {
+				t.Fatal("Expected the execution to fail once the actor used up its restarts")
+			}
+			for _, failure := range []error{err, result.Error} {
+				var bpErr *bperrors.BlueprintError
+				if !errors.As(failure, &bpErr) || bpErr.Code != bperrors.ErrActorFailed {
+					t.Errorf("Expected an %s error, got %v", bperrors.ErrActorFailed, failure)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package engine_test
+
+import (
+	"sync"
+	"testing"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// switchPins are the execution outputs of the switch in switchBlueprint
+var switchPins = []string{"case_A", "case_B", "default"}
+
+// switchBlueprint switches on the value of a source node between the cases A and B. Each
+// output of the switch runs its own probe, which records the matched case.
+func switchBlueprint() *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("switch", "Switch", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "source", Type: "choice-source"})
+	bp.AddNode(blueprint.BlueprintNode{
+		ID:   "choice",
+		Type: "switch",
+		Data: map[string]interface{}{
+			"cases": []interface{}{
+				map[string]interface{}{"id": "A", "value": "A"},
+				map[string]interface{}{"id": "B", "value": "B"},
+			},
+		},
+	})
+	connections := []blueprint.Connection{
+		{ID: "exec-source", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "source", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "exec-choice", SourceNodeID: "source", SourcePinID: "then", TargetNodeID: "choice", TargetPinID: "exec", ConnectionType: "execution"},
+		{ID: "data-choice", SourceNodeID: "source", SourcePinID: "value", TargetNodeID: "choice", TargetPinID: "value", ConnectionType: "data"},
+	}
+
+	for _, pinID := range switchPins {
+		probeID := "probe-" + pinID
+		bp.AddNode(blueprint.BlueprintNode{ID: probeID, Type: probeID})
+		connections = append(connections,
+			blueprint.Connection{ID: "exec-" + probeID, SourceNodeID: "choice", SourcePinID: pinID, TargetNodeID: probeID, TargetPinID: "exec", ConnectionType: "execution"},
+			blueprint.Connection{ID: "data-" + probeID, SourceNodeID: "choice", SourcePinID: "matched_case", TargetNodeID: probeID, TargetPinID: "value", ConnectionType: "data"},
+		)
+	}
+
+	for _, conn := range connections {
+		bp.AddConnection(conn)
+	}
+	return bp
+}
+
+func TestSwitchRunsTheMatchingCaseInEveryMode(t *testing.T) {
+	for _, test := range []struct {
+		value   string
+		pin     string
+		matched string
+	}{
+		{"B", "case_B", "B"},
+		{"Z", "default", "default"},
+	} {
+		for _, mode := range []engine.ExecutionMode{engine.ModeStandard, engine.ModeActor} {
+			t.Run(test.value+"/"+string(mode), func(t *testing.T) {
+				flowEngine := newEngine(t, mode)
+				registry.GetInstance().RegisterNodeType("choice-source", func() node.Node {
+					return newSourceNode(types.NewValue(types.PinTypes.String, test.value))
+				})
+				probes := make(map[string]*sync.Map)
+				for _, pinID := range switchPins {
+					received := &sync.Map{}
+					probes[pinID] = received
+					registry.GetInstance().RegisterNodeType("probe-"+pinID, func() node.Node {
+						return newProbeNode(types.PinTypes.String, received)
+					})
+				}
+				executionID := "switch-" + test.value + "-" + string(mode)
+
+				result, err := flowEngine.Execute(switchBlueprint(), executionID, map[string]types.Value{})
+				if err != nil || !result.Success {
+					t.Fatalf("expected the execution to succeed, got %v", err)
+				}
+
+				for _, pinID := range switchPins {
+					stored, ran := probes[pinID].Load(executionID)
+					if expected := pinID == test.pin; ran != expected {
+						t.Errorf("%s: expected the output to run: %v, ran: %v", pinID, expected, ran)
+						continue
+					}
+					if ran && stored.(types.Value).RawValue != test.matched {
+						t.Errorf("%s: expected the matched case %s, got %v", pinID, test.matched, stored.(types.Value).RawValue)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"errors"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/core"
+	"webblueprint/internal/event"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+
+	"github.com/google/uuid"
+)
+
+// dispatchLifecycleEvent dispatches a lifecycle event of an execution to the handlers bound to
+// it by any blueprint. Handlers run in the background, each dispatch in an execution of its
+// own, so they neither hold up nor fail the execution the event is about.
+func (e *ExecutionEngine) dispatchLifecycleEvent(eventType core.SystemEventType, blueprintID, executionID string, parameters map[string]types.Value) {
+	e.mutex.RLock()
+	extensions := e.extensions
+	e.mutex.RUnlock()
+	if extensions == nil || extensions.GetConcreteEventManager() == nil {
+		return
+	}
+
+	events := extensions.GetConcreteEventManager()
+	eventID, exists := events.GetSystemEventID(eventType)
+	if !exists || !events.HasBindings(eventID) {
+		return
+	}
+
+	parameters["blueprintID"] = types.NewValue(types.PinTypes.String, blueprintID)
+	parameters["executionID"] = types.NewValue(types.PinTypes.String, executionID)
+	request := event.EventDispatchRequest{
+		EventID:     eventID,
+		Parameters:  parameters,
+		SourceID:    executionID,
+		BlueprintID: blueprintID,
+		ExecutionID: uuid.New().String(),
+		Timestamp:   time.Now(),
+	}
+
+	go func() {
+		for _, err := range events.DispatchEvent(request) {
+			e.logger.Warn("Lifecycle event handler failed", map[string]interface{}{
+				"eventId":     eventID,
+				"executionId": executionID,
+				"error":       err.Error(),
+			})
+		}
+	}()
+}
+
+// dispatchExecutionEnd dispatches whether an execution completed or failed. Executions whose
+// entry point guards rejected the trigger were skipped rather than failed.
+func (e *ExecutionEngine) dispatchExecutionEnd(bp *blueprint.Blueprint, executionID string, success bool, err error) {
+	status, started := e.GetExecutionStatus(executionID)
+	if !started || status.Status == "skipped" {
+		return
+	}
+
+	parameters := map[string]types.Value{
+		"durationMs": types.NewValue(types.PinTypes.Number, float64(time.Since(status.StartTime).Milliseconds())),
+	}
+	if success && err == nil {
+		e.dispatchLifecycleEvent(event.EventTypeExecutionCompleted, bp.ID, executionID, parameters)
+		return
+	}
+
+	if err == nil {
+		err = errors.New("execution failed")
+	}
+	for name, value := range failureParameters(err) {
+		parameters[name] = value
+	}
+	e.dispatchLifecycleEvent(event.EventTypeExecutionFailed, bp.ID, executionID, parameters)
+}
+
+// dispatchNodeError dispatches the unhandled error of a node
+func (e *ExecutionEngine) dispatchNodeError(blueprintID, executionID, nodeID string, err error) {
+	parameters := failureParameters(err)
+	parameters["nodeID"] = types.NewValue(types.PinTypes.String, nodeID)
+	e.dispatchLifecycleEvent(event.EventTypeNodeError, blueprintID, executionID, parameters)
+}
+
+// failureParameters returns the message of an error and its code, if it has one
+func failureParameters(err error) map[string]types.Value {
+	parameters := map[string]types.Value{
+		"errorMessage": types.NewValue(types.PinTypes.String, err.Error()),
+	}
+	var bpErr *bperrors.BlueprintError
+	if errors.As(err, &bpErr) {
+		parameters["errorCode"] = types.NewValue(types.PinTypes.String, string(bpErr.Code))
+	}
+	return parameters
+}
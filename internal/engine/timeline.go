@@ -0,0 +1,315 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+// maxTimelineSpans bounds the node executions recorded per execution, e.g. for long loops
+const maxTimelineSpans = 10000
+
+// TimelineTrigger describes the pin activation that started a node execution
+type TimelineTrigger struct {
+	SpanIndex    int    `json:"spanIndex"` // Span of the source node, -1 if unknown
+	SourceNodeID string `json:"sourceNodeId"`
+	SourcePinID  string `json:"sourcePinId"`
+	TargetPinID  string `json:"targetPinId"`
+}
+
+// TimelineSpan is a single execution of a node
+type TimelineSpan struct {
+	Index          int              `json:"index"`
+	NodeID         string           `json:"nodeId"`
+	NodeType       string           `json:"nodeType"`
+	Status         string           `json:"status"` // "executing", "completed", "error"
+	Error          string           `json:"error,omitempty"`
+	StartTime      time.Time        `json:"startTime"`
+	EndTime        time.Time        `json:"endTime"`
+	DurationMs     float64          `json:"durationMs"`
+	SelfMs         float64          `json:"selfMs"` // Duration excluding nodes triggered while this node was running
+	TriggeredBy    *TimelineTrigger `json:"triggeredBy,omitempty"`
+	Triggered      []int            `json:"triggered,omitempty"`
+	OnCriticalPath bool             `json:"onCriticalPath"`
+}
+
+// ExecutionTimeline lists the node executions of an execution in start order
+type ExecutionTimeline struct {
+	ExecutionID    string         `json:"executionId"`
+	StartTime      time.Time      `json:"startTime"`
+	EndTime        time.Time      `json:"endTime"`
+	DurationMs     float64        `json:"durationMs"`
+	Spans          []TimelineSpan `json:"spans"`
+	CriticalPath   []int          `json:"criticalPath"`
+	CriticalPathMs float64        `json:"criticalPathMs"`
+	Truncated      bool           `json:"truncated"`
+}
+
+// executionTimeline records the spans and pending activations of an execution
+type executionTimeline struct {
+	spans     []TimelineSpan
+	pending   map[string][]TimelineTrigger // Target node ID -> activations not yet started
+	lastSpan  map[string]int               // Node ID -> index of its latest span
+	truncated bool
+}
+
+func newExecutionTimeline() *executionTimeline {
+	return &executionTimeline{
+		spans:    make([]TimelineSpan, 0),
+		pending:  make(map[string][]TimelineTrigger),
+		lastSpan: make(map[string]int),
+	}
+}
+
+// timelineLocked returns the timeline of an execution, creating it if needed. Requires the write lock.
+func (dm *DebugManager) timelineLocked(executionID string) *executionTimeline {
+	dm.touchLocked(executionID)
+
+	timeline, exists := dm.timelines[executionID]
+	if !exists {
+		timeline = newExecutionTimeline()
+		dm.timelines[executionID] = timeline
+	}
+	return timeline
+}
+
+// RecordActivation records that an execution pin of a node triggered another node
+func (dm *DebugManager) RecordActivation(executionID, sourceNodeID, sourcePinID, targetNodeID, targetPinID string) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	timeline := dm.timelineLocked(executionID)
+
+	trigger := TimelineTrigger{
+		SpanIndex:    -1,
+		SourceNodeID: sourceNodeID,
+		SourcePinID:  sourcePinID,
+		TargetPinID:  targetPinID,
+	}
+	if index, exists := timeline.lastSpan[sourceNodeID]; exists {
+		trigger.SpanIndex = index
+	}
+
+	timeline.pending[targetNodeID] = append(timeline.pending[targetNodeID], trigger)
+}
+
+// BeginNodeSpan records the start of a node execution and returns its span index,
+// or -1 if the timeline is full. The oldest pending activation of the node is
+// recorded as its trigger.
+func (dm *DebugManager) BeginNodeSpan(executionID, nodeID, nodeType string) int {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	timeline := dm.timelineLocked(executionID)
+
+	var trigger *TimelineTrigger
+	if pending := timeline.pending[nodeID]; len(pending) > 0 {
+		trigger = &pending[0]
+		timeline.pending[nodeID] = pending[1:]
+	}
+
+	if len(timeline.spans) >= maxTimelineSpans {
+		timeline.truncated = true
+		dm.beginBoundaryLocked(executionID, -1, nodeID, trigger)
+		return -1
+	}
+
+	index := len(timeline.spans)
+	dm.beginBoundaryLocked(executionID, index, nodeID, trigger)
+	timeline.spans = append(timeline.spans, TimelineSpan{
+		Index:       index,
+		NodeID:      nodeID,
+		NodeType:    nodeType,
+		Status:      "executing",
+		StartTime:   time.Now(),
+		TriggeredBy: trigger,
+	})
+	timeline.lastSpan[nodeID] = index
+
+	return index
+}
+
+// EndNodeSpan records the end of a node execution started with BeginNodeSpan
+func (dm *DebugManager) EndNodeSpan(executionID string, index int, err error) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	timeline, exists := dm.timelines[executionID]
+	if !exists || index < 0 || index >= len(timeline.spans) {
+		return
+	}
+	dm.endBoundaryLocked(executionID, index)
+
+	span := &timeline.spans[index]
+	span.EndTime = time.Now()
+	span.Status = "completed"
+	if err != nil {
+		span.Status = "error"
+		span.Error = err.Error()
+	}
+}
+
+// GetExecutionTimeline returns the analyzed timeline of an execution
+func (dm *DebugManager) GetExecutionTimeline(executionID string) (*ExecutionTimeline, bool) {
+	dm.mutex.RLock()
+	timeline, exists := dm.timelines[executionID]
+	if !exists {
+		dm.mutex.RUnlock()
+		return nil, false
+	}
+	spans := make([]TimelineSpan, len(timeline.spans))
+	copy(spans, timeline.spans)
+	truncated := timeline.truncated
+	dm.mutex.RUnlock()
+
+	dm.touch(executionID)
+
+	result := BuildExecutionTimeline(executionID, spans)
+	result.Truncated = truncated
+	return result, true
+}
+
+// BuildExecutionTimeline computes durations, trigger relations and the critical path
+// of node spans. Spans must be ordered by start time and indexed by their position;
+// spans that haven't ended are measured up to now.
+func BuildExecutionTimeline(executionID string, spans []TimelineSpan) *ExecutionTimeline {
+	timeline := &ExecutionTimeline{
+		ExecutionID:  executionID,
+		Spans:        spans,
+		CriticalPath: make([]int, 0),
+	}
+	if len(spans) == 0 {
+		return timeline
+	}
+
+	now := time.Now()
+	ends := make([]time.Time, len(spans))
+	children := make([][]int, len(spans))
+
+	timeline.StartTime = spans[0].StartTime
+	for i := range spans {
+		span := &spans[i]
+		span.Index = i
+		span.Triggered = nil
+		span.OnCriticalPath = false
+
+		ends[i] = span.EndTime
+		if ends[i].IsZero() {
+			ends[i] = now
+		}
+		span.DurationMs = milliseconds(ends[i].Sub(span.StartTime))
+
+		if span.StartTime.Before(timeline.StartTime) {
+			timeline.StartTime = span.StartTime
+		}
+		if ends[i].After(timeline.EndTime) {
+			timeline.EndTime = ends[i]
+		}
+
+		if parent := triggerParent(span, i); parent >= 0 {
+			children[parent] = append(children[parent], i)
+			spans[parent].Triggered = append(spans[parent].Triggered, i)
+		}
+	}
+	timeline.DurationMs = milliseconds(timeline.EndTime.Sub(timeline.StartTime))
+
+	// Nodes activated synchronously run inside their trigger's span, so that time
+	// is subtracted to get the time spent in the node itself
+	for i := range spans {
+		spans[i].SelfMs = spans[i].DurationMs - milliseconds(overlap(spans, ends, i, children[i]))
+	}
+
+	// The critical path is the chain of spans with the most self time. A span waits
+	// for the span that triggered it and, when siblings run one after another as in
+	// the standard engine, for the siblings that ended before it started. Those
+	// always start earlier, so a single pass in start order finds the longest chain.
+	best := make([]float64, len(spans))
+	previous := make([]int, len(spans))
+	for i := range spans {
+		previous[i] = triggerParent(&spans[i], i)
+		if parent := previous[i]; parent >= 0 {
+			for _, sibling := range children[parent] {
+				if sibling >= i {
+					break
+				}
+				if !ends[sibling].After(spans[i].StartTime) && best[sibling] > best[previous[i]] {
+					previous[i] = sibling
+				}
+			}
+		}
+
+		best[i] = spans[i].SelfMs
+		if previous[i] >= 0 {
+			best[i] += best[previous[i]]
+		}
+	}
+
+	last := 0
+	for i := range spans {
+		if best[i] > best[last] {
+			last = i
+		}
+	}
+
+	for i := last; i >= 0; i = previous[i] {
+		spans[i].OnCriticalPath = true
+		timeline.CriticalPath = append(timeline.CriticalPath, i)
+	}
+	for i, j := 0, len(timeline.CriticalPath)-1; i < j; i, j = i+1, j-1 {
+		timeline.CriticalPath[i], timeline.CriticalPath[j] = timeline.CriticalPath[j], timeline.CriticalPath[i]
+	}
+	timeline.CriticalPathMs = best[last]
+
+	return timeline
+}
+
+// triggerParent returns the span that triggered a span, or -1 if it's a root
+func triggerParent(span *TimelineSpan, index int) int {
+	if span.TriggeredBy == nil || span.TriggeredBy.SpanIndex < 0 || span.TriggeredBy.SpanIndex >= index {
+		return -1
+	}
+	return span.TriggeredBy.SpanIndex
+}
+
+// overlap returns how long the children of a span ran within the span
+func overlap(spans []TimelineSpan, ends []time.Time, parent int, children []int) time.Duration {
+	type interval struct{ start, end time.Time }
+
+	intervals := make([]interval, 0, len(children))
+	for _, child := range children {
+		start, end := spans[child].StartTime, ends[child]
+		if start.Before(spans[parent].StartTime) {
+			start = spans[parent].StartTime
+		}
+		if end.After(ends[parent]) {
+			end = ends[parent]
+		}
+		if end.After(start) {
+			intervals = append(intervals, interval{start, end})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	var total time.Duration
+	var current *interval
+	for i := range intervals {
+		if current != nil && !intervals[i].start.After(current.end) {
+			if intervals[i].end.After(current.end) {
+				current.end = intervals[i].end
+			}
+			continue
+		}
+		if current != nil {
+			total += current.end.Sub(current.start)
+		}
+		current = &intervals[i]
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+	return total
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,183 @@
+package engine_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+)
+
+// timelineSpan describes a span by its start and end in milliseconds after the execution
+// started and the span that triggered it, -1 for a root
+type timelineSpan struct {
+	start, end int
+	parent     int
+}
+
+// timelineSpans creates the spans of an execution started at base
+func timelineSpans(base time.Time, described []timelineSpan) []engine.TimelineSpan {
+	spans := make([]engine.TimelineSpan, len(described))
+	for i, span := range described {
+		spans[i] = engine.TimelineSpan{
+			NodeID:    string(rune('a' + i)),
+			Status:    "completed",
+			StartTime: base.Add(time.Duration(span.start) * time.Millisecond),
+			EndTime:   base.Add(time.Duration(span.end) * time.Millisecond),
+		}
+		if span.parent >= 0 {
+			spans[i].TriggeredBy = &engine.TimelineTrigger{SpanIndex: span.parent, SourceNodeID: string(rune('a' + span.parent))}
+		}
+	}
+	return spans
+}
+
+func TestBuildExecutionTimeline(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		name         string
+		spans        []timelineSpan
+		durationMs   float64
+		selfMs       []float64
+		triggered    [][]int
+		criticalPath []int
+		criticalMs   float64
+	}{
+		{
+			name:         "no spans",
+			criticalPath: []int{},
+		},
+		{
+			name:         "serial chain",
+			spans:        []timelineSpan{{0, 10, -1}, {10, 30, 0}, {30, 60, 1}},
+			durationMs:   60,
+			selfMs:       []float64{10, 20, 30},
+			triggered:    [][]int{{1}, {2}, nil},
+			criticalPath: []int{0, 1, 2},
+			criticalMs:   60,
+		},
+		{
+			name:         "parallel branches",
+			spans:        []timelineSpan{{0, 10, -1}, {10, 50, 0}, {10, 30, 0}},
+			durationMs:   50,
+			selfMs:       []float64{10, 40, 20},
+			triggered:    [][]int{{1, 2}, nil, nil},
+			criticalPath: []int{0, 1},
+			criticalMs:   50,
+		},
+		{
+			name:         "parallel branches with the longest chain started last",
+			spans:        []timelineSpan{{0, 10, -1}, {10, 50, 0}, {10, 20, 0}, {20, 55, 2}},
+			durationMs:   55,
+			selfMs:       []float64{10, 40, 10, 35},
+			triggered:    [][]int{{1, 2}, nil, {3}, nil},
+			criticalPath: []int{0, 2, 3},
+			criticalMs:   55,
+		},
+		{
+			name:         "nested siblings running one after another",
+			spans:        []timelineSpan{{0, 100, -1}, {10, 40, 0}, {40, 90, 0}},
+			durationMs:   100,
+			selfMs:       []float64{20, 30, 50},
+			triggered:    [][]int{{1, 2}, nil, nil},
+			criticalPath: []int{0, 1, 2},
+			criticalMs:   100,
+		},
+		{
+			name:         "nested siblings running together",
+			spans:        []timelineSpan{{0, 100, -1}, {10, 60, 0}, {20, 50, 0}},
+			durationMs:   100,
+			selfMs:       []float64{50, 50, 30},
+			triggered:    [][]int{{1, 2}, nil, nil},
+			criticalPath: []int{0, 1},
+			criticalMs:   100,
+		},
+		{
+			name:         "nested spans at several levels",
+			spans:        []timelineSpan{{0, 100, -1}, {10, 70, 0}, {20, 50, 1}, {70, 90, 0}},
+			durationMs:   100,
+			selfMs:       []float64{20, 30, 30, 20},
+			triggered:    [][]int{{1, 3}, {2}, nil, nil},
+			criticalPath: []int{0, 1, 2},
+			criticalMs:   80,
+		},
+		{
+			name:         "child outlasting its trigger",
+			spans:        []timelineSpan{{0, 50, -1}, {40, 80, 0}},
+			durationMs:   80,
+			selfMs:       []float64{40, 40},
+			triggered:    [][]int{{1}, nil},
+			criticalPath: []int{0, 1},
+			criticalMs:   80,
+		},
+		{
+			name:         "independent roots",
+			spans:        []timelineSpan{{0, 30, -1}, {5, 20, -1}, {20, 45, 1}},
+			durationMs:   45,
+			selfMs:       []float64{30, 15, 25},
+			triggered:    [][]int{nil, {2}, nil},
+			criticalPath: []int{1, 2},
+			criticalMs:   40,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			timeline := engine.BuildExecutionTimeline("exec", timelineSpans(base, test.spans))
+
+			if timeline.ExecutionID != "exec" {
+				t.Errorf("expected the timeline of exec, got %s", timeline.ExecutionID)
+			}
+			if timeline.DurationMs != test.durationMs {
+				t.Errorf("expected the execution to take %vms, got %vms", test.durationMs, timeline.DurationMs)
+			}
+			for i, span := range timeline.Spans {
+				described := test.spans[i]
+				if span.Index != i {
+					t.Errorf("span %d: expected index %d, got %d", i, i, span.Index)
+				}
+				if durationMs := float64(described.end - described.start); span.DurationMs != durationMs {
+					t.Errorf("span %d: expected a duration of %vms, got %vms", i, durationMs, span.DurationMs)
+				}
+				if span.SelfMs != test.selfMs[i] {
+					t.Errorf("span %d: expected %vms of self time, got %vms", i, test.selfMs[i], span.SelfMs)
+				}
+				if !reflect.DeepEqual(span.Triggered, test.triggered[i]) {
+					t.Errorf("span %d: expected to trigger %v, got %v", i, test.triggered[i], span.Triggered)
+				}
+			}
+			if !reflect.DeepEqual(timeline.CriticalPath, test.criticalPath) {
+				t.Errorf("expected the critical path %v, got %v", test.criticalPath, timeline.CriticalPath)
+			}
+			if timeline.CriticalPathMs != test.criticalMs {
+				t.Errorf("expected a critical path of %vms, got %vms", test.criticalMs, timeline.CriticalPathMs)
+			}
+			onPath := make(map[int]bool)
+			for _, index := range test.criticalPath {
+				onPath[index] = true
+			}
+			for i, span := range timeline.Spans {
+				if span.OnCriticalPath != onPath[i] {
+					t.Errorf("span %d: expected on the critical path %v, got %v", i, onPath[i], span.OnCriticalPath)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildExecutionTimelineMeasuresRunningSpans(t *testing.T) {
+	start := time.Now().Add(-time.Second)
+	spans := []engine.TimelineSpan{
+		{NodeID: "done", Status: "completed", StartTime: start, EndTime: start.Add(100 * time.Millisecond)},
+		{NodeID: "running", Status: "executing", StartTime: start.Add(100 * time.Millisecond)},
+	}
+
+	timeline := engine.BuildExecutionTimeline("exec", spans)
+	if running := timeline.Spans[1]; running.DurationMs < 900 {
+		t.Errorf("expected the running span to be measured up to now, got %vms", running.DurationMs)
+	}
+	if timeline.DurationMs < 1000 {
+		t.Errorf("expected the execution to last until now, got %vms", timeline.DurationMs)
+	}
+	if !reflect.DeepEqual(timeline.CriticalPath, []int{1}) {
+		t.Errorf("expected the running span to be the critical path, got %v", timeline.CriticalPath)
+	}
+}
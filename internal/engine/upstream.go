@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"time"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// isUpstream reports whether a node is upstream of another in the execution graph of a
+// blueprint, i.e. the other node is reachable from it over connections of any kind. A node
+// isn't upstream of itself, even in a loop.
+func isUpstream(bp *blueprint.Blueprint, upstreamID, nodeID string) bool {
+	if bp == nil || upstreamID == nodeID {
+		return false
+	}
+
+	sources := make(map[string][]string)
+	for _, conn := range bp.Connections {
+		sources[conn.TargetNodeID] = append(sources[conn.TargetNodeID], conn.SourceNodeID)
+	}
+
+	visited := map[string]bool{nodeID: true}
+	queue := []string{nodeID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, source := range sources[current] {
+			if source == upstreamID {
+				return true
+			}
+			if !visited[source] {
+				visited[source] = true
+				queue = append(queue, source)
+			}
+		}
+	}
+	return false
+}
+
+// upstreamEdge creates the lineage edge of an output value a node read from an upstream
+// node rather than received over a connection
+func upstreamEdge(sourceNodeID, sourcePinID, targetNodeID string, value types.Value) LineageEdge {
+	return LineageEdge{
+		SourceNodeID: sourceNodeID,
+		SourcePinID:  sourcePinID,
+		TargetNodeID: targetNodeID,
+		Upstream:     true,
+		Value:        value.RawValue,
+		Delivered:    value.RawValue,
+		Time:         time.Now(),
+	}
+}
+
+// upstreamOutput resolves GetUpstreamOutput for a node of a standard mode execution, from
+// the output values stored in the node's scope or the debug manager
+func (e *ExecutionEngine) upstreamOutput(bp *blueprint.Blueprint, executionID, nodeID, sourceNodeID, pinID string, scope *executionScope) (types.Value, bool) {
+	if !isUpstream(bp, sourceNodeID, nodeID) {
+		return types.Value{}, false
+	}
+
+	raw, exists := e.outputValue(scope, executionID, sourceNodeID, pinID)
+	if !exists {
+		return types.Value{}, false
+	}
+
+	value := types.NewValue(types.InferPinType(raw), raw)
+	e.debugManager.RecordDataFlow(executionID, upstreamEdge(sourceNodeID, pinID, nodeID, value))
+	return value, true
+}
+
+// upstreamOutput resolves GetUpstreamOutput for a node of an actor mode execution, from
+// the outputs of the upstream node's actor
+func (s *ActorSystem) upstreamOutput(bp *blueprint.Blueprint, nodeID, sourceNodeID, pinID string) (types.Value, bool) {
+	if !isUpstream(bp, sourceNodeID, nodeID) {
+		return types.Value{}, false
+	}
+
+	s.mutex.RLock()
+	source, exists := s.actors[sourceNodeID]
+	s.mutex.RUnlock()
+	if !exists {
+		// Child scopes only have actors for the nodes of their branch
+		if s.parent != nil {
+			return s.parent.upstreamOutput(bp, nodeID, sourceNodeID, pinID)
+		}
+		return types.Value{}, false
+	}
+
+	// Loop nodes keep their outputs on the actor, the others on its context
+	value, exists := source.GetOutput(pinID)
+	if !exists && source.ctx != nil {
+		value, exists = source.ctx.GetOutputValue(pinID)
+	}
+	if !exists {
+		return types.Value{}, false
+	}
+
+	if s.debugMgr != nil {
+		s.debugMgr.RecordDataFlow(s.executionID, upstreamEdge(sourceNodeID, pinID, nodeID, value))
+	}
+	return value, true
+}
@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxVariableChanges bounds the variable changes recorded per execution, e.g. for long loops
+const maxVariableChanges = 10000
+
+// VariableChange is a value an execution variable took, starting with the initial values
+type VariableChange struct {
+	Sequence int         `json:"sequence"` // Position in the history, from 0
+	Name     string      `json:"name"`
+	Value    interface{} `json:"value"`
+	Time     time.Time   `json:"time"`
+}
+
+// NodeBoundary places a node execution in the variable history of its execution: the
+// number of changes made when the node started and when it was done. A node is done when
+// it hands over to the first node it triggers, or else when it ends, so that the changes
+// of the nodes it runs in turn don't count as its own.
+type NodeBoundary struct {
+	SpanIndex int    `json:"spanIndex"` // Span of the node execution in the timeline
+	NodeID    string `json:"nodeId"`
+	Before    int    `json:"before"`
+	After     int    `json:"after"` // -1 while the node is running
+}
+
+// VariableHistory is the ordered record of the variable changes of an execution and of
+// the node boundaries between them, from which the variables at any boundary are rebuilt
+type VariableHistory struct {
+	ExecutionID string           `json:"executionId"`
+	Changes     []VariableChange `json:"changes"`
+	Boundaries  []NodeBoundary   `json:"boundaries"`
+	Truncated   bool             `json:"truncated"` // Changes beyond maxVariableChanges weren't recorded
+}
+
+// VariableState is the variables of an execution at a node execution: when the node
+// started and when it was done, with the changes in between. Changes made by nodes
+// running concurrently count too. Without a node, Before is empty and After holds the
+// variables at the end of the execution.
+type VariableState struct {
+	ExecutionID string                 `json:"executionId"`
+	NodeID      string                 `json:"nodeId,omitempty"`
+	Iteration   int                    `json:"iteration,omitempty"`  // Execution of the node, from 1
+	Iterations  int                    `json:"iterations,omitempty"` // Number of executions of the node
+	SpanIndex   int                    `json:"spanIndex"`
+	Before      map[string]interface{} `json:"before"`
+	After       map[string]interface{} `json:"after"`
+	Changes     []VariableChange       `json:"changes"`
+	Truncated   bool                   `json:"truncated"`
+}
+
+// variableHistory records the variable changes and node boundaries of an execution
+type variableHistory struct {
+	changes    []VariableChange
+	boundaries []NodeBoundary // Indexed like the spans of the timeline
+	truncated  bool
+}
+
+// variableHistoryLocked returns the variable history of an execution, creating it if
+// needed. Requires the write lock.
+func (dm *DebugManager) variableHistoryLocked(executionID string) *variableHistory {
+	history, exists := dm.variableHistories[executionID]
+	if !exists {
+		history = &variableHistory{
+			changes:    make([]VariableChange, 0),
+			boundaries: make([]NodeBoundary, 0),
+		}
+		dm.variableHistories[executionID] = history
+	}
+	return history
+}
+
+// recordVariableChangeLocked appends a variable change to the history of an execution.
+// Requires the write lock.
+func (dm *DebugManager) recordVariableChangeLocked(executionID, name string, value interface{}) {
+	history := dm.variableHistoryLocked(executionID)
+	if len(history.changes) >= maxVariableChanges {
+		history.truncated = true
+		return
+	}
+	history.changes = append(history.changes, VariableChange{
+		Sequence: len(history.changes),
+		Name:     name,
+		Value:    value,
+		Time:     time.Now(),
+	})
+}
+
+// beginBoundaryLocked records the start of the node execution of a span, and that the node
+// which triggered it, if any, is done. Requires the write lock.
+func (dm *DebugManager) beginBoundaryLocked(executionID string, span int, nodeID string, trigger *TimelineTrigger) {
+	history := dm.variableHistoryLocked(executionID)
+	if trigger != nil && trigger.SpanIndex >= 0 && trigger.SpanIndex < len(history.boundaries) {
+		if source := &history.boundaries[trigger.SpanIndex]; source.After < 0 {
+			source.After = len(history.changes)
+		}
+	}
+	if span != len(history.boundaries) {
+		return
+	}
+	history.boundaries = append(history.boundaries, NodeBoundary{
+		SpanIndex: span,
+		NodeID:    nodeID,
+		Before:    len(history.changes),
+		After:     -1,
+	})
+}
+
+// endBoundaryLocked records the end of the node execution of a span, if the node wasn't
+// done before. Requires the write lock.
+func (dm *DebugManager) endBoundaryLocked(executionID string, span int) {
+	history, exists := dm.variableHistories[executionID]
+	if !exists || span < 0 || span >= len(history.boundaries) {
+		return
+	}
+	if boundary := &history.boundaries[span]; boundary.After < 0 {
+		boundary.After = len(history.changes)
+	}
+}
+
+// GetVariableHistory returns the variable history of an execution
+func (dm *DebugManager) GetVariableHistory(executionID string) (*VariableHistory, bool) {
+	dm.mutex.RLock()
+	defer dm.mutex.RUnlock()
+
+	history, exists := dm.variableHistories[executionID]
+	if !exists {
+		return nil, false
+	}
+	result := &VariableHistory{
+		ExecutionID: executionID,
+		Changes:     make([]VariableChange, len(history.changes)),
+		Boundaries:  make([]NodeBoundary, len(history.boundaries)),
+		Truncated:   history.truncated,
+	}
+	copy(result.Changes, history.changes)
+	copy(result.Boundaries, history.boundaries)
+	return result, true
+}
+
+// GetVariableHistory returns the variable history of an execution still retained by the
+// debug manager
+func (e *ExecutionEngine) GetVariableHistory(executionID string) (*VariableHistory, bool) {
+	return e.debugManager.GetVariableHistory(executionID)
+}
+
+// StateAt rebuilds the variables at an execution of a node, counting its executions from
+// 1, or at the end of the execution if the node ID is empty
+func (h *VariableHistory) StateAt(nodeID string, iteration int) (*VariableState, error) {
+	state := &VariableState{
+		ExecutionID: h.ExecutionID,
+		SpanIndex:   -1,
+		Before:      make(map[string]interface{}),
+		Changes:     make([]VariableChange, 0),
+		Truncated:   h.Truncated,
+	}
+	if nodeID == "" {
+		state.After = h.apply(len(h.Changes))
+		return state, nil
+	}
+	if iteration < 1 {
+		return nil, fmt.Errorf("iteration must be at least 1, got %d", iteration)
+	}
+
+	var boundary *NodeBoundary
+	for i := range h.Boundaries {
+		if h.Boundaries[i].NodeID != nodeID {
+			continue
+		}
+		state.Iterations++
+		if state.Iterations == iteration {
+			boundary = &h.Boundaries[i]
+		}
+	}
+	if boundary == nil {
+		if state.Iterations == 0 {
+			return nil, fmt.Errorf("node %s didn't run in execution %s", nodeID, h.ExecutionID)
+		}
+		return nil, fmt.Errorf("node %s ran %d times in execution %s, not %d", nodeID, state.Iterations, h.ExecutionID, iteration)
+	}
+
+	after := boundary.After
+	if after < 0 || after > len(h.Changes) {
+		after = len(h.Changes)
+	}
+	before := min(boundary.Before, after)
+
+	state.NodeID = nodeID
+	state.Iteration = iteration
+	state.SpanIndex = boundary.SpanIndex
+	state.Before = h.apply(before)
+	state.After = h.apply(after)
+	state.Changes = append(state.Changes, h.Changes[before:after]...)
+	return state, nil
+}
+
+// apply returns the variables after the first count changes
+func (h *VariableHistory) apply(count int) map[string]interface{} {
+	variables := make(map[string]interface{})
+	for _, change := range h.Changes[:count] {
+		variables[change.Name] = change.Value
+	}
+	return variables
+}
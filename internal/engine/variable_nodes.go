@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// processVariableNodes runs the variable nodes of a blueprint, and the data nodes they read
+// from, before its entry points. The stages of the plan run one after another and the nodes
+// of a stage in parallel, each on a copy of the variables that the variables it sets are
+// merged back from. A failed stage stops the preprocessing with the error of its first
+// failed node.
+func (e *ExecutionEngine) processVariableNodes(bp *blueprint.Blueprint, executionID string, variables map[string]types.Value) error {
+	plan := e.planFor(bp)
+	var mutex sync.Mutex
+
+	for _, stage := range plan.VariableStages() {
+		errs := make([]error, len(stage))
+		var wg sync.WaitGroup
+		for i, nodeID := range stage {
+			wg.Add(1)
+			go func(i int, nodeID string) {
+				defer wg.Done()
+
+				mutex.Lock()
+				local := make(map[string]types.Value, len(variables))
+				for name, value := range variables {
+					local[name] = value
+				}
+				mutex.Unlock()
+
+				set := make(map[string]bool)
+				errs[i] = e.executeVariableNode(nodeID, bp, executionID, local, func(name string) {
+					set[name] = true
+				})
+
+				mutex.Lock()
+				for name := range set {
+					variables[name] = local[name]
+				}
+				mutex.Unlock()
+			}(i, nodeID)
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				return fmt.Errorf("error processing %s node %s: %w", variableNodeRole(plan.Node(stage[i])), stage[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// variableNodeRole describes a preprocessed node in errors
+func variableNodeRole(nodeConfig *blueprint.BlueprintNode) string {
+	switch {
+	case strings.HasPrefix(nodeConfig.Type, "set-variable-"):
+		return "setter"
+	case strings.HasPrefix(nodeConfig.Type, "get-variable-"):
+		return "getter"
+	default:
+		return "data"
+	}
+}
+
+// registerVariableNodes registers the getter and setter node types of the variables of a
+// blueprint version in its registry namespace, and unregisters those of variables the
+// version no longer has
+func (e *ExecutionEngine) registerVariableNodes(bp *blueprint.Blueprint) {
+	global := registry.GetInstance()
+	if global == nil {
+		return
+	}
+
+	namespace := registry.BlueprintVersionNamespace(bp.ID, bp.Version)
+	factories := data.VariableNodeFactories(bp)
+	for typeID, factory := range factories {
+		global.RegisterNamespacedNodeType(namespace, typeID, factory)
+	}
+	for typeID := range global.GetNamespaceFactories(namespace) {
+		if _, exists := factories[typeID]; !exists {
+			global.UnregisterNamespacedNodeType(namespace, typeID)
+		}
+	}
+}
+
+// blueprintNodeTypes returns the node types registered in the namespace of a blueprint
+// version, which take precedence over the engine's node types for its nodes
+func blueprintNodeTypes(key blueprintKey) map[string]node.NodeFactory {
+	global := registry.GetInstance()
+	if global == nil {
+		return nil
+	}
+	return global.GetNamespaceFactories(registry.BlueprintVersionNamespace(key.id, key.version))
+}
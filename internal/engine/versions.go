@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"sort"
+	"webblueprint/internal/registry"
+	"webblueprint/pkg/blueprint"
+)
+
+// blueprintKey identifies a loaded version of a blueprint. Versions of the same blueprint
+// are loaded side by side, e.g. while an older version finishes its executions or a new one
+// runs canary executions, each with its own variables and node types.
+type blueprintKey struct {
+	id      string
+	version string
+}
+
+// keyOf returns the key a blueprint is loaded under
+func keyOf(bp *blueprint.Blueprint) blueprintKey {
+	return blueprintKey{id: bp.ID, version: bp.Version}
+}
+
+// LoadedVersions returns the versions of a blueprint the engine holds, sorted
+func (e *ExecutionEngine) LoadedVersions(blueprintID string) []string {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	versions := make([]string, 0)
+	for key := range e.blueprints {
+		if key.id == blueprintID {
+			versions = append(versions, key.version)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// CurrentVersion returns the version of a blueprint its event handlers and executions
+// without a version of their own run
+func (e *ExecutionEngine) CurrentVersion(blueprintID string) (string, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	version, exists := e.currentVersions[blueprintID]
+	return version, exists
+}
+
+// PinVersion runs an execution on the version of the blueprint it's given without making
+// that version current, e.g. for canary runs of a version that hasn't been activated. The
+// version gets its own variables and no event bindings, and is unloaded once no execution
+// runs it. The pin is dropped when the execution ends.
+func (e *ExecutionEngine) PinVersion(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.pinned[executionID] = true
+}
+
+// versionPinned reports whether an execution runs a pinned version
+func (e *ExecutionEngine) versionPinned(executionID string) bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.pinned[executionID]
+}
+
+// unpinVersion drops the pin of an execution
+func (e *ExecutionEngine) unpinVersion(executionID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.pinned, executionID)
+}
+
+// resolveKey returns the version of a blueprint an execution runs, falling back to the
+// current version for executions the engine didn't start, e.g. those of event handlers.
+// The caller must hold the mutex.
+func (e *ExecutionEngine) resolveKey(blueprintID, executionID string) blueprintKey {
+	if key, exists := e.executionVersions[executionID]; exists && key.id == blueprintID {
+		return key
+	}
+	return blueprintKey{id: blueprintID, version: e.currentVersions[blueprintID]}
+}
+
+// resolveBlueprint returns the loaded version of a blueprint an execution runs
+func (e *ExecutionEngine) resolveBlueprint(blueprintID, executionID string) (*blueprint.Blueprint, blueprintKey, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	key := e.resolveKey(blueprintID, executionID)
+	bp, exists := e.blueprints[key]
+	return bp, key, exists
+}
+
+// setExecutionVersion records the version of its blueprint an execution runs
+func (e *ExecutionEngine) setExecutionVersion(executionID string, bp *blueprint.Blueprint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.executionVersions[executionID] = keyOf(bp)
+}
+
+// clearExecutionVersion drops the version an execution ran, unloading the version once it's
+// neither current nor run by another execution
+func (e *ExecutionEngine) clearExecutionVersion(executionID string) {
+	e.mutex.Lock()
+	key, exists := e.executionVersions[executionID]
+	delete(e.executionVersions, executionID)
+	retired := exists && e.retireLocked(key)
+	e.mutex.Unlock()
+
+	if retired {
+		e.unregisterVersionNodes(key)
+	}
+}
+
+// retireLocked unloads a version of a blueprint that is neither current nor run by an
+// execution and reports whether it did. The caller must hold the mutex and unregister the
+// node types of retired versions.
+func (e *ExecutionEngine) retireLocked(key blueprintKey) bool {
+	if _, loaded := e.blueprints[key]; !loaded {
+		return false
+	}
+	if version, exists := e.currentVersions[key.id]; exists && version == key.version {
+		return false
+	}
+	for _, running := range e.executionVersions {
+		if running == key {
+			return false
+		}
+	}
+
+	delete(e.blueprints, key)
+	e.variables.Delete(key)
+	delete(e.plans, key)
+	return true
+}
+
+// makeCurrentLocked switches the version of a blueprint event handlers and executions
+// without a version of their own run, retiring the previous current version unless
+// executions still run it. The caller must hold the mutex and unregister the node types of
+// the returned retired version, if any.
+func (e *ExecutionEngine) makeCurrentLocked(bp *blueprint.Blueprint) (blueprintKey, bool) {
+	previous, exists := e.currentVersions[bp.ID]
+	e.currentVersions[bp.ID] = bp.Version
+	if !exists || previous == bp.Version {
+		return blueprintKey{}, false
+	}
+
+	key := blueprintKey{id: bp.ID, version: previous}
+	return key, e.retireLocked(key)
+}
+
+// unregisterVersionNodes drops the node types registered for a version of a blueprint
+func (e *ExecutionEngine) unregisterVersionNodes(key blueprintKey) {
+	if global := registry.GetInstance(); global != nil {
+		global.UnregisterNamespace(registry.BlueprintVersionNamespace(key.id, key.version))
+	}
+}
@@ -0,0 +1,79 @@
+package engine_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// versionOf returns a copy of a blueprint with another version
+func versionOf(bp *blueprint.Blueprint, version string) *blueprint.Blueprint {
+	copied := bp.Clone()
+	copied.Version = version
+	return copied
+}
+
+func TestPinnedVersionRunsAlongsideTheCurrentOne(t *testing.T) {
+	flowEngine := newEngine(t, engine.ModeStandard)
+	unblock := make(chan struct{})
+	registry.GetInstance().RegisterNodeType("blocking-print", func() node.Node {
+		return blockingNode{utility.NewPrintNode(), unblock}
+	})
+
+	current := chainBlueprint(1)
+	if err := flowEngine.LoadBlueprint(current); err != nil {
+		t.Fatalf("failed to load blueprint: %v", err)
+	}
+	canary := versionOf(current, "2.0.0")
+	canary.Nodes[1].Type = "blocking-print"
+
+	flowEngine.PinVersion("canary")
+	done := make(chan error, 1)
+	go func() {
+		_, err := flowEngine.Execute(canary, "canary", map[string]types.Value{})
+		done <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(flowEngine.LoadedVersions(current.ID)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if versions := flowEngine.LoadedVersions(current.ID); fmt.Sprint(versions) != "[1.0.0 2.0.0]" {
+		t.Errorf("expected both versions to be loaded while the canary runs, got %v", versions)
+	}
+	if version, _ := flowEngine.CurrentVersion(current.ID); version != current.Version {
+		t.Errorf("expected the pinned version not to become current, got %s", version)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("expected the canary to succeed, got %v", err)
+	}
+	if versions := flowEngine.LoadedVersions(current.ID); fmt.Sprint(versions) != "[1.0.0]" {
+		t.Errorf("expected the pinned version to be unloaded once its execution ended, got %v", versions)
+	}
+}
+
+func TestExecutingANewVersionMakesItCurrent(t *testing.T) {
+	flowEngine := newEngine(t, engine.ModeStandard)
+	current := chainBlueprint(1)
+	if err := flowEngine.LoadBlueprint(current); err != nil {
+		t.Fatalf("failed to load blueprint: %v", err)
+	}
+
+	if _, err := flowEngine.Execute(versionOf(current, "2.0.0"), "upgrade", map[string]types.Value{}); err != nil {
+		t.Fatalf("expected the new version to run, got %v", err)
+	}
+	if version, _ := flowEngine.CurrentVersion(current.ID); version != "2.0.0" {
+		t.Errorf("expected the new version to become current, got %s", version)
+	}
+	if versions := flowEngine.LoadedVersions(current.ID); fmt.Sprint(versions) != "[2.0.0]" {
+		t.Errorf("expected the previous version to be unloaded, got %v", versions)
+	}
+}
@@ -24,6 +24,7 @@ type ContextBuilder struct {
 	hooks        *node.ExecutionHooks
 	activateFlow func(ctx *DefaultExecutionContext, nodeID, pinID string) error
 	repoFactory  repository.RepositoryFactory // Ensure field is present
+	ctx          context.Context              // Context of the execution, background if unset
 
 	// Feature flags
 	withErrorHandling bool
@@ -79,6 +80,12 @@ func NewContextBuilder(
 	}
 }
 
+// WithContext sets the context of the execution the nodes use for their outbound calls
+func (b *ContextBuilder) WithContext(ctx context.Context) *ContextBuilder {
+	b.ctx = ctx
+	return b
+}
+
 // WithErrorHandling adds error handling capabilities
 func (b *ContextBuilder) WithErrorHandling(errorManager *bperrors.ErrorManager, recoveryManager *bperrors.RecoveryManager) *ContextBuilder {
 	b.withErrorHandling = true
@@ -116,6 +123,10 @@ func (b *ContextBuilder) WithFunction(fnID string) *ContextBuilder {
 func (b *ContextBuilder) Build() node.ExecutionContext {
 	// Pass the concrete event manager to the factory
 	ctxFactory := NewContextFactory(b.errorManager, b.recoveryManager, b.concreteEventManager, b.repoFactory) // Pass repoFactory
+	parent := b.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
 	// Create the base execution context
 	baseCtx := NewExecutionContext(
 		b.nodeID,
@@ -127,7 +138,7 @@ func (b *ContextBuilder) Build() node.ExecutionContext {
 		b.logger,
 		b.hooks,
 		b.activateFlow,
-		context.WithValue(context.Background(), "bp", b.bp),
+		context.WithValue(parent, "bp", b.bp),
 		b.repoFactory, // Pass repoFactory
 	)
 
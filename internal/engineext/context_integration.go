@@ -22,6 +22,7 @@ type ExecutionEngineExtensions struct {
 	RecoveryManager      *bperrors.RecoveryManager
 	EventManager         core.EventManagerInterface // Core interface for general use
 	ConcreteEventManager *event.EventManager        // Concrete type for specific needs
+	TimerService         *TimerService              // Coalesces the triggers of rate limited nodes
 
 	// Logger from engine
 	logger node.Logger
@@ -158,6 +159,11 @@ func (ext *ExecutionEngineExtensions) GetConcreteEventManager() *event.EventMana
 	return ext.ConcreteEventManager
 }
 
+// GetTimerService returns the timer service of rate limited nodes
+func (ext *ExecutionEngineExtensions) GetTimerService() *TimerService {
+	return ext.TimerService
+}
+
 // EventAwareExecutionEngine backwards compatibility type
 type EventAwareExecutionEngine struct {
 	*ExecutionEngineExtensions
@@ -1,6 +1,7 @@
 package engineext
 
 import (
+	"context"
 	"webblueprint/internal/bperrors"
 	"webblueprint/internal/core"
 	"webblueprint/internal/node"
@@ -16,6 +17,10 @@ type ContextManager struct {
 	recoveryManager *bperrors.RecoveryManager
 	eventManager    core.EventManagerInterface
 	repoFactory     repository.RepositoryFactory // Added field
+
+	// executionContexts returns the context of an execution, which nodes use for their
+	// outbound calls
+	executionContexts func(executionID string) context.Context
 }
 
 // NewContextManager creates a new context manager
@@ -33,6 +38,21 @@ func NewContextManager(
 	}
 }
 
+// SetExecutionContexts sets the function returning the context of an execution, which the
+// contexts created for its nodes return from Context
+func (cm *ContextManager) SetExecutionContexts(executionContexts func(executionID string) context.Context) {
+	cm.executionContexts = executionContexts
+}
+
+// contextOf returns the context of an execution, or the background context if the contexts
+// of executions aren't known
+func (cm *ContextManager) contextOf(executionID string) context.Context {
+	if cm.executionContexts == nil {
+		return context.Background()
+	}
+	return cm.executionContexts(executionID)
+}
+
 // CreateContextBuilder creates a new context builder with default settings
 func (cm *ContextManager) CreateContextBuilder(
 	bp *blueprint.Blueprint,
@@ -58,7 +78,7 @@ func (cm *ContextManager) CreateContextBuilder(
 		hooks,
 		activateFlow,
 		cm.repoFactory, // Pass repoFactory
-	)
+	).WithContext(cm.contextOf(executionID))
 }
 
 // CreateStandardContext creates a context with the most common settings
@@ -88,6 +108,7 @@ func (cm *ContextManager) CreateStandardContext(
 		activateFlow,
 		cm.repoFactory, // Pass repoFactory
 	).
+		WithContext(cm.contextOf(executionID)).
 		WithErrorHandling(cm.errorManager, cm.recoveryManager).
 		WithEventSupport(cm.eventManager, false, nil).
 		Build()
@@ -120,6 +141,7 @@ func (cm *ContextManager) CreateActorContext(
 		activateFlow,
 		cm.repoFactory, // Pass repoFactory
 	).
+		WithContext(cm.contextOf(executionID)).
 		WithErrorHandling(cm.errorManager, cm.recoveryManager).
 		WithEventSupport(cm.eventManager, true, nil).
 		WithActorMode().
@@ -157,6 +179,7 @@ func (cm *ContextManager) CreateEventHandlerContext(
 		activateFlow,
 		cm.repoFactory, // Pass repoFactory
 	).
+		WithContext(cm.contextOf(executionID)).
 		WithErrorHandling(cm.errorManager, cm.recoveryManager).
 		WithEventSupport(cm.eventManager, true, eventHandlerContext).
 		Build()
@@ -188,6 +211,7 @@ func (cm *ContextManager) CreateFunctionContext(
 		activateFlow,
 		cm.repoFactory, // Pass repoFactory
 	).
+		WithContext(cm.contextOf(executionID)).
 		WithErrorHandling(cm.errorManager, cm.recoveryManager).
 		WithEventSupport(cm.eventManager, false, nil).
 		WithFunction(functionID).
@@ -19,6 +19,7 @@ import (
 // DefaultExecutionContext is a simplified implementation of node.ExecutionContext
 // It also implements node.ExtendedExecutionContext
 type DefaultExecutionContext struct {
+	ctx                context.Context // Context of the execution, without the saved data
 	storeCtx           context.Context
 	nodeID             string
 	nodeType           string
@@ -36,8 +37,17 @@ type DefaultExecutionContext struct {
 	activePins         map[string]bool // Tracks which input execution pin was activated
 	mutex              sync.RWMutex
 	repoFactory        repository.RepositoryFactory // Added field
+	upstream           UpstreamResolver             // Resolves GetUpstreamOutput, unset outside an engine
+	scopeRunner        ScopeRunner                  // Runs RunScope, unset outside an engine
 }
 
+// UpstreamResolver returns the output value of a node upstream of the executing node
+type UpstreamResolver func(nodeID, pinID string) (types.Value, bool)
+
+// ScopeRunner runs the branch connected to an output execution pin of the executing node in
+// a child scope of the execution
+type ScopeRunner func(pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error)
+
 // NewExecutionContext creates a new execution context
 func NewExecutionContext(
 	nodeID string,
@@ -55,7 +65,11 @@ func NewExecutionContext(
 	if logger != nil {
 		logger.Opts(map[string]interface{}{"nodeId": nodeID})
 	}
+	if storeContext == nil {
+		storeContext = context.Background()
+	}
 	return &DefaultExecutionContext{
+		ctx:            storeContext,
 		storeCtx:       storeContext,
 		nodeID:         nodeID,
 		nodeType:       nodeType,
@@ -98,6 +112,15 @@ func (ctx *DefaultExecutionContext) GetInputValue(pinID string) (types.Value, bo
 	value, exists := ctx.inputs[pinID]
 	ctx.mutex.RUnlock()
 
+	// An upstream pin that produced nothing resolves to the pin default or null
+	if exists && value.IsNull() {
+		inputPins, _ := ctx.storeCtx.Value("node.inputPins").([]types.Pin)
+		if pin, found := types.FindPin(inputPins, pinID); found {
+			return types.ResolveInputValue(pin, value, true)
+		}
+		return value, true
+	}
+
 	// If the value exists, return it
 	if exists {
 		//// Log the input access
@@ -272,6 +295,32 @@ func (ctx *DefaultExecutionContext) GetOutputValue(pinID string) (types.Value, b
 	return value, exists
 }
 
+// SetUpstreamResolver sets how the outputs of upstream nodes are resolved
+func (ctx *DefaultExecutionContext) SetUpstreamResolver(resolve UpstreamResolver) {
+	ctx.upstream = resolve
+}
+
+// GetUpstreamOutput returns the latest output value of a node upstream of this one
+func (ctx *DefaultExecutionContext) GetUpstreamOutput(nodeID, pinID string) (types.Value, bool) {
+	if ctx.upstream == nil {
+		return types.Value{}, false
+	}
+	return ctx.upstream(nodeID, pinID)
+}
+
+// SetScopeRunner sets how the branches run in child scopes are executed
+func (ctx *DefaultExecutionContext) SetScopeRunner(run ScopeRunner) {
+	ctx.scopeRunner = run
+}
+
+// RunScope runs the branch connected to an output execution pin in a child scope
+func (ctx *DefaultExecutionContext) RunScope(pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error) {
+	if ctx.scopeRunner == nil {
+		return types.Value{}, false, fmt.Errorf("node %s can't run pin %s in a child scope outside an engine", ctx.nodeID, pinID)
+	}
+	return ctx.scopeRunner(pinID, outputs, resultPin)
+}
+
 func (ctx *DefaultExecutionContext) SetInput(pingID string, value types.Value) {
 	ctx.mutex.Lock()
 	defer ctx.mutex.Unlock()
@@ -334,8 +383,11 @@ func (ctx *DefaultExecutionContext) GetVariable(name string) (types.Value, bool)
 
 // SetVariable sets a variable by name
 func (ctx *DefaultExecutionContext) SetVariable(name string, value types.Value) {
-	// TODO: Consider adding hooks for variable changes if needed
 	ctx.variables[name] = value
+
+	if ctx.hooks != nil && ctx.hooks.OnVariableChange != nil {
+		ctx.hooks.OnVariableChange(name, value.RawValue)
+	}
 }
 
 // Logger returns the execution logger
@@ -384,6 +436,11 @@ func (ctx *DefaultExecutionContext) GetExecutionID() string {
 	return ctx.executionID
 }
 
+// Context returns the context of the execution the context was created with
+func (ctx *DefaultExecutionContext) Context() context.Context {
+	return ctx.ctx
+}
+
 // GetAllOutputs returns all outputs from this execution context (implements ExtendedExecutionContext)
 func (ctx *DefaultExecutionContext) GetAllOutputs() map[string]types.Value {
 	// Return a copy to prevent external modification
@@ -0,0 +1,280 @@
+package engineext
+
+import (
+	"context"
+	"sync"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// TimerEventID identifies the triggers of rate limited nodes whose timer fired
+const TimerEventID = "node-timer"
+
+// TimerTriggersParameter is the event parameter with the number of triggers coalesced
+// into an activation
+const TimerTriggersParameter = "triggers"
+
+// TimerService coalesces the triggers of debounce and throttle nodes. Pending activations
+// are persisted in the timer repository, if any, so that long-lived event blueprints pick
+// them up again after a restart.
+type TimerService struct {
+	mutex     sync.Mutex
+	timers    map[string]*nodeTimer // "blueprintID/nodeID" -> rate limit state of the node
+	repo      repository.TimerRepository
+	fire      func(timer models.NodeTimer, storeErr error)
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) stopper
+}
+
+// stopper is a running timer
+type stopper interface {
+	Stop() bool
+}
+
+// nodeTimer is the rate limit state of a node
+type nodeTimer struct {
+	pending    *models.NodeTimer // Activation waiting for the timer, if any
+	timer      stopper
+	generation int           // Tells stale timers apart after the pending activation moved
+	windowEnd  time.Time     // End of the current throttle window
+	interval   time.Duration // Throttle interval
+}
+
+// NewTimerService creates a new timer service. Pending activations are only kept in
+// memory if the repository is nil.
+func NewTimerService(repo repository.TimerRepository) *TimerService {
+	return &TimerService{
+		timers: make(map[string]*nodeTimer),
+		repo:   repo,
+		now:    time.Now,
+		afterFunc: func(d time.Duration, f func()) stopper {
+			return time.AfterFunc(d, f)
+		},
+	}
+}
+
+// SetFireHandler sets the function running the pending activation of a node once its
+// timer fired, along with the error of dropping the persisted activation, if any
+func (s *TimerService) SetFireHandler(fire func(timer models.NodeTimer, storeErr error)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fire = fire
+}
+
+// Debounce postpones the activation of a node until no trigger arrived for the wait. The
+// activation runs with the inputs of the latest trigger.
+func (s *TimerService) Debounce(blueprintID, nodeID, executionID string, wait time.Duration, inputs models.JSONB) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := timerKey(blueprintID, nodeID)
+	state := s.state(key)
+
+	pending := &models.NodeTimer{
+		BlueprintID: blueprintID,
+		NodeID:      nodeID,
+		ExecutionID: executionID,
+		Mode:        node.RateLimitDebounce,
+		FireAt:      s.now().Add(wait),
+		Triggers:    coalescedTriggers(state.pending),
+		Inputs:      inputs,
+	}
+	s.schedule(key, state, pending)
+	return s.save(pending)
+}
+
+// Throttle reports whether a trigger of a node passes, which happens at most once per
+// interval. With trailing, the latest trigger absorbed during an interval runs when it ends.
+func (s *TimerService) Throttle(blueprintID, nodeID, executionID string, interval time.Duration, trailing bool, inputs models.JSONB) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := timerKey(blueprintID, nodeID)
+	state := s.state(key)
+	state.interval = interval
+
+	now := s.now()
+	if state.pending == nil && !now.Before(state.windowEnd) {
+		state.windowEnd = now.Add(interval)
+		return true, nil
+	}
+	if !trailing {
+		return false, nil
+	}
+
+	pending := &models.NodeTimer{
+		BlueprintID: blueprintID,
+		NodeID:      nodeID,
+		ExecutionID: executionID,
+		Mode:        node.RateLimitThrottle,
+		FireAt:      state.windowEnd,
+		Triggers:    coalescedTriggers(state.pending),
+		Inputs:      inputs,
+	}
+	s.schedule(key, state, pending)
+	return false, s.save(pending)
+}
+
+// Restore schedules the activations persisted before a restart. load prepares the
+// blueprint of an activation; activations whose blueprint can't be loaded are dropped.
+// Overdue activations fire right away.
+func (s *TimerService) Restore(ctx context.Context, load func(blueprintID string) error) (int, error) {
+	if s.repo == nil {
+		return 0, nil
+	}
+
+	timers, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, pending := range timers {
+		if err := load(pending.BlueprintID); err != nil {
+			if err := s.repo.Delete(ctx, pending.BlueprintID, pending.NodeID); err != nil {
+				return restored, err
+			}
+			continue
+		}
+
+		s.mutex.Lock()
+		key := timerKey(pending.BlueprintID, pending.NodeID)
+		if state := s.state(key); state.pending == nil {
+			s.schedule(key, state, pending)
+			restored++
+		}
+		s.mutex.Unlock()
+	}
+
+	return restored, nil
+}
+
+// Pending returns the activations waiting for their timer
+func (s *TimerService) Pending() []models.NodeTimer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pending := make([]models.NodeTimer, 0, len(s.timers))
+	for _, state := range s.timers {
+		if state.pending != nil {
+			pending = append(pending, *state.pending)
+		}
+	}
+	return pending
+}
+
+// Schedule schedules activations taken over from another process, e.g. from a snapshot of
+// its engine, and persists them. Nodes that already have a pending activation keep it.
+func (s *TimerService) Schedule(timers []models.NodeTimer) (int, error) {
+	scheduled := 0
+	for i := range timers {
+		pending := timers[i]
+
+		s.mutex.Lock()
+		key := timerKey(pending.BlueprintID, pending.NodeID)
+		state := s.state(key)
+		if state.pending != nil {
+			s.mutex.Unlock()
+			continue
+		}
+		s.schedule(key, state, &pending)
+		s.mutex.Unlock()
+
+		if err := s.save(&pending); err != nil {
+			return scheduled, err
+		}
+		scheduled++
+	}
+	return scheduled, nil
+}
+
+// Stop stops all timers. Persisted activations are kept for the next start.
+func (s *TimerService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, state := range s.timers {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+	}
+	s.timers = make(map[string]*nodeTimer)
+}
+
+// state returns the rate limit state of a node, creating it if needed
+func (s *TimerService) state(key string) *nodeTimer {
+	state, exists := s.timers[key]
+	if !exists {
+		state = &nodeTimer{}
+		s.timers[key] = state
+	}
+	return state
+}
+
+// schedule replaces the pending activation of a node and (re)starts its timer
+func (s *TimerService) schedule(key string, state *nodeTimer, pending *models.NodeTimer) {
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+
+	state.pending = pending
+	state.generation++
+	generation := state.generation
+	state.timer = s.afterFunc(pending.FireAt.Sub(s.now()), func() {
+		s.elapse(key, generation)
+	})
+}
+
+// elapse hands the pending activation of a node to the fire handler
+func (s *TimerService) elapse(key string, generation int) {
+	s.mutex.Lock()
+	state, exists := s.timers[key]
+	if !exists || state.generation != generation || state.pending == nil {
+		s.mutex.Unlock()
+		return
+	}
+
+	pending := *state.pending
+	state.pending = nil
+	state.timer = nil
+	if pending.Mode == node.RateLimitThrottle {
+		// The trailing activation opens the next window
+		state.windowEnd = s.now().Add(state.interval)
+	} else {
+		delete(s.timers, key)
+	}
+
+	var storeErr error
+	if s.repo != nil {
+		storeErr = s.repo.Delete(context.Background(), pending.BlueprintID, pending.NodeID)
+	}
+	fire := s.fire
+	s.mutex.Unlock()
+
+	if fire != nil {
+		fire(pending, storeErr)
+	}
+}
+
+// save persists a pending activation
+func (s *TimerService) save(pending *models.NodeTimer) error {
+	if s.repo == nil {
+		return nil
+	}
+	return s.repo.Save(context.Background(), pending)
+}
+
+// coalescedTriggers returns the number of triggers of an activation replacing the
+// pending one
+func coalescedTriggers(pending *models.NodeTimer) int {
+	if pending == nil {
+		return 1
+	}
+	return pending.Triggers + 1
+}
+
+func timerKey(blueprintID, nodeID string) string {
+	return blueprintID + "/" + nodeID
+}
@@ -0,0 +1,355 @@
+package engineext
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/pkg/models"
+)
+
+// fakeClock is a clock whose timers fire as the tests advance it
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer runs its function once the clock reaches the time it fires at
+type fakeTimer struct {
+	clock   *fakeClock
+	fireAt  time.Time
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	active := !t.stopped
+	t.stopped = true
+	return active
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) stopper {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := &fakeTimer{clock: c, fireAt: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance moves the clock forward, firing the timers due on the way in order
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(d)
+	c.mutex.Unlock()
+
+	for {
+		c.mutex.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].fireAt.Before(c.timers[j].fireAt) })
+		var due *fakeTimer
+		for i, timer := range c.timers {
+			if timer.stopped {
+				continue
+			}
+			if timer.fireAt.After(end) {
+				break
+			}
+			due = timer
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			break
+		}
+		if due == nil {
+			c.now = end
+			c.mutex.Unlock()
+			return
+		}
+		if due.fireAt.After(c.now) {
+			c.now = due.fireAt
+		}
+		due.stopped = true
+		c.mutex.Unlock()
+		due.f()
+	}
+}
+
+// memoryTimerRepo persists the pending activations in memory
+type memoryTimerRepo struct {
+	mutex  sync.Mutex
+	timers map[string]models.NodeTimer
+}
+
+func newMemoryTimerRepo(timers ...models.NodeTimer) *memoryTimerRepo {
+	repo := &memoryTimerRepo{timers: make(map[string]models.NodeTimer)}
+	for _, timer := range timers {
+		repo.timers[timerKey(timer.BlueprintID, timer.NodeID)] = timer
+	}
+	return repo
+}
+
+func (r *memoryTimerRepo) Save(ctx context.Context, timer *models.NodeTimer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.timers[timerKey(timer.BlueprintID, timer.NodeID)] = *timer
+	return nil
+}
+
+func (r *memoryTimerRepo) Delete(ctx context.Context, blueprintID, nodeID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.timers, timerKey(blueprintID, nodeID))
+	return nil
+}
+
+func (r *memoryTimerRepo) GetAll(ctx context.Context) ([]*models.NodeTimer, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	timers := make([]*models.NodeTimer, 0, len(r.timers))
+	for _, timer := range r.timers {
+		timer := timer
+		timers = append(timers, &timer)
+	}
+	return timers, nil
+}
+
+func (r *memoryTimerRepo) get(blueprintID, nodeID string) (models.NodeTimer, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	timer, exists := r.timers[timerKey(blueprintID, nodeID)]
+	return timer, exists
+}
+
+// newFakeTimerService creates a timer service persisting to repo on a fake clock, and
+// records the activations it fires
+func newFakeTimerService(repo *memoryTimerRepo) (*TimerService, *fakeClock, *[]models.NodeTimer) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s := NewTimerService(repo)
+	s.now = clock.Now
+	s.afterFunc = clock.AfterFunc
+
+	fired := make([]models.NodeTimer, 0)
+	s.SetFireHandler(func(timer models.NodeTimer, storeErr error) {
+		if storeErr != nil {
+			panic(storeErr)
+		}
+		fired = append(fired, timer)
+	})
+	return s, clock, &fired
+}
+
+// The rate limits of the nodes under test, as configured in their data
+var (
+	debounceLimit = node.RateLimit{Mode: node.RateLimitDebounce, Interval: 300 * time.Millisecond}
+	throttleLimit = node.RateLimit{Mode: node.RateLimitThrottle, Interval: time.Second}
+)
+
+func TestDebounceCoalescesTriggers(t *testing.T) {
+	repo := newMemoryTimerRepo()
+	s, clock, fired := newFakeTimerService(repo)
+	start := clock.Now()
+
+	// A burst of triggers, each within the wait of the previous one
+	for i, value := range []string{"a", "b", "c"} {
+		if i > 0 {
+			clock.Advance(100 * time.Millisecond)
+		}
+		if err := s.Debounce("bp", "debounce", "exec", debounceLimit.Interval, models.JSONB{"value": value}); err != nil {
+			t.Fatalf("failed to debounce: %v", err)
+		}
+	}
+
+	persisted, exists := repo.get("bp", "debounce")
+	if !exists {
+		t.Fatal("expected the pending activation to be persisted")
+	}
+	if persisted.Triggers != 3 || persisted.Inputs["value"] != "c" || !persisted.FireAt.Equal(start.Add(500*time.Millisecond)) {
+		t.Errorf("expected the latest of 3 triggers to fire 300ms after it, got %+v", persisted)
+	}
+
+	clock.Advance(299 * time.Millisecond)
+	if len(*fired) != 0 {
+		t.Fatalf("expected nothing to fire before the wait ended, got %+v", *fired)
+	}
+
+	clock.Advance(time.Millisecond)
+	if len(*fired) != 1 {
+		t.Fatalf("expected the burst to fire once, got %+v", *fired)
+	}
+	if activation := (*fired)[0]; activation.Triggers != 3 || activation.Inputs["value"] != "c" || activation.Mode != node.RateLimitDebounce {
+		t.Errorf("expected the latest of 3 triggers, got %+v", activation)
+	}
+	if _, exists := repo.get("bp", "debounce"); exists {
+		t.Error("expected the fired activation to be dropped from the repository")
+	}
+	if pending := s.Pending(); len(pending) != 0 {
+		t.Errorf("expected no pending activation, got %+v", pending)
+	}
+
+	// A trigger after the burst starts a new one
+	if err := s.Debounce("bp", "debounce", "exec", debounceLimit.Interval, models.JSONB{"value": "d"}); err != nil {
+		t.Fatalf("failed to debounce: %v", err)
+	}
+	clock.Advance(time.Second)
+	if len(*fired) != 2 || (*fired)[1].Triggers != 1 || (*fired)[1].Inputs["value"] != "d" {
+		t.Errorf("expected the next trigger to fire on its own, got %+v", *fired)
+	}
+}
+
+func TestDebounceNodesAreIndependent(t *testing.T) {
+	s, clock, fired := newFakeTimerService(newMemoryTimerRepo())
+
+	s.Debounce("bp", "first", "exec", debounceLimit.Interval, models.JSONB{})
+	clock.Advance(200 * time.Millisecond)
+	s.Debounce("bp", "second", "exec", debounceLimit.Interval, models.JSONB{})
+	clock.Advance(100 * time.Millisecond)
+
+	if len(*fired) != 1 || (*fired)[0].NodeID != "first" {
+		t.Fatalf("expected only the first node to fire, got %+v", *fired)
+	}
+	clock.Advance(200 * time.Millisecond)
+	if len(*fired) != 2 || (*fired)[1].NodeID != "second" || (*fired)[1].Triggers != 1 {
+		t.Errorf("expected the second node to fire on its own wait, got %+v", *fired)
+	}
+}
+
+func TestThrottleWindows(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		trailing bool
+		triggers []time.Duration // When the triggers arrive after the first one
+		passed   []bool          // Whether each trigger passes right away
+		fired    []int           // Coalesced triggers of each trailing activation
+	}{
+		{
+			name:     "without trailing",
+			triggers: []time.Duration{0, 200 * time.Millisecond, 999 * time.Millisecond, time.Second, 1500 * time.Millisecond, 2 * time.Second},
+			passed:   []bool{true, false, false, true, false, true},
+		},
+		{
+			name:     "with trailing",
+			trailing: true,
+			triggers: []time.Duration{0, 200 * time.Millisecond, 400 * time.Millisecond, 1500 * time.Millisecond, 3 * time.Second},
+			passed:   []bool{true, false, false, false, true},
+			fired:    []int{2, 1},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			repo := newMemoryTimerRepo()
+			s, clock, fired := newFakeTimerService(repo)
+			start := clock.Now()
+
+			for i, at := range test.triggers {
+				clock.Advance(start.Add(at).Sub(clock.Now()))
+				passed, err := s.Throttle("bp", "throttle", "exec", throttleLimit.Interval, test.trailing, models.JSONB{"value": i})
+				if err != nil {
+					t.Fatalf("trigger %d: failed to throttle: %v", i, err)
+				}
+				if passed != test.passed[i] {
+					t.Errorf("trigger %d at %v: expected passed %v, got %v", i, at, test.passed[i], passed)
+				}
+
+				// Absorbed triggers of a trailing throttle wait for the end of the window
+				persisted, exists := repo.get("bp", "throttle")
+				if !test.trailing || passed {
+					if exists {
+						t.Errorf("trigger %d: expected no pending activation, got %+v", i, persisted)
+					}
+					continue
+				}
+				if !exists || persisted.Inputs["value"] != i {
+					t.Errorf("trigger %d: expected it to be persisted as the pending activation, got %+v", i, persisted)
+				}
+			}
+			clock.Advance(10 * time.Second)
+
+			if len(*fired) != len(test.fired) {
+				t.Fatalf("expected %d trailing activations, got %+v", len(test.fired), *fired)
+			}
+			for i, triggers := range test.fired {
+				if (*fired)[i].Triggers != triggers || (*fired)[i].Mode != node.RateLimitThrottle {
+					t.Errorf("activation %d: expected %d coalesced triggers, got %+v", i, triggers, (*fired)[i])
+				}
+			}
+		})
+	}
+}
+
+func TestThrottleTrailingActivationOpensTheNextWindow(t *testing.T) {
+	s, clock, fired := newFakeTimerService(newMemoryTimerRepo())
+	start := clock.Now()
+
+	s.Throttle("bp", "throttle", "exec", throttleLimit.Interval, true, models.JSONB{})
+	clock.Advance(500 * time.Millisecond)
+	s.Throttle("bp", "throttle", "exec", throttleLimit.Interval, true, models.JSONB{})
+	clock.Advance(500 * time.Millisecond)
+	if len(*fired) != 1 || !(*fired)[0].FireAt.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected the trailing activation at the end of the window, got %+v", *fired)
+	}
+
+	// The window the trailing activation opened absorbs the next trigger
+	clock.Advance(999 * time.Millisecond)
+	if passed, _ := s.Throttle("bp", "throttle", "exec", throttleLimit.Interval, true, models.JSONB{}); passed {
+		t.Error("expected the trigger within the window of the trailing activation to be absorbed")
+	}
+	clock.Advance(time.Millisecond)
+	if len(*fired) != 2 || !(*fired)[1].FireAt.Equal(start.Add(2*time.Second)) {
+		t.Errorf("expected a trailing activation at the end of that window, got %+v", *fired)
+	}
+}
+
+func TestRestorePersistedTimers(t *testing.T) {
+	clockStart := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMemoryTimerRepo(
+		models.NodeTimer{BlueprintID: "bp", NodeID: "debounce", ExecutionID: "exec", Mode: node.RateLimitDebounce, FireAt: clockStart.Add(200 * time.Millisecond), Triggers: 4},
+		models.NodeTimer{BlueprintID: "bp", NodeID: "throttle", ExecutionID: "exec", Mode: node.RateLimitThrottle, FireAt: clockStart.Add(-time.Minute), Triggers: 2},
+		models.NodeTimer{BlueprintID: "deleted", NodeID: "debounce", ExecutionID: "exec", Mode: node.RateLimitDebounce, FireAt: clockStart.Add(time.Second), Triggers: 1},
+	)
+	s, clock, fired := newFakeTimerService(repo)
+
+	restored, err := s.Restore(context.Background(), func(blueprintID string) error {
+		if blueprintID == "deleted" {
+			return errors.New("blueprint not found")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to restore the timers: %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("expected 2 restored timers, got %d", restored)
+	}
+	if _, exists := repo.get("deleted", "debounce"); exists {
+		t.Error("expected the timer of a blueprint that can't be loaded to be dropped")
+	}
+
+	// Overdue activations fire right away, the others when they're due
+	clock.Advance(0)
+	if len(*fired) != 1 || (*fired)[0].NodeID != "throttle" || (*fired)[0].Triggers != 2 {
+		t.Fatalf("expected the overdue throttle to fire right away, got %+v", *fired)
+	}
+	clock.Advance(200 * time.Millisecond)
+	if len(*fired) != 2 || (*fired)[1].NodeID != "debounce" || (*fired)[1].Triggers != 4 {
+		t.Fatalf("expected the debounce to fire when it's due, got %+v", *fired)
+	}
+
+	// A trigger of the restored debounce node coalesces with nothing, as it fired
+	if err := s.Debounce("bp", "debounce", "exec", debounceLimit.Interval, models.JSONB{}); err != nil {
+		t.Fatalf("failed to debounce: %v", err)
+	}
+	if persisted, _ := repo.get("bp", "debounce"); persisted.Triggers != 1 {
+		t.Errorf("expected a new burst, got %+v", persisted)
+	}
+	if _, exists := repo.get("bp", "throttle"); exists {
+		t.Error("expected the fired activations to be dropped from the repository")
+	}
+}
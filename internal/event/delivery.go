@@ -0,0 +1,363 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of deliveries to at-least-once bindings
+const (
+	DeliveryStatusPending = "pending" // Waiting for the handler to acknowledge it
+	DeliveryStatusDead    = "dead"    // Dead-lettered after its last attempt failed
+)
+
+// Default retry policy of at-least-once deliveries
+const (
+	defaultDeliveryAttempts   = 5
+	defaultDeliveryBackoff    = time.Second
+	defaultDeliveryMaxBackoff = 5 * time.Minute
+)
+
+// deliveryStoreTimeout bounds every write of a delivery to the store
+const deliveryStoreTimeout = 10 * time.Second
+
+// ErrDeliveryNotFound is returned for deliveries that were acknowledged or never existed
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// DeliveryStore persists the deliveries of at-least-once bindings, so that they outlive a
+// restart. The event repository implements it.
+type DeliveryStore interface {
+	SaveDelivery(ctx context.Context, delivery *models.EventDelivery) error
+	DeleteDelivery(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context) ([]*models.EventDelivery, error)
+}
+
+// deliveryQueue keeps the deliveries of at-least-once bindings until their handler
+// acknowledges them by returning without an error. The mutex only guards the queue; the
+// store is written after releasing it, with a copy of the delivery.
+type deliveryQueue struct {
+	mutex       sync.Mutex
+	store       DeliveryStore                    // Nil to keep deliveries in memory only
+	deliveries  map[string]*models.EventDelivery // DeliveryID -> pending delivery or dead letter
+	timers      map[string]*time.Timer           // DeliveryID -> retry of a pending delivery
+	maxAttempts int
+	backoff     time.Duration // Delay before the first retry, doubled for every further retry
+	maxBackoff  time.Duration
+}
+
+func newDeliveryQueue() *deliveryQueue {
+	return &deliveryQueue{
+		deliveries:  make(map[string]*models.EventDelivery),
+		timers:      make(map[string]*time.Timer),
+		maxAttempts: defaultDeliveryAttempts,
+		backoff:     defaultDeliveryBackoff,
+		maxBackoff:  defaultDeliveryMaxBackoff,
+	}
+}
+
+// SetDeliveryStore sets the store deliveries of at-least-once bindings are persisted in
+func (em *EventManager) SetDeliveryStore(store DeliveryStore) {
+	em.deliveries.mutex.Lock()
+	defer em.deliveries.mutex.Unlock()
+	em.deliveries.store = store
+}
+
+// SetDeliveryRetries sets how often a delivery is attempted before it's dead-lettered and the
+// delay before its first retry, which doubles for every further retry
+func (em *EventManager) SetDeliveryRetries(maxAttempts int, backoff time.Duration) {
+	em.deliveries.mutex.Lock()
+	defer em.deliveries.mutex.Unlock()
+	if maxAttempts > 0 {
+		em.deliveries.maxAttempts = maxAttempts
+	}
+	if backoff > 0 {
+		em.deliveries.backoff = backoff
+	}
+}
+
+// RestoreDeliveries schedules the retries of the deliveries that were pending when the
+// server stopped and keeps the dead letters. load prepares the blueprint of a pending
+// delivery's handler; retries whose binding still isn't bound fail like any other attempt.
+// Overdue retries run right away.
+func (em *EventManager) RestoreDeliveries(ctx context.Context, load func(blueprintID string) error) (int, error) {
+	queue := em.deliveries
+	queue.mutex.Lock()
+	store := queue.store
+	queue.mutex.Unlock()
+	if store == nil {
+		return 0, nil
+	}
+
+	deliveries, err := store.GetDeliveries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := make(map[string]bool)
+	for _, delivery := range deliveries {
+		if delivery.Status == DeliveryStatusPending && !loaded[delivery.BlueprintID] {
+			loaded[delivery.BlueprintID] = true
+			_ = load(delivery.BlueprintID)
+		}
+	}
+
+	restored := 0
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	for _, delivery := range deliveries {
+		if _, exists := queue.deliveries[delivery.ID]; exists {
+			continue
+		}
+		queue.deliveries[delivery.ID] = delivery
+		if delivery.Status == DeliveryStatusPending {
+			em.scheduleLocked(delivery)
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+// GetDeliveries returns the pending deliveries and dead letters of a blueprint's bindings,
+// or of all bindings if the blueprint ID is empty, oldest first
+func (em *EventManager) GetDeliveries(blueprintID, status string) []models.EventDelivery {
+	queue := em.deliveries
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	deliveries := make([]models.EventDelivery, 0)
+	for _, delivery := range queue.deliveries {
+		if (blueprintID == "" || delivery.BlueprintID == blueprintID) && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	return deliveries
+}
+
+// Redeliver gives a dead letter a new round of attempts, the first of which runs right away.
+// The attempts run even if persisting the delivery fails.
+func (em *EventManager) Redeliver(deliveryID string) (models.EventDelivery, error) {
+	queue := em.deliveries
+	queue.mutex.Lock()
+	delivery, exists := queue.deliveries[deliveryID]
+	if !exists {
+		queue.mutex.Unlock()
+		return models.EventDelivery{}, fmt.Errorf("%w: %s", ErrDeliveryNotFound, deliveryID)
+	}
+	if delivery.Status != DeliveryStatusDead {
+		queue.mutex.Unlock()
+		return models.EventDelivery{}, fmt.Errorf("delivery %s is still pending", deliveryID)
+	}
+
+	delivery.Status = DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	redelivered := queue.snapshotLocked(delivery)
+	store := queue.store
+	queue.mutex.Unlock()
+
+	storeErr := saveDelivery(store, redelivered)
+	em.schedule(delivery)
+	if storeErr != nil {
+		return models.EventDelivery{}, storeErr
+	}
+	return redelivered, nil
+}
+
+// deliver persists an event for an at-least-once binding, runs its handler and, unless the
+// handler acknowledged it, schedules a retry. An error persisting the delivery is reported
+// along with the handler's, but the handler runs and the retries happen either way.
+func (em *EventManager) deliver(binding EventBinding, handlerFunc EventHandlerFunc, handlerCtx EventHandlerContext) error {
+	parameters := make(models.JSONB, len(handlerCtx.Parameters))
+	for name, value := range handlerCtx.Parameters {
+		parameters[name] = value.RawValue
+	}
+	now := time.Now()
+	delivery := &models.EventDelivery{
+		ID:            uuid.New().String(),
+		BindingID:     binding.ID,
+		EventID:       handlerCtx.EventID,
+		BlueprintID:   binding.BlueprintID,
+		SourceID:      handlerCtx.SourceID,
+		ExecutionID:   handlerCtx.ExecutionID,
+		Parameters:    parameters,
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	queue := em.deliveries
+	queue.mutex.Lock()
+	queue.deliveries[delivery.ID] = delivery
+	created := queue.snapshotLocked(delivery)
+	store := queue.store
+	queue.mutex.Unlock()
+	storeErr := saveDelivery(store, created)
+
+	err := em.settle(delivery, em.runHandler(binding, handlerFunc, handlerCtx))
+	if storeErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to persist delivery: %w", storeErr))
+	}
+	return err
+}
+
+// redeliver retries a pending delivery with the handler its binding has now
+func (em *EventManager) redeliver(deliveryID string) {
+	queue := em.deliveries
+	queue.mutex.Lock()
+	delete(queue.timers, deliveryID)
+	delivery, exists := queue.deliveries[deliveryID]
+	if !exists || delivery.Status != DeliveryStatusPending {
+		queue.mutex.Unlock()
+		return
+	}
+	pending := *delivery
+	queue.mutex.Unlock()
+
+	em.mutex.RLock()
+	definition := em.definitions[pending.EventID]
+	handlerFunc, hasHandler := em.handlerFuncs[pending.BindingID]
+	em.mutex.RUnlock()
+	binding, bound := em.GetBinding(pending.BindingID)
+
+	var err error
+	switch {
+	case !bound || !hasHandler:
+		err = fmt.Errorf("binding %s is no longer bound", pending.BindingID)
+	case !binding.Enabled:
+		err = fmt.Errorf("binding %s is disabled", pending.BindingID)
+	default:
+		err = em.runHandler(binding, handlerFunc, EventHandlerContext{
+			EventID:     pending.EventID,
+			Parameters:  deliveryParameters(definition, pending.Parameters),
+			SourceID:    pending.SourceID,
+			BlueprintID: pending.BlueprintID,
+			BindingID:   pending.BindingID,
+			ExecutionID: pending.ExecutionID,
+			HandlerID:   binding.HandlerID,
+			Timestamp:   pending.CreatedAt,
+		})
+	}
+	em.settle(delivery, err)
+}
+
+// settle acknowledges a delivery whose handler succeeded, or records the failed attempt and
+// schedules the next one, dead-lettering the delivery after its last attempt. It returns
+// the handler's error.
+func (em *EventManager) settle(delivery *models.EventDelivery, handlerErr error) error {
+	queue := em.deliveries
+	queue.mutex.Lock()
+	store := queue.store
+
+	if handlerErr == nil {
+		delete(queue.deliveries, delivery.ID)
+		queue.mutex.Unlock()
+		return deleteDelivery(store, delivery.ID)
+	}
+
+	delivery.Attempts++
+	delivery.LastError = handlerErr.Error()
+	if delivery.Attempts >= queue.maxAttempts {
+		delivery.Status = DeliveryStatusDead
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(queue.retryDelay(delivery.Attempts))
+	}
+	settled := queue.snapshotLocked(delivery)
+	queue.mutex.Unlock()
+
+	// The retry is scheduled once the attempt is persisted, so that a later attempt's write
+	// can't be overtaken by this one's
+	err := saveDelivery(store, settled)
+	em.schedule(delivery)
+	if err != nil {
+		return errors.Join(handlerErr, fmt.Errorf("failed to persist delivery: %w", err))
+	}
+	return handlerErr
+}
+
+// schedule schedules the next attempt of a delivery, unless it isn't pending anymore
+func (em *EventManager) schedule(delivery *models.EventDelivery) {
+	queue := em.deliveries
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	if queue.deliveries[delivery.ID] == delivery && delivery.Status == DeliveryStatusPending {
+		em.scheduleLocked(delivery)
+	}
+}
+
+// scheduleLocked schedules the next attempt of a pending delivery. The caller must hold the
+// mutex of the delivery queue.
+func (em *EventManager) scheduleLocked(delivery *models.EventDelivery) {
+	queue := em.deliveries
+	if timer, exists := queue.timers[delivery.ID]; exists {
+		timer.Stop()
+	}
+	deliveryID := delivery.ID
+	queue.timers[deliveryID] = time.AfterFunc(time.Until(delivery.NextAttemptAt), func() {
+		em.redeliver(deliveryID)
+	})
+}
+
+// snapshotLocked stamps a changed delivery and returns a copy of it to persist. The caller
+// must hold the mutex.
+func (q *deliveryQueue) snapshotLocked(delivery *models.EventDelivery) models.EventDelivery {
+	delivery.UpdatedAt = time.Now()
+	return *delivery
+}
+
+// saveDelivery persists a copy of a delivery, if there's a store
+func saveDelivery(store DeliveryStore, delivery models.EventDelivery) error {
+	if store == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryStoreTimeout)
+	defer cancel()
+	return store.SaveDelivery(ctx, &delivery)
+}
+
+// deleteDelivery removes an acknowledged delivery, if there's a store
+func deleteDelivery(store DeliveryStore, deliveryID string) error {
+	if store == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryStoreTimeout)
+	defer cancel()
+	return store.DeleteDelivery(ctx, deliveryID)
+}
+
+// retryDelay returns the backoff after the given number of failed attempts
+func (q *deliveryQueue) retryDelay(attempts int) time.Duration {
+	delay := q.backoff << (attempts - 1)
+	if delay <= 0 || delay > q.maxBackoff {
+		return q.maxBackoff
+	}
+	return delay
+}
+
+// deliveryParameters turns the raw parameter values of a delivery back into values, typed by
+// the event's definition
+func deliveryParameters(definition EventDefinition, raw models.JSONB) map[string]types.Value {
+	parameters := make(map[string]types.Value, len(raw))
+	for name, value := range raw {
+		pinType := types.PinTypes.Any
+		for _, parameter := range definition.Parameters {
+			if parameter.Name == name && parameter.Type != nil {
+				pinType = parameter.Type
+				break
+			}
+		}
+		parameters[name] = types.NewValue(pinType, value)
+	}
+	return parameters
+}
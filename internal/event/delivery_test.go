@@ -0,0 +1,290 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"webblueprint/internal/core"
+	"webblueprint/internal/event"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/models"
+)
+
+// memoryDeliveryStore keeps the persisted deliveries in memory
+type memoryDeliveryStore struct {
+	mutex      sync.Mutex
+	deliveries map[string]models.EventDelivery
+}
+
+func newMemoryDeliveryStore(deliveries ...models.EventDelivery) *memoryDeliveryStore {
+	store := &memoryDeliveryStore{deliveries: make(map[string]models.EventDelivery)}
+	for _, delivery := range deliveries {
+		store.deliveries[delivery.ID] = delivery
+	}
+	return store
+}
+
+func (s *memoryDeliveryStore) SaveDelivery(ctx context.Context, delivery *models.EventDelivery) error {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		return errors.New("expected a bounded context")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (s *memoryDeliveryStore) DeleteDelivery(ctx context.Context, id string) error {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		return errors.New("expected a bounded context")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.deliveries, id)
+	return nil
+}
+
+func (s *memoryDeliveryStore) GetDeliveries(ctx context.Context) ([]*models.EventDelivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	deliveries := make([]*models.EventDelivery, 0, len(s.deliveries))
+	for _, delivery := range s.deliveries {
+		delivery := delivery
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+func (s *memoryDeliveryStore) get(id string) (models.EventDelivery, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delivery, exists := s.deliveries[id]
+	return delivery, exists
+}
+
+func (s *memoryDeliveryStore) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.deliveries)
+}
+
+// failingController triggers handler nodes, failing the first triggers
+type failingController struct {
+	mutex    sync.Mutex
+	failures int // Triggers left to fail, negative to fail all of them
+	triggers int
+}
+
+func (c *failingController) TriggerNodeExecution(blueprintID string, nodeID string, triggerContext core.EventHandlerContext) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.triggers++
+	if c.failures == 0 {
+		return nil
+	}
+	c.failures--
+	return errors.New("handler failed")
+}
+
+func (c *failingController) setFailures(failures int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.failures = failures
+}
+
+func (c *failingController) count() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.triggers
+}
+
+const (
+	deliveryEventID   = "order.created"
+	deliveryBindingID = "binding-1"
+	deliveryBlueprint = "handler-bp"
+)
+
+// newDeliveryManager creates an event manager with an at-least-once binding of the handler
+// blueprint that attempts deliveries 3 times, 10ms apart at first
+func newDeliveryManager(t *testing.T, controller *failingController, store *memoryDeliveryStore) *event.EventManager {
+	t.Helper()
+	manager := event.NewEventManager(controller)
+	manager.SetDeliveryStore(store)
+	manager.SetDeliveryRetries(3, 10*time.Millisecond)
+
+	if err := manager.RegisterEvent(event.EventDefinition{
+		ID:         deliveryEventID,
+		Name:       "Order Created",
+		Parameters: []event.EventParameter{{Name: "orderID", Type: types.PinTypes.String}},
+	}); err != nil {
+		t.Fatalf("failed to register the event: %v", err)
+	}
+	if err := manager.BindEvent(event.EventBinding{
+		ID:          deliveryBindingID,
+		EventID:     deliveryEventID,
+		HandlerID:   "on-order",
+		BlueprintID: deliveryBlueprint,
+		Enabled:     true,
+		Delivery:    event.DeliveryAtLeastOnce,
+	}); err != nil {
+		t.Fatalf("failed to bind the event: %v", err)
+	}
+	return manager
+}
+
+func dispatchOrder(manager *event.EventManager) []error {
+	return manager.DispatchEvent(event.EventDispatchRequest{
+		EventID:    deliveryEventID,
+		Parameters: map[string]types.Value{"orderID": types.NewValue(types.PinTypes.String, "order-1")},
+		Timestamp:  time.Now(),
+	})
+}
+
+// eventually waits for a condition the retries of deliveries meet asynchronously
+func eventually(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDeliveryAcknowledged(t *testing.T) {
+	controller := &failingController{}
+	store := newMemoryDeliveryStore()
+	manager := newDeliveryManager(t, controller, store)
+
+	if errs := dispatchOrder(manager); len(errs) > 0 {
+		t.Fatalf("expected the handler to succeed, got %v", errs)
+	}
+	if deliveries := manager.GetDeliveries("", ""); len(deliveries) != 0 {
+		t.Errorf("expected the delivery to be acknowledged, got %+v", deliveries)
+	}
+	if store.count() != 0 {
+		t.Errorf("expected the acknowledged delivery to be removed from the store")
+	}
+	if controller.count() != 1 {
+		t.Errorf("expected 1 trigger, got %d", controller.count())
+	}
+}
+
+func TestDeliveryRetriedUntilAcknowledged(t *testing.T) {
+	controller := &failingController{failures: 2}
+	store := newMemoryDeliveryStore()
+	manager := newDeliveryManager(t, controller, store)
+
+	if errs := dispatchOrder(manager); len(errs) != 1 {
+		t.Fatalf("expected the first attempt to fail, got %v", errs)
+	}
+	pending := manager.GetDeliveries(deliveryBlueprint, event.DeliveryStatusPending)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("expected a pending delivery with 1 failed attempt, got %+v", pending)
+	}
+	if parameters := pending[0].Parameters; parameters["orderID"] != "order-1" {
+		t.Errorf("expected the parameters to be kept, got %v", parameters)
+	}
+
+	eventually(t, "the retries to be acknowledged", func() bool {
+		return len(manager.GetDeliveries("", "")) == 0 && store.count() == 0
+	})
+	if controller.count() != 3 {
+		t.Errorf("expected 3 triggers, got %d", controller.count())
+	}
+}
+
+func TestDeliveryDeadLetteredAndRedelivered(t *testing.T) {
+	controller := &failingController{failures: -1}
+	store := newMemoryDeliveryStore()
+	manager := newDeliveryManager(t, controller, store)
+
+	dispatchOrder(manager)
+	eventually(t, "the delivery to be dead-lettered", func() bool {
+		return len(manager.GetDeliveries(deliveryBlueprint, event.DeliveryStatusDead)) == 1
+	})
+
+	dead := manager.GetDeliveries(deliveryBlueprint, event.DeliveryStatusDead)[0]
+	if dead.Attempts != 3 || dead.LastError == "" {
+		t.Errorf("expected 3 failed attempts, got %+v", dead)
+	}
+	eventually(t, "the dead letter to be persisted", func() bool {
+		stored, exists := store.get(dead.ID)
+		return exists && stored.Status == event.DeliveryStatusDead
+	})
+	if controller.count() != 3 {
+		t.Errorf("expected no attempts after the last one, got %d triggers", controller.count())
+	}
+
+	// Only dead letters can be redelivered
+	if _, err := manager.Redeliver("unknown"); !errors.Is(err, event.ErrDeliveryNotFound) {
+		t.Errorf("expected ErrDeliveryNotFound, got %v", err)
+	}
+
+	controller.setFailures(0)
+	redelivered, err := manager.Redeliver(dead.ID)
+	if err != nil {
+		t.Fatalf("failed to redeliver: %v", err)
+	}
+	if redelivered.Status != event.DeliveryStatusPending || redelivered.Attempts != 0 {
+		t.Errorf("expected a new round of attempts, got %+v", redelivered)
+	}
+	eventually(t, "the redelivery to be acknowledged", func() bool {
+		return len(manager.GetDeliveries("", "")) == 0 && store.count() == 0
+	})
+	if _, err := manager.Redeliver(dead.ID); !errors.Is(err, event.ErrDeliveryNotFound) {
+		t.Errorf("expected the acknowledged delivery to be gone, got %v", err)
+	}
+}
+
+func TestRestoreDeliveries(t *testing.T) {
+	now := time.Now()
+	pending := models.EventDelivery{
+		ID:            "pending-1",
+		BindingID:     deliveryBindingID,
+		EventID:       deliveryEventID,
+		BlueprintID:   deliveryBlueprint,
+		Parameters:    models.JSONB{"orderID": "order-1"},
+		Status:        event.DeliveryStatusPending,
+		Attempts:      1,
+		NextAttemptAt: now.Add(-time.Minute), // Overdue while the server was down
+		CreatedAt:     now.Add(-time.Hour),
+	}
+	dead := pending
+	dead.ID = "dead-1"
+	dead.Status = event.DeliveryStatusDead
+	dead.Attempts = 3
+
+	controller := &failingController{}
+	store := newMemoryDeliveryStore(pending, dead)
+	manager := newDeliveryManager(t, controller, store)
+
+	var loaded []string
+	restored, err := manager.RestoreDeliveries(context.Background(), func(blueprintID string) error {
+		loaded = append(loaded, blueprintID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to restore the deliveries: %v", err)
+	}
+	if restored != 1 {
+		t.Errorf("expected 1 pending delivery to be restored, got %d", restored)
+	}
+	if len(loaded) != 1 || loaded[0] != deliveryBlueprint {
+		t.Errorf("expected the handler blueprint to be loaded once, got %v", loaded)
+	}
+
+	eventually(t, "the overdue retry to be acknowledged", func() bool {
+		_, exists := store.get(pending.ID)
+		return !exists
+	})
+	if deliveries := manager.GetDeliveries("", ""); len(deliveries) != 1 || deliveries[0].ID != dead.ID {
+		t.Errorf("expected only the dead letter to be kept, got %+v", deliveries)
+	}
+	if controller.count() != 1 {
+		t.Errorf("expected the dead letter not to be retried, got %d triggers", controller.count())
+	}
+}
@@ -1,13 +1,17 @@
 package event
 
 import (
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"sort"
 	"sync"
 	"time"
 
 	"webblueprint/internal/core" // Now includes EngineController
+	"webblueprint/internal/crash"
 	"webblueprint/internal/types"
+	"webblueprint/pkg/models"
 )
 
 // EventManager implements event management capabilities
@@ -17,6 +21,9 @@ type EventManager struct {
 	handlerFuncs     map[string]EventHandlerFunc     // BindingID -> Generated HandlerFunc that triggers engine
 	systemEvents     map[core.SystemEventType]string // SystemEventType -> EventID
 	blueprintEvents  map[string][]string             // BlueprintID -> []EventID
+	enabled          map[string]bool                 // BindingID -> enabled state set at runtime, overriding the declared one
+	stats            map[string]*BindingStats        // BindingID -> dispatches to the binding
+	deliveries       *deliveryQueue                  // Unacknowledged deliveries of at-least-once bindings
 	engineController core.EngineController           // Interface to trigger node execution
 	mutex            sync.RWMutex
 }
@@ -33,6 +40,9 @@ func NewEventManager(engineController core.EngineController) *EventManager {
 		handlerFuncs:     make(map[string]EventHandlerFunc),
 		systemEvents:     make(map[core.SystemEventType]string),
 		blueprintEvents:  make(map[string][]string),
+		enabled:          make(map[string]bool),
+		stats:            make(map[string]*BindingStats),
+		deliveries:       newDeliveryQueue(),
 		engineController: engineController, // Store engine controller reference
 	}
 	// Register built-in system events
@@ -78,9 +88,100 @@ func (em *EventManager) registerSystemEvents() {
 	// Map system event types to event IDs
 	em.systemEvents[EventTypeInitialize] = initEvent.ID
 	em.systemEvents[EventTypeShutdown] = shutdownEvent.ID
+
+	for eventType, definition := range lifecycleEvents() {
+		em.definitions[definition.ID] = definition
+		em.bindings[definition.ID] = make([]EventBinding, 0)
+		em.systemEvents[eventType] = definition.ID
+	}
 	// Add other system events here...
 }
 
+// lifecycleEvents returns the lifecycle events of executions, which the engine dispatches for
+// every blueprint so that other blueprints can react to them, e.g. alert on failures
+func lifecycleEvents() map[core.SystemEventType]EventDefinition {
+	execution := []EventParameter{
+		{Name: "blueprintID", Type: types.PinTypes.String, Description: "ID of the executed blueprint", Optional: false},
+		{Name: "executionID", Type: types.PinTypes.String, Description: "ID of the execution", Optional: false},
+	}
+	failure := []EventParameter{
+		{Name: "errorMessage", Type: types.PinTypes.String, Description: "Message of the error", Optional: false},
+		{Name: "errorCode", Type: types.PinTypes.String, Description: "Code of the error, if it has one", Optional: true},
+	}
+	withParameters := func(groups ...[]EventParameter) []EventParameter {
+		parameters := make([]EventParameter, 0)
+		for _, group := range groups {
+			parameters = append(parameters, group...)
+		}
+		return parameters
+	}
+
+	return map[core.SystemEventType]EventDefinition{
+		EventTypeExecutionStarted: {
+			ID:          "system.execution.started",
+			Name:        string(EventTypeExecutionStarted),
+			Description: "Triggered when an execution of any blueprint starts",
+			Parameters:  withParameters(execution),
+			Category:    "System",
+			CreatedAt:   time.Now(),
+		},
+		EventTypeExecutionCompleted: {
+			ID:          "system.execution.completed",
+			Name:        string(EventTypeExecutionCompleted),
+			Description: "Triggered when an execution of any blueprint completes successfully",
+			Parameters: withParameters(execution, []EventParameter{
+				{Name: "durationMs", Type: types.PinTypes.Number, Description: "How long the execution ran, in milliseconds", Optional: false},
+			}),
+			Category:  "System",
+			CreatedAt: time.Now(),
+		},
+		EventTypeExecutionFailed: {
+			ID:          "system.execution.failed",
+			Name:        string(EventTypeExecutionFailed),
+			Description: "Triggered when an execution of any blueprint fails",
+			Parameters: withParameters(execution, failure, []EventParameter{
+				{Name: "durationMs", Type: types.PinTypes.Number, Description: "How long the execution ran, in milliseconds", Optional: false},
+			}),
+			Category:  "System",
+			CreatedAt: time.Now(),
+		},
+		EventTypeNodeError: {
+			ID:          "system.node.error",
+			Name:        string(EventTypeNodeError),
+			Description: "Triggered when a node of any blueprint fails with an error its recovery strategies didn't handle",
+			Parameters: withParameters(execution, []EventParameter{
+				{Name: "nodeID", Type: types.PinTypes.String, Description: "ID of the failed node", Optional: false},
+			}, failure),
+			Category:  "System",
+			CreatedAt: time.Now(),
+		},
+	}
+}
+
+// Health reports why the event manager can't dispatch events, if it can't: it needs its
+// engine controller and the definitions of the system events
+func (em *EventManager) Health() error {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+
+	if em.engineController == nil {
+		return errors.New("event manager has no engine controller")
+	}
+	for eventType, eventID := range em.systemEvents {
+		if _, exists := em.definitions[eventID]; !exists {
+			return fmt.Errorf("system event %s isn't registered", eventType)
+		}
+	}
+	return nil
+}
+
+// HasBindings reports whether any binding is bound to an event, enabled or not
+func (em *EventManager) HasBindings(eventID string) bool {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+	return len(em.bindings[eventID]) > 0
+}
+
 // RegisterEvent registers a new event definition
 func (em *EventManager) RegisterEvent(event EventDefinition) error {
 	em.mutex.Lock()
@@ -89,6 +190,9 @@ func (em *EventManager) RegisterEvent(event EventDefinition) error {
 	if _, exists := em.definitions[event.ID]; exists {
 		return fmt.Errorf("event with ID %s already exists", event.ID)
 	}
+	if !ValidDispatchMode(event.DispatchMode) {
+		return fmt.Errorf("event %s has unknown dispatch mode %q", event.ID, event.DispatchMode)
+	}
 	em.definitions[event.ID] = event
 	if _, exists := em.bindings[event.ID]; !exists {
 		em.bindings[event.ID] = make([]EventBinding, 0)
@@ -166,8 +270,14 @@ func (em *EventManager) RegisterHandler(binding EventBinding) error {
 		// return fmt.Errorf("handler already registered for binding %s", binding.ID)
 	}
 
-	// --- Generate the handler function ---
-	handlerFunc := func(ctx EventHandlerContext) error {
+	// --- Store the generated function ---
+	em.handlerFuncs[binding.ID] = em.newHandlerFunc(binding)
+	return nil
+}
+
+// newHandlerFunc generates the handler function of a binding, which triggers its node
+func (em *EventManager) newHandlerFunc(binding EventBinding) EventHandlerFunc {
+	return func(ctx EventHandlerContext) error {
 		// Convert local event context to core event context
 		coreCtx := core.EventHandlerContext{
 			EventID:     ctx.EventID,
@@ -192,9 +302,6 @@ func (em *EventManager) RegisterHandler(binding EventBinding) error {
 		}
 		return nil
 	}
-	// --- Store the generated function ---
-	em.handlerFuncs[binding.ID] = handlerFunc
-	return nil
 }
 
 // BindEvent creates a binding and registers its handler function.
@@ -206,6 +313,10 @@ func (em *EventManager) BindEvent(binding EventBinding) error {
 		em.mutex.Unlock()
 		return fmt.Errorf("event with ID %s does not exist", binding.EventID)
 	}
+	if !ValidDelivery(binding.Delivery) {
+		em.mutex.Unlock()
+		return fmt.Errorf("binding %s has unknown delivery %q", binding.ID, binding.Delivery)
+	}
 
 	// Check if this exact binding already exists to prevent duplicates
 	if bindings, ok := em.bindings[binding.EventID]; ok {
@@ -219,13 +330,14 @@ func (em *EventManager) BindEvent(binding EventBinding) error {
 		}
 	}
 
+	// Bindings toggled at runtime keep their state when they're bound again
+	binding.Enabled = em.enabledLocked(binding)
+
 	// Add binding to the event's bindings list
 	em.bindings[binding.EventID] = append(em.bindings[binding.EventID], binding)
 
 	// Sort bindings by priority (higher priority first)
-	sort.Slice(em.bindings[binding.EventID], func(i, j int) bool {
-		return em.bindings[binding.EventID][i].Priority > em.bindings[binding.EventID][j].Priority
-	})
+	sortBindings(em.bindings[binding.EventID])
 
 	em.mutex.Unlock() // Unlock before calling RegisterHandler
 
@@ -281,10 +393,10 @@ func (em *EventManager) DispatchEvent(request EventDispatchRequest) []error {
 		return validationErrors // Don't dispatch if params are invalid
 	}
 
-	var dispatchErrors []error
-
-	// Execute handlers based on (copied and sorted) bindings
-	for _, binding := range eventBindings {
+	// Handlers are dispatched in the order of their (copied and sorted) bindings
+	results := make([]error, len(eventBindings))
+	var running sync.WaitGroup
+	for i, binding := range eventBindings {
 		if !binding.Enabled {
 			continue
 		}
@@ -292,7 +404,7 @@ func (em *EventManager) DispatchEvent(request EventDispatchRequest) []error {
 		handlerFunc, exists := handlersSnapshot[binding.ID]
 		if !exists {
 			// This indicates an inconsistency, likely RegisterHandler failed after BindEvent
-			dispatchErrors = append(dispatchErrors, fmt.Errorf("handler function for binding %s not found during dispatch", binding.ID))
+			results[i] = fmt.Errorf("handler function for binding %s not found during dispatch", binding.ID)
 			continue
 		}
 
@@ -314,18 +426,68 @@ func (em *EventManager) DispatchEvent(request EventDispatchRequest) []error {
 			Timestamp:   request.Timestamp,
 		}
 
-		// Execute the handler function (which triggers the engine) synchronously
-		err := handlerFunc(handlerCtx)
+		// At-least-once bindings get the event persisted and retried until acknowledged
+		run := em.runHandler
+		if binding.Delivery == DeliveryAtLeastOnce {
+			run = em.deliver
+		}
+
+		if event.DispatchMode == DispatchModeSerial {
+			results[i] = run(binding, handlerFunc, handlerCtx)
+			continue
+		}
+
+		running.Add(1)
+		go func(i int, binding EventBinding) {
+			defer running.Done()
+			results[i] = run(binding, handlerFunc, handlerCtx)
+		}(i, binding)
+	}
+	running.Wait()
+
+	// Errors of one handler don't stop the others, and are reported in dispatch order
+	var dispatchErrors []error
+	for i, err := range results {
 		if err != nil {
-			// Collect errors from triggering the node execution
-			dispatchErrors = append(dispatchErrors, fmt.Errorf("error executing handler for binding %s: %w", binding.ID, err))
-			// Decide if one handler error should stop others. For now, continue.
+			dispatchErrors = append(dispatchErrors, fmt.Errorf("error executing handler for binding %s: %w", eventBindings[i].ID, err))
 		}
 	}
 
 	return dispatchErrors
 }
 
+// runHandler runs the handler of a binding and records the dispatch. A panicking handler is
+// turned into an error, so it can't affect the other handlers of the event.
+func (em *EventManager) runHandler(binding EventBinding, handlerFunc EventHandlerFunc, handlerCtx EventHandlerContext) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panicked: %v", recovered)
+			crash.Default().Report(&models.CrashReport{
+				Source:      crash.SourceEventHandler,
+				ExecutionID: handlerCtx.ExecutionID,
+				BlueprintID: binding.BlueprintID,
+				NodeID:      binding.HandlerID,
+				NodeType:    binding.HandlerType,
+				Message:     err.Error(),
+				Stack:       string(debug.Stack()),
+			})
+		}
+		em.recordDispatch(binding.ID, err)
+	}()
+	return handlerFunc(handlerCtx)
+}
+
+// sortBindings orders the bindings of an event by priority, higher first, and by ID among
+// equal priorities, which is the order their handlers are dispatched in
+func sortBindings(bindings []EventBinding) {
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].Priority != bindings[j].Priority {
+			return bindings[i].Priority > bindings[j].Priority
+		}
+		return bindings[i].ID < bindings[j].ID
+	})
+}
+
 // validateParameters validates event parameters against the event definition
 func validateParameters(event EventDefinition, params map[string]types.Value) []error {
 	var errors []error
@@ -387,6 +549,98 @@ func (em *EventManager) GetBlueprintEvents(blueprintID string) []EventDefinition
 	return events
 }
 
+// GetBlueprintBindings returns the bindings of the handler nodes of a blueprint, to its own
+// custom events as well as to other events
+func (em *EventManager) GetBlueprintBindings(blueprintID string) []EventBinding {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+	var bindings []EventBinding
+	for _, eventBindings := range em.bindings {
+		for _, binding := range eventBindings {
+			if binding.BlueprintID == blueprintID {
+				bindings = append(bindings, binding)
+			}
+		}
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].ID < bindings[j].ID
+	})
+	return bindings
+}
+
+// GetBinding returns a bound binding by its ID
+func (em *EventManager) GetBinding(bindingID string) (EventBinding, bool) {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+	for _, bindings := range em.bindings {
+		for _, binding := range bindings {
+			if binding.ID == bindingID {
+				return binding, true
+			}
+		}
+	}
+	return EventBinding{}, false
+}
+
+// SetBindingEnabled enables or disables a binding at runtime, without redeploying its
+// blueprint. The state overrides the one the binding declares, also when the binding is
+// bound again, e.g. after its blueprint is reloaded. It returns the binding and whether it's
+// currently bound; the state of bindings that aren't bound applies once they are.
+func (em *EventManager) SetBindingEnabled(bindingID string, enabled bool) (EventBinding, bool) {
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	em.enabled[bindingID] = enabled
+	for _, bindings := range em.bindings {
+		for i := range bindings {
+			if bindings[i].ID == bindingID {
+				bindings[i].Enabled = enabled
+				return bindings[i], true
+			}
+		}
+	}
+	return EventBinding{}, false
+}
+
+// GetBindingStats returns the dispatches of events to a binding
+func (em *EventManager) GetBindingStats(bindingID string) BindingStats {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+	if stats, exists := em.stats[bindingID]; exists {
+		return *stats
+	}
+	return BindingStats{}
+}
+
+// enabledLocked returns whether a binding is enabled, preferring the state set at runtime.
+// The caller must hold the mutex.
+func (em *EventManager) enabledLocked(binding EventBinding) bool {
+	if enabled, exists := em.enabled[binding.ID]; exists {
+		return enabled
+	}
+	return binding.Enabled
+}
+
+// recordDispatch counts the dispatch of an event to a binding and its outcome
+func (em *EventManager) recordDispatch(bindingID string, err error) {
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	stats, exists := em.stats[bindingID]
+	if !exists {
+		stats = &BindingStats{}
+		em.stats[bindingID] = stats
+	}
+	now := time.Now()
+	stats.Dispatches++
+	stats.LastDispatchAt = &now
+	if err != nil {
+		stats.Failures++
+		stats.LastError = err.Error()
+		stats.LastErrorAt = &now
+	}
+}
+
 // RemoveBinding removes an event binding and its associated handler function
 func (em *EventManager) RemoveBinding(bindingID string) {
 	em.mutex.Lock()
@@ -434,6 +688,77 @@ func (em *EventManager) ClearBindings(blueprintID string) {
 	}
 }
 
+// ReplaceBlueprintEvents replaces the custom events and bindings of a blueprint in one step,
+// so that dispatched events see either the old or the new ones. Bindings to events that
+// don't exist, and events whose ID is taken by another blueprint, are skipped and reported.
+func (em *EventManager) ReplaceBlueprintEvents(blueprintID string, definitions []EventDefinition, bindings []EventBinding) error {
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	// Drop the custom events of the blueprint along with every binding to them
+	for _, eventID := range em.blueprintEvents[blueprintID] {
+		for _, binding := range em.bindings[eventID] {
+			delete(em.handlerFuncs, binding.ID)
+		}
+		delete(em.bindings, eventID)
+		delete(em.definitions, eventID)
+	}
+	delete(em.blueprintEvents, blueprintID)
+
+	// Drop the bindings of the blueprint to other events
+	for eventID, eventBindings := range em.bindings {
+		kept := make([]EventBinding, 0, len(eventBindings))
+		for _, binding := range eventBindings {
+			if binding.BlueprintID == blueprintID {
+				delete(em.handlerFuncs, binding.ID)
+				continue
+			}
+			kept = append(kept, binding)
+		}
+		em.bindings[eventID] = kept
+	}
+
+	var errs []error
+	for _, definition := range definitions {
+		if _, exists := em.definitions[definition.ID]; exists {
+			errs = append(errs, fmt.Errorf("event with ID %s already exists", definition.ID))
+			continue
+		}
+		if !ValidDispatchMode(definition.DispatchMode) {
+			errs = append(errs, fmt.Errorf("event %s has unknown dispatch mode %q", definition.ID, definition.DispatchMode))
+			continue
+		}
+		definition.BlueprintID = blueprintID
+		em.definitions[definition.ID] = definition
+		em.bindings[definition.ID] = make([]EventBinding, 0)
+		em.blueprintEvents[blueprintID] = append(em.blueprintEvents[blueprintID], definition.ID)
+	}
+
+	boundEvents := make(map[string]struct{})
+	for _, binding := range bindings {
+		if _, exists := em.definitions[binding.EventID]; !exists {
+			errs = append(errs, fmt.Errorf("event with ID %s does not exist", binding.EventID))
+			continue
+		}
+		if !ValidDelivery(binding.Delivery) {
+			errs = append(errs, fmt.Errorf("binding %s has unknown delivery %q", binding.ID, binding.Delivery))
+			continue
+		}
+		binding.BlueprintID = blueprintID
+		binding.Enabled = em.enabledLocked(binding)
+		em.bindings[binding.EventID] = append(em.bindings[binding.EventID], binding)
+		em.handlerFuncs[binding.ID] = em.newHandlerFunc(binding)
+		boundEvents[binding.EventID] = struct{}{}
+	}
+
+	// Keep the bindings sorted by priority (higher priority first)
+	for eventID := range boundEvents {
+		sortBindings(em.bindings[eventID])
+	}
+
+	return errors.Join(errs...)
+}
+
 // GetAllEvents returns all registered events
 func (em *EventManager) GetAllEvents() []EventDefinition {
 	em.mutex.RLock()
@@ -16,6 +16,12 @@ const (
 	EventTypeNodeCreated core.SystemEventType = "OnNodeCreated" // New node created
 	EventTypeNodeDeleted core.SystemEventType = "OnNodeDeleted" // Node deleted
 	EventTypeError       core.SystemEventType = "OnError"       // Error occurred
+
+	// Lifecycle events of the executions of every blueprint, dispatched by the engine
+	EventTypeExecutionStarted   core.SystemEventType = "OnExecutionStarted"   // Execution started
+	EventTypeExecutionCompleted core.SystemEventType = "OnExecutionCompleted" // Execution completed successfully
+	EventTypeExecutionFailed    core.SystemEventType = "OnExecutionFailed"    // Execution failed
+	EventTypeNodeError          core.SystemEventType = "OnNodeError"          // Node failed with an unhandled error
 )
 
 // EventHandler is the interface that must be implemented by all event handlers
@@ -33,6 +39,21 @@ type EventDefinition struct {
 	Category    string           `json:"category"`    // Category for organization (System, UI, Custom, etc.)
 	BlueprintID string           `json:"blueprintId"` // ID of the blueprint that defined this event (empty for system events)
 	CreatedAt   time.Time        `json:"createdAt"`   // When the event was defined
+	// How the handlers bound to the event run, DispatchModeParallel if empty
+	DispatchMode string `json:"dispatchMode,omitempty"`
+}
+
+// Dispatch modes of events. Either way, handlers are dispatched in the order of their
+// bindings: higher priority first, then by binding ID. A failing or panicking handler
+// doesn't keep the others from running, and dispatching returns once every handler returned.
+const (
+	DispatchModeParallel = "parallel" // Handlers are started in order and run concurrently
+	DispatchModeSerial   = "serial"   // Handlers run one by one, each once the previous one returned
+)
+
+// ValidDispatchMode reports whether a dispatch mode is known, the empty mode being parallel
+func ValidDispatchMode(mode string) bool {
+	return mode == "" || mode == DispatchModeParallel || mode == DispatchModeSerial
 }
 
 // EventParameter represents a parameter that can be passed with an event
@@ -54,6 +75,30 @@ type EventBinding struct {
 	Priority    int       // Priority (higher numbers execute first)
 	CreatedAt   time.Time // When the binding was created
 	Enabled     bool      // Whether the binding is active
+	Delivery    string    // Delivery guarantee of the handler, DeliveryAtMostOnce if empty
+}
+
+// Delivery guarantees of event bindings
+const (
+	// DeliveryAtMostOnce runs the handler once per event; failures are only reported
+	DeliveryAtMostOnce = "at-most-once"
+	// DeliveryAtLeastOnce persists the event before running the handler and retries it
+	// with backoff until the handler acknowledges it, dead-lettering it after the last attempt
+	DeliveryAtLeastOnce = "at-least-once"
+)
+
+// ValidDelivery reports whether a delivery guarantee is known, the empty one being at-most-once
+func ValidDelivery(delivery string) bool {
+	return delivery == "" || delivery == DeliveryAtMostOnce || delivery == DeliveryAtLeastOnce
+}
+
+// BindingStats counts the dispatches of an event to a binding since the server started
+type BindingStats struct {
+	Dispatches     int64      `json:"dispatches"`               // Events dispatched to the binding's handler
+	Failures       int64      `json:"failures"`                 // Dispatches whose handler failed
+	LastDispatchAt *time.Time `json:"lastDispatchAt,omitempty"` // When the latest event was dispatched
+	LastError      string     `json:"lastError,omitempty"`      // Error of the latest failed dispatch
+	LastErrorAt    *time.Time `json:"lastErrorAt,omitempty"`    // When the latest dispatch failed
 }
 
 // EventDispatchRequest represents a request to dispatch an event
@@ -0,0 +1,207 @@
+// Package export writes the node results of executions as tables for analytics pipelines,
+// one row per output pin value, as CSV or Parquet. The columns a value goes to follow the
+// type of its pin, so numbers and booleans keep their types in the table.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/types"
+)
+
+// Formats of the exported tables
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Kind is the type of the values of a column
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindNumber
+	KindBoolean
+)
+
+// Column is a column of the exported table
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Columns of the exported table. The value of a row is in the column of its pin type,
+// string_value, number_value or boolean_value, and objects, arrays and bytes are in
+// json_value as JSON; the other value columns are null.
+var Columns = []Column{
+	{Name: "execution_id", Kind: KindString},
+	{Name: "blueprint_id", Kind: KindString},
+	{Name: "node_id", Kind: KindString},
+	{Name: "node_type", Kind: KindString},
+	{Name: "node_status", Kind: KindString},
+	{Name: "pin_id", Kind: KindString},
+	{Name: "pin_type", Kind: KindString},
+	{Name: "string_value", Kind: KindString},
+	{Name: "number_value", Kind: KindNumber},
+	{Name: "boolean_value", Kind: KindBoolean},
+	{Name: "json_value", Kind: KindString},
+}
+
+// Row is the value of an output pin of a node of an execution
+type Row struct {
+	ExecutionID string
+	BlueprintID string
+	NodeID      string
+	NodeType    string
+	NodeStatus  string
+	PinID       string
+	PinType     *types.PinType // Inferred from the value if nil or any
+	Value       interface{}
+}
+
+// Writer writes the rows of a table. Close completes the table; nothing may be written after.
+type Writer interface {
+	Write(row Row) error
+	Close() error
+}
+
+// NewWriter creates a writer of a table in the format
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatParquet:
+		return newParquetWriter(w, defaultRowGroupSize), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q, expected csv or parquet", format)
+	}
+}
+
+// ContentType returns the media type of a format
+func ContentType(format string) string {
+	if format == FormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+// OutputPinTypes returns the types of the output pins of a node type, which is empty for
+// node types that aren't registered, e.g. the variable nodes of a blueprint
+func OutputPinTypes(nodeType string) map[string]*types.PinType {
+	pinTypes := make(map[string]*types.PinType)
+	global := registry.GetInstance()
+	if global == nil {
+		return pinTypes
+	}
+	factory, exists := global.GetNodeFactory(nodeType)
+	if !exists {
+		return pinTypes
+	}
+	for _, pin := range factory().GetOutputPins() {
+		pinTypes[pin.ID] = pin.Type
+	}
+	return pinTypes
+}
+
+// cells returns the values of the columns of a row: strings, float64s, bools or nil for null
+func (r Row) cells() []interface{} {
+	pinType := r.PinType
+	if pinType == nil || pinType == types.PinTypes.Any {
+		pinType = types.InferPinType(r.Value)
+	}
+
+	var stringValue, numberValue, booleanValue, jsonValue interface{}
+	if r.Value != nil {
+		switch pinType {
+		case types.PinTypes.String:
+			stringValue = fmt.Sprint(r.Value)
+		case types.PinTypes.Number:
+			if number, err := types.NewValue(types.PinTypes.Number, r.Value).AsNumber(); err == nil {
+				numberValue = number
+			} else {
+				jsonValue = encodeJSON(r.Value)
+			}
+		case types.PinTypes.Boolean:
+			if boolean, ok := r.Value.(bool); ok {
+				booleanValue = boolean
+			} else {
+				jsonValue = encodeJSON(r.Value)
+			}
+		case types.PinTypes.Stream:
+			// Streams are consumed by the nodes reading them and have no value to export
+		default:
+			jsonValue = encodeJSON(r.Value)
+		}
+	}
+
+	return []interface{}{
+		r.ExecutionID,
+		r.BlueprintID,
+		r.NodeID,
+		r.NodeType,
+		r.NodeStatus,
+		r.PinID,
+		pinType.ID,
+		stringValue,
+		numberValue,
+		booleanValue,
+		jsonValue,
+	}
+}
+
+// encodeJSON encodes a value as JSON, or as text if it can't be
+func encodeJSON(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// NodeResult is the outcome of a node of an execution
+type NodeResult struct {
+	NodeID   string
+	NodeType string
+	Status   string
+	Outputs  map[string]interface{} // Values of the output pins, by pin ID
+}
+
+// WriteExecution writes the output pin values of the nodes of an execution, ordered by node
+// and pin ID, with the types of the pins of their node types
+func WriteExecution(w Writer, executionID, blueprintID string, nodes []NodeResult) error {
+	sorted := append([]NodeResult(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].NodeID < sorted[j].NodeID })
+
+	pinTypes := make(map[string]map[string]*types.PinType)
+	for _, result := range sorted {
+		if pinTypes[result.NodeType] == nil {
+			pinTypes[result.NodeType] = OutputPinTypes(result.NodeType)
+		}
+
+		pinIDs := make([]string, 0, len(result.Outputs))
+		for pinID := range result.Outputs {
+			pinIDs = append(pinIDs, pinID)
+		}
+		sort.Strings(pinIDs)
+
+		for _, pinID := range pinIDs {
+			err := w.Write(Row{
+				ExecutionID: executionID,
+				BlueprintID: blueprintID,
+				NodeID:      result.NodeID,
+				NodeType:    result.NodeType,
+				NodeStatus:  result.Status,
+				PinID:       pinID,
+				PinType:     pinTypes[result.NodeType][pinID],
+				Value:       result.Outputs[pinID],
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"webblueprint/internal/types"
+)
+
+var testRows = []Row{
+	{ExecutionID: "e1", BlueprintID: "bp", NodeID: "fetch", NodeType: "http-request", NodeStatus: "completed", PinID: "status", PinType: types.PinTypes.Number, Value: 200},
+	{ExecutionID: "e1", BlueprintID: "bp", NodeID: "fetch", NodeType: "http-request", NodeStatus: "completed", PinID: "body", PinType: types.PinTypes.Object, Value: map[string]interface{}{"ok": true}},
+	{ExecutionID: "e1", BlueprintID: "bp", NodeID: "check", NodeType: "if", NodeStatus: "completed", PinID: "result", PinType: types.PinTypes.Boolean, Value: true},
+	{ExecutionID: "e1", BlueprintID: "bp", NodeID: "print", NodeType: "print", NodeStatus: "completed", PinID: "output", Value: "done"},
+	{ExecutionID: "e1", BlueprintID: "bp", NodeID: "print", NodeType: "print", NodeStatus: "error", PinID: "missing", Value: nil},
+}
+
+func TestCSVWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, FormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, row := range testRows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `execution_id,blueprint_id,node_id,node_type,node_status,pin_id,pin_type,string_value,number_value,boolean_value,json_value
+e1,bp,fetch,http-request,completed,status,number,,200,,
+e1,bp,fetch,http-request,completed,body,object,,,,"{""ok"":true}"
+e1,bp,check,if,completed,result,boolean,,,true,
+e1,bp,print,print,completed,output,string,done,,,
+e1,bp,print,print,error,missing,any,,,,
+`
+	if out.String() != expected {
+		t.Errorf("unexpected CSV:\n%s", out.String())
+	}
+}
+
+func TestCSVWriterEmptyTable(t *testing.T) {
+	var out bytes.Buffer
+	writer, _ := NewWriter(&out, FormatCSV)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "execution_id,") || strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("expected the header only, got %q", out.String())
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "xlsx"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	var out bytes.Buffer
+	// Row groups of 2 rows spread the rows over 3 groups
+	writer := newParquetWriter(&out, 2)
+	for _, row := range testRows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	file := out.Bytes()
+	if string(file[:4]) != parquetMagic || string(file[len(file)-4:]) != parquetMagic {
+		t.Fatal("expected the file to start and end with the Parquet magic")
+	}
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	metadata, _ := readStruct(file[len(file)-8-footerLength : len(file)-8])
+
+	if numRows := metadata[3].(int64); numRows != int64(len(testRows)) {
+		t.Errorf("expected %d rows, got %d", len(testRows), numRows)
+	}
+	schema := metadata[2].([]interface{})
+	if len(schema) != len(Columns)+1 {
+		t.Fatalf("expected a root and %d columns in the schema, got %d elements", len(Columns), len(schema))
+	}
+	for i, column := range Columns {
+		if name := string(schema[i+1].(map[int16]interface{})[4].([]byte)); name != column.Name {
+			t.Errorf("expected column %d to be %s, got %s", i, column.Name, name)
+		}
+	}
+
+	// Read the number and JSON columns back from the pages of the row groups
+	numbers := make([]interface{}, 0)
+	jsonValues := make([]interface{}, 0)
+	for _, group := range metadata[4].([]interface{}) {
+		chunks := group.(map[int16]interface{})[1].([]interface{})
+		for i, column := range Columns {
+			columnMeta := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+			offset := columnMeta[9].(int64)
+			values := readPage(t, file[offset:], column.Kind)
+			switch column.Name {
+			case "number_value":
+				numbers = append(numbers, values...)
+			case "json_value":
+				jsonValues = append(jsonValues, values...)
+			}
+		}
+	}
+
+	expectedNumbers := []interface{}{200.0, nil, nil, nil, nil}
+	expectedJSON := []interface{}{nil, `{"ok":true}`, nil, nil, nil}
+	for i := range testRows {
+		if numbers[i] != expectedNumbers[i] {
+			t.Errorf("row %d: expected number %v, got %v", i, expectedNumbers[i], numbers[i])
+		}
+		if jsonValues[i] != expectedJSON[i] {
+			t.Errorf("row %d: expected JSON %v, got %v", i, expectedJSON[i], jsonValues[i])
+		}
+	}
+}
+
+// readPage decodes the values of a data page, nil for nulls
+func readPage(t *testing.T, data []byte, kind Kind) []interface{} {
+	header, n := readStruct(data)
+	numValues := int(header[5].(map[int16]interface{})[1].(int64))
+	page := data[n : n+int(header[3].(int64))]
+
+	levelsLength := int(binary.LittleEndian.Uint32(page))
+	levels := make([]bool, 0, numValues)
+	for rest := page[4 : 4+levelsLength]; len(rest) > 0; {
+		run, size := binary.Uvarint(rest)
+		for i := 0; i < int(run>>1); i++ {
+			levels = append(levels, rest[size] == 1)
+		}
+		rest = rest[size+1:]
+	}
+	if len(levels) != numValues {
+		t.Fatalf("expected %d definition levels, got %d", numValues, len(levels))
+	}
+
+	body := page[4+levelsLength:]
+	values := make([]interface{}, numValues)
+	bit := 0
+	for i, defined := range levels {
+		if !defined {
+			continue
+		}
+		switch kind {
+		case KindString:
+			length := int(binary.LittleEndian.Uint32(body))
+			values[i] = string(body[4 : 4+length])
+			body = body[4+length:]
+		case KindNumber:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(body))
+			body = body[8:]
+		case KindBoolean:
+			values[i] = body[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		}
+	}
+	return values
+}
+
+// readStruct decodes a struct of the Thrift compact protocol into its fields by ID, and
+// returns the number of bytes it took. Integers are int64, binaries []byte, lists
+// []interface{} and structs map[int16]interface{}.
+func readStruct(data []byte) (map[int16]interface{}, int) {
+	fields := make(map[int16]interface{})
+	position := 0
+	var lastID int16
+	for {
+		header := data[position]
+		position++
+		if header == 0 {
+			return fields, position
+		}
+		fieldType := header & 0x0F
+		if delta := header >> 4; delta != 0 {
+			lastID += int16(delta)
+		} else {
+			id, n := binary.Varint(data[position:])
+			lastID = int16(id)
+			position += n
+		}
+		value, n := readValue(data[position:], fieldType)
+		fields[lastID] = value
+		position += n
+	}
+}
+
+func readValue(data []byte, valueType byte) (interface{}, int) {
+	switch valueType {
+	case thriftI32, thriftI64:
+		value, n := binary.Varint(data)
+		return value, n
+	case thriftBinary:
+		length, n := binary.Uvarint(data)
+		return data[n : n+int(length)], n + int(length)
+	case thriftList:
+		size := int(data[0] >> 4)
+		elementType := data[0] & 0x0F
+		position := 1
+		if size == 15 {
+			longSize, n := binary.Uvarint(data[1:])
+			size = int(longSize)
+			position += n
+		}
+		elements := make([]interface{}, size)
+		for i := range elements {
+			element, n := readValue(data[position:], elementType)
+			elements[i] = element
+			position += n
+		}
+		return elements, position
+	case thriftStruct:
+		return readStruct(data)
+	}
+	panic("unexpected Thrift type")
+}
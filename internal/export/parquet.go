@@ -0,0 +1,373 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// defaultRowGroupSize is how many rows the Parquet writer buffers before writing them out
+// as a row group
+const defaultRowGroupSize = 10000
+
+// Parquet format constants, as defined by parquet.thrift
+const (
+	parquetMagic = "PAR1"
+
+	parquetBoolean   = 0
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional = 1
+
+	parquetUTF8 = 0 // Converted type of strings
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+// parquetWriter writes a table as a Parquet file: every column is optional, its values are
+// PLAIN encoded in a single uncompressed data page per row group. Rows are buffered by
+// column and written out a row group at a time, the file metadata once the writer closes.
+type parquetWriter struct {
+	w            io.Writer
+	rowGroupSize int
+	offset       int64
+	rowGroups    []parquetRowGroup
+	numRows      int64
+
+	columns [][]interface{} // Buffered values of the row group, by column
+}
+
+// parquetRowGroup locates a row group written to the file
+type parquetRowGroup struct {
+	numRows int64
+	chunks  []parquetColumnChunk
+}
+
+// parquetColumnChunk locates the page of a column in a row group
+type parquetColumnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+func newParquetWriter(w io.Writer, rowGroupSize int) *parquetWriter {
+	return &parquetWriter{
+		w:            w,
+		rowGroupSize: rowGroupSize,
+		columns:      make([][]interface{}, len(Columns)),
+	}
+}
+
+// Write buffers a row, writing the row group out once it's full
+func (w *parquetWriter) Write(row Row) error {
+	for i, cell := range row.cells() {
+		w.columns[i] = append(w.columns[i], cell)
+	}
+	if len(w.columns[0]) >= w.rowGroupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close writes the buffered rows and the file metadata
+func (w *parquetWriter) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.offset == 0 {
+		if err := w.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+
+	footer := w.fileMetadata()
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(footer)))
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	if err := w.write(length); err != nil {
+		return err
+	}
+	return w.write([]byte(parquetMagic))
+}
+
+// flush writes the buffered rows as a row group
+func (w *parquetWriter) flush() error {
+	numRows := len(w.columns[0])
+	if numRows == 0 {
+		return nil
+	}
+	if w.offset == 0 {
+		if err := w.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+
+	group := parquetRowGroup{numRows: int64(numRows)}
+	for i, column := range Columns {
+		page := dataPage(column.Kind, w.columns[i])
+		chunk := parquetColumnChunk{offset: w.offset, size: int64(len(page)), numValues: int64(numRows)}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		w.columns[i] = w.columns[i][:0]
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += int64(numRows)
+	return nil
+}
+
+func (w *parquetWriter) write(data []byte) error {
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+// dataPage encodes the values of a column as a data page with its header: the definition
+// levels telling nulls apart, then the values that aren't null
+func dataPage(kind Kind, values []interface{}) []byte {
+	levels := make([]bool, len(values))
+	var body bytes.Buffer
+	var bits []bool
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		levels[i] = true
+		switch kind {
+		case KindString:
+			text := value.(string)
+			binary.Write(&body, binary.LittleEndian, uint32(len(text)))
+			body.WriteString(text)
+		case KindNumber:
+			binary.Write(&body, binary.LittleEndian, math.Float64bits(value.(float64)))
+		case KindBoolean:
+			bits = append(bits, value.(bool))
+		}
+	}
+	if kind == KindBoolean {
+		body.Write(packBits(bits))
+	}
+
+	encodedLevels := encodeLevels(levels)
+	var page bytes.Buffer
+	binary.Write(&page, binary.LittleEndian, uint32(len(encodedLevels)))
+	page.Write(encodedLevels)
+	page.Write(body.Bytes())
+
+	header := newThriftWriter()
+	header.i32(1, parquetDataPage)
+	header.i32(2, int32(page.Len()))
+	header.i32(3, int32(page.Len()))
+	header.beginStruct(5)
+	header.i32(1, int32(len(values)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.endStruct()
+	header.endStruct()
+
+	return append(header.bytes(), page.Bytes()...)
+}
+
+// encodeLevels encodes definition levels of bit width 1 as runs of the RLE hybrid encoding
+func encodeLevels(levels []bool) []byte {
+	var encoded []byte
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		encoded = binary.AppendUvarint(encoded, uint64(end-start)<<1)
+		if levels[start] {
+			encoded = append(encoded, 1)
+		} else {
+			encoded = append(encoded, 0)
+		}
+		start = end
+	}
+	return encoded
+}
+
+// packBits packs booleans one bit each, starting with the least significant bit
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// fileMetadata encodes the FileMetaData of the file: its schema and row groups
+func (w *parquetWriter) fileMetadata() []byte {
+	meta := newThriftWriter()
+	meta.i32(1, 1)
+
+	meta.listHeader(2, thriftStruct, len(Columns)+1)
+	meta.beginElement()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(Columns)))
+	meta.endStruct()
+	for _, column := range Columns {
+		meta.beginElement()
+		meta.i32(1, physicalType(column.Kind))
+		meta.i32(3, parquetOptional)
+		meta.binary(4, column.Name)
+		if column.Kind == KindString {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.endStruct()
+	}
+
+	meta.i64(3, w.numRows)
+
+	meta.listHeader(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		meta.beginElement()
+		var totalSize int64
+		meta.listHeader(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			totalSize += chunk.size
+			meta.beginElement()
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32(1, physicalType(Columns[i].Kind))
+			meta.listHeader(2, thriftI32, 2)
+			meta.elementI32(parquetPlain)
+			meta.elementI32(parquetRLE)
+			meta.listHeader(3, thriftBinary, 1)
+			meta.elementBinary(Columns[i].Name)
+			meta.i32(4, parquetUncompressed)
+			meta.i64(5, chunk.numValues)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64(2, totalSize)
+		meta.i64(3, group.numRows)
+		meta.endStruct()
+	}
+
+	meta.binary(6, "webblueprint")
+	meta.endStruct()
+	return meta.bytes()
+}
+
+// physicalType returns the Parquet type of the values of a column kind
+func physicalType(kind Kind) int32 {
+	switch kind {
+	case KindNumber:
+		return parquetDouble
+	case KindBoolean:
+		return parquetBoolean
+	default:
+		return parquetByteArray
+	}
+}
+
+// Types of the Thrift compact protocol
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes a struct with the Thrift compact protocol, which the Parquet page
+// headers and file metadata are serialized with
+type thriftWriter struct {
+	buf       bytes.Buffer
+	lastField []int16 // ID of the last field written, by nesting level
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastField: []int16{0}}
+}
+
+// bytes returns the encoded struct, which must have been ended with endStruct
+func (t *thriftWriter) bytes() []byte {
+	return t.buf.Bytes()
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	level := len(t.lastField) - 1
+	if delta := id - t.lastField[level]; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	t.lastField[level] = id
+}
+
+func (t *thriftWriter) varint(value int64) {
+	t.buf.Write(binary.AppendVarint(nil, value))
+}
+
+func (t *thriftWriter) i32(id int16, value int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(value))
+}
+
+func (t *thriftWriter) i64(id int16, value int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(value)
+}
+
+func (t *thriftWriter) binary(id int16, value string) {
+	t.fieldHeader(id, thriftBinary)
+	t.elementBinary(value)
+}
+
+// beginStruct starts a struct field, which endStruct ends
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.lastField = append(t.lastField, 0)
+}
+
+// endStruct ends the current struct, or the encoded struct itself at the top level
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	if len(t.lastField) > 1 {
+		t.lastField = t.lastField[:len(t.lastField)-1]
+	}
+}
+
+// listHeader starts a list field of size elements of a type
+func (t *thriftWriter) listHeader(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	t.buf.WriteByte(0xF0 | elementType)
+	t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+// beginElement starts a struct element of a list, which endStruct ends
+func (t *thriftWriter) beginElement() {
+	t.lastField = append(t.lastField, 0)
+}
+
+func (t *thriftWriter) elementI32(value int32) {
+	t.varint(int64(value))
+}
+
+func (t *thriftWriter) elementBinary(value string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(value))))
+	t.buf.WriteString(value)
+}
@@ -0,0 +1,806 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxLength = 1024 // Longest expression source accepted
+	MaxDepth  = 64   // Deepest nesting of sub-expressions accepted
+)
+
+// ValueIdentifier is the identifier an expression reads its input value from
+const ValueIdentifier = "value"
+
+// Expression is a parsed expression. Expressions can read their input value, access
+// members and indexes, do arithmetic, comparisons and boolean logic, and call a fixed
+// set of built-in functions. They can't loop, assign, or reach anything outside their
+// input, so they're safe to evaluate on user input.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses an expression
+func Parse(source string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > MaxLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", MaxLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression on an input value. Numbers are evaluated as float64.
+func (e *Expression) Eval(value any) (any, error) {
+	return e.root.eval(value)
+}
+
+// tokenKind identifies the kind of a token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators lists the operators, longest first so they're matched greedily
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "<", ">", "!", "?", ":", ".", ",", "(", ")", "[", "]",
+}
+
+func tokenize(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	pos := 0
+
+	for pos < len(source) {
+		c := source[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+
+		case c >= '0' && c <= '9':
+			start := pos
+			for pos < len(source) && (isDigit(source[pos]) || source[pos] == '.') {
+				pos++
+			}
+			n, err := strconv.ParseFloat(source[start:pos], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", source[start:pos], start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:pos], value: n, pos: start})
+
+		case c == '"' || c == '\'':
+			start := pos
+			var text strings.Builder
+			pos++
+			for {
+				if pos >= len(source) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if source[pos] == c {
+					pos++
+					break
+				}
+				if source[pos] == '\\' && pos+1 < len(source) {
+					pos++
+					switch source[pos] {
+					case 'n':
+						text.WriteByte('\n')
+					case 't':
+						text.WriteByte('\t')
+					default:
+						text.WriteByte(source[pos])
+					}
+					pos++
+					continue
+				}
+				text.WriteByte(source[pos])
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[start:pos], value: text.String(), pos: start})
+
+		case isIdentStart(c):
+			start := pos
+			for pos < len(source) && (isIdentStart(source[pos]) || isDigit(source[pos])) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:pos], pos: start})
+
+		default:
+			matched := ""
+			for _, op := range operators {
+				if strings.HasPrefix(source[pos:], op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: matched, pos: pos})
+			pos += len(matched)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parser is a recursive descent parser over the tokens of an expression
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it's one of the operators
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q but found %s at position %d", op, tok, tok.pos)
+	}
+	return nil
+}
+
+// parseExpression parses a conditional expression, the lowest precedence level
+func (p *parser) parseExpression() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return nil, fmt.Errorf("expression is nested more than %d levels deep", MaxDepth)
+	}
+
+	condition, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return condition, nil
+	}
+
+	then, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+// precedence lists the binary operators from the lowest to the highest precedence
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(precedence[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > MaxDepth {
+			return nil, fmt.Errorf("expression is nested more than %d levels deep", MaxDepth)
+		}
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("."); ok {
+			tok := p.next()
+			if tok.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a member name but found %s at position %d", tok, tok.pos)
+			}
+			target = &indexNode{target: target, index: &literalNode{value: tok.text}}
+			continue
+		}
+		if _, ok := p.accept("["); ok {
+			index, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			target = &indexNode{target: target, index: index}
+			continue
+		}
+		return target, nil
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: tok.value}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		case ValueIdentifier:
+			return &valueNode{}, nil
+		}
+
+		fn, exists := builtins[tok.text]
+		if !exists {
+			return nil, fmt.Errorf("unknown identifier %q at position %d", tok.text, tok.pos)
+		}
+		return p.parseCall(tok, fn)
+
+	case tokenOperator:
+		if tok.text == "(" {
+			inner, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+}
+
+func (p *parser) parseCall(name token, fn builtin) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args := make([]node, 0, fn.arity)
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); ok {
+				continue
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("%s expects %d arguments but got %d at position %d", name.text, fn.arity, len(args), name.pos)
+	}
+	return &callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+// node is a node of the syntax tree of an expression
+type node interface {
+	eval(value any) (any, error)
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(any) (any, error) {
+	return n.value, nil
+}
+
+type valueNode struct{}
+
+func (n *valueNode) eval(value any) (any, error) {
+	return value, nil
+}
+
+type conditionalNode struct {
+	condition node
+	then      node
+	otherwise node
+}
+
+func (n *conditionalNode) eval(value any) (any, error) {
+	condition, err := n.condition.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(condition) {
+		return n.then.eval(value)
+	}
+	return n.otherwise.eval(value)
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(value any) (any, error) {
+	operand, err := n.operand.eval(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !Truthy(operand), nil
+	}
+	number, ok := toNumber(operand)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", describe(operand))
+	}
+	return -number, nil
+}
+
+type binaryNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *binaryNode) eval(value any) (any, error) {
+	left, err := n.left.eval(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Boolean operators short-circuit
+	switch n.op {
+	case "&&":
+		if !Truthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(value)
+		return err == nil && Truthy(right), err
+	case "||":
+		if Truthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(value)
+		return err == nil && Truthy(right), err
+	}
+
+	right, err := n.right.eval(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+
+	// Adding a string to anything concatenates
+	if n.op == "+" {
+		leftString, leftIsString := left.(string)
+		rightString, rightIsString := right.(string)
+		if leftIsString || rightIsString {
+			if !leftIsString {
+				leftString = toString(left)
+			}
+			if !rightIsString {
+				rightString = toString(right)
+			}
+			return leftString + rightString, nil
+		}
+	}
+
+	// Strings compare lexically
+	if leftString, ok := left.(string); ok {
+		if rightString, ok := right.(string); ok {
+			switch n.op {
+			case "<":
+				return leftString < rightString, nil
+			case "<=":
+				return leftString <= rightString, nil
+			case ">":
+				return leftString > rightString, nil
+			case ">=":
+				return leftString >= rightString, nil
+			}
+		}
+	}
+
+	leftNumber, leftOk := toNumber(left)
+	rightNumber, rightOk := toNumber(right)
+	if !leftOk || !rightOk {
+		return nil, fmt.Errorf("cannot apply %q to %s and %s", n.op, describe(left), describe(right))
+	}
+
+	switch n.op {
+	case "+":
+		return leftNumber + rightNumber, nil
+	case "-":
+		return leftNumber - rightNumber, nil
+	case "*":
+		return leftNumber * rightNumber, nil
+	case "/":
+		if rightNumber == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return leftNumber / rightNumber, nil
+	case "%":
+		if rightNumber == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(leftNumber, rightNumber), nil
+	case "<":
+		return leftNumber < rightNumber, nil
+	case "<=":
+		return leftNumber <= rightNumber, nil
+	case ">":
+		return leftNumber > rightNumber, nil
+	case ">=":
+		return leftNumber >= rightNumber, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(value any) (any, error) {
+	target, err := n.target.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		return nil, fmt.Errorf("cannot read %s of null", describeIndex(index))
+	}
+
+	v := reflect.ValueOf(target)
+	switch v.Kind() {
+	case reflect.Map:
+		key, ok := index.(string)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot index an object with %s", describe(index))
+		}
+		entry := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		if !entry.IsValid() {
+			// Missing members read as null, so optional fields can be tested
+			return nil, nil
+		}
+		return entry.Interface(), nil
+
+	case reflect.Slice, reflect.Array, reflect.String:
+		if key, ok := index.(string); ok && key == "length" {
+			return float64(v.Len()), nil
+		}
+		number, ok := toNumber(index)
+		if !ok || number != math.Trunc(number) {
+			return nil, fmt.Errorf("cannot index a list with %s", describe(index))
+		}
+		i := int(number)
+		if i < 0 {
+			// Negative indexes count from the end
+			i += v.Len()
+		}
+		if i < 0 || i >= v.Len() {
+			return nil, fmt.Errorf("index %d is out of range for length %d", int(number), v.Len())
+		}
+		if v.Kind() == reflect.String {
+			return string(v.String()[i]), nil
+		}
+		return v.Index(i).Interface(), nil
+	}
+
+	return nil, fmt.Errorf("cannot read %s of %s", describeIndex(index), describe(target))
+}
+
+type callNode struct {
+	name string
+	fn   builtin
+	args []node
+}
+
+func (n *callNode) eval(value any) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		evaluated, err := arg.eval(value)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = evaluated
+	}
+
+	result, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return result, nil
+}
+
+// builtin is a function expressions can call
+type builtin struct {
+	arity int
+	call  func(args []any) (any, error)
+}
+
+// builtins are the functions expressions can call
+var builtins = map[string]builtin{
+	"len": {arity: 1, call: func(args []any) (any, error) {
+		v := reflect.ValueOf(args[0])
+		switch v.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array, reflect.String:
+			return float64(v.Len()), nil
+		}
+		return nil, fmt.Errorf("%s has no length", describe(args[0]))
+	}},
+	"string": {arity: 1, call: func(args []any) (any, error) {
+		return toString(args[0]), nil
+	}},
+	"number": {arity: 1, call: func(args []any) (any, error) {
+		if s, ok := args[0].(string); ok {
+			n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", s)
+			}
+			return n, nil
+		}
+		if n, ok := toNumber(args[0]); ok {
+			return n, nil
+		}
+		if b, ok := args[0].(bool); ok {
+			if b {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		}
+		return nil, fmt.Errorf("cannot convert %s to a number", describe(args[0]))
+	}},
+	"lower": {arity: 1, call: stringFunc(strings.ToLower)},
+	"upper": {arity: 1, call: stringFunc(strings.ToUpper)},
+	"trim":  {arity: 1, call: stringFunc(strings.TrimSpace)},
+	"abs":   {arity: 1, call: numberFunc(math.Abs)},
+	"round": {arity: 1, call: numberFunc(math.Round)},
+	"floor": {arity: 1, call: numberFunc(math.Floor)},
+	"ceil":  {arity: 1, call: numberFunc(math.Ceil)},
+}
+
+func stringFunc(fn func(string) string) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string but got %s", describe(args[0]))
+		}
+		return fn(s), nil
+	}
+}
+
+func numberFunc(fn func(float64) float64) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		n, ok := toNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("expected a number but got %s", describe(args[0]))
+		}
+		return fn(n), nil
+	}
+}
+
+// toNumber converts the numeric kinds to float64
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return s
+	}
+	if n, ok := toNumber(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// Truthy reports whether a value counts as true in a condition: null, false, zero and
+// the empty string are false, anything else is true
+func Truthy(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	}
+	if n, ok := toNumber(v); ok {
+		return n != 0
+	}
+	return true
+}
+
+func equal(left, right any) bool {
+	leftNumber, leftOk := toNumber(left)
+	rightNumber, rightOk := toNumber(right)
+	if leftOk && rightOk {
+		return leftNumber == rightNumber
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func describe(v any) string {
+	if v == nil {
+		return "null"
+	}
+	if _, ok := toNumber(v); ok {
+		return "a number"
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	}
+	return fmt.Sprintf("a %T", v)
+}
+
+func describeIndex(index any) string {
+	if key, ok := index.(string); ok {
+		return fmt.Sprintf("property %q", key)
+	}
+	return fmt.Sprintf("index %s", toString(index))
+}
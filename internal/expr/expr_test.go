@@ -0,0 +1,97 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	value := map[string]any{
+		"price": 12.5,
+		"count": 3,
+		"name":  "  Widget ",
+		"items": []any{
+			map[string]any{"name": "first"},
+			map[string]any{"name": "second"},
+		},
+	}
+
+	tests := []struct {
+		source   string
+		expected any
+	}{
+		{"value.price * 100", float64(1250)},
+		{"value.count + 1", float64(4)},
+		{"value.items[0].name", "first"},
+		{"value.items[-1].name", "second"},
+		{`value["items"][1]["name"]`, "second"},
+		{"value.items.length", float64(2)},
+		{"len(value.items)", float64(2)},
+		{"upper(trim(value.name))", "WIDGET"},
+		{`"#" + value.count`, "#3"},
+		{"value.count > 2 && value.price < 20", true},
+		{"!(value.count == 3)", false},
+		{"value.missing == null", true},
+		{"value.count % 2 == 1 ? 'odd' : 'even'", "odd"},
+		{"-value.price + 2 * (1 + 2)", float64(-6.5)},
+		{"round(value.price)", float64(13)},
+		{`number("4.5") / 2`, float64(2.25)},
+	}
+
+	for _, test := range tests {
+		expression, err := Parse(test.source)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", test.source, err)
+		}
+		result, err := expression.Eval(value)
+		if err != nil {
+			t.Fatalf("Eval(%q) failed: %v", test.source, err)
+		}
+		if result != test.expected {
+			t.Errorf("Eval(%q) = %#v, expected %#v", test.source, result, test.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                       "empty",
+		"value +":                "unexpected end of expression",
+		"os.Exit(1)":             `unknown identifier "os"`,
+		"value.items[0":          `expected "]"`,
+		"'unterminated":          "unterminated string",
+		"len(value, value)":      "expects 1 arguments",
+		"value ? 1":              `expected ":"`,
+		"value @ 2":              "unexpected character",
+		strings.Repeat("(", 100): "nested more than",
+	}
+
+	for source, expected := range tests {
+		_, err := Parse(source)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Parse(%q) = %v, expected an error containing %q", source, err, expected)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := map[string]string{
+		"value.items[5]":     "out of range",
+		"value.missing.name": `cannot read property "name" of null`,
+		"value.count / 0":    "division by zero",
+		"value.items * 2":    "cannot apply",
+		"upper(value.count)": "upper: expected a string",
+	}
+
+	value := map[string]any{"count": 1, "items": []any{1, 2}}
+	for source, expected := range tests {
+		expression, err := Parse(source)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", source, err)
+		}
+		_, err = expression.Eval(value)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Eval(%q) = %v, expected an error containing %q", source, err, expected)
+		}
+	}
+}
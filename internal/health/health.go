@@ -0,0 +1,171 @@
+// Package health reports whether the server is alive and ready to take traffic. Liveness
+// only depends on the process; readiness also depends on the phase of the server and on
+// the checks of its dependencies.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long a readiness check may take before it counts as failing
+const DefaultTimeout = 2 * time.Second
+
+// Phase is the stage of the server's lifecycle
+type Phase string
+
+const (
+	PhaseStarting Phase = "starting" // Dependencies are being set up
+	PhaseReady    Phase = "ready"    // The server takes traffic while its checks pass
+	PhaseDraining Phase = "draining" // The server is shutting down and finishes its requests
+)
+
+// Statuses of reports and check results
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Check reports whether a dependency works, returning why it doesn't
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check
+type Result struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// Report is the readiness of the server with the result of each check
+type Report struct {
+	Status    string            `json:"status"` // ok, failing, or the phase while not ready
+	Phase     Phase             `json:"phase"`
+	Checks    map[string]Result `json:"checks"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Ready reports whether the server should take traffic
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// namedCheck is a registered check
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker tracks the phase of the server and the checks of its dependencies
+type Checker struct {
+	phase   Phase
+	checks  []namedCheck
+	timeout time.Duration
+	mutex   sync.RWMutex
+}
+
+// NewChecker creates a checker in the starting phase. Checks taking longer than the timeout
+// fail; DefaultTimeout is used if it isn't positive.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{
+		phase:   PhaseStarting,
+		timeout: timeout,
+	}
+}
+
+// Register adds a readiness check, replacing the one with the same name
+func (c *Checker) Register(name string, check Check) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, existing := range c.checks {
+		if existing.name == name {
+			c.checks[i].check = check
+			return
+		}
+	}
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetPhase moves the server to another phase of its lifecycle
+func (c *Checker) SetPhase(phase Phase) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.phase = phase
+}
+
+// Phase returns the phase the server is in
+func (c *Checker) Phase() Phase {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.phase
+}
+
+// Ready runs the checks at once and reports whether the server is ready. A server that is
+// starting or draining isn't, whatever its checks report.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mutex.RLock()
+	phase := c.phase
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, check.check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusOK,
+		Phase:     phase,
+		Checks:    make(map[string]Result, len(checks)),
+		Timestamp: time.Now().UTC(),
+	}
+	for i, check := range checks {
+		report.Checks[check.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	if phase != PhaseReady {
+		report.Status = string(phase)
+	}
+	return report
+}
+
+// run runs a check, failing it if it doesn't return before the context is done
+func run(ctx context.Context, check Check) Result {
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
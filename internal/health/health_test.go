@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReadyFollowsPhase(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("database", func(ctx context.Context) error { return nil })
+
+	if report := checker.Ready(context.Background()); report.Ready() || report.Status != string(PhaseStarting) {
+		t.Errorf("expected a starting server not to be ready, got %+v", report)
+	}
+
+	checker.SetPhase(PhaseReady)
+	report := checker.Ready(context.Background())
+	if !report.Ready() || report.Checks["database"].Status != StatusOK {
+		t.Errorf("expected the server to be ready, got %+v", report)
+	}
+
+	checker.SetPhase(PhaseDraining)
+	if report := checker.Ready(context.Background()); report.Ready() || report.Status != string(PhaseDraining) {
+		t.Errorf("expected a draining server not to be ready, got %+v", report)
+	}
+}
+
+func TestReadyReportsFailingChecks(t *testing.T) {
+	checker := NewChecker(50 * time.Millisecond)
+	checker.SetPhase(PhaseReady)
+	checker.Register("database", func(ctx context.Context) error { return nil })
+	checker.Register("migrations", func(ctx context.Context) error { return errors.New("2 migrations aren't applied") })
+	checker.Register("events", func(ctx context.Context) error {
+		// Ignores its context, so only the timeout ends it
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	started := time.Now()
+	report := checker.Ready(context.Background())
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("expected slow checks to time out, took %s", elapsed)
+	}
+	if report.Ready() || report.Status != StatusFailing {
+		t.Errorf("expected the server to fail its checks, got %+v", report)
+	}
+	if result := report.Checks["migrations"]; result.Status != StatusFailing || result.Error != "2 migrations aren't applied" {
+		t.Errorf("expected the migrations check to fail with its error, got %+v", result)
+	}
+	if result := report.Checks["events"]; result.Status != StatusFailing || result.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected the events check to time out, got %+v", result)
+	}
+	if result := report.Checks["database"]; result.Status != StatusOK {
+		t.Errorf("expected the database check to pass, got %+v", result)
+	}
+
+	// Registering a check again replaces it
+	checker.Register("migrations", func(ctx context.Context) error { return nil })
+	if report := checker.Ready(context.Background()); len(report.Checks) != 3 || report.Checks["migrations"].Status != StatusOK {
+		t.Errorf("expected the migrations check to be replaced, got %+v", report.Checks)
+	}
+}
@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageRange is a locale of an Accept-Language header with its weight
+type languageRange struct {
+	locale string // Canonical locale, or "*"
+	weight float64
+}
+
+// parseAcceptLanguage returns the locales of an Accept-Language header, e.g.
+// "de-CH, de;q=0.9, en;q=0.5", by decreasing weight. Ranges with a weight of 0 and
+// malformed ones are left out.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(item, ";")
+		tag := strings.TrimSpace(parts[0])
+
+		weight := 1.0
+		for _, param := range parts[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				weight = 0
+				break
+			}
+			weight = parsed
+		}
+		if weight == 0 {
+			continue
+		}
+
+		locale := tag
+		if tag != "*" {
+			// Ranges may carry a script, e.g. zh-Hant-TW, which catalogs don't distinguish
+			subtags := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+			locale = Canonical(subtags[0])
+			if len(subtags) > 1 {
+				if withRegion := Canonical(subtags[0] + "-" + subtags[len(subtags)-1]); withRegion != "" {
+					locale = withRegion
+				}
+			}
+		}
+		if locale != "" {
+			ranges = append(ranges, languageRange{locale: locale, weight: weight})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].weight > ranges[j].weight
+	})
+	return ranges
+}
+
+// Match returns the supported locale that suits an Accept-Language header best, or the
+// default locale if none does. A region the bundle has no catalog for falls back to its
+// language, e.g. de-AT to de.
+func (b *Bundle) Match(acceptLanguage string) string {
+	for _, r := range parseAcceptLanguage(acceptLanguage) {
+		if r.locale == "*" {
+			return DefaultLocale
+		}
+		if language(r.locale) == DefaultLocale {
+			return DefaultLocale
+		}
+		for _, candidate := range fallbacks(r.locale) {
+			if b.hasCatalog(candidate) {
+				return candidate
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// hasCatalog reports whether the bundle has a catalog for a canonical locale itself
+func (b *Bundle) hasCatalog(locale string) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	_, exists := b.catalogs[locale]
+	return exists
+}
@@ -0,0 +1,201 @@
+// Package i18n localizes the texts the server shows to people: the names, descriptions and
+// pin labels of node types, and error messages. Texts are looked up in message catalogs by
+// locale and key. English is the source language, so a text missing from a catalog stays
+// as the node type or the error gives it.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the source language of the texts, served when no catalog matches
+const DefaultLocale = "en"
+
+// Catalog holds the messages of a locale by key, e.g. "node.print.name"
+type Catalog map[string]string
+
+// Bundle holds the catalogs of the supported locales
+type Bundle struct {
+	mutex    sync.RWMutex
+	catalogs map[string]Catalog
+}
+
+// NewBundle creates a bundle without catalogs, supporting the default locale only
+func NewBundle() *Bundle {
+	return &Bundle{catalogs: make(map[string]Catalog)}
+}
+
+//go:embed locales/*.json
+var builtinCatalogs embed.FS
+
+var (
+	defaultBundle     *Bundle
+	defaultBundleOnce sync.Once
+)
+
+// Default returns the bundle of the server, holding the catalogs shipped with it and the
+// ones added at startup, e.g. from the configured locales directory
+func Default() *Bundle {
+	defaultBundleOnce.Do(func() {
+		defaultBundle = NewBundle()
+		if err := defaultBundle.LoadFS(builtinCatalogs, "locales"); err != nil {
+			panic(fmt.Sprintf("i18n: invalid builtin catalog: %v", err))
+		}
+	})
+	return defaultBundle
+}
+
+// Add merges messages into the catalog of a locale, replacing those with the same keys. Node
+// types registered at runtime add the texts of their locales this way.
+func (b *Bundle) Add(locale string, catalog Catalog) {
+	locale = Canonical(locale)
+	if locale == "" {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	merged, exists := b.catalogs[locale]
+	if !exists {
+		merged = make(Catalog, len(catalog))
+		b.catalogs[locale] = merged
+	}
+	for key, message := range catalog {
+		merged[key] = message
+	}
+}
+
+// LoadFS adds the catalogs of a directory of a file system. Every <locale>.json file, e.g.
+// de.json or pt-BR.json, holds an object of messages by key.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		locale := strings.TrimSuffix(entry.Name(), ".json")
+		if Canonical(locale) == "" {
+			return fmt.Errorf("%s: %q isn't a locale", entry.Name(), locale)
+		}
+		b.Add(locale, catalog)
+	}
+	return nil
+}
+
+// LoadDir adds the catalogs of a directory, like LoadFS
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// Locales returns the supported locales, sorted, starting with the default locale
+func (b *Bundle) Locales() []string {
+	b.mutex.RLock()
+	locales := make([]string, 0, len(b.catalogs)+1)
+	for locale := range b.catalogs {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	b.mutex.RUnlock()
+
+	sort.Strings(locales)
+	return append([]string{DefaultLocale}, locales...)
+}
+
+// Message returns the message of a key in a locale, falling back to the catalog of its
+// language, e.g. from pt-BR to pt
+func (b *Bundle) Message(locale, key string) (string, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for _, candidate := range fallbacks(Canonical(locale)) {
+		if message, exists := b.catalogs[candidate][key]; exists {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Catalog returns the messages of a locale, including those of its language it doesn't
+// override
+func (b *Bundle) Catalog(locale string) Catalog {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	catalog := make(Catalog)
+	candidates := fallbacks(Canonical(locale))
+	for i := len(candidates) - 1; i >= 0; i-- {
+		for key, message := range b.catalogs[candidates[i]] {
+			catalog[key] = message
+		}
+	}
+	return catalog
+}
+
+// Canonical returns the canonical form of a locale, with a lower case language and an upper
+// case region, e.g. pt-BR for pt_br. It returns an empty string if it isn't a locale.
+func Canonical(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if len(parts) > 2 || !isAlpha(parts[0], 2, 3) {
+		return ""
+	}
+	canonical := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		if !isAlpha(parts[1], 2, 2) {
+			return ""
+		}
+		canonical += "-" + strings.ToUpper(parts[1])
+	}
+	return canonical
+}
+
+// isAlpha checks that a part of a locale consists of min to max letters
+func isAlpha(part string, min, max int) bool {
+	if len(part) < min || len(part) > max {
+		return false
+	}
+	for _, r := range part {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// language returns the language of a canonical locale
+func language(locale string) string {
+	if i := strings.IndexByte(locale, '-'); i >= 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// fallbacks returns the locales whose catalogs are searched for a canonical locale, most
+// specific first
+func fallbacks(locale string) []string {
+	if locale == "" {
+		return nil
+	}
+	if lang := language(locale); lang != locale {
+		return []string{locale, lang}
+	}
+	return []string{locale}
+}
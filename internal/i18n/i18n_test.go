@@ -0,0 +1,125 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestCanonical(t *testing.T) {
+	for locale, expected := range map[string]string{
+		"de":      "de",
+		"pt_br":   "pt-BR",
+		"EN-us":   "en-US",
+		" fr ":    "fr",
+		"":        "",
+		"d":       "",
+		"de-DE-1": "",
+		"de-123":  "",
+		"../de":   "",
+	} {
+		if got := Canonical(locale); got != expected {
+			t.Errorf("Canonical(%q) = %q, expected %q", locale, got, expected)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	bundle := NewBundle()
+	bundle.Add("de", Catalog{"category.Math": "Mathematik"})
+	bundle.Add("pt-BR", Catalog{"category.Math": "Matemática"})
+
+	for header, expected := range map[string]string{
+		"":                           "en",
+		"de":                         "de",
+		"de-AT":                      "de",
+		"fr, de;q=0.8":               "de",
+		"en;q=0.9, de":               "de",
+		"de;q=0, pt-br;q=0.5":        "pt-BR",
+		"pt":                         "en",
+		"zh-Hant-TW, *;q=0.1":        "en",
+		"fr-CH, fr;q=0.9, en;q=0.8":  "en",
+		"de;q=bogus, pt-BR;q=0.3":    "pt-BR",
+		"en-GB, de;q=0.9":            "en",
+		"ja, it-IT;q=0.5, pt-BR;q=1": "pt-BR",
+	} {
+		if got := bundle.Match(header); got != expected {
+			t.Errorf("Match(%q) = %q, expected %q", header, got, expected)
+		}
+	}
+}
+
+func TestMessageFallsBackToLanguage(t *testing.T) {
+	bundle := NewBundle()
+	bundle.Add("de", Catalog{"node.print.name": "Ausgeben", "category.Utility": "Werkzeuge"})
+	bundle.Add("de-CH", Catalog{"category.Utility": "Hilfsmittel"})
+
+	localizer := bundle.Localizer("de-CH")
+	if got := localizer.Text("category.Utility", "Utility"); got != "Hilfsmittel" {
+		t.Errorf("expected the regional message, got %q", got)
+	}
+	if got := localizer.Text("node.print.name", "Print"); got != "Ausgeben" {
+		t.Errorf("expected the message of the language, got %q", got)
+	}
+	if got := localizer.Text("node.loop.name", "Loop"); got != "Loop" {
+		t.Errorf("expected the fallback for a missing message, got %q", got)
+	}
+
+	var english *Localizer
+	if got := english.Text("node.print.name", "Print"); got != "Print" {
+		t.Errorf("expected a nil localizer to keep the English text, got %q", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	bundle := NewBundle()
+	bundle.Add("de", Catalog{"error.Q003": "Der Blueprint hat {nodeCount} Knoten und überschreitet das Limit von {limit}"})
+	localizer := bundle.Localizer("de")
+
+	fallback := "blueprint has too many nodes"
+	got := localizer.Format("error.Q003", fallback, map[string]interface{}{"nodeCount": 120, "limit": 100})
+	if expected := "Der Blueprint hat 120 Knoten und überschreitet das Limit von 100"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// A message would lose the information of a placeholder the details don't fill
+	if got := localizer.Format("error.Q003", fallback, map[string]interface{}{"limit": 100}); got != fallback {
+		t.Errorf("expected the fallback for an unfilled placeholder, got %q", got)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	bundle := NewBundle()
+	bundle.Add("de", Catalog{"category.Math": "Mathematik", "category.Web": "Web"})
+
+	err := bundle.LoadFS(fstest.MapFS{
+		"locales/de.json":    {Data: []byte(`{"category.Web": "Netz"}`)},
+		"locales/pt_br.json": {Data: []byte(`{"category.Math": "Matemática"}`)},
+		"locales/README.md":  {Data: []byte("not a catalog")},
+	}, "locales")
+	if err != nil {
+		t.Fatalf("failed to load catalogs: %v", err)
+	}
+
+	catalog := bundle.Catalog("de")
+	if catalog["category.Math"] != "Mathematik" || catalog["category.Web"] != "Netz" {
+		t.Errorf("expected the loaded catalog to extend the existing one, got %v", catalog)
+	}
+	if locales := bundle.Locales(); len(locales) != 3 || locales[0] != "en" || locales[1] != "de" || locales[2] != "pt-BR" {
+		t.Errorf("expected en, de and pt-BR, got %v", locales)
+	}
+
+	err = bundle.LoadFS(fstest.MapFS{"locales/fr.json": {Data: []byte(`{"category.Math": 1}`)}}, "locales")
+	if err == nil {
+		t.Error("expected an error for an invalid catalog")
+	}
+}
+
+func TestDefaultCatalogs(t *testing.T) {
+	localizer := Default().Localizer("de")
+	if got := localizer.Text(NodeKey("print", "name"), "Print"); got == "Print" {
+		t.Error("expected the built-in German catalog to translate the print node")
+	}
+	if got := localizer.Text(CategoryKey("Math"), "Math"); got == "Math" {
+		t.Error("expected the built-in German catalog to translate the categories")
+	}
+}
@@ -0,0 +1,113 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Localizer translates texts into a locale. A nil localizer leaves them in English.
+type Localizer struct {
+	bundle *Bundle
+	locale string
+}
+
+// Localizer returns the localizer of a locale
+func (b *Bundle) Localizer(locale string) *Localizer {
+	return &Localizer{bundle: b, locale: Canonical(locale)}
+}
+
+// Locale returns the locale of the localizer
+func (l *Localizer) Locale() string {
+	if l == nil || l.locale == "" {
+		return DefaultLocale
+	}
+	return l.locale
+}
+
+// Text returns the message of a key, or the fallback, usually the English text, if the
+// catalogs of the locale don't have it
+func (l *Localizer) Text(key, fallback string) string {
+	if l == nil {
+		return fallback
+	}
+	if message, exists := l.bundle.Message(l.locale, key); exists {
+		return message
+	}
+	return fallback
+}
+
+// placeholder matches the {name} placeholders of a message
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// Format returns the message of a key with its placeholders replaced by the arguments of the
+// same name, e.g. {limit}. Messages with a placeholder the arguments don't fill would lose
+// information, so the fallback is returned for them.
+func (l *Localizer) Format(key, fallback string, args map[string]interface{}) string {
+	message := l.Text(key, fallback)
+	if message == fallback {
+		return fallback
+	}
+
+	complete := true
+	formatted := placeholder.ReplaceAllStringFunc(message, func(match string) string {
+		value, exists := args[match[1:len(match)-1]]
+		if !exists {
+			complete = false
+			return match
+		}
+		return fmt.Sprint(value)
+	})
+	if !complete {
+		return fallback
+	}
+	return formatted
+}
+
+// Keys of the catalog messages
+
+// NodeKey is the key of a text of a node type, e.g. node.print.name
+func NodeKey(typeID, field string) string {
+	return "node." + typeID + "." + field
+}
+
+// InputKey is the key of a text of an input pin of a node type, e.g. node.print.inputs.message.name
+func InputKey(typeID, pinID, field string) string {
+	return NodeKey(typeID, "inputs."+pinID+"."+field)
+}
+
+// OutputKey is the key of a text of an output pin of a node type, e.g. node.print.outputs.then.name
+func OutputKey(typeID, pinID, field string) string {
+	return NodeKey(typeID, "outputs."+pinID+"."+field)
+}
+
+// PropertyKey is the key of a text of a property of a node type, e.g. node.timer.properties.interval.displayName
+func PropertyKey(typeID, name, field string) string {
+	return NodeKey(typeID, "properties."+name+"."+field)
+}
+
+// CategoryKey is the key of the name of a node category, e.g. category.Utility
+func CategoryKey(category string) string {
+	return "category." + category
+}
+
+// ErrorKey is the key of the message of an error code, e.g. error.Q001
+func ErrorKey(code string) string {
+	return "error." + code
+}
+
+// contextKey is the context key of the locale of a request
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying a locale
+func NewContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale of a context, the default locale if it has none
+func FromContext(ctx context.Context) string {
+	if locale, _ := ctx.Value(contextKey{}).(string); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
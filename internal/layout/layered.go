@@ -0,0 +1,312 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+	"webblueprint/pkg/blueprint"
+)
+
+// Direction is the direction the layers of a layout follow each other in
+type Direction string
+
+const (
+	LeftToRight Direction = "left-to-right" // Execution flows to the right, the default
+	TopToBottom Direction = "top-to-bottom" // Execution flows down
+)
+
+// Options configures a layered layout
+type Options struct {
+	Direction    Direction          `json:"direction"`
+	LayerSpacing float64            `json:"layerSpacing"` // Distance between consecutive layers
+	NodeSpacing  float64            `json:"nodeSpacing"`  // Distance between the nodes of a layer
+	Origin       blueprint.Position `json:"origin"`       // Position of the first node of the first layer
+}
+
+// DefaultOptions returns the options of layouts that don't set their own
+func DefaultOptions() Options {
+	return Options{
+		Direction:    LeftToRight,
+		LayerSpacing: 320,
+		NodeSpacing:  160,
+	}
+}
+
+// Validate reports invalid options, after the unset ones took their defaults
+func (o Options) Validate() error {
+	if o.Direction != LeftToRight && o.Direction != TopToBottom {
+		return fmt.Errorf("direction must be %s or %s, got %q", LeftToRight, TopToBottom, o.Direction)
+	}
+	if o.LayerSpacing <= 0 || o.NodeSpacing <= 0 {
+		return fmt.Errorf("layerSpacing and nodeSpacing must be positive")
+	}
+	return nil
+}
+
+// withDefaults fills in the unset options
+func (o Options) withDefaults() Options {
+	defaults := DefaultOptions()
+	if o.Direction == "" {
+		o.Direction = defaults.Direction
+	}
+	if o.LayerSpacing == 0 {
+		o.LayerSpacing = defaults.LayerSpacing
+	}
+	if o.NodeSpacing == 0 {
+		o.NodeSpacing = defaults.NodeSpacing
+	}
+	return o
+}
+
+// sweeps is how often the node order is improved in each direction
+const sweeps = 4
+
+// graph is the layered graph of a blueprint. Vertices past the blueprint nodes are dummies
+// that route edges spanning several layers, so those edges don't run through nodes.
+type graph struct {
+	ids          []string // Node ID of each real vertex
+	successors   [][]int
+	predecessors [][]int
+	layers       []int
+}
+
+// Layered computes a layered (Sugiyama style) layout of the executable nodes of a
+// blueprint from its execution and data connections, and returns their new positions.
+// Cycles are broken by reversing edges, each node is placed one layer after its furthest
+// predecessor, and the nodes of each layer are ordered to reduce crossings. Comments keep
+// their positions and aren't returned.
+func Layered(bp *blueprint.Blueprint, options Options) (map[string]blueprint.Position, error) {
+	options = options.withDefaults()
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := newGraph(bp)
+	g.breakCycles()
+	g.assignLayers()
+	g.addDummies()
+	order := g.orderLayers()
+
+	positions := make(map[string]blueprint.Position, len(g.ids))
+	widest := 0
+	for _, layer := range order {
+		if len(layer) > widest {
+			widest = len(layer)
+		}
+	}
+	for layerIndex, layer := range order {
+		// Layers are centered on the widest one
+		offset := float64(widest-len(layer)) / 2
+		for index, vertex := range layer {
+			if vertex >= len(g.ids) {
+				continue
+			}
+			along := options.LayerSpacing * float64(layerIndex)
+			across := options.NodeSpacing * (float64(index) + offset)
+			if options.Direction == TopToBottom {
+				along, across = across, along
+			}
+			positions[g.ids[vertex]] = blueprint.Position{
+				X: options.Origin.X + along,
+				Y: options.Origin.Y + across,
+			}
+		}
+	}
+	return positions, nil
+}
+
+// newGraph builds the graph of the executable nodes of a blueprint, with one edge per
+// connected pair of nodes
+func newGraph(bp *blueprint.Blueprint) *graph {
+	nodes := bp.ExecutableNodes()
+	g := &graph{
+		ids:          make([]string, len(nodes)),
+		successors:   make([][]int, len(nodes)),
+		predecessors: make([][]int, len(nodes)),
+	}
+
+	index := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		g.ids[i] = node.ID
+		index[node.ID] = i
+	}
+
+	type edge struct{ from, to int }
+	seen := make(map[edge]bool)
+	for _, conn := range bp.Connections {
+		from, fromExists := index[conn.SourceNodeID]
+		to, toExists := index[conn.TargetNodeID]
+		if !fromExists || !toExists || from == to || seen[edge{from, to}] {
+			continue
+		}
+		seen[edge{from, to}] = true
+		g.addEdge(from, to)
+	}
+	return g
+}
+
+func (g *graph) addEdge(from, to int) {
+	g.successors[from] = append(g.successors[from], to)
+	g.predecessors[to] = append(g.predecessors[to], from)
+}
+
+func (g *graph) removeEdge(from, to int) {
+	g.successors[from] = without(g.successors[from], to)
+	g.predecessors[to] = without(g.predecessors[to], from)
+}
+
+// breakCycles reverses the edges that close a cycle, found by a depth-first search in
+// node order, so the graph can be layered
+func (g *graph) breakCycles() {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make([]int, len(g.ids))
+
+	var visit func(vertex int)
+	visit = func(vertex int) {
+		state[vertex] = active
+		for _, next := range append([]int(nil), g.successors[vertex]...) {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case active:
+				g.removeEdge(vertex, next)
+				if !contains(g.successors[next], vertex) {
+					g.addEdge(next, vertex)
+				}
+			}
+		}
+		state[vertex] = done
+	}
+
+	for vertex := range g.ids {
+		if state[vertex] == unvisited {
+			visit(vertex)
+		}
+	}
+}
+
+// assignLayers places every vertex one layer after its furthest predecessor
+func (g *graph) assignLayers() {
+	g.layers = make([]int, len(g.ids))
+	remaining := make([]int, len(g.ids))
+	queue := make([]int, 0, len(g.ids))
+	for vertex := range g.ids {
+		remaining[vertex] = len(g.predecessors[vertex])
+		if remaining[vertex] == 0 {
+			queue = append(queue, vertex)
+		}
+	}
+
+	for len(queue) > 0 {
+		vertex := queue[0]
+		queue = queue[1:]
+		for _, next := range g.successors[vertex] {
+			if g.layers[vertex]+1 > g.layers[next] {
+				g.layers[next] = g.layers[vertex] + 1
+			}
+			remaining[next]--
+			if remaining[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// addDummies splits the edges spanning several layers with a dummy vertex in each layer
+// they cross
+func (g *graph) addDummies() {
+	real := len(g.ids)
+	for from := 0; from < real; from++ {
+		for _, to := range append([]int(nil), g.successors[from]...) {
+			if g.layers[to]-g.layers[from] <= 1 {
+				continue
+			}
+			g.removeEdge(from, to)
+			previous := from
+			for layer := g.layers[from] + 1; layer < g.layers[to]; layer++ {
+				dummy := len(g.layers)
+				g.layers = append(g.layers, layer)
+				g.successors = append(g.successors, nil)
+				g.predecessors = append(g.predecessors, nil)
+				g.addEdge(previous, dummy)
+				previous = dummy
+			}
+			g.addEdge(previous, to)
+		}
+	}
+}
+
+// orderLayers returns the vertices of each layer, ordered by the barycenter of their
+// neighbours in the previous layer and then the next one, a few times over
+func (g *graph) orderLayers() [][]int {
+	layerCount := 0
+	for _, layer := range g.layers {
+		if layer+1 > layerCount {
+			layerCount = layer + 1
+		}
+	}
+
+	order := make([][]int, layerCount)
+	for vertex, layer := range g.layers {
+		order[layer] = append(order[layer], vertex)
+	}
+	position := make([]float64, len(g.layers))
+	for _, layer := range order {
+		for index, vertex := range layer {
+			position[vertex] = float64(index)
+		}
+	}
+
+	sortLayer := func(layer []int, neighbours [][]int) {
+		barycenter := make(map[int]float64, len(layer))
+		for _, vertex := range layer {
+			// Vertices without neighbours on that side keep their place
+			barycenter[vertex] = position[vertex]
+			if len(neighbours[vertex]) > 0 {
+				sum := 0.0
+				for _, neighbour := range neighbours[vertex] {
+					sum += position[neighbour]
+				}
+				barycenter[vertex] = sum / float64(len(neighbours[vertex]))
+			}
+		}
+		sort.SliceStable(layer, func(i, j int) bool {
+			return barycenter[layer[i]] < barycenter[layer[j]]
+		})
+		for index, vertex := range layer {
+			position[vertex] = float64(index)
+		}
+	}
+
+	for sweep := 0; sweep < sweeps; sweep++ {
+		for layer := 1; layer < layerCount; layer++ {
+			sortLayer(order[layer], g.predecessors)
+		}
+		for layer := layerCount - 2; layer >= 0; layer-- {
+			sortLayer(order[layer], g.successors)
+		}
+	}
+	return order
+}
+
+func without(vertices []int, vertex int) []int {
+	result := vertices[:0]
+	for _, v := range vertices {
+		if v != vertex {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func contains(vertices []int, vertex int) bool {
+	for _, v := range vertices {
+		if v == vertex {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package layout
+
+import (
+	"testing"
+	"webblueprint/pkg/blueprint"
+)
+
+func newBlueprint(nodeIDs []string, edges [][2]string) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("bp", "Layout", "1.0.0")
+	for _, id := range nodeIDs {
+		bp.AddNode(blueprint.BlueprintNode{ID: id, Type: "print"})
+	}
+	for i, edge := range edges {
+		bp.AddConnection(blueprint.Connection{
+			ID:             string(rune('a' + i)),
+			SourceNodeID:   edge[0],
+			SourcePinID:    "then",
+			TargetNodeID:   edge[1],
+			TargetPinID:    "exec",
+			ConnectionType: "execution",
+		})
+	}
+	return bp
+}
+
+func TestLayeredPlacesNodesAfterTheirPredecessors(t *testing.T) {
+	bp := newBlueprint([]string{"d", "c", "b", "a"}, [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"a", "d"}})
+
+	positions, err := Layered(bp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedX := map[string]float64{"a": 0, "b": 320, "c": 320, "d": 640}
+	for id, x := range expectedX {
+		if positions[id].X != x {
+			t.Errorf("expected %s at x %v, got %v", id, x, positions[id].X)
+		}
+	}
+	if positions["b"].Y == positions["c"].Y {
+		t.Error("expected nodes of the same layer not to overlap")
+	}
+}
+
+func TestLayeredBreaksCycles(t *testing.T) {
+	bp := newBlueprint([]string{"a", "b", "c"}, [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}})
+
+	positions, err := Layered(bp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(positions) != 3 {
+		t.Fatalf("expected 3 positions, got %d", len(positions))
+	}
+	if !(positions["a"].X < positions["b"].X && positions["b"].X < positions["c"].X) {
+		t.Errorf("expected the cycle to be laid out in node order, got %v", positions)
+	}
+}
+
+func TestLayeredSkipsComments(t *testing.T) {
+	bp := newBlueprint([]string{"a", "b"}, [][2]string{{"a", "b"}})
+	bp.AddNode(blueprint.BlueprintNode{ID: "note", Type: blueprint.CommentNodeType, Position: blueprint.Position{X: 5, Y: 5}})
+
+	positions, err := Layered(bp, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, exists := positions["note"]; exists {
+		t.Error("expected comments to keep their positions")
+	}
+}
+
+func TestLayeredOptions(t *testing.T) {
+	bp := newBlueprint([]string{"a", "b"}, [][2]string{{"a", "b"}})
+
+	positions, err := Layered(bp, Options{Direction: TopToBottom, LayerSpacing: 100, Origin: blueprint.Position{X: 10, Y: 20}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if positions["b"] != (blueprint.Position{X: 10, Y: 120}) {
+		t.Errorf("expected b below a, got %v", positions["b"])
+	}
+
+	if _, err := Layered(bp, Options{Direction: "sideways"}); err == nil {
+		t.Error("expected an unknown direction to be rejected")
+	}
+	if _, err := Layered(bp, Options{NodeSpacing: -1}); err == nil {
+		t.Error("expected a negative spacing to be rejected")
+	}
+}
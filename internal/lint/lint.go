@@ -0,0 +1,202 @@
+package lint
+
+import (
+	"fmt"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/blueprint"
+)
+
+// Severity is how serious a lint finding is. Only errors fail a report.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// valid checks if the severity is known
+func (s Severity) valid() bool {
+	return s == SeverityError || s == SeverityWarning || s == SeverityInfo
+}
+
+// Options holds the options of a rule, as decoded from JSON
+type Options map[string]interface{}
+
+// Int returns an integer option, or 0 if it isn't a number
+func (o Options) Int(key string) int {
+	switch value := o[key].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+// String returns a string option, or "" if it isn't a string
+func (o Options) String(key string) string {
+	value, _ := o[key].(string)
+	return value
+}
+
+// Bool returns a boolean option, or false if it isn't a boolean
+func (o Options) Bool(key string) bool {
+	value, _ := o[key].(bool)
+	return value
+}
+
+// Rule is a lint check with its default severity and options
+type Rule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Options     Options  `json:"options,omitempty"`
+
+	check    func(r *run, options Options) []finding
+	validate func(options Options) error
+}
+
+// RuleConfig configures a rule for a workspace. An unset severity keeps the default of the
+// rule, and options missing from it keep their defaults.
+type RuleConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Severity Severity `json:"severity,omitempty"`
+	Options  Options  `json:"options,omitempty"`
+}
+
+// RuleSet configures the rules by name. Rules missing from it run with their defaults.
+type RuleSet map[string]RuleConfig
+
+// Validate checks that a rule set only configures known rules with valid severities and options
+func (s RuleSet) Validate() error {
+	for name, config := range s {
+		rule, exists := findRule(name)
+		if !exists {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+		if config.Severity != "" && !config.Severity.valid() {
+			return fmt.Errorf("lint rule %q has invalid severity %q", name, config.Severity)
+		}
+		if rule.validate != nil {
+			if err := rule.validate(rule.options(config)); err != nil {
+				return fmt.Errorf("lint rule %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// options returns the default options of the rule overridden by the configured ones
+func (r Rule) options(config RuleConfig) Options {
+	options := make(Options, len(r.Options)+len(config.Options))
+	for key, value := range r.Options {
+		options[key] = value
+	}
+	for key, value := range config.Options {
+		options[key] = value
+	}
+	return options
+}
+
+// Finding is a problem a rule found in a blueprint
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	NodeID   string   `json:"nodeId,omitempty"`
+}
+
+// finding is a problem reported by a rule, before the rule and severity are attached
+type finding struct {
+	nodeID  string
+	message string
+}
+
+// Report is the result of linting a blueprint. It passes when nothing has the error severity.
+type Report struct {
+	BlueprintID string           `json:"blueprintId"`
+	Passed      bool             `json:"passed"`
+	Counts      map[Severity]int `json:"counts"`
+	Findings    []Finding        `json:"findings"`
+}
+
+// run is the state of one lint run
+type run struct {
+	bp            *blueprint.Blueprint
+	lookupFactory bperrors.NodeFactoryLookup
+}
+
+// Linter checks blueprints against rule sets
+type Linter struct {
+	lookupFactory bperrors.NodeFactoryLookup
+}
+
+// NewLinter creates a new linter
+func NewLinter() *Linter {
+	return &Linter{}
+}
+
+// SetNodeFactoryLookup lets rules inspect the pins of node types, e.g. to find the
+// side-effecting nodes. Without it those rules find nothing.
+func (l *Linter) SetNodeFactoryLookup(lookup bperrors.NodeFactoryLookup) {
+	l.lookupFactory = lookup
+}
+
+// Lint checks a blueprint against the enabled rules of a rule set
+func (l *Linter) Lint(bp *blueprint.Blueprint, rules RuleSet) Report {
+	report := Report{
+		BlueprintID: bp.ID,
+		Passed:      true,
+		Counts:      map[Severity]int{SeverityError: 0, SeverityWarning: 0, SeverityInfo: 0},
+		Findings:    make([]Finding, 0),
+	}
+
+	r := &run{bp: bp, lookupFactory: l.lookupFactory}
+	for _, rule := range builtinRules {
+		config, configured := rules[rule.Name]
+		if !configured {
+			config = RuleConfig{Enabled: true}
+		}
+		if !config.Enabled {
+			continue
+		}
+
+		severity := rule.Severity
+		if config.Severity != "" {
+			severity = config.Severity
+		}
+
+		for _, found := range rule.check(r, rule.options(config)) {
+			report.Findings = append(report.Findings, Finding{
+				Rule:     rule.Name,
+				Severity: severity,
+				Message:  found.message,
+				NodeID:   found.nodeID,
+			})
+			report.Counts[severity]++
+			if severity == SeverityError {
+				report.Passed = false
+			}
+		}
+	}
+
+	return report
+}
+
+// Rules returns the built-in rules with their default severities and options
+func Rules() []Rule {
+	rules := make([]Rule, len(builtinRules))
+	copy(rules, builtinRules)
+	return rules
+}
+
+// findRule returns the built-in rule with a name
+func findRule(name string) (Rule, bool) {
+	for _, rule := range builtinRules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
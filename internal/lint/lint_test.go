@@ -0,0 +1,124 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/web"
+	"webblueprint/pkg/blueprint"
+)
+
+func lookupFactory(typeID string) (node.NodeFactory, bool) {
+	if typeID == "http-request" {
+		return web.NewHTTPRequestNode, true
+	}
+	return nil, false
+}
+
+func findingsOf(report Report, rule string) []Finding {
+	findings := make([]Finding, 0)
+	for _, finding := range report.Findings {
+		if finding.Rule == rule {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func TestLintDefaults(t *testing.T) {
+	bp := blueprint.NewBlueprint("bp", "Lint", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "request", Type: "http-request", Data: map[string]interface{}{
+		"headers": map[string]interface{}{"Authorization": "Bearer abcdef123456"},
+		"apiKey":  "${API_KEY}",
+	}})
+	bp.AddVariable(blueprint.Variable{Name: "Retry_Count"})
+
+	linter := NewLinter()
+	linter.SetNodeFactoryLookup(lookupFactory)
+	report := linter.Lint(bp, nil)
+
+	if report.Passed {
+		t.Error("expected the hardcoded credential to fail the report")
+	}
+
+	credentials := findingsOf(report, RuleNoHardcodedCredentials)
+	if len(credentials) != 1 || !strings.Contains(credentials[0].Message, "data headers.Authorization") {
+		t.Fatalf("expected one credential in the headers, got %v", credentials)
+	}
+	if strings.Contains(credentials[0].Message, "abcdef") {
+		t.Error("expected the finding not to contain the credential")
+	}
+
+	if len(findingsOf(report, RuleNaming)) != 1 {
+		t.Error("expected the variable name to be reported")
+	}
+	if errorHandling := findingsOf(report, RuleErrorHandling); len(errorHandling) != 1 || errorHandling[0].NodeID != "request" {
+		t.Errorf("expected the request to be reported for its errors, got %v", errorHandling)
+	}
+	if report.Counts[SeverityError] != 1 || report.Counts[SeverityWarning] != 2 {
+		t.Errorf("unexpected counts %v", report.Counts)
+	}
+}
+
+func TestLintErrorHandling(t *testing.T) {
+	bp := blueprint.NewBlueprint("bp", "Lint", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "request", Type: "http-request"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "log", Type: "print"})
+	bp.AddConnection(blueprint.Connection{ID: "c1", SourceNodeID: "request", SourcePinID: "catch", TargetNodeID: "log", TargetPinID: "exec"})
+
+	linter := NewLinter()
+	linter.SetNodeFactoryLookup(lookupFactory)
+	if findings := findingsOf(linter.Lint(bp, nil), RuleErrorHandling); len(findings) != 0 {
+		t.Errorf("expected the connected catch flow to count as error handling, got %v", findings)
+	}
+
+	bp.Connections = nil
+	bp.AddNode(blueprint.BlueprintNode{ID: "handler", Type: blueprint.ErrorHandlerNodeType})
+	if findings := findingsOf(linter.Lint(bp, nil), RuleErrorHandling); len(findings) != 0 {
+		t.Errorf("expected the error handler node to count as error handling, got %v", findings)
+	}
+
+	rules := RuleSet{RuleErrorHandling: {Enabled: true, Severity: SeverityError, Options: Options{"allowErrorHandler": false}}}
+	report := linter.Lint(bp, rules)
+	if findings := findingsOf(report, RuleErrorHandling); len(findings) != 1 || findings[0].Severity != SeverityError {
+		t.Errorf("expected the configured severity without the handler exemption, got %v", findings)
+	}
+	if report.Passed {
+		t.Error("expected the error finding to fail the report")
+	}
+}
+
+func TestLintRuleSet(t *testing.T) {
+	bp := blueprint.NewBlueprint("bp", "Lint", "1.0.0")
+	for _, id := range []string{"a", "b", "c"} {
+		bp.AddNode(blueprint.BlueprintNode{ID: id, Type: "print"})
+	}
+
+	rules := RuleSet{
+		RuleMaxNodes: {Enabled: true, Options: Options{"max": float64(2)}},
+		RuleNaming:   {Enabled: false},
+	}
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := NewLinter().Lint(bp, rules)
+	if findings := findingsOf(report, RuleMaxNodes); len(findings) != 1 || findings[0].Severity != SeverityWarning {
+		t.Errorf("expected the node count to be reported as a warning, got %v", findings)
+	}
+	if !report.Passed {
+		t.Error("expected warnings not to fail the report")
+	}
+
+	invalid := []RuleSet{
+		{"unknown": {Enabled: true}},
+		{RuleNaming: {Enabled: true, Severity: "fatal"}},
+		{RuleNaming: {Enabled: true, Options: Options{"variablePattern": "("}}},
+		{RuleMaxNodes: {Enabled: true, Options: Options{"max": float64(0)}}},
+	}
+	for _, rules := range invalid {
+		if err := rules.Validate(); err == nil {
+			t.Errorf("expected %v to be rejected", rules)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"webblueprint/internal/bperrors"
+)
+
+// Names of the built-in rules
+const (
+	RuleNaming                 = "naming"
+	RuleMaxNodes               = "max-nodes"
+	RuleErrorHandling          = "error-handling"
+	RuleNoHardcodedCredentials = "no-hardcoded-credentials"
+)
+
+// builtinRules are the rules every lint run knows, in the order they run
+var builtinRules = []Rule{
+	{
+		Name:        RuleNaming,
+		Description: "Variables and functions follow the naming conventions",
+		Severity:    SeverityWarning,
+		Options: Options{
+			"variablePattern": "^[a-z][a-zA-Z0-9]*$",
+			"functionPattern": "^[A-Za-z][a-zA-Z0-9]*$",
+		},
+		check:    checkNaming,
+		validate: validatePatterns("variablePattern", "functionPattern"),
+	},
+	{
+		Name:        RuleMaxNodes,
+		Description: "Blueprints stay below a number of nodes, so they remain readable",
+		Severity:    SeverityWarning,
+		Options:     Options{"max": 200},
+		check:       checkMaxNodes,
+		validate: func(options Options) error {
+			if options.Int("max") <= 0 {
+				return fmt.Errorf("max must be a positive number")
+			}
+			return nil
+		},
+	},
+	{
+		Name:        RuleErrorHandling,
+		Description: "Side-effecting nodes handle their errors through their catch flow or error output",
+		Severity:    SeverityWarning,
+		Options:     Options{"allowErrorHandler": true},
+		check:       checkErrorHandling,
+	},
+	{
+		Name:        RuleNoHardcodedCredentials,
+		Description: "Node properties and data don't contain passwords, tokens or keys",
+		Severity:    SeverityError,
+		Options: Options{
+			"keyPattern": `(?i)(passw(or)?d|secret|token|api[_-]?key|credential|private[_-]?key|authorization)`,
+		},
+		check:    checkCredentials,
+		validate: validatePatterns("keyPattern"),
+	},
+}
+
+// validatePatterns checks that options hold valid regular expressions
+func validatePatterns(keys ...string) func(options Options) error {
+	return func(options Options) error {
+		for _, key := range keys {
+			if _, err := regexp.Compile(options.String(key)); err != nil {
+				return fmt.Errorf("invalid %s: %v", key, err)
+			}
+		}
+		return nil
+	}
+}
+
+// checkNaming reports variables and functions whose names don't match their pattern
+func checkNaming(r *run, options Options) []finding {
+	variablePattern := regexp.MustCompile(options.String("variablePattern"))
+	functionPattern := regexp.MustCompile(options.String("functionPattern"))
+
+	findings := make([]finding, 0)
+	for _, variable := range r.bp.Variables {
+		if !variablePattern.MatchString(variable.Name) {
+			findings = append(findings, finding{
+				message: fmt.Sprintf("variable %q doesn't match the naming pattern %s", variable.Name, variablePattern),
+			})
+		}
+	}
+	for _, function := range r.bp.Functions {
+		if !functionPattern.MatchString(function.Name) {
+			findings = append(findings, finding{
+				message: fmt.Sprintf("function %q doesn't match the naming pattern %s", function.Name, functionPattern),
+			})
+		}
+	}
+	return findings
+}
+
+// checkMaxNodes reports blueprints with more executable nodes than allowed
+func checkMaxNodes(r *run, options Options) []finding {
+	max := options.Int("max")
+	count := len(r.bp.ExecutableNodes())
+	if count <= max {
+		return nil
+	}
+	return []finding{{
+		message: fmt.Sprintf("blueprint has %d nodes, more than the maximum of %d; consider splitting it into functions", count, max),
+	}}
+}
+
+// checkErrorHandling reports side-effecting nodes, the ones with a catch flow, whose catch
+// flow and error output are both unconnected. With allowErrorHandler, an on-error handler
+// node in the blueprint handles the errors of every node.
+func checkErrorHandling(r *run, options Options) []finding {
+	if r.lookupFactory == nil {
+		return nil
+	}
+	if options.Bool("allowErrorHandler") && r.bp.GetErrorPolicy().HandlerNodeID != "" {
+		return nil
+	}
+
+	handled := make(map[string]bool)
+	for _, conn := range r.bp.Connections {
+		if conn.SourcePinID == bperrors.CatchPinID || conn.SourcePinID == bperrors.ErrorPinID {
+			handled[conn.SourceNodeID] = true
+		}
+	}
+
+	findings := make([]finding, 0)
+	for _, node := range r.bp.ExecutableNodes() {
+		if handled[node.ID] || !hasCatchFlow(r, node.Type) {
+			continue
+		}
+		findings = append(findings, finding{
+			nodeID:  node.ID,
+			message: fmt.Sprintf("%s node %s doesn't handle its errors; connect its catch flow or error output", node.Type, node.ID),
+		})
+	}
+	return findings
+}
+
+// hasCatchFlow checks if a node type has the catch flow of side-effecting nodes
+func hasCatchFlow(r *run, nodeType string) bool {
+	factory, exists := r.lookupFactory(nodeType)
+	if !exists {
+		return false
+	}
+	for _, pin := range factory().GetOutputPins() {
+		if pin.ID == bperrors.CatchPinID {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialValuePatterns match values that are credentials whatever they're stored under
+var credentialValuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(?i)^(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}$`),
+}
+
+// referencePattern matches values that refer to a secret instead of holding it, like
+// ${API_TOKEN} or {{secrets.apiToken}}
+var referencePattern = regexp.MustCompile(`^(\$\{[^}]+\}|\{\{[^}]+\}\})$`)
+
+// checkCredentials reports node properties and data holding credentials. Findings name
+// where the credential is but never the credential itself.
+func checkCredentials(r *run, options Options) []finding {
+	keyPattern := regexp.MustCompile(options.String("keyPattern"))
+
+	findings := make([]finding, 0)
+	for _, node := range r.bp.ExecutableNodes() {
+		report := func(location string) {
+			findings = append(findings, finding{
+				nodeID:  node.ID,
+				message: fmt.Sprintf("node %s has a hardcoded credential in %s; pass it in as a variable or secret reference instead", node.ID, location),
+			})
+		}
+
+		for _, property := range node.Properties {
+			findCredentials(keyPattern, "property "+property.Name, property.Name, property.Value, report)
+		}
+
+		keys := make([]string, 0, len(node.Data))
+		for key := range node.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			findCredentials(keyPattern, "data "+key, key, node.Data[key], report)
+		}
+	}
+	return findings
+}
+
+// findCredentials reports a value if it's a credential, looking into maps and lists.
+// Strings count when their key looks sensitive or the value itself looks like a key.
+func findCredentials(keyPattern *regexp.Regexp, location, key string, value interface{}, report func(location string)) {
+	switch value := value.(type) {
+	case string:
+		value = strings.TrimSpace(value)
+		if value == "" || referencePattern.MatchString(value) {
+			return
+		}
+		if keyPattern.MatchString(key) {
+			report(location)
+			return
+		}
+		for _, pattern := range credentialValuePatterns {
+			if pattern.MatchString(value) {
+				report(location)
+				return
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for nested := range value {
+			keys = append(keys, nested)
+		}
+		sort.Strings(keys)
+		for _, nested := range keys {
+			findCredentials(keyPattern, location+"."+nested, nested, value[nested], report)
+		}
+	case []interface{}:
+		for i, item := range value {
+			findCredentials(keyPattern, fmt.Sprintf("%s[%d]", location, i), key, item, report)
+		}
+	}
+}
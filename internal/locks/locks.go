@@ -0,0 +1,83 @@
+package locks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Lock is a named lock, or one permit of a named semaphore, held by an execution
+type Lock struct {
+	Name       string    `json:"name"`
+	Owner      string    `json:"owner"`   // ID of the execution holding the lock
+	Permits    int       `json:"permits"` // Number of executions that can hold the lock at once
+	AcquiredAt time.Time `json:"acquiredAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Locker serializes access of concurrent executions to shared resources. A lock is held
+// by its owner until it's released, or its TTL ran out so that crashed or stuck executions
+// don't hold it forever. Acquiring a lock the owner holds again extends its TTL.
+type Locker interface {
+	// TryAcquire takes a permit of a named lock for an owner without waiting, and reports
+	// whether it got one
+	TryAcquire(ctx context.Context, name, owner string, permits int, ttl time.Duration) (bool, error)
+
+	// Release gives back the permit of a named lock held by an owner, and reports whether
+	// the owner held one
+	Release(ctx context.Context, name, owner string) (bool, error)
+
+	// ReleaseAll gives back all permits held by an owner, e.g. once its execution ended
+	ReleaseAll(ctx context.Context, owner string) int
+
+	// Locks returns the locks held through this locker
+	Locks() []Lock
+}
+
+// pollInterval is how often Acquire retries a lock that is held
+const pollInterval = 50 * time.Millisecond
+
+// Acquire takes a permit of a named lock, waiting up to timeout for one to be released.
+// It reports false if the timeout ran out first.
+func Acquire(ctx context.Context, locker Locker, name, owner string, permits int, ttl, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := locker.TryAcquire(ctx, name, owner, permits, ttl)
+		if err != nil || acquired {
+			return acquired, err
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return false, nil
+		}
+		if wait > pollInterval {
+			wait = pollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// defaultLocker is the locker of the lock nodes; it holds locks in memory until the server
+// replaces it with one shared by all its instances
+var defaultLocker Locker = NewMemoryLocker()
+var defaultMutex sync.RWMutex
+
+// Default returns the locker the lock nodes use
+func Default() Locker {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultLocker
+}
+
+// SetDefault replaces the locker the lock nodes use
+func SetDefault(locker Locker) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultLocker = locker
+}
@@ -0,0 +1,104 @@
+package locks
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLockerExclusive(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	if acquired, _ := locker.TryAcquire(ctx, "billing", "exec-1", 1, time.Minute); !acquired {
+		t.Fatal("expected the first execution to get the lock")
+	}
+	if acquired, _ := locker.TryAcquire(ctx, "billing", "exec-2", 1, time.Minute); acquired {
+		t.Fatal("expected the second execution to be kept out")
+	}
+
+	// Holders can take the lock again
+	if acquired, _ := locker.TryAcquire(ctx, "billing", "exec-1", 1, time.Minute); !acquired {
+		t.Error("expected the holder to get the lock again")
+	}
+
+	if released, _ := locker.Release(ctx, "billing", "exec-2"); released {
+		t.Error("expected only the holder to release the lock")
+	}
+	if released, _ := locker.Release(ctx, "billing", "exec-1"); !released {
+		t.Fatal("expected the holder to release the lock")
+	}
+	if acquired, _ := locker.TryAcquire(ctx, "billing", "exec-2", 1, time.Minute); !acquired {
+		t.Error("expected the lock to be free once released")
+	}
+}
+
+func TestMemoryLockerSemaphore(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	for _, owner := range []string{"exec-1", "exec-2"} {
+		if acquired, _ := locker.TryAcquire(ctx, "api", owner, 2, time.Minute); !acquired {
+			t.Fatalf("expected %s to get a permit", owner)
+		}
+	}
+	if acquired, _ := locker.TryAcquire(ctx, "api", "exec-3", 2, time.Minute); acquired {
+		t.Fatal("expected the permits to run out")
+	}
+
+	if locks := locker.Locks(); len(locks) != 2 {
+		t.Errorf("expected 2 held permits, got %+v", locks)
+	}
+}
+
+func TestMemoryLockerExpiry(t *testing.T) {
+	locker := NewMemoryLocker()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	locker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	locker.TryAcquire(ctx, "billing", "exec-1", 1, time.Minute)
+
+	now = now.Add(time.Minute)
+	if acquired, _ := locker.TryAcquire(ctx, "billing", "exec-2", 1, time.Minute); !acquired {
+		t.Error("expected the lock to be free once its TTL ran out")
+	}
+}
+
+func TestReleaseAll(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	locker.TryAcquire(ctx, "a", "exec-1", 1, time.Minute)
+	locker.TryAcquire(ctx, "b", "exec-1", 1, time.Minute)
+	locker.TryAcquire(ctx, "b", "exec-2", 2, time.Minute)
+
+	if released := locker.ReleaseAll(ctx, "exec-1"); released != 2 {
+		t.Errorf("expected 2 locks to be released, got %d", released)
+	}
+	if locks := locker.Locks(); len(locks) != 1 || locks[0].Owner != "exec-2" {
+		t.Errorf("expected only the lock of the other execution to be held, got %+v", locks)
+	}
+}
+
+func TestAcquireWaits(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	locker.TryAcquire(ctx, "billing", "exec-1", 1, time.Minute)
+
+	acquired, err := Acquire(ctx, locker, "billing", "exec-2", 1, time.Minute, 20*time.Millisecond)
+	if err != nil || acquired {
+		t.Fatalf("expected the wait to time out, got %v (%v)", acquired, err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		locker.Release(ctx, "billing", "exec-1")
+	}()
+
+	acquired, err = Acquire(ctx, locker, "billing", "exec-2", 1, time.Minute, time.Second)
+	if err != nil || !acquired {
+		t.Errorf("expected the lock once released, got %v (%v)", acquired, err)
+	}
+}
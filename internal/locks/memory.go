@@ -0,0 +1,137 @@
+package locks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryLocker holds locks in memory, shared by the executions of a single process
+type MemoryLocker struct {
+	locks map[string]map[string]*Lock // Name -> owner -> lock
+	mutex sync.Mutex
+	now   func() time.Time
+}
+
+// NewMemoryLocker creates a new in-memory locker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		locks: make(map[string]map[string]*Lock),
+		now:   time.Now,
+	}
+}
+
+// TryAcquire takes a permit of a named lock for an owner without waiting
+func (l *MemoryLocker) TryAcquire(ctx context.Context, name, owner string, permits int, ttl time.Duration) (bool, error) {
+	if permits < 1 {
+		permits = 1
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	holders := l.holders(name, now)
+
+	if lock, held := holders[owner]; held {
+		lock.ExpiresAt = now.Add(ttl)
+		return true, nil
+	}
+	if len(holders) >= permits {
+		return false, nil
+	}
+
+	holders[owner] = &Lock{
+		Name:       name,
+		Owner:      owner,
+		Permits:    permits,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	return true, nil
+}
+
+// Release gives back the permit of a named lock held by an owner
+func (l *MemoryLocker) Release(ctx context.Context, name, owner string) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	holders := l.holders(name, l.now())
+	if _, held := holders[owner]; !held {
+		return false, nil
+	}
+
+	delete(holders, owner)
+	if len(holders) == 0 {
+		delete(l.locks, name)
+	}
+	return true, nil
+}
+
+// ReleaseAll gives back all permits held by an owner
+func (l *MemoryLocker) ReleaseAll(ctx context.Context, owner string) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	released := 0
+	for name, holders := range l.locks {
+		if _, held := holders[owner]; !held {
+			continue
+		}
+		delete(holders, owner)
+		if len(holders) == 0 {
+			delete(l.locks, name)
+		}
+		released++
+	}
+	return released
+}
+
+// Locks returns the locks that haven't expired, ordered by name and acquisition
+func (l *MemoryLocker) Locks() []Lock {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	result := make([]Lock, 0)
+	for name := range l.locks {
+		holders := l.holders(name, now)
+		if len(holders) == 0 {
+			delete(l.locks, name)
+		}
+		for _, lock := range holders {
+			result = append(result, *lock)
+		}
+	}
+
+	sortLocks(result)
+	return result
+}
+
+// holders returns the holders of a named lock without the expired ones. The caller must
+// hold the mutex.
+func (l *MemoryLocker) holders(name string, now time.Time) map[string]*Lock {
+	holders, exists := l.locks[name]
+	if !exists {
+		holders = make(map[string]*Lock)
+		l.locks[name] = holders
+	}
+
+	for owner, lock := range holders {
+		if !now.Before(lock.ExpiresAt) {
+			delete(holders, owner)
+		}
+	}
+	return holders
+}
+
+// sortLocks orders locks by name and acquisition
+func sortLocks(locks []Lock) {
+	sort.Slice(locks, func(i, j int) bool {
+		if locks[i].Name != locks[j].Name {
+			return locks[i].Name < locks[j].Name
+		}
+		return locks[i].AcquiredAt.Before(locks[j].AcquiredAt)
+	})
+}
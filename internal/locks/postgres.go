@@ -0,0 +1,198 @@
+package locks
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxHeldLocks caps the permits a server holds at once, since each keeps a connection out
+// of the pool the repositories share
+const maxHeldLocks = 10
+
+// PostgresLocker holds locks as PostgreSQL session advisory locks, so they're shared by
+// every server on the database. Each held permit keeps a connection of its own, and the
+// database releases the permits of a server that crashed when its sessions end.
+type PostgresLocker struct {
+	db    *sql.DB
+	held  map[string]map[string]*heldLock // Name -> owner -> permit
+	mutex sync.Mutex
+}
+
+// heldLock is a permit held through an advisory lock on its own connection
+type heldLock struct {
+	lock  Lock
+	slot  int // Permit of the semaphore, the second key of the advisory lock
+	conn  *sql.Conn
+	timer *time.Timer
+}
+
+// NewPostgresLocker creates a new PostgreSQL advisory lock based locker
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{
+		db:   db,
+		held: make(map[string]map[string]*heldLock),
+	}
+}
+
+// TryAcquire takes a permit of a named lock for an owner without waiting. A semaphore of
+// n permits is n advisory locks keyed by the hash of its name and the permit.
+func (l *PostgresLocker) TryAcquire(ctx context.Context, name, owner string, permits int, ttl time.Duration) (bool, error) {
+	if permits < 1 {
+		permits = 1
+	}
+
+	l.mutex.Lock()
+	if held, exists := l.held[name][owner]; exists {
+		held.lock.ExpiresAt = time.Now().Add(ttl)
+		held.timer.Reset(ttl)
+		l.mutex.Unlock()
+		return true, nil
+	}
+	if held := l.heldCount(); held >= maxHeldLocks {
+		l.mutex.Unlock()
+		return false, fmt.Errorf("the server already holds %d locks, the most it can", held)
+	}
+	l.mutex.Unlock()
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get a lock connection: %w", err)
+	}
+
+	slot := -1
+	for permit := 0; permit < permits; permit++ {
+		var acquired bool
+		err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1), $2)`, name, permit).Scan(&acquired)
+		if err != nil {
+			discardConn(conn)
+			return false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+		}
+		if acquired {
+			slot = permit
+			break
+		}
+	}
+
+	if slot < 0 {
+		conn.Close()
+		return false, nil
+	}
+
+	now := time.Now()
+	held := &heldLock{
+		lock: Lock{
+			Name:       name,
+			Owner:      owner,
+			Permits:    permits,
+			AcquiredAt: now,
+			ExpiresAt:  now.Add(ttl),
+		},
+		slot: slot,
+		conn: conn,
+	}
+	held.timer = time.AfterFunc(ttl, func() {
+		l.Release(context.Background(), name, owner)
+	})
+
+	l.mutex.Lock()
+	if l.held[name] == nil {
+		l.held[name] = make(map[string]*heldLock)
+	}
+	l.held[name][owner] = held
+	l.mutex.Unlock()
+
+	return true, nil
+}
+
+// Release gives back the permit of a named lock held by an owner
+func (l *PostgresLocker) Release(ctx context.Context, name, owner string) (bool, error) {
+	l.mutex.Lock()
+	held, exists := l.held[name][owner]
+	if exists {
+		delete(l.held[name], owner)
+		if len(l.held[name]) == 0 {
+			delete(l.held, name)
+		}
+	}
+	l.mutex.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	return true, l.unlock(ctx, held)
+}
+
+// ReleaseAll gives back all permits held by an owner
+func (l *PostgresLocker) ReleaseAll(ctx context.Context, owner string) int {
+	l.mutex.Lock()
+	released := make([]*heldLock, 0)
+	for name, holders := range l.held {
+		held, exists := holders[owner]
+		if !exists {
+			continue
+		}
+		delete(holders, owner)
+		if len(holders) == 0 {
+			delete(l.held, name)
+		}
+		released = append(released, held)
+	}
+	l.mutex.Unlock()
+
+	for _, held := range released {
+		l.unlock(ctx, held)
+	}
+	return len(released)
+}
+
+// Locks returns the locks held by this server
+func (l *PostgresLocker) Locks() []Lock {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	result := make([]Lock, 0)
+	for _, holders := range l.held {
+		for _, held := range holders {
+			result = append(result, held.lock)
+		}
+	}
+
+	sortLocks(result)
+	return result
+}
+
+// heldCount returns the number of permits held by this server. The caller must hold the
+// mutex.
+func (l *PostgresLocker) heldCount() int {
+	count := 0
+	for _, holders := range l.held {
+		count += len(holders)
+	}
+	return count
+}
+
+// unlock releases the advisory lock of a permit and returns its connection to the pool
+func (l *PostgresLocker) unlock(ctx context.Context, held *heldLock) error {
+	held.timer.Stop()
+
+	_, err := held.conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1), $2)`, held.lock.Name, held.slot)
+	if err != nil {
+		// The session must not go back to the pool still holding the lock
+		discardConn(held.conn)
+		return fmt.Errorf("failed to release lock %s: %w", held.lock.Name, err)
+	}
+	return held.conn.Close()
+}
+
+// discardConn closes the session of a connection instead of returning it to the pool,
+// which releases the advisory locks it holds
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(driverConn interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
@@ -0,0 +1,188 @@
+package logsink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the Loki sink
+const (
+	defaultLokiBatchSize     = 100
+	defaultLokiFlushInterval = time.Second
+	maxLokiPending           = 10000 // Entries kept while Loki is unreachable, the oldest are dropped beyond
+)
+
+// LokiSink pushes entries to Grafana Loki in batches. Entries are grouped in streams by
+// their level, along with the labels of the sink.
+type LokiSink struct {
+	pushURL       string
+	labels        map[string]string
+	batchSize     int
+	flushInterval time.Duration
+	client        *http.Client
+
+	pending []Entry
+	mutex   sync.Mutex
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewLokiSink creates a Loki sink pushing to the Loki server at the base URL. Entries are
+// pushed once a batch is full or the flush interval passed.
+func NewLokiSink(url string, labels map[string]string, batchSize int, flushInterval time.Duration) *LokiSink {
+	if batchSize <= 0 {
+		batchSize = defaultLokiBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultLokiFlushInterval
+	}
+
+	s := &LokiSink{
+		pushURL:       strings.TrimSuffix(url, "/") + "/loki/api/v1/push",
+		labels:        labels,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		flush:         make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write queues an entry for the next push
+func (s *LokiSink) Write(entry Entry) error {
+	s.mutex.Lock()
+	s.pending = append(s.pending, entry)
+	if len(s.pending) > maxLokiPending {
+		s.pending = s.pending[len(s.pending)-maxLokiPending:]
+	}
+	full := len(s.pending) >= s.batchSize
+	s.mutex.Unlock()
+
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close pushes the pending entries and stops the sink
+func (s *LokiSink) Close() error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	close(s.done)
+	<-s.stopped
+	return s.push()
+}
+
+// run pushes the pending entries whenever a batch is full or the flush interval passed
+func (s *LokiSink) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		case <-s.flush:
+		}
+		// Failed pushes are retried with the next batch
+		s.push()
+	}
+}
+
+// lokiPush is the body of a Loki push request
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiStream is a stream of a Loki push request, values being pairs of a timestamp in
+// nanoseconds and a line
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// push pushes the pending entries, keeping them for the next push if Loki rejects them
+func (s *LokiSink) push() error {
+	s.mutex.Lock()
+	entries := s.pending
+	s.pending = nil
+	s.mutex.Unlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	streams := make(map[string]*lokiStream)
+	order := make([]string, 0)
+	for _, entry := range entries {
+		stream, exists := streams[entry.Level]
+		if !exists {
+			labels := make(map[string]string, len(s.labels)+1)
+			for name, value := range s.labels {
+				labels[name] = value
+			}
+			labels["level"] = entry.Level
+			stream = &lokiStream{Stream: labels}
+			streams[entry.Level] = stream
+			order = append(order, entry.Level)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(entry.Time.UnixNano(), 10),
+			string(entry.JSON()),
+		})
+	}
+
+	body := lokiPush{Streams: make([]lokiStream, 0, len(order))}
+	for _, level := range order {
+		body.Streams = append(body.Streams, *streams[level])
+	}
+
+	err := s.send(body)
+	if err != nil {
+		s.mutex.Lock()
+		s.pending = append(entries, s.pending...)
+		if len(s.pending) > maxLokiPending {
+			s.pending = s.pending[len(s.pending)-maxLokiPending:]
+		}
+		s.mutex.Unlock()
+	}
+	return err
+}
+
+// send posts a push request to Loki
+func (s *LokiSink) send(body lokiPush) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode Loki push: %w", err)
+	}
+
+	resp, err := s.client.Post(s.pushURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to push logs to Loki: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("loki rejected the logs with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package logsink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/pkg/blueprint"
+)
+
+// Entry is a structured log entry of the engine or of a node of an execution
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	NodeID  string                 `json:"nodeId,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// JSON encodes the entry as a single line of JSON. Fields that can't be encoded are
+// written as text.
+func (e Entry) JSON() []byte {
+	line, err := json.Marshal(e)
+	if err == nil {
+		return line
+	}
+
+	fields := make(map[string]interface{}, len(e.Fields))
+	for key, value := range e.Fields {
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprint(value)
+		}
+		fields[key] = value
+	}
+	e.Fields = fields
+	line, _ = json.Marshal(e)
+	return line
+}
+
+// Sink forwards log entries to a destination such as a file, syslog or Loki
+type Sink interface {
+	// Write forwards an entry
+	Write(entry Entry) error
+
+	// Close flushes the pending entries and releases the destination
+	Close() error
+}
+
+// leveledSink drops the entries below a level before forwarding them
+type leveledSink struct {
+	sink Sink
+	rank int
+}
+
+// Leveled returns a sink that only forwards the entries at or above a level to a sink.
+// Unknown levels forward every entry.
+func Leveled(sink Sink, level string) Sink {
+	rank := blueprint.LogLevelRank(level)
+	if rank <= 0 {
+		return sink
+	}
+	return &leveledSink{sink: sink, rank: rank}
+}
+
+func (s *leveledSink) Write(entry Entry) error {
+	if rank := blueprint.LogLevelRank(entry.Level); rank >= 0 && rank < s.rank {
+		return nil
+	}
+	return s.sink.Write(entry)
+}
+
+func (s *leveledSink) Close() error {
+	return s.sink.Close()
+}
+
+// Multi forwards every entry to several sinks
+type Multi struct {
+	sinks []Sink
+}
+
+// NewMulti creates a sink forwarding to all of the sinks
+func NewMulti(sinks ...Sink) *Multi {
+	return &Multi{sinks: sinks}
+}
+
+// Write forwards an entry to every sink, even if some of them fail
+func (m *Multi) Write(entry Entry) error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Write(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink
+func (m *Multi) Close() error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,136 @@
+package logsink
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "server.log")
+	sink, err := NewFileSink(path, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.maxBytes = 200
+
+	for i := 0; i < 10; i++ {
+		if err := sink.Write(Entry{Time: time.Now(), Level: "info", Message: strings.Repeat("x", 50)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, rotated := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(rotated)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", rotated, err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("expected %s to be rotated at 200 bytes, got %d", rotated, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files to be kept")
+	}
+
+	contents, _ := os.ReadFile(path)
+	var entry Entry
+	if err := json.Unmarshal([]byte(strings.Split(string(contents), "\n")[0]), &entry); err != nil || entry.Level != "info" {
+		t.Errorf("expected JSON lines, got %q (%v)", contents, err)
+	}
+}
+
+func TestLeveled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	file, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := Leveled(file, "warn")
+	defer sink.Close()
+
+	for _, level := range []string{"debug", "info", "warn", "error"} {
+		sink.Write(Entry{Level: level, Message: level})
+	}
+
+	contents, _ := os.ReadFile(path)
+	if lines := strings.Count(string(contents), "\n"); lines != 2 {
+		t.Errorf("expected only warnings and errors, got %q", contents)
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "webblueprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err := sink.Write(Entry{Time: time.Now(), Level: "error", NodeID: "fetch", Message: "request failed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := string(buffer[:n])
+	// local0.err
+	if !strings.HasPrefix(message, "<131>1 ") || !strings.Contains(message, " webblueprint ") || !strings.Contains(message, `"nodeId":"fetch"`) {
+		t.Errorf("unexpected syslog message %q", message)
+	}
+}
+
+func TestLokiSinkPushes(t *testing.T) {
+	pushes := make(chan lokiPush, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" {
+			t.Errorf("unexpected push path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var push lokiPush
+		json.Unmarshal(body, &push)
+		pushes <- push
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewLokiSink(server.URL, map[string]string{"app": "webblueprint"}, 2, time.Hour)
+	sink.Write(Entry{Time: time.Now(), Level: "info", Message: "started"})
+	sink.Write(Entry{Time: time.Now(), Level: "error", Message: "failed"})
+
+	select {
+	case push := <-pushes:
+		if len(push.Streams) != 2 || push.Streams[0].Stream["app"] != "webblueprint" || push.Streams[1].Stream["level"] != "error" {
+			t.Errorf("expected a stream per level, got %+v", push.Streams)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a full batch to be pushed")
+	}
+
+	// Close pushes what's pending
+	sink.Write(Entry{Time: time.Now(), Level: "info", Message: "stopping"})
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if push := <-pushes; len(push.Streams) != 1 || len(push.Streams[0].Values) != 1 {
+		t.Errorf("expected the pending entry to be pushed on close, got %+v", push.Streams)
+	}
+}
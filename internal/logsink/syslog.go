@@ -0,0 +1,125 @@
+package logsink
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// syslogFacility is the facility of the messages, local0
+const syslogFacility = 16
+
+// SyslogSink sends entries as RFC 5424 messages with a JSON body to a syslog server over
+// UDP or TCP
+type SyslogSink struct {
+	network  string
+	address  string
+	tag      string
+	hostname string
+	conn     net.Conn
+	mutex    sync.Mutex
+}
+
+// NewSyslogSink connects a syslog sink. The tag is the app name of the messages.
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	if network == "" {
+		network = "udp"
+	}
+	if tag == "" {
+		tag = "webblueprint"
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	s := &SyslogSink{
+		network:  network,
+		address:  address,
+		tag:      tag,
+		hostname: hostname,
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write sends an entry, reconnecting once if the connection was lost
+func (s *SyslogSink) Write(entry Entry) error {
+	message := s.format(entry)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn != nil {
+		if _, err := s.conn.Write(message); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+	if _, err := s.conn.Write(message); err != nil {
+		return fmt.Errorf("failed to send syslog message to %s: %w", s.address, err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (s *SyslogSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// connect dials the syslog server. The caller must hold the mutex, if shared.
+func (s *SyslogSink) connect() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// format formats an entry as a syslog message. Messages over TCP are framed by their
+// length, as in RFC 6587.
+func (s *SyslogSink) format(entry Entry) []byte {
+	message := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		syslogFacility*8+syslogSeverity(entry.Level),
+		entry.Time.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		s.tag,
+		os.Getpid(),
+		entry.JSON(),
+	)
+	if s.network == "tcp" || s.network == "tcp4" || s.network == "tcp6" {
+		message = fmt.Sprintf("%d %s", len(message), message)
+	}
+	return []byte(message)
+}
+
+// syslogSeverity returns the syslog severity of a log level
+func syslogSeverity(level string) int {
+	switch level {
+	case "debug":
+		return 7
+	case "warn", "warning":
+		return 4
+	case "error":
+		return 3
+	}
+	return 6
+}
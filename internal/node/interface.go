@@ -1,6 +1,8 @@
 package node
 
 import (
+	"context"
+	"time"
 	"webblueprint/internal/db"
 	"webblueprint/internal/types"
 )
@@ -15,6 +17,9 @@ type NodeMetadata struct {
 	Properties  []types.Property // Node properties
 	InputPins   []types.Pin      // Input pins for the node
 	OutputPins  []types.Pin      // Output pins for the node
+
+	// PropertySchema refines the editors and accepted values of the properties
+	PropertySchema []PropertySchema
 }
 
 // Node is the interface that all node types must implement
@@ -43,6 +48,93 @@ type Node interface {
 	Execute(ctx ExecutionContext) error
 }
 
+// NondeterministicNode is implemented by nodes whose results depend on the outside
+// world, e.g. network responses or the clock. Their results are recorded during an
+// execution and fed back when it's replayed.
+type NondeterministicNode interface {
+	IsNondeterministic() bool
+}
+
+// DynamicPinNode is implemented by nodes whose pins depend on their configuration in the
+// blueprint, e.g. the case pins of a switch node. The engine configures the pins from the
+// data of the blueprint node before the node runs.
+type DynamicPinNode interface {
+	ConfigurePins(data map[string]interface{}) error
+}
+
+// ConfigurePins declares the pins of a node from the data of its blueprint node, if the
+// node has dynamic pins
+func ConfigurePins(n Node, data map[string]interface{}) error {
+	dynamic, ok := n.(DynamicPinNode)
+	if !ok {
+		return nil
+	}
+	return dynamic.ConfigurePins(data)
+}
+
+// JoinNode is implemented by nodes that synchronize parallel branches. The engine keeps
+// track of the execution inputs activated on the node during an execution, and only runs
+// the node once the required number of distinct inputs has been activated.
+type JoinNode interface {
+	RequiredInputs() int
+}
+
+// ScopedNode is implemented by nodes that run a branch of the blueprint in child scopes of
+// the execution, e.g. once per element of an array. The nodes connected to the scope pins
+// only run through ScopedExecutionContext.RunScope, never when the node completes.
+type ScopedNode interface {
+	ScopePins() []string
+}
+
+// ScopedExecutionContext is implemented by the execution contexts of engines that support
+// child scopes. The nodes run in a scope see the outputs the running node gave the scope,
+// and the values they produce stay in the scope, so that concurrent scopes don't read each
+// other's values.
+type ScopedExecutionContext interface {
+	ExecutionContext
+
+	// RunScope runs the nodes connected to an output execution pin of the node in a new
+	// child scope and waits for them. It returns the value delivered to the node's input pin
+	// resultPin from within the scope, if any.
+	RunScope(pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error)
+}
+
+// ScopedContext returns the context supporting child scopes behind an execution context,
+// unwrapping the decorators around it
+func ScopedContext(ctx ExecutionContext) (ScopedExecutionContext, bool) {
+	for i := 0; ctx != nil && i < 10; i++ {
+		if scoped, ok := ctx.(ScopedExecutionContext); ok {
+			return scoped, true
+		}
+		wrapper, ok := ctx.(interface{ Unwrap() ExecutionContext })
+		if !ok {
+			break
+		}
+		ctx = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// Rate limit modes of nodes that coalesce rapid triggers
+const (
+	RateLimitDebounce = "debounce" // Runs once the triggers paused for the interval, with the latest inputs
+	RateLimitThrottle = "throttle" // Runs at most once per interval
+)
+
+// RateLimit describes how a rate limited node coalesces its triggers
+type RateLimit struct {
+	Mode     string
+	Interval time.Duration
+	Trailing bool // Throttle only: runs the latest absorbed trigger when the interval ends
+}
+
+// RateLimitedNode is implemented by nodes that coalesce rapid triggers, e.g. many webhook
+// hits. The engine hands the triggers of the node to its timer service, and only runs the
+// node when the rate limit lets a trigger through.
+type RateLimitedNode interface {
+	RateLimit() RateLimit
+}
+
 // Logger interface for node execution logging
 type Logger interface {
 	Opts(map[string]interface{})
@@ -60,6 +152,11 @@ type ExecutionContext interface {
 	SetOutputValue(pinID string, value types.Value)
 	IsInputPinActive(pinID string) bool
 
+	// GetUpstreamOutput returns the latest output value of a node upstream of this one in
+	// the execution graph, i.e. one this node is reachable from over connections of any
+	// kind. The read is recorded as a data dependency for value lineage.
+	GetUpstreamOutput(nodeID, pinID string) (types.Value, bool)
+
 	// Execution control
 	ActivateOutputFlow(pinID string) error
 
@@ -85,6 +182,11 @@ type ExecutionContext interface {
 	GetBlueprintID() string
 	GetExecutionID() string
 
+	// Context returns the context of the execution, done once the execution is cancelled or
+	// exceeds its duration limit. Nodes pass it to their outbound calls, e.g. HTTP requests
+	// and database queries, so that those stop with the execution.
+	Context() context.Context
+
 	// CreateLoopContext creates a specialized context for loop iterations (if supported)
 	// Returns the LoopContext and a boolean indicating if loop context is supported/created.
 	CreateLoopContext(loopVarName string, maxIterations int, startIndex float64) (LoopContext, bool)
@@ -109,6 +211,8 @@ type ExecutionHooks struct {
 	OnNodeError    func(nodeID string, err error)
 	OnPinValue     func(nodeID, pinName string, value interface{})
 	OnLog          func(nodeID, message string)
+
+	OnVariableChange func(name string, value interface{})
 }
 
 // ActivationAwareContext is an interface for checking input pin activation
@@ -0,0 +1,144 @@
+package node
+
+import (
+	"fmt"
+	"webblueprint/internal/types"
+)
+
+// Editors of node properties, hinting the widget the UI shows for them
+const (
+	PropertyEditorText      = "text"
+	PropertyEditorMultiline = "multiline"
+	PropertyEditorNumber    = "number"
+	PropertyEditorBoolean   = "boolean"
+	PropertyEditorSelect    = "select"
+	PropertyEditorJSON      = "json"
+)
+
+// PropertySchema describes how a node property is edited and which values it accepts. Zero
+// fields don't constrain the property.
+type PropertySchema struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`        // Pin type ID of the value
+	Editor      string   `json:"editor,omitempty"`      // One of the PropertyEditor constants
+	Enum        []string `json:"enum,omitempty"`        // Values the property can take
+	Min         *float64 `json:"min,omitempty"`         // Lowest number the property can take
+	Max         *float64 `json:"max,omitempty"`         // Highest number the property can take
+	Step        float64  `json:"step,omitempty"`        // Increment of number editors
+	Required    bool     `json:"required,omitempty"`    // Whether the property must be set
+	Secret      bool     `json:"secret,omitempty"`      // Whether the value is masked in editors
+	Placeholder string   `json:"placeholder,omitempty"` // Hint shown while the property is empty
+}
+
+// PropertySchemas returns the schema of each property of a node. Schemas are derived from
+// the type and options of the properties, then refined by the ones the node declares in its
+// metadata.
+func PropertySchemas(n Node) []PropertySchema {
+	declared := make(map[string]PropertySchema)
+	for _, schema := range n.GetMetadata().PropertySchema {
+		declared[schema.Name] = schema
+	}
+
+	properties := n.GetProperties()
+	schemas := make([]PropertySchema, 0, len(properties))
+	for _, property := range properties {
+		derived := derivePropertySchema(property)
+		schema, exists := declared[property.Name]
+		if !exists {
+			schemas = append(schemas, derived)
+			continue
+		}
+		delete(declared, property.Name)
+
+		if schema.Type == "" {
+			schema.Type = derived.Type
+		}
+		if len(schema.Enum) == 0 {
+			schema.Enum = derived.Enum
+		}
+		if schema.Editor == "" {
+			schema.Editor = derived.Editor
+			if len(schema.Enum) > 0 {
+				schema.Editor = PropertyEditorSelect
+			}
+		}
+		schemas = append(schemas, schema)
+	}
+
+	// Keep the schemas of properties the node only declares in its metadata
+	for _, schema := range n.GetMetadata().PropertySchema {
+		if _, exists := declared[schema.Name]; exists {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// derivePropertySchema derives the schema of a property from its type and options
+func derivePropertySchema(property types.Property) PropertySchema {
+	schema := PropertySchema{Name: property.Name, Editor: PropertyEditorText}
+	if property.Type != nil {
+		schema.Type = property.Type.ID
+		switch property.Type.ID {
+		case types.PinTypes.Number.ID:
+			schema.Editor = PropertyEditorNumber
+		case types.PinTypes.Boolean.ID:
+			schema.Editor = PropertyEditorBoolean
+		case types.PinTypes.Object.ID, types.PinTypes.Array.ID:
+			schema.Editor = PropertyEditorJSON
+		}
+	}
+	if len(property.Options) > 0 {
+		schema.Enum = property.Options
+		schema.Editor = PropertyEditorSelect
+	}
+	return schema
+}
+
+// Validate checks a value of the property against the schema
+func (s PropertySchema) Validate(value interface{}) error {
+	if value == nil || value == "" {
+		if s.Required {
+			return fmt.Errorf("property %s is required", s.Name)
+		}
+		return nil
+	}
+
+	if pinType, exists := types.GetPinTypeByID(s.Type); exists && pinType.Converter != nil {
+		converted, err := pinType.Converter(value)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", s.Name, err)
+		}
+		value = converted
+	}
+
+	if len(s.Enum) > 0 {
+		text := fmt.Sprintf("%v", value)
+		allowed := false
+		for _, option := range s.Enum {
+			if option == text {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("property %s must be one of %v, got %q", s.Name, s.Enum, text)
+		}
+	}
+
+	if s.Min == nil && s.Max == nil {
+		return nil
+	}
+	converted, err := types.PinTypes.Number.Converter(value)
+	if err != nil {
+		return fmt.Errorf("property %s: %w", s.Name, err)
+	}
+	number := converted.(float64)
+	if s.Min != nil && number < *s.Min {
+		return fmt.Errorf("property %s must be at least %v, got %v", s.Name, *s.Min, number)
+	}
+	if s.Max != nil && number > *s.Max {
+		return fmt.Errorf("property %s must be at most %v, got %v", s.Name, *s.Max, number)
+	}
+	return nil
+}
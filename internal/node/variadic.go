@@ -0,0 +1,98 @@
+package node
+
+import (
+	"fmt"
+	"strconv"
+	"webblueprint/internal/types"
+)
+
+// VariadicPins describes a group of numbered pins whose count comes from the data of the
+// blueprint node, e.g. the values of a merge node or the arguments of a format node. The
+// pins are named Prefix1, Prefix2, ... and are optional.
+type VariadicPins struct {
+	Prefix      string         `json:"prefix"`         // Pin IDs are the prefix followed by the number
+	Name        string         `json:"name"`           // Pin names are the name followed by the number
+	Description string         `json:"description"`    // Description of every pin of the group
+	Type        *types.PinType `json:"-"`              // Type of every pin of the group
+	Output      bool           `json:"output"`         // Whether the pins are outputs
+	CountKey    string         `json:"countKey"`       // Key of the number of pins in the node data
+	Default     int            `json:"default"`        // Number of pins when the data doesn't set it
+	Min         int            `json:"min"`            // Lowest number of pins
+	Max         int            `json:"max,omitempty"`  // Highest number of pins, unbounded when 0
+	TypeID      string         `json:"type,omitempty"` // Set from Type when the group is described
+}
+
+// VariadicPinNode is implemented by nodes with groups of variadic pins. They also implement
+// DynamicPinNode, declaring the pins of each group from the node data.
+type VariadicPinNode interface {
+	DynamicPinNode
+	VariadicPins() []VariadicPins
+}
+
+// DescribeVariadicPins returns the variadic pin groups of a node for clients, or nil if it
+// has none
+func DescribeVariadicPins(n Node) []VariadicPins {
+	variadic, ok := n.(VariadicPinNode)
+	if !ok {
+		return nil
+	}
+	groups := variadic.VariadicPins()
+	for i := range groups {
+		if groups[i].Type != nil {
+			groups[i].TypeID = groups[i].Type.ID
+		}
+	}
+	return groups
+}
+
+// Count reads the number of pins of the group from the node data
+func (v VariadicPins) Count(data map[string]interface{}) (int, error) {
+	count := v.Default
+	if raw, exists := data[v.CountKey]; exists && raw != nil {
+		switch value := raw.(type) {
+		case int:
+			count = value
+		case float64:
+			if value != float64(int(value)) {
+				return 0, fmt.Errorf("%s must be a whole number, got %v", v.CountKey, raw)
+			}
+			count = int(value)
+		case string:
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return 0, fmt.Errorf("%s must be a whole number, got %v", v.CountKey, raw)
+			}
+			count = parsed
+		default:
+			return 0, fmt.Errorf("%s must be a whole number, got %v", v.CountKey, raw)
+		}
+	}
+
+	if count < v.Min {
+		return 0, fmt.Errorf("%s must be at least %d, got %d", v.CountKey, v.Min, count)
+	}
+	if v.Max > 0 && count > v.Max {
+		return 0, fmt.Errorf("%s must be at most %d, got %d", v.CountKey, v.Max, count)
+	}
+	return count, nil
+}
+
+// Pins returns the pins of the group for a number of pins
+func (v VariadicPins) Pins(count int) []types.Pin {
+	pins := make([]types.Pin, 0, count)
+	for i := 1; i <= count; i++ {
+		pins = append(pins, types.Pin{
+			ID:          v.PinID(i),
+			Name:        fmt.Sprintf("%s %d", v.Name, i),
+			Description: v.Description,
+			Type:        v.Type,
+			Optional:    true,
+		})
+	}
+	return pins
+}
+
+// PinID returns the ID of the pin of the group with the given number, from 1
+func (v VariadicPins) PinID(number int) string {
+	return v.Prefix + strconv.Itoa(number)
+}
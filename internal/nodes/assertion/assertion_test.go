@@ -0,0 +1,82 @@
+package assertion_test
+
+import (
+	"testing"
+	"webblueprint/internal/bptest"
+	"webblueprint/internal/nodes/assertion"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
+)
+
+func TestExpectEqualNode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		actual   interface{}
+		expected interface{}
+		passed   bool
+	}{
+		{"equal strings", "hello", "hello", true},
+		{"different strings", "hello", "world", false},
+		{"numbers of different types", 42, 42.0, true},
+		{"equal objects", map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1.0}, true},
+		{"nil and value", nil, "value", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := mocks.NewMockExecutionContext("assert", "expect-equal", mocks.NewMockLogger())
+			ctx.SetInputValue("actual", types.NewValue(types.PinTypes.Any, tc.actual))
+			ctx.SetInputValue("expected", types.NewValue(types.PinTypes.Any, tc.expected))
+
+			if err := assertion.NewExpectEqualNode().Execute(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			results := bptest.Collect(ctx.GetExecutionID())
+			if len(results) != 1 {
+				t.Fatalf("expected 1 assertion result, got %d", len(results))
+			}
+			if results[0].Passed != tc.passed {
+				t.Errorf("expected passed=%v, got %v (%s)", tc.passed, results[0].Passed, results[0].Message)
+			}
+			if ctx.GetActivatedFlow() != "then" {
+				t.Errorf("expected then flow to be activated, got %q", ctx.GetActivatedFlow())
+			}
+		})
+	}
+}
+
+func TestExpectErrorNode(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    interface{}
+		code   string
+		passed bool
+	}{
+		{"no error", nil, "", false},
+		{"any error", map[string]interface{}{"code": "E001"}, "", true},
+		{"matching code", map[string]interface{}{"code": "E001"}, "E001", true},
+		{"different code", map[string]interface{}{"code": "E002"}, "E001", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := mocks.NewMockExecutionContext("assert", "expect-error", mocks.NewMockLogger())
+			if tc.err != nil {
+				ctx.SetInputValue("error", types.NewValue(types.PinTypes.Object, tc.err))
+			}
+			if tc.code != "" {
+				ctx.SetInputValue("code", types.NewValue(types.PinTypes.String, tc.code))
+			}
+
+			if err := assertion.NewExpectErrorNode().Execute(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			results := bptest.Collect(ctx.GetExecutionID())
+			if len(results) != 1 || results[0].Passed != tc.passed {
+				t.Errorf("expected one result with passed=%v, got %+v", tc.passed, results)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package assertion
+
+import (
+	"time"
+	"webblueprint/internal/bptest"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// ExpectCalledNode implements an assertion that a flow was reached.
+// Each execution records a call; the test runner compares the number of
+// calls to the times property once the test execution is done.
+type ExpectCalledNode struct {
+	node.BaseNode
+}
+
+// minCalls is the lowest number of calls the times property can expect
+var minCalls = 0.0
+
+// NewExpectCalledNode creates a new Expect Called node
+func NewExpectCalledNode() node.Node {
+	return &ExpectCalledNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      string(bptest.KindCalled),
+				Name:        "Expect Called",
+				Description: "Asserts that the connected flow is reached during a test run",
+				Category:    "Testing",
+				Version:     "1.0.0",
+				PropertySchema: []node.PropertySchema{
+					{Name: "times", Min: &minCalls, Step: 1},
+				},
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues",
+					Type:        types.PinTypes.Execution,
+				},
+			},
+			Properties: []types.Property{
+				{
+					Name:        "times",
+					Description: "Exact number of expected calls; at least once when empty",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					Name:        "label",
+					Description: "Label reported with the assertion",
+					Value:       "",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *ExpectCalledNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Expect Called node", nil)
+
+	bptest.Record(ctx.GetExecutionID(), bptest.AssertionResult{
+		NodeID:  ctx.GetNodeID(),
+		Kind:    bptest.KindCalled,
+		Passed:  true,
+		Message: propertyString(&n.BaseNode, "label"),
+	})
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Expect Called",
+		Value: map[string]interface{}{
+			"called": true,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
@@ -0,0 +1,167 @@
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+	"webblueprint/internal/bptest"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// ExpectEqualNode implements an assertion that two values are equal
+type ExpectEqualNode struct {
+	node.BaseNode
+}
+
+// NewExpectEqualNode creates a new Expect Equal node
+func NewExpectEqualNode() node.Node {
+	return &ExpectEqualNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      string(bptest.KindEqual),
+				Name:        "Expect Equal",
+				Description: "Asserts that a value equals the expected value during a test run",
+				Category:    "Testing",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "actual",
+					Name:        "Actual",
+					Description: "Value to check",
+					Type:        types.PinTypes.Any,
+					Optional:    true,
+				},
+				{
+					ID:          "expected",
+					Name:        "Expected",
+					Description: "Expected value, defaults to the expected property",
+					Type:        types.PinTypes.Any,
+					Optional:    true,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Executed after the assertion, whether it passed or not",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "passed",
+					Name:        "Passed",
+					Description: "Whether the assertion passed",
+					Type:        types.PinTypes.Boolean,
+				},
+			},
+			Properties: []types.Property{
+				{
+					Name:        "expected",
+					Description: "Expected value when the expected pin isn't connected",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					Name:        "message",
+					Description: "Message reported when the assertion fails",
+					Value:       "",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *ExpectEqualNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Expect Equal node", nil)
+
+	var actual interface{}
+	if actualValue, exists := ctx.GetInputValue("actual"); exists {
+		actual = actualValue.RawValue
+	}
+
+	expected := propertyValue(&n.BaseNode, "expected")
+	if expectedValue, exists := ctx.GetInputValue("expected"); exists {
+		expected = expectedValue.RawValue
+	}
+
+	passed := ValuesEqual(expected, actual)
+	message := ""
+	if !passed {
+		message = propertyString(&n.BaseNode, "message")
+		if message == "" {
+			message = fmt.Sprintf("expected %v, got %v", expected, actual)
+		}
+	}
+
+	bptest.Record(ctx.GetExecutionID(), bptest.AssertionResult{
+		NodeID:   ctx.GetNodeID(),
+		Kind:     bptest.KindEqual,
+		Passed:   passed,
+		Message:  message,
+		Expected: expected,
+		Actual:   actual,
+	})
+
+	ctx.SetOutputValue("passed", types.NewValue(types.PinTypes.Boolean, passed))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Expect Equal",
+		Value: map[string]interface{}{
+			"expected": expected,
+			"actual":   actual,
+			"passed":   passed,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// ValuesEqual compares two pin values structurally. Values are normalized through
+// JSON first, so numbers of different Go types and equivalent maps compare equal.
+func ValuesEqual(expected, actual interface{}) bool {
+	return reflect.DeepEqual(normalizeValue(expected), normalizeValue(actual))
+}
+
+// normalizeValue converts a value to its JSON representation
+func normalizeValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// propertyValue returns the value of a node property
+func propertyValue(n *node.BaseNode, name string) interface{} {
+	for _, property := range n.Properties {
+		if property.Name == name {
+			return property.Value
+		}
+	}
+	return nil
+}
+
+// propertyString returns the value of a node property as a string
+func propertyString(n *node.BaseNode, name string) string {
+	if value, ok := propertyValue(n, name).(string); ok {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/bptest"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// ExpectErrorNode implements an assertion that an error was produced
+type ExpectErrorNode struct {
+	node.BaseNode
+}
+
+// NewExpectErrorNode creates a new Expect Error node
+func NewExpectErrorNode() node.Node {
+	return &ExpectErrorNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      string(bptest.KindError),
+				Name:        "Expect Error",
+				Description: "Asserts that an error pin carries an error during a test run",
+				Category:    "Testing",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "error",
+					Name:        "Error",
+					Description: "Error output of the node under test",
+					Type:        types.PinTypes.Any,
+					Optional:    true,
+				},
+				{
+					ID:          "code",
+					Name:        "Code",
+					Description: "Expected error code, defaults to the code property",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Executed after the assertion, whether it passed or not",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "passed",
+					Name:        "Passed",
+					Description: "Whether the assertion passed",
+					Type:        types.PinTypes.Boolean,
+				},
+			},
+			Properties: []types.Property{
+				{
+					Name:        "code",
+					Description: "Expected error code; any error passes when empty",
+					Value:       "",
+					Type:        types.PinTypes.String,
+				},
+				{
+					Name:        "message",
+					Description: "Message reported when the assertion fails",
+					Value:       "",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *ExpectErrorNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Expect Error node", nil)
+
+	var actual interface{}
+	if errorValue, exists := ctx.GetInputValue("error"); exists {
+		actual = errorValue.RawValue
+	}
+
+	expectedCode := propertyString(&n.BaseNode, "code")
+	if codeValue, exists := ctx.GetInputValue("code"); exists {
+		if code, err := codeValue.AsString(); err == nil && code != "" {
+			expectedCode = code
+		}
+	}
+
+	passed := actual != nil
+	failure := "expected an error, got none"
+	if passed && expectedCode != "" {
+		actualCode := errorCode(actual)
+		passed = actualCode == expectedCode
+		failure = fmt.Sprintf("expected error code %s, got %q", expectedCode, actualCode)
+	}
+
+	message := ""
+	if !passed {
+		message = propertyString(&n.BaseNode, "message")
+		if message == "" {
+			message = failure
+		}
+	}
+
+	var expected interface{}
+	if expectedCode != "" {
+		expected = expectedCode
+	}
+
+	bptest.Record(ctx.GetExecutionID(), bptest.AssertionResult{
+		NodeID:   ctx.GetNodeID(),
+		Kind:     bptest.KindError,
+		Passed:   passed,
+		Message:  message,
+		Expected: expected,
+		Actual:   actual,
+	})
+
+	ctx.SetOutputValue("passed", types.NewValue(types.PinTypes.Boolean, passed))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Expect Error",
+		Value: map[string]interface{}{
+			"error":        actual,
+			"expectedCode": expectedCode,
+			"passed":       passed,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// errorCode extracts the code of an error value, which nodes output
+// on their error pin as an object with a "code" field
+func errorCode(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if code, ok := v["code"]; ok {
+			return fmt.Sprintf("%v", code)
+		}
+	case error:
+		var bpErr *bperrors.BlueprintError
+		if errors.As(v, &bpErr) {
+			return string(bpErr.Code)
+		}
+		return v.Error()
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+// Package convert provides nodes that convert units and currencies, measure distances
+// between coordinates and geocode addresses. Currency rates and geocoding come from
+// pluggable providers the server configures.
+package convert
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// flowPins returns the output flows of the convert nodes
+func flowPins(catchDescription string) []types.Pin {
+	return []types.Pin{
+		{
+			ID:          "then",
+			Name:        "Then",
+			Description: "Execution continues",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          bperrors.CatchPinID,
+			Name:        "Catch",
+			Description: catchDescription,
+			Type:        types.PinTypes.Execution,
+		},
+		bperrors.ErrorPin(),
+	}
+}
+
+// numberInput reads a number input, the fallback if it's optional and not set
+func numberInput(ctx node.ExecutionContext, pinID string, fallback *float64) (float64, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.RawValue == nil {
+		if fallback == nil {
+			return 0, bperrors.MissingInput(pinID)
+		}
+		return *fallback, nil
+	}
+	number, err := value.AsNumber()
+	if err != nil {
+		return 0, bperrors.InvalidInput(pinID, err)
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, bperrors.InvalidInput(pinID, fmt.Errorf("%v is not a finite number", number))
+	}
+	return number, nil
+}
+
+// stringInput reads a string input, the fallback if it's empty
+func stringInput(ctx node.ExecutionContext, pinID, fallback string) (string, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.RawValue == nil {
+		if fallback == "" {
+			return "", bperrors.MissingInput(pinID)
+		}
+		return fallback, nil
+	}
+	text, err := value.AsString()
+	if err != nil {
+		return "", bperrors.InvalidInput(pinID, err)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if fallback == "" {
+			return "", bperrors.MissingInput(pinID)
+		}
+		return fallback, nil
+	}
+	return text, nil
+}
+
+// fail reports an error on the error output and continues on the catch flow
+func fail(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow(bperrors.CatchPinID)
+}
@@ -0,0 +1,240 @@
+package convert_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webblueprint/internal/nodes/convert"
+	"webblueprint/internal/test"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
+)
+
+func TestConvertUnitNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name:            "kilometers to miles",
+			Inputs:          map[string]interface{}{"value": 1.609344, "from": "km", "to": "mi"},
+			ExpectedOutputs: map[string]interface{}{"result": 1.0, "dimension": "length"},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "pounds to kilograms",
+			Inputs:          map[string]interface{}{"value": 2, "from": "lb", "to": "Kilograms"},
+			ExpectedOutputs: map[string]interface{}{"result": 0.90718474, "dimension": "weight"},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "celsius to fahrenheit",
+			Inputs:          map[string]interface{}{"value": 100, "from": "celsius", "to": "F"},
+			ExpectedOutputs: map[string]interface{}{"result": 212.0, "dimension": "temperature"},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:         "different dimensions",
+			Inputs:       map[string]interface{}{"value": 1, "from": "kg", "to": "m"},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "unknown unit",
+			Inputs:       map[string]interface{}{"value": 1, "from": "parsec", "to": "m"},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "missing value",
+			Inputs:       map[string]interface{}{"from": "m", "to": "km"},
+			ExpectedFlow: "catch",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, convert.NewConvertUnitNode(), tc)
+		})
+	}
+
+	// Fahrenheit and Celsius meet at -40, through kelvin
+	ctx := mocks.NewMockExecutionContext("convert", "convert-unit", mocks.NewMockLogger())
+	ctx.SetInputValue("value", types.NewValue(types.PinTypes.Number, -40.0))
+	ctx.SetInputValue("from", types.NewValue(types.PinTypes.String, "f"))
+	ctx.SetInputValue("to", types.NewValue(types.PinTypes.String, "c"))
+	convert.NewConvertUnitNode().Execute(ctx)
+	if result, _ := ctx.GetOutputValue("result"); math.Abs(result.RawValue.(float64)+40) > 1e-9 {
+		t.Errorf("Expected -40°F to be -40°C, got %v", result.RawValue)
+	}
+}
+
+func TestConvertCurrencyNode(t *testing.T) {
+	convert.SetDefaultRates(nil)
+	test.ExecuteNodeTestCase(t, convert.NewConvertCurrencyNode(), test.NodeTestCase{
+		Name:         "no provider",
+		Inputs:       map[string]interface{}{"amount": 10, "from": "USD", "to": "EUR"},
+		ExpectedFlow: "catch",
+	})
+
+	convert.SetDefaultRates(convert.StaticRates{Base: "EUR", Rates: map[string]float64{"USD": 1.25, "GBP": 0.8}})
+	defer convert.SetDefaultRates(nil)
+
+	testCases := []test.NodeTestCase{
+		{
+			Name:            "from the base",
+			Inputs:          map[string]interface{}{"amount": 10, "from": "eur", "to": "usd"},
+			ExpectedOutputs: map[string]interface{}{"result": 12.5, "rate": 1.25},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "cross rate",
+			Inputs:          map[string]interface{}{"amount": 10, "from": "USD", "to": "GBP"},
+			ExpectedOutputs: map[string]interface{}{"result": 6.4, "rate": 0.64},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "rounding",
+			Inputs:          map[string]interface{}{"amount": 1, "from": "GBP", "to": "USD", "decimals": 0},
+			ExpectedOutputs: map[string]interface{}{"result": 2.0},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:         "unknown currency",
+			Inputs:       map[string]interface{}{"amount": 1, "from": "XYZ", "to": "USD"},
+			ExpectedFlow: "catch",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, convert.NewConvertCurrencyNode(), tc)
+		})
+	}
+}
+
+func TestHTTPRates(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"base_code": "USD", "rates": {"USD": 1, "EUR": 0.5}}`))
+	}))
+	defer server.Close()
+
+	rates := convert.NewHTTPRates(server.URL, time.Hour)
+	for i := 0; i < 2; i++ {
+		rate, err := rates.Rate(context.Background(), "EUR", "USD")
+		if err != nil || rate != 2 {
+			t.Fatalf("Expected a rate of 2, got %v, %v", rate, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the rates to be cached, got %d requests", requests)
+	}
+
+	var unknown *convert.UnknownCurrencyError
+	if _, err := rates.Rate(context.Background(), "EUR", "JPY"); !errors.As(err, &unknown) || unknown.Currency != "JPY" {
+		t.Errorf("Expected JPY to be unknown, got %v", err)
+	}
+}
+
+func TestDistanceNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name:            "same point",
+			Inputs:          map[string]interface{}{"from": []interface{}{52.52, 13.405}, "to": map[string]interface{}{"lat": 52.52, "lng": 13.405}},
+			ExpectedOutputs: map[string]interface{}{"distance": 0.0},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:         "latitude out of range",
+			Inputs:       map[string]interface{}{"from": []interface{}{91, 0}, "to": []interface{}{0, 0}},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "not a point",
+			Inputs:       map[string]interface{}{"from": "Berlin", "to": []interface{}{0, 0}},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "unknown unit",
+			Inputs:       map[string]interface{}{"from": []interface{}{0, 0}, "to": []interface{}{0, 1}, "unit": "league"},
+			ExpectedFlow: "catch",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, convert.NewDistanceNode(), tc)
+		})
+	}
+
+	distance := func(unit string) float64 {
+		ctx := mocks.NewMockExecutionContext("distance", "geo-distance", mocks.NewMockLogger())
+		// Berlin to Paris, about 878 km
+		ctx.SetInputValue("from", types.NewValue(types.PinTypes.Object, map[string]interface{}{"latitude": 52.5200, "longitude": 13.4050}))
+		ctx.SetInputValue("to", types.NewValue(types.PinTypes.Object, map[string]interface{}{"lat": "48.8566", "lon": "2.3522"}))
+		ctx.SetInputValue("unit", types.NewValue(types.PinTypes.String, unit))
+		convert.NewDistanceNode().Execute(ctx)
+		value, _ := ctx.GetOutputValue("distance")
+		return value.RawValue.(float64)
+	}
+	if km := distance("km"); math.Abs(km-878) > 2 {
+		t.Errorf("Expected about 878 km, got %v", km)
+	}
+	if mi := distance("mi"); math.Abs(mi-545.6) > 2 {
+		t.Errorf("Expected about 545.6 mi, got %v", mi)
+	}
+}
+
+func TestGeocodeNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "webblueprint-test" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Query().Get("q") {
+		case "Brandenburger Tor":
+			w.Write([]byte(`[{"lat": "52.5162746", "lon": "13.3777041", "display_name": "Brandenburger Tor, Berlin"}]`))
+		case "fail":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	convert.SetDefaultGeocoder(convert.NewNominatimGeocoder(server.URL+"/search", "webblueprint-test"))
+	defer convert.SetDefaultGeocoder(nil)
+
+	testCases := []test.NodeTestCase{
+		{
+			Name:   "found",
+			Inputs: map[string]interface{}{"address": "Brandenburger Tor"},
+			ExpectedOutputs: map[string]interface{}{
+				"latitude":  52.5162746,
+				"longitude": 13.3777041,
+				"location":  map[string]interface{}{"latitude": 52.5162746, "longitude": 13.3777041, "address": "Brandenburger Tor, Berlin"},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name:         "not found",
+			Inputs:       map[string]interface{}{"address": "Atlantis"},
+			ExpectedFlow: "notFound",
+		},
+		{
+			Name:         "geocoder fails",
+			Inputs:       map[string]interface{}{"address": "fail"},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "empty address",
+			Inputs:       map[string]interface{}{"address": " "},
+			ExpectedFlow: "catch",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, convert.NewGeocodeNode(), tc)
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// UnknownCurrencyError is returned by rate providers without a rate for a currency
+type UnknownCurrencyError struct {
+	Currency string
+}
+
+func (e *UnknownCurrencyError) Error() string {
+	return fmt.Sprintf("unknown currency %q", e.Currency)
+}
+
+// RateProvider provides exchange rates. Rate returns how many units of the to currency
+// one unit of the from currency buys; the codes are upper-case ISO 4217 codes.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// StaticRates are fixed exchange rates against a base currency, e.g. for tests or for
+// rates a business sets itself
+type StaticRates struct {
+	Base  string
+	Rates map[string]float64 // Currency -> units of it one unit of the base buys
+}
+
+// Rate returns the rate between two currencies, crossing through the base
+func (s StaticRates) Rate(_ context.Context, from, to string) (float64, error) {
+	fromRate, err := s.rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := s.rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+// rate returns the rate of a currency against the base
+func (s StaticRates) rate(currency string) (float64, error) {
+	if strings.EqualFold(currency, s.Base) {
+		return 1, nil
+	}
+	rate, exists := s.Rates[strings.ToUpper(currency)]
+	if !exists || rate <= 0 {
+		return 0, &UnknownCurrencyError{Currency: currency}
+	}
+	return rate, nil
+}
+
+// HTTPRates fetches the exchange rates from a JSON endpoint and caches them. The endpoint
+// returns its base currency and the rates against it, as {"base": "EUR", "rates": {"USD":
+// 1.08}}; base_code is accepted for base, as returned by some providers.
+type HTTPRates struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mutex     sync.Mutex
+	rates     StaticRates
+	fetchedAt time.Time
+}
+
+// NewHTTPRates creates a provider fetching the rates from the URL, at most once per TTL
+func NewHTTPRates(url string, ttl time.Duration) *HTTPRates {
+	return &HTTPRates{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Rate returns the rate between two currencies, fetching the rates if they're stale
+func (h *HTTPRates) Rate(ctx context.Context, from, to string) (float64, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.fetchedAt.IsZero() || time.Since(h.fetchedAt) > h.ttl {
+		rates, err := h.fetch(ctx)
+		if err != nil {
+			return 0, err
+		}
+		h.rates = rates
+		h.fetchedAt = time.Now()
+	}
+	return h.rates.Rate(ctx, from, to)
+}
+
+// fetch requests the current rates
+func (h *HTTPRates) fetch(ctx context.Context) (StaticRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return StaticRates{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return StaticRates{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return StaticRates{}, fmt.Errorf("rates endpoint answered %s", resp.Status)
+	}
+
+	var body struct {
+		Base     string             `json:"base"`
+		BaseCode string             `json:"base_code"`
+		Rates    map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return StaticRates{}, fmt.Errorf("invalid rates: %w", err)
+	}
+	if body.Base == "" {
+		body.Base = body.BaseCode
+	}
+	if body.Base == "" || len(body.Rates) == 0 {
+		return StaticRates{}, errors.New("invalid rates: base or rates missing")
+	}
+	return StaticRates{Base: strings.ToUpper(body.Base), Rates: body.Rates}, nil
+}
+
+// defaultRates is the rate provider of the convert-currency node; there's none until the
+// server configures one
+var defaultRates RateProvider
+var defaultRatesMutex sync.RWMutex
+
+// DefaultRates returns the rate provider the convert-currency node uses, nil if none is
+// configured
+func DefaultRates() RateProvider {
+	defaultRatesMutex.RLock()
+	defer defaultRatesMutex.RUnlock()
+	return defaultRates
+}
+
+// SetDefaultRates replaces the rate provider the convert-currency node uses
+func SetDefaultRates(provider RateProvider) {
+	defaultRatesMutex.Lock()
+	defer defaultRatesMutex.Unlock()
+	defaultRates = provider
+}
+
+// ConvertCurrencyNode converts an amount between currencies with the rates of the
+// configured provider
+type ConvertCurrencyNode struct {
+	node.BaseNode
+}
+
+// NewConvertCurrencyNode creates a new Convert Currency node
+func NewConvertCurrencyNode() node.Node {
+	return &ConvertCurrencyNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "convert-currency",
+				Name:        "Convert Currency",
+				Description: "Converts an amount between currencies using the configured exchange rates",
+				Category:    "Convert",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "amount",
+					Name:        "Amount",
+					Description: "Amount to convert",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "from",
+					Name:        "From",
+					Description: "Currency of the amount, e.g. USD",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "to",
+					Name:        "To",
+					Description: "Currency to convert to, e.g. EUR",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "decimals",
+					Name:        "Decimals",
+					Description: "Decimals to round the result to, -1 to not round",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     2,
+				},
+			},
+			Outputs: append(flowPins("Executed if no rate is available for the currencies"),
+				types.Pin{
+					ID:          "result",
+					Name:        "Result",
+					Description: "Converted amount",
+					Type:        types.PinTypes.Number,
+				},
+				types.Pin{
+					ID:          "rate",
+					Name:        "Rate",
+					Description: "Units of the to currency one unit of the from currency buys",
+					Type:        types.PinTypes.Number,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that rates change, so replays reuse the recorded result
+func (n *ConvertCurrencyNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *ConvertCurrencyNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Convert Currency node", nil)
+
+	amount, bpErr := numberInput(ctx, "amount", nil)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	from, bpErr := stringInput(ctx, "from", "")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	to, bpErr := stringInput(ctx, "to", "")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	defaultDecimals := 2.0
+	decimals, bpErr := numberInput(ctx, "decimals", &defaultDecimals)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+
+	provider := DefaultRates()
+	if provider == nil {
+		return fail(ctx, bperrors.New(bperrors.ErrorTypeSystem, bperrors.ErrServiceUnavailable, "no exchange rate provider is configured", bperrors.SeverityHigh))
+	}
+
+	rate := 1.0
+	if from != to {
+		rateCtx, cancel := context.WithTimeout(ctx.Context(), 15*time.Second)
+		defer cancel()
+
+		var err error
+		rate, err = provider.Rate(rateCtx, from, to)
+		var unknown *UnknownCurrencyError
+		if errors.As(err, &unknown) {
+			pinID := "to"
+			if strings.EqualFold(unknown.Currency, from) {
+				pinID = "from"
+			}
+			return fail(ctx, bperrors.InvalidInput(pinID, err))
+		}
+		if err != nil {
+			return fail(ctx, bperrors.RequestFailed("failed to get the exchange rate", err))
+		}
+	}
+
+	result := amount * rate
+	if decimals >= 0 {
+		scale := math.Pow(10, math.Floor(decimals))
+		result = math.Round(result*scale) / scale
+	}
+	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.Number, result))
+	ctx.SetOutputValue("rate", types.NewValue(types.PinTypes.Number, rate))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Convert Currency",
+		Value: map[string]interface{}{
+			"amount": amount,
+			"from":   from,
+			"to":     to,
+			"rate":   rate,
+			"result": result,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
@@ -0,0 +1,426 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371008.8
+
+// distanceUnits are the units the geo-distance node reports in, in meters
+var distanceUnits = map[string]float64{
+	"m":   1,
+	"km":  1000,
+	"mi":  1609.344,
+	"nmi": 1852,
+}
+
+// Location is a point on the earth
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Address   string  `json:"address,omitempty"` // Address of the point, as the geocoder formats it
+}
+
+// parseLocation reads a point given as {lat, lon}, {latitude, longitude}, {lat, lng} or
+// [lat, lon]
+func parseLocation(pinID string, value types.Value) (Location, *bperrors.BlueprintError) {
+	var latitude, longitude interface{}
+	switch raw := value.RawValue.(type) {
+	case map[string]interface{}:
+		latitude = firstOf(raw, "lat", "latitude")
+		longitude = firstOf(raw, "lon", "lng", "longitude")
+	case []interface{}:
+		if len(raw) == 2 {
+			latitude, longitude = raw[0], raw[1]
+		}
+	case []float64:
+		if len(raw) == 2 {
+			latitude, longitude = raw[0], raw[1]
+		}
+	}
+	if latitude == nil || longitude == nil {
+		return Location{}, bperrors.InvalidInput(pinID, errors.New("expected {lat, lon} or [lat, lon]"))
+	}
+
+	lat, err := types.NewValue(types.PinTypes.Any, latitude).AsNumber()
+	if err != nil || lat < -90 || lat > 90 {
+		return Location{}, bperrors.InvalidInput(pinID, fmt.Errorf("latitude must be between -90 and 90, got %v", latitude))
+	}
+	lon, err := types.NewValue(types.PinTypes.Any, longitude).AsNumber()
+	if err != nil || lon < -180 || lon > 180 {
+		return Location{}, bperrors.InvalidInput(pinID, fmt.Errorf("longitude must be between -180 and 180, got %v", longitude))
+	}
+	return Location{Latitude: lat, Longitude: lon}, nil
+}
+
+// firstOf returns the value of the first of the keys that's set
+func firstOf(object map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, exists := object[key]; exists && value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// haversine returns the great-circle distance between two points in meters
+func haversine(from, to Location) float64 {
+	lat1, lat2 := from.Latitude*math.Pi/180, to.Latitude*math.Pi/180
+	deltaLat := lat2 - lat1
+	deltaLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// DistanceNode measures the great-circle distance between two points with the haversine
+// formula
+type DistanceNode struct {
+	node.BaseNode
+}
+
+// NewDistanceNode creates a new Geo Distance node
+func NewDistanceNode() node.Node {
+	return &DistanceNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "geo-distance",
+				Name:        "Geo Distance",
+				Description: "Measures the distance between two coordinates along the surface of the earth",
+				Category:    "Convert",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "from",
+					Name:        "From",
+					Description: "First point, as {lat, lon} or [lat, lon]",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					ID:          "to",
+					Name:        "To",
+					Description: "Second point, as {lat, lon} or [lat, lon]",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					ID:          "unit",
+					Name:        "Unit",
+					Description: "Unit of the distance (km, m, mi, nmi)",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+					Default:     "km",
+				},
+			},
+			Outputs: append(flowPins("Executed if a point or the unit is invalid"),
+				types.Pin{
+					ID:          "distance",
+					Name:        "Distance",
+					Description: "Distance between the points",
+					Type:        types.PinTypes.Number,
+				},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *DistanceNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Geo Distance node", nil)
+
+	points := make([]Location, 0, 2)
+	for _, pinID := range []string{"from", "to"} {
+		value, exists := ctx.GetInputValue(pinID)
+		if !exists {
+			return fail(ctx, bperrors.MissingInput(pinID))
+		}
+		point, bpErr := parseLocation(pinID, value)
+		if bpErr != nil {
+			return fail(ctx, bpErr)
+		}
+		points = append(points, point)
+	}
+	unitName, bpErr := stringInput(ctx, "unit", "km")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	meters, exists := distanceUnits[strings.ToLower(unitName)]
+	if !exists {
+		return fail(ctx, bperrors.InvalidInput("unit", fmt.Errorf("unknown unit %q", unitName)))
+	}
+
+	distance := haversine(points[0], points[1]) / meters
+	ctx.SetOutputValue("distance", types.NewValue(types.PinTypes.Number, distance))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Geo Distance",
+		Value: map[string]interface{}{
+			"from":     points[0],
+			"to":       points[1],
+			"unit":     unitName,
+			"distance": distance,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// Geocoder finds the locations of an address, the best match first. It returns no
+// locations if the address is unknown.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) ([]Location, error)
+}
+
+// NominatimGeocoder geocodes with the search API of Nominatim, the geocoder of
+// OpenStreetMap, or any service compatible with it
+type NominatimGeocoder struct {
+	url       string
+	userAgent string
+	client    *http.Client
+}
+
+// NewNominatimGeocoder creates a geocoder calling the search endpoint at the URL, e.g.
+// https://nominatim.openstreetmap.org/search. Public instances require a user agent
+// identifying the application.
+func NewNominatimGeocoder(searchURL, userAgent string) *NominatimGeocoder {
+	return &NominatimGeocoder{
+		url:       searchURL,
+		userAgent: userAgent,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Geocode searches the address
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) ([]Location, error) {
+	endpoint, err := url.Parse(g.url)
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("q", address)
+	query.Set("format", "jsonv2")
+	query.Set("limit", "5")
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoder answered %s", resp.Status)
+	}
+
+	// Nominatim returns the coordinates as strings
+	var results []struct {
+		Latitude    string `json:"lat"`
+		Longitude   string `json:"lon"`
+		DisplayName string `json:"display_name"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&results); err != nil {
+		return nil, fmt.Errorf("invalid geocoder response: %w", err)
+	}
+
+	locations := make([]Location, 0, len(results))
+	for _, result := range results {
+		latitude, latErr := strconv.ParseFloat(result.Latitude, 64)
+		longitude, lonErr := strconv.ParseFloat(result.Longitude, 64)
+		if latErr != nil || lonErr != nil {
+			return nil, fmt.Errorf("invalid geocoder response: coordinates %q, %q", result.Latitude, result.Longitude)
+		}
+		locations = append(locations, Location{Latitude: latitude, Longitude: longitude, Address: result.DisplayName})
+	}
+	return locations, nil
+}
+
+// defaultGeocoder is the geocoder of the geocode node; there's none until the server
+// configures one
+var defaultGeocoder Geocoder
+var defaultGeocoderMutex sync.RWMutex
+
+// DefaultGeocoder returns the geocoder the geocode node uses, nil if none is configured
+func DefaultGeocoder() Geocoder {
+	defaultGeocoderMutex.RLock()
+	defer defaultGeocoderMutex.RUnlock()
+	return defaultGeocoder
+}
+
+// SetDefaultGeocoder replaces the geocoder the geocode node uses
+func SetDefaultGeocoder(geocoder Geocoder) {
+	defaultGeocoderMutex.Lock()
+	defer defaultGeocoderMutex.Unlock()
+	defaultGeocoder = geocoder
+}
+
+// GeocodeNode finds the coordinates of an address with the configured geocoder
+type GeocodeNode struct {
+	node.BaseNode
+}
+
+// NewGeocodeNode creates a new Geocode node
+func NewGeocodeNode() node.Node {
+	return &GeocodeNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "geocode",
+				Name:        "Geocode",
+				Description: "Finds the coordinates of an address using the configured geocoder",
+				Category:    "Convert",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "address",
+					Name:        "Address",
+					Description: "Address to find",
+					Type:        types.PinTypes.String,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Executed if the address is found",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "notFound",
+					Name:        "Not Found",
+					Description: "Executed if the geocoder doesn't know the address",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          bperrors.CatchPinID,
+					Name:        "Catch",
+					Description: "Executed if the geocoder can't be reached",
+					Type:        types.PinTypes.Execution,
+				},
+				bperrors.ErrorPin(),
+				{
+					ID:          "location",
+					Name:        "Location",
+					Description: "Best match, as {latitude, longitude, address}",
+					Type:        types.PinTypes.Object,
+				},
+				{
+					ID:          "latitude",
+					Name:        "Latitude",
+					Description: "Latitude of the best match",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "longitude",
+					Name:        "Longitude",
+					Description: "Longitude of the best match",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "candidates",
+					Name:        "Candidates",
+					Description: "Every match, the best first",
+					Type:        types.PinTypes.Array,
+				},
+			},
+		},
+	}
+}
+
+// IsNondeterministic reports that the node calls a remote geocoder
+func (n *GeocodeNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *GeocodeNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Geocode node", nil)
+
+	address, bpErr := stringInput(ctx, "address", "")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	geocoder := DefaultGeocoder()
+	if geocoder == nil {
+		return fail(ctx, bperrors.New(bperrors.ErrorTypeSystem, bperrors.ErrServiceUnavailable, "no geocoder is configured", bperrors.SeverityHigh))
+	}
+
+	geocodeCtx, cancel := context.WithTimeout(ctx.Context(), 15*time.Second)
+	defer cancel()
+	locations, err := geocoder.Geocode(geocodeCtx, address)
+	if err != nil {
+		return fail(ctx, bperrors.RequestFailed("failed to geocode the address", err))
+	}
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Geocode",
+		Value: map[string]interface{}{
+			"address": address,
+			"matches": len(locations),
+		},
+		Timestamp: time.Now(),
+	})
+
+	if len(locations) == 0 {
+		logger.Info("Address not found", map[string]interface{}{"address": address})
+		return ctx.ActivateOutputFlow("notFound")
+	}
+
+	candidates := make([]interface{}, 0, len(locations))
+	for _, location := range locations {
+		candidates = append(candidates, locationObject(location))
+	}
+	ctx.SetOutputValue("location", types.NewValue(types.PinTypes.Object, candidates[0]))
+	ctx.SetOutputValue("latitude", types.NewValue(types.PinTypes.Number, locations[0].Latitude))
+	ctx.SetOutputValue("longitude", types.NewValue(types.PinTypes.Number, locations[0].Longitude))
+	ctx.SetOutputValue("candidates", types.NewValue(types.PinTypes.Array, candidates))
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// locationObject converts a location to the object of a pin, which geo-distance accepts
+func locationObject(location Location) map[string]interface{} {
+	return map[string]interface{}{
+		"latitude":  location.Latitude,
+		"longitude": location.Longitude,
+		"address":   location.Address,
+	}
+}
@@ -0,0 +1,195 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// Dimensions of the units the convert-unit node converts between
+const (
+	dimensionLength      = "length"
+	dimensionWeight      = "weight"
+	dimensionTemperature = "temperature"
+)
+
+// unit converts a value of its unit to and from the base unit of its dimension: meters,
+// kilograms or kelvin
+type unit struct {
+	dimension string
+	toBase    func(float64) float64
+	fromBase  func(float64) float64
+}
+
+// linearUnit is a unit that is a multiple of the base unit of its dimension
+func linearUnit(dimension string, factor float64) unit {
+	return unit{
+		dimension: dimension,
+		toBase:    func(value float64) float64 { return value * factor },
+		fromBase:  func(value float64) float64 { return value / factor },
+	}
+}
+
+// units maps the symbols and names of the units to their conversions
+var units = map[string]unit{}
+
+func init() {
+	register := func(u unit, names ...string) {
+		for _, name := range names {
+			units[name] = u
+		}
+	}
+
+	register(linearUnit(dimensionLength, 0.001), "mm", "millimeter", "millimeters", "millimetre", "millimetres")
+	register(linearUnit(dimensionLength, 0.01), "cm", "centimeter", "centimeters", "centimetre", "centimetres")
+	register(linearUnit(dimensionLength, 1), "m", "meter", "meters", "metre", "metres")
+	register(linearUnit(dimensionLength, 1000), "km", "kilometer", "kilometers", "kilometre", "kilometres")
+	register(linearUnit(dimensionLength, 0.0254), "in", "inch", "inches")
+	register(linearUnit(dimensionLength, 0.3048), "ft", "foot", "feet")
+	register(linearUnit(dimensionLength, 0.9144), "yd", "yard", "yards")
+	register(linearUnit(dimensionLength, 1609.344), "mi", "mile", "miles")
+	register(linearUnit(dimensionLength, 1852), "nmi", "nautical mile", "nautical miles")
+
+	register(linearUnit(dimensionWeight, 1e-6), "mg", "milligram", "milligrams")
+	register(linearUnit(dimensionWeight, 0.001), "g", "gram", "grams")
+	register(linearUnit(dimensionWeight, 1), "kg", "kilogram", "kilograms")
+	register(linearUnit(dimensionWeight, 1000), "t", "tonne", "tonnes")
+	register(linearUnit(dimensionWeight, 0.028349523125), "oz", "ounce", "ounces")
+	register(linearUnit(dimensionWeight, 0.45359237), "lb", "lbs", "pound", "pounds")
+	register(linearUnit(dimensionWeight, 6.35029318), "st", "stone", "stones")
+
+	register(unit{
+		dimension: dimensionTemperature,
+		toBase:    func(value float64) float64 { return value + 273.15 },
+		fromBase:  func(value float64) float64 { return value - 273.15 },
+	}, "c", "°c", "celsius")
+	register(unit{
+		dimension: dimensionTemperature,
+		toBase:    func(value float64) float64 { return (value-32)*5/9 + 273.15 },
+		fromBase:  func(value float64) float64 { return (value-273.15)*9/5 + 32 },
+	}, "f", "°f", "fahrenheit")
+	register(unit{
+		dimension: dimensionTemperature,
+		toBase:    func(value float64) float64 { return value },
+		fromBase:  func(value float64) float64 { return value },
+	}, "k", "kelvin")
+}
+
+// lookupUnit finds a unit by its symbol or name, ignoring case
+func lookupUnit(pinID, name string) (unit, *bperrors.BlueprintError) {
+	u, exists := units[strings.ToLower(name)]
+	if !exists {
+		return unit{}, bperrors.InvalidInput(pinID, fmt.Errorf("unknown unit %q", name))
+	}
+	return u, nil
+}
+
+// ConvertUnitNode converts a value between units of length, weight or temperature
+type ConvertUnitNode struct {
+	node.BaseNode
+}
+
+// NewConvertUnitNode creates a new Convert Unit node
+func NewConvertUnitNode() node.Node {
+	return &ConvertUnitNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "convert-unit",
+				Name:        "Convert Unit",
+				Description: "Converts a value between units of length, weight or temperature",
+				Category:    "Convert",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "value",
+					Name:        "Value",
+					Description: "Value to convert",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "from",
+					Name:        "From",
+					Description: "Unit of the value, e.g. km, lb or celsius",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "to",
+					Name:        "To",
+					Description: "Unit to convert to, of the same dimension",
+					Type:        types.PinTypes.String,
+				},
+			},
+			Outputs: append(flowPins("Executed if a unit is unknown or the units measure different things"),
+				types.Pin{
+					ID:          "result",
+					Name:        "Result",
+					Description: "Converted value",
+					Type:        types.PinTypes.Number,
+				},
+				types.Pin{
+					ID:          "dimension",
+					Name:        "Dimension",
+					Description: "What the units measure: length, weight or temperature",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *ConvertUnitNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Convert Unit node", nil)
+
+	value, bpErr := numberInput(ctx, "value", nil)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	fromName, bpErr := stringInput(ctx, "from", "")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	toName, bpErr := stringInput(ctx, "to", "")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	from, bpErr := lookupUnit("from", fromName)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	to, bpErr := lookupUnit("to", toName)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	if from.dimension != to.dimension {
+		return fail(ctx, bperrors.InvalidInput("to", fmt.Errorf("can't convert %s (%s) to %s (%s)", fromName, from.dimension, toName, to.dimension)))
+	}
+
+	result := to.fromBase(from.toBase(value))
+	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.Number, result))
+	ctx.SetOutputValue("dimension", types.NewValue(types.PinTypes.String, from.dimension))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Convert Unit",
+		Value: map[string]interface{}{
+			"value":  value,
+			"from":   fromName,
+			"to":     toName,
+			"result": result,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
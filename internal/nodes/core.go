@@ -2,10 +2,13 @@ package nodes
 
 import (
 	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/assertion"
+	"webblueprint/internal/nodes/convert"
 	"webblueprint/internal/nodes/data"
 	"webblueprint/internal/nodes/events"
 	"webblueprint/internal/nodes/logic"
 	"webblueprint/internal/nodes/math"
+	"webblueprint/internal/nodes/security"
 	"webblueprint/internal/nodes/utility"
 	"webblueprint/internal/nodes/web"
 )
@@ -17,12 +20,16 @@ var (
 		"loop":         logic.NewLoopNode,
 		"sequence":     logic.NewSequenceNode,
 		"branch":       logic.NewBranchNode,
+		"switch":       logic.NewSwitchNode,
+		"join":         logic.NewJoinNode,
+		"parallel-for": logic.NewParallelForNode,
 
 		// Web düğümleri
 		"http-request": web.NewHTTPRequestNode,
 		"dom-element":  web.NewDOMElementNode,
 		"dom-event":    web.NewDOMEventNode,
 		"storage":      web.NewStorageNode,
+		"grpc-call":    web.NewGRPCCallNode,
 
 		// Veri düğümleri
 		"constant-string":    data.NewStringConstantNode,
@@ -30,11 +37,24 @@ var (
 		"constant-boolean":   data.NewBooleanConstantNode,
 		"variable-get":       data.NewVariableGetNode,
 		"variable-set":       data.NewVariableSetNode,
+		"state-get":          data.NewStateGetNode,
+		"state-set":          data.NewStateSetNode,
 		"json-processor":     data.NewJSONNode,
 		"array-operations":   data.NewArrayNode,
 		"object-operations":  data.NewObjectNode,
 		"type-conversion":    data.NewTypeConversionNode,
 		"schema-transformer": data.NewSchemaNode, // Updated registration for Schema Node
+		"merge":              data.NewMergeNode,
+		"statistics":         data.NewStatisticsNode,
+		"group-by":           data.NewGroupByNode,
+		"pivot":              data.NewPivotNode,
+		"validate-data":      data.NewValidateDataNode,
+
+		// Generators
+		"uuid":            data.NewUUIDNode,
+		"random-number":   data.NewRandomNumberNode,
+		"random-string":   data.NewRandomStringNode,
+		"sequence-number": data.NewSequenceNumberNode,
 
 		// Matematik düğümleri
 		"math-add":      math.NewAddNode,
@@ -43,8 +63,35 @@ var (
 		"math-divide":   math.NewDivideNode,
 
 		// Yardımcı düğümler
-		"print": utility.NewPrintNode,
-		"timer": utility.NewTimerNode,
+		"print":         utility.NewPrintNode,
+		"timer":         utility.NewTimerNode,
+		"acquire-lock":  utility.NewAcquireLockNode,
+		"release-lock":  utility.NewReleaseLockNode,
+		"format-string": utility.NewFormatStringNode,
+		"run-command":   utility.NewRunCommandNode,
+
+		// Security
+		"hash":       security.NewHashNode,
+		"encode":     security.NewEncodeNode,
+		"decode":     security.NewDecodeNode,
+		"encrypt":    security.NewEncryptNode,
+		"decrypt":    security.NewDecryptNode,
+		"jwt-sign":   security.NewJWTSignNode,
+		"jwt-verify": security.NewJWTVerifyNode,
+
+		// Convert
+		"convert-unit":     convert.NewConvertUnitNode,
+		"convert-currency": convert.NewConvertCurrencyNode,
+		"geo-distance":     convert.NewDistanceNode,
+		"geocode":          convert.NewGeocodeNode,
+
+		// Annotations
+		"comment": utility.NewCommentNode,
+
+		// Test assertions
+		"expect-equal":  assertion.NewExpectEqualNode,
+		"expect-error":  assertion.NewExpectErrorNode,
+		"expect-called": assertion.NewExpectCalledNode,
 
 		// Events
 		"event-definition":          events.NewEventDefinitionNode,
@@ -54,9 +101,12 @@ var (
 		"event-unbind":              events.NewEventUnbindNode,
 		"clear-event-bindings":      events.NewClearBindingsNode,
 		"timer-event":               events.NewTimerEventNode,
+		"debounce":                  events.NewDebounceNode,
+		"throttle":                  events.NewThrottleNode,
 		"event-with-payload":        events.NewEventWithPayloadNode,
 		"event-on-created":          events.NewOnCreatedEventNode,
 		"event-on-tick":             events.NewOnTickEventNode,
 		"event-on-input":            events.NewOnInputEventNode,
+		"event-on-error":            events.NewOnErrorEventNode,
 	}
 )
@@ -0,0 +1,635 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// Operations of the pivot node, which aggregate the values of a cell
+const (
+	aggregateSum   = "sum"
+	aggregateAvg   = "avg"
+	aggregateMin   = "min"
+	aggregateMax   = "max"
+	aggregateCount = "count"
+)
+
+// itemsPin is the input of the items the analytic nodes aggregate
+var itemsPin = types.Pin{
+	ID:          "items",
+	Name:        "Items",
+	Description: "Array of items, or a stream of a JSON array or newline-delimited JSON items",
+	Type:        types.PinTypes.Any,
+}
+
+// aggregateFlowPins are the execution and error outputs of the analytic nodes
+var aggregateFlowPins = []types.Pin{
+	{
+		ID:          "then",
+		Name:        "Then",
+		Description: "Execution continues",
+		Type:        types.PinTypes.Execution,
+	},
+	{
+		ID:          "error",
+		Name:        "Error",
+		Description: "Executed if the items can't be read",
+		Type:        types.PinTypes.Execution,
+	},
+	{
+		ID:          "errorMessage",
+		Name:        "Error Message",
+		Description: "Error message if the aggregation fails",
+		Type:        types.PinTypes.String,
+	},
+}
+
+// aggregate accumulates the statistics of numbers in a single pass
+type aggregate struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func (a *aggregate) add(number float64) {
+	if a.count == 0 || number < a.min {
+		a.min = number
+	}
+	if a.count == 0 || number > a.max {
+		a.max = number
+	}
+	a.count++
+	a.sum += number
+}
+
+// value returns the result of an operation, nil for the average, minimum and maximum of
+// no numbers
+func (a *aggregate) value(operation string) interface{} {
+	switch operation {
+	case aggregateCount:
+		return float64(a.count)
+	case aggregateSum:
+		return a.sum
+	}
+	if a.count == 0 {
+		return nil
+	}
+	switch operation {
+	case aggregateAvg:
+		return a.sum / float64(a.count)
+	case aggregateMin:
+		return a.min
+	default:
+		return a.max
+	}
+}
+
+// statistics returns all the results of the aggregate
+func (a *aggregate) statistics() map[string]interface{} {
+	return map[string]interface{}{
+		aggregateCount: a.value(aggregateCount),
+		aggregateSum:   a.value(aggregateSum),
+		aggregateAvg:   a.value(aggregateAvg),
+		aggregateMin:   a.value(aggregateMin),
+		aggregateMax:   a.value(aggregateMax),
+	}
+}
+
+// eachItem calls fn with the items of an array, or of a stream decoded one item at a time
+// so large payloads aren't read into memory at once
+func eachItem(value types.Value, fn func(item interface{}) error) error {
+	switch items := value.RawValue.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *types.Stream:
+		return eachStreamItem(items, fn)
+	default:
+		return fmt.Errorf("expected an array or a stream, got %T", items)
+	}
+}
+
+// eachStreamItem decodes the items of a stream holding a JSON array or newline-delimited
+// JSON values
+func eachStreamItem(stream *types.Stream, fn func(item interface{}) error) error {
+	reader := bufio.NewReader(stream)
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			break
+		}
+		reader.ReadByte()
+	}
+
+	decoder := json.NewDecoder(reader)
+	if b, _ := reader.Peek(1); b[0] == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		for decoder.More() {
+			var item interface{}
+			if err := decoder.Decode(&item); err != nil {
+				return fmt.Errorf("invalid JSON item: %w", err)
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		_, err := decoder.Token()
+		return err
+	}
+
+	for {
+		var item interface{}
+		err := decoder.Decode(&item)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON item: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+// fieldOf returns the value of a field of an item, following dots into nested objects. The
+// item itself is the value of an empty field.
+func fieldOf(item interface{}, field string) (interface{}, bool) {
+	if field == "" {
+		return item, true
+	}
+	value := item
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// numberOf returns the number of a value, parsing numeric strings
+func numberOf(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// keyOf returns the group key of an item: the text of its key field, or "null" if the
+// item has none
+func keyOf(item interface{}, field string) string {
+	value, exists := fieldOf(item, field)
+	if !exists || value == nil {
+		return "null"
+	}
+	if text, ok := value.(string); ok {
+		return text
+	}
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// stringInput returns the text of an optional string input, empty if it isn't connected
+func stringInput(ctx node.ExecutionContext, pinID string) string {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.IsNull() {
+		return ""
+	}
+	text, err := value.AsString()
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+// failAggregation reports an error of an analytic node on its error flow
+func failAggregation(ctx node.ExecutionContext, description, message string) error {
+	ctx.Logger().Error("Aggregation failed", map[string]interface{}{"error": message})
+	ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, message))
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: description,
+		Value:       map[string]interface{}{"error": message},
+		Timestamp:   time.Now(),
+	})
+	return ctx.ActivateOutputFlow("error")
+}
+
+// StatisticsNode computes the count, sum, average, minimum and maximum of the numbers of an
+// array, or of a field of its objects. Items without a number are left out.
+type StatisticsNode struct {
+	node.BaseNode
+}
+
+// NewStatisticsNode creates a new Statistics node
+func NewStatisticsNode() node.Node {
+	return &StatisticsNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "statistics",
+				Name:        "Statistics",
+				Description: "Computes the count, sum, average, minimum and maximum of the numbers of an array",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				itemsPin,
+				{
+					ID:          "field",
+					Name:        "Field",
+					Description: "Field of the objects to aggregate, dot-separated for nested fields (the items themselves if empty)",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+			},
+			Outputs: append(append([]types.Pin(nil), aggregateFlowPins...),
+				types.Pin{ID: "count", Name: "Count", Description: "Number of numbers aggregated", Type: types.PinTypes.Number},
+				types.Pin{ID: "sum", Name: "Sum", Description: "Sum of the numbers", Type: types.PinTypes.Number},
+				types.Pin{ID: "avg", Name: "Average", Description: "Average of the numbers, null if there are none", Type: types.PinTypes.Number},
+				types.Pin{ID: "min", Name: "Minimum", Description: "Smallest number, null if there are none", Type: types.PinTypes.Number},
+				types.Pin{ID: "max", Name: "Maximum", Description: "Largest number, null if there are none", Type: types.PinTypes.Number},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *StatisticsNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Statistics node", nil)
+
+	itemsValue, exists := ctx.GetInputValue("items")
+	if !exists {
+		return failAggregation(ctx, "Statistics Error", bperrors.MissingInput("items").Message)
+	}
+	field := stringInput(ctx, "field")
+
+	var result aggregate
+	err := eachItem(itemsValue, func(item interface{}) error {
+		if value, exists := fieldOf(item, field); exists {
+			if number, ok := numberOf(value); ok {
+				result.add(number)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return failAggregation(ctx, "Statistics Error", "Invalid items: "+err.Error())
+	}
+
+	statistics := result.statistics()
+	for _, operation := range []string{aggregateCount, aggregateSum, aggregateAvg, aggregateMin, aggregateMax} {
+		ctx.SetOutputValue(operation, types.NewValue(types.PinTypes.Number, statistics[operation]))
+	}
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Statistics",
+		Value: map[string]interface{}{
+			"field":      field,
+			"statistics": statistics,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// GroupByNode groups the items of an array by the value of a key field, and computes the
+// statistics of a number field per group
+type GroupByNode struct {
+	node.BaseNode
+}
+
+// NewGroupByNode creates a new Group By node
+func NewGroupByNode() node.Node {
+	return &GroupByNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "group-by",
+				Name:        "Group By",
+				Description: "Groups the items of an array by a key field, with the statistics of each group",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				itemsPin,
+				{
+					ID:          "key",
+					Name:        "Key",
+					Description: "Field to group the items by, dot-separated for nested fields",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "field",
+					Name:        "Field",
+					Description: "Number field to compute the statistics of each group of (counts the items if empty)",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+				{
+					ID:          "collect",
+					Name:        "Collect Items",
+					Description: "Whether to output the items of each group; disable for large inputs to keep only the statistics",
+					Type:        types.PinTypes.Boolean,
+					Optional:    true,
+					Default:     true,
+				},
+			},
+			Outputs: append(append([]types.Pin(nil), aggregateFlowPins...),
+				types.Pin{ID: "groups", Name: "Groups", Description: "Items of each group, by key", Type: types.PinTypes.Object},
+				types.Pin{ID: "statistics", Name: "Statistics", Description: "Count, sum, avg, min and max of each group, by key", Type: types.PinTypes.Object},
+				types.Pin{ID: "keys", Name: "Keys", Description: "Keys of the groups, in the order they were first seen", Type: types.PinTypes.Array},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *GroupByNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Group By node", nil)
+
+	itemsValue, exists := ctx.GetInputValue("items")
+	if !exists {
+		return failAggregation(ctx, "Group By Error", bperrors.MissingInput("items").Message)
+	}
+	key := stringInput(ctx, "key")
+	if key == "" {
+		return failAggregation(ctx, "Group By Error", bperrors.MissingInput("key").Message)
+	}
+	field := stringInput(ctx, "field")
+	collect := true
+	if collectValue, exists := ctx.GetInputValue("collect"); exists && !collectValue.IsNull() {
+		collect, _ = collectValue.AsBoolean()
+	}
+
+	keys := make([]interface{}, 0)
+	groups := make(map[string]interface{})
+	aggregates := make(map[string]*aggregate)
+	err := eachItem(itemsValue, func(item interface{}) error {
+		groupKey := keyOf(item, key)
+		group, exists := aggregates[groupKey]
+		if !exists {
+			group = &aggregate{}
+			aggregates[groupKey] = group
+			keys = append(keys, groupKey)
+			if collect {
+				groups[groupKey] = make([]interface{}, 0)
+			}
+		}
+		if collect {
+			groups[groupKey] = append(groups[groupKey].([]interface{}), item)
+		}
+
+		if field == "" {
+			group.add(1)
+		} else if value, exists := fieldOf(item, field); exists {
+			if number, ok := numberOf(value); ok {
+				group.add(number)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return failAggregation(ctx, "Group By Error", "Invalid items: "+err.Error())
+	}
+
+	statistics := make(map[string]interface{}, len(aggregates))
+	for groupKey, group := range aggregates {
+		statistics[groupKey] = group.statistics()
+	}
+
+	ctx.SetOutputValue("groups", types.NewValue(types.PinTypes.Object, groups))
+	ctx.SetOutputValue("statistics", types.NewValue(types.PinTypes.Object, statistics))
+	ctx.SetOutputValue("keys", types.NewValue(types.PinTypes.Array, keys))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Group By",
+		Value: map[string]interface{}{
+			"key":    key,
+			"field":  field,
+			"groups": len(keys),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// PivotNode turns the items of an array into a table: a row per value of the row field, a
+// column per value of the column field and in each cell the aggregate of a number field of
+// the items it holds
+type PivotNode struct {
+	node.BaseNode
+}
+
+// NewPivotNode creates a new Pivot node
+func NewPivotNode() node.Node {
+	return &PivotNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "pivot",
+				Name:        "Pivot",
+				Description: "Pivots the items of an array into rows and columns of aggregated values",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				itemsPin,
+				{
+					ID:          "rows",
+					Name:        "Rows",
+					Description: "Field whose values are the rows of the table",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "columns",
+					Name:        "Columns",
+					Description: "Field whose values are the columns of the table",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "field",
+					Name:        "Field",
+					Description: "Number field aggregated in the cells (counts the items if empty)",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+				{
+					ID:          "operation",
+					Name:        "Operation",
+					Description: "Aggregation of the cells (sum, avg, min, max, count), count if there's no field",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+					Default:     aggregateSum,
+				},
+			},
+			Outputs: append(append([]types.Pin(nil), aggregateFlowPins...),
+				types.Pin{ID: "table", Name: "Table", Description: "Aggregated value of each column, by row", Type: types.PinTypes.Object},
+				types.Pin{ID: "rowKeys", Name: "Row Keys", Description: "Rows of the table, in the order they were first seen", Type: types.PinTypes.Array},
+				types.Pin{ID: "columnKeys", Name: "Column Keys", Description: "Columns of the table, in the order they were first seen", Type: types.PinTypes.Array},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *PivotNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Pivot node", nil)
+
+	itemsValue, exists := ctx.GetInputValue("items")
+	if !exists {
+		return failAggregation(ctx, "Pivot Error", bperrors.MissingInput("items").Message)
+	}
+	rows := stringInput(ctx, "rows")
+	if rows == "" {
+		return failAggregation(ctx, "Pivot Error", bperrors.MissingInput("rows").Message)
+	}
+	columns := stringInput(ctx, "columns")
+	if columns == "" {
+		return failAggregation(ctx, "Pivot Error", bperrors.MissingInput("columns").Message)
+	}
+	field := stringInput(ctx, "field")
+	operation := stringInput(ctx, "operation")
+	if operation == "" {
+		operation = aggregateSum
+	}
+	switch operation {
+	case aggregateSum, aggregateAvg, aggregateMin, aggregateMax, aggregateCount:
+	default:
+		return failAggregation(ctx, "Pivot Error", fmt.Sprintf("Invalid operation: %s", operation))
+	}
+	// Without a field the cells count their items
+	if field == "" {
+		operation = aggregateCount
+	}
+
+	rowKeys := make([]interface{}, 0)
+	columnKeys := make([]interface{}, 0)
+	seenColumns := make(map[string]bool)
+	cells := make(map[string]map[string]*aggregate)
+	err := eachItem(itemsValue, func(item interface{}) error {
+		rowKey := keyOf(item, rows)
+		columnKey := keyOf(item, columns)
+		row, exists := cells[rowKey]
+		if !exists {
+			row = make(map[string]*aggregate)
+			cells[rowKey] = row
+			rowKeys = append(rowKeys, rowKey)
+		}
+		if !seenColumns[columnKey] {
+			seenColumns[columnKey] = true
+			columnKeys = append(columnKeys, columnKey)
+		}
+		cell := row[columnKey]
+		if cell == nil {
+			cell = &aggregate{}
+			row[columnKey] = cell
+		}
+
+		if field == "" {
+			cell.add(1)
+		} else if value, exists := fieldOf(item, field); exists {
+			if number, ok := numberOf(value); ok {
+				cell.add(number)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return failAggregation(ctx, "Pivot Error", "Invalid items: "+err.Error())
+	}
+
+	// Every row has every column, empty cells counting 0 and having no other aggregate
+	empty := &aggregate{}
+	table := make(map[string]interface{}, len(cells))
+	for rowKey, row := range cells {
+		values := make(map[string]interface{}, len(columnKeys))
+		for _, columnKey := range columnKeys {
+			cell := row[columnKey.(string)]
+			if cell == nil {
+				cell = empty
+			}
+			values[columnKey.(string)] = cell.value(operation)
+		}
+		table[rowKey] = values
+	}
+
+	ctx.SetOutputValue("table", types.NewValue(types.PinTypes.Object, table))
+	ctx.SetOutputValue("rowKeys", types.NewValue(types.PinTypes.Array, rowKeys))
+	ctx.SetOutputValue("columnKeys", types.NewValue(types.PinTypes.Array, columnKeys))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Pivot",
+		Value: map[string]interface{}{
+			"rows":      len(rowKeys),
+			"columns":   len(columnKeys),
+			"operation": operation,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
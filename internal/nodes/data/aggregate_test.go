@@ -0,0 +1,231 @@
+package data_test
+
+import (
+	"strings"
+	"testing"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/test"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
+)
+
+var sales = []interface{}{
+	map[string]interface{}{"region": "eu", "product": "a", "amount": 10.0},
+	map[string]interface{}{"region": "us", "product": "a", "amount": 5.0},
+	map[string]interface{}{"region": "eu", "product": "b", "amount": "30"},
+	map[string]interface{}{"region": "eu", "product": "a", "amount": 20.0},
+	map[string]interface{}{"product": "b"},
+}
+
+func TestStatisticsNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name: "numbers of a field",
+			Inputs: map[string]interface{}{
+				"items": sales,
+				"field": "amount",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"count": 4.0,
+				"sum":   65.0,
+				"avg":   16.25,
+				"min":   5.0,
+				"max":   30.0,
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "numbers of the array",
+			Inputs: map[string]interface{}{
+				"items": []interface{}{3.0, "x", 1.0},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"count": 2.0,
+				"sum":   4.0,
+				"avg":   2.0,
+				"min":   1.0,
+				"max":   3.0,
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "not an array",
+			Inputs: map[string]interface{}{
+				"items": true,
+			},
+			ExpectedFlow: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewStatisticsNode(), tc)
+		})
+	}
+}
+
+func TestStatisticsNodeStreams(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		field        string
+		expectedFlow string
+		expectedSum  float64
+	}{
+		{name: "newline-delimited items", body: "{\"v\":{\"n\":2}}\n{\"v\":{\"n\":4}}\n", field: "v.n", expectedFlow: "then", expectedSum: 6},
+		{name: "JSON array", body: " [1, 2, 6]", expectedFlow: "then", expectedSum: 9},
+		{name: "empty stream", body: "", expectedFlow: "then", expectedSum: 0},
+		{name: "invalid JSON", body: "[1, oops]", expectedFlow: "error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := mocks.NewMockExecutionContext("stats", "statistics", mocks.NewMockLogger())
+			ctx.SetInputValue("items", types.NewValue(types.PinTypes.Stream, types.NewStream(strings.NewReader(tc.body))))
+			if tc.field != "" {
+				ctx.SetInputValue("field", types.NewValue(types.PinTypes.String, tc.field))
+			}
+			if err := data.NewStatisticsNode().Execute(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if flow := ctx.GetActivatedFlow(); flow != tc.expectedFlow {
+				t.Fatalf("expected flow %s, got %s", tc.expectedFlow, flow)
+			}
+			if tc.expectedFlow != "then" {
+				return
+			}
+			if sum, _ := ctx.GetOutputValue("sum"); sum.RawValue != tc.expectedSum {
+				t.Errorf("expected sum %v, got %v", tc.expectedSum, sum.RawValue)
+			}
+		})
+	}
+}
+
+func TestGroupByNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name: "statistics of a field by key",
+			Inputs: map[string]interface{}{
+				"items": sales,
+				"key":   "region",
+				"field": "amount",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"keys": []interface{}{"eu", "us", "null"},
+				"statistics": map[string]interface{}{
+					"eu":   map[string]interface{}{"count": 3.0, "sum": 60.0, "avg": 20.0, "min": 10.0, "max": 30.0},
+					"us":   map[string]interface{}{"count": 1.0, "sum": 5.0, "avg": 5.0, "min": 5.0, "max": 5.0},
+					"null": map[string]interface{}{"count": 0.0, "sum": 0.0, "avg": nil, "min": nil, "max": nil},
+				},
+				"groups": map[string]interface{}{
+					"eu":   []interface{}{sales[0], sales[2], sales[3]},
+					"us":   []interface{}{sales[1]},
+					"null": []interface{}{sales[4]},
+				},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "counts without collecting items",
+			Inputs: map[string]interface{}{
+				"items":   sales,
+				"key":     "product",
+				"collect": false,
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"keys":   []interface{}{"a", "b"},
+				"groups": map[string]interface{}{},
+				"statistics": map[string]interface{}{
+					"a": map[string]interface{}{"count": 3.0, "sum": 3.0, "avg": 1.0, "min": 1.0, "max": 1.0},
+					"b": map[string]interface{}{"count": 2.0, "sum": 2.0, "avg": 1.0, "min": 1.0, "max": 1.0},
+				},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "missing key",
+			Inputs: map[string]interface{}{
+				"items": sales,
+			},
+			ExpectedFlow: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewGroupByNode(), tc)
+		})
+	}
+}
+
+func TestPivotNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name: "sum of a field",
+			Inputs: map[string]interface{}{
+				"items":   sales[:4],
+				"rows":    "region",
+				"columns": "product",
+				"field":   "amount",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"rowKeys":    []interface{}{"eu", "us"},
+				"columnKeys": []interface{}{"a", "b"},
+				"table": map[string]interface{}{
+					"eu": map[string]interface{}{"a": 30.0, "b": 30.0},
+					"us": map[string]interface{}{"a": 5.0, "b": 0.0},
+				},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "maximum leaves empty cells null",
+			Inputs: map[string]interface{}{
+				"items":     sales[:4],
+				"rows":      "region",
+				"columns":   "product",
+				"field":     "amount",
+				"operation": "max",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"table": map[string]interface{}{
+					"eu": map[string]interface{}{"a": 20.0, "b": 30.0},
+					"us": map[string]interface{}{"a": 5.0, "b": nil},
+				},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "counts without a field",
+			Inputs: map[string]interface{}{
+				"items":   sales,
+				"rows":    "product",
+				"columns": "region",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"table": map[string]interface{}{
+					"a": map[string]interface{}{"eu": 2.0, "us": 1.0, "null": 0.0},
+					"b": map[string]interface{}{"eu": 1.0, "us": 0.0, "null": 1.0},
+				},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "invalid operation",
+			Inputs: map[string]interface{}{
+				"items":     sales,
+				"rows":      "product",
+				"columns":   "region",
+				"field":     "amount",
+				"operation": "median",
+			},
+			ExpectedFlow: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewPivotNode(), tc)
+		})
+	}
+}
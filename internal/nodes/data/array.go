@@ -3,6 +3,7 @@ package data
 import (
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -112,9 +113,9 @@ func (n *ArrayNode) Execute(ctx node.ExecutionContext) error {
 	// Get operation value
 	operationValue, operationExists := ctx.GetInputValue("operation")
 	if !operationExists {
-		err := fmt.Errorf("missing required input: operation")
+		err := bperrors.MissingInput("operation")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -162,9 +163,9 @@ func (n *ArrayNode) handleCreateOperation(ctx node.ExecutionContext, debugData m
 	// Get size value
 	sizeValue, sizeExists := ctx.GetInputValue("size")
 	if !sizeExists {
-		err := fmt.Errorf("missing required input: size")
+		err := bperrors.MissingInput("size")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -204,17 +205,17 @@ func (n *ArrayNode) handleGetOperation(ctx node.ExecutionContext, debugData map[
 	// Get array and index values
 	arrayValue, arrayExists := ctx.GetInputValue("array")
 	if !arrayExists {
-		err := fmt.Errorf("missing required input: array")
+		err := bperrors.MissingInput("array")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	indexValue, indexExists := ctx.GetInputValue("index")
 	if !indexExists {
-		err := fmt.Errorf("missing required input: index")
+		err := bperrors.MissingInput("index")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -236,9 +237,9 @@ func (n *ArrayNode) handleGetOperation(ctx node.ExecutionContext, debugData map[
 	// Check if index is valid
 	intIndex := int(index)
 	if intIndex < 0 || intIndex >= len(array) {
-		err := fmt.Errorf("index out of bounds: %d", intIndex)
+		err := bperrors.OperationFailed(fmt.Sprintf("index out of bounds: %d", intIndex))
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -275,25 +276,25 @@ func (n *ArrayNode) handleSetOperation(ctx node.ExecutionContext, debugData map[
 	// Get array, index, and value
 	arrayValue, arrayExists := ctx.GetInputValue("array")
 	if !arrayExists {
-		err := fmt.Errorf("missing required input: array")
+		err := bperrors.MissingInput("array")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	indexValue, indexExists := ctx.GetInputValue("index")
 	if !indexExists {
-		err := fmt.Errorf("missing required input: index")
+		err := bperrors.MissingInput("index")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	valueValue, valueExists := ctx.GetInputValue("value")
 	if !valueExists {
-		err := fmt.Errorf("missing required input: value")
+		err := bperrors.MissingInput("value")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -315,15 +316,14 @@ func (n *ArrayNode) handleSetOperation(ctx node.ExecutionContext, debugData map[
 	// Check if index is valid
 	intIndex := int(index)
 	if intIndex < 0 || intIndex >= len(array) {
-		err := fmt.Errorf("index out of bounds: %d", intIndex)
+		err := bperrors.OperationFailed(fmt.Sprintf("index out of bounds: %d", intIndex))
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
-	// Create a new array with the value set at the specified index
-	newArray := make([]interface{}, len(array))
-	copy(newArray, array)
+	// Set the value on a copy, the input array is shared with other nodes
+	newArray := types.CopyArray(array, 0)
 	newArray[intIndex] = valueValue.RawValue
 
 	// Set output values
@@ -353,17 +353,17 @@ func (n *ArrayNode) handlePushOperation(ctx node.ExecutionContext, debugData map
 	// Get array and value
 	arrayValue, arrayExists := ctx.GetInputValue("array")
 	if !arrayExists {
-		err := fmt.Errorf("missing required input: array")
+		err := bperrors.MissingInput("array")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	valueValue, valueExists := ctx.GetInputValue("value")
 	if !valueExists {
-		err := fmt.Errorf("missing required input: value")
+		err := bperrors.MissingInput("value")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -375,10 +375,8 @@ func (n *ArrayNode) handlePushOperation(ctx node.ExecutionContext, debugData map
 		return ctx.ActivateOutputFlow("error")
 	}
 
-	// Create a new array with the value pushed to the end
-	newArray := make([]interface{}, len(array)+1)
-	copy(newArray, array)
-	newArray[len(array)] = valueValue.RawValue
+	// Push the value to a copy, the input array is shared with other nodes
+	newArray := append(types.CopyArray(array, 1), valueValue.RawValue)
 
 	// Set output values
 	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.Array, newArray))
@@ -406,9 +404,9 @@ func (n *ArrayNode) handlePopOperation(ctx node.ExecutionContext, debugData map[
 	// Get array
 	arrayValue, arrayExists := ctx.GetInputValue("array")
 	if !arrayExists {
-		err := fmt.Errorf("missing required input: array")
+		err := bperrors.MissingInput("array")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -422,18 +420,17 @@ func (n *ArrayNode) handlePopOperation(ctx node.ExecutionContext, debugData map[
 
 	// Check if array is not empty
 	if len(array) == 0 {
-		err := fmt.Errorf("cannot pop from empty array")
+		err := bperrors.OperationFailed("cannot pop from empty array")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	// Get the popped item
 	poppedItem := array[len(array)-1]
 
-	// Create a new array without the last element
-	newArray := make([]interface{}, len(array)-1)
-	copy(newArray, array[:len(array)-1])
+	// The remaining elements are shared with the input rather than copied
+	newArray := types.SliceArray(array, 0, len(array)-1)
 
 	// Set output values
 	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.Array, newArray))
@@ -462,9 +459,9 @@ func (n *ArrayNode) handleLengthOperation(ctx node.ExecutionContext, debugData m
 	// Get array
 	arrayValue, arrayExists := ctx.GetInputValue("array")
 	if !arrayExists {
-		err := fmt.Errorf("missing required input: array")
+		err := bperrors.MissingInput("array")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -1,9 +1,14 @@
 package data_test
 
 import (
+	"reflect"
+	"sync"
 	"testing"
+	"webblueprint/internal/node"
 	"webblueprint/internal/nodes/data"
 	"webblueprint/internal/test"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
 )
 
 func TestArrayNode(t *testing.T) {
@@ -408,3 +413,70 @@ func TestArrayNode(t *testing.T) {
 		})
 	}
 }
+
+// Run with -race: the engine passes the same array and object to every connection of an
+// output pin, so nodes modifying them concurrently must leave the shared values alone
+func TestSharedInputsAreNotModified(t *testing.T) {
+	sharedArray := types.NewValue(types.PinTypes.Array, []interface{}{"a", "b", "c"})
+	sharedObject := types.NewValue(types.PinTypes.Object, map[string]interface{}{"name": "shared"})
+
+	operations := []struct {
+		create func() node.Node
+		inputs map[string]types.Value
+	}{
+		{data.NewArrayNode, map[string]types.Value{
+			"operation": types.NewValue(types.PinTypes.String, "set"),
+			"array":     sharedArray,
+			"index":     types.NewValue(types.PinTypes.Number, 0.0),
+			"value":     types.NewValue(types.PinTypes.String, "x"),
+		}},
+		{data.NewArrayNode, map[string]types.Value{
+			"operation": types.NewValue(types.PinTypes.String, "push"),
+			"array":     sharedArray,
+			"value":     types.NewValue(types.PinTypes.String, "x"),
+		}},
+		{data.NewArrayNode, map[string]types.Value{
+			"operation": types.NewValue(types.PinTypes.String, "pop"),
+			"array":     sharedArray,
+		}},
+		{data.NewObjectNode, map[string]types.Value{
+			"operation": types.NewValue(types.PinTypes.String, "set"),
+			"object":    sharedObject,
+			"key":       types.NewValue(types.PinTypes.String, "name"),
+			"value":     types.NewValue(types.PinTypes.String, "changed"),
+		}},
+		{data.NewObjectNode, map[string]types.Value{
+			"operation": types.NewValue(types.PinTypes.String, "delete"),
+			"object":    sharedObject,
+			"key":       types.NewValue(types.PinTypes.String, "name"),
+		}},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, operation := range operations {
+			wg.Add(1)
+			go func(create func() node.Node, inputs map[string]types.Value) {
+				defer wg.Done()
+				ctx := mocks.NewMockExecutionContext("shared", "data", mocks.NewMockLogger())
+				for pinID, value := range inputs {
+					ctx.SetInputValue(pinID, value)
+				}
+				if err := create().Execute(ctx); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if flow := ctx.GetActivatedFlow(); flow != "then" {
+					t.Errorf("expected the then flow, got %q", flow)
+				}
+			}(operation.create, operation.inputs)
+		}
+	}
+	wg.Wait()
+
+	if !reflect.DeepEqual(sharedArray.RawValue, []interface{}{"a", "b", "c"}) {
+		t.Errorf("expected the shared array to be unchanged, got %v", sharedArray.RawValue)
+	}
+	if !reflect.DeepEqual(sharedObject.RawValue, map[string]interface{}{"name": "shared"}) {
+		t.Errorf("expected the shared object to be unchanged, got %v", sharedObject.RawValue)
+	}
+}
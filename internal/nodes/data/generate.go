@@ -0,0 +1,606 @@
+package data
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	mathrand "math/rand/v2"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/statestore"
+	"webblueprint/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// charsets are the named character sets of random strings
+var charsets = map[string]string{
+	"alphanumeric": "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":        "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"numeric":      "0123456789",
+	"hex":          "0123456789abcdef",
+	"lower":        "abcdefghijklmnopqrstuvwxyz",
+	"upper":        "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	// Without 0/O and 1/I/l, for codes people read out or type
+	"readable": "ABCDEFGHJKLMNPQRSTUVWXYZ23456789",
+}
+
+// maxRandomStringLength bounds random strings so a wrong input can't exhaust memory
+const maxRandomStringLength = 4096
+
+// sequenceAttempts is how often a sequence retries when other executions advance it
+// concurrently
+const sequenceAttempts = 10
+
+// generatorFlowPins returns the output flows of the generator nodes
+func generatorFlowPins(catchDescription string) []types.Pin {
+	return []types.Pin{
+		{
+			ID:          "then",
+			Name:        "Then",
+			Description: "Execution continues",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          "catch",
+			Name:        "Catch",
+			Description: catchDescription,
+			Type:        types.PinTypes.Execution,
+		},
+		bperrors.ErrorPin(),
+	}
+}
+
+// numberInput reads an optional number input of a generator node
+func numberInput(ctx node.ExecutionContext, pinID string, fallback float64) (float64, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.RawValue == nil {
+		return fallback, nil
+	}
+	number, err := value.AsNumber()
+	if err != nil {
+		return 0, bperrors.InvalidInput(pinID, err)
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, bperrors.InvalidInput(pinID, fmt.Errorf("%v is not a finite number", number))
+	}
+	return number, nil
+}
+
+// optionalString reads an optional string input of a generator node
+func optionalString(ctx node.ExecutionContext, pinID, fallback string) (string, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.RawValue == nil {
+		return fallback, nil
+	}
+	text, err := value.AsString()
+	if err != nil {
+		return "", bperrors.InvalidInput(pinID, err)
+	}
+	if text == "" {
+		return fallback, nil
+	}
+	return text, nil
+}
+
+// UUIDNode implements a node that generates UUIDs
+type UUIDNode struct {
+	node.BaseNode
+}
+
+// NewUUIDNode creates a new UUID node
+func NewUUIDNode() node.Node {
+	return &UUIDNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "uuid",
+				Name:        "UUID",
+				Description: "Generates a random (v4) or time-ordered (v7) UUID",
+				Category:    "Generators",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "version",
+					Name:        "Version",
+					Description: "UUID version: v4 for random UUIDs, v7 for UUIDs that sort by creation time",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+					Default:     "v4",
+				},
+			},
+			Outputs: append(generatorFlowPins("Executed if the UUID can't be generated"),
+				types.Pin{
+					ID:          "uuid",
+					Name:        "UUID",
+					Description: "Generated UUID",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that UUIDs are random, so replays reuse the recorded ones
+func (n *UUIDNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *UUIDNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing UUID node", nil)
+
+	version, bpErr := optionalString(ctx, "version", "v4")
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+
+	var id uuid.UUID
+	var err error
+	switch strings.TrimPrefix(strings.ToLower(version), "v") {
+	case "4":
+		id, err = uuid.NewRandom()
+	case "7":
+		id, err = uuid.NewV7()
+	default:
+		return failState(ctx, bperrors.InvalidInput("version", fmt.Errorf("unsupported UUID version %q, expected v4 or v7", version)))
+	}
+	if err != nil {
+		return failState(ctx, bperrors.OperationFailed(fmt.Sprintf("failed to generate a UUID: %v", err)))
+	}
+
+	ctx.SetOutputValue("uuid", types.NewValue(types.PinTypes.String, id.String()))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "UUID",
+		Value: map[string]interface{}{
+			"version": version,
+			"uuid":    id.String(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// RandomNumberNode implements a node that generates random numbers in a range
+type RandomNumberNode struct {
+	node.BaseNode
+}
+
+// NewRandomNumberNode creates a new Random Number node
+func NewRandomNumberNode() node.Node {
+	return &RandomNumberNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "random-number",
+				Name:        "Random Number",
+				Description: "Generates a random number between a minimum and a maximum",
+				Category:    "Generators",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "min",
+					Name:        "Min",
+					Description: "Smallest number",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     0,
+				},
+				{
+					ID:          "max",
+					Name:        "Max",
+					Description: "Largest number; integers include it, fractions stay below it",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     1,
+				},
+				{
+					ID:          "integer",
+					Name:        "Integer",
+					Description: "Whether to generate a whole number",
+					Type:        types.PinTypes.Boolean,
+					Optional:    true,
+					Default:     false,
+				},
+			},
+			Outputs: append(generatorFlowPins("Executed if the range is invalid"),
+				types.Pin{
+					ID:          "value",
+					Name:        "Value",
+					Description: "Generated number",
+					Type:        types.PinTypes.Number,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that numbers are random, so replays reuse the recorded ones
+func (n *RandomNumberNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *RandomNumberNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Random Number node", nil)
+
+	minimum, bpErr := numberInput(ctx, "min", 0)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	maximum, bpErr := numberInput(ctx, "max", 1)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	integer := false
+	if integerValue, exists := ctx.GetInputValue("integer"); exists && integerValue.RawValue != nil {
+		var err error
+		if integer, err = integerValue.AsBoolean(); err != nil {
+			return failState(ctx, bperrors.InvalidInput("integer", err))
+		}
+	}
+
+	var value float64
+	if integer {
+		low, high := math.Ceil(minimum), math.Floor(maximum)
+		if low > high {
+			return failState(ctx, bperrors.InvalidInput("max", fmt.Errorf("no whole number between %v and %v", minimum, maximum)))
+		}
+		if high-low >= math.MaxInt64 {
+			return failState(ctx, bperrors.InvalidInput("max", errors.New("range is too large")))
+		}
+		value = low + float64(mathrand.Int64N(int64(high-low)+1))
+	} else {
+		if minimum > maximum {
+			return failState(ctx, bperrors.InvalidInput("max", fmt.Errorf("max %v is below min %v", maximum, minimum)))
+		}
+		value = minimum + mathrand.Float64()*(maximum-minimum)
+	}
+
+	ctx.SetOutputValue("value", types.NewValue(types.PinTypes.Number, value))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Random Number",
+		Value: map[string]interface{}{
+			"min":     minimum,
+			"max":     maximum,
+			"integer": integer,
+			"value":   value,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// RandomStringNode implements a node that generates random strings from a charset. It uses
+// a cryptographic source, so the strings can serve as tokens or coupon codes.
+type RandomStringNode struct {
+	node.BaseNode
+}
+
+// NewRandomStringNode creates a new Random String node
+func NewRandomStringNode() node.Node {
+	return &RandomStringNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "random-string",
+				Name:        "Random String",
+				Description: "Generates a random string from a set of characters",
+				Category:    "Generators",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "length",
+					Name:        "Length",
+					Description: "Number of characters",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     16,
+				},
+				{
+					ID:          "charset",
+					Name:        "Charset",
+					Description: "alphanumeric, alpha, numeric, hex, lower, upper, readable, or the characters to pick from",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+					Default:     "alphanumeric",
+				},
+			},
+			Outputs: append(generatorFlowPins("Executed if the length or the charset is invalid"),
+				types.Pin{
+					ID:          "value",
+					Name:        "Value",
+					Description: "Generated string",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that strings are random, so replays reuse the recorded ones
+func (n *RandomStringNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *RandomStringNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Random String node", nil)
+
+	length, bpErr := numberInput(ctx, "length", 16)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	if length < 1 || length > maxRandomStringLength || length != math.Trunc(length) {
+		return failState(ctx, bperrors.InvalidInput("length", fmt.Errorf("length must be a whole number from 1 to %d, got %v", maxRandomStringLength, length)))
+	}
+	charset, bpErr := optionalString(ctx, "charset", "alphanumeric")
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	if named, exists := charsets[strings.ToLower(charset)]; exists {
+		charset = named
+	}
+	characters := uniqueRunes(charset)
+	if len(characters) < 2 {
+		return failState(ctx, bperrors.InvalidInput("charset", errors.New("charset must have at least 2 different characters")))
+	}
+
+	value, err := randomString(characters, int(length))
+	if err != nil {
+		return failState(ctx, bperrors.OperationFailed(fmt.Sprintf("failed to generate a random string: %v", err)))
+	}
+	ctx.SetOutputValue("value", types.NewValue(types.PinTypes.String, value))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Random String",
+		Value: map[string]interface{}{
+			"length":     int(length),
+			"characters": len(characters),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// uniqueRunes returns the characters of a charset without duplicates, which would make
+// them more likely
+func uniqueRunes(charset string) []rune {
+	seen := make(map[rune]bool)
+	characters := make([]rune, 0, len(charset))
+	for _, r := range charset {
+		if !seen[r] {
+			seen[r] = true
+			characters = append(characters, r)
+		}
+	}
+	return characters
+}
+
+// randomString picks length characters uniformly with crypto/rand
+func randomString(characters []rune, length int) (string, error) {
+	limit := big.NewInt(int64(len(characters)))
+	result := make([]rune, length)
+	for i := range result {
+		index, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		result[i] = characters[index.Int64()]
+	}
+	return string(result), nil
+}
+
+// SequenceNumberNode implements a node that hands out increasing numbers, e.g. for order
+// numbers. The last number is kept in the state store under the key, so the sequence
+// survives restarts and concurrent executions never get the same number.
+type SequenceNumberNode struct {
+	node.BaseNode
+}
+
+// NewSequenceNumberNode creates a new Sequence Number node
+func NewSequenceNumberNode() node.Node {
+	return &SequenceNumberNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "sequence-number",
+				Name:        "Sequence Number",
+				Description: "Generates the next number of a sequence kept across executions",
+				Category:    "Generators",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "key",
+					Name:        "Key",
+					Description: "State key of the sequence",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "start",
+					Name:        "Start",
+					Description: "First number of the sequence",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     1,
+				},
+				{
+					ID:          "step",
+					Name:        "Step",
+					Description: "Amount the sequence grows by; it must be positive",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     1,
+				},
+				{
+					ID:          "prefix",
+					Name:        "Prefix",
+					Description: "Text before the number in the formatted output, e.g. ORD-",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+				{
+					ID:          "padding",
+					Name:        "Padding",
+					Description: "Digits to pad the number to with zeros in the formatted output",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     0,
+				},
+			},
+			Outputs: append(generatorFlowPins("Executed if the sequence can't be advanced"),
+				types.Pin{
+					ID:          "value",
+					Name:        "Value",
+					Description: "Next number of the sequence",
+					Type:        types.PinTypes.Number,
+				},
+				types.Pin{
+					ID:          "formatted",
+					Name:        "Formatted",
+					Description: "Next number with its prefix and padding, e.g. ORD-000042",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that the number depends on earlier executions, so replays
+// reuse the recorded one instead of advancing the sequence
+func (n *SequenceNumberNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *SequenceNumberNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Sequence Number node", nil)
+
+	key, bpErr := stateKey(ctx)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	start, bpErr := numberInput(ctx, "start", 1)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	step, bpErr := numberInput(ctx, "step", 1)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	if step <= 0 {
+		return failState(ctx, bperrors.InvalidInput("step", fmt.Errorf("%v is not positive", step)))
+	}
+	prefix, bpErr := optionalString(ctx, "prefix", "")
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+	padding, bpErr := numberInput(ctx, "padding", 0)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+
+	value, version, err := nextInSequence(ctx, key, start, step)
+	if err != nil {
+		return failState(ctx, err)
+	}
+
+	formatted := prefix + fmt.Sprintf("%0*s", int(math.Max(padding, 0)), formatSequenceNumber(value))
+	ctx.SetOutputValue("value", types.NewValue(types.PinTypes.Number, value))
+	ctx.SetOutputValue("formatted", types.NewValue(types.PinTypes.String, formatted))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Sequence Number",
+		Value: map[string]interface{}{
+			"key":     key,
+			"value":   value,
+			"version": version,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// nextInSequence advances the sequence of a key, retrying when another execution advanced
+// it between the read and the write
+func nextInSequence(ctx node.ExecutionContext, key string, start, step float64) (float64, int64, *bperrors.BlueprintError) {
+	store := statestore.Default()
+	for attempt := 0; attempt < sequenceAttempts; attempt++ {
+		entry, exists, err := store.Get(ctx.Context(), ctx.GetBlueprintID(), key)
+		if err != nil {
+			return 0, 0, stateError(key, err)
+		}
+
+		next := start
+		if exists {
+			current, err := types.NewValue(types.PinTypes.Any, entry.Value).AsNumber()
+			if err != nil {
+				return 0, 0, bperrors.InvalidInput("key", fmt.Errorf("state %q doesn't hold a number: %w", key, err))
+			}
+			next = current + step
+		}
+
+		written, err := store.Set(ctx.Context(), ctx.GetBlueprintID(), key, next, entry.Version, ctx.GetExecutionID())
+		var conflict *statestore.ConflictError
+		if errors.As(err, &conflict) {
+			ctx.Logger().Debug("Sequence advanced concurrently, retrying", map[string]interface{}{
+				"key":     key,
+				"attempt": attempt + 1,
+			})
+			continue
+		}
+		if err != nil {
+			return 0, 0, stateError(key, err)
+		}
+		return next, written.Version, nil
+	}
+
+	return 0, 0, stateError(key, fmt.Errorf("sequence still contended after %d attempts", sequenceAttempts))
+}
+
+// formatSequenceNumber formats whole numbers without a fraction or an exponent
+func formatSequenceNumber(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return fmt.Sprintf("%d", int64(value))
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,218 @@
+package data_test
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/statestore"
+	"webblueprint/internal/test"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
+)
+
+// generate executes a generator node and returns the output of a pin and the activated flow
+func generate(t *testing.T, generator node.Node, inputs map[string]types.Value, pinID string) (interface{}, string) {
+	t.Helper()
+	ctx := mocks.NewMockExecutionContext("generator", generator.GetMetadata().TypeID, mocks.NewMockLogger())
+	for id, value := range inputs {
+		ctx.SetInputValue(id, value)
+	}
+	if err := generator.Execute(ctx); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	value, _ := ctx.GetOutputValue(pinID)
+	return value.RawValue, ctx.GetActivatedFlow()
+}
+
+func TestUUIDNode(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-([47])[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	for _, version := range []string{"v4", "v7", "7"} {
+		id, flow := generate(t, data.NewUUIDNode(), map[string]types.Value{
+			"version": types.NewValue(types.PinTypes.String, version),
+		}, "uuid")
+		match := uuidPattern.FindStringSubmatch(id.(string))
+		if flow != "then" || match == nil || match[1] != strings.TrimPrefix(version, "v") {
+			t.Errorf("Expected a %s UUID, got %v on %s", version, id, flow)
+		}
+	}
+
+	first, _ := generate(t, data.NewUUIDNode(), nil, "uuid")
+	second, _ := generate(t, data.NewUUIDNode(), nil, "uuid")
+	if first == second {
+		t.Error("Expected different UUIDs")
+	}
+
+	test.ExecuteNodeTestCase(t, data.NewUUIDNode(), test.NodeTestCase{
+		Name:         "unsupported version",
+		Inputs:       map[string]interface{}{"version": "v1"},
+		ExpectedFlow: "catch",
+	})
+}
+
+func TestRandomNumberNode(t *testing.T) {
+	seen := make(map[float64]bool)
+	for i := 0; i < 200; i++ {
+		value, flow := generate(t, data.NewRandomNumberNode(), map[string]types.Value{
+			"min":     types.NewValue(types.PinTypes.Number, 1.5),
+			"max":     types.NewValue(types.PinTypes.Number, 4),
+			"integer": types.NewValue(types.PinTypes.Boolean, true),
+		}, "value")
+		number := value.(float64)
+		if flow != "then" || number < 2 || number > 4 || number != float64(int(number)) {
+			t.Fatalf("Expected a whole number from 2 to 4, got %v on %s", number, flow)
+		}
+		seen[number] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected every number from 2 to 4, got %v", seen)
+	}
+
+	value, _ := generate(t, data.NewRandomNumberNode(), nil, "value")
+	if number := value.(float64); number < 0 || number >= 1 {
+		t.Errorf("Expected a number from 0 below 1, got %v", number)
+	}
+
+	testCases := []test.NodeTestCase{
+		{
+			Name:         "max below min",
+			Inputs:       map[string]interface{}{"min": 5, "max": 1},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "no whole number in range",
+			Inputs:       map[string]interface{}{"min": 1.2, "max": 1.8, "integer": true},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:            "single number",
+			Inputs:          map[string]interface{}{"min": 3, "max": 3, "integer": true},
+			ExpectedOutputs: map[string]interface{}{"value": 3.0},
+			ExpectedFlow:    "then",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewRandomNumberNode(), tc)
+		})
+	}
+}
+
+func TestRandomStringNode(t *testing.T) {
+	value, flow := generate(t, data.NewRandomStringNode(), nil, "value")
+	if flow != "then" || !regexp.MustCompile(`^[A-Za-z0-9]{16}$`).MatchString(value.(string)) {
+		t.Errorf("Expected 16 alphanumeric characters, got %v on %s", value, flow)
+	}
+
+	value, _ = generate(t, data.NewRandomStringNode(), map[string]types.Value{
+		"length":  types.NewValue(types.PinTypes.Number, 64),
+		"charset": types.NewValue(types.PinTypes.String, "ab✓"),
+	}, "value")
+	if text := value.(string); len([]rune(text)) != 64 || strings.Trim(text, "ab✓") != "" {
+		t.Errorf("Expected 64 characters of the charset, got %q", text)
+	}
+
+	testCases := []test.NodeTestCase{
+		{
+			Name:         "zero length",
+			Inputs:       map[string]interface{}{"length": 0},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "fractional length",
+			Inputs:       map[string]interface{}{"length": 2.5},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "single character",
+			Inputs:       map[string]interface{}{"charset": "aaa"},
+			ExpectedFlow: "catch",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewRandomStringNode(), tc)
+		})
+	}
+}
+
+func TestSequenceNumberNode(t *testing.T) {
+	statestore.SetDefault(statestore.NewStore(nil))
+	defer statestore.SetDefault(statestore.NewStore(nil))
+
+	// In order since they share the store
+	testCases := []test.NodeTestCase{
+		{
+			Name: "first number",
+			Inputs: map[string]interface{}{
+				"key":     "orders",
+				"start":   1000,
+				"prefix":  "ORD-",
+				"padding": 6,
+			},
+			ExpectedOutputs: map[string]interface{}{"value": 1000.0, "formatted": "ORD-001000"},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "next number",
+			Inputs:          map[string]interface{}{"key": "orders", "start": 1000, "step": 5},
+			ExpectedOutputs: map[string]interface{}{"value": 1005.0, "formatted": "1005"},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "other key",
+			Inputs:          map[string]interface{}{"key": "invoices"},
+			ExpectedOutputs: map[string]interface{}{"value": 1.0},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:         "negative step",
+			Inputs:       map[string]interface{}{"key": "orders", "step": -1},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "missing key",
+			Inputs:       map[string]interface{}{},
+			ExpectedFlow: "catch",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewSequenceNumberNode(), tc)
+		})
+	}
+
+	t.Run("concurrent executions", func(t *testing.T) {
+		const executions = 20
+		numbers := make(chan float64, executions)
+		var wg sync.WaitGroup
+		for i := 0; i < executions; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx := mocks.NewMockExecutionContext("sequence-number", "sequence-number", mocks.NewMockLogger())
+				ctx.SetInputValue("key", types.NewValue(types.PinTypes.String, "tickets"))
+				data.NewSequenceNumberNode().Execute(ctx)
+				if value, exists := ctx.GetOutputValue("value"); exists {
+					numbers <- value.RawValue.(float64)
+				}
+			}()
+		}
+		wg.Wait()
+		close(numbers)
+
+		seen := make(map[float64]bool)
+		for number := range numbers {
+			if seen[number] {
+				t.Errorf("Expected unique numbers, got %v twice", number)
+			}
+			seen[number] = true
+		}
+		if len(seen) == 0 {
+			t.Error("Expected the sequence to advance")
+		}
+	})
+}
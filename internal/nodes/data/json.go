@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -97,16 +98,16 @@ func (n *JSONNode) Execute(ctx node.ExecutionContext) error {
 
 	// Check required inputs
 	if !operationExists {
-		err := fmt.Errorf("missing required input: operation")
+		err := bperrors.MissingInput("operation")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	if !dataExists {
-		err := fmt.Errorf("missing required input: data")
+		err := bperrors.MissingInput("data")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
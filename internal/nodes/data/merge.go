@@ -0,0 +1,120 @@
+package data
+
+import (
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// mergeValues is the variadic group of the values a merge node merges
+var mergeValues = node.VariadicPins{
+	Prefix:      "value_",
+	Name:        "Value",
+	Description: "Value to merge",
+	Type:        types.PinTypes.Any,
+	CountKey:    "inputs",
+	Default:     2,
+	Min:         1,
+	Max:         32,
+}
+
+// MergeNode merges a variable number of values, declared in the node data:
+//
+//	{"inputs": 3}
+//
+// Objects are merged key by key and arrays are concatenated, later values winning.
+type MergeNode struct {
+	node.BaseNode
+	inputs int
+}
+
+// NewMergeNode creates a new Merge node
+func NewMergeNode() node.Node {
+	n := &MergeNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "merge",
+				Name:        "Merge",
+				Description: "Merges objects key by key and concatenates arrays from any number of inputs",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "result",
+					Name:        "Result",
+					Description: "Merged value",
+					Type:        types.PinTypes.Any,
+				},
+			},
+		},
+		inputs: mergeValues.Default,
+	}
+	n.Inputs = mergeInputPins(mergeValues.Default)
+	return n
+}
+
+// mergeInputPins returns the input pins of a merge node
+func mergeInputPins(count int) []types.Pin {
+	pins := []types.Pin{
+		{
+			ID:          "exec",
+			Name:        "Execute",
+			Description: "Execution input",
+			Type:        types.PinTypes.Execution,
+		},
+	}
+	return append(pins, mergeValues.Pins(count)...)
+}
+
+// VariadicPins returns the value inputs of the node
+func (n *MergeNode) VariadicPins() []node.VariadicPins {
+	return []node.VariadicPins{mergeValues}
+}
+
+// ConfigurePins declares the value inputs from the node data
+func (n *MergeNode) ConfigurePins(data map[string]interface{}) error {
+	count, err := mergeValues.Count(data)
+	if err != nil {
+		return err
+	}
+	n.inputs = count
+	n.Inputs = mergeInputPins(count)
+	return nil
+}
+
+// Execute runs the node logic
+func (n *MergeNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Merge node", map[string]interface{}{"inputs": n.inputs})
+
+	// Unconnected and null inputs don't take part in the merge
+	values := make([]types.Value, 0, n.inputs)
+	for i := 1; i <= n.inputs; i++ {
+		if value, exists := ctx.GetInputValue(mergeValues.PinID(i)); exists && !value.IsNull() {
+			values = append(values, value)
+		}
+	}
+
+	result := types.MergeValues(values)
+	ctx.SetOutputValue("result", result)
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Merge",
+		Value: map[string]interface{}{
+			"inputs": n.inputs,
+			"merged": len(values),
+			"result": result.RawValue,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
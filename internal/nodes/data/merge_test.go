@@ -0,0 +1,85 @@
+package data_test
+
+import (
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/test"
+)
+
+func TestMergeNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name: "merge objects",
+			Inputs: map[string]interface{}{
+				"value_1": map[string]interface{}{"name": "John", "age": 30.0},
+				"value_2": map[string]interface{}{"age": 31.0},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"result": map[string]interface{}{"name": "John", "age": 31.0},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "concatenate arrays",
+			Inputs: map[string]interface{}{
+				"value_1": []interface{}{1.0},
+				"value_2": []interface{}{2.0, 3.0},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"result": []interface{}{1.0, 2.0, 3.0},
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "skip unconnected values",
+			Inputs: map[string]interface{}{
+				"value_2": "only",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"result": "only",
+			},
+			ExpectedFlow: "then",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, data.NewMergeNode(), tc)
+		})
+	}
+}
+
+func TestMergeNodeConfiguration(t *testing.T) {
+	mergeNode := data.NewMergeNode()
+	if err := node.ConfigurePins(mergeNode, map[string]interface{}{"inputs": 4.0}); err != nil {
+		t.Fatalf("Failed to configure pins: %v", err)
+	}
+
+	pins := mergeNode.GetInputPins()
+	if len(pins) != 5 || pins[4].ID != "value_4" || !pins[4].Optional {
+		t.Errorf("Expected the execution input and 4 optional values, got %+v", pins)
+	}
+
+	test.ExecuteNodeTestCase(t, mergeNode, test.NodeTestCase{
+		Name: "merge the configured values",
+		Inputs: map[string]interface{}{
+			"value_1": map[string]interface{}{"a": 1.0},
+			"value_4": map[string]interface{}{"b": 2.0},
+		},
+		ExpectedOutputs: map[string]interface{}{
+			"result": map[string]interface{}{"a": 1.0, "b": 2.0},
+		},
+		ExpectedFlow: "then",
+	})
+
+	for name, config := range map[string]map[string]interface{}{
+		"no inputs":        {"inputs": 0.0},
+		"too many inputs":  {"inputs": 33.0},
+		"fractional count": {"inputs": 1.5},
+	} {
+		if err := node.ConfigurePins(data.NewMergeNode(), config); err == nil {
+			t.Errorf("%s: expected a configuration error", name)
+		}
+	}
+}
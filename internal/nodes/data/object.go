@@ -3,6 +3,7 @@ package data
 import (
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -99,9 +100,9 @@ func (n *ObjectNode) Execute(ctx node.ExecutionContext) error {
 	// Get operation value
 	operationValue, operationExists := ctx.GetInputValue("operation")
 	if !operationExists {
-		err := fmt.Errorf("missing required input: operation")
+		err := bperrors.MissingInput("operation")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -173,17 +174,17 @@ func (n *ObjectNode) handleGetOperation(ctx node.ExecutionContext, debugData map
 	// Get object and key values
 	objectValue, objectExists := ctx.GetInputValue("object")
 	if !objectExists {
-		err := fmt.Errorf("missing required input: object")
+		err := bperrors.MissingInput("object")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	keyValue, keyExists := ctx.GetInputValue("key")
 	if !keyExists {
-		err := fmt.Errorf("missing required input: key")
+		err := bperrors.MissingInput("key")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -236,25 +237,25 @@ func (n *ObjectNode) handleSetOperation(ctx node.ExecutionContext, debugData map
 	// Get object, key, and value
 	objectValue, objectExists := ctx.GetInputValue("object")
 	if !objectExists {
-		err := fmt.Errorf("missing required input: object")
+		err := bperrors.MissingInput("object")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	keyValue, keyExists := ctx.GetInputValue("key")
 	if !keyExists {
-		err := fmt.Errorf("missing required input: key")
+		err := bperrors.MissingInput("key")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	valueValue, valueExists := ctx.GetInputValue("value")
 	if !valueExists {
-		err := fmt.Errorf("missing required input: value")
+		err := bperrors.MissingInput("value")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -273,11 +274,8 @@ func (n *ObjectNode) handleSetOperation(ctx node.ExecutionContext, debugData map
 		return ctx.ActivateOutputFlow("error")
 	}
 
-	// Create a new object with the value set
-	newObj := make(map[string]interface{})
-	for k, v := range obj {
-		newObj[k] = v
-	}
+	// Set the value on a copy, the input object is shared with other nodes
+	newObj := types.CopyObject(obj)
 	newObj[key] = valueValue.RawValue
 
 	// Set output value
@@ -307,17 +305,17 @@ func (n *ObjectNode) handleDeleteOperation(ctx node.ExecutionContext, debugData
 	// Get object and key
 	objectValue, objectExists := ctx.GetInputValue("object")
 	if !objectExists {
-		err := fmt.Errorf("missing required input: object")
+		err := bperrors.MissingInput("object")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	keyValue, keyExists := ctx.GetInputValue("key")
 	if !keyExists {
-		err := fmt.Errorf("missing required input: key")
+		err := bperrors.MissingInput("key")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -336,13 +334,9 @@ func (n *ObjectNode) handleDeleteOperation(ctx node.ExecutionContext, debugData
 		return ctx.ActivateOutputFlow("error")
 	}
 
-	// Create a new object without the key
-	newObj := make(map[string]interface{})
-	for k, v := range obj {
-		if k != key {
-			newObj[k] = v
-		}
-	}
+	// Delete the key from a copy, the input object is shared with other nodes
+	newObj := types.CopyObject(obj)
+	delete(newObj, key)
 
 	// Set output value
 	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.Object, newObj))
@@ -370,17 +364,17 @@ func (n *ObjectNode) handleHasOperation(ctx node.ExecutionContext, debugData map
 	// Get object and key
 	objectValue, objectExists := ctx.GetInputValue("object")
 	if !objectExists {
-		err := fmt.Errorf("missing required input: object")
+		err := bperrors.MissingInput("object")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	keyValue, keyExists := ctx.GetInputValue("key")
 	if !keyExists {
-		err := fmt.Errorf("missing required input: key")
+		err := bperrors.MissingInput("key")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
@@ -428,9 +422,9 @@ func (n *ObjectNode) handleKeysOperation(ctx node.ExecutionContext, debugData ma
 	// Get object
 	objectValue, objectExists := ctx.GetInputValue("object")
 	if !objectExists {
-		err := fmt.Errorf("missing required input: object")
+		err := bperrors.MissingInput("object")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
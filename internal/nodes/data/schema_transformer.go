@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/db"
 	"webblueprint/internal/engineext"
 	"webblueprint/internal/node"
@@ -73,6 +74,7 @@ func NewSchemaNode() node.Node {
 					Description: "Details about any error encountered.",
 					Type:        types.PinTypes.String,
 				},
+				bperrors.ErrorPin(),
 			},
 			// Updated Properties
 			Properties: []types.Property{
@@ -112,40 +114,35 @@ func (n *SchemaNode) Execute(ctx node.ExecutionContext) error {
 
 	// Use updated property name in error message
 	if !foundProp || schemaID == "" {
-		errMsg := "Schema Component property ('schemaComponentId') is not set or invalid"
-		logger.Error(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, bperrors.New(
+			bperrors.ErrorTypeValidation,
+			bperrors.ErrMissingProperty,
+			"Schema Component property ('schemaComponentId') is not set or invalid",
+			bperrors.SeverityMedium,
+		))
 	}
 
 	// --- 2. Get Schema Component Store from Context ---
 	// Use the standard SchemaAccessContext interface from node package
 	schemaCtx, ok := engineext.GetExtendedContext(ctx).(node.SchemaAccessContext)
 	if !ok {
-		errMsg := "Execution context does not support schema component access"
-		logger.Error(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, bperrors.ServiceUnavailable("schema component access"))
 	}
 	schemaStore := schemaCtx.GetSchemaComponentStore()
 	if schemaStore == nil {
-		errMsg := "Schema Component Store is not available in the execution context"
-		logger.Error(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, bperrors.ServiceUnavailable("Schema Component Store"))
 	}
 
 	// --- 3. Fetch Schema Definition from DB ---
 	schemaComponent, err := schemaStore.GetSchemaComponent(schemaID)
 	if err != nil {
-		errMsg := fmt.Sprintf("Failed to fetch schema component %s: %v", schemaID, err)
-		logger.Error(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, bperrors.Wrap(
+			err,
+			bperrors.ErrorTypeDatabase,
+			bperrors.ErrDatabaseQuery,
+			fmt.Sprintf("Failed to fetch schema component %s: %v", schemaID, err),
+			bperrors.SeverityHigh,
+		))
 	}
 
 	// --- 4. Compile the Schema (assuming JSON Schema validation for now) ---
@@ -154,29 +151,17 @@ func (n *SchemaNode) Execute(ctx node.ExecutionContext) error {
 	compiler := jsonschema.NewCompiler()
 	err = compiler.AddResource("schema.json", strings.NewReader(schemaComponent.SchemaDefinition))
 	if err != nil {
-		errMsg := fmt.Sprintf("Failed to add schema resource for %s: %v", schemaID, err)
-		logger.Error(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, invalidSchema(fmt.Sprintf("Failed to add schema resource for %s", schemaID), err))
 	}
 	schema, err := compiler.Compile("schema.json")
 	if err != nil {
-		errMsg := fmt.Sprintf("Failed to compile schema %s: %v", schemaID, err)
-		logger.Error(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, invalidSchema(fmt.Sprintf("Failed to compile schema %s", schemaID), err))
 	}
 
 	// --- 5. Get Input Data (use updated pin ID 'value') ---
 	inputValue, exists := ctx.GetInputValue("value")
 	if !exists {
-		errMsg := "Input value 'value' is missing" // Updated pin name in message
-		logger.Warn(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, bperrors.MissingInput("value"))
 	}
 
 	// --- 6. Prepare Input Data for Validation/Transformation ---
@@ -208,11 +193,7 @@ func (n *SchemaNode) Execute(ctx node.ExecutionContext) error {
 	// --- 7. Validate Data using JSON Schema ---
 	validationErr := schema.Validate(dataToProcess)
 	if validationErr != nil {
-		errMsg := fmt.Sprintf("Schema validation failed: %v", validationErr)
-		logger.Warn(errMsg, nil)
-		// Use updated error pins
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, errMsg))
-		return ctx.ActivateOutputFlow("onError")
+		return n.fail(ctx, bperrors.InvalidInput("value", fmt.Errorf("schema validation failed: %w", validationErr)))
 	}
 
 	// --- 8. Transformation ---
@@ -249,3 +230,22 @@ func (n *SchemaNode) Execute(ctx node.ExecutionContext) error {
 
 	return ctx.ActivateOutputFlow("then") // Use 'then' output pin
 }
+
+// fail sets the error outputs and activates the error flow
+func (n *SchemaNode) fail(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow("onError")
+}
+
+// invalidSchema creates the error of a schema component whose definition can't be compiled
+func invalidSchema(message string, err error) *bperrors.BlueprintError {
+	return bperrors.Wrap(
+		err,
+		bperrors.ErrorTypeValidation,
+		bperrors.ErrInvalidNodeConfiguration,
+		fmt.Sprintf("%s: %v", message, err),
+		bperrors.SeverityHigh,
+	)
+}
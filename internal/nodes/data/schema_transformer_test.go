@@ -0,0 +1,48 @@
+package data_test
+
+import (
+	"testing"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/test/mocks"
+)
+
+func TestSchemaNodeErrorPin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schemaID string
+		code     string
+	}{
+		{name: "missing schema component", schemaID: "", code: "V003"},
+		{name: "no schema component access", schemaID: "schema-1", code: "E008"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schemaNode := data.NewSchemaNode().(*data.SchemaNode)
+			schemaNode.SetProperty("schemaComponentId", tc.schemaID)
+
+			ctx := mocks.NewMockExecutionContext("transform", "schema-transformer", mocks.NewMockLogger())
+			if err := schemaNode.Execute(ctx); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ctx.GetActivatedFlow() != "onError" {
+				t.Fatalf("Expected onError flow, got %q", ctx.GetActivatedFlow())
+			}
+
+			errorValue, exists := ctx.GetOutputValue("error")
+			if !exists {
+				t.Fatal("Expected the error pin to be set")
+			}
+			info, ok := errorValue.RawValue.(map[string]interface{})
+			if !ok {
+				t.Fatalf("Expected structured error info, got %T", errorValue.RawValue)
+			}
+			if info["code"] != tc.code || info["nodeId"] != "transform" {
+				t.Errorf("Unexpected error info: %+v", info)
+			}
+			if message, _ := ctx.GetOutputValue("errorMessage"); message.RawValue == "" {
+				t.Error("Expected the error message to be set")
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/statestore"
+	"webblueprint/internal/types"
+	"webblueprint/internal/usage"
+)
+
+// StateGetNode implements a node that reads a value the blueprint keeps across executions
+type StateGetNode struct {
+	node.BaseNode
+}
+
+// NewStateGetNode creates a new State Get node
+func NewStateGetNode() node.Node {
+	return &StateGetNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "state-get",
+				Name:        "Get State",
+				Description: "Gets a value the blueprint keeps across executions",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "key",
+					Name:        "Key",
+					Description: "Key of the state value",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "default",
+					Name:        "Default",
+					Description: "Value to output if the key isn't set",
+					Type:        types.PinTypes.Any,
+					Optional:    true,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "catch",
+					Name:        "Catch",
+					Description: "Executed if the state can't be read",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "value",
+					Name:        "Value",
+					Description: "State value, or the default if the key isn't set",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					ID:          "version",
+					Name:        "Version",
+					Description: "Version of the value to pass to Set State, 0 if the key isn't set",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "exists",
+					Name:        "Exists",
+					Description: "Whether the key is set",
+					Type:        types.PinTypes.Boolean,
+				},
+				bperrors.ErrorPin(),
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *StateGetNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Get State node", nil)
+
+	key, bpErr := stateKey(ctx)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+
+	store := statestore.Default()
+	entry, exists, err := store.Get(ctx.Context(), ctx.GetBlueprintID(), key)
+	if err != nil {
+		return failState(ctx, stateError(key, err))
+	}
+	if exists && store.Durable() {
+		usage.Record(ctx.GetExecutionID(), usage.MetricDBRowsRead, 1)
+	}
+
+	value := types.NewValue(types.PinTypes.Any, entry.Value)
+	if !exists {
+		value = types.NewValue(types.PinTypes.Any, nil)
+		if defaultValue, hasDefault := ctx.GetInputValue("default"); hasDefault {
+			value = defaultValue
+		}
+	}
+
+	ctx.SetOutputValue("value", value)
+	ctx.SetOutputValue("version", types.NewValue(types.PinTypes.Number, float64(entry.Version)))
+	ctx.SetOutputValue("exists", types.NewValue(types.PinTypes.Boolean, exists))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "State Get",
+		Value: map[string]interface{}{
+			"key":     key,
+			"exists":  exists,
+			"version": entry.Version,
+			"value":   value.RawValue,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// StateSetNode implements a node that writes a value the blueprint keeps across executions
+type StateSetNode struct {
+	node.BaseNode
+}
+
+// NewStateSetNode creates a new State Set node
+func NewStateSetNode() node.Node {
+	return &StateSetNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "state-set",
+				Name:        "Set State",
+				Description: "Sets a value the blueprint keeps across executions",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "key",
+					Name:        "Key",
+					Description: "Key of the state value",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "value",
+					Name:        "Value",
+					Description: "Value to keep; it must be JSON serializable",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					ID:          "expectedVersion",
+					Name:        "Expected Version",
+					Description: "Version read by Get State, 0 to only set a key that isn't set. Without it the value is written whatever its version.",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues once the value is written",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "conflict",
+					Name:        "Conflict",
+					Description: "Executed if the key isn't at the expected version, e.g. because another execution wrote it",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "catch",
+					Name:        "Catch",
+					Description: "Executed if the state can't be written",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "version",
+					Name:        "Version",
+					Description: "New version of the value, or its current version on a conflict",
+					Type:        types.PinTypes.Number,
+				},
+				bperrors.ErrorPin(),
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *StateSetNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Set State node", nil)
+
+	key, bpErr := stateKey(ctx)
+	if bpErr != nil {
+		return failState(ctx, bpErr)
+	}
+
+	value, exists := ctx.GetInputValue("value")
+	if !exists {
+		return failState(ctx, bperrors.MissingInput("value"))
+	}
+
+	expectedVersion := statestore.AnyVersion
+	if versionValue, hasVersion := ctx.GetInputValue("expectedVersion"); hasVersion && versionValue.RawValue != nil {
+		version, err := versionValue.AsNumber()
+		if err != nil || version < 0 {
+			if err == nil {
+				err = fmt.Errorf("%v is negative", version)
+			}
+			return failState(ctx, bperrors.InvalidInput("expectedVersion", err))
+		}
+		expectedVersion = int64(version)
+	}
+
+	entry, err := statestore.Default().Set(ctx.Context(), ctx.GetBlueprintID(), key, value.RawValue, expectedVersion, ctx.GetExecutionID())
+
+	var conflict *statestore.ConflictError
+	if errors.As(err, &conflict) {
+		logger.Info("State version conflict", map[string]interface{}{
+			"key":      key,
+			"expected": conflict.Expected,
+			"actual":   conflict.Actual,
+		})
+		ctx.SetOutputValue("version", types.NewValue(types.PinTypes.Number, float64(conflict.Actual)))
+		return ctx.ActivateOutputFlow("conflict")
+	}
+	if err != nil {
+		return failState(ctx, stateError(key, err))
+	}
+
+	ctx.SetOutputValue("version", types.NewValue(types.PinTypes.Number, float64(entry.Version)))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "State Set",
+		Value: map[string]interface{}{
+			"key":     key,
+			"version": entry.Version,
+			"value":   value.RawValue,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// stateKey reads the key input of a state node
+func stateKey(ctx node.ExecutionContext) (string, *bperrors.BlueprintError) {
+	keyValue, exists := ctx.GetInputValue("key")
+	if !exists {
+		return "", bperrors.MissingInput("key")
+	}
+
+	key, err := keyValue.AsString()
+	if err != nil {
+		return "", bperrors.InvalidInput("key", err)
+	}
+	if key == "" {
+		return "", bperrors.InvalidInput("key", errors.New("key is empty"))
+	}
+	return key, nil
+}
+
+// stateError creates the error of a state value the store couldn't read or write
+func stateError(key string, err error) *bperrors.BlueprintError {
+	return bperrors.Wrap(err, bperrors.ErrorTypeDatabase, bperrors.ErrDatabaseQuery, fmt.Sprintf("state %q: %v", key, err), bperrors.SeverityHigh).
+		WithRecoveryOptions(bperrors.RecoveryRetry)
+}
+
+func failState(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow("catch")
+}
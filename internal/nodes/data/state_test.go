@@ -0,0 +1,132 @@
+package data_test
+
+import (
+	"testing"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/statestore"
+	"webblueprint/internal/test"
+)
+
+func TestStateNodes(t *testing.T) {
+	statestore.SetDefault(statestore.NewStore(nil))
+	defer statestore.SetDefault(statestore.NewStore(nil))
+
+	// StateSetNode tests, in order since they share the store
+	t.Run("StateSetNode", func(t *testing.T) {
+		testCases := []test.NodeTestCase{
+			{
+				Name: "create key",
+				Inputs: map[string]interface{}{
+					"key":             "counter",
+					"value":           1.0,
+					"expectedVersion": 0,
+				},
+				ExpectedOutputs: map[string]interface{}{
+					"version": 1.0,
+				},
+				ExpectedFlow: "then",
+			},
+			{
+				Name: "create key that is set",
+				Inputs: map[string]interface{}{
+					"key":             "counter",
+					"value":           1.0,
+					"expectedVersion": 0,
+				},
+				ExpectedOutputs: map[string]interface{}{
+					"version": 1.0,
+				},
+				ExpectedFlow: "conflict",
+			},
+			{
+				Name: "update expected version",
+				Inputs: map[string]interface{}{
+					"key":             "counter",
+					"value":           2.0,
+					"expectedVersion": 1,
+				},
+				ExpectedOutputs: map[string]interface{}{
+					"version": 2.0,
+				},
+				ExpectedFlow: "then",
+			},
+			{
+				Name: "update any version",
+				Inputs: map[string]interface{}{
+					"key":   "counter",
+					"value": 3.0,
+				},
+				ExpectedOutputs: map[string]interface{}{
+					"version": 3.0,
+				},
+				ExpectedFlow: "then",
+			},
+			{
+				Name: "missing key",
+				Inputs: map[string]interface{}{
+					"value": 1.0,
+				},
+				ExpectedFlow: "catch",
+			},
+			{
+				Name: "negative expected version",
+				Inputs: map[string]interface{}{
+					"key":             "counter",
+					"value":           1.0,
+					"expectedVersion": -1,
+				},
+				ExpectedFlow: "catch",
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				node := data.NewStateSetNode()
+				test.ExecuteNodeTestCase(t, node, tc)
+			})
+		}
+	})
+
+	// StateGetNode tests
+	t.Run("StateGetNode", func(t *testing.T) {
+		testCases := []test.NodeTestCase{
+			{
+				Name: "get set key",
+				Inputs: map[string]interface{}{
+					"key": "counter",
+				},
+				ExpectedOutputs: map[string]interface{}{
+					"value":   3.0,
+					"version": 3.0,
+					"exists":  true,
+				},
+				ExpectedFlow: "then",
+			},
+			{
+				Name: "get key that isn't set",
+				Inputs: map[string]interface{}{
+					"key":     "cursor",
+					"default": "start",
+				},
+				ExpectedOutputs: map[string]interface{}{
+					"value":   "start",
+					"version": 0.0,
+					"exists":  false,
+				},
+				ExpectedFlow: "then",
+			},
+			{
+				Name:         "missing key",
+				Inputs:       map[string]interface{}{},
+				ExpectedFlow: "catch",
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				node := data.NewStateGetNode()
+				test.ExecuteNodeTestCase(t, node, tc)
+			})
+		}
+	})
+}
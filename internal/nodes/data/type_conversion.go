@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -104,16 +105,16 @@ func (n *TypeConversionNode) Execute(ctx node.ExecutionContext) error {
 
 	// Check required inputs
 	if !inputExists {
-		err := fmt.Errorf("missing required input: input")
+		err := bperrors.MissingInput("input")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
 	if !targetTypeExists {
-		err := fmt.Errorf("missing required input: targetType")
+		err := bperrors.MissingInput("targetType")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("error")
 	}
 
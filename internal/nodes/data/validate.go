@@ -0,0 +1,332 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"time"
+	"unicode/utf8"
+	"webblueprint/internal/expr"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// ValidationRule is a rule of a validate-data node, declared in the node data. The checks
+// of a rule apply to the value of its field, and only if it has one, except required.
+type ValidationRule struct {
+	Field     string        `json:"field,omitempty"` // Dot-separated path, the item itself if empty
+	Required  bool          `json:"required,omitempty"`
+	Type      string        `json:"type,omitempty"` // string, number, boolean, object or array
+	Pattern   string        `json:"pattern,omitempty"`
+	Min       *float64      `json:"min,omitempty"`
+	Max       *float64      `json:"max,omitempty"`
+	MinLength *int          `json:"minLength,omitempty"` // Of strings, in characters, and arrays
+	MaxLength *int          `json:"maxLength,omitempty"`
+	OneOf     []interface{} `json:"oneOf,omitempty"`
+	Condition string        `json:"condition,omitempty"` // Expression on the whole item, as value
+	Message   string        `json:"message,omitempty"`   // Replaces the message of the violations
+
+	pattern   *regexp.Regexp
+	condition *expr.Expression
+}
+
+// Violation is a rule an item broke
+type Violation struct {
+	Path    string      `json:"path"`
+	Rule    string      `json:"rule"`
+	Message string      `json:"message"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+// validationTypes are the types rules can require
+var validationTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+}
+
+// ValidateDataNode checks an object, or every item of an array, against rules declared in
+// the node data:
+//
+//	{"rules": [
+//	  {"field": "email", "required": true, "pattern": "^[^@]+@[^@]+$"},
+//	  {"field": "amount", "type": "number", "min": 0, "max": 10000},
+//	  {"field": "amount", "condition": "value.type != 'refund' || value.amount < 0", "message": "refunds must be negative"}
+//	]}
+//
+// The data continues on valid if it broke no rule and on invalid otherwise, with the
+// violations of all the rules.
+type ValidateDataNode struct {
+	node.BaseNode
+	rules []ValidationRule
+}
+
+// NewValidateDataNode creates a new Validate Data node
+func NewValidateDataNode() node.Node {
+	return &ValidateDataNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "validate-data",
+				Name:        "Validate Data",
+				Description: "Checks an object or the items of an array against rules defined on the node",
+				Category:    "Data",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "data",
+					Name:        "Data",
+					Description: "Object, or array of objects, to validate",
+					Type:        types.PinTypes.Any,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "valid",
+					Name:        "Valid",
+					Description: "Executed if the data broke no rule",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "invalid",
+					Name:        "Invalid",
+					Description: "Executed if the data broke a rule",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "passed",
+					Name:        "Passed",
+					Description: "Whether the data broke no rule",
+					Type:        types.PinTypes.Boolean,
+				},
+				{
+					ID:          "violations",
+					Name:        "Violations",
+					Description: "Rules the data broke, with the path, rule and message of each",
+					Type:        types.PinTypes.Array,
+				},
+			},
+		},
+	}
+}
+
+// ConfigurePins reads the rules from the node data, rejecting rules that don't compile
+func (n *ValidateDataNode) ConfigurePins(data map[string]interface{}) error {
+	rules, err := parseValidationRules(data["rules"])
+	if err != nil {
+		return err
+	}
+	n.rules = rules
+	return nil
+}
+
+// parseValidationRules reads the rules of a validate-data node from its data
+func parseValidationRules(raw interface{}) ([]ValidationRule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	var rules []ValidationRule
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Type != "" && !validationTypes[rule.Type] {
+			return nil, fmt.Errorf("rule %d: unknown type %q", i+1, rule.Type)
+		}
+		if rule.Pattern != "" {
+			if rule.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern: %w", i+1, err)
+			}
+		}
+		if rule.Condition != "" {
+			if rule.condition, err = expr.Parse(rule.Condition); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid condition: %w", i+1, err)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// Execute runs the node logic
+func (n *ValidateDataNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Validate Data node", map[string]interface{}{"rules": len(n.rules)})
+
+	var data interface{}
+	if value, exists := ctx.GetInputValue("data"); exists {
+		data = value.RawValue
+	}
+
+	violations := make([]Violation, 0)
+	if items, ok := data.([]interface{}); ok {
+		for i, item := range items {
+			violations = append(violations, n.validate(item, fmt.Sprintf("[%d]", i))...)
+		}
+	} else {
+		violations = n.validate(data, "")
+	}
+
+	encoded := make([]interface{}, len(violations))
+	for i, violation := range violations {
+		entry := map[string]interface{}{
+			"path":    violation.Path,
+			"rule":    violation.Rule,
+			"message": violation.Message,
+		}
+		if violation.Value != nil {
+			entry["value"] = violation.Value
+		}
+		encoded[i] = entry
+	}
+
+	passed := len(violations) == 0
+	ctx.SetOutputValue("passed", types.NewValue(types.PinTypes.Boolean, passed))
+	ctx.SetOutputValue("violations", types.NewValue(types.PinTypes.Array, encoded))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Data Validation",
+		Value: map[string]interface{}{
+			"rules":      len(n.rules),
+			"passed":     passed,
+			"violations": encoded,
+		},
+		Timestamp: time.Now(),
+	})
+
+	if !passed {
+		return ctx.ActivateOutputFlow("invalid")
+	}
+	return ctx.ActivateOutputFlow("valid")
+}
+
+// validate checks an item against the rules, prefixing the paths of its violations
+func (n *ValidateDataNode) validate(item interface{}, prefix string) []Violation {
+	violations := make([]Violation, 0)
+	for _, rule := range n.rules {
+		path := joinPath(prefix, rule.Field)
+		value, exists := fieldOf(item, rule.Field)
+		if !exists || value == nil {
+			if rule.Required {
+				violations = append(violations, rule.violation(path, "required", "is required", nil))
+				continue
+			}
+			if rule.condition == nil {
+				continue
+			}
+		} else if violation, broken := rule.check(value); broken {
+			violation.Path = path
+			violations = append(violations, violation)
+			continue
+		}
+
+		if rule.condition != nil {
+			result, err := rule.condition.Eval(item)
+			if err != nil {
+				violations = append(violations, rule.violation(path, "condition", fmt.Sprintf("condition failed: %v", err), nil))
+			} else if !expr.Truthy(result) {
+				violations = append(violations, rule.violation(path, "condition", "must satisfy "+rule.Condition, nil))
+			}
+		}
+	}
+	return violations
+}
+
+// check checks a value against the checks of the rule, returning the first it broke
+func (r ValidationRule) check(value interface{}) (Violation, bool) {
+	if r.Type != "" && types.InferPinType(value).ID != r.Type {
+		return r.violation("", "type", "must be a "+r.Type, value), true
+	}
+
+	if r.pattern != nil {
+		text, ok := value.(string)
+		if !ok || !r.pattern.MatchString(text) {
+			return r.violation("", "pattern", "must match "+r.Pattern, value), true
+		}
+	}
+
+	if r.Min != nil || r.Max != nil {
+		number, ok := numberOf(value)
+		switch {
+		case !ok:
+			return r.violation("", "type", "must be a number", value), true
+		case r.Min != nil && number < *r.Min:
+			return r.violation("", "min", fmt.Sprintf("must be at least %v", *r.Min), value), true
+		case r.Max != nil && number > *r.Max:
+			return r.violation("", "max", fmt.Sprintf("must be at most %v", *r.Max), value), true
+		}
+	}
+
+	if r.MinLength != nil || r.MaxLength != nil {
+		length := -1
+		switch v := value.(type) {
+		case string:
+			length = utf8.RuneCountInString(v)
+		case []interface{}:
+			length = len(v)
+		}
+		switch {
+		case length < 0:
+			return r.violation("", "type", "must be a string or an array", value), true
+		case r.MinLength != nil && length < *r.MinLength:
+			return r.violation("", "minLength", fmt.Sprintf("must have a length of at least %d", *r.MinLength), value), true
+		case r.MaxLength != nil && length > *r.MaxLength:
+			return r.violation("", "maxLength", fmt.Sprintf("must have a length of at most %d", *r.MaxLength), value), true
+		}
+	}
+
+	if len(r.OneOf) > 0 {
+		for _, allowed := range r.OneOf {
+			if oneOfEqual(allowed, value) {
+				return Violation{}, false
+			}
+		}
+		return r.violation("", "oneOf", fmt.Sprintf("must be one of %v", r.OneOf), value), true
+	}
+
+	return Violation{}, false
+}
+
+// oneOfEqual compares an allowed value of a rule to a value, numbers by their value
+func oneOfEqual(allowed, value interface{}) bool {
+	if types.InferPinType(allowed) == types.PinTypes.Number && types.InferPinType(value) == types.PinTypes.Number {
+		a, _ := numberOf(allowed)
+		b, _ := numberOf(value)
+		return a == b
+	}
+	return reflect.DeepEqual(allowed, value)
+}
+
+// violation creates a violation of the rule, with its message if it has one
+func (r ValidationRule) violation(path, rule, message string, value interface{}) Violation {
+	if r.Message != "" {
+		message = r.Message
+	}
+	return Violation{Path: path, Rule: rule, Message: message, Value: value}
+}
+
+// joinPath joins the path of an item and of a field in it
+func joinPath(prefix, field string) string {
+	if prefix == "" || field == "" {
+		return prefix + field
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,95 @@
+package data_test
+
+import (
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/data"
+	"webblueprint/internal/test"
+)
+
+var webhookRules = map[string]interface{}{
+	"rules": []interface{}{
+		map[string]interface{}{"field": "email", "required": true, "pattern": "^[^@]+@[^@]+$"},
+		map[string]interface{}{"field": "amount", "type": "number", "min": 0.0, "max": 100.0},
+		map[string]interface{}{"field": "currency", "oneOf": []interface{}{"EUR", "USD"}},
+		map[string]interface{}{"field": "tags", "maxLength": 2.0},
+		map[string]interface{}{"field": "refund", "condition": "!value.refund || value.amount > 0", "message": "refunds need an amount"},
+	},
+}
+
+func TestValidateDataNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name: "valid object",
+			Inputs: map[string]interface{}{
+				"data": map[string]interface{}{"email": "a@b.c", "amount": 10.0, "currency": "EUR", "tags": []interface{}{"x"}},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"passed":     true,
+				"violations": []interface{}{},
+			},
+			ExpectedFlow: "valid",
+		},
+		{
+			Name: "broken rules",
+			Inputs: map[string]interface{}{
+				"data": map[string]interface{}{"amount": 200.0, "currency": "GBP", "tags": []interface{}{"x", "y", "z"}, "refund": true},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"passed": false,
+				"violations": []interface{}{
+					map[string]interface{}{"path": "email", "rule": "required", "message": "is required"},
+					map[string]interface{}{"path": "amount", "rule": "max", "message": "must be at most 100", "value": 200.0},
+					map[string]interface{}{"path": "currency", "rule": "oneOf", "message": "must be one of [EUR USD]", "value": "GBP"},
+					map[string]interface{}{"path": "tags", "rule": "maxLength", "message": "must have a length of at most 2", "value": []interface{}{"x", "y", "z"}},
+				},
+			},
+			ExpectedFlow: "invalid",
+		},
+		{
+			Name: "items of an array",
+			Inputs: map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"email": "a@b.c"},
+					map[string]interface{}{"email": "nope", "amount": "5"},
+					map[string]interface{}{"email": "c@d.e", "refund": true, "amount": 0.0},
+				},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"passed": false,
+				"violations": []interface{}{
+					map[string]interface{}{"path": "[1].email", "rule": "pattern", "message": "must match ^[^@]+@[^@]+$", "value": "nope"},
+					map[string]interface{}{"path": "[1].amount", "rule": "type", "message": "must be a number", "value": "5"},
+					map[string]interface{}{"path": "[2].refund", "rule": "condition", "message": "refunds need an amount"},
+				},
+			},
+			ExpectedFlow: "invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			validateNode := data.NewValidateDataNode()
+			if err := node.ConfigurePins(validateNode, webhookRules); err != nil {
+				t.Fatalf("Failed to configure rules: %v", err)
+			}
+			test.ExecuteNodeTestCase(t, validateNode, tc)
+		})
+	}
+}
+
+func TestValidateDataNodeInvalidRules(t *testing.T) {
+	for name, rule := range map[string]map[string]interface{}{
+		"unknown key":       {"field": "a", "requird": true},
+		"unknown type":      {"field": "a", "type": "date"},
+		"invalid pattern":   {"field": "a", "pattern": "("},
+		"invalid condition": {"condition": "value.a >"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := node.ConfigurePins(data.NewValidateDataNode(), map[string]interface{}{"rules": []interface{}{rule}})
+			if err == nil {
+				t.Error("Expected the rules to be rejected")
+			}
+		})
+	}
+}
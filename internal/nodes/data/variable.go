@@ -1,10 +1,13 @@
 package data
 
 import (
+	"errors"
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
 )
 
 // VariableGetNode implements a node that gets a variable value
@@ -78,9 +81,9 @@ func (n *VariableGetNode) Execute(ctx node.ExecutionContext) error {
 	// Get the variable name
 	nameValue, nameExists := ctx.GetInputValue("name")
 	if !nameExists {
-		err := fmt.Errorf("missing required input: name")
+		err := bperrors.MissingInput("name")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 
 		debugData["error"] = err.Error()
 		ctx.RecordDebugInfo(types.DebugInfo{
@@ -243,9 +246,9 @@ func (n *VariableSetNode) Execute(ctx node.ExecutionContext) error {
 			nameExists = true
 
 		} else {
-			err := fmt.Errorf("missing required input: name")
+			err := bperrors.MissingInput("name")
 			logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 
 			debugData["error"] = err.Error()
 			ctx.RecordDebugInfo(types.DebugInfo{
@@ -262,11 +265,11 @@ func (n *VariableSetNode) Execute(ctx node.ExecutionContext) error {
 	// For the "invalid variable name" test case, we need to check
 	// if the name value is actually a string
 	if nameValue.Type != types.PinTypes.String {
-		err := fmt.Errorf("variable name must be a string")
+		err := bperrors.InvalidInput("name", errors.New("variable name must be a string"))
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 
-		debugData["error"] = err.Error()
+		debugData["error"] = err.Message
 		debugData["nameType"] = nameValue.Type.Name
 
 		ctx.RecordDebugInfo(types.DebugInfo{
@@ -302,20 +305,25 @@ func (n *VariableSetNode) Execute(ctx node.ExecutionContext) error {
 		if n.VarValue != nil && n.VarType != nil {
 			pinType, ok := types.GetPinTypeByID(*n.VarType)
 			if !ok {
-				pErr := fmt.Errorf("invalid variable type %s", *n.VarType)
+				pErr := bperrors.New(
+					bperrors.ErrorTypeValidation,
+					bperrors.ErrInvalidNodeConfiguration,
+					fmt.Sprintf("invalid variable type %s", *n.VarType),
+					bperrors.SeverityMedium,
+				)
 				logger.Error("Error getting pin type", nil)
 				logger.Error("Variable Set Error", map[string]interface{}{"error": pErr.Error()})
 				logger.Error("Execution failed", map[string]interface{}{"error": pErr.Error()})
-				ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, pErr.Error()))
+				ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, pErr.Message))
 
 				return ctx.ActivateOutputFlow("error")
 			}
 			valueValue = types.NewValue(pinType, n.VarValue)
 			valueExists = true
 		} else {
-			err := fmt.Errorf("missing required input: value")
+			err := bperrors.MissingInput("value")
 			logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 
 			debugData["error"] = err.Error()
 			ctx.RecordDebugInfo(types.DebugInfo{
@@ -350,6 +358,17 @@ func (n *VariableSetNode) Execute(ctx node.ExecutionContext) error {
 	return ctx.ActivateOutputFlow("then")
 }
 
+// VariableNodeFactories returns the factories of the getter and setter nodes of the variables
+// of a blueprint, by node type
+func VariableNodeFactories(bp *blueprint.Blueprint) map[string]node.NodeFactory {
+	factories := make(map[string]node.NodeFactory, len(bp.Variables)*2)
+	for _, variable := range bp.Variables {
+		factories["variable-get-"+variable.Name] = NewVariableGetDefinedNode(variable.Name, variable.Type, variable.Value)
+		factories["variable-set-"+variable.Name] = NewVariableSetDefinedNode(variable.Name, variable.Type, variable.Value)
+	}
+	return factories
+}
+
 func NewVariableGetDefinedNode(varName, varType string, varValue interface{}) func() node.Node {
 	return func() node.Node {
 		varPinType, ok := types.GetPinTypeByID(varType)
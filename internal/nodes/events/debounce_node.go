@@ -0,0 +1,61 @@
+package events
+
+import (
+	"time"
+	"webblueprint/internal/node"
+)
+
+// defaultDebounceWait is the wait of a debounce node without one in its data
+const defaultDebounceWait = 300 * time.Millisecond
+
+// DebounceNode coalesces bursts of triggers into one activation, which runs once no
+// trigger arrived for the wait configured in the node data:
+//
+//	{"wait": 500}
+//
+// The engine holds the triggers back through its timer service and runs the node with
+// the inputs of the latest one.
+type DebounceNode struct {
+	node.BaseNode
+	wait time.Duration
+}
+
+// NewDebounceNode creates a new debounce node
+func NewDebounceNode() node.Node {
+	inputs, outputs := rateLimitedPins()
+	return &DebounceNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "debounce",
+				Name:        "Debounce",
+				Description: "Continues once the triggers paused for a while, with the latest one",
+				Category:    "Events",
+				Version:     "1.0.0",
+			},
+			Inputs:  inputs,
+			Outputs: outputs,
+		},
+		wait: defaultDebounceWait,
+	}
+}
+
+// ConfigurePins reads the wait from the node data
+func (n *DebounceNode) ConfigurePins(data map[string]interface{}) error {
+	wait, err := durationFromData(data, "wait", defaultDebounceWait)
+	if err != nil {
+		return err
+	}
+	n.wait = wait
+	return nil
+}
+
+// RateLimit returns the wait of the node
+func (n *DebounceNode) RateLimit() node.RateLimit {
+	return node.RateLimit{Mode: node.RateLimitDebounce, Interval: n.wait}
+}
+
+// Execute runs the node logic
+func (n *DebounceNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Debounce node", map[string]interface{}{"wait": n.wait.String()})
+	return passTrigger(ctx)
+}
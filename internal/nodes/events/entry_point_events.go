@@ -246,3 +246,62 @@ func (n *OnInputEventNode) Execute(ctx node.ExecutionContext) error {
 	// Activate execution flow
 	return ctx.ActivateOutputFlow("execution")
 }
+
+// OnErrorEventNode is the entry point of a blueprint's error handler. The engine runs it
+// for node errors that no catch flow handled, passing the error and the failing node.
+type OnErrorEventNode struct {
+	node.BaseNode
+}
+
+// NewOnErrorEventNode creates a new OnError entry point node
+func NewOnErrorEventNode() node.Node {
+	return &OnErrorEventNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "event-on-error",
+				Name:        "On Error",
+				Description: "Entry point called when a node fails and no catch flow handles the error",
+				Category:    "Constructive Events",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution flow handling the error",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "error",
+					Name:        "Error",
+					Description: "Error information (code, message, retryable)",
+					Type:        types.PinTypes.Object,
+				},
+				{
+					ID:          "nodeId",
+					Name:        "Node ID",
+					Description: "ID of the node that failed",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the OnError entry point node's logic
+func (n *OnErrorEventNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing OnErrorEventNode", nil)
+
+	// The engine passes the error details as inputs
+	if errorValue, exists := ctx.GetInputValue("error"); exists {
+		ctx.SetOutputValue("error", errorValue)
+	}
+	if nodeIDValue, exists := ctx.GetInputValue("nodeId"); exists {
+		ctx.SetOutputValue("nodeId", nodeIDValue)
+	}
+
+	// Activate the execution flow
+	return ctx.ActivateOutputFlow("then")
+}
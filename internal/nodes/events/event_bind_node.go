@@ -107,7 +107,12 @@ func (n *EventBindNode) Execute(ctx node.ExecutionContext) error {
 	handlerCtx := evtCtx.GetEventHandlerContext()
 	if handlerCtx == nil {
 		// This shouldn't happen if IsEventHandlerActive is true, but check defensively.
-		err := fmt.Errorf("event handler context is nil despite being active for node %s", ctx.GetNodeID())
+		err := bperrors.New(
+			bperrors.ErrorTypeSystem,
+			bperrors.ErrInternalServerError,
+			fmt.Sprintf("event handler context is nil despite being active for node %s", ctx.GetNodeID()),
+			bperrors.SeverityHigh,
+		)
 		logger.Error(err.Error(), nil)
 		return err
 	}
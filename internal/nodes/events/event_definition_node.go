@@ -3,6 +3,7 @@ package events
 import (
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/event"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
@@ -191,7 +192,7 @@ func (n *EventDefinitionNode) Execute(ctx node.ExecutionContext) error {
 		ctx.SetOutputValue("eventID", types.NewValue(types.PinTypes.String, ""))
 		ctx.SetOutputValue("success", types.NewValue(types.PinTypes.Boolean, false))
 
-		return fmt.Errorf("event manager not available in context")
+		return bperrors.ServiceUnavailable("event manager")
 	}
 
 	// Register the event
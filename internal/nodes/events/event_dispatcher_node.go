@@ -1,7 +1,7 @@
 package events
 
 import (
-	"fmt"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/event"
 	"webblueprint/internal/node"
 	"webblueprint/internal/nodes/events/utils"
@@ -205,5 +205,5 @@ func (n *EventDispatcherNode) Execute(ctx node.ExecutionContext) error {
 		return ctx.ActivateOutputFlow("then")
 	}
 
-	return fmt.Errorf("event manager not available in context")
+	return bperrors.ServiceUnavailable("event manager")
 }
@@ -1,8 +1,13 @@
 package events
 
 import (
+	"fmt"
+	"time"
 	"webblueprint/internal/core"
+	"webblueprint/internal/engineext"
 	"webblueprint/internal/event"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
 )
 
 // convertEventHandlerContext converts an event.EventHandlerContext to a core.EventHandlerContext
@@ -15,6 +20,82 @@ func convertEventHandlerContext(ctx event.EventHandlerContext) *core.EventHandle
 	}
 }
 
+// durationFromData reads a duration in milliseconds from the data of a node
+func durationFromData(data map[string]interface{}, key string, fallback time.Duration) (time.Duration, error) {
+	raw, exists := data[key]
+	if !exists || raw == nil {
+		return fallback, nil
+	}
+
+	ms, ok := raw.(float64)
+	if !ok {
+		if n, isInt := raw.(int); isInt {
+			ms, ok = float64(n), true
+		}
+	}
+	if !ok || ms <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number of milliseconds, got %v", key, raw)
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+// rateLimitedPins returns the pins of debounce and throttle nodes
+func rateLimitedPins() ([]types.Pin, []types.Pin) {
+	inputs := []types.Pin{
+		{
+			ID:          "execute",
+			Name:        "Execute",
+			Description: "Trigger to coalesce",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          "value",
+			Name:        "Value",
+			Description: "Value passed on with the trigger",
+			Type:        types.PinTypes.Any,
+			Optional:    true,
+		},
+	}
+	outputs := []types.Pin{
+		{
+			ID:          "then",
+			Name:        "Then",
+			Description: "Executed when a trigger gets through",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          "value",
+			Name:        "Value",
+			Description: "Value of the trigger that got through",
+			Type:        types.PinTypes.Any,
+		},
+		{
+			ID:          "triggers",
+			Name:        "Triggers",
+			Description: "Number of triggers coalesced into this activation",
+			Type:        types.PinTypes.Number,
+		},
+	}
+	return inputs, outputs
+}
+
+// passTrigger continues the flow of a debounce or throttle node the engine let through
+func passTrigger(ctx node.ExecutionContext) error {
+	if value, exists := ctx.GetInputValue("value"); exists {
+		ctx.SetOutputValue("value", value)
+	}
+
+	triggers := float64(1)
+	if value, exists := ctx.GetInputValue(engineext.TimerTriggersParameter); exists {
+		if n, err := value.AsNumber(); err == nil {
+			triggers = n
+		}
+	}
+	ctx.SetOutputValue("triggers", types.NewValue(types.PinTypes.Number, triggers))
+
+	return ctx.ActivateOutputFlow("then")
+}
+
 // NOTE: Removed convertEventManager and eventManagerAdapter as they are likely
 // redundant with the adapter provided by event.EventManager itself and were
 // causing compilation errors due to interface mismatches after refactoring.
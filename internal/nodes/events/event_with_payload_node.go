@@ -1,8 +1,8 @@
 package events
 
 import (
-	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/event"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
@@ -217,5 +217,5 @@ func (n *EventWithPayloadNode) Execute(ctx node.ExecutionContext) error {
 		return ctx.ActivateOutputFlow("then")
 	}
 
-	return fmt.Errorf("event manager not available in context")
+	return bperrors.ServiceUnavailable("event manager")
 }
@@ -1,8 +1,8 @@
 package events
 
 import (
-	"fmt"
 	"strings"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/event"
 	"webblueprint/internal/node"
 	"webblueprint/internal/nodes/events/utils"
@@ -263,7 +263,7 @@ func (n *ImprovedEventDispatcherNode) Execute(ctx node.ExecutionContext) error {
 		return ctx.ActivateOutputFlow("then")
 	}
 
-	return fmt.Errorf("event manager not available in context")
+	return bperrors.ServiceUnavailable("event manager")
 }
 
 // generateEventID creates a standardized event ID from a name
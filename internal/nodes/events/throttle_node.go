@@ -0,0 +1,73 @@
+package events
+
+import (
+	"fmt"
+	"time"
+	"webblueprint/internal/node"
+)
+
+// defaultThrottleInterval is the interval of a throttle node without one in its data
+const defaultThrottleInterval = time.Second
+
+// ThrottleNode lets at most one trigger through per interval, configured in the node data:
+//
+//	{"interval": 1000, "trailing": true}
+//
+// Triggers arriving within the interval are dropped, unless trailing is set, in which case
+// the latest of them runs when the interval ends.
+type ThrottleNode struct {
+	node.BaseNode
+	interval time.Duration
+	trailing bool
+}
+
+// NewThrottleNode creates a new throttle node
+func NewThrottleNode() node.Node {
+	inputs, outputs := rateLimitedPins()
+	return &ThrottleNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "throttle",
+				Name:        "Throttle",
+				Description: "Lets at most one trigger through per interval",
+				Category:    "Events",
+				Version:     "1.0.0",
+			},
+			Inputs:  inputs,
+			Outputs: outputs,
+		},
+		interval: defaultThrottleInterval,
+	}
+}
+
+// ConfigurePins reads the interval and the trailing option from the node data
+func (n *ThrottleNode) ConfigurePins(data map[string]interface{}) error {
+	interval, err := durationFromData(data, "interval", defaultThrottleInterval)
+	if err != nil {
+		return err
+	}
+
+	trailing := false
+	if raw, exists := data["trailing"]; exists && raw != nil {
+		value, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("trailing must be a boolean, got %v", raw)
+		}
+		trailing = value
+	}
+
+	n.interval = interval
+	n.trailing = trailing
+	return nil
+}
+
+// RateLimit returns the interval of the node
+func (n *ThrottleNode) RateLimit() node.RateLimit {
+	return node.RateLimit{Mode: node.RateLimitThrottle, Interval: n.interval, Trailing: n.trailing}
+}
+
+// Execute runs the node logic
+func (n *ThrottleNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Throttle node", map[string]interface{}{"interval": n.interval.String(), "trailing": n.trailing})
+	return passTrigger(ctx)
+}
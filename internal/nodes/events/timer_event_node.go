@@ -3,6 +3,7 @@ package events
 import (
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/event"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
@@ -84,6 +85,11 @@ func NewTimerEventNode() node.Node {
 	}
 }
 
+// IsNondeterministic reports that the node starts real timers; replays use the recorded timer events instead
+func (n *TimerEventNode) IsNondeterministic() bool {
+	return true
+}
+
 // Execute runs the node logic
 func (n *TimerEventNode) Execute(ctx node.ExecutionContext) error {
 	logger := ctx.Logger()
@@ -137,14 +143,19 @@ func (n *TimerEventNode) startTimer(ctx node.ExecutionContext) error {
 		eventManager = evtCtx.GetEventManager()
 	} else {
 		logger.Error("Event manager not available in context", nil)
-		return fmt.Errorf("event manager not available in context")
+		return bperrors.ServiceUnavailable("event manager")
 	}
 
 	// Get the system timer event ID
 	timerEventID, exists := eventManager.GetSystemEventID(event.EventTypeTimer)
 	if !exists {
 		logger.Error("Timer event not defined in system", nil)
-		return fmt.Errorf("timer event not defined in system")
+		return bperrors.New(
+			bperrors.ErrorTypeSystem,
+			bperrors.ErrServiceUnavailable,
+			"timer event not defined in system",
+			bperrors.SeverityHigh,
+		)
 	}
 
 	// Reset count
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -119,7 +120,7 @@ func (n *BranchNode) Execute(ctx node.ExecutionContext) error {
 	// Get the value to switch on
 	valueInput, valueExists := ctx.GetInputValue("value")
 	if !valueExists {
-		err := fmt.Errorf("missing required input: value")
+		err := bperrors.MissingInput("value")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -1,8 +1,8 @@
 package logic
 
 import (
-	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -63,7 +63,7 @@ func (n *IfConditionNode) Execute(ctx node.ExecutionContext) error {
 	// Get the condition value
 	conditionValue, exists := ctx.GetInputValue("condition")
 	if !exists {
-		err := fmt.Errorf("missing required input: condition")
+		err := bperrors.MissingInput("condition")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 		return err
 	}
@@ -72,7 +72,7 @@ func (n *IfConditionNode) Execute(ctx node.ExecutionContext) error {
 	condition, err := conditionValue.AsBoolean()
 	if err != nil {
 		logger.Error("Invalid condition value", map[string]interface{}{"error": err.Error()})
-		return err
+		return bperrors.InvalidInput("condition", err)
 	}
 
 	// Record debug info
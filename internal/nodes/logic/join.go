@@ -0,0 +1,167 @@
+package logic
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// JoinMode decides how many execution inputs of a join node must be activated before it
+// continues
+type JoinMode string
+
+const (
+	JoinAll   JoinMode = "all"   // Waits for every input
+	JoinAny   JoinMode = "any"   // Continues on the first input
+	JoinCount JoinMode = "count" // Waits for a number of inputs
+)
+
+const (
+	joinDefaultInputs = 2
+	joinInputPrefix   = "in_"
+)
+
+// JoinNode synchronizes parallel branches. The engine holds the node back until enough of
+// its execution inputs have been activated, which is configured in the node data:
+//
+//	{"mode": "count", "inputs": 3, "count": 2}
+//
+// Once the node continued, later activations are absorbed until every input has been
+// activated, after which the node waits again.
+type JoinNode struct {
+	node.BaseNode
+	mode   JoinMode
+	inputs int
+	count  int
+}
+
+// NewJoinNode creates a new Join node
+func NewJoinNode() node.Node {
+	n := &JoinNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "join",
+				Name:        "Join",
+				Description: "Waits for parallel branches before continuing",
+				Category:    "Logic",
+				Version:     "1.0.0",
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Executed once the branches joined",
+					Type:        types.PinTypes.Execution,
+				},
+			},
+		},
+		mode:   JoinAll,
+		inputs: joinDefaultInputs,
+	}
+	n.Inputs = joinInputPins(joinDefaultInputs)
+	return n
+}
+
+// joinInputPins returns the execution inputs of a join node
+func joinInputPins(count int) []types.Pin {
+	pins := make([]types.Pin, 0, count)
+	for i := 1; i <= count; i++ {
+		pins = append(pins, types.Pin{
+			ID:          joinInputPrefix + strconv.Itoa(i),
+			Name:        fmt.Sprintf("Input %d", i),
+			Description: fmt.Sprintf("Execution input of branch %d", i),
+			Type:        types.PinTypes.Execution,
+		})
+	}
+	return pins
+}
+
+// ConfigurePins declares the execution inputs and the join mode from the node data
+func (n *JoinNode) ConfigurePins(data map[string]interface{}) error {
+	mode := JoinAll
+	if value, ok := data["mode"].(string); ok && value != "" {
+		mode = JoinMode(value)
+	}
+
+	inputs, err := joinInteger(data, "inputs", joinDefaultInputs)
+	if err != nil {
+		return err
+	}
+	if inputs < 1 {
+		return fmt.Errorf("inputs must be at least 1, got %d", inputs)
+	}
+
+	count := 0
+	switch mode {
+	case JoinAll:
+		count = inputs
+	case JoinAny:
+		count = 1
+	case JoinCount:
+		if count, err = joinInteger(data, "count", 0); err != nil {
+			return err
+		}
+		if count < 1 || count > inputs {
+			return fmt.Errorf("count must be between 1 and %d, got %d", inputs, count)
+		}
+	default:
+		return fmt.Errorf("invalid join mode %q (expected %q, %q or %q)", mode, JoinAll, JoinAny, JoinCount)
+	}
+
+	n.mode = mode
+	n.inputs = inputs
+	n.count = count
+	n.Inputs = joinInputPins(inputs)
+	return nil
+}
+
+// joinInteger reads a whole number from the node data
+func joinInteger(data map[string]interface{}, key string, fallback int) (int, error) {
+	raw, exists := data[key]
+	if !exists || raw == nil {
+		return fallback, nil
+	}
+
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%s must be a whole number, got %v", key, raw)
+}
+
+// RequiredInputs returns how many distinct inputs must be activated before the node runs
+func (n *JoinNode) RequiredInputs() int {
+	if n.count == 0 {
+		return n.inputs
+	}
+	return n.count
+}
+
+// Execute runs the node logic
+func (n *JoinNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Join node", nil)
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Join: branches joined",
+		Value: map[string]interface{}{
+			"mode":     n.mode,
+			"inputs":   n.inputs,
+			"required": n.RequiredInputs(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
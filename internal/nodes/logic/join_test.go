@@ -0,0 +1,63 @@
+package logic_test
+
+import (
+	"fmt"
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/logic"
+	"webblueprint/internal/test"
+)
+
+func TestJoinNode(t *testing.T) {
+	test.ExecuteNodeTestCase(t, logic.NewJoinNode(), test.NodeTestCase{
+		Name:         "continues once joined",
+		Inputs:       map[string]interface{}{},
+		ExpectedFlow: "then",
+	})
+}
+
+func TestJoinNodeConfiguration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     map[string]interface{}
+		inputs   int
+		required int
+	}{
+		{"defaults to wait for all", nil, 2, 2},
+		{"wait for all", map[string]interface{}{"mode": "all", "inputs": 4.0}, 4, 4},
+		{"wait for any", map[string]interface{}{"mode": "any", "inputs": 3.0}, 3, 1},
+		{"wait for count", map[string]interface{}{"mode": "count", "inputs": 5.0, "count": 3.0}, 5, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			joinNode := logic.NewJoinNode()
+			if err := node.ConfigurePins(joinNode, tc.data); err != nil {
+				t.Fatalf("Failed to configure pins: %v", err)
+			}
+
+			pins := joinNode.GetInputPins()
+			if len(pins) != tc.inputs {
+				t.Fatalf("Expected %d inputs, got %d", tc.inputs, len(pins))
+			}
+			if last := fmt.Sprintf("in_%d", tc.inputs); pins[len(pins)-1].ID != last {
+				t.Errorf("Unexpected last input pin %s", pins[len(pins)-1].ID)
+			}
+			if required := joinNode.(node.JoinNode).RequiredInputs(); required != tc.required {
+				t.Errorf("Expected %d required inputs, got %d", tc.required, required)
+			}
+		})
+	}
+
+	for name, data := range map[string]map[string]interface{}{
+		"invalid mode":     {"mode": "first"},
+		"no inputs":        {"inputs": 0.0},
+		"fractional input": {"inputs": 2.5},
+		"count too high":   {"mode": "count", "inputs": 2.0, "count": 3.0},
+		"count missing":    {"mode": "count"},
+	} {
+		if err := node.ConfigurePins(logic.NewJoinNode(), data); err == nil {
+			t.Errorf("%s: expected a configuration error", name)
+		}
+	}
+}
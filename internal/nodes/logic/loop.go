@@ -3,6 +3,7 @@ package logic
 import (
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 
 	// "webblueprint/internal/engineext" // Removed import to break cycle
 	"webblueprint/internal/engine"
@@ -83,7 +84,7 @@ func (n *LoopNode) Execute(ctx node.ExecutionContext) error {
 	// Get the number of iterations
 	iterationsValue, exists := ctx.GetInputValue("iterations")
 	if !exists {
-		err := fmt.Errorf("missing required input: iterations")
+		err := bperrors.MissingInput("iterations")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -115,7 +116,7 @@ func (n *LoopNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("iterations", err)
 	}
 
 	// Get the start value (default to 0)
@@ -170,9 +171,14 @@ func (n *LoopNode) Execute(ctx node.ExecutionContext) error {
 	actorCtx, ok := ctx.(*engine.ActorExecutionContext) // Use concrete type from engine
 	if !ok {
 		// This node requires the actor model context to function correctly
-		err := fmt.Errorf("LoopNode requires ActorExecutionContext in actor mode, received %T", ctx)
+		err := bperrors.New(
+			bperrors.ErrorTypeSystem,
+			bperrors.ErrSystemUnavailable,
+			fmt.Sprintf("LoopNode requires ActorExecutionContext in actor mode, received %T", ctx),
+			bperrors.SeverityHigh,
+		)
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 		// Cannot proceed, maybe activate error flow?
 		// For now, return error. Loop won't start.
 		return err
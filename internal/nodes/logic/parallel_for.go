@@ -0,0 +1,211 @@
+package logic
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+const (
+	parallelForDefaultLimit = 4
+	parallelForBodyPin      = "body"
+	parallelForResultPin    = "result"
+)
+
+// ParallelForNode runs its body branch once per element of an array, concurrently up to a
+// limit. Each element runs in its own child scope of the execution, so the body sees its
+// own item and index, and the value the body delivers to the result pin is collected in
+// input order. Failing elements don't stop the others; their errors are listed by index.
+type ParallelForNode struct {
+	node.BaseNode
+}
+
+// NewParallelForNode creates a new Parallel For node
+func NewParallelForNode() node.Node {
+	return &ParallelForNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "parallel-for",
+				Name:        "Parallel For",
+				Description: "Executes a branch for each array element concurrently and collects the results",
+				Category:    "Logic",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "array",
+					Name:        "Array",
+					Description: "Elements to run the body for",
+					Type:        types.PinTypes.Array,
+				},
+				{
+					ID:          "maxParallel",
+					Name:        "Max Parallel",
+					Description: "How many elements run at the same time (default: 4)",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     parallelForDefaultLimit,
+				},
+				{
+					ID:          parallelForResultPin,
+					Name:        "Result",
+					Description: "Result of the body for the current element",
+					Type:        types.PinTypes.Any,
+					Optional:    true,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          parallelForBodyPin,
+					Name:        "Body",
+					Description: "Executed for each element in its own scope",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "completed",
+					Name:        "Completed",
+					Description: "Executed when every element is done",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "item",
+					Name:        "Item",
+					Description: "Current element",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					ID:          "index",
+					Name:        "Index",
+					Description: "Index of the current element",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "results",
+					Name:        "Results",
+					Description: "Results of the elements in input order, null for failed elements",
+					Type:        types.PinTypes.Array,
+				},
+				{
+					ID:          "errors",
+					Name:        "Errors",
+					Description: "Errors of the failed elements, as objects with index and message",
+					Type:        types.PinTypes.Array,
+				},
+			},
+		},
+	}
+}
+
+// ScopePins returns the pins whose branch only runs in child scopes
+func (n *ParallelForNode) ScopePins() []string {
+	return []string{parallelForBodyPin}
+}
+
+// Execute runs the node logic
+func (n *ParallelForNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Parallel For node", nil)
+
+	arrayValue, exists := ctx.GetInputValue("array")
+	if !exists {
+		return bperrors.MissingInput("array")
+	}
+	items, err := arrayValue.AsArray()
+	if err != nil {
+		return bperrors.InvalidInput("array", err)
+	}
+
+	limit := parallelForDefaultLimit
+	if limitValue, exists := ctx.GetInputValue("maxParallel"); exists && !limitValue.IsNull() {
+		number, err := limitValue.AsNumber()
+		if err != nil {
+			return bperrors.InvalidInput("maxParallel", err)
+		}
+		if number < 1 || number != float64(int(number)) {
+			return bperrors.InvalidInput("maxParallel", fmt.Errorf("must be a whole number of at least 1, got %v", number))
+		}
+		limit = int(number)
+	}
+
+	scoped, ok := node.ScopedContext(ctx)
+	if !ok {
+		return bperrors.New(
+			bperrors.ErrorTypeSystem,
+			bperrors.ErrServiceUnavailable,
+			fmt.Sprintf("parallel-for requires an execution context with child scopes, received %T", ctx),
+			bperrors.SeverityHigh,
+		)
+	}
+
+	started := time.Now()
+	results := make([]interface{}, len(items))
+	failures := make([]error, len(items))
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for index, item := range items {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(index int, item interface{}) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			outputs := map[string]types.Value{
+				"item":  types.NewValue(types.InferPinType(item), item),
+				"index": types.NewValue(types.PinTypes.Number, float64(index)),
+			}
+			result, exists, err := scoped.RunScope(parallelForBodyPin, outputs, parallelForResultPin)
+			if err != nil {
+				failures[index] = err
+				return
+			}
+			if exists {
+				results[index] = result.RawValue
+			}
+		}(index, item)
+	}
+	wg.Wait()
+
+	errorList := make([]interface{}, 0)
+	for index, err := range failures {
+		if err == nil {
+			continue
+		}
+		errorList = append(errorList, map[string]interface{}{
+			"index":   float64(index),
+			"message": err.Error(),
+		})
+	}
+
+	ctx.SetOutputValue("results", types.NewValue(types.PinTypes.Array, results))
+	ctx.SetOutputValue("errors", types.NewValue(types.PinTypes.Array, errorList))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Parallel For completed",
+		Value: map[string]interface{}{
+			"items":       len(items),
+			"maxParallel": limit,
+			"failed":      len(errorList),
+			"duration":    time.Since(started).String(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	if len(errorList) > 0 {
+		logger.Warn("Parallel For elements failed", map[string]interface{}{
+			"failed": len(errorList),
+			"items":  len(items),
+		})
+	}
+
+	return ctx.ActivateOutputFlow("completed")
+}
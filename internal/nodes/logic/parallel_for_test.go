@@ -0,0 +1,110 @@
+package logic_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"webblueprint/internal/nodes/logic"
+	"webblueprint/internal/test"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
+)
+
+// scopedContext runs the scopes of a node with a function standing in for its body branch
+type scopedContext struct {
+	*mocks.MockExecutionContext
+	body    func(outputs map[string]types.Value) (types.Value, bool, error)
+	mutex   sync.Mutex
+	running int
+	peak    int
+}
+
+func (c *scopedContext) RunScope(pinID string, outputs map[string]types.Value, resultPin string) (types.Value, bool, error) {
+	c.mutex.Lock()
+	c.running++
+	if c.running > c.peak {
+		c.peak = c.running
+	}
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		c.running--
+		c.mutex.Unlock()
+	}()
+	return c.body(outputs)
+}
+
+func newScopedContext(array []interface{}, maxParallel float64, body func(outputs map[string]types.Value) (types.Value, bool, error)) *scopedContext {
+	ctx := mocks.NewMockExecutionContext("test-node", "parallel-for", mocks.NewMockLogger())
+	ctx.SetInputValue("array", types.NewValue(types.PinTypes.Array, array))
+	ctx.SetInputValue("maxParallel", types.NewValue(types.PinTypes.Number, maxParallel))
+	return &scopedContext{MockExecutionContext: ctx, body: body}
+}
+
+func TestParallelForNodeCollectsResultsInOrder(t *testing.T) {
+	ctx := newScopedContext([]interface{}{3.0, 1.0, 2.0, 5.0, 4.0}, 2, func(outputs map[string]types.Value) (types.Value, bool, error) {
+		item, _ := outputs["item"].AsNumber()
+		index, _ := outputs["index"].AsNumber()
+		return types.NewValue(types.PinTypes.Number, item*10+index), true, nil
+	})
+
+	if err := logic.NewParallelForNode().Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, _ := ctx.GetOutputValue("results")
+	expected := []interface{}{30.0, 11.0, 22.0, 53.0, 44.0}
+	if !reflect.DeepEqual(results.RawValue, expected) {
+		t.Errorf("Expected results %v, got %v", expected, results.RawValue)
+	}
+	if ctx.peak > 2 {
+		t.Errorf("Expected at most 2 elements at a time, got %d", ctx.peak)
+	}
+	if flow := ctx.GetActivatedFlow(); flow != "completed" {
+		t.Errorf("Expected the completed flow, got %q", flow)
+	}
+}
+
+func TestParallelForNodeAggregatesErrors(t *testing.T) {
+	ctx := newScopedContext([]interface{}{"a", "b", "c"}, 4, func(outputs map[string]types.Value) (types.Value, bool, error) {
+		if item, _ := outputs["item"].AsString(); item == "b" {
+			return types.Value{}, false, errors.New("element rejected")
+		}
+		return outputs["item"], true, nil
+	})
+
+	if err := logic.NewParallelForNode().Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, _ := ctx.GetOutputValue("results")
+	if expected := []interface{}{"a", nil, "c"}; !reflect.DeepEqual(results.RawValue, expected) {
+		t.Errorf("Expected results %v, got %v", expected, results.RawValue)
+	}
+	failures, _ := ctx.GetOutputValue("errors")
+	expected := []interface{}{map[string]interface{}{"index": 1.0, "message": "element rejected"}}
+	if !reflect.DeepEqual(failures.RawValue, expected) {
+		t.Errorf("Expected errors %v, got %v", expected, failures.RawValue)
+	}
+}
+
+func TestParallelForNodeInputs(t *testing.T) {
+	test.ExecuteNodeTestCase(t, logic.NewParallelForNode(), test.NodeTestCase{
+		Name:          "requires child scopes",
+		Inputs:        map[string]interface{}{"array": []interface{}{1.0}},
+		ExpectedError: true,
+		ErrorContains: "child scopes",
+	})
+	test.ExecuteNodeTestCase(t, logic.NewParallelForNode(), test.NodeTestCase{
+		Name:          "rejects a parallelism below 1",
+		Inputs:        map[string]interface{}{"array": []interface{}{1.0}, "maxParallel": 0.0},
+		ExpectedError: true,
+	})
+	test.ExecuteNodeTestCase(t, logic.NewParallelForNode(), test.NodeTestCase{
+		Name:          "requires an array",
+		Inputs:        map[string]interface{}{},
+		ExpectedError: true,
+	})
+}
@@ -0,0 +1,259 @@
+package logic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// SwitchMatchMode decides how the value of a switch node is compared to its cases
+type SwitchMatchMode string
+
+const (
+	SwitchMatchString SwitchMatchMode = "string" // Values are compared as text
+	SwitchMatchNumber SwitchMatchMode = "number" // Values are compared as numbers
+)
+
+const (
+	switchDefaultPin = "default"
+	switchCasePrefix = "case_"
+)
+
+// SwitchCase is a case of a switch node, declared in the node data
+type SwitchCase struct {
+	ID    string      `json:"id"`
+	Label string      `json:"label,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// PinID returns the ID of the output pin of the case
+func (c SwitchCase) PinID() string {
+	return switchCasePrefix + c.ID
+}
+
+// SwitchNode routes execution to the case matching its value. Its cases and their output
+// pins are declared in the node data:
+//
+//	{"match": "string", "cases": [{"id": "admin", "value": "admin"}, {"id": "guest", "value": "guest"}]}
+//
+// Cases may also be listed as plain values, in which case they're numbered from 1.
+type SwitchNode struct {
+	node.BaseNode
+	mode  SwitchMatchMode
+	cases []SwitchCase
+}
+
+// NewSwitchNode creates a new Switch node
+func NewSwitchNode() node.Node {
+	return &SwitchNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "switch",
+				Name:        "Switch",
+				Description: "Routes execution to the case matching a value, with cases defined on the node",
+				Category:    "Logic",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "value",
+					Name:        "Value",
+					Description: "Value to match against the cases",
+					Type:        types.PinTypes.Any,
+				},
+			},
+			Outputs: switchStaticOutputs(),
+		},
+		mode: SwitchMatchString,
+	}
+}
+
+// switchStaticOutputs returns the output pins every switch node has
+func switchStaticOutputs() []types.Pin {
+	return []types.Pin{
+		{
+			ID:          switchDefaultPin,
+			Name:        "Default",
+			Description: "Executed if no case matches",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          "matched_case",
+			Name:        "Matched Case",
+			Description: "The ID of the matched case, or \"default\"",
+			Type:        types.PinTypes.String,
+		},
+	}
+}
+
+// ConfigurePins declares an output pin for every case in the node data
+func (n *SwitchNode) ConfigurePins(data map[string]interface{}) error {
+	mode := SwitchMatchString
+	if value, ok := data["match"].(string); ok && value != "" {
+		mode = SwitchMatchMode(value)
+	}
+	if mode != SwitchMatchString && mode != SwitchMatchNumber {
+		return fmt.Errorf("invalid match mode %q (expected %q or %q)", mode, SwitchMatchString, SwitchMatchNumber)
+	}
+
+	cases, err := parseSwitchCases(data["cases"])
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]types.Pin, 0, len(cases)+2)
+	for _, c := range cases {
+		name := c.Label
+		if name == "" {
+			name = fmt.Sprintf("Case %v", c.Value)
+		}
+		outputs = append(outputs, types.Pin{
+			ID:          c.PinID(),
+			Name:        name,
+			Description: fmt.Sprintf("Executed if the value matches %v", c.Value),
+			Type:        types.PinTypes.Execution,
+		})
+	}
+
+	n.mode = mode
+	n.cases = cases
+	n.Outputs = append(outputs, switchStaticOutputs()...)
+	return nil
+}
+
+// parseSwitchCases reads the cases of a switch node from its data
+func parseSwitchCases(raw interface{}) ([]SwitchCase, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cases must be a list")
+	}
+
+	cases := make([]SwitchCase, 0, len(list))
+	ids := make(map[string]bool, len(list))
+	for i, entry := range list {
+		c := SwitchCase{ID: strconv.Itoa(i + 1), Value: entry}
+		if fields, ok := entry.(map[string]interface{}); ok {
+			c.Value = fields["value"]
+			if id, ok := fields["id"].(string); ok && id != "" {
+				c.ID = id
+			}
+			if label, ok := fields["label"].(string); ok {
+				c.Label = label
+			}
+		}
+
+		if ids[c.ID] {
+			return nil, fmt.Errorf("case ID %q is used more than once", c.ID)
+		}
+		ids[c.ID] = true
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// Execute runs the node logic
+func (n *SwitchNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Switch node", nil)
+
+	valueInput, exists := ctx.GetInputValue("value")
+	if !exists {
+		logger.Warn("No value to switch on, taking default branch", nil)
+		ctx.SetOutputValue("matched_case", types.NewValue(types.PinTypes.String, switchDefaultPin))
+		return ctx.ActivateOutputFlow(switchDefaultPin)
+	}
+
+	for _, c := range n.cases {
+		if !n.matches(valueInput.RawValue, c.Value) {
+			continue
+		}
+
+		ctx.RecordDebugInfo(types.DebugInfo{
+			NodeID:      ctx.GetNodeID(),
+			Description: "Switch: case matched",
+			Value: map[string]interface{}{
+				"value": valueInput.RawValue,
+				"case":  c.ID,
+				"mode":  n.mode,
+			},
+			Timestamp: time.Now(),
+		})
+
+		ctx.SetOutputValue("matched_case", types.NewValue(types.PinTypes.String, c.ID))
+		return ctx.ActivateOutputFlow(c.PinID())
+	}
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Switch: no case matched",
+		Value: map[string]interface{}{
+			"value": valueInput.RawValue,
+			"cases": len(n.cases),
+			"mode":  n.mode,
+		},
+		Timestamp: time.Now(),
+	})
+
+	ctx.SetOutputValue("matched_case", types.NewValue(types.PinTypes.String, switchDefaultPin))
+	return ctx.ActivateOutputFlow(switchDefaultPin)
+}
+
+// matches compares a value to a case value in the match mode of the node
+func (n *SwitchNode) matches(value, caseValue interface{}) bool {
+	if n.mode == SwitchMatchNumber {
+		number, ok := switchNumber(value)
+		if !ok {
+			return false
+		}
+		caseNumber, ok := switchNumber(caseValue)
+		return ok && number == caseNumber
+	}
+	return switchString(value) == switchString(caseValue)
+}
+
+// switchString formats a value as text for string matching
+func switchString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
+
+// switchNumber converts a value to a number for number matching
+func switchNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
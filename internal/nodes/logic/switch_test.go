@@ -0,0 +1,108 @@
+package logic_test
+
+import (
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/logic"
+	"webblueprint/internal/test"
+)
+
+func TestSwitchNode(t *testing.T) {
+	roles := map[string]interface{}{
+		"cases": []interface{}{
+			map[string]interface{}{"id": "admin", "value": "admin", "label": "Admin"},
+			map[string]interface{}{"id": "guest", "value": "guest"},
+		},
+	}
+	codes := map[string]interface{}{
+		"match": "number",
+		"cases": []interface{}{200.0, "404"},
+	}
+
+	testCases := []struct {
+		data map[string]interface{}
+		tc   test.NodeTestCase
+	}{
+		{roles, test.NodeTestCase{
+			Name:            "string match",
+			Inputs:          map[string]interface{}{"value": "guest"},
+			ExpectedOutputs: map[string]interface{}{"matched_case": "guest"},
+			ExpectedFlow:    "case_guest",
+		}},
+		{roles, test.NodeTestCase{
+			Name:            "no match - default",
+			Inputs:          map[string]interface{}{"value": "owner"},
+			ExpectedOutputs: map[string]interface{}{"matched_case": "default"},
+			ExpectedFlow:    "default",
+		}},
+		{roles, test.NodeTestCase{
+			Name:            "missing value - default",
+			Inputs:          map[string]interface{}{},
+			ExpectedOutputs: map[string]interface{}{"matched_case": "default"},
+			ExpectedFlow:    "default",
+		}},
+		{codes, test.NodeTestCase{
+			Name:            "number match",
+			Inputs:          map[string]interface{}{"value": "200"},
+			ExpectedOutputs: map[string]interface{}{"matched_case": "1"},
+			ExpectedFlow:    "case_1",
+		}},
+		{codes, test.NodeTestCase{
+			Name:            "number match with string case",
+			Inputs:          map[string]interface{}{"value": 404},
+			ExpectedOutputs: map[string]interface{}{"matched_case": "2"},
+			ExpectedFlow:    "case_2",
+		}},
+		{nil, test.NodeTestCase{
+			Name:            "no cases - default",
+			Inputs:          map[string]interface{}{"value": "anything"},
+			ExpectedOutputs: map[string]interface{}{"matched_case": "default"},
+			ExpectedFlow:    "default",
+		}},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.tc.Name, func(t *testing.T) {
+			switchNode := logic.NewSwitchNode()
+			if err := node.ConfigurePins(switchNode, c.data); err != nil {
+				t.Fatalf("Failed to configure pins: %v", err)
+			}
+			test.ExecuteNodeTestCase(t, switchNode, c.tc)
+		})
+	}
+}
+
+func TestSwitchNodePins(t *testing.T) {
+	switchNode := logic.NewSwitchNode()
+	err := node.ConfigurePins(switchNode, map[string]interface{}{
+		"cases": []interface{}{"a", map[string]interface{}{"id": "b", "value": "b"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to configure pins: %v", err)
+	}
+
+	ids := make([]string, 0)
+	for _, pin := range switchNode.GetOutputPins() {
+		ids = append(ids, pin.ID)
+	}
+	expected := []string{"case_1", "case_b", "default", "matched_case"}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected output pins %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected output pins %v, got %v", expected, ids)
+			break
+		}
+	}
+
+	for name, data := range map[string]map[string]interface{}{
+		"invalid mode":  {"match": "regex"},
+		"cases no list": {"cases": "a,b"},
+		"duplicate IDs": {"cases": []interface{}{map[string]interface{}{"id": "a"}, map[string]interface{}{"id": "a"}}},
+	} {
+		if err := node.ConfigurePins(logic.NewSwitchNode(), data); err == nil {
+			t.Errorf("%s: expected a configuration error", name)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package math
 
 import (
-	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -80,7 +80,7 @@ func (n *AddNode) Execute(ctx node.ExecutionContext) error {
 	}
 
 	if !aExists {
-		err := fmt.Errorf("missing required input: a")
+		err := bperrors.MissingInput("a")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -98,7 +98,7 @@ func (n *AddNode) Execute(ctx node.ExecutionContext) error {
 	}
 
 	if !bExists {
-		err := fmt.Errorf("missing required input: b")
+		err := bperrors.MissingInput("b")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -131,7 +131,7 @@ func (n *AddNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("a", err)
 	}
 
 	b, err := bValue.AsNumber()
@@ -149,7 +149,7 @@ func (n *AddNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("b", err)
 	}
 
 	// Perform addition
@@ -257,14 +257,12 @@ func (n *SubtractNode) Execute(ctx node.ExecutionContext) error {
 	}
 
 	if !aExists || !bExists {
-		errorMsg := ""
+		pinID := "b"
 		if !aExists {
-			errorMsg = "missing required input: a"
-		} else {
-			errorMsg = "missing required input: b"
+			pinID = "a"
 		}
 
-		err := fmt.Errorf(errorMsg)
+		err := bperrors.MissingInput(pinID)
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -297,7 +295,7 @@ func (n *SubtractNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("a", err)
 	}
 
 	b, err := bValue.AsNumber()
@@ -315,7 +313,7 @@ func (n *SubtractNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("b", err)
 	}
 
 	// Perform subtraction
@@ -423,14 +421,12 @@ func (n *MultiplyNode) Execute(ctx node.ExecutionContext) error {
 	}
 
 	if !aExists || !bExists {
-		errorMsg := ""
+		pinID := "b"
 		if !aExists {
-			errorMsg = "missing required input: a"
-		} else {
-			errorMsg = "missing required input: b"
+			pinID = "a"
 		}
 
-		err := fmt.Errorf(errorMsg)
+		err := bperrors.MissingInput(pinID)
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -463,7 +459,7 @@ func (n *MultiplyNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("a", err)
 	}
 
 	b, err := bValue.AsNumber()
@@ -481,7 +477,7 @@ func (n *MultiplyNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("b", err)
 	}
 
 	// Perform multiplication
@@ -595,14 +591,12 @@ func (n *DivideNode) Execute(ctx node.ExecutionContext) error {
 	}
 
 	if !aExists || !bExists {
-		errorMsg := ""
+		pinID := "b"
 		if !aExists {
-			errorMsg = "missing required input: a"
-		} else {
-			errorMsg = "missing required input: b"
+			pinID = "a"
 		}
 
-		err := fmt.Errorf(errorMsg)
+		err := bperrors.MissingInput(pinID)
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -635,7 +629,7 @@ func (n *DivideNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("a", err)
 	}
 
 	b, err := bValue.AsNumber()
@@ -653,7 +647,7 @@ func (n *DivideNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		return err
+		return bperrors.InvalidInput("b", err)
 	}
 
 	// Update debug data with actual values
@@ -664,7 +658,7 @@ func (n *DivideNode) Execute(ctx node.ExecutionContext) error {
 
 	// Check for division by zero
 	if b == 0 {
-		err := fmt.Errorf("division by zero")
+		err := bperrors.OperationFailed("division by zero")
 		logger.Error("Division by zero", nil)
 
 		debugData["error"] = map[string]string{
@@ -99,6 +99,9 @@ func (n *SafeDivideNode) GetMetadata() node.NodeMetadata {
 		Description: "Divides two numbers with error handling",
 		Category:    "Math",
 		Version:     "1.0.0",
+		PropertySchema: []node.PropertySchema{
+			{Name: "errorHandlingMode", Enum: []string{"auto", "manual", "default"}},
+		},
 	}
 }
 
@@ -168,7 +171,7 @@ func (n *SafeDivideNode) Execute(ctx node.ExecutionContext) error {
 				return ctx.ActivateOutputFlow("catch")
 			}
 		} else {
-			return fmt.Errorf("missing required dividend input")
+			return bperrors.MissingInput("dividend")
 		}
 	}
 
@@ -194,7 +197,7 @@ func (n *SafeDivideNode) Execute(ctx node.ExecutionContext) error {
 				return ctx.ActivateOutputFlow("catch")
 			}
 		} else {
-			return fmt.Errorf("missing required divisor input")
+			return bperrors.MissingInput("divisor")
 		}
 	}
 
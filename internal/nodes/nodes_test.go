@@ -5,6 +5,7 @@ import (
 	"testing"
 	node2 "webblueprint/internal/node"
 	"webblueprint/internal/nodes"
+	"webblueprint/pkg/blueprint"
 )
 
 // TestNodeFactories verifies that all registered node factories can create nodes
@@ -24,10 +25,17 @@ func TestNodeFactories(t *testing.T) {
 				t.Errorf("Node type mismatch: expected %s, got %s", nodeType, metadata.TypeID)
 			}
 
-			// Check that the node has input pins (except for constant nodes which may not have input pins)
+			// Annotations never execute and have no pins
+			if nodeType == blueprint.CommentNodeType {
+				return
+			}
+
+			// Check that the node has input pins (except for constant nodes which may not have input pins,
+			// and entry points, which the engine starts with the details of what triggered them)
 			isConstant := strings.HasPrefix(nodeType, "constant-")
+			isEntryPoint := nodeType == "event-on-created" || nodeType == "event-on-error"
 			inputs := node.GetInputPins()
-			if len(inputs) == 0 && !isConstant {
+			if len(inputs) == 0 && !isConstant && !isEntryPoint {
 				t.Errorf("Node has no input pins")
 			}
 
@@ -0,0 +1,253 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// aesKey decodes the AES key held by a secret, 16, 24 or 32 bytes encoded as hex or base64
+func aesKey(secret string) ([]byte, error) {
+	key, err := hex.DecodeString(secret)
+	if err != nil {
+		key, err = decode(encodingBase64, secret)
+	}
+	if err != nil {
+		return nil, errors.New("key must be encoded as hex or base64")
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("key must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// aesInputs returns the inputs of the AES-GCM nodes after their data input
+func aesInputs() []types.Pin {
+	return []types.Pin{
+		secretPin("key", "Key", "Secret holding the AES key, 16, 24 or 32 bytes as hex or base64", false),
+		{
+			ID:          "associatedData",
+			Name:        "Associated Data",
+			Description: "Data authenticated along with the ciphertext but not encrypted, e.g. an ID",
+			Type:        types.PinTypes.String,
+			Optional:    true,
+		},
+		encodingPin("Encoding of the ciphertext", encodingBase64),
+	}
+}
+
+// aesGCM creates the AES-GCM cipher of the key of a node
+func aesGCM(ctx node.ExecutionContext) (cipher.AEAD, []byte, *bperrors.BlueprintError) {
+	secret, bpErr := secretInput(ctx, "key")
+	if bpErr != nil {
+		return nil, nil, bpErr
+	}
+	key, err := aesKey(secret)
+	if err != nil {
+		return nil, nil, bperrors.InvalidInput("key", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, bperrors.InvalidInput("key", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, bperrors.InvalidInput("key", err)
+	}
+
+	associatedData, bpErr := stringInput(ctx, "associatedData", "")
+	if bpErr != nil {
+		return nil, nil, bpErr
+	}
+	return gcm, []byte(associatedData), nil
+}
+
+// EncryptNode encrypts data with AES-GCM. The ciphertext holds the random nonce it was
+// encrypted with followed by the sealed data, so decrypt only needs the key.
+type EncryptNode struct {
+	node.BaseNode
+}
+
+// NewEncryptNode creates a new Encrypt node
+func NewEncryptNode() node.Node {
+	return &EncryptNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "encrypt",
+				Name:        "Encrypt",
+				Description: "Encrypts data with AES-GCM using a key from the secrets store",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: append([]types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "data",
+					Name:        "Data",
+					Description: "Text or bytes to encrypt",
+					Type:        types.PinTypes.Any,
+				},
+			}, aesInputs()...),
+			Outputs: append(flowPins(),
+				types.Pin{
+					ID:          "ciphertext",
+					Name:        "Ciphertext",
+					Description: "Nonce and encrypted data",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that encryption uses a random nonce, so replays reuse the
+// recorded ciphertext
+func (n *EncryptNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *EncryptNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Encrypt node", nil)
+
+	data, bpErr := bytesInput(ctx, "data")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	encoding, bpErr := stringInput(ctx, "encoding", encodingBase64)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	gcm, associatedData, bpErr := aesGCM(ctx)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(data)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return fail(ctx, bperrors.OperationFailed(fmt.Sprintf("failed to generate a nonce: %v", err)))
+	}
+	sealed := gcm.Seal(nonce, nonce, data, associatedData)
+
+	ciphertext, err := encode(encoding, sealed)
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("encoding", err))
+	}
+	ctx.SetOutputValue("ciphertext", types.NewValue(types.PinTypes.String, ciphertext))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Encrypt",
+		Value: map[string]interface{}{
+			"size": len(data),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// DecryptNode decrypts and authenticates data encrypted by an encrypt node
+type DecryptNode struct {
+	node.BaseNode
+}
+
+// NewDecryptNode creates a new Decrypt node
+func NewDecryptNode() node.Node {
+	return &DecryptNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "decrypt",
+				Name:        "Decrypt",
+				Description: "Decrypts AES-GCM ciphertext using a key from the secrets store",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: append([]types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "ciphertext",
+					Name:        "Ciphertext",
+					Description: "Nonce and encrypted data, as produced by an encrypt node",
+					Type:        types.PinTypes.String,
+				},
+			}, aesInputs()...),
+			Outputs: append(flowPins(),
+				types.Pin{
+					ID:          "text",
+					Name:        "Text",
+					Description: "Decrypted data as text, null if it isn't valid UTF-8",
+					Type:        types.PinTypes.String,
+				},
+				types.Pin{
+					ID:          "bytes",
+					Name:        "Bytes",
+					Description: "Decrypted data",
+					Type:        types.PinTypes.Bytes,
+				},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *DecryptNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Decrypt node", nil)
+
+	encoded, bpErr := bytesInput(ctx, "ciphertext")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	encoding, bpErr := stringInput(ctx, "encoding", encodingBase64)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	gcm, associatedData, bpErr := aesGCM(ctx)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+
+	sealed, err := decode(encoding, string(encoded))
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("ciphertext", err))
+	}
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return fail(ctx, bperrors.InvalidInput("ciphertext", errors.New("ciphertext is too short")))
+	}
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	data, err := gcm.Open(nil, nonce, sealed, associatedData)
+	if err != nil {
+		// Wrong keys and tampered ciphertexts can't be told apart
+		return fail(ctx, bperrors.OperationFailed("decryption failed: the key is wrong or the data was modified"))
+	}
+	setDecodedOutputs(ctx, data)
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Decrypt",
+		Value: map[string]interface{}{
+			"size": len(data),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
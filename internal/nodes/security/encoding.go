@@ -0,0 +1,175 @@
+package security
+
+import (
+	"time"
+	"unicode/utf8"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// EncodeNode encodes text or bytes as hex, base64 or base64url
+type EncodeNode struct {
+	node.BaseNode
+}
+
+// NewEncodeNode creates a new Encode node
+func NewEncodeNode() node.Node {
+	return &EncodeNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "encode",
+				Name:        "Encode",
+				Description: "Encodes text or bytes as hex, base64 or base64url",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "data",
+					Name:        "Data",
+					Description: "Text or bytes to encode",
+					Type:        types.PinTypes.Any,
+				},
+				encodingPin("Encoding of the result", encodingBase64),
+			},
+			Outputs: append(flowPins(),
+				types.Pin{
+					ID:          "result",
+					Name:        "Result",
+					Description: "Encoded data",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *EncodeNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Encode node", nil)
+
+	data, bpErr := bytesInput(ctx, "data")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	encoding, bpErr := stringInput(ctx, "encoding", encodingBase64)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+
+	result, err := encode(encoding, data)
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("encoding", err))
+	}
+	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.String, result))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Encode",
+		Value: map[string]interface{}{
+			"encoding": encoding,
+			"size":     len(data),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// DecodeNode decodes hex, base64 or base64url text
+type DecodeNode struct {
+	node.BaseNode
+}
+
+// NewDecodeNode creates a new Decode node
+func NewDecodeNode() node.Node {
+	return &DecodeNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "decode",
+				Name:        "Decode",
+				Description: "Decodes hex, base64 or base64url text into text and bytes",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "data",
+					Name:        "Data",
+					Description: "Text to decode",
+					Type:        types.PinTypes.String,
+				},
+				encodingPin("Encoding of the data", encodingBase64),
+			},
+			Outputs: append(flowPins(),
+				types.Pin{
+					ID:          "text",
+					Name:        "Text",
+					Description: "Decoded data as text, null if it isn't valid UTF-8",
+					Type:        types.PinTypes.String,
+				},
+				types.Pin{
+					ID:          "bytes",
+					Name:        "Bytes",
+					Description: "Decoded data",
+					Type:        types.PinTypes.Bytes,
+				},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *DecodeNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing Decode node", nil)
+
+	text, bpErr := bytesInput(ctx, "data")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	encoding, bpErr := stringInput(ctx, "encoding", encodingBase64)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+
+	data, err := decode(encoding, string(text))
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("data", err))
+	}
+	setDecodedOutputs(ctx, data)
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Decode",
+		Value: map[string]interface{}{
+			"encoding": encoding,
+			"size":     len(data),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// setDecodedOutputs sets the text and bytes outputs of decoded or decrypted data
+func setDecodedOutputs(ctx node.ExecutionContext, data []byte) {
+	if utf8.Valid(data) {
+		ctx.SetOutputValue("text", types.NewValue(types.PinTypes.String, string(data)))
+	} else {
+		ctx.SetOutputValue("text", types.NewValue(types.PinTypes.String, nil))
+	}
+	ctx.SetOutputValue("bytes", types.NewValue(types.PinTypes.Bytes, data))
+}
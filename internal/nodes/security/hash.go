@@ -0,0 +1,162 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// hashAlgorithms are the hash functions of the hash node. SHA-1 is only there for
+// webhooks that still sign with it.
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// HashNode hashes data with SHA-2, or signs it with HMAC if it has a secret, and compares
+// the digest to an expected signature in constant time, e.g. the signature header of a
+// webhook
+type HashNode struct {
+	node.BaseNode
+}
+
+// NewHashNode creates a new Hash node
+func NewHashNode() node.Node {
+	return &HashNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "hash",
+				Name:        "Hash",
+				Description: "Hashes data with SHA-256 or signs it with HMAC, and verifies signatures",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "data",
+					Name:        "Data",
+					Description: "Text or bytes to hash",
+					Type:        types.PinTypes.Any,
+				},
+				{
+					ID:          "algorithm",
+					Name:        "Algorithm",
+					Description: "Hash function (sha256, sha384, sha512, sha1)",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+					Default:     "sha256",
+				},
+				secretPin("secret", "Secret", "Secret to sign the data with using HMAC", true),
+				encodingPin("Encoding of the digest", encodingHex),
+				{
+					ID:          "expected",
+					Name:        "Expected",
+					Description: "Signature to compare the digest to, optionally prefixed with the algorithm as in sha256=<digest>",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+			},
+			Outputs: append(flowPins(),
+				types.Pin{
+					ID:          "digest",
+					Name:        "Digest",
+					Description: "Hash or HMAC of the data",
+					Type:        types.PinTypes.String,
+				},
+				types.Pin{
+					ID:          "matches",
+					Name:        "Matches",
+					Description: "Whether the digest matches the expected signature",
+					Type:        types.PinTypes.Boolean,
+				},
+			),
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *HashNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Hash node", nil)
+
+	data, bpErr := bytesInput(ctx, "data")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	algorithm, bpErr := stringInput(ctx, "algorithm", "sha256")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	newHash, exists := hashAlgorithms[strings.ToLower(algorithm)]
+	if !exists {
+		return fail(ctx, bperrors.InvalidInput("algorithm", fmt.Errorf("unknown algorithm %q", algorithm)))
+	}
+	encoding, bpErr := stringInput(ctx, "encoding", encodingHex)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+
+	hasher := newHash()
+	signed := false
+	if secretValue, exists := ctx.GetInputValue("secret"); exists && !secretValue.IsNull() {
+		secret, bpErr := secretInput(ctx, "secret")
+		if bpErr != nil {
+			return fail(ctx, bpErr)
+		}
+		hasher = hmac.New(newHash, []byte(secret))
+		signed = true
+	}
+	hasher.Write(data)
+	sum := hasher.Sum(nil)
+
+	digest, err := encode(encoding, sum)
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("encoding", err))
+	}
+	ctx.SetOutputValue("digest", types.NewValue(types.PinTypes.String, digest))
+
+	matches := false
+	expected, bpErr := stringInput(ctx, "expected", "")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	if expected != "" {
+		expected = strings.TrimPrefix(expected, strings.ToLower(algorithm)+"=")
+		// Signatures that don't decode don't match
+		if expectedSum, err := decode(encoding, expected); err == nil {
+			matches = hmac.Equal(sum, expectedSum)
+		}
+	}
+	ctx.SetOutputValue("matches", types.NewValue(types.PinTypes.Boolean, matches))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Hash",
+		Value: map[string]interface{}{
+			"algorithm": algorithm,
+			"hmac":      signed,
+			"size":      len(data),
+			"compared":  expected != "",
+			"matches":   matches,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
@@ -0,0 +1,342 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// jwtAlgorithms are the HMAC algorithms JWTs are signed with
+var jwtAlgorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// jwtAlgorithmPin is the input choosing the algorithm of a JWT
+var jwtAlgorithmPin = types.Pin{
+	ID:          "algorithm",
+	Name:        "Algorithm",
+	Description: "Signing algorithm (HS256, HS384, HS512)",
+	Type:        types.PinTypes.String,
+	Optional:    true,
+	Default:     "HS256",
+}
+
+// jwtAlgorithm reads the algorithm input of a JWT node
+func jwtAlgorithm(ctx node.ExecutionContext) (string, func() hash.Hash, *bperrors.BlueprintError) {
+	algorithm, bpErr := stringInput(ctx, "algorithm", "HS256")
+	if bpErr != nil {
+		return "", nil, bpErr
+	}
+	algorithm = strings.ToUpper(algorithm)
+	newHash, exists := jwtAlgorithms[algorithm]
+	if !exists {
+		return "", nil, bperrors.InvalidInput("algorithm", fmt.Errorf("unsupported algorithm %q", algorithm))
+	}
+	return algorithm, newHash, nil
+}
+
+// signJWT signs the header and claims segments of a JWT
+func signJWT(newHash func() hash.Hash, secret, signingInput string) []byte {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// JWTSignNode signs claims as a JSON Web Token with HMAC
+type JWTSignNode struct {
+	node.BaseNode
+}
+
+// NewJWTSignNode creates a new JWT Sign node
+func NewJWTSignNode() node.Node {
+	return &JWTSignNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "jwt-sign",
+				Name:        "JWT Sign",
+				Description: "Signs claims as a JSON Web Token using a secret from the secrets store",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "claims",
+					Name:        "Claims",
+					Description: "Claims of the token",
+					Type:        types.PinTypes.Object,
+				},
+				secretPin("secret", "Secret", "Secret to sign the token with", false),
+				jwtAlgorithmPin,
+				{
+					ID:          "expiresIn",
+					Name:        "Expires In",
+					Description: "Seconds until the token expires, setting its iat and exp claims (0 for no expiry)",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     0,
+				},
+			},
+			Outputs: append(flowPins(),
+				types.Pin{
+					ID:          "token",
+					Name:        "Token",
+					Description: "Signed token",
+					Type:        types.PinTypes.String,
+				},
+			),
+		},
+	}
+}
+
+// IsNondeterministic reports that tokens with an expiry depend on the clock
+func (n *JWTSignNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *JWTSignNode) Execute(ctx node.ExecutionContext) error {
+	ctx.Logger().Debug("Executing JWT Sign node", nil)
+
+	claimsValue, exists := ctx.GetInputValue("claims")
+	if !exists {
+		return fail(ctx, bperrors.MissingInput("claims"))
+	}
+	claims, err := claimsValue.MutableObject()
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("claims", err))
+	}
+	algorithm, newHash, bpErr := jwtAlgorithm(ctx)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	secret, bpErr := secretInput(ctx, "secret")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+
+	if expiresValue, exists := ctx.GetInputValue("expiresIn"); exists && !expiresValue.IsNull() {
+		expiresIn, err := expiresValue.AsNumber()
+		if err != nil || expiresIn < 0 {
+			if err == nil {
+				err = fmt.Errorf("%v is negative", expiresIn)
+			}
+			return fail(ctx, bperrors.InvalidInput("expiresIn", err))
+		}
+		if expiresIn > 0 {
+			now := time.Now().Unix()
+			claims["iat"] = now
+			claims["exp"] = now + int64(expiresIn)
+		}
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return fail(ctx, bperrors.InvalidInput("claims", err))
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signJWT(newHash, secret, signingInput))
+	ctx.SetOutputValue("token", types.NewValue(types.PinTypes.String, token))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "JWT Sign",
+		Value: map[string]interface{}{
+			"algorithm": algorithm,
+			"claims":    len(claims),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// JWTVerifyNode verifies the signature and the expiry of a JSON Web Token signed with HMAC.
+// Tokens must use the algorithm of the node, so they can't pick a weaker one or none.
+type JWTVerifyNode struct {
+	node.BaseNode
+}
+
+// NewJWTVerifyNode creates a new JWT Verify node
+func NewJWTVerifyNode() node.Node {
+	return &JWTVerifyNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "jwt-verify",
+				Name:        "JWT Verify",
+				Description: "Verifies a JSON Web Token using a secret from the secrets store and outputs its claims",
+				Category:    "Security",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "token",
+					Name:        "Token",
+					Description: "Token to verify, optionally prefixed with Bearer",
+					Type:        types.PinTypes.String,
+				},
+				secretPin("secret", "Secret", "Secret the token was signed with", false),
+				jwtAlgorithmPin,
+				{
+					ID:          "leeway",
+					Name:        "Leeway",
+					Description: "Seconds of clock skew tolerated when checking the exp and nbf claims",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     0,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "valid",
+					Name:        "Valid",
+					Description: "Executed if the token is valid",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "invalid",
+					Name:        "Invalid",
+					Description: "Executed if the token is malformed, forged or expired",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          bperrors.CatchPinID,
+					Name:        "Catch",
+					Description: "Executed if the token can't be verified, e.g. without its secret",
+					Type:        types.PinTypes.Execution,
+				},
+				bperrors.ErrorPin(),
+				{
+					ID:          "claims",
+					Name:        "Claims",
+					Description: "Claims of the valid token",
+					Type:        types.PinTypes.Object,
+				},
+				{
+					ID:          "reason",
+					Name:        "Reason",
+					Description: "Why the token is invalid",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+	}
+}
+
+// IsNondeterministic reports that the expiry of tokens depends on the clock
+func (n *JWTVerifyNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *JWTVerifyNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing JWT Verify node", nil)
+
+	tokenBytes, bpErr := bytesInput(ctx, "token")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	algorithm, newHash, bpErr := jwtAlgorithm(ctx)
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	secret, bpErr := secretInput(ctx, "secret")
+	if bpErr != nil {
+		return fail(ctx, bpErr)
+	}
+	var leeway int64
+	if leewayValue, exists := ctx.GetInputValue("leeway"); exists && !leewayValue.IsNull() {
+		seconds, err := leewayValue.AsNumber()
+		if err != nil {
+			return fail(ctx, bperrors.InvalidInput("leeway", err))
+		}
+		leeway = int64(seconds)
+	}
+
+	token := strings.TrimSpace(string(tokenBytes))
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+
+	claims, reason := verifyJWT(token, algorithm, newHash, secret, time.Now().Unix(), leeway)
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "JWT Verify",
+		Value: map[string]interface{}{
+			"algorithm": algorithm,
+			"valid":     reason == "",
+			"reason":    reason,
+		},
+		Timestamp: time.Now(),
+	})
+
+	if reason != "" {
+		logger.Info("Invalid token", map[string]interface{}{"reason": reason})
+		ctx.SetOutputValue("reason", types.NewValue(types.PinTypes.String, reason))
+		return ctx.ActivateOutputFlow("invalid")
+	}
+	ctx.SetOutputValue("claims", types.NewValue(types.PinTypes.Object, claims))
+	return ctx.ActivateOutputFlow("valid")
+}
+
+// verifyJWT verifies a token at a time, returning its claims or why it's invalid
+func verifyJWT(token, algorithm string, newHash func() hash.Hash, secret string, now, leeway int64) (map[string]interface{}, string) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, "token must have 3 segments"
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(segments[0])
+	if err != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return nil, "malformed header"
+	}
+	if header.Algorithm != algorithm {
+		return nil, fmt.Sprintf("unexpected algorithm %q", header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil || !hmac.Equal(signature, signJWT(newHash, secret, segments[0]+"."+segments[1])) {
+		return nil, "invalid signature"
+	}
+
+	var claims map[string]interface{}
+	payload, err := base64.RawURLEncoding.DecodeString(segments[1])
+	if err != nil || json.Unmarshal(payload, &claims) != nil || claims == nil {
+		return nil, "malformed claims"
+	}
+	if exp, ok := claims["exp"].(float64); ok && now > int64(exp)+leeway {
+		return nil, "token is expired"
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < int64(nbf)-leeway {
+		return nil, "token is not valid yet"
+	}
+	return claims, ""
+}
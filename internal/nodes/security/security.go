@@ -0,0 +1,155 @@
+// Package security implements the hashing, encoding, encryption and JWT nodes, so
+// blueprints can verify webhook signatures and handle sensitive payloads without script
+// nodes. Keys are read from the secrets store by name and never pass through pins.
+package security
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/secrets"
+	"webblueprint/internal/types"
+)
+
+// Text encodings of binary data
+const (
+	encodingHex       = "hex"
+	encodingBase64    = "base64"
+	encodingBase64URL = "base64url"
+)
+
+// flowPins are the execution outputs of the security nodes
+func flowPins() []types.Pin {
+	return []types.Pin{
+		{
+			ID:          "then",
+			Name:        "Then",
+			Description: "Execution continues",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          bperrors.CatchPinID,
+			Name:        "Catch",
+			Description: "Executed if the operation fails",
+			Type:        types.PinTypes.Execution,
+		},
+		bperrors.ErrorPin(),
+	}
+}
+
+// encodingPin is the input choosing the text encoding of binary data
+func encodingPin(description, fallback string) types.Pin {
+	return types.Pin{
+		ID:          "encoding",
+		Name:        "Encoding",
+		Description: description + " (hex, base64, base64url)",
+		Type:        types.PinTypes.String,
+		Optional:    true,
+		Default:     fallback,
+	}
+}
+
+// secretPin is the input naming a secret of the secrets store
+func secretPin(id, name, description string, optional bool) types.Pin {
+	return types.Pin{
+		ID:          id,
+		Name:        name,
+		Description: description + ", as a name or {{secrets.name}}",
+		Type:        types.PinTypes.String,
+		Optional:    optional,
+	}
+}
+
+// stringInput reads an optional string input, the fallback if it isn't connected
+func stringInput(ctx node.ExecutionContext, pinID, fallback string) (string, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.IsNull() {
+		return fallback, nil
+	}
+	text, err := value.AsString()
+	if err != nil {
+		return "", bperrors.InvalidInput(pinID, err)
+	}
+	if text == "" {
+		return fallback, nil
+	}
+	return text, nil
+}
+
+// bytesInput reads a required input of text or bytes
+func bytesInput(ctx node.ExecutionContext, pinID string) ([]byte, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists {
+		return nil, bperrors.MissingInput(pinID)
+	}
+	data, err := value.AsBytes()
+	if err != nil {
+		return nil, bperrors.InvalidInput(pinID, err)
+	}
+	return data, nil
+}
+
+// secretInput resolves the secret named by an input. Errors name the secret, never its value.
+func secretInput(ctx node.ExecutionContext, pinID string) (string, *bperrors.BlueprintError) {
+	reference, bpErr := stringInput(ctx, pinID, "")
+	if bpErr != nil {
+		return "", bpErr
+	}
+	if reference == "" {
+		return "", bperrors.MissingInput(pinID)
+	}
+	secret, err := secrets.Default().Get(ctx.Context(), secrets.Name(reference))
+	if err != nil {
+		if !errors.Is(err, secrets.ErrNotFound) {
+			return "", bperrors.Wrap(err, bperrors.ErrorTypeSystem, bperrors.ErrOperationFailed,
+				fmt.Sprintf("failed to read secret %s: %v", secrets.Name(reference), err), bperrors.SeverityHigh)
+		}
+		return "", bperrors.InvalidInput(pinID, err)
+	}
+	return secret, nil
+}
+
+// encode encodes binary data as text
+func encode(encoding string, data []byte) (string, error) {
+	switch encoding {
+	case encodingHex:
+		return hex.EncodeToString(data), nil
+	case encodingBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case encodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	default:
+		return "", fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
+
+// decode decodes text into binary data. base64 and base64url accept text with or
+// without padding.
+func decode(encoding, text string) ([]byte, error) {
+	switch encoding {
+	case encodingHex:
+		return hex.DecodeString(text)
+	case encodingBase64:
+		if len(text)%4 != 0 {
+			return base64.RawStdEncoding.DecodeString(text)
+		}
+		return base64.StdEncoding.DecodeString(text)
+	case encodingBase64URL:
+		if len(text)%4 != 0 {
+			return base64.RawURLEncoding.DecodeString(text)
+		}
+		return base64.URLEncoding.DecodeString(text)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
+
+// fail reports an error of a security node on its catch flow
+func fail(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow(bperrors.CatchPinID)
+}
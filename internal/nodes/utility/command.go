@@ -0,0 +1,260 @@
+package utility
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/sandbox"
+	"webblueprint/internal/types"
+)
+
+// RunCommandNode implements a node that runs a local command in the sandbox the server
+// configures. Commands are disabled without one, and workspaces have to allow the node
+// type in their node policies.
+type RunCommandNode struct {
+	node.BaseNode
+}
+
+// NewRunCommandNode creates a new Run Command node
+func NewRunCommandNode() node.Node {
+	return &RunCommandNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "run-command",
+				Name:        "Run Command",
+				Description: "Runs an allowed local command or script and outputs what it printed",
+				Category:    "Utility",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "command",
+					Name:        "Command",
+					Description: "Binary to run, as allowed by the server; it isn't run by a shell",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "args",
+					Name:        "Arguments",
+					Description: "Arguments of the command",
+					Type:        types.PinTypes.Array,
+					Optional:    true,
+				},
+				{
+					ID:          "workDir",
+					Name:        "Working Directory",
+					Description: "Directory to run in, relative to the sandbox root",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+				{
+					ID:          "env",
+					Name:        "Environment",
+					Description: "Environment variables to set, on top of the ones the server passes on; those the server doesn't allow are dropped",
+					Type:        types.PinTypes.Object,
+					Optional:    true,
+				},
+				{
+					ID:          "stdin",
+					Name:        "Standard Input",
+					Description: "Text written to the standard input of the command",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+				{
+					ID:          "timeout",
+					Name:        "Timeout",
+					Description: "Milliseconds the command may run, capped by the server; the server's limit if 0",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     0,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Executed if the command exits with 0",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "failed",
+					Name:        "Failed",
+					Description: "Executed if the command exits with another code or exceeds its time limit",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "catch",
+					Name:        "Catch",
+					Description: "Executed if the command can't run, e.g. because it isn't allowed",
+					Type:        types.PinTypes.Execution,
+				},
+				bperrors.ErrorPin(),
+				{
+					ID:          "exitCode",
+					Name:        "Exit Code",
+					Description: "Exit code of the command, -1 if it was killed",
+					Type:        types.PinTypes.Number,
+				},
+				{
+					ID:          "stdout",
+					Name:        "Standard Output",
+					Description: "What the command printed to its standard output",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "stderr",
+					Name:        "Standard Error",
+					Description: "What the command printed to its standard error",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+	}
+}
+
+// IsNondeterministic reports that commands have side effects, so replays reuse the
+// recorded output instead of running them again
+func (n *RunCommandNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *RunCommandNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Run Command node", nil)
+
+	box := sandbox.Default()
+	if box == nil {
+		return failCommand(ctx, commandNotAllowed(errors.New("commands are disabled on this server")))
+	}
+
+	command, bpErr := commandInput(ctx)
+	if bpErr != nil {
+		return failCommand(ctx, bpErr)
+	}
+
+	result, err := box.Run(ctx.Context(), command)
+	if errors.Is(err, sandbox.ErrNotAllowed) || errors.Is(err, sandbox.ErrOutsideRoot) {
+		return failCommand(ctx, commandNotAllowed(err))
+	}
+	if err != nil {
+		return failCommand(ctx, bperrors.Wrap(err, bperrors.ErrorTypeExecution, bperrors.ErrOperationFailed,
+			fmt.Sprintf("failed to run %s: %v", command.Name, err), bperrors.SeverityHigh))
+	}
+
+	if len(result.DroppedEnv) > 0 {
+		logger.Warn("Dropped environment variables the server doesn't allow", map[string]interface{}{
+			"command":   command.Name,
+			"variables": result.DroppedEnv,
+		})
+	}
+
+	ctx.SetOutputValue("exitCode", types.NewValue(types.PinTypes.Number, float64(result.ExitCode)))
+	ctx.SetOutputValue("stdout", types.NewValue(types.PinTypes.String, result.Stdout))
+	ctx.SetOutputValue("stderr", types.NewValue(types.PinTypes.String, result.Stderr))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Run Command",
+		Value: map[string]interface{}{
+			"command":    command.Name,
+			"args":       command.Args,
+			"exitCode":   result.ExitCode,
+			"timedOut":   result.TimedOut,
+			"truncated":  result.Truncated,
+			"droppedEnv": result.DroppedEnv,
+			"duration":   result.Duration.String(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	if result.ExitCode != 0 || result.TimedOut {
+		logger.Info("Command failed", map[string]interface{}{
+			"command":  command.Name,
+			"exitCode": result.ExitCode,
+			"timedOut": result.TimedOut,
+		})
+		return ctx.ActivateOutputFlow("failed")
+	}
+	return ctx.ActivateOutputFlow("then")
+}
+
+// commandInput reads the command of the node from its inputs
+func commandInput(ctx node.ExecutionContext) (sandbox.Command, *bperrors.BlueprintError) {
+	var command sandbox.Command
+
+	nameValue, exists := ctx.GetInputValue("command")
+	if !exists || nameValue.RawValue == nil {
+		return command, bperrors.MissingInput("command")
+	}
+	name, err := nameValue.AsString()
+	if err != nil || name == "" {
+		if err == nil {
+			err = errors.New("command is empty")
+		}
+		return command, bperrors.InvalidInput("command", err)
+	}
+	command.Name = name
+
+	if argsValue, exists := ctx.GetInputValue("args"); exists && argsValue.RawValue != nil {
+		args, err := argsValue.AsArray()
+		if err != nil {
+			return command, bperrors.InvalidInput("args", err)
+		}
+		for _, arg := range args {
+			// Numbers and booleans are passed as they print
+			command.Args = append(command.Args, fmt.Sprint(arg))
+		}
+	}
+
+	if dirValue, exists := ctx.GetInputValue("workDir"); exists && dirValue.RawValue != nil {
+		if command.Dir, err = dirValue.AsString(); err != nil {
+			return command, bperrors.InvalidInput("workDir", err)
+		}
+	}
+
+	if envValue, exists := ctx.GetInputValue("env"); exists && envValue.RawValue != nil {
+		env, err := envValue.AsObject()
+		if err != nil {
+			return command, bperrors.InvalidInput("env", err)
+		}
+		command.Env = make(map[string]string, len(env))
+		for name, value := range env {
+			command.Env[name] = fmt.Sprint(value)
+		}
+	}
+
+	if stdinValue, exists := ctx.GetInputValue("stdin"); exists && stdinValue.RawValue != nil {
+		if command.Stdin, err = stdinValue.AsString(); err != nil {
+			return command, bperrors.InvalidInput("stdin", err)
+		}
+	}
+
+	timeout, bpErr := lockDuration(ctx, "timeout", 0)
+	if bpErr != nil {
+		return command, bpErr
+	}
+	command.Timeout = timeout
+
+	return command, nil
+}
+
+// commandNotAllowed creates the error of a command the sandbox refuses
+func commandNotAllowed(err error) *bperrors.BlueprintError {
+	return bperrors.Wrap(err, bperrors.ErrorTypePermission, bperrors.ErrCommandNotAllowed, err.Error(), bperrors.SeverityHigh)
+}
+
+func failCommand(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow("catch")
+}
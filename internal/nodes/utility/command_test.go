@@ -0,0 +1,76 @@
+package utility_test
+
+import (
+	"testing"
+	"time"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/sandbox"
+	"webblueprint/internal/test"
+)
+
+func TestRunCommandNode(t *testing.T) {
+	sandbox.SetDefault(nil)
+	test.ExecuteNodeTestCase(t, utility.NewRunCommandNode(), test.NodeTestCase{
+		Name:         "commands disabled",
+		Inputs:       map[string]interface{}{"command": "sh", "args": []interface{}{"-c", "echo hi"}},
+		ExpectedFlow: "catch",
+	})
+
+	box, err := sandbox.New(sandbox.Policy{Allow: []string{"sh"}, AllowEnv: []string{"GREETING"}, Root: t.TempDir(), Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create sandbox: %v", err)
+	}
+	sandbox.SetDefault(box)
+	defer sandbox.SetDefault(nil)
+
+	testCases := []test.NodeTestCase{
+		{
+			Name: "command succeeds",
+			Inputs: map[string]interface{}{
+				"command": "sh",
+				"args":    []interface{}{"-c", `read name; echo "$GREETING, $name"`},
+				"env":     map[string]interface{}{"GREETING": "Hello"},
+				"stdin":   "Ada\n",
+			},
+			ExpectedOutputs: map[string]interface{}{"exitCode": 0.0, "stdout": "Hello, Ada\n", "stderr": ""},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name: "variables the server doesn't allow are dropped",
+			Inputs: map[string]interface{}{
+				"command": "sh",
+				"args":    []interface{}{"-c", `echo "$BASH_ENV$NODE_OPTIONS"`},
+				"env":     map[string]interface{}{"BASH_ENV": "/tmp/hook.sh", "NODE_OPTIONS": "--require /tmp/hook.js"},
+			},
+			ExpectedOutputs: map[string]interface{}{"exitCode": 0.0, "stdout": "\n"},
+			ExpectedFlow:    "then",
+		},
+		{
+			Name:            "command exits with an error",
+			Inputs:          map[string]interface{}{"command": "sh", "args": []interface{}{"-c", "echo oops >&2; exit 2"}},
+			ExpectedOutputs: map[string]interface{}{"exitCode": 2.0, "stderr": "oops\n"},
+			ExpectedFlow:    "failed",
+		},
+		{
+			Name:         "command not allowed",
+			Inputs:       map[string]interface{}{"command": "ls"},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "working directory outside the root",
+			Inputs:       map[string]interface{}{"command": "sh", "workDir": "../"},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name:         "missing command",
+			Inputs:       map[string]interface{}{},
+			ExpectedFlow: "catch",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, utility.NewRunCommandNode(), tc)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package utility
+
+import (
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+)
+
+// CommentNode documents a blueprint on the canvas. It has no pins, and the engine and the
+// validator skip it, so it never runs.
+type CommentNode struct {
+	node.BaseNode
+}
+
+// NewCommentNode creates a new Comment node
+func NewCommentNode() node.Node {
+	return &CommentNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      blueprint.CommentNodeType,
+				Name:        "Comment",
+				Description: "Documents the blueprint inline; never executes",
+				Category:    "Utility",
+				Version:     "1.0.0",
+				PropertySchema: []node.PropertySchema{
+					{Name: "text", Editor: node.PropertyEditorMultiline},
+				},
+			},
+			Inputs:  []types.Pin{},
+			Outputs: []types.Pin{},
+			Properties: []types.Property{
+				{
+					Name:        "title",
+					DisplayName: "Title",
+					Description: "Heading of the comment",
+					Type:        types.PinTypes.String,
+				},
+				{
+					Name:        "text",
+					DisplayName: "Text",
+					Description: "Text of the comment",
+					Type:        types.PinTypes.String,
+				},
+				{
+					Name:         "color",
+					DisplayName:  "Color",
+					Description:  "Background color of the comment",
+					Type:         types.PinTypes.String,
+					DefaultValue: "#fff3b0",
+				},
+				{
+					Name:         "collapsed",
+					DisplayName:  "Collapsed",
+					Description:  "Whether only the title is shown",
+					Type:         types.PinTypes.Boolean,
+					DefaultValue: false,
+				},
+			},
+		},
+	}
+}
+
+// Execute does nothing; comment nodes are skipped before they could run
+func (n *CommentNode) Execute(ctx node.ExecutionContext) error {
+	return nil
+}
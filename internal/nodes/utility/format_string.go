@@ -0,0 +1,155 @@
+package utility
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+// formatArgs is the variadic group of the arguments of a format-string node
+var formatArgs = node.VariadicPins{
+	Prefix:      "arg_",
+	Name:        "Argument",
+	Description: "Value replacing its placeholder in the template",
+	Type:        types.PinTypes.Any,
+	CountKey:    "args",
+	Default:     1,
+	Min:         0,
+	Max:         32,
+}
+
+// FormatStringNode fills the placeholders {1}, {2}, ... of a template with a variable
+// number of arguments, declared in the node data:
+//
+//	{"args": 3}
+type FormatStringNode struct {
+	node.BaseNode
+	args int
+}
+
+// NewFormatStringNode creates a new Format String node
+func NewFormatStringNode() node.Node {
+	n := &FormatStringNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "format-string",
+				Name:        "Format String",
+				Description: "Replaces the placeholders {1}, {2}, ... of a template with any number of arguments",
+				Category:    "Utility",
+				Version:     "1.0.0",
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "result",
+					Name:        "Result",
+					Description: "Formatted text",
+					Type:        types.PinTypes.String,
+				},
+			},
+		},
+		args: formatArgs.Default,
+	}
+	n.Inputs = formatInputPins(formatArgs.Default)
+	return n
+}
+
+// formatInputPins returns the input pins of a format-string node
+func formatInputPins(count int) []types.Pin {
+	pins := []types.Pin{
+		{
+			ID:          "exec",
+			Name:        "Execute",
+			Description: "Execution input",
+			Type:        types.PinTypes.Execution,
+		},
+		{
+			ID:          "template",
+			Name:        "Template",
+			Description: "Text with placeholders {1}, {2}, ...",
+			Type:        types.PinTypes.String,
+		},
+	}
+	return append(pins, formatArgs.Pins(count)...)
+}
+
+// VariadicPins returns the argument inputs of the node
+func (n *FormatStringNode) VariadicPins() []node.VariadicPins {
+	return []node.VariadicPins{formatArgs}
+}
+
+// ConfigurePins declares the argument inputs from the node data
+func (n *FormatStringNode) ConfigurePins(data map[string]interface{}) error {
+	count, err := formatArgs.Count(data)
+	if err != nil {
+		return err
+	}
+	n.args = count
+	n.Inputs = formatInputPins(count)
+	return nil
+}
+
+// Execute runs the node logic
+func (n *FormatStringNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Format String node", map[string]interface{}{"args": n.args})
+
+	templateValue, exists := ctx.GetInputValue("template")
+	if !exists {
+		return bperrors.MissingInput("template")
+	}
+	template, err := templateValue.AsString()
+	if err != nil {
+		return bperrors.InvalidInput("template", err)
+	}
+
+	// Unconnected arguments leave an empty text in place of their placeholder
+	replacements := make([]string, 0, n.args*2)
+	for i := 1; i <= n.args; i++ {
+		text := ""
+		if value, exists := ctx.GetInputValue(formatArgs.PinID(i)); exists {
+			text = formatArgument(value)
+		}
+		replacements = append(replacements, fmt.Sprintf("{%d}", i), text)
+	}
+	result := strings.NewReplacer(replacements...).Replace(template)
+
+	ctx.SetOutputValue("result", types.NewValue(types.PinTypes.String, result))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Format String",
+		Value: map[string]interface{}{
+			"template": template,
+			"args":     n.args,
+			"result":   result,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return ctx.ActivateOutputFlow("then")
+}
+
+// formatArgument returns the text of an argument. Objects and arrays are written as JSON.
+func formatArgument(value types.Value) string {
+	switch raw := value.RawValue.(type) {
+	case nil:
+		return ""
+	case string:
+		return raw
+	case map[string]interface{}, []interface{}:
+		if encoded, err := json.Marshal(raw); err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprintf("%v", value.RawValue)
+}
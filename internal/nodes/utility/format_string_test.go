@@ -0,0 +1,52 @@
+package utility_test
+
+import (
+	"testing"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/test"
+)
+
+func TestFormatStringNode(t *testing.T) {
+	testCases := []test.NodeTestCase{
+		{
+			Name: "fill placeholders",
+			Inputs: map[string]interface{}{
+				"template": "Hello, {1} and {2}!",
+				"arg_1":    "Ada",
+				"arg_2":    map[string]interface{}{"id": 7.0},
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"result": `Hello, Ada and {"id":7}!`,
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "leave unconnected arguments empty",
+			Inputs: map[string]interface{}{
+				"template": "[{1}] [{3}]",
+				"arg_2":    42.0,
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"result": "[] [{3}]",
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name:          "missing template",
+			Inputs:        map[string]interface{}{},
+			ExpectedError: true,
+			ErrorContains: "template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			formatNode := utility.NewFormatStringNode()
+			if err := node.ConfigurePins(formatNode, map[string]interface{}{"args": 2.0}); err != nil {
+				t.Fatalf("Failed to configure pins: %v", err)
+			}
+			test.ExecuteNodeTestCase(t, formatNode, tc)
+		})
+	}
+}
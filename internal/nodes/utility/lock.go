@@ -0,0 +1,280 @@
+package utility
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/locks"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+)
+
+const (
+	defaultLockTTL = 30 * time.Second // TTL of a lock without a ttl input
+	maxLockTimeout = 10 * time.Minute // Longest an acquire-lock node waits for a lock
+)
+
+// AcquireLockNode implements a node that takes a named lock shared by all executions
+type AcquireLockNode struct {
+	node.BaseNode
+}
+
+// NewAcquireLockNode creates a new Acquire Lock node
+func NewAcquireLockNode() node.Node {
+	return &AcquireLockNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "acquire-lock",
+				Name:        "Acquire Lock",
+				Description: "Takes a named lock or semaphore permit shared by all executions, released when the execution ends",
+				Category:    "Utility",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "name",
+					Name:        "Name",
+					Description: "Name of the lock",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "ttl",
+					Name:        "TTL",
+					Description: "Milliseconds after which the lock is released if the execution didn't release it",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     defaultLockTTL.Milliseconds(),
+				},
+				{
+					ID:          "timeout",
+					Name:        "Timeout",
+					Description: "Milliseconds to wait for the lock, 0 to try once",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     0,
+				},
+				{
+					ID:          "permits",
+					Name:        "Permits",
+					Description: "Number of executions that can hold the lock at once, making it a semaphore",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     1,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues once the lock is held",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "timedOut",
+					Name:        "Timed Out",
+					Description: "Executed if the lock is still held by other executions when the timeout runs out",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "catch",
+					Name:        "Catch",
+					Description: "Executed if the lock can't be acquired",
+					Type:        types.PinTypes.Execution,
+				},
+				bperrors.ErrorPin(),
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *AcquireLockNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Acquire Lock node", nil)
+
+	name, bpErr := lockName(ctx)
+	if bpErr != nil {
+		return failLock(ctx, bpErr)
+	}
+
+	ttl, bpErr := lockDuration(ctx, "ttl", defaultLockTTL)
+	if bpErr != nil {
+		return failLock(ctx, bpErr)
+	}
+	if ttl <= 0 {
+		return failLock(ctx, bperrors.InvalidInput("ttl", errors.New("TTL must be positive")))
+	}
+
+	timeout, bpErr := lockDuration(ctx, "timeout", 0)
+	if bpErr != nil {
+		return failLock(ctx, bpErr)
+	}
+	if timeout > maxLockTimeout {
+		timeout = maxLockTimeout
+	}
+
+	permits := 1
+	if permitsValue, exists := ctx.GetInputValue("permits"); exists && permitsValue.RawValue != nil {
+		value, err := permitsValue.AsNumber()
+		if err != nil || value < 1 {
+			if err == nil {
+				err = fmt.Errorf("%v is less than 1", value)
+			}
+			return failLock(ctx, bperrors.InvalidInput("permits", err))
+		}
+		permits = int(value)
+	}
+
+	start := time.Now()
+	acquired, err := locks.Acquire(ctx.Context(), locks.Default(), name, ctx.GetExecutionID(), permits, ttl, timeout)
+	if err != nil {
+		return failLock(ctx, bperrors.Wrap(err, bperrors.ErrorTypeSystem, bperrors.ErrOperationFailed,
+			fmt.Sprintf("failed to acquire lock %s: %v", name, err), bperrors.SeverityHigh).WithRecoveryOptions(bperrors.RecoveryRetry))
+	}
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Acquire Lock",
+		Value: map[string]interface{}{
+			"name":     name,
+			"permits":  permits,
+			"ttl":      ttl.Milliseconds(),
+			"acquired": acquired,
+			"waited":   time.Since(start).Milliseconds(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	if !acquired {
+		logger.Info("Lock is held by other executions", map[string]interface{}{"name": name, "timeout": timeout.Milliseconds()})
+		return ctx.ActivateOutputFlow("timedOut")
+	}
+	return ctx.ActivateOutputFlow("then")
+}
+
+// ReleaseLockNode implements a node that gives back a lock taken by Acquire Lock
+type ReleaseLockNode struct {
+	node.BaseNode
+}
+
+// NewReleaseLockNode creates a new Release Lock node
+func NewReleaseLockNode() node.Node {
+	return &ReleaseLockNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "release-lock",
+				Name:        "Release Lock",
+				Description: "Gives back a named lock or semaphore permit held by the execution",
+				Category:    "Utility",
+				Version:     "1.0.0",
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "name",
+					Name:        "Name",
+					Description: "Name of the lock",
+					Type:        types.PinTypes.String,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Execution continues",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "catch",
+					Name:        "Catch",
+					Description: "Executed if the lock can't be released",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "released",
+					Name:        "Released",
+					Description: "Whether the execution held the lock, rather than its TTL having run out",
+					Type:        types.PinTypes.Boolean,
+				},
+				bperrors.ErrorPin(),
+			},
+		},
+	}
+}
+
+// Execute runs the node logic
+func (n *ReleaseLockNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing Release Lock node", nil)
+
+	name, bpErr := lockName(ctx)
+	if bpErr != nil {
+		return failLock(ctx, bpErr)
+	}
+
+	// The lock is released even once the execution is cancelled, rather than held until its ttl
+	released, err := locks.Default().Release(context.Background(), name, ctx.GetExecutionID())
+	if err != nil {
+		return failLock(ctx, bperrors.Wrap(err, bperrors.ErrorTypeSystem, bperrors.ErrOperationFailed,
+			fmt.Sprintf("failed to release lock %s: %v", name, err), bperrors.SeverityHigh))
+	}
+	if !released {
+		logger.Warn("Lock wasn't held by the execution", map[string]interface{}{"name": name})
+	}
+
+	ctx.SetOutputValue("released", types.NewValue(types.PinTypes.Boolean, released))
+	return ctx.ActivateOutputFlow("then")
+}
+
+// lockName reads the name input of a lock node
+func lockName(ctx node.ExecutionContext) (string, *bperrors.BlueprintError) {
+	nameValue, exists := ctx.GetInputValue("name")
+	if !exists {
+		return "", bperrors.MissingInput("name")
+	}
+
+	name, err := nameValue.AsString()
+	if err != nil {
+		return "", bperrors.InvalidInput("name", err)
+	}
+	if name == "" {
+		return "", bperrors.InvalidInput("name", errors.New("name is empty"))
+	}
+	return name, nil
+}
+
+// lockDuration reads an input in milliseconds
+func lockDuration(ctx node.ExecutionContext, pinID string, fallback time.Duration) (time.Duration, *bperrors.BlueprintError) {
+	value, exists := ctx.GetInputValue(pinID)
+	if !exists || value.RawValue == nil {
+		return fallback, nil
+	}
+
+	ms, err := value.AsNumber()
+	if err != nil || ms < 0 {
+		if err == nil {
+			err = fmt.Errorf("%v is negative", ms)
+		}
+		return 0, bperrors.InvalidInput(pinID, err)
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+func failLock(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow("catch")
+}
@@ -0,0 +1,93 @@
+package utility_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"webblueprint/internal/locks"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/test"
+)
+
+func TestLockNodes(t *testing.T) {
+	locker := locks.NewMemoryLocker()
+	locks.SetDefault(locker)
+	defer locks.SetDefault(locks.NewMemoryLocker())
+
+	// Another execution holds the billing lock
+	locker.TryAcquire(context.Background(), "billing", "other-execution", 1, time.Minute)
+
+	acquireCases := []test.NodeTestCase{
+		{
+			Name: "acquire free lock",
+			Inputs: map[string]interface{}{
+				"name": "reports",
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "acquire held lock",
+			Inputs: map[string]interface{}{
+				"name":    "billing",
+				"timeout": 10,
+			},
+			ExpectedFlow: "timedOut",
+		},
+		{
+			Name: "acquire semaphore permit",
+			Inputs: map[string]interface{}{
+				"name":    "billing",
+				"permits": 2,
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name:         "missing name",
+			Inputs:       map[string]interface{}{},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name: "invalid permits",
+			Inputs: map[string]interface{}{
+				"name":    "billing",
+				"permits": 0,
+			},
+			ExpectedFlow: "catch",
+		},
+	}
+
+	for _, tc := range acquireCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, utility.NewAcquireLockNode(), tc)
+		})
+	}
+
+	releaseCases := []test.NodeTestCase{
+		{
+			Name: "release held lock",
+			Inputs: map[string]interface{}{
+				"name": "reports",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"released": true,
+			},
+			ExpectedFlow: "then",
+		},
+		{
+			Name: "release lock that isn't held",
+			Inputs: map[string]interface{}{
+				"name": "reports",
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"released": false,
+			},
+			ExpectedFlow: "then",
+		},
+	}
+
+	for _, tc := range releaseCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			test.ExecuteNodeTestCase(t, utility.NewReleaseLockNode(), tc)
+		})
+	}
+}
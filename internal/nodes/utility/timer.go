@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -110,6 +111,11 @@ func NewTimerNode() node.Node {
 	}
 }
 
+// IsNondeterministic reports that timestamps and delays depend on the clock
+func (n *TimerNode) IsNondeterministic() bool {
+	return true
+}
+
 // Execute runs the node logic
 func (n *TimerNode) Execute(ctx node.ExecutionContext) error {
 	logger := ctx.Logger()
@@ -127,9 +133,9 @@ func (n *TimerNode) Execute(ctx node.ExecutionContext) error {
 
 	// Check if operation is specified
 	if !operationExists {
-		err := fmt.Errorf("missing required input: operation")
+		err := bperrors.MissingInput("operation")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
 
 		debugData["error"] = err.Error()
 		ctx.RecordDebugInfo(types.DebugInfo{
@@ -0,0 +1,407 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/circuit"
+	"webblueprint/internal/node"
+	"webblueprint/internal/types"
+	"webblueprint/internal/usage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCCallNode implements a unary gRPC call using a user supplied descriptor set
+type GRPCCallNode struct {
+	node.BaseNode
+}
+
+// NewGRPCCallNode creates a new gRPC call node
+func NewGRPCCallNode() node.Node {
+	return &GRPCCallNode{
+		BaseNode: node.BaseNode{
+			Metadata: node.NodeMetadata{
+				TypeID:      "grpc-call",
+				Name:        "gRPC Call",
+				Description: "Invokes a unary gRPC method described by an uploaded .proto descriptor set",
+				Category:    "Web",
+				Version:     "1.0.0",
+				PropertySchema: []node.PropertySchema{
+					{Name: "descriptorSet", Editor: node.PropertyEditorMultiline, Placeholder: "Base64 encoded FileDescriptorSet"},
+				},
+			},
+			Inputs: []types.Pin{
+				{
+					ID:          "exec",
+					Name:        "Execute",
+					Description: "Execution input",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "target",
+					Name:        "Target",
+					Description: "Service address (host:port)",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "method",
+					Name:        "Method",
+					Description: "Fully qualified method (pkg.Service/Method)",
+					Type:        types.PinTypes.String,
+				},
+				{
+					ID:          "request",
+					Name:        "Request",
+					Description: "Request message as an object",
+					Type:        types.PinTypes.Object,
+					Optional:    true,
+				},
+				{
+					ID:          "metadata",
+					Name:        "Metadata",
+					Description: "Outgoing request metadata",
+					Type:        types.PinTypes.Object,
+					Optional:    true,
+				},
+				{
+					ID:          "descriptorSet",
+					Name:        "Descriptor Set",
+					Description: "Base64 encoded FileDescriptorSet (overrides the property)",
+					Type:        types.PinTypes.String,
+					Optional:    true,
+				},
+				{
+					ID:          "timeout",
+					Name:        "Timeout",
+					Description: "Call deadline in milliseconds",
+					Type:        types.PinTypes.Number,
+					Optional:    true,
+					Default:     float64(30000),
+				},
+				{
+					ID:          "tls",
+					Name:        "TLS",
+					Description: "Use TLS transport credentials",
+					Type:        types.PinTypes.Boolean,
+					Optional:    true,
+					Default:     false,
+				},
+			},
+			Outputs: []types.Pin{
+				{
+					ID:          "then",
+					Name:        "Then",
+					Description: "Executed on successful call",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "catch",
+					Name:        "Catch",
+					Description: "Executed if an error occurs",
+					Type:        types.PinTypes.Execution,
+				},
+				{
+					ID:          "response",
+					Name:        "Response",
+					Description: "Response message as an object",
+					Type:        types.PinTypes.Object,
+				},
+				{
+					ID:          "headers",
+					Name:        "Headers",
+					Description: "Response header metadata",
+					Type:        types.PinTypes.Object,
+				},
+				{
+					ID:          "status",
+					Name:        "Status",
+					Description: "gRPC status code name",
+					Type:        types.PinTypes.String,
+				},
+				bperrors.ErrorPin(),
+			},
+			Properties: []types.Property{
+				{
+					Name:        "descriptorSet",
+					DisplayName: "Descriptor Set",
+					Description: "Uploaded FileDescriptorSet (protoc --include_imports --descriptor_set_out), base64 encoded",
+					Type:        types.PinTypes.String,
+				},
+				{
+					Name:         "insecureSkipVerify",
+					DisplayName:  "Skip TLS Verification",
+					Description:  "Do not verify the server certificate when TLS is enabled",
+					Type:         types.PinTypes.Boolean,
+					Value:        false,
+					DefaultValue: false,
+				},
+			},
+		},
+	}
+}
+
+// IsNondeterministic reports that the node calls a remote service
+func (n *GRPCCallNode) IsNondeterministic() bool {
+	return true
+}
+
+// Execute runs the node logic
+func (n *GRPCCallNode) Execute(ctx node.ExecutionContext) error {
+	logger := ctx.Logger()
+	logger.Debug("Executing gRPC Call node", nil)
+
+	debugData := make(map[string]interface{})
+
+	targetValue, targetExists := ctx.GetInputValue("target")
+	methodValue, methodExists := ctx.GetInputValue("method")
+	requestValue, requestExists := ctx.GetInputValue("request")
+	metadataValue, metadataExists := ctx.GetInputValue("metadata")
+	descriptorValue, descriptorExists := ctx.GetInputValue("descriptorSet")
+	timeoutValue, timeoutExists := ctx.GetInputValue("timeout")
+	tlsValue, tlsExists := ctx.GetInputValue("tls")
+
+	debugData["inputs"] = map[string]interface{}{
+		"target":        targetExists,
+		"method":        methodExists,
+		"request":       requestExists,
+		"metadata":      metadataExists,
+		"descriptorSet": descriptorExists,
+	}
+
+	if !targetExists || !methodExists {
+		return n.fail(ctx, debugData, "missing_input", "Error: Missing target or method",
+			bperrors.MissingInput("target and method"))
+	}
+
+	target, err := targetValue.AsString()
+	if err != nil || target == "" {
+		return n.fail(ctx, debugData, "invalid_target", "Error: Invalid target",
+			bperrors.InvalidInput("target", errors.New("target must be a non-empty string")))
+	}
+
+	method, err := methodValue.AsString()
+	if err != nil || method == "" {
+		return n.fail(ctx, debugData, "invalid_method", "Error: Invalid method",
+			bperrors.InvalidInput("method", errors.New("method must be a non-empty string")))
+	}
+
+	// The descriptor set pin takes precedence over the uploaded property
+	var descriptorSet string
+	var skipVerify bool
+	for _, prop := range n.GetProperties() {
+		switch prop.Name {
+		case "descriptorSet":
+			if s, ok := prop.Value.(string); ok {
+				descriptorSet = s
+			}
+		case "insecureSkipVerify":
+			if b, ok := prop.Value.(bool); ok {
+				skipVerify = b
+			}
+		}
+	}
+	if descriptorExists {
+		if s, err := descriptorValue.AsString(); err == nil && s != "" {
+			descriptorSet = s
+		}
+	}
+	if descriptorSet == "" {
+		return n.fail(ctx, debugData, "missing_descriptor", "Error: Missing descriptor set",
+			bperrors.MissingInput("descriptorSet"))
+	}
+
+	files, err := loadDescriptorSet(descriptorSet)
+	if err != nil {
+		return n.fail(ctx, debugData, "descriptor_load", "Error: Invalid descriptor set",
+			bperrors.Wrap(err, bperrors.ErrorTypeValidation, bperrors.ErrInvalidPropertyValue, err.Error(), bperrors.SeverityMedium))
+	}
+
+	methodDesc, fullMethod, err := resolveGRPCMethod(files, method)
+	if err != nil {
+		return n.fail(ctx, debugData, "method_resolution", "Error: Method not found", bperrors.InvalidInput("method", err))
+	}
+
+	// Map the request object onto the dynamic request message
+	request := dynamicpb.NewMessage(methodDesc.Input())
+	if requestExists && requestValue.RawValue != nil {
+		requestObj, err := requestValue.AsObject()
+		if err != nil {
+			return n.fail(ctx, debugData, "invalid_request", "Error: Invalid request", bperrors.InvalidInput("request", err))
+		}
+
+		requestJSON, err := json.Marshal(requestObj)
+		if err != nil {
+			return n.fail(ctx, debugData, "request_marshal", "Error: Invalid request", bperrors.InvalidInput("request", err))
+		}
+
+		if err := protojson.Unmarshal(requestJSON, request); err != nil {
+			return n.fail(ctx, debugData, "request_mapping", "Error: Request does not match message",
+				bperrors.InvalidInput("request", fmt.Errorf("failed to map request to %s: %w", methodDesc.Input().FullName(), err)))
+		}
+	}
+
+	timeout := 30 * time.Second
+	if timeoutExists {
+		if ms, err := timeoutValue.AsNumber(); err == nil && ms > 0 {
+			timeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	useTLS := false
+	if tlsExists {
+		if b, err := tlsValue.AsBoolean(); err == nil {
+			useTLS = b
+		}
+	}
+
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: skipVerify})
+	}
+
+	debugData["request"] = map[string]interface{}{
+		"target":  target,
+		"method":  fullMethod,
+		"timeout": timeout.String(),
+		"tls":     useTLS,
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return n.fail(ctx, debugData, "connection", "Error: Failed to create connection",
+			bperrors.RequestFailed("failed to create connection", err))
+	}
+	defer conn.Close()
+
+	callCtx, cancel := context.WithTimeout(ctx.Context(), timeout)
+	defer cancel()
+
+	if metadataExists {
+		if md, err := metadataValue.AsObject(); err == nil {
+			pairs := metadata.MD{}
+			for key, value := range md {
+				pairs.Append(key, fmt.Sprintf("%v", value))
+			}
+			callCtx = metadata.NewOutgoingContext(callCtx, pairs)
+		}
+	}
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "Making gRPC Call",
+		Value:       debugData,
+		Timestamp:   time.Now(),
+	})
+
+	logger.Info("Invoking gRPC method", map[string]interface{}{
+		"target": target,
+		"method": fullMethod,
+	})
+
+	// Calls to a target that keeps failing are short-circuited during the cool-down
+	nodeType := n.GetMetadata().TypeID
+	if err := circuit.Allow(nodeType, target); err != nil {
+		return n.fail(ctx, debugData, "circuit_open", "Error: Circuit open", err)
+	}
+
+	response := dynamicpb.NewMessage(methodDesc.Output())
+	var header metadata.MD
+	startTime := time.Now()
+	err = conn.Invoke(callCtx, fullMethod, request, response, grpc.Header(&header))
+	circuit.Record(nodeType, target, err != nil && callError(err).IsRetryable())
+	debugData["timing"] = map[string]interface{}{
+		"start":    startTime.Format(time.RFC3339),
+		"duration": time.Since(startTime).String(),
+	}
+
+	if err != nil {
+		ctx.SetOutputValue("status", types.NewValue(types.PinTypes.String, status.Code(err).String()))
+		return n.fail(ctx, debugData, "call_failed", "Error: gRPC call failed", callError(err))
+	}
+
+	// Account the transferred message bytes to the execution
+	usage.Record(ctx.GetExecutionID(), usage.MetricHTTPBytes, int64(proto.Size(request)+proto.Size(response)))
+
+	responseJSON, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return n.fail(ctx, debugData, "response_mapping", "Error: Failed to read response",
+			bperrors.InvalidResponse("failed to read response", err))
+	}
+
+	var responseObj map[string]interface{}
+	if err := json.Unmarshal(responseJSON, &responseObj); err != nil {
+		return n.fail(ctx, debugData, "response_mapping", "Error: Failed to read response",
+			bperrors.InvalidResponse("failed to read response", err))
+	}
+
+	headers := make(map[string]interface{}, len(header))
+	for key, values := range header {
+		if len(values) == 1 {
+			headers[key] = values[0]
+		} else {
+			headers[key] = values
+		}
+	}
+
+	debugData["response"] = responseObj
+
+	ctx.SetOutputValue("response", types.NewValue(types.PinTypes.Object, responseObj))
+	ctx.SetOutputValue("headers", types.NewValue(types.PinTypes.Object, headers))
+	ctx.SetOutputValue("status", types.NewValue(types.PinTypes.String, "OK"))
+
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: "gRPC Call Completed",
+		Value:       debugData,
+		Timestamp:   time.Now(),
+	})
+
+	logger.Info("gRPC call completed", map[string]interface{}{"method": fullMethod})
+	return ctx.ActivateOutputFlow("then")
+}
+
+// fail records the error for debugging, sets the error output and activates the catch flow
+func (n *GRPCCallNode) fail(ctx node.ExecutionContext, debugData map[string]interface{}, errType, description string, err error) error {
+	ctx.Logger().Error("gRPC call failed", map[string]interface{}{"error": err.Error()})
+
+	debugData["error"] = map[string]string{
+		"type":    errType,
+		"message": err.Error(),
+	}
+	ctx.RecordDebugInfo(types.DebugInfo{
+		NodeID:      ctx.GetNodeID(),
+		Description: description,
+		Value:       debugData,
+		Timestamp:   time.Now(),
+	})
+
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow("catch")
+}
+
+// callError classifies a failed call by its gRPC status code
+func callError(err error) *bperrors.BlueprintError {
+	code := status.Code(err)
+	bpErr := bperrors.RequestFailed("gRPC call failed", err)
+	switch code {
+	case codes.DeadlineExceeded:
+		bpErr.Code = bperrors.ErrRequestTimeout
+		bpErr.WithRecoveryOptions(bperrors.RecoveryRetry)
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		bpErr.WithRecoveryOptions(bperrors.RecoveryRetry)
+	}
+	return bpErr.WithDetails(map[string]interface{}{"status": code.String()})
+}
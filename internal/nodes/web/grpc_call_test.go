@@ -0,0 +1,153 @@
+package web_test
+
+import (
+	"encoding/base64"
+	"net"
+	"testing"
+	"webblueprint/internal/nodes/web"
+	"webblueprint/internal/test"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// echoDescriptorSet builds a descriptor set for:
+//
+//	package echo;
+//	message EchoRequest { string text = 1; }
+//	message EchoReply { string text = 1; int32 length = 2; }
+//	service Echo { rpc Say(EchoRequest) returns (EchoReply); }
+func echoDescriptorSet(t *testing.T) (*descriptorpb.FileDescriptorSet, protoreflect.FileDescriptor) {
+	t.Helper()
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("echo.proto"),
+		Package: proto.String("echo"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("EchoRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("text"), JsonName: proto.String("text"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+			{
+				Name: proto.String("EchoReply"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("text"), JsonName: proto.String("text"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("length"), JsonName: proto.String("length"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Echo"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{Name: proto.String("Say"), InputType: proto.String(".echo.EchoRequest"), OutputType: proto.String(".echo.EchoReply")},
+				},
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatalf("failed to build file descriptor: %v", err)
+	}
+
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}, fd
+}
+
+// startEchoServer serves echo.Echo/Say on a local port using dynamic messages
+func startEchoServer(t *testing.T, fd protoreflect.FileDescriptor) string {
+	t.Helper()
+
+	reqDesc := fd.Messages().ByName("EchoRequest")
+	replyDesc := fd.Messages().ByName("EchoReply")
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		req := dynamicpb.NewMessage(reqDesc)
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		text := req.Get(reqDesc.Fields().ByName("text")).String()
+
+		reply := dynamicpb.NewMessage(replyDesc)
+		reply.Set(replyDesc.Fields().ByName("text"), protoreflect.ValueOfString(text))
+		reply.Set(replyDesc.Fields().ByName("length"), protoreflect.ValueOfInt32(int32(len(text))))
+		return stream.SendMsg(reply)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	server := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestGRPCCallNode(t *testing.T) {
+	set, fd := echoDescriptorSet(t)
+	raw, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal descriptor set: %v", err)
+	}
+	descriptorSet := base64.StdEncoding.EncodeToString(raw)
+	target := startEchoServer(t, fd)
+
+	testCases := []test.NodeTestCase{
+		{
+			Name: "missing target",
+			Inputs: map[string]interface{}{
+				"method": "echo.Echo/Say",
+			},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name: "missing descriptor set",
+			Inputs: map[string]interface{}{
+				"target": target,
+				"method": "echo.Echo/Say",
+			},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name: "unknown method",
+			Inputs: map[string]interface{}{
+				"target":        target,
+				"method":        "echo.Echo/Shout",
+				"descriptorSet": descriptorSet,
+			},
+			ExpectedFlow: "catch",
+		},
+		{
+			Name: "unary call",
+			Inputs: map[string]interface{}{
+				"target":        target,
+				"method":        "/echo.Echo/Say",
+				"descriptorSet": descriptorSet,
+				"request":       map[string]interface{}{"text": "hello"},
+				"timeout":       5000,
+			},
+			ExpectedOutputs: map[string]interface{}{
+				"response": map[string]interface{}{"text": "hello", "length": float64(5)},
+				"status":   "OK",
+			},
+			ExpectedFlow: "then",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			node := web.NewGRPCCallNode()
+			test.ExecuteNodeTestCase(t, node, tc)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// descriptorCacheSize bounds how many parsed descriptor sets are kept. Descriptor
+// sets are uploaded by blueprints, so the cache must not grow with every new one.
+const descriptorCacheSize = 64
+
+// descriptorCache keeps the most recently used parsed descriptor sets keyed by the
+// hash of their bytes, so repeated calls with the same uploaded .proto set are not
+// re-parsed
+var descriptorCache = newDescriptorLRU(descriptorCacheSize)
+
+type descriptorEntry struct {
+	key   string
+	files *protoregistry.Files
+}
+
+// descriptorLRU is a fixed size cache of file registries evicting the least
+// recently used one
+type descriptorLRU struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List // Front is the most recently used
+	entries  map[string]*list.Element
+}
+
+func newDescriptorLRU(capacity int) *descriptorLRU {
+	return &descriptorLRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *descriptorLRU) get(key string) (*protoregistry.Files, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*descriptorEntry).files, true
+}
+
+func (c *descriptorLRU) add(key string, files *protoregistry.Files) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*descriptorEntry).files = files
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&descriptorEntry{key: key, files: files})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*descriptorEntry).key)
+	}
+}
+
+func (c *descriptorLRU) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// loadDescriptorSet decodes a base64 encoded FileDescriptorSet (as produced by
+// `protoc --include_imports --descriptor_set_out`) into a file registry
+func loadDescriptorSet(encoded string) (*protoregistry.Files, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("descriptor set is not valid base64: %w", err)
+	}
+
+	sum := sha256.Sum256(raw)
+	key := hex.EncodeToString(sum[:])
+
+	if files, ok := descriptorCache.get(key); ok {
+		return files, nil
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build descriptors: %w", err)
+	}
+
+	descriptorCache.add(key, files)
+	return files, nil
+}
+
+// resolveGRPCMethod finds a unary method descriptor in the registry. The method
+// may be given as "/pkg.Service/Method", "pkg.Service/Method" or
+// "pkg.Service.Method". The canonical "/pkg.Service/Method" path is returned
+// alongside the descriptor.
+func resolveGRPCMethod(files *protoregistry.Files, method string) (protoreflect.MethodDescriptor, string, error) {
+	name := strings.TrimPrefix(strings.TrimSpace(method), "/")
+
+	var serviceName, methodName string
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		serviceName, methodName = name[:idx], name[idx+1:]
+	} else if idx := strings.LastIndex(name, "."); idx >= 0 {
+		serviceName, methodName = name[:idx], name[idx+1:]
+	}
+	if serviceName == "" || methodName == "" {
+		return nil, "", fmt.Errorf("invalid method %q: expected pkg.Service/Method", method)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, "", fmt.Errorf("service %q not found in descriptor set", serviceName)
+	}
+
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, "", fmt.Errorf("%q is not a service", serviceName)
+	}
+
+	md := service.Methods().ByName(protoreflect.Name(methodName))
+	if md == nil {
+		return nil, "", fmt.Errorf("method %q not found in service %q", methodName, serviceName)
+	}
+
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, "", fmt.Errorf("method %q is streaming; only unary methods are supported", methodName)
+	}
+
+	return md, fmt.Sprintf("/%s/%s", service.FullName(), md.Name()), nil
+}
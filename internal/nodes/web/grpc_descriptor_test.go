@@ -0,0 +1,73 @@
+package web
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestDescriptorLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newDescriptorLRU(2)
+	a, b, c := new(protoregistry.Files), new(protoregistry.Files), new(protoregistry.Files)
+
+	cache.add("a", a)
+	cache.add("b", b)
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.add("c", c)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected b, the least recently used entry, to be evicted")
+	}
+	if files, ok := cache.get("a"); !ok || files != a {
+		t.Error("expected a to stay cached after it was used")
+	}
+	if files, ok := cache.get("c"); !ok || files != c {
+		t.Error("expected c to be cached")
+	}
+	if cache.len() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.len())
+	}
+}
+
+func TestLoadDescriptorSetBoundsCache(t *testing.T) {
+	previous := descriptorCache
+	descriptorCache = newDescriptorLRU(3)
+	defer func() { descriptorCache = previous }()
+
+	encoded := func(i int) string {
+		set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String(fmt.Sprintf("file%d.proto", i)),
+			Package: proto.String(fmt.Sprintf("pkg%d", i)),
+			Syntax:  proto.String("proto3"),
+		}}}
+		raw, err := proto.Marshal(set)
+		if err != nil {
+			t.Fatalf("failed to marshal descriptor set: %v", err)
+		}
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	first, err := loadDescriptorSet(encoded(0))
+	if err != nil {
+		t.Fatalf("failed to load descriptor set: %v", err)
+	}
+	again, err := loadDescriptorSet(encoded(0))
+	if err != nil || again != first {
+		t.Fatalf("expected the cached registry for the same descriptor set, got %p and %p (%v)", first, again, err)
+	}
+
+	for i := 1; i <= 10; i++ {
+		if _, err := loadDescriptorSet(encoded(i)); err != nil {
+			t.Fatalf("failed to load descriptor set %d: %v", i, err)
+		}
+	}
+	if size := descriptorCache.len(); size != 3 {
+		t.Errorf("expected the cache to stay at 3 entries, got %d", size)
+	}
+}
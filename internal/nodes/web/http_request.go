@@ -3,12 +3,14 @@ package web
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/circuit"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
+	"webblueprint/internal/usage"
 )
 
 // HTTPRequestNode implements an HTTP request node
@@ -62,6 +64,14 @@ func NewHTTPRequestNode() node.Node {
 					Type:        types.PinTypes.Any,
 					Optional:    true,
 				},
+				{
+					ID:          "streamResponse",
+					Name:        "Stream Response",
+					Description: "Return the response body as a stream instead of reading it into memory",
+					Type:        types.PinTypes.Boolean,
+					Optional:    true,
+					Default:     false,
+				},
 			},
 			Outputs: []types.Pin{
 				{
@@ -88,17 +98,35 @@ func NewHTTPRequestNode() node.Node {
 					Description: "Raw response data",
 					Type:        types.PinTypes.String,
 				},
+				{
+					ID:          "rawBytes",
+					Name:        "Raw Bytes",
+					Description: "Response body as binary data",
+					Type:        types.PinTypes.Bytes,
+				},
+				{
+					ID:          "bodyStream",
+					Name:        "Body Stream",
+					Description: "Response body stream (when Stream Response is enabled)",
+					Type:        types.PinTypes.Stream,
+				},
 				{
 					ID:          "status",
 					Name:        "Status Code",
 					Description: "HTTP status code",
 					Type:        types.PinTypes.Number,
 				},
+				bperrors.ErrorPin(),
 			},
 		},
 	}
 }
 
+// IsNondeterministic reports that responses depend on the remote server
+func (n *HTTPRequestNode) IsNondeterministic() bool {
+	return true
+}
+
 // Execute runs the node logic
 func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 	logger := ctx.Logger()
@@ -112,6 +140,12 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 	methodValue, methodExists := ctx.GetInputValue("method")
 	headersValue, headersExist := ctx.GetInputValue("headers")
 	bodyValue, bodyExists := ctx.GetInputValue("body")
+	streamValue, streamExists := ctx.GetInputValue("streamResponse")
+
+	streamResponse := false
+	if streamExists {
+		streamResponse, _ = streamValue.AsBoolean()
+	}
 
 	// Record input values for debugging
 	debugData["inputs"] = map[string]interface{}{
@@ -123,7 +157,7 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 
 	// Validate URL
 	if !urlExists {
-		err := fmt.Errorf("missing required input: url")
+		err := bperrors.MissingInput("url")
 		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
 
 		debugData["error"] = map[string]string{
@@ -137,7 +171,8 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
-		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
+		bperrors.SetErrorOutput(ctx, err)
+		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Message))
 		return ctx.ActivateOutputFlow("catch")
 	}
 
@@ -156,6 +191,7 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
+		bperrors.SetErrorOutput(ctx, bperrors.InvalidInput("url", err))
 		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
 		return ctx.ActivateOutputFlow("catch")
 	}
@@ -187,12 +223,19 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 	// Prepare request
 	var req *http.Request
 
-	if bodyExists && bodyValue.RawValue != nil {
+	if stream, ok := bodyValue.RawValue.(*types.Stream); bodyExists && ok {
+		// Streamed bodies are piped without being read into memory
+		debugData["requestBody"] = stream
+		req, err = http.NewRequestWithContext(ctx.Context(), method, url, stream)
+	} else if bodyExists && bodyValue.RawValue != nil {
 		// Convert body to JSON if it's not already a string
 		var bodyContent []byte
 
 		if bodyStr, ok := bodyValue.RawValue.(string); ok {
 			bodyContent = []byte(bodyStr)
+		} else if bodyBytes, ok := bodyValue.RawValue.([]byte); ok {
+			// Binary bodies are sent as is
+			bodyContent = bodyBytes
 		} else {
 			// Try to marshal as JSON
 			jsonData, err := json.Marshal(bodyValue.RawValue)
@@ -211,6 +254,7 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 					Timestamp:   time.Now(),
 				})
 
+				bperrors.SetErrorOutput(ctx, bperrors.InvalidInput("body", err))
 				ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
 				return ctx.ActivateOutputFlow("catch")
 			}
@@ -218,9 +262,9 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 		}
 
 		debugData["requestBody"] = string(bodyContent)
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(bodyContent))
+		req, err = http.NewRequestWithContext(ctx.Context(), method, url, bytes.NewBuffer(bodyContent))
 	} else {
-		req, err = http.NewRequest(method, url, nil)
+		req, err = http.NewRequestWithContext(ctx.Context(), method, url, nil)
 	}
 
 	if err != nil {
@@ -238,6 +282,13 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
+		bperrors.SetErrorOutput(ctx, bperrors.Wrap(
+			err,
+			bperrors.ErrorTypeValidation,
+			bperrors.ErrInvalidInputValue,
+			"failed to create HTTP request: "+err.Error(),
+			bperrors.SeverityMedium,
+		))
 		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
 		return ctx.ActivateOutputFlow("catch")
 	}
@@ -275,11 +326,37 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 		Timestamp:   time.Now(),
 	})
 
+	// Calls to a host that keeps failing are short-circuited during the cool-down
+	nodeType := n.GetMetadata().TypeID
+	if err := circuit.Allow(nodeType, req.URL.Host); err != nil {
+		logger.Warn("HTTP request short-circuited", map[string]interface{}{"host": req.URL.Host})
+
+		debugData["error"] = map[string]string{
+			"type":    "circuit_open",
+			"message": err.Error(),
+		}
+		ctx.RecordDebugInfo(types.DebugInfo{
+			NodeID:      ctx.GetNodeID(),
+			Description: "Error: Circuit open",
+			Value:       debugData,
+			Timestamp:   time.Now(),
+		})
+
+		bperrors.SetErrorOutput(ctx, err)
+		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
+		ctx.SetOutputValue("status", types.NewValue(types.PinTypes.Number, float64(0)))
+		return ctx.ActivateOutputFlow("catch")
+	}
+
 	// Execute the request
 	logger.Debug("Sending HTTP request...", map[string]interface{}{"url": url, "method": method})
 	startTime := time.Now()
 	resp, err := client.Do(req)
 	requestDuration := time.Since(startTime)
+	// Requests stopped with the execution say nothing about the health of the host
+	if err == nil || ctx.Context().Err() == nil {
+		circuit.Record(nodeType, req.URL.Host, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	}
 	logger.Debug("HTTP request finished", map[string]interface{}{"duration": requestDuration.String(), "error": err})
 
 	// Add timing information
@@ -303,10 +380,32 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
+		bperrors.SetErrorOutput(ctx, bperrors.RequestFailed("HTTP request failed", err))
 		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
 		ctx.SetOutputValue("status", types.NewValue(types.PinTypes.Number, float64(0)))
 		return ctx.ActivateOutputFlow("catch")
 	}
+
+	if streamResponse {
+		// The engine closes the stream when the execution finishes
+		debugData["responseFormat"] = "stream"
+		debugData["response"] = map[string]interface{}{
+			"statusCode": resp.StatusCode,
+			"headers":    resp.Header,
+		}
+
+		ctx.SetOutputValue("bodyStream", types.NewValue(types.PinTypes.Stream, types.NewStream(resp.Body)))
+		ctx.SetOutputValue("status", types.NewValue(types.PinTypes.Number, float64(resp.StatusCode)))
+
+		ctx.RecordDebugInfo(types.DebugInfo{
+			NodeID:      ctx.GetNodeID(),
+			Description: "HTTP Response Stream Opened",
+			Value:       debugData,
+			Timestamp:   time.Now(),
+		})
+
+		return ctx.ActivateOutputFlow("then")
+	}
 	defer resp.Body.Close()
 
 	// Read the response body
@@ -326,11 +425,19 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 			Timestamp:   time.Now(),
 		})
 
+		bperrors.SetErrorOutput(ctx, bperrors.InvalidResponse("failed to read response body", err))
 		ctx.SetOutputValue("response", types.NewValue(types.PinTypes.String, err.Error()))
 		ctx.SetOutputValue("status", types.NewValue(types.PinTypes.Number, float64(resp.StatusCode)))
 		return ctx.ActivateOutputFlow("catch")
 	}
 
+	// Account the transferred bytes to the execution
+	requestBytes := int64(0)
+	if req.ContentLength > 0 {
+		requestBytes = req.ContentLength
+	}
+	usage.Record(ctx.GetExecutionID(), usage.MetricHTTPBytes, requestBytes+int64(len(responseBody)))
+
 	// Try to parse as JSON first
 	var responseData interface{}
 	if err := json.Unmarshal(responseBody, &responseData); err != nil {
@@ -350,6 +457,11 @@ func (n *HTTPRequestNode) Execute(ctx node.ExecutionContext) error {
 
 	// Set output values
 	ctx.SetOutputValue("raw", types.NewValue(types.PinTypes.String, string(responseBody)))
+	if err := types.PinTypes.Bytes.Validator(responseBody); err != nil {
+		logger.Warn("Response body too large for bytes output", map[string]interface{}{"error": err.Error()})
+	} else {
+		ctx.SetOutputValue("rawBytes", types.NewValue(types.PinTypes.Bytes, responseBody))
+	}
 	ctx.SetOutputValue("response", types.NewValue(types.PinTypes.Any, responseData))
 	ctx.SetOutputValue("status", types.NewValue(types.PinTypes.Number, float64(resp.StatusCode)))
 
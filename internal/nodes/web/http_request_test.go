@@ -1,9 +1,16 @@
 package web_test
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 	"webblueprint/internal/nodes/web"
 	"webblueprint/internal/test"
+	"webblueprint/internal/test/mocks"
+	"webblueprint/internal/types"
 )
 
 func TestHTTPRequestNode(t *testing.T) {
@@ -35,3 +42,91 @@ func TestHTTPRequestNode(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPRequestNodeStreamResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("streamed body"))
+	}))
+	defer server.Close()
+
+	ctx := mocks.NewMockExecutionContext("test-node", "http-request", mocks.NewMockLogger())
+	ctx.SetInputValue("url", types.NewValue(types.PinTypes.String, server.URL))
+	ctx.SetInputValue("streamResponse", types.NewValue(types.PinTypes.Boolean, true))
+
+	if err := web.NewHTTPRequestNode().Execute(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.GetActivatedFlow() != "then" {
+		t.Fatalf("expected then flow, got %s", ctx.GetActivatedFlow())
+	}
+
+	value, exists := ctx.GetOutputValue("bodyStream")
+	if !exists {
+		t.Fatal("expected bodyStream output")
+	}
+	stream, err := value.AsStream()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	body, err := io.ReadAll(stream)
+	if err != nil || string(body) != "streamed body" {
+		t.Errorf("expected streamed body, got %q (%v)", body, err)
+	}
+	if _, exists := ctx.GetOutputValue("raw"); exists {
+		t.Error("expected raw output not to be set when streaming")
+	}
+}
+
+func TestHTTPRequestNodeErrorPin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	ctx := mocks.NewMockExecutionContext("fetch", "http-request", mocks.NewMockLogger())
+	ctx.SetInputValue("url", types.NewValue(types.PinTypes.String, url))
+
+	if err := web.NewHTTPRequestNode().Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ctx.GetActivatedFlow() != "catch" {
+		t.Fatalf("Expected catch flow, got %q", ctx.GetActivatedFlow())
+	}
+
+	errorValue, exists := ctx.GetOutputValue("error")
+	if !exists {
+		t.Fatal("Expected the error pin to be set")
+	}
+	info, ok := errorValue.RawValue.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected structured error info, got %T", errorValue.RawValue)
+	}
+	if info["code"] != "N001" || info["retryable"] != true || info["nodeId"] != "fetch" {
+		t.Errorf("Unexpected error info: %+v", info)
+	}
+}
+
+func TestHTTPRequestNodeStopsWithExecution(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	execution, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ctx := mocks.NewMockExecutionContext("fetch", "http-request", mocks.NewMockLogger())
+	ctx.SetContext(execution)
+	ctx.SetInputValue("url", types.NewValue(types.PinTypes.String, server.URL))
+
+	start := time.Now()
+	if err := web.NewHTTPRequestNode().Execute(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the request to stop with the execution, took %v", elapsed)
+	}
+	if ctx.GetActivatedFlow() != "catch" {
+		t.Fatalf("Expected catch flow, got %q", ctx.GetActivatedFlow())
+	}
+}
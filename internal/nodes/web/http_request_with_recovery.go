@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 	"webblueprint/internal/bperrors"
+	"webblueprint/internal/circuit"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -182,6 +183,11 @@ func (n *HTTPRequestWithRecoveryNode) SetProperty(name string, value interface{}
 	}
 }
 
+// IsNondeterministic reports that the node performs HTTP requests
+func (n *HTTPRequestWithRecoveryNode) IsNondeterministic() bool {
+	return true
+}
+
 // Execute runs the node
 func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 	// Check if we have error-aware context
@@ -240,7 +246,7 @@ func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 		}
 	} else if !urlExists {
 		// Standard error handling without recovery if not error-aware
-		return fmt.Errorf("missing required URL input")
+		return bperrors.MissingInput("url")
 	}
 
 	// Get other inputs with fallbacks to properties
@@ -326,7 +332,7 @@ func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 		}
 	}
 
-	req, err := http.NewRequest(methodVal, urlVal, nil)
+	req, err := http.NewRequestWithContext(ctx.Context(), methodVal, urlVal, nil)
 	if err != nil {
 		// Handle request creation error
 		if isErrorAware {
@@ -338,10 +344,7 @@ func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 			)
 
 			// Set error output
-			ctx.SetOutputValue("error", types.NewValue(types.PinTypes.Object, map[string]interface{}{
-				"message": apiErr.Message,
-				"code":    string(apiErr.Code),
-			}))
+			bperrors.SetErrorOutput(ctx, apiErr)
 			ctx.ActivateOutputFlow("catch")
 			return apiErr
 		}
@@ -384,11 +387,23 @@ func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 		}
 	}
 
+	nodeType := n.GetMetadata().TypeID
 	retryCount := 0
 
 	for retryCount <= maxRetries {
+		// Calls to a host that keeps failing are short-circuited during the cool-down
+		if circuitErr := circuit.Allow(nodeType, req.URL.Host); circuitErr != nil {
+			bperrors.SetErrorOutput(ctx, circuitErr)
+			ctx.ActivateOutputFlow("catch")
+			return circuitErr
+		}
+
 		resp, err = client.Do(req)
-		if err == nil {
+		// Requests stopped with the execution say nothing about the health of the host
+		if err == nil || ctx.Context().Err() == nil {
+			circuit.Record(nodeType, req.URL.Host, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+		}
+		if err == nil || ctx.Context().Err() != nil {
 			break
 		}
 
@@ -419,12 +434,7 @@ func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 			)
 
 			// Set error output
-			ctx.SetOutputValue("error", types.NewValue(types.PinTypes.Object, map[string]interface{}{
-				"message": apiErr.Message,
-				"code":    string(apiErr.Code),
-				"details": apiErr.Details,
-				"retries": retryCount,
-			}))
+			bperrors.SetErrorOutput(ctx, apiErr.WithDetails(map[string]interface{}{"retries": retryCount}))
 			ctx.ActivateOutputFlow("catch")
 			return apiErr
 		}
@@ -445,10 +455,7 @@ func (n *HTTPRequestWithRecoveryNode) Execute(ctx node.ExecutionContext) error {
 				"Failed to read response body",
 				err,
 			)
-			ctx.SetOutputValue("error", types.NewValue(types.PinTypes.Object, map[string]interface{}{
-				"message": apiErr.Message,
-				"code":    string(apiErr.Code),
-			}))
+			bperrors.SetErrorOutput(ctx, apiErr)
 			ctx.ActivateOutputFlow("catch")
 			return apiErr
 		}
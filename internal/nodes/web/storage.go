@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+	"webblueprint/internal/bperrors"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -68,8 +69,8 @@ func NewStorageNode() node.Node {
 					Type:        types.PinTypes.Execution,
 				},
 				{
-					ID:          "error",
-					Name:        "Error",
+					ID:          "catch",
+					Name:        "Catch",
 					Description: "Executed if an error occurs",
 					Type:        types.PinTypes.Execution,
 				},
@@ -91,6 +92,7 @@ func NewStorageNode() node.Node {
 					Description: "Error message if operation fails",
 					Type:        types.PinTypes.String,
 				},
+				bperrors.ErrorPin(),
 			},
 		},
 	}
@@ -112,41 +114,28 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 
 	// Check required inputs
 	if !operationExists {
-		err := fmt.Errorf("missing required input: operation")
-		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-		return ctx.ActivateOutputFlow("error")
+		return n.fail(ctx, bperrors.MissingInput("operation"))
 	}
 
 	if !storageTypeExists {
-		err := fmt.Errorf("missing required input: storageType")
-		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-		return ctx.ActivateOutputFlow("error")
+		return n.fail(ctx, bperrors.MissingInput("storageType"))
 	}
 
 	// Parse operation
 	operation, err := operationValue.AsString()
 	if err != nil {
-		logger.Error("Invalid operation", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, "Invalid operation: "+err.Error()))
-		return ctx.ActivateOutputFlow("error")
+		return n.fail(ctx, bperrors.InvalidInput("operation", err))
 	}
 
 	// Parse storage type
 	storageType, err := storageTypeValue.AsString()
 	if err != nil {
-		logger.Error("Invalid storage type", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, "Invalid storage type: "+err.Error()))
-		return ctx.ActivateOutputFlow("error")
+		return n.fail(ctx, bperrors.InvalidInput("storageType", err))
 	}
 
 	// Validate storage type
 	if storageType != "local" && storageType != "session" {
-		err := fmt.Errorf("invalid storage type: %s (must be 'local' or 'session')", storageType)
-		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-		return ctx.ActivateOutputFlow("error")
+		return n.fail(ctx, bperrors.InvalidInput("storageType", fmt.Errorf("%s (must be 'local' or 'session')", storageType)))
 	}
 
 	// Record input values for debugging
@@ -170,17 +159,12 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 	switch operation {
 	case "get":
 		if !keyExists {
-			err := fmt.Errorf("missing required input: key (for get operation)")
-			logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.MissingInput("key"))
 		}
 
 		key, err := keyValue.AsString()
 		if err != nil {
-			logger.Error("Invalid key", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, "Invalid key: "+err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.InvalidInput("key", err))
 		}
 
 		storageOperation["key"] = key
@@ -264,24 +248,16 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 
 	case "set":
 		if !keyExists {
-			err := fmt.Errorf("missing required input: key (for set operation)")
-			logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.MissingInput("key"))
 		}
 
 		if !valueExists {
-			err := fmt.Errorf("missing required input: value (for set operation)")
-			logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.MissingInput("value"))
 		}
 
 		key, err := keyValue.AsString()
 		if err != nil {
-			logger.Error("Invalid key", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, "Invalid key: "+err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.InvalidInput("key", err))
 		}
 
 		// For objects and non-primitive values, convert to JSON string
@@ -296,9 +272,7 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 			// In real browser storage, complex values need to be JSON stringified
 			jsonBytes, err := json.Marshal(valueInput.RawValue)
 			if err != nil {
-				logger.Error("Failed to marshal value to JSON", map[string]interface{}{"error": err.Error()})
-				ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, "Failed to marshal value to JSON: "+err.Error()))
-				return ctx.ActivateOutputFlow("error")
+				return n.fail(ctx, bperrors.InvalidInput("value", err))
 			}
 			valueForStorage = string(jsonBytes)
 		}
@@ -324,17 +298,12 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 
 	case "remove":
 		if !keyExists {
-			err := fmt.Errorf("missing required input: key (for remove operation)")
-			logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.MissingInput("key"))
 		}
 
 		key, err := keyValue.AsString()
 		if err != nil {
-			logger.Error("Invalid key", map[string]interface{}{"error": err.Error()})
-			ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, "Invalid key: "+err.Error()))
-			return ctx.ActivateOutputFlow("error")
+			return n.fail(ctx, bperrors.InvalidInput("key", err))
 		}
 
 		storageOperation["key"] = key
@@ -361,10 +330,7 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 		})
 
 	default:
-		err := fmt.Errorf("invalid operation: %s (must be 'get', 'set', 'remove', or 'clear')", operation)
-		logger.Error("Execution failed", map[string]interface{}{"error": err.Error()})
-		ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Error()))
-		return ctx.ActivateOutputFlow("error")
+		return n.fail(ctx, bperrors.InvalidInput("operation", fmt.Errorf("%s (must be 'get', 'set', 'remove', or 'clear')", operation)))
 	}
 
 	// Result includes the operation that would be performed on the client
@@ -381,3 +347,11 @@ func (n *StorageNode) Execute(ctx node.ExecutionContext) error {
 	// Continue execution
 	return ctx.ActivateOutputFlow("then")
 }
+
+// fail sets the error outputs and activates the catch flow
+func (n *StorageNode) fail(ctx node.ExecutionContext, err *bperrors.BlueprintError) error {
+	ctx.Logger().Error("Execution failed", map[string]interface{}{"error": err.Error()})
+	ctx.SetOutputValue("errorMessage", types.NewValue(types.PinTypes.String, err.Message))
+	bperrors.SetErrorOutput(ctx, err)
+	return ctx.ActivateOutputFlow("catch")
+}
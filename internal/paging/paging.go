@@ -0,0 +1,206 @@
+// Package paging implements the pagination, sorting and filtering conventions of the list
+// endpoints. Pages are addressed by opaque cursors holding the sort key and ID of the last
+// item of the previous page, so they stay stable while items are added, and lists are
+// sorted by one field, ascending or descending, with the ID breaking ties.
+package paging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLimit is the size of a page when the request gives none
+const DefaultLimit = 100
+
+// MaxLimit is the largest page a request may ask for
+const MaxLimit = 1000
+
+// timeKeyLayout formats the times of sort keys with a fixed width, so they sort as strings
+const timeKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Sort is the order of a list: a field and a direction
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String returns the sort as it's written in queries, e.g. -updated_at
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Cursor marks the position of a page: the sort key and ID of the last item before it
+type Cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"id"`
+}
+
+// Encode returns the cursor as an opaque token
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a token returned by Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("cursor is malformed")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, errors.New("cursor is malformed")
+	}
+	return &cursor, nil
+}
+
+// Request is a page asked for by a list request
+type Request struct {
+	Limit int
+	Sort  Sort
+	After *Cursor // Position of the page, nil for the first one
+}
+
+// Parse reads the limit, sort and cursor query parameters of a list request. The sort must
+// be one of the sortable fields, prefixed with "-" for descending order, and a cursor must
+// come from a page of the same sort.
+func Parse(query url.Values, sortable []string, defaultSort Sort) (Request, error) {
+	request := Request{Limit: DefaultLimit, Sort: defaultSort}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return request, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+		}
+		request.Limit = limit
+	}
+
+	if value := query.Get("sort"); value != "" {
+		field := strings.TrimPrefix(value, "-")
+		if !contains(sortable, field) {
+			return request, fmt.Errorf("sort must be one of %s, optionally prefixed with -", strings.Join(sortable, ", "))
+		}
+		request.Sort = Sort{Field: field, Desc: strings.HasPrefix(value, "-")}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			return request, err
+		}
+		if cursor.Sort != request.Sort.String() {
+			return request, fmt.Errorf("cursor belongs to a list sorted by %s, not %s", cursor.Sort, request.Sort)
+		}
+		request.After = cursor
+	}
+	return request, nil
+}
+
+// Filters reads a filter parameter that may be repeated or comma-separated, such as
+// ?tag=a,b&tag=c
+func Filters(query url.Values, name string) []string {
+	var values []string
+	for _, value := range query[name] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
+
+// Bool reads a boolean filter parameter, nil if it's absent
+func Bool(query url.Values, name string) (*bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", name)
+	}
+	return &parsed, nil
+}
+
+// TimeKey returns a time as a sort key
+func TimeKey(t time.Time) string {
+	return t.UTC().Format(timeKeyLayout)
+}
+
+// ParseTimeKey parses a sort key returned by TimeKey
+func ParseTimeKey(key string) (time.Time, error) {
+	return time.Parse(timeKeyLayout, key)
+}
+
+// Page is a page of a list
+type Page[T any] struct {
+	Items []T
+	Total int     // Number of items in the whole list
+	Next  *Cursor // Position of the next page, nil on the last one
+}
+
+// NextCursor returns the cursor of the page after items, if the query found more items than
+// the limit; key returns the sort key and ID of an item
+func NextCursor[T any](items []T, request Request, more bool, key func(T) (string, string)) *Cursor {
+	if !more || len(items) == 0 {
+		return nil
+	}
+	sortKey, id := key(items[len(items)-1])
+	return &Cursor{Sort: request.Sort.String(), Key: sortKey, ID: id}
+}
+
+// Slice pages a list held in memory. The items are sorted by the sort key and ID key
+// returns for them, and the page after the cursor of the request is taken.
+func Slice[T any](items []T, request Request, key func(T) (string, string)) Page[T] {
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	less := func(a, b T) bool {
+		keyA, idA := key(a)
+		keyB, idB := key(b)
+		if keyA != keyB {
+			return (keyA < keyB) != request.Sort.Desc
+		}
+		return (idA < idB) != request.Sort.Desc
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	start := 0
+	if request.After != nil {
+		start = sort.Search(len(sorted), func(i int) bool {
+			sortKey, id := key(sorted[i])
+			if sortKey != request.After.Key {
+				return (sortKey > request.After.Key) != request.Sort.Desc
+			}
+			return id != request.After.ID && (id > request.After.ID) != request.Sort.Desc
+		})
+	}
+
+	end := start + request.Limit
+	more := end < len(sorted)
+	if !more {
+		end = len(sorted)
+	}
+	page := sorted[start:end]
+	return Page[T]{Items: page, Total: len(items), Next: NextCursor(page, request, more, key)}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package paging
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	id   string
+	name string
+}
+
+func itemKey(i item) (string, string) {
+	return i.name, i.id
+}
+
+func TestSliceWalksEveryPage(t *testing.T) {
+	items := []item{{"5", "e"}, {"1", "a"}, {"3", "c"}, {"2", "c"}, {"4", "d"}}
+
+	for _, sort := range []Sort{{Field: "name"}, {Field: "name", Desc: true}} {
+		request := Request{Limit: 2, Sort: sort}
+		var seen []string
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("expected the pages to end")
+			}
+			page := Slice(items, request, itemKey)
+			if page.Total != len(items) {
+				t.Errorf("expected a total of %d, got %d", len(items), page.Total)
+			}
+			for _, i := range page.Items {
+				seen = append(seen, i.id)
+			}
+			if page.Next == nil {
+				break
+			}
+			// The cursor survives the round trip through the query
+			next, err := Parse(url.Values{"limit": {"2"}, "sort": {sort.String()}, "cursor": {page.Next.Encode()}}, []string{"name"}, Sort{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request = next
+		}
+
+		expected := "1,2,3,4,5"
+		if sort.Desc {
+			expected = "5,4,3,2,1"
+		}
+		if got := strings.Join(seen, ","); got != expected {
+			t.Errorf("sorted by %s, expected %s, got %s", sort, expected, got)
+		}
+	}
+}
+
+func TestParseRejectsInvalidRequests(t *testing.T) {
+	sortable := []string{"name", "updated_at"}
+	other := Cursor{Sort: "name", Key: "a", ID: "1"}.Encode()
+
+	for _, query := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"1001"}},
+		{"limit": {"ten"}},
+		{"sort": {"size"}},
+		{"cursor": {"not a cursor"}},
+		{"sort": {"-updated_at"}, "cursor": {other}},
+	} {
+		if _, err := Parse(query, sortable, Sort{Field: "name"}); err == nil {
+			t.Errorf("expected %v to be rejected", query)
+		}
+	}
+
+	request, err := Parse(url.Values{}, sortable, Sort{Field: "updated_at", Desc: true})
+	if err != nil || request.Limit != DefaultLimit || request.Sort.String() != "-updated_at" || request.After != nil {
+		t.Errorf("expected the defaults, got %+v, %v", request, err)
+	}
+}
+
+func TestFiltersAndTimeKeys(t *testing.T) {
+	query := url.Values{"tag": {"a, b", "c", ""}}
+	if got := strings.Join(Filters(query, "tag"), ","); got != "a,b,c" {
+		t.Errorf("expected a,b,c, got %s", got)
+	}
+
+	if value, err := Bool(url.Values{"is_template": {"true"}}, "is_template"); err != nil || value == nil || !*value {
+		t.Errorf("expected true, got %v, %v", value, err)
+	}
+	if _, err := Bool(url.Values{"is_template": {"maybe"}}, "is_template"); err == nil {
+		t.Error("expected an invalid boolean to be rejected")
+	}
+
+	earlier := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := earlier.Add(time.Millisecond)
+	if TimeKey(earlier) >= TimeKey(later) {
+		t.Errorf("expected %s to sort before %s", TimeKey(earlier), TimeKey(later))
+	}
+	if parsed, err := ParseTimeKey(TimeKey(later)); err != nil || !parsed.Equal(later) {
+		t.Errorf("expected %v back, got %v, %v", later, parsed, err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package proxy adapts requests that reach the server through reverse proxies. It serves
+// the server under a base path and takes the client address, host and path prefix from the
+// X-Forwarded-* headers of the proxies it trusts.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Trusted is a set of proxy addresses whose X-Forwarded-* headers are believed
+type Trusted struct {
+	networks []*net.IPNet
+}
+
+// ParseTrusted parses proxy addresses, as IPs or CIDR ranges such as 10.0.0.0/8
+func ParseTrusted(addresses []string) (*Trusted, error) {
+	trusted := &Trusted{}
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if !strings.Contains(address, "/") {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", address)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trusted.networks = append(trusted.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q", address)
+		}
+		trusted.networks = append(trusted.networks, network)
+	}
+	return trusted, nil
+}
+
+// Contains checks if an IP is a trusted proxy
+func (t *Trusted) Contains(ip net.IP) bool {
+	if t == nil || ip == nil {
+		return false
+	}
+	for _, network := range t.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client of a request. The X-Forwarded-For header is
+// only read when the request comes from a trusted proxy, and is walked from the nearest hop
+// back to the first address that isn't a trusted proxy, as earlier ones can be forged.
+func (t *Trusted) ClientIP(r *http.Request) string {
+	remote := remoteIP(r.RemoteAddr)
+	if !t.Contains(net.ParseIP(remote)) {
+		return remote
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(remoteIP(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip.String()
+		if !t.Contains(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// remoteIP strips the port of an address, if it has one
+func remoteIP(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return strings.Trim(address, "[]")
+}
+
+// prefixKey is the context key of the path prefix the client sees the server under
+type prefixKey struct{}
+
+// clientIPKey is the context key of the client address
+type clientIPKey struct{}
+
+// Handler serves next under basePath, e.g. /webblueprint, for proxies that forward the path
+// as the client requested it. Requests without the base path are served as they are, for
+// proxies that strip it and for probes that reach the server directly. Requests from
+// trusted proxies take their host from X-Forwarded-Host, and their prefix from
+// X-Forwarded-Prefix when no base path is configured.
+func Handler(next http.Handler, basePath string, trusted *Trusted) http.Handler {
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromProxy := trusted.Contains(net.ParseIP(remoteIP(r.RemoteAddr)))
+
+		prefix := basePath
+		if fromProxy {
+			if host := r.Header.Get("X-Forwarded-Host"); host != "" {
+				r.Host = strings.TrimSpace(strings.Split(host, ",")[0])
+			}
+			if prefix == "" {
+				prefix = strings.TrimSuffix(r.Header.Get("X-Forwarded-Prefix"), "/")
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), clientIPKey{}, trusted.ClientIP(r))
+		ctx = context.WithValue(ctx, prefixKey{}, prefix)
+		r = r.WithContext(ctx)
+
+		if basePath != "" && (r.URL.Path == basePath || strings.HasPrefix(r.URL.Path, basePath+"/")) {
+			r = stripPrefix(r, basePath)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// stripPrefix returns a copy of a request with the prefix removed from its path
+func stripPrefix(r *http.Request, prefix string) *http.Request {
+	stripped := new(url.URL)
+	*stripped = *r.URL
+	stripped.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if r.URL.RawPath != "" {
+		stripped.RawPath = "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.RawPath, prefix), "/")
+	}
+
+	r2 := r.Clone(r.Context())
+	r2.URL = stripped
+	return r2
+}
+
+// Prefix returns the path prefix the client sees the server under, empty if it's served at
+// the root
+func Prefix(r *http.Request) string {
+	prefix, _ := r.Context().Value(prefixKey{}).(string)
+	return prefix
+}
+
+// ClientIP returns the address of the client of a request served by Handler, or the remote
+// address of other requests
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r.RemoteAddr)
+}
@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrusted([]string{"10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("expected the proxies to parse, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		expected  string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"untrusted remote can't forge", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", "198.51.100.1", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:5000", "198.51.100.1, 192.168.1.5, 10.9.9.9", "198.51.100.1"},
+		{"forged hops before the client", "10.1.2.3:5000", "1.1.1.1, 198.51.100.1", "198.51.100.1"},
+		{"only trusted hops", "10.1.2.3:5000", "10.4.4.4", "10.4.4.4"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = tc.remote
+			if tc.forwarded != "" {
+				request.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+			if ip := trusted.ClientIP(request); ip != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, ip)
+			}
+		})
+	}
+
+	if _, err := ParseTrusted([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected an invalid range to be rejected")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	trusted, _ := ParseTrusted([]string{"10.0.0.1"})
+
+	var path, prefix, host, client string
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, prefix, host, client = r.URL.Path, Prefix(r), r.Host, ClientIP(r)
+	}), "/webblueprint/", trusted)
+
+	serve := func(target, remote string, header map[string]string) {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.RemoteAddr = remote
+		for name, value := range header {
+			request.Header.Set(name, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	serve("/webblueprint/api/nodes", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Forwarded-Host": "example.com"})
+	if path != "/api/nodes" || prefix != "/webblueprint" || host != "example.com" || client != "198.51.100.1" {
+		t.Errorf("expected the base path stripped behind the proxy, got %s %s %s %s", path, prefix, host, client)
+	}
+
+	serve("/api/health/live", "10.0.0.2:80", map[string]string{"X-Forwarded-Host": "evil.example"})
+	if path != "/api/health/live" || host == "evil.example" || client != "10.0.0.2" {
+		t.Errorf("expected requests without the base path to pass and untrusted headers to be ignored, got %s %s %s", path, host, client)
+	}
+
+	serve("/webblueprint", "10.0.0.1:80", nil)
+	if path != "/" {
+		t.Errorf("expected the base path to serve the root, got %s", path)
+	}
+
+	forwarded := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix = Prefix(r)
+	}), "", trusted)
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = "10.0.0.1:80"
+	request.Header.Set("X-Forwarded-Prefix", "/studio/")
+	forwarded.ServeHTTP(httptest.NewRecorder(), request)
+	if prefix != "/studio" {
+		t.Errorf("expected the prefix of a proxy that strips it, got %q", prefix)
+	}
+}
@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"sort"
+	"webblueprint/internal/node"
+)
+
+// Namespaces scope the node types a blueprint or plugin defines at runtime, e.g. the getters
+// and setters of its variables, so types of the same name from different owners don't
+// replace each other. Lookups within a namespace prefer its own types. Plain lookups see a
+// namespaced type for as long as any namespace registers it, preferring the latest
+// registration, while types registered without a namespace always come first.
+
+// BlueprintNamespace returns the namespace of the node types a blueprint defines
+func BlueprintNamespace(blueprintID string) string {
+	return "blueprint:" + blueprintID
+}
+
+// BlueprintVersionNamespace returns the namespace of the node types a version of a blueprint
+// defines, so versions loaded side by side keep their own variables
+func BlueprintVersionNamespace(blueprintID, version string) string {
+	return BlueprintNamespace(blueprintID) + "@" + version
+}
+
+// PluginNamespace returns the namespace of the node types a plugin defines
+func PluginNamespace(plugin string) string {
+	return "plugin:" + plugin
+}
+
+// RegisterNamespacedNodeType registers a node type within a namespace, replacing the type
+// the namespace registered before under the same ID
+func (r *GlobalNodeRegistry) RegisterNamespacedNodeType(namespace, typeID string, factory node.NodeFactory) {
+	if factory == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	factories, exists := r.namespaces[namespace]
+	if !exists {
+		factories = make(map[string]node.NodeFactory)
+		r.namespaces[namespace] = factories
+	}
+	if _, registered := factories[typeID]; !registered {
+		r.refs[typeID]++
+	}
+	factories[typeID] = factory
+	r.scoped[typeID] = namespace
+}
+
+// UnregisterNamespacedNodeType removes a node type from a namespace
+func (r *GlobalNodeRegistry) UnregisterNamespacedNodeType(namespace, typeID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.releaseLocked(namespace, typeID)
+}
+
+// UnregisterNamespace removes every node type of a namespace, e.g. when its blueprint is
+// unloaded. Types other namespaces still register stay available.
+func (r *GlobalNodeRegistry) UnregisterNamespace(namespace string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for typeID := range r.namespaces[namespace] {
+		r.releaseLocked(namespace, typeID)
+	}
+	delete(r.namespaces, namespace)
+}
+
+// GetNamespacedNodeFactory retrieves a node factory within a namespace, falling back to the
+// types outside of it
+func (r *GlobalNodeRegistry) GetNamespacedNodeFactory(namespace, typeID string) (node.NodeFactory, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if factory, exists := r.namespaces[namespace][typeID]; exists {
+		return factory, true
+	}
+	return r.lookupLocked(typeID)
+}
+
+// GetNamespaceFactories returns the node factories registered within a namespace
+func (r *GlobalNodeRegistry) GetNamespaceFactories(namespace string) map[string]node.NodeFactory {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	factories := make(map[string]node.NodeFactory, len(r.namespaces[namespace]))
+	for typeID, factory := range r.namespaces[namespace] {
+		factories[typeID] = factory
+	}
+	return factories
+}
+
+// NodeTypeRefs returns how many namespaces register a node type
+func (r *GlobalNodeRegistry) NodeTypeRefs(typeID string) int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.refs[typeID]
+}
+
+// lookupLocked retrieves a node factory registered without a namespace, or else the latest
+// namespaced one, or else the latest one registered at runtime. The caller must hold the
+// mutex.
+func (r *GlobalNodeRegistry) lookupLocked(typeID string) (node.NodeFactory, bool) {
+	if factory, exists := r.factories[typeID]; exists {
+		return factory, true
+	}
+	if namespace, exists := r.scoped[typeID]; exists {
+		factory, exists := r.namespaces[namespace][typeID]
+		return factory, exists
+	}
+	return r.runtimeLookupLocked(typeID)
+}
+
+// releaseLocked drops the reference of a namespace to a node type. Once no namespace
+// registers the type, plain lookups no longer see it. The caller must hold the mutex.
+func (r *GlobalNodeRegistry) releaseLocked(namespace, typeID string) {
+	factories := r.namespaces[namespace]
+	if _, registered := factories[typeID]; !registered {
+		return
+	}
+	delete(factories, typeID)
+
+	r.refs[typeID]--
+	if r.refs[typeID] <= 0 {
+		delete(r.refs, typeID)
+		delete(r.scoped, typeID)
+		return
+	}
+	if r.scoped[typeID] != namespace {
+		return
+	}
+
+	// Plain lookups switch to another namespace that registers the type
+	remaining := make([]string, 0)
+	for other, otherFactories := range r.namespaces {
+		if _, registered := otherFactories[typeID]; registered {
+			remaining = append(remaining, other)
+		}
+	}
+	sort.Strings(remaining)
+	r.scoped[typeID] = remaining[0]
+}
@@ -1,7 +1,6 @@
 package registry
 
 import (
-	"fmt"
 	"sync"
 	"webblueprint/internal/node"
 )
@@ -10,10 +9,16 @@ import (
 // This is useful for user-defined functions to access the same node types
 // as the main execution engine
 type GlobalNodeRegistry struct {
-	factories map[string]node.NodeFactory
-	chanF     chan map[string]node.NodeFactory
-	mutex     sync.RWMutex
-	wg        sync.WaitGroup // Add WaitGroup to track registration goroutines
+	factories  map[string]node.NodeFactory
+	namespaces map[string]map[string]node.NodeFactory // Namespace -> type ID -> factory
+	scoped     map[string]string                      // Type ID -> namespace plain lookups take it from
+	refs       map[string]int                         // Type ID -> namespaces registering it
+	runtime    map[string]map[string]*runtimeNodeType // Type ID -> owner -> type registered at runtime
+	collected  int64                                  // Runtime registrations removed by the cleanup
+	stop       chan struct{}                          // Stops the cleanup of runtime registrations
+	chanF      chan map[string]node.NodeFactory
+	mutex      sync.RWMutex
+	wg         sync.WaitGroup // Add WaitGroup to track registration goroutines
 }
 
 var (
@@ -30,8 +35,12 @@ func Make(def ...map[string]node.NodeFactory) {
 		}
 
 		instance = &GlobalNodeRegistry{
-			factories: factories,
-			chanF:     make(chan map[string]node.NodeFactory),
+			factories:  factories,
+			namespaces: make(map[string]map[string]node.NodeFactory),
+			scoped:     make(map[string]string),
+			refs:       make(map[string]int),
+			runtime:    make(map[string]map[string]*runtimeNodeType),
+			chanF:      make(chan map[string]node.NodeFactory),
 			// wg is initialized with zero value
 		}
 	})
@@ -49,37 +58,18 @@ func (r *GlobalNodeRegistry) RegisterNodeType(typeID string, factory node.NodeFa
 	r.factories[typeID] = factory
 }
 
-func (r *GlobalNodeRegistry) RegisterNodeTypeRuntime(typeID string, factory node.NodeFactory) {
-	if factory == nil {
-		return
-	}
-
+// UnregisterNodeType removes a node type from the registry
+func (r *GlobalNodeRegistry) UnregisterNodeType(typeID string) {
 	r.mutex.Lock()
-	r.factories[typeID] = factory
-	r.mutex.Unlock()
-
-	r.wg.Add(1) // Increment WaitGroup counter
-	go func() {
-		defer r.wg.Done() // Decrement counter when goroutine finishes
-		// Use a select with a default case or timeout to prevent blocking indefinitely
-		// if the channel is closed or never read.
-		select {
-		case r.chanF <- map[string]node.NodeFactory{typeID: factory}:
-			// Sent successfully
-		default:
-			// Channel might be closed or blocked, log or handle appropriately
-			// Depending on requirements, could try a timed send
-			fmt.Printf("[WARN] Could not send runtime node factory update for %s to channel\n", typeID)
-		}
-	}()
+	defer r.mutex.Unlock()
+	delete(r.factories, typeID)
 }
 
-// GetNodeFactory retrieves a node factory by type ID
+// GetNodeFactory retrieves a node factory by type ID, including the namespaced ones
 func (r *GlobalNodeRegistry) GetNodeFactory(typeID string) (node.NodeFactory, bool) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
-	factory, exists := r.factories[typeID]
-	return factory, exists
+	return r.lookupLocked(typeID)
 }
 
 // GetAllNodeFactories returns all registered node factories
@@ -89,6 +79,12 @@ func (r *GlobalNodeRegistry) GetAllNodeFactories() map[string]node.NodeFactory {
 
 	// Create a copy to avoid concurrency issues
 	factoriesCopy := make(map[string]node.NodeFactory)
+	for typeID := range r.runtime {
+		factoriesCopy[typeID], _ = r.runtimeLookupLocked(typeID)
+	}
+	for typeID, namespace := range r.scoped {
+		factoriesCopy[typeID] = r.namespaces[namespace][typeID]
+	}
 	for k, v := range r.factories {
 		factoriesCopy[k] = v
 	}
@@ -100,7 +96,15 @@ func (r *GlobalNodeRegistry) FactoryChannel() chan map[string]node.NodeFactory {
 	return r.chanF
 }
 
+// Close stops the cleanup of runtime registrations and closes the factory channel
 func (r *GlobalNodeRegistry) Close() {
+	r.mutex.Lock()
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+	r.mutex.Unlock()
+
 	// Wait for any pending registration goroutines to finish sending
 	r.wg.Wait()
 
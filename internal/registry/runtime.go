@@ -0,0 +1,228 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"webblueprint/internal/node"
+)
+
+// Node types registered at runtime, e.g. the getters and setters of the variables of a
+// blueprint open in the editor, belong to owners. An owner refreshes its types by
+// registering them again; types are removed once their owner releases them, e.g. when its
+// blueprint is deleted, or once it didn't refresh them for a while. Plain lookups see a
+// runtime type after those registered without a namespace and the namespaced ones,
+// preferring the latest registration among its owners.
+
+// Defaults of the cleanup of runtime registrations
+const (
+	DefaultRuntimeCleanupInterval = 10 * time.Minute
+	DefaultRuntimeTTL             = time.Hour
+)
+
+// runtimeNodeType is a node type an owner registered at runtime
+type runtimeNodeType struct {
+	factory      node.NodeFactory
+	registeredAt time.Time // When the owner last registered the type
+}
+
+// RegistryStats is the size of the registry
+type RegistryStats struct {
+	NodeTypes            int   `json:"nodeTypes"`            // Registered without a namespace
+	Namespaces           int   `json:"namespaces"`           // Namespaces registering node types
+	NamespacedNodeTypes  int   `json:"namespacedNodeTypes"`  // Registrations across namespaces
+	RuntimeNodeTypes     int   `json:"runtimeNodeTypes"`     // Type IDs registered at runtime
+	RuntimeOwners        int   `json:"runtimeOwners"`        // Owners of runtime registrations
+	RuntimeRegistrations int   `json:"runtimeRegistrations"` // Registrations across owners
+	CollectedRuntime     int64 `json:"collectedRuntime"`     // Runtime registrations removed for not being refreshed
+}
+
+// RegisterNodeTypeRuntime registers a node type on behalf of an owner, refreshing the
+// registration if the owner registered the type before
+func (r *GlobalNodeRegistry) RegisterNodeTypeRuntime(owner, typeID string, factory node.NodeFactory) {
+	if factory == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.registerRuntimeLocked(owner, typeID, factory, time.Now())
+	r.mutex.Unlock()
+
+	r.notify(map[string]node.NodeFactory{typeID: factory})
+}
+
+// RegisterRuntimeNodeTypes registers the node types of an owner, releasing those it
+// registered before that aren't among them
+func (r *GlobalNodeRegistry) RegisterRuntimeNodeTypes(owner string, factories map[string]node.NodeFactory) {
+	r.mutex.Lock()
+	now := time.Now()
+	for typeID, owners := range r.runtime {
+		if _, kept := factories[typeID]; !kept && owners[owner] != nil {
+			r.releaseRuntimeLocked(owner, typeID)
+		}
+	}
+	registered := make(map[string]node.NodeFactory, len(factories))
+	for typeID, factory := range factories {
+		if factory != nil {
+			r.registerRuntimeLocked(owner, typeID, factory, now)
+			registered[typeID] = factory
+		}
+	}
+	r.mutex.Unlock()
+
+	if len(registered) > 0 {
+		r.notify(registered)
+	}
+}
+
+// ReleaseRuntimeNodeTypes removes the node types an owner registered at runtime. Types
+// other owners still register stay available.
+func (r *GlobalNodeRegistry) ReleaseRuntimeNodeTypes(owner string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for typeID, owners := range r.runtime {
+		if owners[owner] != nil {
+			r.releaseRuntimeLocked(owner, typeID)
+		}
+	}
+}
+
+// CollectRuntimeNodeTypes removes the runtime registrations their owners didn't refresh
+// within the time to live and returns how many it removed
+func (r *GlobalNodeRegistry) CollectRuntimeNodeTypes(ttl time.Duration) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cutoff := time.Now().Add(-ttl)
+	collected := 0
+	for typeID, owners := range r.runtime {
+		for owner, registration := range owners {
+			if registration.registeredAt.Before(cutoff) {
+				r.releaseRuntimeLocked(owner, typeID)
+				collected++
+			}
+		}
+	}
+	r.collected += int64(collected)
+	return collected
+}
+
+// StartRuntimeCleanup collects the stale runtime registrations every interval until the
+// registry is closed. It does nothing if the cleanup already runs.
+func (r *GlobalNodeRegistry) StartRuntimeCleanup(interval, ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil || interval <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	r.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.CollectRuntimeNodeTypes(ttl)
+			}
+		}
+	}()
+}
+
+// Stats returns the size of the registry
+func (r *GlobalNodeRegistry) Stats() RegistryStats {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := RegistryStats{
+		NodeTypes:        len(r.factories),
+		RuntimeNodeTypes: len(r.runtime),
+		CollectedRuntime: r.collected,
+	}
+	for _, factories := range r.namespaces {
+		if len(factories) > 0 {
+			stats.Namespaces++
+			stats.NamespacedNodeTypes += len(factories)
+		}
+	}
+	owners := make(map[string]bool)
+	for _, registrations := range r.runtime {
+		stats.RuntimeRegistrations += len(registrations)
+		for owner := range registrations {
+			owners[owner] = true
+		}
+	}
+	stats.RuntimeOwners = len(owners)
+	return stats
+}
+
+// RuntimeOwners returns the owners registering a node type at runtime, sorted
+func (r *GlobalNodeRegistry) RuntimeOwners(typeID string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	owners := make([]string, 0, len(r.runtime[typeID]))
+	for owner := range r.runtime[typeID] {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// registerRuntimeLocked registers a node type of an owner. The caller must hold the mutex.
+func (r *GlobalNodeRegistry) registerRuntimeLocked(owner, typeID string, factory node.NodeFactory, now time.Time) {
+	owners, exists := r.runtime[typeID]
+	if !exists {
+		owners = make(map[string]*runtimeNodeType)
+		r.runtime[typeID] = owners
+	}
+	owners[owner] = &runtimeNodeType{factory: factory, registeredAt: now}
+}
+
+// releaseRuntimeLocked drops the registration of a node type by an owner. The caller must
+// hold the mutex.
+func (r *GlobalNodeRegistry) releaseRuntimeLocked(owner, typeID string) {
+	owners := r.runtime[typeID]
+	delete(owners, owner)
+	if len(owners) == 0 {
+		delete(r.runtime, typeID)
+	}
+}
+
+// runtimeLookupLocked retrieves the latest registration of a node type registered at
+// runtime. The caller must hold the mutex.
+func (r *GlobalNodeRegistry) runtimeLookupLocked(typeID string) (node.NodeFactory, bool) {
+	var latest *runtimeNodeType
+	latestOwner := ""
+	for owner, registration := range r.runtime[typeID] {
+		if latest == nil || registration.registeredAt.After(latest.registeredAt) ||
+			registration.registeredAt.Equal(latest.registeredAt) && owner < latestOwner {
+			latest, latestOwner = registration, owner
+		}
+	}
+	if latest == nil {
+		return nil, false
+	}
+	return latest.factory, true
+}
+
+// notify sends node types registered at runtime to the factory channel, without blocking
+func (r *GlobalNodeRegistry) notify(factories map[string]node.NodeFactory) {
+	r.wg.Add(1) // Increment WaitGroup counter
+	go func() {
+		defer r.wg.Done() // Decrement counter when goroutine finishes
+		// Use a select with a default case to prevent blocking indefinitely if the channel
+		// is never read
+		select {
+		case r.chanF <- factories:
+			// Sent successfully
+		default:
+			fmt.Printf("[WARN] Could not send runtime node factory update for %d node types to channel\n", len(factories))
+		}
+	}()
+}
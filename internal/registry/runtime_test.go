@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"testing"
+	"time"
+	"webblueprint/internal/node"
+)
+
+func newTestRegistry() *GlobalNodeRegistry {
+	return &GlobalNodeRegistry{
+		factories:  make(map[string]node.NodeFactory),
+		namespaces: make(map[string]map[string]node.NodeFactory),
+		scoped:     make(map[string]string),
+		refs:       make(map[string]int),
+		runtime:    make(map[string]map[string]*runtimeNodeType),
+		chanF:      make(chan map[string]node.NodeFactory),
+	}
+}
+
+func factory() node.NodeFactory {
+	return func() node.Node { return nil }
+}
+
+func TestRuntimeNodeTypesAreReleasedByOwner(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterRuntimeNodeTypes("blueprint:a", map[string]node.NodeFactory{"get-variable-x": factory(), "set-variable-x": factory()})
+	r.RegisterRuntimeNodeTypes("blueprint:b", map[string]node.NodeFactory{"get-variable-x": factory()})
+
+	if owners := r.RuntimeOwners("get-variable-x"); len(owners) != 2 {
+		t.Fatalf("expected both blueprints to own get-variable-x, got %v", owners)
+	}
+
+	r.ReleaseRuntimeNodeTypes("blueprint:a")
+	if _, exists := r.GetNodeFactory("set-variable-x"); exists {
+		t.Error("expected set-variable-x to be removed with its only owner")
+	}
+	if _, exists := r.GetNodeFactory("get-variable-x"); !exists {
+		t.Error("expected get-variable-x to stay while blueprint b registers it")
+	}
+
+	// Registering the types of an owner again drops those it no longer has
+	r.RegisterRuntimeNodeTypes("blueprint:b", map[string]node.NodeFactory{"get-variable-y": factory()})
+	if _, exists := r.GetAllNodeFactories()["get-variable-x"]; exists {
+		t.Error("expected get-variable-x to be removed once blueprint b dropped it")
+	}
+
+	stats := r.Stats()
+	if stats.RuntimeNodeTypes != 1 || stats.RuntimeOwners != 1 || stats.RuntimeRegistrations != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	r.wg.Wait()
+}
+
+func TestCollectRuntimeNodeTypes(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterNodeTypeRuntime("blueprint:stale", "get-variable-old", factory())
+	r.RegisterNodeTypeRuntime("blueprint:fresh", "get-variable-new", factory())
+	r.runtime["get-variable-old"]["blueprint:stale"].registeredAt = time.Now().Add(-2 * time.Hour)
+
+	if collected := r.CollectRuntimeNodeTypes(time.Hour); collected != 1 {
+		t.Fatalf("expected 1 stale registration to be collected, got %d", collected)
+	}
+	if _, exists := r.GetNodeFactory("get-variable-old"); exists {
+		t.Error("expected the stale node type to be collected")
+	}
+	if _, exists := r.GetNodeFactory("get-variable-new"); !exists {
+		t.Error("expected the fresh node type to stay")
+	}
+	if stats := r.Stats(); stats.CollectedRuntime != 1 {
+		t.Errorf("expected 1 collected registration in the stats, got %d", stats.CollectedRuntime)
+	}
+
+	r.StartRuntimeCleanup(time.Millisecond, time.Hour)
+	r.Close()
+}
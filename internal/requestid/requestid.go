@@ -0,0 +1,73 @@
+// Package requestid identifies the requests the server handles. The ID of a request is taken
+// from its X-Request-ID header, or generated, and travels in its context into the logs, the
+// error responses and the executions it starts.
+package requestid
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header is the header a request ID is read from and echoed in
+const Header = "X-Request-ID"
+
+// LogKey is the attribute the request ID is logged under
+const LogKey = "requestId"
+
+// validID matches the request IDs taken from clients and proxies; others are replaced, so
+// they can't inject text into the logs
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// contextKey is the context key of the request ID
+type contextKey struct{}
+
+// New generates a request ID
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid checks if a request ID sent by a client can be kept
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// NewContext returns a copy of ctx carrying a request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID of a context, empty if it has none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// logHandler adds the request ID of the context to the records it handles
+type logHandler struct {
+	slog.Handler
+}
+
+// LogHandler wraps a log handler so the records logged with the context of a request carry
+// its ID
+func LogHandler(handler slog.Handler) slog.Handler {
+	return &logHandler{Handler: handler}
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		record = record.Clone()
+		record.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithGroup(name)}
+}
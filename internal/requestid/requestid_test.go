@@ -0,0 +1,42 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for id, valid := range map[string]bool{
+		New():                     true,
+		"req-42.retry:1":          true,
+		"":                        false,
+		"two words":               false,
+		"line\nbreak":             false,
+		strings.Repeat("a", 129):  false,
+		`"quoted"`:                false,
+		"01HZX3K9Q2B7W1V6N4M8P5R": true,
+	} {
+		if got := Valid(id); got != valid {
+			t.Errorf("Valid(%q) = %v, expected %v", id, got, valid)
+		}
+	}
+}
+
+func TestLogHandlerAddsRequestID(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(LogHandler(slog.NewTextHandler(&output, nil))).With("component", "api")
+
+	logger.InfoContext(NewContext(context.Background(), "req-1"), "handled")
+	if !strings.Contains(output.String(), "requestId=req-1") || !strings.Contains(output.String(), "component=api") {
+		t.Errorf("expected the request ID and the logger's attributes, got %q", output.String())
+	}
+
+	output.Reset()
+	logger.InfoContext(context.Background(), "background")
+	if strings.Contains(output.String(), "requestId") {
+		t.Errorf("expected no request ID outside of requests, got %q", output.String())
+	}
+}
@@ -0,0 +1,35 @@
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// isolate starts the command in its own process group and kills the whole group when
+// the command is cancelled
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// limitCPU limits the CPU time of a started process. It gets SIGXCPU at the limit and
+// SIGKILL a second later if it ignores it.
+func limitCPU(pid int, limit time.Duration) {
+	if limit <= 0 {
+		return
+	}
+	seconds := uint64((limit + time.Second - 1) / time.Second)
+	_ = unix.Prlimit(pid, unix.RLIMIT_CPU, &unix.Rlimit{Cur: seconds, Max: seconds + 1}, nil)
+}
+
+// killedForCPU reports whether a process was killed for exceeding its CPU time
+func killedForCPU(state *os.ProcessState) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGXCPU
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"time"
+)
+
+// isolate is a no-op outside Linux; only the command itself is killed on cancellation
+func isolate(cmd *exec.Cmd) {}
+
+// limitCPU is a no-op outside Linux, where the wall-clock timeout still applies
+func limitCPU(pid int, limit time.Duration) {}
+
+// killedForCPU is always false outside Linux
+func killedForCPU(state *os.ProcessState) bool {
+	return false
+}
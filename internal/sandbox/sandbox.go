@@ -0,0 +1,279 @@
+// Package sandbox runs local commands for the run-command node under a policy: only
+// allowed binaries start, in a working directory inside a root, with a scrubbed
+// environment and time, CPU and output limits. It confines what a blueprint can start, not
+// what the started programs do, so allow only binaries and scripts you trust.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotAllowed is returned for commands whose binary isn't allowed
+	ErrNotAllowed = errors.New("command is not allowed")
+	// ErrOutsideRoot is returned for working directories outside the root
+	ErrOutsideRoot = errors.New("working directory is outside the sandbox root")
+)
+
+// envNamePattern matches the names of environment variables a policy may let commands set
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Policy confines the commands of the run-command node
+type Policy struct {
+	Allow     []string      // Binaries that may run, as absolute paths or names looked up in PATH
+	Root      string        // Directory working directories must be inside, created if missing
+	PassEnv   []string      // Variables of the server environment commands inherit
+	AllowEnv  []string      // Variables commands may set; the others they set are dropped
+	Timeout   time.Duration // Longest wall-clock time a command runs, and the default of the node
+	CPUTime   time.Duration // CPU time limit of a command, 0 for none; only enforced on Linux
+	MaxOutput int           // Bytes of stdout and of stderr kept, 0 for no limit
+}
+
+// Command is a command to run. It isn't interpreted by a shell.
+type Command struct {
+	Name    string            // Allowed binary, by name or absolute path
+	Args    []string          // Arguments, passed as they are
+	Dir     string            // Working directory relative to the root, the root if empty
+	Env     map[string]string // Variables set on top of the inherited ones, if the policy allows them
+	Stdin   string            // Standard input
+	Timeout time.Duration     // Wall-clock limit, capped by the policy; the policy's if 0
+}
+
+// Result is the outcome of a command that ran
+type Result struct {
+	ExitCode   int // -1 if the command was killed
+	Stdout     string
+	Stderr     string
+	Truncated  bool     // Whether stdout or stderr exceeded the output limit
+	TimedOut   bool     // Whether the command was killed for exceeding a time limit
+	DroppedEnv []string // Variables the command set that the policy doesn't allow, sorted
+	Duration   time.Duration
+}
+
+// Sandbox runs commands under a policy
+type Sandbox struct {
+	policy   Policy
+	root     string
+	binaries map[string]string // Allowed name or path -> resolved absolute path
+	allowEnv map[string]bool   // Variables commands may set
+}
+
+// New creates a sandbox, resolving the allowed binaries and creating the root. It fails
+// if an allowed binary can't be found.
+func New(policy Policy) (*Sandbox, error) {
+	if len(policy.Allow) == 0 {
+		return nil, errors.New("no binaries are allowed")
+	}
+	if policy.Root == "" {
+		return nil, errors.New("root is required")
+	}
+	if policy.Timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if err := os.MkdirAll(policy.Root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create root: %w", err)
+	}
+	root, err := resolve(policy.Root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root: %w", err)
+	}
+
+	allowEnv := make(map[string]bool, len(policy.AllowEnv))
+	for _, name := range policy.AllowEnv {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid allowed variable %q", name)
+		}
+		// Loader variables would run code inside an allowed binary
+		if upper := strings.ToUpper(name); strings.HasPrefix(upper, "LD_") || strings.HasPrefix(upper, "DYLD_") {
+			return nil, fmt.Errorf("allowed variable %s: loader variables can't be allowed", name)
+		}
+		allowEnv[name] = true
+	}
+
+	binaries := make(map[string]string, len(policy.Allow))
+	for _, name := range policy.Allow {
+		found, err := exec.LookPath(name)
+		if err != nil {
+			return nil, fmt.Errorf("allowed binary %q: %w", name, err)
+		}
+		path, err := resolve(found)
+		if err != nil {
+			return nil, fmt.Errorf("allowed binary %q: %w", name, err)
+		}
+		binaries[name] = path
+		binaries[found] = path
+		binaries[path] = path
+	}
+
+	return &Sandbox{policy: policy, root: root, binaries: binaries, allowEnv: allowEnv}, nil
+}
+
+// Policy returns the policy of the sandbox
+func (s *Sandbox) Policy() Policy {
+	return s.policy
+}
+
+// Run runs a command and waits for it. Commands that start and exit with an error
+// return a result with their exit code and no error.
+func (s *Sandbox) Run(ctx context.Context, command Command) (*Result, error) {
+	binary, allowed := s.binaries[command.Name]
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s", ErrNotAllowed, command.Name)
+	}
+	dir, err := s.workDir(command.Dir)
+	if err != nil {
+		return nil, err
+	}
+	env, dropped := s.env(command.Env)
+
+	timeout := s.policy.Timeout
+	if command.Timeout > 0 && command.Timeout < timeout {
+		timeout = command.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: s.policy.MaxOutput}
+	stderr := &limitedBuffer{limit: s.policy.MaxOutput}
+	cmd := exec.CommandContext(ctx, binary, command.Args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(command.Stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Children the command starts are killed with it
+	isolate(cmd)
+	cmd.WaitDelay = time.Second
+
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command.Name, err)
+	}
+	limitCPU(cmd.Process.Pid, s.policy.CPUTime)
+	waitErr := cmd.Wait()
+
+	result := &Result{
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		Truncated:  stdout.truncated || stderr.truncated,
+		TimedOut:   ctx.Err() == context.DeadlineExceeded || killedForCPU(cmd.ProcessState),
+		DroppedEnv: dropped,
+		Duration:   time.Since(started),
+	}
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) && !errors.Is(waitErr, exec.ErrWaitDelay) && !result.TimedOut {
+		return nil, waitErr
+	}
+	return result, nil
+}
+
+// workDir resolves a working directory relative to the root, rejecting any outside it
+func (s *Sandbox) workDir(dir string) (string, error) {
+	if dir == "" {
+		return s.root, nil
+	}
+	if filepath.IsAbs(dir) {
+		return "", fmt.Errorf("%w: %s must be relative to the root", ErrOutsideRoot, dir)
+	}
+
+	// Symlinks are resolved, so they can't lead out of the root
+	resolved, err := resolve(filepath.Join(s.root, dir))
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory %s: %w", dir, err)
+	}
+	if resolved != s.root && !strings.HasPrefix(resolved, s.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, dir)
+	}
+	return resolved, nil
+}
+
+// env builds the environment of a command from the inherited variables and those of its
+// own the policy allows. It returns the names of the variables it dropped.
+func (s *Sandbox) env(own map[string]string) ([]string, []string) {
+	variables := make(map[string]string)
+	for _, name := range s.policy.PassEnv {
+		if value, exists := os.LookupEnv(name); exists {
+			variables[name] = value
+		}
+	}
+	// Variables such as BASH_ENV or NODE_OPTIONS run code inside an allowed binary, so
+	// only the ones the policy lists are set
+	var dropped []string
+	for name, value := range own {
+		if !s.allowEnv[name] {
+			dropped = append(dropped, name)
+			continue
+		}
+		variables[name] = value
+	}
+	sort.Strings(dropped)
+
+	env := make([]string, 0, len(variables))
+	for name, value := range variables {
+		env = append(env, name+"="+value)
+	}
+	return env, dropped
+}
+
+// resolve returns the absolute path of a file with its symlinks resolved
+func resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// limitedBuffer keeps up to a limit of the bytes written to it and discards the rest
+type limitedBuffer struct {
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.buffer.Len()+len(p) > b.limit {
+		b.truncated = true
+		b.buffer.Write(p[:b.limit-b.buffer.Len()])
+		// Reporting the whole write keeps the command from failing on a broken pipe
+		return len(p), nil
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buffer.String()
+}
+
+// defaultSandbox is the sandbox of the run-command node; there's none, so commands are
+// disabled, until the server configures one
+var defaultSandbox *Sandbox
+var defaultMutex sync.RWMutex
+
+// Default returns the sandbox the run-command node uses, nil if commands are disabled
+func Default() *Sandbox {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultSandbox
+}
+
+// SetDefault replaces the sandbox the run-command node uses; nil disables commands
+func SetDefault(sandbox *Sandbox) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultSandbox = sandbox
+}
@@ -0,0 +1,168 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSandbox(t *testing.T, policy Policy) *Sandbox {
+	t.Helper()
+	if policy.Root == "" {
+		policy.Root = filepath.Join(t.TempDir(), "root")
+	}
+	if policy.Allow == nil {
+		policy.Allow = []string{"sh"}
+	}
+	if policy.Timeout == 0 {
+		policy.Timeout = 5 * time.Second
+	}
+	box, err := New(policy)
+	if err != nil {
+		t.Fatalf("failed to create sandbox: %v", err)
+	}
+	return box
+}
+
+func TestRunCommand(t *testing.T) {
+	box := newTestSandbox(t, Policy{AllowEnv: []string{"GREETING"}})
+	if err := os.Mkdir(filepath.Join(box.root, "jobs"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := box.Run(context.Background(), Command{
+		Name:  "sh",
+		Args:  []string{"-c", `pwd; read line; echo "$line $GREETING" >&2; exit 3`},
+		Dir:   "jobs",
+		Env:   map[string]string{"GREETING": "world"},
+		Stdin: "hello\n",
+	})
+	if err != nil {
+		t.Fatalf("expected the command to run, got %v", err)
+	}
+	if result.ExitCode != 3 || result.TimedOut {
+		t.Errorf("expected exit code 3, got %+v", result)
+	}
+	if strings.TrimSpace(result.Stdout) != filepath.Join(box.root, "jobs") {
+		t.Errorf("expected the command to run in jobs, got %q", result.Stdout)
+	}
+	if strings.TrimSpace(result.Stderr) != "hello world" {
+		t.Errorf("expected stdin and the environment to reach the command, got %q", result.Stderr)
+	}
+}
+
+func TestRunRejectsCommands(t *testing.T) {
+	box := newTestSandbox(t, Policy{})
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(box.root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		command  Command
+		expected error
+	}{
+		"binary not allowed":     {Command{Name: "ls"}, ErrNotAllowed},
+		"path of another binary": {Command{Name: "/bin/ls"}, ErrNotAllowed},
+		"parent directory":       {Command{Name: "sh", Dir: "../"}, ErrOutsideRoot},
+		"absolute directory":     {Command{Name: "sh", Dir: outside}, ErrOutsideRoot},
+		"symlink out of root":    {Command{Name: "sh", Dir: "escape"}, ErrOutsideRoot},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := box.Run(context.Background(), tt.command); !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRunScrubsEnvironment(t *testing.T) {
+	t.Setenv("SANDBOX_TEST_SECRET", "hunter2")
+	t.Setenv("SANDBOX_TEST_PASSED", "yes")
+	box := newTestSandbox(t, Policy{PassEnv: []string{"PATH", "SANDBOX_TEST_PASSED"}})
+
+	result, err := box.Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "echo \"$SANDBOX_TEST_SECRET|$SANDBOX_TEST_PASSED\""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(result.Stdout) != "|yes" {
+		t.Errorf("expected only the passed variable, got %q", result.Stdout)
+	}
+}
+
+func TestRunDropsVariablesThePolicyDoesNotAllow(t *testing.T) {
+	// BASH_ENV and NODE_OPTIONS would make an allowed shell or node run code of the blueprint
+	hook := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(hook, []byte("echo hooked\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	box := newTestSandbox(t, Policy{AllowEnv: []string{"GREETING"}})
+
+	result, err := box.Run(context.Background(), Command{
+		Name: "sh",
+		Args: []string{"-c", `echo "$GREETING|$BASH_ENV|$NODE_OPTIONS|$LD_PRELOAD|$ENV"`},
+		Env: map[string]string{
+			"GREETING":     "hello",
+			"BASH_ENV":     hook,
+			"ENV":          hook,
+			"NODE_OPTIONS": "--require " + hook,
+			"LD_PRELOAD":   "/tmp/x.so",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(result.Stdout) != "hello||||" {
+		t.Errorf("expected only the allowed variable to be set, got %q", result.Stdout)
+	}
+	if dropped := strings.Join(result.DroppedEnv, ","); dropped != "BASH_ENV,ENV,LD_PRELOAD,NODE_OPTIONS" {
+		t.Errorf("expected the dropped variables to be reported, got %s", dropped)
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	box := newTestSandbox(t, Policy{MaxOutput: 10, Timeout: time.Minute})
+
+	result, err := box.Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "echo 0123456789abcdef"}})
+	if err != nil || result.Stdout != "0123456789" || !result.Truncated || result.ExitCode != 0 {
+		t.Errorf("expected the output to be truncated to 10 bytes, got %+v, %v", result, err)
+	}
+
+	started := time.Now()
+	result, err = box.Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "sleep 30 & wait"}, Timeout: 200 * time.Millisecond})
+	if err != nil || !result.TimedOut || result.ExitCode != -1 {
+		t.Errorf("expected the command to time out, got %+v, %v", result, err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the command and its children to be killed, took %s", elapsed)
+	}
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	box = newTestSandbox(t, Policy{CPUTime: time.Second, Timeout: 30 * time.Second})
+	result, err = box.Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "while :; do :; done"}})
+	if err != nil || !result.TimedOut {
+		t.Errorf("expected the command to exceed its CPU time, got %+v, %v", result, err)
+	}
+}
+
+func TestNewValidatesPolicy(t *testing.T) {
+	if _, err := New(Policy{Allow: []string{"no-such-binary-anywhere"}, Root: t.TempDir(), Timeout: time.Second}); err == nil {
+		t.Error("expected a missing binary to be reported")
+	}
+	if _, err := New(Policy{Root: t.TempDir(), Timeout: time.Second}); err == nil {
+		t.Error("expected an empty allowlist to be reported")
+	}
+	for _, name := range []string{"LD_PRELOAD", "dyld_insert_libraries", "A=B"} {
+		if _, err := New(Policy{Allow: []string{"sh"}, AllowEnv: []string{name}, Root: t.TempDir(), Timeout: time.Second}); err == nil {
+			t.Errorf("expected allowing %s to be reported", name)
+		}
+	}
+}
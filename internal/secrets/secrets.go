@@ -0,0 +1,97 @@
+// Package secrets resolves the secrets nodes refer to by name, e.g. the keys of the
+// encryption and signing nodes, so blueprints never hold them.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultEnvPrefix is the prefix of the environment variables the default store reads
+const DefaultEnvPrefix = "BLUEPRINT_SECRET_"
+
+// ErrNotFound is returned for secrets a store doesn't have
+var ErrNotFound = errors.New("secret not found")
+
+// Store resolves secrets by name
+type Store interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Name returns the name of a secret from a reference to it, either the name itself or
+// {{secrets.name}} as the blueprint linter allows in node data
+func Name(reference string) string {
+	reference = strings.TrimSpace(reference)
+	if strings.HasPrefix(reference, "{{") && strings.HasSuffix(reference, "}}") {
+		inner := strings.TrimSpace(reference[2 : len(reference)-2])
+		if name, found := strings.CutPrefix(inner, "secrets."); found {
+			return name
+		}
+	}
+	return reference
+}
+
+// EnvStore reads secrets from environment variables named by a prefix and the upper-cased
+// name of the secret, non-alphanumeric characters replaced by underscores: the secret
+// webhook-key is read from BLUEPRINT_SECRET_WEBHOOK_KEY.
+type EnvStore struct {
+	prefix string
+}
+
+// NewEnvStore creates a store reading the environment variables with a prefix
+func NewEnvStore(prefix string) *EnvStore {
+	return &EnvStore{prefix: prefix}
+}
+
+// Get reads a secret from its environment variable
+func (s *EnvStore) Get(_ context.Context, name string) (string, error) {
+	variable := s.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	value, exists := os.LookupEnv(variable)
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// MapStore holds secrets in memory, e.g. for tests or applications embedding the engine
+type MapStore map[string]string
+
+// Get returns a secret of the map
+func (s MapStore) Get(_ context.Context, name string) (string, error) {
+	value, exists := s[name]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// defaultStore is the store of the nodes reading secrets
+var defaultStore Store = NewEnvStore(DefaultEnvPrefix)
+var defaultMutex sync.RWMutex
+
+// Default returns the store the nodes read secrets from
+func Default() Store {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultStore
+}
+
+// SetDefault replaces the store the nodes read secrets from
+func SetDefault(store Store) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultStore = store
+}
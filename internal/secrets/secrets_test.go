@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnvStore(t *testing.T) {
+	t.Setenv("TEST_SECRET_WEBHOOK_KEY", "s3cret")
+	store := NewEnvStore("TEST_SECRET_")
+
+	value, err := store.Get(context.Background(), "webhook-key")
+	if err != nil || value != "s3cret" {
+		t.Errorf("expected the secret, got %q, %v", value, err)
+	}
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMapStore(t *testing.T) {
+	store := MapStore{"key": "value"}
+	if value, err := store.Get(context.Background(), "key"); err != nil || value != "value" {
+		t.Errorf("expected the secret, got %q, %v", value, err)
+	}
+	if _, err := store.Get(context.Background(), "other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestName(t *testing.T) {
+	for reference, expected := range map[string]string{
+		"apiKey":               "apiKey",
+		"{{secrets.apiKey}}":   "apiKey",
+		"{{ secrets.apiKey }}": "apiKey",
+		"{{variables.apiKey}}": "{{variables.apiKey}}",
+		"  webhook-key ":       "webhook-key",
+	} {
+		if name := Name(reference); name != expected {
+			t.Errorf("Name(%q) = %q, expected %q", reference, name, expected)
+		}
+	}
+}
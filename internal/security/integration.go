@@ -146,6 +146,10 @@ func (m *mockExecutionContext) GetInputValue(pinID string) (types.Value, bool) {
 	return value, exists
 }
 
+func (m *mockExecutionContext) GetUpstreamOutput(nodeID, pinID string) (types.Value, bool) {
+	return types.Value{}, false
+}
+
 func (m *mockExecutionContext) SetOutputValue(pinID string, value types.Value) {
 	m.outputs[pinID] = value
 }
@@ -196,6 +200,10 @@ func (m *mockExecutionContext) GetExecutionID() string {
 	return m.executionID
 }
 
+func (m *mockExecutionContext) Context() context.Context {
+	return context.Background()
+}
+
 func (m *mockExecutionContext) RecordDebugInfo(info types.DebugInfo) {
 	// No-op in mock
 }
@@ -203,3 +211,11 @@ func (m *mockExecutionContext) RecordDebugInfo(info types.DebugInfo) {
 func (m *mockExecutionContext) GetDebugData() map[string]interface{} {
 	return make(map[string]interface{})
 }
+
+func (m *mockExecutionContext) SaveData(key string, value interface{}) {
+	// No-op in mock
+}
+
+func (m *mockExecutionContext) CreateLoopContext(loopVarName string, maxIterations int, startIndex float64) (node.LoopContext, bool) {
+	return nil, false
+}
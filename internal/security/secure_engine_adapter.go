@@ -97,7 +97,7 @@ func (adapter *SecureEngineAdapter) CheckSecurityPreExecution(
 	}
 
 	// Check for potentially dangerous nodes
-	for _, nodeConfig := range bp.Nodes {
+	for _, nodeConfig := range bp.ExecutableNodes() {
 		if !checker.IsNodeAllowed(nodeConfig.Type, permLevel) {
 			return fmt.Errorf("blueprint contains restricted node type: %s", nodeConfig.Type)
 		}
@@ -1,6 +1,7 @@
 package security
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -264,6 +265,10 @@ func (m *TestMockExecutionContext) GetInputValue(pinID string) (types.Value, boo
 	return value, exists
 }
 
+func (m *TestMockExecutionContext) GetUpstreamOutput(nodeID, pinID string) (types.Value, bool) {
+	return types.Value{}, false
+}
+
 func (m *TestMockExecutionContext) SetOutputValue(pinID string, value types.Value) {
 	m.outputs[pinID] = value
 }
@@ -309,6 +314,10 @@ func (m *TestMockExecutionContext) GetExecutionID() string {
 	return m.executionID
 }
 
+func (m *TestMockExecutionContext) Context() context.Context {
+	return context.Background()
+}
+
 func (m *TestMockExecutionContext) GetDebugData() map[string]interface{} {
 	return make(map[string]interface{})
 }
@@ -316,3 +325,15 @@ func (m *TestMockExecutionContext) GetDebugData() map[string]interface{} {
 func (m *TestMockExecutionContext) RecordDebugInfo(info types.DebugInfo) {
 	// Do nothing
 }
+
+func (m *TestMockExecutionContext) SaveData(key string, value interface{}) {
+	// Do nothing
+}
+
+func (m *TestMockExecutionContext) CreateLoopContext(loopVarName string, maxIterations int, startIndex float64) (node.LoopContext, bool) {
+	return nil, false
+}
+
+func (m *TestMockExecutionContext) IsInputPinActive(pinID string) bool {
+	return true
+}
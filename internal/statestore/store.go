@@ -0,0 +1,190 @@
+package statestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// AnyVersion writes a value whatever the version of its key
+const AnyVersion int64 = -1
+
+// Entry is a value a blueprint keeps across executions
+type Entry struct {
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	Version   int64       `json:"version"`
+	UpdatedBy string      `json:"updatedBy,omitempty"` // Execution that wrote the value
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// ConflictError is returned when a key isn't at the version a write expects, e.g. because
+// another execution wrote it in the meantime
+type ConflictError struct {
+	BlueprintID string
+	Key         string
+	Expected    int64
+	Actual      int64 // 0 if the key isn't set
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("state %q of blueprint %s is at version %d, not %d", e.Key, e.BlueprintID, e.Actual, e.Expected)
+}
+
+// Store keeps durable per-blueprint values with optimistic concurrency: every write bumps
+// the version of its key, and writes can require the version they read
+type Store struct {
+	repo   repository.StateRepository
+	memory map[string]map[string]Entry // BlueprintID -> key -> entry, without a repository
+	mutex  sync.Mutex
+}
+
+// NewStore creates a state store. Without a repository the values only live as long as
+// the process, e.g. for headless runs.
+func NewStore(repo repository.StateRepository) *Store {
+	return &Store{
+		repo:   repo,
+		memory: make(map[string]map[string]Entry),
+	}
+}
+
+// Durable reports whether the values are kept in the database rather than in memory
+func (s *Store) Durable() bool {
+	return s.repo != nil
+}
+
+// Get returns the value of a key and whether it's set
+func (s *Store) Get(ctx context.Context, blueprintID, key string) (Entry, bool, error) {
+	if s.repo == nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		entry, exists := s.memory[blueprintID][key]
+		return entry, exists, nil
+	}
+
+	state, err := s.repo.Get(ctx, blueprintID, key)
+	if err != nil || state == nil {
+		return Entry{}, false, err
+	}
+	return toEntry(state), true, nil
+}
+
+// Set writes the value of a key if it's at the expected version: 0 for a key that isn't
+// set, or AnyVersion. It returns the written entry with its new version, or a ConflictError.
+func (s *Store) Set(ctx context.Context, blueprintID, key string, value interface{}, expectedVersion int64, executionID string) (Entry, error) {
+	if s.repo == nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		current := s.memory[blueprintID][key]
+		if expectedVersion >= 0 && current.Version != expectedVersion {
+			return Entry{}, &ConflictError{BlueprintID: blueprintID, Key: key, Expected: expectedVersion, Actual: current.Version}
+		}
+
+		entry := Entry{Key: key, Value: value, Version: current.Version + 1, UpdatedBy: executionID, UpdatedAt: time.Now()}
+		if s.memory[blueprintID] == nil {
+			s.memory[blueprintID] = make(map[string]Entry)
+		}
+		s.memory[blueprintID][key] = entry
+		return entry, nil
+	}
+
+	state := &models.BlueprintState{BlueprintID: blueprintID, Key: key, Value: value, UpdatedBy: executionID}
+	if err := s.repo.Save(ctx, state, expectedVersion); err != nil {
+		return Entry{}, s.conflict(ctx, err, blueprintID, key, expectedVersion)
+	}
+	return toEntry(state), nil
+}
+
+// Delete removes a key if it's at the expected version, or AnyVersion
+func (s *Store) Delete(ctx context.Context, blueprintID, key string, expectedVersion int64) error {
+	if s.repo == nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		current := s.memory[blueprintID][key]
+		if expectedVersion > 0 && current.Version != expectedVersion {
+			return &ConflictError{BlueprintID: blueprintID, Key: key, Expected: expectedVersion, Actual: current.Version}
+		}
+		delete(s.memory[blueprintID], key)
+		return nil
+	}
+
+	if err := s.repo.Delete(ctx, blueprintID, key, expectedVersion); err != nil {
+		return s.conflict(ctx, err, blueprintID, key, expectedVersion)
+	}
+	return nil
+}
+
+// List returns all values of a blueprint, ordered by key
+func (s *Store) List(ctx context.Context, blueprintID string) ([]Entry, error) {
+	entries := make([]Entry, 0)
+
+	if s.repo == nil {
+		s.mutex.Lock()
+		for _, entry := range s.memory[blueprintID] {
+			entries = append(entries, entry)
+		}
+		s.mutex.Unlock()
+
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+		return entries, nil
+	}
+
+	states, err := s.repo.GetByBlueprintID(ctx, blueprintID)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		entries = append(entries, toEntry(state))
+	}
+	return entries, nil
+}
+
+// conflict turns a version conflict of the repository into a ConflictError with the
+// current version of the key
+func (s *Store) conflict(ctx context.Context, err error, blueprintID, key string, expectedVersion int64) error {
+	if !errors.Is(err, repository.ErrStateVersionConflict) {
+		return err
+	}
+
+	conflict := &ConflictError{BlueprintID: blueprintID, Key: key, Expected: expectedVersion}
+	if current, getErr := s.repo.Get(ctx, blueprintID, key); getErr == nil && current != nil {
+		conflict.Actual = current.Version
+	}
+	return conflict
+}
+
+func toEntry(state *models.BlueprintState) Entry {
+	return Entry{
+		Key:       state.Key,
+		Value:     state.Value,
+		Version:   state.Version,
+		UpdatedBy: state.UpdatedBy,
+		UpdatedAt: state.UpdatedAt,
+	}
+}
+
+// defaultStore is the store of the state nodes; it keeps values in memory until the server
+// replaces it with a persisted one
+var defaultStore = NewStore(nil)
+var defaultMutex sync.RWMutex
+
+// Default returns the store the state nodes use
+func Default() *Store {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultStore
+}
+
+// SetDefault replaces the store the state nodes use
+func SetDefault(store *Store) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultStore = store
+}
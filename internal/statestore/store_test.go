@@ -0,0 +1,68 @@
+package statestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreVersions(t *testing.T) {
+	store := NewStore(nil)
+	ctx := context.Background()
+
+	if _, exists, _ := store.Get(ctx, "bp-1", "counter"); exists {
+		t.Fatal("expected the key not to be set")
+	}
+
+	entry, err := store.Set(ctx, "bp-1", "counter", 1.0, 0, "exec-1")
+	if err != nil || entry.Version != 1 {
+		t.Fatalf("expected version 1, got %d (%v)", entry.Version, err)
+	}
+
+	entry, err = store.Set(ctx, "bp-1", "counter", 2.0, 1, "exec-2")
+	if err != nil || entry.Version != 2 {
+		t.Fatalf("expected version 2, got %d (%v)", entry.Version, err)
+	}
+
+	got, exists, _ := store.Get(ctx, "bp-1", "counter")
+	if !exists || got.Value != 2.0 || got.UpdatedBy != "exec-2" {
+		t.Errorf("expected the second value, got %+v", got)
+	}
+
+	// Keys are kept per blueprint
+	if _, exists, _ := store.Get(ctx, "bp-2", "counter"); exists {
+		t.Error("expected other blueprints not to see the key")
+	}
+}
+
+func TestStoreConflicts(t *testing.T) {
+	store := NewStore(nil)
+	ctx := context.Background()
+
+	store.Set(ctx, "bp-1", "cursor", "a", AnyVersion, "exec-1")
+	store.Set(ctx, "bp-1", "cursor", "b", AnyVersion, "exec-1")
+
+	// A writer that read version 1 lost the race
+	_, err := store.Set(ctx, "bp-1", "cursor", "c", 1, "exec-2")
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+		t.Fatalf("expected a conflict at version 2, got %v", err)
+	}
+
+	// Creating a key that is set conflicts too
+	if _, err := store.Set(ctx, "bp-1", "cursor", "c", 0, "exec-2"); !errors.As(err, &conflict) {
+		t.Errorf("expected a conflict creating a set key, got %v", err)
+	}
+
+	if err := store.Delete(ctx, "bp-1", "cursor", 1); !errors.As(err, &conflict) {
+		t.Errorf("expected a conflict deleting an old version, got %v", err)
+	}
+	if err := store.Delete(ctx, "bp-1", "cursor", 2); err != nil {
+		t.Fatalf("expected the current version to be deleted, got %v", err)
+	}
+
+	entries, _ := store.List(ctx, "bp-1")
+	if len(entries) != 0 {
+		t.Errorf("expected no state after deleting, got %+v", entries)
+	}
+}
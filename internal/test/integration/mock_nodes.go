@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/logic"
 	"webblueprint/internal/types"
 )
 
@@ -22,6 +23,7 @@ func RegisterMockNodes(runner *BlueprintTestRunner) {
 	runner.RegisterNodeType("while", NewWhileNodeFactory())
 	runner.RegisterNodeType("split", NewSplitNodeFactory())
 	runner.RegisterNodeType("merge", NewMergeNodeFactory())
+	runner.RegisterNodeType("switch", logic.NewSwitchNode)
 
 	// Register variable nodes (these are special processed by the engine)
 	// Register all possible variable node types that might be used in tests
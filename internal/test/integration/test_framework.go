@@ -102,7 +102,7 @@ func NewBlueprintTestRunner() *BlueprintTestRunner {
 	}
 
 	// Set up node execution recorder
-	execEngine.OnNodeExecutionHook = func(
+	execEngine.SetHooks(engine.Hooks{OnNodeExecution: func(
 		ctx context.Context,
 		executionID, nodeID, nodeType, execState string,
 		inputs, outputs map[string]interface{},
@@ -137,7 +137,7 @@ func NewBlueprintTestRunner() *BlueprintTestRunner {
 		}
 
 		return nil
-	}
+	}})
 
 	return runner
 }
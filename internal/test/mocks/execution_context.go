@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"webblueprint/internal/node"
 	"webblueprint/internal/types"
 )
@@ -20,6 +21,8 @@ type MockExecutionContext struct {
 	debugData     map[string]interface{}
 	executedPins  map[string]bool
 	activePins    map[string]bool
+	upstream      map[string]map[string]types.Value // Node ID -> pin ID -> value
+	ctx           context.Context                   // Context of the execution, background if unset
 }
 
 // NewMockExecutionContext creates a new mock execution context for testing
@@ -36,6 +39,7 @@ func NewMockExecutionContext(nodeID string, nodeType string, logger node.Logger)
 		debugData:    make(map[string]interface{}),
 		executedPins: make(map[string]bool),
 		activePins:   make(map[string]bool),
+		upstream:     make(map[string]map[string]types.Value),
 	}
 }
 
@@ -71,6 +75,20 @@ func (m *MockExecutionContext) SetOutputValue(pinID string, value types.Value) {
 	m.outputValues[pinID] = value
 }
 
+// GetUpstreamOutput returns an output value of an upstream node
+func (m *MockExecutionContext) GetUpstreamOutput(nodeID, pinID string) (types.Value, bool) {
+	val, exists := m.upstream[nodeID][pinID]
+	return val, exists
+}
+
+// SetUpstreamOutput sets an output value of an upstream node for testing
+func (m *MockExecutionContext) SetUpstreamOutput(nodeID, pinID string, value types.Value) {
+	if m.upstream[nodeID] == nil {
+		m.upstream[nodeID] = make(map[string]types.Value)
+	}
+	m.upstream[nodeID][pinID] = value
+}
+
 // GetOutputValue gets an output value for assertions
 func (m *MockExecutionContext) GetOutputValue(pinID string) (types.Value, bool) {
 	val, exists := m.outputValues[pinID]
@@ -149,3 +167,24 @@ func (m *MockExecutionContext) GetBlueprintID() string {
 func (m *MockExecutionContext) GetExecutionID() string {
 	return m.executionID
 }
+
+// SetContext sets the context of the execution, e.g. to cancel the calls of a node
+func (m *MockExecutionContext) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// Context returns the context of the execution
+func (m *MockExecutionContext) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// SaveData is a no-op for the mock context
+func (m *MockExecutionContext) SaveData(key string, value interface{}) {}
+
+// CreateLoopContext is not supported by the mock context
+func (m *MockExecutionContext) CreateLoopContext(loopVarName string, maxIterations int, startIndex float64) (node.LoopContext, bool) {
+	return nil, false
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+const (
+	// DefaultMaxBytesSize is the default size limit of Bytes values (16 MB)
+	DefaultMaxBytesSize = 16 << 20
+
+	// DebugBytesPreviewSize is the number of bytes kept when Bytes values are shown in debug views
+	DebugBytesPreviewSize = 256
+
+	// bytesEncodingKey marks base64 encoded bytes at API and WebSocket boundaries
+	bytesEncodingKey = "$bytes"
+)
+
+// MaxBytesSize is the size limit enforced on Bytes values
+var MaxBytesSize = DefaultMaxBytesSize
+
+// checkBytesSize validates a byte slice against the size limit
+func checkBytesSize(size int) error {
+	if MaxBytesSize > 0 && size > MaxBytesSize {
+		return fmt.Errorf("bytes value of %d bytes exceeds the limit of %d bytes", size, MaxBytesSize)
+	}
+	return nil
+}
+
+func validateBytes(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("expected bytes, got %T", value)
+	}
+	return checkBytesSize(len(b))
+}
+
+func convertToBytes(value interface{}) (interface{}, error) {
+	if value == nil {
+		return []byte{}, nil
+	}
+
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case *Stream:
+		data, err := readStream(v)
+		if err != nil {
+			return nil, err
+		}
+		b = data
+	default:
+		return nil, fmt.Errorf("cannot convert %T to bytes", value)
+	}
+
+	if err := checkBytesSize(len(b)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// AsBytes converts the value to a byte slice
+func (v Value) AsBytes() ([]byte, error) {
+	if v.RawValue == nil {
+		return []byte{}, nil
+	}
+
+	conv, err := convertToBytes(v.RawValue)
+	if err != nil {
+		return nil, err
+	}
+	return conv.([]byte), nil
+}
+
+// EncodeBytes encodes a byte slice for JSON boundaries (API and WebSocket)
+func EncodeBytes(b []byte) map[string]interface{} {
+	return map[string]interface{}{
+		bytesEncodingKey: base64.StdEncoding.EncodeToString(b),
+	}
+}
+
+// DecodeBytesValue decodes a value produced by EncodeBytes. Other values are returned unchanged.
+func DecodeBytesValue(value interface{}) (interface{}, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(obj) != 1 {
+		return value, nil
+	}
+
+	encoded, exists := obj[bytesEncodingKey]
+	if !exists {
+		return value, nil
+	}
+
+	str, ok := encoded.(string)
+	if !ok {
+		return nil, fmt.Errorf("bytes value must be a base64 string, got %T", encoded)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 bytes value: %w", err)
+	}
+	if err := checkBytesSize(len(b)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DebugValue prepares a value for debug views and events. Bytes values are
+// base64 encoded and truncated so large blobs are not stored or broadcast in full,
+// streams are replaced by a description.
+func DebugValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		preview := v
+		if len(preview) > DebugBytesPreviewSize {
+			preview = preview[:DebugBytesPreviewSize]
+		}
+		encoded := EncodeBytes(preview)
+		encoded["size"] = len(v)
+		encoded["truncated"] = len(v) > DebugBytesPreviewSize
+		return encoded
+	case *Stream:
+		return v.describe()
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = DebugValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = DebugValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
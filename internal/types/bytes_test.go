@@ -0,0 +1,57 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytesBoundaryEncoding(t *testing.T) {
+	data := []byte{0x00, 0xff, 0x10}
+
+	decoded, err := DecodeBytesValue(EncodeBytes(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, ok := decoded.([]byte); !ok || !bytes.Equal(b, data) {
+		t.Errorf("expected %v, got %v", data, decoded)
+	}
+
+	if _, err := DecodeBytesValue(map[string]interface{}{"$bytes": "not base64!"}); err == nil {
+		t.Error("expected invalid base64 to fail")
+	}
+
+	plain := map[string]interface{}{"name": "value"}
+	if decoded, _ := DecodeBytesValue(plain); decoded.(map[string]interface{})["name"] != "value" {
+		t.Error("expected other objects to be returned unchanged")
+	}
+}
+
+func TestBytesSizeLimit(t *testing.T) {
+	previous := MaxBytesSize
+	MaxBytesSize = 4
+	defer func() { MaxBytesSize = previous }()
+
+	if _, err := NewValue(PinTypes.Bytes, []byte("12345")).AsBytes(); err == nil {
+		t.Error("expected bytes over the limit to fail")
+	}
+	if _, err := Coerce(NewValue(PinTypes.Any, []byte("12345")), PinTypes.Bytes); err == nil {
+		t.Error("expected coercion over the limit to fail")
+	}
+	if _, err := Coerce(NewValue(PinTypes.Any, "1234"), PinTypes.Bytes); err != nil {
+		t.Errorf("expected string within the limit to coerce: %v", err)
+	}
+}
+
+func TestDebugValueTruncatesBytes(t *testing.T) {
+	data := make([]byte, DebugBytesPreviewSize*2)
+
+	debug, ok := DebugValue(map[string]interface{}{"body": data}).(map[string]interface{})
+	if !ok {
+		t.Fatal("expected object debug value")
+	}
+
+	body := debug["body"].(map[string]interface{})
+	if body["size"] != len(data) || body["truncated"] != true {
+		t.Errorf("unexpected debug value: %v", body)
+	}
+}
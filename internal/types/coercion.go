@@ -0,0 +1,361 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Compatibility describes whether values of one pin type can flow into another
+type Compatibility int
+
+const (
+	CompatibilityExact        Compatibility = iota // Same type, no conversion needed
+	CompatibilityImplicit                          // Safe conversion that never fails (number -> string)
+	CompatibilityLossy                             // Conversion that may fail or lose information (string -> number)
+	CompatibilityDynamic                           // Depends on the runtime value (any -> number)
+	CompatibilityIncompatible                      // No conversion exists
+)
+
+// String returns the name of the compatibility level
+func (c Compatibility) String() string {
+	switch c {
+	case CompatibilityExact:
+		return "exact"
+	case CompatibilityImplicit:
+		return "implicit"
+	case CompatibilityLossy:
+		return "lossy"
+	case CompatibilityDynamic:
+		return "dynamic"
+	default:
+		return "incompatible"
+	}
+}
+
+// coercionRules lists the allowed conversions between distinct data pin types.
+// Pairs that are not listed are incompatible.
+var coercionRules = map[string]map[string]Compatibility{
+	"string": {
+		"number":  CompatibilityLossy,
+		"boolean": CompatibilityLossy,
+		"object":  CompatibilityLossy, // Parsed as JSON
+		"array":   CompatibilityLossy, // Parsed as JSON
+		"bytes":   CompatibilityImplicit,
+		"stream":  CompatibilityImplicit,
+	},
+	"number": {
+		"string":  CompatibilityImplicit,
+		"boolean": CompatibilityLossy,
+	},
+	"boolean": {
+		"string": CompatibilityImplicit,
+		"number": CompatibilityImplicit,
+	},
+	"object": {
+		"string": CompatibilityImplicit, // Serialized as JSON
+	},
+	"array": {
+		"string": CompatibilityImplicit, // Serialized as JSON
+	},
+	"bytes": {
+		"string": CompatibilityLossy, // Fails for invalid UTF-8
+		"stream": CompatibilityImplicit,
+	},
+	"stream": {
+		"bytes":  CompatibilityLossy, // Reads the whole stream, subject to the size limit
+		"string": CompatibilityLossy,
+	},
+}
+
+// CheckCompatibility returns how values of the source type convert to the target type
+func CheckCompatibility(source, target *PinType) Compatibility {
+	if source == nil || target == nil {
+		return CompatibilityDynamic
+	}
+
+	if source.ID == target.ID {
+		return CompatibilityExact
+	}
+
+	// Execution pins only connect to execution pins
+	if source.ID == PinTypes.Execution.ID || target.ID == PinTypes.Execution.ID {
+		return CompatibilityIncompatible
+	}
+
+	if target.ID == PinTypes.Any.ID {
+		return CompatibilityExact
+	}
+
+	if source.ID == PinTypes.Any.ID {
+		return CompatibilityDynamic
+	}
+
+	if rules, exists := coercionRules[source.ID]; exists {
+		if compatibility, exists := rules[target.ID]; exists {
+			return compatibility
+		}
+	}
+
+	return CompatibilityIncompatible
+}
+
+// InferPinType returns the pin type matching a raw Go value
+func InferPinType(value interface{}) *PinType {
+	switch value.(type) {
+	case string:
+		return PinTypes.String
+	case float64, float32, int, int32, int64:
+		return PinTypes.Number
+	case bool:
+		return PinTypes.Boolean
+	case map[string]interface{}:
+		return PinTypes.Object
+	case []interface{}:
+		return PinTypes.Array
+	case []byte:
+		return PinTypes.Bytes
+	case *Stream:
+		return PinTypes.Stream
+	}
+
+	// Other Go types are inferred by their kind, e.g. []string as an array
+	switch kind := reflect.ValueOf(value); kind.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return PinTypes.Number
+	case reflect.String:
+		return PinTypes.String
+	case reflect.Bool:
+		return PinTypes.Boolean
+	case reflect.Map:
+		if kind.Type().Key().Kind() == reflect.String {
+			return PinTypes.Object
+		}
+	case reflect.Slice:
+		if kind.Type().Elem().Kind() == reflect.Uint8 {
+			return PinTypes.Bytes
+		}
+		return PinTypes.Array
+	case reflect.Array:
+		return PinTypes.Array
+	}
+	return PinTypes.Any
+}
+
+// CoercionError is returned when a value cannot be converted to a pin type
+type CoercionError struct {
+	From   string
+	To     string
+	Value  interface{}
+	Reason string
+}
+
+// Error implements the error interface
+func (e *CoercionError) Error() string {
+	value := fmt.Sprintf("%v", e.Value)
+	if len(value) > 64 {
+		value = value[:61] + "..."
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("cannot coerce %s value '%s' to %s: %s", e.From, value, e.To, e.Reason)
+	}
+	return fmt.Sprintf("cannot coerce %s value '%s' to %s", e.From, value, e.To)
+}
+
+// Coerce converts a value to the target pin type following the coercion rules.
+// Values are converted based on their runtime type, so values declared as Any
+// are handled like their concrete type. Null values stay null.
+func Coerce(value Value, target *PinType) (Value, error) {
+	if target == nil || target.ID == PinTypes.Any.ID || target.ID == PinTypes.Execution.ID {
+		return value, nil
+	}
+
+	raw := normalize(value.RawValue)
+	if raw == nil {
+		return NullValue(target), nil
+	}
+
+	source := InferPinType(raw)
+	if source.ID == target.ID {
+		if target.Validator != nil {
+			if err := target.Validator(raw); err != nil {
+				return value, &CoercionError{From: source.ID, To: target.ID, Value: value.RawValue, Reason: err.Error()}
+			}
+		}
+		return NewValue(target, raw), nil
+	}
+
+	if CheckCompatibility(source, target) == CompatibilityIncompatible {
+		return value, &CoercionError{From: source.ID, To: target.ID, Value: value.RawValue, Reason: "incompatible types"}
+	}
+
+	converted, err := convert(raw, target)
+	if err != nil {
+		return value, &CoercionError{From: source.ID, To: target.ID, Value: value.RawValue, Reason: err.Error()}
+	}
+
+	return NewValue(target, converted), nil
+}
+
+// normalize converts numeric values to float64, the canonical number representation, and
+// other Go values to the representations of their pin types, e.g. []string and
+// map[string]string to []interface{} and map[string]interface{}. Structs and pointers go
+// through JSON, as nodes would see them after a round trip through the API.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, float64, bool, map[string]interface{}, []interface{}, []byte, *Stream:
+		return value
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(reflected.Uint())
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float()
+	case reflect.String:
+		return reflected.String()
+	case reflect.Bool:
+		return reflected.Bool()
+	case reflect.Slice:
+		if reflected.Type().Elem().Kind() == reflect.Uint8 {
+			return reflected.Bytes()
+		}
+		return normalizeArray(reflected)
+	case reflect.Array:
+		return normalizeArray(reflected)
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		obj := make(map[string]interface{}, reflected.Len())
+		iter := reflected.MapRange()
+		for iter.Next() {
+			obj[iter.Key().String()] = normalize(iter.Value().Interface())
+		}
+		return obj
+	case reflect.Pointer, reflect.Interface:
+		if reflected.IsNil() {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// normalizeArray converts the elements of a slice or array of another Go type
+func normalizeArray(reflected reflect.Value) []interface{} {
+	arr := make([]interface{}, reflected.Len())
+	for i := range arr {
+		arr[i] = normalize(reflected.Index(i).Interface())
+	}
+	return arr
+}
+
+// convert performs a conversion that the coercion rules allow
+func convert(value interface{}, target *PinType) (interface{}, error) {
+	value = normalize(value)
+
+	switch target.ID {
+	case "string":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		case map[string]interface{}, []interface{}:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		case []byte:
+			if !utf8.Valid(v) {
+				return nil, fmt.Errorf("not valid UTF-8 text")
+			}
+			return string(v), nil
+		case *Stream:
+			data, err := readStream(v)
+			if err != nil {
+				return nil, err
+			}
+			if !utf8.Valid(data) {
+				return nil, fmt.Errorf("not valid UTF-8 text")
+			}
+			return string(data), nil
+		}
+	case "number":
+		switch v := value.(type) {
+		case bool:
+			if v {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		case string:
+			num, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("not a valid number")
+			}
+			return num, nil
+		}
+	case "boolean":
+		switch v := value.(type) {
+		case float64:
+			return v != 0, nil
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return false, nil
+			}
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("not a valid boolean")
+			}
+			return b, nil
+		}
+	case "object":
+		if v, ok := value.(string); ok {
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(v), &obj); err != nil {
+				return nil, fmt.Errorf("not a valid JSON object")
+			}
+			return obj, nil
+		}
+	case "array":
+		if v, ok := value.(string); ok {
+			var arr []interface{}
+			if err := json.Unmarshal([]byte(v), &arr); err != nil {
+				return nil, fmt.Errorf("not a valid JSON array")
+			}
+			return arr, nil
+		}
+	case "bytes":
+		return convertToBytes(value)
+	case "stream":
+		return convertToStream(value)
+	}
+
+	return nil, fmt.Errorf("no conversion available")
+}
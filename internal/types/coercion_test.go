@@ -0,0 +1,138 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		source   *PinType
+		target   *PinType
+		expected Compatibility
+	}{
+		{PinTypes.String, PinTypes.String, CompatibilityExact},
+		{PinTypes.Number, PinTypes.Any, CompatibilityExact},
+		{PinTypes.Number, PinTypes.String, CompatibilityImplicit},
+		{PinTypes.Boolean, PinTypes.Number, CompatibilityImplicit},
+		{PinTypes.String, PinTypes.Number, CompatibilityLossy},
+		{PinTypes.Any, PinTypes.Number, CompatibilityDynamic},
+		{PinTypes.Object, PinTypes.Array, CompatibilityIncompatible},
+		{PinTypes.Execution, PinTypes.String, CompatibilityIncompatible},
+		{PinTypes.Any, PinTypes.Execution, CompatibilityIncompatible},
+	}
+
+	for _, tt := range tests {
+		if got := CheckCompatibility(tt.source, tt.target); got != tt.expected {
+			t.Errorf("%s -> %s: expected %s, got %s", tt.source.ID, tt.target.ID, tt.expected, got)
+		}
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	value, err := Coerce(NewValue(PinTypes.Any, 42), PinTypes.String)
+	if err != nil || value.RawValue != "42" || value.Type != PinTypes.String {
+		t.Errorf("expected \"42\", got %v (%v)", value.RawValue, err)
+	}
+
+	value, err = Coerce(NewValue(PinTypes.Any, " 3.5 "), PinTypes.Number)
+	if err != nil || value.RawValue != 3.5 {
+		t.Errorf("expected 3.5, got %v (%v)", value.RawValue, err)
+	}
+
+	value, err = Coerce(NewValue(PinTypes.Any, `{"a":1}`), PinTypes.Object)
+	if err != nil {
+		t.Fatalf("expected JSON object to be parsed: %v", err)
+	}
+	if obj, _ := value.AsObject(); obj["a"] != float64(1) {
+		t.Errorf("expected parsed object, got %v", value.RawValue)
+	}
+
+	value, err = Coerce(NewValue(PinTypes.Any, nil), PinTypes.Boolean)
+	if err != nil || !value.IsNull() || value.Type != PinTypes.Boolean {
+		t.Errorf("expected a null boolean for nil input, got %v (%v)", value.RawValue, err)
+	}
+
+	_, err = Coerce(NewValue(PinTypes.Any, "abc"), PinTypes.Number)
+	var coercionErr *CoercionError
+	if !errors.As(err, &coercionErr) {
+		t.Fatalf("expected a coercion error, got %v", err)
+	}
+	if coercionErr.From != "string" || coercionErr.To != "number" {
+		t.Errorf("unexpected coercion error: %v", coercionErr)
+	}
+
+	if _, err := Coerce(NewValue(PinTypes.Any, []interface{}{1}), PinTypes.Object); err == nil {
+		t.Error("expected array -> object to fail")
+	}
+}
+
+func TestCoerceNormalizesGoValues(t *testing.T) {
+	type label string
+	type order struct {
+		ID    string `json:"id"`
+		Total int    `json:"total"`
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		target   *PinType
+		expected interface{}
+	}{
+		{"[]string to array", []string{"a", "b"}, PinTypes.Array, []interface{}{"a", "b"}},
+		{"[]string to string", []string{"a"}, PinTypes.String, `["a"]`},
+		{"[]int to array", []int{1, 2}, PinTypes.Array, []interface{}{float64(1), float64(2)}},
+		{"map[string]string to object", map[string]string{"a": "b"}, PinTypes.Object, map[string]interface{}{"a": "b"}},
+		{"map[string]int to string", map[string]int{"a": 1}, PinTypes.String, `{"a":1}`},
+		{"[]map[string]interface{} to array", []map[string]interface{}{{"a": 1}}, PinTypes.Array, []interface{}{map[string]interface{}{"a": 1}}},
+		{"nested slices", map[string][]string{"tags": {"x"}}, PinTypes.Object, map[string]interface{}{"tags": []interface{}{"x"}}},
+		{"uint8 to number", uint8(7), PinTypes.Number, float64(7)},
+		{"uint64 to string", uint64(42), PinTypes.String, "42"},
+		{"int16 to boolean", int16(1), PinTypes.Boolean, true},
+		{"named string to number", label("12"), PinTypes.Number, float64(12)},
+		{"struct to object", order{ID: "o-1", Total: 3}, PinTypes.Object, map[string]interface{}{"id": "o-1", "total": float64(3)}},
+		{"struct pointer to object", &order{ID: "o-2"}, PinTypes.Object, map[string]interface{}{"id": "o-2", "total": float64(0)}},
+		{"array to array", [2]bool{true, false}, PinTypes.Array, []interface{}{true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Coerce(NewValue(InferPinType(tt.value), tt.value), tt.target)
+			if err != nil {
+				t.Fatalf("expected the value to coerce, got %v", err)
+			}
+			if value.Type != tt.target || !reflect.DeepEqual(value.RawValue, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, value.RawValue)
+			}
+		})
+	}
+
+	if value, err := Coerce(NewValue(PinTypes.Any, (*order)(nil)), PinTypes.Object); err != nil || !value.IsNull() {
+		t.Errorf("expected a nil pointer to coerce to null, got %v (%v)", value.RawValue, err)
+	}
+	if _, err := Coerce(NewValue(PinTypes.Any, []string{"a"}), PinTypes.Object); err == nil {
+		t.Error("expected []string -> object to fail")
+	}
+}
+
+func TestInferPinTypeOfGoValues(t *testing.T) {
+	for _, tt := range []struct {
+		value    interface{}
+		expected *PinType
+	}{
+		{[]string{"a"}, PinTypes.Array},
+		{map[string]string{}, PinTypes.Object},
+		{[]map[string]interface{}{}, PinTypes.Array},
+		{uint8(1), PinTypes.Number},
+		{json.RawMessage(`{}`), PinTypes.Bytes},
+		{map[int]string{}, PinTypes.Any},
+		{func() {}, PinTypes.Any},
+	} {
+		if got := InferPinType(tt.value); got != tt.expected {
+			t.Errorf("%T: expected %s, got %s", tt.value, tt.expected.ID, got.ID)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package types
+
+// Values are shared rather than copied as they flow: every connection of an output pin,
+// the debug data and the data lineage hold the same objects and arrays, however large.
+// They are therefore immutable once set as an output. A node deriving a changed object or
+// array modifies a copy from MutableObject or MutableArray, leaving the shared value to
+// the other nodes reading it concurrently.
+
+// CopyObject returns a shallow copy of an object. Nested objects and arrays stay shared,
+// which is safe as they aren't modified either.
+func CopyObject(obj map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(obj))
+	for key, field := range obj {
+		copied[key] = field
+	}
+	return copied
+}
+
+// CopyArray returns a shallow copy of an array with capacity for extra more elements
+func CopyArray(arr []interface{}, extra int) []interface{} {
+	copied := make([]interface{}, len(arr), len(arr)+max(extra, 0))
+	copy(copied, arr)
+	return copied
+}
+
+// SliceArray returns the elements from to of an array without copying them. Its capacity
+// ends with it, so appending to the slice copies rather than overwriting the shared array.
+func SliceArray(arr []interface{}, from, to int) []interface{} {
+	return arr[from:to:to]
+}
+
+// MutableObject returns a copy of the object value that the node may modify
+func (v Value) MutableObject() (map[string]interface{}, error) {
+	obj, err := v.AsObject()
+	if err != nil {
+		return nil, err
+	}
+	return CopyObject(obj), nil
+}
+
+// MutableArray returns a copy of the array value that the node may modify, with capacity
+// for extra more elements
+func (v Value) MutableArray(extra int) ([]interface{}, error) {
+	arr, err := v.AsArray()
+	if err != nil {
+		return nil, err
+	}
+	return CopyArray(arr, extra), nil
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMutableObjectLeavesSharedValue(t *testing.T) {
+	shared := NewValue(PinTypes.Object, map[string]interface{}{"a": float64(1)})
+
+	obj, err := shared.MutableObject()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj["a"] = float64(2)
+	obj["b"] = true
+
+	original := shared.RawValue.(map[string]interface{})
+	if original["a"] != float64(1) || len(original) != 1 {
+		t.Errorf("expected the shared object to be unchanged, got %v", original)
+	}
+}
+
+func TestMutableArrayLeavesSharedValue(t *testing.T) {
+	shared := NewValue(PinTypes.Array, []interface{}{"a", "b"})
+
+	arr, err := shared.MutableArray(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	arr[0] = "changed"
+	arr = append(arr, "c")
+
+	original := shared.RawValue.([]interface{})
+	if original[0] != "a" || len(original) != 2 {
+		t.Errorf("expected the shared array to be unchanged, got %v", original)
+	}
+	if cap(arr) != 3 {
+		t.Errorf("expected the extra capacity to hold the appended element, got capacity %d", cap(arr))
+	}
+}
+
+func TestSliceArrayAppendDoesNotOverwrite(t *testing.T) {
+	shared := []interface{}{"a", "b", "c"}
+
+	prefix := SliceArray(shared, 0, 2)
+	prefix = append(prefix, "x")
+
+	if shared[2] != "c" {
+		t.Errorf("expected appending to the slice to leave the shared array alone, got %v", shared)
+	}
+	if len(prefix) != 3 || prefix[2] != "x" {
+		t.Errorf("unexpected slice after append: %v", prefix)
+	}
+}
+
+// Run with -race: readers of a shared value and nodes deriving copies from it must not
+// race, as the engine passes the same value to every connection of a pin
+func TestSharedValueConcurrentCopyOnWrite(t *testing.T) {
+	fields := make(map[string]interface{})
+	for i := 0; i < 100; i++ {
+		fields[fmt.Sprintf("field%d", i)] = float64(i)
+	}
+	shared := NewValue(PinTypes.Object, fields)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if worker%2 == 0 {
+					obj, _ := shared.AsObject()
+					for range obj {
+					}
+					continue
+				}
+				obj, _ := shared.MutableObject()
+				obj[fmt.Sprintf("field%d", i)] = "written"
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if fields["field0"] != float64(0) || len(fields) != 100 {
+		t.Error("expected the shared object to be unchanged")
+	}
+}
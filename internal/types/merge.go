@@ -0,0 +1,39 @@
+package types
+
+// MergeValues merges objects key by key, later values winning, and concatenates arrays. A
+// value that can't be merged with the ones before it replaces them.
+func MergeValues(values []Value) Value {
+	if len(values) == 0 {
+		return NullValue(PinTypes.Any)
+	}
+
+	merged := values[0]
+	for _, value := range values[1:] {
+		switch next := value.RawValue.(type) {
+		case map[string]interface{}:
+			current, ok := merged.RawValue.(map[string]interface{})
+			if !ok {
+				merged = value
+				continue
+			}
+			object := CopyObject(current)
+			for key, field := range next {
+				object[key] = field
+			}
+			merged = NewValue(PinTypes.Object, object)
+
+		case []interface{}:
+			current, ok := merged.RawValue.([]interface{})
+			if !ok {
+				merged = value
+				continue
+			}
+			array := append(CopyArray(current, len(next)), next...)
+			merged = NewValue(PinTypes.Array, array)
+
+		default:
+			merged = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,55 @@
+package types
+
+// Null semantics
+//
+// A value is null when its raw value is nil, e.g. when an upstream pin produced
+// nothing. Inputs are resolved the same way in every execution mode:
+//
+//  1. A non-null value received from a connection or the context is used as is.
+//  2. Otherwise the pin default is used, if the pin declares one.
+//  3. Otherwise a null value of the pin type is returned if the input was received
+//     (the upstream pin produced nothing), or the input is reported as missing.
+
+// NullValue creates a null value of the given pin type
+func NullValue(pinType *PinType) Value {
+	if pinType == nil {
+		pinType = PinTypes.Any
+	}
+	return Value{
+		Type:     pinType,
+		RawValue: nil,
+	}
+}
+
+// IsNull checks if the value holds nothing
+func (v Value) IsNull() bool {
+	return v.RawValue == nil
+}
+
+// ResolveInputValue applies the null semantics to an input value of a pin.
+// It returns false if the input is missing and the pin has no default.
+func ResolveInputValue(pin Pin, value Value, exists bool) (Value, bool) {
+	if exists && !value.IsNull() {
+		return value, true
+	}
+
+	if pin.Default != nil {
+		return NewValue(pin.Type, pin.Default), true
+	}
+
+	if exists {
+		return NullValue(pin.Type), true
+	}
+
+	return Value{}, false
+}
+
+// FindPin returns the pin with the given ID
+func FindPin(pins []Pin, pinID string) (Pin, bool) {
+	for _, pin := range pins {
+		if pin.ID == pinID {
+			return pin, true
+		}
+	}
+	return Pin{}, false
+}
@@ -0,0 +1,38 @@
+package types
+
+import "testing"
+
+func TestResolveInputValue(t *testing.T) {
+	pin := Pin{ID: "count", Type: PinTypes.Number, Default: float64(5)}
+
+	value, ok := ResolveInputValue(pin, NewValue(PinTypes.Number, float64(2)), true)
+	if !ok || value.RawValue != float64(2) {
+		t.Errorf("expected received value to be used, got %v", value.RawValue)
+	}
+
+	value, ok = ResolveInputValue(pin, NullValue(PinTypes.Any), true)
+	if !ok || value.RawValue != float64(5) {
+		t.Errorf("expected default for null input, got %v", value.RawValue)
+	}
+
+	pin.Default = nil
+	value, ok = ResolveInputValue(pin, NullValue(PinTypes.Any), true)
+	if !ok || !value.IsNull() || value.Type != PinTypes.Number {
+		t.Errorf("expected typed null value, got %v (%v)", value.RawValue, ok)
+	}
+
+	if _, ok := ResolveInputValue(pin, Value{}, false); ok {
+		t.Error("expected missing input without default to be reported as missing")
+	}
+}
+
+func TestNullValueConversions(t *testing.T) {
+	null := NullValue(PinTypes.Boolean)
+
+	if b, err := null.AsBoolean(); err != nil || b {
+		t.Errorf("expected false for null boolean, got %v (%v)", b, err)
+	}
+	if s, err := NullValue(PinTypes.String).AsString(); err != nil || s != "" {
+		t.Errorf("expected empty string for null string, got %q (%v)", s, err)
+	}
+}
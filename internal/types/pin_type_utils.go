@@ -15,6 +15,10 @@ func GetPinTypeByID(id string) (*PinType, bool) {
 		return PinTypes.Object, true
 	case "array":
 		return PinTypes.Array, true
+	case "bytes":
+		return PinTypes.Bytes, true
+	case "stream":
+		return PinTypes.Stream, true
 	case "any":
 		return PinTypes.Any, true
 	default:
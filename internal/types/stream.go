@@ -0,0 +1,176 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrStreamClosed is returned when reading a stream that has been closed
+	ErrStreamClosed = errors.New("stream is closed")
+
+	// ErrStreamClaimed is returned when a second node tries to consume a stream
+	ErrStreamClaimed = errors.New("stream is already consumed by another node")
+)
+
+// Stream is an io.Reader backed value for large payloads flowing between nodes.
+// A stream can be consumed by a single node; closing it is idempotent and the
+// engine closes all streams of an execution when the execution finishes.
+type Stream struct {
+	reader    io.Reader
+	closer    io.Closer
+	consumer  string
+	bytesRead int64
+	closed    bool
+	mutex     sync.Mutex
+}
+
+// NewStream creates a stream reading from r. If r is an io.Closer it is closed with the stream.
+func NewStream(r io.Reader) *Stream {
+	stream := &Stream{reader: r}
+	if closer, ok := r.(io.Closer); ok {
+		stream.closer = closer
+	}
+	return stream
+}
+
+// Read implements io.Reader. The lock isn't held while the underlying reader blocks, so
+// closing or describing the stream doesn't wait for a slow producer; closing unblocks a
+// pending read if the reader is an io.Closer.
+func (s *Stream) Read(p []byte) (int, error) {
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+	if closed {
+		return 0, ErrStreamClosed
+	}
+
+	n, err := s.reader.Read(p)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bytesRead += int64(n)
+	if s.closed && err == nil {
+		err = ErrStreamClosed
+	}
+	return n, err
+}
+
+// Close releases the underlying reader
+func (s *Stream) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// Closed checks if the stream has been closed
+func (s *Stream) Closed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+// BytesRead returns the number of bytes read from the stream so far
+func (s *Stream) BytesRead() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.bytesRead
+}
+
+// Claim marks the stream as consumed by a node. Claiming again from the same node
+// (e.g. in a loop) is allowed, any other node gets ErrStreamClaimed.
+func (s *Stream) Claim(consumerID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.consumer != "" && s.consumer != consumerID {
+		return fmt.Errorf("%w: %s", ErrStreamClaimed, s.consumer)
+	}
+	s.consumer = consumerID
+	return nil
+}
+
+// MarshalJSON describes the stream instead of its contents
+func (s *Stream) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.describe())
+}
+
+// describe returns a placeholder used wherever the stream would be serialized
+func (s *Stream) describe() map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return map[string]interface{}{
+		"$stream":   true,
+		"bytesRead": s.bytesRead,
+		"closed":    s.closed,
+	}
+}
+
+func validateStream(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if _, ok := value.(*Stream); !ok {
+		return fmt.Errorf("expected stream, got %T", value)
+	}
+	return nil
+}
+
+func convertToStream(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return NewStream(bytes.NewReader(nil)), nil
+	case *Stream:
+		return v, nil
+	case []byte:
+		return NewStream(bytes.NewReader(v)), nil
+	case string:
+		return NewStream(strings.NewReader(v)), nil
+	default:
+		return nil, fmt.Errorf("cannot convert %T to stream", value)
+	}
+}
+
+// readStream materializes a stream, enforcing the Bytes size limit
+func readStream(s *Stream) ([]byte, error) {
+	defer s.Close()
+
+	reader := io.Reader(s)
+	if MaxBytesSize > 0 {
+		reader = io.LimitReader(s, int64(MaxBytesSize)+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBytesSize(len(data)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// AsStream converts the value to a stream
+func (v Value) AsStream() (*Stream, error) {
+	conv, err := convertToStream(v.RawValue)
+	if err != nil {
+		return nil, err
+	}
+	return conv.(*Stream), nil
+}
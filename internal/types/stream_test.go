@@ -0,0 +1,108 @@
+package types
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestStreamLifetime(t *testing.T) {
+	source := &closeRecorder{Reader: strings.NewReader("payload")}
+	stream := NewStream(source)
+
+	data, err := io.ReadAll(stream)
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("expected payload, got %q (%v)", data, err)
+	}
+	if stream.BytesRead() != 7 {
+		t.Errorf("expected 7 bytes read, got %d", stream.BytesRead())
+	}
+
+	stream.Close()
+	stream.Close()
+	if source.closed != 1 {
+		t.Errorf("expected underlying reader to be closed once, got %d", source.closed)
+	}
+
+	if _, err := stream.Read(make([]byte, 1)); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("expected ErrStreamClosed, got %v", err)
+	}
+}
+
+func TestStreamClosesWhileReadBlocks(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	stream := NewStream(reader)
+
+	read := make(chan error, 1)
+	go func() {
+		_, err := stream.Read(make([]byte, 8))
+		read <- err
+	}()
+
+	// Describing and closing the stream don't wait for the producer
+	described := make(chan struct{})
+	go func() {
+		stream.MarshalJSON()
+		stream.Close()
+		close(described)
+	}()
+	select {
+	case <-described:
+	case <-time.After(time.Second):
+		t.Fatal("expected describing and closing not to wait for a blocked read")
+	}
+
+	select {
+	case err := <-read:
+		if err == nil {
+			t.Error("expected the blocked read to fail once the stream is closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected closing to unblock the read")
+	}
+	if !stream.Closed() {
+		t.Error("expected the stream to be closed")
+	}
+}
+
+func TestStreamClaim(t *testing.T) {
+	stream := NewStream(strings.NewReader(""))
+
+	if err := stream.Claim("node-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stream.Claim("node-a"); err != nil {
+		t.Errorf("expected the same node to claim again: %v", err)
+	}
+	if err := stream.Claim("node-b"); !errors.Is(err, ErrStreamClaimed) {
+		t.Errorf("expected ErrStreamClaimed, got %v", err)
+	}
+}
+
+func TestStreamCoercion(t *testing.T) {
+	value, err := Coerce(NewValue(PinTypes.Any, "text"), PinTypes.Stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	str, err := Coerce(value, PinTypes.String)
+	if err != nil || str.RawValue != "text" {
+		t.Errorf("expected stream to read back as text, got %v (%v)", str.RawValue, err)
+	}
+	if !value.RawValue.(*Stream).Closed() {
+		t.Error("expected materialized stream to be closed")
+	}
+}
@@ -115,14 +115,14 @@ func (v Value) AsNumber() (float64, error) {
 
 // AsBoolean converts the value to a boolean
 func (v Value) AsBoolean() (bool, error) {
-	if v.Type == PinTypes.Boolean {
-		return v.RawValue.(bool), nil
-	}
-
 	if v.RawValue == nil {
 		return false, nil
 	}
 
+	if v.Type == PinTypes.Boolean {
+		return v.RawValue.(bool), nil
+	}
+
 	if v.Type.Converter != nil {
 		conv, err := v.Type.Converter(v.RawValue)
 		if err != nil {
@@ -323,6 +323,8 @@ var PinTypes = struct {
 	Boolean   *PinType
 	Object    *PinType
 	Array     *PinType
+	Bytes     *PinType
+	Stream    *PinType
 	Any       *PinType
 }{
 	Execution: &PinType{
@@ -364,6 +366,20 @@ var PinTypes = struct {
 		Description: "List of values",
 		Validator:   validateArray,
 	},
+	Bytes: &PinType{
+		ID:          "bytes",
+		Name:        "Bytes",
+		Description: "Binary data such as file contents or raw HTTP bodies",
+		Validator:   validateBytes,
+		Converter:   convertToBytes,
+	},
+	Stream: &PinType{
+		ID:          "stream",
+		Name:        "Stream",
+		Description: "Large data read incrementally, consumed by a single node",
+		Validator:   validateStream,
+		Converter:   convertToStream,
+	},
 	Any: &PinType{
 		ID:          "any",
 		Name:        "Any",
@@ -413,17 +429,8 @@ func (p *Pin) ValidateConnection(targetPin *Pin) error {
 		return fmt.Errorf("cannot connect data pin to execution pin")
 	}
 
-	// Any type pins can connect to any data pin
-	if p.Type == PinTypes.Any || targetPin.Type == PinTypes.Any {
-		return nil
-	}
-
-	// Check if the types are compatible
-	if p.Type != targetPin.Type {
-		// Check if we can convert between the types
-		if targetPin.Type.Converter != nil {
-			return nil
-		}
+	// Check if the types are compatible according to the coercion rules
+	if CheckCompatibility(p.Type, targetPin.Type) == CompatibilityIncompatible {
 		return fmt.Errorf("incompatible pin types: %s -> %s", p.Type.Name, targetPin.Type.Name)
 	}
 
@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"sync"
+)
+
+// Metric identifies a resource counted per execution
+type Metric string
+
+const (
+	MetricNodesExecuted Metric = "nodesExecuted" // Number of node executions
+	MetricHTTPBytes     Metric = "httpBytes"     // Bytes sent and received by HTTP/gRPC nodes
+	MetricDBRowsRead    Metric = "dbRowsRead"    // Rows read from the database by nodes
+)
+
+// Counters holds the accumulated usage of a single execution
+type Counters map[Metric]int64
+
+// Tracker accumulates resource usage per execution ID
+type Tracker struct {
+	executions map[string]Counters
+	mutex      sync.Mutex
+}
+
+// NewTracker creates a new usage tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		executions: make(map[string]Counters),
+	}
+}
+
+// Record adds an amount to a metric of an execution
+func (t *Tracker) Record(executionID string, metric Metric, amount int64) {
+	if executionID == "" || amount == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	counters, exists := t.executions[executionID]
+	if !exists {
+		counters = make(Counters)
+		t.executions[executionID] = counters
+	}
+	counters[metric] += amount
+}
+
+// Get returns a copy of the usage recorded for an execution so far
+func (t *Tracker) Get(executionID string) Counters {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make(Counters)
+	for metric, value := range t.executions[executionID] {
+		result[metric] = value
+	}
+	return result
+}
+
+// Collect returns the usage of an execution and stops tracking it
+func (t *Tracker) Collect(executionID string) Counters {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	counters, exists := t.executions[executionID]
+	if !exists {
+		return make(Counters)
+	}
+	delete(t.executions, executionID)
+	return counters
+}
+
+// defaultTracker is the process-wide tracker used by nodes and the engine
+var defaultTracker = NewTracker()
+
+// Record adds an amount to a metric of an execution on the default tracker
+func Record(executionID string, metric Metric, amount int64) {
+	defaultTracker.Record(executionID, metric, amount)
+}
+
+// Get returns the usage recorded so far for an execution on the default tracker
+func Get(executionID string) Counters {
+	return defaultTracker.Get(executionID)
+}
+
+// Collect returns and clears the usage of an execution on the default tracker
+func Collect(executionID string) Counters {
+	return defaultTracker.Collect(executionID)
+}
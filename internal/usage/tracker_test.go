@@ -0,0 +1,30 @@
+package usage
+
+import "testing"
+
+func TestTrackerRecordAndCollect(t *testing.T) {
+	tracker := NewTracker()
+
+	tracker.Record("exec-1", MetricNodesExecuted, 1)
+	tracker.Record("exec-1", MetricNodesExecuted, 2)
+	tracker.Record("exec-1", MetricHTTPBytes, 512)
+	tracker.Record("exec-2", MetricDBRowsRead, 4)
+	tracker.Record("", MetricNodesExecuted, 1)
+
+	if got := tracker.Get("exec-1")[MetricNodesExecuted]; got != 3 {
+		t.Errorf("expected 3 nodes executed, got %d", got)
+	}
+
+	counters := tracker.Collect("exec-1")
+	if counters[MetricHTTPBytes] != 512 {
+		t.Errorf("expected 512 http bytes, got %d", counters[MetricHTTPBytes])
+	}
+
+	if len(tracker.Collect("exec-1")) != 0 {
+		t.Error("expected usage to be cleared after collect")
+	}
+
+	if tracker.Get("exec-2")[MetricDBRowsRead] != 4 {
+		t.Error("expected other executions to be unaffected")
+	}
+}
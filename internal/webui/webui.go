@@ -0,0 +1,271 @@
+// Package webui serves the built web editor, from a directory or embedded in the binary. It
+// falls back to index.html for the client-side routes of the single-page app, sets cache
+// headers suited to the hashed assets of the build and gzips text files. Pages are rebased
+// onto the path prefix the server is served under behind a reverse proxy.
+package webui
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"webblueprint/internal/proxy"
+)
+
+// indexFile is served for the routes of the app that aren't files
+const indexFile = "index.html"
+
+// Cache policies: the entry page is revalidated so new builds are picked up right away,
+// hashed assets never change and other files are cached for a while
+const (
+	cacheIndex  = "no-cache"
+	cacheHashed = "public, max-age=31536000, immutable"
+	cacheOther  = "public, max-age=3600"
+)
+
+// hashedDir holds the assets whose names carry a hash of their content
+const hashedDir = "assets/"
+
+// minGzipSize is the size below which compressing a file doesn't pay off
+const minGzipSize = 1024
+
+// compressible lists the extensions of the files worth compressing
+var compressible = map[string]bool{
+	".html": true,
+	".css":  true,
+	".js":   true,
+	".mjs":  true,
+	".json": true,
+	".map":  true,
+	".svg":  true,
+	".txt":  true,
+	".xml":  true,
+	".wasm": true,
+}
+
+// safePrefix matches the path prefixes pages may be rebased onto
+var safePrefix = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)*$`)
+
+// rootURL matches the root-relative URLs of src and href attributes
+var rootURL = regexp.MustCompile(`(\s(?:src|href)=["'])/([^/])`)
+
+// asset is a file read into memory, with its compressed form if it's worth compressing
+type asset struct {
+	modTime time.Time
+	size    int64
+	content []byte
+	gzipped []byte
+	etag    string
+}
+
+// Handler serves the files of a built single-page app
+type Handler struct {
+	fsys     fs.FS
+	reserved []string
+	assets   map[string]*asset
+	mutex    sync.Mutex
+}
+
+// NewHandler creates a handler serving the files of fsys. Requests under the reserved path
+// prefixes, such as "/api/", never fall back to index.html, so unknown API routes get 404.
+func NewHandler(fsys fs.FS, reserved ...string) *Handler {
+	return &Handler{
+		fsys:     fsys,
+		reserved: reserved,
+		assets:   make(map[string]*asset),
+	}
+}
+
+// ServeHTTP serves the file of the request path, or index.html for paths that look like
+// routes of the app
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	for _, prefix := range h.reserved {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	name, found := h.resolve(r.URL.Path)
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := h.load(name)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.serve(w, r, name, file)
+}
+
+// resolve maps a request path to the file serving it. Paths without an extension that
+// match no file are routes of the app and get index.html; missing files with an extension
+// aren't found, so a stale asset URL doesn't get a page instead.
+func (h *Handler) resolve(urlPath string) (string, bool) {
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = indexFile
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+	if err == nil && info.IsDir() {
+		name = path.Join(name, indexFile)
+		_, err = fs.Stat(h.fsys, name)
+	}
+	if err == nil {
+		return name, true
+	}
+	if path.Ext(name) != "" {
+		return "", false
+	}
+	if _, err := fs.Stat(h.fsys, indexFile); err != nil {
+		return "", false
+	}
+	return indexFile, true
+}
+
+// load returns a file, read again only if it changed since it was last served
+func (h *Handler) load(name string) (*asset, error) {
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mutex.Lock()
+	cached, exists := h.assets[name]
+	h.mutex.Unlock()
+	if exists && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached, nil
+	}
+
+	content, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	file := &asset{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		content: content,
+		etag:    hex.EncodeToString(sum[:8]),
+	}
+	if compressible[path.Ext(name)] && len(content) >= minGzipSize {
+		if file.gzipped, err = compress(content); err != nil {
+			return nil, err
+		}
+	}
+
+	h.mutex.Lock()
+	h.assets[name] = file
+	h.mutex.Unlock()
+	return file, nil
+}
+
+// serve writes a file with its cache headers, compressed if the client accepts gzip
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, name string, file *asset) {
+	header := w.Header()
+	header.Set("Cache-Control", cacheControl(name))
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	} else {
+		header.Set("Content-Type", http.DetectContentType(file.content))
+	}
+
+	content, gzipped, etag := file.content, file.gzipped, file.etag
+	if path.Ext(name) == ".html" {
+		content, etag = rebase(content, proxy.Prefix(r))
+		gzipped = nil
+		if len(content) >= minGzipSize && acceptsGzip(r) {
+			gzipped, _ = compress(content)
+		}
+	}
+	if len(content) >= minGzipSize && compressible[path.Ext(name)] {
+		header.Add("Vary", "Accept-Encoding")
+		if gzipped != nil && acceptsGzip(r) {
+			header.Set("Content-Encoding", "gzip")
+			content, etag = gzipped, etag+"-gzip"
+		}
+	}
+	header.Set("ETag", `"`+etag+`"`)
+
+	http.ServeContent(w, r, name, file.modTime, bytes.NewReader(content))
+}
+
+// rebase points a page at the path prefix the client sees the server under. It sets the base
+// URL of the page, which relative asset URLs resolve against on any route of the app, tells
+// the app the prefix as window.__WEBBLUEPRINT_BASE__ and prefixes root-relative src and href
+// URLs. It returns the page and its ETag.
+func rebase(page []byte, prefix string) ([]byte, string) {
+	if !safePrefix.MatchString(prefix) {
+		prefix = ""
+	}
+
+	html := string(page)
+	if prefix != "" {
+		html = rootURL.ReplaceAllString(html, "${1}"+prefix+"/${2}")
+	}
+	if head := strings.Index(strings.ToLower(html), "<head>"); head >= 0 {
+		encoded, _ := json.Marshal(prefix)
+		injected := `<base href="` + prefix + `/"><script>window.__WEBBLUEPRINT_BASE__ = ` + string(encoded) + `</script>`
+		head += len("<head>")
+		html = html[:head] + injected + html[head:]
+	}
+
+	sum := sha256.Sum256([]byte(html))
+	return []byte(html), hex.EncodeToString(sum[:8])
+}
+
+// cacheControl returns the cache policy of a file
+func cacheControl(name string) string {
+	switch {
+	case path.Ext(name) == ".html":
+		return cacheIndex
+	case strings.HasPrefix(name, hashedDir):
+		return cacheHashed
+	default:
+		return cacheOther
+	}
+}
+
+// acceptsGzip checks if the client accepts gzip-encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// compress gzips content at the best compression, as files are compressed once
+func compress(content []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buffer, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,112 @@
+package webui
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestHandler() *Handler {
+	return NewHandler(fstest.MapFS{
+		"index.html":           {Data: []byte("<html>app</html>")},
+		"assets/index-1a2b.js": {Data: []byte(strings.Repeat("console.log('app');\n", 100))},
+		"favicon.ico":          {Data: []byte{0, 0, 1, 0}},
+	}, "/api/")
+}
+
+func get(handler http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, value := range header {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestHandlerFallsBackToIndex(t *testing.T) {
+	handler := newTestHandler()
+
+	tests := map[string]int{
+		"/":                     http.StatusOK,
+		"/blueprints/42/edit":   http.StatusOK,
+		"/favicon.ico":          http.StatusOK,
+		"/assets/missing-9z.js": http.StatusNotFound,
+		"/api/unknown":          http.StatusNotFound,
+	}
+	for path, status := range tests {
+		response := get(handler, path, nil)
+		if response.Code != status {
+			t.Errorf("%s: expected %d, got %d", path, status, response.Code)
+		}
+	}
+
+	if body := get(handler, "/blueprints/42/edit", nil).Body.String(); body != "<html>app</html>" {
+		t.Errorf("expected the index page for a route of the app, got %q", body)
+	}
+}
+
+func TestHandlerCacheHeaders(t *testing.T) {
+	handler := newTestHandler()
+
+	tests := map[string]string{
+		"/":                     cacheIndex,
+		"/settings":             cacheIndex,
+		"/assets/index-1a2b.js": cacheHashed,
+		"/favicon.ico":          cacheOther,
+	}
+	for path, expected := range tests {
+		if cacheControl := get(handler, path, nil).Header().Get("Cache-Control"); cacheControl != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, cacheControl)
+		}
+	}
+
+	first := get(handler, "/", nil)
+	revalidated := get(handler, "/", map[string]string{"If-None-Match": first.Header().Get("ETag")})
+	if revalidated.Code != http.StatusNotModified {
+		t.Errorf("expected an unchanged index page to answer 304, got %d", revalidated.Code)
+	}
+}
+
+func TestHandlerGzip(t *testing.T) {
+	handler := newTestHandler()
+
+	response := get(handler, "/assets/index-1a2b.js", map[string]string{"Accept-Encoding": "br, gzip"})
+	if response.Header().Get("Content-Encoding") != "gzip" || response.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected a gzipped response, got %v", response.Header())
+	}
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		t.Fatalf("expected a gzip body, got %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	if !strings.HasPrefix(string(content), "console.log('app');") {
+		t.Errorf("expected the script, got %q", content)
+	}
+
+	if encoding := get(handler, "/assets/index-1a2b.js", nil).Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("expected no encoding without Accept-Encoding, got %q", encoding)
+	}
+	if encoding := get(handler, "/", map[string]string{"Accept-Encoding": "gzip"}).Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("expected small files not to be compressed, got %q", encoding)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	page := []byte(`<html><head><script type="module" src="/assets/index.js"></script><link href="//cdn.example/x.css"></head></html>`)
+
+	rebased, _ := rebase(page, "/webblueprint")
+	expected := `<html><head><base href="/webblueprint/"><script>window.__WEBBLUEPRINT_BASE__ = "/webblueprint"</script>` +
+		`<script type="module" src="/webblueprint/assets/index.js"></script><link href="//cdn.example/x.css"></head></html>`
+	if string(rebased) != expected {
+		t.Errorf("expected %s, got %s", expected, rebased)
+	}
+
+	if rebased, _ := rebase(page, `/"><script>`); !strings.Contains(string(rebased), `<base href="/">`) {
+		t.Errorf("expected an unsafe prefix to be ignored, got %s", rebased)
+	}
+}
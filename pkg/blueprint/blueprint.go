@@ -1,6 +1,10 @@
 package blueprint
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // BlueprintNode represents a node in a blueprint
 type BlueprintNode struct {
@@ -11,6 +15,104 @@ type BlueprintNode struct {
 	Data       map[string]interface{} `json:"data,omitempty"`
 }
 
+// CommentNodeType is the type of the nodes that document a blueprint on the canvas. Comment
+// nodes have no pins; validation and execution skip them.
+const CommentNodeType = "comment"
+
+// IsAnnotation reports whether the node only documents the blueprint and never executes
+func (n BlueprintNode) IsAnnotation() bool {
+	return n.Type == CommentNodeType
+}
+
+// NodeGuardKey is the node data key of the guard of an entry point, e.g.
+// "value.amount > 100". Triggers whose payload doesn't satisfy it are skipped.
+const NodeGuardKey = "guard"
+
+// GetGuard returns the guard expression the node is only triggered under, if any. Guards
+// read the payload of the trigger as the value.
+func (n BlueprintNode) GetGuard() string {
+	if n.Data == nil {
+		return ""
+	}
+	guard, _ := n.Data[NodeGuardKey].(string)
+	return strings.TrimSpace(guard)
+}
+
+// NodeLogLevelKey is the node data key of the least severe level the node logs at, e.g.
+// "warn". Nodes without one stream every entry and keep those of info and above.
+const NodeLogLevelKey = "logLevel"
+
+// Log levels of node logs, from the least to the most severe
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// LogLevelRank returns the rank of a log level, higher for more severe levels, or -1 if
+// the level is unknown
+func LogLevelRank(level string) int {
+	switch strings.ToLower(level) {
+	case LogLevelDebug:
+		return 0
+	case LogLevelInfo:
+		return 1
+	case LogLevelWarn, "warning":
+		return 2
+	case LogLevelError:
+		return 3
+	}
+	return -1
+}
+
+// GetLogLevel returns the least severe level the node logs at, if configured
+func (n BlueprintNode) GetLogLevel() string {
+	if n.Data == nil {
+		return ""
+	}
+	level, _ := n.Data[NodeLogLevelKey].(string)
+	return strings.ToLower(strings.TrimSpace(level))
+}
+
+// NodeFanInKey is the node data key of the fan-in policies of the node's input pins by
+// pin ID, e.g. {"value": "collect"}. A policy decides the value of a pin that several data
+// connections lead to; pins without one take the last value they received.
+const NodeFanInKey = "fanIn"
+
+// Fan-in policies of input pins. Values are ordered as their connections are in the
+// blueprint.
+const (
+	FanInLast    = "last"    // The value of the last connection that delivered one
+	FanInFirst   = "first"   // The value of the first connection that delivered one
+	FanInMerge   = "merge"   // Objects merged key by key and arrays concatenated
+	FanInCollect = "collect" // The values of every connection that delivered one, as an array
+)
+
+// IsFanInPolicy reports whether a fan-in policy is known
+func IsFanInPolicy(policy string) bool {
+	switch policy {
+	case FanInLast, FanInFirst, FanInMerge, FanInCollect:
+		return true
+	}
+	return false
+}
+
+// GetFanInPolicies returns the fan-in policies of the node's input pins by pin ID. Policies
+// that aren't strings are returned empty.
+func (n BlueprintNode) GetFanInPolicies() map[string]string {
+	policies := make(map[string]string)
+	if n.Data == nil {
+		return policies
+	}
+	configured, _ := n.Data[NodeFanInKey].(map[string]interface{})
+	for pinID, policy := range configured {
+		name, _ := policy.(string)
+		policies[pinID] = strings.ToLower(strings.TrimSpace(name))
+	}
+	return policies
+}
+
 type BlueprintNodeType struct {
 	Inputs     []NodePin      `json:"inputs"`
 	Outputs    []NodePin      `json:"outputs"`
@@ -58,6 +160,34 @@ type Connection struct {
 	Data           map[string]any `json:"data"`
 }
 
+// ConnectionTransformKey is the connection data key of the transform expression of a
+// data connection, e.g. "value * 100" or "value.items[0].name"
+const ConnectionTransformKey = "transform"
+
+// GetTransform returns the transform expression applied to the values flowing across
+// the connection, if any
+func (c Connection) GetTransform() string {
+	if c.ConnectionType != "data" || c.Data == nil {
+		return ""
+	}
+	transform, _ := c.Data[ConnectionTransformKey].(string)
+	return strings.TrimSpace(transform)
+}
+
+// ConnectionConditionKey is the connection data key of the condition of an execution
+// connection, e.g. "value.status == 200"
+const ConnectionConditionKey = "condition"
+
+// GetCondition returns the condition an execution connection is only followed under, if
+// any. Conditions read the outputs of the source node as the value.
+func (c Connection) GetCondition() string {
+	if c.ConnectionType != "execution" || c.Data == nil {
+		return ""
+	}
+	condition, _ := c.Data[ConnectionConditionKey].(string)
+	return strings.TrimSpace(condition)
+}
+
 // Variable represents a blueprint variable
 type Variable struct {
 	ID          string      `json:"id"`
@@ -80,19 +210,98 @@ type Function struct {
 	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
+// Size represents the size of an element on the canvas
+type Size struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Frame groups nodes on the canvas under a title, e.g. to document a part of a large
+// blueprint. Frames are layout only; validation and execution ignore them.
+type Frame struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Comment   string   `json:"comment,omitempty"`
+	Color     string   `json:"color,omitempty"`
+	Position  Position `json:"position"`
+	Size      Size     `json:"size"`
+	Collapsed bool     `json:"collapsed,omitempty"`
+	NodeIDs   []string `json:"nodeIds,omitempty"` // Nodes moved and collapsed with the frame
+}
+
 // Blueprint represents a complete blueprint definition
 type Blueprint struct {
-	ID            string            `json:"id"`
-	Name          string            `json:"name"`
-	Description   string            `json:"description,omitempty"`
-	Version       string            `json:"version"`
-	Nodes         []BlueprintNode   `json:"nodes"`
-	Functions     []Function        `json:"functions"`
-	Connections   []Connection      `json:"connections"`
-	Variables     []Variable        `json:"variables,omitempty"`
-	Metadata      map[string]string `json:"metadata,omitempty"`
-	Events        []EventDefinition `json:"events,omitempty"`        // User-defined event definitions
-	EventBindings []EventBinding    `json:"eventBindings,omitempty"` // User-defined event bindings
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	Version       string             `json:"version"`
+	Nodes         []BlueprintNode    `json:"nodes"`
+	Functions     []Function         `json:"functions"`
+	Connections   []Connection       `json:"connections"`
+	Variables     []Variable         `json:"variables,omitempty"`
+	Metadata      map[string]string  `json:"metadata,omitempty"`
+	Events        []EventDefinition  `json:"events,omitempty"`            // User-defined event definitions
+	EventBindings []EventBinding     `json:"eventBindings,omitempty"`     // User-defined event bindings
+	ErrorPolicy   *ErrorPolicy       `json:"errorPolicy,omitempty"`       // Handling of node errors without a catch flow
+	Concurrency   *ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"` // Handling of executions started while one runs
+	Budget        *ExecutionBudget   `json:"budget,omitempty"`            // Bounds of every execution
+	Frames        []Frame            `json:"frames,omitempty"`            // Groups of nodes on the canvas
+}
+
+// ErrorMode defines what happens to the rest of an execution when a node error isn't handled
+type ErrorMode string
+
+const (
+	ErrorModeFailFast ErrorMode = "fail-fast" // Stop the whole execution at the first unhandled error
+	ErrorModeContinue ErrorMode = "continue"  // Keep running the branches that don't depend on the failed node
+)
+
+// ErrorHandlerNodeType is the type of the entry node that receives unhandled node errors
+const ErrorHandlerNodeType = "event-on-error"
+
+// ErrorPolicy defines how an execution deals with node errors that no catch flow handles
+type ErrorPolicy struct {
+	Mode          ErrorMode `json:"mode,omitempty"`          // Defaults to fail-fast
+	HandlerNodeID string    `json:"handlerNodeId,omitempty"` // Defaults to the first error handler node
+}
+
+// ConcurrencyMode defines what happens to an execution started while another execution of
+// the same blueprint is running
+type ConcurrencyMode string
+
+const (
+	ConcurrencyAllowParallel   ConcurrencyMode = "allow-parallel"    // Executions run side by side
+	ConcurrencyQueue           ConcurrencyMode = "queue"             // Executions wait for the running one and start in order
+	ConcurrencyRejectIfRunning ConcurrencyMode = "reject-if-running" // Executions fail while one is running
+)
+
+// ConcurrencyPolicy defines whether executions of a blueprint may overlap, e.g. for
+// migrations that must never run twice at once
+type ConcurrencyPolicy struct {
+	Mode      ConcurrencyMode `json:"mode,omitempty"`      // Defaults to allow-parallel
+	MaxQueued int             `json:"maxQueued,omitempty"` // Queued executions beyond this many are rejected; 0 is unlimited
+}
+
+// ExecutionBudget bounds how long each execution of a blueprint may run and how many nodes
+// it may execute. Zero fields don't bound the execution.
+type ExecutionBudget struct {
+	MaxDuration       string `json:"maxDuration,omitempty"`       // Duration such as "30s" or "5m"
+	MaxNodeExecutions int    `json:"maxNodeExecutions,omitempty"` // Every run of a node counts, including loop iterations
+}
+
+// Duration parses the maximum duration of the budget, zero when it isn't set
+func (b ExecutionBudget) Duration() (time.Duration, error) {
+	if b.MaxDuration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(b.MaxDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maximum duration %q: %w", b.MaxDuration, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("maximum duration %q is negative", b.MaxDuration)
+	}
+	return duration, nil
 }
 
 // EventParameter defines a parameter for a custom event within a blueprint
@@ -111,6 +320,8 @@ type EventDefinition struct {
 	Description string           `json:"description,omitempty"` // Description of what the event does
 	Parameters  []EventParameter `json:"parameters,omitempty"`  // Parameters that can be passed with the event
 	Category    string           `json:"category,omitempty"`    // Category for organization (defaults to "Custom")
+	// How handlers bound to the event run: "parallel" (the default) or "serial"
+	DispatchMode string `json:"dispatchMode,omitempty"`
 }
 
 // EventBinding defines a binding between an event and a handler
@@ -121,6 +332,8 @@ type EventBinding struct {
 	HandlerType string `json:"handlerType"` // Type of handler (e.g., "node", "function")
 	Priority    int    `json:"priority"`    // Priority for execution order
 	Enabled     bool   `json:"enabled"`     // Whether the binding is enabled
+	// Delivery guarantee: "at-most-once" (the default) or "at-least-once"
+	Delivery string `json:"delivery,omitempty"`
 }
 
 // NewBlueprint creates a new empty blueprint
@@ -143,6 +356,22 @@ func (b *Blueprint) AddNode(node BlueprintNode) {
 	b.Nodes = append(b.Nodes, node)
 }
 
+// AddFrame adds a frame to the blueprint
+func (b *Blueprint) AddFrame(frame Frame) {
+	b.Frames = append(b.Frames, frame)
+}
+
+// ExecutableNodes returns the nodes of the blueprint without the annotations
+func (b *Blueprint) ExecutableNodes() []BlueprintNode {
+	nodes := make([]BlueprintNode, 0, len(b.Nodes))
+	for _, node := range b.Nodes {
+		if !node.IsAnnotation() {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
 // AddConnection adds a connection to the blueprint
 func (b *Blueprint) AddConnection(conn Connection) {
 	b.Connections = append(b.Connections, conn)
@@ -238,7 +467,7 @@ func (b *Blueprint) GetNodeOutputConnections(nodeID string) []Connection {
 	return connections
 }
 
-// RemoveNode removes a node and all its connections
+// RemoveNode removes a node, all its connections and its place in frames
 func (b *Blueprint) RemoveNode(nodeID string) {
 	// Remove connections first
 	newConnections := make([]Connection, 0)
@@ -257,6 +486,16 @@ func (b *Blueprint) RemoveNode(nodeID string) {
 		}
 	}
 	b.Nodes = newNodes
+
+	for i := range b.Frames {
+		nodeIDs := make([]string, 0, len(b.Frames[i].NodeIDs))
+		for _, id := range b.Frames[i].NodeIDs {
+			if id != nodeID {
+				nodeIDs = append(nodeIDs, id)
+			}
+		}
+		b.Frames[i].NodeIDs = nodeIDs
+	}
 }
 
 // RemoveConnection removes a connection
@@ -270,6 +509,48 @@ func (b *Blueprint) RemoveConnection(connID string) {
 	b.Connections = newConnections
 }
 
+// GetErrorPolicy returns the error policy of the blueprint with the defaults applied
+func (b *Blueprint) GetErrorPolicy() ErrorPolicy {
+	policy := ErrorPolicy{Mode: ErrorModeFailFast}
+	if b.ErrorPolicy != nil {
+		policy = *b.ErrorPolicy
+		if policy.Mode == "" {
+			policy.Mode = ErrorModeFailFast
+		}
+	}
+
+	if policy.HandlerNodeID == "" {
+		for _, node := range b.Nodes {
+			if node.Type == ErrorHandlerNodeType {
+				policy.HandlerNodeID = node.ID
+				break
+			}
+		}
+	}
+
+	return policy
+}
+
+// GetConcurrencyPolicy returns the concurrency policy of the blueprint with the defaults applied
+func (b *Blueprint) GetConcurrencyPolicy() ConcurrencyPolicy {
+	policy := ConcurrencyPolicy{Mode: ConcurrencyAllowParallel}
+	if b.Concurrency != nil {
+		policy = *b.Concurrency
+		if policy.Mode == "" {
+			policy.Mode = ConcurrencyAllowParallel
+		}
+	}
+	return policy
+}
+
+// GetBudget returns the execution budget of the blueprint, unbounded when it has none
+func (b *Blueprint) GetBudget() ExecutionBudget {
+	if b.Budget == nil {
+		return ExecutionBudget{}
+	}
+	return *b.Budget
+}
+
 // FindEntryPoints finds nodes that should be triggered first
 // (nodes with execution outputs but no execution inputs)
 func (b *Blueprint) FindEntryPoints() []string {
@@ -0,0 +1,216 @@
+package blueprint
+
+// Clone returns a deep copy of the blueprint, which shares no slices, maps or values of
+// properties and data with it
+func (b *Blueprint) Clone() *Blueprint {
+	clone := *b
+	clone.Nodes = cloneNodes(b.Nodes)
+	clone.Connections = cloneConnections(b.Connections)
+	clone.Variables = cloneVariables(b.Variables)
+	clone.Metadata = cloneStrings(b.Metadata)
+
+	if b.Functions != nil {
+		clone.Functions = make([]Function, len(b.Functions))
+		for i, function := range b.Functions {
+			function.NodeType = BlueprintNodeType{
+				Inputs:     clonePins(function.NodeType.Inputs),
+				Outputs:    clonePins(function.NodeType.Outputs),
+				Properties: cloneProperties(function.NodeType.Properties),
+			}
+			function.Nodes = cloneNodes(function.Nodes)
+			function.Connections = cloneConnections(function.Connections)
+			function.Variables = cloneVariables(function.Variables)
+			function.Metadata = cloneStrings(function.Metadata)
+			clone.Functions[i] = function
+		}
+	}
+
+	if b.Events != nil {
+		clone.Events = make([]EventDefinition, len(b.Events))
+		for i, definition := range b.Events {
+			if definition.Parameters != nil {
+				parameters := make([]EventParameter, len(definition.Parameters))
+				for j, parameter := range definition.Parameters {
+					parameter.Default = cloneValue(parameter.Default)
+					parameters[j] = parameter
+				}
+				definition.Parameters = parameters
+			}
+			clone.Events[i] = definition
+		}
+	}
+	if b.EventBindings != nil {
+		clone.EventBindings = append([]EventBinding{}, b.EventBindings...)
+	}
+
+	if b.ErrorPolicy != nil {
+		policy := *b.ErrorPolicy
+		clone.ErrorPolicy = &policy
+	}
+	if b.Concurrency != nil {
+		concurrency := *b.Concurrency
+		clone.Concurrency = &concurrency
+	}
+	if b.Budget != nil {
+		budget := *b.Budget
+		clone.Budget = &budget
+	}
+
+	if b.Frames != nil {
+		clone.Frames = make([]Frame, len(b.Frames))
+		for i, frame := range b.Frames {
+			if frame.NodeIDs != nil {
+				frame.NodeIDs = append([]string{}, frame.NodeIDs...)
+			}
+			clone.Frames[i] = frame
+		}
+	}
+	return &clone
+}
+
+// ReplaceNodeIDs gives the nodes of the blueprint the IDs newID returns, and updates the
+// connections, frames, error policy and event bindings referring to them. The nodes of
+// functions keep theirs, as they're only referred to within their function. It returns the
+// new ID of each node by its old ID.
+func (b *Blueprint) ReplaceNodeIDs(newID func() string) map[string]string {
+	ids := make(map[string]string, len(b.Nodes))
+	for i := range b.Nodes {
+		id := newID()
+		ids[b.Nodes[i].ID] = id
+		b.Nodes[i].ID = id
+	}
+	replace := func(id string) string {
+		if replaced, exists := ids[id]; exists {
+			return replaced
+		}
+		return id
+	}
+
+	for i := range b.Connections {
+		b.Connections[i].SourceNodeID = replace(b.Connections[i].SourceNodeID)
+		b.Connections[i].TargetNodeID = replace(b.Connections[i].TargetNodeID)
+	}
+	for i := range b.Frames {
+		for j, id := range b.Frames[i].NodeIDs {
+			b.Frames[i].NodeIDs[j] = replace(id)
+		}
+	}
+	if b.ErrorPolicy != nil {
+		b.ErrorPolicy.HandlerNodeID = replace(b.ErrorPolicy.HandlerNodeID)
+	}
+	for i := range b.EventBindings {
+		b.EventBindings[i].HandlerID = replace(b.EventBindings[i].HandlerID)
+	}
+	return ids
+}
+
+func cloneNodes(nodes []BlueprintNode) []BlueprintNode {
+	if nodes == nil {
+		return nil
+	}
+	clone := make([]BlueprintNode, len(nodes))
+	for i, node := range nodes {
+		node.Properties = cloneProperties(node.Properties)
+		node.Data = cloneMap(node.Data)
+		clone[i] = node
+	}
+	return clone
+}
+
+func cloneConnections(connections []Connection) []Connection {
+	if connections == nil {
+		return nil
+	}
+	clone := make([]Connection, len(connections))
+	for i, conn := range connections {
+		conn.Data = cloneMap(conn.Data)
+		clone[i] = conn
+	}
+	return clone
+}
+
+func cloneVariables(variables []Variable) []Variable {
+	if variables == nil {
+		return nil
+	}
+	clone := make([]Variable, len(variables))
+	for i, variable := range variables {
+		variable.Value = cloneValue(variable.Value)
+		clone[i] = variable
+	}
+	return clone
+}
+
+func cloneProperties(properties []NodeProperty) []NodeProperty {
+	if properties == nil {
+		return nil
+	}
+	clone := make([]NodeProperty, len(properties))
+	for i, property := range properties {
+		property.Value = cloneValue(property.Value)
+		if property.Type != nil {
+			pinType := *property.Type
+			property.Type = &pinType
+		}
+		clone[i] = property
+	}
+	return clone
+}
+
+func clonePins(pins []NodePin) []NodePin {
+	if pins == nil {
+		return nil
+	}
+	clone := make([]NodePin, len(pins))
+	for i, pin := range pins {
+		pin.Default = cloneValue(pin.Default)
+		if pin.Type != nil {
+			pinType := *pin.Type
+			pin.Type = &pinType
+		}
+		clone[i] = pin
+	}
+	return clone
+}
+
+func cloneStrings(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(values))
+	for key, value := range values {
+		clone[key] = value
+	}
+	return clone
+}
+
+func cloneMap(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		clone[key] = cloneValue(value)
+	}
+	return clone
+}
+
+// cloneValue copies the maps and slices of a value decoded from JSON; other values are
+// immutable and returned as they are
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	case map[string]string:
+		return cloneStrings(v)
+	case []string:
+		return append([]string{}, v...)
+	}
+	return value
+}
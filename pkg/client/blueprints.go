@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"webblueprint/pkg/blueprint"
+)
+
+// blueprintPage is a page of a blueprint list
+type blueprintPage struct {
+	Items      []*blueprint.Blueprint `json:"items"`
+	Total      int                    `json:"total"`
+	NextCursor string                 `json:"nextCursor"`
+}
+
+// ListBlueprints returns the blueprints of the client's workspace, or of the server without
+// a workspace, following the pages of the list
+func (c *Client) ListBlueprints(ctx context.Context) ([]*blueprint.Blueprint, error) {
+	query := url.Values{}
+	if c.workspaceID != "" {
+		query.Set("workspace", c.workspaceID)
+	}
+
+	var blueprints []*blueprint.Blueprint
+	for {
+		var page blueprintPage
+		if _, err := c.do(ctx, http.MethodGet, "/api/blueprints", query, nil, &page); err != nil {
+			return nil, err
+		}
+		blueprints = append(blueprints, page.Items...)
+		if page.NextCursor == "" {
+			return blueprints, nil
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+}
+
+// GetBlueprint returns the current version of a blueprint
+func (c *Client) GetBlueprint(ctx context.Context, id string) (*blueprint.Blueprint, error) {
+	var bp blueprint.Blueprint
+	if _, err := c.do(ctx, http.MethodGet, "/api/blueprints/"+url.PathEscape(id), nil, nil, &bp); err != nil {
+		return nil, err
+	}
+	return &bp, nil
+}
+
+// CreateBlueprint saves a new blueprint in the client's workspace and returns it as stored
+func (c *Client) CreateBlueprint(ctx context.Context, bp *blueprint.Blueprint) (*blueprint.Blueprint, error) {
+	query, err := c.workspaceQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	var created blueprint.Blueprint
+	if _, err := c.do(ctx, http.MethodPost, "/api/blueprints", query, bp, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateBlueprint saves a blueprint as its new version and returns it as stored
+func (c *Client) UpdateBlueprint(ctx context.Context, bp *blueprint.Blueprint) (*blueprint.Blueprint, error) {
+	query, err := c.workspaceQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	var updated blueprint.Blueprint
+	if _, err := c.do(ctx, http.MethodPut, "/api/blueprints/"+url.PathEscape(bp.ID), query, bp, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteBlueprint moves a blueprint to the trash of its workspace
+func (c *Client) DeleteBlueprint(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/api/blueprints/"+url.PathEscape(id), nil, nil, nil)
+	return err
+}
+
+// RestoreBlueprint restores a blueprint from the trash
+func (c *Client) RestoreBlueprint(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodPost, "/api/blueprints/"+url.PathEscape(id)+"/restore", nil, nil, nil)
+	return err
+}
+
+// DuplicateOptions configures the copy made by DuplicateBlueprint. Empty fields keep the
+// defaults of the server: the workspace of the original and "<name> (copy)".
+type DuplicateOptions struct {
+	WorkspaceID string `json:"workspaceId,omitempty"`
+	Name        string `json:"name,omitempty"`
+	NewNodeIDs  bool   `json:"newNodeIds,omitempty"`
+}
+
+// DuplicateBlueprint copies the current version of a blueprint into a new blueprint and
+// returns the ID of the copy
+func (c *Client) DuplicateBlueprint(ctx context.Context, id string, options DuplicateOptions) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/api/blueprints/"+url.PathEscape(id)+"/duplicate", nil, options, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,168 @@
+// Package client is a Go client of the WebBlueprint REST and WebSocket API, for services
+// that manage, run and follow blueprints without going through the editor.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the API of a WebBlueprint server. It is safe for concurrent use.
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	userID      string // Sent as X-User-ID, the server's default user if empty
+	workspaceID string // Workspace blueprints are listed in and saved to
+}
+
+// Option configures a client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with, e.g. for custom timeouts or
+// transports
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUser sets the user requests are made on behalf of
+func WithUser(userID string) Option {
+	return func(c *Client) {
+		c.userID = userID
+	}
+}
+
+// WithWorkspace sets the workspace blueprints are listed in and saved to
+func WithWorkspace(workspaceID string) Option {
+	return func(c *Client) {
+		c.workspaceID = workspaceID
+	}
+}
+
+// New creates a client of the server at baseURL, e.g. "http://localhost:8089"
+func New(baseURL string, options ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+
+	client := &Client{
+		baseURL:    parsed,
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client, nil
+}
+
+// APIError is an error response of the server
+type APIError struct {
+	StatusCode int
+	Code       string // Blueprint error code, such as V006
+	Message    string
+	Details    map[string]interface{} // Details of the error, such as the invalid field
+	RequestID  string                 // ID the server logged the request under
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("webblueprint: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	if e.RequestID != "" {
+		message += " (request " + e.RequestID + ")"
+	}
+	return message
+}
+
+// IsNotFound reports whether an error is a 404 response of the server
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// do sends a request with a JSON body, if any, and decodes the JSON response into out, if
+// it isn't nil. Responses with a status of 400 or above are returned as an APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	endpoint := *c.baseURL
+	endpoint.Path += path
+	endpoint.RawQuery = query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), reader)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.userID != "" {
+		request.Header.Set("X-User-ID", c.userID)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return response.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return response.StatusCode, newAPIError(response.StatusCode, data)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return response.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return response.StatusCode, nil
+}
+
+// newAPIError decodes an error response, which is either the JSON error envelope of the
+// server or plain text
+func newAPIError(status int, data []byte) *APIError {
+	apiErr := &APIError{StatusCode: status}
+	var body struct {
+		Code      string                 `json:"code"`
+		Message   string                 `json:"message"`
+		Details   map[string]interface{} `json:"details"`
+		RequestID string                 `json:"requestId"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.Message != "" {
+		apiErr.Code, apiErr.Message, apiErr.Details, apiErr.RequestID = body.Code, body.Message, body.Details, body.RequestID
+		return apiErr
+	}
+	apiErr.Message = strings.TrimSpace(string(data))
+	return apiErr
+}
+
+// workspaceQuery returns the query selecting the client's workspace, which requests saving
+// blueprints require
+func (c *Client) workspaceQuery() (url.Values, error) {
+	if c.workspaceID == "" {
+		return nil, fmt.Errorf("a workspace is required, see WithWorkspace")
+	}
+	return url.Values{"workspace": {c.workspaceID}}, nil
+}
@@ -0,0 +1,342 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestClient starts a server answering with handler and returns a client of it
+func newTestClient(t *testing.T, handler http.HandlerFunc, options ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL+"/", options...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+// writeJSON writes a JSON response with a status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestNewRejectsInvalidBaseURLs(t *testing.T) {
+	for _, baseURL := range []string{"localhost:8089", "ftp://localhost", "http://[::1"} {
+		if _, err := New(baseURL); err == nil {
+			t.Errorf("%s: expected the base URL to be rejected", baseURL)
+		}
+	}
+}
+
+func TestRequestsCarryTheUserAndWorkspace(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/blueprints" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if user := r.Header.Get("X-User-ID"); user != "user" {
+			t.Errorf("expected the user header, got %q", user)
+		}
+		if workspace := r.URL.Query().Get("workspace"); workspace != "workspace" {
+			t.Errorf("expected the workspace query, got %q", workspace)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("expected a JSON body, got %q", contentType)
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"id": "created", "name": "Created"})
+	}, WithUser("user"), WithWorkspace("workspace"))
+
+	created, err := c.CreateBlueprint(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected the blueprint to be created, got %v", err)
+	}
+	if created.ID != "created" {
+		t.Errorf("expected the stored blueprint to be decoded, got %q", created.ID)
+	}
+}
+
+func TestSavingBlueprintsRequiresAWorkspace(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no request, got %s %s", r.Method, r.URL.Path)
+	})
+
+	if _, err := c.CreateBlueprint(context.Background(), nil); err == nil {
+		t.Error("expected creating a blueprint without a workspace to fail")
+	}
+}
+
+func TestErrorResponsesAreDecoded(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		status   int
+		body     string
+		expected APIError
+	}{
+		{
+			name:   "error envelope",
+			status: http.StatusBadRequest,
+			body:   `{"code": "V006", "message": "invalid blueprint", "details": {"field": "nodes"}, "requestId": "request"}`,
+			expected: APIError{
+				StatusCode: http.StatusBadRequest,
+				Code:       "V006",
+				Message:    "invalid blueprint",
+				Details:    map[string]interface{}{"field": "nodes"},
+				RequestID:  "request",
+			},
+		},
+		{
+			name:     "plain text",
+			status:   http.StatusNotFound,
+			body:     "Blueprint not found\n",
+			expected: APIError{StatusCode: http.StatusNotFound, Message: "Blueprint not found"},
+		},
+		{
+			name:     "JSON without a message",
+			status:   http.StatusInternalServerError,
+			body:     `{"error": true}`,
+			expected: APIError{StatusCode: http.StatusInternalServerError, Message: `{"error": true}`},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			})
+
+			_, err := c.GetBlueprint(context.Background(), "blueprint")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if apiErr.StatusCode != test.expected.StatusCode || apiErr.Code != test.expected.Code ||
+				apiErr.Message != test.expected.Message || apiErr.RequestID != test.expected.RequestID ||
+				fmt.Sprint(apiErr.Details) != fmt.Sprint(test.expected.Details) {
+				t.Errorf("expected %+v, got %+v", test.expected, *apiErr)
+			}
+			if IsNotFound(err) != (test.status == http.StatusNotFound) {
+				t.Errorf("expected IsNotFound to be %v", test.status == http.StatusNotFound)
+			}
+			if test.expected.RequestID != "" && !strings.Contains(err.Error(), test.expected.RequestID) {
+				t.Errorf("expected the request ID in the error, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestListBlueprintsFollowsThePages(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch cursor := r.URL.Query().Get("cursor"); cursor {
+		case "":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"items": []map[string]string{{"id": "a"}, {"id": "b"}}, "nextCursor": "next"})
+		case "next":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"items": []map[string]string{{"id": "c"}}})
+		default:
+			t.Errorf("unexpected cursor %q", cursor)
+		}
+	})
+
+	blueprints, err := c.ListBlueprints(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list blueprints: %v", err)
+	}
+	var ids []string
+	for _, bp := range blueprints {
+		ids = append(ids, bp.ID)
+	}
+	if fmt.Sprint(ids) != "[a b c]" {
+		t.Errorf("expected the blueprints of both pages, got %v", ids)
+	}
+}
+
+func TestWaitForResultPollsUntilTheExecutionIsDone(t *testing.T) {
+	var polls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/executions/execution/result" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		if wait := r.URL.Query().Get("wait"); wait != resultPollWait.String() {
+			t.Errorf("expected to wait on the server, got %q", wait)
+		}
+		done := polls.Add(1) == 3
+		writeJSON(w, http.StatusOK, map[string]interface{}{"executionId": "execution", "status": "running", "done": done})
+	})
+
+	result, err := c.WaitForResult(context.Background(), "execution")
+	if err != nil || !result.Done {
+		t.Fatalf("expected the finished result, got %+v (%v)", result, err)
+	}
+	if polls.Load() != 3 {
+		t.Errorf("expected 3 polls, got %d", polls.Load())
+	}
+}
+
+func TestWaitForResultStopsWhenTheContextIsDone(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitForResult(ctx, "execution"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline of the context, got %v", err)
+	}
+}
+
+func TestRequestsTimeOutWithTheHTTPClient(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}, WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+
+	start := time.Now()
+	_, err := c.GetExecution(context.Background(), "execution")
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the timeout of the HTTP client, took %v", elapsed)
+	}
+}
+
+// streamServer is a WebSocket endpoint that sends each connection the welcome message with a
+// resume token and the messages of its turn, then drops it
+type streamServer struct {
+	t     *testing.T
+	turns [][]string // Messages sent on each connection, in order
+	mu    sync.Mutex
+	conns int
+	sent  chan map[string]interface{} // Messages the client sent
+}
+
+func (s *streamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if version := r.URL.Query().Get("version"); version != "1" {
+		s.t.Errorf("expected the protocol version of the client, got %q", version)
+	}
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		s.t.Errorf("failed to upgrade: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	turn := s.conns
+	s.conns++
+	s.mu.Unlock()
+
+	conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type": "execution.status", "payload": {"status": "connected", "resumeToken": "%d"}}`, turn*10)))
+	if turn >= len(s.turns) {
+		// Keep the last connection open until the client goes away
+		for {
+			var message map[string]interface{}
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			s.sent <- message
+		}
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(strings.Join(s.turns[turn], "\n")))
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	for {
+		var message map[string]interface{}
+		if err := conn.ReadJSON(&message); err != nil {
+			return
+		}
+		s.sent <- message
+	}
+}
+
+func TestSubscribeReconnectsAndResumes(t *testing.T) {
+	stream := &streamServer{t: t, sent: make(chan map[string]interface{}, 16), turns: [][]string{
+		{
+			`{"type": "node.start", "payload": {"executionId": "followed", "nodeId": "a"}, "seq": 1}`,
+			`{"type": "node.start", "payload": {"executionId": "other", "nodeId": "b"}, "seq": 2}`,
+		},
+		{
+			`{"type": "node.complete", "payload": {"executionId": "followed", "nodeId": "a"}, "seq": 3}`,
+		},
+	}}
+	c := newTestClient(t, stream.ServeHTTP)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan Message, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Subscribe(ctx, StreamOptions{ExecutionIDs: []string{"followed"}, ReconnectDelay: 10 * time.Millisecond}, func(message Message) {
+			received <- message
+		})
+	}()
+
+	var types []string
+	deadline := time.After(5 * time.Second)
+	for len(types) < 2 {
+		select {
+		case message := <-received:
+			if message.Type == MessageExecutionStatus {
+				continue
+			}
+			if executionID := message.ExecutionID(); executionID != "followed" {
+				t.Errorf("expected only the messages of the followed execution, got one of %q", executionID)
+			}
+			types = append(types, message.Type)
+		case <-deadline:
+			t.Fatalf("expected the messages of both connections, got %v", types)
+		}
+	}
+	if fmt.Sprint(types) != "[node.start node.complete]" {
+		t.Errorf("expected the messages in order, got %v", types)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Subscribe to return the error of the context, got %v", err)
+	}
+
+	var resume map[string]interface{}
+	select {
+	case resume = <-stream.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the client to resume after reconnecting")
+	}
+	payload, _ := resume["payload"].(map[string]interface{})
+	if resume["type"] != "events.resume" || payload["token"] != "2" || fmt.Sprint(payload["executionIds"]) != "[followed]" {
+		t.Errorf("expected to resume after the latest message received, got %v", resume)
+	}
+}
+
+func TestSubscribeGivesUpOnRejectedProtocols(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code": "W001", "message": "unsupported protocol version"}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := c.Subscribe(ctx, StreamOptions{}, func(Message) {})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "W001" {
+		t.Errorf("expected the rejection as an APIError, got %v", err)
+	}
+}
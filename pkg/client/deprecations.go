@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"webblueprint/pkg/models"
+)
+
+// DeprecationOptions are the parameters of a deprecation
+type DeprecationOptions struct {
+	VersionNumber int        `json:"versionNumber,omitempty"` // Version to deprecate, every version if 0
+	Message       string     `json:"message"`                 // What to use instead
+	SunsetAt      *time.Time `json:"sunsetAt,omitempty"`      // Executions are blocked from then on, never if nil
+}
+
+// Deprecate deprecates a blueprint, or one of its versions, replacing the deprecation it had
+func (c *Client) Deprecate(ctx context.Context, blueprintID string, options DeprecationOptions) (*models.BlueprintDeprecation, error) {
+	var deprecation models.BlueprintDeprecation
+	if _, err := c.do(ctx, http.MethodPost, "/api/blueprints/"+url.PathEscape(blueprintID)+"/deprecations", nil, options, &deprecation); err != nil {
+		return nil, err
+	}
+	return &deprecation, nil
+}
+
+// GetDeprecations returns the deprecations of a blueprint and of its versions
+func (c *Client) GetDeprecations(ctx context.Context, blueprintID string) ([]*models.BlueprintDeprecation, error) {
+	var deprecations []*models.BlueprintDeprecation
+	if _, err := c.do(ctx, http.MethodGet, "/api/blueprints/"+url.PathEscape(blueprintID)+"/deprecations", nil, nil, &deprecations); err != nil {
+		return nil, err
+	}
+	return deprecations, nil
+}
+
+// Undeprecate withdraws the deprecation of a version, or of every version if the number is 0
+func (c *Client) Undeprecate(ctx context.Context, blueprintID string, versionNumber int) error {
+	path := "/api/blueprints/" + url.PathEscape(blueprintID) + "/deprecations/" + strconv.Itoa(versionNumber)
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// DispatchEvent dispatches an event to the handlers bound to it, with the raw values of its
+// parameters. Failures of the handlers are returned as an APIError.
+func (c *Client) DispatchEvent(ctx context.Context, eventID string, params map[string]interface{}) error {
+	request := struct {
+		EventID string                 `json:"eventId"`
+		Params  map[string]interface{} `json:"params"`
+	}{
+		EventID: eventID,
+		Params:  params,
+	}
+
+	_, err := c.do(ctx, http.MethodPost, "/api/events/dispatch", nil, request, nil)
+	return err
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+	"webblueprint/pkg/models"
+)
+
+// resultPollWait is how long a single request for the result of an execution waits on the
+// server, which caps it at a minute
+const resultPollWait = 30 * time.Second
+
+// ExecuteOptions are the parameters of an execution
+type ExecuteOptions struct {
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	Priority  string                 `json:"priority,omitempty"` // "low", "normal" (the default) or "high"
+	Version   int                    `json:"version,omitempty"`  // Version number to run, the current version if 0
+	Profile   bool                   `json:"profile,omitempty"`  // Profile the node executions, see GET /api/executions/{id}/profile
+	// Run a deprecated blueprint past its sunset date
+	IgnoreSunset bool `json:"ignoreSunset,omitempty"`
+}
+
+// ExecutionStart is the response to starting an execution
+type ExecutionStart struct {
+	ExecutionID string `json:"executionId"` // Empty if the execution was skipped
+	Status      string `json:"status"`      // "running", or "skipped" when the entry point guards rejected the trigger
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Skipped reports whether the entry point guards of the blueprint skipped the execution
+func (s *ExecutionStart) Skipped() bool {
+	return s.Status == "skipped"
+}
+
+// ExecutionResult is the outcome of an execution
+type ExecutionResult struct {
+	ExecutionID string                 `json:"executionId"`
+	BlueprintID string                 `json:"blueprintId"`
+	Status      string                 `json:"status"`
+	Done        bool                   `json:"done"`              // Whether the execution finished
+	Outputs     map[string]interface{} `json:"outputs,omitempty"` // Outputs of each node, by node ID
+	Error       string                 `json:"error,omitempty"`
+	StartedAt   time.Time              `json:"startedAt"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+	DurationMs  *int32                 `json:"durationMs,omitempty"`
+	Budget      map[string]interface{} `json:"budget,omitempty"` // Consumption of the blueprint's execution budget
+}
+
+// Execute starts an execution of a blueprint. Executions the server turns away, e.g. because
+// of a quota or the concurrency policy of the blueprint, are returned as an APIError.
+func (c *Client) Execute(ctx context.Context, blueprintID string, options ExecuteOptions) (*ExecutionStart, error) {
+	var start ExecutionStart
+	if _, err := c.do(ctx, http.MethodPost, "/api/blueprints/"+url.PathEscape(blueprintID)+"/execute", nil, options, &start); err != nil {
+		return nil, err
+	}
+	return &start, nil
+}
+
+// CancelExecution cancels a running execution
+func (c *Client) CancelExecution(ctx context.Context, executionID string) error {
+	_, err := c.do(ctx, http.MethodPost, "/api/executions/"+url.PathEscape(executionID)+"/cancel", nil, nil, nil)
+	return err
+}
+
+// GetExecution returns the record of an execution
+func (c *Client) GetExecution(ctx context.Context, executionID string) (*models.Execution, error) {
+	var execution models.Execution
+	if _, err := c.do(ctx, http.MethodGet, "/api/executions/"+url.PathEscape(executionID), nil, nil, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// GetResult returns the outcome of an execution so far, without waiting for it to finish
+func (c *Client) GetResult(ctx context.Context, executionID string) (*ExecutionResult, error) {
+	return c.getResult(ctx, executionID, 0)
+}
+
+// WaitForResult waits until an execution finished and returns its outcome. It long-polls the
+// server, so it doesn't need the WebSocket; the wait ends early when ctx is done.
+func (c *Client) WaitForResult(ctx context.Context, executionID string) (*ExecutionResult, error) {
+	for {
+		result, err := c.getResult(ctx, executionID, resultPollWait)
+		if err != nil || result.Done {
+			return result, err
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+}
+
+// getResult requests the outcome of an execution, waiting on the server for it to finish
+func (c *Client) getResult(ctx context.Context, executionID string, wait time.Duration) (*ExecutionResult, error) {
+	query := url.Values{}
+	if wait > 0 {
+		query.Set("wait", wait.String())
+	}
+
+	var result ExecutionResult
+	if _, err := c.do(ctx, http.MethodGet, "/api/executions/"+url.PathEscape(executionID)+"/result", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
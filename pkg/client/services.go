@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ServiceOptions are the parameters of a blueprint running as a service. Options left out
+// use the defaults of the server.
+type ServiceOptions struct {
+	Variables         map[string]interface{} `json:"variables,omitempty"`
+	MaxRestarts       int                    `json:"maxRestarts,omitempty"`       // Restarts after consecutive failures, unlimited if negative
+	RestartDelayMs    int                    `json:"restartDelayMs,omitempty"`    // Delay before the first restart, doubled with every failure
+	MaxRestartDelayMs int                    `json:"maxRestartDelayMs,omitempty"` // Cap of the delay between restarts
+	IgnoreSunset      bool                   `json:"ignoreSunset,omitempty"`      // Run, and restart, a deprecated blueprint past its sunset date
+}
+
+// Service is the status of a blueprint running as a service
+type Service struct {
+	BlueprintID         string     `json:"blueprintId"`
+	Status              string     `json:"status"`                // "running", "restarting", "stopped", "completed" or "failed"
+	ExecutionID         string     `json:"executionId,omitempty"` // Current execution, or the last one once the service ended
+	InitiatedBy         string     `json:"initiatedBy"`
+	StartedAt           time.Time  `json:"startedAt"`
+	RunStartedAt        *time.Time `json:"runStartedAt,omitempty"`
+	StoppedAt           *time.Time `json:"stoppedAt,omitempty"`
+	Restarts            int        `json:"restarts"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	MaxRestarts         int        `json:"maxRestarts"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastFailureAt       *time.Time `json:"lastFailureAt,omitempty"`
+	NextRestartAt       *time.Time `json:"nextRestartAt,omitempty"`
+}
+
+// StartService runs a blueprint as a service until it's stopped, restarting its execution
+// when it fails
+func (c *Client) StartService(ctx context.Context, blueprintID string, options ServiceOptions) (*Service, error) {
+	var service Service
+	if _, err := c.do(ctx, http.MethodPost, "/api/blueprints/"+url.PathEscape(blueprintID)+"/service/start", nil, options, &service); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// StopService stops the service of a blueprint and returns its status once its execution ended
+func (c *Client) StopService(ctx context.Context, blueprintID string) (*Service, error) {
+	var service Service
+	if _, err := c.do(ctx, http.MethodPost, "/api/blueprints/"+url.PathEscape(blueprintID)+"/service/stop", nil, nil, &service); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// GetService returns the status of the service of a blueprint
+func (c *Client) GetService(ctx context.Context, blueprintID string) (*Service, error) {
+	var service Service
+	if _, err := c.do(ctx, http.MethodGet, "/api/blueprints/"+url.PathEscape(blueprintID)+"/service", nil, nil, &service); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// ListServices returns the services started on the server
+func (c *Client) ListServices(ctx context.Context) ([]Service, error) {
+	var response struct {
+		Services []Service `json:"services"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, "/api/services", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Services, nil
+}
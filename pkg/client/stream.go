@@ -0,0 +1,214 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ProtocolVersion is the version of the WebSocket protocol the client speaks
+const ProtocolVersion = 1
+
+// Types of the messages the server streams, see /api/ws/schema
+const (
+	MessageNodeStart       = "node.start"       // Node execution started
+	MessageNodeComplete    = "node.complete"    // Node execution completed
+	MessageNodeError       = "node.error"       // Node execution error
+	MessageNodeRecovered   = "node.recovered"   // Node error recovered
+	MessageDataFlow        = "data.flow"        // Data flowing between nodes
+	MessageDataBatch       = "data.batch"       // Latest data of a node's pins, batched
+	MessageDebugData       = "debug.data"       // Debug data available
+	MessageExecutionStart  = "execution.start"  // Blueprint execution started
+	MessageExecutionEnd    = "execution.end"    // Blueprint execution ended
+	MessageExecutionStatus = "execution.status" // Execution status update
+	MessageLog             = "log"              // Log message
+	MessageBatchStatus     = "batch.status"     // Batch execution progress
+	MessageResumed         = "events.resumed"   // Missed broadcast messages replayed after a reconnect
+	MessageProtocolError   = "protocol.error"   // A message of the client was rejected
+)
+
+// Default reconnect backoff of Subscribe
+const (
+	defaultReconnectDelay    = time.Second
+	defaultMaxReconnectDelay = 30 * time.Second
+)
+
+// Message is a message streamed by the server
+type Message struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+	Seq     uint64          `json:"seq,omitempty"` // Number of a broadcast message
+}
+
+// Decode decodes the payload of the message into v
+func (m Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+// ExecutionID returns the execution the message is about, if any
+func (m Message) ExecutionID() string {
+	var scoped struct {
+		ExecutionID string `json:"executionId"`
+	}
+	if err := json.Unmarshal(m.Payload, &scoped); err != nil {
+		return ""
+	}
+	return scoped.ExecutionID
+}
+
+// StreamOptions configure a subscription to the messages of the server
+type StreamOptions struct {
+	// ExecutionIDs limits the messages about executions to those of these executions.
+	// Messages that aren't about an execution are always delivered.
+	ExecutionIDs []string
+
+	// ReconnectDelay is the delay before reconnecting after the connection dropped, doubled
+	// for every failed attempt up to MaxReconnectDelay. They default to 1s and 30s.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+}
+
+// Subscribe delivers the messages the server streams to handler until ctx is done. When the
+// connection drops it reconnects with backoff and asks the server to replay the messages
+// missed in the meantime, which it reports with an events.resumed message whose complete
+// field is false if some of them were lost. Handler runs on the reading goroutine, so a
+// slow handler delays the messages after it.
+//
+// Subscribe returns the error of ctx, or an APIError if the server refused the connection
+// for good, e.g. because it doesn't speak the client's protocol version.
+func (c *Client) Subscribe(ctx context.Context, options StreamOptions, handler func(Message)) error {
+	if options.ReconnectDelay <= 0 {
+		options.ReconnectDelay = defaultReconnectDelay
+	}
+	if options.MaxReconnectDelay < options.ReconnectDelay {
+		options.MaxReconnectDelay = max(defaultMaxReconnectDelay, options.ReconnectDelay)
+	}
+
+	header := http.Header{}
+	if c.userID != "" {
+		header.Set("X-User-ID", c.userID)
+	}
+
+	subscription := &subscription{options: options, handler: handler}
+	for _, executionID := range options.ExecutionIDs {
+		if subscription.filter == nil {
+			subscription.filter = make(map[string]bool)
+		}
+		subscription.filter[executionID] = true
+	}
+
+	delay := options.ReconnectDelay
+	for {
+		conn, response, err := websocket.DefaultDialer.DialContext(ctx, c.streamURL(), header)
+		if err == nil {
+			delay = options.ReconnectDelay
+			subscription.read(ctx, conn)
+		} else if response != nil && response.StatusCode == http.StatusBadRequest {
+			data, _ := io.ReadAll(response.Body)
+			return newAPIError(response.StatusCode, data)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, options.MaxReconnectDelay)
+	}
+}
+
+// streamURL returns the WebSocket endpoint of the server
+func (c *Client) streamURL() string {
+	endpoint := *c.baseURL
+	endpoint.Scheme = "ws"
+	if c.baseURL.Scheme == "https" {
+		endpoint.Scheme = "wss"
+	}
+	endpoint.Path += "/ws"
+	endpoint.RawQuery = "version=" + strconv.Itoa(ProtocolVersion)
+	return endpoint.String()
+}
+
+// subscription is the state of Subscribe that outlives a connection
+type subscription struct {
+	options  StreamOptions
+	filter   map[string]bool // Executions whose messages are delivered, all if nil
+	handler  func(Message)
+	resumeAt uint64 // Sequence number of the latest broadcast message received
+	resuming bool   // Whether a token was received, so a reconnect can resume from it
+}
+
+// read delivers the messages of a connection until it drops or ctx is done
+func (s *subscription) read(ctx context.Context, conn *websocket.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// The server writes queued messages into one frame, separated by newlines
+		for _, line := range bytes.Split(data, []byte{'\n'}) {
+			var message Message
+			if err := json.Unmarshal(line, &message); err != nil {
+				continue
+			}
+			s.track(conn, message)
+
+			if executionID := message.ExecutionID(); s.filter != nil && executionID != "" && !s.filter[executionID] {
+				continue
+			}
+			s.handler(message)
+		}
+	}
+}
+
+// track keeps the resume token up to date. On the welcome message of a reconnect, it asks
+// the server for the messages missed while disconnected.
+func (s *subscription) track(conn *websocket.Conn, message Message) {
+	if message.Seq > 0 {
+		// Replayed messages are older than the live ones received meanwhile
+		s.resumeAt = max(s.resumeAt, message.Seq)
+		s.resuming = true
+		return
+	}
+
+	var welcome struct {
+		Status      string `json:"status"`
+		ResumeToken string `json:"resumeToken"`
+	}
+	if message.Type != MessageExecutionStatus || message.Decode(&welcome) != nil || welcome.Status != "connected" {
+		return
+	}
+
+	if s.resuming {
+		conn.WriteJSON(map[string]interface{}{
+			"type":    "events.resume",
+			"version": ProtocolVersion,
+			"payload": map[string]interface{}{
+				"token":        strconv.FormatUint(s.resumeAt, 10),
+				"executionIds": s.options.ExecutionIDs,
+			},
+		})
+	}
+	if seq, err := strconv.ParseUint(welcome.ResumeToken, 10, 64); err == nil {
+		s.resumeAt = seq
+		s.resuming = true
+	}
+}
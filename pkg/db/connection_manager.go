@@ -13,6 +13,7 @@ import (
 
 // Config holds database connection configuration
 type Config struct {
+	DSN      string // Connection string used instead of the fields below when set
 	Host     string
 	Port     int
 	User     string
@@ -50,10 +51,13 @@ func (cm *ConnectionManager) Initialize(config Config) error {
 		return nil // Already initialized
 	}
 
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
-	)
+	connStr := config.DSN
+	if connStr == "" {
+		connStr = fmt.Sprintf(
+			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
+		)
+	}
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -4,10 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"log"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -21,112 +19,244 @@ import (
 // MigrationManager handles database migrations and schema setup
 type MigrationManager struct {
 	db         *sql.DB
-	schemaPath string
+	migrations fs.FS
 }
 
-// NewMigrationManager creates a new migration manager
-func NewMigrationManager(db *sql.DB, schemaPath string) *MigrationManager {
+// Migration is a schema migration. Its version is the name of its up file without the
+// .sql extension; the matching .down.sql file reverts it.
+type Migration struct {
+	Version string
+	up      string
+	down    string
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Version    string     `json:"version"`
+	Applied    bool       `json:"applied"`
+	AppliedAt  *time.Time `json:"appliedAt,omitempty"`
+	Reversible bool       `json:"reversible"`
+}
+
+// NewMigrationManager creates a new migration manager reading migration files from
+// the given file system, typically EmbeddedMigrations
+func NewMigrationManager(db *sql.DB, migrations fs.FS) *MigrationManager {
 	return &MigrationManager{
 		db:         db,
-		schemaPath: schemaPath,
+		migrations: migrations,
 	}
 }
 
-// SetupSchema sets up the database schema
+// SetupSchema sets up the database schema by applying the pending migrations
 func (m *MigrationManager) SetupSchema(ctx context.Context) error {
-	// Create migrations table if it doesn't exist
-	_, err := m.db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+	_, err := m.Up(ctx)
+	return err
+}
+
+// Migrations returns the available migrations ordered by version
+func (m *MigrationManager) Migrations() ([]Migration, error) {
+	if m.migrations == nil {
+		return nil, fmt.Errorf("no migrations configured")
 	}
 
-	// Get list of applied migrations
-	appliedMigrations := make(map[string]bool)
-	rows, err := m.db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	files, err := fs.ReadDir(m.migrations, ".")
 	if err != nil {
-		return fmt.Errorf("failed to query migrations: %w", err)
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return fmt.Errorf("failed to scan migration version: %w", err)
+	// Migration files are named after their version, which orders them
+	migrations := make([]Migration, 0)
+	downFiles := make(map[string]string)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		if strings.HasSuffix(name, ".down.sql") {
+			downFiles[strings.TrimSuffix(name, ".down.sql")] = name
+			continue
 		}
-		appliedMigrations[version] = true
+		migrations = append(migrations, Migration{
+			Version: strings.TrimSuffix(name, ".sql"),
+			up:      name,
+		})
+	}
+
+	for i := range migrations {
+		migrations[i].down = downFiles[migrations[i].Version]
 	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
 
-	// Get list of migration files
-	files, err := os.ReadDir(m.schemaPath)
+	return migrations, nil
+}
+
+// Up applies the pending migrations in order and returns the versions it applied
+func (m *MigrationManager) Up(ctx context.Context) ([]string, error) {
+	migrations, err := m.Migrations()
 	if err != nil {
-		return fmt.Errorf("failed to read schema directory: %w", err)
+		return nil, err
 	}
 
-	// Sort migration files by name (which should follow a versioning convention)
-	migrationFiles := make([]string, 0)
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			migrationFiles = append(migrationFiles, file.Name())
-		}
+	appliedMigrations, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
 	}
-	sort.Strings(migrationFiles)
 
-	// Apply each migration
-	for _, filename := range migrationFiles {
-		version := strings.TrimSuffix(filename, filepath.Ext(filename))
-		if appliedMigrations[version] {
-			log.Printf("Skipping already applied migration: %s", version)
+	applied := make([]string, 0)
+	for _, migration := range migrations {
+		if _, exists := appliedMigrations[migration.Version]; exists {
 			continue
 		}
 
-		log.Printf("Applying migration: %s", version)
-
-		// Read the migration file
-		filePath := filepath.Join(m.schemaPath, filename)
-		content, err := ioutil.ReadFile(filePath)
+		log.Printf("Applying migration: %s", migration.Version)
+		err := m.run(ctx, migration.up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(
+				ctx,
+				"INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)",
+				migration.Version,
+				time.Now(),
+			)
+			return err
+		})
 		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", filePath, err)
+			return applied, fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
 		}
 
-		// Begin transaction
-		tx, err := m.db.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+		log.Printf("Successfully applied migration: %s", migration.Version)
+		applied = append(applied, migration.Version)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the given number of most recently applied migrations and returns the
+// versions it reverted
+func (m *MigrationManager) Down(ctx context.Context, steps int) ([]string, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedMigrations, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := make([]string, 0, steps)
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := migrations[i]
+		if _, exists := appliedMigrations[migration.Version]; !exists {
+			continue
+		}
+		if migration.down == "" {
+			return reverted, fmt.Errorf("migration %s has no down file", migration.Version)
 		}
 
-		// Execute the migration
-		_, err = tx.ExecContext(ctx, string(content))
+		log.Printf("Reverting migration: %s", migration.Version)
+		err := m.run(ctx, migration.down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			return err
+		})
 		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to execute migration %s: %w", version, err)
+			return reverted, fmt.Errorf("failed to revert migration %s: %w", migration.Version, err)
 		}
 
-		// Record the migration
-		_, err = tx.ExecContext(
-			ctx,
-			"INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)",
-			version,
-			time.Now(),
-		)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		log.Printf("Successfully reverted migration: %s", migration.Version)
+		reverted = append(reverted, migration.Version)
+	}
+
+	return reverted, nil
+}
+
+// Status returns the available migrations along with whether they have been applied
+func (m *MigrationManager) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedMigrations, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{
+			Version:    migration.Version,
+			Reversible: migration.down != "",
+		}
+		if appliedAt, exists := appliedMigrations[migration.Version]; exists {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
 		}
+		statuses = append(statuses, status)
+	}
 
-		// Commit the transaction
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit migration %s: %w", version, err)
+	return statuses, nil
+}
+
+// appliedMigrations returns the versions of the applied migrations and when they were
+// applied, creating the version tracking table if it doesn't exist
+func (m *MigrationManager) appliedMigrations(ctx context.Context) (map[string]time.Time, error) {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]time.Time)
+	for rows.Next() {
+		var version string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
 		}
+		applied[version] = appliedAt
+	}
 
-		log.Printf("Successfully applied migration: %s", version)
+	return applied, rows.Err()
+}
+
+// run executes a migration file and records the change of version in one transaction
+func (m *MigrationManager) run(ctx context.Context, filename string, record func(tx *sql.Tx) error) error {
+	content, err := fs.ReadFile(m.migrations, filename)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s: %w", filename, err)
 	}
 
-	return nil
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit()
 }
 
 // MigrateInMemoryBlueprints migrates blueprints from in-memory storage to the database
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// EmbeddedMigrations returns the migration files built into the binary
+func EmbeddedMigrations() fs.FS {
+	migrations, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		// The embedded directory always exists
+		panic(err)
+	}
+	return migrations
+}
@@ -0,0 +1,37 @@
+-- Reverts the initial WebBlueprint schema
+DROP VIEW IF EXISTS user_activity_summary;
+DROP VIEW IF EXISTS execution_summary;
+DROP VIEW IF EXISTS blueprint_summary;
+
+DROP TABLE IF EXISTS
+    marketplace_versions,
+    marketplace_items,
+    plugin_node_types,
+    plugins,
+    workspace_settings,
+    user_preferences,
+    blueprint_dependencies,
+    asset_references,
+    execution_logs,
+    execution_nodes,
+    executions,
+    node_types,
+    node_categories,
+    variables,
+    functions,
+    blueprint_versions,
+    blueprints,
+    assets,
+    workspace_members,
+    workspaces,
+    team_members,
+    teams,
+    users
+CASCADE;
+
+DROP FUNCTION IF EXISTS extract_blueprint_references();
+DROP FUNCTION IF EXISTS update_execution_completion();
+DROP FUNCTION IF EXISTS sync_blueprint_components();
+DROP FUNCTION IF EXISTS update_current_version();
+DROP FUNCTION IF EXISTS update_blueprint_counts();
+DROP FUNCTION IF EXISTS update_timestamp();
@@ -0,0 +1,3 @@
+-- Reverts the event system tables
+DROP TABLE IF EXISTS event_bindings;
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,9 @@
+-- Reverts the events of blueprint versions
+DROP INDEX IF EXISTS idx_blueprint_versions_event_bindings;
+DROP INDEX IF EXISTS idx_blueprint_versions_events;
+
+ALTER TABLE blueprint_versions
+DROP COLUMN IF EXISTS event_bindings;
+
+ALTER TABLE blueprint_versions
+DROP COLUMN IF EXISTS events;
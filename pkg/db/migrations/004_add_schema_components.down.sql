@@ -0,0 +1,3 @@
+-- Reverts the schema components table
+DROP TABLE IF EXISTS schema_components;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,9 @@
+-- Reverts the resource usage accounting columns; idx_executions_started_at belongs to the initial schema
+ALTER TABLE executions
+DROP COLUMN IF EXISTS db_rows_read;
+
+ALTER TABLE executions
+DROP COLUMN IF EXISTS http_bytes;
+
+ALTER TABLE executions
+DROP COLUMN IF EXISTS nodes_executed;
@@ -0,0 +1,16 @@
+-- Add resource usage accounting columns to executions
+ALTER TABLE executions
+ADD COLUMN nodes_executed INT NOT NULL DEFAULT 0;
+
+ALTER TABLE executions
+ADD COLUMN http_bytes BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE executions
+ADD COLUMN db_rows_read BIGINT NOT NULL DEFAULT 0;
+
+-- Daily usage summaries are grouped by start time
+CREATE INDEX IF NOT EXISTS idx_executions_started_at ON executions(started_at);
+
+COMMENT ON COLUMN executions.nodes_executed IS 'Number of node executions performed by this execution.';
+COMMENT ON COLUMN executions.http_bytes IS 'Bytes sent and received by HTTP and gRPC nodes.';
+COMMENT ON COLUMN executions.db_rows_read IS 'Rows read from the database by nodes.';
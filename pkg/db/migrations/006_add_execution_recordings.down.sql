@@ -0,0 +1,2 @@
+-- Reverts the execution recordings table
+DROP TABLE IF EXISTS execution_recordings;
@@ -0,0 +1,8 @@
+-- Recorded external inputs of executions, used to replay them deterministically
+CREATE TABLE IF NOT EXISTS execution_recordings (
+    execution_id UUID PRIMARY KEY REFERENCES executions(id) ON DELETE CASCADE,
+    recording JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE execution_recordings IS 'Results of nondeterministic nodes and dispatched events of an execution.';
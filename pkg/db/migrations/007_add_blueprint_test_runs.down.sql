@@ -0,0 +1,2 @@
+-- Reverts the blueprint test runs table
+DROP TABLE IF EXISTS blueprint_test_runs;
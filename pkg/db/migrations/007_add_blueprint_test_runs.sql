@@ -0,0 +1,15 @@
+-- Results and coverage of blueprint test runs
+CREATE TABLE IF NOT EXISTS blueprint_test_runs (
+    id UUID PRIMARY KEY,
+    blueprint_id UUID NOT NULL REFERENCES blueprints(id) ON DELETE CASCADE,
+    version_id UUID,
+    initiated_by UUID NOT NULL REFERENCES users(id),
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    passed BOOLEAN NOT NULL,
+    results JSONB NOT NULL,
+    coverage JSONB NOT NULL
+);
+
+CREATE INDEX idx_blueprint_test_runs_blueprint ON blueprint_test_runs(blueprint_id, started_at DESC);
+
+COMMENT ON TABLE blueprint_test_runs IS 'Suite results and node, connection and branch coverage of blueprint test runs.';
@@ -0,0 +1,3 @@
+-- Reverts the applied recoveries of executions
+ALTER TABLE executions
+DROP COLUMN IF EXISTS recoveries;
@@ -0,0 +1,5 @@
+-- Add the recovery strategies applied to node errors to executions
+ALTER TABLE executions
+ADD COLUMN recoveries JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN executions.recoveries IS 'Recovery strategies (retry, skip node, default value, alternate path) applied to node errors.';
@@ -0,0 +1,5 @@
+-- Reverts the trash of assets; trashed assets become live again
+DROP INDEX IF EXISTS idx_assets_deleted_at;
+
+ALTER TABLE assets
+DROP COLUMN IF EXISTS deleted_at;
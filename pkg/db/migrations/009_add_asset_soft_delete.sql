@@ -0,0 +1,7 @@
+-- Deleted blueprints stay in the trash until they're restored or purged
+ALTER TABLE assets
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_assets_deleted_at ON assets(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN assets.deleted_at IS 'Time the asset was moved to the trash; NULL for live assets.';
@@ -0,0 +1,2 @@
+-- Reverts the node timers table; pending debounce and throttle activations are lost
+DROP TABLE IF EXISTS node_timers;
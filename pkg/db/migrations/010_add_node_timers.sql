@@ -0,0 +1,14 @@
+-- Pending activations of debounce and throttle nodes, restored when the server restarts
+CREATE TABLE IF NOT EXISTS node_timers (
+    blueprint_id VARCHAR(255) NOT NULL,
+    node_id VARCHAR(255) NOT NULL,
+    execution_id VARCHAR(255) NOT NULL,
+    mode VARCHAR(20) NOT NULL,
+    fire_at TIMESTAMPTZ NOT NULL,
+    triggers INT NOT NULL DEFAULT 0,
+    inputs JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blueprint_id, node_id)
+);
+
+COMMENT ON TABLE node_timers IS 'Coalesced triggers of debounce and throttle nodes waiting for their timer to fire.';
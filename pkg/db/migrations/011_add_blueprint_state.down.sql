@@ -0,0 +1,2 @@
+-- Reverts the blueprint state table; the state of every blueprint is lost
+DROP TABLE IF EXISTS blueprint_state;
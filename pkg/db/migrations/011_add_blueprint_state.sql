@@ -0,0 +1,12 @@
+-- Durable key-value state of blueprints, kept across executions
+CREATE TABLE IF NOT EXISTS blueprint_state (
+    blueprint_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    value JSONB,
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blueprint_id, key)
+);
+
+COMMENT ON TABLE blueprint_state IS 'Values blueprints keep across executions, written with optimistic concurrency on their version.';
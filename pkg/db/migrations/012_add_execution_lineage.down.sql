@@ -0,0 +1,2 @@
+-- Reverts the execution lineage table
+DROP TABLE IF EXISTS execution_lineage;
@@ -0,0 +1,8 @@
+-- Recorded data flow of executions, used to trace the lineage of their output values
+CREATE TABLE IF NOT EXISTS execution_lineage (
+    execution_id UUID PRIMARY KEY REFERENCES executions(id) ON DELETE CASCADE,
+    data_flow JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE execution_lineage IS 'Values that flowed across the data connections of an execution, with the latest output values of its nodes.';
@@ -0,0 +1,3 @@
+-- Reverts the execution budget consumption of executions
+ALTER TABLE executions
+DROP COLUMN IF EXISTS budget;
@@ -0,0 +1,5 @@
+-- Add the consumption of the blueprint's execution budget to executions
+ALTER TABLE executions
+ADD COLUMN budget JSONB;
+
+COMMENT ON COLUMN executions.budget IS 'Execution budget of the blueprint (maximum duration, maximum node executions), how much of it the execution consumed and the limit it exceeded, if any.';
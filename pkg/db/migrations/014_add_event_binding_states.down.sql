@@ -0,0 +1,2 @@
+-- Reverts the runtime state of event bindings; bindings fall back to the state they declare
+DROP TABLE IF EXISTS event_binding_states;
@@ -0,0 +1,12 @@
+-- Enabled state of event bindings toggled at runtime, overriding the state the binding declares
+CREATE TABLE IF NOT EXISTS event_binding_states (
+    binding_id VARCHAR(255) PRIMARY KEY,
+    blueprint_id VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_binding_states_blueprint_id ON event_binding_states(blueprint_id);
+
+COMMENT ON TABLE event_binding_states IS 'Event bindings enabled or disabled without redeploying their blueprint, applied whenever the binding is bound.';
@@ -0,0 +1,2 @@
+-- Reverts the event deliveries table; pending retries and dead letters are lost
+DROP TABLE IF EXISTS event_deliveries;
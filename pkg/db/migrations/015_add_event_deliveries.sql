@@ -0,0 +1,21 @@
+-- Events dispatched to at-least-once bindings, kept until their handler acknowledges them
+CREATE TABLE IF NOT EXISTS event_deliveries (
+    id VARCHAR(255) PRIMARY KEY,
+    binding_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    blueprint_id VARCHAR(255) NOT NULL,
+    source_id VARCHAR(255),
+    execution_id VARCHAR(255),
+    parameters JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_status ON event_deliveries(status);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_blueprint_id ON event_deliveries(blueprint_id);
+
+COMMENT ON TABLE event_deliveries IS 'Unacknowledged deliveries of at-least-once event bindings, retried with backoff, and the dead letters whose attempts ran out.';
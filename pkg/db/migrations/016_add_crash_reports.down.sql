@@ -0,0 +1,2 @@
+-- Reverts the crash reports table; recorded crash reports are lost
+DROP TABLE IF EXISTS crash_reports;
@@ -0,0 +1,20 @@
+-- Panics recovered during executions, with the context they happened in
+CREATE TABLE IF NOT EXISTS crash_reports (
+    id VARCHAR(255) PRIMARY KEY,
+    source VARCHAR(50) NOT NULL,
+    execution_id VARCHAR(255),
+    blueprint_id VARCHAR(255),
+    blueprint_version VARCHAR(255),
+    node_id VARCHAR(255),
+    node_type VARCHAR(255),
+    message TEXT NOT NULL,
+    stack TEXT NOT NULL,
+    events JSONB NOT NULL DEFAULT '[]'::jsonb,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_crash_reports_execution_id ON crash_reports(execution_id);
+CREATE INDEX IF NOT EXISTS idx_crash_reports_blueprint_id ON crash_reports(blueprint_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_crash_reports_occurred_at ON crash_reports(occurred_at DESC);
+
+COMMENT ON TABLE crash_reports IS 'Panics of nodes, actors, event handlers and executions, with the execution, node, blueprint version and latest node executions they happened after.';
@@ -0,0 +1,3 @@
+-- Reverts the share link tables; every share link stops working
+DROP TABLE IF EXISTS share_link_executions;
+DROP TABLE IF EXISTS share_links;
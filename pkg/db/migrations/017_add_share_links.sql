@@ -0,0 +1,25 @@
+-- Tokens that let anyone view or run a blueprint without an account
+CREATE TABLE IF NOT EXISTS share_links (
+    id VARCHAR(255) PRIMARY KEY,
+    blueprint_id UUID NOT NULL REFERENCES blueprints(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    permission VARCHAR(20) NOT NULL,
+    runs_per_minute INT NOT NULL DEFAULT 0,
+    denied_node_types JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_blueprint_id ON share_links(blueprint_id);
+
+-- Executions started through share links, which their token may read
+CREATE TABLE IF NOT EXISTS share_link_executions (
+    share_link_id VARCHAR(255) NOT NULL REFERENCES share_links(id) ON DELETE CASCADE,
+    execution_id UUID NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
+    PRIMARY KEY (share_link_id, execution_id)
+);
+
+COMMENT ON TABLE share_links IS 'Public links to blueprints with a view or run permission, a rate limit and denied node types. Tokens are stored as SHA-256 hashes.';
+COMMENT ON TABLE share_link_executions IS 'Executions started through share links, readable with the token of their link.';
@@ -0,0 +1,4 @@
+-- Reverts the request IDs of executions
+DROP INDEX IF EXISTS idx_executions_request_id;
+ALTER TABLE executions
+DROP COLUMN IF EXISTS request_id;
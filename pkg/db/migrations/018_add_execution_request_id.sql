@@ -0,0 +1,7 @@
+-- Add the ID of the API request that started an execution
+ALTER TABLE executions
+ADD COLUMN request_id VARCHAR(128);
+
+CREATE INDEX IF NOT EXISTS idx_executions_request_id ON executions(request_id);
+
+COMMENT ON COLUMN executions.request_id IS 'X-Request-ID of the API request that started the execution, matching the requestId of its logs and error responses; NULL for executions started by events and schedules.';
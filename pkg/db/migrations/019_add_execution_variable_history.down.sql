@@ -0,0 +1,2 @@
+-- Reverts the execution variable history table
+DROP TABLE IF EXISTS execution_variable_history;
@@ -0,0 +1,8 @@
+-- Ordered variable changes of executions, used to rebuild their variables at any node
+CREATE TABLE IF NOT EXISTS execution_variable_history (
+    execution_id UUID PRIMARY KEY REFERENCES executions(id) ON DELETE CASCADE,
+    history JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE execution_variable_history IS 'Values the variables of an execution took, in order, with the position of each node execution among them.';
@@ -0,0 +1,2 @@
+-- Reverts the execution profiles table
+DROP TABLE IF EXISTS execution_profiles;
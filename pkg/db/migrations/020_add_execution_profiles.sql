@@ -0,0 +1,8 @@
+-- Node execution profiles of the executions started with profiling
+CREATE TABLE IF NOT EXISTS execution_profiles (
+    execution_id UUID PRIMARY KEY REFERENCES executions(id) ON DELETE CASCADE,
+    profile JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE execution_profiles IS 'Time and allocations of the node executions of a profiled execution, by stack of node types.';
@@ -0,0 +1,2 @@
+-- Reverts the deprecations; deprecated blueprints run without warnings again
+DROP TABLE IF EXISTS blueprint_deprecations;
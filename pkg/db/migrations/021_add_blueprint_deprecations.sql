@@ -0,0 +1,12 @@
+-- Deprecations of blueprints and of single versions, version 0 standing for every version
+CREATE TABLE IF NOT EXISTS blueprint_deprecations (
+    blueprint_id UUID NOT NULL REFERENCES blueprints(id) ON DELETE CASCADE,
+    version_number INT NOT NULL DEFAULT 0,
+    message TEXT NOT NULL,
+    sunset_at TIMESTAMPTZ,
+    deprecated_by UUID NOT NULL REFERENCES users(id),
+    deprecated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blueprint_id, version_number)
+);
+
+COMMENT ON TABLE blueprint_deprecations IS 'Deprecated blueprints and versions. Their executions log a warning until the sunset date and are blocked afterwards unless they override it.';
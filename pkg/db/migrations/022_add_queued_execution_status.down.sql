@@ -0,0 +1,11 @@
+-- Reverts the completion of queued executions; only running executions get a completion time
+CREATE OR REPLACE FUNCTION update_execution_completion()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status IN ('completed', 'failed', 'cancelled') AND OLD.status = 'running' THEN
+        NEW.completed_at = NOW();
+        NEW.duration_ms = EXTRACT(EPOCH FROM (NOW() - NEW.started_at)) * 1000;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,12 @@
+-- Executions are queued until a scheduler worker starts them, so queued executions that
+-- are cancelled get their completion time too
+CREATE OR REPLACE FUNCTION update_execution_completion()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status IN ('completed', 'failed', 'cancelled') AND OLD.status IN ('queued', 'running') THEN
+        NEW.completed_at = NOW();
+        NEW.duration_ms = EXTRACT(EPOCH FROM (NOW() - NEW.started_at)) * 1000;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
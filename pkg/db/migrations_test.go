@@ -0,0 +1,51 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrationsAreOrderedAndPairedWithDownFiles(t *testing.T) {
+	manager := NewMigrationManager(nil, fstest.MapFS{
+		"002_second.sql":      {Data: []byte("SELECT 2;")},
+		"001_first.sql":       {Data: []byte("SELECT 1;")},
+		"001_first.down.sql":  {Data: []byte("SELECT -1;")},
+		"README.md":           {Data: []byte("Not a migration")},
+		"nested/003_deep.sql": {Data: []byte("SELECT 3;")},
+	})
+
+	migrations, err := manager.Migrations()
+	if err != nil {
+		t.Fatalf("failed to list migrations: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %+v", migrations)
+	}
+	if migrations[0].Version != "001_first" || migrations[0].down != "001_first.down.sql" {
+		t.Errorf("expected the first migration to be reversible, got %+v", migrations[0])
+	}
+	if migrations[1].Version != "002_second" || migrations[1].down != "" {
+		t.Errorf("expected the second migration without a down file, got %+v", migrations[1])
+	}
+}
+
+func TestEmbeddedMigrationsAreReversible(t *testing.T) {
+	migrations, err := NewMigrationManager(nil, EmbeddedMigrations()).Migrations()
+	if err != nil {
+		t.Fatalf("failed to list the embedded migrations: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != "001_initial_schema" {
+		t.Fatalf("expected the embedded migrations to start with the initial schema, got %+v", migrations)
+	}
+	for _, migration := range migrations {
+		if migration.down == "" {
+			t.Errorf("migration %s has no down file", migration.Version)
+		}
+	}
+}
+
+func TestMigrationsWithoutFiles(t *testing.T) {
+	if _, err := NewMigrationManager(nil, nil).Migrations(); err == nil {
+		t.Error("expected a manager without migration files to fail")
+	}
+}
@@ -3,9 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
-	"path/filepath"
 	"time"
 	"webblueprint/internal/db"
 	"webblueprint/internal/registry"
@@ -15,31 +15,23 @@ import (
 	"webblueprint/pkg/repository/postgres"
 )
 
-// Setup initializes the database and sets up the connection
+// Setup initializes the database, applies the pending migrations and sets up the connection
 func Setup(ctx context.Context) (*ConnectionManager, repository.RepositoryFactory, error) {
-	// Get database configuration from environment variables
-	dbConfig := Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnvAsInt("DB_PORT", 5432),
-		User:     getEnv("DB_USER", "root"),
-		Password: getEnv("DB_PASSWORD", "root"),
-		DBName:   getEnv("DB_NAME", "webblueprint"),
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
-	}
+	return SetupWithConfig(ctx, ConfigFromEnv())
+}
 
-	// Initialize connection
-	connectionManager := GetConnectionManager()
-	err := connectionManager.Initialize(dbConfig)
+// SetupWithConfig is Setup with the given connection configuration instead of the environment
+func SetupWithConfig(ctx context.Context, dbConfig Config) (*ConnectionManager, repository.RepositoryFactory, error) {
+	connectionManager, err := ConnectWithConfig(dbConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to initialize database connection: %w", err)
+		return nil, nil, err
 	}
 
 	// Create repository factory
 	repoFactory := postgres.NewRepositoryFactory(connectionManager.GetDB())
 
-	// Set up schema if needed
-	schemaPath := getEnv("SCHEMA_PATH", "./migrations")
-	if err := setupSchema(ctx, connectionManager, schemaPath); err != nil {
+	// Bring the schema up to date
+	if err := setupSchema(ctx, connectionManager); err != nil {
 		connectionManager.Close()
 		return nil, nil, fmt.Errorf("failed to set up database schema: %w", err)
 	}
@@ -62,46 +54,49 @@ func Setup(ctx context.Context) (*ConnectionManager, repository.RepositoryFactor
 	return connectionManager, repoFactory, nil
 }
 
-// setupSchema sets up the database schema
-func setupSchema(ctx context.Context, cm *ConnectionManager, schemaPath string) error {
-	// Check if schema path exists
-	if _, err := os.Stat(schemaPath); os.IsNotExist(err) {
-		log.Printf("Schema path not found: %s", schemaPath)
-
-		// Try to find schema in alternative locations
-		alternatives := []string{
-			"./migrations",
-			"../migrations",
-			"../../migrations",
-			"./db/migrations",
-			"../db/migrations",
-		}
+// Connect initializes the database connection from the environment without touching the schema
+func Connect() (*ConnectionManager, error) {
+	return ConnectWithConfig(ConfigFromEnv())
+}
 
-		for _, alt := range alternatives {
-			if _, err := os.Stat(alt); !os.IsNotExist(err) {
-				log.Printf("Found schema at: %s", alt)
-				schemaPath = alt
-				break
-			}
-		}
+// ConfigFromEnv reads the database configuration from the environment. DATABASE_URL
+// takes precedence over the DB_* variables.
+func ConfigFromEnv() Config {
+	return Config{
+		DSN:      os.Getenv("DATABASE_URL"),
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnvAsInt("DB_PORT", 5432),
+		User:     getEnv("DB_USER", "root"),
+		Password: getEnv("DB_PASSWORD", "root"),
+		DBName:   getEnv("DB_NAME", "webblueprint"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
 	}
+}
 
-	// If still not found, create a basic schema
-	if _, err := os.Stat(schemaPath); os.IsNotExist(err) {
-		log.Printf("Creating basic schema directory at: %s", schemaPath)
-		if err := os.MkdirAll(schemaPath, 0755); err != nil {
-			return fmt.Errorf("failed to create schema directory: %w", err)
-		}
+// ConnectWithConfig initializes the database connection without touching the schema
+func ConnectWithConfig(dbConfig Config) (*ConnectionManager, error) {
+	// Initialize connection
+	connectionManager := GetConnectionManager()
+	if err := connectionManager.Initialize(dbConfig); err != nil {
+		return nil, fmt.Errorf("failed to initialize database connection: %w", err)
+	}
 
-		// Write a basic schema file with essential tables
-		basicSchemaPath := filepath.Join(schemaPath, "001_initial_schema.sql")
-		if err := writeBasicSchema(basicSchemaPath); err != nil {
-			return fmt.Errorf("failed to write basic schema: %w", err)
-		}
+	return connectionManager, nil
+}
+
+// MigrationsFromEnv returns the migration files to apply: the directory in SCHEMA_PATH
+// if set, otherwise the migrations built into the binary
+func MigrationsFromEnv() fs.FS {
+	if schemaPath := os.Getenv("SCHEMA_PATH"); schemaPath != "" {
+		log.Printf("Using migrations from: %s", schemaPath)
+		return os.DirFS(schemaPath)
 	}
+	return EmbeddedMigrations()
+}
 
-	// Run migrations
-	migrationManager := NewMigrationManager(cm.GetDB(), schemaPath)
+// setupSchema applies the pending migrations
+func setupSchema(ctx context.Context, cm *ConnectionManager) error {
+	migrationManager := NewMigrationManager(cm.GetDB(), MigrationsFromEnv())
 	if err := migrationManager.SetupSchema(ctx); err != nil {
 		return fmt.Errorf("failed to apply schema migrations: %w", err)
 	}
@@ -109,173 +104,12 @@ func setupSchema(ctx context.Context, cm *ConnectionManager, schemaPath string)
 	return nil
 }
 
-// writeBasicSchema writes a minimal schema file to get started
-func writeBasicSchema(path string) error {
-	// This is a simplified version of the schema that includes just enough
-	// to get the system running. In a real application, you'd have a more
-	// complete schema, possibly split into multiple migration files.
-
-	basicSchema := `-- Basic schema for WebBlueprint
-CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-
--- Users and Authentication
-CREATE TABLE users (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    username VARCHAR(255) NOT NULL UNIQUE,
-    email VARCHAR(255) NOT NULL UNIQUE,
-    password_hash VARCHAR(255) NOT NULL,
-    full_name VARCHAR(255),
-    avatar_url TEXT,
-    role VARCHAR(50) NOT NULL DEFAULT 'user',
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    last_login_at TIMESTAMPTZ,
-    is_active BOOLEAN NOT NULL DEFAULT TRUE
-);
-
--- Workspaces
-CREATE TABLE workspaces (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(255) NOT NULL,
-    description TEXT,
-    owner_type VARCHAR(10) NOT NULL CHECK (owner_type IN ('user', 'team')),
-    owner_id UUID NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    is_public BOOLEAN NOT NULL DEFAULT FALSE,
-    thumbnail_url TEXT,
-    metadata JSONB DEFAULT '{}'::jsonb
-    -- The validation of owner_id will be handled at the application level
-);
-
-CREATE TABLE workspace_members (
-    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
-    user_id UUID NOT NULL REFERENCES users(id),
-    role VARCHAR(50) NOT NULL DEFAULT 'editor',
-    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    PRIMARY KEY (workspace_id, user_id)
-);
-
--- Assets
-CREATE TABLE assets (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
-    name VARCHAR(255) NOT NULL,
-    description TEXT,
-    type VARCHAR(50) NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    created_by UUID NOT NULL REFERENCES users(id),
-    updated_by UUID NOT NULL REFERENCES users(id),
-    is_public BOOLEAN NOT NULL DEFAULT FALSE,
-    tags TEXT[] DEFAULT ARRAY[]::TEXT[],
-    thumbnail_url TEXT,
-    metadata JSONB DEFAULT '{}'::jsonb,
-    CONSTRAINT unique_asset_name_per_workspace_type UNIQUE (workspace_id, name, type)
-);
-
--- Blueprints
-CREATE TABLE blueprints (
-    id UUID PRIMARY KEY REFERENCES assets(id) ON DELETE CASCADE,
-    current_version_id UUID,
-    node_count INT NOT NULL DEFAULT 0,
-    connection_count INT NOT NULL DEFAULT 0,
-    entry_points TEXT[] DEFAULT ARRAY[]::TEXT[],
-    is_template BOOLEAN NOT NULL DEFAULT FALSE,
-    category VARCHAR(100)
-);
-
-CREATE TABLE blueprint_versions (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    blueprint_id UUID NOT NULL REFERENCES blueprints(id) ON DELETE CASCADE,
-    version_number INT NOT NULL,
-    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    created_by UUID NOT NULL REFERENCES users(id),
-    comment TEXT,
-    nodes JSONB NOT NULL DEFAULT '[]'::jsonb,
-    connections JSONB NOT NULL DEFAULT '[]'::jsonb,
-    variables JSONB NOT NULL DEFAULT '[]'::jsonb,
-    functions JSONB NOT NULL DEFAULT '[]'::jsonb,
-    metadata JSONB DEFAULT '{}'::jsonb,
-    CONSTRAINT unique_blueprint_version UNIQUE (blueprint_id, version_number)
-);
-
--- Add foreign key after both tables are created
-ALTER TABLE blueprints 
-    ADD CONSTRAINT fk_current_version 
-    FOREIGN KEY (current_version_id) 
-    REFERENCES blueprint_versions(id);
-
--- Executions
-CREATE TABLE executions (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    blueprint_id UUID NOT NULL REFERENCES blueprints(id),
-    version_id UUID REFERENCES blueprint_versions(id),
-    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    completed_at TIMESTAMPTZ,
-    status VARCHAR(50) NOT NULL DEFAULT 'running',
-    initiated_by UUID NOT NULL REFERENCES users(id),
-    execution_mode VARCHAR(50) NOT NULL DEFAULT 'standard',
-    initial_variables JSONB DEFAULT '{}'::jsonb,
-    result JSONB,
-    error TEXT,
-    duration_ms INT
-);
-
-CREATE TABLE execution_nodes (
-    execution_id UUID NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
-    node_id TEXT NOT NULL,
-    node_type VARCHAR(255) NOT NULL,
-    started_at TIMESTAMPTZ,
-    completed_at TIMESTAMPTZ,
-    status VARCHAR(50) NOT NULL DEFAULT 'pending',
-    inputs JSONB DEFAULT '{}'::jsonb,
-    outputs JSONB DEFAULT '{}'::jsonb,
-    error TEXT,
-    duration_ms INT,
-    debug_data JSONB,
-    PRIMARY KEY (execution_id, node_id)
-);
-
-CREATE TABLE execution_logs (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    execution_id UUID NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
-    node_id TEXT,
-    log_level VARCHAR(20) NOT NULL,
-    message TEXT NOT NULL,
-    details JSONB,
-    timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW()
-);
-
--- References
-CREATE TABLE asset_references (
-    source_asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
-    target_asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
-    reference_type VARCHAR(50) NOT NULL,
-    reference_count INT NOT NULL DEFAULT 1,
-    details JSONB,
-    PRIMARY KEY (source_asset_id, target_asset_id, reference_type)
-);
-
--- Indexes
-CREATE INDEX idx_assets_workspace ON assets(workspace_id);
-CREATE INDEX idx_assets_type ON assets(type);
-CREATE INDEX idx_assets_created_by ON assets(created_by);
-CREATE INDEX idx_blueprint_versions_blueprint ON blueprint_versions(blueprint_id);
-CREATE INDEX idx_executions_blueprint ON executions(blueprint_id);
-CREATE INDEX idx_execution_logs_execution ON execution_logs(execution_id);
-CREATE INDEX idx_asset_references_target ON asset_references(target_asset_id);
-`
-
-	return os.WriteFile(path, []byte(basicSchema), 0644)
-}
-
 // setupDefaultUser creates a default user if no users exist
 func setupDefaultUser(ctx context.Context, cm *ConnectionManager, repoFactory repository.RepositoryFactory) error {
 	userRepo := repoFactory.GetUserRepository()
 
 	// Create migration manager
-	migrationManager := NewMigrationManager(cm.GetDB(), "")
+	migrationManager := NewMigrationManager(cm.GetDB(), nil)
 
 	// Try to create or get default user
 	userID, err := migrationManager.CreateDefaultUser(ctx, userRepo)
@@ -319,7 +153,7 @@ func migrateInMemoryData(ctx context.Context, cm *ConnectionManager, repoFactory
 	workspaceID := ""
 
 	// Create migration manager
-	migrationManager := NewMigrationManager(cm.GetDB(), "")
+	migrationManager := NewMigrationManager(cm.GetDB(), nil)
 
 	// Migrate blueprints
 	inMemoryBlueprints := make(map[string]*blueprint.Blueprint)
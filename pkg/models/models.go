@@ -203,6 +203,7 @@ type Asset struct {
 	Tags         StringArray
 	ThumbnailURL sql.NullString
 	Metadata     JSONB
+	DeletedAt    sql.NullTime // Set while the asset is in the trash
 }
 
 // Blueprint represents a visual programming blueprint
@@ -278,6 +279,144 @@ type Execution struct {
 	Result           JSONB
 	Error            sql.NullString
 	DurationMs       sql.NullInt32
+	NodesExecuted    int
+	HTTPBytes        int64
+	DBRowsRead       int64
+	Recoveries       JSONArray
+	Budget           JSONB          // Consumption of the blueprint's execution budget, if it has one
+	RequestID        sql.NullString // ID of the API request that started the execution, if one did
+}
+
+// TestRun represents a run of the tests of a blueprint
+type TestRun struct {
+	ID          string
+	BlueprintID string
+	VersionID   sql.NullString
+	InitiatedBy string
+	StartedAt   time.Time
+	Passed      bool
+	Results     JSONB
+	Coverage    JSONB
+}
+
+// NodeTimer is a pending activation of a debounce or throttle node, coalescing the
+// triggers that arrived since the node last ran
+type NodeTimer struct {
+	BlueprintID string
+	NodeID      string
+	ExecutionID string
+	Mode        string
+	FireAt      time.Time
+	Triggers    int
+	Inputs      JSONB
+}
+
+// BlueprintState is a durable value a blueprint keeps across executions, such as a counter
+// or a cursor. Its version grows with every write.
+type BlueprintState struct {
+	BlueprintID string
+	Key         string
+	Value       interface{}
+	Version     int64
+	UpdatedBy   string // Execution that wrote the value
+	UpdatedAt   time.Time
+}
+
+// EventBindingState is the enabled state of an event binding set at runtime, which overrides
+// the state the binding declares
+type EventBindingState struct {
+	BindingID   string
+	BlueprintID string
+	Enabled     bool
+	UpdatedBy   string // User that toggled the binding
+	UpdatedAt   time.Time
+}
+
+// EventDelivery is an event dispatched to an at-least-once binding whose handler hasn't
+// acknowledged it yet, or a dead letter once its attempts ran out
+type EventDelivery struct {
+	ID            string    `json:"id"`
+	BindingID     string    `json:"bindingId"`
+	EventID       string    `json:"eventId"`
+	BlueprintID   string    `json:"blueprintId"` // Blueprint of the handler
+	SourceID      string    `json:"sourceId,omitempty"`
+	ExecutionID   string    `json:"executionId,omitempty"` // Execution that dispatched the event
+	Parameters    JSONB     `json:"parameters"`            // Raw values of the event parameters
+	Status        string    `json:"status"`                // "pending" or "dead"
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastError     string    `json:"lastError,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"` // When the event was dispatched
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ShareLink lets anyone with its token view or run a blueprint without an account, until it
+// expires or is revoked. Only the hash of the token is stored.
+type ShareLink struct {
+	ID              string     `json:"id"`
+	BlueprintID     string     `json:"blueprintId"`
+	TokenHash       string     `json:"-"`
+	Permission      string     `json:"permission"`      // "view" or "run"
+	RunsPerMinute   int        `json:"runsPerMinute"`   // Executions the link may start per minute
+	DeniedNodeTypes []string   `json:"deniedNodeTypes"` // Node type patterns runs of the link may not use
+	CreatedBy       string     `json:"createdBy"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt       *time.Time `json:"revokedAt,omitempty"`
+}
+
+// BlueprintDeprecation marks a blueprint, or one of its versions, as deprecated. Executions
+// of it log a warning until its sunset date, and are blocked from then on unless they
+// override the sunset.
+type BlueprintDeprecation struct {
+	BlueprintID   string     `json:"blueprintId"`
+	VersionNumber int        `json:"versionNumber"` // 0 deprecates every version of the blueprint
+	Message       string     `json:"message"`       // What to use instead, shown with every warning
+	SunsetAt      *time.Time `json:"sunsetAt,omitempty"`
+	DeprecatedBy  string     `json:"deprecatedBy"`
+	DeprecatedAt  time.Time  `json:"deprecatedAt"`
+}
+
+// SunsetPassed reports whether the sunset date of the deprecation passed at a time
+func (d *BlueprintDeprecation) SunsetPassed(at time.Time) bool {
+	return d.SunsetAt != nil && !at.Before(*d.SunsetAt)
+}
+
+// CrashReport is a panic recovered during an execution, with the context it happened in
+type CrashReport struct {
+	ID               string       `json:"id"`
+	Source           string       `json:"source"` // What panicked: "node", "actor", "event-handler" or "execution"
+	ExecutionID      string       `json:"executionId,omitempty"`
+	BlueprintID      string       `json:"blueprintId,omitempty"`
+	BlueprintVersion string       `json:"blueprintVersion,omitempty"`
+	NodeID           string       `json:"nodeId,omitempty"`
+	NodeType         string       `json:"nodeType,omitempty"`
+	Message          string       `json:"message"`
+	Stack            string       `json:"stack"`
+	Events           []CrashEvent `json:"events"` // Latest events of the execution before the panic, oldest first
+	OccurredAt       time.Time    `json:"occurredAt"`
+}
+
+// CrashEvent is an event of an execution leading up to a crash, such as a node execution
+type CrashEvent struct {
+	Type       string    `json:"type"`
+	NodeID     string    `json:"nodeId,omitempty"`
+	NodeType   string    `json:"nodeType,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs float64   `json:"durationMs,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// ExecutionUsageSummary aggregates execution resource usage of a workspace for one day
+type ExecutionUsageSummary struct {
+	WorkspaceID     string    `json:"workspaceId"`
+	Day             time.Time `json:"day"`
+	Executions      int       `json:"executions"`
+	TotalDurationMs int64     `json:"totalDurationMs"`
+	NodesExecuted   int64     `json:"nodesExecuted"`
+	HTTPBytes       int64     `json:"httpBytes"`
+	DBRowsRead      int64     `json:"dbRowsRead"`
 }
 
 // ExecutionNode represents execution data for a single node
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 	"webblueprint/internal/db" // Added import for db package
 	"webblueprint/internal/event"
 	"webblueprint/internal/node"
+	"webblueprint/internal/paging"
 	"webblueprint/pkg/api/dt"
 	"webblueprint/pkg/blueprint"
 	"webblueprint/pkg/models"
@@ -34,6 +37,17 @@ type AssetRepository interface {
 	Search(ctx context.Context, query string, limit, offset int) ([]*models.Asset, int, error)
 }
 
+// BlueprintSortFields are the fields blueprint lists can be sorted by
+var BlueprintSortFields = []string{"name", "updated_at", "created_at"}
+
+// BlueprintFilter selects the blueprints of a list. Empty fields don't filter.
+type BlueprintFilter struct {
+	WorkspaceID string
+	Tags        []string // Blueprints having every one of the tags
+	Category    string
+	IsTemplate  *bool
+}
+
 // Repository interface for managing blueprints
 type BlueprintRepository interface {
 	// Create a new blueprint
@@ -48,12 +62,24 @@ type BlueprintRepository interface {
 	// GetAll returns all blueprints (development only)
 	GetAll(ctx context.Context, limit, offset int) ([]*models.Blueprint, error)
 
+	// List gets a page of the blueprints matching a filter, with their current version
+	List(ctx context.Context, filter BlueprintFilter, page paging.Request) (paging.Page[*models.Blueprint], error)
+
 	// Update a blueprint
 	Update(ctx context.Context, bp *models.Blueprint) error
 
-	// Delete a blueprint
+	// Delete moves a blueprint to the trash
 	Delete(ctx context.Context, id string) error
 
+	// GetTrashed Get the deleted blueprints of a workspace that haven't been purged yet
+	GetTrashed(ctx context.Context, workspaceID string) ([]*models.Blueprint, error)
+
+	// Restore a deleted blueprint from the trash
+	Restore(ctx context.Context, id string) error
+
+	// Purge permanently deletes the blueprints deleted before the given time
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+
 	// FindByTags Find blueprints by tag
 	FindByTags(ctx context.Context, tags []string) ([]*models.Blueprint, error)
 
@@ -136,6 +162,16 @@ type UserRepository interface {
 	ToDt(user *models.User) *dt.User
 }
 
+// ExecutionSortFields are the fields execution lists can be sorted by
+var ExecutionSortFields = []string{"started_at", "status"}
+
+// ExecutionFilter selects the executions of a blueprint. Empty fields don't filter.
+type ExecutionFilter struct {
+	BlueprintID string
+	Status      string
+	InitiatedBy string
+}
+
 // Repository interface for managing executions
 type ExecutionRepository interface {
 	// Create a new execution record
@@ -147,6 +183,9 @@ type ExecutionRepository interface {
 	// Get executions by blueprint ID
 	GetByBlueprintID(ctx context.Context, blueprintID string) ([]*models.Execution, error)
 
+	// List gets a page of the executions of a blueprint matching a filter
+	List(ctx context.Context, filter ExecutionFilter, page paging.Request) (paging.Page[*models.Execution], error)
+
 	// Update execution status
 	UpdateStatus(ctx context.Context, id, status string) error
 
@@ -164,6 +203,152 @@ type ExecutionRepository interface {
 
 	// Get execution logs
 	GetLogs(ctx context.Context, executionID string) ([]*models.ExecutionLog, error)
+
+	// Get a page of the logs of a node of an execution at or above a log level rank, along
+	// with the number of matching logs
+	GetNodeLogs(ctx context.Context, executionID, nodeID string, minRank, limit, offset int) ([]*models.ExecutionLog, int, error)
+
+	// Record resource usage of an execution
+	RecordUsage(ctx context.Context, executionID string, nodesExecuted int, httpBytes, dbRowsRead int64) error
+
+	// Record the recovery strategies applied to the node errors of an execution
+	RecordRecoveries(ctx context.Context, executionID string, recoveries models.JSONArray) error
+
+	// Record how much of the blueprint's execution budget an execution consumed
+	RecordBudget(ctx context.Context, executionID string, budget models.JSONB) error
+
+	// Get per-day usage summaries of a workspace within a time range
+	GetDailyUsage(ctx context.Context, workspaceID string, from, to time.Time) ([]*models.ExecutionUsageSummary, error)
+
+	// Save debug data of a node execution
+	SaveNodeDebugData(ctx context.Context, executionID, nodeID string, debugData map[string]interface{}) error
+
+	// Get node executions of an execution ordered by start time
+	GetNodeExecutions(ctx context.Context, executionID string) ([]*models.ExecutionNode, error)
+
+	// Save the recorded external inputs of an execution
+	SaveRecording(ctx context.Context, executionID string, recording models.JSONB) error
+
+	// Get the recorded external inputs of an execution
+	GetRecording(ctx context.Context, executionID string) (models.JSONB, error)
+
+	// Save the recorded data flow of an execution
+	SaveDataFlow(ctx context.Context, executionID string, dataFlow models.JSONB) error
+
+	// Get the recorded data flow of an execution
+	GetDataFlow(ctx context.Context, executionID string) (models.JSONB, error)
+
+	// Save the recorded variable history of an execution
+	SaveVariableHistory(ctx context.Context, executionID string, history models.JSONB) error
+
+	// Get the recorded variable history of an execution
+	GetVariableHistory(ctx context.Context, executionID string) (models.JSONB, error)
+
+	// Save the node execution profile of an execution
+	SaveProfile(ctx context.Context, executionID string, profile models.JSONB) error
+
+	// Get the node execution profile of an execution
+	GetProfile(ctx context.Context, executionID string) (models.JSONB, error)
+}
+
+// Repository interface for managing blueprint test runs
+type TestRunRepository interface {
+	// Create stores a test run
+	Create(ctx context.Context, run *models.TestRun) error
+
+	// GetByID gets a test run by ID
+	GetByID(ctx context.Context, id string) (*models.TestRun, error)
+
+	// GetByBlueprintID gets the most recent test runs of a blueprint
+	GetByBlueprintID(ctx context.Context, blueprintID string, limit int) ([]*models.TestRun, error)
+}
+
+// Repository interface for the pending activations of debounce and throttle nodes
+type TimerRepository interface {
+	// Save creates or replaces the pending activation of a node
+	Save(ctx context.Context, timer *models.NodeTimer) error
+
+	// Delete removes the pending activation of a node
+	Delete(ctx context.Context, blueprintID, nodeID string) error
+
+	// GetAll gets all pending activations
+	GetAll(ctx context.Context) ([]*models.NodeTimer, error)
+}
+
+// Repository interface for the panics recovered during executions
+type CrashReportRepository interface {
+	// Save stores a crash report
+	Save(ctx context.Context, report *models.CrashReport) error
+
+	// GetByID gets a crash report, or nil if there's none with the ID
+	GetByID(ctx context.Context, id string) (*models.CrashReport, error)
+
+	// List gets the latest crash reports, newest first, of an execution or a blueprint if
+	// their ID isn't empty
+	List(ctx context.Context, executionID, blueprintID string, limit int) ([]*models.CrashReport, error)
+}
+
+// Repository interface for the public links to blueprints
+type ShareLinkRepository interface {
+	// Create stores a new share link
+	Create(ctx context.Context, link *models.ShareLink) error
+
+	// GetByID gets a share link, or nil if there's none with the ID
+	GetByID(ctx context.Context, id string) (*models.ShareLink, error)
+
+	// GetByTokenHash gets the share link of a token hash, or nil if there's none
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.ShareLink, error)
+
+	// GetByBlueprint gets the share links of a blueprint, newest first, including revoked ones
+	GetByBlueprint(ctx context.Context, blueprintID string) ([]*models.ShareLink, error)
+
+	// Revoke marks a share link as revoked
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+
+	// AddExecution records an execution started through a share link
+	AddExecution(ctx context.Context, linkID, executionID string) error
+
+	// HasExecution checks if an execution was started through a share link
+	HasExecution(ctx context.Context, linkID, executionID string) (bool, error)
+}
+
+// Repository interface for the deprecations of blueprints and their versions
+type DeprecationRepository interface {
+	// Set stores the deprecation of a blueprint or version, replacing the one it had
+	Set(ctx context.Context, deprecation *models.BlueprintDeprecation) error
+
+	// Get gets the deprecation of a version, or of every version if the number is 0, or nil
+	// if there's none
+	Get(ctx context.Context, blueprintID string, versionNumber int) (*models.BlueprintDeprecation, error)
+
+	// GetByBlueprint gets the deprecations of a blueprint and of its versions, by version
+	GetByBlueprint(ctx context.Context, blueprintID string) ([]*models.BlueprintDeprecation, error)
+
+	// Delete removes the deprecation of a version, or of every version if the number is 0
+	Delete(ctx context.Context, blueprintID string, versionNumber int) error
+}
+
+// ErrWorkspaceNotFound is returned when a workspace doesn't exist
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
+// ErrStateVersionConflict is returned when a state value isn't at the version a write expects
+var ErrStateVersionConflict = errors.New("state version conflict")
+
+// Repository interface for the durable state blueprints keep across executions
+type StateRepository interface {
+	// Get gets the value of a key, or nil if the key isn't set
+	Get(ctx context.Context, blueprintID, key string) (*models.BlueprintState, error)
+
+	// Save stores a value if its key is at the expected version, 0 for a key that isn't set
+	// and a negative version for any, and sets the new version on the state. It returns
+	// ErrStateVersionConflict when the key is at another version.
+	Save(ctx context.Context, state *models.BlueprintState, expectedVersion int64) error
+
+	// Delete removes a key if it's at the expected version, a negative version for any
+	Delete(ctx context.Context, blueprintID, key string, expectedVersion int64) error
+
+	// GetByBlueprintID gets all values of a blueprint
+	GetByBlueprintID(ctx context.Context, blueprintID string) ([]*models.BlueprintState, error)
 }
 
 type NodeRepository interface {
@@ -212,6 +397,21 @@ type EventRepository interface {
 	DeleteBindingsByBlueprintID(ctx context.Context, blueprintID string) error
 
 	GetAllBindings(ctx context.Context) ([]event.EventBinding, error)
+
+	// SaveBindingState stores the enabled state of a binding set at runtime
+	SaveBindingState(ctx context.Context, state *models.EventBindingState) error
+
+	// GetBindingStates gets the enabled states of all bindings set at runtime
+	GetBindingStates(ctx context.Context) ([]*models.EventBindingState, error)
+
+	// SaveDelivery creates or replaces a delivery of an at-least-once binding
+	SaveDelivery(ctx context.Context, delivery *models.EventDelivery) error
+
+	// DeleteDelivery removes a delivery once its handler acknowledged it
+	DeleteDelivery(ctx context.Context, id string) error
+
+	// GetDeliveries gets all pending deliveries and dead letters
+	GetDeliveries(ctx context.Context) ([]*models.EventDelivery, error)
 }
 
 // Repository factory interface for creating repository instances
@@ -234,6 +434,24 @@ type RepositoryFactory interface {
 	// Get execution repository
 	GetExecutionRepository() ExecutionRepository
 
+	// Get test run repository
+	GetTestRunRepository() TestRunRepository
+
+	// Get timer repository
+	GetTimerRepository() TimerRepository
+
+	// Get state repository
+	GetStateRepository() StateRepository
+
+	// Get crash report repository
+	GetCrashReportRepository() CrashReportRepository
+
+	// Get share link repository
+	GetShareLinkRepository() ShareLinkRepository
+
+	// Get deprecation repository
+	GetDeprecationRepository() DeprecationRepository
+
 	// Get node repository
 	GetNodeRepository() NodeRepository
 
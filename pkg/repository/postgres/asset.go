@@ -116,7 +116,7 @@ func (r *PostgresAssetRepository) GetByWorkspaceID(ctx context.Context, workspac
 			id, workspace_id, name, description, type, created_at, updated_at,
 			created_by, updated_by, is_public, tags, thumbnail_url, metadata
 		FROM assets
-		WHERE workspace_id = $1
+		WHERE workspace_id = $1 AND deleted_at IS NULL
 		ORDER BY name
 	`
 
@@ -164,7 +164,7 @@ func (r *PostgresAssetRepository) GetByType(ctx context.Context, assetType strin
 			id, workspace_id, name, description, type, created_at, updated_at,
 			created_by, updated_by, is_public, tags, thumbnail_url, metadata
 		FROM assets
-		WHERE type = $1
+		WHERE type = $1 AND deleted_at IS NULL
 		ORDER BY name
 	`
 
@@ -276,12 +276,13 @@ func (r *PostgresAssetRepository) Search(ctx context.Context, query string, limi
 	countQuery := `
 		SELECT COUNT(*)
 		FROM assets
-		WHERE LOWER(name) LIKE $1
+		WHERE deleted_at IS NULL AND (
+		   LOWER(name) LIKE $1
 		   OR LOWER(description) LIKE $1
 		   OR EXISTS (
 			  SELECT 1 FROM unnest(tags) tag
 			  WHERE LOWER(tag) LIKE $1
-		   )
+		   ))
 	`
 
 	var total int
@@ -296,12 +297,13 @@ func (r *PostgresAssetRepository) Search(ctx context.Context, query string, limi
 			id, workspace_id, name, description, type, created_at, updated_at,
 			created_by, updated_by, is_public, tags, thumbnail_url, metadata
 		FROM assets
-		WHERE LOWER(name) LIKE $1
+		WHERE deleted_at IS NULL AND (
+		   LOWER(name) LIKE $1
 		   OR LOWER(description) LIKE $1
 		   OR EXISTS (
 			  SELECT 1 FROM unnest(tags) tag
 			  WHERE LOWER(tag) LIKE $1
-		   )
+		   ))
 		ORDER BY 
 			CASE WHEN LOWER(name) LIKE $1 THEN 0
 				 WHEN EXISTS (SELECT 1 FROM unnest(tags) tag WHERE LOWER(tag) LIKE $1) THEN 1
@@ -3,15 +3,18 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
+	"webblueprint/internal/paging"
 	"webblueprint/pkg/blueprint"
 	"webblueprint/pkg/models"
 	"webblueprint/pkg/repository"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // PostgresBlueprintRepository implements BlueprintRepository using PostgreSQL
@@ -149,7 +152,7 @@ func (r *PostgresBlueprintRepository) GetByID(ctx context.Context, id string) (*
 			b.current_version_id, b.node_count, b.connection_count, b.entry_points, b.is_template, b.category
 		FROM blueprints b
 		JOIN assets a ON b.id = a.id
-		WHERE b.id = $1
+		WHERE b.id = $1 AND a.deleted_at IS NULL
 	`
 
 	var bp models.Blueprint
@@ -229,7 +232,7 @@ func (r *PostgresBlueprintRepository) GetByWorkspaceID(ctx context.Context, work
 			b.current_version_id, b.node_count, b.connection_count, b.entry_points, b.is_template, b.category
 		FROM blueprints b
 		JOIN assets a ON b.id = a.id
-		WHERE a.workspace_id = $1
+		WHERE a.workspace_id = $1 AND a.deleted_at IS NULL
 		ORDER BY a.name
 	`
 
@@ -283,6 +286,7 @@ func (r *PostgresBlueprintRepository) GetAll(ctx context.Context, limit, offset
 			b.current_version_id, b.node_count, b.connection_count, b.entry_points, b.is_template, b.category
 		FROM blueprints b
 		JOIN assets a ON b.id = a.id
+		WHERE a.deleted_at IS NULL
 		ORDER BY a.created_at
 		LIMIT $1
 		OFFSET $2
@@ -329,43 +333,154 @@ func (r *PostgresBlueprintRepository) GetAll(ctx context.Context, limit, offset
 
 	// If there's a current version ID, get the current version
 	for _, bp := range blueprints {
-		if bp.CurrentVersionID.Valid {
-			versionQuery := `
-			SELECT 
-				id, blueprint_id, version_number, created_at, created_by,
-				comment, nodes, connections, variables, functions, events, event_bindings, metadata
-			FROM blueprint_versions
-			WHERE id = $1
-		`
+		if err := r.loadCurrentVersion(ctx, bp); err != nil {
+			return nil, err
+		}
+	}
 
-			var version models.BlueprintVersion
-			err = r.db.QueryRowContext(ctx, versionQuery, bp.CurrentVersionID.String).Scan(
-				&version.ID,
-				&version.BlueprintID,
-				&version.VersionNumber,
-				&version.CreatedAt,
-				&version.CreatedBy,
-				&version.Comment,
-				&version.Nodes,
-				&version.Connections,
-				&version.Variables,
-				&version.Functions,
-				&version.Events,
-				&version.EventBindings,
-				&version.Metadata,
-			)
-
-			if err != nil && !errors.Is(err, sql.ErrNoRows) {
-				return nil, fmt.Errorf("error retrieving current version: %w", err)
-			}
+	return blueprints, nil
+}
 
-			if err == nil {
-				bp.CurrentVersion = &version
-			}
+// blueprintSortColumns are the columns of the sort fields of blueprint lists
+var blueprintSortColumns = map[string]sortColumn{
+	"name":       {column: "a.name"},
+	"updated_at": {column: "a.updated_at", isTime: true},
+	"created_at": {column: "a.created_at", isTime: true},
+}
+
+// List gets a page of the blueprints matching a filter, with their current version
+func (r *PostgresBlueprintRepository) List(ctx context.Context, filter repository.BlueprintFilter, page paging.Request) (paging.Page[*models.Blueprint], error) {
+	var result paging.Page[*models.Blueprint]
+
+	conditions := []string{"a.deleted_at IS NULL"}
+	var args []interface{}
+	if filter.WorkspaceID != "" {
+		args = append(args, filter.WorkspaceID)
+		conditions = append(conditions, fmt.Sprintf("a.workspace_id = $%d", len(args)))
+	}
+	if len(filter.Tags) > 0 {
+		args = append(args, pq.Array(filter.Tags))
+		conditions = append(conditions, fmt.Sprintf("a.tags @> $%d", len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("b.category = $%d", len(args)))
+	}
+	if filter.IsTemplate != nil {
+		args = append(args, *filter.IsTemplate)
+		conditions = append(conditions, fmt.Sprintf("b.is_template = $%d", len(args)))
+	}
+
+	countQuery := "SELECT COUNT(*) FROM blueprints b JOIN assets a ON b.id = a.id " + whereClause(conditions)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+		return result, fmt.Errorf("error counting blueprints: %w", err)
+	}
+
+	after, tail, args, err := pageClauses(page, blueprintSortColumns, "a.id", args)
+	if err != nil {
+		return result, err
+	}
+	query := `
+		SELECT 
+			a.id, a.workspace_id, a.name, a.description, a.created_at, a.updated_at,
+			a.created_by, a.updated_by, a.is_public, a.tags, a.thumbnail_url, a.metadata,
+			b.current_version_id, b.node_count, b.connection_count, b.entry_points, b.is_template, b.category
+		FROM blueprints b
+		JOIN assets a ON b.id = a.id
+		` + whereClause(append(conditions, after)) + `
+		` + tail
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("error querying blueprints: %w", err)
+	}
+	defer rows.Close()
+
+	var blueprints []*models.Blueprint
+	for rows.Next() {
+		var bp models.Blueprint
+		err := rows.Scan(
+			&bp.ID,
+			&bp.WorkspaceID,
+			&bp.Name,
+			&bp.Description,
+			&bp.CreatedAt,
+			&bp.UpdatedAt,
+			&bp.CreatedBy,
+			&bp.UpdatedBy,
+			&bp.IsPublic,
+			&bp.Tags,
+			&bp.ThumbnailURL,
+			&bp.Metadata,
+			&bp.CurrentVersionID,
+			&bp.NodeCount,
+			&bp.ConnectionCount,
+			&bp.EntryPoints,
+			&bp.IsTemplate,
+			&bp.Category,
+		)
+		if err != nil {
+			return result, fmt.Errorf("error scanning blueprint row: %w", err)
 		}
+		blueprints = append(blueprints, &bp)
+	}
+	if err = rows.Err(); err != nil {
+		return result, fmt.Errorf("error iterating blueprint rows: %w", err)
 	}
 
-	return blueprints, nil
+	blueprints, more := trimPage(blueprints, page)
+	for _, bp := range blueprints {
+		if err := r.loadCurrentVersion(ctx, bp); err != nil {
+			return result, err
+		}
+	}
+
+	sorted := blueprintSortColumns[page.Sort.Field]
+	result.Items = blueprints
+	result.Next = paging.NextCursor(blueprints, page, more, func(bp *models.Blueprint) (string, string) {
+		values := map[string]interface{}{"name": bp.Name, "updated_at": bp.UpdatedAt, "created_at": bp.CreatedAt}
+		return sorted.key(values[page.Sort.Field]), bp.ID
+	})
+	return result, nil
+}
+
+// loadCurrentVersion reads the current version of a blueprint, if it has one
+func (r *PostgresBlueprintRepository) loadCurrentVersion(ctx context.Context, bp *models.Blueprint) error {
+	if !bp.CurrentVersionID.Valid {
+		return nil
+	}
+
+	versionQuery := `
+		SELECT 
+			id, blueprint_id, version_number, created_at, created_by,
+			comment, nodes, connections, variables, functions, events, event_bindings, metadata
+		FROM blueprint_versions
+		WHERE id = $1
+	`
+
+	var version models.BlueprintVersion
+	err := r.db.QueryRowContext(ctx, versionQuery, bp.CurrentVersionID.String).Scan(
+		&version.ID,
+		&version.BlueprintID,
+		&version.VersionNumber,
+		&version.CreatedAt,
+		&version.CreatedBy,
+		&version.Comment,
+		&version.Nodes,
+		&version.Connections,
+		&version.Variables,
+		&version.Functions,
+		&version.Events,
+		&version.EventBindings,
+		&version.Metadata,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error retrieving current version: %w", err)
+	}
+	if err == nil {
+		bp.CurrentVersion = &version
+	}
+	return nil
 }
 
 // Update updates a blueprint's metadata (not its version content)
@@ -435,17 +550,127 @@ func (r *PostgresBlueprintRepository) Update(ctx context.Context, bp *models.Blu
 	return nil
 }
 
-// Delete deletes a blueprint and all its versions
+// Delete moves a blueprint to the trash, from which it can be restored until it's purged
 func (r *PostgresBlueprintRepository) Delete(ctx context.Context, id string) error {
-	// The asset deletion will cascade to the blueprint and versions due to foreign key constraints
-	query := `DELETE FROM assets WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE assets SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete blueprint: %w", err)
 	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("blueprint not found: %s", id)
+	}
 	return nil
 }
 
+// GetTrashed retrieves the deleted blueprints of a workspace, most recently deleted first
+func (r *PostgresBlueprintRepository) GetTrashed(ctx context.Context, workspaceID string) ([]*models.Blueprint, error) {
+	query := `
+		SELECT 
+			a.id, a.workspace_id, a.name, a.description, a.created_at, a.updated_at,
+			a.created_by, a.updated_by, a.is_public, a.tags, a.thumbnail_url, a.metadata, a.deleted_at,
+			b.current_version_id, b.node_count, b.connection_count, b.entry_points, b.is_template, b.category
+		FROM blueprints b
+		JOIN assets a ON b.id = a.id
+		WHERE a.workspace_id = $1 AND a.deleted_at IS NOT NULL
+		ORDER BY a.deleted_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying deleted blueprints: %w", err)
+	}
+	defer rows.Close()
+
+	blueprints := make([]*models.Blueprint, 0)
+	for rows.Next() {
+		var bp models.Blueprint
+		err := rows.Scan(
+			&bp.ID,
+			&bp.WorkspaceID,
+			&bp.Name,
+			&bp.Description,
+			&bp.CreatedAt,
+			&bp.UpdatedAt,
+			&bp.CreatedBy,
+			&bp.UpdatedBy,
+			&bp.IsPublic,
+			&bp.Tags,
+			&bp.ThumbnailURL,
+			&bp.Metadata,
+			&bp.DeletedAt,
+			&bp.CurrentVersionID,
+			&bp.NodeCount,
+			&bp.ConnectionCount,
+			&bp.EntryPoints,
+			&bp.IsTemplate,
+			&bp.Category,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning blueprint row: %w", err)
+		}
+		blueprints = append(blueprints, &bp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blueprint rows: %w", err)
+	}
+
+	return blueprints, nil
+}
+
+// Restore takes a deleted blueprint out of the trash
+func (r *PostgresBlueprintRepository) Restore(ctx context.Context, id string) error {
+	query := `UPDATE assets SET deleted_at = NULL, updated_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to restore blueprint: %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("blueprint not found in trash: %s", id)
+	}
+	return nil
+}
+
+// Purge permanently deletes the blueprints deleted before the given time, along with their
+// versions, executions and state, and returns the number of purged blueprints
+func (r *PostgresBlueprintRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	purged := `SELECT a.id FROM assets a JOIN blueprints b ON b.id = a.id WHERE a.deleted_at < $1`
+
+	// Executions reference their blueprint without cascading
+	_, err = tx.ExecContext(ctx, `DELETE FROM executions WHERE blueprint_id IN (`+purged+`)`, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge executions: %w", err)
+	}
+
+	// So does the state the blueprints kept across executions
+	_, err = tx.ExecContext(ctx, `DELETE FROM blueprint_state WHERE blueprint_id IN (`+purged+`)`, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge blueprint state: %w", err)
+	}
+
+	// The asset deletion will cascade to the blueprint and versions due to foreign key constraints
+	result, err := tx.ExecContext(ctx, `DELETE FROM assets WHERE id IN (`+purged+`)`, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge blueprints: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return int(count), nil
+}
+
 // FindByTags finds blueprints by tags
 func (r *PostgresBlueprintRepository) FindByTags(ctx context.Context, tags []string) ([]*models.Blueprint, error) {
 	if len(tags) == 0 {
@@ -460,8 +685,8 @@ func (r *PostgresBlueprintRepository) FindByTags(ctx context.Context, tags []str
 			b.current_version_id, b.node_count, b.connection_count, b.entry_points, b.is_template, b.category
 		FROM blueprints b
 		JOIN assets a ON b.id = a.id
-		WHERE a.type = 'blueprint' 
-	` // @TODO missing query
+		WHERE a.type = 'blueprint' AND a.deleted_at IS NULL AND (
+	`
 
 	// Build the tag conditions: a.tags @> ARRAY['tag1'] OR a.tags @> ARRAY['tag2'] ...
 	conditions := make([]string, len(tags))
@@ -1188,11 +1413,41 @@ func (r *PostgresBlueprintRepository) ToPkgBlueprint(blueprintModel *models.Blue
 
 			bp.EventBindings = append(bp.EventBindings, binding)
 		}
+
+		// Frames are kept in the version metadata
+		if frames, ok := versionModel.Metadata[framesMetadataKey]; ok {
+			encoded, err := json.Marshal(frames)
+			if err == nil {
+				err = json.Unmarshal(encoded, &bp.Frames)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid frames in blueprint version: %w", err)
+			}
+		}
+
+		if policy, ok := versionModel.Metadata[concurrencyMetadataKey].(map[string]interface{}); ok {
+			bp.Concurrency = &blueprint.ConcurrencyPolicy{}
+			if mode, ok := policy["mode"].(string); ok {
+				bp.Concurrency.Mode = blueprint.ConcurrencyMode(mode)
+			}
+			switch maxQueued := policy["maxQueued"].(type) {
+			case float64:
+				bp.Concurrency.MaxQueued = int(maxQueued)
+			case int:
+				bp.Concurrency.MaxQueued = maxQueued
+			}
+		}
 	}
 
 	return bp, nil
 }
 
+// framesMetadataKey is the version metadata key of the frames of a blueprint
+const framesMetadataKey = "frames"
+
+// concurrencyMetadataKey is the version metadata key of the concurrency policy of a blueprint
+const concurrencyMetadataKey = "concurrencyPolicy"
+
 // FromPkgBlueprint converts a package blueprint to database models
 func (r *PostgresBlueprintRepository) FromPkgBlueprint(bp *blueprint.Blueprint) (*models.Blueprint, *models.BlueprintVersion, error) {
 	if bp == nil {
@@ -1454,5 +1709,36 @@ func (r *PostgresBlueprintRepository) FromPkgBlueprint(bp *blueprint.Blueprint)
 	blueprintModel.NodeCount = len(bp.Nodes)
 	blueprintModel.ConnectionCount = len(bp.Connections)
 
+	// Convert frames
+	if len(bp.Frames) > 0 {
+		frames := make([]interface{}, len(bp.Frames))
+		for i, frame := range bp.Frames {
+			frames[i] = map[string]interface{}{
+				"id":        frame.ID,
+				"title":     frame.Title,
+				"comment":   frame.Comment,
+				"color":     frame.Color,
+				"position":  map[string]interface{}{"x": frame.Position.X, "y": frame.Position.Y},
+				"size":      map[string]interface{}{"width": frame.Size.Width, "height": frame.Size.Height},
+				"collapsed": frame.Collapsed,
+				"nodeIds":   frame.NodeIDs,
+			}
+		}
+		if versionModel.Metadata == nil {
+			versionModel.Metadata = make(models.JSONB)
+		}
+		versionModel.Metadata[framesMetadataKey] = frames
+	}
+
+	if bp.Concurrency != nil {
+		if versionModel.Metadata == nil {
+			versionModel.Metadata = make(models.JSONB)
+		}
+		versionModel.Metadata[concurrencyMetadataKey] = map[string]interface{}{
+			"mode":      string(bp.Concurrency.Mode),
+			"maxQueued": bp.Concurrency.MaxQueued,
+		}
+	}
+
 	return blueprintModel, versionModel, nil
 }
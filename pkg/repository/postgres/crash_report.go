@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// PostgresCrashReportRepository implements CrashReportRepository using PostgreSQL
+type PostgresCrashReportRepository struct {
+	db *sql.DB
+}
+
+// NewCrashReportRepository creates a new PostgreSQL-based crash report repository
+func NewCrashReportRepository(db *sql.DB) repository.CrashReportRepository {
+	return &PostgresCrashReportRepository{
+		db: db,
+	}
+}
+
+// Save stores a crash report
+func (r *PostgresCrashReportRepository) Save(ctx context.Context, report *models.CrashReport) error {
+	events := report.Events
+	if events == nil {
+		events = []models.CrashEvent{}
+	}
+	encodedEvents, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode crash report events: %w", err)
+	}
+
+	query := `
+		INSERT INTO crash_reports (
+			id, source, execution_id, blueprint_id, blueprint_version, node_id, node_type,
+			message, stack, events, occurred_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		report.ID,
+		report.Source,
+		report.ExecutionID,
+		report.BlueprintID,
+		report.BlueprintVersion,
+		report.NodeID,
+		report.NodeType,
+		report.Message,
+		report.Stack,
+		encodedEvents,
+		report.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save crash report: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID gets a crash report, or nil if there's none with the ID
+func (r *PostgresCrashReportRepository) GetByID(ctx context.Context, id string) (*models.CrashReport, error) {
+	query := crashReportColumns + ` WHERE id = $1`
+
+	report, err := scanCrashReport(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting crash report: %w", err)
+	}
+
+	return report, nil
+}
+
+// List gets the latest crash reports, newest first, of an execution or a blueprint if their
+// ID isn't empty
+func (r *PostgresCrashReportRepository) List(ctx context.Context, executionID, blueprintID string, limit int) ([]*models.CrashReport, error) {
+	query := crashReportColumns + `
+		WHERE ($1 = '' OR execution_id = $1) AND ($2 = '' OR blueprint_id = $2)
+		ORDER BY occurred_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, executionID, blueprintID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying crash reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]*models.CrashReport, 0)
+	for rows.Next() {
+		report, err := scanCrashReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning crash report row: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating crash report rows: %w", err)
+	}
+
+	return reports, nil
+}
+
+// crashReportColumns selects the columns scanCrashReport reads
+const crashReportColumns = `
+	SELECT id, source, COALESCE(execution_id, ''), COALESCE(blueprint_id, ''),
+		COALESCE(blueprint_version, ''), COALESCE(node_id, ''), COALESCE(node_type, ''),
+		message, stack, events, occurred_at
+	FROM crash_reports
+`
+
+// scanCrashReport reads a crash report from a row of crashReportColumns
+func scanCrashReport(row stateRow) (*models.CrashReport, error) {
+	var report models.CrashReport
+	var events []byte
+	err := row.Scan(
+		&report.ID,
+		&report.Source,
+		&report.ExecutionID,
+		&report.BlueprintID,
+		&report.BlueprintVersion,
+		&report.NodeID,
+		&report.NodeType,
+		&report.Message,
+		&report.Stack,
+		&events,
+		&report.OccurredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(events, &report.Events); err != nil {
+		return nil, fmt.Errorf("invalid events of crash report %s: %w", report.ID, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// PostgresDeprecationRepository implements DeprecationRepository using PostgreSQL
+type PostgresDeprecationRepository struct {
+	db *sql.DB
+}
+
+// NewDeprecationRepository creates a new PostgreSQL-based deprecation repository
+func NewDeprecationRepository(db *sql.DB) repository.DeprecationRepository {
+	return &PostgresDeprecationRepository{
+		db: db,
+	}
+}
+
+// Set stores the deprecation of a blueprint or version, replacing the one it had
+func (r *PostgresDeprecationRepository) Set(ctx context.Context, deprecation *models.BlueprintDeprecation) error {
+	query := `
+		INSERT INTO blueprint_deprecations (
+			blueprint_id, version_number, message, sunset_at, deprecated_by, deprecated_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (blueprint_id, version_number) DO UPDATE SET
+			message = EXCLUDED.message,
+			sunset_at = EXCLUDED.sunset_at,
+			deprecated_by = EXCLUDED.deprecated_by,
+			deprecated_at = EXCLUDED.deprecated_at
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		deprecation.BlueprintID,
+		deprecation.VersionNumber,
+		deprecation.Message,
+		deprecation.SunsetAt,
+		deprecation.DeprecatedBy,
+		deprecation.DeprecatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set deprecation: %w", err)
+	}
+
+	return nil
+}
+
+// Get gets the deprecation of a version, or of every version if the number is 0, or nil if
+// there's none
+func (r *PostgresDeprecationRepository) Get(ctx context.Context, blueprintID string, versionNumber int) (*models.BlueprintDeprecation, error) {
+	query := deprecationColumns + ` WHERE blueprint_id = $1 AND version_number = $2`
+
+	deprecation, err := scanDeprecation(r.db.QueryRowContext(ctx, query, blueprintID, versionNumber))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting deprecation: %w", err)
+	}
+
+	return deprecation, nil
+}
+
+// GetByBlueprint gets the deprecations of a blueprint and of its versions, by version
+func (r *PostgresDeprecationRepository) GetByBlueprint(ctx context.Context, blueprintID string) ([]*models.BlueprintDeprecation, error) {
+	query := deprecationColumns + ` WHERE blueprint_id = $1 ORDER BY version_number`
+
+	rows, err := r.db.QueryContext(ctx, query, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying deprecations: %w", err)
+	}
+	defer rows.Close()
+
+	deprecations := make([]*models.BlueprintDeprecation, 0)
+	for rows.Next() {
+		deprecation, err := scanDeprecation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning deprecation row: %w", err)
+		}
+		deprecations = append(deprecations, deprecation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deprecation rows: %w", err)
+	}
+
+	return deprecations, nil
+}
+
+// Delete removes the deprecation of a version, or of every version if the number is 0
+func (r *PostgresDeprecationRepository) Delete(ctx context.Context, blueprintID string, versionNumber int) error {
+	query := `DELETE FROM blueprint_deprecations WHERE blueprint_id = $1 AND version_number = $2`
+
+	result, err := r.db.ExecContext(ctx, query, blueprintID, versionNumber)
+	if err != nil {
+		return fmt.Errorf("failed to delete deprecation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deprecation not found: %s version %d", blueprintID, versionNumber)
+	}
+
+	return nil
+}
+
+// deprecationColumns selects the columns scanDeprecation reads
+const deprecationColumns = `
+	SELECT blueprint_id, version_number, message, sunset_at, deprecated_by, deprecated_at
+	FROM blueprint_deprecations
+`
+
+// scanDeprecation reads a deprecation from a row of deprecationColumns
+func scanDeprecation(row stateRow) (*models.BlueprintDeprecation, error) {
+	var deprecation models.BlueprintDeprecation
+	var sunsetAt sql.NullTime
+	err := row.Scan(
+		&deprecation.BlueprintID,
+		&deprecation.VersionNumber,
+		&deprecation.Message,
+		&sunsetAt,
+		&deprecation.DeprecatedBy,
+		&deprecation.DeprecatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if sunsetAt.Valid {
+		deprecation.SunsetAt = &sunsetAt.Time
+	}
+	return &deprecation, nil
+}
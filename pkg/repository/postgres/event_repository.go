@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 	"webblueprint/internal/event"
+	"webblueprint/pkg/models"
 )
 
 // EventRepository handles database operations for events
@@ -570,3 +571,161 @@ func (r *PostgresEventRepository) GetAllBindings(ctx context.Context) ([]event.E
 
 	return bindings, nil
 }
+
+// SaveBindingState stores the enabled state of a binding set at runtime
+func (r *PostgresEventRepository) SaveBindingState(ctx context.Context, state *models.EventBindingState) error {
+	query := `
+		INSERT INTO event_binding_states (binding_id, blueprint_id, enabled, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (binding_id) DO UPDATE SET
+			blueprint_id = EXCLUDED.blueprint_id,
+			enabled = EXCLUDED.enabled,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, state.BindingID, state.BlueprintID, state.Enabled, state.UpdatedBy).Scan(&state.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save binding state: %w", err)
+	}
+
+	return nil
+}
+
+// GetBindingStates gets the enabled states of all bindings set at runtime
+func (r *PostgresEventRepository) GetBindingStates(ctx context.Context) ([]*models.EventBindingState, error) {
+	query := `
+		SELECT binding_id, blueprint_id, enabled, updated_by, updated_at
+		FROM event_binding_states
+		ORDER BY binding_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query binding states: %w", err)
+	}
+	defer rows.Close()
+
+	states := make([]*models.EventBindingState, 0)
+	for rows.Next() {
+		var state models.EventBindingState
+		var updatedBy sql.NullString
+		if err := rows.Scan(&state.BindingID, &state.BlueprintID, &state.Enabled, &updatedBy, &state.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan binding state row: %w", err)
+		}
+		state.UpdatedBy = updatedBy.String
+		states = append(states, &state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating binding state rows: %w", err)
+	}
+
+	return states, nil
+}
+
+// SaveDelivery creates or replaces a delivery of an at-least-once binding
+func (r *PostgresEventRepository) SaveDelivery(ctx context.Context, delivery *models.EventDelivery) error {
+	parameters := delivery.Parameters
+	if parameters == nil {
+		parameters = models.JSONB{}
+	}
+
+	query := `
+		INSERT INTO event_deliveries (
+			id, binding_id, event_id, blueprint_id, source_id, execution_id, parameters,
+			status, attempts, next_attempt_at, last_error, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			attempts = EXCLUDED.attempts,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			last_error = EXCLUDED.last_error,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		delivery.ID,
+		delivery.BindingID,
+		delivery.EventID,
+		delivery.BlueprintID,
+		delivery.SourceID,
+		delivery.ExecutionID,
+		parameters,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.CreatedAt,
+	).Scan(&delivery.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save event delivery: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDelivery removes a delivery once its handler acknowledged it
+func (r *PostgresEventRepository) DeleteDelivery(ctx context.Context, id string) error {
+	query := `DELETE FROM event_deliveries WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete event delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeliveries gets all pending deliveries and dead letters
+func (r *PostgresEventRepository) GetDeliveries(ctx context.Context) ([]*models.EventDelivery, error) {
+	query := `
+		SELECT id, binding_id, event_id, blueprint_id, source_id, execution_id, parameters,
+			status, attempts, next_attempt_at, last_error, created_at, updated_at
+		FROM event_deliveries
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.EventDelivery, 0)
+	for rows.Next() {
+		var delivery models.EventDelivery
+		var sourceID, executionID, lastError sql.NullString
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.BindingID,
+			&delivery.EventID,
+			&delivery.BlueprintID,
+			&sourceID,
+			&executionID,
+			&delivery.Parameters,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&lastError,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event delivery row: %w", err)
+		}
+		delivery.SourceID = sourceID.String
+		delivery.ExecutionID = executionID.String
+		delivery.LastError = lastError.String
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event delivery rows: %w", err)
+	}
+
+	return deliveries, nil
+}
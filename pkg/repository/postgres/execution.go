@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"time"
+	"webblueprint/internal/paging"
 	"webblueprint/pkg/models"
 	"webblueprint/pkg/repository"
 
@@ -34,8 +35,8 @@ func (r *PostgresExecutionRepository) Create(ctx context.Context, execution *mod
 	query := `
 		INSERT INTO executions (
 			id, blueprint_id, version_id, started_at, status, initiated_by,
-			execution_mode, initial_variables
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			execution_mode, initial_variables, request_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.ExecContext(
@@ -49,6 +50,7 @@ func (r *PostgresExecutionRepository) Create(ctx context.Context, execution *mod
 		execution.InitiatedBy,
 		execution.ExecutionMode,
 		execution.InitialVariables,
+		execution.RequestID,
 	)
 
 	if err != nil {
@@ -63,7 +65,8 @@ func (r *PostgresExecutionRepository) GetByID(ctx context.Context, id string) (*
 	query := `
 		SELECT 
 			id, blueprint_id, version_id, started_at, completed_at, status, initiated_by,
-			execution_mode, initial_variables, result, error, duration_ms
+			execution_mode, initial_variables, result, error, duration_ms,
+			nodes_executed, http_bytes, db_rows_read, recoveries, budget, request_id
 		FROM executions
 		WHERE id = $1
 	`
@@ -82,6 +85,12 @@ func (r *PostgresExecutionRepository) GetByID(ctx context.Context, id string) (*
 		&execution.Result,
 		&execution.Error,
 		&execution.DurationMs,
+		&execution.NodesExecuted,
+		&execution.HTTPBytes,
+		&execution.DBRowsRead,
+		&execution.Recoveries,
+		&execution.Budget,
+		&execution.RequestID,
 	)
 
 	if err != nil {
@@ -99,7 +108,8 @@ func (r *PostgresExecutionRepository) GetByBlueprintID(ctx context.Context, blue
 	query := `
 		SELECT 
 			id, blueprint_id, version_id, started_at, completed_at, status, initiated_by,
-			execution_mode, initial_variables, result, error, duration_ms
+			execution_mode, initial_variables, result, error, duration_ms,
+			nodes_executed, http_bytes, db_rows_read, recoveries, budget, request_id
 		FROM executions
 		WHERE blueprint_id = $1
 		ORDER BY started_at DESC
@@ -127,6 +137,12 @@ func (r *PostgresExecutionRepository) GetByBlueprintID(ctx context.Context, blue
 			&execution.Result,
 			&execution.Error,
 			&execution.DurationMs,
+			&execution.NodesExecuted,
+			&execution.HTTPBytes,
+			&execution.DBRowsRead,
+			&execution.Recoveries,
+			&execution.Budget,
+			&execution.RequestID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning execution row: %w", err)
@@ -141,6 +157,95 @@ func (r *PostgresExecutionRepository) GetByBlueprintID(ctx context.Context, blue
 	return executions, nil
 }
 
+// executionSortColumns are the columns of the sort fields of execution lists
+var executionSortColumns = map[string]sortColumn{
+	"started_at": {column: "started_at", isTime: true},
+	"status":     {column: "status"},
+}
+
+// List gets a page of the executions of a blueprint matching a filter
+func (r *PostgresExecutionRepository) List(ctx context.Context, filter repository.ExecutionFilter, page paging.Request) (paging.Page[*models.Execution], error) {
+	var result paging.Page[*models.Execution]
+
+	var conditions []string
+	var args []interface{}
+	for _, field := range []struct{ column, value string }{
+		{"blueprint_id", filter.BlueprintID},
+		{"status", filter.Status},
+		{"initiated_by", filter.InitiatedBy},
+	} {
+		if field.value != "" {
+			args = append(args, field.value)
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", field.column, len(args)))
+		}
+	}
+
+	countQuery := "SELECT COUNT(*) FROM executions " + whereClause(conditions)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+		return result, fmt.Errorf("error counting executions: %w", err)
+	}
+
+	after, tail, args, err := pageClauses(page, executionSortColumns, "id", args)
+	if err != nil {
+		return result, err
+	}
+	query := `
+		SELECT 
+			id, blueprint_id, version_id, started_at, completed_at, status, initiated_by,
+			execution_mode, initial_variables, result, error, duration_ms,
+			nodes_executed, http_bytes, db_rows_read, recoveries, budget, request_id
+		FROM executions
+		` + whereClause(append(conditions, after)) + `
+		` + tail
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return result, fmt.Errorf("error querying executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []*models.Execution
+	for rows.Next() {
+		var execution models.Execution
+		err := rows.Scan(
+			&execution.ID,
+			&execution.BlueprintID,
+			&execution.VersionID,
+			&execution.StartedAt,
+			&execution.CompletedAt,
+			&execution.Status,
+			&execution.InitiatedBy,
+			&execution.ExecutionMode,
+			&execution.InitialVariables,
+			&execution.Result,
+			&execution.Error,
+			&execution.DurationMs,
+			&execution.NodesExecuted,
+			&execution.HTTPBytes,
+			&execution.DBRowsRead,
+			&execution.Recoveries,
+			&execution.Budget,
+			&execution.RequestID,
+		)
+		if err != nil {
+			return result, fmt.Errorf("error scanning execution row: %w", err)
+		}
+		executions = append(executions, &execution)
+	}
+	if err = rows.Err(); err != nil {
+		return result, fmt.Errorf("error iterating execution rows: %w", err)
+	}
+
+	executions, more := trimPage(executions, page)
+	sorted := executionSortColumns[page.Sort.Field]
+	result.Items = executions
+	result.Next = paging.NextCursor(executions, page, more, func(execution *models.Execution) (string, string) {
+		values := map[string]interface{}{"started_at": execution.StartedAt, "status": execution.Status}
+		return sorted.key(values[page.Sort.Field]), execution.ID
+	})
+	return result, nil
+}
+
 // UpdateStatus updates an execution status
 func (r *PostgresExecutionRepository) UpdateStatus(ctx context.Context, id, status string) error {
 	query := `UPDATE executions SET status = $1 WHERE id = $2`
@@ -477,3 +582,415 @@ func (r *PostgresExecutionRepository) GetLogs(ctx context.Context, executionID s
 
 	return logs, nil
 }
+
+// logRankSQL ranks the level of execution logs like blueprint.LogLevelRank, treating
+// unknown levels as info
+const logRankSQL = `
+	CASE LOWER(log_level)
+		WHEN 'debug' THEN 0
+		WHEN 'info' THEN 1
+		WHEN 'warn' THEN 2
+		WHEN 'warning' THEN 2
+		WHEN 'error' THEN 3
+		ELSE 1
+	END`
+
+// GetNodeLogs retrieves a page of the logs of a node of an execution
+func (r *PostgresExecutionRepository) GetNodeLogs(
+	ctx context.Context,
+	executionID, nodeID string,
+	minRank, limit, offset int,
+) ([]*models.ExecutionLog, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM execution_logs
+		WHERE execution_id = $1 AND node_id = $2 AND` + logRankSQL + ` >= $3
+	`
+
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery, executionID, nodeID, minRank).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting node logs: %w", err)
+	}
+
+	query := `
+		SELECT 
+			id, execution_id, node_id, log_level, message, details, timestamp
+		FROM execution_logs
+		WHERE execution_id = $1 AND node_id = $2 AND` + logRankSQL + ` >= $3
+		ORDER BY timestamp
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, executionID, nodeID, minRank, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying node logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*models.ExecutionLog, 0)
+	for rows.Next() {
+		var log models.ExecutionLog
+		err := rows.Scan(
+			&log.ID,
+			&log.ExecutionID,
+			&log.NodeID,
+			&log.LogLevel,
+			&log.Message,
+			&log.Details,
+			&log.Timestamp,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error scanning log row: %w", err)
+		}
+		logs = append(logs, &log)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating log rows: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// SaveNodeDebugData stores the debug data of a node execution
+func (r *PostgresExecutionRepository) SaveNodeDebugData(
+	ctx context.Context,
+	executionID, nodeID string,
+	debugData map[string]interface{},
+) error {
+	query := `
+		INSERT INTO execution_nodes (
+			execution_id, node_id, node_type, status, debug_data
+		) VALUES ($1, $2, '', 'completed', $3)
+		ON CONFLICT (execution_id, node_id)
+		DO UPDATE SET debug_data = EXCLUDED.debug_data
+	`
+
+	_, err := r.db.ExecContext(ctx, query, executionID, nodeID, models.JSONB(debugData))
+	if err != nil {
+		return fmt.Errorf("failed to save node debug data: %w", err)
+	}
+
+	return nil
+}
+
+// GetNodeExecutions gets the node executions of an execution ordered by start time
+func (r *PostgresExecutionRepository) GetNodeExecutions(ctx context.Context, executionID string) ([]*models.ExecutionNode, error) {
+	query := `
+		SELECT
+			execution_id, node_id, node_type, started_at, completed_at, status, error, duration_ms
+		FROM execution_nodes
+		WHERE execution_id = $1
+		ORDER BY started_at NULLS LAST, node_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying node executions: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*models.ExecutionNode
+	for rows.Next() {
+		var node models.ExecutionNode
+		err := rows.Scan(
+			&node.ExecutionID,
+			&node.NodeID,
+			&node.NodeType,
+			&node.StartedAt,
+			&node.CompletedAt,
+			&node.Status,
+			&node.Error,
+			&node.DurationMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning node execution row: %w", err)
+		}
+		nodes = append(nodes, &node)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating node execution rows: %w", err)
+	}
+
+	return nodes, nil
+}
+
+// SaveRecording stores the recorded external inputs of an execution
+func (r *PostgresExecutionRepository) SaveRecording(ctx context.Context, executionID string, recording models.JSONB) error {
+	query := `
+		INSERT INTO execution_recordings (execution_id, recording)
+		VALUES ($1, $2)
+		ON CONFLICT (execution_id)
+		DO UPDATE SET recording = EXCLUDED.recording
+	`
+
+	_, err := r.db.ExecContext(ctx, query, executionID, recording)
+	if err != nil {
+		return fmt.Errorf("failed to save execution recording: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecording gets the recorded external inputs of an execution
+func (r *PostgresExecutionRepository) GetRecording(ctx context.Context, executionID string) (models.JSONB, error) {
+	var recording models.JSONB
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT recording FROM execution_recordings WHERE execution_id = $1",
+		executionID,
+	).Scan(&recording)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no recording for execution: %s", executionID)
+		}
+		return nil, fmt.Errorf("error getting execution recording: %w", err)
+	}
+
+	return recording, nil
+}
+
+// SaveDataFlow stores the recorded data flow of an execution
+func (r *PostgresExecutionRepository) SaveDataFlow(ctx context.Context, executionID string, dataFlow models.JSONB) error {
+	query := `
+		INSERT INTO execution_lineage (execution_id, data_flow)
+		VALUES ($1, $2)
+		ON CONFLICT (execution_id)
+		DO UPDATE SET data_flow = EXCLUDED.data_flow
+	`
+
+	_, err := r.db.ExecContext(ctx, query, executionID, dataFlow)
+	if err != nil {
+		return fmt.Errorf("failed to save execution data flow: %w", err)
+	}
+
+	return nil
+}
+
+// GetDataFlow gets the recorded data flow of an execution
+func (r *PostgresExecutionRepository) GetDataFlow(ctx context.Context, executionID string) (models.JSONB, error) {
+	var dataFlow models.JSONB
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT data_flow FROM execution_lineage WHERE execution_id = $1",
+		executionID,
+	).Scan(&dataFlow)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no data flow for execution: %s", executionID)
+		}
+		return nil, fmt.Errorf("error getting execution data flow: %w", err)
+	}
+
+	return dataFlow, nil
+}
+
+// SaveVariableHistory stores the recorded variable history of an execution
+func (r *PostgresExecutionRepository) SaveVariableHistory(ctx context.Context, executionID string, history models.JSONB) error {
+	query := `
+		INSERT INTO execution_variable_history (execution_id, history)
+		VALUES ($1, $2)
+		ON CONFLICT (execution_id)
+		DO UPDATE SET history = EXCLUDED.history
+	`
+
+	_, err := r.db.ExecContext(ctx, query, executionID, history)
+	if err != nil {
+		return fmt.Errorf("failed to save execution variable history: %w", err)
+	}
+
+	return nil
+}
+
+// GetVariableHistory gets the recorded variable history of an execution
+func (r *PostgresExecutionRepository) GetVariableHistory(ctx context.Context, executionID string) (models.JSONB, error) {
+	var history models.JSONB
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT history FROM execution_variable_history WHERE execution_id = $1",
+		executionID,
+	).Scan(&history)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no variable history for execution: %s", executionID)
+		}
+		return nil, fmt.Errorf("error getting execution variable history: %w", err)
+	}
+
+	return history, nil
+}
+
+// SaveProfile stores the node execution profile of an execution
+func (r *PostgresExecutionRepository) SaveProfile(ctx context.Context, executionID string, profile models.JSONB) error {
+	query := `
+		INSERT INTO execution_profiles (execution_id, profile)
+		VALUES ($1, $2)
+		ON CONFLICT (execution_id)
+		DO UPDATE SET profile = EXCLUDED.profile
+	`
+
+	_, err := r.db.ExecContext(ctx, query, executionID, profile)
+	if err != nil {
+		return fmt.Errorf("failed to save execution profile: %w", err)
+	}
+
+	return nil
+}
+
+// GetProfile gets the node execution profile of an execution
+func (r *PostgresExecutionRepository) GetProfile(ctx context.Context, executionID string) (models.JSONB, error) {
+	var profile models.JSONB
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT profile FROM execution_profiles WHERE execution_id = $1",
+		executionID,
+	).Scan(&profile)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no profile for execution: %s", executionID)
+		}
+		return nil, fmt.Errorf("error getting execution profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// RecordUsage stores the resource usage of an execution
+func (r *PostgresExecutionRepository) RecordUsage(
+	ctx context.Context,
+	executionID string,
+	nodesExecuted int,
+	httpBytes, dbRowsRead int64,
+) error {
+	query := `
+		UPDATE executions
+		SET
+			nodes_executed = $1,
+			http_bytes = $2,
+			db_rows_read = $3
+		WHERE id = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, nodesExecuted, httpBytes, dbRowsRead, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to record execution usage: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("execution not found: %s", executionID)
+	}
+
+	return nil
+}
+
+// RecordRecoveries stores the recovery strategies applied to the node errors of an execution
+func (r *PostgresExecutionRepository) RecordRecoveries(ctx context.Context, executionID string, recoveries models.JSONArray) error {
+	query := `
+		UPDATE executions
+		SET recoveries = $1
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, recoveries, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to record execution recoveries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("execution not found: %s", executionID)
+	}
+
+	return nil
+}
+
+// RecordBudget stores how much of the blueprint's execution budget an execution consumed
+func (r *PostgresExecutionRepository) RecordBudget(ctx context.Context, executionID string, budget models.JSONB) error {
+	query := `
+		UPDATE executions
+		SET budget = $1
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, budget, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to record execution budget: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("execution not found: %s", executionID)
+	}
+
+	return nil
+}
+
+// GetDailyUsage aggregates execution usage of a workspace per day
+func (r *PostgresExecutionRepository) GetDailyUsage(
+	ctx context.Context,
+	workspaceID string,
+	from, to time.Time,
+) ([]*models.ExecutionUsageSummary, error) {
+	query := `
+		SELECT
+			date_trunc('day', e.started_at) AS day,
+			COUNT(*),
+			COALESCE(SUM(e.duration_ms), 0),
+			COALESCE(SUM(e.nodes_executed), 0),
+			COALESCE(SUM(e.http_bytes), 0),
+			COALESCE(SUM(e.db_rows_read), 0)
+		FROM executions e
+		JOIN assets a ON a.id = e.blueprint_id
+		WHERE a.workspace_id = $1 AND e.started_at >= $2 AND e.started_at < $3
+		GROUP BY day
+		ORDER BY day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying execution usage: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*models.ExecutionUsageSummary
+	for rows.Next() {
+		summary := &models.ExecutionUsageSummary{WorkspaceID: workspaceID}
+		err := rows.Scan(
+			&summary.Day,
+			&summary.Executions,
+			&summary.TotalDurationMs,
+			&summary.NodesExecuted,
+			&summary.HTTPBytes,
+			&summary.DBRowsRead,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning usage row: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage rows: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -17,6 +17,12 @@ type PostgresRepositoryFactory struct {
 	workspaceRepo         repository.WorkspaceRepository
 	userRepo              repository.UserRepository
 	executionRepo         repository.ExecutionRepository
+	testRunRepo           repository.TestRunRepository
+	timerRepo             repository.TimerRepository
+	stateRepo             repository.StateRepository
+	crashReportRepo       repository.CrashReportRepository
+	shareLinkRepo         repository.ShareLinkRepository
+	deprecationRepo       repository.DeprecationRepository
 	nodeRepo              repository.NodeRepository
 	eventRepo             repository.EventRepository
 	schemaComponentStore  db.SchemaComponentStore // Added field
@@ -77,6 +83,54 @@ func (f *PostgresRepositoryFactory) GetExecutionRepository() repository.Executio
 	return f.executionRepo
 }
 
+// GetTestRunRepository returns a TestRunRepository implementation
+func (f *PostgresRepositoryFactory) GetTestRunRepository() repository.TestRunRepository {
+	if f.testRunRepo == nil {
+		f.testRunRepo = NewTestRunRepository(f.db)
+	}
+	return f.testRunRepo
+}
+
+// GetTimerRepository returns a TimerRepository implementation
+func (f *PostgresRepositoryFactory) GetTimerRepository() repository.TimerRepository {
+	if f.timerRepo == nil {
+		f.timerRepo = NewTimerRepository(f.db)
+	}
+	return f.timerRepo
+}
+
+// GetStateRepository returns a StateRepository implementation
+func (f *PostgresRepositoryFactory) GetStateRepository() repository.StateRepository {
+	if f.stateRepo == nil {
+		f.stateRepo = NewStateRepository(f.db)
+	}
+	return f.stateRepo
+}
+
+// GetCrashReportRepository returns a CrashReportRepository implementation
+func (f *PostgresRepositoryFactory) GetCrashReportRepository() repository.CrashReportRepository {
+	if f.crashReportRepo == nil {
+		f.crashReportRepo = NewCrashReportRepository(f.db)
+	}
+	return f.crashReportRepo
+}
+
+// GetShareLinkRepository returns a ShareLinkRepository implementation
+func (f *PostgresRepositoryFactory) GetShareLinkRepository() repository.ShareLinkRepository {
+	if f.shareLinkRepo == nil {
+		f.shareLinkRepo = NewShareLinkRepository(f.db)
+	}
+	return f.shareLinkRepo
+}
+
+// GetDeprecationRepository returns a DeprecationRepository implementation
+func (f *PostgresRepositoryFactory) GetDeprecationRepository() repository.DeprecationRepository {
+	if f.deprecationRepo == nil {
+		f.deprecationRepo = NewDeprecationRepository(f.db)
+	}
+	return f.deprecationRepo
+}
+
 func (f *PostgresRepositoryFactory) GetNodeRepository() repository.NodeRepository {
 	if f.nodeRepo == nil {
 		f.nodeRepo = NewPostgresNodeRepository(f.db)
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"webblueprint/internal/paging"
+)
+
+// sortColumn is a column lists can be sorted by, and how the sort keys of its values are
+// written into cursors
+type sortColumn struct {
+	column string
+	isTime bool
+}
+
+// key returns the sort key of a value of the column
+func (c sortColumn) key(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return paging.TimeKey(t)
+	}
+	return fmt.Sprint(value)
+}
+
+// pageClauses returns the condition selecting the rows after the cursor of a page, if it has
+// one, and the order and limit clauses, appending their values to args. One row more than
+// the limit is asked for, which tells whether there's a next page.
+func pageClauses(page paging.Request, columns map[string]sortColumn, idColumn string, args []interface{}) (string, string, []interface{}, error) {
+	sorted, exists := columns[page.Sort.Field]
+	if !exists {
+		return "", "", nil, fmt.Errorf("unknown sort field %q", page.Sort.Field)
+	}
+	direction, comparison := "ASC", ">"
+	if page.Sort.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	condition := ""
+	if page.After != nil {
+		var key interface{} = page.After.Key
+		if sorted.isTime {
+			parsed, err := paging.ParseTimeKey(page.After.Key)
+			if err != nil {
+				return "", "", nil, fmt.Errorf("cursor is malformed")
+			}
+			key = parsed
+		}
+		args = append(args, key, page.After.ID)
+		condition = fmt.Sprintf("(%s, %s) %s ($%d, $%d)", sorted.column, idColumn, comparison, len(args)-1, len(args))
+	}
+
+	args = append(args, page.Limit+1)
+	tail := fmt.Sprintf("ORDER BY %s %s, %s %s LIMIT $%d", sorted.column, direction, idColumn, direction, len(args))
+	return condition, tail, args, nil
+}
+
+// whereClause joins conditions into a WHERE clause, leaving out empty ones
+func whereClause(conditions []string) string {
+	var kept []string
+	for _, condition := range conditions {
+		if condition != "" {
+			kept = append(kept, condition)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(kept, " AND ")
+}
+
+// trimPage drops the row asked for beyond the limit of a page, reporting whether there was one
+func trimPage[T any](rows []T, page paging.Request) ([]T, bool) {
+	if len(rows) > page.Limit {
+		return rows[:page.Limit], true
+	}
+	return rows, false
+}
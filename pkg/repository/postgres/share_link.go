@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// PostgresShareLinkRepository implements ShareLinkRepository using PostgreSQL
+type PostgresShareLinkRepository struct {
+	db *sql.DB
+}
+
+// NewShareLinkRepository creates a new PostgreSQL-based share link repository
+func NewShareLinkRepository(db *sql.DB) repository.ShareLinkRepository {
+	return &PostgresShareLinkRepository{
+		db: db,
+	}
+}
+
+// Create stores a new share link
+func (r *PostgresShareLinkRepository) Create(ctx context.Context, link *models.ShareLink) error {
+	deniedNodeTypes := link.DeniedNodeTypes
+	if deniedNodeTypes == nil {
+		deniedNodeTypes = []string{}
+	}
+	encodedNodeTypes, err := json.Marshal(deniedNodeTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode denied node types: %w", err)
+	}
+
+	query := `
+		INSERT INTO share_links (
+			id, blueprint_id, token_hash, permission, runs_per_minute, denied_node_types,
+			created_by, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		link.ID,
+		link.BlueprintID,
+		link.TokenHash,
+		link.Permission,
+		link.RunsPerMinute,
+		encodedNodeTypes,
+		link.CreatedBy,
+		link.CreatedAt,
+		link.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID gets a share link, or nil if there's none with the ID
+func (r *PostgresShareLinkRepository) GetByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	return r.getOne(ctx, shareLinkColumns+` WHERE id = $1`, id)
+}
+
+// GetByTokenHash gets the share link of a token hash, or nil if there's none
+func (r *PostgresShareLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.ShareLink, error) {
+	return r.getOne(ctx, shareLinkColumns+` WHERE token_hash = $1`, tokenHash)
+}
+
+// getOne gets the share link a query selects, or nil if it selects none
+func (r *PostgresShareLinkRepository) getOne(ctx context.Context, query string, arg string) (*models.ShareLink, error) {
+	link, err := scanShareLink(r.db.QueryRowContext(ctx, query, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting share link: %w", err)
+	}
+
+	return link, nil
+}
+
+// GetByBlueprint gets the share links of a blueprint, newest first, including revoked ones
+func (r *PostgresShareLinkRepository) GetByBlueprint(ctx context.Context, blueprintID string) ([]*models.ShareLink, error) {
+	query := shareLinkColumns + ` WHERE blueprint_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]*models.ShareLink, 0)
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning share link row: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share link rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// Revoke marks a share link as revoked
+func (r *PostgresShareLinkRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	query := `UPDATE share_links SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("share link not found: %s", id)
+	}
+
+	return nil
+}
+
+// AddExecution records an execution started through a share link
+func (r *PostgresShareLinkRepository) AddExecution(ctx context.Context, linkID, executionID string) error {
+	query := `
+		INSERT INTO share_link_executions (share_link_id, execution_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, linkID, executionID); err != nil {
+		return fmt.Errorf("failed to record share link execution: %w", err)
+	}
+
+	return nil
+}
+
+// HasExecution checks if an execution was started through a share link
+func (r *PostgresShareLinkRepository) HasExecution(ctx context.Context, linkID, executionID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM share_link_executions WHERE share_link_id = $1 AND execution_id::text = $2
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, linkID, executionID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error checking share link execution: %w", err)
+	}
+
+	return exists, nil
+}
+
+// shareLinkColumns selects the columns scanShareLink reads
+const shareLinkColumns = `
+	SELECT id, blueprint_id, token_hash, permission, runs_per_minute, denied_node_types,
+		created_by, created_at, expires_at, revoked_at
+	FROM share_links
+`
+
+// scanShareLink reads a share link from a row of shareLinkColumns
+func scanShareLink(row stateRow) (*models.ShareLink, error) {
+	var link models.ShareLink
+	var deniedNodeTypes []byte
+	var expiresAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&link.ID,
+		&link.BlueprintID,
+		&link.TokenHash,
+		&link.Permission,
+		&link.RunsPerMinute,
+		&deniedNodeTypes,
+		&link.CreatedBy,
+		&link.CreatedAt,
+		&expiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(deniedNodeTypes, &link.DeniedNodeTypes); err != nil {
+		return nil, fmt.Errorf("invalid denied node types of share link %s: %w", link.ID, err)
+	}
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	return &link, nil
+}
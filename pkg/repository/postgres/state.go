@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// PostgresStateRepository implements StateRepository using PostgreSQL
+type PostgresStateRepository struct {
+	db *sql.DB
+}
+
+// NewStateRepository creates a new PostgreSQL-based state repository
+func NewStateRepository(db *sql.DB) repository.StateRepository {
+	return &PostgresStateRepository{
+		db: db,
+	}
+}
+
+// Get gets the value of a key, or nil if the key isn't set
+func (r *PostgresStateRepository) Get(ctx context.Context, blueprintID, key string) (*models.BlueprintState, error) {
+	query := `
+		SELECT blueprint_id, key, value, version, updated_by, updated_at
+		FROM blueprint_state
+		WHERE blueprint_id = $1 AND key = $2
+	`
+
+	state, err := scanState(r.db.QueryRowContext(ctx, query, blueprintID, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting blueprint state: %w", err)
+	}
+
+	return state, nil
+}
+
+// Save stores a value if its key is at the expected version, 0 for a key that isn't set
+// and a negative version for any, and sets the new version on the state
+func (r *PostgresStateRepository) Save(ctx context.Context, state *models.BlueprintState, expectedVersion int64) error {
+	value, err := json.Marshal(state.Value)
+	if err != nil {
+		return fmt.Errorf("state value of %s isn't JSON: %w", state.Key, err)
+	}
+
+	var query string
+	args := []interface{}{state.BlueprintID, state.Key, value, state.UpdatedBy}
+	switch {
+	case expectedVersion < 0:
+		query = `
+			INSERT INTO blueprint_state (blueprint_id, key, value, version, updated_by, updated_at)
+			VALUES ($1, $2, $3, 1, $4, NOW())
+			ON CONFLICT (blueprint_id, key) DO UPDATE SET
+				value = EXCLUDED.value,
+				version = blueprint_state.version + 1,
+				updated_by = EXCLUDED.updated_by,
+				updated_at = NOW()
+			RETURNING version, updated_at
+		`
+	case expectedVersion == 0:
+		query = `
+			INSERT INTO blueprint_state (blueprint_id, key, value, version, updated_by, updated_at)
+			VALUES ($1, $2, $3, 1, $4, NOW())
+			ON CONFLICT (blueprint_id, key) DO NOTHING
+			RETURNING version, updated_at
+		`
+	default:
+		query = `
+			UPDATE blueprint_state SET
+				value = $3,
+				version = version + 1,
+				updated_by = $4,
+				updated_at = NOW()
+			WHERE blueprint_id = $1 AND key = $2 AND version = $5
+			RETURNING version, updated_at
+		`
+		args = append(args, expectedVersion)
+	}
+
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&state.Version, &state.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrStateVersionConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save blueprint state: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a key if it's at the expected version, a negative version for any
+func (r *PostgresStateRepository) Delete(ctx context.Context, blueprintID, key string, expectedVersion int64) error {
+	query := `DELETE FROM blueprint_state WHERE blueprint_id = $1 AND key = $2`
+	args := []interface{}{blueprintID, key}
+	if expectedVersion >= 0 {
+		query += ` AND version = $3`
+		args = append(args, expectedVersion)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete blueprint state: %w", err)
+	}
+
+	// Deleting a key that isn't set only conflicts when a version was expected
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 && expectedVersion > 0 {
+		return repository.ErrStateVersionConflict
+	}
+	return nil
+}
+
+// GetByBlueprintID gets all values of a blueprint
+func (r *PostgresStateRepository) GetByBlueprintID(ctx context.Context, blueprintID string) ([]*models.BlueprintState, error) {
+	query := `
+		SELECT blueprint_id, key, value, version, updated_by, updated_at
+		FROM blueprint_state
+		WHERE blueprint_id = $1
+		ORDER BY key
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying blueprint state: %w", err)
+	}
+	defer rows.Close()
+
+	states := make([]*models.BlueprintState, 0)
+	for rows.Next() {
+		state, err := scanState(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning blueprint state row: %w", err)
+		}
+		states = append(states, state)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blueprint state rows: %w", err)
+	}
+
+	return states, nil
+}
+
+// stateRow is a blueprint state row of a query or of a result set
+type stateRow interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanState reads a blueprint state row, decoding its JSON value
+func scanState(row stateRow) (*models.BlueprintState, error) {
+	var state models.BlueprintState
+	var value []byte
+	var updatedBy sql.NullString
+
+	err := row.Scan(&state.BlueprintID, &state.Key, &value, &state.Version, &updatedBy, &state.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	state.UpdatedBy = updatedBy.String
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &state.Value); err != nil {
+			return nil, fmt.Errorf("invalid state value of %s: %w", state.Key, err)
+		}
+	}
+	return &state, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// PostgresTestRunRepository implements TestRunRepository using PostgreSQL
+type PostgresTestRunRepository struct {
+	db *sql.DB
+}
+
+// NewTestRunRepository creates a new PostgreSQL-based test run repository
+func NewTestRunRepository(db *sql.DB) repository.TestRunRepository {
+	return &PostgresTestRunRepository{
+		db: db,
+	}
+}
+
+// Create stores a test run
+func (r *PostgresTestRunRepository) Create(ctx context.Context, run *models.TestRun) error {
+	// Generate ID if not provided
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO blueprint_test_runs (
+			id, blueprint_id, version_id, initiated_by, started_at, passed, results, coverage
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		run.ID,
+		run.BlueprintID,
+		run.VersionID,
+		run.InitiatedBy,
+		run.StartedAt,
+		run.Passed,
+		run.Results,
+		run.Coverage,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create test run: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID gets a test run by ID
+func (r *PostgresTestRunRepository) GetByID(ctx context.Context, id string) (*models.TestRun, error) {
+	query := `
+		SELECT id, blueprint_id, version_id, initiated_by, started_at, passed, results, coverage
+		FROM blueprint_test_runs
+		WHERE id = $1
+	`
+
+	var run models.TestRun
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&run.ID,
+		&run.BlueprintID,
+		&run.VersionID,
+		&run.InitiatedBy,
+		&run.StartedAt,
+		&run.Passed,
+		&run.Results,
+		&run.Coverage,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("test run not found: %s", id)
+		}
+		return nil, fmt.Errorf("error getting test run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// GetByBlueprintID gets the most recent test runs of a blueprint
+func (r *PostgresTestRunRepository) GetByBlueprintID(ctx context.Context, blueprintID string, limit int) ([]*models.TestRun, error) {
+	query := `
+		SELECT id, blueprint_id, version_id, initiated_by, started_at, passed, results, coverage
+		FROM blueprint_test_runs
+		WHERE blueprint_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, blueprintID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying test runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.TestRun, 0)
+	for rows.Next() {
+		var run models.TestRun
+		err := rows.Scan(
+			&run.ID,
+			&run.BlueprintID,
+			&run.VersionID,
+			&run.InitiatedBy,
+			&run.StartedAt,
+			&run.Passed,
+			&run.Results,
+			&run.Coverage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning test run row: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test run rows: %w", err)
+	}
+
+	return runs, nil
+}
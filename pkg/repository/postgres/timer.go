@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// PostgresTimerRepository implements TimerRepository using PostgreSQL
+type PostgresTimerRepository struct {
+	db *sql.DB
+}
+
+// NewTimerRepository creates a new PostgreSQL-based timer repository
+func NewTimerRepository(db *sql.DB) repository.TimerRepository {
+	return &PostgresTimerRepository{
+		db: db,
+	}
+}
+
+// Save creates or replaces the pending activation of a node
+func (r *PostgresTimerRepository) Save(ctx context.Context, timer *models.NodeTimer) error {
+	inputs := timer.Inputs
+	if inputs == nil {
+		inputs = models.JSONB{}
+	}
+
+	query := `
+		INSERT INTO node_timers (
+			blueprint_id, node_id, execution_id, mode, fire_at, triggers, inputs, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (blueprint_id, node_id) DO UPDATE SET
+			execution_id = EXCLUDED.execution_id,
+			mode = EXCLUDED.mode,
+			fire_at = EXCLUDED.fire_at,
+			triggers = EXCLUDED.triggers,
+			inputs = EXCLUDED.inputs,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		timer.BlueprintID,
+		timer.NodeID,
+		timer.ExecutionID,
+		timer.Mode,
+		timer.FireAt,
+		timer.Triggers,
+		inputs,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save node timer: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the pending activation of a node
+func (r *PostgresTimerRepository) Delete(ctx context.Context, blueprintID, nodeID string) error {
+	query := `DELETE FROM node_timers WHERE blueprint_id = $1 AND node_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, blueprintID, nodeID); err != nil {
+		return fmt.Errorf("failed to delete node timer: %w", err)
+	}
+
+	return nil
+}
+
+// GetAll gets all pending activations
+func (r *PostgresTimerRepository) GetAll(ctx context.Context) ([]*models.NodeTimer, error) {
+	query := `
+		SELECT blueprint_id, node_id, execution_id, mode, fire_at, triggers, inputs
+		FROM node_timers
+		ORDER BY fire_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying node timers: %w", err)
+	}
+	defer rows.Close()
+
+	timers := make([]*models.NodeTimer, 0)
+	for rows.Next() {
+		var timer models.NodeTimer
+		err := rows.Scan(
+			&timer.BlueprintID,
+			&timer.NodeID,
+			&timer.ExecutionID,
+			&timer.Mode,
+			&timer.FireAt,
+			&timer.Triggers,
+			&timer.Inputs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning node timer row: %w", err)
+		}
+		timers = append(timers, &timer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating node timer rows: %w", err)
+	}
+
+	return timers, nil
+}
@@ -95,7 +95,7 @@ func (r *PostgresWorkspaceRepository) GetByID(ctx context.Context, id string) (*
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("workspace not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", repository.ErrWorkspaceNotFound, id)
 		}
 		return nil, fmt.Errorf("error retrieving workspace: %w", err)
 	}
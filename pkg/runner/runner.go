@@ -0,0 +1,205 @@
+// Package runner embeds blueprint execution into other Go applications. It runs blueprints
+// with the core node types on an in-process engine, without the API server, the WebSocket
+// or a database; logging and the persistence of node state and timers are pluggable.
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/event"
+	"webblueprint/internal/nodes"
+	"webblueprint/internal/registry"
+	"webblueprint/internal/statestore"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// Execution modes of a runner
+const (
+	ModeActor    = "actor"    // Nodes run as concurrent actors, the default
+	ModeStandard = "standard" // Nodes run one after another
+)
+
+// Logger receives the log of the executions
+type Logger interface {
+	Debug(msg string, fields map[string]interface{})
+	Info(msg string, fields map[string]interface{})
+	Warn(msg string, fields map[string]interface{})
+	Error(msg string, fields map[string]interface{})
+}
+
+// Options configure a runner. The zero value runs blueprints without logging and keeps
+// node state and timers in memory.
+type Options struct {
+	Logger Logger
+
+	// Mode is how the nodes of an execution run, ModeActor unless it is ModeStandard
+	Mode string
+
+	// State persists the values of the state nodes. The state store is shared by the
+	// whole process, so setting it replaces the store of other runners as well.
+	State repository.StateRepository
+
+	// Timers persists the pending activations of debounce and throttle nodes, which
+	// Restore schedules again after a restart
+	Timers repository.TimerRepository
+
+	// MaxDuration bounds every execution on top of the deadline of its context
+	MaxDuration time.Duration
+}
+
+// Result is the outcome of an execution
+type Result struct {
+	ExecutionID string
+	Success     bool
+	Outputs     map[string]map[string]interface{} // Values of the output pins, by node and pin ID
+	Error       error
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// Runner runs blueprints. It is safe for concurrent use.
+type Runner struct {
+	engine      *engine.ExecutionEngine
+	timers      *engineext.TimerService
+	maxDuration time.Duration
+	closeOnce   sync.Once
+}
+
+// New creates a runner with the core node types
+func New(options Options) *Runner {
+	registry.Make()
+	for typeID, factory := range nodes.Core {
+		registry.GetInstance().RegisterNodeType(typeID, factory)
+	}
+
+	var logger Logger = nopLogger{}
+	if options.Logger != nil {
+		logger = options.Logger
+	}
+	if options.State != nil {
+		statestore.SetDefault(statestore.NewStore(options.State))
+	}
+
+	errorManager := bperrors.NewErrorManager()
+	recoveryManager := bperrors.NewRecoveryManager(errorManager)
+
+	flowEngine := engine.NewExecutionEngine(nodeLogger{logger}, engine.NewDebugManager())
+	eventManager := event.NewEventManager(flowEngine)
+	if options.Mode == ModeStandard {
+		flowEngine.SetExecutionMode(engine.ModeStandard)
+	} else {
+		flowEngine.SetExecutionMode(engine.ModeActor)
+	}
+
+	contextManager := engineext.NewContextManager(
+		errorManager,
+		recoveryManager,
+		eventManager.AsEventManagerInterface(),
+		nil,
+	)
+	extensions := engineext.InitializeExtensions(
+		flowEngine,
+		contextManager,
+		errorManager,
+		recoveryManager,
+		eventManager,
+	)
+	extensions.TimerService = engineext.NewTimerService(options.Timers)
+	flowEngine.SetExtensions(extensions)
+
+	for typeID, factory := range nodes.Core {
+		flowEngine.RegisterNodeType(typeID, factory)
+	}
+
+	return &Runner{
+		engine:      flowEngine,
+		timers:      extensions.TimerService,
+		maxDuration: options.MaxDuration,
+	}
+}
+
+// Run executes a blueprint with the values of its variables and waits for it to finish.
+// The error of a failed execution is returned along with its result. When ctx is done
+// first, Run returns its error; like an execution exceeding MaxDuration, the blueprint has
+// no cancellation points and finishes in the background.
+func (r *Runner) Run(ctx context.Context, bp *blueprint.Blueprint, inputs map[string]interface{}) (*Result, error) {
+	if bp == nil {
+		return nil, fmt.Errorf("blueprint is required")
+	}
+	if err := r.engine.LoadBlueprint(bp); err != nil {
+		return nil, fmt.Errorf("failed to load blueprint %s: %w", bp.ID, err)
+	}
+
+	variables := make(map[string]types.Value, len(inputs))
+	for name, value := range inputs {
+		variables[name] = types.NewValue(types.InferPinType(value), value)
+	}
+
+	executionID := uuid.New().String()
+	done := make(chan *Result, 1)
+	go func() {
+		result, err := r.engine.ExecuteWithLimits(engine.PriorityNormal, bp, executionID, variables, engine.ExecutionLimits{
+			MaxDuration: r.maxDuration,
+		})
+		if err != nil && result.Error == nil {
+			result.Error = err
+		}
+		done <- &Result{
+			ExecutionID: executionID,
+			Success:     result.Success && result.Error == nil,
+			Outputs:     result.NodeResults,
+			Error:       result.Error,
+			StartedAt:   result.StartTime,
+			CompletedAt: result.EndTime,
+		}
+	}()
+
+	select {
+	case result := <-done:
+		return result, result.Error
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Restore schedules the debounce and throttle activations that were pending when the
+// application stopped. Lookup returns the blueprints they belong to.
+func (r *Runner) Restore(ctx context.Context, lookup func(blueprintID string) (*blueprint.Blueprint, error)) (int, error) {
+	return r.timers.Restore(ctx, func(blueprintID string) error {
+		bp, err := lookup(blueprintID)
+		if err != nil {
+			return err
+		}
+		return r.engine.LoadBlueprint(bp)
+	})
+}
+
+// Close stops the timers of the runner. Persisted activations stay in the timer
+// repository for Restore.
+func (r *Runner) Close() {
+	r.closeOnce.Do(r.timers.Stop)
+}
+
+// nodeLogger adapts a Logger to the logger of the engine
+type nodeLogger struct {
+	Logger
+}
+
+func (nodeLogger) Opts(map[string]interface{}) {}
+
+// nopLogger discards the log
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, map[string]interface{}) {}
+func (nopLogger) Info(string, map[string]interface{})  {}
+func (nopLogger) Warn(string, map[string]interface{})  {}
+func (nopLogger) Error(string, map[string]interface{}) {}
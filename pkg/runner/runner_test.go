@@ -0,0 +1,156 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/runner"
+)
+
+// fetchBlueprint requests url when it's created and exposes the response on the outputs of
+// the "fetch" node
+func fetchBlueprint(url string) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint("fetch", "Fetch", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{
+		ID:   "fetch",
+		Type: "http-request",
+		Data: map[string]interface{}{"defaults": map[string]interface{}{"url": url}},
+	})
+	bp.AddConnection(blueprint.Connection{ID: "exec-fetch", SourceNodeID: "start", SourcePinID: "then", TargetNodeID: "fetch", TargetPinID: "exec", ConnectionType: "execution"})
+	return bp
+}
+
+// memoryTimers is a timer repository in memory
+type memoryTimers struct {
+	mu     sync.Mutex
+	timers map[string]*models.NodeTimer
+}
+
+func newMemoryTimers(timers ...*models.NodeTimer) *memoryTimers {
+	repo := &memoryTimers{timers: make(map[string]*models.NodeTimer)}
+	for _, timer := range timers {
+		repo.Save(context.Background(), timer)
+	}
+	return repo
+}
+
+func (r *memoryTimers) Save(ctx context.Context, timer *models.NodeTimer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timers[timer.BlueprintID+"/"+timer.NodeID] = timer
+	return nil
+}
+
+func (r *memoryTimers) Delete(ctx context.Context, blueprintID, nodeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.timers, blueprintID+"/"+nodeID)
+	return nil
+}
+
+func (r *memoryTimers) GetAll(ctx context.Context) ([]*models.NodeTimer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	timers := make([]*models.NodeTimer, 0, len(r.timers))
+	for _, timer := range r.timers {
+		timers = append(timers, timer)
+	}
+	return timers, nil
+}
+
+func TestRunExecutesBlueprintsInEveryMode(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	for _, mode := range []string{runner.ModeActor, runner.ModeStandard} {
+		t.Run(mode, func(t *testing.T) {
+			r := runner.New(runner.Options{Mode: mode})
+			defer r.Close()
+
+			result, err := r.Run(context.Background(), fetchBlueprint(server.URL), nil)
+			if err != nil || !result.Success {
+				t.Fatalf("expected the execution to succeed, got %+v (%v)", result, err)
+			}
+			if result.ExecutionID == "" || result.CompletedAt.Before(result.StartedAt) {
+				t.Errorf("expected the execution to be identified and timed, got %+v", result)
+			}
+			if status := result.Outputs["fetch"]["status"]; status != float64(http.StatusOK) {
+				t.Errorf("expected the status of the response in the outputs, got %v", status)
+			}
+		})
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected a request per execution, got %d", requests.Load())
+	}
+}
+
+func TestRunRequiresABlueprint(t *testing.T) {
+	r := runner.New(runner.Options{})
+	defer r.Close()
+
+	if _, err := r.Run(context.Background(), nil, nil); err == nil {
+		t.Error("expected running without a blueprint to fail")
+	}
+}
+
+func TestRunReturnsWhenTheContextIsDone(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	r := runner.New(runner.Options{})
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if result, err := r.Run(ctx, fetchBlueprint(server.URL), nil); !errors.Is(err, context.DeadlineExceeded) || result != nil {
+		t.Errorf("expected the deadline of the context, got %+v (%v)", result, err)
+	}
+}
+
+func TestRestoreSchedulesThePendingTimers(t *testing.T) {
+	fireAt := time.Now().Add(time.Hour)
+	timers := newMemoryTimers(
+		&models.NodeTimer{BlueprintID: "fetch", NodeID: "fetch", ExecutionID: "execution", Mode: "debounce", FireAt: fireAt},
+		&models.NodeTimer{BlueprintID: "deleted", NodeID: "node", ExecutionID: "execution", Mode: "debounce", FireAt: fireAt},
+	)
+	r := runner.New(runner.Options{Timers: timers})
+
+	restored, err := r.Restore(context.Background(), func(blueprintID string) (*blueprint.Blueprint, error) {
+		if blueprintID != "fetch" {
+			return nil, fmt.Errorf("blueprint %s not found", blueprintID)
+		}
+		return fetchBlueprint("http://localhost"), nil
+	})
+	if err != nil || restored != 1 {
+		t.Fatalf("expected the timer of the known blueprint to be restored, got %d (%v)", restored, err)
+	}
+
+	// Closing keeps the restored timer for the next start, but not the dropped one
+	r.Close()
+	r.Close()
+	pending, _ := timers.GetAll(context.Background())
+	if len(pending) != 1 || pending[0].BlueprintID != "fetch" {
+		t.Errorf("expected only the restored timer to stay persisted, got %v", pending)
+	}
+}
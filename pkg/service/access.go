@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/repository"
+)
+
+// User and workspace member roles checked by the access service
+const (
+	RoleAdmin = "admin" // Users administering the server
+
+	MemberRoleOwner  = "owner"
+	MemberRoleAdmin  = "admin"
+	MemberRoleEditor = "editor"
+)
+
+// AccessService decides whether users may administer the server and its workspaces
+type AccessService struct {
+	userRepo      repository.UserRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+// NewAccessService creates a new access service
+func NewAccessService(userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository) *AccessService {
+	return &AccessService{
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+// RequireAdmin fails unless the user administers the server
+func (s *AccessService) RequireAdmin(ctx context.Context, userID string) error {
+	if !s.isAdmin(ctx, userID) {
+		return permissionDenied(userID, "only administrators can perform this action")
+	}
+	return nil
+}
+
+// RequireAdminOfWorkspace fails unless the user administers the server, and with
+// repository.ErrWorkspaceNotFound if the workspace doesn't exist
+func (s *AccessService) RequireAdminOfWorkspace(ctx context.Context, workspaceID, userID string) error {
+	if err := s.RequireAdmin(ctx, userID); err != nil {
+		return err
+	}
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return fmt.Errorf("error retrieving workspace: %w", err)
+	}
+	return nil
+}
+
+// RequireWorkspaceAdmin fails unless the user administers the server, owns the workspace
+// or is one of its owner or admin members
+func (s *AccessService) RequireWorkspaceAdmin(ctx context.Context, workspaceID, userID string) error {
+	allowed, err := s.hasWorkspaceRole(ctx, workspaceID, userID, MemberRoleOwner, MemberRoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return permissionDenied(userID, "only administrators of the workspace can perform this action").
+			WithDetails(map[string]interface{}{"workspaceId": workspaceID})
+	}
+	return nil
+}
+
+// RequireWorkspaceEditor fails unless the user may administer the workspace or edit its assets
+func (s *AccessService) RequireWorkspaceEditor(ctx context.Context, workspaceID, userID string) error {
+	allowed, err := s.hasWorkspaceRole(ctx, workspaceID, userID, MemberRoleOwner, MemberRoleAdmin, MemberRoleEditor)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return permissionDenied(userID, "only editors of the workspace can perform this action").
+			WithDetails(map[string]interface{}{"workspaceId": workspaceID})
+	}
+	return nil
+}
+
+// isAdmin reports whether the user administers the server. Unknown users aren't administrators.
+func (s *AccessService) isAdmin(ctx context.Context, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false
+	}
+	return user.IsActive && user.Role == RoleAdmin
+}
+
+// hasWorkspaceRole reports whether the user administers the server, owns the workspace or
+// is a member with one of the roles
+func (s *AccessService) hasWorkspaceRole(ctx context.Context, workspaceID, userID string, roles ...string) (bool, error) {
+	if s.isAdmin(ctx, userID) {
+		return true, nil
+	}
+	if userID == "" {
+		return false, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return false, fmt.Errorf("workspace not found: %w", err)
+	}
+	if workspace.OwnerType == "user" && workspace.OwnerID == userID {
+		return true, nil
+	}
+
+	members, err := s.workspaceRepo.GetMembers(ctx, workspaceID)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving workspace members: %w", err)
+	}
+	for _, member := range members {
+		if member.UserID != userID {
+			continue
+		}
+		for _, role := range roles {
+			if member.Role == role {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// permissionDenied returns the error of a user that isn't allowed to perform an action
+func permissionDenied(userID, message string) *bperrors.BlueprintError {
+	return bperrors.New(
+		bperrors.ErrorTypePermission,
+		bperrors.ErrPermissionDenied,
+		message,
+		bperrors.SeverityMedium,
+	).WithDetails(map[string]interface{}{"userId": userID})
+}
+
+// IsPermissionDeniedError returns the blueprint error if err denies a user an action
+func IsPermissionDeniedError(err error) (*bperrors.BlueprintError, bool) {
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrPermissionDenied {
+		return nil, false
+	}
+	return bpErr, true
+}
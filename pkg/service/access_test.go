@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAccessService(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	access := NewAccessService(users, workspaces)
+	ctx := context.Background()
+
+	tests := []struct {
+		userID                      string
+		admin, workspaceAdmin, edit bool
+	}{
+		{userID: "admin", admin: true, workspaceAdmin: true, edit: true},
+		{userID: "owner", workspaceAdmin: true, edit: true},
+		{userID: "manager", workspaceAdmin: true, edit: true},
+		{userID: "editor", edit: true},
+		{userID: "viewer"},
+		{userID: "stranger"},
+		{userID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			for _, check := range []struct {
+				name    string
+				err     error
+				allowed bool
+			}{
+				{"admin", access.RequireAdmin(ctx, tt.userID), tt.admin},
+				{"workspace admin", access.RequireWorkspaceAdmin(ctx, "workspace", tt.userID), tt.workspaceAdmin},
+				{"workspace editor", access.RequireWorkspaceEditor(ctx, "workspace", tt.userID), tt.edit},
+			} {
+				if check.allowed && check.err != nil {
+					t.Errorf("%s: expected access, got %v", check.name, check.err)
+				}
+				if !check.allowed {
+					if _, denied := IsPermissionDeniedError(check.err); !denied {
+						t.Errorf("%s: expected a permission error, got %v", check.name, check.err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAccessServiceUnknownWorkspace(t *testing.T) {
+	users, workspaces := newFakeAccessRepos()
+	access := NewAccessService(users, workspaces)
+
+	err := access.RequireWorkspaceEditor(context.Background(), "missing", "owner")
+	if err == nil {
+		t.Fatal("expected an unknown workspace to be reported")
+	}
+	if _, denied := IsPermissionDeniedError(err); denied {
+		t.Errorf("expected a lookup error rather than a permission error, got %v", err)
+	}
+	if err := access.RequireWorkspaceEditor(context.Background(), "missing", "admin"); err != nil {
+		t.Errorf("expected administrators to pass without a lookup, got %v", err)
+	}
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
+	"webblueprint/internal/paging"
+	"webblueprint/internal/requestid"
 	"webblueprint/pkg/blueprint"
 	"webblueprint/pkg/models"
 	"webblueprint/pkg/repository"
@@ -17,8 +20,23 @@ type BlueprintService struct {
 	workspaceRepo repository.WorkspaceRepository
 	assetRepo     repository.AssetRepository
 	executionRepo repository.ExecutionRepository
+
+	// nodePolicyService enforces workspace node policies when set
+	nodePolicyService *NodePolicyService
+
+	// onActivate is called with the blueprint whenever a new version becomes current
+	onActivate func(bp *blueprint.Blueprint)
+
+	// onDelete is called with the ID of a blueprint once it's moved to the trash
+	onDelete func(id string)
+
+	// trashRetention is how long deleted blueprints stay in the trash
+	trashRetention time.Duration
 }
 
+// DefaultTrashRetention is how long deleted blueprints stay in the trash by default
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
 // NewBlueprintService creates a new blueprint service
 func NewBlueprintService(
 	blueprintRepo repository.BlueprintRepository,
@@ -27,11 +45,37 @@ func NewBlueprintService(
 	executionRepo repository.ExecutionRepository,
 ) *BlueprintService {
 	return &BlueprintService{
-		blueprintRepo: blueprintRepo,
-		workspaceRepo: workspaceRepo,
-		assetRepo:     assetRepo,
-		executionRepo: executionRepo,
+		blueprintRepo:  blueprintRepo,
+		workspaceRepo:  workspaceRepo,
+		assetRepo:      assetRepo,
+		executionRepo:  executionRepo,
+		trashRetention: DefaultTrashRetention,
+	}
+}
+
+// SetNodePolicyService enables workspace node policy enforcement for saved blueprints
+func (s *BlueprintService) SetNodePolicyService(nodePolicyService *NodePolicyService) {
+	s.nodePolicyService = nodePolicyService
+}
+
+// SetActivationHandler sets the function called with the blueprint whenever a new version
+// becomes current, e.g. to reload the copies loaded by the engine
+func (s *BlueprintService) SetActivationHandler(onActivate func(bp *blueprint.Blueprint)) {
+	s.onActivate = onActivate
+}
+
+// SetDeletionHandler sets the function called with the ID of a blueprint once it's deleted,
+// e.g. to unload it from the engine
+func (s *BlueprintService) SetDeletionHandler(onDelete func(id string)) {
+	s.onDelete = onDelete
+}
+
+// checkNodePolicies fails if the node policies of a workspace block any node of a blueprint
+func (s *BlueprintService) checkNodePolicies(ctx context.Context, workspaceID string, bp *blueprint.Blueprint) error {
+	if s.nodePolicyService == nil {
+		return nil
 	}
+	return s.nodePolicyService.Check(ctx, workspaceID, bp)
 }
 
 // CreateBlueprint creates a new blueprint from a package blueprint
@@ -47,10 +91,31 @@ func (s *BlueprintService) CreateBlueprint(
 		return "", fmt.Errorf("workspace not found: %w", err)
 	}
 
+	if err := s.checkNodePolicies(ctx, workspaceID, bp); err != nil {
+		return "", err
+	}
+
+	blueprintModel, err := s.newBlueprintModel(bp, workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	// Create the blueprint
+	err = s.blueprintRepo.Create(ctx, blueprintModel)
+	if err != nil {
+		return "", fmt.Errorf("error creating blueprint: %w", err)
+	}
+
+	return blueprintModel.ID, nil
+}
+
+// newBlueprintModel converts a package blueprint to the database model of a new blueprint
+// of a workspace, with the blueprint as its first version
+func (s *BlueprintService) newBlueprintModel(bp *blueprint.Blueprint, workspaceID, userID string) (*models.Blueprint, error) {
 	// Convert package blueprint to database model
 	blueprintModel, versionModel, err := s.blueprintRepo.FromPkgBlueprint(bp)
 	if err != nil {
-		return "", fmt.Errorf("error converting blueprint: %w", err)
+		return nil, fmt.Errorf("error converting blueprint: %w", err)
 	}
 
 	// Set additional metadata
@@ -70,13 +135,7 @@ func (s *BlueprintService) CreateBlueprint(
 	blueprintModel.CurrentVersionID.String = versionModel.ID
 	blueprintModel.CurrentVersionID.Valid = true
 
-	// Create the blueprint
-	err = s.blueprintRepo.Create(ctx, blueprintModel)
-	if err != nil {
-		return "", fmt.Errorf("error creating blueprint: %w", err)
-	}
-
-	return blueprintModel.ID, nil
+	return blueprintModel, nil
 }
 
 // GetBlueprint ...
@@ -165,6 +224,26 @@ func (s *BlueprintService) GetAll(ctx context.Context, limit, offset int) ([]*bl
 	return blueprints, nil
 }
 
+// ListBlueprints gets a page of the blueprints matching a filter
+func (s *BlueprintService) ListBlueprints(ctx context.Context, filter repository.BlueprintFilter, page paging.Request) (paging.Page[*blueprint.Blueprint], error) {
+	var result paging.Page[*blueprint.Blueprint]
+	models, err := s.blueprintRepo.List(ctx, filter, page)
+	if err != nil {
+		return result, fmt.Errorf("error retrieving blueprints: %w", err)
+	}
+
+	result.Total, result.Next = models.Total, models.Next
+	result.Items = make([]*blueprint.Blueprint, 0, len(models.Items))
+	for _, blueprintModel := range models.Items {
+		pkgBlueprint, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
+		if err != nil {
+			return result, fmt.Errorf("error converting blueprint: %w", err)
+		}
+		result.Items = append(result.Items, pkgBlueprint)
+	}
+	return result, nil
+}
+
 // SaveVersion saves a new version of a blueprint
 func (s *BlueprintService) SaveVersion(
 	ctx context.Context,
@@ -174,11 +253,15 @@ func (s *BlueprintService) SaveVersion(
 	userID string,
 ) (int, error) {
 	// Get the current blueprint model
-	_, err := s.blueprintRepo.GetByID(ctx, blueprintID) // ?
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
 	if err != nil {
 		return 0, fmt.Errorf("error retrieving blueprint: %w", err)
 	}
 
+	if err := s.checkNodePolicies(ctx, blueprintModel.WorkspaceID, bp); err != nil {
+		return 0, err
+	}
+
 	// Convert package blueprint to version model
 	_, versionModel, err := s.blueprintRepo.FromPkgBlueprint(bp)
 	if err != nil {
@@ -211,9 +294,39 @@ func (s *BlueprintService) SaveVersion(
 		return 0, fmt.Errorf("error creating version: %w", err)
 	}
 
+	if s.onActivate != nil {
+		// The activated blueprint is identified like the ones read back from the repository
+		activated := bp.Clone()
+		activated.ID = blueprintID
+		activated.Version = strconv.Itoa(nextVersion)
+		s.onActivate(activated)
+	}
+
 	return nextVersion, nil
 }
 
+// UpdateBlueprint changes the current version of a blueprint and saves the result as a new
+// version, which the engine reloads. Blueprints loaded by the engine are copies that never
+// change, so this is how a change reaches executions: those in flight finish on the version
+// they started with.
+func (s *BlueprintService) UpdateBlueprint(
+	ctx context.Context,
+	blueprintID string,
+	change func(bp *blueprint.Blueprint) error,
+	comment string,
+	userID string,
+) (int, error) {
+	// The blueprint is read back from the repository, so the change has it to itself
+	bp, err := s.GetBlueprint(ctx, blueprintID)
+	if err != nil {
+		return 0, err
+	}
+	if err := change(bp); err != nil {
+		return 0, err
+	}
+	return s.SaveVersion(ctx, blueprintID, bp, comment, userID)
+}
+
 // GetVersion gets a specific version of a blueprint
 func (s *BlueprintService) GetVersion(
 	ctx context.Context,
@@ -263,9 +376,64 @@ func (s *BlueprintService) GetVersions(ctx context.Context, blueprintID string)
 	return versionInfos, nil
 }
 
-// DeleteBlueprint deletes a blueprint
+// DeleteBlueprint moves a blueprint to the trash, where it can be restored until it's purged
 func (s *BlueprintService) DeleteBlueprint(ctx context.Context, id string) error {
-	return s.blueprintRepo.Delete(ctx, id)
+	if err := s.blueprintRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if s.onDelete != nil {
+		s.onDelete(id)
+	}
+	return nil
+}
+
+// TrashedBlueprint describes a blueprint in the trash
+type TrashedBlueprint struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspaceId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	PurgeAt     time.Time `json:"purgeAt"`
+}
+
+// SetTrashRetention sets how long deleted blueprints stay in the trash before they're purged
+func (s *BlueprintService) SetTrashRetention(retention time.Duration) {
+	s.trashRetention = retention
+}
+
+// GetTrash gets the blueprints of a workspace that are in the trash
+func (s *BlueprintService) GetTrash(ctx context.Context, workspaceID string) ([]TrashedBlueprint, error) {
+	blueprintModels, err := s.blueprintRepo.GetTrashed(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving trashed blueprints: %w", err)
+	}
+
+	trash := make([]TrashedBlueprint, 0, len(blueprintModels))
+	for _, blueprintModel := range blueprintModels {
+		trash = append(trash, TrashedBlueprint{
+			ID:          blueprintModel.ID,
+			WorkspaceID: blueprintModel.WorkspaceID,
+			Name:        blueprintModel.Name,
+			Description: blueprintModel.Description.String,
+			DeletedAt:   blueprintModel.DeletedAt.Time,
+			PurgeAt:     blueprintModel.DeletedAt.Time.Add(s.trashRetention),
+		})
+	}
+
+	return trash, nil
+}
+
+// RestoreBlueprint restores a blueprint from the trash
+func (s *BlueprintService) RestoreBlueprint(ctx context.Context, id string) error {
+	return s.blueprintRepo.Restore(ctx, id)
+}
+
+// PurgeTrash permanently deletes the blueprints that have been in the trash longer than
+// the retention period, along with their versions and executions
+func (s *BlueprintService) PurgeTrash(ctx context.Context) (int, error) {
+	return s.blueprintRepo.Purge(ctx, time.Now().Add(-s.trashRetention))
 }
 
 // ExecuteBlueprint executes a blueprint
@@ -284,6 +452,7 @@ func (s *BlueprintService) ExecuteBlueprint(
 		InitiatedBy:      userID,
 		ExecutionMode:    "standard",
 		InitialVariables: models.JSONB(initialVariables),
+		RequestID:        models.NullString(requestid.FromContext(ctx)),
 	}
 
 	// Set current version ID if available
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"webblueprint/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Errors of blueprint duplication
+var (
+	ErrDuplicateSourceNotFound    = errors.New("blueprint to duplicate not found")
+	ErrDuplicateWorkspaceNotFound = errors.New("workspace of the copy not found")
+)
+
+// DuplicateOptions configures the copy made by DuplicateBlueprint
+type DuplicateOptions struct {
+	// WorkspaceID is the workspace of the copy, the workspace of the original if empty
+	WorkspaceID string
+	// Name is the name of the copy. If empty, copies within the workspace of the original
+	// are named "<name> (copy)" and copies into other workspaces keep the name.
+	Name string
+	// NewNodeIDs gives the nodes of the copy new IDs, e.g. so that logs and debug data of
+	// the copy can't be mistaken for those of the original
+	NewNodeIDs bool
+}
+
+// DuplicateBlueprint copies the current version of a blueprint, with its variables,
+// functions and event definitions, into a new blueprint and returns its ID. The copy starts
+// at version 1 and keeps the description, tags, category and template flag of the original.
+// Names taken in the workspace, including by blueprints in its trash, get a number, e.g.
+// "Orders (copy 2)". Event bindings get new IDs, as binding IDs are unique across blueprints.
+func (s *BlueprintService) DuplicateBlueprint(ctx context.Context, id string, options DuplicateOptions, userID string) (string, error) {
+	source, err := s.blueprintRepo.GetByID(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDuplicateSourceNotFound, err)
+	}
+	original, err := s.blueprintRepo.ToPkgBlueprint(source, source.CurrentVersion)
+	if err != nil {
+		return "", fmt.Errorf("error converting blueprint: %w", err)
+	}
+
+	workspaceID := options.WorkspaceID
+	if workspaceID == "" {
+		workspaceID = source.WorkspaceID
+	}
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDuplicateWorkspaceNotFound, err)
+	}
+
+	name := options.Name
+	if name == "" {
+		name = original.Name
+		if workspaceID == source.WorkspaceID {
+			name += " (copy)"
+		}
+	}
+	name, err = s.availableName(ctx, workspaceID, name)
+	if err != nil {
+		return "", err
+	}
+
+	bp := original.Clone()
+	bp.ID = ""
+	bp.Name = name
+	bp.Version = "1"
+	if options.NewNodeIDs {
+		bp.ReplaceNodeIDs(func() string { return uuid.New().String() })
+	}
+	for i := range bp.EventBindings {
+		bp.EventBindings[i].ID = uuid.New().String()
+	}
+
+	if err := s.checkNodePolicies(ctx, workspaceID, bp); err != nil {
+		return "", err
+	}
+
+	blueprintModel, err := s.newBlueprintModel(bp, workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+	blueprintModel.Tags = append(models.StringArray{}, source.Tags...)
+	blueprintModel.Category = source.Category
+	blueprintModel.IsTemplate = source.IsTemplate
+	blueprintModel.ThumbnailURL = source.ThumbnailURL
+
+	if err := s.blueprintRepo.Create(ctx, blueprintModel); err != nil {
+		return "", fmt.Errorf("error creating blueprint: %w", err)
+	}
+	return blueprintModel.ID, nil
+}
+
+// availableName returns a name no blueprint of a workspace has, including those in its
+// trash: the name itself, or the name with the first free number
+func (s *BlueprintService) availableName(ctx context.Context, workspaceID, name string) (string, error) {
+	blueprints, err := s.blueprintRepo.GetByWorkspaceID(ctx, workspaceID)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving blueprints: %w", err)
+	}
+	trashed, err := s.blueprintRepo.GetTrashed(ctx, workspaceID)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving trash: %w", err)
+	}
+
+	taken := make(map[string]bool, len(blueprints)+len(trashed))
+	for _, bp := range append(blueprints, trashed...) {
+		taken[bp.Name] = true
+	}
+	if !taken[name] {
+		return name, nil
+	}
+
+	// "Orders (copy)" continues as "Orders (copy 2)", "Orders" as "Orders (2)"
+	escaped := strings.ReplaceAll(name, "%", "%%")
+	format := escaped + " (%d)"
+	if strings.HasSuffix(escaped, ")") {
+		format = strings.TrimSuffix(escaped, ")") + " %d)"
+	}
+	for number := 2; ; number++ {
+		if candidate := fmt.Sprintf(format, number); !taken[candidate] {
+			return candidate, nil
+		}
+	}
+}
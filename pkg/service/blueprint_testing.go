@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"webblueprint/internal/bptest"
+	"webblueprint/internal/node"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// AssetTypeBlueprintTest is the asset type under which test definitions are stored
+const AssetTypeBlueprintTest = "blueprint_test"
+
+// defaultTestRunLimit bounds the number of test runs listed for a blueprint
+const defaultTestRunLimit = 20
+
+// BlueprintTestRun is a stored run of the tests of a blueprint, with the coverage
+// of all its suites combined
+type BlueprintTestRun struct {
+	ID          string                `json:"id"`
+	BlueprintID string                `json:"blueprintId"`
+	VersionID   string                `json:"versionId,omitempty"`
+	InitiatedBy string                `json:"initiatedBy"`
+	StartedAt   time.Time             `json:"startedAt"`
+	Passed      bool                  `json:"passed"`
+	Suites      []*bptest.SuiteResult `json:"suites"`
+	Coverage    *bptest.Coverage      `json:"coverage"`
+}
+
+// BlueprintTestService stores blueprint test definitions as assets, runs them
+// and keeps the results and coverage of every run
+type BlueprintTestService struct {
+	blueprintRepo repository.BlueprintRepository
+	assetRepo     repository.AssetRepository
+	testRunRepo   repository.TestRunRepository
+	runner        *bptest.Runner
+}
+
+// NewBlueprintTestService creates a new blueprint test service
+func NewBlueprintTestService(
+	blueprintRepo repository.BlueprintRepository,
+	assetRepo repository.AssetRepository,
+	testRunRepo repository.TestRunRepository,
+	logger node.Logger,
+) *BlueprintTestService {
+	return &BlueprintTestService{
+		blueprintRepo: blueprintRepo,
+		assetRepo:     assetRepo,
+		testRunRepo:   testRunRepo,
+		runner:        bptest.NewRunner(logger),
+	}
+}
+
+// CreateTest stores a test definition for a blueprint in the blueprint's workspace
+func (s *BlueprintTestService) CreateTest(
+	ctx context.Context,
+	blueprintID string,
+	def *bptest.Definition,
+	userID string,
+) (*bptest.Definition, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("test name is required")
+	}
+
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("blueprint not found: %w", err)
+	}
+
+	def.ID = ""
+	def.BlueprintID = blueprintID
+
+	metadata, err := definitionToMetadata(def)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &models.Asset{
+		WorkspaceID: blueprintModel.WorkspaceID,
+		Name:        def.Name,
+		Description: sql.NullString{String: def.Description, Valid: def.Description != ""},
+		Type:        AssetTypeBlueprintTest,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+		Tags:        models.StringArray{},
+		Metadata:    metadata,
+	}
+
+	if err := s.assetRepo.Create(ctx, asset); err != nil {
+		return nil, fmt.Errorf("error creating test: %w", err)
+	}
+
+	def.ID = asset.ID
+	return def, nil
+}
+
+// GetTests returns the test definitions of a blueprint
+func (s *BlueprintTestService) GetTests(ctx context.Context, blueprintID string) ([]*bptest.Definition, error) {
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("blueprint not found: %w", err)
+	}
+
+	assets, err := s.assetRepo.GetByWorkspaceID(ctx, blueprintModel.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving tests: %w", err)
+	}
+
+	definitions := make([]*bptest.Definition, 0)
+	for _, asset := range assets {
+		if asset.Type != AssetTypeBlueprintTest {
+			continue
+		}
+
+		def, err := assetToDefinition(asset)
+		if err != nil {
+			return nil, err
+		}
+		if def.BlueprintID == blueprintID {
+			definitions = append(definitions, def)
+		}
+	}
+
+	return definitions, nil
+}
+
+// RunTests runs the test definitions of a blueprint against its current version and
+// stores the results with their coverage. When testIDs is empty every test runs.
+func (s *BlueprintTestService) RunTests(ctx context.Context, blueprintID string, testIDs []string, userID string) (*BlueprintTestRun, error) {
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("blueprint not found: %w", err)
+	}
+
+	bp, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blueprint: %w", err)
+	}
+
+	definitions, err := s.GetTests(ctx, blueprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(testIDs) > 0 {
+		selected := make(map[string]bool, len(testIDs))
+		for _, id := range testIDs {
+			selected[id] = true
+		}
+
+		filtered := make([]*bptest.Definition, 0, len(testIDs))
+		for _, def := range definitions {
+			if selected[def.ID] {
+				filtered = append(filtered, def)
+				delete(selected, def.ID)
+			}
+		}
+		for id := range selected {
+			return nil, fmt.Errorf("test not found: %s", id)
+		}
+		definitions = filtered
+	}
+
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("blueprint has no tests")
+	}
+
+	run := &BlueprintTestRun{
+		BlueprintID: blueprintID,
+		InitiatedBy: userID,
+		StartedAt:   time.Now(),
+		Passed:      true,
+		Suites:      make([]*bptest.SuiteResult, 0, len(definitions)),
+		Coverage:    bptest.NewCoverage(bp),
+	}
+	if blueprintModel.CurrentVersionID.Valid {
+		run.VersionID = blueprintModel.CurrentVersionID.String
+	}
+
+	for _, def := range definitions {
+		result := s.runner.Run(bp, def)
+		if result.Failures > 0 || result.Errors > 0 {
+			run.Passed = false
+		}
+		run.Coverage.Merge(result.Coverage)
+		run.Suites = append(run.Suites, result)
+	}
+
+	runModel, err := testRunToModel(run)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.testRunRepo.Create(ctx, runModel); err != nil {
+		return nil, fmt.Errorf("error storing test run: %w", err)
+	}
+
+	run.ID = runModel.ID
+	return run, nil
+}
+
+// GetTestRun returns a stored test run
+func (s *BlueprintTestService) GetTestRun(ctx context.Context, id string) (*BlueprintTestRun, error) {
+	runModel, err := s.testRunRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return modelToTestRun(runModel)
+}
+
+// GetTestRuns returns the most recent test runs of a blueprint
+func (s *BlueprintTestService) GetTestRuns(ctx context.Context, blueprintID string, limit int) ([]*BlueprintTestRun, error) {
+	if limit <= 0 {
+		limit = defaultTestRunLimit
+	}
+
+	runModels, err := s.testRunRepo.GetByBlueprintID(ctx, blueprintID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving test runs: %w", err)
+	}
+
+	runs := make([]*BlueprintTestRun, 0, len(runModels))
+	for _, runModel := range runModels {
+		run, err := modelToTestRun(runModel)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// GetLatestCoverage returns the coverage of the most recent test run of a blueprint
+func (s *BlueprintTestService) GetLatestCoverage(ctx context.Context, blueprintID string) (*bptest.Coverage, error) {
+	runs, err := s.GetTestRuns(ctx, blueprintID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("blueprint has no test runs")
+	}
+
+	return runs[0].Coverage, nil
+}
+
+// testRunToModel encodes a test run for storage
+func testRunToModel(run *BlueprintTestRun) (*models.TestRun, error) {
+	var results models.JSONB
+	if err := convertJSON(map[string]interface{}{"suites": run.Suites}, &results); err != nil {
+		return nil, fmt.Errorf("failed to encode test results: %w", err)
+	}
+
+	var coverage models.JSONB
+	if err := convertJSON(run.Coverage, &coverage); err != nil {
+		return nil, fmt.Errorf("failed to encode test coverage: %w", err)
+	}
+
+	return &models.TestRun{
+		ID:          run.ID,
+		BlueprintID: run.BlueprintID,
+		VersionID:   sql.NullString{String: run.VersionID, Valid: run.VersionID != ""},
+		InitiatedBy: run.InitiatedBy,
+		StartedAt:   run.StartedAt,
+		Passed:      run.Passed,
+		Results:     results,
+		Coverage:    coverage,
+	}, nil
+}
+
+// modelToTestRun decodes a stored test run
+func modelToTestRun(runModel *models.TestRun) (*BlueprintTestRun, error) {
+	run := &BlueprintTestRun{
+		ID:          runModel.ID,
+		BlueprintID: runModel.BlueprintID,
+		InitiatedBy: runModel.InitiatedBy,
+		StartedAt:   runModel.StartedAt,
+		Passed:      runModel.Passed,
+	}
+	if runModel.VersionID.Valid {
+		run.VersionID = runModel.VersionID.String
+	}
+
+	var results struct {
+		Suites []*bptest.SuiteResult `json:"suites"`
+	}
+	if err := convertJSON(runModel.Results, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode test run %s: %w", runModel.ID, err)
+	}
+	run.Suites = results.Suites
+
+	if err := convertJSON(runModel.Coverage, &run.Coverage); err != nil {
+		return nil, fmt.Errorf("failed to decode test run %s: %w", runModel.ID, err)
+	}
+
+	return run, nil
+}
+
+// convertJSON converts a value to another type by round-tripping it through JSON
+func convertJSON(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// definitionToMetadata encodes a test definition as asset metadata
+func definitionToMetadata(def *bptest.Definition) (models.JSONB, error) {
+	data, err := json.Marshal(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode test: %w", err)
+	}
+
+	var metadata models.JSONB
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to encode test: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// assetToDefinition decodes a test definition from its asset
+func assetToDefinition(asset *models.Asset) (*bptest.Definition, error) {
+	data, err := json.Marshal(asset.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode test %s: %w", asset.ID, err)
+	}
+
+	var def bptest.Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to decode test %s: %w", asset.ID, err)
+	}
+
+	def.ID = asset.ID
+	def.Name = asset.Name
+	if asset.Description.Valid {
+		def.Description = asset.Description.String
+	}
+
+	return &def, nil
+}
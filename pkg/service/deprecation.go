@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// Deprecations announce that a blueprint, or one of its versions, is going away. Executions
+// of it keep running with a warning in their logs until the sunset date of the deprecation;
+// from then on they're blocked, unless they're started with WithSunsetOverride. Triggers
+// whose response nobody reads, like share links and services, also notify about them.
+
+// ErrDeprecationNotFound is returned for a blueprint or version that isn't deprecated
+var ErrDeprecationNotFound = errors.New("blueprint isn't deprecated")
+
+// Triggers of executions, reported with deprecation warnings
+const (
+	TriggerManual    = "manual"     // Started through the API or the editor
+	TriggerBatch     = "batch"      // An item of a batch
+	TriggerShareLink = "share-link" // Started through a share link
+	TriggerService   = "service"    // The start or a restart of a service
+)
+
+// DeprecationOptions configures the deprecation of a blueprint or version
+type DeprecationOptions struct {
+	VersionNumber int        `json:"versionNumber"` // Version to deprecate, every version if 0
+	Message       string     `json:"message"`       // What to use instead
+	SunsetAt      *time.Time `json:"sunsetAt"`      // Executions are blocked from then on, never if nil
+}
+
+// DeprecationWarning reports an execution attempt of a deprecated blueprint
+type DeprecationWarning struct {
+	BlueprintID   string     `json:"blueprintId"`
+	VersionNumber int        `json:"versionNumber"` // Version of the execution, 0 if the blueprint has none
+	Trigger       string     `json:"trigger"`
+	Message       string     `json:"message"`
+	SunsetAt      *time.Time `json:"sunsetAt,omitempty"`
+	Blocked       bool       `json:"blocked"`    // The sunset passed and the execution didn't start
+	Overridden    bool       `json:"overridden"` // The sunset passed and the execution overrode it
+}
+
+// DeprecationService manages the deprecations of blueprints and their versions
+type DeprecationService struct {
+	deprecationRepo repository.DeprecationRepository
+	blueprintRepo   repository.BlueprintRepository
+}
+
+// NewDeprecationService creates a new deprecation service
+func NewDeprecationService(
+	deprecationRepo repository.DeprecationRepository,
+	blueprintRepo repository.BlueprintRepository,
+) *DeprecationService {
+	return &DeprecationService{
+		deprecationRepo: deprecationRepo,
+		blueprintRepo:   blueprintRepo,
+	}
+}
+
+// Deprecate deprecates a blueprint, or one of its versions, replacing the deprecation it had
+func (s *DeprecationService) Deprecate(ctx context.Context, blueprintID, userID string, options DeprecationOptions) (*models.BlueprintDeprecation, error) {
+	message := strings.TrimSpace(options.Message)
+	if message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if options.VersionNumber < 0 {
+		return nil, fmt.Errorf("versionNumber must be a positive version number, or 0 for every version")
+	}
+
+	// The blueprint, and the version, must exist
+	if _, err := s.blueprintRepo.GetByID(ctx, blueprintID); err != nil {
+		return nil, fmt.Errorf("blueprint not found: %w", err)
+	}
+	if options.VersionNumber > 0 {
+		if _, err := s.blueprintRepo.GetVersion(ctx, blueprintID, options.VersionNumber); err != nil {
+			return nil, fmt.Errorf("version not found: %w", err)
+		}
+	}
+
+	deprecation := &models.BlueprintDeprecation{
+		BlueprintID:   blueprintID,
+		VersionNumber: options.VersionNumber,
+		Message:       message,
+		SunsetAt:      options.SunsetAt,
+		DeprecatedBy:  userID,
+		DeprecatedAt:  time.Now().UTC(),
+	}
+	if err := s.deprecationRepo.Set(ctx, deprecation); err != nil {
+		return nil, err
+	}
+	return deprecation, nil
+}
+
+// GetDeprecations returns the deprecations of a blueprint and of its versions, by version
+func (s *DeprecationService) GetDeprecations(ctx context.Context, blueprintID string) ([]*models.BlueprintDeprecation, error) {
+	return s.deprecationRepo.GetByBlueprint(ctx, blueprintID)
+}
+
+// Undeprecate withdraws the deprecation of a version, or of every version if the number is 0
+func (s *DeprecationService) Undeprecate(ctx context.Context, blueprintID string, versionNumber int) error {
+	deprecation, err := s.deprecationRepo.Get(ctx, blueprintID, versionNumber)
+	if err != nil {
+		return err
+	}
+	if deprecation == nil {
+		return ErrDeprecationNotFound
+	}
+	return s.deprecationRepo.Delete(ctx, blueprintID, versionNumber)
+}
+
+// Find returns the deprecation that applies to a version of a blueprint, or nil if there's
+// none. When both the version and every version are deprecated, the one sunsetting first
+// applies.
+func (s *DeprecationService) Find(ctx context.Context, blueprintID string, versionNumber int) (*models.BlueprintDeprecation, error) {
+	deprecations, err := s.deprecationRepo.GetByBlueprint(ctx, blueprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	var own, all *models.BlueprintDeprecation
+	for _, deprecation := range deprecations {
+		switch deprecation.VersionNumber {
+		case 0:
+			all = deprecation
+		case versionNumber:
+			own = deprecation
+		}
+	}
+	return firstSunset(own, all), nil
+}
+
+// firstSunset returns the deprecation that sunsets first, a if they sunset together
+func firstSunset(a, b *models.BlueprintDeprecation) *models.BlueprintDeprecation {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if b.SunsetAt != nil && (a.SunsetAt == nil || b.SunsetAt.Before(*a.SunsetAt)) {
+		return b
+	}
+	return a
+}
+
+// sunsetOverrideKey is the context key of executions that run past the sunset
+type sunsetOverrideKey struct{}
+
+// WithSunsetOverride returns a copy of ctx with which executions of deprecated blueprints
+// start past their sunset date, with a warning
+func WithSunsetOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sunsetOverrideKey{}, true)
+}
+
+// sunsetOverridden checks if executions started with a context run past the sunset
+func sunsetOverridden(ctx context.Context) bool {
+	overridden, _ := ctx.Value(sunsetOverrideKey{}).(bool)
+	return overridden
+}
+
+// IsBlueprintSunsetError checks if an error is an execution blocked by the sunset of its
+// blueprint
+func IsBlueprintSunsetError(err error) (*bperrors.BlueprintError, bool) {
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrBlueprintSunset {
+		return nil, false
+	}
+	return bpErr, true
+}
+
+// SetDeprecationService enables deprecation warnings and sunsets for executions
+func (s *ExecutionService) SetDeprecationService(deprecationService *DeprecationService) {
+	s.deprecationService = deprecationService
+}
+
+// checkDeprecation returns the deprecation of the version an execution would run, and fails
+// with an ErrBlueprintSunset error once its sunset passed, unless the context overrides it.
+// Triggers other than manual ones notify about every attempt.
+func (s *ExecutionService) checkDeprecation(
+	ctx context.Context,
+	blueprintModel *models.Blueprint,
+	versionNumber int,
+	trigger string,
+) (*models.BlueprintDeprecation, error) {
+	if s.deprecationService == nil {
+		return nil, nil
+	}
+	deprecation, err := s.deprecationService.Find(ctx, blueprintModel.ID, versionNumber)
+	if err != nil || deprecation == nil {
+		return nil, err
+	}
+
+	sunset := deprecation.SunsetPassed(time.Now())
+	overridden := sunset && sunsetOverridden(ctx)
+	if trigger != TriggerManual && s.OnDeprecationHook != nil {
+		s.OnDeprecationHook(DeprecationWarning{
+			BlueprintID:   blueprintModel.ID,
+			VersionNumber: versionNumber,
+			Trigger:       trigger,
+			Message:       deprecation.Message,
+			SunsetAt:      deprecation.SunsetAt,
+			Blocked:       sunset && !overridden,
+			Overridden:    overridden,
+		})
+	}
+	if !sunset || overridden {
+		return deprecation, nil
+	}
+
+	slog.WarnContext(ctx, "Blocked an execution of a blueprint past its sunset",
+		"blueprintId", blueprintModel.ID, "version", versionNumber, "trigger", trigger)
+
+	subject := "blueprint " + blueprintModel.ID
+	if deprecation.VersionNumber > 0 {
+		subject = fmt.Sprintf("version %d of blueprint %s", deprecation.VersionNumber, blueprintModel.ID)
+	}
+	sunsetAt := deprecation.SunsetAt.UTC().Format(time.RFC3339)
+	return nil, bperrors.New(
+		bperrors.ErrorTypePermission,
+		bperrors.ErrBlueprintSunset,
+		fmt.Sprintf("%s reached its sunset on %s: %s", subject, sunsetAt, deprecation.Message),
+		bperrors.SeverityHigh,
+	).WithBlueprintInfo(blueprintModel.ID, "").WithDetails(map[string]interface{}{
+		"versionNumber": deprecation.VersionNumber,
+		"sunsetAt":      sunsetAt,
+		"deprecation":   deprecation.Message,
+	})
+}
+
+// warnDeprecated logs the deprecation of its version to a recorded execution
+func (s *ExecutionService) warnDeprecated(ctx context.Context, executionID string, deprecation *models.BlueprintDeprecation, trigger string) {
+	if deprecation == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"versionNumber": deprecation.VersionNumber,
+		"deprecation":   deprecation.Message,
+		"trigger":       trigger,
+	}
+	message := "blueprint is deprecated"
+	if deprecation.SunsetAt != nil {
+		details["sunsetAt"] = deprecation.SunsetAt.UTC().Format(time.RFC3339)
+		if deprecation.SunsetPassed(time.Now()) {
+			message = "blueprint is past its sunset, running with an override"
+			details["overridden"] = true
+		}
+	}
+	s.AddLogEntry(ctx, executionID, "", "warn", message, details)
+}
+
+// versionNumberOf returns the number of a version, 0 if the blueprint has none
+func versionNumberOf(version *models.BlueprintVersion) int {
+	if version == nil {
+		return 0
+	}
+	return version.VersionNumber
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"webblueprint/pkg/models"
+)
+
+// deprecationOf deprecates a version of the blueprint "bp", every version if 0, with the
+// message the tests tell the deprecations apart by
+func deprecationOf(versionNumber int, message string, sunsetAt *time.Time) *models.BlueprintDeprecation {
+	return &models.BlueprintDeprecation{
+		BlueprintID:   "bp",
+		VersionNumber: versionNumber,
+		Message:       message,
+		SunsetAt:      sunsetAt,
+	}
+}
+
+// newDeprecationTestService creates a deprecation service of the blueprint "bp" with the
+// given deprecations
+func newDeprecationTestService(t *testing.T, deprecations ...*models.BlueprintDeprecation) *DeprecationService {
+	t.Helper()
+	repo := newFakeDeprecationRepo()
+	for _, deprecation := range deprecations {
+		if err := repo.Set(context.Background(), deprecation); err != nil {
+			t.Fatalf("failed to deprecate: %v", err)
+		}
+	}
+	blueprints := newFakeBlueprintRepo()
+	blueprints.add("workspace", "owner", printBlueprint("bp"))
+	return NewDeprecationService(repo, blueprints)
+}
+
+func TestFindDeprecation(t *testing.T) {
+	now := time.Now()
+	past, soon, later := now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour)
+
+	for _, test := range []struct {
+		name         string
+		deprecations []*models.BlueprintDeprecation
+		expected     string // Message of the deprecation that applies to version 2, none if empty
+	}{
+		{
+			name: "not deprecated",
+		},
+		{
+			name:         "deprecation of another version",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(3, "version 3", &past)},
+		},
+		{
+			name:         "every version deprecated",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", &soon)},
+			expected:     "every version",
+		},
+		{
+			name:         "version deprecated",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(2, "version 2", &soon)},
+			expected:     "version 2",
+		},
+		{
+			name: "version sunsetting first",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &later),
+				deprecationOf(2, "version 2", &soon),
+			},
+			expected: "version 2",
+		},
+		{
+			name: "every version sunsetting first",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &soon),
+				deprecationOf(2, "version 2", &later),
+			},
+			expected: "every version",
+		},
+		{
+			name: "only every version sunsetting",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &later),
+				deprecationOf(2, "version 2", nil),
+			},
+			expected: "every version",
+		},
+		{
+			name: "only the version sunsetting",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", nil),
+				deprecationOf(2, "version 2", &later),
+			},
+			expected: "version 2",
+		},
+		{
+			name: "neither sunsetting",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", nil),
+				deprecationOf(2, "version 2", nil),
+			},
+			expected: "version 2",
+		},
+		{
+			name: "sunsetting together",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &soon),
+				deprecationOf(2, "version 2", &soon),
+			},
+			expected: "version 2",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := newDeprecationTestService(t, test.deprecations...)
+			deprecation, err := s.Find(context.Background(), "bp", 2)
+			if err != nil {
+				t.Fatalf("failed to find the deprecation: %v", err)
+			}
+			if test.expected == "" {
+				if deprecation != nil {
+					t.Errorf("expected no deprecation, got %q", deprecation.Message)
+				}
+				return
+			}
+			if deprecation == nil || deprecation.Message != test.expected {
+				t.Errorf("expected the deprecation %q, got %+v", test.expected, deprecation)
+			}
+		})
+	}
+}
+
+func TestCheckDeprecation(t *testing.T) {
+	now := time.Now()
+	past, earlier, soon := now.Add(-time.Hour), now.Add(-2*time.Hour), now.Add(time.Hour)
+
+	for _, test := range []struct {
+		name         string
+		deprecations []*models.BlueprintDeprecation
+		trigger      string
+		override     bool
+		expected     string // Message of the deprecation the execution runs or is blocked with
+		blocked      bool
+		subject      string // What the sunset error names
+		warned       bool   // The trigger notifies about the attempt
+	}{
+		{
+			name:    "not deprecated",
+			trigger: TriggerBatch,
+		},
+		{
+			name:         "without a sunset",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", nil)},
+			trigger:      TriggerBatch,
+			expected:     "every version",
+			warned:       true,
+		},
+		{
+			name:         "before the sunset",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", &soon)},
+			trigger:      TriggerBatch,
+			expected:     "every version",
+			warned:       true,
+		},
+		{
+			name:         "after the sunset",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", &past)},
+			trigger:      TriggerBatch,
+			expected:     "every version",
+			blocked:      true,
+			subject:      "blueprint bp",
+			warned:       true,
+		},
+		{
+			name:         "after the sunset with an override",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", &past)},
+			trigger:      TriggerBatch,
+			override:     true,
+			expected:     "every version",
+			warned:       true,
+		},
+		{
+			name:         "before the sunset with an override",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", &soon)},
+			trigger:      TriggerShareLink,
+			override:     true,
+			expected:     "every version",
+			warned:       true,
+		},
+		{
+			name:         "manual execution after the sunset",
+			deprecations: []*models.BlueprintDeprecation{deprecationOf(0, "every version", &past)},
+			trigger:      TriggerManual,
+			expected:     "every version",
+			blocked:      true,
+			subject:      "blueprint bp",
+		},
+		{
+			name: "version past its sunset before the blueprint",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &soon),
+				deprecationOf(2, "version 2", &past),
+			},
+			trigger:  TriggerService,
+			expected: "version 2",
+			blocked:  true,
+			subject:  "version 2 of blueprint bp",
+			warned:   true,
+		},
+		{
+			name: "blueprint past its sunset before the version",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &past),
+				deprecationOf(2, "version 2", &soon),
+			},
+			trigger:  TriggerService,
+			expected: "every version",
+			blocked:  true,
+			subject:  "blueprint bp",
+			warned:   true,
+		},
+		{
+			name: "both past their sunset",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &past),
+				deprecationOf(2, "version 2", &earlier),
+			},
+			trigger:  TriggerService,
+			expected: "version 2",
+			blocked:  true,
+			subject:  "version 2 of blueprint bp",
+			warned:   true,
+		},
+		{
+			name: "version without a sunset",
+			deprecations: []*models.BlueprintDeprecation{
+				deprecationOf(0, "every version", &past),
+				deprecationOf(2, "version 2", nil),
+			},
+			trigger:  TriggerService,
+			expected: "every version",
+			blocked:  true,
+			subject:  "blueprint bp",
+			warned:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			blueprints := newFakeBlueprintRepo()
+			blueprintModel := blueprints.add("workspace", "owner", printBlueprint("bp"))
+			s := NewExecutionService(newFakeExecutionRepo(), blueprints, newTestEngine(t))
+			s.SetDeprecationService(newDeprecationTestService(t, test.deprecations...))
+			var warnings []DeprecationWarning
+			s.OnDeprecationHook = func(warning DeprecationWarning) {
+				warnings = append(warnings, warning)
+			}
+
+			ctx := context.Background()
+			if test.override {
+				ctx = WithSunsetOverride(ctx)
+			}
+			deprecation, err := s.checkDeprecation(ctx, blueprintModel, 2, test.trigger)
+
+			if test.blocked {
+				bpErr, isSunset := IsBlueprintSunsetError(err)
+				if !isSunset {
+					t.Fatalf("expected the execution to be blocked by the sunset, got %v", err)
+				}
+				if !strings.HasPrefix(bpErr.Message, test.subject+" reached its sunset") || !strings.HasSuffix(bpErr.Message, test.expected) {
+					t.Errorf("expected the sunset of %s with %q, got %q", test.subject, test.expected, bpErr.Message)
+				}
+				if deprecation != nil {
+					t.Errorf("expected no deprecation with the error, got %+v", deprecation)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expected the execution to run, got %v", err)
+				}
+				if test.expected == "" && deprecation != nil {
+					t.Errorf("expected no deprecation, got %q", deprecation.Message)
+				}
+				if test.expected != "" && (deprecation == nil || deprecation.Message != test.expected) {
+					t.Errorf("expected the deprecation %q, got %+v", test.expected, deprecation)
+				}
+			}
+
+			if !test.warned {
+				if len(warnings) != 0 {
+					t.Errorf("expected no warning, got %+v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("expected 1 warning, got %+v", warnings)
+			}
+			warning := warnings[0]
+			if warning.BlueprintID != "bp" || warning.VersionNumber != 2 || warning.Trigger != test.trigger || warning.Message != test.expected {
+				t.Errorf("expected a warning of version 2 with %q, got %+v", test.expected, warning)
+			}
+			if warning.Blocked != test.blocked {
+				t.Errorf("expected the warning to report blocked %v, got %v", test.blocked, warning.Blocked)
+			}
+			if overridden := test.override && warning.SunsetAt != nil && warning.SunsetAt.Before(now); warning.Overridden != overridden {
+				t.Errorf("expected the warning to report overridden %v, got %v", overridden, warning.Overridden)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"webblueprint/internal/engine"
+)
+
+// EngineRestore describes what restoring an engine snapshot took over, including the
+// suspended executions this server resumed
+type EngineRestore struct {
+	engine.SnapshotRestore
+	Resumed []string          `json:"resumed"`           // Suspended executions started by this server
+	Dropped map[string]string `json:"dropped,omitempty"` // Suspended executions that weren't resumed, with the reason
+	Errors  []string          `json:"errors,omitempty"`  // Blueprints, events and node timers that couldn't be restored
+}
+
+// SnapshotEngine captures the runtime state of the engine for a new server version to
+// restore. The snapshot should be taken once the server stopped accepting work, since
+// executions it starts afterwards aren't handed over.
+func (s *ExecutionService) SnapshotEngine() *engine.EngineSnapshot {
+	return s.executionEngine.Snapshot()
+}
+
+// RestoreEngine restores a snapshot taken by another server into the engine and resumes
+// the executions that were waiting to start there. Executions that already finished, were
+// cancelled or are known to this server are left alone. Parts of the snapshot that can't be
+// restored are reported in the result; only snapshots that can't be restored at all fail.
+func (s *ExecutionService) RestoreEngine(ctx context.Context, snapshot *engine.EngineSnapshot) (*EngineRestore, error) {
+	restored, err := s.executionEngine.RestoreSnapshot(snapshot)
+	if restored == nil {
+		return nil, err
+	}
+
+	result := &EngineRestore{
+		SnapshotRestore: *restored,
+		Resumed:         make([]string, 0),
+		Dropped:         make(map[string]string),
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, restoreErr := range joined.Unwrap() {
+			result.Errors = append(result.Errors, restoreErr.Error())
+		}
+	} else if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	for _, suspended := range restored.Executions {
+		if reason := s.resumeExecution(ctx, suspended); reason != "" {
+			result.Dropped[suspended.ExecutionID] = reason
+			continue
+		}
+		result.Resumed = append(result.Resumed, suspended.ExecutionID)
+	}
+
+	return result, nil
+}
+
+// resumeExecution starts a suspended execution of another server under its own ID, with
+// the current version of its blueprint. It returns why the execution wasn't resumed, if it
+// wasn't.
+func (s *ExecutionService) resumeExecution(ctx context.Context, suspended engine.SuspendedExecution) string {
+	if _, exists := s.executionEngine.GetExecutionStatus(suspended.ExecutionID); exists {
+		return "execution is already known to this server"
+	}
+
+	execution, err := s.executionRepo.GetByID(ctx, suspended.ExecutionID)
+	if err != nil {
+		return fmt.Sprintf("execution not found: %v", err)
+	}
+	if execution.Status != "queued" && execution.Status != "running" {
+		return fmt.Sprintf("execution is %s", execution.Status)
+	}
+
+	// Executions that can't be resumed are failed, or their record would stay running
+	fail := func(reason string) string {
+		s.executionRepo.Complete(ctx, execution.ID, false, nil, "failed to resume after restoring an engine snapshot: "+reason)
+		return reason
+	}
+
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, execution.BlueprintID)
+	if err != nil {
+		return fail(fmt.Sprintf("blueprint not found: %v", err))
+	}
+
+	bp, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
+	if err != nil {
+		return fail(fmt.Sprintf("failed to load blueprint: %v", err))
+	}
+
+	variables, err := toEngineVariables(map[string]interface{}(execution.InitialVariables))
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	if err := s.checkNodePolicies(ctx, blueprintModel, bp); err != nil {
+		return fail(err.Error())
+	}
+
+	release, limits, err := s.acquireQuota(ctx, blueprintModel, bp)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	// Unknown priorities fall back to normal
+	priority, _ := engine.ParseExecutionPriority(suspended.Priority)
+
+	s.trackExecution(execution.ID)
+	s.AddLogEntry(ctx, execution.ID, "", "info", "resuming execution from an engine snapshot", map[string]interface{}{
+		"waitingFor": suspended.WaitingFor,
+		"queuedAt":   suspended.QueuedAt,
+	})
+
+	go func() {
+		defer release()
+		s.runExecution(bp, execution.ID, variables, priority, limits)
+	}()
+
+	return ""
+}
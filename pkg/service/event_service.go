@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 	"webblueprint/internal/event"
+	"webblueprint/pkg/models"
 	"webblueprint/pkg/repository"
 )
 
@@ -108,6 +109,26 @@ func (s *EventService) DeleteBindingsByBlueprintID(ctx context.Context, blueprin
 	return s.eventRepo.DeleteBindingsByBlueprintID(ctx, blueprintID)
 }
 
+// SetBindingEnabled persists the enabled state of a binding set at runtime, so that it
+// survives restarts and reloads of the binding's blueprint
+func (s *EventService) SetBindingEnabled(ctx context.Context, binding event.EventBinding, enabled bool, userID string) (*models.EventBindingState, error) {
+	state := &models.EventBindingState{
+		BindingID:   binding.ID,
+		BlueprintID: binding.BlueprintID,
+		Enabled:     enabled,
+		UpdatedBy:   userID,
+	}
+	if err := s.eventRepo.SaveBindingState(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// GetBindingStates retrieves the enabled states of the bindings toggled at runtime
+func (s *EventService) GetBindingStates(ctx context.Context) ([]*models.EventBindingState, error) {
+	return s.eventRepo.GetBindingStates(ctx)
+}
+
 // validateEvent validates an event's fields
 func (s *EventService) validateEvent(event event.EventDefinition) error {
 	if event.ID == "" {
@@ -2,10 +2,20 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/common"
 	"webblueprint/internal/engine"
+	"webblueprint/internal/paging"
+	"webblueprint/internal/requestid"
 	"webblueprint/internal/types"
+	"webblueprint/internal/usage"
 	"webblueprint/pkg/blueprint"
 	"webblueprint/pkg/models"
 	"webblueprint/pkg/repository"
@@ -18,6 +28,33 @@ type ExecutionService struct {
 	executionRepo   repository.ExecutionRepository
 	blueprintRepo   repository.BlueprintRepository
 	executionEngine *engine.ExecutionEngine
+
+	batches    map[string]*ExecutionBatch
+	batchMutex sync.RWMutex
+
+	// done holds a channel per running execution, closed once it finishes
+	done      map[string]chan struct{}
+	doneMutex sync.Mutex
+
+	// services holds the blueprints running as services, by blueprint ID
+	services     map[string]*ServiceExecution
+	serviceMutex sync.Mutex
+
+	// quotaService enforces workspace quotas when set
+	quotaService *QuotaService
+
+	// nodePolicyService enforces workspace node policies when set
+	nodePolicyService *NodePolicyService
+
+	// deprecationService warns about deprecated blueprints and enforces their sunset when set
+	deprecationService *DeprecationService
+
+	// OnBatchProgressHook is called whenever a batch makes progress
+	OnBatchProgressHook func(progress BatchProgress)
+
+	// OnDeprecationHook is called when a trigger other than a manual one starts, or is
+	// blocked from starting, an execution of a deprecated blueprint
+	OnDeprecationHook func(warning DeprecationWarning)
 }
 
 // NewExecutionService creates a new execution service
@@ -26,19 +63,126 @@ func NewExecutionService(
 	blueprintRepo repository.BlueprintRepository,
 	executionEngine *engine.ExecutionEngine,
 ) *ExecutionService {
-	return &ExecutionService{
+	s := &ExecutionService{
 		executionRepo:   executionRepo,
 		blueprintRepo:   blueprintRepo,
 		executionEngine: executionEngine,
+		batches:         make(map[string]*ExecutionBatch),
+		done:            make(map[string]chan struct{}),
+		services:        make(map[string]*ServiceExecution),
+	}
+
+	// Register the hooks once: executions read them from scheduler workers
+	executionEngine.SetHooks(engine.Hooks{
+		OnAny:            s.AddLogEntry,
+		OnNodeExecution:  s.RecordNodeExecution,
+		OnExecutionStart: s.markExecutionRunning,
+	})
+	return s
+}
+
+// SetQuotaService enables workspace quota enforcement for executions
+func (s *ExecutionService) SetQuotaService(quotaService *QuotaService) {
+	s.quotaService = quotaService
+}
+
+// SetNodePolicyService enables workspace node policy enforcement for executions
+func (s *ExecutionService) SetNodePolicyService(nodePolicyService *NodePolicyService) {
+	s.nodePolicyService = nodePolicyService
+}
+
+// checkNodePolicies fails if the node policies of the blueprint's workspace block any
+// of its nodes. Policies may have changed since the blueprint was saved.
+func (s *ExecutionService) checkNodePolicies(ctx context.Context, blueprintModel *models.Blueprint, bp *blueprint.Blueprint) error {
+	if s.nodePolicyService == nil {
+		return nil
+	}
+	return s.nodePolicyService.Check(ctx, blueprintModel.WorkspaceID, bp)
+}
+
+// acquireQuota reserves a workspace execution slot and returns the release function
+// and the engine limits for the execution. Without a quota service nothing is enforced.
+func (s *ExecutionService) acquireQuota(
+	ctx context.Context,
+	blueprintModel *models.Blueprint,
+	bp *blueprint.Blueprint,
+) (func(), engine.ExecutionLimits, error) {
+	if s.quotaService == nil {
+		return func() {}, engine.ExecutionLimits{}, nil
 	}
+
+	quota, err := s.quotaService.GetQuota(ctx, blueprintModel.WorkspaceID)
+	if err != nil {
+		return nil, engine.ExecutionLimits{}, err
+	}
+
+	release, err := s.quotaService.Acquire(ctx, blueprintModel.WorkspaceID, blueprintModel.ID, len(bp.Nodes))
+	if err != nil {
+		return nil, engine.ExecutionLimits{}, err
+	}
+
+	return release, quota.ExecutionLimits(), nil
 }
 
-// StartExecution starts a new blueprint execution
+// StartExecution starts a new blueprint execution with normal priority
 func (s *ExecutionService) StartExecution(
 	ctx context.Context,
 	blueprintID string,
 	initialVariables map[string]interface{},
 	userID string,
+) (string, error) {
+	return s.StartExecutionWithPriority(ctx, blueprintID, initialVariables, userID, engine.PriorityNormal)
+}
+
+// StartExecutionWithPriority queues a new blueprint execution with the given priority
+func (s *ExecutionService) StartExecutionWithPriority(
+	ctx context.Context,
+	blueprintID string,
+	initialVariables map[string]interface{},
+	userID string,
+	priority engine.ExecutionPriority,
+) (string, error) {
+	return s.StartVersionExecution(ctx, blueprintID, 0, initialVariables, userID, priority)
+}
+
+// StartVersionExecution queues a new execution of a specific version of a blueprint, or of
+// its current version when the version number is 0. Other versions run alongside the
+// current one with their own variables and without event bindings, e.g. to try a new
+// version on a share of the traffic before activating it.
+func (s *ExecutionService) StartVersionExecution(
+	ctx context.Context,
+	blueprintID string,
+	versionNumber int,
+	initialVariables map[string]interface{},
+	userID string,
+	priority engine.ExecutionPriority,
+) (string, error) {
+	return s.startExecution(ctx, blueprintID, versionNumber, initialVariables, userID, priority, nil, TriggerManual)
+}
+
+// StartSharedExecution queues an execution of the current version of a blueprint through a
+// share link. The nodes of the blueprint must also pass the restrictions of the link.
+func (s *ExecutionService) StartSharedExecution(
+	ctx context.Context,
+	blueprintID string,
+	initialVariables map[string]interface{},
+	userID string,
+	restrictions []NodePolicy,
+) (string, error) {
+	return s.startExecution(ctx, blueprintID, 0, initialVariables, userID, engine.PriorityNormal, restrictions, TriggerShareLink)
+}
+
+// startExecution queues a new execution of a version of a blueprint, checking its nodes
+// against the restrictions too if there are any
+func (s *ExecutionService) startExecution(
+	ctx context.Context,
+	blueprintID string,
+	versionNumber int,
+	initialVariables map[string]interface{},
+	userID string,
+	priority engine.ExecutionPriority,
+	restrictions []NodePolicy,
+	trigger string,
 ) (string, error) {
 	// Create a unique execution ID
 	executionID := uuid.New().String()
@@ -54,78 +198,530 @@ func (s *ExecutionService) StartExecution(
 		return "", fmt.Errorf("blueprint not found: %w", err)
 	}
 
-	// Create execution record
+	version := blueprintModel.CurrentVersion
+	versionID := blueprintModel.CurrentVersionID
+	pinned := versionNumber > 0 && (version == nil || version.VersionNumber != versionNumber)
+	if pinned {
+		version, err = s.blueprintRepo.GetVersion(ctx, blueprintID, versionNumber)
+		if err != nil {
+			return "", err
+		}
+		versionID = sql.NullString{String: version.ID, Valid: true}
+	}
+
+	bp, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to load blueprint: %w", err)
+	}
+
+	// Convert to the format expected by the execution engine
+	variables, err := toEngineVariables(initialVariables)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkNodePolicies(ctx, blueprintModel, bp); err != nil {
+		return "", err
+	}
+	if restrictions != nil {
+		if err := checkRestrictions(blueprintModel.WorkspaceID, bp, restrictions); err != nil {
+			return "", err
+		}
+	}
+
+	// Deprecated blueprints run with a warning until their sunset
+	deprecation, err := s.checkDeprecation(ctx, blueprintModel, versionNumberOf(version), trigger)
+	if err != nil {
+		return "", err
+	}
+
+	// Blueprints that don't run concurrently turn executions away while one is running
+	if err := s.executionEngine.CheckConcurrency(bp); err != nil {
+		return "", err
+	}
+
+	// Triggers whose payload the entry point guards reject are skipped without an execution
+	if err := s.executionEngine.CheckGuards(bp, variables); err != nil {
+		return "", err
+	}
+
+	// Enforce workspace quotas before anything is recorded
+	release, limits, err := s.acquireQuota(ctx, blueprintModel, bp)
+	if err != nil {
+		return "", err
+	}
+
+	// Create and save the execution record
+	if err := s.createExecutionRecord(ctx, executionID, blueprintModel, versionID, initialVariables, userID, "standard"); err != nil {
+		release()
+		return "", err
+	}
+	s.warnDeprecated(ctx, executionID, deprecation, trigger)
+
+	// Versions other than the current one don't take over its event handlers
+	if pinned {
+		s.executionEngine.PinVersion(executionID)
+	}
+	if profilingRequested(ctx) {
+		s.executionEngine.StartProfiling(executionID)
+	}
+
+	// Queue the blueprint execution in a goroutine
+	go func() {
+		defer release()
+		s.runExecution(bp, executionID, variables, priority, limits)
+	}()
+
+	return executionID, nil
+}
+
+// createExecutionRecord stores a queued execution record for a version of the blueprint
+func (s *ExecutionService) createExecutionRecord(
+	ctx context.Context,
+	executionID string,
+	blueprintModel *models.Blueprint,
+	versionID sql.NullString,
+	initialVariables map[string]interface{},
+	userID string,
+	executionMode string,
+) error {
 	execution := &models.Execution{
 		ID:               executionID,
-		BlueprintID:      blueprintID,
+		BlueprintID:      blueprintModel.ID,
 		StartedAt:        time.Now(),
-		Status:           "running",
+		Status:           "queued",
 		InitiatedBy:      userID,
-		ExecutionMode:    "standard", // Could be configurable
+		ExecutionMode:    executionMode,
 		InitialVariables: models.JSONB(initialVariables),
+		RequestID:        models.NullString(requestid.FromContext(ctx)),
 	}
 
 	// Set the version ID if available
-	if blueprintModel.CurrentVersionID.Valid {
-		execution.VersionID = blueprintModel.CurrentVersionID
+	if versionID.Valid {
+		execution.VersionID = versionID
+	}
+
+	if err := s.executionRepo.Create(ctx, execution); err != nil {
+		return fmt.Errorf("failed to create execution record: %w", err)
+	}
+
+	// Requests waiting for the result are woken once runExecution completes the record
+	s.trackExecution(executionID)
+	return nil
+}
+
+// markExecutionRunning marks the record of an execution a scheduler worker started as running
+func (s *ExecutionService) markExecutionRunning(executionID string) {
+	if err := s.executionRepo.UpdateStatus(context.Background(), executionID, "running"); err != nil {
+		s.AddLogEntry(context.Background(), executionID, "", "warn", "failed to mark execution as running", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// runExecution executes the blueprint and stores the outcome on the execution record
+func (s *ExecutionService) runExecution(bp *blueprint.Blueprint, executionID string, variables map[string]types.Value, priority engine.ExecutionPriority, limits engine.ExecutionLimits) error {
+	return s.runExecutionContext(context.Background(), bp, executionID, variables, priority, limits)
+}
+
+// runExecutionContext runs the execution like runExecution, stopping it once the context is
+// done. Both return once the execution ended, even when it outlived its duration limit.
+func (s *ExecutionService) runExecutionContext(ctx context.Context, bp *blueprint.Blueprint, executionID string, variables map[string]types.Value, priority engine.ExecutionPriority, limits engine.ExecutionLimits) error {
+	// Get a background context since the request context will be canceled
+	bgCtx := context.Background()
+	// Executions that outlive their duration limit hold their quota slot until they end
+	defer s.executionEngine.WaitForExecution(executionID)
+	defer s.finishExecution(executionID)
+
+	// Record external inputs so the execution can be replayed; replays keep their recording
+	s.executionEngine.StartRecording(executionID)
+
+	// Execute the blueprint
+	result, err := s.executionEngine.ExecuteWithLimitsContext(ctx, priority, bp, executionID, variables, limits)
+
+	if recording, recorded := s.executionEngine.TakeRecording(executionID); recorded {
+		if saveErr := s.saveRecording(bgCtx, recording); saveErr != nil {
+			s.AddLogEntry(bgCtx, executionID, "", "warn", "failed to save execution recording", map[string]interface{}{
+				"error": saveErr.Error(),
+			})
+		}
 	}
 
-	// Save execution record
-	err = s.executionRepo.Create(ctx, execution)
+	if profile, profiled := s.executionEngine.TakeProfile(executionID); profiled {
+		if saveErr := s.saveProfile(bgCtx, profile); saveErr != nil {
+			s.AddLogEntry(bgCtx, executionID, "", "warn", "failed to save execution profile", map[string]interface{}{
+				"error": saveErr.Error(),
+			})
+		}
+	}
+
+	// Keep the data flow so the lineage of the outputs can be traced after the debug data is gone
+	if dataFlow, recorded := s.executionEngine.GetExecutionDataFlow(executionID); recorded {
+		if saveErr := s.saveDataFlow(bgCtx, dataFlow); saveErr != nil {
+			s.AddLogEntry(bgCtx, executionID, "", "warn", "failed to save execution data flow", map[string]interface{}{
+				"error": saveErr.Error(),
+			})
+		}
+	}
+
+	// Keep the variable history so variables can be inspected at any node once the execution is gone
+	if history, recorded := s.executionEngine.GetVariableHistory(executionID); recorded {
+		if saveErr := s.saveVariableHistory(bgCtx, history); saveErr != nil {
+			s.AddLogEntry(bgCtx, executionID, "", "warn", "failed to save execution variable history", map[string]interface{}{
+				"error": saveErr.Error(),
+			})
+		}
+	}
+
+	// Update execution record with result
 	if err != nil {
-		return "", fmt.Errorf("failed to create execution record: %w", err)
+		// Execution failed
+		s.executionRepo.Complete(bgCtx, executionID, false, nil, err.Error())
+	} else {
+		// Execution succeeded
+		resultMap := make(map[string]interface{})
+		for nodeID, outputs := range result.NodeResults {
+			resultMap[nodeID] = outputs
+		}
+		s.executionRepo.Complete(bgCtx, executionID, true, resultMap, "")
 	}
 
-	// Convert to the format expected by the execution engine
-	variables := make(map[string]types.Value)
-	for k, v := range initialVariables {
-		// Determine type based on value
-		var pinType *types.PinType
-		switch v.(type) {
-		case string:
-			pinType = types.PinTypes.String
-		case float64, int, int64:
-			pinType = types.PinTypes.Number
-		case bool:
-			pinType = types.PinTypes.Boolean
-		case map[string]interface{}:
-			pinType = types.PinTypes.Object
-		case []interface{}:
-			pinType = types.PinTypes.Array
-		default:
-			pinType = types.PinTypes.Any
+	// Persist the resource usage accumulated during the execution
+	counters := usage.Collect(executionID)
+	s.executionRepo.RecordUsage(
+		bgCtx,
+		executionID,
+		int(counters[usage.MetricNodesExecuted]),
+		counters[usage.MetricHTTPBytes],
+		counters[usage.MetricDBRowsRead],
+	)
+
+	// Persist the recoveries applied to node errors
+	if len(result.Recoveries) > 0 {
+		if saveErr := s.saveRecoveries(bgCtx, executionID, result.Recoveries); saveErr != nil {
+			s.AddLogEntry(bgCtx, executionID, "", "warn", "failed to save execution recoveries", map[string]interface{}{
+				"error": saveErr.Error(),
+			})
+		}
+	}
+
+	// Persist the consumption of the blueprint's execution budget
+	if result.Budget != nil {
+		if saveErr := s.saveBudget(bgCtx, executionID, result.Budget); saveErr != nil {
+			s.AddLogEntry(bgCtx, executionID, "", "warn", "failed to save execution budget", map[string]interface{}{
+				"error": saveErr.Error(),
+			})
 		}
-		variables[k] = types.NewValue(pinType, v)
 	}
-	bp, _ := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
 
-	// Register hooks
-	s.executionEngine.OnAnyHook = s.AddLogEntry
-	s.executionEngine.OnNodeExecutionHook = s.RecordNodeExecution
+	return err
+}
+
+// saveRecording persists the recorded external inputs of an execution
+func (s *ExecutionService) saveRecording(ctx context.Context, recording *engine.ExecutionRecording) error {
+	data, err := json.Marshal(recording)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	var stored models.JSONB
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	return s.executionRepo.SaveRecording(ctx, recording.ExecutionID, stored)
+}
+
+// saveDataFlow persists the recorded data flow of an execution
+func (s *ExecutionService) saveDataFlow(ctx context.Context, dataFlow *engine.ExecutionDataFlow) error {
+	data, err := json.Marshal(dataFlow)
+	if err != nil {
+		return fmt.Errorf("failed to encode data flow: %w", err)
+	}
 
-	// Execute the blueprint in a goroutine
-	go func(bp *blueprint.Blueprint) {
-		// Get a background context since the request context will be canceled
-		bgCtx := context.Background()
+	var stored models.JSONB
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to encode data flow: %w", err)
+	}
 
-		// Execute the blueprint
-		result, err := s.executionEngine.Execute(bp, executionID, variables)
+	return s.executionRepo.SaveDataFlow(ctx, dataFlow.ExecutionID, stored)
+}
+
+// saveVariableHistory persists the recorded variable history of an execution
+func (s *ExecutionService) saveVariableHistory(ctx context.Context, history *engine.VariableHistory) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to encode variable history: %w", err)
+	}
+
+	var stored models.JSONB
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to encode variable history: %w", err)
+	}
+
+	return s.executionRepo.SaveVariableHistory(ctx, history.ExecutionID, stored)
+}
+
+// saveRecoveries persists the recovery strategies applied to the node errors of an execution
+func (s *ExecutionService) saveRecoveries(ctx context.Context, executionID string, recoveries []common.NodeRecovery) error {
+	data, err := json.Marshal(recoveries)
+	if err != nil {
+		return fmt.Errorf("failed to encode recoveries: %w", err)
+	}
+
+	var stored models.JSONArray
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to encode recoveries: %w", err)
+	}
+
+	return s.executionRepo.RecordRecoveries(ctx, executionID, stored)
+}
+
+// saveBudget persists how much of the blueprint's execution budget an execution consumed
+func (s *ExecutionService) saveBudget(ctx context.Context, executionID string, budget *common.BudgetUsage) error {
+	data, err := json.Marshal(budget)
+	if err != nil {
+		return fmt.Errorf("failed to encode budget: %w", err)
+	}
+
+	var stored models.JSONB
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to encode budget: %w", err)
+	}
 
-		// Update execution record with result
+	return s.executionRepo.RecordBudget(ctx, executionID, stored)
+}
+
+// GetExecutionRecording returns the recorded external inputs of an execution
+func (s *ExecutionService) GetExecutionRecording(ctx context.Context, executionID string) (*engine.ExecutionRecording, error) {
+	stored, err := s.executionRepo.GetRecording(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode recording: %w", err)
+	}
+
+	var recording engine.ExecutionRecording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, fmt.Errorf("failed to decode recording: %w", err)
+	}
+
+	return &recording, nil
+}
+
+// getDataFlow returns the data flow of an execution, from the debug data while it's
+// retained and from the stored one otherwise
+func (s *ExecutionService) getDataFlow(ctx context.Context, executionID string) (*engine.ExecutionDataFlow, error) {
+	if dataFlow, retained := s.executionEngine.GetExecutionDataFlow(executionID); retained {
+		return dataFlow, nil
+	}
+
+	stored, err := s.executionRepo.GetDataFlow(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode data flow: %w", err)
+	}
+
+	var dataFlow engine.ExecutionDataFlow
+	if err := json.Unmarshal(data, &dataFlow); err != nil {
+		return nil, fmt.Errorf("failed to decode data flow: %w", err)
+	}
+
+	return &dataFlow, nil
+}
+
+// GetExecutionLineage returns the lineage of the final output values of an execution,
+// those that weren't passed on to other nodes
+func (s *ExecutionService) GetExecutionLineage(ctx context.Context, executionID string) (*engine.ExecutionLineage, error) {
+	dataFlow, err := s.getDataFlow(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	return dataFlow.FinalOutputs(), nil
+}
+
+// GetValueLineage returns the lineage of an output value of a node of an execution
+func (s *ExecutionService) GetValueLineage(ctx context.Context, executionID, nodeID, pinID string) (*engine.ValueLineage, error) {
+	dataFlow, err := s.getDataFlow(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage, exists := dataFlow.Lineage(nodeID, pinID)
+	if !exists {
+		return nil, fmt.Errorf("no value of output %s of node %s in execution %s", pinID, nodeID, executionID)
+	}
+	return lineage, nil
+}
+
+// getVariableHistory returns the variable history of an execution, from the debug data
+// while it's retained and from the stored one otherwise
+func (s *ExecutionService) getVariableHistory(ctx context.Context, executionID string) (*engine.VariableHistory, error) {
+	if history, retained := s.executionEngine.GetVariableHistory(executionID); retained {
+		return history, nil
+	}
+
+	stored, err := s.executionRepo.GetVariableHistory(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode variable history: %w", err)
+	}
+
+	var history engine.VariableHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to decode variable history: %w", err)
+	}
+
+	return &history, nil
+}
+
+// GetVariableState returns the variables of an execution when an execution of a node
+// started and when it was done, counting the executions of the node from 1, or the
+// variables at the end of the execution if the node ID is empty
+func (s *ExecutionService) GetVariableState(ctx context.Context, executionID, nodeID string, iteration int) (*engine.VariableState, error) {
+	history, err := s.getVariableHistory(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	return history.StateAt(nodeID, iteration)
+}
+
+// ReplayExecution re-runs an execution with its recorded external inputs. The current
+// version of the blueprint is used, so a fixed blueprint can be verified against the
+// inputs that made the original execution fail.
+func (s *ExecutionService) ReplayExecution(ctx context.Context, executionID string, userID string) (string, error) {
+	original, err := s.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return "", fmt.Errorf("execution not found: %w", err)
+	}
+
+	recording, err := s.GetExecutionRecording(ctx, executionID)
+	if err != nil {
+		return "", err
+	}
+
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, original.BlueprintID)
+	if err != nil {
+		return "", fmt.Errorf("blueprint not found: %w", err)
+	}
+
+	bp, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to load blueprint: %w", err)
+	}
+
+	initialVariables := map[string]interface{}(original.InitialVariables)
+	variables, err := toEngineVariables(initialVariables)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkNodePolicies(ctx, blueprintModel, bp); err != nil {
+		return "", err
+	}
+
+	release, limits, err := s.acquireQuota(ctx, blueprintModel, bp)
+	if err != nil {
+		return "", err
+	}
+
+	replayID := uuid.New().String()
+	if err := s.createExecutionRecord(ctx, replayID, blueprintModel, blueprintModel.CurrentVersionID, initialVariables, userID, "replay"); err != nil {
+		release()
+		return "", err
+	}
+	s.AddLogEntry(ctx, replayID, "", "info", "replaying execution", map[string]interface{}{
+		"replayOf": executionID,
+	})
+
+	s.executionEngine.StartReplay(replayID, recording)
+	go func() {
+		defer release()
+		s.runExecution(bp, replayID, variables, engine.PriorityNormal, limits)
+	}()
+
+	return replayID, nil
+}
+
+// toEngineVariables converts raw initial variables to typed engine values.
+// Base64 encoded bytes values ({"$bytes": "..."}) are decoded.
+func toEngineVariables(initialVariables map[string]interface{}) (map[string]types.Value, error) {
+	variables := make(map[string]types.Value)
+	for k, v := range initialVariables {
+		decoded, err := types.DecodeBytesValue(v)
 		if err != nil {
-			// Execution failed
-			s.executionRepo.Complete(bgCtx, executionID, false, nil, err.Error())
-		} else {
-			// Execution succeeded
-			resultMap := make(map[string]interface{})
-			for nodeID, outputs := range result.NodeResults {
-				resultMap[nodeID] = outputs
-			}
-			s.executionRepo.Complete(bgCtx, executionID, true, resultMap, "")
+			return nil, fmt.Errorf("invalid variable %s: %w", k, err)
 		}
-	}(bp)
+		variables[k] = types.NewValue(types.InferPinType(decoded), decoded)
+	}
+	return variables, nil
+}
 
-	return executionID, nil
+// GetQueueDepth returns the number of queued executions per priority
+func (s *ExecutionService) GetQueueDepth() map[string]int {
+	return s.executionEngine.GetQueueDepth()
+}
+
+// GetLoadSignals returns the load of the engine autoscalers scale on
+func (s *ExecutionService) GetLoadSignals() engine.LoadSignals {
+	return s.executionEngine.GetLoadSignals()
+}
+
+// GetBlueprintConcurrency returns the running and queued executions of a blueprint that
+// doesn't run concurrently
+func (s *ExecutionService) GetBlueprintConcurrency(blueprintID string) engine.BlueprintConcurrency {
+	return s.executionEngine.GetBlueprintConcurrency(blueprintID)
+}
+
+// IsBlueprintRunningError checks if an execution was turned away by the concurrency policy
+// of its blueprint
+func IsBlueprintRunningError(err error) (*bperrors.BlueprintError, bool) {
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrBlueprintRunning {
+		return nil, false
+	}
+	return bpErr, true
+}
+
+// GetSkippedTriggers returns the latest triggers of a blueprint its entry point guards
+// rejected, newest first
+func (s *ExecutionService) GetSkippedTriggers(blueprintID string) []engine.SkippedTrigger {
+	return s.executionEngine.GetSkippedTriggers(blueprintID)
+}
+
+// IsTriggerSkippedError checks if an execution was skipped because the entry point guards
+// of its blueprint rejected its payload
+func IsTriggerSkippedError(err error) (*bperrors.BlueprintError, bool) {
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrTriggerSkipped {
+		return nil, false
+	}
+	return bpErr, true
+}
+
+// GetWorkspaceUsage returns per-day execution usage summaries of a workspace
+func (s *ExecutionService) GetWorkspaceUsage(ctx context.Context, workspaceID string, from, to time.Time) ([]*models.ExecutionUsageSummary, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid range: end must be after start")
+	}
+
+	summaries, err := s.executionRepo.GetDailyUsage(ctx, workspaceID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving usage: %w", err)
+	}
+
+	return summaries, nil
 }
 
 // GetExecution retrieves execution details by ID
@@ -137,6 +733,19 @@ func (s *ExecutionService) GetExecution(ctx context.Context, id string) (*models
 	return execution, nil
 }
 
+// ListExecutions gets a page of the executions of a blueprint matching a filter
+func (s *ExecutionService) ListExecutions(ctx context.Context, filter repository.ExecutionFilter, page paging.Request) (paging.Page[*models.Execution], error) {
+	if _, err := s.blueprintRepo.GetByID(ctx, filter.BlueprintID); err != nil {
+		return paging.Page[*models.Execution]{}, fmt.Errorf("blueprint not found: %w", err)
+	}
+
+	executions, err := s.executionRepo.List(ctx, filter, page)
+	if err != nil {
+		return executions, fmt.Errorf("error retrieving executions: %w", err)
+	}
+	return executions, nil
+}
+
 // GetExecutionsByBlueprint retrieves all executions for a blueprint
 func (s *ExecutionService) GetExecutionsByBlueprint(ctx context.Context, blueprintID string) ([]*models.Execution, error) {
 	// Check if blueprint exists
@@ -171,6 +780,129 @@ func (s *ExecutionService) GetExecutionLogs(ctx context.Context, executionID str
 	return logs, nil
 }
 
+// Bounds of the pages of node logs
+const (
+	defaultNodeLogLimit = 100
+	maxNodeLogLimit     = 1000
+)
+
+// NodeLogEntry is a log entry of a node of an execution
+type NodeLogEntry struct {
+	ID        string                 `json:"id"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// NodeLogPage is a page of the logs of a node of an execution, oldest first
+type NodeLogPage struct {
+	ExecutionID string         `json:"executionId"`
+	NodeID      string         `json:"nodeId"`
+	Level       string         `json:"level"` // Least severe level listed
+	Total       int            `json:"total"` // Logs at or above the level
+	Limit       int            `json:"limit"`
+	Offset      int            `json:"offset"`
+	Logs        []NodeLogEntry `json:"logs"`
+}
+
+// GetNodeLogs retrieves a page of the logs of a node of an execution at or above a log
+// level, debug if empty. The limit defaults to 100 and is capped at 1000.
+func (s *ExecutionService) GetNodeLogs(ctx context.Context, executionID, nodeID, level string, limit, offset int) (*NodeLogPage, error) {
+	if level == "" {
+		level = blueprint.LogLevelDebug
+	}
+	rank := blueprint.LogLevelRank(level)
+	if rank < 0 {
+		return nil, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	if limit <= 0 {
+		limit = defaultNodeLogLimit
+	}
+	if limit > maxNodeLogLimit {
+		limit = maxNodeLogLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Check if execution exists
+	_, err := s.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("execution not found: %w", err)
+	}
+
+	logs, total, err := s.executionRepo.GetNodeLogs(ctx, executionID, nodeID, rank, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving node logs: %w", err)
+	}
+
+	page := &NodeLogPage{
+		ExecutionID: executionID,
+		NodeID:      nodeID,
+		Level:       strings.ToLower(level),
+		Total:       total,
+		Limit:       limit,
+		Offset:      offset,
+		Logs:        make([]NodeLogEntry, 0, len(logs)),
+	}
+	for _, log := range logs {
+		page.Logs = append(page.Logs, NodeLogEntry{
+			ID:        log.ID,
+			Level:     strings.ToLower(log.LogLevel),
+			Message:   log.Message,
+			Fields:    log.Details,
+			Timestamp: log.Timestamp,
+		})
+	}
+	return page, nil
+}
+
+// GetExecutionTimeline returns the node execution timeline of an execution. Timelines of
+// executions still retained in memory include the pin activations between nodes; older
+// executions are rebuilt from the stored node executions without trigger information.
+func (s *ExecutionService) GetExecutionTimeline(ctx context.Context, executionID string) (*engine.ExecutionTimeline, error) {
+	// Check if execution exists
+	_, err := s.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("execution not found: %w", err)
+	}
+
+	if timeline, exists := s.executionEngine.GetExecutionTimeline(executionID); exists {
+		return timeline, nil
+	}
+
+	nodes, err := s.executionRepo.GetNodeExecutions(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving node executions: %w", err)
+	}
+
+	spans := make([]engine.TimelineSpan, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.StartedAt.Valid {
+			continue
+		}
+
+		span := engine.TimelineSpan{
+			NodeID:    node.NodeID,
+			NodeType:  node.NodeType,
+			Status:    node.Status,
+			StartTime: node.StartedAt.Time,
+		}
+		if node.CompletedAt.Valid {
+			span.EndTime = node.CompletedAt.Time
+		} else if node.DurationMs.Valid {
+			span.EndTime = span.StartTime.Add(time.Duration(node.DurationMs.Int32) * time.Millisecond)
+		}
+		if node.Error.Valid {
+			span.Error = node.Error.String
+		}
+		spans = append(spans, span)
+	}
+
+	return engine.BuildExecutionTimeline(executionID, spans), nil
+}
+
 // RecordNodeExecution records the execution of a node
 func (s *ExecutionService) RecordNodeExecution(
 	ctx context.Context,
@@ -184,6 +916,17 @@ func (s *ExecutionService) RecordNodeExecution(
 	return nil
 }
 
+// SpillDebugData persists the debug data of an execution evicted from memory
+func (s *ExecutionService) SpillDebugData(executionID string, nodeData map[string]map[string]interface{}) error {
+	ctx := context.Background()
+	for nodeID, data := range nodeData {
+		if err := s.executionRepo.SaveNodeDebugData(ctx, executionID, nodeID, data); err != nil {
+			return fmt.Errorf("failed to spill debug data of node %s: %w", nodeID, err)
+		}
+	}
+	return nil
+}
+
 // UpdateNodeStatus updates the status of a node execution
 func (s *ExecutionService) UpdateNodeStatus(
 	ctx context.Context,
@@ -218,7 +961,7 @@ func (s *ExecutionService) CancelExecution(ctx context.Context, executionID stri
 	}
 
 	// Check if execution can be canceled
-	if execution.Status != "running" {
+	if execution.Status != "queued" && execution.Status != "running" {
 		return fmt.Errorf("execution cannot be canceled: status is %s", execution.Status)
 	}
 
@@ -228,8 +971,10 @@ func (s *ExecutionService) CancelExecution(ctx context.Context, executionID stri
 		return fmt.Errorf("failed to update execution status: %w", err)
 	}
 
-	// TODO: Signal the execution engine to stop this execution
+	// Queued executions never start; running ones can't be stopped yet
+	// TODO: Signal the execution engine to stop running executions
 	// This would require adding cancellation capabilities to the engine
+	s.executionEngine.CancelQueuedExecution(executionID)
 
 	return nil
 }
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultBatchConcurrency is used when a batch request doesn't specify a concurrency
+	DefaultBatchConcurrency = 4
+	// MaxBatchConcurrency caps how many executions of a batch may run at once
+	MaxBatchConcurrency = 32
+
+	// batchQuotaRetryInterval is how long a batch item waits before retrying a rate limited slot
+	batchQuotaRetryInterval = time.Second
+	// batchQuotaTimeout is how long a batch item waits for a slot before it fails
+	batchQuotaTimeout = 10 * time.Minute
+
+	// batchRetention is how long a finished batch can still be queried
+	batchRetention = time.Hour
+)
+
+// Batch and batch item statuses
+const (
+	BatchStatusPending   = "pending"
+	BatchStatusRunning   = "running"
+	BatchStatusCompleted = "completed"
+	BatchStatusFailed    = "failed"
+	BatchStatusSkipped   = "skipped" // The entry point guards rejected the inputs of the item
+)
+
+// BatchItem is the result of a single input set within a batch
+type BatchItem struct {
+	Index       int                    `json:"index"`
+	ExecutionID string                 `json:"executionId,omitempty"`
+	Status      string                 `json:"status"`
+	Inputs      map[string]interface{} `json:"inputs"`
+	Error       string                 `json:"error,omitempty"`
+	StartedAt   *time.Time             `json:"startedAt,omitempty"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+}
+
+// ExecutionBatch tracks a blueprint run over many input sets
+type ExecutionBatch struct {
+	ID          string       `json:"id"`
+	BlueprintID string       `json:"blueprintId"`
+	Status      string       `json:"status"`
+	Concurrency int          `json:"concurrency"`
+	Total       int          `json:"total"`
+	Completed   int          `json:"completed"`
+	Failed      int          `json:"failed"`
+	InitiatedBy string       `json:"initiatedBy"`
+	StartedAt   time.Time    `json:"startedAt"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty"`
+	Items       []*BatchItem `json:"items,omitempty"`
+
+	mutex sync.RWMutex
+}
+
+// BatchProgress is the aggregate progress reported while a batch runs
+type BatchProgress struct {
+	BatchID     string `json:"batchId"`
+	BlueprintID string `json:"blueprintId"`
+	Status      string `json:"status"`
+	Total       int    `json:"total"`
+	Completed   int    `json:"completed"`
+	Failed      int    `json:"failed"`
+}
+
+// snapshot returns a copy of the batch that is safe to serialize
+func (b *ExecutionBatch) snapshot(includeItems bool) *ExecutionBatch {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	copied := &ExecutionBatch{
+		ID:          b.ID,
+		BlueprintID: b.BlueprintID,
+		Status:      b.Status,
+		Concurrency: b.Concurrency,
+		Total:       b.Total,
+		Completed:   b.Completed,
+		Failed:      b.Failed,
+		InitiatedBy: b.InitiatedBy,
+		StartedAt:   b.StartedAt,
+		CompletedAt: b.CompletedAt,
+	}
+
+	if includeItems {
+		copied.Items = make([]*BatchItem, len(b.Items))
+		for i, item := range b.Items {
+			itemCopy := *item
+			copied.Items[i] = &itemCopy
+		}
+	}
+
+	return copied
+}
+
+// progress returns the aggregate progress of the batch
+func (b *ExecutionBatch) progress() BatchProgress {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return BatchProgress{
+		BatchID:     b.ID,
+		BlueprintID: b.BlueprintID,
+		Status:      b.Status,
+		Total:       b.Total,
+		Completed:   b.Completed,
+		Failed:      b.Failed,
+	}
+}
+
+// StartBatchExecution runs a blueprint once per input set with bounded concurrency.
+// It returns immediately with the batch; item results can be queried with GetBatch.
+func (s *ExecutionService) StartBatchExecution(
+	ctx context.Context,
+	blueprintID string,
+	inputs []map[string]interface{},
+	concurrency int,
+	userID string,
+) (*ExecutionBatch, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("batch must contain at least one input set")
+	}
+
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > MaxBatchConcurrency {
+		concurrency = MaxBatchConcurrency
+	}
+
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("blueprint not found: %w", err)
+	}
+
+	bp, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blueprint: %w", err)
+	}
+
+	if err := s.checkNodePolicies(ctx, blueprintModel, bp); err != nil {
+		return nil, err
+	}
+
+	// The items of a batch share the deprecation check of its start
+	deprecation, err := s.checkDeprecation(ctx, blueprintModel, versionNumberOf(blueprintModel.CurrentVersion), TriggerBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject batches whose blueprint can never fit the workspace quota
+	if s.quotaService != nil {
+		quota, err := s.quotaService.GetQuota(ctx, blueprintModel.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if quota.MaxNodesPerBlueprint > 0 && len(bp.Nodes) > quota.MaxNodesPerBlueprint {
+			return nil, bperrors.New(
+				bperrors.ErrorTypeQuota,
+				bperrors.ErrBlueprintTooLarge,
+				fmt.Sprintf("blueprint has %d nodes, exceeding the workspace limit of %d", len(bp.Nodes), quota.MaxNodesPerBlueprint),
+				bperrors.SeverityHigh,
+			).WithBlueprintInfo(blueprintID, "")
+		}
+	}
+
+	batch := &ExecutionBatch{
+		ID:          uuid.New().String(),
+		BlueprintID: blueprintID,
+		Status:      BatchStatusRunning,
+		Concurrency: concurrency,
+		Total:       len(inputs),
+		InitiatedBy: userID,
+		StartedAt:   time.Now(),
+		Items:       make([]*BatchItem, len(inputs)),
+	}
+	for i, input := range inputs {
+		if input == nil {
+			input = make(map[string]interface{})
+		}
+		batch.Items[i] = &BatchItem{
+			Index:  i,
+			Status: BatchStatusPending,
+			Inputs: input,
+		}
+	}
+
+	s.batchMutex.Lock()
+	s.pruneBatchesLocked(time.Now())
+	s.batches[batch.ID] = batch
+	s.batchMutex.Unlock()
+
+	go func() {
+		// Get a background context since the request context will be canceled
+		bgCtx := context.Background()
+
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+
+		for _, item := range batch.Items {
+			sem <- struct{}{}
+			wg.Add(1)
+
+			go func(item *BatchItem) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				executionID := uuid.New().String()
+				startedAt := time.Now()
+
+				batch.mutex.Lock()
+				item.ExecutionID = executionID
+				item.Status = BatchStatusRunning
+				item.StartedAt = &startedAt
+				batch.mutex.Unlock()
+
+				// Items whose inputs the entry point guards reject don't create an execution
+				variables, err := toEngineVariables(item.Inputs)
+				if err == nil {
+					err = s.executionEngine.CheckGuards(bp, variables)
+				}
+				if _, skipped := IsTriggerSkippedError(err); skipped {
+					completedAt := time.Now()
+					batch.mutex.Lock()
+					item.ExecutionID = ""
+					item.CompletedAt = &completedAt
+					item.Status = BatchStatusSkipped
+					item.Error = err.Error()
+					batch.Completed++
+					batch.mutex.Unlock()
+
+					s.reportBatchProgress(batch)
+					return
+				}
+
+				if err == nil {
+					var release func()
+					var limits engine.ExecutionLimits
+					quotaCtx, cancel := context.WithTimeout(bgCtx, batchQuotaTimeout)
+					release, limits, err = s.acquireBatchQuota(quotaCtx, blueprintModel, bp)
+					cancel()
+					if err == nil {
+						err = s.createExecutionRecord(bgCtx, executionID, blueprintModel, blueprintModel.CurrentVersionID, item.Inputs, userID, "standard")
+						if err == nil {
+							s.warnDeprecated(bgCtx, executionID, deprecation, TriggerBatch)
+							// Batches are background work and must not starve interactive runs
+							err = s.runExecution(bp, executionID, variables, engine.PriorityLow, limits)
+						}
+						release()
+					}
+				}
+
+				completedAt := time.Now()
+				batch.mutex.Lock()
+				item.CompletedAt = &completedAt
+				if err != nil {
+					item.Status = BatchStatusFailed
+					item.Error = err.Error()
+					batch.Failed++
+				} else {
+					item.Status = BatchStatusCompleted
+				}
+				batch.Completed++
+				batch.mutex.Unlock()
+
+				s.reportBatchProgress(batch)
+			}(item)
+		}
+
+		wg.Wait()
+
+		completedAt := time.Now()
+		batch.mutex.Lock()
+		batch.CompletedAt = &completedAt
+		if batch.Failed > 0 {
+			batch.Status = BatchStatusFailed
+		} else {
+			batch.Status = BatchStatusCompleted
+		}
+		batch.mutex.Unlock()
+
+		s.reportBatchProgress(batch)
+	}()
+
+	s.reportBatchProgress(batch)
+
+	return batch.snapshot(false), nil
+}
+
+// acquireBatchQuota waits for a workspace execution slot. Rate and concurrency
+// limits are retried since batches run in the background until the context is done;
+// other quota errors fail the item.
+func (s *ExecutionService) acquireBatchQuota(
+	ctx context.Context,
+	blueprintModel *models.Blueprint,
+	bp *blueprint.Blueprint,
+) (func(), engine.ExecutionLimits, error) {
+	for {
+		release, limits, err := s.acquireQuota(ctx, blueprintModel, bp)
+		if err == nil {
+			return release, limits, nil
+		}
+
+		bpErr, isQuota := IsQuotaError(err)
+		if !isQuota || (bpErr.Code != bperrors.ErrRateLimitExceeded && bpErr.Code != bperrors.ErrConcurrencyLimitExceeded) {
+			return nil, limits, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, limits, fmt.Errorf("gave up waiting for an execution slot: %w", err)
+		case <-time.After(batchQuotaRetryInterval):
+		}
+	}
+}
+
+// GetBatch returns a batch with its item-level results
+func (s *ExecutionService) GetBatch(ctx context.Context, batchID string) (*ExecutionBatch, error) {
+	s.batchMutex.Lock()
+	s.pruneBatchesLocked(time.Now())
+	batch, exists := s.batches[batchID]
+	s.batchMutex.Unlock()
+
+	if !exists {
+		return nil, fmt.Errorf("batch not found: %s", batchID)
+	}
+
+	return batch.snapshot(true), nil
+}
+
+// pruneBatchesLocked drops the batches that finished more than batchRetention before now.
+// The caller must hold batchMutex.
+func (s *ExecutionService) pruneBatchesLocked(now time.Time) {
+	for id, batch := range s.batches {
+		batch.mutex.RLock()
+		expired := batch.CompletedAt != nil && now.Sub(*batch.CompletedAt) > batchRetention
+		batch.mutex.RUnlock()
+
+		if expired {
+			delete(s.batches, id)
+		}
+	}
+}
+
+// reportBatchProgress forwards the batch progress to the progress hook, if any
+func (s *ExecutionService) reportBatchProgress(batch *ExecutionBatch) {
+	if s.OnBatchProgressHook != nil {
+		s.OnBatchProgressHook(batch.progress())
+	}
+}
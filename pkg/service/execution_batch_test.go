@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"webblueprint/pkg/blueprint"
+)
+
+// printBlueprint starts with an event node running a print node
+func printBlueprint(id string) *blueprint.Blueprint {
+	bp := blueprint.NewBlueprint(id, "Print", "1.0.0")
+	bp.AddNode(blueprint.BlueprintNode{ID: "start", Type: "event-on-created"})
+	bp.AddNode(blueprint.BlueprintNode{ID: "print", Type: "print"})
+	bp.AddConnection(blueprint.Connection{
+		ID:             "exec-print",
+		SourceNodeID:   "start",
+		SourcePinID:    "then",
+		TargetNodeID:   "print",
+		TargetPinID:    "execute",
+		ConnectionType: "execution",
+	})
+	return bp
+}
+
+// waitForBatch polls the batch until it finishes
+func waitForBatch(t *testing.T, s *ExecutionService, batchID string) *ExecutionBatch {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		batch, err := s.GetBatch(context.Background(), batchID)
+		if err != nil {
+			t.Fatalf("failed to get batch: %v", err)
+		}
+		if batch.CompletedAt != nil {
+			return batch
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("batch %s didn't finish", batchID)
+	return nil
+}
+
+func newBatchTestService(t *testing.T) (*ExecutionService, *fakeExecutionRepo) {
+	t.Helper()
+	blueprints := newFakeBlueprintRepo()
+	blueprints.add("workspace", "owner", printBlueprint("bp"))
+	executions := newFakeExecutionRepo()
+	return NewExecutionService(executions, blueprints, newTestEngine(t)), executions
+}
+
+func TestStartBatchExecutionRunsEveryItem(t *testing.T) {
+	s, executions := newBatchTestService(t)
+
+	inputs := []map[string]interface{}{{"n": 1}, {"n": 2}, nil, {"n": 4}, {"n": 5}}
+	started, err := s.StartBatchExecution(context.Background(), "bp", inputs, 2, "user")
+	if err != nil {
+		t.Fatalf("failed to start batch: %v", err)
+	}
+	if started.Total != len(inputs) || started.Concurrency != 2 {
+		t.Fatalf("unexpected batch %+v", started)
+	}
+
+	batch := waitForBatch(t, s, started.ID)
+	if batch.Status != BatchStatusCompleted {
+		t.Errorf("expected the batch to complete, got %s", batch.Status)
+	}
+	if batch.Completed != len(inputs) || batch.Failed != 0 {
+		t.Errorf("expected %d completed items without failures, got %d completed and %d failed", len(inputs), batch.Completed, batch.Failed)
+	}
+	if len(batch.Items) != len(inputs) {
+		t.Fatalf("expected %d items, got %d", len(inputs), len(batch.Items))
+	}
+	for _, item := range batch.Items {
+		if item.Status != BatchStatusCompleted {
+			t.Errorf("item %d: expected status %s, got %s (%s)", item.Index, BatchStatusCompleted, item.Status, item.Error)
+		}
+		if status := executions.status(item.ExecutionID); status != "completed" {
+			t.Errorf("item %d: expected a completed execution record, got %q", item.Index, status)
+		}
+	}
+}
+
+func TestStartBatchExecutionClampsConcurrency(t *testing.T) {
+	s, _ := newBatchTestService(t)
+
+	for requested, expected := range map[int]int{0: DefaultBatchConcurrency, -3: DefaultBatchConcurrency, 1000: MaxBatchConcurrency} {
+		batch, err := s.StartBatchExecution(context.Background(), "bp", []map[string]interface{}{{}}, requested, "user")
+		if err != nil {
+			t.Fatalf("failed to start batch: %v", err)
+		}
+		if batch.Concurrency != expected {
+			t.Errorf("concurrency %d: expected %d, got %d", requested, expected, batch.Concurrency)
+		}
+		waitForBatch(t, s, batch.ID)
+	}
+}
+
+func TestStartBatchExecutionRejectsInvalidBatches(t *testing.T) {
+	s, _ := newBatchTestService(t)
+
+	if _, err := s.StartBatchExecution(context.Background(), "bp", nil, 1, "user"); err == nil {
+		t.Error("expected a batch without input sets to be rejected")
+	}
+	if _, err := s.StartBatchExecution(context.Background(), "missing", []map[string]interface{}{{}}, 1, "user"); err == nil {
+		t.Error("expected a batch of an unknown blueprint to be rejected")
+	}
+}
+
+func TestGetBatchEvictsFinishedBatches(t *testing.T) {
+	s, _ := newBatchTestService(t)
+
+	if _, err := s.GetBatch(context.Background(), "missing"); err == nil {
+		t.Error("expected an unknown batch to be reported")
+	}
+
+	started, err := s.StartBatchExecution(context.Background(), "bp", []map[string]interface{}{{}}, 1, "user")
+	if err != nil {
+		t.Fatalf("failed to start batch: %v", err)
+	}
+	batch := waitForBatch(t, s, started.ID)
+
+	// Batches are kept for the retention period after they finish
+	s.batchMutex.Lock()
+	s.pruneBatchesLocked(batch.CompletedAt.Add(batchRetention / 2))
+	s.batchMutex.Unlock()
+	if _, err := s.GetBatch(context.Background(), started.ID); err != nil {
+		t.Fatalf("expected the batch to be kept within the retention: %v", err)
+	}
+
+	s.batchMutex.Lock()
+	s.pruneBatchesLocked(batch.CompletedAt.Add(batchRetention + time.Second))
+	s.batchMutex.Unlock()
+	if _, err := s.GetBatch(context.Background(), started.ID); err == nil {
+		t.Error("expected the batch to be evicted after the retention")
+	}
+}
+
+func TestAcquireBatchQuotaStopsWhenContextIsDone(t *testing.T) {
+	s, _ := newBatchTestService(t)
+	quotas := NewQuotaService(nil)
+	quotas.quotas["workspace"] = WorkspaceQuota{MaxConcurrentExecutions: 1}
+	s.SetQuotaService(quotas)
+
+	release, err := quotas.Acquire(context.Background(), "workspace", "bp", 2)
+	if err != nil {
+		t.Fatalf("failed to take the only slot: %v", err)
+	}
+	defer release()
+
+	model, _ := s.blueprintRepo.GetByID(context.Background(), "bp")
+	bp, _ := s.blueprintRepo.ToPkgBlueprint(model, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if _, _, err := s.acquireBatchQuota(ctx, model, bp); err == nil {
+		t.Fatal("expected waiting for a slot to fail once the context is done")
+	}
+	if elapsed := time.Since(started); elapsed > 5*batchQuotaRetryInterval {
+		t.Errorf("expected the wait to stop with the context, took %s", elapsed)
+	}
+}
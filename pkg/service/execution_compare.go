@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"webblueprint/internal/comparison"
+	"webblueprint/internal/engine"
+)
+
+// ErrExecutionRunning is returned when comparing an execution that hasn't finished yet
+var ErrExecutionRunning = errors.New("execution is still running")
+
+// ComparisonTarget is a side of a comparison run: a blueprint and the version to run, its
+// current version when the version number is 0
+type ComparisonTarget struct {
+	BlueprintID string `json:"blueprintId"`
+	Version     int    `json:"version,omitempty"`
+}
+
+// ComparisonRun is a pair of executions started with the same inputs, and their comparison
+// once both are done
+type ComparisonRun struct {
+	BaselineExecutionID  string                 `json:"baselineExecutionId"`
+	CandidateExecutionID string                 `json:"candidateExecutionId"`
+	Done                 bool                   `json:"done"`
+	Comparison           *comparison.Comparison `json:"comparison,omitempty"`
+}
+
+// RunComparison executes the baseline and the candidate with the same variables
+// concurrently and compares them once both are done. If they're still running when the
+// wait elapses, the run is returned without a comparison, which CompareExecutions makes once
+// they're done.
+func (s *ExecutionService) RunComparison(
+	ctx context.Context,
+	baseline, candidate ComparisonTarget,
+	variables map[string]interface{},
+	wait time.Duration,
+	userID string,
+) (*ComparisonRun, error) {
+	baselineID, err := s.StartVersionExecution(ctx, baseline.BlueprintID, baseline.Version, variables, userID, engine.PriorityNormal)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the baseline: %w", err)
+	}
+	candidateID, err := s.StartVersionExecution(ctx, candidate.BlueprintID, candidate.Version, variables, userID, engine.PriorityNormal)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the candidate: %w", err)
+	}
+
+	run := &ComparisonRun{BaselineExecutionID: baselineID, CandidateExecutionID: candidateID}
+	deadline := time.Now().Add(min(wait, MaxResultWait))
+	for _, executionID := range []string{baselineID, candidateID} {
+		result, err := s.WaitForExecutionResult(ctx, executionID, time.Until(deadline))
+		if err != nil {
+			return nil, err
+		}
+		if !result.Done {
+			return run, nil
+		}
+	}
+
+	run.Comparison, err = s.CompareExecutions(ctx, baselineID, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	run.Done = true
+	return run, nil
+}
+
+// CompareExecutions compares two finished executions, the candidate against the baseline:
+// their outputs, the time each node took, and the branches only one of them followed
+func (s *ExecutionService) CompareExecutions(ctx context.Context, baselineID, candidateID string) (*comparison.Comparison, error) {
+	baseline, err := s.comparisonRun(ctx, baselineID)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := s.comparisonRun(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	return comparison.Compare(baseline, candidate), nil
+}
+
+// comparisonRun reads the outcome and timeline of a finished execution
+func (s *ExecutionService) comparisonRun(ctx context.Context, executionID string) (comparison.Run, error) {
+	result, err := s.getExecutionResult(ctx, executionID)
+	if err != nil {
+		return comparison.Run{}, err
+	}
+	if !result.Done {
+		return comparison.Run{}, fmt.Errorf("%w: %s", ErrExecutionRunning, executionID)
+	}
+
+	timeline, err := s.GetExecutionTimeline(ctx, executionID)
+	if err != nil {
+		return comparison.Run{}, err
+	}
+
+	run := comparison.Run{
+		ExecutionID: executionID,
+		Status:      result.Status,
+		Error:       result.Error,
+		DurationMs:  timeline.DurationMs,
+		Outputs:     result.Outputs,
+		Timeline:    timeline,
+	}
+	if len(timeline.Spans) == 0 && result.DurationMs != nil {
+		run.DurationMs = float64(*result.DurationMs)
+	}
+	return run, nil
+}
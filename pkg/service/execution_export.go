@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"webblueprint/internal/export"
+	"webblueprint/pkg/models"
+)
+
+// ExportExecution writes the output pin values of the nodes of a finished execution to the
+// table writer, which the caller closes. The values come from the result of the execution,
+// so failed executions have none.
+func (s *ExecutionService) ExportExecution(ctx context.Context, execution *models.Execution, w export.Writer) error {
+	nodeExecutions, err := s.executionRepo.GetNodeExecutions(ctx, execution.ID)
+	if err != nil {
+		return fmt.Errorf("error retrieving node executions: %w", err)
+	}
+	recorded := make(map[string]*models.ExecutionNode, len(nodeExecutions))
+	for _, nodeExecution := range nodeExecutions {
+		recorded[nodeExecution.NodeID] = nodeExecution
+	}
+
+	nodes := make([]export.NodeResult, 0, len(execution.Result))
+	for nodeID, outputs := range execution.Result {
+		result := export.NodeResult{NodeID: nodeID, Status: "completed"}
+		result.Outputs, _ = outputs.(map[string]interface{})
+		if nodeExecution, exists := recorded[nodeID]; exists {
+			result.NodeType = nodeExecution.NodeType
+			result.Status = nodeExecution.Status
+		}
+		nodes = append(nodes, result)
+	}
+
+	return export.WriteExecution(w, execution.ID, execution.BlueprintID, nodes)
+}
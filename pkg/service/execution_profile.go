@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"webblueprint/internal/engine"
+	"webblueprint/pkg/models"
+)
+
+// profilingKey is the context key marking the executions to profile
+type profilingKey struct{}
+
+// WithProfiling returns a copy of ctx with which executions are started profiled: the time
+// and allocations of their node executions are stored once they end
+func WithProfiling(ctx context.Context) context.Context {
+	return context.WithValue(ctx, profilingKey{}, true)
+}
+
+// profilingRequested checks if executions started with a context are profiled
+func profilingRequested(ctx context.Context) bool {
+	profiled, _ := ctx.Value(profilingKey{}).(bool)
+	return profiled
+}
+
+// saveProfile persists the node execution profile of an execution
+func (s *ExecutionService) saveProfile(ctx context.Context, profile *engine.ExecutionProfile) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	var stored models.JSONB
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	return s.executionRepo.SaveProfile(ctx, profile.ExecutionID, stored)
+}
+
+// GetExecutionProfile returns the node execution profile of a profiled execution that ended
+func (s *ExecutionService) GetExecutionProfile(ctx context.Context, executionID string) (*engine.ExecutionProfile, error) {
+	stored, err := s.executionRepo.GetProfile(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+
+	var profile engine.ExecutionProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxResultWait caps how long a request may wait for the result of an execution
+	MaxResultWait = time.Minute
+
+	// resultPollInterval is how often the result of an execution run by another server is
+	// looked up while waiting for it
+	resultPollInterval = 500 * time.Millisecond
+)
+
+// ExecutionResult is the outcome of an execution, or its status while it's still running
+type ExecutionResult struct {
+	ExecutionID string                 `json:"executionId"`
+	BlueprintID string                 `json:"blueprintId"`
+	Status      string                 `json:"status"`
+	Done        bool                   `json:"done"`
+	Outputs     map[string]interface{} `json:"outputs,omitempty"` // Outputs of each node, by node ID
+	Error       string                 `json:"error,omitempty"`
+	StartedAt   time.Time              `json:"startedAt"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+	DurationMs  *int32                 `json:"durationMs,omitempty"`
+	Budget      map[string]interface{} `json:"budget,omitempty"`    // Consumption of the blueprint's execution budget
+	RequestID   string                 `json:"requestId,omitempty"` // ID of the API request that started the execution
+}
+
+// trackExecution registers an execution whose completion requests can wait for
+func (s *ExecutionService) trackExecution(executionID string) {
+	s.doneMutex.Lock()
+	defer s.doneMutex.Unlock()
+	s.done[executionID] = make(chan struct{})
+}
+
+// finishExecution wakes the requests waiting for an execution
+func (s *ExecutionService) finishExecution(executionID string) {
+	s.doneMutex.Lock()
+	defer s.doneMutex.Unlock()
+	if done, exists := s.done[executionID]; exists {
+		close(done)
+		delete(s.done, executionID)
+	}
+}
+
+// WaitForExecutionResult returns the result of an execution once it's done, or its status
+// once the wait elapses. Executions this server runs wake the waiting requests as soon as
+// they finish, while the others are looked up periodically.
+func (s *ExecutionService) WaitForExecutionResult(ctx context.Context, executionID string, wait time.Duration) (*ExecutionResult, error) {
+	if wait > MaxResultWait {
+		wait = MaxResultWait
+	}
+
+	// Take the channel before reading the status, so a completion in between isn't missed
+	s.doneMutex.Lock()
+	done, tracked := s.done[executionID]
+	s.doneMutex.Unlock()
+
+	result, err := s.getExecutionResult(ctx, executionID)
+	if err != nil || result.Done || wait <= 0 {
+		return result, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	var poll <-chan time.Time
+	if !tracked {
+		ticker := time.NewTicker(resultPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return s.getExecutionResult(ctx, executionID)
+		case <-poll:
+			if result, err = s.getExecutionResult(ctx, executionID); err != nil || result.Done {
+				return result, err
+			}
+		case <-timer.C:
+			return s.getExecutionResult(ctx, executionID)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// getExecutionResult reads the result of an execution from its record
+func (s *ExecutionService) getExecutionResult(ctx context.Context, executionID string) (*ExecutionResult, error) {
+	execution, err := s.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("execution not found: %w", err)
+	}
+
+	result := &ExecutionResult{
+		ExecutionID: execution.ID,
+		BlueprintID: execution.BlueprintID,
+		Status:      execution.Status,
+		Done:        execution.Status != "queued" && execution.Status != "running",
+		StartedAt:   execution.StartedAt,
+		RequestID:   execution.RequestID.String,
+	}
+	if !result.Done {
+		return result, nil
+	}
+
+	result.Outputs = execution.Result
+	result.Budget = execution.Budget
+	if execution.Error.Valid {
+		result.Error = execution.Error.String
+	}
+	if execution.CompletedAt.Valid {
+		completedAt := execution.CompletedAt.Time
+		result.CompletedAt = &completedAt
+	}
+	if execution.DurationMs.Valid {
+		durationMs := execution.DurationMs.Int32
+		result.DurationMs = &durationMs
+	}
+	return result, nil
+}
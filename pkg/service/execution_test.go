@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"webblueprint/internal/engine"
+)
+
+func TestStartVersionExecutionRejectsUnknownVersions(t *testing.T) {
+	s, executions := newBatchTestService(t)
+
+	if _, err := s.StartVersionExecution(context.Background(), "bp", 7, nil, "user", engine.PriorityNormal); err == nil {
+		t.Fatal("expected an unknown version to be rejected")
+	}
+	if len(executions.executions) != 0 {
+		t.Errorf("expected no execution to be recorded, got %d", len(executions.executions))
+	}
+	if versions := s.executionEngine.LoadedVersions("bp"); len(versions) != 0 {
+		t.Errorf("expected no version to be loaded, got %v", versions)
+	}
+}
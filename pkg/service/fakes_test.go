@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/engineext"
+	"webblueprint/internal/event"
+	"webblueprint/internal/nodes"
+	"webblueprint/internal/registry"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// nopLogger discards the log of the engine
+type nopLogger struct{}
+
+func (nopLogger) Opts(map[string]interface{})          {}
+func (nopLogger) Debug(string, map[string]interface{}) {}
+func (nopLogger) Info(string, map[string]interface{})  {}
+func (nopLogger) Warn(string, map[string]interface{})  {}
+func (nopLogger) Error(string, map[string]interface{}) {}
+
+// newTestEngine creates a standard mode engine with the core node types
+func newTestEngine(t testing.TB) *engine.ExecutionEngine {
+	t.Helper()
+	registry.Make()
+	for typeID, factory := range nodes.Core {
+		registry.GetInstance().RegisterNodeType(typeID, factory)
+	}
+
+	errorManager := bperrors.NewErrorManager()
+	recoveryManager := bperrors.NewRecoveryManager(errorManager)
+	flowEngine := engine.NewExecutionEngine(nopLogger{}, engine.NewDebugManager())
+	eventManager := event.NewEventManager(flowEngine)
+	contextManager := engineext.NewContextManager(errorManager, recoveryManager, eventManager.AsEventManagerInterface(), nil)
+	flowEngine.SetExtensions(engineext.InitializeExtensions(flowEngine, contextManager, errorManager, recoveryManager, eventManager))
+	for typeID, factory := range nodes.Core {
+		flowEngine.RegisterNodeType(typeID, factory)
+	}
+	return flowEngine
+}
+
+// fakeBlueprintRepo serves blueprints from memory. Methods the tests don't use panic.
+type fakeBlueprintRepo struct {
+	repository.BlueprintRepository
+
+	models     map[string]*models.Blueprint
+	blueprints map[string]*blueprint.Blueprint
+}
+
+func newFakeBlueprintRepo() *fakeBlueprintRepo {
+	return &fakeBlueprintRepo{
+		models:     make(map[string]*models.Blueprint),
+		blueprints: make(map[string]*blueprint.Blueprint),
+	}
+}
+
+// add stores a blueprint in the workspace
+func (r *fakeBlueprintRepo) add(workspaceID, ownerID string, bp *blueprint.Blueprint) *models.Blueprint {
+	model := &models.Blueprint{Asset: models.Asset{ID: bp.ID, Name: bp.Name, WorkspaceID: workspaceID, CreatedBy: ownerID}}
+	r.models[bp.ID] = model
+	r.blueprints[bp.ID] = bp
+	return model
+}
+
+func (r *fakeBlueprintRepo) GetByID(ctx context.Context, id string) (*models.Blueprint, error) {
+	model, exists := r.models[id]
+	if !exists {
+		return nil, fmt.Errorf("blueprint %s does not exist", id)
+	}
+	return model, nil
+}
+
+func (r *fakeBlueprintRepo) GetVersion(ctx context.Context, blueprintID string, versionNumber int) (*models.BlueprintVersion, error) {
+	return nil, fmt.Errorf("version %d of blueprint %s does not exist", versionNumber, blueprintID)
+}
+
+func (r *fakeBlueprintRepo) ToPkgBlueprint(model *models.Blueprint, version *models.BlueprintVersion) (*blueprint.Blueprint, error) {
+	bp, exists := r.blueprints[model.ID]
+	if !exists {
+		return nil, fmt.Errorf("blueprint %s does not exist", model.ID)
+	}
+	return bp, nil
+}
+
+// fakeExecutionRepo keeps execution records in memory and discards their debug data
+type fakeExecutionRepo struct {
+	repository.ExecutionRepository
+
+	mutex      sync.Mutex
+	executions map[string]*models.Execution
+}
+
+func newFakeExecutionRepo() *fakeExecutionRepo {
+	return &fakeExecutionRepo{executions: make(map[string]*models.Execution)}
+}
+
+func (r *fakeExecutionRepo) Create(ctx context.Context, execution *models.Execution) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.executions[execution.ID] = execution
+	return nil
+}
+
+func (r *fakeExecutionRepo) GetByID(ctx context.Context, id string) (*models.Execution, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	execution, exists := r.executions[id]
+	if !exists {
+		return nil, fmt.Errorf("execution %s does not exist", id)
+	}
+	copied := *execution
+	return &copied, nil
+}
+
+func (r *fakeExecutionRepo) UpdateStatus(ctx context.Context, id, status string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if execution, exists := r.executions[id]; exists {
+		execution.Status = status
+	}
+	return nil
+}
+
+func (r *fakeExecutionRepo) Complete(ctx context.Context, id string, success bool, result map[string]interface{}, errorMsg string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if execution, exists := r.executions[id]; exists {
+		execution.Status = "completed"
+		if !success {
+			execution.Status = "failed"
+		}
+	}
+	return nil
+}
+
+func (r *fakeExecutionRepo) RecordNodeExecution(ctx context.Context, executionID, nodeID, nodeType, execState string, inputs, outputs map[string]interface{}) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) UpdateNodeStatus(ctx context.Context, executionID, nodeID, status string) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) AddLogEntry(ctx context.Context, executionID, nodeID, level, message string, details map[string]interface{}) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) RecordUsage(ctx context.Context, executionID string, nodesExecuted int, httpBytes, dbRowsRead int64) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) RecordRecoveries(ctx context.Context, executionID string, recoveries models.JSONArray) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) RecordBudget(ctx context.Context, executionID string, budget models.JSONB) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) SaveNodeDebugData(ctx context.Context, executionID, nodeID string, debugData map[string]interface{}) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) SaveRecording(ctx context.Context, executionID string, recording models.JSONB) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) SaveDataFlow(ctx context.Context, executionID string, dataFlow models.JSONB) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) SaveVariableHistory(ctx context.Context, executionID string, history models.JSONB) error {
+	return nil
+}
+
+func (r *fakeExecutionRepo) SaveProfile(ctx context.Context, executionID string, profile models.JSONB) error {
+	return nil
+}
+
+// status returns the status of an execution record
+func (r *fakeExecutionRepo) status(id string) string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if execution, exists := r.executions[id]; exists {
+		return execution.Status
+	}
+	return ""
+}
+
+// fakeUserRepo serves users from memory
+type fakeUserRepo struct {
+	repository.UserRepository
+
+	users map[string]*models.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	user, exists := r.users[id]
+	if !exists {
+		return nil, fmt.Errorf("user %s does not exist", id)
+	}
+	return user, nil
+}
+
+// fakeWorkspaceRepo serves workspaces and their members from memory
+type fakeWorkspaceRepo struct {
+	repository.WorkspaceRepository
+
+	workspaces map[string]*models.Workspace
+	members    map[string][]*models.WorkspaceMember
+}
+
+func (r *fakeWorkspaceRepo) GetByID(ctx context.Context, id string) (*models.Workspace, error) {
+	workspace, exists := r.workspaces[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", repository.ErrWorkspaceNotFound, id)
+	}
+	return workspace, nil
+}
+
+func (r *fakeWorkspaceRepo) Update(ctx context.Context, workspace *models.Workspace) error {
+	r.workspaces[workspace.ID] = workspace
+	return nil
+}
+
+func (r *fakeWorkspaceRepo) GetMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	return r.members[workspaceID], nil
+}
+
+// newFakeAccessRepos returns a workspace owned by "owner" with an admin, an editor and a
+// viewer member, and an "admin" user administering the server
+func newFakeAccessRepos() (*fakeUserRepo, *fakeWorkspaceRepo) {
+	users := &fakeUserRepo{users: map[string]*models.User{
+		"admin":  {ID: "admin", Role: RoleAdmin, IsActive: true},
+		"owner":  {ID: "owner", Role: "user", IsActive: true},
+		"editor": {ID: "editor", Role: "user", IsActive: true},
+		"viewer": {ID: "viewer", Role: "user", IsActive: true},
+	}}
+	workspaces := &fakeWorkspaceRepo{
+		workspaces: map[string]*models.Workspace{
+			"workspace": {ID: "workspace", OwnerType: "user", OwnerID: "owner"},
+		},
+		members: map[string][]*models.WorkspaceMember{
+			"workspace": {
+				{WorkspaceID: "workspace", UserID: "manager", Role: MemberRoleAdmin},
+				{WorkspaceID: "workspace", UserID: "editor", Role: MemberRoleEditor},
+				{WorkspaceID: "workspace", UserID: "viewer", Role: "viewer"},
+			},
+		},
+	}
+	return users, workspaces
+}
+
+// fakeShareLinkRepo keeps share links and the executions started through them in memory
+type fakeShareLinkRepo struct {
+	mutex      sync.Mutex
+	links      map[string]*models.ShareLink
+	executions map[string]map[string]bool // Link ID -> executions started through it
+}
+
+func newFakeShareLinkRepo() *fakeShareLinkRepo {
+	return &fakeShareLinkRepo{
+		links:      make(map[string]*models.ShareLink),
+		executions: make(map[string]map[string]bool),
+	}
+}
+
+func (r *fakeShareLinkRepo) Create(ctx context.Context, link *models.ShareLink) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.links[link.ID] = link
+	return nil
+}
+
+func (r *fakeShareLinkRepo) GetByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.links[id], nil
+}
+
+func (r *fakeShareLinkRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.ShareLink, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, link := range r.links {
+		if link.TokenHash == tokenHash {
+			return link, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeShareLinkRepo) GetByBlueprint(ctx context.Context, blueprintID string) ([]*models.ShareLink, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	links := make([]*models.ShareLink, 0)
+	for _, link := range r.links {
+		if link.BlueprintID == blueprintID {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (r *fakeShareLinkRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if link, exists := r.links[id]; exists {
+		link.RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+func (r *fakeShareLinkRepo) AddExecution(ctx context.Context, linkID, executionID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.executions[linkID] == nil {
+		r.executions[linkID] = make(map[string]bool)
+	}
+	r.executions[linkID][executionID] = true
+	return nil
+}
+
+func (r *fakeShareLinkRepo) HasExecution(ctx context.Context, linkID, executionID string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.executions[linkID][executionID], nil
+}
+
+// fakeDeprecationRepo keeps the deprecations of blueprints and their versions in memory
+type fakeDeprecationRepo struct {
+	mutex        sync.Mutex
+	deprecations map[string]map[int]*models.BlueprintDeprecation // Blueprint ID -> version -> deprecation
+}
+
+func newFakeDeprecationRepo() *fakeDeprecationRepo {
+	return &fakeDeprecationRepo{deprecations: make(map[string]map[int]*models.BlueprintDeprecation)}
+}
+
+func (r *fakeDeprecationRepo) Set(ctx context.Context, deprecation *models.BlueprintDeprecation) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.deprecations[deprecation.BlueprintID] == nil {
+		r.deprecations[deprecation.BlueprintID] = make(map[int]*models.BlueprintDeprecation)
+	}
+	r.deprecations[deprecation.BlueprintID][deprecation.VersionNumber] = deprecation
+	return nil
+}
+
+func (r *fakeDeprecationRepo) Get(ctx context.Context, blueprintID string, versionNumber int) (*models.BlueprintDeprecation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.deprecations[blueprintID][versionNumber], nil
+}
+
+func (r *fakeDeprecationRepo) GetByBlueprint(ctx context.Context, blueprintID string) ([]*models.BlueprintDeprecation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	deprecations := make([]*models.BlueprintDeprecation, 0)
+	for _, deprecation := range r.deprecations[blueprintID] {
+		deprecations = append(deprecations, deprecation)
+	}
+	sort.Slice(deprecations, func(i, j int) bool {
+		return deprecations[i].VersionNumber < deprecations[j].VersionNumber
+	})
+	return deprecations, nil
+}
+
+func (r *fakeDeprecationRepo) Delete(ctx context.Context, blueprintID string, versionNumber int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.deprecations[blueprintID], versionNumber)
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/lint"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// lintRulesMetadataKey is the workspace metadata key lint rule sets are persisted under
+const lintRulesMetadataKey = "lintRules"
+
+// LintService lints blueprints against the lint rules configured for their workspace
+type LintService struct {
+	workspaceRepo repository.WorkspaceRepository
+	blueprintRepo repository.BlueprintRepository
+	linter        *lint.Linter
+	ruleSets      map[string]lint.RuleSet
+	mutex         sync.Mutex
+}
+
+// NewLintService creates a new lint service
+func NewLintService(workspaceRepo repository.WorkspaceRepository, blueprintRepo repository.BlueprintRepository) *LintService {
+	return &LintService{
+		workspaceRepo: workspaceRepo,
+		blueprintRepo: blueprintRepo,
+		linter:        lint.NewLinter(),
+		ruleSets:      make(map[string]lint.RuleSet),
+	}
+}
+
+// SetNodeFactoryLookup lets the rules inspect the pins of node types
+func (s *LintService) SetNodeFactoryLookup(lookup bperrors.NodeFactoryLookup) {
+	s.linter.SetNodeFactoryLookup(lookup)
+}
+
+// GetRuleSet returns the lint rules configured for a workspace. Rules it doesn't
+// configure run with their defaults.
+func (s *LintService) GetRuleSet(ctx context.Context, workspaceID string) (lint.RuleSet, error) {
+	s.mutex.Lock()
+	ruleSet, cached := s.ruleSets[workspaceID]
+	s.mutex.Unlock()
+	if cached {
+		return ruleSet, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found: %w", err)
+	}
+
+	ruleSet = make(lint.RuleSet)
+	if stored, exists := workspace.Metadata[lintRulesMetadataKey]; exists {
+		// Metadata is stored as JSON, so round-trip it into the rule set
+		data, err := json.Marshal(stored)
+		if err == nil {
+			_ = json.Unmarshal(data, &ruleSet)
+		}
+	}
+
+	s.mutex.Lock()
+	s.ruleSets[workspaceID] = ruleSet
+	s.mutex.Unlock()
+
+	return ruleSet, nil
+}
+
+// SetRuleSet replaces the lint rules configured for a workspace
+func (s *LintService) SetRuleSet(ctx context.Context, workspaceID string, ruleSet lint.RuleSet) error {
+	if err := ruleSet.Validate(); err != nil {
+		return err
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return fmt.Errorf("workspace not found: %w", err)
+	}
+
+	// Stored through JSON, so the cached rule set matches the one read back later
+	data, err := json.Marshal(ruleSet)
+	if err != nil {
+		return fmt.Errorf("invalid lint rules: %w", err)
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("invalid lint rules: %w", err)
+	}
+	cached := make(lint.RuleSet)
+	_ = json.Unmarshal(data, &cached)
+
+	if workspace.Metadata == nil {
+		workspace.Metadata = make(models.JSONB)
+	}
+	workspace.Metadata[lintRulesMetadataKey] = stored
+	workspace.UpdatedAt = time.Now()
+
+	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+		return fmt.Errorf("failed to update workspace lint rules: %w", err)
+	}
+
+	s.mutex.Lock()
+	s.ruleSets[workspaceID] = cached
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Lint checks a blueprint against the lint rules of a workspace
+func (s *LintService) Lint(ctx context.Context, workspaceID string, bp *blueprint.Blueprint) (lint.Report, error) {
+	ruleSet, err := s.GetRuleSet(ctx, workspaceID)
+	if err != nil {
+		return lint.Report{}, err
+	}
+
+	return s.linter.Lint(bp, ruleSet), nil
+}
+
+// LintStored checks a stored blueprint against the lint rules of its workspace
+func (s *LintService) LintStored(ctx context.Context, blueprintID string, bp *blueprint.Blueprint) (lint.Report, error) {
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return lint.Report{}, fmt.Errorf("error retrieving blueprint: %w", err)
+	}
+
+	return s.Lint(ctx, blueprintModel.WorkspaceID, bp)
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// nodePolicyMetadataKey is the workspace metadata key node policies are persisted under
+const nodePolicyMetadataKey = "nodePolicies"
+
+// optInPolicyName names the violations of opt-in node types no policy opts into
+const optInPolicyName = "opt-in"
+
+// optInNodeTypes reach outside the engine, so the blueprints of a workspace may only use
+// them if one of its opt-in policies lists them by name; patterns don't count
+var optInNodeTypes = map[string]bool{
+	"run-command": true,
+}
+
+// NodePolicyMode decides whether a policy lists the node types allowed, the ones denied or
+// the opt-in node types enabled
+type NodePolicyMode string
+
+const (
+	NodePolicyAllow NodePolicyMode = "allow"  // Only the listed node types may be used
+	NodePolicyDeny  NodePolicyMode = "deny"   // The listed node types may not be used
+	NodePolicyOptIn NodePolicyMode = "opt-in" // The listed opt-in node types may be used; others aren't restricted
+)
+
+// NodePolicy restricts the node types the blueprints of a workspace may use. Node types
+// are matched as patterns, so "variable-set-*" covers every defined variable setter.
+type NodePolicy struct {
+	Name      string         `json:"name"`
+	Mode      NodePolicyMode `json:"mode"`
+	NodeTypes []string       `json:"nodeTypes"`
+}
+
+// blocks checks if the policy blocks a node type
+func (p NodePolicy) blocks(nodeType string) bool {
+	if p.Mode == NodePolicyOptIn {
+		return false
+	}
+
+	listed := false
+	for _, pattern := range p.NodeTypes {
+		if matched, _ := path.Match(pattern, nodeType); matched {
+			listed = true
+			break
+		}
+	}
+
+	if p.Mode == NodePolicyAllow {
+		return !listed
+	}
+	return listed
+}
+
+// validate checks the policy before it's stored
+func (p NodePolicy) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("policy name is required")
+	}
+	if p.Mode != NodePolicyAllow && p.Mode != NodePolicyDeny && p.Mode != NodePolicyOptIn {
+		return fmt.Errorf("policy %q has invalid mode %q (expected %q, %q or %q)", p.Name, p.Mode, NodePolicyAllow, NodePolicyDeny, NodePolicyOptIn)
+	}
+	for _, pattern := range p.NodeTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("policy %q has invalid node type pattern %q", p.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// NodePolicyViolation identifies a node blocked by a policy
+type NodePolicyViolation struct {
+	NodeID   string `json:"nodeId"`
+	NodeType string `json:"nodeType"`
+	Policy   string `json:"policy"`
+}
+
+// NodePolicyService enforces the node policies of workspaces on their blueprints
+type NodePolicyService struct {
+	workspaceRepo repository.WorkspaceRepository
+	policies      map[string][]NodePolicy
+	mutex         sync.Mutex
+}
+
+// NewNodePolicyService creates a new node policy service
+func NewNodePolicyService(workspaceRepo repository.WorkspaceRepository) *NodePolicyService {
+	return &NodePolicyService{
+		workspaceRepo: workspaceRepo,
+		policies:      make(map[string][]NodePolicy),
+	}
+}
+
+// GetPolicies returns the node policies of a workspace
+func (s *NodePolicyService) GetPolicies(ctx context.Context, workspaceID string) ([]NodePolicy, error) {
+	s.mutex.Lock()
+	policies, cached := s.policies[workspaceID]
+	s.mutex.Unlock()
+	if cached {
+		return policies, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found: %w", err)
+	}
+
+	policies = make([]NodePolicy, 0)
+	if stored, exists := workspace.Metadata[nodePolicyMetadataKey]; exists {
+		// Metadata is stored as JSON, so round-trip it into the policies
+		data, err := json.Marshal(stored)
+		if err == nil {
+			_ = json.Unmarshal(data, &policies)
+		}
+	}
+
+	s.mutex.Lock()
+	s.policies[workspaceID] = policies
+	s.mutex.Unlock()
+
+	return policies, nil
+}
+
+// SetPolicies replaces the node policies of a workspace
+func (s *NodePolicyService) SetPolicies(ctx context.Context, workspaceID string, policies []NodePolicy) error {
+	names := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("policy name %q is used more than once", policy.Name)
+		}
+		names[policy.Name] = true
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return fmt.Errorf("workspace not found: %w", err)
+	}
+
+	stored := make([]interface{}, 0, len(policies))
+	for _, policy := range policies {
+		stored = append(stored, map[string]interface{}{
+			"name":      policy.Name,
+			"mode":      policy.Mode,
+			"nodeTypes": policy.NodeTypes,
+		})
+	}
+
+	if workspace.Metadata == nil {
+		workspace.Metadata = make(models.JSONB)
+	}
+	workspace.Metadata[nodePolicyMetadataKey] = stored
+	workspace.UpdatedAt = time.Now()
+
+	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+		return fmt.Errorf("failed to update workspace node policies: %w", err)
+	}
+
+	s.mutex.Lock()
+	s.policies[workspaceID] = policies
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Violations returns the nodes of a blueprint blocked by the policies of a workspace
+func (s *NodePolicyService) Violations(ctx context.Context, workspaceID string, bp *blueprint.Blueprint) ([]NodePolicyViolation, error) {
+	policies, err := s.GetPolicies(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return policyViolations(policies, bp), nil
+}
+
+// policyViolations returns the nodes of a blueprint blocked by policies
+func policyViolations(policies []NodePolicy, bp *blueprint.Blueprint) []NodePolicyViolation {
+	violations := make([]NodePolicyViolation, 0)
+	for _, node := range bp.Nodes {
+		if optInNodeTypes[node.Type] && !optedIn(policies, node.Type) {
+			violations = append(violations, NodePolicyViolation{
+				NodeID:   node.ID,
+				NodeType: node.Type,
+				Policy:   optInPolicyName,
+			})
+			continue
+		}
+		for _, policy := range policies {
+			if policy.blocks(node.Type) {
+				violations = append(violations, NodePolicyViolation{
+					NodeID:   node.ID,
+					NodeType: node.Type,
+					Policy:   policy.Name,
+				})
+				break
+			}
+		}
+	}
+
+	return violations
+}
+
+// optedIn checks if an opt-in policy lists a node type by name
+func optedIn(policies []NodePolicy, nodeType string) bool {
+	for _, policy := range policies {
+		if policy.Mode != NodePolicyOptIn {
+			continue
+		}
+		for _, listed := range policy.NodeTypes {
+			if listed == nodeType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check fails with an ErrNodeTypeBlocked error naming the first blocked node type and
+// its policy if the policies of a workspace block any node of a blueprint
+func (s *NodePolicyService) Check(ctx context.Context, workspaceID string, bp *blueprint.Blueprint) error {
+	violations, err := s.Violations(ctx, workspaceID, bp)
+	if err != nil {
+		return err
+	}
+	return violationError(workspaceID, bp, violations)
+}
+
+// checkRestrictions fails like Check if policies that restrict a blueprint beyond its
+// workspace, such as those of a share link, block any of its nodes. As opt-in node types
+// must be opted into by these policies too, they can't loosen the workspace policies.
+func checkRestrictions(workspaceID string, bp *blueprint.Blueprint, restrictions []NodePolicy) error {
+	return violationError(workspaceID, bp, policyViolations(restrictions, bp))
+}
+
+// violationError returns an ErrNodeTypeBlocked error naming the first violation, or nil if
+// there are none
+func violationError(workspaceID string, bp *blueprint.Blueprint, violations []NodePolicyViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	first := violations[0]
+	message := fmt.Sprintf("node type %q is blocked by policy %q", first.NodeType, first.Policy)
+	if len(violations) > 1 {
+		message = fmt.Sprintf("%s (and %d more blocked nodes)", message, len(violations)-1)
+	}
+
+	return bperrors.New(
+		bperrors.ErrorTypePermission,
+		bperrors.ErrNodeTypeBlocked,
+		message,
+		bperrors.SeverityHigh,
+	).WithNodeInfo(first.NodeID, "").WithBlueprintInfo(bp.ID, "").WithDetails(map[string]interface{}{
+		"workspaceId": workspaceID,
+		"nodeType":    first.NodeType,
+		"policy":      first.Policy,
+		"violations":  violations,
+	})
+}
+
+// IsNodePolicyError checks if an error is a node policy violation
+func IsNodePolicyError(err error) (*bperrors.BlueprintError, bool) {
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Code != bperrors.ErrNodeTypeBlocked {
+		return nil, false
+	}
+	return bpErr, true
+}
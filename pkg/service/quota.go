@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/security"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+)
+
+// quotaMetadataKey is the workspace metadata key quotas are persisted under
+const quotaMetadataKey = "quotas"
+
+// WorkspaceQuota defines the execution limits of a workspace; zero means unlimited
+type WorkspaceQuota struct {
+	ExecutionsPerMinute         int `json:"executionsPerMinute"`
+	MaxConcurrentExecutions     int `json:"maxConcurrentExecutions"`
+	MaxNodesPerBlueprint        int `json:"maxNodesPerBlueprint"`
+	MaxExecutionDurationSeconds int `json:"maxExecutionDurationSeconds"`
+}
+
+// DefaultWorkspaceQuota returns the quota applied to workspaces without one
+func DefaultWorkspaceQuota() WorkspaceQuota {
+	return WorkspaceQuota{
+		ExecutionsPerMinute:         60,
+		MaxConcurrentExecutions:     10,
+		MaxNodesPerBlueprint:        500,
+		MaxExecutionDurationSeconds: 300,
+	}
+}
+
+// ExecutionLimits converts the quota to engine execution limits
+func (q WorkspaceQuota) ExecutionLimits() engine.ExecutionLimits {
+	return engine.ExecutionLimits{
+		MaxNodes:    q.MaxNodesPerBlueprint,
+		MaxDuration: time.Duration(q.MaxExecutionDurationSeconds) * time.Second,
+	}
+}
+
+// QuotaService enforces per-workspace rate limits and quotas
+type QuotaService struct {
+	workspaceRepo repository.WorkspaceRepository
+	quotas        map[string]WorkspaceQuota
+	buckets       map[string]*security.TokenBucket
+	running       map[string]int
+	mutex         sync.Mutex
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(workspaceRepo repository.WorkspaceRepository) *QuotaService {
+	return &QuotaService{
+		workspaceRepo: workspaceRepo,
+		quotas:        make(map[string]WorkspaceQuota),
+		buckets:       make(map[string]*security.TokenBucket),
+		running:       make(map[string]int),
+	}
+}
+
+// GetQuota returns the quota of a workspace, falling back to the default quota
+func (s *QuotaService) GetQuota(ctx context.Context, workspaceID string) (WorkspaceQuota, error) {
+	s.mutex.Lock()
+	quota, cached := s.quotas[workspaceID]
+	s.mutex.Unlock()
+	if cached {
+		return quota, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return WorkspaceQuota{}, fmt.Errorf("workspace not found: %w", err)
+	}
+
+	quota = DefaultWorkspaceQuota()
+	if stored, exists := workspace.Metadata[quotaMetadataKey]; exists {
+		// Metadata is stored as JSON, so round-trip it into the quota struct
+		data, err := json.Marshal(stored)
+		if err == nil {
+			_ = json.Unmarshal(data, &quota)
+		}
+	}
+
+	s.mutex.Lock()
+	s.quotas[workspaceID] = quota
+	s.mutex.Unlock()
+
+	return quota, nil
+}
+
+// SetQuota stores a new quota for a workspace and resets its rate limit bucket
+func (s *QuotaService) SetQuota(ctx context.Context, workspaceID string, quota WorkspaceQuota) error {
+	if quota.ExecutionsPerMinute < 0 || quota.MaxConcurrentExecutions < 0 ||
+		quota.MaxNodesPerBlueprint < 0 || quota.MaxExecutionDurationSeconds < 0 {
+		return fmt.Errorf("quota values must not be negative")
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return fmt.Errorf("workspace not found: %w", err)
+	}
+
+	if workspace.Metadata == nil {
+		workspace.Metadata = make(models.JSONB)
+	}
+	workspace.Metadata[quotaMetadataKey] = map[string]interface{}{
+		"executionsPerMinute":         quota.ExecutionsPerMinute,
+		"maxConcurrentExecutions":     quota.MaxConcurrentExecutions,
+		"maxNodesPerBlueprint":        quota.MaxNodesPerBlueprint,
+		"maxExecutionDurationSeconds": quota.MaxExecutionDurationSeconds,
+	}
+	workspace.UpdatedAt = time.Now()
+
+	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+		return fmt.Errorf("failed to update workspace quota: %w", err)
+	}
+
+	s.mutex.Lock()
+	s.quotas[workspaceID] = quota
+	delete(s.buckets, workspaceID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Acquire reserves an execution slot for the workspace. It checks the blueprint size,
+// the execution rate and the number of concurrent executions. The returned release
+// function must be called once the execution has finished.
+func (s *QuotaService) Acquire(ctx context.Context, workspaceID, blueprintID string, nodeCount int) (func(), error) {
+	quota, err := s.GetQuota(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quota.MaxNodesPerBlueprint > 0 && nodeCount > quota.MaxNodesPerBlueprint {
+		return nil, bperrors.New(
+			bperrors.ErrorTypeQuota,
+			bperrors.ErrBlueprintTooLarge,
+			fmt.Sprintf("blueprint has %d nodes, exceeding the workspace limit of %d", nodeCount, quota.MaxNodesPerBlueprint),
+			bperrors.SeverityHigh,
+		).WithBlueprintInfo(blueprintID, "").WithDetails(map[string]interface{}{
+			"workspaceId": workspaceID,
+			"nodeCount":   nodeCount,
+			"limit":       quota.MaxNodesPerBlueprint,
+		})
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if quota.MaxConcurrentExecutions > 0 && s.running[workspaceID] >= quota.MaxConcurrentExecutions {
+		return nil, bperrors.New(
+			bperrors.ErrorTypeQuota,
+			bperrors.ErrConcurrencyLimitExceeded,
+			fmt.Sprintf("workspace already has %d running executions (limit: %d)", s.running[workspaceID], quota.MaxConcurrentExecutions),
+			bperrors.SeverityMedium,
+		).WithBlueprintInfo(blueprintID, "").WithDetails(map[string]interface{}{
+			"workspaceId": workspaceID,
+			"limit":       quota.MaxConcurrentExecutions,
+		}).WithRecoveryOptions(bperrors.RecoveryRetry)
+	}
+
+	if quota.ExecutionsPerMinute > 0 {
+		bucket, exists := s.buckets[workspaceID]
+		if !exists {
+			bucket = security.NewTokenBucket(quota.ExecutionsPerMinute, time.Minute)
+			s.buckets[workspaceID] = bucket
+		}
+
+		if !bucket.TakeToken() {
+			retryAfter := time.Duration(float64(time.Minute) / float64(quota.ExecutionsPerMinute))
+			return nil, bperrors.New(
+				bperrors.ErrorTypeQuota,
+				bperrors.ErrRateLimitExceeded,
+				fmt.Sprintf("workspace exceeded %d executions per minute", quota.ExecutionsPerMinute),
+				bperrors.SeverityMedium,
+			).WithBlueprintInfo(blueprintID, "").WithDetails(map[string]interface{}{
+				"workspaceId":       workspaceID,
+				"limit":             quota.ExecutionsPerMinute,
+				"retryAfterSeconds": int(retryAfter.Seconds()) + 1,
+			}).WithRecoveryOptions(bperrors.RecoveryRetry)
+		}
+	}
+
+	s.running[workspaceID]++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			if s.running[workspaceID] > 0 {
+				s.running[workspaceID]--
+			}
+		})
+	}
+
+	return release, nil
+}
+
+// GetUsage returns the current usage of a workspace against its quota
+func (s *QuotaService) GetUsage(ctx context.Context, workspaceID string) (map[string]interface{}, error) {
+	quota, err := s.GetQuota(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	usage := map[string]interface{}{
+		"quota":             quota,
+		"runningExecutions": s.running[workspaceID],
+	}
+	if bucket, exists := s.buckets[workspaceID]; exists {
+		usage["remainingExecutions"] = int(bucket.AvailableTokens())
+	}
+
+	return usage, nil
+}
+
+// IsQuotaError checks if an error is a quota or rate limit error
+func IsQuotaError(err error) (*bperrors.BlueprintError, bool) {
+	var bpErr *bperrors.BlueprintError
+	if !errors.As(err, &bpErr) || bpErr.Type != bperrors.ErrorTypeQuota {
+		return nil, false
+	}
+	return bpErr, true
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"webblueprint/internal/node"
+	"webblueprint/internal/nodes/utility"
+	"webblueprint/internal/registry"
+)
+
+// blockingNode waits for its channel to close before running the node it wraps
+type blockingNode struct {
+	node.Node
+	unblock <-chan struct{}
+}
+
+func (n blockingNode) Execute(ctx node.ExecutionContext) error {
+	<-n.unblock
+	return n.Node.Execute(ctx)
+}
+
+func TestQuotaSlotIsHeldUntilTimedOutExecutionEnds(t *testing.T) {
+	flowEngine := newTestEngine(t)
+	unblock := make(chan struct{})
+	registry.GetInstance().RegisterNodeType("quota-blocking-print", func() node.Node {
+		return blockingNode{utility.NewPrintNode(), unblock}
+	})
+	bp := printBlueprint("slow")
+	bp.Nodes[1].Type = "quota-blocking-print"
+
+	blueprints := newFakeBlueprintRepo()
+	blueprints.add("workspace", "owner", bp)
+	executions := newFakeExecutionRepo()
+	s := NewExecutionService(executions, blueprints, flowEngine)
+	quotas := NewQuotaService(nil)
+	quotas.quotas["workspace"] = WorkspaceQuota{MaxConcurrentExecutions: 1, MaxExecutionDurationSeconds: 1}
+	s.SetQuotaService(quotas)
+
+	executionID, err := s.StartExecution(context.Background(), "slow", nil, "user")
+	if err != nil {
+		t.Fatalf("failed to start execution: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for executions.status(executionID) != "failed" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := executions.status(executionID); status != "failed" {
+		t.Fatalf("expected the execution to fail on its duration limit, got %q", status)
+	}
+
+	// The node is still running, so the execution keeps its slot
+	if _, err := quotas.Acquire(context.Background(), "workspace", "slow", len(bp.Nodes)); err == nil {
+		t.Fatal("expected the slot to be held while the timed out execution still runs")
+	} else if _, isQuota := IsQuotaError(err); !isQuota {
+		t.Fatalf("expected a quota error, got %v", err)
+	}
+
+	close(unblock)
+	for time.Now().Before(deadline) {
+		if release, err := quotas.Acquire(context.Background(), "workspace", "slow", len(bp.Nodes)); err == nil {
+			release()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected the slot to be released once the execution ended")
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"webblueprint/internal/engine"
+	"webblueprint/internal/types"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Service executions run a blueprint that is meant to run indefinitely, e.g. one consuming
+// events or polling an API, until they're stopped. They aren't bound by the duration limit
+// of the workspace quota or the actor timeout, and an execution that fails is restarted
+// after a delay that grows with the consecutive failures. A blueprint runs as at most one
+// service at a time; the services are kept in memory and end with the server.
+
+const (
+	// DefaultServiceMaxRestarts is how often a service is restarted after consecutive failures
+	// before it gives up
+	DefaultServiceMaxRestarts = 5
+	// DefaultServiceRestartDelay is the delay before the first restart of a service
+	DefaultServiceRestartDelay = time.Second
+	// DefaultServiceMaxRestartDelay caps the delay between restarts of a service
+	DefaultServiceMaxRestartDelay = time.Minute
+)
+
+// Service statuses
+const (
+	ServiceStatusRunning    = "running"
+	ServiceStatusRestarting = "restarting" // Waiting to restart after a failure
+	ServiceStatusStopped    = "stopped"
+	ServiceStatusCompleted  = "completed" // The execution ended without an error
+	ServiceStatusFailed     = "failed"    // Gave up after too many consecutive failures
+)
+
+var (
+	ErrServiceRunning  = errors.New("blueprint already runs as a service")
+	ErrServiceNotFound = errors.New("blueprint doesn't run as a service")
+)
+
+// ServiceOptions configures the restarts of a service
+type ServiceOptions struct {
+	MaxRestarts     int           // Restarts after consecutive failures, the default if 0 and unlimited if negative
+	RestartDelay    time.Duration // Delay before the first restart, doubled with every consecutive failure
+	MaxRestartDelay time.Duration // Cap of the delay; an execution running this long resets the failures
+}
+
+// withDefaults fills in the options left out
+func (o ServiceOptions) withDefaults() ServiceOptions {
+	if o.MaxRestarts == 0 {
+		o.MaxRestarts = DefaultServiceMaxRestarts
+	}
+	if o.RestartDelay <= 0 {
+		o.RestartDelay = DefaultServiceRestartDelay
+	}
+	if o.MaxRestartDelay <= 0 {
+		o.MaxRestartDelay = DefaultServiceMaxRestartDelay
+	}
+	if o.MaxRestartDelay < o.RestartDelay {
+		o.MaxRestartDelay = o.RestartDelay
+	}
+	return o
+}
+
+// restartDelay returns the delay before restarting after the given consecutive failures
+func (o ServiceOptions) restartDelay(failures int) time.Duration {
+	delay := o.RestartDelay
+	for i := 1; i < failures && delay < o.MaxRestartDelay; i++ {
+		delay *= 2
+	}
+	if delay > o.MaxRestartDelay {
+		delay = o.MaxRestartDelay
+	}
+	return delay
+}
+
+// ServiceExecution is the status of a blueprint running as a service
+type ServiceExecution struct {
+	BlueprintID         string     `json:"blueprintId"`
+	Status              string     `json:"status"`
+	ExecutionID         string     `json:"executionId,omitempty"` // Current execution, or the last one once the service ended
+	InitiatedBy         string     `json:"initiatedBy"`
+	StartedAt           time.Time  `json:"startedAt"`
+	RunStartedAt        *time.Time `json:"runStartedAt,omitempty"` // Start of the current execution
+	StoppedAt           *time.Time `json:"stoppedAt,omitempty"`
+	Restarts            int        `json:"restarts"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	MaxRestarts         int        `json:"maxRestarts"` // Negative if unlimited
+	LastError           string     `json:"lastError,omitempty"`
+	LastFailureAt       *time.Time `json:"lastFailureAt,omitempty"`
+	NextRestartAt       *time.Time `json:"nextRestartAt,omitempty"`
+
+	cancel context.CancelFunc
+	done   chan struct{} // Closed once the service ended
+	mutex  sync.RWMutex
+}
+
+// snapshot returns a copy of the status that is safe to serialize
+func (e *ServiceExecution) snapshot() *ServiceExecution {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	return &ServiceExecution{
+		BlueprintID:         e.BlueprintID,
+		Status:              e.Status,
+		ExecutionID:         e.ExecutionID,
+		InitiatedBy:         e.InitiatedBy,
+		StartedAt:           e.StartedAt,
+		RunStartedAt:        e.RunStartedAt,
+		StoppedAt:           e.StoppedAt,
+		Restarts:            e.Restarts,
+		ConsecutiveFailures: e.ConsecutiveFailures,
+		MaxRestarts:         e.MaxRestarts,
+		LastError:           e.LastError,
+		LastFailureAt:       e.LastFailureAt,
+		NextRestartAt:       e.NextRestartAt,
+	}
+}
+
+// ended reports whether the service ended
+func (e *ServiceExecution) ended() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// finish records the end of the service
+func (e *ServiceExecution) finish(status string) {
+	stoppedAt := time.Now()
+	e.mutex.Lock()
+	e.Status = status
+	e.StoppedAt = &stoppedAt
+	e.RunStartedAt = nil
+	e.NextRestartAt = nil
+	e.mutex.Unlock()
+}
+
+// serviceRun is what every execution of a service runs
+type serviceRun struct {
+	blueprintModel   *models.Blueprint
+	bp               *blueprint.Blueprint
+	initialVariables map[string]interface{}
+	variables        map[string]types.Value
+	limits           engine.ExecutionLimits
+	userID           string
+	deprecation      *models.BlueprintDeprecation // Deprecation found when the service started
+	overrideSunset   bool                         // Restarts run past the sunset too
+}
+
+// StartService runs the current version of a blueprint as a service until it's stopped
+func (s *ExecutionService) StartService(
+	ctx context.Context,
+	blueprintID string,
+	initialVariables map[string]interface{},
+	userID string,
+	options ServiceOptions,
+) (*ServiceExecution, error) {
+	options = options.withDefaults()
+
+	s.serviceMutex.Lock()
+	defer s.serviceMutex.Unlock()
+	if running, exists := s.services[blueprintID]; exists && !running.ended() {
+		return nil, ErrServiceRunning
+	}
+
+	blueprintModel, err := s.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("blueprint not found: %w", err)
+	}
+	bp, err := s.blueprintRepo.ToPkgBlueprint(blueprintModel, blueprintModel.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blueprint: %w", err)
+	}
+	variables, err := toEngineVariables(initialVariables)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNodePolicies(ctx, blueprintModel, bp); err != nil {
+		return nil, err
+	}
+	if err := s.executionEngine.CheckConcurrency(bp); err != nil {
+		return nil, err
+	}
+	if err := s.executionEngine.CheckGuards(bp, variables); err != nil {
+		return nil, err
+	}
+	deprecation, err := s.checkDeprecation(ctx, blueprintModel, versionNumberOf(blueprintModel.CurrentVersion), TriggerService)
+	if err != nil {
+		return nil, err
+	}
+
+	// The service holds its workspace execution slot until it ends, and isn't bound by the
+	// duration limit of the quota
+	release, limits, err := s.acquireQuota(ctx, blueprintModel, bp)
+	if err != nil {
+		return nil, err
+	}
+	limits.MaxDuration = 0
+
+	serviceCtx, cancel := context.WithCancel(context.Background())
+	service := &ServiceExecution{
+		BlueprintID: blueprintID,
+		Status:      ServiceStatusRunning,
+		InitiatedBy: userID,
+		StartedAt:   time.Now(),
+		MaxRestarts: options.MaxRestarts,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	s.services[blueprintID] = service
+
+	go func() {
+		defer close(service.done)
+		defer release()
+		defer cancel()
+		s.superviseService(serviceCtx, service, serviceRun{
+			blueprintModel:   blueprintModel,
+			bp:               bp,
+			initialVariables: initialVariables,
+			variables:        variables,
+			limits:           limits,
+			userID:           userID,
+			deprecation:      deprecation,
+			overrideSunset:   sunsetOverridden(ctx),
+		}, options)
+	}()
+
+	return service.snapshot(), nil
+}
+
+// superviseService runs the executions of a service until it's stopped, one of them ends
+// without an error, or it failed more often in a row than it may be restarted
+func (s *ExecutionService) superviseService(ctx context.Context, service *ServiceExecution, run serviceRun, options ServiceOptions) {
+	// Get a background context since the request context will be canceled
+	bgCtx := context.Background()
+	checkCtx := bgCtx
+	if run.overrideSunset {
+		checkCtx = WithSunsetOverride(bgCtx)
+	}
+
+	deprecation := run.deprecation
+	for started := false; ; started = true {
+		// Restarts are new executions, which the sunset of the blueprint may block by now
+		if started {
+			var err error
+			deprecation, err = s.checkDeprecation(checkCtx, run.blueprintModel, versionNumberOf(run.blueprintModel.CurrentVersion), TriggerService)
+			if err != nil {
+				service.mutex.Lock()
+				service.LastError = err.Error()
+				service.mutex.Unlock()
+				service.finish(ServiceStatusFailed)
+				return
+			}
+		}
+
+		executionID := uuid.New().String()
+		runStartedAt := time.Now()
+		service.mutex.Lock()
+		service.Status = ServiceStatusRunning
+		service.ExecutionID = executionID
+		service.RunStartedAt = &runStartedAt
+		service.NextRestartAt = nil
+		service.mutex.Unlock()
+
+		err := s.createExecutionRecord(bgCtx, executionID, run.blueprintModel, run.blueprintModel.CurrentVersionID, run.initialVariables, run.userID, "service")
+		if err == nil {
+			s.warnDeprecated(bgCtx, executionID, deprecation, TriggerService)
+			s.executionEngine.MarkLongRunning(executionID)
+			err = s.runExecutionContext(ctx, run.bp, executionID, run.variables, engine.PriorityNormal, run.limits)
+			if ctx.Err() != nil {
+				s.executionRepo.UpdateStatus(bgCtx, executionID, "cancelled")
+			}
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			service.finish(ServiceStatusStopped)
+			return
+		case err == nil:
+			service.finish(ServiceStatusCompleted)
+			return
+		}
+
+		// An execution that stayed up for the longest delay starts counting failures afresh
+		failedAt := time.Now()
+		service.mutex.Lock()
+		if failedAt.Sub(runStartedAt) >= options.MaxRestartDelay {
+			service.ConsecutiveFailures = 0
+		}
+		service.ConsecutiveFailures++
+		failures := service.ConsecutiveFailures
+		service.LastError = err.Error()
+		service.LastFailureAt = &failedAt
+		service.mutex.Unlock()
+
+		if options.MaxRestarts >= 0 && failures > options.MaxRestarts {
+			service.finish(ServiceStatusFailed)
+			return
+		}
+
+		restartAt := failedAt.Add(options.restartDelay(failures))
+		service.mutex.Lock()
+		service.Status = ServiceStatusRestarting
+		service.Restarts++
+		service.RunStartedAt = nil
+		service.NextRestartAt = &restartAt
+		service.mutex.Unlock()
+
+		timer := time.NewTimer(time.Until(restartAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			service.finish(ServiceStatusStopped)
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// StopService stops a blueprint running as a service. It returns the status of the service
+// once its execution ended, or the current status if the context is done first.
+func (s *ExecutionService) StopService(ctx context.Context, blueprintID string) (*ServiceExecution, error) {
+	s.serviceMutex.Lock()
+	service, exists := s.services[blueprintID]
+	s.serviceMutex.Unlock()
+	if !exists || service.ended() {
+		return nil, ErrServiceNotFound
+	}
+
+	service.cancel()
+	select {
+	case <-service.done:
+	case <-ctx.Done():
+	}
+	return service.snapshot(), nil
+}
+
+// GetService returns the status of the service of a blueprint, including one that ended
+func (s *ExecutionService) GetService(blueprintID string) (*ServiceExecution, error) {
+	s.serviceMutex.Lock()
+	service, exists := s.services[blueprintID]
+	s.serviceMutex.Unlock()
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+	return service.snapshot(), nil
+}
+
+// ListServices returns the status of the services started on this server, by blueprint ID
+func (s *ExecutionService) ListServices() []*ServiceExecution {
+	s.serviceMutex.Lock()
+	services := make([]*ServiceExecution, 0, len(s.services))
+	for _, service := range s.services {
+		services = append(services, service)
+	}
+	s.serviceMutex.Unlock()
+
+	snapshots := make([]*ServiceExecution, len(services))
+	for i, service := range services {
+		snapshots[i] = service.snapshot()
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].BlueprintID < snapshots[j].BlueprintID
+	})
+	return snapshots
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/security"
+	"webblueprint/pkg/blueprint"
+	"webblueprint/pkg/models"
+	"webblueprint/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// Permissions of share links
+const (
+	SharePermissionView = "view" // The blueprint can be viewed read-only
+	SharePermissionRun  = "run"  // The blueprint can be viewed and executed
+)
+
+// Runs per minute of share links that don't set their own, and the most they may set
+const (
+	defaultShareRunsPerMinute = 10
+	maxShareRunsPerMinute     = 600
+)
+
+// sharePolicyName names the violations of the denied node types of share links
+const sharePolicyName = "share-link"
+
+// shareBucketSweepInterval is how often the rate limits of expired and idle links are dropped
+const shareBucketSweepInterval = time.Minute
+
+// Errors of share link tokens
+var (
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrShareLinkInactive = errors.New("share link has expired or was revoked")
+	ErrShareLinkViewOnly = errors.New("share link doesn't allow running the blueprint")
+)
+
+// ShareLinkOptions configures a new share link
+type ShareLinkOptions struct {
+	Permission      string     `json:"permission"`
+	RunsPerMinute   int        `json:"runsPerMinute"`   // Defaults to 10 for run links
+	DeniedNodeTypes []string   `json:"deniedNodeTypes"` // Node type patterns runs of the link may not use
+	ExpiresAt       *time.Time `json:"expiresAt"`       // The link never expires if nil
+}
+
+// ShareLinkService manages the public links to blueprints and what their tokens may do.
+// Runs of a link are initiated by its creator and count towards the quota of the workspace.
+type ShareLinkService struct {
+	shareLinkRepo    repository.ShareLinkRepository
+	blueprintService *BlueprintService
+	executionService *ExecutionService
+	accessService    *AccessService
+	buckets          map[string]*shareBucket // Link ID -> rate limit of its runs
+	sweptAt          time.Time               // When the buckets were last swept
+	mutex            sync.Mutex
+}
+
+// shareBucket is the rate limit of the runs of a share link
+type shareBucket struct {
+	bucket        *security.TokenBucket
+	runsPerMinute int
+	expiresAt     *time.Time
+}
+
+// NewShareLinkService creates a new share link service
+func NewShareLinkService(
+	shareLinkRepo repository.ShareLinkRepository,
+	blueprintService *BlueprintService,
+	executionService *ExecutionService,
+	accessService *AccessService,
+) *ShareLinkService {
+	return &ShareLinkService{
+		shareLinkRepo:    shareLinkRepo,
+		blueprintService: blueprintService,
+		executionService: executionService,
+		accessService:    accessService,
+		buckets:          make(map[string]*shareBucket),
+		sweptAt:          time.Now(),
+	}
+}
+
+// requireEditor fails unless the user created the blueprint or may edit the assets of its
+// workspace, which is what managing its share links takes
+func (s *ShareLinkService) requireEditor(ctx context.Context, blueprintID, userID string) error {
+	blueprintModel, err := s.blueprintService.blueprintRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return fmt.Errorf("error retrieving blueprint: %w", err)
+	}
+	if userID != "" && blueprintModel.CreatedBy == userID {
+		return nil
+	}
+	return s.accessService.RequireWorkspaceEditor(ctx, blueprintModel.WorkspaceID, userID)
+}
+
+// CreateLink creates a share link for a blueprint the user may edit. The token is only
+// returned here; the link stores its hash.
+func (s *ShareLinkService) CreateLink(ctx context.Context, blueprintID, userID string, options ShareLinkOptions) (*models.ShareLink, string, error) {
+	if options.Permission != SharePermissionView && options.Permission != SharePermissionRun {
+		return nil, "", fmt.Errorf("invalid permission %q (expected %q or %q)", options.Permission, SharePermissionView, SharePermissionRun)
+	}
+	if options.RunsPerMinute < 0 || options.RunsPerMinute > maxShareRunsPerMinute {
+		return nil, "", fmt.Errorf("runsPerMinute must be between 0 and %d", maxShareRunsPerMinute)
+	}
+	if options.RunsPerMinute == 0 && options.Permission == SharePermissionRun {
+		options.RunsPerMinute = defaultShareRunsPerMinute
+	}
+	for _, pattern := range options.DeniedNodeTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, "", fmt.Errorf("invalid node type pattern %q", pattern)
+		}
+	}
+	now := time.Now().UTC()
+	if options.ExpiresAt != nil && !options.ExpiresAt.After(now) {
+		return nil, "", fmt.Errorf("expiresAt must be in the future")
+	}
+
+	if err := s.requireEditor(ctx, blueprintID, userID); err != nil {
+		return nil, "", err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	link := &models.ShareLink{
+		ID:              uuid.New().String(),
+		BlueprintID:     blueprintID,
+		TokenHash:       hashShareToken(token),
+		Permission:      options.Permission,
+		RunsPerMinute:   options.RunsPerMinute,
+		DeniedNodeTypes: options.DeniedNodeTypes,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		ExpiresAt:       options.ExpiresAt,
+	}
+	if link.DeniedNodeTypes == nil {
+		link.DeniedNodeTypes = []string{}
+	}
+	if err := s.shareLinkRepo.Create(ctx, link); err != nil {
+		return nil, "", err
+	}
+
+	return link, token, nil
+}
+
+// GetLinks returns the share links of a blueprint the user may edit, newest first,
+// including revoked ones
+func (s *ShareLinkService) GetLinks(ctx context.Context, blueprintID, userID string) ([]*models.ShareLink, error) {
+	if err := s.requireEditor(ctx, blueprintID, userID); err != nil {
+		return nil, err
+	}
+	return s.shareLinkRepo.GetByBlueprint(ctx, blueprintID)
+}
+
+// RevokeLink revokes a share link of a blueprint the user may edit; its token stops working
+// right away
+func (s *ShareLinkService) RevokeLink(ctx context.Context, blueprintID, linkID, userID string) error {
+	if err := s.requireEditor(ctx, blueprintID, userID); err != nil {
+		return err
+	}
+
+	link, err := s.shareLinkRepo.GetByID(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if link == nil || link.BlueprintID != blueprintID {
+		return ErrShareLinkNotFound
+	}
+
+	if err := s.shareLinkRepo.Revoke(ctx, linkID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.buckets, linkID)
+	s.mutex.Unlock()
+	return nil
+}
+
+// resolve returns the active share link of a token
+func (s *ShareLinkService) resolve(ctx context.Context, token string) (*models.ShareLink, error) {
+	if token == "" {
+		return nil, ErrShareLinkNotFound
+	}
+
+	link, err := s.shareLinkRepo.GetByTokenHash(ctx, hashShareToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	if link.RevokedAt != nil || (link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now())) {
+		return nil, ErrShareLinkInactive
+	}
+	return link, nil
+}
+
+// GetSharedBlueprint returns the current version of the blueprint of a token, along with
+// its link
+func (s *ShareLinkService) GetSharedBlueprint(ctx context.Context, token string) (*models.ShareLink, *blueprint.Blueprint, error) {
+	link, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bp, err := s.blueprintService.GetBlueprint(ctx, link.BlueprintID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return link, bp, nil
+}
+
+// RunShared starts an execution of the blueprint of a token. It fails with an
+// ErrRateLimitExceeded quota error once the link used up its runs of the minute, and with an
+// ErrNodeTypeBlocked error if the blueprint uses a node type the link denies or an opt-in
+// node type.
+func (s *ShareLinkService) RunShared(ctx context.Context, token string, variables map[string]interface{}) (string, error) {
+	link, err := s.resolve(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if link.Permission != SharePermissionRun {
+		return "", ErrShareLinkViewOnly
+	}
+
+	if err := s.takeRun(link); err != nil {
+		return "", err
+	}
+
+	restrictions := []NodePolicy{{
+		Name:      sharePolicyName,
+		Mode:      NodePolicyDeny,
+		NodeTypes: link.DeniedNodeTypes,
+	}}
+	executionID, err := s.executionService.StartSharedExecution(ctx, link.BlueprintID, variables, link.CreatedBy, restrictions)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.shareLinkRepo.AddExecution(ctx, link.ID, executionID); err != nil {
+		return "", err
+	}
+	return executionID, nil
+}
+
+// takeRun takes a run of the rate limit of a share link
+func (s *ShareLinkService) takeRun(link *models.ShareLink) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.sweptAt) >= shareBucketSweepInterval {
+		s.sweepBuckets(now)
+	}
+
+	entry, exists := s.buckets[link.ID]
+	if !exists {
+		entry = &shareBucket{
+			bucket:        security.NewTokenBucket(link.RunsPerMinute, time.Minute),
+			runsPerMinute: link.RunsPerMinute,
+			expiresAt:     link.ExpiresAt,
+		}
+		s.buckets[link.ID] = entry
+	}
+	if entry.bucket.TakeToken() {
+		return nil
+	}
+
+	retryAfter := time.Duration(float64(time.Minute) / float64(link.RunsPerMinute))
+	return bperrors.New(
+		bperrors.ErrorTypeQuota,
+		bperrors.ErrRateLimitExceeded,
+		fmt.Sprintf("share link exceeded %d runs per minute", link.RunsPerMinute),
+		bperrors.SeverityMedium,
+	).WithBlueprintInfo(link.BlueprintID, "").WithDetails(map[string]interface{}{
+		"limit":             link.RunsPerMinute,
+		"retryAfterSeconds": int(retryAfter.Seconds()) + 1,
+	}).WithRecoveryOptions(bperrors.RecoveryRetry)
+}
+
+// sweepBuckets drops the rate limits of expired links and of links whose bucket refilled,
+// which a new bucket replaces without a difference. The caller holds the mutex.
+func (s *ShareLinkService) sweepBuckets(now time.Time) {
+	for linkID, entry := range s.buckets {
+		expired := entry.expiresAt != nil && !entry.expiresAt.After(now)
+		if expired || entry.bucket.AvailableTokens() >= float64(entry.runsPerMinute) {
+			delete(s.buckets, linkID)
+		}
+	}
+	s.sweptAt = now
+}
+
+// GetSharedExecution returns an execution started through the link of a token
+func (s *ShareLinkService) GetSharedExecution(ctx context.Context, token, executionID string) (*models.Execution, error) {
+	link, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	started, err := s.shareLinkRepo.HasExecution(ctx, link.ID, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, fmt.Errorf("execution %s wasn't started through the share link", executionID)
+	}
+
+	return s.executionService.GetExecution(ctx, executionID)
+}
+
+// newShareToken generates a random URL-safe share token
+func newShareToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashShareToken hashes a share token the way it's stored
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
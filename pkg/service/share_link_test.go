@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"webblueprint/internal/bperrors"
+	"webblueprint/internal/security"
+	"webblueprint/pkg/blueprint"
+)
+
+// newShareLinkTestService shares the blueprints of the workspace of newFakeAccessRepos: "bp"
+// created by its owner, "outsider" created by a user who isn't a member and "commands",
+// which runs a command
+func newShareLinkTestService(t *testing.T) (*ShareLinkService, *fakeShareLinkRepo) {
+	t.Helper()
+	blueprints := newFakeBlueprintRepo()
+	blueprints.add("workspace", "owner", printBlueprint("bp"))
+	blueprints.add("workspace", "outsider", printBlueprint("outsider"))
+	commands := printBlueprint("commands")
+	commands.AddNode(blueprint.BlueprintNode{ID: "command", Type: "run-command"})
+	blueprints.add("workspace", "owner", commands)
+
+	users, workspaces := newFakeAccessRepos()
+	links := newFakeShareLinkRepo()
+	return NewShareLinkService(
+		links,
+		NewBlueprintService(blueprints, workspaces, nil, nil),
+		NewExecutionService(newFakeExecutionRepo(), blueprints, newTestEngine(t)),
+		NewAccessService(users, workspaces),
+	), links
+}
+
+// createLink creates a share link of a blueprint as its owner
+func createLink(t *testing.T, s *ShareLinkService, blueprintID string, options ShareLinkOptions) string {
+	t.Helper()
+	_, token, err := s.CreateLink(context.Background(), blueprintID, "owner", options)
+	if err != nil {
+		t.Fatalf("failed to create share link: %v", err)
+	}
+	return token
+}
+
+func TestShareLinksAreManagedByEditors(t *testing.T) {
+	s, _ := newShareLinkTestService(t)
+	ctx := context.Background()
+	options := ShareLinkOptions{Permission: SharePermissionView}
+
+	for _, userID := range []string{"owner", "manager", "editor", "admin"} {
+		if _, _, err := s.CreateLink(ctx, "bp", userID, options); err != nil {
+			t.Errorf("%s: expected to create a share link, got %v", userID, err)
+		}
+	}
+	if _, _, err := s.CreateLink(ctx, "outsider", "outsider", options); err != nil {
+		t.Errorf("expected the creator of the blueprint to create a share link, got %v", err)
+	}
+
+	link, _, err := s.CreateLink(ctx, "bp", "owner", options)
+	if err != nil {
+		t.Fatalf("failed to create share link: %v", err)
+	}
+	for _, userID := range []string{"viewer", "outsider", "unknown", ""} {
+		if _, _, err := s.CreateLink(ctx, "bp", userID, options); !isPermissionDenied(err) {
+			t.Errorf("%s: expected creating a share link to be denied, got %v", userID, err)
+		}
+		if _, err := s.GetLinks(ctx, "bp", userID); !isPermissionDenied(err) {
+			t.Errorf("%s: expected listing the share links to be denied, got %v", userID, err)
+		}
+		if err := s.RevokeLink(ctx, "bp", link.ID, userID); !isPermissionDenied(err) {
+			t.Errorf("%s: expected revoking a share link to be denied, got %v", userID, err)
+		}
+	}
+
+	if _, _, err := s.CreateLink(ctx, "missing", "admin", options); err == nil {
+		t.Error("expected a share link of an unknown blueprint to be rejected")
+	}
+	if err := s.RevokeLink(ctx, "outsider", link.ID, "owner"); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("expected a link of another blueprint not to be found, got %v", err)
+	}
+}
+
+func TestSharedTokensStopWorkingOnceInactive(t *testing.T) {
+	s, links := newShareLinkTestService(t)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	expiring := createLink(t, s, "bp", ShareLinkOptions{Permission: SharePermissionRun, ExpiresAt: &expiresAt})
+	if _, _, err := s.GetSharedBlueprint(ctx, expiring); err != nil {
+		t.Fatalf("expected the link to work before it expires, got %v", err)
+	}
+	for _, link := range links.links {
+		expired := time.Now().Add(-time.Second)
+		link.ExpiresAt = &expired
+	}
+	if _, _, err := s.GetSharedBlueprint(ctx, expiring); !errors.Is(err, ErrShareLinkInactive) {
+		t.Errorf("expected an expired link to be inactive, got %v", err)
+	}
+	if _, err := s.RunShared(ctx, expiring, nil); !errors.Is(err, ErrShareLinkInactive) {
+		t.Errorf("expected an expired link not to run, got %v", err)
+	}
+
+	revoked, token, err := s.CreateLink(ctx, "bp", "owner", ShareLinkOptions{Permission: SharePermissionRun})
+	if err != nil {
+		t.Fatalf("failed to create share link: %v", err)
+	}
+	if err := s.RevokeLink(ctx, "bp", revoked.ID, "editor"); err != nil {
+		t.Fatalf("failed to revoke share link: %v", err)
+	}
+	if _, err := s.RunShared(ctx, token, nil); !errors.Is(err, ErrShareLinkInactive) {
+		t.Errorf("expected a revoked link not to run, got %v", err)
+	}
+
+	for _, token := range []string{"", "unknown"} {
+		if _, _, err := s.GetSharedBlueprint(ctx, token); !errors.Is(err, ErrShareLinkNotFound) {
+			t.Errorf("%q: expected an unknown token not to be found, got %v", token, err)
+		}
+	}
+}
+
+func TestViewLinksDontRun(t *testing.T) {
+	s, _ := newShareLinkTestService(t)
+	token := createLink(t, s, "bp", ShareLinkOptions{Permission: SharePermissionView})
+
+	if _, bp, err := s.GetSharedBlueprint(context.Background(), token); err != nil || bp.ID != "bp" {
+		t.Errorf("expected a view link to show the blueprint, got %v", err)
+	}
+	if _, err := s.RunShared(context.Background(), token, nil); !errors.Is(err, ErrShareLinkViewOnly) {
+		t.Errorf("expected a view link not to run, got %v", err)
+	}
+}
+
+func TestSharedRunsAreRateLimited(t *testing.T) {
+	s, _ := newShareLinkTestService(t)
+	ctx := context.Background()
+	token := createLink(t, s, "bp", ShareLinkOptions{Permission: SharePermissionRun, RunsPerMinute: 2})
+
+	for i := 0; i < 2; i++ {
+		executionID, err := s.RunShared(ctx, token, nil)
+		if err != nil {
+			t.Fatalf("run %d: expected the execution to start, got %v", i, err)
+		}
+		if _, err := s.GetSharedExecution(ctx, token, executionID); err != nil {
+			t.Errorf("run %d: expected the execution to be shared, got %v", i, err)
+		}
+	}
+
+	_, err := s.RunShared(ctx, token, nil)
+	if bpErr, ok := IsQuotaError(err); !ok || bpErr.Code != bperrors.ErrRateLimitExceeded {
+		t.Fatalf("expected the third run of the minute to be rate limited, got %v", err)
+	}
+
+	other := createLink(t, s, "bp", ShareLinkOptions{Permission: SharePermissionRun, RunsPerMinute: 1})
+	if _, err := s.RunShared(ctx, other, nil); err != nil {
+		t.Errorf("expected another link to have its own rate limit, got %v", err)
+	}
+}
+
+func TestSharedRunsRejectDeniedNodeTypes(t *testing.T) {
+	s, _ := newShareLinkTestService(t)
+	ctx := context.Background()
+
+	denied := createLink(t, s, "bp", ShareLinkOptions{Permission: SharePermissionRun, DeniedNodeTypes: []string{"pr*"}})
+	if _, err := s.RunShared(ctx, denied, nil); !isNodeTypeBlocked(err) {
+		t.Errorf("expected a denied node type to block the run, got %v", err)
+	}
+
+	commands := createLink(t, s, "commands", ShareLinkOptions{Permission: SharePermissionRun})
+	if _, err := s.RunShared(ctx, commands, nil); !isNodeTypeBlocked(err) {
+		t.Errorf("expected an opt-in node type to block the run, got %v", err)
+	}
+
+	if _, _, err := s.CreateLink(ctx, "bp", "owner", ShareLinkOptions{Permission: SharePermissionRun, DeniedNodeTypes: []string{"["}}); err == nil {
+		t.Error("expected an invalid node type pattern to be rejected")
+	}
+}
+
+func TestSweepBucketsDropsExpiredAndIdleLinks(t *testing.T) {
+	s, _ := newShareLinkTestService(t)
+	expired := time.Now().Add(-time.Second)
+	drained := security.NewTokenBucket(1, time.Minute)
+	drained.TakeToken()
+
+	s.buckets = map[string]*shareBucket{
+		"expired": {bucket: security.NewTokenBucket(1, time.Minute), runsPerMinute: 1, expiresAt: &expired},
+		"idle":    {bucket: security.NewTokenBucket(5, time.Minute), runsPerMinute: 5},
+		"drained": {bucket: drained, runsPerMinute: 1},
+	}
+	s.sweepBuckets(time.Now())
+
+	if _, kept := s.buckets["drained"]; !kept || len(s.buckets) != 1 {
+		t.Errorf("expected only the bucket of the drained link to be kept, got %v", s.buckets)
+	}
+}
+
+// isPermissionDenied reports whether err denies a user an action
+func isPermissionDenied(err error) bool {
+	_, denied := IsPermissionDeniedError(err)
+	return denied
+}
+
+// isNodeTypeBlocked reports whether err blocks a node type
+func isNodeTypeBlocked(err error) bool {
+	bpErr, ok := IsNodePolicyError(err)
+	return ok && bpErr.Code == bperrors.ErrNodeTypeBlocked
+}
@@ -0,0 +1,19 @@
+//go:build embedui
+
+// Package webblueprint embeds the built web editor in builds with the embedui tag, for
+// single-binary deployments.
+package webblueprint
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// EmbeddedUI returns the built web editor embedded in the binary
+func EmbeddedUI() (fs.FS, bool) {
+	ui, err := fs.Sub(dist, "dist")
+	return ui, err == nil
+}
@@ -0,0 +1,13 @@
+//go:build !embedui
+
+// Package webblueprint embeds the built web editor in builds with the embedui tag, for
+// single-binary deployments.
+package webblueprint
+
+import "io/fs"
+
+// EmbeddedUI reports that the web editor isn't embedded; the server serves it from
+// server.staticDir
+func EmbeddedUI() (fs.FS, bool) {
+	return nil, false
+}
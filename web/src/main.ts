@@ -2,12 +2,16 @@ import { createApp } from 'vue'
 import { createPinia } from 'pinia'
 import App from './App.vue'
 import router from './router'
+import { installBasePath } from './utils/basePath'
 
 import './assets/scss/style.scss'
 
 // Import error handling setup
 import './error-handling-setup'
 
+// API calls go through the path prefix of a reverse proxy
+installBasePath()
+
 const pinia = createPinia()
 const app = createApp(App)
 app.use(pinia)
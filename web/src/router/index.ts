@@ -1,9 +1,10 @@
 import { createRouter, createWebHistory } from 'vue-router'
 import WorkspaceView from '../views/WorkspaceView.vue'
 import HomeView from '../views/HomeView.vue'
+import { basePath } from '../utils/basePath'
 
 const router = createRouter({
-    history: createWebHistory(),
+    history: createWebHistory(basePath + '/'),
     routes: [
         {
             path: '/',
@@ -0,0 +1,97 @@
+/**
+ * Deprecation of a blueprint, or of one of its versions. Executions log a warning until the
+ * sunset date and are blocked afterwards unless they set ignoreSunset.
+ */
+export interface BlueprintDeprecation {
+  blueprintId: string;
+  versionNumber: number; // 0 for every version
+  message: string;
+  sunsetAt?: string;
+  deprecatedBy: string;
+  deprecatedAt: string;
+}
+
+/**
+ * Options of a deprecation
+ */
+export interface DeprecationOptions {
+  versionNumber?: number;
+  message: string;
+  sunsetAt?: string;
+}
+
+/**
+ * Notification of a share link, batch or service that triggered a deprecated blueprint
+ */
+export interface DeprecationWarning {
+  blueprintId: string;
+  versionNumber: number;
+  trigger: 'share-link' | 'batch' | 'service';
+  message: string;
+  sunsetAt?: string;
+  blocked: boolean;
+  overridden: boolean;
+}
+
+/**
+ * Client of the endpoints deprecating blueprints and their versions
+ */
+export class BlueprintDeprecationApi {
+  /**
+   * Fetch the deprecations of a blueprint and of its versions
+   */
+  static async fetchDeprecations(blueprintId: string): Promise<BlueprintDeprecation[]> {
+    try {
+      const response = await fetch(`/api/blueprints/${blueprintId}/deprecations`);
+      if (!response.ok) {
+        throw new Error(`Failed to fetch deprecations: ${response.statusText}`);
+      }
+      return await response.json();
+    } catch (error) {
+      console.error(`Error fetching deprecations of blueprint ${blueprintId}:`, error);
+      return [];
+    }
+  }
+
+  /**
+   * Deprecate a blueprint, or one of its versions, replacing the deprecation it had
+   */
+  static async deprecate(blueprintId: string, options: DeprecationOptions): Promise<BlueprintDeprecation> {
+    try {
+      const response = await fetch(`/api/blueprints/${blueprintId}/deprecations`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(options),
+      });
+
+      if (!response.ok) {
+        throw new Error(`Failed to deprecate blueprint: ${response.statusText}`);
+      }
+
+      return await response.json();
+    } catch (error) {
+      console.error(`Error deprecating blueprint ${blueprintId}:`, error);
+      throw error;
+    }
+  }
+
+  /**
+   * Withdraw the deprecation of a version, or of every version for 0
+   */
+  static async undeprecate(blueprintId: string, versionNumber = 0): Promise<void> {
+    try {
+      const response = await fetch(`/api/blueprints/${blueprintId}/deprecations/${versionNumber}`, {
+        method: 'DELETE',
+      });
+
+      if (!response.ok) {
+        throw new Error(`Failed to withdraw deprecation: ${response.statusText}`);
+      }
+    } catch (error) {
+      console.error(`Error withdrawing deprecation of blueprint ${blueprintId}:`, error);
+      throw error;
+    }
+  }
+}
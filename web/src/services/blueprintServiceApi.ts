@@ -0,0 +1,115 @@
+/**
+ * Status of a blueprint running as a service, i.e. until it's stopped, with supervised
+ * restarts after failures
+ */
+export interface ServiceStatus {
+  blueprintId: string;
+  status: 'running' | 'restarting' | 'stopped' | 'completed' | 'failed';
+  executionId?: string;
+  initiatedBy: string;
+  startedAt: string;
+  runStartedAt?: string;
+  stoppedAt?: string;
+  restarts: number;
+  consecutiveFailures: number;
+  maxRestarts: number;
+  lastError?: string;
+  lastFailureAt?: string;
+  nextRestartAt?: string;
+}
+
+/**
+ * Options of a service; left out options use the server defaults
+ */
+export interface StartServiceOptions {
+  variables?: Record<string, any>;
+  maxRestarts?: number;
+  restartDelayMs?: number;
+  maxRestartDelayMs?: number;
+  ignoreSunset?: boolean; // Run, and restart, a deprecated blueprint past its sunset date
+}
+
+/**
+ * Client of the endpoints starting, stopping and monitoring service blueprints
+ */
+export class BlueprintServiceApi {
+  /**
+   * Fetch the services started on the server, for the status dashboard
+   */
+  static async fetchServices(): Promise<ServiceStatus[]> {
+    try {
+      const response = await fetch('/api/services');
+      if (!response.ok) {
+        throw new Error(`Failed to fetch services: ${response.statusText}`);
+      }
+      const body = await response.json();
+      return body.services || [];
+    } catch (error) {
+      console.error('Error fetching services:', error);
+      return [];
+    }
+  }
+
+  /**
+   * Fetch the status of the service of a blueprint, or null if it never ran as one
+   */
+  static async fetchService(blueprintId: string): Promise<ServiceStatus | null> {
+    try {
+      const response = await fetch(`/api/blueprints/${blueprintId}/service`);
+      if (response.status === 404) {
+        return null;
+      }
+      if (!response.ok) {
+        throw new Error(`Failed to fetch service: ${response.statusText}`);
+      }
+      return await response.json();
+    } catch (error) {
+      console.error(`Error fetching service of blueprint ${blueprintId}:`, error);
+      return null;
+    }
+  }
+
+  /**
+   * Start running a blueprint as a service
+   */
+  static async startService(blueprintId: string, options: StartServiceOptions = {}): Promise<ServiceStatus> {
+    try {
+      const response = await fetch(`/api/blueprints/${blueprintId}/service/start`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(options),
+      });
+
+      if (!response.ok) {
+        throw new Error(`Failed to start service: ${response.statusText}`);
+      }
+
+      return await response.json();
+    } catch (error) {
+      console.error(`Error starting service of blueprint ${blueprintId}:`, error);
+      throw error;
+    }
+  }
+
+  /**
+   * Stop the service of a blueprint, resolving once its execution ended
+   */
+  static async stopService(blueprintId: string): Promise<ServiceStatus> {
+    try {
+      const response = await fetch(`/api/blueprints/${blueprintId}/service/stop`, {
+        method: 'POST',
+      });
+
+      if (!response.ok) {
+        throw new Error(`Failed to stop service: ${response.statusText}`);
+      }
+
+      return await response.json();
+    } catch (error) {
+      console.error(`Error stopping service of blueprint ${blueprintId}:`, error);
+      throw error;
+    }
+  }
+}
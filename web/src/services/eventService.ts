@@ -22,6 +22,7 @@ export interface EventDefinition {
   parameters: EventParameter[];
   blueprintId?: string;
   createdAt?: string;
+  dispatchMode?: 'parallel' | 'serial';
 }
 
 /**
@@ -36,6 +37,44 @@ export interface EventBinding {
   priority: number;
   enabled: boolean;
   createdAt?: string;
+  delivery?: 'at-most-once' | 'at-least-once';
+}
+
+/**
+ * Dispatches of events to a binding since the server started
+ */
+export interface BindingStats {
+  dispatches: number;
+  failures: number;
+  lastDispatchAt?: string;
+  lastError?: string;
+  lastErrorAt?: string;
+}
+
+/**
+ * Binding held by the server's event manager, with its dispatches
+ */
+export interface BindingStatus extends EventBinding {
+  stats: BindingStats;
+}
+
+/**
+ * Unacknowledged delivery of an at-least-once binding, or a dead letter
+ */
+export interface EventDelivery {
+  id: string;
+  bindingId: string;
+  eventId: string;
+  blueprintId: string;
+  sourceId?: string;
+  executionId?: string;
+  parameters: Record<string, any>;
+  status: 'pending' | 'dead';
+  attempts: number;
+  nextAttemptAt: string;
+  lastError?: string;
+  createdAt: string;
+  updatedAt: string;
 }
 
 /**
@@ -47,11 +86,19 @@ export class EventService {
    */
   static async fetchEvents(): Promise<EventDefinition[]> {
     try {
-      const response = await fetch('/api/events');
-      if (!response.ok) {
-        throw new Error(`Failed to fetch events: ${response.statusText}`);
-      }
-      return await response.json();
+      const events: EventDefinition[] = [];
+      let cursor = '';
+      do {
+        const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : '';
+        const response = await fetch(`/api/events${query}`);
+        if (!response.ok) {
+          throw new Error(`Failed to fetch events: ${response.statusText}`);
+        }
+        const page = await response.json();
+        events.push(...page.items);
+        cursor = page.nextCursor || '';
+      } while (cursor);
+      return events;
     } catch (error) {
       console.error('Error fetching events:', error);
       return [];
@@ -172,6 +219,83 @@ export class EventService {
     }
   }
 
+  /**
+   * Fetch the bindings of a blueprint the server holds, with their dispatch counts
+   */
+  static async fetchBlueprintBindings(blueprintId: string): Promise<BindingStatus[]> {
+    try {
+      const response = await fetch(`/api/events/blueprint/${blueprintId}/bindings`);
+      if (!response.ok) {
+        throw new Error(`Failed to fetch blueprint bindings: ${response.statusText}`);
+      }
+      return await response.json();
+    } catch (error) {
+      console.error(`Error fetching bindings for blueprint ${blueprintId}:`, error);
+      return [];
+    }
+  }
+
+  /**
+   * Enable or disable a binding without redeploying its blueprint
+   */
+  static async setBindingEnabled(bindingId: string, enabled: boolean): Promise<BindingStatus> {
+    try {
+      const action = enabled ? 'enable' : 'disable';
+      const response = await fetch(`/api/events/bindings/${bindingId}/${action}`, {
+        method: 'POST',
+      });
+
+      if (!response.ok) {
+        throw new Error(`Failed to ${action} binding: ${response.statusText}`);
+      }
+
+      return await response.json();
+    } catch (error) {
+      console.error(`Error updating binding ${bindingId}:`, error);
+      throw error;
+    }
+  }
+
+  /**
+   * Fetch the unacknowledged deliveries of at-least-once bindings, e.g. the dead letters of a blueprint
+   */
+  static async fetchDeliveries(blueprintId?: string, status?: 'pending' | 'dead'): Promise<EventDelivery[]> {
+    try {
+      const params = new URLSearchParams();
+      if (blueprintId) params.set('blueprintId', blueprintId);
+      if (status) params.set('status', status);
+
+      const response = await fetch(`/api/events/deliveries?${params.toString()}`);
+      if (!response.ok) {
+        throw new Error(`Failed to fetch deliveries: ${response.statusText}`);
+      }
+      return await response.json();
+    } catch (error) {
+      console.error('Error fetching deliveries:', error);
+      return [];
+    }
+  }
+
+  /**
+   * Give a dead letter a new round of attempts
+   */
+  static async redeliver(deliveryId: string): Promise<EventDelivery> {
+    try {
+      const response = await fetch(`/api/events/deliveries/${deliveryId}/redeliver`, {
+        method: 'POST',
+      });
+
+      if (!response.ok) {
+        throw new Error(`Failed to redeliver: ${response.statusText}`);
+      }
+
+      return await response.json();
+    } catch (error) {
+      console.error(`Error redelivering ${deliveryId}:`, error);
+      throw error;
+    }
+  }
+
   /**
    * Create a new event binding
    */
@@ -1,6 +1,6 @@
 import {defineStore} from 'pinia'
 import {computed, ref} from 'vue'
-import type {Blueprint, Function, Connection, Node, Position, Variable} from '../types/blueprint'
+import type {Blueprint, Function, Connection, Frame, LintReport, Node, Position, Variable} from '../types/blueprint'
 import {v4 as uuid} from 'uuid'
 import {useWorkspaceStore} from "./workspace";
 import {isEqual} from 'lodash'
@@ -33,6 +33,9 @@ export const useBlueprintStore = defineStore('blueprint', () => {
     // Track available versions for the current blueprint
     const availableVersions = ref<{versionNumber: number, createdAt: string, comment: string}[]>([])
 
+    // Latest lint report of the blueprint, against the lint rules of its workspace
+    const lintReport = ref<LintReport | null>(null)
+
     // Getters
     const nodes = computed(() => blueprint.value.nodes || [])
     const connections = computed(() => blueprint.value.connections || [])
@@ -357,6 +360,35 @@ export const useBlueprintStore = defineStore('blueprint', () => {
         }
     }
 
+    // Lints the current, possibly unsaved, state of the blueprint
+    async function lintBlueprint(workspaceId?: string): Promise<LintReport | null> {
+        const workspace = workspaceId || useWorkspaceStore().currentWorkspace?.id;
+        if (!workspace) {
+            error.value = 'No workspace specified for linting blueprint';
+            return null;
+        }
+
+        try {
+            const response = await fetch(`/api/workspaces/${workspace}/lint`, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json'
+                },
+                body: JSON.stringify(blueprint.value)
+            });
+
+            if (!response.ok) {
+                throw new Error(`Failed to lint blueprint: ${response.statusText}`);
+            }
+
+            lintReport.value = await response.json();
+            return lintReport.value;
+        } catch (err) {
+            console.error('Error linting blueprint:', err);
+            return null;
+        }
+    }
+
     function checkNodeBind(node: Node) {
         return node.type === 'event-bind'
     }
@@ -426,6 +458,32 @@ export const useBlueprintStore = defineStore('blueprint', () => {
 
         // Remove node
         blueprint.value.nodes = blueprint.value.nodes.filter(node => node.id !== id)
+
+        // Remove it from its frames
+        blueprint.value.frames?.forEach(frame => {
+            frame.nodeIds = frame.nodeIds?.filter(nodeId => nodeId !== id)
+        })
+    }
+
+    function addFrame(frame: Frame) {
+        if (!frame.id) {
+            frame.id = uuid()
+        }
+        if (!blueprint.value.frames) {
+            blueprint.value.frames = []
+        }
+        blueprint.value.frames.push(frame)
+    }
+
+    function updateFrame(id: string, updates: Partial<Frame>) {
+        const frame = blueprint.value.frames?.find(frame => frame.id === id)
+        if (frame) {
+            Object.assign(frame, updates)
+        }
+    }
+
+    function removeFrame(id: string) {
+        blueprint.value.frames = blueprint.value.frames?.filter(frame => frame.id !== id)
     }
 
     function findNode(id: string) {
@@ -625,11 +683,16 @@ export const useBlueprintStore = defineStore('blueprint', () => {
         loadBlueprintVersions,
         saveBlueprint,
         createNewVersion,
+        lintReport,
+        lintBlueprint,
         addNode,
         updateNode,
         updateNodePosition,
         updateNodeProperty,
         removeNode,
+        addFrame,
+        updateFrame,
+        removeFrame,
         addConnection,
         removeConnection,
         variables,
@@ -62,6 +62,27 @@ export const useEventStore = defineStore('events', () => {
         }
       ]
     },
+    {
+      id: 'event-on-error',
+      name: 'On Error',
+      description: 'Called when a node fails and no catch flow handles the error',
+      type: 'entry',
+      category: 'System Events',
+      parameters: [
+        {
+          name: 'error',
+          type: 'object',
+          description: 'Error information (code, message, retryable)',
+          optional: false
+        },
+        {
+          name: 'nodeId',
+          type: 'string',
+          description: 'ID of the node that failed',
+          optional: false
+        }
+      ]
+    },
   ])
   
   // Events from server (custom event dispatchers)
@@ -1,7 +1,7 @@
 import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
 import { useWebSocketStore, WebSocketEvents } from './websocket'
-import type {NodeExecutionStatus, NodeDebugData, DataFlow, LogEntry} from '../types/execution'
+import type {NodeExecutionStatus, NodeDebugData, DataFlow, DataBatch, LogEntry} from '../types/execution'
 import { useBlueprintStore } from './blueprint'
 
 type NodeStatus = 'idle' | 'executing' | 'completed' | 'error'
@@ -72,6 +72,7 @@ export const useExecutionStore = defineStore('execution', () => {
 
     function startExecution(executionId: string) {
         currentExecutionId.value = executionId
+        websocketStore.subscribeExecution(executionId)
         executionStatus.value = 'running'
         executionStartTime.value = new Date()
         executionEndTime.value = null
@@ -196,6 +197,24 @@ export const useExecutionStore = defineStore('execution', () => {
         }
     }
 
+    function recordDataBatch(batch: DataBatch) {
+        for (const value of batch.values) {
+            recordDataFlow({
+                sourceNodeId: value.sourceNodeId ?? batch.nodeId,
+                sourcePinId: value.sourcePinId ?? value.pinId ?? '',
+                targetNodeId: value.targetNodeId ?? '',
+                targetPinId: value.targetPinId ?? '',
+                value: value.value,
+                timestamp: new Date(batch.timestamp)
+            })
+        }
+    }
+
+    // Asks for every value a node produces instead of batches, e.g. while debugging the node
+    function setNodeFullFidelity(nodeId: string, full: boolean) {
+        websocketStore.send(WebSocketEvents.VALUES_FIDELITY, { nodeId, full })
+    }
+
     async function loadExecution(executionId: string) {
         try {
             const response = await fetch(`/api/executions/${executionId}`)
@@ -261,6 +280,7 @@ export const useExecutionStore = defineStore('execution', () => {
         websocketStore.on(WebSocketEvents.NODE_ERROR, updateNodeStatus)
         websocketStore.on(WebSocketEvents.DEBUG_DATA, updateNodeDebugData)
         websocketStore.on(WebSocketEvents.DATA_FLOW, recordDataFlow)
+        websocketStore.on(WebSocketEvents.DATA_BATCH, recordDataBatch)
         websocketStore.on(WebSocketEvents.LOG, (data: any) => {
             addLogEntry({
                 level: data.level.toUpperCase(),
@@ -292,6 +312,7 @@ export const useExecutionStore = defineStore('execution', () => {
         updateNodeStatus,
         updateNodeDebugData,
         recordDataFlow,
+        setNodeFullFidelity,
         clearDebugData,
         fetchNodeDebugData,
         logs,
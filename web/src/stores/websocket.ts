@@ -1,5 +1,9 @@
 import { defineStore } from 'pinia'
 import { ref } from 'vue'
+import { basePath } from '../utils/basePath'
+
+// Version of the WebSocket protocol this client speaks, see /api/ws/schema
+export const PROTOCOL_VERSION = 1
 
 // Enhanced WebSocket message types with more precise typing
 export const WebSocketEvents = {
@@ -8,19 +12,40 @@ export const WebSocketEvents = {
     NODE_COMPLETE: 'node.complete',
     NODE_ERROR: 'node.error',
     DATA_FLOW: 'data.flow',
+    DATA_BATCH: 'data.batch',
+    VALUES_FIDELITY: 'values.fidelity',
     DEBUG_DATA: 'debug.data',
     EXEC_START: 'execution.start',
     EXEC_END: 'execution.end',
     EXEC_STATUS: 'execution.status',
     RESULT: 'result',
-    LOG: 'log'
+    LOG: 'log',
+    EVENTS_RESUME: 'events.resume',
+    EVENTS_RESUMED: 'events.resumed',
+    PROTOCOL_ERROR: 'protocol.error'
 }
 
 export type MessageHandler = (data: unknown) => void
 
 export interface WebSocketMessage {
     type: string
+    version?: number
     payload: unknown
+    seq?: number
+}
+
+// Payload of the protocol.error message, sent when the server rejects a message of the client
+export interface ProtocolError {
+    code: 'malformed' | 'unsupported_version' | 'unknown_type' | 'invalid_payload'
+    error: string
+    type?: string
+}
+
+// Payload of the events.resumed message; complete is false when events were missed for good
+export interface ResumeResult {
+    complete: boolean
+    replayed: number
+    error?: string
 }
 
 export const useWebSocketStore = defineStore('websocket', () => {
@@ -31,6 +56,9 @@ export const useWebSocketStore = defineStore('websocket', () => {
     const reconnectAttempts = ref(0)
     const maxReconnectAttempts = ref(5)
     const handlers = ref<Map<string, MessageHandler[]>>(new Map())
+    // Token of the latest broadcast event received, sent on reconnect to resume from it
+    const resumeToken = ref<string | null>(null)
+    const subscribedExecutions = ref<Set<string>>(new Set())
 
     // Actions
     function connect() {
@@ -43,7 +71,7 @@ export const useWebSocketStore = defineStore('websocket', () => {
         // Determine WebSocket URL based on current location
         const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
         const host = window.location.host
-        const wsUrl = `${protocol}//${host}/ws`
+        const wsUrl = `${protocol}//${host}${basePath}/ws?version=${PROTOCOL_VERSION}`
 
         console.log(`Connecting to WebSocket at ${wsUrl}`)
 
@@ -126,11 +154,53 @@ export const useWebSocketStore = defineStore('websocket', () => {
             return
         }
 
-        const message = { type, payload }
+        const message = { type, version: PROTOCOL_VERSION, payload }
         socket.value.send(JSON.stringify(message))
     }
 
+    function subscribeExecution(executionId: string): void {
+        subscribedExecutions.value.add(executionId)
+    }
+
+    function unsubscribeExecution(executionId: string): void {
+        subscribedExecutions.value.delete(executionId)
+    }
+
+    // Tracks the resume token; on the welcome message of a reconnect, requests the
+    // events of the subscribed executions missed while disconnected
+    function trackResumeToken(message: WebSocketMessage): void {
+        if (message.seq) {
+            // Replayed events are older than the live ones received meanwhile
+            if (resumeToken.value === null || message.seq > Number(resumeToken.value)) {
+                resumeToken.value = String(message.seq)
+            }
+            return
+        }
+
+        const payload = message.payload as { status?: string, resumeToken?: string } | null
+        if (message.type !== WebSocketEvents.EXEC_STATUS || payload?.status !== 'connected') {
+            return
+        }
+
+        if (resumeToken.value !== null) {
+            send(WebSocketEvents.EVENTS_RESUME, {
+                token: resumeToken.value,
+                executionIds: Array.from(subscribedExecutions.value)
+            })
+        }
+        if (payload.resumeToken !== undefined) {
+            resumeToken.value = payload.resumeToken
+        }
+    }
+
     function handleMessage(message: WebSocketMessage): void {
+        trackResumeToken(message)
+
+        if (message.type === WebSocketEvents.PROTOCOL_ERROR) {
+            const error = message.payload as ProtocolError
+            console.warn(`WebSocket message ${error.type ?? ''} rejected (${error.code}): ${error.error}`)
+        }
+
         // Call handlers for this message type
         const eventHandlers = handlers.value.get(message.type) || []
         eventHandlers.forEach(handler => handler(message.payload))
@@ -146,6 +216,8 @@ export const useWebSocketStore = defineStore('websocket', () => {
         connect,
         disconnect,
         on,
-        send
+        send,
+        subscribeExecution,
+        unsubscribeExecution
     }
 })
\ No newline at end of file
@@ -23,6 +23,18 @@ export interface Node {
     data?: Record<string, any>
 }
 
+// Whether executions of a blueprint may overlap, e.g. for migrations
+export interface ConcurrencyPolicy {
+    mode?: 'allow-parallel' | 'queue' | 'reject-if-running'  // Defaults to allow-parallel
+    maxQueued?: number                                        // 0 or unset is unlimited
+}
+
+// Bounds every execution of a blueprint; unset limits don't bound it
+export interface ExecutionBudget {
+    maxDuration?: string        // Go duration, e.g. "30s"
+    maxNodeExecutions?: number  // Every run of a node counts
+}
+
 // Represents a connection between nodes
 export interface Connection {
     id: string
@@ -64,9 +76,47 @@ export interface Blueprint {
     variables: Variable[]
     events: EventDefinition[]
     eventBindings: EventBinding[]
+    errorPolicy?: ErrorPolicy
+    concurrencyPolicy?: ConcurrencyPolicy
+    budget?: ExecutionBudget
+    frames?: Frame[]
     metadata: Record<string, string>
 }
 
+// Groups nodes on the canvas under a title; validation and execution ignore frames
+export interface Frame {
+    id: string
+    title: string
+    comment?: string
+    color?: string
+    position: Position
+    size: { width: number, height: number }
+    collapsed?: boolean
+    nodeIds?: string[]
+}
+
+// A problem a lint rule found in a blueprint
+export interface LintFinding {
+    rule: string
+    severity: 'error' | 'warning' | 'info'
+    message: string
+    nodeId?: string
+}
+
+// Result of linting a blueprint; it passes when no finding is an error
+export interface LintReport {
+    blueprintId: string
+    passed: boolean
+    counts: Record<'error' | 'warning' | 'info', number>
+    findings: LintFinding[]
+}
+
+// Handling of node errors that no catch flow handles
+export interface ErrorPolicy {
+    mode?: 'fail-fast' | 'continue'  // Defaults to fail-fast
+    handlerNodeId?: string           // Defaults to the first event-on-error node
+}
+
 // Represents a connection between nodes
 export interface Connection {
     id: string
@@ -24,6 +24,7 @@ export enum RecoveryStrategy {
   Retry = "retry",
   SkipNode = "skip_node",
   UseDefaultValue = "use_default_value",
+  AlternatePath = "alternate_path",
   ManualIntervention = "manual",
   None = "none"
 }
@@ -36,6 +37,13 @@ export enum BlueprintErrorCode {
   ExecutionTimeout = "E004",
   ExecutionCancelled = "E005",
   NoEntryPoints = "E006",
+  OperationFailed = "E007",
+  ServiceUnavailable = "E008",
+  NodeTimeout = "E009",
+  NodePanicked = "E010",
+  ActorFailed = "E011",
+  BlueprintRunning = "E012",
+  TriggerSkipped = "E013",
 
   // Connection errors
   InvalidConnection = "C001",
@@ -43,12 +51,21 @@ export enum BlueprintErrorCode {
   MissingRequiredInput = "C003",
   TypeMismatch = "C004",
   NodeDisconnected = "C005",
+  InvalidTransform = "C006",
+  InvalidCondition = "C007",
 
   // Validation errors
   InvalidBlueprintStructure = "V001",
   InvalidNodeConfiguration = "V002",
   MissingProperty = "V003",
   InvalidPropertyValue = "V004",
+  InvalidInputValue = "V005",
+
+  // Network errors
+  RequestFailed = "N001",
+  RequestTimeout = "N002",
+  InvalidResponse = "N003",
+  CircuitOpen = "N004",
 
   // Database errors
   DatabaseConnection = "D001",
@@ -61,6 +78,9 @@ export enum BlueprintErrorCode {
   ResourceExhausted = "S002",
   SystemUnavailable = "S003",
 
+  // Permission errors
+  NodeTypeBlocked = "P001",
+
   // Other error codes
   Unknown = "U001"
 }
@@ -84,6 +104,18 @@ export interface BlueprintError {
   expanded?: boolean;
 }
 
+// Value of the "error" pin of side-effecting nodes
+export interface ErrorPinValue {
+  code: BlueprintErrorCode;
+  type: ErrorType;
+  message: string;
+  severity: ErrorSeverity;
+  retryable: boolean;
+  nodeId?: string;
+  pinId?: string;
+  details?: Record<string, any>;
+}
+
 export interface RecoveryAttempt {
   strategy: RecoveryStrategy;
   successful: boolean;
@@ -35,6 +35,15 @@ export interface DataFlow {
     timestamp: Date
 }
 
+// Latest values of a node's pins produced since the previous batch; updates counts every
+// value the batch stands for
+export interface DataBatch {
+    nodeId: string
+    values: Array<Partial<DataFlow> & { pinId?: string }>
+    updates: number
+    timestamp: string
+}
+
 // Execution result
 export interface ExecutionResult {
     executionId: string
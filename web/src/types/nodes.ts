@@ -33,17 +33,34 @@ export interface NodePropertyDefinition {
     options?: string[]; // For select property type
 }
 
+// Group of numbered pins whose count is set in the data of each node, mirroring
+// node.VariadicPins on the server
+export interface VariadicPinGroup {
+    prefix: string; // Pin IDs are the prefix followed by the number
+    name: string;
+    description: string;
+    type?: string; // Pin type ID
+    output: boolean;
+    countKey: string; // Key of the number of pins in the node data
+    default: number;
+    min: number;
+    max?: number;
+}
+
 // Represents a node type definition
 export interface NodeTypeDefinition {
     typeId: string;
     name: string;
     description: string;
     category: string;
+    categoryName?: string; // Category in the locale of the catalog, the palette still groups by category
     version: string;
     inputs: PinDefinition[];
     outputs: PinDefinition[];
     properties?: NodePropertyDefinition[];
     icon?: string; // Optional icon for the node
+    dynamicPins?: boolean; // Whether the node data declares additional pins
+    variadicPins?: VariadicPinGroup[]; // Numbered pins whose count the node data sets
 }
 
 // Built-in pin types
@@ -54,6 +71,8 @@ export const PinTypes = {
     BOOLEAN: 'boolean',
     OBJECT: 'object',
     ARRAY: 'array',
+    BYTES: 'bytes',
+    STREAM: 'stream',
     ANY: 'any'
 };
 
@@ -65,6 +84,8 @@ export const PinTypeColors = {
     [PinTypes.BOOLEAN]: '#dc5050',
     [PinTypes.OBJECT]: '#8ab4f8',
     [PinTypes.ARRAY]: '#bb86fc',
+    [PinTypes.BYTES]: '#d4a373',
+    [PinTypes.STREAM]: '#4ec9b0',
     [PinTypes.ANY]: '#aaaaaa'
 };
 
@@ -0,0 +1,28 @@
+/**
+ * Path prefix the server is served under behind a reverse proxy, e.g. /webblueprint. The
+ * server sets it in index.html; it's empty when the editor is served at the root.
+ */
+export const basePath: string = ((window as any).__WEBBLUEPRINT_BASE__ || '').replace(/\/$/, '');
+
+/**
+ * Prefixes a root-relative URL, such as /api/nodes, with the base path
+ */
+export function withBase(url: string): string {
+    if (!basePath || !url.startsWith('/') || url.startsWith('//') || url === basePath || url.startsWith(basePath + '/')) {
+        return url;
+    }
+    return basePath + url;
+}
+
+/**
+ * Sends the requests of fetch to root-relative URLs through the base path, so the API
+ * calls of the editor reach the server behind the proxy
+ */
+export function installBasePath(): void {
+    if (!basePath) {
+        return;
+    }
+    const originalFetch = window.fetch.bind(window);
+    window.fetch = (input: RequestInfo | URL, init?: RequestInit) =>
+        originalFetch(typeof input === 'string' ? withBase(input) : input, init);
+}
@@ -1,6 +1,7 @@
 import type { NodeTypeDefinition, PinDefinition } from '../types/nodes';
 import { useBlueprintStore } from '../stores/blueprint';
 import { useNodeRegistryStore } from '../stores/nodeRegistry';
+import { getNodeInputPins, getNodeOutputPins } from './dynamicPins';
 
 interface ValidationResult {
     valid: boolean;
@@ -43,8 +44,8 @@ export function validateConnection(
     }
 
     // Find the pin definitions
-    const sourcePin = sourceNodeType.outputs.find(pin => pin.id === sourcePinId);
-    const targetPin = targetNodeType.inputs.find(pin => pin.id === targetPinId);
+    const sourcePin = getNodeOutputPins(sourceNodeType, sourceNode).find(pin => pin.id === sourcePinId);
+    const targetPin = getNodeInputPins(targetNodeType, targetNode).find(pin => pin.id === targetPinId);
 
     if (!sourcePin || !targetPin) {
         return {
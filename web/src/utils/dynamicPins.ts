@@ -0,0 +1,130 @@
+import type { Node } from '../types/blueprint';
+import type { NodeTypeDefinition, PinDefinition, VariadicPinGroup } from '../types/nodes';
+
+const executionPinType = {
+    id: 'execution',
+    name: 'Execution',
+    description: 'Controls execution flow',
+};
+
+/**
+ * Declares the case pins of a switch node from its data, mirroring SwitchNode.ConfigurePins
+ * on the server: cases are numbered from 1 unless they have an ID.
+ */
+function switchCasePins(node: Node): PinDefinition[] {
+    const cases = Array.isArray(node.data?.cases) ? node.data.cases : [];
+
+    return cases.map((entry: any, index: number) => {
+        const isObject = entry !== null && typeof entry === 'object';
+        const value = isObject ? entry.value : entry;
+        const id = isObject && entry.id ? String(entry.id) : String(index + 1);
+
+        return {
+            id: `case_${id}`,
+            name: isObject && entry.label ? entry.label : `Case ${value}`,
+            description: `Executed if the value matches ${value}`,
+            type: executionPinType,
+        };
+    });
+}
+
+/**
+ * Declares the execution inputs of a join node from its data, mirroring
+ * JoinNode.ConfigurePins on the server
+ */
+function joinInputPins(node: Node): PinDefinition[] {
+    const count = Number(node.data?.inputs ?? 2);
+    const inputs = Number.isInteger(count) && count > 0 ? count : 2;
+
+    return Array.from({ length: inputs }, (_, index) => ({
+        id: `in_${index + 1}`,
+        name: `Input ${index + 1}`,
+        description: `Execution input of branch ${index + 1}`,
+        type: executionPinType,
+    }));
+}
+
+/**
+ * Replaces the pins of a variadic group with the number of pins the node data sets,
+ * mirroring VariadicPins.Count and VariadicPins.Pins on the server
+ */
+function variadicGroupPins(group: VariadicPinGroup, pins: PinDefinition[], node: Node): PinDefinition[] {
+    const isGroupPin = (pin: PinDefinition) =>
+        pin.id.startsWith(group.prefix) && /^\d+$/.test(pin.id.slice(group.prefix.length));
+
+    const raw = node.data?.[group.countKey];
+    let count = raw === undefined || raw === null ? group.default : Number(raw);
+    if (!Number.isInteger(count) || count < group.min || (group.max && count > group.max)) {
+        count = group.default;
+    }
+
+    const template = pins.find(isGroupPin);
+    const type = template?.type ?? { id: group.type ?? 'any', name: group.type ?? 'any', description: '' };
+    const generated = Array.from({ length: count }, (_, index) => ({
+        id: `${group.prefix}${index + 1}`,
+        name: `${group.name} ${index + 1}`,
+        description: group.description,
+        type,
+        optional: true,
+    }));
+
+    const first = pins.findIndex(isGroupPin);
+    const kept = pins.filter(pin => !isGroupPin(pin));
+    const at = first < 0 ? kept.length : first;
+    return [...kept.slice(0, at), ...generated, ...kept.slice(at)];
+}
+
+/**
+ * Declares the pins of the variadic groups of a node from its data
+ */
+function variadicPins(nodeType: NodeTypeDefinition, node: Node): DynamicPins {
+    let inputs = nodeType.inputs;
+    let outputs = nodeType.outputs;
+    for (const group of nodeType.variadicPins ?? []) {
+        if (group.output) {
+            outputs = variadicGroupPins(group, outputs, node);
+        } else {
+            inputs = variadicGroupPins(group, inputs, node);
+        }
+    }
+    return { inputs, outputs, replaceOutputs: true };
+}
+
+interface DynamicPins {
+    inputs?: PinDefinition[];  // Replace the inputs of the node type
+    outputs?: PinDefinition[]; // Precede the outputs of the node type
+    replaceOutputs?: boolean;  // The outputs replace those of the node type instead
+}
+
+const dynamicPinResolvers: Record<string, (node: Node) => DynamicPins> = {
+    switch: node => ({ outputs: switchCasePins(node) }),
+    join: node => ({ inputs: joinInputPins(node) }),
+};
+
+function resolveDynamicPins(nodeType: NodeTypeDefinition, node: Node): DynamicPins {
+    if (nodeType.variadicPins?.length) {
+        return variadicPins(nodeType, node);
+    }
+    const resolve = nodeType.dynamicPins ? dynamicPinResolvers[nodeType.typeId] : undefined;
+    return resolve ? resolve(node) : {};
+}
+
+/**
+ * Returns the input pins of a node, including the pins declared by its data for node
+ * types with dynamic pins
+ */
+export function getNodeInputPins(nodeType: NodeTypeDefinition, node: Node): PinDefinition[] {
+    return resolveDynamicPins(nodeType, node).inputs ?? nodeType.inputs;
+}
+
+/**
+ * Returns the output pins of a node, including the pins declared by its data for node
+ * types with dynamic pins
+ */
+export function getNodeOutputPins(nodeType: NodeTypeDefinition, node: Node): PinDefinition[] {
+    const { outputs, replaceOutputs } = resolveDynamicPins(nodeType, node);
+    if (!outputs) {
+        return nodeType.outputs;
+    }
+    return replaceOutputs ? outputs : [...outputs, ...nodeType.outputs];
+}
@@ -4,6 +4,8 @@ import path from 'path'
 
 // https://vitejs.dev/config/
 export default defineConfig({
+    // Relative asset URLs work under any path prefix; the server sets the base URL of the page
+    base: './',
     plugins: [vue()],
     resolve: {
         alias: {